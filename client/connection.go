@@ -62,6 +62,9 @@ type ConnectionArgs struct {
 	// Skip automatic GetServer request upon connection
 	SkipGetServer bool
 
+	// Secret of the instance share to access the server through (API extension: instance_shares)
+	ShareSecret string
+
 	// Caching support for image servers
 	CachePath   string
 	CacheExpiry time.Duration
@@ -337,6 +340,7 @@ func httpsIncus(ctx context.Context, requestURL string, args *ConnectionArgs) (I
 		httpBaseURL:        *httpBaseURL,
 		httpProtocol:       "https",
 		httpUserAgent:      args.UserAgent,
+		httpShareSecret:    args.ShareSecret,
 		ctxConnected:       ctxConnected,
 		ctxConnectedCancel: ctxConnectedCancel,
 		eventConns:         make(map[string]*websocket.Conn),
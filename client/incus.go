@@ -43,6 +43,7 @@ type ProtocolIncus struct {
 	httpUnixPath    string
 	httpProtocol    string
 	httpUserAgent   string
+	httpShareSecret string
 
 	requireAuthenticated bool
 
@@ -157,12 +158,17 @@ func (r *ProtocolIncus) DoHTTP(req *http.Request) (*http.Response, error) {
 // addClientHeaders sets headers from client settings.
 // User-Agent (if r.httpUserAgent is set).
 // X-Incus-authenticated (if r.requireAuthenticated is set).
+// X-Incus-share (if r.httpShareSecret is set).
 // OIDC Authorization header (if r.oidcClient is set).
 func (r *ProtocolIncus) addClientHeaders(req *http.Request) {
 	if r.httpUserAgent != "" {
 		req.Header.Set("User-Agent", r.httpUserAgent)
 	}
 
+	if r.httpShareSecret != "" {
+		req.Header.Set("X-Incus-share", r.httpShareSecret)
+	}
+
 	if r.requireAuthenticated {
 		req.Header.Set("X-Incus-authenticated", "true")
 	}
@@ -464,10 +470,11 @@ func (r *ProtocolIncus) rawWebsocket(url string) (*websocket.Conn, error) {
 func (r *ProtocolIncus) websocket(path string) (*websocket.Conn, error) {
 	// Generate the URL
 	var url string
+	basePath := strings.TrimSuffix(r.httpBaseURL.Path, "/")
 	if r.httpBaseURL.Scheme == "https" {
-		url = fmt.Sprintf("wss://%s/1.0%s", r.httpBaseURL.Host, path)
+		url = fmt.Sprintf("wss://%s%s/1.0%s", r.httpBaseURL.Host, basePath, path)
 	} else {
-		url = fmt.Sprintf("ws://%s/1.0%s", r.httpBaseURL.Host, path)
+		url = fmt.Sprintf("ws://%s%s/1.0%s", r.httpBaseURL.Host, basePath, path)
 	}
 
 	return r.rawWebsocket(url)
@@ -502,7 +509,13 @@ func (r *ProtocolIncus) getSourceImageConnectionInfo(source ImageServer, image a
 
 	// Optimization for the local image case
 	if r.isSameServer(source) {
-		// Always use fingerprints for local case
+		// Let the server resolve aliases so it can pick the image matching the architecture of the target member.
+		if instSrc.Alias != "" && r.HasExtension("cluster_architecture_placement") {
+			instSrc.Fingerprint = ""
+			return nil, nil
+		}
+
+		// Otherwise use fingerprints for local case
 		instSrc.Fingerprint = image.Fingerprint
 		instSrc.Alias = ""
 		return nil, nil
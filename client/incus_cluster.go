@@ -172,6 +172,21 @@ func (r *ProtocolIncus) UpdateClusterCertificate(certs api.ClusterCertificatePut
 	return nil
 }
 
+// RolloutClusterConfig applies a cluster-wide configuration change to canary members first, then to the whole cluster.
+func (r *ProtocolIncus) RolloutClusterConfig(rollout api.ClusterConfigRolloutPost) (Operation, error) {
+	err := r.CheckExtension("clustering_config_rollout")
+	if err != nil {
+		return nil, err
+	}
+
+	op, _, err := r.queryOperation("POST", "/cluster/config-rollout", rollout, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // GetClusterMemberState gets state information about a cluster member.
 func (r *ProtocolIncus) GetClusterMemberState(name string) (*api.ClusterMemberState, string, error) {
 	err := r.CheckExtension("cluster_member_state")
@@ -189,6 +204,23 @@ func (r *ProtocolIncus) GetClusterMemberState(name string) (*api.ClusterMemberSt
 	return &state, etag, err
 }
 
+// GetClusterMemberManagedRules gets the firewall rules, OVN ACLs and sysctls managed by Incus on a cluster member.
+func (r *ProtocolIncus) GetClusterMemberManagedRules(name string) ([]api.ClusterMemberManagedRule, error) {
+	err := r.CheckExtension("cluster_member_managed_rules")
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []api.ClusterMemberManagedRule{}
+	u := api.NewURL().Path("cluster", "members", name, "managed-rules")
+	_, err = r.queryStruct("GET", u.String(), nil, "", &rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
 // UpdateClusterMemberState evacuates or restores a cluster member.
 func (r *ProtocolIncus) UpdateClusterMemberState(name string, state api.ClusterMemberStatePost) (Operation, error) {
 	if !r.HasExtension("clustering_evacuation") {
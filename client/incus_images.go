@@ -75,6 +75,29 @@ func (r *ProtocolIncus) GetImagesWithFilter(filters []string) ([]api.Image, erro
 	return images, nil
 }
 
+// IterateImages passes the images matching the filters to the handler, retrieving them in pages of pageSize images.
+func (r *ProtocolIncus) IterateImages(filters []string, pageSize int, handler func(image api.Image) error) error {
+	if len(filters) > 0 && !r.HasExtension("api_filtering") {
+		return fmt.Errorf("The server is missing the required \"api_filtering\" API extension")
+	}
+
+	return iteratePages(r.HasExtension("api_pagination"), pageSize, func(v url.Values) ([]api.Image, error) {
+		images := []api.Image{}
+
+		v.Set("recursion", "1")
+		if len(filters) > 0 {
+			v.Set("filter", parseFilters(filters))
+		}
+
+		_, err := r.queryStruct("GET", fmt.Sprintf("/images?%s", v.Encode()), nil, "", &images)
+		if err != nil {
+			return nil, err
+		}
+
+		return images, nil
+	}, handler)
+}
+
 // GetImageFingerprints returns a list of available image fingerprints.
 func (r *ProtocolIncus) GetImageFingerprints() ([]string, error) {
 	// Fetch the raw URL values.
@@ -961,6 +984,21 @@ func (r *ProtocolIncus) RefreshImage(fingerprint string) (Operation, error) {
 	return op, nil
 }
 
+// OptimizeImage requests that Incus converts the stored files of an image to a new compression or format.
+func (r *ProtocolIncus) OptimizeImage(fingerprint string, image api.ImageOptimizePost) (Operation, error) {
+	if !r.HasExtension("image_optimize") {
+		return nil, fmt.Errorf("The server is missing the required \"image_optimize\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/images/%s/optimize", url.PathEscape(fingerprint)), image, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // CreateImageSecret requests that Incus issues a temporary image secret.
 func (r *ProtocolIncus) CreateImageSecret(fingerprint string) (Operation, error) {
 	// Send the request
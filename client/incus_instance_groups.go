@@ -0,0 +1,111 @@
+package incus
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// GetInstanceGroupNames returns a list of instance group names.
+func (r *ProtocolIncus) GetInstanceGroupNames() ([]string, error) {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/instance-groups"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetInstanceGroups returns a list of instance group structs.
+func (r *ProtocolIncus) GetInstanceGroups() ([]api.InstanceGroup, error) {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []api.InstanceGroup{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/instance-groups?recursion=1", nil, "", &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// GetInstanceGroup returns an instance group entry for the provided name.
+func (r *ProtocolIncus) GetInstanceGroup(name string) (*api.InstanceGroup, string, error) {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return nil, "", err
+	}
+
+	group := api.InstanceGroup{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/instance-groups/%s", url.PathEscape(name)), nil, "", &group)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &group, etag, nil
+}
+
+// CreateInstanceGroup defines a new instance group using the provided struct.
+func (r *ProtocolIncus) CreateInstanceGroup(group api.InstanceGroupsPost) error {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/instance-groups", group, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateInstanceGroup updates the instance group to match the provided struct.
+func (r *ProtocolIncus) UpdateInstanceGroup(name string, group api.InstanceGroupPut, ETag string) error {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/instance-groups/%s", url.PathEscape(name)), group, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteInstanceGroup deletes an existing instance group.
+func (r *ProtocolIncus) DeleteInstanceGroup(name string) error {
+	err := r.CheckExtension("instance_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/instance-groups/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package incus
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// GetInstancePoolNames returns a list of instance pool names.
+func (r *ProtocolIncus) GetInstancePoolNames() ([]string, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/instance-pools"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetInstancePools returns a list of instance pool structs.
+func (r *ProtocolIncus) GetInstancePools() ([]api.InstancePool, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	pools := []api.InstancePool{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/instance-pools?recursion=1", nil, "", &pools)
+	if err != nil {
+		return nil, err
+	}
+
+	return pools, nil
+}
+
+// GetInstancePool returns an instance pool entry for the provided name.
+func (r *ProtocolIncus) GetInstancePool(name string) (*api.InstancePool, string, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, "", err
+	}
+
+	pool := api.InstancePool{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/instance-pools/%s", url.PathEscape(name)), nil, "", &pool)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &pool, etag, nil
+}
+
+// CreateInstancePool defines a new instance pool using the provided struct.
+func (r *ProtocolIncus) CreateInstancePool(pool api.InstancePoolsPost) error {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/instance-pools", pool, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateInstancePool updates the instance pool to match the provided struct.
+func (r *ProtocolIncus) UpdateInstancePool(name string, pool api.InstancePoolPut, ETag string) error {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/instance-pools/%s", url.PathEscape(name)), pool, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteInstancePool deletes an existing instance pool.
+func (r *ProtocolIncus) DeleteInstancePool(name string) error {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/instance-pools/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AcquireInstancePoolMember hands out one of the ready instances of the instance pool.
+func (r *ProtocolIncus) AcquireInstancePoolMember(name string) (*api.InstancePoolMember, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	member := api.InstancePoolMember{}
+
+	// Send the request.
+	_, err = r.queryStruct("POST", fmt.Sprintf("/instance-pools/%s/acquire", url.PathEscape(name)), nil, "", &member)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// ReleaseInstancePoolMember gives an acquired instance back to the instance pool.
+func (r *ProtocolIncus) ReleaseInstancePoolMember(name string, release api.InstancePoolReleasePost) (Operation, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request.
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/instance-pools/%s/release", url.PathEscape(name)), release, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
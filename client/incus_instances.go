@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -201,6 +202,21 @@ func (r *ProtocolIncus) UpdateInstances(state api.InstancesPut, ETag string) (Op
 	return op, nil
 }
 
+// CreateInstancesBatch runs a batch of actions on instances.
+func (r *ProtocolIncus) CreateInstancesBatch(batch api.InstancesBatchPost) (Operation, error) {
+	if !r.HasExtension("instances_batch") {
+		return nil, fmt.Errorf("The server is missing the required \"instances_batch\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", "/instances/batch", batch, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // rebuildInstance initiates a rebuild of a given instance on the Incus Protocol server and returns the corresponding operation or an error.
 func (r *ProtocolIncus) rebuildInstance(instanceName string, instance api.InstanceRebuildPost) (Operation, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -327,6 +343,27 @@ func (r *ProtocolIncus) RebuildInstance(instanceName string, instance api.Instan
 	return r.rebuildInstance(instanceName, instance)
 }
 
+// ConvertInstance converts a container to a virtual machine or a virtual machine to a container.
+func (r *ProtocolIncus) ConvertInstance(instanceName string, req api.InstanceConvertPost) (Operation, error) {
+	err := r.CheckExtension("instance_convert")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/convert", path, url.PathEscape(instanceName)), req, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // GetInstancesFull returns a list of instances including snapshots, backups and state.
 func (r *ProtocolIncus) GetInstancesFull(instanceType api.InstanceType) ([]api.InstanceFull, error) {
 	instances := []api.InstanceFull{}
@@ -443,6 +480,38 @@ func (r *ProtocolIncus) GetInstancesFullAllProjectsWithFilter(instanceType api.I
 	return instances, nil
 }
 
+// IterateInstances passes the instances matching the filters to the handler, retrieving them in pages of pageSize instances.
+func (r *ProtocolIncus) IterateInstances(instanceType api.InstanceType, filters []string, pageSize int, handler func(instance api.Instance) error) error {
+	if len(filters) > 0 && !r.HasExtension("api_filtering") {
+		return fmt.Errorf("The server is missing the required \"api_filtering\" API extension")
+	}
+
+	path, typeValues, err := r.instanceTypeToPath(instanceType)
+	if err != nil {
+		return err
+	}
+
+	return iteratePages(r.HasExtension("api_pagination"), pageSize, func(v url.Values) ([]api.Instance, error) {
+		instances := []api.Instance{}
+
+		for key := range typeValues {
+			v.Set(key, typeValues.Get(key))
+		}
+
+		v.Set("recursion", "1")
+		if len(filters) > 0 {
+			v.Set("filter", parseFilters(filters))
+		}
+
+		_, err := r.queryStruct("GET", fmt.Sprintf("%s?%s", path, v.Encode()), nil, "", &instances)
+		if err != nil {
+			return nil, err
+		}
+
+		return instances, nil
+	}, handler)
+}
+
 // GetInstance returns the instance entry for the provided name.
 func (r *ProtocolIncus) GetInstance(name string) (*api.Instance, string, error) {
 	instance := api.Instance{}
@@ -539,6 +608,10 @@ func (r *ProtocolIncus) CreateInstanceFromBackup(args InstanceBackupArgs) (Opera
 		return nil, fmt.Errorf(`The server is missing the required "backup_override_name" API extension`)
 	}
 
+	if args.NetworkState && !r.HasExtension("instance_backup_network_state") {
+		return nil, fmt.Errorf(`The server is missing the required "instance_backup_network_state" API extension`)
+	}
+
 	// Prepare the HTTP request
 	reqURL, err := r.setQueryAttributes(fmt.Sprintf("%s/1.0%s", r.httpBaseURL.String(), path))
 	if err != nil {
@@ -560,6 +633,10 @@ func (r *ProtocolIncus) CreateInstanceFromBackup(args InstanceBackupArgs) (Opera
 		req.Header.Set("X-Incus-name", args.Name)
 	}
 
+	if args.NetworkState {
+		req.Header.Set("X-Incus-network-state", "true")
+	}
+
 	// Send the request
 	resp, err := r.DoHTTP(req)
 	if err != nil {
@@ -788,8 +865,10 @@ func (r *ProtocolIncus) CopyInstance(source InstanceServer, instance api.Instanc
 		return nil, fmt.Errorf("Failed to get destination connection info: %w", err)
 	}
 
-	// Optimization for the local copy case
-	if destInfo.URL == sourceInfo.URL && destInfo.SocketPath == sourceInfo.SocketPath && (!r.IsClustered() || instance.Location == r.clusterTarget || r.HasExtension("cluster_internal_copy")) {
+	// Optimization for the local copy case, also used between members of the same cluster so that
+	// the server can clone the instance when the storage pool is shared.
+	sameServer := destInfo.URL == sourceInfo.URL && destInfo.SocketPath == sourceInfo.SocketPath
+	if (sameServer || r.isSameCluster(source)) && (!r.IsClustered() || instance.Location == r.clusterTarget || r.HasExtension("cluster_internal_copy")) {
 		// Project handling
 		if destInfo.Project != sourceInfo.Project {
 			if !r.HasExtension("container_copy_project") {
@@ -1084,6 +1163,40 @@ func (r *ProtocolIncus) DeleteInstance(name string) (Operation, error) {
 	return op, nil
 }
 
+// ExecInstanceStructured runs a command in a virtual machine through its agent and returns its exit code,
+// captured output and resource usage without using any websocket.
+func (r *ProtocolIncus) ExecInstanceStructured(instanceName string, exec api.InstanceExecPost) (*api.InstanceExecResult, error) {
+	err := r.CheckExtension("instance_exec_structured")
+	if err != nil {
+		return nil, err
+	}
+
+	exec.Structured = true
+
+	var uri string
+
+	if r.IsAgent() {
+		uri = "/exec"
+	} else {
+		path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+		if err != nil {
+			return nil, err
+		}
+
+		uri = fmt.Sprintf("%s/%s/exec", path, url.PathEscape(instanceName))
+	}
+
+	result := api.InstanceExecResult{}
+
+	// Send the request
+	_, err = r.queryStruct("POST", uri, exec, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 // ExecInstance requests that Incus spawns a command inside the instance.
 func (r *ProtocolIncus) ExecInstance(instanceName string, exec api.InstanceExecPost, args *InstanceExecArgs) (Operation, error) {
 	// Ensure args are equivalent to empty InstanceExecArgs.
@@ -1787,8 +1900,10 @@ func (r *ProtocolIncus) CopyInstanceSnapshot(source InstanceServer, instanceName
 		return nil, fmt.Errorf("Failed to get instance info: %w", err)
 	}
 
-	// Optimization for the local copy case
-	if destInfo.URL == sourceInfo.URL && destInfo.SocketPath == sourceInfo.SocketPath && (!r.IsClustered() || instance.Location == r.clusterTarget || r.HasExtension("cluster_internal_copy")) {
+	// Optimization for the local copy case, also used between members of the same cluster so that
+	// the server can clone the instance when the storage pool is shared.
+	sameServer := destInfo.URL == sourceInfo.URL && destInfo.SocketPath == sourceInfo.SocketPath
+	if (sameServer || r.isSameCluster(source)) && (!r.IsClustered() || instance.Location == r.clusterTarget || r.HasExtension("cluster_internal_copy")) {
 		// Project handling
 		if destInfo.Project != sourceInfo.Project {
 			if !r.HasExtension("container_copy_project") {
@@ -2104,6 +2219,53 @@ func (r *ProtocolIncus) GetInstanceState(name string) (*api.InstanceState, strin
 	return &state, etag, nil
 }
 
+// GetInstanceStateStream returns a websocket receiving the resource usage of the instance at every interval.
+func (r *ProtocolIncus) GetInstanceStateStream(name string, interval time.Duration) (*websocket.Conn, error) {
+	err := r.CheckExtension("instance_state_stream")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	uri, err := r.setQueryAttributes(fmt.Sprintf("%s/%s/state?stream=1&interval=%d", path, url.PathEscape(name), int(interval.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.websocket(uri)
+}
+
+// GetInstancesStateStream returns a websocket receiving the resource usage of the instances at every interval.
+func (r *ProtocolIncus) GetInstancesStateStream(allProjects bool, interval time.Duration) (*websocket.Conn, error) {
+	err := r.CheckExtension("instance_state_stream")
+	if err != nil {
+		return nil, err
+	}
+
+	path, v, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Set("stream", "1")
+	v.Set("interval", fmt.Sprintf("%d", int(interval.Seconds())))
+
+	if allProjects {
+		v.Set("all-projects", "true")
+	}
+
+	uri, err := r.setQueryAttributes(fmt.Sprintf("%s?%s", path, v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.websocket(uri)
+}
+
 // UpdateInstanceState updates the instance to match the requested state.
 func (r *ProtocolIncus) UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (Operation, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2194,6 +2356,268 @@ func (r *ProtocolIncus) DeleteInstanceLogfile(name string, filename string) erro
 	return nil
 }
 
+// GetInstanceCoreDumps returns a list of core dumps for the instance.
+func (r *ProtocolIncus) GetInstanceCoreDumps(name string) ([]api.InstanceCoreDump, error) {
+	err := r.CheckExtension("instance_coredumps")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw value
+	dumps := []api.InstanceCoreDump{}
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/coredumps?recursion=1", path, url.PathEscape(name)), nil, "", &dumps)
+	if err != nil {
+		return nil, err
+	}
+
+	return dumps, nil
+}
+
+// GetInstanceCoreDumpFile returns the content of the requested core dump.
+//
+// Note that it's the caller's responsibility to close the returned ReadCloser.
+func (r *ProtocolIncus) GetInstanceCoreDumpFile(name string, filename string) (io.ReadCloser, int64, error) {
+	err := r.CheckExtension("instance_coredumps")
+	if err != nil {
+		return nil, -1, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	// Prepare the HTTP request
+	url := fmt.Sprintf("%s/1.0%s/%s/coredumps/%s", r.httpBaseURL.String(), path, url.PathEscape(name), url.PathEscape(filename))
+
+	url, err = r.setQueryAttributes(url)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	// Send the request
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := incusParseResponse(resp)
+		if err != nil {
+			return nil, -1, err
+		}
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+// DeleteInstanceCoreDump deletes the requested core dump.
+func (r *ProtocolIncus) DeleteInstanceCoreDump(name string, filename string) error {
+	err := r.CheckExtension("instance_coredumps")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("DELETE", fmt.Sprintf("%s/%s/coredumps/%s", path, url.PathEscape(name), url.PathEscape(filename)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetInstanceBootLog returns the diagnostics collected about the last start attempt of the instance.
+func (r *ProtocolIncus) GetInstanceBootLog(name string, args *InstanceBootLogArgs) (*api.InstanceBootLog, error) {
+	err := r.CheckExtension("instance_bootlog")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := fmt.Sprintf("%s/%s/bootlog", path, url.PathEscape(name))
+	if args != nil && !args.Since.IsZero() {
+		uri += fmt.Sprintf("?since=%s", url.QueryEscape(args.Since.UTC().Format(time.RFC3339)))
+	}
+
+	// Fetch the raw value
+	bootlog := api.InstanceBootLog{}
+	_, err = r.queryStruct("GET", uri, nil, "", &bootlog)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bootlog, nil
+}
+
+// GetInstanceRefreshes returns the scheduled refreshes of the instances.
+func (r *ProtocolIncus) GetInstanceRefreshes() ([]api.InstanceRefresh, error) {
+	err := r.CheckExtension("instance_refresh_schedule")
+	if err != nil {
+		return nil, err
+	}
+
+	refreshes := []api.InstanceRefresh{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/instance-refreshes?recursion=1", nil, "", &refreshes)
+	if err != nil {
+		return nil, err
+	}
+
+	return refreshes, nil
+}
+
+// GetInstanceRefresh returns the scheduled refresh of the instance.
+func (r *ProtocolIncus) GetInstanceRefresh(name string) (*api.InstanceRefresh, error) {
+	err := r.CheckExtension("instance_refresh_schedule")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw value.
+	refresh := api.InstanceRefresh{}
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/refresh", path, url.PathEscape(name)), nil, "", &refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &refresh, nil
+}
+
+// GetInstanceAppArmor returns the AppArmor profiles defined in raw.apparmor.profiles and whether they're loaded.
+func (r *ProtocolIncus) GetInstanceAppArmor(name string) (*api.InstanceAppArmor, error) {
+	err := r.CheckExtension("instance_apparmor_profiles")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw value
+	apparmor := api.InstanceAppArmor{}
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/apparmor", path, url.PathEscape(name)), nil, "", &apparmor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apparmor, nil
+}
+
+// ReloadInstanceAppArmor loads the AppArmor profiles defined in raw.apparmor.profiles again.
+func (r *ProtocolIncus) ReloadInstanceAppArmor(name string) error {
+	err := r.CheckExtension("instance_apparmor_profiles")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("POST", fmt.Sprintf("%s/%s/apparmor", path, url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetInstanceShares returns the unexpired shares of the instance.
+func (r *ProtocolIncus) GetInstanceShares(name string) ([]api.InstanceShare, error) {
+	err := r.CheckExtension("instance_shares")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw value
+	shares := []api.InstanceShare{}
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/shares", path, url.PathEscape(name)), nil, "", &shares)
+	if err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// CreateInstanceShare creates a time-limited share of the instance, the result holds the token to use it.
+func (r *ProtocolIncus) CreateInstanceShare(name string, share api.InstanceSharesPost) (*api.InstanceShare, error) {
+	err := r.CheckExtension("instance_shares")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	result := api.InstanceShare{}
+	_, err = r.queryStruct("POST", fmt.Sprintf("%s/%s/shares", path, url.PathEscape(name)), share, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// DeleteInstanceShare revokes the share of the instance with the given ID.
+func (r *ProtocolIncus) DeleteInstanceShare(name string, id string) error {
+	err := r.CheckExtension("instance_shares")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("DELETE", fmt.Sprintf("%s/%s/shares/%s", path, url.PathEscape(name), url.PathEscape(id)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // getInstanceExecOutputLogFile returns the content of the requested exec logfile.
 //
 // Note that it's the caller's responsibility to close the returned ReadCloser.
@@ -2728,6 +3152,10 @@ func (r *ProtocolIncus) CreateInstanceBackup(instanceName string, backup api.Ins
 		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
 	}
 
+	if backup.NetworkState && !r.HasExtension("instance_backup_network_state") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_backup_network_state\" API extension")
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/backups", path, url.PathEscape(instanceName)), backup, "")
 	if err != nil {
@@ -3,6 +3,7 @@ package incus
 import (
 	"fmt"
 	"net/url"
+	"sort"
 
 	"github.com/gorilla/websocket"
 
@@ -42,6 +43,44 @@ func (r *ProtocolIncus) GetOperations() ([]api.Operation, error) {
 	return operations, nil
 }
 
+// IterateOperations passes the operations matching the filters to the handler, by creation date, retrieving them in pages of pageSize operations.
+func (r *ProtocolIncus) IterateOperations(filters []string, pageSize int, handler func(operation api.Operation) error) error {
+	paginated := r.HasExtension("api_pagination")
+	if len(filters) > 0 && !paginated {
+		return fmt.Errorf("The server is missing the required \"api_pagination\" API extension")
+	}
+
+	return iteratePages(paginated, pageSize, func(v url.Values) ([]api.Operation, error) {
+		apiOperations := map[string][]api.Operation{}
+
+		v.Set("recursion", "1")
+		if len(filters) > 0 {
+			v.Set("filter", parseFilters(filters))
+		}
+
+		_, err := r.queryStruct("GET", fmt.Sprintf("/operations?%s", v.Encode()), nil, "", &apiOperations)
+		if err != nil {
+			return nil, err
+		}
+
+		// The operations of a page are grouped by status, restore the order of the page.
+		operations := []api.Operation{}
+		for _, v := range apiOperations {
+			operations = append(operations, v...)
+		}
+
+		sort.SliceStable(operations, func(i, j int) bool {
+			if !operations[i].CreatedAt.Equal(operations[j].CreatedAt) {
+				return operations[i].CreatedAt.Before(operations[j].CreatedAt)
+			}
+
+			return operations[i].ID < operations[j].ID
+		})
+
+		return operations, nil
+	}, handler)
+}
+
 // GetOperationsAllProjects returns a list of operations from all projects.
 func (r *ProtocolIncus) GetOperationsAllProjects() ([]api.Operation, error) {
 	err := r.CheckExtension("operations_get_query_all_projects")
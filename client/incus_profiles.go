@@ -87,6 +87,24 @@ func (r *ProtocolIncus) UpdateProfile(name string, profile api.ProfilePut, ETag
 	return nil
 }
 
+// PreviewProfileUpdate returns the effect updating the profile would have on the instances using it.
+func (r *ProtocolIncus) PreviewProfileUpdate(name string, profile api.ProfilePut) (*api.ProfilePreview, error) {
+	err := r.CheckExtension("profile_preview")
+	if err != nil {
+		return nil, err
+	}
+
+	preview := api.ProfilePreview{}
+
+	// Send the request
+	_, err = r.queryStruct("POST", fmt.Sprintf("/profiles/%s/preview", url.PathEscape(name)), profile, "", &preview)
+	if err != nil {
+		return nil, err
+	}
+
+	return &preview, nil
+}
+
 // RenameProfile renames an existing profile entry.
 func (r *ProtocolIncus) RenameProfile(name string, profile api.ProfilePost) error {
 	// Send the request
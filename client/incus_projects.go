@@ -78,6 +78,23 @@ func (r *ProtocolIncus) GetProjectState(name string) (*api.ProjectState, error)
 	return &projectState, nil
 }
 
+// GetProjectIdleInstances returns the instances of the project which were detected as idle.
+func (r *ProtocolIncus) GetProjectIdleInstances(name string) ([]api.ProjectIdleInstance, error) {
+	if !r.HasExtension("instances_idle_detection") {
+		return nil, fmt.Errorf("The server is missing the required \"instances_idle_detection\" API extension")
+	}
+
+	idleInstances := []api.ProjectIdleInstance{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/projects/%s/idle-instances", url.PathEscape(name)), nil, "", &idleInstances)
+	if err != nil {
+		return nil, err
+	}
+
+	return idleInstances, nil
+}
+
 // CreateProject defines a new project.
 func (r *ProtocolIncus) CreateProject(project api.ProjectsPost) error {
 	if !r.HasExtension("projects") {
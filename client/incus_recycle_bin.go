@@ -0,0 +1,75 @@
+package incus
+
+import (
+	"fmt"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// GetRecycleBinEntries returns the entries of the recycle bin.
+func (r *ProtocolIncus) GetRecycleBinEntries() ([]api.RecycleBinEntry, error) {
+	err := r.CheckExtension("recycle_bin")
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []api.RecycleBinEntry{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/recycle-bin?recursion=1", nil, "", &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetRecycleBinEntry returns the recycle bin entry with the provided ID.
+func (r *ProtocolIncus) GetRecycleBinEntry(id int64) (*api.RecycleBinEntry, string, error) {
+	err := r.CheckExtension("recycle_bin")
+	if err != nil {
+		return nil, "", err
+	}
+
+	entry := api.RecycleBinEntry{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/recycle-bin/%d", id), nil, "", &entry)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &entry, etag, nil
+}
+
+// RestoreRecycleBinEntry restores the deleted instance or custom volume held by the recycle bin entry.
+func (r *ProtocolIncus) RestoreRecycleBinEntry(id int64, entry api.RecycleBinEntryPost) (Operation, error) {
+	err := r.CheckExtension("recycle_bin")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request.
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/recycle-bin/%d", id), entry, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// DeleteRecycleBinEntry permanently deletes the instance or custom volume held by the recycle bin entry.
+func (r *ProtocolIncus) DeleteRecycleBinEntry(id int64) error {
+	err := r.CheckExtension("recycle_bin")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/recycle-bin/%d", id), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
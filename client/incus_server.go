@@ -81,6 +81,27 @@ func (r *ProtocolIncus) IsClustered() bool {
 	return r.server.Environment.ServerClustered
 }
 
+// isSameCluster returns true if the given server is a member of the same Incus cluster, reached with the same
+// credentials. The destination copies the instance on its own, using the credentials it was reached with, so the
+// source credentials must be the same ones for the copy to be allowed exactly as if it was done through the source.
+func (r *ProtocolIncus) isSameCluster(server InstanceServer) bool {
+	if r.server == nil || !r.IsClustered() {
+		return false
+	}
+
+	info, _, err := server.GetServer()
+	if err != nil || !info.Environment.ServerClustered {
+		return false
+	}
+
+	// Cluster members share the cluster certificate.
+	if info.Environment.CertificateFingerprint == "" || info.Environment.CertificateFingerprint != r.server.Environment.CertificateFingerprint {
+		return false
+	}
+
+	return info.Auth == "trusted" && info.AuthUserName != "" && info.AuthUserName == r.server.AuthUserName && info.AuthUserMethod == r.server.AuthUserMethod
+}
+
 // GetServerResources returns the resources available to a given Incus server.
 func (r *ProtocolIncus) GetServerResources() (*api.Resources, error) {
 	if !r.HasExtension("resources") {
@@ -98,6 +119,24 @@ func (r *ProtocolIncus) GetServerResources() (*api.Resources, error) {
 	return &resources, nil
 }
 
+// GetServerIdmapResources returns the user and group ID ranges of the server and their use by the containers.
+func (r *ProtocolIncus) GetServerIdmapResources() (*api.ResourcesIdmap, error) {
+	err := r.CheckExtension("resources_idmap")
+	if err != nil {
+		return nil, err
+	}
+
+	resources := api.ResourcesIdmap{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", "/resources/idmap", nil, "", &resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resources, nil
+}
+
 // UseProject returns a client that will use a specific project.
 func (r *ProtocolIncus) UseProject(name string) InstanceServer {
 	return &ProtocolIncus{
@@ -149,6 +188,22 @@ func (r *ProtocolIncus) IsAgent() bool {
 	return r.server != nil && r.server.Environment.Server == "incus-agent"
 }
 
+// GetCatalog returns the public image aliases and the instance size presets of the server.
+func (r *ProtocolIncus) GetCatalog() (*api.Catalog, error) {
+	if !r.HasExtension("catalog") {
+		return nil, fmt.Errorf("The server is missing the required \"catalog\" API extension")
+	}
+
+	catalog := api.Catalog{}
+
+	_, err := r.queryStruct("GET", "/catalog", nil, "", &catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	return &catalog, nil
+}
+
 // GetMetrics returns the text OpenMetrics data.
 func (r *ProtocolIncus) GetMetrics() (string, error) {
 	// Check that the server supports it.
@@ -188,6 +243,22 @@ func (r *ProtocolIncus) GetMetrics() (string, error) {
 	return string(content), nil
 }
 
+// RunQuery runs a query statement against the server and returns the selected fields of the matching objects.
+func (r *ProtocolIncus) RunQuery(statement string) (*api.QueryResult, error) {
+	if !r.HasExtension("query_list") {
+		return nil, fmt.Errorf("The server is missing the required \"query_list\" API extension")
+	}
+
+	result := api.QueryResult{}
+
+	_, err := r.queryStruct("POST", "/query", api.QueryPost{Query: statement}, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 // ApplyServerPreseed configures a target Incus server with the provided server and cluster configuration.
 func (r *ProtocolIncus) ApplyServerPreseed(config api.InitPreseed) error {
 	// Apply server configuration.
@@ -0,0 +1,59 @@
+package incus
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestIsSameCluster(t *testing.T) {
+	cluster := api.Server{
+		ServerUntrusted: api.ServerUntrusted{Auth: "trusted"},
+		AuthUserName:    "0123456789abcdef",
+		AuthUserMethod:  "tls",
+		Environment:     api.ServerEnvironment{ServerClustered: true, CertificateFingerprint: "cluster"},
+	}
+
+	tests := []struct {
+		name   string
+		source func(server *api.Server)
+		result bool
+	}{
+		{"Same cluster and credentials", func(server *api.Server) {}, true},
+		{"Standalone server", func(server *api.Server) { server.Environment.ServerClustered = false }, false},
+		{"Other cluster", func(server *api.Server) { server.Environment.CertificateFingerprint = "other" }, false},
+		{"Other user", func(server *api.Server) { server.AuthUserName = "fedcba9876543210" }, false},
+		{"Other authentication method", func(server *api.Server) { server.AuthUserMethod = "oidc" }, false},
+		{"Untrusted", func(server *api.Server) { server.Auth = "untrusted" }, false},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		source := cluster
+		tt.source(&source)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			metadata, err := json.Marshal(source)
+			require.NoError(t, err)
+
+			_ = json.NewEncoder(w).Encode(api.Response{Type: api.SyncResponse, Status: "Success", StatusCode: http.StatusOK, Metadata: metadata})
+		}))
+
+		baseURL, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+
+		destination := &ProtocolIncus{server: &cluster}
+		require.Equal(t, tt.result, destination.isSameCluster(&ProtocolIncus{ctx: context.Background(), http: srv.Client(), httpBaseURL: *baseURL}))
+
+		srv.Close()
+	}
+}
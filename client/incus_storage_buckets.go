@@ -87,6 +87,25 @@ func (r *ProtocolIncus) GetStoragePoolBucket(poolName string, bucketName string)
 	return &bucket, etag, nil
 }
 
+// GetStoragePoolBucketState returns the space used by a storage bucket.
+func (r *ProtocolIncus) GetStoragePoolBucketState(poolName string, bucketName string) (*api.StorageBucketState, error) {
+	err := r.CheckExtension("storage_bucket_quota_lifecycle")
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.StorageBucketState{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("storage-pools", poolName, "buckets", bucketName, "state")
+	_, err = r.queryStruct("GET", u.String(), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
 // CreateStoragePoolBucket defines a new storage bucket using the provided struct.
 // If the server supports storage_buckets_create_credentials API extension, then this function will return the
 // initial admin credentials. Otherwise it will be nil.
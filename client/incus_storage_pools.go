@@ -122,3 +122,37 @@ func (r *ProtocolIncus) GetStoragePoolResources(name string) (*api.ResourcesStor
 
 	return &res, nil
 }
+
+// GetStoragePoolMirrorState gets the mirroring state of the storage pool.
+func (r *ProtocolIncus) GetStoragePoolMirrorState(name string) (*api.StoragePoolMirrorState, error) {
+	err := r.CheckExtension("storage_ceph_rbd_mirroring")
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.StoragePoolMirrorState{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/mirror", url.PathEscape(name)), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// UpdateStoragePoolMirror promotes or demotes the mirrored volumes of the storage pool.
+func (r *ProtocolIncus) UpdateStoragePoolMirror(name string, mirror api.StoragePoolMirrorPost) error {
+	err := r.CheckExtension("storage_ceph_rbd_mirroring")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", fmt.Sprintf("/storage-pools/%s/mirror", url.PathEscape(name)), mirror, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
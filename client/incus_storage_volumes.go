@@ -141,6 +141,30 @@ func (r *ProtocolIncus) GetStoragePoolVolumesWithFilter(pool string, filters []s
 	return volumes, nil
 }
 
+// IterateStoragePoolVolumes passes the volumes of the pool matching the filters to the handler, retrieving them in pages of pageSize volumes.
+func (r *ProtocolIncus) IterateStoragePoolVolumes(pool string, filters []string, pageSize int, handler func(volume api.StorageVolume) error) error {
+	err := r.CheckExtension("storage")
+	if err != nil {
+		return err
+	}
+
+	return iteratePages(r.HasExtension("api_pagination"), pageSize, func(v url.Values) ([]api.StorageVolume, error) {
+		volumes := []api.StorageVolume{}
+
+		v.Set("recursion", "1")
+		if len(filters) > 0 {
+			v.Set("filter", parseFilters(filters))
+		}
+
+		_, err := r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/volumes?%s", url.PathEscape(pool), v.Encode()), nil, "", &volumes)
+		if err != nil {
+			return nil, err
+		}
+
+		return volumes, nil
+	}, handler)
+}
+
 // GetStoragePoolVolumesWithFilterAllProjects returns a filtered list of StorageVolume entries for the provided pool for all projects.
 func (r *ProtocolIncus) GetStoragePoolVolumesWithFilterAllProjects(pool string, filters []string) ([]api.StorageVolume, error) {
 	err := r.CheckExtension("storage")
@@ -204,6 +228,37 @@ func (r *ProtocolIncus) GetStoragePoolVolumeState(pool string, volType string, n
 	return &state, nil
 }
 
+// UpdateStoragePoolVolumeMirror promotes or demotes a mirrored storage volume.
+func (r *ProtocolIncus) UpdateStoragePoolVolumeMirror(pool string, volType string, name string, mirror api.StorageVolumeMirrorPost) error {
+	if !r.HasExtension("storage_ceph_rbd_mirroring_volume_role") {
+		return fmt.Errorf("The server is missing the required \"storage_ceph_rbd_mirroring_volume_role\" API extension")
+	}
+
+	// Send the request
+	path := fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/mirror", url.PathEscape(pool), url.PathEscape(volType), url.PathEscape(name))
+	_, _, err := r.query("POST", path, mirror, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TransferStoragePoolVolume sends a custom volume to an external target.
+func (r *ProtocolIncus) TransferStoragePoolVolume(pool string, volName string, transfer api.StorageVolumeTransferPost) (Operation, error) {
+	if !r.HasExtension("storage_volume_transfer") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_volume_transfer\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/custom/%s/transfer", url.PathEscape(pool), url.PathEscape(volName)), transfer, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // CreateStoragePoolVolume defines a new storage volume.
 func (r *ProtocolIncus) CreateStoragePoolVolume(pool string, volume api.StorageVolumesPost) error {
 	if !r.HasExtension("storage") {
@@ -741,6 +796,14 @@ func (r *ProtocolIncus) MoveStoragePoolVolume(pool string, source InstanceServer
 		req.Project = args.Project
 	}
 
+	if args.Live {
+		if !r.HasExtension("storage_volume_live_move") {
+			return nil, fmt.Errorf("The server is missing the required \"storage_volume_live_move\" API extension")
+		}
+
+		req.Live = true
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/%s/%s", url.PathEscape(sourcePool), url.PathEscape(volume.Type), volume.Name), req, "")
 	if err != nil {
@@ -983,6 +1046,13 @@ func (r *ProtocolIncus) CreateStoragePoolVolumeFromISO(pool string, args Storage
 		return nil, err
 	}
 
+	if args.AttachTo != "" {
+		err := r.CheckExtension("storage_volume_import_disk")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	path := fmt.Sprintf("/storage-pools/%s/volumes/custom", url.PathEscape(pool))
 
 	// Prepare the HTTP request.
@@ -1004,6 +1074,74 @@ func (r *ProtocolIncus) CreateStoragePoolVolumeFromISO(pool string, args Storage
 	req.Header.Set("X-Incus-name", args.Name)
 	req.Header.Set("X-Incus-type", "iso")
 
+	if args.AttachTo != "" {
+		req.Header.Set("X-Incus-attach-to", args.AttachTo)
+		req.Header.Set("X-Incus-device", args.DeviceName)
+	}
+
+	// Send the request.
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	// Handle errors.
+	response, _, err := incusParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get to the operation.
+	respOperation, err := response.MetadataAsOperation()
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup an Operation wrapper.
+	op := operation{
+		Operation: *respOperation,
+		r:         r,
+		chActive:  make(chan bool),
+	}
+
+	return &op, nil
+}
+
+// CreateStoragePoolVolumeFromDiskImage creates a block custom volume from a disk image file, such as a qcow2 or VMDK file.
+func (r *ProtocolIncus) CreateStoragePoolVolumeFromDiskImage(pool string, args StoragePoolVolumeBackupArgs) (Operation, error) {
+	err := r.CheckExtension("storage_volume_import_disk")
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/storage-pools/%s/volumes/custom", url.PathEscape(pool))
+
+	// Prepare the HTTP request.
+	reqURL, err := r.setQueryAttributes(fmt.Sprintf("%s/1.0%s", r.httpBaseURL.String(), path))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", reqURL, args.BackupFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Name == "" {
+		return nil, fmt.Errorf("Missing volume name")
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Incus-name", args.Name)
+	req.Header.Set("X-Incus-type", "disk")
+
+	if args.AttachTo != "" {
+		req.Header.Set("X-Incus-attach-to", args.AttachTo)
+		req.Header.Set("X-Incus-device", args.DeviceName)
+	}
+
 	// Send the request.
 	resp, err := r.DoHTTP(req)
 	if err != nil {
@@ -88,3 +88,19 @@ func (r *ProtocolIncus) DeleteWarning(UUID string) error {
 
 	return nil
 }
+
+// ResolveWarning applies the known-safe fix of the provided warning and marks it as resolved.
+func (r *ProtocolIncus) ResolveWarning(UUID string) error {
+	err := r.CheckExtension("warnings_remediation")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("POST", fmt.Sprintf("/warnings/%s/resolve", url.PathEscape(UUID)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -74,11 +75,14 @@ type InstanceServer interface {
 	ImageServer
 
 	// Server functions
+	GetCatalog() (catalog *api.Catalog, err error)
 	GetMetrics() (metrics string, err error)
 	GetServer() (server *api.Server, ETag string, err error)
 	GetServerResources() (resources *api.Resources, err error)
+	GetServerIdmapResources() (resources *api.ResourcesIdmap, err error)
 	UpdateServer(server api.ServerPut, ETag string) (err error)
 	ApplyServerPreseed(config api.InitPreseed) error
+	RunQuery(statement string) (result *api.QueryResult, err error)
 	HasExtension(extension string) (exists bool)
 	RequireAuthenticated(authenticated bool)
 	IsClustered() (clustered bool)
@@ -105,6 +109,7 @@ type InstanceServer interface {
 	GetInstancesFullWithFilter(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
 	GetInstancesAllProjectsWithFilter(instanceType api.InstanceType, filters []string) (instances []api.Instance, err error)
 	GetInstancesFullAllProjectsWithFilter(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
+	IterateInstances(instanceType api.InstanceType, filters []string, pageSize int, handler func(instance api.Instance) error) (err error)
 	GetInstance(name string) (instance *api.Instance, ETag string, err error)
 	GetInstanceFull(name string) (instance *api.InstanceFull, ETag string, err error)
 	CreateInstance(instance api.InstancesPost) (op Operation, err error)
@@ -115,10 +120,13 @@ type InstanceServer interface {
 	MigrateInstance(name string, instance api.InstancePost) (op Operation, err error)
 	DeleteInstance(name string) (op Operation, err error)
 	UpdateInstances(state api.InstancesPut, ETag string) (op Operation, err error)
+	CreateInstancesBatch(batch api.InstancesBatchPost) (op Operation, err error)
 	RebuildInstance(instanceName string, req api.InstanceRebuildPost) (op Operation, err error)
 	RebuildInstanceFromImage(source ImageServer, image api.Image, instanceName string, req api.InstanceRebuildPost) (op RemoteOperation, err error)
+	ConvertInstance(instanceName string, req api.InstanceConvertPost) (op Operation, err error)
 
 	ExecInstance(instanceName string, exec api.InstanceExecPost, args *InstanceExecArgs) (op Operation, err error)
+	ExecInstanceStructured(instanceName string, exec api.InstanceExecPost) (result *api.InstanceExecResult, err error)
 	ConsoleInstance(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (op Operation, err error)
 	ConsoleInstanceDynamic(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (Operation, func(io.ReadWriteCloser) error, error)
 
@@ -152,12 +160,30 @@ type InstanceServer interface {
 	CreateInstanceFromBackup(args InstanceBackupArgs) (op Operation, err error)
 
 	GetInstanceState(name string) (state *api.InstanceState, ETag string, err error)
+	GetInstanceStateStream(name string, interval time.Duration) (conn *websocket.Conn, err error)
+	GetInstancesStateStream(allProjects bool, interval time.Duration) (conn *websocket.Conn, err error)
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
 
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
 	GetInstanceLogfile(name string, filename string) (content io.ReadCloser, err error)
 	DeleteInstanceLogfile(name string, filename string) (err error)
 
+	GetInstanceCoreDumps(name string) (dumps []api.InstanceCoreDump, err error)
+	GetInstanceCoreDumpFile(name string, filename string) (content io.ReadCloser, size int64, err error)
+	DeleteInstanceCoreDump(name string, filename string) (err error)
+
+	GetInstanceBootLog(name string, args *InstanceBootLogArgs) (bootlog *api.InstanceBootLog, err error)
+
+	GetInstanceRefreshes() (refreshes []api.InstanceRefresh, err error)
+	GetInstanceRefresh(name string) (refresh *api.InstanceRefresh, err error)
+
+	GetInstanceAppArmor(name string) (apparmor *api.InstanceAppArmor, err error)
+	ReloadInstanceAppArmor(name string) (err error)
+
+	GetInstanceShares(name string) (shares []api.InstanceShare, err error)
+	CreateInstanceShare(name string, share api.InstanceSharesPost) (result *api.InstanceShare, err error)
+	DeleteInstanceShare(name string, id string) (err error)
+
 	GetInstanceMetadata(name string) (metadata *api.ImageMetadata, ETag string, err error)
 	UpdateInstanceMetadata(name string, metadata api.ImageMetadata, ETag string) (err error)
 
@@ -166,17 +192,43 @@ type InstanceServer interface {
 	CreateInstanceTemplateFile(instanceName string, templateName string, content io.ReadSeeker) (err error)
 	DeleteInstanceTemplateFile(name string, templateName string) (err error)
 
+	// Instance group functions ("instance_groups" API extension)
+	GetInstanceGroupNames() (names []string, err error)
+	GetInstanceGroups() (groups []api.InstanceGroup, err error)
+	GetInstanceGroup(name string) (group *api.InstanceGroup, ETag string, err error)
+	CreateInstanceGroup(group api.InstanceGroupsPost) (err error)
+	UpdateInstanceGroup(name string, group api.InstanceGroupPut, ETag string) (err error)
+	DeleteInstanceGroup(name string) (err error)
+
+	// Instance pool functions ("instance_pools" API extension)
+	GetInstancePoolNames() (names []string, err error)
+	GetInstancePools() (pools []api.InstancePool, err error)
+	GetInstancePool(name string) (pool *api.InstancePool, ETag string, err error)
+	CreateInstancePool(pool api.InstancePoolsPost) (err error)
+	UpdateInstancePool(name string, pool api.InstancePoolPut, ETag string) (err error)
+	DeleteInstancePool(name string) (err error)
+	AcquireInstancePoolMember(name string) (member *api.InstancePoolMember, err error)
+	ReleaseInstancePoolMember(name string, release api.InstancePoolReleasePost) (op Operation, err error)
+
+	// Recycle bin functions ("recycle_bin" API extension)
+	GetRecycleBinEntries() (entries []api.RecycleBinEntry, err error)
+	GetRecycleBinEntry(id int64) (entry *api.RecycleBinEntry, ETag string, err error)
+	RestoreRecycleBinEntry(id int64, entry api.RecycleBinEntryPost) (op Operation, err error)
+	DeleteRecycleBinEntry(id int64) (err error)
+
 	// Event handling functions
 	GetEvents() (listener *EventListener, err error)
 	GetEventsAllProjects() (listener *EventListener, err error)
 	SendEvent(event api.Event) error
 
 	// Image functions
+	IterateImages(filters []string, pageSize int, handler func(image api.Image) error) (err error)
 	CreateImage(image api.ImagesPost, args *ImageCreateArgs) (op Operation, err error)
 	CopyImage(source ImageServer, image api.Image, args *ImageCopyArgs) (op RemoteOperation, err error)
 	UpdateImage(fingerprint string, image api.ImagePut, ETag string) (err error)
 	DeleteImage(fingerprint string) (op Operation, err error)
 	RefreshImage(fingerprint string) (op Operation, err error)
+	OptimizeImage(fingerprint string, image api.ImageOptimizePost) (op Operation, err error)
 	CreateImageSecret(fingerprint string) (op Operation, err error)
 	CreateImageAlias(alias api.ImageAliasesPost) (err error)
 	UpdateImageAlias(name string, alias api.ImageAliasesEntryPut, ETag string) (err error)
@@ -266,6 +318,7 @@ type InstanceServer interface {
 	GetOperationUUIDs() (uuids []string, err error)
 	GetOperations() (operations []api.Operation, err error)
 	GetOperationsAllProjects() (operations []api.Operation, err error)
+	IterateOperations(filters []string, pageSize int, handler func(operation api.Operation) error) (err error)
 	GetOperation(uuid string) (op *api.Operation, ETag string, err error)
 	GetOperationWait(uuid string, timeout int) (op *api.Operation, ETag string, err error)
 	GetOperationWaitSecret(uuid string, secret string, timeout int) (op *api.Operation, ETag string, err error)
@@ -279,6 +332,7 @@ type InstanceServer interface {
 	GetProfile(name string) (profile *api.Profile, ETag string, err error)
 	CreateProfile(profile api.ProfilesPost) (err error)
 	UpdateProfile(name string, profile api.ProfilePut, ETag string) (err error)
+	PreviewProfileUpdate(name string, profile api.ProfilePut) (preview *api.ProfilePreview, err error)
 	RenameProfile(name string, profile api.ProfilePost) (err error)
 	DeleteProfile(name string) (err error)
 
@@ -287,6 +341,7 @@ type InstanceServer interface {
 	GetProjects() (projects []api.Project, err error)
 	GetProject(name string) (project *api.Project, ETag string, err error)
 	GetProjectState(name string) (project *api.ProjectState, err error)
+	GetProjectIdleInstances(name string) (instances []api.ProjectIdleInstance, err error)
 	CreateProject(project api.ProjectsPost) (err error)
 	UpdateProject(name string, project api.ProjectPut, ETag string) (err error)
 	RenameProject(name string, project api.ProjectPost) (op Operation, err error)
@@ -297,6 +352,8 @@ type InstanceServer interface {
 	GetStoragePools() (pools []api.StoragePool, err error)
 	GetStoragePool(name string) (pool *api.StoragePool, ETag string, err error)
 	GetStoragePoolResources(name string) (resources *api.ResourcesStoragePool, err error)
+	GetStoragePoolMirrorState(name string) (state *api.StoragePoolMirrorState, err error)
+	UpdateStoragePoolMirror(name string, mirror api.StoragePoolMirrorPost) (err error)
 	CreateStoragePool(pool api.StoragePoolsPost) (err error)
 	UpdateStoragePool(name string, pool api.StoragePoolPut, ETag string) (err error)
 	DeleteStoragePool(name string) (err error)
@@ -306,6 +363,7 @@ type InstanceServer interface {
 	GetStoragePoolBucketsAllProjects(poolName string) ([]api.StorageBucket, error)
 	GetStoragePoolBuckets(poolName string) ([]api.StorageBucket, error)
 	GetStoragePoolBucket(poolName string, bucketName string) (bucket *api.StorageBucket, ETag string, err error)
+	GetStoragePoolBucketState(poolName string, bucketName string) (state *api.StorageBucketState, err error)
 	CreateStoragePoolBucket(poolName string, bucket api.StorageBucketsPost) (*api.StorageBucketKey, error)
 	UpdateStoragePoolBucket(poolName string, bucketName string, bucket api.StorageBucketPut, ETag string) (err error)
 	DeleteStoragePoolBucket(poolName string, bucketName string) (err error)
@@ -329,8 +387,10 @@ type InstanceServer interface {
 	GetStoragePoolVolumesAllProjects(pool string) (volumes []api.StorageVolume, err error)
 	GetStoragePoolVolumesWithFilter(pool string, filters []string) (volumes []api.StorageVolume, err error)
 	GetStoragePoolVolumesWithFilterAllProjects(pool string, filters []string) (volumes []api.StorageVolume, err error)
+	IterateStoragePoolVolumes(pool string, filters []string, pageSize int, handler func(volume api.StorageVolume) error) (err error)
 	GetStoragePoolVolume(pool string, volType string, name string) (volume *api.StorageVolume, ETag string, err error)
 	GetStoragePoolVolumeState(pool string, volType string, name string) (state *api.StorageVolumeState, err error)
+	UpdateStoragePoolVolumeMirror(pool string, volType string, name string, mirror api.StorageVolumeMirrorPost) (err error)
 	CreateStoragePoolVolume(pool string, volume api.StorageVolumesPost) (err error)
 	UpdateStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePut, ETag string) (err error)
 	DeleteStoragePoolVolume(pool string, volType string, name string) (err error)
@@ -338,6 +398,7 @@ type InstanceServer interface {
 	CopyStoragePoolVolume(pool string, source InstanceServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeCopyArgs) (op RemoteOperation, err error)
 	MoveStoragePoolVolume(pool string, source InstanceServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeMoveArgs) (op RemoteOperation, err error)
 	MigrateStoragePoolVolume(pool string, volume api.StorageVolumePost) (op Operation, err error)
+	TransferStoragePoolVolume(pool string, volName string, transfer api.StorageVolumeTransferPost) (op Operation, err error)
 
 	// Storage volume snapshot functions ("storage_api_volume_snapshots" API extension)
 	CreateStoragePoolVolumeSnapshot(pool string, volumeType string, volumeName string, snapshot api.StorageVolumeSnapshotsPost) (op Operation, err error)
@@ -361,6 +422,9 @@ type InstanceServer interface {
 	// Storage volume ISO import function ("custom_volume_iso" API extension)
 	CreateStoragePoolVolumeFromISO(pool string, args StoragePoolVolumeBackupArgs) (op Operation, err error)
 
+	// Storage volume disk image import function ("storage_volume_import_disk" API extension)
+	CreateStoragePoolVolumeFromDiskImage(pool string, args StoragePoolVolumeBackupArgs) (op Operation, err error)
+
 	// Cluster functions ("cluster" API extensions)
 	GetCluster() (cluster *api.Cluster, ETag string, err error)
 	UpdateCluster(cluster api.ClusterPut, ETag string) (op Operation, err error)
@@ -372,7 +436,9 @@ type InstanceServer interface {
 	RenameClusterMember(name string, member api.ClusterMemberPost) (err error)
 	CreateClusterMember(member api.ClusterMembersPost) (op Operation, err error)
 	UpdateClusterCertificate(certs api.ClusterCertificatePut, ETag string) (err error)
+	RolloutClusterConfig(rollout api.ClusterConfigRolloutPost) (op Operation, err error)
 	GetClusterMemberState(name string) (*api.ClusterMemberState, string, error)
+	GetClusterMemberManagedRules(name string) ([]api.ClusterMemberManagedRule, error)
 	UpdateClusterMemberState(name string, state api.ClusterMemberStatePost) (op Operation, err error)
 	GetClusterGroups() ([]api.ClusterGroup, error)
 	GetClusterGroupNames() ([]string, error)
@@ -388,6 +454,7 @@ type InstanceServer interface {
 	GetWarning(UUID string) (warning *api.Warning, ETag string, err error)
 	UpdateWarning(UUID string, warning api.WarningPut, ETag string) (err error)
 	DeleteWarning(UUID string) (err error)
+	ResolveWarning(UUID string) (err error)
 
 	// Internal functions (for internal use)
 	RawQuery(method string, path string, data any, queryETag string) (resp *api.Response, ETag string, err error)
@@ -462,6 +529,16 @@ type ImageFileRequest struct {
 	// Path retriever for image delta downloads
 	// If set, it must return the path to the image file or an empty string if not available
 	DeltaSourceRetriever func(fingerprint string, file string) string
+
+	// Whether to resume from the existing content of the target files (they must also implement io.Reader)
+	// Only supported by the simplestreams protocol
+	Resume bool
+
+	// Maximum number of times an interrupted transfer is resumed
+	Retries int
+
+	// Retry handler (called before resuming an interrupted transfer)
+	RetryHandler func(attempt int, err error)
 }
 
 // The ImageFileResponse struct is used as the response for image downloads.
@@ -526,6 +603,9 @@ type StoragePoolVolumeMoveArgs struct {
 
 	// API extension: storage_volume_project_move
 	Project string
+
+	// API extension: storage_volume_live_move
+	Live bool
 }
 
 // The StoragePoolVolumeBackupArgs struct is used when creating a storage volume from a backup.
@@ -536,6 +616,14 @@ type StoragePoolVolumeBackupArgs struct {
 
 	// Name to import backup as
 	Name string
+
+	// Instance to attach the imported ISO or disk image to
+	// API extension: storage_volume_import_disk
+	AttachTo string
+
+	// Name of the device to attach the imported volume as (defaults to the volume name)
+	// API extension: storage_volume_import_disk
+	DeviceName string
 }
 
 // The InstanceBackupArgs struct is used when creating a instance from a backup.
@@ -548,6 +636,11 @@ type InstanceBackupArgs struct {
 
 	// Name to import backup as
 	Name string
+
+	// Whether to restore the network dependencies of the instance stored in the backup
+	//
+	// API extension: instance_backup_network_state
+	NetworkState bool
 }
 
 // The InstanceCopyArgs struct is used to pass additional options during instance copy.
@@ -603,6 +696,13 @@ type InstanceConsoleArgs struct {
 type InstanceConsoleLogArgs struct {
 }
 
+// The InstanceBootLogArgs struct is used to pass additional options during an
+// instance boot log request.
+type InstanceBootLogArgs struct {
+	// Only include the kernel log entries from this time on
+	Since time.Time
+}
+
 // The InstanceExecArgs struct is used to pass additional options during instance exec.
 type InstanceExecArgs struct {
 	// Standard input
@@ -99,14 +99,20 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 	resp := ImageFileResponse{}
 
 	// Download function
-	download := func(path string, filename string, hash string, target io.WriteSeeker) (int64, error) {
+	download := func(path string, filename string, hash string, target io.WriteSeeker, existing bool) (int64, error) {
+		resume := &util.DownloadResume{
+			Existing:     existing,
+			Retries:      req.Retries,
+			RetryHandler: req.RetryHandler,
+		}
+
 		// Try over http
 		uri, err := url.JoinPath(fmt.Sprintf("http://%s", strings.TrimPrefix(r.httpHost, "https://")), path)
 		if err != nil {
 			return -1, err
 		}
 
-		size, err := util.DownloadFileHash(context.TODO(), &httpClient, r.httpUserAgent, req.ProgressHandler, req.Canceler, filename, uri, hash, sha256.New(), target)
+		size, err := util.DownloadFileHashResume(context.TODO(), &httpClient, r.httpUserAgent, req.ProgressHandler, req.Canceler, filename, uri, hash, sha256.New(), target, resume)
 		if err != nil {
 			// Handle cancelation
 			if err.Error() == "net/http: request canceled" {
@@ -119,7 +125,7 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 				return -1, err
 			}
 
-			size, err = util.DownloadFileHash(context.TODO(), &httpClient, r.httpUserAgent, req.ProgressHandler, req.Canceler, filename, uri, hash, sha256.New(), target)
+			size, err = util.DownloadFileHashResume(context.TODO(), &httpClient, r.httpUserAgent, req.ProgressHandler, req.Canceler, filename, uri, hash, sha256.New(), target, resume)
 			if err != nil {
 				return -1, err
 			}
@@ -131,7 +137,7 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 	// Download the Incus image file
 	meta, ok := files["meta"]
 	if ok && req.MetaFile != nil {
-		size, err := download(meta.Path, "metadata", meta.Sha256, req.MetaFile)
+		size, err := download(meta.Path, "metadata", meta.Sha256, req.MetaFile, req.Resume)
 		if err != nil {
 			return nil, err
 		}
@@ -171,7 +177,7 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 				defer func() { _ = os.Remove(deltaFile.Name()) }()
 
 				// Download the delta
-				_, err = download(file.Path, "rootfs delta", file.Sha256, deltaFile)
+				_, err = download(file.Path, "rootfs delta", file.Sha256, deltaFile, false)
 				if err != nil {
 					return nil, err
 				}
@@ -207,7 +213,7 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 
 		// Download the whole file
 		if !downloaded {
-			size, err := download(rootfs.Path, "rootfs", rootfs.Sha256, req.RootfsFile)
+			size, err := download(rootfs.Path, "rootfs", rootfs.Sha256, req.RootfsFile, req.Resume)
 			if err != nil {
 				return nil, err
 			}
@@ -284,3 +284,39 @@ func openBrowser(url string) error {
 
 	return nil
 }
+
+// defaultPageSize is the number of entries retrieved per request by the iterator helpers when not specified.
+const defaultPageSize = 250
+
+// iteratePages retrieves consecutive pages of pageSize entries through fetch and passes each entry to the handler.
+// The page is set through the "limit" and "offset" values given to fetch.
+// If the server doesn't support pagination, all the entries are retrieved at once.
+func iteratePages[T any](paginated bool, pageSize int, fetch func(v url.Values) ([]T, error), handler func(entry T) error) error {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	for offset := 0; ; offset += pageSize {
+		v := url.Values{}
+		if paginated {
+			v.Set("limit", fmt.Sprintf("%d", pageSize))
+			v.Set("offset", fmt.Sprintf("%d", offset))
+		}
+
+		entries, err := fetch(v)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			err := handler(entry)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !paginated || len(entries) < pageSize {
+			return nil
+		}
+	}
+}
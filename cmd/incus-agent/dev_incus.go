@@ -217,6 +217,42 @@ var DevIncusDevicesGet = devIncusHandler{"/1.0/devices", func(d *Daemon, w http.
 	return okResponse(devices, "json")
 }}
 
+var DevIncusLeaseHandler = devIncusHandler{"/1.0/leases/{name}", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devIncusResponse {
+	if r.Method != "GET" && r.Method != "PUT" && r.Method != "DELETE" {
+		return &devIncusResponse{fmt.Sprintf("method %q not allowed", r.Method), http.StatusBadRequest, "raw"}
+	}
+
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to host over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	var body any
+	if r.Method == "PUT" {
+		body = r.Body
+	}
+
+	resp, _, err := client.RawQuery(r.Method, r.URL.EscapedPath(), body, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	if r.Method == "DELETE" {
+		return okResponse("", "raw")
+	}
+
+	var lease api.DevIncusLease
+
+	err = resp.MetadataAsStruct(&lease)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed parsing response from host: %w", err))
+	}
+
+	return okResponse(lease, "json")
+}}
+
 var handlers = []devIncusHandler{
 	{"/", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devIncusResponse {
 		return okResponse([]string{"/1.0"}, "json")
@@ -227,6 +263,7 @@ var handlers = []devIncusHandler{
 	DevIncusMetadataGet,
 	devIncusEventsGet,
 	DevIncusDevicesGet,
+	DevIncusLeaseHandler,
 }
 
 func hoistReq(f func(*Daemon, http.ResponseWriter, *http.Request) *devIncusResponse, d *Daemon) func(http.ResponseWriter, *http.Request) {
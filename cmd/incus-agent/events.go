@@ -73,7 +73,7 @@ func eventsSocket(d *Daemon, r *http.Request, w http.ResponseWriter) error {
 	}
 
 	// As we don't know which project we are in, subscribe to events from all projects.
-	listener, err := d.events.AddListener("", true, nil, listenerConnection, strings.Split(typeStr, ","), nil, nil, nil)
+	listener, err := d.events.AddListener("", true, nil, listenerConnection, strings.Split(typeStr, ","), nil, nil, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -142,7 +142,12 @@ func eventsProcess(event api.Event) {
 	// Attempt to perform the mount.
 	mntSource := fmt.Sprintf("incus_%s", e.Name)
 
-	err = tryMountShared(mntSource, e.Config["path"], "virtiofs", nil)
+	var opts []string
+	if e.Config["virtiofs.dax"] != "" {
+		opts = append(opts, "dax=always")
+	}
+
+	err = tryMountShared(mntSource, e.Config["path"], "virtiofs", opts)
 	if err != nil {
 		logger.Infof("Failed to mount hotplug %q (Type: %q) to %q", mntSource, "virtiofs", e.Config["path"])
 		return
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -55,10 +56,14 @@ func execPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
-	if !post.WaitForWS {
+	if !post.WaitForWS && !post.Structured {
 		return response.BadRequest(fmt.Errorf("Websockets are required for VM exec"))
 	}
 
+	if len(post.Command) == 0 {
+		return response.BadRequest(fmt.Errorf("Missing command"))
+	}
+
 	env := map[string]string{}
 
 	if post.Environment != nil {
@@ -106,6 +111,19 @@ func execPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	if post.Structured {
+		if post.Interactive {
+			return response.BadRequest(fmt.Errorf("Cannot use %q in combination with %q", "interactive", "structured"))
+		}
+
+		result, err := execStructured(post, env)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.SyncResponse(true, result)
+	}
+
 	ws := &execWs{}
 	ws.fds = map[int]string{}
 
@@ -150,6 +168,89 @@ func execPost(d *Daemon, r *http.Request) response.Response {
 	return operations.OperationResponse(op)
 }
 
+// execOutputLimit is the maximum amount of output captured from each stream for structured exec requests.
+const execOutputLimit = 8 * 1024 * 1024
+
+// execLimitedBuffer is a buffer which discards any data written past execOutputLimit.
+type execLimitedBuffer struct {
+	bytes.Buffer
+	truncated bool
+}
+
+func (b *execLimitedBuffer) Write(p []byte) (int, error) {
+	remaining := execOutputLimit - b.Len()
+	if len(p) > remaining {
+		b.truncated = true
+		_, _ = b.Buffer.Write(p[:max(remaining, 0)])
+
+		return len(p), nil
+	}
+
+	return b.Buffer.Write(p)
+}
+
+// execStructured runs the command with a detached stdin and returns its exit code, output and resource usage.
+func execStructured(post api.InstanceExecPost, env map[string]string) (*api.InstanceExecResult, error) {
+	cmd := exec.Command(post.Command[0], post.Command[1:]...)
+
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	stdout := &execLimitedBuffer{}
+	stderr := &execLimitedBuffer{}
+
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{
+			Uid: post.User,
+			Gid: post.Group,
+		},
+		Setsid: true,
+	}
+
+	cmd.Dir = post.Cwd
+
+	start := time.Now()
+	err := cmd.Run()
+	duration := time.Since(start)
+
+	exitStatus, err := linux.ExitStatus(err)
+	if err != nil {
+		// The command couldn't be started, report it the same way as a shell would.
+		if errors.Is(err, exec.ErrNotFound) || os.IsNotExist(err) {
+			exitStatus = 127
+		} else if errors.Is(err, fs.ErrPermission) {
+			exitStatus = 126
+		} else {
+			return nil, err
+		}
+
+		_, _ = fmt.Fprintln(stderr, err.Error())
+	}
+
+	result := &api.InstanceExecResult{
+		ExitCode:        exitStatus,
+		Stdout:          stdout.String(),
+		Stderr:          stderr.String(),
+		OutputTruncated: stdout.truncated || stderr.truncated,
+		Duration:        duration.Nanoseconds(),
+	}
+
+	if cmd.ProcessState != nil {
+		rusage, ok := cmd.ProcessState.SysUsage().(*syscall.Rusage)
+		if ok {
+			// Linux reports the peak RSS in KiB.
+			result.MaxRSS = rusage.Maxrss * 1024
+		}
+	}
+
+	logger.Debug("Structured exec finished", logger.Ctx{"command": post.Command, "exitStatus": exitStatus, "duration": duration})
+
+	return result, nil
+}
+
 type execWs struct {
 	command               []string
 	env                   map[string]string
@@ -314,6 +314,7 @@ func tryMountShared(src string, dst string, fstype string, opts []string) error
 	// Prepare the arguments.
 	sharedArgs := []string{}
 	p9Args := []string{}
+	virtiofsArgs := []string{}
 
 	for _, opt := range opts {
 		// transport and msize mount option are specific to 9p.
@@ -322,12 +323,19 @@ func tryMountShared(src string, dst string, fstype string, opts []string) error
 			continue
 		}
 
+		// dax mount option is specific to virtiofs.
+		if opt == "dax" || strings.HasPrefix(opt, "dax=") {
+			virtiofsArgs = append(virtiofsArgs, "-o", opt)
+			continue
+		}
+
 		sharedArgs = append(sharedArgs, "-o", opt)
 	}
 
 	// Always try virtiofs first.
 	args := []string{"-t", "virtiofs", src, dst}
 	args = append(args, sharedArgs...)
+	args = append(args, virtiofsArgs...)
 
 	_, err := subprocess.RunCommand("mount", args...)
 	if err == nil {
@@ -11,6 +11,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/server/metrics"
@@ -376,9 +377,53 @@ func getMemoryMetrics(d *Daemon) (metrics.MemoryMetrics, error) {
 		}
 	}
 
+	out.OOMKills, out.OOMKillTask = getOOMKills()
+
 	return out, nil
 }
 
+// oomKillsLast records the out-of-memory kills last reported, for the kernel log to only be read once there are new
+// ones.
+var oomKillsLast struct {
+	mu    sync.Mutex
+	count uint64
+	task  string
+}
+
+// getOOMKills returns the number of processes killed by the out-of-memory killer and the name of the last one, while
+// it's still in the kernel log.
+func getOOMKills() (uint64, string) {
+	content, err := os.ReadFile("/proc/vmstat")
+	if err != nil {
+		return 0, ""
+	}
+
+	var count uint64
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "oom_kill" {
+			count, _ = strconv.ParseUint(fields[1], 10, 64)
+			break
+		}
+	}
+
+	oomKillsLast.mu.Lock()
+	defer oomKillsLast.mu.Unlock()
+
+	if count == 0 || count == oomKillsLast.count {
+		return count, oomKillsLast.task
+	}
+
+	oomKillsLast.count = count
+
+	kills, err := linux.OOMKills()
+	if err == nil && len(kills) > 0 {
+		oomKillsLast.task = kills[len(kills)-1].Task
+	}
+
+	return count, oomKillsLast.task
+}
+
 func getNetworkMetrics(d *Daemon) ([]metrics.NetworkMetrics, error) {
 	out := []metrics.NetworkMetrics{}
 
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// libvirtDomain is the subset of a libvirt domain XML definition relevant to an import.
+type libvirtDomain struct {
+	Name string `xml:"name"`
+
+	Memory struct {
+		Value uint64 `xml:",chardata"`
+		Unit  string `xml:"unit,attr"`
+	} `xml:"memory"`
+
+	VCPU struct {
+		Value int `xml:",chardata"`
+	} `xml:"vcpu"`
+
+	OS struct {
+		Firmware string `xml:"firmware,attr"`
+
+		Type struct {
+			Arch string `xml:"arch,attr"`
+		} `xml:"type"`
+
+		Loader *struct {
+			Type   string `xml:"type,attr"`
+			Secure string `xml:"secure,attr"`
+		} `xml:"loader"`
+
+		FirmwareFeatures []struct {
+			Name    string `xml:"name,attr"`
+			Enabled string `xml:"enabled,attr"`
+		} `xml:"firmware>feature"`
+	} `xml:"os"`
+
+	Devices struct {
+		Disks      []libvirtDisk      `xml:"disk"`
+		Interfaces []libvirtInterface `xml:"interface"`
+	} `xml:"devices"`
+}
+
+// libvirtDisk is a disk of a libvirt domain.
+type libvirtDisk struct {
+	Type   string `xml:"type,attr"`
+	Device string `xml:"device,attr"`
+
+	Driver struct {
+		Type string `xml:"type,attr"`
+	} `xml:"driver"`
+
+	Source struct {
+		File string `xml:"file,attr"`
+		Dev  string `xml:"dev,attr"`
+	} `xml:"source"`
+
+	Target struct {
+		Dev string `xml:"dev,attr"`
+	} `xml:"target"`
+
+	Boot *struct {
+		Order int `xml:"order,attr"`
+	} `xml:"boot"`
+}
+
+// Path returns the path of the disk on the host.
+func (d libvirtDisk) Path() string {
+	if d.Source.File != "" {
+		return d.Source.File
+	}
+
+	return d.Source.Dev
+}
+
+// libvirtInterface is a network interface of a libvirt domain.
+type libvirtInterface struct {
+	Type string `xml:"type,attr"`
+
+	MAC struct {
+		Address string `xml:"address,attr"`
+	} `xml:"mac"`
+
+	Source struct {
+		Network string `xml:"network,attr"`
+		Bridge  string `xml:"bridge,attr"`
+		Dev     string `xml:"dev,attr"`
+	} `xml:"source"`
+}
+
+// parseLibvirtDomain parses a libvirt domain XML definition.
+func parseLibvirtDomain(data []byte) (*libvirtDomain, error) {
+	domain := libvirtDomain{}
+
+	err := xml.Unmarshal(data, &domain)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing domain XML: %w", err)
+	}
+
+	if domain.Name == "" {
+		return nil, fmt.Errorf("Domain XML doesn't define a name")
+	}
+
+	return &domain, nil
+}
+
+// MemoryBytes returns the memory of the domain in bytes.
+func (d *libvirtDomain) MemoryBytes() (uint64, error) {
+	multipliers := map[string]uint64{
+		"b":     1,
+		"bytes": 1,
+		"kb":    1000,
+		"k":     1024,
+		"kib":   1024,
+		"mb":    1000 * 1000,
+		"m":     1024 * 1024,
+		"mib":   1024 * 1024,
+		"gb":    1000 * 1000 * 1000,
+		"g":     1024 * 1024 * 1024,
+		"gib":   1024 * 1024 * 1024,
+		"tb":    1000 * 1000 * 1000 * 1000,
+		"t":     1024 * 1024 * 1024 * 1024,
+		"tib":   1024 * 1024 * 1024 * 1024,
+	}
+
+	// Libvirt defaults to KiB.
+	unit := strings.ToLower(d.Memory.Unit)
+	if unit == "" {
+		unit = "kib"
+	}
+
+	multiplier, ok := multipliers[unit]
+	if !ok {
+		return 0, fmt.Errorf("Unknown memory unit %q", d.Memory.Unit)
+	}
+
+	return d.Memory.Value * multiplier, nil
+}
+
+// UEFI returns whether the domain boots with UEFI and whether it enables Secure Boot.
+func (d *libvirtDomain) UEFI() (bool, bool) {
+	secureBoot := false
+
+	if d.OS.Firmware == "efi" {
+		for _, feature := range d.OS.FirmwareFeatures {
+			if feature.Name == "secure-boot" && feature.Enabled == "yes" {
+				secureBoot = true
+			}
+		}
+
+		return true, secureBoot
+	}
+
+	// Older definitions point to the firmware directly.
+	if d.OS.Loader != nil && d.OS.Loader.Type == "pflash" {
+		return true, d.OS.Loader.Secure == "yes"
+	}
+
+	return false, false
+}
+
+// DeviceName returns the name of the Incus device for the disk, based on its target in the guest.
+func (d libvirtDisk) DeviceName(index int) string {
+	if d.Target.Dev != "" {
+		return d.Target.Dev
+	}
+
+	return fmt.Sprintf("disk%d", index)
+}
+
+// Disks returns the disk the domain boots from and its other disks.
+// It also returns a description of the storage devices which can't be imported, like CD-ROM drives.
+func (d *libvirtDomain) Disks() (*libvirtDisk, []libvirtDisk, []string, error) {
+	var others []libvirtDisk
+	skipped := []string{}
+
+	disks := []libvirtDisk{}
+	for _, disk := range d.Devices.Disks {
+		if disk.Device != "" && disk.Device != "disk" {
+			if disk.Path() != "" {
+				skipped = append(skipped, fmt.Sprintf("Disk %q of type %q (%s)", disk.Target.Dev, disk.Device, disk.Path()))
+			}
+
+			continue
+		}
+
+		disks = append(disks, disk)
+	}
+
+	if len(disks) == 0 {
+		return nil, nil, nil, fmt.Errorf("Domain %q doesn't have any disk", d.Name)
+	}
+
+	// Use the disk with the lowest boot order, or the first one.
+	bootIndex := 0
+	bootOrder := 0
+	for i, disk := range disks {
+		if disk.Boot != nil && (bootOrder == 0 || disk.Boot.Order < bootOrder) {
+			bootIndex = i
+			bootOrder = disk.Boot.Order
+		}
+	}
+
+	boot := &disks[bootIndex]
+	if boot.Path() == "" {
+		return nil, nil, nil, fmt.Errorf("Boot disk %q of domain %q doesn't have a source", boot.Target.Dev, d.Name)
+	}
+
+	for i, disk := range disks {
+		if i == bootIndex {
+			continue
+		}
+
+		if disk.Path() == "" {
+			skipped = append(skipped, fmt.Sprintf("Disk %q without a source", disk.Target.Dev))
+			continue
+		}
+
+		others = append(others, disk)
+	}
+
+	return boot, others, skipped, nil
+}
+
+// NICs returns the Incus NIC devices equivalent to the interfaces of the domain.
+// Interfaces attached to a libvirt network are attached to the Incus network of the same name, or to network if set.
+// It also returns a description of the interfaces which couldn't be converted.
+func (d *libvirtDomain) NICs(network string) (map[string]map[string]string, []string) {
+	devices := map[string]map[string]string{}
+	skipped := []string{}
+
+	for _, iface := range d.Devices.Interfaces {
+		device := map[string]string{"type": "nic"}
+
+		switch iface.Type {
+		case "network":
+			device["network"] = iface.Source.Network
+			if network != "" {
+				device["network"] = network
+			}
+
+		case "bridge":
+			device["nictype"] = "bridged"
+			device["parent"] = iface.Source.Bridge
+		case "direct":
+			device["nictype"] = "macvlan"
+			device["parent"] = iface.Source.Dev
+		default:
+			skipped = append(skipped, fmt.Sprintf("Interface %q of type %q", iface.MAC.Address, iface.Type))
+			continue
+		}
+
+		// Keep the MAC address so the guest network configuration keeps working.
+		if iface.MAC.Address != "" {
+			device["hwaddr"] = iface.MAC.Address
+		}
+
+		devices["eth"+strconv.Itoa(len(devices))] = device
+	}
+
+	return devices, skipped
+}
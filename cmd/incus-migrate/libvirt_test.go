@@ -0,0 +1,201 @@
+package main
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testLibvirtDomain = `<domain type="kvm">
+  <name>vm1</name>
+  <memory unit="KiB">2097152</memory>
+  <vcpu placement="static">2</vcpu>
+  <os firmware="efi">
+    <type arch="x86_64" machine="pc-q35-8.2">hvm</type>
+    <firmware>
+      <feature enabled="yes" name="secure-boot"/>
+    </firmware>
+  </os>
+  <devices>
+    <disk type="file" device="disk">
+      <driver name="qemu" type="qcow2"/>
+      <source file="/var/lib/libvirt/images/data.qcow2"/>
+      <target dev="vdb" bus="virtio"/>
+    </disk>
+    <disk type="file" device="disk">
+      <driver name="qemu" type="qcow2"/>
+      <source file="/var/lib/libvirt/images/vm1.qcow2"/>
+      <target dev="vda" bus="virtio"/>
+      <boot order="1"/>
+    </disk>
+    <disk type="block" device="disk">
+      <driver name="qemu" type="raw"/>
+      <source dev="/dev/vg0/vm1-logs"/>
+      <target dev="vdc" bus="virtio"/>
+    </disk>
+    <disk type="file" device="cdrom">
+      <source file="/var/lib/libvirt/images/install.iso"/>
+      <target dev="sda" bus="sata"/>
+    </disk>
+    <disk type="file" device="cdrom">
+      <target dev="sdb" bus="sata"/>
+    </disk>
+    <interface type="network">
+      <mac address="52:54:00:00:00:01"/>
+      <source network="default"/>
+    </interface>
+    <interface type="bridge">
+      <mac address="52:54:00:00:00:02"/>
+      <source bridge="br0"/>
+    </interface>
+    <interface type="direct">
+      <mac address="52:54:00:00:00:03"/>
+      <source dev="eno1" mode="bridge"/>
+    </interface>
+    <interface type="user">
+      <mac address="52:54:00:00:00:04"/>
+    </interface>
+  </devices>
+</domain>
+`
+
+func TestParseLibvirtDomain(t *testing.T) {
+	domain, err := parseLibvirtDomain([]byte(testLibvirtDomain))
+	require.NoError(t, err)
+	require.Equal(t, "vm1", domain.Name)
+	require.Equal(t, 2, domain.VCPU.Value)
+	require.Equal(t, "x86_64", domain.OS.Type.Arch)
+
+	memory, err := domain.MemoryBytes()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2*1024*1024*1024), memory)
+
+	uefi, secureBoot := domain.UEFI()
+	require.True(t, uefi)
+	require.True(t, secureBoot)
+
+	_, err = parseLibvirtDomain([]byte("vm1"))
+	require.Error(t, err)
+
+	_, err = parseLibvirtDomain([]byte(`<domain type="kvm"></domain>`))
+	require.Error(t, err)
+}
+
+func TestLibvirtDomainMemoryBytes(t *testing.T) {
+	tests := []struct {
+		value  uint64
+		unit   string
+		result uint64
+		fails  bool
+	}{
+		{1048576, "", 1024 * 1024 * 1024, false},
+		{1024, "MiB", 1024 * 1024 * 1024, false},
+		{2, "G", 2 * 1024 * 1024 * 1024, false},
+		{1, "GB", 1000 * 1000 * 1000, false},
+		{512, "bytes", 512, false},
+		{1, "pages", 0, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %d %s", i, tt.value, tt.unit)
+
+		domain := libvirtDomain{}
+		domain.Memory.Value = tt.value
+		domain.Memory.Unit = tt.unit
+
+		result, err := domain.MemoryBytes()
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.result, result)
+	}
+}
+
+func TestLibvirtDomainUEFI(t *testing.T) {
+	tests := []struct {
+		name       string
+		os         string
+		uefi       bool
+		secureBoot bool
+	}{
+		{"BIOS", `<os><type>hvm</type></os>`, false, false},
+		{"UEFI", `<os firmware="efi"><type>hvm</type></os>`, true, false},
+		{"UEFI with Secure Boot disabled", `<os firmware="efi"><firmware><feature enabled="no" name="secure-boot"/></firmware></os>`, true, false},
+		{"Loader", `<os><loader readonly="yes" type="pflash">/usr/share/OVMF/OVMF_CODE.fd</loader></os>`, true, false},
+		{"Secure loader", `<os><loader readonly="yes" secure="yes" type="pflash">/usr/share/OVMF/OVMF_CODE.secboot.fd</loader></os>`, true, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		domain, err := parseLibvirtDomain([]byte(`<domain><name>vm1</name>` + tt.os + `</domain>`))
+		require.NoError(t, err)
+
+		uefi, secureBoot := domain.UEFI()
+		require.Equal(t, tt.uefi, uefi)
+		require.Equal(t, tt.secureBoot, secureBoot)
+	}
+}
+
+func TestLibvirtDomainDisks(t *testing.T) {
+	domain, err := parseLibvirtDomain([]byte(testLibvirtDomain))
+	require.NoError(t, err)
+
+	// The disk with the boot order is the boot disk, the other disks are imported and the CD-ROM drives skipped.
+	boot, others, skipped, err := domain.Disks()
+	require.NoError(t, err)
+	require.Equal(t, "/var/lib/libvirt/images/vm1.qcow2", boot.Path())
+	require.Equal(t, "qcow2", boot.Driver.Type)
+	require.Len(t, others, 2)
+	require.Equal(t, "/var/lib/libvirt/images/data.qcow2", others[0].Path())
+	require.Equal(t, "vdb", others[0].DeviceName(1))
+	require.Equal(t, "/dev/vg0/vm1-logs", others[1].Path())
+	require.Equal(t, "vdc", others[1].DeviceName(2))
+	require.Equal(t, []string{`Disk "sda" of type "cdrom" (/var/lib/libvirt/images/install.iso)`}, skipped)
+
+	// Without a boot order, the first disk is used.
+	domain, err = parseLibvirtDomain([]byte(`<domain><name>vm1</name><devices><disk><source file="/a.img"/></disk><disk><target dev="vdb"/></disk></devices></domain>`))
+	require.NoError(t, err)
+
+	boot, others, skipped, err = domain.Disks()
+	require.NoError(t, err)
+	require.Equal(t, "/a.img", boot.Path())
+	require.Empty(t, others)
+	require.Equal(t, []string{`Disk "vdb" without a source`}, skipped)
+	require.Equal(t, "disk1", libvirtDisk{}.DeviceName(1))
+
+	// The boot disk must have a source.
+	domain, err = parseLibvirtDomain([]byte(`<domain><name>vm1</name><devices><disk><target dev="vda"/></disk></devices></domain>`))
+	require.NoError(t, err)
+
+	_, _, _, err = domain.Disks()
+	require.Error(t, err)
+
+	// A disk is required.
+	domain, err = parseLibvirtDomain([]byte(`<domain><name>vm1</name><devices><disk device="cdrom"><source file="/install.iso"/></disk></devices></domain>`))
+	require.NoError(t, err)
+
+	_, _, _, err = domain.Disks()
+	require.Error(t, err)
+}
+
+func TestLibvirtDomainNICs(t *testing.T) {
+	domain, err := parseLibvirtDomain([]byte(testLibvirtDomain))
+	require.NoError(t, err)
+
+	nics, skipped := domain.NICs("")
+	require.Equal(t, map[string]map[string]string{
+		"eth0": {"type": "nic", "network": "default", "hwaddr": "52:54:00:00:00:01"},
+		"eth1": {"type": "nic", "nictype": "bridged", "parent": "br0", "hwaddr": "52:54:00:00:00:02"},
+		"eth2": {"type": "nic", "nictype": "macvlan", "parent": "eno1", "hwaddr": "52:54:00:00:00:03"},
+	}, nics)
+	require.Equal(t, []string{`Interface "52:54:00:00:00:04" of type "user"`}, skipped)
+
+	// Interfaces on libvirt networks can be attached to another network.
+	nics, _ = domain.NICs("incusbr0")
+	require.Equal(t, "incusbr0", nics["eth0"]["network"])
+}
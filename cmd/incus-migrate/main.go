@@ -41,6 +41,10 @@ func main() {
 	netcatCmd := cmdNetcat{global: &globalCmd}
 	app.AddCommand(netcatCmd.Command())
 
+	// import-vm sub-command
+	importVMCmd := cmdImportVM{global: &globalCmd}
+	app.AddCommand(importVMCmd.Command())
+
 	// Run the main command and handle errors
 	err := app.Execute()
 	if err != nil {
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/ioprogress"
+	"github.com/lxc/incus/v6/shared/osarch"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+type cmdImportVM struct {
+	global *cmdGlobal
+
+	flagFrom      string
+	flagXML       string
+	flagName      string
+	flagProject   string
+	flagStorage   string
+	flagNetwork   string
+	flagRsyncArgs string
+}
+
+func (c *cmdImportVM) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "import-vm --from libvirt <domain>"
+	cmd.Short = "Import a virtual machine from another hypervisor"
+	cmd.Long = `Description:
+  Import a virtual machine from another hypervisor
+
+  This converts a stopped libvirt domain into an Incus virtual machine.
+
+  The boot disk is converted to a raw image with qemu-img and transferred
+  through the migration API. The other disks are imported as custom block
+  volumes attached to the instance. The number of CPUs, the memory, the
+  firmware type and the network interfaces (including their MAC addresses)
+  are carried over to the new instance.
+`
+	cmd.RunE = c.Run
+	cmd.Flags().StringVar(&c.flagFrom, "from", "", "Hypervisor to import from (libvirt)"+"``")
+	cmd.Flags().StringVar(&c.flagXML, "xml", "", "Read the domain definition from a file instead of virsh"+"``")
+	cmd.Flags().StringVar(&c.flagName, "name", "", "Name of the new instance (defaults to the domain name)"+"``")
+	cmd.Flags().StringVar(&c.flagProject, "project", "", "Project to create the instance in"+"``")
+	cmd.Flags().StringVarP(&c.flagStorage, "storage", "s", "", "Storage pool to create the instance in"+"``")
+	cmd.Flags().StringVar(&c.flagNetwork, "network", "", "Network to attach interfaces using libvirt networks to (defaults to the network of the same name)"+"``")
+	cmd.Flags().StringVar(&c.flagRsyncArgs, "rsync-args", "", "Extra arguments to pass to rsync"+"``")
+
+	return cmd
+}
+
+func (c *cmdImportVM) Run(cmd *cobra.Command, args []string) error {
+	// Help and usage
+	if len(args) != 1 && c.flagXML == "" {
+		_ = cmd.Help()
+		return nil
+	}
+
+	if c.flagFrom != "libvirt" {
+		return fmt.Errorf("Unsupported source hypervisor %q, only libvirt is supported", c.flagFrom)
+	}
+
+	// Quick checks.
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("This tool must be run as root")
+	}
+
+	for _, tool := range []string{"rsync", "qemu-img"} {
+		_, err := exec.LookPath(tool)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Load the domain definition.
+	var data []byte
+	var err error
+
+	if c.flagXML != "" {
+		data, err = os.ReadFile(c.flagXML)
+		if err != nil {
+			return fmt.Errorf("Failed reading domain XML: %w", err)
+		}
+	} else {
+		state, err := exec.Command("virsh", "domstate", args[0]).Output()
+		if err != nil {
+			return fmt.Errorf("Failed getting the state of domain %q: %w", args[0], err)
+		}
+
+		if strings.TrimSpace(string(state)) != "shut off" {
+			return fmt.Errorf("Domain %q must be stopped before being imported", args[0])
+		}
+
+		data, err = exec.Command("virsh", "dumpxml", "--inactive", args[0]).Output()
+		if err != nil {
+			return fmt.Errorf("Failed getting the definition of domain %q: %w", args[0], err)
+		}
+	}
+
+	domain, err := parseLibvirtDomain(data)
+	if err != nil {
+		return err
+	}
+
+	bootDisk, otherDisks, skippedDisks, err := domain.Disks()
+	if err != nil {
+		return err
+	}
+
+	// Server
+	migrate := cmdMigrate{global: c.global}
+	server, clientFingerprint, err := migrate.askServer()
+	if err != nil {
+		return err
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-sigChan
+
+		if clientFingerprint != "" {
+			_ = server.DeleteCertificate(clientFingerprint)
+		}
+
+		cancel()
+		os.Exit(1)
+	}()
+
+	if clientFingerprint != "" {
+		defer func() { _ = server.DeleteCertificate(clientFingerprint) }()
+	}
+
+	if c.flagProject != "" {
+		server = server.UseProject(c.flagProject)
+	}
+
+	// Build the instance definition.
+	req := api.InstancesPost{
+		Name: domain.Name,
+		Type: api.InstanceTypeVM,
+		Source: api.InstanceSource{
+			Type: "migration",
+			Mode: "push",
+		},
+	}
+
+	if c.flagName != "" {
+		req.Name = c.flagName
+	}
+
+	req.Config = map[string]string{}
+	req.Devices = map[string]map[string]string{}
+
+	req.Architecture = domain.OS.Type.Arch
+	if req.Architecture == "" {
+		req.Architecture, err = osarch.ArchitectureGetLocal()
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = osarch.ArchitectureId(req.Architecture)
+	if err != nil {
+		return fmt.Errorf("Unsupported architecture %q: %w", req.Architecture, err)
+	}
+
+	if domain.VCPU.Value > 0 {
+		req.Config["limits.cpu"] = fmt.Sprintf("%d", domain.VCPU.Value)
+	}
+
+	memory, err := domain.MemoryBytes()
+	if err != nil {
+		return err
+	}
+
+	if memory > 0 {
+		req.Config["limits.memory"] = fmt.Sprintf("%dMiB", memory/1024/1024)
+	}
+
+	if slices.Contains([]string{"x86_64", "aarch64"}, req.Architecture) {
+		uefi, secureBoot := domain.UEFI()
+		if !uefi {
+			req.Config["security.csm"] = "true"
+		}
+
+		if !secureBoot {
+			req.Config["security.secureboot"] = "false"
+		}
+	}
+
+	nics, skipped := domain.NICs(c.flagNetwork)
+	for name, device := range nics {
+		req.Devices[name] = device
+	}
+
+	// Check that the networks the interfaces get attached to exist.
+	networks, err := server.GetNetworkNames()
+	if err != nil {
+		return err
+	}
+
+	for name, device := range req.Devices {
+		if device["network"] != "" && !slices.Contains(networks, device["network"]) {
+			skipped = append(skipped, fmt.Sprintf("Interface %q on network %q which doesn't exist", device["hwaddr"], device["network"]))
+			delete(req.Devices, name)
+		}
+	}
+
+	if c.flagStorage != "" {
+		req.Devices["root"] = map[string]string{
+			"type": "disk",
+			"pool": c.flagStorage,
+			"path": "/",
+		}
+	}
+
+	if len(otherDisks) > 0 && !server.HasExtension("storage_volume_import_disk") {
+		return fmt.Errorf("The server doesn't support importing disk images, which is required for the additional disks of domain %q", domain.Name)
+	}
+
+	skipped = append(skipped, skippedDisks...)
+
+	for _, entry := range skipped {
+		fmt.Printf("Skipping %s, add it to the instance manually after the import\n", entry)
+	}
+
+	// Convert the boot disk.
+	path, err := os.MkdirTemp("", "incus-migrate_import_")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.RemoveAll(path) }()
+
+	target := filepath.Join(path, "root.img")
+
+	if bootDisk.Driver.Type == "raw" && bootDisk.Source.File != "" {
+		err = os.Symlink(bootDisk.Source.File, target)
+		if err != nil {
+			return err
+		}
+	} else {
+		format := bootDisk.Driver.Type
+		if format == "" {
+			format = "raw"
+		}
+
+		fmt.Printf("Converting disk %q\n", bootDisk.Path())
+
+		convert := exec.CommandContext(ctx, "qemu-img", "convert", "-p", "-f", format, "-O", "raw", bootDisk.Path(), target)
+		convert.Stdout = os.Stdout
+		convert.Stderr = os.Stderr
+
+		err = convert.Run()
+		if err != nil {
+			return fmt.Errorf("Failed converting disk %q: %w", bootDisk.Path(), err)
+		}
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Import the other disks as custom block volumes attached to the instance.
+	dst := server
+
+	if len(otherDisks) > 0 {
+		pool, err := importVMRootPool(server, c.flagStorage)
+		if err != nil {
+			return err
+		}
+
+		for i, disk := range otherDisks {
+			devName := disk.DeviceName(i + 1)
+			volName := fmt.Sprintf("%s-%s", req.Name, devName)
+
+			err = importVMDisk(dst, pool, volName, disk.Path())
+			if err != nil {
+				return err
+			}
+
+			revert.Add(func() { _ = dst.DeleteStoragePoolVolume(pool, "custom", volName) })
+
+			// Keep everything on the member the first volume got imported to.
+			if i == 0 && server.IsClustered() {
+				vol, _, err := server.GetStoragePoolVolume(pool, "custom", volName)
+				if err != nil {
+					return err
+				}
+
+				if vol.Location != "" && vol.Location != "none" {
+					dst = server.UseTarget(vol.Location)
+				}
+			}
+
+			req.Devices[devName] = map[string]string{
+				"type":   "disk",
+				"pool":   pool,
+				"source": volName,
+			}
+		}
+	}
+
+	// Create the instance
+	op, err := dst.CreateInstance(req)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_, _ = dst.DeleteInstance(req.Name)
+	})
+
+	progress := cli.ProgressRenderer{Format: "Transferring instance: %s"}
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = transferRootfs(ctx, dst, op, path, c.flagRsyncArgs, req.Type)
+	if err != nil {
+		return err
+	}
+
+	progress.Done(fmt.Sprintf("Instance %s successfully created", req.Name))
+	revert.Success()
+
+	return nil
+}
+
+// importVMRootPool returns the storage pool of the root disk of the new instance.
+func importVMRootPool(server incus.InstanceServer, pool string) (string, error) {
+	if pool != "" {
+		return pool, nil
+	}
+
+	profile, _, err := server.GetProfile("default")
+	if err != nil {
+		return "", fmt.Errorf("Failed loading the default profile: %w", err)
+	}
+
+	for _, device := range profile.Devices {
+		if device["type"] == "disk" && device["path"] == "/" && device["pool"] != "" {
+			return device["pool"], nil
+		}
+	}
+
+	return "", fmt.Errorf("No storage pool found for the instance, use --storage to select one")
+}
+
+// importVMDisk imports a disk image, in any format supported by qemu-img, as a custom block volume.
+func importVMDisk(server incus.InstanceServer, pool string, volName string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{Format: fmt.Sprintf("Importing disk %s: %s", path, "%s")}
+
+	reader := &ioprogress.ProgressReader{
+		ReadCloser: file,
+		Tracker: &ioprogress.ProgressTracker{
+			Length: stat.Size(),
+			Handler: func(percent int64, speed int64) {
+				progress.UpdateProgress(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
+			},
+		},
+	}
+
+	op, err := server.CreateStoragePoolVolumeFromDiskImage(pool, incus.StoragePoolVolumeBackupArgs{
+		BackupFile: reader,
+		Name:       volName,
+	})
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		progress.Done("")
+		return fmt.Errorf("Failed importing disk %q: %w", path, err)
+	}
+
+	progress.Done(fmt.Sprintf("Disk %s imported as volume %s", path, volName))
+
+	return nil
+}
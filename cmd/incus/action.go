@@ -144,12 +144,15 @@ func (c *cmdStop) Command() *cobra.Command {
 type cmdAction struct {
 	global *cmdGlobal
 
-	flagAll       bool
-	flagConsole   string
-	flagForce     bool
-	flagStateful  bool
-	flagStateless bool
-	flagTimeout   int
+	flagAll         bool
+	flagAllMatching string
+	flagConsole     string
+	flagForce       bool
+	flagStateful    bool
+	flagStateless   bool
+	flagToDisk      bool
+	flagFromDisk    bool
+	flagTimeout     int
 }
 
 // Command is a method of the cmdAction structure which constructs and configures a cobra Command object.
@@ -160,10 +163,18 @@ func (c *cmdAction) Command(action string) *cobra.Command {
 
 	cmd.Flags().BoolVar(&c.flagAll, "all", false, i18n.G("Run against all instances"))
 
+	if slices.Contains([]string{"start", "stop"}, action) {
+		cmd.Flags().StringVar(&c.flagAllMatching, "all-matching", "", i18n.G("Run against all instances matching the filters (same syntax as \"incus list\")")+"``")
+	}
+
 	if action == "stop" {
 		cmd.Flags().BoolVar(&c.flagStateful, "stateful", false, i18n.G("Store the instance state"))
 	} else if action == "start" {
 		cmd.Flags().BoolVar(&c.flagStateless, "stateless", false, i18n.G("Ignore the instance state"))
+	} else if action == "pause" {
+		cmd.Flags().BoolVar(&c.flagToDisk, "to-disk", false, i18n.G("Checkpoint the instance state to disk and stop it"))
+	} else if action == "resume" {
+		cmd.Flags().BoolVar(&c.flagFromDisk, "from-disk", false, i18n.G("Start the instance from its checkpoint on disk"))
 	}
 
 	if slices.Contains([]string{"start", "restart", "stop"}, action) {
@@ -194,14 +205,21 @@ func (c *cmdAction) doActionAll(action string, resource remoteResource) error {
 	}
 
 	// Pause is called freeze, resume is called unfreeze.
-	if action == "pause" {
+	// When going through disk, they are a stateful stop and start.
+	state := false
+	if action == "pause" && c.flagToDisk {
+		action = "stop"
+		state = true
+	} else if action == "resume" && c.flagFromDisk {
+		action = "start"
+		state = true
+	} else if action == "pause" {
 		action = "freeze"
 	} else if action == "resume" {
 		action = "unfreeze"
 	}
 
 	// Only store state if asked to.
-	state := false
 	if action == "stop" && c.flagStateful {
 		state = true
 	}
@@ -242,11 +260,124 @@ func (c *cmdAction) doActionAll(action string, resource remoteResource) error {
 	return nil
 }
 
+// doActionAllMatching runs the action on all the instances matching the filters of --all-matching.
+// The actions are sent as a single batch to the servers supporting it, the names of the other
+// matching instances are returned for the action to be run on them one by one.
+func (c *cmdAction) doActionAllMatching(action string, args []string) ([]string, error) {
+	// If no server passed, use current default.
+	if len(args) == 0 {
+		args = []string{fmt.Sprintf("%s:", c.global.conf.DefaultRemote)}
+	}
+
+	resources, err := c.global.ParseServers(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := strings.Fields(c.flagAllMatching)
+	list := cmdList{global: c.global}
+
+	var names []string
+	for _, resource := range resources {
+		// We don't allow instance names with --all-matching.
+		if resource.name != "" {
+			return nil, fmt.Errorf(i18n.G("Both --all-matching and instance name given"))
+		}
+
+		instances, err := resource.server.GetInstancesFull(api.InstanceTypeAny)
+		if err != nil {
+			return nil, err
+		}
+
+		batch := api.InstancesBatchPost{}
+		for _, inst := range instances {
+			if !list.shouldShow(filters, &inst.Instance, inst.State, false) {
+				continue
+			}
+
+			switch action {
+			case "start":
+				if inst.StatusCode == api.Running {
+					continue
+				}
+
+			case "stop":
+				if inst.StatusCode == api.Stopped {
+					continue
+				}
+			}
+
+			timeout := c.flagTimeout
+			if timeout < 0 {
+				timeout = 0
+			}
+
+			batch.Actions = append(batch.Actions, api.InstancesBatchAction{
+				Instance: inst.Name,
+				Action:   action,
+				Timeout:  timeout,
+				Force:    c.flagForce,
+				Stateful: (action == "start" && inst.Stateful && !c.flagStateless) || (action == "stop" && c.flagStateful),
+			})
+		}
+
+		if len(batch.Actions) == 0 {
+			continue
+		}
+
+		// Fallback to individual actions.
+		if !resource.server.HasExtension("instances_batch") {
+			for _, batchAction := range batch.Actions {
+				names = append(names, fmt.Sprintf("%s:%s", resource.remote, batchAction.Instance))
+			}
+
+			continue
+		}
+
+		op, err := resource.server.CreateInstancesBatch(batch)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", resource.remote, err)
+		}
+
+		progress := cli.ProgressRenderer{
+			Quiet: c.global.flagQuiet,
+		}
+
+		_, err = op.AddHandler(progress.UpdateOp)
+		if err != nil {
+			progress.Done("")
+			return nil, err
+		}
+
+		err = cli.CancelableWait(op, &progress)
+		if err != nil {
+			progress.Done("")
+			return nil, fmt.Errorf("%s: %w", resource.remote, err)
+		}
+
+		progress.Done("")
+	}
+
+	return names, nil
+}
+
 // doAction is a method of the cmdAction structure. It carries out a specified action on an instance,
 // using a given config and instance name. It manages state changes, flag checks, error handling and console attachment.
 func (c *cmdAction) doAction(action string, conf *config.Config, nameArg string) error {
 	state := false
 
+	// Pausing to disk is a stateful stop
+	if action == "pause" && c.flagToDisk {
+		action = "stop"
+		state = true
+	}
+
+	// Resuming from disk is a stateful start
+	if action == "resume" && c.flagFromDisk {
+		action = "start"
+		state = true
+	}
+
 	// Pause is called freeze
 	if action == "pause" {
 		action = "freeze"
@@ -286,6 +417,10 @@ func (c *cmdAction) doAction(action string, conf *config.Config, nameArg string)
 			return err
 		}
 
+		if c.flagFromDisk && !current.Stateful {
+			return fmt.Errorf(i18n.G("Instance %q doesn't have a checkpoint on disk"), name)
+		}
+
 		// "start" for a frozen instance means "unfreeze"
 		if current.StatusCode == api.Frozen {
 			action = "unfreeze"
@@ -353,7 +488,25 @@ func (c *cmdAction) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
 
 	var names []string
-	if c.flagAll {
+	if c.flagAllMatching != "" {
+		if c.flagAll {
+			return fmt.Errorf(i18n.G("Both --all and --all-matching given"))
+		}
+
+		if c.flagConsole != "" {
+			return fmt.Errorf(i18n.G("--console can't be used with --all-matching"))
+		}
+
+		var err error
+		names, err = c.doActionAllMatching(cmd.Name(), args)
+		if err != nil {
+			return err
+		}
+
+		if len(names) == 0 {
+			return nil
+		}
+	} else if c.flagAll {
 		// If no server passed, use current default.
 		if len(args) == 0 {
 			args = []string{fmt.Sprintf("%s:", conf.DefaultRemote)}
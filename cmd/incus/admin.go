@@ -24,14 +24,30 @@ func (c *cmdAdmin) Command() *cobra.Command {
 	adminClusterCmd := cmdAdminCluster{global: c.global}
 	cmd.AddCommand(adminClusterCmd.Command())
 
+	// db sub-command
+	adminDBCmd := cmdAdminDB{global: c.global}
+	cmd.AddCommand(adminDBCmd.Command())
+
+	// drain sub-command
+	adminDrainCmd := cmdAdminDrain{global: c.global}
+	cmd.AddCommand(adminDrainCmd.Command())
+
 	// init
 	adminInitCmd := cmdAdminInit{global: c.global}
 	cmd.AddCommand(adminInitCmd.Command())
 
+	// log sub-command
+	adminLogCmd := cmdAdminLog{global: c.global}
+	cmd.AddCommand(adminLogCmd.Command())
+
 	// recover sub-command
 	adminRecoverCmd := cmdAdminRecover{global: c.global}
 	cmd.AddCommand(adminRecoverCmd.Command())
 
+	// remap sub-command
+	adminRemapCmd := cmdAdminRemap{global: c.global}
+	cmd.AddCommand(adminRemapCmd.Command())
+
 	// shutdown sub-command
 	shutdownCmd := cmdAdminShutdown{global: c.global}
 	cmd.AddCommand(shutdownCmd.Command())
@@ -40,6 +56,14 @@ func (c *cmdAdmin) Command() *cobra.Command {
 	sqlCmd := cmdAdminSQL{global: c.global}
 	cmd.AddCommand(sqlCmd.Command())
 
+	// support-bundle sub-command
+	adminSupportBundleCmd := cmdAdminSupportBundle{global: c.global}
+	cmd.AddCommand(adminSupportBundleCmd.Command())
+
+	// verify-limits sub-command
+	adminVerifyLimitsCmd := cmdAdminVerifyLimits{global: c.global}
+	cmd.AddCommand(adminVerifyLimitsCmd.Command())
+
 	// waitready sub-command
 	adminWaitreadyCmd := cmdAdminWaitready{global: c.global}
 	cmd.AddCommand(adminWaitreadyCmd.Command())
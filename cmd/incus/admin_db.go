@@ -0,0 +1,285 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	internalSQL "github.com/lxc/incus/v6/internal/sql"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+type cmdAdminDB struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAdminDB) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("db")
+	cmd.Short = i18n.G("Back up and restore the global database")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Back up and restore the global database
+
+  Backups are consistent snapshots of the global database taken while the
+  daemon is running. They are stored on the server the command targets.`))
+
+	// backup sub-command
+	adminDBBackupCmd := cmdAdminDBBackup{global: c.global}
+	cmd.AddCommand(adminDBBackupCmd.Command())
+
+	// list sub-command
+	adminDBListCmd := cmdAdminDBList{global: c.global}
+	cmd.AddCommand(adminDBListCmd.Command())
+
+	// restore sub-command
+	adminDBRestoreCmd := cmdAdminDBRestore{global: c.global}
+	cmd.AddCommand(adminDBRestoreCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+// Backup.
+type cmdAdminDBBackup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAdminDBBackup) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("backup")
+	cmd.Short = i18n.G("Create a backup of the global database")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Create a backup of the global database
+
+  Run this command periodically, for example from cron, to be able to
+  restore the database to a previous point in time.`))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAdminDBBackup) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 0)
+	if exit {
+		return err
+	}
+
+	d, err := incus.ConnectIncusUnix("", &incus.ConnectionArgs{SkipGetServer: true})
+	if err != nil {
+		return err
+	}
+
+	response, _, err := d.RawQuery("POST", "/internal/database/backups", nil, "")
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to create database backup: %w"), err)
+	}
+
+	backup := internalSQL.SQLBackup{}
+	err = json.Unmarshal(response.Metadata, &backup)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to parse backup response: %w"), err)
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Database backup %s created")+"\n", backup.Name)
+	}
+
+	return nil
+}
+
+// List.
+type cmdAdminDBList struct {
+	global *cmdGlobal
+
+	flagFormat string
+}
+
+func (c *cmdAdminDBList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list")
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List the global database backups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List the global database backups`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAdminDBList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 0)
+	if exit {
+		return err
+	}
+
+	d, err := incus.ConnectIncusUnix("", &incus.ConnectionArgs{SkipGetServer: true})
+	if err != nil {
+		return err
+	}
+
+	response, _, err := d.RawQuery("GET", "/internal/database/backups", nil, "")
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to list database backups: %w"), err)
+	}
+
+	backups := []internalSQL.SQLBackup{}
+	err = json.Unmarshal(response.Metadata, &backups)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to parse backup response: %w"), err)
+	}
+
+	data := [][]string{}
+	for _, backup := range backups {
+		data = append(data, []string{
+			backup.Name,
+			backup.CreatedAt.Local().Format(dateLayout),
+			units.GetByteSizeStringIEC(backup.Size, 2),
+		})
+	}
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("CREATED AT"),
+		i18n.G("SIZE"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, backups)
+}
+
+// Restore.
+type cmdAdminDBRestore struct {
+	global *cmdGlobal
+
+	flagDryRun    bool
+	flagForce     bool
+	flagTimestamp string
+}
+
+func (c *cmdAdminDBRestore) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("restore")
+	cmd.Short = i18n.G("Restore the global database from a backup")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Restore the global database from a backup
+
+  The global database is rolled back to the most recent backup created at or
+  before the given timestamp. The rows that would be added and removed are
+  shown with --dry-run.
+
+  All cluster members should be restarted after the restore so that they
+  reload their state from the database.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus admin db restore --timestamp 2024-05-01T12:00:00Z --dry-run
+    Show the changes needed to restore the database as of that time.`))
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the changes the restore would make"))
+	cmd.Flags().BoolVar(&c.flagForce, "force", false, i18n.G("Don't require user confirmation"))
+	cmd.Flags().StringVar(&c.flagTimestamp, "timestamp", "", i18n.G("Point in time to restore the database to (RFC3339 or \"YYYY-MM-DD hh:mm:ss\" local time)")+"``")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAdminDBRestore) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 0)
+	if exit {
+		return err
+	}
+
+	if c.flagTimestamp == "" {
+		return fmt.Errorf(i18n.G("A timestamp must be provided with --timestamp"))
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, c.flagTimestamp)
+	if err != nil {
+		timestamp, err = time.ParseInLocation(time.DateTime, c.flagTimestamp, time.Local)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Invalid timestamp %q"), c.flagTimestamp)
+		}
+	}
+
+	d, err := incus.ConnectIncusUnix("", &incus.ConnectionArgs{SkipGetServer: true})
+	if err != nil {
+		return err
+	}
+
+	restore := func(req internalSQL.SQLRestore) (*internalSQL.SQLRestoreResult, error) {
+		response, _, err := d.RawQuery("POST", "/internal/database/restore", req, "")
+		if err != nil {
+			return nil, err
+		}
+
+		result := internalSQL.SQLRestoreResult{}
+		err = json.Unmarshal(response.Metadata, &result)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("Failed to parse restore response: %w"), err)
+		}
+
+		return &result, nil
+	}
+
+	// Always compute the changes first.
+	result, err := restore(internalSQL.SQLRestore{Timestamp: timestamp, DryRun: true})
+	if err != nil {
+		return err
+	}
+
+	if c.flagDryRun || !c.flagForce {
+		fmt.Printf(i18n.G("Backup: %s (%s)")+"\n", result.Backup.Name, result.Backup.CreatedAt.Local().Format(dateLayout))
+
+		for _, row := range result.Removed {
+			fmt.Printf("- %s\n", row)
+		}
+
+		for _, row := range result.Added {
+			fmt.Printf("+ %s\n", row)
+		}
+
+		fmt.Printf(i18n.G("%d rows added, %d rows removed")+"\n", len(result.Added), len(result.Removed))
+	}
+
+	if c.flagDryRun {
+		return nil
+	}
+
+	if len(result.Added) == 0 && len(result.Removed) == 0 {
+		return nil
+	}
+
+	if !c.flagForce {
+		ok, err := c.global.asker.AskBool(i18n.G("Restoring replaces the whole content of the global database, continue?")+" (yes/no) [default=no]: ", "no")
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return nil
+		}
+	}
+
+	// Restore the backup whose changes were shown, even if newer backups were created meanwhile.
+	result, err = restore(internalSQL.SQLRestore{Name: result.Backup.Name})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		if result.Previous != nil {
+			fmt.Printf(i18n.G("Previous content saved as backup %s")+"\n", result.Previous.Name)
+		}
+
+		fmt.Println(i18n.G("Database restored, restart all cluster members to reload their state"))
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/drain"
+	"github.com/lxc/incus/v6/internal/i18n"
+)
+
+type cmdAdminDrain struct {
+	global *cmdGlobal
+
+	flagCancel     bool
+	flagRetryAfter int
+	flagStatus     bool
+	flagTimeout    int
+}
+
+func (c *cmdAdminDrain) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("drain")
+	cmd.Short = i18n.G("Drain the daemon ahead of maintenance")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Drain the daemon ahead of maintenance
+
+  This will tell a standalone daemon to stop accepting new changes,
+  rejecting them with a 503 error and a Retry-After header, and to wait
+  for the running operations to finish.
+
+  Once drained, the local state is flushed to disk and the daemon can
+  safely be stopped, backed up or upgraded.
+
+  Clustered servers should be evacuated instead.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus admin drain --timeout 600
+    Drain the daemon, waiting for up to 10 minutes for operations to finish.
+
+incus admin drain --status
+    Show whether the daemon is drained.
+
+incus admin drain --cancel
+    Resume normal operation.`))
+	cmd.RunE = c.Run
+	cmd.Flags().IntVarP(&c.flagTimeout, "timeout", "t", 0, i18n.G("Number of seconds to wait for operations to finish")+"``")
+	cmd.Flags().IntVar(&c.flagRetryAfter, "retry-after", 0, i18n.G("Number of seconds clients are told to wait before retrying")+"``")
+	cmd.Flags().BoolVar(&c.flagCancel, "cancel", false, i18n.G("Cancel draining and resume normal operation"))
+	cmd.Flags().BoolVar(&c.flagStatus, "status", false, i18n.G("Show the draining status"))
+
+	return cmd
+}
+
+func (c *cmdAdminDrain) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 0)
+	if exit {
+		return err
+	}
+
+	if c.flagCancel && c.flagStatus {
+		return fmt.Errorf(i18n.G("--cancel and --status can't be used together"))
+	}
+
+	connArgs := &incus.ConnectionArgs{
+		SkipGetServer: true,
+	}
+
+	d, err := incus.ConnectIncusUnix("", connArgs)
+	if err != nil {
+		return err
+	}
+
+	if c.flagCancel {
+		_, _, err = d.RawQuery("DELETE", "/internal/drain", nil, "")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(i18n.G("Draining cancelled, the daemon is accepting changes again"))
+		return nil
+	}
+
+	var status drain.Status
+	if c.flagStatus {
+		resp, _, err := d.RawQuery("GET", "/internal/drain", nil, "")
+		if err != nil {
+			return err
+		}
+
+		err = json.Unmarshal(resp.Metadata, &status)
+		if err != nil {
+			return err
+		}
+	} else {
+		httpClient, err := d.GetHTTPClient()
+		if err != nil {
+			return err
+		}
+
+		// Request draining, this doesn't return until the operations are done so use a large request timeout.
+		httpTransport := httpClient.Transport.(*http.Transport)
+		httpTransport.ResponseHeaderTimeout = 3600 * time.Second
+
+		req := drain.Post{
+			Timeout:    c.flagTimeout,
+			RetryAfter: c.flagRetryAfter,
+		}
+
+		resp, _, err := d.RawQuery("POST", "/internal/drain", req, "")
+		if err != nil {
+			return err
+		}
+
+		err = json.Unmarshal(resp.Metadata, &status)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !status.Draining {
+		fmt.Println(i18n.G("The daemon isn't draining"))
+		return nil
+	}
+
+	if status.Safe {
+		fmt.Println(i18n.G("The daemon is drained and can safely be stopped"))
+		return nil
+	}
+
+	fmt.Printf(i18n.G("The daemon is draining since %s, waiting for %d operations:")+"\n", status.Since.Local().Format(dateLayout), len(status.Operations))
+	for _, op := range status.Operations {
+		fmt.Printf(" - %s\n", op)
+	}
+
+	if status.Tasks > 0 {
+		fmt.Printf(i18n.G("Background tasks still running: %d")+"\n", status.Tasks)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+)
+
+type cmdAdminLog struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAdminLog) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("log")
+	cmd.Short = i18n.G("Manage the daemon logging")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage the daemon logging`))
+
+	// get-level
+	adminLogGetLevelCmd := cmdAdminLogGetLevel{global: c.global}
+	cmd.AddCommand(adminLogGetLevelCmd.Command())
+
+	// set-level
+	adminLogSetLevelCmd := cmdAdminLogSetLevel{global: c.global}
+	cmd.AddCommand(adminLogSetLevelCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// adminLogConnect connects to the local daemon.
+func adminLogConnect() (incus.InstanceServer, error) {
+	connArgs := &incus.ConnectionArgs{
+		SkipGetServer: true,
+	}
+
+	return incus.ConnectIncusUnix("", connArgs)
+}
+
+// adminLogPrintLevels prints the log levels returned by the daemon.
+func adminLogPrintLevels(metadata json.RawMessage) error {
+	levels := map[string]string{}
+	err := json.Unmarshal(metadata, &levels)
+	if err != nil {
+		return err
+	}
+
+	subsystems := make([]string, 0, len(levels))
+	for subsystem := range levels {
+		subsystems = append(subsystems, subsystem)
+	}
+
+	sort.Strings(subsystems)
+
+	for _, subsystem := range subsystems {
+		fmt.Printf("%s=%s\n", subsystem, levels[subsystem])
+	}
+
+	return nil
+}
+
+// Get level.
+type cmdAdminLogGetLevel struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAdminLogGetLevel) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get-level")
+	cmd.Short = i18n.G("Show the log level of the daemon subsystems")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the log level of the daemon subsystems`))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAdminLogGetLevel) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 0)
+	if exit {
+		return err
+	}
+
+	d, err := adminLogConnect()
+	if err != nil {
+		return err
+	}
+
+	resp, _, err := d.RawQuery("GET", "/internal/log-levels", nil, "")
+	if err != nil {
+		return err
+	}
+
+	return adminLogPrintLevels(resp.Metadata)
+}
+
+// Set level.
+type cmdAdminLogSetLevel struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAdminLogSetLevel) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set-level", i18n.G("<subsystem>=<level>..."))
+	cmd.Short = i18n.G("Set the log level of daemon subsystems")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Set the log level of daemon subsystems
+
+  The subsystems are auth, cluster, network, qemu and storage.
+  The levels are error, warning, info and debug, an empty level resetting
+  the subsystem to the level of the daemon.
+
+  The levels are kept until the daemon restarts.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus admin log set-level storage=debug network=info
+    Log the debug messages of the storage subsystem and the informational messages of the network subsystem.
+
+incus admin log set-level storage=
+    Reset the storage subsystem to the log level of the daemon.`))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAdminLogSetLevel) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	levels := map[string]string{}
+	for _, arg := range args {
+		subsystem, level, found := strings.Cut(arg, "=")
+		if !found {
+			return fmt.Errorf(i18n.G("Invalid log level %q, expected <subsystem>=<level>"), arg)
+		}
+
+		levels[subsystem] = level
+	}
+
+	d, err := adminLogConnect()
+	if err != nil {
+		return err
+	}
+
+	resp, _, err := d.RawQuery("PUT", "/internal/log-levels", levels, "")
+	if err != nil {
+		return err
+	}
+
+	return adminLogPrintLevels(resp.Metadata)
+}
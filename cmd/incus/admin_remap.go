@@ -0,0 +1,113 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/internal/remap"
+)
+
+type cmdAdminRemap struct {
+	global *cmdGlobal
+
+	flagToUnprivileged bool
+	flagIsolated       bool
+	flagBase           int64
+	flagSize           int64
+}
+
+func (c *cmdAdminRemap) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remap", i18n.G("<instance>"))
+	cmd.Short = i18n.G("Remap the filesystem of a stopped container")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Remap the filesystem of a stopped container
+
+  This converts a privileged container to unprivileged or changes the ID
+  range it uses, by shifting the ownership of its filesystem to the new ID map.
+
+  The remap is performed in place and its progress is recorded, so running
+  the same command again resumes an interrupted remap.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus admin remap c1 --to-unprivileged
+    Convert the privileged container c1 to unprivileged.
+
+incus admin remap c1 --isolated
+    Give the container c1 its own isolated ID range.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().BoolVar(&c.flagToUnprivileged, "to-unprivileged", false, i18n.G("Convert the container to unprivileged"))
+	cmd.Flags().BoolVar(&c.flagIsolated, "isolated", false, i18n.G("Use an isolated ID range for the container"))
+	cmd.Flags().Int64Var(&c.flagBase, "idmap-base", -1, i18n.G("Host ID the ID range of the container starts at")+"``")
+	cmd.Flags().Int64Var(&c.flagSize, "idmap-size", -1, i18n.G("Size of the ID range of the container")+"``")
+
+	return cmd
+}
+
+func (c *cmdAdminRemap) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	config := map[string]string{}
+
+	if c.flagToUnprivileged {
+		config["security.privileged"] = "false"
+	}
+
+	if c.flagIsolated {
+		config["security.idmap.isolated"] = "true"
+	}
+
+	if c.flagBase >= 0 {
+		config["security.idmap.base"] = strconv.FormatInt(c.flagBase, 10)
+	}
+
+	if c.flagSize >= 0 {
+		config["security.idmap.size"] = strconv.FormatInt(c.flagSize, 10)
+	}
+
+	// Connect to the daemon.
+	d, err := incus.ConnectIncusUnix("", nil)
+	if err != nil {
+		return err
+	}
+
+	req := remap.Post{
+		Project:  c.global.flagProject,
+		Instance: args[0],
+		Config:   config,
+	}
+
+	op, _, err := d.RawOperation("POST", "/internal/instance-remap", req, "")
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{
+		Quiet: c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done(fmt.Sprintf(i18n.G("Container %s remapped"), args[0]))
+
+	return nil
+}
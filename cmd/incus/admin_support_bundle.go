@@ -0,0 +1,124 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+type cmdAdminSupportBundle struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAdminSupportBundle) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("support-bundle", i18n.G("[<path>]"))
+	cmd.Short = i18n.G("Produce a support bundle")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Produce a support bundle
+
+  The support bundle is an archive holding the server configuration, cluster
+  state, instance configurations, recent logs, warnings and resources of the
+  server. The values of configuration keys that may hold secrets, like
+  passwords, tokens, private keys and cloud-init data, are redacted.
+
+  The archive is written to the current directory if no path is provided.`))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAdminSupportBundle) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	d, err := incus.ConnectIncusUnix("", &incus.ConnectionArgs{SkipGetServer: true})
+	if err != nil {
+		return err
+	}
+
+	httpInfo, err := d.GetConnectionInfo()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/internal/support-bundle", httpInfo.URL), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.DoHTTP(req)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to produce support bundle: %w"), err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		response := api.Response{}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		if err != nil || response.Error == "" {
+			return fmt.Errorf(i18n.G("Failed to produce support bundle: %s"), resp.Status)
+		}
+
+		return fmt.Errorf(i18n.G("Failed to produce support bundle: %s"), response.Error)
+	}
+
+	// Figure out the target path.
+	target := ""
+	_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+	if err == nil {
+		target = filepath.Base(params["filename"])
+	}
+
+	if target == "" || target == "." || target == "/" {
+		target = "incus-support.tar.gz"
+	}
+
+	if len(args) > 0 {
+		info, err := os.Stat(args[0])
+		if err == nil && info.IsDir() {
+			target = filepath.Join(args[0], target)
+		} else {
+			target = args[0]
+		}
+	}
+
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	_, err = io.Copy(f, resp.Body)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to write support bundle: %w"), err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to write support bundle: %w"), err)
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Support bundle written to %s")+"\n", target)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/internal/limitcheck"
+)
+
+type cmdAdminVerifyLimits struct {
+	global *cmdGlobal
+
+	flagFormat string
+}
+
+func (c *cmdAdminVerifyLimits) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("verify-limits")
+	cmd.Short = i18n.G("Check that instance limits are applied as configured")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Check that instance limits are applied as configured
+
+  This audits the running instances of the local server, comparing their
+  configured limits with the values actually applied to their cgroups,
+  to QEMU and to the traffic control rules of their network devices.
+
+  Any discrepancy is reported and makes the command fail.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus admin verify-limits
+    Check the limits of all running instances.
+
+incus admin verify-limits --project foo --format yaml
+    Check the limits of the running instances of project foo, reporting in YAML.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdAdminVerifyLimits) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 0)
+	if exit {
+		return err
+	}
+
+	// Connect to the daemon.
+	d, err := incus.ConnectIncusUnix("", nil)
+	if err != nil {
+		return err
+	}
+
+	path := "/internal/verify-limits"
+	if c.global.flagProject != "" {
+		path = fmt.Sprintf("%s?project=%s", path, url.QueryEscape(c.global.flagProject))
+	}
+
+	response, _, err := d.RawQuery("GET", path, nil, "")
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to verify limits: %w"), err)
+	}
+
+	report := limitcheck.Report{}
+	err = json.Unmarshal(response.Metadata, &report)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to parse limits report: %w"), err)
+	}
+
+	for _, entry := range report.Errors {
+		fmt.Fprintf(os.Stderr, i18n.G("Failed to check: %s")+"\n", entry)
+	}
+
+	data := [][]string{}
+	for _, discrepancy := range report.Discrepancies {
+		data = append(data, []string{
+			discrepancy.Project,
+			discrepancy.Instance,
+			discrepancy.Device,
+			discrepancy.Key,
+			discrepancy.Expected,
+			discrepancy.Actual,
+		})
+	}
+
+	sort.Sort(cli.StringList(data))
+
+	if len(data) > 0 || c.flagFormat != cli.TableFormatTable {
+		header := []string{
+			i18n.G("PROJECT"),
+			i18n.G("INSTANCE"),
+			i18n.G("DEVICE"),
+			i18n.G("KEY"),
+			i18n.G("EXPECTED"),
+			i18n.G("ACTUAL"),
+		}
+
+		err = cli.RenderTable(c.flagFormat, header, data, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(report.Discrepancies) > 0 {
+		return fmt.Errorf(i18n.G("Found %d limit discrepancies across %d running instances"), len(report.Discrepancies), report.Instances)
+	}
+
+	if c.flagFormat == cli.TableFormatTable {
+		fmt.Printf(i18n.G("All limits applied as configured on %d running instances")+"\n", report.Instances)
+	}
+
+	return nil
+}
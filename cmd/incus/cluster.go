@@ -99,6 +99,10 @@ func (c *cmdCluster) Command() *cobra.Command {
 	cmdClusterRestore := cmdClusterRestore{global: c.global, cluster: c}
 	cmd.AddCommand(cmdClusterRestore.Command())
 
+	// Roll out configuration
+	cmdClusterRollout := cmdClusterRollout{global: c.global, cluster: c}
+	cmd.AddCommand(cmdClusterRollout.Command())
+
 	clusterGroupCmd := cmdClusterGroup{global: c.global, cluster: c}
 	cmd.AddCommand(clusterGroupCmd.Command())
 
@@ -1503,3 +1507,140 @@ func (c *cmdClusterEvacuateAction) Run(cmd *cobra.Command, args []string) error
 	progress.Done("")
 	return nil
 }
+
+// Roll out cluster-wide configuration.
+type cmdClusterRollout struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+
+	flagCanaries []string
+	flagPeriod   int64
+	flagDryRun   bool
+}
+
+func (c *cmdClusterRollout) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rollout", i18n.G("[<remote>:] <key>=<value>..."))
+	cmd.Short = i18n.G("Roll out cluster-wide configuration through canary members")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Roll out cluster-wide configuration through canary members
+
+The configuration change is first applied to the canary members, without being persisted.
+Once the canary members are verified to still be healthy after the verification period,
+the change is applied to the whole cluster. Otherwise, the canary members are reverted.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus cluster rollout loki.api.url=https://loki.example.net --canary server01
+    Apply the new Loki server to server01, and to the rest of the cluster if server01 is still healthy after 30 seconds.
+
+incus cluster rollout network.ovn.northbound_connection=ssl:10.0.0.1:6641 --dry-run
+    Only validate the change and show the canary member that would be used.`))
+
+	cmd.Flags().StringArrayVar(&c.flagCanaries, "canary", nil, i18n.G("Cluster member to apply the change to first (can be repeated)")+"``")
+	cmd.Flags().Int64Var(&c.flagPeriod, "period", 0, i18n.G("How long to wait before verifying the canary members, in seconds")+"``")
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only validate the change and select the canary members"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	_ = cmd.RegisterFlagCompletionFunc("canary", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return c.global.cmpClusterMembers(toComplete)
+	})
+
+	return cmd
+}
+
+func (c *cmdClusterRollout) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := conf.DefaultRemote
+	if strings.HasSuffix(args[0], ":") && !strings.Contains(args[0], "=") {
+		remote = strings.TrimSuffix(args[0], ":")
+		args = args[1:]
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf(i18n.G("No configuration change provided"))
+	}
+
+	keys, err := getConfig(args...)
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	rollout := api.ClusterConfigRolloutPost{
+		Config:   keys,
+		Canaries: c.flagCanaries,
+		Period:   c.flagPeriod,
+		DryRun:   c.flagDryRun,
+	}
+
+	op, err := d.RolloutClusterConfig(rollout)
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Rolling out configuration: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(func(op api.Operation) {
+		stage, ok := op.Metadata["stage"].(string)
+		if ok {
+			progress.Update(stage)
+		}
+	})
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	if c.flagDryRun {
+		opAPI := op.Get()
+		fmt.Printf(i18n.G("Changed keys: %s")+"\n", strings.Join(opMetadataStrings(opAPI.Metadata["keys"]), ", "))
+		fmt.Printf(i18n.G("Canary members: %s")+"\n", strings.Join(opMetadataStrings(opAPI.Metadata["canaries"]), ", "))
+	}
+
+	return nil
+}
+
+// opMetadataStrings converts a list of strings decoded from operation metadata.
+func opMetadataStrings(value any) []string {
+	values, _ := value.([]any)
+
+	result := make([]string, 0, len(values))
+	for _, entry := range values {
+		str, ok := entry.(string)
+		if ok {
+			result = append(result, str)
+		}
+	}
+
+	return result
+}
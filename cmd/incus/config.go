@@ -59,6 +59,10 @@ func (c *cmdConfig) Command() *cobra.Command {
 	configSetCmd := cmdConfigSet{global: c.global, config: c}
 	cmd.AddCommand(configSetCmd.Command())
 
+	// Share
+	configShareCmd := cmdConfigShare{global: c.global, config: c}
+	cmd.AddCommand(configShareCmd.Command())
+
 	// Show
 	configShowCmd := cmdConfigShow{global: c.global, config: c}
 	cmd.AddCommand(configShowCmd.Command())
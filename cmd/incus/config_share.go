@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+type cmdConfigShare struct {
+	global *cmdGlobal
+	config *cmdConfig
+}
+
+func (c *cmdConfigShare) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("share")
+	cmd.Short = i18n.G("Manage instance shares")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage instance shares
+
+  Shares grant time-limited exec and console access to a single instance.`))
+
+	// Create
+	configShareCreateCmd := cmdConfigShareCreate{global: c.global, config: c.config, configShare: c}
+	cmd.AddCommand(configShareCreateCmd.Command())
+
+	// Delete
+	configShareDeleteCmd := cmdConfigShareDelete{global: c.global, config: c.config, configShare: c}
+	cmd.AddCommand(configShareDeleteCmd.Command())
+
+	// List
+	configShareListCmd := cmdConfigShareList{global: c.global, config: c.config, configShare: c}
+	cmd.AddCommand(configShareListCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Create.
+type cmdConfigShareCreate struct {
+	global      *cmdGlobal
+	config      *cmdConfig
+	configShare *cmdConfigShare
+
+	flagDescription string
+	flagDuration    string
+	flagIdentity    string
+}
+
+func (c *cmdConfigShareCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<instance>"))
+	cmd.Short = i18n.G("Create instance shares")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create instance shares
+
+  The resulting token is to be passed to "incus remote add" by the recipient of the share.
+  Unless restricted to an identity, anyone holding the token can access the instance.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus config share create u1 --duration 8h --description "Vendor support"
+    Share instance u1 for 8 hours
+
+incus config share create u1 --identity 3c3e4c4b9f7f
+    Share instance u1 for an hour with the client whose certificate has the given fingerprint`))
+
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Share description")+"``")
+	cmd.Flags().StringVar(&c.flagDuration, "duration", "1h", i18n.G("How long the share is valid for")+"``")
+	cmd.Flags().StringVar(&c.flagIdentity, "identity", "", i18n.G("Restrict the share to an identity (certificate fingerprint or OIDC user name)")+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdConfigShareCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	duration, err := time.ParseDuration(c.flagDuration)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Invalid duration %q: %w"), c.flagDuration, err)
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing instance name"))
+	}
+
+	// Create the share
+	share, err := resource.server.CreateInstanceShare(resource.name, api.InstanceSharesPost{
+		Description: c.flagDescription,
+		Identity:    c.flagIdentity,
+		ExpiresAt:   time.Now().Add(duration),
+	})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Instance share %s created, expiring at %s")+"\n", share.ID, share.ExpiresAt.Local().Format(dateLayout))
+		fmt.Println(i18n.G("The recipient can access the instance after running: incus remote add <name> <token>"))
+	}
+
+	fmt.Println(share.Token)
+
+	return nil
+}
+
+// Delete.
+type cmdConfigShareDelete struct {
+	global      *cmdGlobal
+	config      *cmdConfig
+	configShare *cmdConfigShare
+}
+
+func (c *cmdConfigShareDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<instance> <share>"))
+	cmd.Aliases = []string{"rm", "revoke"}
+	cmd.Short = i18n.G("Revoke instance shares")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Revoke instance shares`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdConfigShareDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing instance name"))
+	}
+
+	// Revoke the share
+	err = resource.server.DeleteInstanceShare(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Instance share %s revoked")+"\n", args[1])
+	}
+
+	return nil
+}
+
+// List.
+type cmdConfigShareList struct {
+	global      *cmdGlobal
+	config      *cmdConfig
+	configShare *cmdConfigShare
+
+	flagFormat string
+}
+
+func (c *cmdConfigShareList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<instance>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List instance shares")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List instance shares`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdConfigShareList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing instance name"))
+	}
+
+	// List the shares
+	shares, err := resource.server.GetInstanceShares(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Render the table
+	data := [][]string{}
+	for _, share := range shares {
+		data = append(data, []string{
+			share.ID,
+			share.Description,
+			share.Identity,
+			share.CreatedBy,
+			share.ExpiresAt.Local().Format(dateLayout),
+		})
+	}
+
+	header := []string{
+		i18n.G("ID"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("IDENTITY"),
+		i18n.G("CREATED BY"),
+		i18n.G("EXPIRES AT"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, shares)
+}
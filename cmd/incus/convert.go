@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Convert.
+type cmdConvert struct {
+	global *cmdGlobal
+
+	flagTo    string
+	flagImage string
+	flagForce bool
+}
+
+func (c *cmdConvert) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("convert", i18n.G("[<remote>:]<instance> --to vm|container"))
+	cmd.Short = i18n.G("Convert instances to another instance type")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Convert containers to virtual machines and virtual machines to containers
+
+The instance is rebuilt as the new instance type from its root filesystem and the one of its snapshots.
+The kernel, boot loader and agent of virtual machines come from an image of the new instance type, by default the
+same image as the one the instance was created from.
+
+Configuration keys and devices which aren't supported by the new instance type are dropped.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus convert c1 --to vm
+    Convert the container "c1" to a virtual machine.
+
+incus convert v1 --to container --image images:debian/12
+    Convert the virtual machine "v1" to a container, using the container image of Debian 12.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVar(&c.flagTo, "to", "", i18n.G("Instance type to convert to (vm or container)")+"``")
+	cmd.Flags().StringVar(&c.flagImage, "image", "", i18n.G("Image of the new instance type to take the kernel and boot configuration from")+"``")
+	cmd.Flags().BoolVarP(&c.flagForce, "force", "f", false, i18n.G("If the instance is running, stop it and then convert it"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	_ = cmd.RegisterFlagCompletionFunc("to", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"vm", "container"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	_ = cmd.RegisterFlagCompletionFunc("image", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return c.global.cmpImages(toComplete)
+	})
+
+	return cmd
+}
+
+func (c *cmdConvert) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	req := api.InstanceConvertPost{}
+	switch c.flagTo {
+	case "vm", "virtual-machine":
+		req.Type = api.InstanceTypeVM
+	case "container":
+		req.Type = api.InstanceTypeContainer
+	case "":
+		return fmt.Errorf(i18n.G("The instance type to convert to must be specified with --to"))
+	default:
+		return fmt.Errorf(i18n.G("Invalid instance type %q, must be vm or container"), c.flagTo)
+	}
+
+	remote, name, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(name, instance.SnapshotDelimiter) {
+		return fmt.Errorf(i18n.G("Instance snapshots cannot be converted: %s"), name)
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the image of the new instance type.
+	if c.flagImage != "" {
+		iremote, image, err := conf.ParseRemote(c.flagImage)
+		if err != nil {
+			return err
+		}
+
+		iremote, image = guessImage(conf, d, remote, iremote, image)
+		_, imgInfo, err := getImgInfo(d, conf, iremote, remote, image, &req.Source)
+		if err != nil {
+			return err
+		}
+
+		req.Source.Type = "image"
+		if req.Source.Alias == "" {
+			req.Source.Fingerprint = imgInfo.Fingerprint
+		}
+
+		if iremote != remote {
+			imgRemote := conf.Remotes[iremote]
+			if !imgRemote.Public && imgRemote.Protocol != "simplestreams" {
+				return fmt.Errorf(i18n.G("Images from private remotes must first be copied to the server of the instance"))
+			}
+
+			req.Source.Server = imgRemote.Addr
+			req.Source.Protocol = imgRemote.Protocol
+			if req.Source.Protocol == "" {
+				req.Source.Protocol = "incus"
+			}
+		}
+	}
+
+	current, _, err := d.GetInstance(name)
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{
+		Quiet: c.global.flagQuiet,
+	}
+
+	// If the instance is running, stop it first.
+	if c.flagForce && current.StatusCode == api.Running {
+		op, err := d.UpdateInstanceState(name, api.InstanceStatePut{Action: "stop", Force: true}, "")
+		if err != nil {
+			return err
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+	}
+
+	op, err := d.ConvertInstance(name, req)
+	if err != nil {
+		return err
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	// If the instance was stopped, start it back up.
+	if c.flagForce && current.StatusCode == api.Running {
+		op, err := d.UpdateInstanceState(name, api.InstanceStatePut{Action: "start"}, "")
+		if err != nil {
+			return err
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -12,6 +16,7 @@ import (
 	"github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/shared/api"
 	config "github.com/lxc/incus/v6/shared/cliconfig"
+	localtls "github.com/lxc/incus/v6/shared/tls"
 )
 
 type cmdCopy struct {
@@ -29,6 +34,7 @@ type cmdCopy struct {
 	flagTarget            string
 	flagTargetProject     string
 	flagRefresh           bool
+	flagRefreshSchedule   string
 	flagAllowInconsistent bool
 }
 
@@ -46,6 +52,10 @@ Transfer modes (--mode):
  - relay: The CLI connects to both source and server and proxies the data (both source and target must listen on network)
 
 The pull transfer mode is the default as it is compatible with all server versions.
+
+With --refresh-schedule, the target server keeps refreshing the copy from the source instance on the
+given schedule (cron expression or schedule aliases like @daily), without this client.
+The target server certificate is added to the trust store of the source server for this purpose.
 `))
 
 	cmd.RunE = c.Run
@@ -61,6 +71,7 @@ The pull transfer mode is the default as it is compatible with all server versio
 	cmd.Flags().StringVar(&c.flagTargetProject, "target-project", "", i18n.G("Copy to a project different from the source")+"``")
 	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, i18n.G("Create the instance with no profiles applied"))
 	cmd.Flags().BoolVar(&c.flagRefresh, "refresh", false, i18n.G("Perform an incremental copy"))
+	cmd.Flags().StringVar(&c.flagRefreshSchedule, "refresh-schedule", "", i18n.G("Schedule on which the target server refreshes the copy from the source")+"``")
 	cmd.Flags().BoolVar(&c.flagAllowInconsistent, "allow-inconsistent", false, i18n.G("Ignore copy errors for volatile files"))
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -158,6 +169,22 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 		return err
 	}
 
+	// Set up the scheduled refresh of the copy.
+	if c.flagRefreshSchedule != "" {
+		if instance.IsSnapshot(sourceName) {
+			return fmt.Errorf(i18n.G("--refresh-schedule can only be used with instances"))
+		}
+
+		refreshConfig, err := c.refreshScheduleConfig(source, dest, sourceName)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range refreshConfig {
+			configMap[key] = value
+		}
+	}
+
 	var op incus.RemoteOperation
 	var writable api.InstancePut
 	var start bool
@@ -377,6 +404,18 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			writable.Config["volatile.idmap.next"] = inst.Config["volatile.idmap.next"]
 		}
 
+		// Ensure we don't change the target's scheduled refresh, unless set by this copy.
+		for key, value := range inst.Config {
+			if !strings.HasPrefix(key, "copy.refresh.") && key != "volatile.copy.refresh.last" {
+				continue
+			}
+
+			_, ok := configMap[key]
+			if !ok {
+				writable.Config[key] = value
+			}
+		}
+
 		// Ensure we don't change the target's root disk pool.
 		srcRootDiskDeviceKey, _, _ := instance.GetRootDiskDevice(writable.Devices)
 		destRootDiskDeviceKey, destRootDiskDevice, _ := instance.GetRootDiskDevice(inst.Devices)
@@ -431,6 +470,83 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 	return nil
 }
 
+// refreshScheduleConfig returns the configuration keys having the target server refresh the copy from the source
+// instance on the requested schedule, and makes sure that the source server trusts the target server.
+func (c *cmdCopy) refreshScheduleConfig(source incus.InstanceServer, dest incus.InstanceServer, sourceName string) (map[string]string, error) {
+	if !dest.HasExtension("instance_refresh_schedule") {
+		return nil, fmt.Errorf(i18n.G("The target server is missing the required \"instance_refresh_schedule\" API extension"))
+	}
+
+	info, err := source.GetConnectionInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	// The target server needs a network address of the source server.
+	sourceURL := info.URL
+	if !strings.HasPrefix(sourceURL, "https://") {
+		if len(info.Addresses) == 0 {
+			return nil, fmt.Errorf(i18n.G("The source server isn't listening on the network"))
+		}
+
+		sourceURL = info.Addresses[0]
+	}
+
+	sourceProject := info.Project
+	if sourceProject == "" {
+		sourceProject = api.ProjectDefaultName
+	}
+
+	// Trust the target server on the source server, restricted to the project of the source instance.
+	destServer, _, err := dest.GetServer()
+	if err != nil {
+		return nil, err
+	}
+
+	certBlock, _ := pem.Decode([]byte(destServer.Environment.Certificate))
+	if certBlock == nil {
+		return nil, fmt.Errorf(i18n.G("Invalid certificate of the target server"))
+	}
+
+	x509Cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("Invalid certificate of the target server: %w"), err)
+	}
+
+	_, _, err = source.GetCertificate(localtls.CertFingerprint(x509Cert))
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, err
+		}
+
+		cert := api.CertificatesPost{}
+		cert.Certificate = base64.StdEncoding.EncodeToString(x509Cert.Raw)
+		cert.Name = destServer.Environment.ServerName
+		cert.Type = api.CertificateTypeClient
+		cert.Restricted = true
+		cert.Projects = []string{sourceProject}
+
+		err = source.CreateCertificate(cert)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("Failed adding the target server certificate to the source server: %w"), err)
+		}
+	}
+
+	refreshConfig := map[string]string{
+		"copy.refresh.schedule":    c.flagRefreshSchedule,
+		"copy.refresh.server":      sourceURL,
+		"copy.refresh.certificate": info.Certificate,
+		"copy.refresh.project":     sourceProject,
+		"copy.refresh.source":      sourceName,
+	}
+
+	if c.flagInstanceOnly {
+		refreshConfig["copy.refresh.instance_only"] = "true"
+	}
+
+	return refreshConfig, nil
+}
+
 func (c *cmdCopy) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
 
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/ioprogress"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+type cmdCoreDump struct {
+	global *cmdGlobal
+}
+
+func (c *cmdCoreDump) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("coredump")
+	cmd.Short = i18n.G("Manage instance core dumps")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage instance core dumps
+
+  Core dumps are captured for instances with coredumps.enabled set.`))
+
+	// Delete.
+	coreDumpDeleteCmd := cmdCoreDumpDelete{global: c.global, coreDump: c}
+	cmd.AddCommand(coreDumpDeleteCmd.Command())
+
+	// List.
+	coreDumpListCmd := cmdCoreDumpList{global: c.global, coreDump: c}
+	cmd.AddCommand(coreDumpListCmd.Command())
+
+	// Pull.
+	coreDumpPullCmd := cmdCoreDumpPull{global: c.global, coreDump: c}
+	cmd.AddCommand(coreDumpPullCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Delete.
+type cmdCoreDumpDelete struct {
+	global   *cmdGlobal
+	coreDump *cmdCoreDump
+}
+
+func (c *cmdCoreDumpDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<instance> <core dump>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete instance core dumps")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Delete instance core dumps`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdCoreDumpDelete) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	remote, instanceName, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	return d.DeleteInstanceCoreDump(instanceName, args[1])
+}
+
+// List.
+type cmdCoreDumpList struct {
+	global   *cmdGlobal
+	coreDump *cmdCoreDump
+
+	flagFormat string
+}
+
+func (c *cmdCoreDumpList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<instance>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List instance core dumps")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List instance core dumps`))
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdCoreDumpList) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	remote, instanceName, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	dumps, err := d.GetInstanceCoreDumps(instanceName)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, dump := range dumps {
+		data = append(data, []string{
+			dump.Name,
+			dump.CreatedAt.Local().Format(dateLayout),
+			units.GetByteSizeStringIEC(dump.Size, 2),
+		})
+	}
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("CREATED AT"),
+		i18n.G("SIZE"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, dumps)
+}
+
+// Pull.
+type cmdCoreDumpPull struct {
+	global   *cmdGlobal
+	coreDump *cmdCoreDump
+}
+
+func (c *cmdCoreDumpPull) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("pull", i18n.G("[<remote>:]<instance> <core dump> [<target path>]"))
+	cmd.Short = i18n.G("Download instance core dumps")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Download instance core dumps
+
+  The core dump is written to the current directory unless a target path is given.
+  Use "-" as the target path to write it to the standard output.`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	return cmd
+}
+
+func (c *cmdCoreDumpPull) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 3)
+	if exit {
+		return err
+	}
+
+	remote, instanceName, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	targetPath := filepath.Base(args[1])
+	if len(args) > 2 {
+		targetPath = args[2]
+	}
+
+	content, _, err := d.GetInstanceCoreDumpFile(instanceName, args[1])
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = content.Close() }()
+
+	var f *os.File
+	if targetPath == "-" {
+		f = os.Stdout
+	} else {
+		f, err = os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = f.Close() }()
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: fmt.Sprintf(i18n.G("Pulling %s: %%s"), args[1]),
+		Quiet:  c.global.flagQuiet || targetPath == "-",
+	}
+
+	writer := &ioprogress.ProgressWriter{
+		WriteCloser: f,
+		Tracker: &ioprogress.ProgressTracker{
+			Handler: func(bytesReceived int64, speed int64) {
+				progress.UpdateProgress(ioprogress.ProgressData{
+					Text: fmt.Sprintf("%s (%s/s)",
+						units.GetByteSizeString(bytesReceived, 2),
+						units.GetByteSizeString(speed, 2)),
+				})
+			},
+		},
+	}
+
+	_, err = io.Copy(writer, content)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	if targetPath == "-" {
+		return nil
+	}
+
+	return f.Close()
+}
@@ -22,17 +22,22 @@ import (
 type cmdCreate struct {
 	global *cmdGlobal
 
-	flagConfig     []string
-	flagDevice     []string
-	flagEphemeral  bool
-	flagNetwork    string
-	flagProfile    []string
-	flagStorage    string
-	flagTarget     string
-	flagType       string
-	flagNoProfiles bool
-	flagEmpty      bool
-	flagVM         bool
+	flagArchitecture string
+	flagConfig       []string
+	flagDevice       []string
+	flagEphemeral    bool
+	flagNetwork      string
+	flagProfile      []string
+	flagStorage      string
+	flagTarget       string
+	flagType         string
+	flagNoProfiles   bool
+	flagEmpty        bool
+	flagVM           bool
+	flagFromOVA      string
+
+	flagWindows        bool
+	flagWindowsDrivers string
 }
 
 func (c *cmdCreate) Command() *cobra.Command {
@@ -55,9 +60,13 @@ incus create images:ubuntu/22.04 u1 < config.yaml
 	cmd.Flags().StringVarP(&c.flagStorage, "storage", "s", "", i18n.G("Storage pool name")+"``")
 	cmd.Flags().StringVarP(&c.flagType, "type", "t", "", i18n.G("Instance type")+"``")
 	cmd.Flags().StringVar(&c.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVar(&c.flagArchitecture, "architecture", "", i18n.G("Architecture of the image to use")+"``")
 	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, i18n.G("Create the instance with no profiles applied"))
 	cmd.Flags().BoolVar(&c.flagEmpty, "empty", false, i18n.G("Create an empty instance"))
 	cmd.Flags().BoolVar(&c.flagVM, "vm", false, i18n.G("Create a virtual machine"))
+	cmd.Flags().StringVar(&c.flagFromOVA, "from-ova", "", i18n.G("Create a virtual machine from an OVA or OVF appliance")+"``")
+	cmd.Flags().BoolVar(&c.flagWindows, "windows", false, i18n.G("Create a virtual machine set up to run Windows"))
+	cmd.Flags().StringVar(&c.flagWindowsDrivers, "windows-drivers", "", i18n.G("Windows drivers ISO file or volume to attach (default \"virtio-win\")")+"``")
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) != 0 {
@@ -77,7 +86,7 @@ func (c *cmdCreate) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(args) == 0 && !c.flagEmpty {
+	if len(args) == 0 && !c.flagEmpty && c.flagFromOVA == "" {
 		_ = cmd.Usage()
 		return nil
 	}
@@ -129,7 +138,15 @@ func (c *cmdCreate) create(conf *config.Config, args []string, launch bool) (inc
 		}
 	}
 
-	if c.flagEmpty {
+	if c.flagEmpty && c.flagFromOVA != "" {
+		return nil, "", fmt.Errorf(i18n.G("--empty cannot be combined with --from-ova"))
+	}
+
+	if c.flagEmpty || c.flagFromOVA != "" {
+		if len(args) > 1 && c.flagFromOVA != "" {
+			return nil, "", fmt.Errorf(i18n.G("--from-ova cannot be combined with an image name"))
+		}
+
 		if len(args) > 1 {
 			return nil, "", fmt.Errorf(i18n.G("--empty cannot be combined with an image name"))
 		}
@@ -248,7 +265,7 @@ func (c *cmdCreate) create(conf *config.Config, args []string, launch bool) (inc
 
 	// Decide whether we are creating a container or a virtual machine.
 	instanceDBType := api.InstanceTypeContainer
-	if c.flagVM {
+	if c.flagVM || c.flagWindows {
 		instanceDBType = api.InstanceTypeVM
 	}
 
@@ -263,6 +280,9 @@ func (c *cmdCreate) create(conf *config.Config, args []string, launch bool) (inc
 		InstanceType: c.flagType,
 		Type:         instanceDBType,
 		Start:        launch,
+		InstancePut: api.InstancePut{
+			Architecture: c.flagArchitecture,
+		},
 	}
 
 	req.Config = configMap
@@ -279,8 +299,17 @@ func (c *cmdCreate) create(conf *config.Config, args []string, launch bool) (inc
 		req.Profiles = profiles
 	}
 
+	// Apply the Windows configuration.
+	deviceOverrideArgs := c.flagDevice
+	if c.flagWindows {
+		deviceOverrideArgs, err = c.windowsSetup(d, &req, devicesMap)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
 	// Handle device overrides.
-	deviceOverrides, err := parseDeviceOverrides(c.flagDevice)
+	deviceOverrides, err := parseDeviceOverrides(deviceOverrideArgs)
 	if err != nil {
 		return nil, "", err
 	}
@@ -346,7 +375,14 @@ func (c *cmdCreate) create(conf *config.Config, args []string, launch bool) (inc
 	req.Devices = devicesMap
 
 	var opInfo api.Operation
-	if !c.flagEmpty {
+	if c.flagFromOVA != "" {
+		info, err := c.createFromOVA(d, &req)
+		if err != nil {
+			return nil, "", err
+		}
+
+		opInfo = *info
+	} else if !c.flagEmpty {
 		// Get the image server and image info
 		iremote, image = guessImage(conf, d, remote, iremote, image)
 
@@ -361,7 +397,7 @@ func (c *cmdCreate) create(conf *config.Config, args []string, launch bool) (inc
 		}
 
 		if conf.Remotes[iremote].Protocol != "simplestreams" {
-			if imgInfo.Type != "virtual-machine" && c.flagVM {
+			if imgInfo.Type != "virtual-machine" && (c.flagVM || c.flagWindows) {
 				return nil, "", fmt.Errorf(i18n.G("Asked for a VM but image is of type container"))
 			}
 
@@ -454,3 +490,35 @@ func (c *cmdCreate) checkNetwork(d incus.InstanceServer, name string) {
 	fmt.Fprintf(os.Stderr, "  "+i18n.G("To create a new network, use: incus network create")+"\n")
 	fmt.Fprintf(os.Stderr, "  "+i18n.G("To attach a network to an instance, use: incus network attach")+"\n\n")
 }
+
+// rootPool returns the storage pool the root disk of a new instance will be created on.
+func (c *cmdCreate) rootPool(d incus.InstanceServer, req *api.InstancesPost) (string, error) {
+	if c.flagStorage != "" {
+		return c.flagStorage, nil
+	}
+
+	profiles := req.Profiles
+	if profiles == nil {
+		profiles = []string{"default"}
+	}
+
+	pool := ""
+	for _, profileName := range profiles {
+		profile, _, err := d.GetProfile(profileName)
+		if err != nil {
+			return "", fmt.Errorf(i18n.G("Failed loading profile %q: %w"), profileName, err)
+		}
+
+		for _, device := range profile.Devices {
+			if device["type"] == "disk" && device["path"] == "/" && device["pool"] != "" {
+				pool = device["pool"]
+			}
+		}
+	}
+
+	if pool == "" {
+		return "", fmt.Errorf(i18n.G("No storage pool found for the instance, use --storage to select one"))
+	}
+
+	return pool, nil
+}
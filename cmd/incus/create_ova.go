@@ -0,0 +1,584 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/ioprogress"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+// OVF hardware resource types (CIM_ResourceAllocationSettingData).
+const (
+	ovfResourceCPU      = 3
+	ovfResourceMemory   = 4
+	ovfResourceEthernet = 10
+	ovfResourceDisk     = 17
+)
+
+// ovfEnvelope is the subset of an OVF descriptor relevant to an import.
+type ovfEnvelope struct {
+	Files []struct {
+		ID          string `xml:"id,attr"`
+		Href        string `xml:"href,attr"`
+		Compression string `xml:"compression,attr"`
+		ChunkSize   string `xml:"chunkSize,attr"`
+	} `xml:"References>File"`
+
+	Disks []struct {
+		ID            string `xml:"diskId,attr"`
+		FileRef       string `xml:"fileRef,attr"`
+		Capacity      string `xml:"capacity,attr"`
+		CapacityUnits string `xml:"capacityAllocationUnits,attr"`
+	} `xml:"DiskSection>Disk"`
+
+	Systems []ovfVirtualSystem `xml:"VirtualSystem"`
+
+	Collection *struct{} `xml:"VirtualSystemCollection"`
+}
+
+// ovfVirtualSystem is a virtual machine described by an OVF descriptor.
+type ovfVirtualSystem struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"Name"`
+
+	Hardware struct {
+		Items         []ovfItem `xml:"Item"`
+		StorageItems  []ovfItem `xml:"StorageItem"`
+		EthernetItems []ovfItem `xml:"EthernetPortItem"`
+
+		// VMware extra configuration.
+		Config []struct {
+			Key   string `xml:"key,attr"`
+			Value string `xml:"value,attr"`
+		} `xml:"Config"`
+	} `xml:"VirtualHardwareSection"`
+
+	// VirtualBox machine definition.
+	Firmware struct {
+		Type string `xml:"type,attr"`
+	} `xml:"Machine>Hardware>Firmware"`
+}
+
+// ovfItem is a hardware item of an OVF virtual system.
+type ovfItem struct {
+	ResourceType    int    `xml:"ResourceType"`
+	VirtualQuantity int64  `xml:"VirtualQuantity"`
+	AllocationUnits string `xml:"AllocationUnits"`
+	HostResource    string `xml:"HostResource"`
+	Connection      string `xml:"Connection"`
+	Address         string `xml:"Address"`
+}
+
+// parseOVF parses an OVF descriptor holding a single virtual system.
+func parseOVF(data []byte) (*ovfEnvelope, error) {
+	envelope := ovfEnvelope{}
+
+	err := xml.Unmarshal(data, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("Failed parsing OVF descriptor: %w"), err)
+	}
+
+	if envelope.Collection != nil || len(envelope.Systems) != 1 {
+		return nil, fmt.Errorf(i18n.G("Only OVF descriptors with a single virtual system are supported"))
+	}
+
+	return &envelope, nil
+}
+
+// ovfAllocationUnits returns the number of bytes represented by an OVF allocation unit, like "byte * 2^20".
+func ovfAllocationUnits(value string) (int64, error) {
+	unit := strings.ReplaceAll(strings.ToLower(value), " ", "")
+
+	switch unit {
+	case "", "byte", "bytes":
+		return 1, nil
+	case "kilobytes", "kb":
+		return 1024, nil
+	case "megabytes", "mb":
+		return 1024 * 1024, nil
+	case "gigabytes", "gb":
+		return 1024 * 1024 * 1024, nil
+	}
+
+	base, exponent, found := strings.Cut(strings.TrimPrefix(unit, "byte*"), "^")
+	if !found || !strings.HasPrefix(unit, "byte*") {
+		return -1, fmt.Errorf(i18n.G("Unsupported OVF allocation unit %q"), value)
+	}
+
+	b, err := strconv.ParseInt(base, 10, 64)
+	if err != nil {
+		return -1, fmt.Errorf(i18n.G("Unsupported OVF allocation unit %q"), value)
+	}
+
+	e, err := strconv.ParseInt(exponent, 10, 64)
+	if err != nil || e < 0 || e > 60 {
+		return -1, fmt.Errorf(i18n.G("Unsupported OVF allocation unit %q"), value)
+	}
+
+	result := int64(1)
+	for i := int64(0); i < e; i++ {
+		result *= b
+	}
+
+	return result, nil
+}
+
+// items returns the hardware items of the virtual system with the given resource type.
+func (s *ovfVirtualSystem) items(resourceType int) []ovfItem {
+	items := []ovfItem{}
+
+	for _, list := range [][]ovfItem{s.Hardware.Items, s.Hardware.StorageItems, s.Hardware.EthernetItems} {
+		for _, item := range list {
+			if item.ResourceType == resourceType {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items
+}
+
+// config returns the value of a VMware extra configuration key.
+func (s *ovfVirtualSystem) config(key string) string {
+	for _, entry := range s.Hardware.Config {
+		if entry.Key == key {
+			return entry.Value
+		}
+	}
+
+	return ""
+}
+
+// ovfDisk is a disk of an appliance, either backed by a file or blank.
+type ovfDisk struct {
+	href        string
+	compression string
+	size        int64
+}
+
+// disks returns the disks attached to the virtual system, in the order of its hardware section.
+func (e *ovfEnvelope) disks() ([]ovfDisk, error) {
+	disks := []ovfDisk{}
+
+	for _, item := range e.Systems[0].items(ovfResourceDisk) {
+		diskID := path.Base(item.HostResource)
+
+		found := false
+		for _, disk := range e.Disks {
+			if disk.ID != diskID {
+				continue
+			}
+
+			found = true
+			entry := ovfDisk{}
+
+			if disk.Capacity != "" {
+				unit, err := ovfAllocationUnits(disk.CapacityUnits)
+				if err != nil {
+					return nil, err
+				}
+
+				capacity, err := strconv.ParseInt(disk.Capacity, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf(i18n.G("Invalid capacity %q for disk %q"), disk.Capacity, disk.ID)
+				}
+
+				entry.size = capacity * unit
+			}
+
+			for _, file := range e.Files {
+				if file.ID != disk.FileRef {
+					continue
+				}
+
+				if file.ChunkSize != "" {
+					return nil, fmt.Errorf(i18n.G("Chunked disk %q isn't supported"), file.Href)
+				}
+
+				entry.href = file.Href
+				entry.compression = file.Compression
+			}
+
+			if disk.FileRef != "" && entry.href == "" {
+				return nil, fmt.Errorf(i18n.G("Missing file reference %q for disk %q"), disk.FileRef, disk.ID)
+			}
+
+			disks = append(disks, entry)
+			break
+		}
+
+		if !found {
+			return nil, fmt.Errorf(i18n.G("Unknown disk %q referenced by the virtual hardware"), item.HostResource)
+		}
+	}
+
+	return disks, nil
+}
+
+// ovfOpen opens a file of an OVA archive or of the directory of an OVF descriptor.
+// The returned reader must be closed once done.
+func ovfOpen(appliance string, name string) (io.ReadCloser, int64, error) {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return nil, -1, fmt.Errorf(i18n.G("Invalid appliance file reference %q"), name)
+	}
+
+	if strings.ToLower(filepath.Ext(appliance)) == ".ovf" {
+		file, err := os.Open(filepath.Join(filepath.Dir(appliance), name))
+		if err != nil {
+			return nil, -1, err
+		}
+
+		fstat, err := file.Stat()
+		if err != nil {
+			_ = file.Close()
+			return nil, -1, err
+		}
+
+		return file, fstat.Size(), nil
+	}
+
+	file, err := os.Open(appliance)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	tr := tar.NewReader(file)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			_ = file.Close()
+
+			if errors.Is(err, io.EOF) {
+				return nil, -1, fmt.Errorf(i18n.G("File %q not found in appliance"), name)
+			}
+
+			return nil, -1, err
+		}
+
+		if path.Clean(strings.TrimPrefix(hdr.Name, "./")) != name {
+			continue
+		}
+
+		return &ovfEntryReader{Reader: tr, file: file}, hdr.Size, nil
+	}
+}
+
+// ovfEntryReader reads a single entry of an OVA archive, closing the archive once done.
+type ovfEntryReader struct {
+	io.Reader
+
+	file *os.File
+}
+
+// Close closes the underlying archive.
+func (r *ovfEntryReader) Close() error {
+	return r.file.Close()
+}
+
+// ovfDescriptor returns the OVF descriptor of an OVA archive or an OVF file.
+func ovfDescriptor(appliance string) ([]byte, error) {
+	if strings.ToLower(filepath.Ext(appliance)) == ".ovf" {
+		return os.ReadFile(appliance)
+	}
+
+	file, err := os.Open(appliance)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	// The descriptor is the first file of an OVA archive.
+	tr := tar.NewReader(file)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf(i18n.G("No OVF descriptor found in %q"), appliance)
+			}
+
+			return nil, fmt.Errorf(i18n.G("Failed reading OVA archive %q: %w"), appliance, err)
+		}
+
+		if strings.ToLower(path.Ext(hdr.Name)) == ".ovf" {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// createFromOVA creates a virtual machine from an OVA or OVF appliance.
+// The first disk of the appliance is copied to the root disk of the instance, the other ones being imported
+// as block custom volumes attached to it.
+func (c *cmdCreate) createFromOVA(d incus.InstanceServer, req *api.InstancesPost) (*api.Operation, error) {
+	data, err := ovfDescriptor(c.flagFromOVA)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := parseOVF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	system := envelope.Systems[0]
+
+	if req.Name == "" {
+		req.Name = system.Name
+		if req.Name == "" {
+			req.Name = system.ID
+		}
+	}
+
+	if req.Name == "" {
+		return nil, fmt.Errorf(i18n.G("The appliance doesn't define a name, one must be provided"))
+	}
+
+	req.Type = api.InstanceTypeVM
+
+	// Map the CPU and memory, unless provided by the user.
+	for _, item := range system.items(ovfResourceCPU) {
+		if req.Config["limits.cpu"] == "" && item.VirtualQuantity > 0 {
+			req.Config["limits.cpu"] = strconv.FormatInt(item.VirtualQuantity, 10)
+		}
+	}
+
+	for _, item := range system.items(ovfResourceMemory) {
+		if req.Config["limits.memory"] != "" || item.VirtualQuantity <= 0 {
+			continue
+		}
+
+		unit, err := ovfAllocationUnits(item.AllocationUnits)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Config["limits.memory"] = fmt.Sprintf("%dMiB", item.VirtualQuantity*unit/1024/1024)
+	}
+
+	// Select the firmware.
+	uefi := system.config("firmware") == "efi" || strings.EqualFold(system.Firmware.Type, "efi")
+	secureBoot := system.config("uefi.secureBoot.enabled") == "true"
+
+	if !uefi && req.Config["security.csm"] == "" {
+		req.Config["security.csm"] = "true"
+	}
+
+	if !secureBoot && req.Config["security.secureboot"] == "" {
+		req.Config["security.secureboot"] = "false"
+	}
+
+	// Map the network interfaces to the networks named after their connection.
+	networks, err := d.GetNetworkNames()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range system.items(ovfResourceEthernet) {
+		devName := fmt.Sprintf("eth%d", i)
+
+		device, ok := req.Devices[devName]
+		if !ok {
+			if !slices.Contains(networks, item.Connection) {
+				fmt.Fprintf(os.Stderr, i18n.G("Skipping network interface %q on network %q which doesn't exist")+"\n", devName, item.Connection)
+				continue
+			}
+
+			device = map[string]string{
+				"type":    "nic",
+				"network": item.Connection,
+			}
+
+			req.Devices[devName] = device
+		}
+
+		// Keep the MAC address so the guest network configuration keeps working.
+		if item.Address != "" && device["hwaddr"] == "" {
+			device["hwaddr"] = item.Address
+		}
+	}
+
+	// Import the disks.
+	disks, err := envelope.disks()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(disks) == 0 {
+		return nil, fmt.Errorf(i18n.G("The appliance doesn't have any disk"))
+	}
+
+	if !d.HasExtension("instance_create_from_volume") {
+		return nil, fmt.Errorf(i18n.G("The server doesn't support creating instances from a custom volume"))
+	}
+
+	pool, err := c.rootPool(d, req)
+	if err != nil {
+		return nil, err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	target := d
+
+	for i, disk := range disks {
+		volName := fmt.Sprintf("%s-disk%d", req.Name, i)
+
+		err = c.ovaImportDisk(d, pool, volName, disk)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { _ = d.DeleteStoragePoolVolume(pool, "custom", volName) })
+
+		// The first disk becomes the root disk of the instance.
+		if i == 0 {
+			req.Source.Type = "volume"
+			req.Source.Source = volName
+			req.Source.Pool = pool
+
+			// Create the instance where the volume got imported.
+			vol, _, err := d.GetStoragePoolVolume(pool, "custom", volName)
+			if err != nil {
+				return nil, err
+			}
+
+			if d.IsClustered() && vol.Location != "" && vol.Location != "none" {
+				target = d.UseTarget(vol.Location)
+			}
+
+			continue
+		}
+
+		req.Devices[fmt.Sprintf("disk%d", i)] = map[string]string{
+			"type":   "disk",
+			"pool":   pool,
+			"source": volName,
+		}
+	}
+
+	// Create the instance.
+	op, err := target.CreateInstance(*req)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := cli.ProgressRenderer{
+		Quiet: c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return nil, err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return nil, err
+	}
+
+	progress.Done("")
+	revert.Success()
+
+	// The root disk holds a copy of the first disk, which is no longer needed.
+	err = d.DeleteStoragePoolVolume(pool, "custom", req.Source.Source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.G("Failed deleting the imported volume %q: %v")+"\n", req.Source.Source, err)
+	}
+
+	opInfo := op.Get()
+
+	return &opInfo, nil
+}
+
+// ovaImportDisk imports a disk of an appliance as a block custom volume.
+func (c *cmdCreate) ovaImportDisk(d incus.InstanceServer, pool string, volName string, disk ovfDisk) error {
+	// Blank disks only define a capacity.
+	if disk.href == "" {
+		return d.CreateStoragePoolVolume(pool, api.StorageVolumesPost{
+			Name:        volName,
+			Type:        "custom",
+			ContentType: "block",
+			StorageVolumePut: api.StorageVolumePut{
+				Config: map[string]string{"size": strconv.FormatInt(disk.size, 10)},
+			},
+		})
+	}
+
+	if disk.compression != "" && disk.compression != "gzip" {
+		return fmt.Errorf(i18n.G("Unsupported compression %q for disk %q"), disk.compression, disk.href)
+	}
+
+	file, size, err := ovfOpen(c.flagFromOVA, disk.href)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	progress := cli.ProgressRenderer{
+		Format: fmt.Sprintf(i18n.G("Importing disk %s: %s"), disk.href, "%s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	var reader io.Reader = &ioprogress.ProgressReader{
+		ReadCloser: file,
+		Tracker: &ioprogress.ProgressTracker{
+			Length: size,
+			Handler: func(percent int64, speed int64) {
+				progress.UpdateProgress(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
+			},
+		},
+	}
+
+	if disk.compression == "gzip" {
+		reader, err = gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed decompressing disk %q: %w"), disk.href, err)
+		}
+	}
+
+	op, err := d.CreateStoragePoolVolumeFromDiskImage(pool, incus.StoragePoolVolumeBackupArgs{
+		BackupFile: reader,
+		Name:       volName,
+	})
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return fmt.Errorf(i18n.G("Failed importing disk %q: %w"), disk.href, err)
+	}
+
+	progress.Done("")
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"archive/tar"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testOVF = `<?xml version="1.0" encoding="UTF-8"?>
+<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:rasd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1">
+  <References>
+    <File ovf:id="file1" ovf:href="disk1.vmdk" ovf:compression="gzip"/>
+  </References>
+  <DiskSection>
+    <Disk ovf:diskId="vmdisk1" ovf:fileRef="file1" ovf:capacity="16" ovf:capacityAllocationUnits="byte * 2^30"/>
+    <Disk ovf:diskId="vmdisk2" ovf:capacity="1073741824"/>
+  </DiskSection>
+  <VirtualSystem ovf:id="appliance">
+    <Name>web01</Name>
+    <VirtualHardwareSection>
+      <Item>
+        <rasd:ResourceType>3</rasd:ResourceType>
+        <rasd:VirtualQuantity>2</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:AllocationUnits>byte * 2^20</rasd:AllocationUnits>
+        <rasd:ResourceType>4</rasd:ResourceType>
+        <rasd:VirtualQuantity>2048</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:Address>00:16:3e:00:00:01</rasd:Address>
+        <rasd:Connection>incusbr0</rasd:Connection>
+        <rasd:ResourceType>10</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:HostResource>ovf:/disk/vmdisk1</rasd:HostResource>
+        <rasd:ResourceType>17</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:HostResource>ovf:/disk/vmdisk2</rasd:HostResource>
+        <rasd:ResourceType>17</rasd:ResourceType>
+      </Item>
+      <vmw:Config xmlns:vmw="http://www.vmware.com/schema/ovf" vmw:key="firmware" vmw:value="efi"/>
+    </VirtualHardwareSection>
+  </VirtualSystem>
+</Envelope>
+`
+
+func TestParseOVF(t *testing.T) {
+	envelope, err := parseOVF([]byte(testOVF))
+	require.NoError(t, err)
+	require.Len(t, envelope.Systems, 1)
+
+	system := envelope.Systems[0]
+	require.Equal(t, "web01", system.Name)
+	require.Equal(t, "appliance", system.ID)
+	require.Equal(t, "efi", system.config("firmware"))
+	require.Equal(t, "", system.config("uefi.secureBoot.enabled"))
+
+	cpus := system.items(ovfResourceCPU)
+	require.Len(t, cpus, 1)
+	require.Equal(t, int64(2), cpus[0].VirtualQuantity)
+
+	memory := system.items(ovfResourceMemory)
+	require.Len(t, memory, 1)
+	require.Equal(t, "byte * 2^20", memory[0].AllocationUnits)
+
+	nics := system.items(ovfResourceEthernet)
+	require.Len(t, nics, 1)
+	require.Equal(t, "incusbr0", nics[0].Connection)
+	require.Equal(t, "00:16:3e:00:00:01", nics[0].Address)
+
+	disks, err := envelope.disks()
+	require.NoError(t, err)
+	require.Equal(t, []ovfDisk{
+		{href: "disk1.vmdk", compression: "gzip", size: 16 * 1024 * 1024 * 1024},
+		{size: 1024 * 1024 * 1024},
+	}, disks)
+}
+
+func TestParseOVFInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		descriptor string
+	}{
+		{"Not XML", "appliance"},
+		{"No virtual system", `<Envelope></Envelope>`},
+		{"Several virtual systems", `<Envelope><VirtualSystem/><VirtualSystem/></Envelope>`},
+		{"Virtual system collection", `<Envelope><VirtualSystem/><VirtualSystemCollection/></Envelope>`},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		_, err := parseOVF([]byte(tt.descriptor))
+		require.Error(t, err)
+	}
+}
+
+func TestOVFDisksInvalid(t *testing.T) {
+	system := `<VirtualSystem><VirtualHardwareSection><Item><ResourceType>17</ResourceType><HostResource>ovf:/disk/vmdisk1</HostResource></Item></VirtualHardwareSection></VirtualSystem>`
+
+	tests := []struct {
+		name       string
+		descriptor string
+	}{
+		{"Unknown disk", `<Envelope>` + system + `</Envelope>`},
+		{"Missing file", `<Envelope><DiskSection><Disk diskId="vmdisk1" fileRef="file1"/></DiskSection>` + system + `</Envelope>`},
+		{"Chunked file", `<Envelope><References><File id="file1" href="disk1.vmdk" chunkSize="1024"/></References><DiskSection><Disk diskId="vmdisk1" fileRef="file1"/></DiskSection>` + system + `</Envelope>`},
+		{"Invalid capacity", `<Envelope><DiskSection><Disk diskId="vmdisk1" capacity="big"/></DiskSection>` + system + `</Envelope>`},
+		{"Invalid capacity unit", `<Envelope><DiskSection><Disk diskId="vmdisk1" capacity="1" capacityAllocationUnits="byte * 2"/></DiskSection>` + system + `</Envelope>`},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		envelope, err := parseOVF([]byte(tt.descriptor))
+		require.NoError(t, err)
+
+		_, err = envelope.disks()
+		require.Error(t, err)
+	}
+}
+
+func TestOVFAllocationUnits(t *testing.T) {
+	tests := []struct {
+		value  string
+		result int64
+		fails  bool
+	}{
+		{"", 1, false},
+		{"byte", 1, false},
+		{"bytes", 1, false},
+		{"KiloBytes", 1024, false},
+		{"MegaBytes", 1024 * 1024, false},
+		{"GigaBytes", 1024 * 1024 * 1024, false},
+		{"byte * 2^20", 1024 * 1024, false},
+		{"byte*2^30", 1024 * 1024 * 1024, false},
+		{"byte * 10^3", 1000, false},
+		{"byte * 2^61", -1, true},
+		{"byte * 2", -1, true},
+		{"bit", -1, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %q", i, tt.value)
+
+		result, err := ovfAllocationUnits(tt.value)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.result, result)
+	}
+}
+
+func TestOVFOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	// Write an OVA archive with the descriptor first.
+	appliance := filepath.Join(dir, "web01.ova")
+	f, err := os.Create(appliance)
+	require.NoError(t, err)
+
+	tw := tar.NewWriter(f)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "web01.ovf", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(testOVF))}))
+	_, err = tw.Write([]byte(testOVF))
+	require.NoError(t, err)
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./disk1.vmdk", Typeflag: tar.TypeReg, Mode: 0644, Size: 4}))
+	_, err = tw.Write([]byte("disk"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, f.Close())
+
+	descriptor, err := ovfDescriptor(appliance)
+	require.NoError(t, err)
+	require.Equal(t, testOVF, string(descriptor))
+
+	reader, size, err := ovfOpen(appliance, "disk1.vmdk")
+	require.NoError(t, err)
+	require.Equal(t, int64(4), size)
+
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "disk", string(body))
+	require.NoError(t, reader.Close())
+
+	_, _, err = ovfOpen(appliance, "disk2.vmdk")
+	require.Error(t, err)
+
+	// References outside of the appliance are rejected.
+	_, _, err = ovfOpen(appliance, "../disk1.vmdk")
+	require.Error(t, err)
+
+	// The files of an OVF descriptor are next to it.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "web01.ovf"), []byte(testOVF), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "disk1.vmdk"), []byte("disk"), 0644))
+
+	descriptor, err = ovfDescriptor(filepath.Join(dir, "web01.ovf"))
+	require.NoError(t, err)
+	require.Equal(t, testOVF, string(descriptor))
+
+	reader, size, err = ovfOpen(filepath.Join(dir, "web01.ovf"), "disk1.vmdk")
+	require.NoError(t, err)
+	require.Equal(t, int64(4), size)
+	require.NoError(t, reader.Close())
+}
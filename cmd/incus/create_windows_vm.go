@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/ioprogress"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+// windowsDriversVolume is the name of the custom volume looked up for the virtio-win drivers ISO.
+const windowsDriversVolume = "virtio-win"
+
+// windowsDefaultConfig is the configuration applied to Windows virtual machines, unless set by the user or a profile.
+// Windows 11 requires at least 2 CPUs and 4GiB of memory.
+var windowsDefaultConfig = map[string]string{
+	"limits.cpu":          "2",
+	"limits.memory":       "4GiB",
+	"security.secureboot": "true",
+}
+
+// windowsDefaultRootDisk is the root disk configuration applied to Windows virtual machines, unless already set.
+// Windows 11 requires a 64GiB disk, and its installer can see NVMe disks without additional drivers.
+var windowsDefaultRootDisk = map[string]string{
+	"size":   "64GiB",
+	"io.bus": "nvme",
+}
+
+// windowsSetup applies the configuration needed to run Windows to a virtual machine creation request.
+// It adds a TPM and the virtio-win drivers ISO to the devices and returns the device overrides to apply.
+func (c *cmdCreate) windowsSetup(d incus.InstanceServer, req *api.InstancesPost, devices map[string]map[string]string) ([]string, error) {
+	// If the list of profiles is empty then the default profile would be applied on the server side.
+	profileNames := req.Profiles
+	if len(profileNames) == 0 {
+		profileNames = []string{"default"}
+	}
+
+	profiles := make([]api.Profile, 0, len(profileNames))
+	for _, profileName := range profileNames {
+		profile, _, err := d.GetProfile(profileName)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("Failed loading profile %q: %w"), profileName, err)
+		}
+
+		profiles = append(profiles, *profile)
+	}
+
+	overrides := windowsApplyDefaults(req.Config, devices, profiles)
+
+	// Attach the drivers ISO.
+	pool, err := c.rootPool(d, req)
+	if err != nil {
+		return nil, err
+	}
+
+	volName, err := c.windowsDrivers(d, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	if volName != "" {
+		devices[volName] = map[string]string{
+			"type":   "disk",
+			"pool":   pool,
+			"source": volName,
+		}
+	}
+
+	// Apply the root disk defaults before the user provided overrides.
+	return append(overrides, c.flagDevice...), nil
+}
+
+// windowsApplyDefaults fills in the Windows defaults for the keys set neither on the instance nor by its profiles.
+// It adds a TPM to the devices if there isn't one yet and returns the root disk overrides to apply.
+func windowsApplyDefaults(config map[string]string, devices map[string]map[string]string, profiles []api.Profile) []string {
+	// Get the effective expanded configuration and devices by overlaying each profile in order.
+	expandedConfig := map[string]string{}
+	expandedDevices := map[string]map[string]string{}
+	for _, profile := range profiles {
+		for k, v := range profile.Config {
+			expandedConfig[k] = v
+		}
+
+		for k, v := range profile.Devices {
+			expandedDevices[k] = v
+		}
+	}
+
+	for k, v := range config {
+		expandedConfig[k] = v
+	}
+
+	for k, v := range devices {
+		expandedDevices[k] = v
+	}
+
+	for key, value := range windowsDefaultConfig {
+		_, ok := expandedConfig[key]
+		if !ok {
+			config[key] = value
+		}
+	}
+
+	// Provide a TPM, as required by Windows 11.
+	hasTPM := false
+	rootName := ""
+	for name, device := range expandedDevices {
+		if device["type"] == "tpm" {
+			hasTPM = true
+		}
+
+		if device["type"] == "disk" && device["path"] == "/" {
+			rootName = name
+		}
+	}
+
+	if !hasTPM {
+		devices["vtpm"] = map[string]string{
+			"type": "tpm",
+			"path": "/dev/tpm0",
+		}
+	}
+
+	// Only override the root disk settings which aren't set yet.
+	overrides := []string{}
+	if rootName == "" {
+		return overrides
+	}
+
+	keys := make([]string, 0, len(windowsDefaultRootDisk))
+	for key := range windowsDefaultRootDisk {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		_, ok := expandedDevices[rootName][key]
+		if !ok {
+			overrides = append(overrides, fmt.Sprintf("%s,%s=%s", rootName, key, windowsDefaultRootDisk[key]))
+		}
+	}
+
+	return overrides
+}
+
+// windowsDrivers returns the name of the ISO volume holding the Windows drivers, importing it first if given as a file.
+// It returns an empty name if the default drivers volume doesn't exist.
+func (c *cmdCreate) windowsDrivers(d incus.InstanceServer, pool string) (string, error) {
+	volName := c.flagWindowsDrivers
+	if volName == "" {
+		volName = windowsDriversVolume
+	}
+
+	// Import the ISO if given a file.
+	if strings.HasSuffix(strings.ToLower(volName), ".iso") {
+		file, err := os.Open(volName)
+		if err != nil {
+			return "", err
+		}
+
+		defer func() { _ = file.Close() }()
+
+		volName = strings.TrimSuffix(filepath.Base(file.Name()), filepath.Ext(file.Name()))
+
+		_, _, err = d.GetStoragePoolVolume(pool, "custom", volName)
+		if err == nil {
+			// Reuse the drivers imported earlier.
+			return volName, nil
+		}
+
+		fstat, err := file.Stat()
+		if err != nil {
+			return "", err
+		}
+
+		progress := cli.ProgressRenderer{
+			Format: i18n.G("Importing drivers: %s"),
+			Quiet:  c.global.flagQuiet,
+		}
+
+		op, err := d.CreateStoragePoolVolumeFromISO(pool, incus.StoragePoolVolumeBackupArgs{
+			BackupFile: &ioprogress.ProgressReader{
+				ReadCloser: file,
+				Tracker: &ioprogress.ProgressTracker{
+					Length: fstat.Size(),
+					Handler: func(percent int64, speed int64) {
+						progress.UpdateProgress(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
+					},
+				},
+			},
+			Name: volName,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		err = cli.CancelableWait(op, &progress)
+		if err != nil {
+			progress.Done("")
+			return "", err
+		}
+
+		progress.Done("")
+
+		return volName, nil
+	}
+
+	_, _, err := d.GetStoragePoolVolume(pool, "custom", volName)
+	if err != nil {
+		if !api.StatusErrorCheck(err, 404) {
+			return "", err
+		}
+
+		if c.flagWindowsDrivers != "" {
+			return "", fmt.Errorf(i18n.G("Drivers volume %q not found in storage pool %q"), volName, pool)
+		}
+
+		fmt.Fprintf(os.Stderr, i18n.G("No %q volume found in storage pool %q, the virtio-win drivers won't be available to the instance. Use --windows-drivers with the path to the drivers ISO to import it.")+"\n", windowsDriversVolume, pool)
+
+		return "", nil
+	}
+
+	return volName, nil
+}
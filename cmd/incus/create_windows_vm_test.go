@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestWindowsApplyDefaults(t *testing.T) {
+	profiles := []api.Profile{{
+		Name: "default",
+		ProfilePut: api.ProfilePut{
+			Config:  map[string]string{"limits.memory": "8GiB"},
+			Devices: map[string]map[string]string{"disk0": {"type": "disk", "path": "/", "pool": "default", "size": "100GiB"}},
+		},
+	}}
+
+	// Only the keys set neither on the instance nor by its profiles are filled in.
+	config := map[string]string{"limits.cpu": "4"}
+	devices := map[string]map[string]string{}
+	overrides := windowsApplyDefaults(config, devices, profiles)
+	require.Equal(t, map[string]string{"limits.cpu": "4", "security.secureboot": "true"}, config)
+	require.Equal(t, map[string]map[string]string{"vtpm": {"type": "tpm", "path": "/dev/tpm0"}}, devices)
+	require.Equal(t, []string{"disk0,io.bus=nvme"}, overrides)
+
+	// An existing TPM of the profiles is kept, and the local root disk replaces the one of the profiles.
+	profiles[0].Devices["tpm"] = map[string]string{"type": "tpm"}
+	config = map[string]string{}
+	devices = map[string]map[string]string{"disk0": {"type": "disk", "path": "/", "pool": "fast"}}
+	overrides = windowsApplyDefaults(config, devices, profiles)
+	require.Equal(t, map[string]string{"limits.cpu": "2", "security.secureboot": "true"}, config)
+	require.Len(t, devices, 1)
+	require.Equal(t, []string{"disk0,io.bus=nvme", "disk0,size=64GiB"}, overrides)
+}
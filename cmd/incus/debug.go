@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+)
+
+type cmdDebug struct {
+	global *cmdGlobal
+}
+
+func (c *cmdDebug) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("debug")
+	cmd.Short = i18n.G("Debug instances")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Debug instances`))
+
+	// Boot log.
+	debugBootLogCmd := cmdDebugBootLog{global: c.global, debug: c}
+	cmd.AddCommand(debugBootLogCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Boot log.
+type cmdDebugBootLog struct {
+	global *cmdGlobal
+	debug  *cmdDebug
+
+	flagFormat string
+	flagSince  string
+}
+
+func (c *cmdDebugBootLog) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("bootlog", i18n.G("[<remote>:]<instance>"))
+	cmd.Short = i18n.G("Show the boot diagnostics of instances")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the boot diagnostics of instances
+
+  The report covers the last start attempt of the instance. It includes the
+  instance log, the console output, the AppArmor denials and seccomp
+  violations found in the kernel log, and the id mapping and control group
+  setup errors.
+
+  The kernel log entries are limited to those since the end of the previous
+  run of the instance, unless --since is set.`))
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "pretty", i18n.G("Format (json|pretty|yaml)")+"``")
+	cmd.Flags().StringVar(&c.flagSince, "since", "", i18n.G("Only show the kernel log entries of this last duration (e.g. 30m)")+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdDebugBootLog) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	if !slices.Contains([]string{"json", "pretty", "yaml"}, c.flagFormat) {
+		return fmt.Errorf(i18n.G("Invalid format: %s"), c.flagFormat)
+	}
+
+	bootlogArgs := incus.InstanceBootLogArgs{}
+	if c.flagSince != "" {
+		duration, err := time.ParseDuration(c.flagSince)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Invalid duration %q: %w"), c.flagSince, err)
+		}
+
+		bootlogArgs.Since = time.Now().Add(-duration)
+	}
+
+	remote, instanceName, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	bootlog, err := d.GetInstanceBootLog(instanceName, &bootlogArgs)
+	if err != nil {
+		return err
+	}
+
+	switch c.flagFormat {
+	case "json":
+		data, err := json.MarshalIndent(bootlog, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(data))
+		return nil
+	case "yaml":
+		data, err := yaml.Marshal(bootlog)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(string(data))
+		return nil
+	}
+
+	section := func(title string, content string) {
+		content = strings.TrimSpace(content)
+		if content == "" {
+			content = i18n.G("(none)")
+		}
+
+		fmt.Printf("%s:\n%s\n\n", title, content)
+	}
+
+	fmt.Printf(i18n.G("Status: %s")+"\n", bootlog.Status)
+	fmt.Printf(i18n.G("Collected at: %s")+"\n", bootlog.CollectedAt.Local().Format(dateLayout))
+	if !bootlog.Since.IsZero() {
+		fmt.Printf(i18n.G("Kernel log since: %s")+"\n", bootlog.Since.Local().Format(dateLayout))
+	}
+
+	fmt.Println("")
+
+	for _, warning := range bootlog.Warnings {
+		fmt.Printf(i18n.G("Warning: %s")+"\n\n", warning)
+	}
+
+	section(i18n.G("Setup errors"), strings.Join(bootlog.SetupErrors, "\n"))
+	section(i18n.G("AppArmor denials"), strings.Join(bootlog.AppArmorDenials, "\n"))
+	section(i18n.G("Seccomp violations"), strings.Join(bootlog.SeccompViolations, "\n"))
+	section(i18n.G("Console log"), bootlog.Console)
+	section(i18n.G("Instance log"), bootlog.Log)
+
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -30,6 +31,7 @@ type cmdExec struct {
 	flagUser                uint32
 	flagGroup               uint32
 	flagCwd                 string
+	flagAgentJSON           bool
 
 	interactive bool
 }
@@ -48,7 +50,10 @@ executable, passing the shell commands as arguments, for example:
 
   incus exec <instance> -- sh -c "cd /tmp && pwd"
 
-Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).`))
+Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).
+
+For virtual machines, --agent-json runs the command through the agent without
+any stdin and prints its exit code, output, duration and peak memory usage as JSON.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().StringArrayVar(&c.flagEnvironment, "env", nil, i18n.G("Environment variable to set (e.g. HOME=/home/foo)")+"``")
@@ -59,6 +64,7 @@ Mode defaults to non-interactive, interactive mode is selected if both stdin AND
 	cmd.Flags().Uint32Var(&c.flagUser, "user", 0, i18n.G("User ID to run the command as (default 0)")+"``")
 	cmd.Flags().Uint32Var(&c.flagGroup, "group", 0, i18n.G("Group ID to run the command as (default 0)")+"``")
 	cmd.Flags().StringVar(&c.flagCwd, "cwd", "", i18n.G("Directory to run the command in (default /root)")+"``")
+	cmd.Flags().BoolVar(&c.flagAgentJSON, "agent-json", false, i18n.G("Run the command through the VM agent and print its result as JSON"))
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -133,6 +139,35 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 		env[pieces[0]] = value
 	}
 
+	if c.flagAgentJSON {
+		if c.flagMode == "interactive" || c.flagForceInteractive {
+			return fmt.Errorf(i18n.G("--agent-json can't be used in interactive mode"))
+		}
+
+		req := api.InstanceExecPost{
+			Command:     args[1:],
+			Environment: env,
+			User:        c.flagUser,
+			Group:       c.flagGroup,
+			Cwd:         c.flagCwd,
+		}
+
+		result, err := d.ExecInstanceStructured(name, req)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(data))
+		c.global.ret = result.ExitCode
+
+		return nil
+	}
+
 	// Configure the terminal
 	stdinFd := getStdinFd()
 	stdoutFd := getStdoutFd()
@@ -23,6 +23,7 @@ type cmdExport struct {
 	flagInstanceOnly         bool
 	flagOptimizedStorage     bool
 	flagCompressionAlgorithm string
+	flagNetworkState         bool
 }
 
 func (c *cmdExport) Command() *cobra.Command {
@@ -41,6 +42,8 @@ func (c *cmdExport) Command() *cobra.Command {
 	cmd.Flags().BoolVar(&c.flagOptimizedStorage, "optimized-storage", false,
 		i18n.G("Use storage driver optimized format (can only be restored on a similar pool)"))
 	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Compression algorithm to use (none for uncompressed)")+"``")
+	cmd.Flags().BoolVar(&c.flagNetworkState, "network-state", false,
+		i18n.G("Include the network dependencies of the instance (ACLs, forwards and DNS records)"))
 
 	return cmd
 }
@@ -73,6 +76,7 @@ func (c *cmdExport) Run(cmd *cobra.Command, args []string) error {
 		InstanceOnly:         instanceOnly,
 		OptimizedStorage:     c.flagOptimizedStorage,
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		NetworkState:         c.flagNetworkState,
 	}
 
 	op, err := d.CreateInstanceBackup(name, req)
@@ -86,6 +86,10 @@ hash or alias name (if one is set).`))
 	imageListCmd := cmdImageList{global: c.global, image: c}
 	cmd.AddCommand(imageListCmd.Command())
 
+	// Optimize
+	imageOptimizeCmd := cmdImageOptimize{global: c.global, image: c}
+	cmd.AddCommand(imageOptimizeCmd.Command())
+
 	// Refresh
 	imageRefreshCmd := cmdImageRefresh{global: c.global, image: c}
 	cmd.AddCommand(imageRefreshCmd.Command())
@@ -1410,6 +1414,140 @@ func (c *cmdImageList) Run(cmd *cobra.Command, args []string) error {
 	return cli.RenderTable(c.flagFormat, headers, data, rawData)
 }
 
+// Optimize.
+type cmdImageOptimize struct {
+	global *cmdGlobal
+	image  *cmdImage
+
+	flagAll                  bool
+	flagCompressionAlgorithm string
+	flagFormat               string
+}
+
+func (c *cmdImageOptimize) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("optimize", i18n.G("[<remote>:]<image> [[<remote>:]<image>...]"))
+	cmd.Short = i18n.G("Convert the stored files of images")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Convert the stored files of images
+
+Images are converted to the given compression algorithm (or to the one configured
+through images.compression_algorithm) and format. The converted image replaces the
+existing one, keeping its properties and aliases, but gets a new fingerprint.
+
+With --all, all the images of the given remotes are converted.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus image optimize ubuntu/24.04 --compression="zstd -19"
+    Re-compress an image with zstd at level 19.
+
+incus image optimize --all --format=unified
+    Convert all the images of the current remote to unified tarballs.`))
+
+	cmd.Flags().BoolVar(&c.flagAll, "all", false, i18n.G("Convert all images"))
+	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Compression algorithm to use (`none` for uncompressed)"))
+	cmd.Flags().StringVar(&c.flagFormat, "format", "", i18n.G("Format of the converted images (unified or split)")+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if c.flagAll {
+			return c.global.cmpRemotes(false)
+		}
+
+		return c.global.cmpImages(toComplete)
+	}
+
+	return cmd
+}
+
+func (c *cmdImageOptimize) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	minArgs := 1
+	if c.flagAll {
+		minArgs = 0
+	}
+
+	exit, err := c.global.CheckArgs(cmd, args, minArgs, -1)
+	if exit {
+		return err
+	}
+
+	if c.flagFormat != "" && c.flagFormat != "unified" && c.flagFormat != "split" {
+		return fmt.Errorf(i18n.G("Invalid format %q, must be unified or split"), c.flagFormat)
+	}
+
+	if c.flagAll && len(args) == 0 {
+		args = []string{""}
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args...)
+	if err != nil {
+		return err
+	}
+
+	req := api.ImageOptimizePost{
+		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		Format:               c.flagFormat,
+	}
+
+	for _, resource := range resources {
+		var fingerprints []string
+
+		if c.flagAll {
+			if resource.name != "" {
+				return fmt.Errorf(i18n.G("Only remotes can be given with --all"))
+			}
+
+			fingerprints, err = resource.server.GetImageFingerprints()
+			if err != nil {
+				return err
+			}
+		} else {
+			if resource.name == "" {
+				return fmt.Errorf(i18n.G("Image identifier missing"))
+			}
+
+			fingerprints = []string{c.image.dereferenceAlias(resource.server, "", resource.name)}
+		}
+
+		for _, fingerprint := range fingerprints {
+			progress := cli.ProgressRenderer{
+				Format: i18n.G("Optimizing the image: %s"),
+				Quiet:  c.global.flagQuiet,
+			}
+
+			op, err := resource.server.OptimizeImage(fingerprint, req)
+			if err != nil {
+				return err
+			}
+
+			// Register progress handler
+			_, err = op.AddHandler(progress.UpdateOp)
+			if err != nil {
+				return err
+			}
+
+			err = op.Wait()
+			if err != nil {
+				progress.Done("")
+				return err
+			}
+
+			opAPI := op.Get()
+
+			newFingerprint, _ := opAPI.Metadata["fingerprint"].(string)
+			if newFingerprint == "" || newFingerprint == fingerprint {
+				progress.Done(fmt.Sprintf(i18n.G("Image %s already optimized"), fingerprint))
+			} else {
+				progress.Done(fmt.Sprintf(i18n.G("Image %s optimized as %s"), fingerprint, newFingerprint))
+			}
+		}
+	}
+
+	return nil
+}
+
 // Refresh.
 type cmdImageRefresh struct {
 	global *cmdGlobal
@@ -17,7 +17,8 @@ import (
 type cmdImport struct {
 	global *cmdGlobal
 
-	flagStorage string
+	flagStorage      string
+	flagNetworkState bool
 }
 
 func (c *cmdImport) Command() *cobra.Command {
@@ -32,6 +33,7 @@ func (c *cmdImport) Command() *cobra.Command {
 
 	cmd.RunE = c.Run
 	cmd.Flags().StringVarP(&c.flagStorage, "storage", "s", "", i18n.G("Storage pool name")+"``")
+	cmd.Flags().BoolVar(&c.flagNetworkState, "network-state", false, i18n.G("Restore the network dependencies of the instance stored in the backup"))
 
 	return cmd
 }
@@ -104,8 +106,9 @@ func (c *cmdImport) Run(cmd *cobra.Command, args []string) error {
 				},
 			},
 		},
-		PoolName: c.flagStorage,
-		Name:     instanceName,
+		PoolName:     c.flagStorage,
+		Name:         instanceName,
+		NetworkState: c.flagNetworkState,
 	}
 
 	op, err := resource.server.CreateInstanceFromBackup(createArgs)
@@ -122,5 +125,14 @@ func (c *cmdImport) Run(cmd *cobra.Command, args []string) error {
 
 	progress.Done("")
 
+	// Report the network dependencies which couldn't be restored.
+	report, ok := op.Get().Metadata["network_state"].([]any)
+	if ok {
+		fmt.Fprintln(os.Stderr, i18n.G("Some network dependencies of the instance weren't restored:"))
+		for _, entry := range report {
+			fmt.Fprintf(os.Stderr, " - %v\n", entry)
+		}
+	}
+
 	return nil
 }
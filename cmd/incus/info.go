@@ -514,6 +514,9 @@ func (c *cmdInfo) remoteInfo(d incus.InstanceServer) error {
 		fmt.Printf("  "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Total-resources.Memory.Used), 2))
 		fmt.Printf("  "+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Used), 2))
 		fmt.Printf("  "+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Total), 2))
+		if resources.Memory.Reserved > 0 {
+			fmt.Printf("  "+i18n.G("Reserved: %v")+"\n", units.GetByteSizeStringIEC(int64(resources.Memory.Reserved), 2))
+		}
 
 		// GPUs
 		if len(resources.GPU.Cards) == 1 {
@@ -646,6 +649,10 @@ func (c *cmdInfo) instanceInfo(d incus.InstanceServer, remote config.Remote, nam
 		// Processes
 		fmt.Printf("  "+i18n.G("Processes: %d")+"\n", inst.State.Processes)
 
+		if inst.State.FileDescriptors > 0 {
+			fmt.Printf("  "+i18n.G("File descriptors: %d")+"\n", inst.State.FileDescriptors)
+		}
+
 		// Disk usage
 		diskInfo := ""
 		if inst.State.Disk != nil {
@@ -667,6 +674,15 @@ func (c *cmdInfo) instanceInfo(d incus.InstanceServer, remote config.Remote, nam
 			cpuInfo += fmt.Sprintf("    %s: %v\n", i18n.G("CPU usage (in seconds)"), inst.State.CPU.Usage/1000000000)
 		}
 
+		if len(inst.State.CPU.NUMANodes) > 0 {
+			numaNodes := make([]string, 0, len(inst.State.CPU.NUMANodes))
+			for _, numaNode := range inst.State.CPU.NUMANodes {
+				numaNodes = append(numaNodes, fmt.Sprintf("%d", numaNode))
+			}
+
+			cpuInfo += fmt.Sprintf("    %s: %s\n", i18n.G("NUMA nodes"), strings.Join(numaNodes, ", "))
+		}
+
 		if cpuInfo != "" {
 			fmt.Printf("  %s\n", i18n.G("CPU usage:"))
 			fmt.Print(cpuInfo)
@@ -695,6 +711,43 @@ func (c *cmdInfo) instanceInfo(d incus.InstanceServer, remote config.Remote, nam
 			fmt.Print(memoryInfo)
 		}
 
+		// GPU usage
+		if len(inst.State.GPU) > 0 {
+			gpuNames := make([]string, 0, len(inst.State.GPU))
+			for name := range inst.State.GPU {
+				gpuNames = append(gpuNames, name)
+			}
+
+			sort.Strings(gpuNames)
+
+			fmt.Printf("  %s\n", i18n.G("GPU usage:"))
+			for _, name := range gpuNames {
+				gpu := inst.State.GPU[name]
+
+				fmt.Printf("    %s:\n", name)
+				fmt.Printf("      %s: %s\n", i18n.G("PCI address"), gpu.PCIAddress)
+				fmt.Printf("      %s: %d%%\n", i18n.G("Utilization"), gpu.Utilization)
+				fmt.Printf("      %s: %s / %s\n", i18n.G("Memory"), units.GetByteSizeStringIEC(gpu.MemoryUsage, 2), units.GetByteSizeStringIEC(gpu.MemoryTotal, 2))
+
+				if gpu.InstanceMemoryUsage >= 0 {
+					fmt.Printf("      %s: %s\n", i18n.G("Memory (instance)"), units.GetByteSizeStringIEC(gpu.InstanceMemoryUsage, 2))
+				}
+			}
+		}
+
+		// Pressure stall information
+		if len(inst.State.Pressure) > 0 {
+			fmt.Printf("  %s\n", i18n.G("Pressure (some / full, last 10s, 60s and 300s):"))
+			for _, resource := range []string{"cpu", "memory", "io"} {
+				pressure, ok := inst.State.Pressure[resource]
+				if !ok {
+					continue
+				}
+
+				fmt.Printf("    %s: %.2f%% %.2f%% %.2f%% / %.2f%% %.2f%% %.2f%%\n", resource, pressure.SomeAvg10, pressure.SomeAvg60, pressure.SomeAvg300, pressure.FullAvg10, pressure.FullAvg60, pressure.FullAvg300)
+			}
+		}
+
 		// Network usage and IP info
 		networkInfo := ""
 		if inst.State.Network != nil {
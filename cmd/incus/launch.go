@@ -33,7 +33,13 @@ incus launch images:ubuntu/22.04 u2 -t aws:t2.micro
     Create and start a container using the same size as an AWS t2.micro (1 vCPU, 1GiB of RAM)
 
 incus launch images:ubuntu/22.04 v1 --vm -c limits.cpu=4 -c limits.memory=4GiB
-    Create and start a virtual machine with 4 vCPUs and 4GiB of RAM`))
+    Create and start a virtual machine with 4 vCPUs and 4GiB of RAM
+
+incus launch --from-ova appliance.ova v2
+    Create and start a virtual machine from the OVA appliance appliance.ova
+
+incus launch win11 w1 --windows --windows-drivers virtio-win.iso
+    Create and start a Windows virtual machine from the local image win11, with a TPM and the virtio-win drivers`))
 	cmd.Hidden = false
 
 	cmd.RunE = c.Run
@@ -56,7 +62,12 @@ func (c *cmdLaunch) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
 
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	minArgs := 1
+	if c.init.flagFromOVA != "" {
+		minArgs = 0
+	}
+
+	exit, err := c.global.CheckArgs(cmd, args, minArgs, 2)
 	if exit {
 		return err
 	}
@@ -133,6 +133,14 @@ Custom commands can be defined through aliases, use "incus alias" to control tho
 	consoleCmd := cmdConsole{global: &globalCmd}
 	app.AddCommand(consoleCmd.Command())
 
+	// convert sub-command
+	convertCmd := cmdConvert{global: &globalCmd}
+	app.AddCommand(convertCmd.Command())
+
+	// coredump sub-command
+	coreDumpCmd := cmdCoreDump{global: &globalCmd}
+	app.AddCommand(coreDumpCmd.Command())
+
 	// create sub-command
 	createCmd := cmdCreate{global: &globalCmd}
 	app.AddCommand(createCmd.Command())
@@ -141,6 +149,10 @@ Custom commands can be defined through aliases, use "incus alias" to control tho
 	copyCmd := cmdCopy{global: &globalCmd}
 	app.AddCommand(copyCmd.Command())
 
+	// debug sub-command
+	debugCmd := cmdDebug{global: &globalCmd}
+	app.AddCommand(debugCmd.Command())
+
 	// delete sub-command
 	deleteCmd := cmdDelete{global: &globalCmd}
 	app.AddCommand(deleteCmd.Command())
@@ -217,6 +229,10 @@ Custom commands can be defined through aliases, use "incus alias" to control tho
 	queryCmd := cmdQuery{global: &globalCmd}
 	app.AddCommand(queryCmd.Command())
 
+	// query-list sub-command
+	queryListCmd := cmdQueryList{global: &globalCmd}
+	app.AddCommand(queryListCmd.Command())
+
 	// rebuild sub-command
 	rebuildCmd := cmdRebuild{global: &globalCmd}
 	app.AddCommand(rebuildCmd.Command())
@@ -233,6 +249,10 @@ Custom commands can be defined through aliases, use "incus alias" to control tho
 	remoteCmd := cmdRemote{global: &globalCmd}
 	app.AddCommand(remoteCmd.Command())
 
+	// restore-deleted sub-command
+	restoreDeletedCmd := cmdRestoreDeleted{global: &globalCmd}
+	app.AddCommand(restoreDeletedCmd.Command())
+
 	// resume sub-command
 	resumeCmd := cmdResume{global: &globalCmd}
 	app.AddCommand(resumeCmd.Command())
@@ -241,6 +261,10 @@ Custom commands can be defined through aliases, use "incus alias" to control tho
 	snapshotCmd := cmdSnapshot{global: &globalCmd}
 	app.AddCommand(snapshotCmd.Command())
 
+	// ssh sub-command
+	sshCmd := cmdSSH{global: &globalCmd}
+	app.AddCommand(sshCmd.Command())
+
 	// storage sub-command
 	storageCmd := cmdStorage{global: &globalCmd}
 	app.AddCommand(storageCmd.Command())
@@ -978,6 +978,39 @@ func (c *cmdNetworkInfo) Run(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s: %s\n", i18n.G("Upper devices"), strings.Join(state.Bridge.UpperDevices, ", "))
 	}
 
+	// Traffic shaping information.
+	if state.Shaping != nil {
+		fmt.Println("")
+		fmt.Println(i18n.G("Traffic shaping:"))
+		if state.Shaping.Ingress != "" {
+			fmt.Printf("  %s: %s\n", i18n.G("Ingress limit"), state.Shaping.Ingress)
+		}
+
+		if state.Shaping.Egress != "" {
+			fmt.Printf("  %s: %s\n", i18n.G("Egress limit"), state.Shaping.Egress)
+		}
+
+		for _, member := range state.Shaping.Members {
+			name := member.Instance
+			if member.Project != api.ProjectDefaultName {
+				name = fmt.Sprintf("%s (%s)", member.Instance, member.Project)
+			}
+
+			fmt.Printf("  %s/%s:\n", name, member.Device)
+
+			for _, entry := range []struct {
+				name     string
+				counters *api.NetworkStateShapingCounters
+			}{{i18n.G("Ingress"), member.Ingress}, {i18n.G("Egress"), member.Egress}} {
+				if entry.counters == nil {
+					continue
+				}
+
+				fmt.Printf("    %s: %s (%s: %dbit/s, %s: %d)\n", entry.name, units.GetByteSizeString(entry.counters.Bytes, 2), i18n.G("limit"), entry.counters.Limit, i18n.G("drops"), entry.counters.Drops)
+			}
+		}
+	}
+
 	// VLAN information.
 	if state.VLAN != nil {
 		fmt.Println("")
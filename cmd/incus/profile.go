@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 
+	"github.com/lxc/incus/v6/client"
 	cli "github.com/lxc/incus/v6/internal/cmd"
 	"github.com/lxc/incus/v6/internal/i18n"
 	"github.com/lxc/incus/v6/shared/api"
@@ -590,6 +591,14 @@ func (c *cmdProfileEdit) Run(cmd *cobra.Command, args []string) error {
 		// Parse the text received from the editor
 		newdata := api.ProfilePut{}
 		err = yaml.Unmarshal(content, &newdata)
+		if err == nil {
+			var confirmed bool
+			confirmed, err = c.confirm(resource.server, resource.name, newdata)
+			if err == nil && !confirmed {
+				return fmt.Errorf(i18n.G("Profile update aborted"))
+			}
+		}
+
 		if err == nil {
 			err = resource.server.UpdateProfile(resource.name, newdata, etag)
 		}
@@ -618,6 +627,47 @@ func (c *cmdProfileEdit) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// confirm shows the running instances which would be affected by the profile change and asks whether to
+// go ahead when any of them would fail validation or need a restart.
+func (c *cmdProfileEdit) confirm(server incus.InstanceServer, name string, profile api.ProfilePut) (bool, error) {
+	if !server.HasExtension("profile_preview") {
+		return true, nil
+	}
+
+	preview, err := server.PreviewProfileUpdate(name, profile)
+	if err != nil {
+		return false, err
+	}
+
+	prompt := false
+	for _, inst := range preview.Instances {
+		if inst.Error != "" || (inst.Running && inst.RequiresRestart) {
+			prompt = true
+			break
+		}
+	}
+
+	if !prompt {
+		return true, nil
+	}
+
+	fmt.Printf(i18n.G("This change affects %d instances:")+"\n", len(preview.Instances))
+	for _, inst := range preview.Instances {
+		changes := append(slices.Clone(inst.Config), inst.Devices...)
+		line := fmt.Sprintf(" - %s (%s): %s", inst.Name, inst.Project, strings.Join(changes, ", "))
+
+		if inst.Error != "" {
+			line += fmt.Sprintf(" ["+i18n.G("invalid: %s")+"]", inst.Error)
+		} else if inst.Running && inst.RequiresRestart {
+			line += " [" + i18n.G("requires restart") + "]"
+		}
+
+		fmt.Println(line)
+	}
+
+	return c.global.asker.AskBool(i18n.G("Apply the change anyway?")+" (yes/no) [default=no]: ", "no")
+}
+
 // Get.
 type cmdProfileGet struct {
 	global  *cmdGlobal
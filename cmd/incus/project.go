@@ -75,6 +75,10 @@ func (c *cmdProject) Command() *cobra.Command {
 	projectGetInfo := cmdProjectInfo{global: c.global, project: c}
 	cmd.AddCommand(projectGetInfo.Command())
 
+	// List idle instances
+	projectListIdleCmd := cmdProjectListIdle{global: c.global, project: c}
+	cmd.AddCommand(projectListIdleCmd.Command())
+
 	// Set default
 	projectSwitchCmd := cmdProjectSwitch{global: c.global, project: c}
 	cmd.AddCommand(projectSwitchCmd.Command())
@@ -1089,3 +1093,83 @@ func (c *cmdProjectInfo) Run(cmd *cobra.Command, args []string) error {
 
 	return cli.RenderTable(c.flagFormat, header, data, projectState)
 }
+
+// List idle instances.
+type cmdProjectListIdle struct {
+	global  *cmdGlobal
+	project *cmdProject
+
+	flagFormat string
+}
+
+func (c *cmdProjectListIdle) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-idle", i18n.G("[<remote>:]<project>"))
+	cmd.Short = i18n.G("List the idle instances of a project")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List the idle instances of a project
+
+Instances get detected as idle when their activity stays below the thresholds
+configured through the instances.idle.* server configuration options.`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpProjects(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdProjectListIdle) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing project name"))
+	}
+
+	idleInstances, err := resource.server.GetProjectIdleInstances(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Render the output
+	data := [][]string{}
+	for _, inst := range idleInstances {
+		lastUsed := ""
+		if !inst.LastUsedAt.IsZero() {
+			lastUsed = inst.LastUsedAt.Local().Format(dateLayout)
+		}
+
+		data = append(data, []string{inst.Name, inst.Type, inst.Location, inst.IdleSince.Local().Format(dateLayout), lastUsed})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("TYPE"),
+		i18n.G("LOCATION"),
+		i18n.G("IDLE SINCE"),
+		i18n.G("LAST USED AT"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, idleInstances)
+}
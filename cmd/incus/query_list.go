@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+)
+
+type cmdQueryList struct {
+	global *cmdGlobal
+
+	flagFormat string
+}
+
+func (c *cmdQueryList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("query-list", i18n.G("[<remote>:] <statement>"))
+	cmd.Short = i18n.G("Query instances across projects and cluster members")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Query instances across projects and cluster members
+
+The statement is executed by the server, which only gathers the state of
+the instances when the statement refers to it:
+
+  SELECT <field>[, <field>...] FROM instances [WHERE <condition>] [ORDER BY <field> [ASC|DESC]] [LIMIT <count>]
+
+Fields use the same names as the API (and the filters of "incus list"),
+for example "name", "location", "config.limits.cpu" or "state.memory.usage".
+Configuration keys can also be written as config['image.os'].
+
+Conditions compare a field with a value using =, !=, <, <=, > or >= and
+can be combined with AND, OR, NOT and parentheses.
+
+Unless --project is set, all projects are queried.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus query-list "select name, state.memory.usage from instances where config['image.os']='Ubuntu' and location='node3'"
+    List the memory usage of the Ubuntu instances running on node3.
+
+incus query-list "select name, project from instances where status=Running order by created_at desc limit 10"
+    List the ten most recently created running instances.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+// formatValue renders a query value for display in a table.
+func (c *cmdQueryList) formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(data)
+}
+
+func (c *cmdQueryList) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := conf.DefaultRemote
+	statement := args[0]
+	if len(args) == 2 {
+		remote, _, err = conf.ParseRemote(args[0])
+		if err != nil {
+			return err
+		}
+
+		statement = args[1]
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	// Query all projects unless one was explicitly requested.
+	if c.global.flagProject == "" {
+		d = d.UseProject("")
+	}
+
+	result, err := d.RunQuery(statement)
+	if err != nil {
+		return err
+	}
+
+	header := make([]string, 0, len(result.Columns))
+	for _, column := range result.Columns {
+		header = append(header, strings.ToUpper(column))
+	}
+
+	data := [][]string{}
+	entries := make([]map[string]any, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		line := make([]string, 0, len(row))
+		entry := map[string]any{}
+
+		for i, value := range row {
+			line = append(line, c.formatValue(value))
+
+			if i < len(result.Columns) {
+				entry[result.Columns[i]] = value
+			}
+		}
+
+		data = append(data, line)
+		entries = append(entries, entry)
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, entries)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+type cmdRestoreDeleted struct {
+	global *cmdGlobal
+
+	flagFormat string
+	flagList   bool
+	flagPool   string
+	flagPurge  bool
+	flagVolume bool
+}
+
+func (c *cmdRestoreDeleted) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("restore-deleted", i18n.G("[<remote>:]<name> [<new name>]"))
+	cmd.Short = i18n.G("Restore deleted instances and custom storage volumes")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Restore deleted instances and custom storage volumes
+
+When the recycle bin is enabled through the instances.recycle_bin.expiry server option,
+deleted instances and custom storage volumes are kept until they expire.
+
+The most recently deleted instance, or custom storage volume with --volume, of that name is restored.
+Use --list to show the content of the recycle bin and --purge to permanently delete an entry.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus restore-deleted --list
+    Show the deleted instances and custom storage volumes.
+
+incus restore-deleted c1 c1-restored
+    Restore the deleted instance "c1" as "c1-restored".
+
+incus restore-deleted vol1 --volume --pool default
+    Restore the deleted custom storage volume "vol1" of the "default" pool.`))
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.Flags().BoolVar(&c.flagList, "list", false, i18n.G("List the content of the recycle bin"))
+	cmd.Flags().StringVar(&c.flagPool, "pool", "", i18n.G("Storage pool of the deleted resource")+"``")
+	cmd.Flags().BoolVar(&c.flagPurge, "purge", false, i18n.G("Permanently delete the resource from the recycle bin"))
+	cmd.Flags().BoolVar(&c.flagVolume, "volume", false, i18n.G("Restore a custom storage volume rather than an instance"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdRestoreDeleted) Run(cmd *cobra.Command, args []string) error {
+	if c.flagList {
+		return c.runList(cmd, args)
+	}
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing name"))
+	}
+
+	entries, err := resource.server.GetRecycleBinEntries()
+	if err != nil {
+		return err
+	}
+
+	entryType := "instance"
+	if c.flagVolume {
+		entryType = "volume"
+	}
+
+	// Entries are sorted by deletion date, pick the most recent match.
+	var entry *api.RecycleBinEntry
+	for i := range entries {
+		if entries[i].Type != entryType || entries[i].Name != resource.name {
+			continue
+		}
+
+		if c.flagPool != "" && entries[i].Pool != c.flagPool {
+			continue
+		}
+
+		entry = &entries[i]
+	}
+
+	if entry == nil {
+		return fmt.Errorf(i18n.G("No deleted %s named %q found in the recycle bin"), entryType, resource.name)
+	}
+
+	if c.flagPurge {
+		return resource.server.DeleteRecycleBinEntry(entry.ID)
+	}
+
+	req := api.RecycleBinEntryPost{}
+	if len(args) > 1 {
+		req.Name = args[1]
+	}
+
+	op, err := resource.server.RestoreRecycleBinEntry(entry.ID, req)
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Restoring: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// Wait for operation to finish.
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	return nil
+}
+
+func (c *cmdRestoreDeleted) runList(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	entries, err := resources[0].server.GetRecycleBinEntries()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, entry := range entries {
+		data = append(data, []string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.Type,
+			entry.Name,
+			entry.Pool,
+			entry.Location,
+			entry.DeletedAt.Local().Format(dateLayout),
+			entry.ExpiresAt.Local().Format(dateLayout),
+		})
+	}
+
+	header := []string{
+		i18n.G("ID"),
+		i18n.G("TYPE"),
+		i18n.G("NAME"),
+		i18n.G("POOL"),
+		i18n.G("LOCATION"),
+		i18n.G("DELETED AT"),
+		i18n.G("EXPIRES AT"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, entries)
+}
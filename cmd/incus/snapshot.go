@@ -357,6 +357,8 @@ func (c *cmdSnapshotList) listSnapshots(d incus.InstanceServer, name string) err
 			row = append(row, "NO")
 		}
 
+		row = append(row, snap.Parent)
+
 		snapData = append(snapData, row)
 	}
 
@@ -365,6 +367,7 @@ func (c *cmdSnapshotList) listSnapshots(d incus.InstanceServer, name string) err
 		i18n.G("Taken at"),
 		i18n.G("Expires at"),
 		i18n.G("Stateful"),
+		i18n.G("Parent"),
 	}
 
 	_ = cli.RenderTable(c.flagFormat, snapHeader, snapData, snapshots)
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+
+	incus "github.com/lxc/incus/v6/client"
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+type cmdSSH struct {
+	global *cmdGlobal
+
+	flagUser     string
+	flagIdentity string
+}
+
+func (c *cmdSSH) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("ssh", i18n.G("[<remote>:]<instance> [-- <ssh arguments>...]"))
+	cmd.Short = i18n.G("Connect to instances over SSH")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Connect to instances over SSH
+
+The address of the instance is resolved from its state and the private key
+matching one of the keys from its ssh.authorized_keys.* configuration is used.
+
+The SSH host keys of the instance are retrieved through the API and trusted
+for the connection.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus ssh c1
+    Connect to c1 as root.
+
+incus ssh c1 --user ubuntu -- uptime
+    Run "uptime" in c1 as the ubuntu user.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagUser, "user", "u", "root", i18n.G("User to connect as")+"``")
+	cmd.Flags().StringVarP(&c.flagIdentity, "identity", "i", "", i18n.G("Private key to authenticate with")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdSSH) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Connect to the daemon.
+	remote, name, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	inst, _, err := d.GetInstance(name)
+	if err != nil {
+		return err
+	}
+
+	if inst.StatusCode != api.Running {
+		return fmt.Errorf(i18n.G("Instance %q isn't running"), name)
+	}
+
+	state, _, err := d.GetInstanceState(name)
+	if err != nil {
+		return err
+	}
+
+	address := c.address(state)
+	if address == "" {
+		return fmt.Errorf(i18n.G("Instance %q doesn't have a global IP address"), name)
+	}
+
+	sshArgs := []string{"-l", c.flagUser}
+
+	// Find the key to authenticate with.
+	identity := c.flagIdentity
+	if identity == "" {
+		identity = c.identity(d, inst)
+	}
+
+	if identity != "" {
+		sshArgs = append(sshArgs, "-i", identity)
+	}
+
+	// Trust the host keys of the instance.
+	hostKeys := c.hostKeys(d, name, address)
+	if len(hostKeys) > 0 {
+		knownHosts, err := os.CreateTemp("", "incus_ssh_known_hosts_")
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = os.Remove(knownHosts.Name()) }()
+
+		_, err = knownHosts.Write(hostKeys)
+		if err != nil {
+			_ = knownHosts.Close()
+			return err
+		}
+
+		err = knownHosts.Close()
+		if err != nil {
+			return err
+		}
+
+		sshArgs = append(sshArgs, "-o", fmt.Sprintf("UserKnownHostsFile=%s", knownHosts.Name()), "-o", "StrictHostKeyChecking=yes")
+	}
+
+	sshArgs = append(sshArgs, address)
+	sshArgs = append(sshArgs, args[1:]...)
+
+	sshCmd := exec.Command("ssh", sshArgs...)
+	sshCmd.Stdin = os.Stdin
+	sshCmd.Stdout = os.Stdout
+	sshCmd.Stderr = os.Stderr
+
+	err = sshCmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			c.global.ret = exitErr.ExitCode()
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// address returns the first global address of the instance, preferring IPv4.
+func (c *cmdSSH) address(state *api.InstanceState) string {
+	networkNames := make([]string, 0, len(state.Network))
+	for networkName := range state.Network {
+		networkNames = append(networkNames, networkName)
+	}
+
+	sort.Strings(networkNames)
+
+	for _, family := range []string{"inet", "inet6"} {
+		for _, networkName := range networkNames {
+			if networkName == "lo" {
+				continue
+			}
+
+			for _, addr := range state.Network[networkName].Addresses {
+				if addr.Family == family && addr.Scope == "global" {
+					return addr.Address
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// identity returns the path to a local private key whose public key is set in the ssh.authorized_keys.*
+// configuration of the instance or of its project.
+func (c *cmdSSH) identity(d incus.InstanceServer, inst *api.Instance) string {
+	config := map[string]string{}
+
+	connInfo, err := d.GetConnectionInfo()
+	if err == nil {
+		project, _, err := d.GetProject(connInfo.Project)
+		if err == nil {
+			for k, v := range project.Config {
+				config[k] = v
+			}
+		}
+	}
+
+	for k, v := range inst.ExpandedConfig {
+		config[k] = v
+	}
+
+	authorizedKeys := [][]byte{}
+	for k, v := range config {
+		if !strings.HasPrefix(k, "ssh.authorized_keys.") {
+			continue
+		}
+
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(v))
+		if err == nil {
+			authorizedKeys = append(authorizedKeys, key.Marshal())
+		}
+	}
+
+	if len(authorizedKeys) == 0 {
+		return ""
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	publicKeys, err := filepath.Glob(filepath.Join(homeDir, ".ssh", "id_*.pub"))
+	if err != nil {
+		return ""
+	}
+
+	for _, publicKey := range publicKeys {
+		content, err := os.ReadFile(publicKey)
+		if err != nil {
+			continue
+		}
+
+		key, _, _, _, err := ssh.ParseAuthorizedKey(content)
+		if err != nil {
+			continue
+		}
+
+		for _, authorizedKey := range authorizedKeys {
+			if bytes.Equal(key.Marshal(), authorizedKey) {
+				return strings.TrimSuffix(publicKey, ".pub")
+			}
+		}
+	}
+
+	return ""
+}
+
+// hostKeys returns known_hosts entries for the SSH host keys of the instance.
+func (c *cmdSSH) hostKeys(d incus.InstanceServer, name string, address string) []byte {
+	entries := bytes.Buffer{}
+
+	for _, keyType := range []string{"ed25519", "ecdsa", "rsa"} {
+		buf, _, err := d.GetInstanceFile(name, fmt.Sprintf("/etc/ssh/ssh_host_%s_key.pub", keyType))
+		if err != nil {
+			continue
+		}
+
+		content := bytes.Buffer{}
+		_, err = content.ReadFrom(buf)
+		_ = buf.Close()
+		if err != nil {
+			continue
+		}
+
+		_, _, _, _, err = ssh.ParseAuthorizedKey(content.Bytes())
+		if err != nil {
+			continue
+		}
+
+		fmt.Fprintf(&entries, "%s %s\n", address, strings.TrimSpace(content.String()))
+	}
+
+	return entries.Bytes()
+}
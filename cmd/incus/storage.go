@@ -61,6 +61,10 @@ func (c *cmdStorage) Command() *cobra.Command {
 	storageListCmd := cmdStorageList{global: c.global, storage: c}
 	cmd.AddCommand(storageListCmd.Command())
 
+	// Mirror
+	storageMirrorCmd := cmdStorageMirror{global: c.global}
+	cmd.AddCommand(storageMirrorCmd.Command())
+
 	// Set
 	storageSetCmd := cmdStorageSet{global: c.global, storage: c}
 	cmd.AddCommand(storageSetCmd.Command())
@@ -49,6 +49,10 @@ func (c *cmdStorageBucket) Command() *cobra.Command {
 	storageBucketGetCmd := cmdStorageBucketGet{global: c.global, storageBucket: c}
 	cmd.AddCommand(storageBucketGetCmd.Command())
 
+	// Info.
+	storageBucketInfoCmd := cmdStorageBucketInfo{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketInfoCmd.Command())
+
 	// List.
 	storageBucketListCmd := cmdStorageBucketList{global: c.global, storageBucket: c}
 	cmd.AddCommand(storageBucketListCmd.Command())
@@ -455,6 +459,73 @@ func (c *cmdStorageBucketGet) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Info.
+type cmdStorageBucketInfo struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+func (c *cmdStorageBucketInfo) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("info", i18n.G("[<remote>:]<pool> <bucket>"))
+	cmd.Short = i18n.G("Show storage bucket usage")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Show storage bucket usage`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus storage bucket info default data
+    Will show the space used by a bucket called "data" in the "default" pool.`))
+
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdStorageBucketInfo) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing bucket name"))
+	}
+
+	client := resource.server
+
+	// If a target member was specified, get the bucket with the matching name on that member, if any.
+	if c.storageBucket.flagTarget != "" {
+		client = client.UseTarget(c.storageBucket.flagTarget)
+	}
+
+	state, err := client.GetStoragePoolBucketState(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Name: %s")+"\n", args[1])
+	fmt.Printf(i18n.G("Usage: %s")+"\n", units.GetByteSizeStringIEC(state.Used, 2))
+	if state.Total > 0 {
+		fmt.Printf(i18n.G("Total: %s")+"\n", units.GetByteSizeStringIEC(state.Total, 2))
+	}
+
+	fmt.Printf(i18n.G("Objects: %d")+"\n", state.Objects)
+
+	return nil
+}
+
 // List.
 type cmdStorageBucketList struct {
 	global        *cmdGlobal
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+type cmdStorageMirror struct {
+	global *cmdGlobal
+}
+
+func (c *cmdStorageMirror) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("mirror")
+	cmd.Short = i18n.G("Manage storage pool mirroring")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage storage pool mirroring
+
+Mirrored storage pools replicate their volumes to the same pool on a peer cluster.
+Failing over to the peer cluster is done by demoting the pool on this cluster, then promoting it on the peer cluster.`))
+
+	// Demote
+	storageMirrorDemoteCmd := cmdStorageMirrorDemote{global: c.global, storageMirror: c}
+	cmd.AddCommand(storageMirrorDemoteCmd.Command())
+
+	// Promote
+	storageMirrorPromoteCmd := cmdStorageMirrorPromote{global: c.global, storageMirror: c}
+	cmd.AddCommand(storageMirrorPromoteCmd.Command())
+
+	// Show
+	storageMirrorShowCmd := cmdStorageMirrorShow{global: c.global, storageMirror: c}
+	cmd.AddCommand(storageMirrorShowCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// run sends a mirroring role change for the storage pool.
+func (c *cmdStorageMirror) run(cmd *cobra.Command, args []string, req api.StoragePoolMirrorPost) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	return resource.server.UpdateStoragePoolMirror(resource.name, req)
+}
+
+// Demote.
+type cmdStorageMirrorDemote struct {
+	global        *cmdGlobal
+	storageMirror *cmdStorageMirror
+}
+
+func (c *cmdStorageMirrorDemote) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("demote", i18n.G("[<remote>:]<pool>"))
+	cmd.Short = i18n.G("Make the mirrored volumes of a storage pool non-primary")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Make the mirrored volumes of a storage pool non-primary
+
+All instances using the storage pool must be stopped first.
+The volumes can then be promoted on the peer cluster.`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdStorageMirrorDemote) Run(cmd *cobra.Command, args []string) error {
+	err := c.storageMirror.run(cmd, args, api.StoragePoolMirrorPost{Action: "demote"})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage pool %s demoted")+"\n", args[0])
+	}
+
+	return nil
+}
+
+// Promote.
+type cmdStorageMirrorPromote struct {
+	global        *cmdGlobal
+	storageMirror *cmdStorageMirror
+
+	flagForce bool
+}
+
+func (c *cmdStorageMirrorPromote) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("promote", i18n.G("[<remote>:]<pool>"))
+	cmd.Short = i18n.G("Make the mirrored volumes of a storage pool primary")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Make the mirrored volumes of a storage pool primary
+
+Use --force to promote the volumes when the peer cluster is unavailable and couldn't demote them.
+Changes which weren't replicated yet are lost.`))
+
+	cmd.Flags().BoolVarP(&c.flagForce, "force", "f", false, i18n.G("Promote the volumes even if they weren't demoted on the peer cluster"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdStorageMirrorPromote) Run(cmd *cobra.Command, args []string) error {
+	err := c.storageMirror.run(cmd, args, api.StoragePoolMirrorPost{Action: "promote", Force: c.flagForce})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage pool %s promoted")+"\n", args[0])
+	}
+
+	return nil
+}
+
+// Show.
+type cmdStorageMirrorShow struct {
+	global        *cmdGlobal
+	storageMirror *cmdStorageMirror
+}
+
+func (c *cmdStorageMirrorShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<pool>"))
+	cmd.Short = i18n.G("Show the mirroring state of a storage pool")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the mirroring state of a storage pool`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdStorageMirrorShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	state, err := resource.server.GetStoragePoolMirrorState(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&state)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
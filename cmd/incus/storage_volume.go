@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 	"slices"
 	"sort"
 	"strconv"
@@ -78,6 +79,10 @@ Unless specified through a prefix, all volume operations affect "custom" (user c
 	storageVolumeDeleteCmd := cmdStorageVolumeDelete{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeDeleteCmd.Command())
 
+	// Demote
+	storageVolumeDemoteCmd := cmdStorageVolumeDemote{global: c.global, storage: c.storage, storageVolume: c}
+	cmd.AddCommand(storageVolumeDemoteCmd.Command())
+
 	// Detach
 	storageVolumeDetachCmd := cmdStorageVolumeDetach{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeDetachCmd.Command())
@@ -118,6 +123,10 @@ Unless specified through a prefix, all volume operations affect "custom" (user c
 	storageVolumeMoveCmd := cmdStorageVolumeMove{global: c.global, storage: c.storage, storageVolume: c, storageVolumeCopy: &storageVolumeCopyCmd, storageVolumeRename: &storageVolumeRenameCmd}
 	cmd.AddCommand(storageVolumeMoveCmd.Command())
 
+	// Promote
+	storageVolumePromoteCmd := cmdStorageVolumePromote{global: c.global, storage: c.storage, storageVolume: c}
+	cmd.AddCommand(storageVolumePromoteCmd.Command())
+
 	// Set
 	storageVolumeSetCmd := cmdStorageVolumeSet{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeSetCmd.Command())
@@ -130,6 +139,10 @@ Unless specified through a prefix, all volume operations affect "custom" (user c
 	storageVolumeSnapshotCmd := cmdStorageVolumeSnapshot{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeSnapshotCmd.Command())
 
+	// Transfer
+	storageVolumeTransferCmd := cmdStorageVolumeTransfer{global: c.global, storage: c.storage, storageVolume: c}
+	cmd.AddCommand(storageVolumeTransferCmd.Command())
+
 	// Unset
 	storageVolumeUnsetCmd := cmdStorageVolumeUnset{global: c.global, storage: c.storage, storageVolume: c, storageVolumeSet: &storageVolumeSetCmd}
 	cmd.AddCommand(storageVolumeUnsetCmd.Command())
@@ -149,6 +162,41 @@ func (c *cmdStorageVolume) parseVolumeWithPool(name string) (string, string) {
 	return fields[1], fields[0]
 }
 
+// updateMirror promotes or demotes the mirrored storage volume given as argument.
+func (c *cmdStorageVolume) updateMirror(cmd *cobra.Command, args []string, req api.StorageVolumeMirrorPost) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	client := resource.server
+
+	// Parse the input
+	volName, volType := parseVolume("custom", args[1])
+	if volType != "custom" && volType != "container" && volType != "virtual-machine" {
+		return fmt.Errorf(i18n.G("Only instance or custom volumes are supported"))
+	}
+
+	// If a target was specified, use the volume on the given member.
+	if c.storage.flagTarget != "" {
+		client = client.UseTarget(c.storage.flagTarget)
+	}
+
+	return client.UpdateStoragePoolVolumeMirror(resource.name, volType, volName, req)
+}
+
 // Attach.
 type cmdStorageVolumeAttach struct {
 	global        *cmdGlobal
@@ -351,6 +399,7 @@ type cmdStorageVolumeCopy struct {
 	flagVolumeOnly    bool
 	flagTargetProject string
 	flagRefresh       bool
+	flagLive          bool
 }
 
 func (c *cmdStorageVolumeCopy) Command() *cobra.Command {
@@ -503,6 +552,7 @@ func (c *cmdStorageVolumeCopy) Run(cmd *cobra.Command, args []string) error {
 		args.Mode = mode
 		args.VolumeOnly = false
 		args.Project = c.flagTargetProject
+		args.Live = c.flagLive
 
 		op, err = dstServer.MoveStoragePoolVolume(dstVolPool, srcServer, srcVolPool, *srcVol, args)
 		if err != nil {
@@ -722,6 +772,60 @@ func (c *cmdStorageVolumeDelete) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Demote.
+type cmdStorageVolumeDemote struct {
+	global        *cmdGlobal
+	storage       *cmdStorage
+	storageVolume *cmdStorageVolume
+}
+
+func (c *cmdStorageVolumeDemote) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("demote", i18n.G("[<remote>:]<pool> [<type>/]<volume>"))
+	cmd.Short = i18n.G("Make mirrored storage volumes non-primary")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Make mirrored storage volumes non-primary
+
+The volume must not be in use by running instances.
+It can then be promoted on the peer cluster.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus storage volume demote default data
+    Demotes the custom volume "data" in pool "default".
+
+incus storage volume demote default virtual-machine/v1
+    Demotes the volume of the virtual machine "v1" in pool "default".`))
+
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpStoragePoolVolumes(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdStorageVolumeDemote) Run(cmd *cobra.Command, args []string) error {
+	err := c.storageVolume.updateMirror(cmd, args, api.StorageVolumeMirrorPost{Action: "demote"})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage volume %s demoted")+"\n", args[1])
+	}
+
+	return nil
+}
+
 // Detach.
 type cmdStorageVolumeDetach struct {
 	global        *cmdGlobal
@@ -1414,6 +1518,24 @@ func (c *cmdStorageVolumeInfo) Run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if volState != nil && volState.Mirror != nil {
+		role := i18n.G("secondary")
+		if volState.Mirror.Primary {
+			role = i18n.G("primary")
+		}
+
+		fmt.Printf(i18n.G("Mirror: %s (%s, %s)")+"\n", volState.Mirror.State, volState.Mirror.Mode, role)
+	}
+
+	if volState != nil && volState.IO != nil {
+		fmt.Printf(i18n.G("Reads: %d (%s)")+"\n", volState.IO.ReadOps, units.GetByteSizeStringIEC(int64(volState.IO.ReadBytes), 2))
+		fmt.Printf(i18n.G("Writes: %d (%s)")+"\n", volState.IO.WriteOps, units.GetByteSizeStringIEC(int64(volState.IO.WriteBytes), 2))
+
+		if volState.IO.ReadLatency > 0 || volState.IO.WriteLatency > 0 {
+			fmt.Printf(i18n.G("Latency: %dµs read, %dµs write")+"\n", volState.IO.ReadLatency, volState.IO.WriteLatency)
+		}
+	}
+
 	if !vol.CreatedAt.IsZero() {
 		fmt.Printf(i18n.G("Created: %s")+"\n", vol.CreatedAt.Local().Format(dateLayout))
 	}
@@ -1743,16 +1865,27 @@ type cmdStorageVolumeMove struct {
 
 func (c *cmdStorageVolumeMove) Command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("move", i18n.G("[<remote>:]<pool>/<volume> [<remote>:]<pool>/<volume>"))
+	cmd.Use = usage("move", i18n.G("[<remote>:]<pool>/[<type>/]<volume> [<remote>:]<pool>/<volume>"))
 	cmd.Aliases = []string{"mv"}
 	cmd.Short = i18n.G("Move storage volumes between pools")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Move storage volumes between pools`))
+		`Move storage volumes between pools
+
+Use --live to move a volume to another pool on the same server while the instances using it keep running.
+Custom volumes are copied, then the instances using them are briefly frozen while the volume is switched over.
+Instance volumes are copied, then the instance is restarted while the remaining changes are synced.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus storage volume move default/data fast --live
+    Moves the custom volume "data" from pool "default" to pool "fast" while the instances using it keep running.
+
+incus storage volume move default/virtual-machine/v1 fast --live
+    Moves the root disk of the running virtual machine "v1" from pool "default" to pool "fast".`))
 
 	cmd.Flags().StringVar(&c.storageVolumeCopy.flagMode, "mode", "pull", i18n.G("Transfer mode, one of pull (default), push or relay")+"``")
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.Flags().StringVar(&c.storageVolume.flagDestinationTarget, "destination-target", "", i18n.G("Destination cluster member name")+"``")
 	cmd.Flags().StringVar(&c.storageVolumeCopy.flagTargetProject, "target-project", "", i18n.G("Move to a project different from the source")+"``")
+	cmd.Flags().BoolVar(&c.storageVolumeCopy.flagLive, "live", false, i18n.G("Move the volume while the instances using it keep running"))
 	cmd.RunE = c.Run
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -1809,6 +1942,14 @@ func (c *cmdStorageVolumeMove) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("No storage pool for target volume specified"))
 	}
 
+	// Instance volumes are moved along with their instance.
+	instName, volType := parseVolume("custom", srcVolName)
+	if volType == "container" || volType == "virtual-machine" {
+		return c.moveInstanceVolume(srcResource.server, instName, srcRemote, srcVolPool, dstRemote, dstVolPool)
+	} else if volType != "custom" {
+		return fmt.Errorf(i18n.G("Only instance or custom volumes are supported"))
+	}
+
 	// Rename volume if both remotes and pools of source and target are equal
 	// and neither destination cluster member name nor target project are set.
 	if srcRemote == dstRemote && srcVolPool == dstVolPool && c.storageVolume.flagDestinationTarget == "" && c.storageVolumeCopy.flagTargetProject == "" {
@@ -1828,6 +1969,110 @@ func (c *cmdStorageVolumeMove) Run(cmd *cobra.Command, args []string) error {
 	return c.storageVolumeCopy.Run(cmd, args)
 }
 
+// moveInstanceVolume moves the root disk of an instance to another storage pool on the same server.
+func (c *cmdStorageVolumeMove) moveInstanceVolume(server incus.InstanceServer, instName string, srcRemote string, srcPool string, dstRemote string, dstPool string) error {
+	if srcRemote != dstRemote || c.storageVolume.flagDestinationTarget != "" || c.storageVolumeCopy.flagTargetProject != "" {
+		return fmt.Errorf(i18n.G("Instance volumes can only be moved to another pool on the same server"))
+	}
+
+	if srcPool == dstPool {
+		return fmt.Errorf(i18n.G("Instance volumes cannot be renamed"))
+	}
+
+	if c.storage.flagTarget != "" {
+		server = server.UseTarget(c.storage.flagTarget)
+	}
+
+	op, err := server.MigrateInstance(instName, api.InstancePost{
+		Migration: true,
+		Pool:      dstPool,
+		Live:      c.storageVolumeCopy.flagLive,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Register progress handler
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Moving the storage volume: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// Wait for operation to finish
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done(i18n.G("Storage volume moved successfully!"))
+
+	return nil
+}
+
+// Promote.
+type cmdStorageVolumePromote struct {
+	global        *cmdGlobal
+	storage       *cmdStorage
+	storageVolume *cmdStorageVolume
+
+	flagForce bool
+}
+
+func (c *cmdStorageVolumePromote) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("promote", i18n.G("[<remote>:]<pool> [<type>/]<volume>"))
+	cmd.Short = i18n.G("Make mirrored storage volumes primary")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Make mirrored storage volumes primary
+
+Use --force to promote the volume when the peer cluster is unavailable and couldn't demote it.
+Changes which weren't replicated yet are lost.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus storage volume promote default data
+    Promotes the custom volume "data" in pool "default".
+
+incus storage volume promote default virtual-machine/v1 --force
+    Promotes the volume of the virtual machine "v1" in pool "default", even if it wasn't demoted on the peer cluster.`))
+
+	cmd.Flags().BoolVarP(&c.flagForce, "force", "f", false, i18n.G("Promote the volume even if it wasn't demoted on the peer cluster"))
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpStoragePoolVolumes(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdStorageVolumePromote) Run(cmd *cobra.Command, args []string) error {
+	err := c.storageVolume.updateMirror(cmd, args, api.StorageVolumeMirrorPost{Action: "promote", Force: c.flagForce})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage volume %s promoted")+"\n", args[1])
+	}
+
+	return nil
+}
+
 // Rename.
 type cmdStorageVolumeRename struct {
 	global        *cmdGlobal
@@ -2167,6 +2412,105 @@ func (c *cmdStorageVolumeShow) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Transfer.
+type cmdStorageVolumeTransfer struct {
+	global        *cmdGlobal
+	storage       *cmdStorage
+	storageVolume *cmdStorageVolume
+}
+
+func (c *cmdStorageVolumeTransfer) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("transfer", i18n.G("[<remote>:]<pool>/<volume> [<target>]"))
+	cmd.Short = i18n.G("Send custom storage volumes to external targets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Send custom storage volumes to external targets
+
+The target is a ZFS dataset on a remote host, reached over SSH, of the form zfs://[user@]host[:port]/dataset.
+It defaults to the zfs.transfer.target configuration of the volume.
+
+The volume is sent as an incremental stream when the dataset already holds the previous transfer.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus storage volume transfer default/data zfs://root@backup01/tank/backup/data
+    Send the "data" volume of the "default" pool to the tank/backup/data dataset of backup01.`))
+
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePoolWithVolume(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdStorageVolumeTransfer) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing source volume name"))
+	}
+
+	client := resource.server
+
+	volName, poolName := c.storageVolume.parseVolumeWithPool(resource.name)
+	if poolName == "" {
+		return fmt.Errorf(i18n.G("No storage pool for source volume specified"))
+	}
+
+	// If a target member was specified, send the volume with the matching name on that member, if any.
+	if c.storage.flagTarget != "" {
+		client = client.UseTarget(c.storage.flagTarget)
+	}
+
+	req := api.StorageVolumeTransferPost{}
+	if len(args) > 1 {
+		req.Target = args[1]
+	}
+
+	op, err := client.TransferStoragePoolVolume(poolName, volName, req)
+	if err != nil {
+		return err
+	}
+
+	// Watch the background operation
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Transferring storage volume: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done(i18n.G("Storage volume transferred successfully!"))
+
+	return nil
+}
+
 // Unset.
 type cmdStorageVolumeUnset struct {
 	global           *cmdGlobal
@@ -3035,7 +3379,9 @@ type cmdStorageVolumeImport struct {
 	storage       *cmdStorage
 	storageVolume *cmdStorageVolume
 
-	flagType string
+	flagType     string
+	flagAttachTo string
+	flagDevice   string
 }
 
 func (c *cmdStorageVolumeImport) Command() *cobra.Command {
@@ -3043,13 +3389,23 @@ func (c *cmdStorageVolumeImport) Command() *cobra.Command {
 	cmd.Use = usage("import", i18n.G("[<remote>:]<pool> <backup file> [<volume name>]"))
 	cmd.Short = i18n.G("Import custom storage volumes")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Import backups of custom volumes including their snapshots.`))
+		`Import backups of custom volumes including their snapshots, ISO images or disk images.
+
+Disk images (qcow2, raw, VDI, VHD, VHDX or VMDK) are converted into block custom volumes.
+If no volume name is given, it's taken from the file name.
+
+ISO and disk images can be attached to an instance right away with --attach-to.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`incus storage volume import default backup0.tar.gz
-		Create a new custom volume using backup0.tar.gz as the source.`))
+		Create a new custom volume using backup0.tar.gz as the source.
+
+incus storage volume import default data.qcow2 --attach-to vm1 --device data1
+		Create a new block custom volume "data" from data.qcow2 and attach it to vm1 as device "data1".`))
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.RunE = c.Run
-	cmd.Flags().StringVar(&c.flagType, "type", "", i18n.G("Import type, backup or iso (default \"backup\")")+"``")
+	cmd.Flags().StringVar(&c.flagType, "type", "", i18n.G("Import type, backup, iso or disk (default \"backup\")")+"``")
+	cmd.Flags().StringVar(&c.flagAttachTo, "attach-to", "", i18n.G("Instance to attach the imported volume to")+"``")
+	cmd.Flags().StringVar(&c.flagDevice, "device", "", i18n.G("Name of the device to attach the imported volume as (default to the volume name)")+"``")
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -3104,24 +3460,41 @@ func (c *cmdStorageVolumeImport) Run(cmd *cobra.Command, args []string) error {
 		volName = args[2]
 	}
 
+	diskImageExtensions := []string{".img", ".qcow2", ".raw", ".vdi", ".vhd", ".vhdx", ".vmdk"}
+
 	if c.flagType == "" {
-		// Set type to iso if filename suffix is .iso
+		// Set type to iso if filename suffix is .iso, and to disk for disk image suffixes.
 		if strings.HasSuffix(file.Name(), ".iso") {
 			c.flagType = "iso"
+		} else if slices.Contains(diskImageExtensions, strings.ToLower(filepath.Ext(file.Name()))) {
+			c.flagType = "disk"
 		} else {
 			c.flagType = "backup"
 		}
 	} else {
 		// Validate type flag
-		if !slices.Contains([]string{"backup", "iso"}, c.flagType) {
-			return fmt.Errorf(i18n.G("Import type needs to be \"backup\" or \"iso\""))
+		if !slices.Contains([]string{"backup", "iso", "disk"}, c.flagType) {
+			return fmt.Errorf(i18n.G("Import type needs to be \"backup\", \"iso\" or \"disk\""))
 		}
 	}
 
+	// Name disk image volumes after the file by default.
+	if c.flagType == "disk" && volName == "" {
+		volName = strings.TrimSuffix(filepath.Base(file.Name()), filepath.Ext(file.Name()))
+	}
+
 	if c.flagType == "iso" && volName == "" {
 		return fmt.Errorf(i18n.G("Importing ISO images requires a volume name to be set"))
 	}
 
+	if c.flagAttachTo != "" && c.flagType == "backup" {
+		return fmt.Errorf(i18n.G("Only ISO and disk images can be attached on import"))
+	}
+
+	if c.flagDevice != "" && c.flagAttachTo == "" {
+		return fmt.Errorf(i18n.G("--device requires --attach-to"))
+	}
+
 	progress := cli.ProgressRenderer{
 		Format: i18n.G("Importing custom volume: %s"),
 		Quiet:  c.global.flagQuiet,
@@ -3137,14 +3510,19 @@ func (c *cmdStorageVolumeImport) Run(cmd *cobra.Command, args []string) error {
 				},
 			},
 		},
-		Name: volName,
+		Name:       volName,
+		AttachTo:   c.flagAttachTo,
+		DeviceName: c.flagDevice,
 	}
 
 	var op incus.Operation
 
-	if c.flagType == "iso" {
+	switch c.flagType {
+	case "iso":
 		op, err = d.CreateStoragePoolVolumeFromISO(pool, createArgs)
-	} else {
+	case "disk":
+		op, err = d.CreateStoragePoolVolumeFromDiskImage(pool, createArgs)
+	default:
 		op, err = d.CreateStoragePoolVolumeFromBackup(pool, createArgs)
 	}
 
@@ -3152,6 +3530,13 @@ func (c *cmdStorageVolumeImport) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Register progress handler.
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
 	// Wait for operation to finish.
 	err = cli.CancelableWait(op, &progress)
 	if err != nil {
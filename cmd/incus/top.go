@@ -10,11 +10,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/spf13/cobra"
 
 	"github.com/lxc/incus/v6/client"
 	cli "github.com/lxc/incus/v6/internal/cmd"
 	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/units"
 )
 
@@ -37,8 +39,9 @@ func (c *cmdTop) Command() *cobra.Command {
 }
 
 // Run is a method of the cmdTop structure. It implements the logic to call `incus top`.
-// This function implements the `top` command. It queries the metrics API at (/1.0/metrics) and renders a list of
-// instances with their CPU, memory and disk usage columns.
+// This function implements the `top` command. It streams the resource usage from the instances API, or queries the
+// metrics API at (/1.0/metrics) on older servers, and renders a list of instances with their CPU, memory and disk
+// usage columns.
 func (c *cmdTop) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
 
@@ -66,6 +69,11 @@ func (c *cmdTop) Run(cmd *cobra.Command, args []string) error {
 	refreshInterval := 5 * time.Second // default 5 seconds, could change this to a flag
 	sortingMethod := alphabetical      // default is alphabetical, could change this to a flag
 
+	// Use the resource usage stream when the server supports it.
+	if d.HasExtension("instance_state_stream") {
+		return c.runStream(d, refreshInterval, sortingMethod)
+	}
+
 	// Start the ticker for periodic updates
 	ticker := time.NewTicker(refreshInterval)
 	defer ticker.Stop()
@@ -128,6 +136,104 @@ func (c *cmdTop) Run(cmd *cobra.Command, args []string) error {
 	}
 }
 
+// runStream renders the resource usage received from the server stream until interrupted.
+func (c *cmdTop) runStream(d incus.InstanceServer, refreshInterval time.Duration, sortingMethod sortType) error {
+	messageChannel := make(chan api.InstanceStateStream)
+	errorChannel := make(chan error, 1)
+
+	// connect opens a stream at the given interval, the server only supporting whole seconds up to a minute.
+	connect := func(interval time.Duration) (*websocket.Conn, error) {
+		interval = min(max(interval.Round(time.Second), time.Second), time.Minute)
+
+		conn, err := d.GetInstancesStateStream(false, interval)
+		if err != nil {
+			return nil, err
+		}
+
+		go func() {
+			for {
+				message := api.InstanceStateStream{}
+				err := conn.ReadJSON(&message)
+				if err != nil {
+					errorChannel <- err
+					return
+				}
+
+				messageChannel <- message
+			}
+		}()
+
+		return conn, nil
+	}
+
+	conn, err := connect(refreshInterval)
+	if err != nil {
+		return err
+	}
+
+	durationChannel := make(chan time.Duration)
+	sortingChannel := make(chan sortType)
+	interruptChannel := make(chan bool)
+
+	go handleKeystrokes(durationChannel, interruptChannel, sortingChannel) // Handles shortcuts on a separate Goroutine
+
+	paused := false
+	for {
+		select {
+		case shouldStop := <-interruptChannel: // This pauses the UI refresh
+			paused = shouldStop
+
+		case message := <-messageChannel:
+			if paused {
+				continue
+			}
+
+			err = c.updateStreamDisplay(message, refreshInterval, sortingMethod)
+			if err != nil {
+				return err
+			}
+
+		case err := <-errorChannel:
+			return err
+
+		case sortType, ok := <-sortingChannel:
+			if !ok {
+				return nil // Exits if the channel is closed
+			}
+
+			sortingMethod = sortType
+
+		case duration, ok := <-durationChannel:
+			if !ok {
+				return nil // Exits if the channel is closed
+			}
+
+			// Replace the stream with one at the new interval.
+			oldConn := conn
+			conn = nil
+			_ = oldConn.Close()
+
+			// Drain the messages and error of the old stream.
+			for done := false; !done; {
+				select {
+				case <-messageChannel:
+				case <-errorChannel:
+					done = true
+				}
+			}
+
+			conn, err = connect(duration)
+			if err != nil {
+				return err
+			}
+
+			refreshInterval = duration
+			paused = false
+			fmt.Printf(i18n.G("Updated interval to %v")+"\n", duration)
+		}
+	}
+}
+
 func handleKeystrokes(durationChannel chan time.Duration, interruptChannel chan bool, sortingChannel chan sortType) {
 	reader := bufio.NewReader(os.Stdin)
 
@@ -298,6 +404,64 @@ func (c *cmdTop) updateDisplay(d incus.InstanceServer, refreshInterval time.Dura
 	return nil
 }
 
+func (c *cmdTop) updateStreamDisplay(message api.InstanceStateStream, refreshInterval time.Duration, sortingType sortType) error {
+	data := make([]displayData, 0, len(message.Instances))
+	network := make(map[string][2]int64, len(message.Instances))
+	for _, usage := range message.Instances {
+		if usage.Status != api.Running.String() || usage.Error != "" {
+			continue
+		}
+
+		cpuPercent := 0.0
+		if message.Interval > 0 {
+			cpuPercent = float64(usage.CPUUsage) / float64(message.Interval*int64(time.Millisecond)) * 100
+		}
+
+		data = append(data, displayData{
+			instanceName: usage.Name,
+			cpuUsage:     cpuPercent,
+			memoryUsage:  float64(usage.MemoryUsage),
+			diskUsage:    float64(usage.DiskUsage),
+		})
+
+		// Convert the network deltas to rates per second.
+		seconds := max(float64(message.Interval)/1000, 1)
+		network[usage.Name] = [2]int64{int64(float64(usage.NetworkBytesReceived) / seconds), int64(float64(usage.NetworkBytesSent) / seconds)}
+	}
+
+	// Perform sort operation
+	sortBySortingType(data, sortingType)
+
+	dataFormatted := make([][]string, len(data))
+	for i := range data {
+		dataFormatted[i] = []string{
+			data[i].instanceName,
+			fmt.Sprintf("%.2f", data[i].cpuUsage),
+			units.GetByteSizeStringIEC(int64(data[i].memoryUsage), 2),
+			units.GetByteSizeStringIEC(int64(data[i].diskUsage), 2),
+			units.GetByteSizeStringIEC(network[data[i].instanceName][0], 2) + "/s",
+			units.GetByteSizeStringIEC(network[data[i].instanceName][1], 2) + "/s",
+		}
+	}
+
+	headers := []string{i18n.G("INSTANCE NAME"), i18n.G("CPU(%)"), i18n.G("MEMORY"), i18n.G("DISK"), i18n.G("NETWORK RX"), i18n.G("NETWORK TX")}
+
+	fmt.Print("\033[H\033[2J") // Clear the terminal on each message
+	err := cli.RenderTable("table", headers, dataFormatted, nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(i18n.G("Press 'd' + ENTER to change delay"))
+	fmt.Println(i18n.G("Press 's' + ENTER to change sorting method"))
+	fmt.Println(i18n.G("Press CTRL-C to exit"))
+	fmt.Println()
+	fmt.Println(i18n.G("Delay:"), refreshInterval)
+	fmt.Println(i18n.G("Sorting Method:"), sortingType)
+
+	return nil
+}
+
 type sample struct {
 	labels map[string]string
 	value  float64
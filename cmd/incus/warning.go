@@ -38,6 +38,10 @@ func (c *cmdWarning) Command() *cobra.Command {
 	warningAcknowledgeCmd := cmdWarningAcknowledge{global: c.global, warning: c}
 	cmd.AddCommand(warningAcknowledgeCmd.Command())
 
+	// Resolve
+	warningResolveCmd := cmdWarningResolve{global: c.global, warning: c}
+	cmd.AddCommand(warningResolveCmd.Command())
+
 	// Show
 	warningShowCmd := cmdWarningShow{global: c.global, warning: c}
 	cmd.AddCommand(warningShowCmd.Command())
@@ -291,6 +295,90 @@ func (c *cmdWarningAcknowledge) Run(cmd *cobra.Command, args []string) error {
 	return remoteServer.UpdateWarning(UUID, warning, "")
 }
 
+// Resolve.
+type cmdWarningResolve struct {
+	global  *cmdGlobal
+	warning *cmdWarning
+
+	flagApply bool
+}
+
+func (c *cmdWarningResolve) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("resolve", i18n.G("[<remote>:]<warning-uuid>"))
+	cmd.Short = i18n.G("Show or apply the fix for a warning")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show or apply the fix for a warning
+
+Without --apply, this only describes how the warning can be addressed.
+With --apply, the server applies the fix itself when it is known to be safe
+and marks the warning as resolved.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus warning resolve 39c61a48-cc17-40ae-8248-4f7b4cadedf4
+    Show how the warning can be addressed.
+
+incus warning resolve 39c61a48-cc17-40ae-8248-4f7b4cadedf4 --apply
+    Apply the fix and resolve the warning.`))
+
+	cmd.Flags().BoolVar(&c.flagApply, "apply", false, i18n.G("Apply the fix"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdWarningResolve) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remoteName, UUID, err := c.global.conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	remoteServer, err := c.global.conf.GetInstanceServer(remoteName)
+	if err != nil {
+		return err
+	}
+
+	warning, _, err := remoteServer.GetWarning(UUID)
+	if err != nil {
+		return err
+	}
+
+	if warning.Remediation == nil {
+		return fmt.Errorf(i18n.G("No known fix for warning %q"), UUID)
+	}
+
+	if !c.flagApply {
+		fmt.Println(warning.Remediation.Description)
+
+		if warning.Remediation.Action != "" {
+			fmt.Printf(i18n.G("Use --apply to run the %q action")+"\n", warning.Remediation.Action)
+		}
+
+		return nil
+	}
+
+	if warning.Remediation.Action == "" {
+		return fmt.Errorf(i18n.G("Warning %q must be fixed manually: %s"), UUID, warning.Remediation.Description)
+	}
+
+	err = remoteServer.ResolveWarning(UUID)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Warning %s resolved")+"\n", UUID)
+	}
+
+	return nil
+}
+
 // Show.
 type cmdWarningShow struct {
 	global  *cmdGlobal
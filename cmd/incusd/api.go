@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -366,8 +369,16 @@ func (s *httpServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if !strings.HasPrefix(req.URL.Path, "/internal") {
 		<-s.d.setupChan
 
+		config := s.d.State().GlobalConfig
+
+		// Strip the API path prefix used by reverse proxies, if any.
+		prefix := config.HTTPSPathPrefix()
+		if prefix != "" && stripPathPrefix(req, prefix) {
+			rw = &prefixResponseWriter{ResponseWriter: rw, prefix: prefix}
+		}
+
 		// Set CORS headers, unless this is an internal request.
-		setCORSHeaders(rw, req, s.d.State().GlobalConfig)
+		setCORSHeaders(rw, req, config)
 	}
 
 	// OPTIONS request don't need any further processing
@@ -379,6 +390,86 @@ func (s *httpServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	s.r.ServeHTTP(rw, req)
 }
 
+// stripPathPrefix removes the given prefix from the path of the request.
+// It returns false if the request doesn't use the prefix.
+func stripPathPrefix(req *http.Request, prefix string) bool {
+	if req.URL.Path != prefix && !strings.HasPrefix(req.URL.Path, prefix+"/") {
+		return false
+	}
+
+	req.URL.Path = strings.TrimPrefix(req.URL.Path, prefix)
+	if req.URL.Path == "" {
+		req.URL.Path = "/"
+	}
+
+	if req.URL.RawPath != "" {
+		rawPath, found := strings.CutPrefix(req.URL.RawPath, prefix)
+		if found && rawPath != "" {
+			req.URL.RawPath = rawPath
+		} else {
+			req.URL.RawPath = ""
+		}
+	}
+
+	return true
+}
+
+// prefixResponseWriter adds the API path prefix to the Location header of the responses
+// to requests that came through a reverse proxy, and provides it to the responses holding URLs.
+type prefixResponseWriter struct {
+	http.ResponseWriter
+
+	prefix      string
+	wroteHeader bool
+}
+
+func (w *prefixResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+
+		location := w.Header().Get("Location")
+		if strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "//") {
+			w.Header().Set("Location", w.prefix+location)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *prefixResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *prefixResponseWriter) Flush() {
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+func (w *prefixResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Response writer doesn't support hijacking")
+	}
+
+	return hijacker.Hijack()
+}
+
+// PathPrefix returns the API path prefix of the request.
+func (w *prefixResponseWriter) PathPrefix() string {
+	return w.prefix
+}
+
+// Unwrap returns the underlying response writer.
+func (w *prefixResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func setCORSHeaders(rw http.ResponseWriter, req *http.Request, config *clusterConfig.Config) {
 	allowedOrigin := config.HTTPSAllowedOrigin()
 	origin := req.Header.Get("Origin")
@@ -22,7 +22,9 @@ import (
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
 	scriptletLoad "github.com/lxc/incus/v6/internal/server/scriptlet/load"
+	"github.com/lxc/incus/v6/internal/server/state"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
@@ -39,22 +41,37 @@ var api10Cmd = APIEndpoint{
 var api10 = []APIEndpoint{
 	api10Cmd,
 	api10ResourcesCmd,
+	api10ResourcesIdmapCmd,
+	catalogCmd,
 	certificateCmd,
 	certificatesCmd,
 	clusterCmd,
+	clusterConfigRolloutCmd,
 	clusterGroupCmd,
 	clusterGroupsCmd,
 	clusterNodeCmd,
+	clusterNodeManagedRulesCmd,
 	clusterNodeStateCmd,
 	clusterNodesCmd,
 	clusterCertificateCmd,
+	instanceAppArmorCmd,
 	instanceBackupCmd,
 	instanceBackupExportCmd,
 	instanceBackupsCmd,
+	instanceBootLogCmd,
+	instancesBatchCmd,
 	instanceCmd,
 	instanceConsoleCmd,
+	instanceCoreDumpCmd,
+	instanceCoreDumpsCmd,
 	instanceExecCmd,
 	instanceFileCmd,
+	instanceGroupCmd,
+	instanceGroupsCmd,
+	instancePoolCmd,
+	instancePoolsCmd,
+	instancePoolAcquireCmd,
+	instancePoolReleaseCmd,
 	instanceExecOutputCmd,
 	instanceExecOutputsCmd,
 	instanceLogCmd,
@@ -63,7 +80,12 @@ var api10 = []APIEndpoint{
 	instanceMetadataTemplatesCmd,
 	instancesCmd,
 	instanceRebuildCmd,
+	instanceRefreshCmd,
+	instanceRefreshesCmd,
+	instanceConvertCmd,
 	instanceSFTPCmd,
+	instanceSharesCmd,
+	instanceShareCmd,
 	instanceSnapshotCmd,
 	instanceSnapshotsCmd,
 	instanceStateCmd,
@@ -73,6 +95,7 @@ var api10 = []APIEndpoint{
 	imageCmd,
 	imageExportCmd,
 	imageRefreshCmd,
+	imageOptimizeCmd,
 	imagesCmd,
 	imageSecretCmd,
 	metadataConfigurationCmd,
@@ -101,15 +124,22 @@ var api10 = []APIEndpoint{
 	operationWait,
 	operationWebsocket,
 	profileCmd,
+	profilePreviewCmd,
 	profilesCmd,
 	projectCmd,
 	projectsCmd,
 	projectStateCmd,
+	projectIdleInstancesCmd,
+	queryCmd,
+	recycleBinCmd,
+	recycleBinEntryCmd,
 	storagePoolCmd,
 	storagePoolResourcesCmd,
+	storagePoolMirrorCmd,
 	storagePoolsCmd,
 	storagePoolBucketsCmd,
 	storagePoolBucketCmd,
+	storagePoolBucketStateCmd,
 	storagePoolBucketKeysCmd,
 	storagePoolBucketKeyCmd,
 	storagePoolBucketBackupsCmd,
@@ -124,8 +154,11 @@ var api10 = []APIEndpoint{
 	storagePoolVolumeTypeCustomBackupCmd,
 	storagePoolVolumeTypeCustomBackupExportCmd,
 	storagePoolVolumeTypeStateCmd,
+	storagePoolVolumeTypeMirrorCmd,
+	storagePoolVolumeTypeTransferCmd,
 	warningsCmd,
 	warningCmd,
+	warningResolveCmd,
 	metricsCmd,
 }
 
@@ -311,6 +344,7 @@ func api10Get(d *Daemon, r *http.Request) response.Response {
 		ServerEventMode:        string(cluster.ServerEventMode()),
 		ServerName:             serverName,
 		Firewall:               s.Firewall.String(),
+		LXCFS:                  d.lxcfs.Status(),
 	}
 
 	env.KernelFeatures = map[string]string{
@@ -460,6 +494,9 @@ func api10Put(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
+		// Keep the configuration change of a canary member on top.
+		config = clusterConfigCanaryOverlay(config, changed)
+
 		// Update the daemon config.
 		d.globalConfigMu.Lock()
 		d.globalConfig = config
@@ -733,11 +770,40 @@ func doApi10Update(d *Daemon, r *http.Request, req api.ServerPut, patch bool) re
 	})
 
 	// Notify the other nodes about changes
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	err = doApi10NotifyClusterChanged(s, clusterChanged)
 	if err != nil {
 		return response.SmartError(err)
 	}
 
+	// Keep the configuration change of a canary member on top.
+	newClusterConfig = clusterConfigCanaryOverlay(newClusterConfig, clusterChanged)
+
+	// Update the daemon config.
+	d.globalConfigMu.Lock()
+	d.globalConfig = newClusterConfig
+	d.localConfig = newNodeConfig
+	d.globalConfigMu.Unlock()
+
+	// Run any update triggers.
+	err = doApi10UpdateTriggers(d, nodeChanged, clusterChanged, newNodeConfig, newClusterConfig)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	revert.Success()
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.ConfigUpdated.Event(request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// doApi10NotifyClusterChanged notifies the other cluster members about the changed cluster-wide configuration keys.
+func doApi10NotifyClusterChanged(s *state.State, clusterChanged map[string]string) error {
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
 	err = notifier(func(client incus.InstanceServer) error {
 		server, etag, err := client.GetServer()
 		if err != nil {
@@ -755,26 +821,10 @@ func doApi10Update(d *Daemon, r *http.Request, req api.ServerPut, patch bool) re
 	})
 	if err != nil {
 		logger.Error("Failed to notify other members about config change", logger.Ctx{"err": err})
-		return response.SmartError(err)
-	}
-
-	// Update the daemon config.
-	d.globalConfigMu.Lock()
-	d.globalConfig = newClusterConfig
-	d.localConfig = newNodeConfig
-	d.globalConfigMu.Unlock()
-
-	// Run any update triggers.
-	err = doApi10UpdateTriggers(d, nodeChanged, clusterChanged, newNodeConfig, newClusterConfig)
-	if err != nil {
-		return response.SmartError(err)
+		return err
 	}
 
-	revert.Success()
-
-	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.ConfigUpdated.Event(request.CreateRequestor(r), nil))
-
-	return response.EmptySyncResponse
+	return nil
 }
 
 func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]string, nodeConfig *node.Config, clusterConfig *clusterConfig.Config) error {
@@ -782,24 +832,33 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 
 	acmeChanged := false
 	bgpChanged := false
+	coreDumpsChanged := false
+	limitsReserveChanged := false
+	lxcfsChanged := false
 	dnsChanged := false
+	edgeChanged := false
 	lokiChanged := false
 	oidcChanged := false
 	openFGAChanged := false
 	ovnChanged := false
 	syslogChanged := false
+	webhooksChanged := false
+	imagesReplicationChanged := false
 
 	for key := range clusterChanged {
 		switch key {
 		case "acme.ca_url", "acme.domain":
 			acmeChanged = true
 
-		case "cluster.images_minimal_replica":
-			err := autoSyncImages(s.ShutdownCtx, s)
+		case "api.rate_limit.address", "api.rate_limit.address.burst", "api.rate_limit.identity", "api.rate_limit.identity.burst":
+			err := d.setupAPIRateLimits(clusterConfig.APIRateLimits())
 			if err != nil {
-				logger.Warn("Could not auto-sync images", logger.Ctx{"err": err})
+				return err
 			}
 
+		case "cluster.images_minimal_replica", "cluster.images_replication":
+			imagesReplicationChanged = true
+
 		case "cluster.offline_threshold":
 			d.gateway.HeartbeatOfflineThreshold = clusterConfig.OfflineThreshold()
 			d.taskClusterHeartbeat.Reset()
@@ -813,6 +872,24 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		case "core.proxy_http", "core.proxy_https", "core.proxy_ignore_hosts":
 			daemonConfigSetProxy(d, clusterConfig)
 
+		case "core.tracing.endpoint", "core.tracing.headers", "core.tracing.ca_cert", "core.tracing.sample_ratio":
+			err := d.setupTracing(clusterConfig.Tracing())
+			if err != nil {
+				return err
+			}
+
+		case "events.journal.size":
+			err := s.Events.SetJournal(internalUtil.VarPath("events.journal"), clusterConfig.EventsJournalSize())
+			if err != nil {
+				return err
+			}
+
+		case "events.lifecycle.enrichment":
+			lifecycle.SetInstanceEnrichment(clusterConfig.EventsLifecycleEnrichment())
+
+		case "events.webhooks.urls", "events.webhooks.actions", "events.webhooks.payload", "events.webhooks.secret", "events.webhooks.retries":
+			webhooksChanged = true
+
 		case "images.auto_update_interval", "images.remote_cache_expiry":
 			if !s.OS.MockMode {
 				d.taskPruneImages.Reset()
@@ -829,6 +906,11 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 
 		case "openfga.api.url", "openfga.api.token", "openfga.store.id":
 			openFGAChanged = true
+
+		case "prometheus.remote_write.url", "prometheus.remote_write.interval":
+			if !s.OS.MockMode {
+				d.taskMetricsRemoteWrite.Reset()
+			}
 		}
 	}
 
@@ -842,6 +924,18 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 
 		case "core.syslog_socket":
 			syslogChanged = true
+
+		case "core.coredumps":
+			coreDumpsChanged = true
+
+		case "limits.reserve.cpu", "limits.reserve.memory":
+			limitsReserveChanged = true
+
+		case "lxcfs.managed", "lxcfs.loadavg", "lxcfs.cpuview":
+			lxcfsChanged = true
+
+		case "cluster.edge":
+			edgeChanged = true
 		}
 	}
 
@@ -917,6 +1011,23 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	// Update the firewall rules required by the services after their listeners.
+	servicesChanged := false
+	for _, changed := range []map[string]string{nodeChanged, clusterChanged} {
+		for key := range changed {
+			if firewallServicesConfigKey(key) {
+				servicesChanged = true
+			}
+		}
+	}
+
+	if servicesChanged {
+		err := firewallServicesUpdate(d.State())
+		if err != nil {
+			return err
+		}
+	}
+
 	if bgpChanged {
 		address := nodeConfig.BGPAddress()
 		asn := clusterConfig.BGPASN()
@@ -950,6 +1061,22 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	if imagesReplicationChanged {
+		err := autoSyncImages(s.ShutdownCtx, s)
+		if err != nil {
+			logger.Warn("Could not auto-sync images", logger.Ctx{"err": err})
+		}
+	}
+
+	if webhooksChanged {
+		webhookURLs, webhookActions, webhookPayload, webhookSecret, webhookRetries := clusterConfig.EventsWebhooks()
+
+		err := d.setupWebhooks(webhookURLs, webhookActions, webhookPayload, webhookSecret, webhookRetries)
+		if err != nil {
+			return err
+		}
+	}
+
 	if oidcChanged {
 		oidcIssuer, oidcClientID, oidcAudience, oidcClaim := clusterConfig.OIDCServer()
 
@@ -986,6 +1113,38 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	if coreDumpsChanged {
+		err := d.setupCoreDumps(nodeConfig.CoreDumps())
+		if err != nil {
+			return err
+		}
+	}
+
+	if limitsReserveChanged {
+		err := d.setupLimitsReserve(nodeConfig.LimitsReserve())
+		if err != nil {
+			return err
+		}
+	}
+
+	if lxcfsChanged {
+		err := d.setupLXCFS(nodeConfig.LXCFS())
+		if err != nil {
+			return err
+		}
+	}
+
+	if edgeChanged {
+		d.edge.SetEnabled(s.ServerClustered && nodeConfig.ClusterEdge())
+
+		if d.edge.Enabled() {
+			err := edgeCacheUpdate(s.ShutdownCtx, s)
+			if err != nil {
+				return fmt.Errorf("Failed caching edge state: %w", err)
+			}
+		}
+	}
+
 	// Compile and load the instance placement scriptlet.
 	value, ok = clusterChanged["instances.placement.scriptlet"]
 	if ok {
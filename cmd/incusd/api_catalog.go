@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+var catalogCmd = APIEndpoint{
+	Path: "catalog",
+
+	Get: APIEndpointAction{Handler: catalogGet, AccessHandler: allowCatalog, AllowUntrusted: true},
+}
+
+func allowCatalog(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.GlobalConfig.CatalogAuthentication() {
+		return response.EmptySyncResponse
+	}
+
+	return allowAuthenticated(d, r)
+}
+
+// swagger:operation GET /1.0/catalog catalog catalog_get
+//
+//	Get the catalog
+//
+//	Returns the public image aliases and the instance size presets available to launch instances.
+//	This doesn't require authentication when `core.catalog_authentication` is disabled.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Catalog
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/Catalog"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func catalogGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	public := true
+
+	catalog := api.Catalog{
+		Images: []api.CatalogImage{},
+		Sizes:  []api.CatalogSize{},
+	}
+
+	// Callers which can't view the project get the same error as for a missing project, so that the catalog doesn't
+	// reveal which projects exist. The catalog of the default project is public.
+	if projectName != api.ProjectDefaultName {
+		err := s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectProject(projectName), auth.EntitlementCanView)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusForbidden) {
+			return response.SmartError(err)
+		} else if err != nil {
+			return response.SmartError(fmt.Errorf("Failed loading image aliases: %w", api.StatusErrorf(http.StatusNotFound, "Project not found")))
+		}
+	}
+
+	// Only list the aliases of public images, same as for untrusted clients of the images API.
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Projects without their own images use the ones of the default project.
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		projectName = project.ImageProjectFromRecord(p)
+
+		names, err := tx.GetImageAliases(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			_, alias, err := tx.GetImageAlias(ctx, projectName, name, false)
+			if err != nil {
+				if api.StatusErrorCheck(err, http.StatusNotFound) {
+					continue
+				}
+
+				return err
+			}
+
+			_, image, err := tx.GetImage(ctx, alias.Target, dbCluster.ImageFilter{Project: &projectName, Public: &public})
+			if err != nil {
+				if api.StatusErrorCheck(err, http.StatusNotFound) {
+					continue
+				}
+
+				return err
+			}
+
+			catalog.Images = append(catalog.Images, api.CatalogImage{
+				Alias:        alias.Name,
+				Description:  alias.Description,
+				Fingerprint:  image.Fingerprint,
+				Type:         image.Type,
+				Architecture: image.Architecture,
+				Properties:   image.Properties,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading image aliases: %w", err))
+	}
+
+	// List the instance types.
+	for source, types := range instanceTypes {
+		for name, limits := range types {
+			catalog.Sizes = append(catalog.Sizes, api.CatalogSize{
+				Name:   fmt.Sprintf("%s:%s", source, name),
+				CPU:    float64(limits.CPU),
+				Memory: int64(limits.Memory * 1024 * 1024 * 1024),
+			})
+		}
+	}
+
+	sort.Slice(catalog.Sizes, func(i, j int) bool { return catalog.Sizes[i].Name < catalog.Sizes[j].Name })
+
+	return response.SyncResponse(true, catalog)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Projects without their own images list the image aliases of the default project.
+func (suite *containerTestSuite) TestCatalogGetProject() {
+	fingerprint := "c0a9f1e6e8a2c1d4f3b5e7a9c1d3f5b7e9a1c3d5f7b9e1a3c5d7f9b1e3a5c7d9"
+
+	err := suite.d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.CreateImage(ctx, api.ProjectDefaultName, fingerprint, "catalog.tar.xz", 1, true, false, "x86_64", time.Now(), time.Time{}, nil, "container", nil)
+		if err != nil {
+			return err
+		}
+
+		public := true
+		projectName := api.ProjectDefaultName
+		imageID, _, err := tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName, Public: &public})
+		if err != nil {
+			return err
+		}
+
+		err = tx.CreateImageAlias(ctx, api.ProjectDefaultName, "catalog/alias", imageID, "")
+		if err != nil {
+			return err
+		}
+
+		_, err = dbCluster.CreateProject(ctx, tx.Tx(), dbCluster.Project{Name: "catalog"})
+
+		return err
+	})
+	suite.Req.Nil(err)
+
+	// Requests coming over the local socket can view all projects.
+	catalogRequest := func(url string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		ctx := context.WithValue(req.Context(), request.CtxUsername, "")
+		ctx = context.WithValue(ctx, request.CtxProtocol, "unix")
+
+		return req.WithContext(ctx)
+	}
+
+	recorder := httptest.NewRecorder()
+	err = catalogGet(suite.d, catalogRequest("/1.0/catalog?project=catalog")).Render(recorder)
+	suite.Req.Nil(err)
+	suite.Req.Equal(http.StatusOK, recorder.Code)
+
+	resp := struct {
+		Metadata api.Catalog `json:"metadata"`
+	}{}
+
+	suite.Req.Nil(json.Unmarshal(recorder.Body.Bytes(), &resp))
+	suite.Req.Len(resp.Metadata.Images, 1)
+	suite.Req.Equal("catalog/alias", resp.Metadata.Images[0].Alias)
+	suite.Req.Equal(fingerprint, resp.Metadata.Images[0].Fingerprint)
+
+	// Unknown projects are reported as such.
+	recorder = httptest.NewRecorder()
+	err = catalogGet(suite.d, catalogRequest("/1.0/catalog?project=missing")).Render(recorder)
+	suite.Req.Nil(err)
+	suite.Req.Equal(http.StatusNotFound, recorder.Code)
+	missing := recorder.Body.String()
+
+	// Untrusted callers can't tell existing projects from missing ones.
+	for _, projectName := range []string{"catalog", "missing"} {
+		recorder = httptest.NewRecorder()
+		err = catalogGet(suite.d, httptest.NewRequest(http.MethodGet, "/1.0/catalog?project="+projectName, nil)).Render(recorder)
+		suite.Req.Nil(err)
+		suite.Req.Equal(http.StatusNotFound, recorder.Code)
+		suite.Req.Equal(missing, recorder.Body.String())
+	}
+
+	// The catalog of the default project is public.
+	recorder = httptest.NewRecorder()
+	err = catalogGet(suite.d, httptest.NewRequest(http.MethodGet, "/1.0/catalog", nil)).Render(recorder)
+	suite.Req.Nil(err)
+	suite.Req.Equal(http.StatusOK, recorder.Code)
+}
@@ -32,13 +32,16 @@ import (
 	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/db/operationtype"
 	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/firewall"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	instanceDrivers "github.com/lxc/incus/v6/internal/server/instance/drivers"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
 	"github.com/lxc/incus/v6/internal/server/node"
 	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/scheduler"
 	"github.com/lxc/incus/v6/internal/server/scriptlet"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
@@ -104,6 +107,12 @@ var clusterNodeStateCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: clusterNodeStatePost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
 }
 
+var clusterNodeManagedRulesCmd = APIEndpoint{
+	Path: "cluster/members/{name}/managed-rules",
+
+	Get: APIEndpointAction{Handler: clusterNodeManagedRulesGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
 var clusterCertificateCmd = APIEndpoint{
 	Path: "cluster/certificate",
 
@@ -1335,6 +1344,11 @@ func clusterNodesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("This server is not clustered"))
 	}
 
+	err = timeSkewCheck(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	expiry, err := internalInstance.GetExpiry(time.Now(), s.GlobalConfig.ClusterJoinTokenExpiry())
 	if err != nil {
 		return response.BadRequest(err)
@@ -2971,6 +2985,104 @@ func clusterNodeStateGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, memberState)
 }
 
+// swagger:operation GET /1.0/cluster/members/{name}/managed-rules cluster cluster_member_managed_rules_get
+//
+//	Get the host rules managed by Incus on the cluster member
+//
+//	Returns the firewall rules, OVN ACLs and sysctls that Incus applied on a specific cluster member,
+//	as well as those the administrator must allow when strict firewall mode is enabled.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Managed rules
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of managed rules
+//	          items:
+//	            $ref: "#/definitions/ClusterMemberManagedRule"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterNodeManagedRulesGet(d *Daemon, r *http.Request) response.Response {
+	memberName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s := d.State()
+
+	// Forward request.
+	resp := forwardedResponseToNode(s, r, memberName)
+	if resp != nil {
+		return resp
+	}
+
+	rules, err := s.Firewall.ManagedRules()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Add the sysctls.
+	sysctls := localUtil.SysctlManaged()
+	sysctlPaths := make([]string, 0, len(sysctls))
+	for path := range sysctls {
+		sysctlPaths = append(sysctlPaths, path)
+	}
+
+	sort.Strings(sysctlPaths)
+
+	for _, path := range sysctlPaths {
+		rules = append(rules, api.ClusterMemberManagedRule{
+			Type:     "sysctl",
+			Location: path,
+			Rule:     sysctls[path],
+			Status:   api.ClusterMemberManagedRuleStatusApplied,
+		})
+	}
+
+	// Add the OVN ACLs.
+	if s.OVNNB != nil {
+		acls, err := s.OVNNB.GetACLRules(r.Context())
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed getting OVN ACLs: %w", err))
+		}
+
+		for _, acl := range acls {
+			rules = append(rules, api.ClusterMemberManagedRule{
+				Type:     "ovn",
+				Location: acl.Location,
+				Rule:     acl.Rule,
+				Status:   api.ClusterMemberManagedRuleStatusApplied,
+			})
+		}
+	}
+
+	// Add the rules left to the administrator in strict firewall mode.
+	rules = append(rules, firewall.RequiredRules()...)
+
+	return response.SyncResponse(true, rules)
+}
+
 // swagger:operation POST /1.0/cluster/members/{name}/state cluster cluster_member_state_post
 //
 //	Evacuate or restore a cluster member
@@ -4412,14 +4524,30 @@ func evacuateClusterSelectTarget(ctx context.Context, s *state.State, gateway *c
 			return err
 		}
 
+		// Honor the placement policy of the instance group, the other instances of the group on the evacuated
+		// member are moved away too.
+		candidateMembers, err = instanceGroupFilterMembers(ctx, tx, inst.Project().Name, inst.Name(), inst.ExpandedConfig(), candidateMembers, inst.Location())
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Run instance placement scriptlet if enabled.
-	if s.GlobalConfig.InstancesPlacementScriptlet() != "" {
+	// Only consider the members with enough free huge pages for virtual machines using them.
+	if inst.Type() == instancetype.VM {
+		candidateMembers, err = instanceHugepagesFilterMembers(s, inst.ExpandedConfig(), candidateMembers)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Run instance placement scriptlet or external placement if enabled.
+	externalEndpoint, _, _ := s.GlobalConfig.SchedulerExternal()
+	if s.GlobalConfig.InstancesPlacementScriptlet() != "" || externalEndpoint != "" {
 		leaderAddress, err := gateway.LeaderAddress()
 		if err != nil {
 			return nil, nil, err
@@ -4448,14 +4576,20 @@ func evacuateClusterSelectTarget(ctx context.Context, s *state.State, gateway *c
 			reqExpanded.Profiles = append(reqExpanded.Profiles, p.Name)
 		}
 
-		ctx, cancel := context.WithTimeout(ctx, time.Second*5)
-		targetMemberInfo, err = scriptlet.InstancePlacementRun(ctx, logger.Log, s, &reqExpanded, candidateMembers, leaderAddress)
-		if err != nil {
+		if s.GlobalConfig.InstancesPlacementScriptlet() != "" {
+			ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+			targetMemberInfo, err = scriptlet.InstancePlacementRun(ctx, logger.Log, s, &reqExpanded, candidateMembers, leaderAddress)
+			if err != nil {
+				cancel()
+				return nil, nil, fmt.Errorf("Failed instance placement scriptlet for instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+			}
+
 			cancel()
-			return nil, nil, fmt.Errorf("Failed instance placement scriptlet for instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
 		}
 
-		cancel()
+		if targetMemberInfo == nil {
+			targetMemberInfo = scheduler.ExternalPlacementRun(ctx, logger.Log, s, &reqExpanded, candidateMembers, leaderAddress)
+		}
 	}
 
 	// If target member not specified yet, then find the least loaded cluster member which
@@ -0,0 +1,667 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	clusterConfig "github.com/lxc/incus/v6/internal/server/cluster/config"
+	"github.com/lxc/incus/v6/internal/server/config"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/node"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/response"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+var clusterConfigRolloutCmd = APIEndpoint{
+	Path: "cluster/config-rollout",
+
+	Post: APIEndpointAction{Handler: clusterConfigRolloutPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalClusterConfigCanaryCmd = APIEndpoint{
+	Path: "cluster/config-canary",
+
+	Post: APIEndpointAction{Handler: internalClusterConfigCanaryPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+// clusterConfigRolloutDefaultPeriod is how long the canary members run with the new configuration before being verified.
+const clusterConfigRolloutDefaultPeriod = 30 * time.Second
+
+// clusterConfigCanaryGrace is how long a canary member keeps the configuration change after the verification period,
+// waiting for the rollout to complete, before reverting it on its own.
+const clusterConfigCanaryGrace = 5 * time.Minute
+
+// internalClusterConfigCanaryPostRequest represents a configuration change applied to a canary member.
+type internalClusterConfigCanaryPostRequest struct {
+	Config  map[string]string `json:"config" yaml:"config"`
+	Revert  bool              `json:"revert" yaml:"revert"`
+	Timeout int64             `json:"timeout" yaml:"timeout"`
+}
+
+// clusterConfigCanary is the configuration change applied to a canary member. It's persisted so that it survives
+// restarts and is applied on top of the cluster configuration each time the configuration is reloaded.
+type clusterConfigCanary struct {
+	Config map[string]string `json:"config"`
+	Expiry time.Time         `json:"expiry"`
+}
+
+// clusterConfigCanaryMu serializes the changes to the configuration change of the canary member.
+var clusterConfigCanaryMu sync.Mutex
+
+// swagger:operation POST /1.0/cluster/config-rollout cluster cluster_config_rollout_post
+//
+//	Roll out a cluster-wide configuration change
+//
+//	Applies a change of the cluster-wide configuration to canary cluster members first, without persisting it.
+//	Once the canary members are verified to still be healthy, the change is applied to the whole cluster.
+//	Otherwise, the canary members are reverted to the current configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: rollout
+//	    description: Configuration change
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ClusterConfigRolloutPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterConfigRolloutPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server isn't clustered"))
+	}
+
+	// Rollouts are coordinated by the leader.
+	leader, err := d.gateway.LeaderAddress()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	if leader != s.LocalConfig.ClusterAddress() {
+		client, err := cluster.Connect(leader, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.ForwardedResponse(client, r)
+	}
+
+	req := api.ClusterConfigRolloutPost{}
+
+	// Parse the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if len(req.Config) == 0 {
+		return response.BadRequest(fmt.Errorf("No configuration change provided"))
+	}
+
+	for key := range req.Config {
+		_, ok := node.ConfigSchema[key]
+		if ok {
+			return response.BadRequest(fmt.Errorf("Member specific configuration key %q can't be rolled out", key))
+		}
+	}
+
+	if req.Period < 0 {
+		return response.BadRequest(fmt.Errorf("Invalid verification period %d", req.Period))
+	}
+
+	period := clusterConfigRolloutDefaultPeriod
+	if req.Period > 0 {
+		period = time.Duration(req.Period) * time.Second
+	}
+
+	// Validate the change and select the canary members.
+	var changed map[string]string
+	var canaries []db.NodeInfo
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		current, err := clusterConfig.Load(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		_, changed, err = current.Preview(req.Config)
+		if err != nil {
+			return err
+		}
+
+		members, err := tx.GetNodes(ctx)
+		if err != nil {
+			return err
+		}
+
+		offlineThreshold, err := tx.GetNodeOfflineThreshold(ctx)
+		if err != nil {
+			return err
+		}
+
+		canaries, err = clusterConfigRolloutCanaries(members, req.Canaries, s.ServerName, offlineThreshold)
+		return err
+	})
+	if err != nil {
+		switch err.(type) {
+		case config.ErrorList:
+			return response.BadRequest(err)
+		default:
+			return response.SmartError(err)
+		}
+	}
+
+	if len(changed) == 0 {
+		return response.BadRequest(fmt.Errorf("The configuration change doesn't change anything"))
+	}
+
+	// Only report the changed keys as their values may be secrets.
+	keys := make([]string, 0, len(changed))
+	for key := range changed {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	canaryNames := make([]string, 0, len(canaries))
+	for _, member := range canaries {
+		canaryNames = append(canaryNames, member.Name)
+	}
+
+	metadata := map[string]any{
+		"keys":     keys,
+		"canaries": canaryNames,
+		"stage":    "pending",
+	}
+
+	run := func(op *operations.Operation) error {
+		if req.DryRun {
+			return nil
+		}
+
+		return clusterConfigRollout(d, op, req.Config, canaries, period)
+	}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterConfigRollout, nil, metadata, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// clusterConfigRolloutCanaries returns the canary members of a rollout, defaulting to the first online member other
+// than the leader.
+func clusterConfigRolloutCanaries(members []db.NodeInfo, names []string, leaderName string, offlineThreshold time.Duration) ([]db.NodeInfo, error) {
+	members = slices.Clone(members)
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+
+	canaries := []db.NodeInfo{}
+	if len(names) == 0 {
+		for _, member := range members {
+			if member.Name == leaderName || member.IsOffline(offlineThreshold) {
+				continue
+			}
+
+			return append(canaries, member), nil
+		}
+
+		// Fallback to the leader on single member clusters.
+		for _, member := range members {
+			if member.Name == leaderName {
+				return append(canaries, member), nil
+			}
+		}
+
+		return nil, fmt.Errorf("No cluster member can be used as a canary")
+	}
+
+	for _, name := range names {
+		idx := slices.IndexFunc(members, func(member db.NodeInfo) bool { return member.Name == name })
+		if idx < 0 {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Cluster member %q doesn't exist", name)
+		}
+
+		if members[idx].IsOffline(offlineThreshold) {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Cluster member %q is offline", name)
+		}
+
+		if !slices.ContainsFunc(canaries, func(member db.NodeInfo) bool { return member.Name == name }) {
+			canaries = append(canaries, members[idx])
+		}
+	}
+
+	return canaries, nil
+}
+
+// clusterConfigRollout applies the configuration change to the canary members, verifies them after the given period
+// and then applies the change to the whole cluster, or reverts the canary members.
+func clusterConfigRollout(d *Daemon, op *operations.Operation, values map[string]string, canaries []db.NodeInfo, period time.Duration) error {
+	s := d.State()
+
+	setStage := func(stage string) {
+		metadata := make(map[string]any, len(op.Metadata()))
+		for key, value := range op.Metadata() {
+			metadata[key] = value
+		}
+
+		metadata["stage"] = stage
+		_ = op.UpdateMetadata(metadata)
+	}
+
+	// Record the health of the canary members before the change.
+	unhealthy := make(map[string]int, len(canaries))
+	for _, member := range canaries {
+		count, err := clusterConfigRolloutUnhealthyInstances(s.ShutdownCtx, d, member.Name)
+		if err != nil {
+			return err
+		}
+
+		unhealthy[member.Name] = count
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Apply the change to the canary members.
+	setStage("canary")
+	releaseCanaries := func() {
+		for _, member := range canaries {
+			err := clusterConfigRolloutCanary(d, member, values, true, 0)
+			if err != nil {
+				logger.Warn("Failed reverting canary cluster member configuration", logger.Ctx{"member": member.Name, "err": err})
+			}
+		}
+	}
+
+	reverter.Add(releaseCanaries)
+
+	for _, member := range canaries {
+		err := clusterConfigRolloutCanary(d, member, values, false, period+clusterConfigCanaryGrace)
+		if err != nil {
+			setStage("reverted")
+			return fmt.Errorf("Failed applying configuration to canary member %q: %w", member.Name, err)
+		}
+	}
+
+	// Verify the canary members.
+	setStage("verifying")
+	select {
+	case <-time.After(period):
+	case <-s.ShutdownCtx.Done():
+		return s.ShutdownCtx.Err()
+	}
+
+	for _, member := range canaries {
+		err := clusterConfigRolloutVerify(s.ShutdownCtx, d, member, unhealthy[member.Name])
+		if err != nil {
+			setStage("reverted")
+			return fmt.Errorf("Canary member %q failed verification: %w", member.Name, err)
+		}
+	}
+
+	// Apply the change to the whole cluster.
+	setStage("rollout")
+	var newConfig *clusterConfig.Config
+	var changed map[string]string
+
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		newConfig, err = clusterConfig.Load(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		changed, err = newConfig.Patch(values)
+		return err
+	})
+	if err != nil {
+		setStage("reverted")
+		return fmt.Errorf("Failed updating cluster configuration: %w", err)
+	}
+
+	reverter.Success()
+
+	err = doApi10NotifyClusterChanged(s, changed)
+	if err != nil {
+		return err
+	}
+
+	// The change is now part of the cluster configuration, drop it from the canary members.
+	releaseCanaries()
+
+	d.globalConfigMu.Lock()
+	d.globalConfig = newConfig
+	d.globalConfigMu.Unlock()
+
+	err = doApi10UpdateTriggers(d, nil, changed, s.LocalConfig, newConfig)
+	if err != nil {
+		return err
+	}
+
+	setStage("done")
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.ConfigUpdated.Event(op.Requestor(), nil))
+
+	return nil
+}
+
+// clusterConfigRolloutCanary applies or reverts the configuration change on a canary member.
+// The canary member reverts the change on its own after the timeout.
+func clusterConfigRolloutCanary(d *Daemon, member db.NodeInfo, values map[string]string, revert bool, timeout time.Duration) error {
+	s := d.State()
+
+	if member.Name == s.ServerName {
+		return clusterConfigCanaryApply(d, values, revert, timeout)
+	}
+
+	client, err := cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.RawQuery("POST", "/internal/cluster/config-canary", internalClusterConfigCanaryPostRequest{Config: values, Revert: revert, Timeout: int64(timeout.Seconds())}, "")
+
+	return err
+}
+
+// clusterConfigRolloutVerify checks that a canary member is still healthy.
+func clusterConfigRolloutVerify(ctx context.Context, d *Daemon, member db.NodeInfo, unhealthy int) error {
+	s := d.State()
+
+	if member.Name != s.ServerName {
+		client, err := cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = client.GetServer()
+		if err != nil {
+			return fmt.Errorf("API isn't responding: %w", err)
+		}
+	}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		info, err := tx.GetNodeByName(ctx, member.Name)
+		if err != nil {
+			return err
+		}
+
+		offlineThreshold, err := tx.GetNodeOfflineThreshold(ctx)
+		if err != nil {
+			return err
+		}
+
+		if info.IsOffline(offlineThreshold) {
+			return fmt.Errorf("Member is offline")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	count, err := clusterConfigRolloutUnhealthyInstances(ctx, d, member.Name)
+	if err != nil {
+		return err
+	}
+
+	if count > unhealthy {
+		return fmt.Errorf("%d more instances are unhealthy", count-unhealthy)
+	}
+
+	return nil
+}
+
+// clusterConfigRolloutUnhealthyInstances returns the number of instances of a cluster member which are failing their
+// health checks or are affected by a storage pool failure.
+func clusterConfigRolloutUnhealthyInstances(ctx context.Context, d *Daemon, memberName string) (int, error) {
+	count := 0
+
+	err := d.State().DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, _ api.Project) error {
+			if inst.Config["volatile.health"] == instanceHealthUnhealthy || inst.Config["volatile.storage.failure"] != "" {
+				count++
+			}
+
+			return nil
+		}, dbCluster.InstanceFilter{Node: &memberName})
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return count, nil
+}
+
+// clusterConfigCanaryPath returns the path of the file holding the configuration change of the canary member.
+func clusterConfigCanaryPath() string {
+	return internalUtil.VarPath("cluster", "config-canary.json")
+}
+
+// clusterConfigCanaryRead returns the configuration change stored at the given path, or nil if there is none.
+func clusterConfigCanaryRead(path string) (*clusterConfigCanary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	canary := &clusterConfigCanary{}
+	err = json.Unmarshal(data, canary)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing %q: %w", path, err)
+	}
+
+	return canary, nil
+}
+
+// clusterConfigCanaryWrite stores the configuration change at the given path, removing it if nil.
+func clusterConfigCanaryWrite(path string, canary *clusterConfigCanary) error {
+	if canary == nil {
+		err := os.Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	data, err := json.Marshal(canary)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path+".tmp", data, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// clusterConfigCanaryOverlay applies the configuration change of the canary member, if any, on top of the given
+// cluster configuration. The values of the changed keys are updated accordingly.
+func clusterConfigCanaryOverlay(config *clusterConfig.Config, changed map[string]string) *clusterConfig.Config {
+	clusterConfigCanaryMu.Lock()
+	canary, err := clusterConfigCanaryRead(clusterConfigCanaryPath())
+	clusterConfigCanaryMu.Unlock()
+	if err != nil {
+		logger.Warn("Failed loading canary configuration change", logger.Ctx{"err": err})
+		return config
+	}
+
+	if canary == nil || time.Now().After(canary.Expiry) {
+		return config
+	}
+
+	newConfig, _, err := config.Preview(canary.Config)
+	if err != nil {
+		logger.Warn("Ignoring invalid canary configuration change", logger.Ctx{"err": err})
+		return config
+	}
+
+	for key, value := range canary.Config {
+		_, ok := changed[key]
+		if ok {
+			changed[key] = value
+		}
+	}
+
+	return newConfig
+}
+
+// clusterConfigCanaryApply runs the local update triggers for the configuration change without persisting it in the
+// cluster configuration, or for the cluster configuration when reverting.
+func clusterConfigCanaryApply(d *Daemon, values map[string]string, revert bool, timeout time.Duration) error {
+	s := d.State()
+
+	var current *clusterConfig.Config
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		current, err = clusterConfig.Load(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	clusterConfigCanaryMu.Lock()
+	defer clusterConfigCanaryMu.Unlock()
+
+	newConfig := current
+	changed := map[string]string{}
+	var canary *clusterConfigCanary
+	if revert {
+		currentValues := current.Dump()
+		for key := range values {
+			changed[key] = currentValues[key]
+		}
+	} else {
+		newConfig, changed, err = current.Preview(values)
+		if err != nil {
+			return err
+		}
+
+		canary = &clusterConfigCanary{Config: values, Expiry: time.Now().Add(timeout)}
+	}
+
+	err = clusterConfigCanaryWrite(clusterConfigCanaryPath(), canary)
+	if err != nil {
+		return fmt.Errorf("Failed storing the configuration change: %w", err)
+	}
+
+	// Update the daemon config.
+	d.globalConfigMu.Lock()
+	d.globalConfig = newConfig
+	d.globalConfigMu.Unlock()
+
+	if canary != nil {
+		clusterConfigCanaryExpire(d, canary)
+	}
+
+	return doApi10UpdateTriggers(d, nil, changed, s.LocalConfig, newConfig)
+}
+
+// clusterConfigCanaryExpire reverts the configuration change of the canary member once it expires, in case the
+// rollout doesn't complete, for example because the leader went away.
+func clusterConfigCanaryExpire(d *Daemon, canary *clusterConfigCanary) {
+	time.AfterFunc(time.Until(canary.Expiry), func() {
+		clusterConfigCanaryMu.Lock()
+		current, err := clusterConfigCanaryRead(clusterConfigCanaryPath())
+		clusterConfigCanaryMu.Unlock()
+		if err != nil || current == nil || !current.Expiry.Equal(canary.Expiry) {
+			return
+		}
+
+		logger.Warn("Reverting expired canary configuration change", logger.Ctx{"expiry": canary.Expiry})
+
+		err = clusterConfigCanaryApply(d, canary.Config, true, 0)
+		if err != nil {
+			logger.Warn("Failed reverting expired canary configuration change", logger.Ctx{"err": err})
+		}
+	})
+}
+
+// clusterConfigCanaryResume applies the configuration change of the canary member on startup, or reverts it if it
+// expired while the daemon wasn't running.
+func clusterConfigCanaryResume(d *Daemon) error {
+	clusterConfigCanaryMu.Lock()
+	canary, err := clusterConfigCanaryRead(clusterConfigCanaryPath())
+	clusterConfigCanaryMu.Unlock()
+	if err != nil || canary == nil {
+		return err
+	}
+
+	if time.Now().After(canary.Expiry) {
+		logger.Warn("Dropping expired canary configuration change", logger.Ctx{"expiry": canary.Expiry})
+
+		clusterConfigCanaryMu.Lock()
+		defer clusterConfigCanaryMu.Unlock()
+
+		return clusterConfigCanaryWrite(clusterConfigCanaryPath(), nil)
+	}
+
+	clusterConfigCanaryExpire(d, canary)
+
+	return nil
+}
+
+func internalClusterConfigCanaryPost(d *Daemon, r *http.Request) response.Response {
+	req := internalClusterConfigCanaryPostRequest{}
+
+	// Parse the request.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = clusterConfigCanaryApply(d, req.Config, req.Revert, time.Duration(req.Timeout)*time.Second)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+)
+
+func TestClusterConfigRolloutCanaries(t *testing.T) {
+	now := time.Now()
+	members := []db.NodeInfo{
+		{Name: "server03", Heartbeat: now},
+		{Name: "server01", Heartbeat: now},
+		{Name: "server02", Heartbeat: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		name    string
+		members []db.NodeInfo
+		names   []string
+		result  []string
+		fails   bool
+	}{
+		{"First online member other than the leader", members, nil, []string{"server03"}, false},
+		{"Leader of single member cluster", members[1:2], nil, []string{"server01"}, false},
+		{"Selected members", members, []string{"server03", "server01", "server03"}, []string{"server03", "server01"}, false},
+		{"Unknown member", members, []string{"server04"}, nil, true},
+		{"Offline member", members, []string{"server02"}, nil, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		canaries, err := clusterConfigRolloutCanaries(tt.members, tt.names, "server01", 20*time.Second)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+
+		names := []string{}
+		for _, member := range canaries {
+			names = append(names, member.Name)
+		}
+
+		require.Equal(t, tt.result, names)
+	}
+}
+
+func TestClusterConfigCanaryReadWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cluster", "config-canary.json")
+
+	// No change stored yet.
+	canary, err := clusterConfigCanaryRead(path)
+	require.NoError(t, err)
+	require.Nil(t, canary)
+
+	expiry := time.Now().Add(time.Minute).Round(0)
+	err = clusterConfigCanaryWrite(path, &clusterConfigCanary{Config: map[string]string{"core.proxy_http": "proxy:3128"}, Expiry: expiry})
+	require.NoError(t, err)
+
+	canary, err = clusterConfigCanaryRead(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"core.proxy_http": "proxy:3128"}, canary.Config)
+	require.True(t, expiry.Equal(canary.Expiry))
+
+	// Removing the change is idempotent.
+	require.NoError(t, clusterConfigCanaryWrite(path, nil))
+	require.NoError(t, clusterConfigCanaryWrite(path, nil))
+
+	canary, err = clusterConfigCanaryRead(path)
+	require.NoError(t, err)
+	require.Nil(t, canary)
+}
@@ -22,6 +22,7 @@ import (
 
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/jmap"
+	"github.com/lxc/incus/v6/internal/limitcheck"
 	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/backup"
@@ -30,6 +31,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/db/query"
 	"github.com/lxc/incus/v6/internal/server/db/warningtype"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/fault"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/project"
@@ -51,6 +53,7 @@ var apiInternal = []APIEndpoint{
 	internalBGPStateCmd,
 	internalClusterAcceptCmd,
 	internalClusterAssignCmd,
+	internalClusterConfigCanaryCmd,
 	internalClusterHandoverCmd,
 	internalClusterRaftNodeCmd,
 	internalClusterRebalanceCmd,
@@ -58,13 +61,18 @@ var apiInternal = []APIEndpoint{
 	internalContainerOnStartCmd,
 	internalContainerOnStopCmd,
 	internalContainerOnStopNSCmd,
+	internalFaultsCmd,
 	internalGarbageCollectorCmd,
 	internalImageOptimizeCmd,
+	internalImageRebaseCmd,
 	internalImageRefreshCmd,
+	internalInstanceRemapCmd,
 	internalRAFTSnapshotCmd,
 	internalReadyCmd,
 	internalShutdownCmd,
 	internalSQLCmd,
+	internalStoragePoolMountCmd,
+	internalVerifyLimitsCmd,
 	internalWarningCreateCmd,
 }
 
@@ -129,12 +137,44 @@ var internalImageOptimizeCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: internalOptimizeImage, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalImageRebaseCmd = APIEndpoint{
+	Path: "image-rebase",
+
+	Post: APIEndpointAction{Handler: internalRebaseImage, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalInstanceRemapCmd = APIEndpoint{
+	Path: "instance-remap",
+
+	Post: APIEndpointAction{Handler: internalRemapInstance, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalVerifyLimitsCmd = APIEndpoint{
+	Path: "verify-limits",
+
+	Get: APIEndpointAction{Handler: internalVerifyLimits, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
 var internalWarningCreateCmd = APIEndpoint{
 	Path: "testing/warnings",
 
 	Post: APIEndpointAction{Handler: internalCreateWarning, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalStoragePoolMountCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/mount",
+
+	Post: APIEndpointAction{Handler: internalStoragePoolMount, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalFaultsCmd = APIEndpoint{
+	Path: "testing/faults",
+
+	Get:    APIEndpointAction{Handler: internalFaultsGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post:   APIEndpointAction{Handler: internalFaultsPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Delete: APIEndpointAction{Handler: internalFaultsDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
 var internalBGPStateCmd = APIEndpoint{
 	Path: "testing/bgp",
 
@@ -215,6 +255,29 @@ func internalOptimizeImage(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
+// internalStoragePoolMount mounts a storage pool again, and is used to revert its release by this member
+// when the deletion of the pool failed elsewhere.
+func internalStoragePoolMount(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	_, err = pool.Mount()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
 func internalRefreshImage(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
@@ -302,7 +365,8 @@ func internalContainerHookLoadFromReference(s *state.State, r *http.Request) (in
 			return nil, err
 		}
 	} else {
-		inst, err = instance.LoadByProjectAndName(s, projectName, instanceRef)
+		// The hooks of a container renamed while running still reference its previous name.
+		inst, err = instance.LoadByProjectAndRuntimeName(s, projectName, instanceRef)
 		if err != nil {
 			if api.StatusErrorCheck(err, http.StatusNotFound) {
 				return nil, err
@@ -1013,6 +1077,85 @@ func internalRAFTSnapshot(d *Daemon, r *http.Request) response.Response {
 	return response.InternalError(fmt.Errorf("Not supported"))
 }
 
+func internalFaultsGet(d *Daemon, r *http.Request) response.Response {
+	if !fault.Enabled() {
+		return response.Forbidden(fmt.Errorf("Fault injection isn't enabled"))
+	}
+
+	return response.SyncResponse(true, fault.List())
+}
+
+func internalFaultsPost(d *Daemon, r *http.Request) response.Response {
+	req := fault.Fault{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = fault.Add(req)
+	if err != nil {
+		if !fault.Enabled() {
+			return response.Forbidden(err)
+		}
+
+		return response.BadRequest(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+func internalFaultsDelete(d *Daemon, r *http.Request) response.Response {
+	if !fault.Enabled() {
+		return response.Forbidden(fmt.Errorf("Fault injection isn't enabled"))
+	}
+
+	fault.Clear()
+
+	return response.EmptySyncResponse
+}
+
+// internalVerifyLimits checks the limits of the running instances on this server against what's applied on the host.
+func internalVerifyLimits(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.QueryParam(r, "project")
+
+	insts, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed loading instances: %w", err))
+	}
+
+	report := limitcheck.Report{
+		Discrepancies: []limitcheck.Discrepancy{},
+		Errors:        []string{},
+	}
+
+	for _, inst := range insts {
+		if projectName != "" && inst.Project().Name != projectName {
+			continue
+		}
+
+		if !inst.IsRunning() {
+			continue
+		}
+
+		report.Instances++
+
+		discrepancies, err := inst.VerifyLimits()
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("Instance %q in project %q: %v", inst.Name(), inst.Project().Name, err))
+		}
+
+		for _, discrepancy := range discrepancies {
+			discrepancy.Project = inst.Project().Name
+			discrepancy.Instance = inst.Name()
+			report.Discrepancies = append(report.Discrepancies, discrepancy)
+		}
+	}
+
+	return response.SyncResponse(true, report)
+}
+
 func internalBGPState(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
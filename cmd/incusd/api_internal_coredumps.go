@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/coredump"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// coreDumpPatternPath is the kernel setting holding the core dump handler.
+const coreDumpPatternPath = "/proc/sys/kernel/core_pattern"
+
+// coreDumpPatternMaxLength is the maximum length of the kernel core dump pattern.
+const coreDumpPatternMaxLength = 127
+
+// coreDumpNameLayout is the time layout used in the name of captured core dumps.
+const coreDumpNameLayout = "20060102T150405Z"
+
+// coreDumpCommandUnsafe matches the characters of a command name not kept in core dump names.
+var coreDumpCommandUnsafe = regexp.MustCompile(`[^a-zA-Z0-9.-]`)
+
+// Define API endpoint for the core dump handler.
+var internalCoreDumpsCmd = APIEndpoint{
+	Path: "coredumps",
+
+	Get:  APIEndpointAction{Handler: internalCoreDumpsGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: internalCoreDumpsPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+// init core dumps adds API endpoints to handler slice.
+func init() {
+	apiInternal = append(apiInternal, internalCoreDumpsCmd)
+}
+
+// coreDumpSavedPatternPath returns the path of the file holding the core dump pattern replaced by the handler.
+func coreDumpSavedPatternPath() string {
+	return internalUtil.VarPath("coredumps", ".core_pattern")
+}
+
+// coreDumpHandlerSpecifiers are the core dump pattern specifiers always passed to the handler.
+// The command name (%e) isn't listed as it's always passed last.
+const coreDumpHandlerSpecifiers = "Piugstch"
+
+// coreDumpPatternSpecifiers returns the specifiers used by a core dump pattern, each listed once.
+func coreDumpPatternSpecifiers(pattern string) string {
+	specifiers := ""
+
+	for i := 0; i < len(pattern)-1; i++ {
+		if pattern[i] != '%' {
+			continue
+		}
+
+		i++
+		if !strings.ContainsRune("pPiIugdsthefECcF", rune(pattern[i])) || strings.IndexByte(specifiers, pattern[i]) >= 0 {
+			continue
+		}
+
+		specifiers += string(pattern[i])
+	}
+
+	return specifiers
+}
+
+// coreDumpHandlerPattern returns the kernel core dump pattern running the handler.
+// Besides the specifiers the handler needs, all those used by the previous pattern are passed so that the core
+// dumps can be handed over to it. The arguments must be kept in sync with cmdForkCoreDump.
+func coreDumpHandlerPattern(execPath string, previous string) string {
+	specifiers := coreDumpHandlerSpecifiers
+	for _, specifier := range coreDumpPatternSpecifiers(previous) {
+		if specifier == 'e' || strings.ContainsRune(specifiers, specifier) {
+			continue
+		}
+
+		specifiers += string(specifier)
+	}
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "|%s forkcoredump %s %s", execPath, internalUtil.VarPath(), specifiers)
+	for _, specifier := range specifiers {
+		_, _ = fmt.Fprintf(&b, " %%%c", specifier)
+	}
+
+	b.WriteString(" %e")
+
+	return b.String()
+}
+
+// setupCoreDumps installs or removes the core dump handler.
+// The core dump pattern in use before the handler was installed is kept so that the core dumps of other
+// processes are still handled the same way, and so that it can be restored.
+func (d *Daemon) setupCoreDumps(enable bool) error {
+	current, err := os.ReadFile(coreDumpPatternPath)
+	if err != nil {
+		return fmt.Errorf("Failed reading core dump pattern: %w", err)
+	}
+
+	installed := strings.Contains(string(current), " forkcoredump ")
+
+	if enable {
+		previous := current
+		if installed {
+			previous, err = os.ReadFile(coreDumpSavedPatternPath())
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("Failed reading saved core dump pattern: %w", err)
+			}
+		}
+
+		pattern := coreDumpHandlerPattern(d.os.ExecPath, strings.TrimSpace(string(previous)))
+		if strings.TrimSpace(string(current)) == pattern {
+			return nil
+		}
+
+		if len(pattern) > coreDumpPatternMaxLength {
+			return fmt.Errorf("Core dump handler %q exceeds the maximum length of the core dump pattern", pattern)
+		}
+
+		// Only keep the pattern of other handlers.
+		if !installed {
+			err = os.MkdirAll(filepath.Dir(coreDumpSavedPatternPath()), 0o700)
+			if err != nil {
+				return err
+			}
+
+			err = os.WriteFile(coreDumpSavedPatternPath(), current, 0o600)
+			if err != nil {
+				return fmt.Errorf("Failed saving core dump pattern: %w", err)
+			}
+		}
+
+		err = os.WriteFile(coreDumpPatternPath, []byte(pattern), 0)
+		if err != nil {
+			return fmt.Errorf("Failed setting core dump pattern: %w", err)
+		}
+
+		logger.Info("Enabled core dump handler")
+
+		return nil
+	}
+
+	if !installed {
+		return nil
+	}
+
+	saved, err := os.ReadFile(coreDumpSavedPatternPath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("Failed reading saved core dump pattern: %w", err)
+		}
+
+		saved = []byte("core")
+	}
+
+	err = os.WriteFile(coreDumpPatternPath, saved, 0)
+	if err != nil {
+		return fmt.Errorf("Failed restoring core dump pattern: %w", err)
+	}
+
+	_ = os.Remove(coreDumpSavedPatternPath())
+
+	logger.Info("Disabled core dump handler")
+
+	return nil
+}
+
+// coreDumpInstance returns the container the process of the request belongs to if it has core dumps enabled.
+// Otherwise an error response is returned.
+func coreDumpInstance(s *state.State, r *http.Request) (instance.Container, int, response.Response) {
+	pid, err := strconv.ParseInt(request.QueryParam(r, "pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		return nil, -1, response.BadRequest(fmt.Errorf("Invalid process ID %q", request.QueryParam(r, "pid")))
+	}
+
+	c, err := findContainerForPid(int32(pid), s)
+	if err != nil {
+		return nil, -1, response.NotFound(fmt.Errorf("Process %d doesn't belong to an instance", pid))
+	}
+
+	if util.IsFalseOrEmpty(c.ExpandedConfig()["coredumps.enabled"]) {
+		return nil, -1, response.NotFound(fmt.Errorf("Core dumps aren't enabled for instance %q", c.Name()))
+	}
+
+	return c, int(pid), nil
+}
+
+// coreDumpNamespacePID returns the ID of the process in its innermost PID namespace.
+func coreDumpNamespacePID(pid int) int {
+	status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return pid
+	}
+
+	for _, line := range strings.Split(string(status), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "NSpid:" {
+			continue
+		}
+
+		nsPID, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			return pid
+		}
+
+		return nsPID
+	}
+
+	return pid
+}
+
+// Check whether the core dump of a process is captured.
+func internalCoreDumpsGet(d *Daemon, r *http.Request) response.Response {
+	_, _, resp := coreDumpInstance(d.State(), r)
+	if resp != nil {
+		return resp
+	}
+
+	return response.EmptySyncResponse
+}
+
+// Store the core dump of a process running in a container.
+func internalCoreDumpsPost(d *Daemon, r *http.Request) response.Response {
+	c, pid, resp := coreDumpInstance(d.State(), r)
+	if resp != nil {
+		return resp
+	}
+
+	// A core size limit of zero disables core dumps for the process.
+	if request.QueryParam(r, "limit") == "0" {
+		return response.EmptySyncResponse
+	}
+
+	createdAt := time.Now()
+	timestamp, err := strconv.ParseInt(request.QueryParam(r, "time"), 10, 64)
+	if err == nil {
+		createdAt = time.Unix(timestamp, 0)
+	}
+
+	command := coreDumpCommandUnsafe.ReplaceAllString(request.QueryParam(r, "comm"), "_")
+	if command == "" {
+		command = "unknown"
+	}
+
+	name := fmt.Sprintf("%s_%s_%d%s", createdAt.UTC().Format(coreDumpNameLayout), command, coreDumpNamespacePID(pid), coredump.Suffix)
+
+	err = coredump.Store(c.Project().Name, c.Name(), name, r.Body, coredump.MaxSize(c.Project().Config))
+	if err != nil {
+		if errors.Is(err, coredump.ErrTooLarge) {
+			logger.Warn("Discarded core dump larger than the project limit", logger.Ctx{"project": c.Project().Name, "instance": c.Name(), "name": name})
+		} else if errors.Is(err, coredump.ErrBusy) {
+			logger.Warn("Discarded core dump as too many are being stored", logger.Ctx{"project": c.Project().Name, "instance": c.Name(), "name": name})
+		}
+
+		return response.SmartError(err)
+	}
+
+	logger.Info("Captured core dump", logger.Ctx{"project": c.Project().Name, "instance": c.Name(), "name": name, "signal": request.QueryParam(r, "signal")})
+	d.State().Events.SendLifecycle(c.Project().Name, lifecycle.InstanceCoreDumpCreated.Event(name, c, nil, map[string]any{"signal": request.QueryParam(r, "signal")}))
+
+	return response.EmptySyncResponse
+}
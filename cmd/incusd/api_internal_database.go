@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db/query"
+	"github.com/lxc/incus/v6/internal/server/response"
+	internalSQL "github.com/lxc/incus/v6/internal/sql"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// databaseBackupLayout is the time layout used in the name of the global database backups.
+const databaseBackupLayout = "20060102T150405Z"
+
+// databaseRestoreKeepTables are the tables of the global database which keep their current rows on restore.
+// Those describe the cluster members, the trusted clients and the server configuration, which rolling back could
+// lock users out or break the cluster, as well as the operations currently running.
+var databaseRestoreKeepTables = []string{
+	"certificates",
+	"certificates_projects",
+	"config",
+	"nodes",
+	"nodes_config",
+	"nodes_failure_domains",
+	"nodes_roles",
+	"operations",
+}
+
+// Define API endpoints for global database backup and restore.
+var internalDatabaseBackupsCmd = APIEndpoint{
+	Path: "database/backups",
+
+	Get:  APIEndpointAction{Handler: internalDatabaseBackupsGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: internalDatabaseBackupsPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalDatabaseRestoreCmd = APIEndpoint{
+	Path: "database/restore",
+
+	Post: APIEndpointAction{Handler: internalDatabaseRestorePost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+// init database adds API endpoints to handler slice.
+func init() {
+	apiInternal = append(apiInternal, internalDatabaseBackupsCmd, internalDatabaseRestoreCmd)
+}
+
+// databaseBackupsPath returns the directory holding the global database backups of this server.
+func databaseBackupsPath() string {
+	return internalUtil.VarPath("database", "backups")
+}
+
+// databaseBackups returns the global database backups of this server, sorted by creation date.
+func databaseBackups() ([]internalSQL.SQLBackup, error) {
+	entries, err := os.ReadDir(databaseBackupsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []internalSQL.SQLBackup{}, nil
+		}
+
+		return nil, err
+	}
+
+	backups := []internalSQL.SQLBackup{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "global-") || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		createdAt, err := time.Parse(databaseBackupLayout, strings.TrimSuffix(strings.TrimPrefix(name, "global-"), ".sql"))
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		backups = append(backups, internalSQL.SQLBackup{
+			Name:      name,
+			CreatedAt: createdAt,
+			Size:      info.Size(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.Before(backups[j].CreatedAt) })
+
+	return backups, nil
+}
+
+// databaseBackupsPrune removes the oldest global database backups of this server beyond the given count.
+func databaseBackupsPrune(count int64) error {
+	if count <= 0 {
+		return nil
+	}
+
+	backups, err := databaseBackups()
+	if err != nil {
+		return err
+	}
+
+	for int64(len(backups)) > count {
+		err = os.Remove(filepath.Join(databaseBackupsPath(), backups[0].Name))
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Removed global database backup", logger.Ctx{"name": backups[0].Name})
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// List the global database backups.
+func internalDatabaseBackupsGet(d *Daemon, r *http.Request) response.Response {
+	backups, err := databaseBackups()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed listing database backups: %w", err))
+	}
+
+	return response.SyncResponse(true, backups)
+}
+
+// databaseBackupWrite stores a dump of the global database as a new backup.
+func databaseBackupWrite(dump string) (*internalSQL.SQLBackup, error) {
+	err := os.MkdirAll(databaseBackupsPath(), 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	name := fmt.Sprintf("global-%s.sql", createdAt.Format(databaseBackupLayout))
+	path := filepath.Join(databaseBackupsPath(), name)
+
+	if util.PathExists(path) {
+		return nil, api.StatusErrorf(http.StatusConflict, "A database backup was already created at %s", createdAt.Format(time.RFC3339))
+	}
+
+	// Write to a temporary file first so that interrupted backups are never used.
+	err = os.WriteFile(path+".tmp", []byte(dump), 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed writing database backup: %w", err)
+	}
+
+	err = os.Rename(path+".tmp", path)
+	if err != nil {
+		_ = os.Remove(path + ".tmp")
+		return nil, fmt.Errorf("Failed writing database backup: %w", err)
+	}
+
+	logger.Info("Created global database backup", logger.Ctx{"name": name})
+
+	return &internalSQL.SQLBackup{
+		Name:      name,
+		CreatedAt: createdAt,
+		Size:      int64(len(dump)),
+	}, nil
+}
+
+// databaseBackupSelect returns the backup to restore, either the one with the given name or the most recent one
+// created at or before the timestamp.
+func databaseBackupSelect(backups []internalSQL.SQLBackup, name string, timestamp time.Time) (*internalSQL.SQLBackup, error) {
+	if name != "" {
+		for i := range backups {
+			if backups[i].Name == name {
+				return &backups[i], nil
+			}
+		}
+
+		return nil, api.StatusErrorf(http.StatusNotFound, "Database backup %q not found", name)
+	}
+
+	var backup *internalSQL.SQLBackup
+	for i := range backups {
+		if backups[i].CreatedAt.After(timestamp) {
+			break
+		}
+
+		backup = &backups[i]
+	}
+
+	if backup == nil {
+		return nil, api.StatusErrorf(http.StatusNotFound, "No database backup found at or before %s", timestamp.UTC().Format(time.RFC3339))
+	}
+
+	return backup, nil
+}
+
+// Create a backup of the global database.
+func internalDatabaseBackupsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// A single read transaction provides a consistent view of the database while it's in use.
+	tx, err := s.DB.Cluster.DB().BeginTx(r.Context(), nil)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed to start transaction: %w", err))
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	dump, err := query.Dump(r.Context(), tx, false)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed dump database global: %w", err))
+	}
+
+	_ = tx.Rollback()
+
+	backup, err := databaseBackupWrite(dump)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = databaseBackupsPrune(s.GlobalConfig.BackupsDatabaseCount())
+	if err != nil {
+		logger.Warn("Failed pruning global database backups", logger.Ctx{"err": err})
+	}
+
+	return response.SyncResponse(true, backup)
+}
+
+// Restore the global database from the requested backup, or from the most recent backup created at or before the
+// requested time. The content being replaced is backed up first so that the restore can be undone.
+func internalDatabaseRestorePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	req := internalSQL.SQLRestore{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Name == "" && req.Timestamp.IsZero() {
+		return response.BadRequest(fmt.Errorf("No backup name or timestamp provided"))
+	}
+
+	backups, err := databaseBackups()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed listing database backups: %w", err))
+	}
+
+	backup, err := databaseBackupSelect(backups, req.Name, req.Timestamp)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(databaseBackupsPath(), backup.Name))
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed reading database backup %q: %w", backup.Name, err))
+	}
+
+	dump := string(content)
+
+	tx, err := s.DB.Cluster.DB().BeginTx(r.Context(), nil)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed to start transaction: %w", err))
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := query.Dump(r.Context(), tx, false)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed dump database global: %w", err))
+	}
+
+	result := internalSQL.SQLRestoreResult{Backup: *backup}
+	result.Added, result.Removed = query.DumpDiff(current, dump, databaseRestoreKeepTables...)
+
+	if req.DryRun {
+		return response.SyncResponse(true, result)
+	}
+
+	result.Previous, err = databaseBackupWrite(current)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed backing up the database before restoring: %w", err))
+	}
+
+	err = query.Restore(r.Context(), tx, dump, databaseRestoreKeepTables...)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed restoring database backup %q: %w", backup.Name, err))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed restoring database backup %q: %w", backup.Name, err))
+	}
+
+	logger.Warn("Restored global database backup", logger.Ctx{"name": backup.Name, "previous": result.Previous.Name, "added": len(result.Added), "removed": len(result.Removed)})
+
+	err = databaseBackupsPrune(s.GlobalConfig.BackupsDatabaseCount())
+	if err != nil {
+		logger.Warn("Failed pruning global database backups", logger.Ctx{"err": err})
+	}
+
+	return response.SyncResponse(true, result)
+}
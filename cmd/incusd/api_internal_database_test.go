@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestDatabaseBackups(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	// A missing backups directory means there are no backups.
+	backups, err := databaseBackups()
+	require.NoError(t, err)
+	require.Empty(t, backups)
+
+	require.NoError(t, os.MkdirAll(databaseBackupsPath(), 0o700))
+
+	for name, content := range map[string]string{
+		"global-20261014T100000Z.sql":     "b",
+		"global-20261013T100000Z.sql":     "a",
+		"global-20261015T100000Z.sql":     "cc",
+		"global-20261016T100000Z.sql.tmp": "interrupted",
+		"global-invalid.sql":              "invalid",
+		"local.sql":                       "local",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(databaseBackupsPath(), name), []byte(content), 0o600))
+	}
+
+	// Only the complete global backups are listed, oldest first.
+	backups, err = databaseBackups()
+	require.NoError(t, err)
+	require.Len(t, backups, 3)
+	require.Equal(t, "global-20261013T100000Z.sql", backups[0].Name)
+	require.Equal(t, "global-20261014T100000Z.sql", backups[1].Name)
+	require.Equal(t, "global-20261015T100000Z.sql", backups[2].Name)
+	require.Equal(t, time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC), backups[2].CreatedAt)
+	require.Equal(t, int64(2), backups[2].Size)
+}
+
+func TestDatabaseBackupsPrune(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+	require.NoError(t, os.MkdirAll(databaseBackupsPath(), 0o700))
+
+	names := []string{
+		"global-20261013T100000Z.sql",
+		"global-20261014T100000Z.sql",
+		"global-20261015T100000Z.sql",
+	}
+
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(databaseBackupsPath(), name), []byte(name), 0o600))
+	}
+
+	// No limit keeps all the backups, as does a limit above their count.
+	require.NoError(t, databaseBackupsPrune(0))
+	require.NoError(t, databaseBackupsPrune(5))
+
+	backups, err := databaseBackups()
+	require.NoError(t, err)
+	require.Len(t, backups, 3)
+
+	// The oldest backups are removed first.
+	require.NoError(t, databaseBackupsPrune(2))
+
+	backups, err = databaseBackups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	require.Equal(t, names[1], backups[0].Name)
+	require.Equal(t, names[2], backups[1].Name)
+	require.NoFileExists(t, filepath.Join(databaseBackupsPath(), names[0]))
+}
+
+func TestDatabaseBackupWrite(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	backup, err := databaseBackupWrite("CREATE TABLE test (id INTEGER);\n")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(databaseBackupsPath(), backup.Name))
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE test (id INTEGER);\n", string(content))
+	require.Equal(t, int64(len(content)), backup.Size)
+
+	backups, err := databaseBackups()
+	require.NoError(t, err)
+	require.Equal(t, []string{backup.Name}, []string{backups[0].Name})
+	require.Equal(t, backup.CreatedAt, backups[0].CreatedAt)
+}
+
+func TestDatabaseBackupSelect(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+	require.NoError(t, os.MkdirAll(databaseBackupsPath(), 0o700))
+
+	for _, name := range []string{"global-20261013T100000Z.sql", "global-20261014T100000Z.sql"} {
+		require.NoError(t, os.WriteFile(filepath.Join(databaseBackupsPath(), name), []byte(name), 0o600))
+	}
+
+	backups, err := databaseBackups()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		backup    string
+		timestamp time.Time
+		expected  string
+		status    int
+	}{
+		{"Exact time", "", time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC), "global-20261014T100000Z.sql", 0},
+		{"Between backups", "", time.Date(2026, 10, 13, 23, 0, 0, 0, time.UTC), "global-20261013T100000Z.sql", 0},
+		{"After all backups", "", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), "global-20261014T100000Z.sql", 0},
+		{"Before all backups", "", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), "", http.StatusNotFound},
+		{"By name", "global-20261013T100000Z.sql", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), "global-20261013T100000Z.sql", 0},
+		{"Unknown name", "global-20261015T100000Z.sql", time.Time{}, "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backup, err := databaseBackupSelect(backups, tt.backup, tt.timestamp)
+			if tt.status != 0 {
+				require.True(t, api.StatusErrorCheck(err, tt.status))
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, backup.Name)
+		})
+	}
+}
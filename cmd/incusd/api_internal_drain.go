@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/drain"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// drainDefaultRetryAfter is the number of seconds clients are told to wait when no value was requested.
+const drainDefaultRetryAfter = 60
+
+// Define API endpoint for draining the server.
+var internalDrainCmd = APIEndpoint{
+	Path: "drain",
+
+	Delete: APIEndpointAction{Handler: internalDrainDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: internalDrainGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post:   APIEndpointAction{Handler: internalDrainPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+// init drain adds API endpoints to handler slice.
+func init() {
+	apiInternal = append(apiInternal, internalDrainCmd)
+}
+
+// drainState returns whether the server is draining and how long clients should wait before retrying.
+func (d *Daemon) drainState() (bool, int) {
+	d.drainMu.Lock()
+	defer d.drainMu.Unlock()
+
+	return !d.drainSince.IsZero(), d.drainRetryAfter
+}
+
+// drainStatus returns the draining status of the server, which is safe to stop once no operation or background
+// task is running anymore.
+func (d *Daemon) drainStatus() drain.Status {
+	d.drainMu.Lock()
+	since := d.drainSince
+	d.drainMu.Unlock()
+
+	status := drain.Status{
+		Draining:   !since.IsZero(),
+		Since:      since,
+		Operations: []string{},
+	}
+
+	for _, op := range operations.Clone() {
+		if op.Status() != api.Running && op.Status() != api.Pending {
+			continue
+		}
+
+		status.Operations = append(status.Operations, fmt.Sprintf("%s (%s)", op.ID(), op.Type().Description()))
+	}
+
+	sort.Strings(status.Operations)
+
+	status.Tasks = d.tasks.Busy()
+	status.Safe = status.Draining && len(status.Operations) == 0 && status.Tasks == 0
+
+	return status
+}
+
+// drainCheckpoint flushes the local state to disk once the operations are done.
+func (d *Daemon) drainCheckpoint(ctx context.Context) error {
+	_, err := d.db.Node.DB().ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)")
+	if err != nil {
+		return fmt.Errorf("Failed checkpointing the local database: %w", err)
+	}
+
+	unix.Sync()
+
+	return nil
+}
+
+func internalDrainGet(d *Daemon, r *http.Request) response.Response {
+	return response.SyncResponse(true, d.drainStatus())
+}
+
+func internalDrainPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("Clustered servers must be evacuated instead"))
+	}
+
+	req := drain.Post{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Timeout < 0 || req.RetryAfter < 0 {
+		return response.BadRequest(fmt.Errorf("Invalid timeout or retry delay"))
+	}
+
+	if req.RetryAfter == 0 {
+		req.RetryAfter = drainDefaultRetryAfter
+	}
+
+	d.drainMu.Lock()
+	if d.drainSince.IsZero() {
+		d.drainSince = time.Now()
+		logger.Info("Draining the server for maintenance")
+
+		// Don't start new background tasks, such as scheduled snapshots or image refreshes.
+		d.tasks.Pause()
+	}
+
+	d.drainRetryAfter = req.RetryAfter
+	d.drainMu.Unlock()
+
+	// Wait for the running operations to finish.
+	ctx := r.Context()
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		status := d.drainStatus()
+		if !status.Draining {
+			return response.SmartError(api.StatusErrorf(http.StatusConflict, "Draining was cancelled"))
+		}
+
+		if status.Safe {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			// Report the remaining operations, the server keeps draining.
+			return response.SyncResponse(true, status)
+		case <-ticker.C:
+		}
+	}
+
+	err = d.drainCheckpoint(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	logger.Info("Server drained, the daemon can now be stopped")
+
+	return response.SyncResponse(true, d.drainStatus())
+}
+
+func internalDrainDelete(d *Daemon, r *http.Request) response.Response {
+	d.drainMu.Lock()
+	defer d.drainMu.Unlock()
+
+	if !d.drainSince.IsZero() {
+		logger.Info("Resuming normal operation after draining")
+		d.tasks.Resume()
+	}
+
+	d.drainSince = time.Time{}
+
+	return response.EmptySyncResponse
+}
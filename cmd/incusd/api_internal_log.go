@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// Define API endpoint for the log levels of the subsystems.
+var internalLogLevelsCmd = APIEndpoint{
+	Path: "log-levels",
+
+	Get: APIEndpointAction{Handler: internalLogLevelsGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Put: APIEndpointAction{Handler: internalLogLevelsPut, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+// init log adds API endpoints to handler slice.
+func init() {
+	apiInternal = append(apiInternal, internalLogLevelsCmd)
+}
+
+func internalLogLevelsGet(d *Daemon, r *http.Request) response.Response {
+	return response.SyncResponse(true, logger.Levels())
+}
+
+func internalLogLevelsPut(d *Daemon, r *http.Request) response.Response {
+	req := map[string]string{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = logger.SetLevels(req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	logger.Info("Updated log levels", logger.Ctx{"levels": req})
+
+	return response.SyncResponse(true, logger.Levels())
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/resources"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/osarch"
+)
+
+// supportBundleLogSize is the maximum amount of data included from the end of each log file.
+const supportBundleLogSize = 1024 * 1024
+
+// supportBundleRedacted replaces the values of secret configuration keys in support bundles.
+const supportBundleRedacted = "<redacted>"
+
+// supportBundleSecretKeys lists the key name fragments identifying configuration keys holding secrets.
+var supportBundleSecretKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"private",
+	"_key",
+	"user-data",
+	"vendor-data",
+}
+
+// supportBundleSecretKeyPrefixes lists the prefixes of configuration keys whose values may hold secrets.
+// The raw LXC and QEMU configurations may pass credentials along to the instance.
+var supportBundleSecretKeyPrefixes = []string{
+	"environment.",
+	"raw.lxc",
+	"raw.qemu",
+}
+
+// Define API endpoint for support bundles.
+var internalSupportBundleCmd = APIEndpoint{
+	Path: "support-bundle",
+
+	Get: APIEndpointAction{Handler: internalSupportBundleGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
+// init support bundle adds API endpoints to handler slice.
+func init() {
+	apiInternal = append(apiInternal, internalSupportBundleCmd)
+}
+
+// supportBundleServer is the server information included in support bundles.
+type supportBundleServer struct {
+	ServerName    string            `yaml:"server_name"`
+	Version       string            `yaml:"version"`
+	APIExtensions int               `yaml:"api_extensions"`
+	Kernel        string            `yaml:"kernel"`
+	KernelVersion string            `yaml:"kernel_version"`
+	Architecture  string            `yaml:"architecture"`
+	Config        map[string]string `yaml:"config"`
+	LocalConfig   map[string]string `yaml:"local_config"`
+}
+
+// supportBundleMember is the cluster member information included in support bundles.
+type supportBundleMember struct {
+	Name          string    `yaml:"name"`
+	Address       string    `yaml:"address"`
+	Architecture  string    `yaml:"architecture"`
+	Roles         []string  `yaml:"roles"`
+	Groups        []string  `yaml:"groups"`
+	Schema        int       `yaml:"schema"`
+	APIExtensions int       `yaml:"api_extensions"`
+	Heartbeat     time.Time `yaml:"heartbeat"`
+	Online        bool      `yaml:"online"`
+}
+
+// supportBundleInstance is the instance information included in support bundles.
+type supportBundleInstance struct {
+	Name         string                       `yaml:"name"`
+	Project      string                       `yaml:"project"`
+	Type         string                       `yaml:"type"`
+	Status       string                       `yaml:"status"`
+	Architecture string                       `yaml:"architecture"`
+	Profiles     []string                     `yaml:"profiles"`
+	Config       map[string]string            `yaml:"config"`
+	Devices      map[string]map[string]string `yaml:"devices"`
+}
+
+// supportBundleRedactConfig returns a copy of the config with the values of the keys holding secrets replaced.
+func supportBundleRedactConfig(config map[string]string) map[string]string {
+	redacted := make(map[string]string, len(config))
+
+	for key, value := range config {
+		if value != "" && supportBundleIsSecretKey(key) {
+			value = supportBundleRedacted
+		}
+
+		redacted[key] = value
+	}
+
+	return redacted
+}
+
+// supportBundleIsSecretKey returns whether the value of a configuration key may hold a secret.
+func supportBundleIsSecretKey(key string) bool {
+	for _, prefix := range supportBundleSecretKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	for _, fragment := range supportBundleSecretKeys {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// supportBundleWriter adds files to a support bundle archive.
+type supportBundleWriter struct {
+	tw      *tar.Writer
+	prefix  string
+	modTime time.Time
+}
+
+// addFile adds a file with the given content to the archive.
+func (w *supportBundleWriter) addFile(name string, content []byte) error {
+	hdr := &tar.Header{
+		Name:    w.prefix + "/" + name,
+		Mode:    0o600,
+		Size:    int64(len(content)),
+		ModTime: w.modTime,
+	}
+
+	err := w.tw.WriteHeader(hdr)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.tw.Write(content)
+	return err
+}
+
+// addYAML adds a file holding the YAML representation of the given value to the archive.
+func (w *supportBundleWriter) addYAML(name string, value any) error {
+	content, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("Failed encoding %q: %w", name, err)
+	}
+
+	return w.addFile(name, content)
+}
+
+// addLog adds the end of a log file to the archive, skipping missing logs.
+func (w *supportBundleWriter) addLog(name string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() > supportBundleLogSize {
+		_, err = f.Seek(info.Size()-supportBundleLogSize, io.SeekStart)
+		if err != nil {
+			return err
+		}
+	}
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	return w.addFile(name, content)
+}
+
+// supportBundleInstances returns the redacted configuration and the log paths of the instances on this server.
+func supportBundleInstances(s *state.State) ([]supportBundleInstance, map[string]string, error) {
+	insts, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	instances := make([]supportBundleInstance, 0, len(insts))
+	logs := map[string]string{}
+
+	for _, inst := range insts {
+		architectureName, _ := osarch.ArchitectureName(inst.Architecture())
+
+		profiles := make([]string, 0, len(inst.Profiles()))
+		for _, profile := range inst.Profiles() {
+			profiles = append(profiles, profile.Name)
+		}
+
+		devices := map[string]map[string]string{}
+		for devName, devConfig := range inst.ExpandedDevices().CloneNative() {
+			devices[devName] = supportBundleRedactConfig(devConfig)
+		}
+
+		instances = append(instances, supportBundleInstance{
+			Name:         inst.Name(),
+			Project:      inst.Project().Name,
+			Type:         inst.Type().String(),
+			Status:       inst.State(),
+			Architecture: architectureName,
+			Profiles:     profiles,
+			Config:       supportBundleRedactConfig(inst.ExpandedConfig()),
+			Devices:      devices,
+		})
+
+		logs[fmt.Sprintf("logs/instances/%s/%s.log", inst.Project().Name, inst.Name())] = inst.LogFilePath()
+	}
+
+	return instances, logs, nil
+}
+
+// Produce a support bundle archive holding the state of the server.
+func internalSupportBundleGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// Server information.
+	architectureName, _ := osarch.ArchitectureName(s.OS.Architectures[0])
+
+	server := supportBundleServer{
+		ServerName:    s.ServerName,
+		Version:       version.Version,
+		APIExtensions: len(version.APIExtensions),
+		Kernel:        s.OS.Uname.Sysname,
+		KernelVersion: s.OS.KernelVersion.String(),
+		Architecture:  architectureName,
+		Config:        supportBundleRedactConfig(s.GlobalConfig.Dump()),
+		LocalConfig:   supportBundleRedactConfig(s.LocalConfig.Dump()),
+	}
+
+	// Cluster state and warnings.
+	var members []supportBundleMember
+	var warnings []api.Warning
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		nodes, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading cluster members: %w", err)
+		}
+
+		offlineThreshold := s.GlobalConfig.OfflineThreshold()
+
+		for _, node := range nodes {
+			memberArchitecture, _ := osarch.ArchitectureName(node.Architecture)
+
+			roles := make([]string, 0, len(node.Roles))
+			for _, role := range node.Roles {
+				roles = append(roles, string(role))
+			}
+
+			members = append(members, supportBundleMember{
+				Name:          node.Name,
+				Address:       node.Address,
+				Architecture:  memberArchitecture,
+				Roles:         roles,
+				Groups:        node.Groups,
+				Schema:        node.Schema,
+				APIExtensions: node.APIExtensions,
+				Heartbeat:     node.Heartbeat,
+				Online:        !node.IsOffline(offlineThreshold),
+			})
+		}
+
+		dbWarnings, err := cluster.GetWarnings(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading warnings: %w", err)
+		}
+
+		for _, w := range dbWarnings {
+			warnings = append(warnings, w.ToAPI())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Instances.
+	instances, instanceLogs, err := supportBundleInstances(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Resources.
+	res, err := resources.GetResources()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed gathering resources: %w", err))
+	}
+
+	// Assemble the archive.
+	now := time.Now().UTC()
+	name := fmt.Sprintf("incus-support-%s-%s", s.ServerName, now.Format("20060102T150405Z"))
+
+	// The archive is written to a temporary file rather than held in memory.
+	f, err := os.CreateTemp(internalUtil.VarPath("backups"), "incus_support_bundle_")
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed creating support bundle: %w", err))
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	reverter.Add(func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	})
+
+	gw := gzip.NewWriter(f)
+	w := &supportBundleWriter{
+		tw:      tar.NewWriter(gw),
+		prefix:  name,
+		modTime: now,
+	}
+
+	files := []struct {
+		name  string
+		value any
+	}{
+		{name: "server.yaml", value: server},
+		{name: "cluster.yaml", value: members},
+		{name: "instances.yaml", value: instances},
+		{name: "warnings.yaml", value: warnings},
+		{name: "resources.yaml", value: res},
+	}
+
+	for _, file := range files {
+		err = w.addYAML(file.name, file.value)
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed writing support bundle: %w", err))
+		}
+	}
+
+	err = w.addLog("logs/incusd.log", internalUtil.LogPath("incusd.log"))
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed writing support bundle: %w", err))
+	}
+
+	for fileName, path := range instanceLogs {
+		err = w.addLog(fileName, path)
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed writing support bundle: %w", err))
+		}
+	}
+
+	err = w.tw.Close()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed writing support bundle: %w", err))
+	}
+
+	err = gw.Close()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed writing support bundle: %w", err))
+	}
+
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// The file is only referenced by its descriptor from now on, so it goes away once the response is sent.
+	err = os.Remove(f.Name())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	logger.Info("Generated support bundle", logger.Ctx{"name": name, "size": size})
+
+	ent := response.FileResponseEntry{
+		Identifier:   name,
+		Filename:     name + ".tar.gz",
+		File:         f,
+		FileSize:     size,
+		FileModified: now,
+		Cleanup: func() {
+			_ = f.Close()
+		},
+	}
+
+	reverter.Success()
+
+	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportBundleIsSecretKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"core.https_address", false},
+		{"limits.cpu", false},
+		{"security.privileged", false},
+		{"core.trust_password", true},
+		{"loki.auth.password", true},
+		{"oidc.client.secret", true},
+		{"cluster.join_token", true},
+		{"acme.private_key", true},
+		{"ssh_key", true},
+		{"cloud-init.user-data", true},
+		{"cloud-init.vendor-data", true},
+		{"user.user-data", true},
+		{"environment.PATH", true},
+		{"environment.DB_PASSWORD", true},
+		{"raw.lxc", true},
+		{"raw.qemu", true},
+		{"raw.qemu.conf", true},
+		{"raw.qemu.scriptlet", true},
+		{"raw.idmap", false},
+		{"raw.apparmor", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, supportBundleIsSecretKey(tt.key))
+		})
+	}
+}
+
+func TestSupportBundleRedactConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]string
+		want   map[string]string
+	}{
+		{
+			"Empty config",
+			map[string]string{},
+			map[string]string{},
+		},
+		{
+			"No secrets",
+			map[string]string{"limits.cpu": "2", "security.nesting": "true"},
+			map[string]string{"limits.cpu": "2", "security.nesting": "true"},
+		},
+		{
+			"Secrets",
+			map[string]string{
+				"limits.cpu":          "2",
+				"core.trust_password": "hunter2",
+				"environment.TOKEN":   "abc",
+				"raw.lxc":             "lxc.environment = PASSWORD=hunter2",
+				"raw.qemu":            "-object secret,id=sec0,data=hunter2",
+				"raw.qemu.conf":       "[object \"sec0\"]\ndata = \"hunter2\"",
+			},
+			map[string]string{
+				"limits.cpu":          "2",
+				"core.trust_password": supportBundleRedacted,
+				"environment.TOKEN":   supportBundleRedacted,
+				"raw.lxc":             supportBundleRedacted,
+				"raw.qemu":            supportBundleRedacted,
+				"raw.qemu.conf":       supportBundleRedacted,
+			},
+		},
+		{
+			"Empty secrets are kept",
+			map[string]string{"core.trust_password": "", "raw.lxc": ""},
+			map[string]string{"core.trust_password": "", "raw.lxc": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]string{}
+			for key, value := range tt.config {
+				config[key] = value
+			}
+
+			assert.Equal(t, tt.want, supportBundleRedactConfig(tt.config))
+
+			// The original config is left untouched.
+			assert.Equal(t, config, tt.config)
+		})
+	}
+}
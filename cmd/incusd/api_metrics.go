@@ -119,6 +119,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 		// Add internal metrics.
 		metricSet.Merge(internalMetrics(ctx, s.StartTime, tx))
+		metricSet.Merge(d.apiRateLimitMetrics())
 
 		return nil
 	})
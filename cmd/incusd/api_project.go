@@ -6,11 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -26,6 +28,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
 	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
@@ -57,6 +60,12 @@ var projectStateCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: projectStateGet, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView, "name")},
 }
 
+var projectIdleInstancesCmd = APIEndpoint{
+	Path: "projects/{name}/idle-instances",
+
+	Get: APIEndpointAction{Handler: projectIdleInstancesGet, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView, "name")},
+}
+
 // swagger:operation GET /1.0/projects projects projects_get
 //
 //  Get the projects
@@ -306,7 +315,7 @@ func projectsPost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Validate the configuration.
-	err = projectValidateConfig(s, project.Config)
+	err = projectValidateConfig(s, project.Config, nil)
 	if err != nil {
 		return response.BadRequest(err)
 	}
@@ -478,6 +487,24 @@ func projectPut(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// The project was already updated by the notifying member, only update the local running instances.
+	if isClusterNotification(r) {
+		go projectSyncSSHAuthorizedKeys(s, name)
+
+		req := api.ProjectPut{}
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		err = network.BridgeShapingProjectUpdate(name, req.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.EmptySyncResponse
+	}
+
 	// Get the current data
 	var project *api.Project
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -697,7 +724,7 @@ func projectChange(ctx context.Context, s *state.State, project *api.Project, re
 	}
 
 	// Validate the configuration.
-	err := projectValidateConfig(s, req.Config)
+	err := projectValidateConfig(s, req.Config, project.Config)
 	if err != nil {
 		return response.BadRequest(err)
 	}
@@ -743,6 +770,25 @@ func projectChange(ctx context.Context, s *state.State, project *api.Project, re
 		return response.SmartError(err)
 	}
 
+	// Update the SSH keys and the network aggregate limits of the running instances, on all cluster members.
+	sshChanged := slices.ContainsFunc(configChanged, func(key string) bool { return strings.HasPrefix(key, "ssh.authorized_keys.") })
+	shapingChanged := slices.Contains(configChanged, "limits.network.ingress") || slices.Contains(configChanged, "limits.network.egress")
+
+	if sshChanged {
+		go projectSyncSSHAuthorizedKeys(s, project.Name)
+	}
+
+	if shapingChanged {
+		err = network.BridgeShapingProjectUpdate(project.Name, req.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	if sshChanged || shapingChanged {
+		projectNotifyInstances(s, project.Name, req)
+	}
+
 	return response.EmptySyncResponse
 }
 
@@ -989,6 +1035,87 @@ func projectStateGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, &state)
 }
 
+// swagger:operation GET /1.0/projects/{name}/idle-instances projects project_idle_instances_get
+//
+//	Get the idle instances of a project
+//
+//	Returns the instances of the project which were detected as idle.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Idle instances
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of idle instances
+//	          items:
+//	            $ref: "#/definitions/ProjectIdleInstance"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func projectIdleInstancesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	idleInstances := []api.ProjectIdleInstance{}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := cluster.GetProject(ctx, tx.Tx(), name)
+		if err != nil {
+			return err
+		}
+
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			if inst.Config["volatile.idle.since"] == "" {
+				return nil
+			}
+
+			idleSince, err := time.Parse(time.RFC3339, inst.Config["volatile.idle.since"])
+			if err != nil {
+				return fmt.Errorf("Invalid idle timestamp for instance %q: %w", inst.Name, err)
+			}
+
+			idleInstances = append(idleInstances, api.ProjectIdleInstance{
+				Name:       inst.Name,
+				Type:       inst.Type.String(),
+				Location:   inst.Node,
+				IdleSince:  idleSince,
+				LastUsedAt: inst.LastUsedDate,
+			})
+
+			return nil
+		}, cluster.InstanceFilter{Project: &name})
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, idleInstances)
+}
+
 // Check if a project is empty.
 func projectIsEmpty(ctx context.Context, project *cluster.Project, tx *db.ClusterTx) (bool, error) {
 	instances, err := cluster.GetInstances(ctx, tx.Tx(), cluster.InstanceFilter{Project: &project.Name})
@@ -1061,7 +1188,7 @@ func isEitherAllowOrBlockOrManaged(value string) error {
 	return validate.Optional(validate.IsOneOf("block", "allow", "managed"))(value)
 }
 
-func projectValidateConfig(s *state.State, config map[string]string) error {
+func projectValidateConfig(s *state.State, config map[string]string, currentConfig map[string]string) error {
 	// Validate the project configuration.
 	projectConfigKeys := map[string]func(value string) error{
 		// gendoc:generate(entity=project, group=specific, key=backups.compression_algorithm)
@@ -1072,6 +1199,14 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  shortdesc: Compression algorithm to use for backups
 		"backups.compression_algorithm": validate.IsCompressionAlgorithm,
 
+		// gendoc:generate(entity=project, group=specific, key=coredumps.max_size)
+		// When a new core dump is captured, the oldest core dumps of the instances in the project are removed to stay within this size.
+		// ---
+		//  type: string
+		//  defaultdesc: `1GiB`
+		//  shortdesc: Maximum total size of the core dumps kept for the project
+		"coredumps.max_size": validate.Optional(validate.IsSize),
+
 		// gendoc:generate(entity=project, group=features, key=features.profiles)
 		//
 		// ---
@@ -1218,6 +1353,46 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  shortdesc: Maximum number of networks that the project can have
 		"limits.networks": validate.Optional(validate.IsUint32),
 
+		// gendoc:generate(entity=project, group=limits, key=limits.network.egress)
+		// This value is the aggregate bandwidth of the traffic sent by the instances of the project on each bridge network using hierarchical traffic shaping.
+		// It nests within the aggregate limit of the network. See {ref}`network-bridge-limits`.
+		// ---
+		//  type: string
+		//  shortdesc: Aggregate egress bandwidth of the project on each shaped bridge network
+		"limits.network.egress": validate.Optional(network.ValidBitRate),
+
+		// gendoc:generate(entity=project, group=limits, key=limits.network.ingress)
+		// This value is the aggregate bandwidth of the traffic received by the instances of the project on each bridge network using hierarchical traffic shaping.
+		// It nests within the aggregate limit of the network. See {ref}`network-bridge-limits`.
+		// ---
+		//  type: string
+		//  shortdesc: Aggregate ingress bandwidth of the project on each shaped bridge network
+		"limits.network.ingress": validate.Optional(network.ValidBitRate),
+
+		// gendoc:generate(entity=project, group=limits, key=limits.operations.backups)
+		// This value is the maximum number of backup creation and restore operations of the project running at the same time on each server.
+		// Additional operations are queued.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of concurrent backup operations of the project
+		"limits.operations.backups": validate.Optional(validate.IsUint32),
+
+		// gendoc:generate(entity=project, group=limits, key=limits.operations.images)
+		// This value is the maximum number of image download and refresh operations of the project running at the same time on each server.
+		// Additional operations are queued.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of concurrent image download operations of the project
+		"limits.operations.images": validate.Optional(validate.IsUint32),
+
+		// gendoc:generate(entity=project, group=limits, key=limits.operations.migrations)
+		// This value is the maximum number of instance and volume migration operations of the project running at the same time on each server.
+		// Additional operations are queued.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of concurrent migration operations of the project
+		"limits.operations.migrations": validate.Optional(validate.IsUint32),
+
 		// gendoc:generate(entity=project, group=restricted, key=restricted)
 		// This option must be enabled to allow the `restricted.*` keys to take effect.
 		// To temporarily remove the restrictions, you can disable this option instead of clearing the related keys.
@@ -1261,6 +1436,14 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  shortdesc: Whether to prevent using system call interception options
 		"restricted.containers.interception": validate.Optional(validate.IsOneOf("allow", "block", "full")),
 
+		// gendoc:generate(entity=project, group=restricted, key=restricted.containers.kernel_modules)
+		// Specify a comma-separated list of kernel modules that containers can use in {config:option}`instance-miscellaneous:linux.kernel_modules`.
+		// Other kernel modules are blocked unless {config:option}`project-restricted:restricted.containers.lowlevel` is set to `allow`.
+		// ---
+		//  type: string
+		//  shortdesc: Kernel modules that containers can load
+		"restricted.containers.kernel_modules": validate.Optional(validate.IsListOf(validate.IsAny)),
+
 		// gendoc:generate(entity=project, group=restricted, key=restricted.containers.nesting)
 		// Possible values are `allow` or `block`.
 		// When set to `allow`, {config:option}`instance-security:security.nesting` can be set to `true` for an instance.
@@ -1356,8 +1539,17 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  shortdesc: Whether to prevent using devices of type `pci`
 		"restricted.devices.pci": isEitherAllowOrBlock,
 
+		// gendoc:generate(entity=project, group=restricted, key=restricted.devices.ivshmem)
+		// Possible values are `allow` or `block`.
+		// ---
+		//  type: string
+		//  defaultdesc: `block`
+		//  shortdesc: Whether to prevent using devices of type `ivshmem`
+		"restricted.devices.ivshmem": isEitherAllowOrBlock,
+
 		// gendoc:generate(entity=project, group=restricted, key=restricted.devices.proxy)
 		// Possible values are `allow` or `block`.
+		// This also controls whether instances can listen on host addresses through {config:option}`instance-activation:activation.listen`.
 		// ---
 		//  type: string
 		//  defaultdesc: `block`
@@ -1398,14 +1590,14 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		"restricted.devices.disk.paths": validate.Optional(validate.IsListOf(validate.IsAbsFilePath)),
 
 		// gendoc:generate(entity=project, group=restricted, key=restricted.idmap.uid)
-		// This option specifies the host UID ranges that are allowed in the instance's {config:option}`instance-raw:raw.idmap` setting.
+		// This option specifies the host UID ranges that are allowed in the instance's {config:option}`instance-raw:raw.idmap` and {config:option}`instance-security:idmap.passthrough.users` settings.
 		// ---
 		//  type: string
 		//  shortdesc: Which host UID ranges are allowed in `raw.idmap`
 		"restricted.idmap.uid": validate.Optional(validate.IsListOf(validate.IsUint32Range)),
 
 		// gendoc:generate(entity=project, group=restricted, key=restricted.idmap.gid)
-		// This option specifies the host GID ranges that are allowed in the instance's {config:option}`instance-raw:raw.idmap` setting.
+		// This option specifies the host GID ranges that are allowed in the instance's {config:option}`instance-raw:raw.idmap` and {config:option}`instance-security:idmap.passthrough.users` settings.
 		// ---
 		//  type: string
 		//  shortdesc: Which host GID ranges are allowed in `raw.idmap`
@@ -1477,6 +1669,49 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 			continue
 		}
 
+		// gendoc:generate(entity=project, group=specific, key=ssh.authorized_keys.<name>)
+		// Specify an SSH public key, in the `authorized_keys` format, to install for the `root` user of all the instances of the project.
+		// ---
+		//  type: string
+		//  shortdesc: SSH public key to install in the project's instances
+		if strings.HasPrefix(key, "ssh.authorized_keys.") {
+			err := validate.IsSSHAuthorizedKey(v)
+			if err != nil {
+				return fmt.Errorf("Invalid project configuration key %q value: %w", k, err)
+			}
+
+			continue
+		}
+
+		// gendoc:generate(entity=project, group=specific, key=storage.<pool>.volume.<option>)
+		// Specify a default for the volume config option `<option>` of the volumes created in storage pool `<pool>` by this project.
+		// It takes precedence over the `volume.<option>` config of the pool.
+		// ---
+		//  type: string
+		//  shortdesc: Default volume config in a storage pool for the project
+		poolName, volKey, ok := projecthelpers.StorageVolumeDefaultKey(key)
+		if ok {
+			pool, err := storagePools.LoadByName(s, poolName)
+			if err != nil {
+				// Keep the defaults of a deleted pool valid so that they don't prevent other changes.
+				if response.IsNotFoundError(err) && currentConfig[k] == v {
+					continue
+				}
+
+				return fmt.Errorf("Invalid project configuration key %q: %w", k, err)
+			}
+
+			poolConfig := maps.Clone(pool.Driver().Config())
+			poolConfig["volume."+volKey] = v
+
+			err = pool.Validate(poolConfig)
+			if err != nil {
+				return fmt.Errorf("Invalid project configuration key %q value: %w", k, err)
+			}
+
+			continue
+		}
+
 		// Then validate.
 		validator, ok := projectConfigKeys[key]
 		if !ok {
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"github.com/lxc/incus/v6/client"
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// projectSyncSSHAuthorizedKeys updates the SSH keys of the running instances of a project on this server.
+func projectSyncSSHAuthorizedKeys(s *state.State, projectName string) {
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		logger.Warn("Failed loading instances to update SSH keys", logger.Ctx{"project": projectName, "err": err})
+		return
+	}
+
+	for _, inst := range instances {
+		if inst.Project().Name != projectName || !inst.IsRunning() {
+			continue
+		}
+
+		err = instance.SyncSSHAuthorizedKeys(inst)
+		if err != nil {
+			logger.Warn("Failed updating SSH keys", logger.Ctx{"project": projectName, "instance": inst.Name(), "err": err})
+		}
+	}
+}
+
+// projectNotifyInstances has the other cluster members apply the changes of a project to their running instances,
+// like the SSH keys and the network aggregate limits. A member which is down gets them when its instances next start.
+func projectNotifyInstances(s *state.State, projectName string, req api.ProjectPut) {
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		logger.Warn("Failed notifying cluster members to update running instances", logger.Ctx{"project": projectName, "err": err})
+		return
+	}
+
+	err = notifier(func(client incus.InstanceServer) error {
+		return client.UpdateProject(projectName, req, "")
+	})
+	if err != nil {
+		logger.Warn("Failed notifying cluster members to update running instances", logger.Ctx{"project": projectName, "err": err})
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/lxc/incus/v6/internal/filter"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+var queryCmd = APIEndpoint{
+	Path: "query",
+
+	Post: APIEndpointAction{Handler: queryPost, AccessHandler: allowAuthenticated},
+}
+
+// swagger:operation POST /1.0/query query query_post
+//
+//	Run a query
+//
+//	Runs a query statement against the server, across all projects and cluster members.
+//	Only the objects the client is allowed to see are considered.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Restrict the query to a single project
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: query
+//	    description: Query statement
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/QueryPost"
+//	responses:
+//	  "200":
+//	    description: Query result
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/QueryResult"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func queryPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	req := api.QueryPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	stmt, err := filter.ParseStatement(req.Query)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid query: %w", err))
+	}
+
+	var rows [][]any
+
+	switch stmt.From {
+	case "instances":
+		rows, err = queryInstances(s, r, stmt)
+	default:
+		return response.BadRequest(fmt.Errorf("Unsupported collection %q, only \"instances\" can be queried", stmt.From))
+	}
+
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, api.QueryResult{Columns: stmt.Fields, Rows: rows})
+}
+
+// queryInstances runs a statement against the instances visible to the client.
+func queryInstances(s *state.State, r *http.Request, stmt *filter.Statement) ([][]any, error) {
+	values := url.Values{}
+
+	projectName := request.QueryParam(r, "project")
+	if projectName != "" {
+		values.Set("project", projectName)
+	} else {
+		values.Set("all-projects", "true")
+	}
+
+	// Rely on the instance listing logic for the permission checks and to reach the other cluster members.
+	listInstances := func(recursion int, selected func(inst db.Instance) bool) (any, error) {
+		values.Set("recursion", strconv.Itoa(recursion))
+
+		listReq := r.Clone(r.Context())
+		listReq.Method = http.MethodGet
+		listReq.Body = http.NoBody
+		listReq.URL.RawQuery = values.Encode()
+		listReq.Form = nil
+
+		return doInstancesGetSelected(s, listReq, selected)
+	}
+
+	// Only gather the state, snapshots and backups of the instances when the statement needs them.
+	full := stmt.Uses("state") || stmt.Uses("snapshots") || stmt.Uses("backups")
+	if !full {
+		result, err := listInstances(1, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return filter.Execute(stmt, result.([]*api.Instance))
+	}
+
+	// And then only for the instances matching the conditions which don't need them.
+	var selected func(inst db.Instance) bool
+
+	prefilter := stmt.Prefilter("state", "snapshots", "backups")
+	if prefilter != nil {
+		result, err := listInstances(1, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		matches := map[string]bool{}
+		for _, inst := range result.([]*api.Instance) {
+			match, err := prefilter.Match(inst)
+			if err != nil {
+				return nil, err
+			}
+
+			if match {
+				matches[project.Instance(inst.Project, inst.Name)] = true
+			}
+		}
+
+		selected = func(inst db.Instance) bool {
+			return matches[project.Instance(inst.Project, inst.Name)]
+		}
+	}
+
+	result, err := listInstances(2, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	return filter.Execute(stmt, result.([]*api.InstanceFull))
+}
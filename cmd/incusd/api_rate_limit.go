@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/metrics"
+	"github.com/lxc/incus/v6/internal/server/ratelimit"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// apiRateLimiters holds the API rate limiters per source address and per identity.
+type apiRateLimiters struct {
+	address  *ratelimit.Limiter
+	identity *ratelimit.Limiter
+}
+
+// newAPIRateLimiter returns a limiter for the given rate and burst size, or nil if no rate is set.
+func newAPIRateLimiter(rate string, burst int64) (*ratelimit.Limiter, error) {
+	if rate == "" {
+		return nil, nil
+	}
+
+	requests, period, err := ratelimit.ParseRate(rate)
+	if err != nil {
+		return nil, err
+	}
+
+	return ratelimit.NewLimiter(requests, period, uint64(burst)), nil
+}
+
+// setupAPIRateLimits applies the api.rate_limit.* configuration.
+func (d *Daemon) setupAPIRateLimits(addressRate string, addressBurst int64, identityRate string, identityBurst int64) error {
+	limiters := &apiRateLimiters{}
+
+	var err error
+	limiters.address, err = newAPIRateLimiter(addressRate, addressBurst)
+	if err != nil {
+		return fmt.Errorf("Failed configuring API rate limit per address: %w", err)
+	}
+
+	limiters.identity, err = newAPIRateLimiter(identityRate, identityBurst)
+	if err != nil {
+		return fmt.Errorf("Failed configuring API rate limit per identity: %w", err)
+	}
+
+	d.apiRateLimiters.Store(limiters)
+
+	return nil
+}
+
+// apiRateLimitAllow checks the API rate limits for a request.
+// It renders a 429 error and returns false if the request is over one of the limits.
+func (d *Daemon) apiRateLimitAllow(w http.ResponseWriter, r *http.Request, protocol string, username string) bool {
+	// Requests from cluster members and on the local socket are exempt.
+	if protocol == "cluster" || protocol == "unix" {
+		return true
+	}
+
+	limiters := d.apiRateLimiters.Load()
+	if limiters == nil {
+		return true
+	}
+
+	reject := func(limit string, wait time.Duration) bool {
+		logger.Warn("Rejecting API request over the rate limit", logger.Ctx{"limit": limit, "ip": r.RemoteAddr, "username": username, "protocol": protocol})
+
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int64(math.Ceil(wait.Seconds()))))
+		_ = response.ErrorResponse(http.StatusTooManyRequests, "Too many requests").Render(w)
+
+		return false
+	}
+
+	if limiters.address != nil {
+		address, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			address = r.RemoteAddr
+		}
+
+		ok, wait := limiters.address.Allow(address)
+		if !ok {
+			d.apiRateLimitedAddress.Add(1)
+			return reject("address", wait)
+		}
+	}
+
+	if limiters.identity != nil && username != "" {
+		ok, wait := limiters.identity.Allow(protocol + "/" + username)
+		if !ok {
+			d.apiRateLimitedIdentity.Add(1)
+			return reject("identity", wait)
+		}
+	}
+
+	return true
+}
+
+// apiRateLimitMetrics returns the metrics of the API requests rejected by the rate limits.
+func (d *Daemon) apiRateLimitMetrics() *metrics.MetricSet {
+	out := metrics.NewMetricSet(nil)
+
+	out.AddSamples(metrics.APIRateLimitedRequestsTotal,
+		metrics.Sample{Value: float64(d.apiRateLimitedAddress.Load()), Labels: map[string]string{"limit": "address"}},
+		metrics.Sample{Value: float64(d.apiRateLimitedIdentity.Load()), Labels: map[string]string{"limit": "identity"}},
+	)
+
+	return out
+}
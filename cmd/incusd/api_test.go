@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/response"
+)
+
+func TestStripPathPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		stripped bool
+		path     string
+		rawPath  string
+	}{
+		{"Prefixed", "/incus/1.0/instances", true, "/1.0/instances", ""},
+		{"Prefix only", "/incus", true, "/", ""},
+		{"Escaped path", "/incus/1.0/instances/c1%2Fsnap0", true, "/1.0/instances/c1/snap0", "/1.0/instances/c1%2Fsnap0"},
+		{"Not prefixed", "/1.0/instances", false, "/1.0/instances", ""},
+		{"Partial prefix", "/incusd/1.0", false, "/incusd/1.0", ""},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		u, err := url.Parse(tt.url)
+		require.NoError(t, err)
+
+		req := &http.Request{URL: u}
+		require.Equal(t, tt.stripped, stripPathPrefix(req, "/incus"))
+		require.Equal(t, tt.path, req.URL.Path)
+		require.Equal(t, tt.rawPath, req.URL.RawPath)
+	}
+}
+
+func TestPrefixResponseWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	w := &prefixResponseWriter{ResponseWriter: recorder, prefix: "/incus"}
+
+	// The prefix is provided to the responses holding URLs.
+	require.Equal(t, "/incus", response.PathPrefix(w))
+	require.Equal(t, "", response.PathPrefix(recorder))
+
+	// Relative locations get the prefix.
+	w.Header().Set("Location", "/1.0/operations/1234")
+	w.WriteHeader(http.StatusAccepted)
+	require.Equal(t, "/incus/1.0/operations/1234", recorder.Header().Get("Location"))
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	// Absolute locations are left alone.
+	recorder = httptest.NewRecorder()
+	w = &prefixResponseWriter{ResponseWriter: recorder, prefix: "/incus"}
+	w.Header().Set("Location", "//example.com/1.0")
+	_, err := w.Write([]byte("{}"))
+	require.NoError(t, err)
+	require.Equal(t, "//example.com/1.0", recorder.Header().Get("Location"))
+}
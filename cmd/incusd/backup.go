@@ -34,7 +34,7 @@ import (
 )
 
 // Create a new backup.
-func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.Instance, op *operations.Operation) error {
+func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.Instance, networkState bool, op *operations.Operation) error {
 	l := logger.AddContext(logger.Ctx{"project": sourceInst.Project().Name, "instance": sourceInst.Name(), "name": args.Name})
 	l.Debug("Instance backup started")
 	defer l.Debug("Instance backup finished")
@@ -183,7 +183,7 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 
 	// Write index file.
 	l.Debug("Adding backup index file")
-	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), tarWriter)
+	err = backupWriteIndex(s, sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), networkState, tarWriter)
 
 	// Check compression errors.
 	if compressErr != nil {
@@ -229,7 +229,8 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 }
 
 // backupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
-func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, tarWriter *instancewriter.InstanceTarWriter) error {
+// If networkState is true, the network dependencies of the instance are included in the index.
+func backupWriteIndex(s *state.State, sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, networkState bool, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
 	poolDriverOptimizedHeader := false
 	if optimized {
@@ -256,6 +257,13 @@ func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, opti
 		return fmt.Errorf("Failed generating instance backup config: %w", err)
 	}
 
+	if networkState {
+		config.Network, err = backupNetworkState(s, sourceInst)
+		if err != nil {
+			return fmt.Errorf("Failed generating instance network state: %w", err)
+		}
+	}
+
 	indexInfo := backup.Info{
 		Name:             sourceInst.Name(),
 		Pool:             pool.Name(),
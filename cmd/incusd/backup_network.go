@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	backupConfig "github.com/lxc/incus/v6/internal/server/backup/config"
+	clusterRequest "github.com/lxc/incus/v6/internal/server/cluster/request"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/network"
+	"github.com/lxc/incus/v6/internal/server/network/acl"
+	"github.com/lxc/incus/v6/internal/server/network/zone"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// backupNetworkNames returns the names of the managed networks the NICs of the instance are connected to.
+func backupNetworkNames(devices map[string]map[string]string) []string {
+	names := []string{}
+	for _, dev := range devices {
+		if dev["type"] != "nic" || dev["network"] == "" || slices.Contains(names, dev["network"]) {
+			continue
+		}
+
+		names = append(names, dev["network"])
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// backupNetworkState returns the network dependencies of the instance: the ACLs applied to its NICs and networks,
+// the forwards targeting its addresses and the DNS records pointing to them.
+func backupNetworkState(s *state.State, inst instance.Instance) (*backupConfig.NetworkState, error) {
+	networkProjectName, _, err := project.NetworkProject(s.DB.Cluster, inst.Project().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := inst.ExpandedDevices().CloneNative()
+
+	// Collect the addresses of the instance, both the static ones and those currently in use.
+	addresses := []string{}
+	addAddress := func(address string) {
+		ip := net.ParseIP(address)
+		if ip != nil && !slices.Contains(addresses, ip.String()) {
+			addresses = append(addresses, ip.String())
+		}
+	}
+
+	aclNames := []string{}
+	for _, dev := range devices {
+		if dev["type"] != "nic" {
+			continue
+		}
+
+		addAddress(dev["ipv4.address"])
+		addAddress(dev["ipv6.address"])
+
+		for _, aclName := range util.SplitNTrimSpace(dev["security.acls"], ",", -1, true) {
+			if !slices.Contains(aclNames, aclName) {
+				aclNames = append(aclNames, aclName)
+			}
+		}
+	}
+
+	if inst.IsRunning() {
+		hostInterfaces, _ := net.Interfaces()
+		instState, err := inst.RenderState(hostInterfaces)
+		if err == nil {
+			for _, nic := range instState.Network {
+				for _, address := range nic.Addresses {
+					if address.Scope == "global" {
+						addAddress(address.Address)
+					}
+				}
+			}
+		}
+	}
+
+	networkState := &backupConfig.NetworkState{}
+	zoneNames := []string{}
+
+	for _, networkName := range backupNetworkNames(devices) {
+		n, err := network.LoadByName(s, networkProjectName, networkName)
+		if err != nil {
+			if response.IsNotFoundError(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("Failed loading network %q: %w", networkName, err)
+		}
+
+		netConfig := n.Config()
+
+		for _, aclName := range util.SplitNTrimSpace(netConfig["security.acls"], ",", -1, true) {
+			if !slices.Contains(aclNames, aclName) {
+				aclNames = append(aclNames, aclName)
+			}
+		}
+
+		for _, key := range []string{"dns.zone.forward", "dns.zone.reverse.ipv4", "dns.zone.reverse.ipv6"} {
+			for _, zoneName := range util.SplitNTrimSpace(netConfig[key], ",", -1, true) {
+				if !slices.Contains(zoneNames, zoneName) {
+					zoneNames = append(zoneNames, zoneName)
+				}
+			}
+		}
+
+		var forwards map[int64]*api.NetworkForward
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			forwards, err = tx.GetNetworkForwards(ctx, n.ID(), false)
+
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading forwards of network %q: %w", networkName, err)
+		}
+
+		for _, forward := range forwards {
+			forward = backupNetworkForward(forward, addresses)
+			if forward != nil {
+				networkState.Forwards = append(networkState.Forwards, &backupConfig.NetworkForward{NetworkForward: *forward, Network: networkName})
+			}
+		}
+	}
+
+	sort.Strings(aclNames)
+	for _, aclName := range aclNames {
+		netACL, err := acl.LoadByName(s, networkProjectName, aclName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading network ACL %q: %w", aclName, err)
+		}
+
+		networkState.ACLs = append(networkState.ACLs, netACL.Info())
+	}
+
+	zoneProjectName, _, err := project.NetworkZoneProject(s.DB.Cluster, inst.Project().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zoneName := range zoneNames {
+		netZone, err := zone.LoadByNameAndProject(s, zoneProjectName, zoneName)
+		if err != nil {
+			if response.IsNotFoundError(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("Failed loading network zone %q: %w", zoneName, err)
+		}
+
+		records, err := netZone.GetRecords()
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading records of network zone %q: %w", zoneName, err)
+		}
+
+		for _, record := range records {
+			record := backupNetworkZoneRecord(record, addresses)
+			if record != nil {
+				networkState.DNSRecords = append(networkState.DNSRecords, &backupConfig.NetworkZoneRecord{NetworkZoneRecord: *record, Zone: zoneName})
+			}
+		}
+	}
+
+	return networkState, nil
+}
+
+// backupNetworkForward returns the part of the network forward targeting the given addresses, or nil if it doesn't
+// target any of them. The ports and the default target of other instances are left out.
+func backupNetworkForward(forward *api.NetworkForward, addresses []string) *api.NetworkForward {
+	filtered := *forward
+	filtered.Config = make(map[string]string, len(forward.Config))
+	filtered.Ports = []api.NetworkForwardPort{}
+
+	for key, value := range forward.Config {
+		if key == "target_address" && !slices.Contains(addresses, value) {
+			continue
+		}
+
+		filtered.Config[key] = value
+	}
+
+	for _, port := range forward.Ports {
+		if slices.Contains(addresses, port.TargetAddress) {
+			filtered.Ports = append(filtered.Ports, port)
+		}
+	}
+
+	if filtered.Config["target_address"] == "" && len(filtered.Ports) == 0 {
+		return nil
+	}
+
+	return &filtered
+}
+
+// backupNetworkZoneRecord returns the part of the DNS record pointing to the given addresses, or nil if it doesn't
+// point to any of them. The entries pointing elsewhere are left out.
+func backupNetworkZoneRecord(record api.NetworkZoneRecord, addresses []string) *api.NetworkZoneRecord {
+	filtered := record
+	filtered.Entries = []api.NetworkZoneRecordEntry{}
+
+	for _, entry := range record.Entries {
+		if slices.Contains(addresses, entry.Value) {
+			filtered.Entries = append(filtered.Entries, entry)
+		}
+	}
+
+	if len(filtered.Entries) == 0 {
+		return nil
+	}
+
+	return &filtered
+}
+
+// backupNetworkStateRestore recreates the network dependencies of an instance from its backup, leaving the existing
+// ones untouched. It returns the list of the dependencies which couldn't be restored, along with the networks of the
+// instance missing from the target. The restored dependencies are removed by the reverter.
+// The dependencies are only restored when restore is true, and are subject to the same permission and project checks
+// as when created through the API.
+func backupNetworkStateRestore(s *state.State, r *http.Request, projectName string, config *backupConfig.Config, restore bool, reverter *revert.Reverter) ([]string, error) {
+	networkProjectName, reqProject, err := project.NetworkProject(s.DB.Cluster, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	zoneProjectName, _, err := project.NetworkZoneProject(s.DB.Cluster, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	report := []string{}
+	reportf := func(format string, args ...any) {
+		message := fmt.Sprintf(format, args...)
+		logger.Warn("Instance backup network dependency not restored", logger.Ctx{"project": projectName, "instance": config.Container.Name, "err": message})
+		report = append(report, message)
+	}
+
+	for _, networkName := range backupNetworkNames(config.Container.ExpandedDevices) {
+		_, err := network.LoadByName(s, networkProjectName, networkName)
+		if err != nil {
+			reportf("Network %q isn't available: %v", networkName, err)
+		}
+	}
+
+	if config.Network == nil || !restore {
+		return report, nil
+	}
+
+	for _, backupACL := range config.Network.ACLs {
+		err := s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectProject(projectName), auth.EntitlementCanCreateNetworkACLs)
+		if err != nil {
+			reportf("Network ACL %q couldn't be created: %v", backupACL.Name, err)
+			continue
+		}
+
+		existing, err := acl.LoadByName(s, networkProjectName, backupACL.Name)
+		if err == nil {
+			if !reflect.DeepEqual(existing.Info().NetworkACLPut, backupACL.NetworkACLPut) {
+				reportf("Network ACL %q already exists with a different definition", backupACL.Name)
+			}
+
+			continue
+		} else if !response.IsNotFoundError(err) {
+			return nil, err
+		}
+
+		err = acl.Create(s, networkProjectName, &api.NetworkACLsPost{NetworkACLPost: api.NetworkACLPost{Name: backupACL.Name}, NetworkACLPut: backupACL.NetworkACLPut})
+		if err != nil {
+			reportf("Network ACL %q couldn't be created: %v", backupACL.Name, err)
+			continue
+		}
+
+		aclName := backupACL.Name
+		reverter.Add(func() {
+			netACL, err := acl.LoadByName(s, networkProjectName, aclName)
+			if err == nil {
+				_ = netACL.Delete()
+			}
+		})
+	}
+
+	for _, forward := range config.Network.Forwards {
+		n, err := network.LoadByName(s, networkProjectName, forward.Network)
+		if err == nil && !project.NetworkAllowed(reqProject.Config, forward.Network, n.IsManaged()) {
+			err = api.StatusErrorf(http.StatusNotFound, "Network not found")
+		}
+
+		if err == nil {
+			err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectNetwork(networkProjectName, forward.Network), auth.EntitlementCanEdit)
+		}
+
+		if err == nil && !n.Info().AddressForwards {
+			err = fmt.Errorf("Network driver %q does not support forwards", n.Type())
+		}
+
+		if err != nil {
+			reportf("Network forward %q of network %q couldn't be created: %v", forward.ListenAddress, forward.Network, err)
+			continue
+		}
+
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			_, _, err := tx.GetNetworkForward(ctx, n.ID(), false, forward.ListenAddress)
+
+			return err
+		})
+		if err == nil {
+			reportf("Network forward %q already exists on network %q", forward.ListenAddress, forward.Network)
+			continue
+		} else if !response.IsNotFoundError(err) {
+			return nil, err
+		}
+
+		err = n.ForwardCreate(api.NetworkForwardsPost{NetworkForwardPut: forward.NetworkForwardPut, ListenAddress: forward.ListenAddress}, clusterRequest.ClientTypeNormal)
+		if err != nil {
+			reportf("Network forward %q of network %q couldn't be created: %v", forward.ListenAddress, forward.Network, err)
+			continue
+		}
+
+		listenAddress := forward.ListenAddress
+		reverter.Add(func() { _ = n.ForwardDelete(listenAddress, clusterRequest.ClientTypeNormal) })
+	}
+
+	for _, record := range config.Network.DNSRecords {
+		netZone, err := zone.LoadByNameAndProject(s, zoneProjectName, record.Zone)
+		if err == nil {
+			err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectNetworkZone(zoneProjectName, record.Zone), auth.EntitlementCanEdit)
+		}
+
+		if err != nil {
+			reportf("DNS record %q of zone %q couldn't be created: %v", record.Name, record.Zone, err)
+			continue
+		}
+
+		_, err = netZone.GetRecord(record.Name)
+		if err == nil {
+			reportf("DNS record %q already exists in zone %q", record.Name, record.Zone)
+			continue
+		} else if !response.IsNotFoundError(err) {
+			return nil, err
+		}
+
+		err = netZone.AddRecord(api.NetworkZoneRecordsPost{NetworkZoneRecordPut: record.NetworkZoneRecordPut, Name: record.Name})
+		if err != nil {
+			reportf("DNS record %q of zone %q couldn't be created: %v", record.Name, record.Zone, err)
+			continue
+		}
+
+		recordName := record.Name
+		reverter.Add(func() { _ = netZone.DeleteRecord(recordName) })
+	}
+
+	return report, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestBackupNetworkNames(t *testing.T) {
+	devices := map[string]map[string]string{
+		"eth0": {"type": "nic", "network": "lan"},
+		"eth1": {"type": "nic", "network": "dmz"},
+		"eth2": {"type": "nic", "network": "lan"},
+		"eth3": {"type": "nic", "nictype": "macvlan", "parent": "enp5s0"},
+		"root": {"type": "disk", "path": "/", "pool": "default"},
+	}
+
+	require.Equal(t, []string{"dmz", "lan"}, backupNetworkNames(devices))
+}
+
+func TestBackupNetworkForward(t *testing.T) {
+	addresses := []string{"10.0.0.2", "fd42::2"}
+
+	forward := &api.NetworkForward{
+		ListenAddress: "192.0.2.1",
+		NetworkForwardPut: api.NetworkForwardPut{
+			Description: "Shared address",
+			Config:      map[string]string{"target_address": "10.0.0.3"},
+			Ports: []api.NetworkForwardPort{
+				{Protocol: "tcp", ListenPort: "80", TargetAddress: "10.0.0.2"},
+				{Protocol: "tcp", ListenPort: "443", TargetAddress: "10.0.0.3"},
+			},
+		},
+	}
+
+	// Only the port targeting the instance is kept, the default target of another instance is left out.
+	filtered := backupNetworkForward(forward, addresses)
+	require.NotNil(t, filtered)
+	require.Equal(t, "192.0.2.1", filtered.ListenAddress)
+	require.Equal(t, "Shared address", filtered.Description)
+	require.Empty(t, filtered.Config)
+	require.Equal(t, []api.NetworkForwardPort{{Protocol: "tcp", ListenPort: "80", TargetAddress: "10.0.0.2"}}, filtered.Ports)
+
+	// The original forward is left untouched.
+	require.Equal(t, "10.0.0.3", forward.Config["target_address"])
+	require.Len(t, forward.Ports, 2)
+
+	// A default target pointing to the instance is kept.
+	forward.Config["target_address"] = "fd42::2"
+	filtered = backupNetworkForward(forward, addresses)
+	require.NotNil(t, filtered)
+	require.Equal(t, map[string]string{"target_address": "fd42::2"}, filtered.Config)
+
+	// Forwards of other instances are skipped.
+	filtered = backupNetworkForward(forward, []string{"10.0.0.4"})
+	require.Nil(t, filtered)
+}
+
+func TestBackupNetworkZoneRecord(t *testing.T) {
+	addresses := []string{"10.0.0.2"}
+
+	record := api.NetworkZoneRecord{
+		Name: "www",
+		NetworkZoneRecordPut: api.NetworkZoneRecordPut{
+			Entries: []api.NetworkZoneRecordEntry{
+				{Type: "A", Value: "10.0.0.2"},
+				{Type: "A", Value: "10.0.0.3"},
+			},
+		},
+	}
+
+	filtered := backupNetworkZoneRecord(record, addresses)
+	require.NotNil(t, filtered)
+	require.Equal(t, "www", filtered.Name)
+	require.Equal(t, []api.NetworkZoneRecordEntry{{Type: "A", Value: "10.0.0.2"}}, filtered.Entries)
+	require.Len(t, record.Entries, 2)
+
+	filtered = backupNetworkZoneRecord(record, []string{"10.0.0.4"})
+	require.Nil(t, filtered)
+}
@@ -518,6 +518,14 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Tokens carry an expiry, don't issue or validate them with a skewed clock.
+	if req.Token || req.TrustToken != "" {
+		err = timeSkewCheck(s)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// Access check.
 	// Check if the user is already trusted.
 	trusted, _, _, err := d.Authenticate(nil, r)
@@ -16,14 +16,18 @@ import (
 	"os/user"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	dqliteClient "github.com/cowsql/go-cowsql/client"
 	"github.com/cowsql/go-cowsql/driver"
 	"github.com/gorilla/mux"
 	liblxc "github.com/lxc/go-lxc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/sys/unix"
 
 	internalIO "github.com/lxc/incus/v6/internal/io"
@@ -36,6 +40,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/auth/oidc"
 	"github.com/lxc/incus/v6/internal/server/bgp"
 	"github.com/lxc/incus/v6/internal/server/certificate"
+	"github.com/lxc/incus/v6/internal/server/cgroup"
 	"github.com/lxc/incus/v6/internal/server/cluster"
 	clusterConfig "github.com/lxc/incus/v6/internal/server/cluster/config"
 	"github.com/lxc/incus/v6/internal/server/daemon"
@@ -44,6 +49,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/db/query"
 	"github.com/lxc/incus/v6/internal/server/db/warningtype"
 	"github.com/lxc/incus/v6/internal/server/dns"
+	"github.com/lxc/incus/v6/internal/server/edge"
 	"github.com/lxc/incus/v6/internal/server/endpoints"
 	"github.com/lxc/incus/v6/internal/server/events"
 	"github.com/lxc/incus/v6/internal/server/firewall"
@@ -51,13 +57,16 @@ import (
 	"github.com/lxc/incus/v6/internal/server/instance"
 	instanceDrivers "github.com/lxc/incus/v6/internal/server/instance/drivers"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
 	"github.com/lxc/incus/v6/internal/server/loki"
+	"github.com/lxc/incus/v6/internal/server/lxcfs"
 	"github.com/lxc/incus/v6/internal/server/network/ovn"
 	"github.com/lxc/incus/v6/internal/server/network/ovs"
 	networkZone "github.com/lxc/incus/v6/internal/server/network/zone"
 	"github.com/lxc/incus/v6/internal/server/node"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/resources"
 	"github.com/lxc/incus/v6/internal/server/response"
 	scriptletLoad "github.com/lxc/incus/v6/internal/server/scriptlet/load"
 	"github.com/lxc/incus/v6/internal/server/seccomp"
@@ -68,9 +77,11 @@ import (
 	"github.com/lxc/incus/v6/internal/server/sys"
 	"github.com/lxc/incus/v6/internal/server/syslog"
 	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/internal/server/tracing"
 	"github.com/lxc/incus/v6/internal/server/ucred"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	"github.com/lxc/incus/v6/internal/server/warnings"
+	"github.com/lxc/incus/v6/internal/server/webhook"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
@@ -103,8 +114,9 @@ type Daemon struct {
 	clusterTasks task.Group
 
 	// Indexes of tasks that need to be reset when their execution interval changes
-	taskPruneImages      *task.Task
-	taskClusterHeartbeat *task.Task
+	taskPruneImages        *task.Task
+	taskClusterHeartbeat   *task.Task
+	taskMetricsRemoteWrite *task.Task
 
 	// Stores startup time of daemon
 	startTime time.Time
@@ -141,8 +153,10 @@ type Daemon struct {
 	// Device monitor for watching filesystem events
 	devmonitor fsmonitor.FSMonitor
 
-	// Keep track of skews.
-	timeSkew bool
+	// Keep track of skews (clock skew with the cluster in nanoseconds, zero when within the threshold) and of when
+	// it was last measured (in nanoseconds since the epoch).
+	timeSkew         atomic.Int64
+	timeSkewMeasured atomic.Int64
 
 	// Configuration.
 	globalConfig   *clusterConfig.Config
@@ -153,7 +167,8 @@ type Daemon struct {
 	serverName      string
 	serverClustered bool
 
-	lokiClient *loki.Client
+	lokiClient    *loki.Client
+	webhookClient *webhook.Client
 
 	// HTTP-01 challenge provider for ACME
 	http01Provider acme.HTTP01Provider
@@ -167,6 +182,22 @@ type Daemon struct {
 	// OVN clients.
 	ovnnb *ovn.NB
 	ovnsb *ovn.SB
+
+	// API rate limiting.
+	apiRateLimiters        atomic.Pointer[apiRateLimiters]
+	apiRateLimitedAddress  atomic.Uint64
+	apiRateLimitedIdentity atomic.Uint64
+
+	// API draining (standalone servers).
+	drainMu         sync.Mutex
+	drainSince      time.Time
+	drainRetryAfter int
+
+	// Edge mode state of the local member.
+	edge *edge.Member
+
+	// LXCFS supervision.
+	lxcfs *lxcfs.Manager
 }
 
 // DaemonConfig holds configuration values for Daemon.
@@ -199,6 +230,7 @@ func newDaemon(config *DaemonConfig, os *sys.OS) *Daemon {
 	}
 
 	d.serverCert = func() *localtls.CertInfo { return d.serverCertInt }
+	d.lxcfs = lxcfs.NewManager(d.lxcfsRemount, d.lxcfsInUse)
 
 	return d
 }
@@ -516,7 +548,9 @@ func (d *Daemon) State() *state.State {
 		LocalConfig:            localConfig,
 		ServerName:             d.serverName,
 		ServerClustered:        d.serverClustered,
+		Edge:                   d.edge,
 		StartTime:              d.startTime,
+		TimeSkew:               d.timeSkewCurrent(globalConfig),
 		Authorizer:             d.authorizer,
 		OVNNB:                  d.ovnnb,
 		OVNSB:                  d.ovnsb,
@@ -563,6 +597,35 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 			}
 		}
 
+		// Trace the handling of the request, only continuing the trace of the client when authenticated.
+		ctx, span := tracing.StartRequest(r, trusted, r.Method+" "+uri, attribute.String("http.request.method", r.Method), attribute.String("url.path", r.URL.Path))
+		defer span.End()
+
+		r = r.WithContext(ctx)
+
+		// Instance shares grant exec and console access to a single instance.
+		// Requests forwarded by other cluster members are checked against the share again.
+		shareForwarded := false
+		if version == "1.0" && r.Header.Get("X-Incus-share") != "" {
+			if protocol == "cluster" {
+				shareForwarded, err = d.instanceShareAuthenticateForwarded(r, c)
+			} else {
+				var shareUsername string
+				shareUsername, err = d.instanceShareAuthenticate(r, c, username)
+				if err == nil && shareUsername != "" {
+					trusted = true
+					username = shareUsername
+					protocol = instanceShareProtocol
+				}
+			}
+
+			if err != nil {
+				logger.Warn("Rejecting instance share request", logger.Ctx{"ip": r.RemoteAddr, "err": err})
+				_ = response.Forbidden(err).Render(w)
+				return
+			}
+		}
+
 		// Reject internal queries to remote, non-cluster, clients
 		if version == "internal" && !slices.Contains([]string{"unix", "cluster"}, protocol) {
 			// Except for the initial cluster accept request (done over trusted TLS)
@@ -573,6 +636,8 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 			}
 		}
 
+		span.SetAttributes(attribute.String("client.address", r.RemoteAddr), attribute.String("incus.protocol", protocol), attribute.String("enduser.id", username))
+
 		logCtx := logger.Ctx{"method": r.Method, "url": r.URL.RequestURI(), "ip": r.RemoteAddr, "protocol": protocol}
 		if protocol == "cluster" {
 			logCtx["fingerprint"] = username
@@ -655,6 +720,19 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 			return
 		}
 
+		// Return Unavailable Error (503) with the same exceptions while draining for maintenance.
+		draining, retryAfter := d.drainState()
+		if draining && !allowedDuringShutdown() {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			_ = response.Unavailable(fmt.Errorf("Server is draining for maintenance")).Render(w)
+			return
+		}
+
+		// Apply the API rate limits.
+		if !d.apiRateLimitAllow(w, r, protocol, username) {
+			return
+		}
+
 		handleRequest := func(action APIEndpointAction) response.Response {
 			if action.Handler == nil {
 				return response.NotImplemented(nil)
@@ -670,8 +748,12 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 				return response.Forbidden(errors.New("You must be authenticated"))
 			}
 
+			// Requests authenticated through an instance share, including those forwarded by other cluster members,
+			// were already checked against the endpoints the share covers.
+			shared := protocol == instanceShareProtocol || (protocol == "cluster" && shareForwarded)
+
 			// Call the access handler if there is one.
-			if action.AccessHandler != nil {
+			if action.AccessHandler != nil && !shared {
 				resp := action.AccessHandler(d, r)
 				if resp != response.EmptySyncResponse {
 					return resp
@@ -698,9 +780,14 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 			resp = response.NotFound(fmt.Errorf("Method %q not found", r.Method))
 		}
 
+		span.SetAttributes(attribute.String("incus.response", resp.String()))
+
 		// Handle errors
 		err = resp.Render(w)
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
 			writeErr := response.SmartError(err).Render(w)
 			if writeErr != nil {
 				logger.Error("Failed writing error for HTTP response", logger.Ctx{"url": uri, "err": err, "writeErr": writeErr})
@@ -813,13 +900,63 @@ func (d *Daemon) setupLoki(URL string, cert string, key string, caCert string, i
 	return nil
 }
 
+func (d *Daemon) setupWebhooks(urls []string, actions []string, payload string, secret string, retries int) error {
+	// Stop any existing webhook client.
+	if d.webhookClient != nil {
+		d.internalListener.RemoveHandler("webhooks")
+		d.webhookClient.Stop()
+		d.webhookClient = nil
+	}
+
+	// Check basic requirements for starting a new client.
+	if len(urls) == 0 {
+		return nil
+	}
+
+	// Start a new client, going through the current proxy configuration.
+	proxy := func(req *http.Request) (*url.URL, error) { return d.proxy(req) }
+
+	client, err := webhook.NewClient(d.shutdownCtx, urls, actions, payload, secret, retries, proxy)
+	if err != nil {
+		return err
+	}
+
+	d.webhookClient = client
+
+	// Attach the new client to the event handler.
+	d.internalListener.AddHandler("webhooks", d.webhookClient.HandleEvent)
+
+	return nil
+}
+
+// setupTracing configures the export of the spans to the OpenTelemetry collector, disabling it when the endpoint is empty.
+func (d *Daemon) setupTracing(endpoint string, headers map[string]string, caCert string, sampleRatio float64) error {
+	serverName := d.serverName
+	if !d.serverClustered {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+
+		serverName = hostname
+	}
+
+	return tracing.Configure(tracing.Config{
+		Endpoint:      endpoint,
+		Headers:       headers,
+		CACertificate: caCert,
+		SampleRatio:   sampleRatio,
+		ServerName:    serverName,
+	})
+}
+
 func (d *Daemon) init() error {
 	var err error
 
 	var dbWarnings []dbCluster.Warning
 
 	// Set default authorizer.
-	d.authorizer, err = auth.LoadAuthorizer(d.shutdownCtx, auth.DriverTLS, logger.Log, d.clientCerts)
+	d.authorizer, err = auth.LoadAuthorizer(d.shutdownCtx, auth.DriverTLS, logger.Subsystem("auth"), d.clientCerts)
 	if err != nil {
 		return err
 	}
@@ -1149,6 +1286,14 @@ func (d *Daemon) init() error {
 		return err
 	}
 
+	// Load the state cached for edge mode.
+	d.edge, err = edge.NewMember(filepath.Join(d.os.VarDir, "edge"))
+	if err != nil {
+		logger.Warn("Failed loading edge state", logger.Ctx{"err": err})
+	}
+
+	d.edge.SetEnabled(d.serverClustered && d.localConfig.ClusterEdge())
+
 	localHTTPAddress := d.localConfig.HTTPSAddress()
 	localClusterAddress := d.localConfig.ClusterAddress()
 	debugAddress := d.localConfig.DebugAddress()
@@ -1271,6 +1416,9 @@ func (d *Daemon) init() error {
 			return err
 		}
 
+		// Keep the configuration change of a canary member on top.
+		config = clusterConfigCanaryOverlay(config, nil)
+
 		// Get the local node (will be used if clustered).
 		serverName, err := tx.GetLocalNodeName(ctx)
 		if err != nil {
@@ -1329,6 +1477,9 @@ func (d *Daemon) init() error {
 			return err
 		}
 
+		// Keep the configuration change of a canary member on top.
+		config = clusterConfigCanaryOverlay(config, nil)
+
 		// Get the local node (will be used if clustered).
 		serverName, err := tx.GetLocalNodeName(ctx)
 		if err != nil {
@@ -1348,6 +1499,12 @@ func (d *Daemon) init() error {
 
 	d.events.SetLocalLocation(d.serverName)
 
+	// Resume or drop the configuration change of a canary member.
+	err = clusterConfigCanaryResume(d)
+	if err != nil {
+		return err
+	}
+
 	// Get daemon configuration.
 	bgpAddress := d.localConfig.BGPAddress()
 	bgpRouterID := d.localConfig.BGPRouterID()
@@ -1364,12 +1521,32 @@ func (d *Daemon) init() error {
 	lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes := d.globalConfig.LokiServer()
 	oidcIssuer, oidcClientID, oidcAudience, oidcClaim := d.globalConfig.OIDCServer()
 	syslogSocketEnabled := d.localConfig.SyslogSocket()
+	coreDumpsEnabled := d.localConfig.CoreDumps()
+	limitsReserveCPU, limitsReserveMemory := d.localConfig.LimitsReserve()
+	lxcfsManaged, lxcfsLoadAvg, lxcfsCPUView := d.localConfig.LXCFS()
 	openfgaAPIURL, openfgaAPIToken, openfgaStoreID := d.globalConfig.OpenFGA()
 	instancePlacementScriptlet := d.globalConfig.InstancesPlacementScriptlet()
+	eventsJournalSize := d.globalConfig.EventsJournalSize()
+	eventsLifecycleEnrichment := d.globalConfig.EventsLifecycleEnrichment()
+	tracingEndpoint, tracingHeaders, tracingCACert, tracingSampleRatio := d.globalConfig.Tracing()
+	webhookURLs, webhookActions, webhookPayload, webhookSecret, webhookRetries := d.globalConfig.EventsWebhooks()
+	apiRateLimitAddress, apiRateLimitAddressBurst, apiRateLimitIdentity, apiRateLimitIdentityBurst := d.globalConfig.APIRateLimits()
 
 	d.endpoints.NetworkUpdateTrustedProxy(d.globalConfig.HTTPSTrustedProxy())
 	d.globalConfigMu.Unlock()
 
+	// Setup the API rate limits.
+	err = d.setupAPIRateLimits(apiRateLimitAddress, apiRateLimitAddressBurst, apiRateLimitIdentity, apiRateLimitIdentityBurst)
+	if err != nil {
+		return err
+	}
+
+	// Setup the event journal.
+	err = d.events.SetJournal(internalUtil.VarPath("events.journal"), eventsJournalSize)
+	if err != nil {
+		return err
+	}
+
 	// Setup Loki logger.
 	if lokiURL != "" {
 		err = d.setupLoki(lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes)
@@ -1378,6 +1555,21 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Setup lifecycle event enrichment.
+	lifecycle.SetInstanceEnrichment(eventsLifecycleEnrichment)
+
+	// Setup tracing.
+	err = d.setupTracing(tracingEndpoint, tracingHeaders, tracingCACert, tracingSampleRatio)
+	if err != nil {
+		return err
+	}
+
+	// Setup webhooks.
+	err = d.setupWebhooks(webhookURLs, webhookActions, webhookPayload, webhookSecret, webhookRetries)
+	if err != nil {
+		return err
+	}
+
 	// Setup syslog listener.
 	if syslogSocketEnabled {
 		err = d.setupSyslogSocket(true)
@@ -1386,6 +1578,38 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Setup instance syslog listener.
+	if !d.os.MockMode {
+		err = syslog.ListenInstances(d.shutdownCtx, d.instanceSyslogSender, d.instanceSyslogHandler)
+		if err != nil {
+			logger.Warn("Failed setting up instance syslog socket", logger.Ctx{"err": err})
+		}
+	}
+
+	// Setup core dump handler.
+	if coreDumpsEnabled {
+		err = d.setupCoreDumps(true)
+		if err != nil {
+			logger.Warn("Failed setting up core dump handler", logger.Ctx{"err": err})
+		}
+	}
+
+	// Setup the host resource reservation.
+	if !d.os.MockMode {
+		err = d.setupLimitsReserve(limitsReserveCPU, limitsReserveMemory)
+		if err != nil {
+			logger.Warn("Failed reserving host resources", logger.Ctx{"err": err})
+		}
+	}
+
+	// Setup LXCFS supervision.
+	if !d.os.MockMode {
+		err = d.setupLXCFS(lxcfsManaged, lxcfsLoadAvg, lxcfsCPUView)
+		if err != nil {
+			logger.Warn("Failed setting up LXCFS", logger.Ctx{"err": err})
+		}
+	}
+
 	// Setup OIDC authentication.
 	if oidcIssuer != "" && oidcClientID != "" {
 		d.oidcVerifier, err = oidc.NewVerifier(oidcIssuer, oidcClientID, oidcAudience, oidcClaim)
@@ -1488,6 +1712,12 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Report the firewall rules required by the services configured to listen on the network.
+	err = firewallServicesUpdate(d.State())
+	if err != nil {
+		logger.Warn("Failed getting required service firewall rules", logger.Ctx{"err": err})
+	}
+
 	// Load instance placement scriptlet.
 	if instancePlacementScriptlet != "" {
 		err = scriptletLoad.InstancePlacementSet(instancePlacementScriptlet)
@@ -1605,22 +1835,71 @@ func (d *Daemon) init() error {
 		// Remove expired backups (hourly)
 		d.tasks.Add(pruneExpiredBackupsTask(d))
 
+		// Purge expired recycle bin entries (hourly)
+		d.tasks.Add(recycleBinPurgeTask(d))
+
 		// Prune expired instance snapshots and take snapshot of instances (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateInstanceSnapshotsTask(d))
 
 		// Prune expired custom volume snapshots and take snapshots of custom volumes (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d))
 
+		// Send custom volumes to their transfer target (minutely check of configurable cron expression)
+		d.tasks.Add(autoTransferCustomVolumesTask(d))
+
+		// Refresh instances from their source (minutely check of configurable cron expression)
+		d.tasks.Add(autoRefreshInstancesTask(d))
+
 		// Remove resolved warnings (daily)
 		d.tasks.Add(pruneResolvedWarningsTask(d))
 
+		// Cache the state assigned to edge members (every minute)
+		d.tasks.Add(edgeCacheTask(d))
+
+		// Resolve warnings whose condition has cleared (every 5 minutes)
+		d.tasks.Add(autoResolveWarningsTask(d))
+
 		// Auto-renew server certificate (daily)
 		d.tasks.Add(autoRenewCertificateTask(d))
 
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Detect idle instances (every 10 minutes)
+		d.tasks.Add(instanceIdleDetectionTask(d))
+
+		// Suspend idle instances (every minute)
+		d.tasks.Add(instanceIdleSuspendTask(d))
+
+		// Check instance limits (every minute)
+		d.tasks.Add(instanceLimitsTask(d))
+
+		// Run instance health checks (every 5 seconds)
+		d.tasks.Add(instanceHealthTask(d))
+
+		// Check for out-of-memory kills (every 30 seconds)
+		d.tasks.Add(instanceOOMTask(d))
+
+		// Trim the console logs of virtual machines (every minute)
+		d.tasks.Add(instanceConsoleLogTrimTask(d))
+
+		// Check storage pool health (every 10 seconds)
+		d.tasks.Add(storagePoolHealthTask(d))
+
+		// Fill the instance pools (every 10 seconds)
+		d.tasks.Add(instancePoolsFillTask(d))
+
+		// Update the socket activated instances (every 5 seconds)
+		d.tasks.Add(instanceActivationTask(d))
+		d.internalListener.AddHandler("activation", instanceActivationHandleEvent)
+
+		// Push metrics to Prometheus (every minute, configurable)
+		d.taskMetricsRemoteWrite = d.tasks.Add(metricsRemoteWriteTask(d))
 	}
 
+	// Pick up the instances frozen on memory pressure before the limits task runs
+	instanceLimitsRestore(instances)
+
 	// Start all background tasks
 	d.tasks.Start(d.shutdownCtx)
 
@@ -1655,6 +1934,9 @@ func (d *Daemon) startClusterTasks() {
 	// Perform automatic evacuation for offline cluster members
 	d.clusterTasks.Add(autoHealClusterTask(d))
 
+	// Resolve the clock skew once it's no longer measured
+	d.clusterTasks.Add(timeSkewExpireTask(d))
+
 	// Start all background tasks
 	d.clusterTasks.Start(d.shutdownCtx)
 }
@@ -1698,6 +1980,9 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 	// Stop any running minio processes cleanly before unmount storage pools.
 	miniod.StopAll()
 
+	// Stop supervising LXCFS, leaving it running for the containers.
+	d.lxcfs.Stop()
+
 	var err error
 	var instances []instance.Instance
 	var instancesLoaded bool // If this is left as false this indicates an error loading instances.
@@ -1803,6 +2088,9 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 	trackError(d.tasks.Stop(3*time.Second), "Stop tasks")                // Give tasks a bit of time to cleanup.
 	trackError(d.clusterTasks.Stop(3*time.Second), "Stop cluster tasks") // Give tasks a bit of time to cleanup.
 
+	// Export the remaining spans.
+	tracing.Shutdown()
+
 	n := d.numRunningInstances(instances)
 	shouldUnmount := instancesLoaded && n <= 0
 
@@ -1871,7 +2159,7 @@ func (d *Daemon) setupOpenFGA(apiURL string, apiToken string, storeID string) er
 
 	if apiURL == "" || apiToken == "" || storeID == "" {
 		// Reset to default authorizer.
-		d.authorizer, err = auth.LoadAuthorizer(d.shutdownCtx, auth.DriverTLS, logger.Log, d.clientCerts)
+		d.authorizer, err = auth.LoadAuthorizer(d.shutdownCtx, auth.DriverTLS, logger.Subsystem("auth"), d.clientCerts)
 		if err != nil {
 			return err
 		}
@@ -1890,7 +2178,7 @@ func (d *Daemon) setupOpenFGA(apiURL string, apiToken string, storeID string) er
 
 	revert.Add(func() {
 		// Reset to default authorizer.
-		d.authorizer, _ = auth.LoadAuthorizer(d.shutdownCtx, auth.DriverTLS, logger.Log, d.clientCerts)
+		d.authorizer, _ = auth.LoadAuthorizer(d.shutdownCtx, auth.DriverTLS, logger.Subsystem("auth"), d.clientCerts)
 	})
 
 	// Build the list of resources to update the model.
@@ -2122,7 +2410,7 @@ func (d *Daemon) setupOpenFGA(apiURL string, apiToken string, storeID string) er
 		return &resources, nil
 	}
 
-	openfgaAuthorizer, err := auth.LoadAuthorizer(d.shutdownCtx, auth.DriverOpenFGA, logger.Log, d.clientCerts, auth.WithConfig(config), auth.WithResourcesFunc(refreshResources))
+	openfgaAuthorizer, err := auth.LoadAuthorizer(d.shutdownCtx, auth.DriverOpenFGA, logger.Subsystem("auth"), d.clientCerts, auth.WithConfig(config), auth.WithResourcesFunc(refreshResources))
 	if err != nil {
 		return err
 	}
@@ -2159,6 +2447,189 @@ func (d *Daemon) setupSyslogSocket(enable bool) error {
 	return nil
 }
 
+// setupLimitsReserve restricts the instances parent cgroup to the host resources left once the reserved CPUs and
+// memory are carved out, moves the running VMs under it, then re-balances the containers off the reserved CPUs.
+func (d *Daemon) setupLimitsReserve(reservedCPUs string, reservedMemory int64) error {
+	cpus := ""
+	if reservedCPUs != "" {
+		reserved, err := resources.ParseCpuset(reservedCPUs)
+		if err != nil {
+			return err
+		}
+
+		cg, err := cgroup.NewFileReadWriter(1, true)
+		if err != nil {
+			return fmt.Errorf("Unable to load cgroup writer: %w", err)
+		}
+
+		effectiveCPUs, err := cg.GetEffectiveCpuset()
+		if err != nil {
+			return fmt.Errorf("Failed getting the CPUs of the host: %w", err)
+		}
+
+		effective, err := resources.ParseCpuset(effectiveCPUs)
+		if err != nil {
+			return err
+		}
+
+		available := []string{}
+		for _, id := range effective {
+			if !slices.Contains(reserved, id) {
+				available = append(available, strconv.FormatInt(id, 10))
+			}
+		}
+
+		if len(available) == 0 {
+			return fmt.Errorf("Cannot reserve all the CPUs of the host")
+		}
+
+		cpus = strings.Join(available, ",")
+	}
+
+	memoryMax := int64(0)
+	if reservedMemory > 0 {
+		totalMemory, err := linux.DeviceTotalMemory()
+		if err != nil {
+			return fmt.Errorf("Failed getting the memory of the host: %w", err)
+		}
+
+		if reservedMemory >= totalMemory {
+			return fmt.Errorf("Cannot reserve all the memory of the host")
+		}
+
+		memoryMax = totalMemory - reservedMemory
+	}
+
+	err := cgroup.SetInstancesReservation(cpus, memoryMax)
+	if err != nil {
+		return err
+	}
+
+	// Move the running VMs under the instances parent cgroup, those started before the reservation being outside of it.
+	// The cgroup of a running container can't be moved, the CPU scheduler keeps them off the reserved CPUs instead.
+	if cgroup.InstancesParentEnabled() {
+		insts, err := instance.LoadNodeAll(d.State(), instancetype.VM)
+		if err != nil {
+			return fmt.Errorf("Failed loading the virtual machines: %w", err)
+		}
+
+		for _, inst := range insts {
+			pid := inst.InitPID()
+			if pid <= 0 {
+				continue
+			}
+
+			err = cgroup.AddToInstancesParent(cgroup.VMInstancesParentName(project.Instance(inst.Project().Name, inst.Name())), pid)
+			if err != nil {
+				logger.Warn("Failed moving the VM into the instances parent cgroup", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			}
+		}
+	}
+
+	cgroup.TaskSchedulerTrigger("reservation", "", "changed")
+
+	return nil
+}
+
+// setupLXCFS starts or stops the supervision of LXCFS.
+func (d *Daemon) setupLXCFS(managed bool, loadAvg bool, cpuView bool) error {
+	if !managed {
+		d.lxcfs.Disable()
+		return nil
+	}
+
+	return d.lxcfs.Enable(lxcfs.Options{LoadAvg: loadAvg, CPUView: cpuView})
+}
+
+// lxcfsRemount mounts the views of a freshly started LXCFS in the running containers.
+func (d *Daemon) lxcfsRemount() {
+	s := d.State()
+
+	instances, err := instance.LoadNodeAll(s, instancetype.Container)
+	if err != nil {
+		logger.Warn("Failed loading containers to mount LXCFS into", logger.Ctx{"err": err})
+		return
+	}
+
+	for _, inst := range instances {
+		if !inst.IsRunning() {
+			continue
+		}
+
+		c, ok := inst.(instance.Container)
+		if !ok {
+			continue
+		}
+
+		err = c.RemountLXCFS()
+		if err != nil {
+			logger.Warn("Failed mounting LXCFS into container", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+		}
+	}
+}
+
+// lxcfsInUse returns whether running containers use the views of LXCFS, which are then kept until it restarts.
+func (d *Daemon) lxcfsInUse() bool {
+	instances, err := instance.LoadNodeAll(d.State(), instancetype.Container)
+	if err != nil {
+		return true
+	}
+
+	for _, inst := range instances {
+		if inst.IsRunning() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// instanceSyslogSender returns the container the process belongs to, for the instance syslog listener.
+func (d *Daemon) instanceSyslogSender(pid int32) (*syslog.Sender, error) {
+	c, err := findContainerForPid(pid, d.State())
+	if err != nil {
+		return nil, err
+	}
+
+	return &syslog.Sender{
+		Project:  c.Project().Name,
+		Instance: c.Name(),
+		Enabled:  util.IsTrue(c.ExpandedConfig()["logging.syslog"]),
+	}, nil
+}
+
+// instanceSyslogHandler forwards the syslog messages of containers with logging.syslog enabled as instance log events.
+func (d *Daemon) instanceSyslogHandler(sender syslog.Sender, msg syslog.Message) {
+	ctx := map[string]string{
+		"instance": sender.Instance,
+		"project":  sender.Project,
+		"facility": strconv.Itoa(msg.Facility),
+	}
+
+	if msg.Hostname != "" {
+		ctx["hostname"] = msg.Hostname
+	}
+
+	if msg.Application != "" {
+		ctx["application"] = msg.Application
+	}
+
+	if msg.ProcessID != "" {
+		ctx["pid"] = msg.ProcessID
+	}
+
+	event := api.EventLogging{
+		Level:   msg.Level().String(),
+		Message: msg.Text,
+		Context: ctx,
+	}
+
+	err := d.events.Send(sender.Project, api.EventTypeInstanceLog, event)
+	if err != nil {
+		logger.Debug("Failed sending instance log event", logger.Ctx{"instance": sender.Instance, "project": sender.Project, "err": err})
+	}
+}
+
 // Create a database connection and perform any updates needed.
 func initializeDbObject(d *Daemon) error {
 	logger.Info("Initializing local database")
@@ -2218,36 +2689,10 @@ func (d *Daemon) heartbeatHandler(w http.ResponseWriter, r *http.Request, isLead
 
 	// Look for time skews.
 	now := time.Now().UTC()
+	d.timeSkewUpdate(s, now.Sub(hbData.Time), fmt.Sprintf("leaderTime: %s, localTime: %s", hbData.Time, now))
 
-	if hbData.Time.Add(5*time.Second).Before(now) || hbData.Time.Add(-5*time.Second).After(now) {
-		if !d.timeSkew {
-			logger.Warn("Time skew detected between leader and local", logger.Ctx{"leaderTime": hbData.Time, "localTime": now})
-
-			if d.db.Cluster != nil {
-				err := d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-					return tx.UpsertWarningLocalNode(ctx, "", -1, -1, warningtype.ClusterTimeSkew, fmt.Sprintf("leaderTime: %s, localTime: %s", hbData.Time, now))
-				})
-				if err != nil {
-					logger.Warn("Failed to create cluster time skew warning", logger.Ctx{"err": err})
-				}
-			}
-		}
-
-		d.timeSkew = true
-	} else {
-		if d.timeSkew {
-			logger.Warn("Time skew resolved")
-
-			if d.db.Cluster != nil {
-				err := warnings.ResolveWarningsByLocalNodeAndType(d.db.Cluster, warningtype.ClusterTimeSkew)
-				if err != nil {
-					logger.Warn("Failed to resolve cluster time skew warning", logger.Ctx{"err": err})
-				}
-			}
-
-			d.timeSkew = false
-		}
-	}
+	// Record the contact with the cluster for edge mode.
+	d.edgeContact()
 
 	// Extract the raft nodes from the heartbeat info.
 	raftNodes := make([]db.RaftNode, 0)
@@ -2321,6 +2766,17 @@ func (d *Daemon) nodeRefreshTask(heartbeatData *cluster.APIHeartbeat, isLeader b
 		return
 	}
 
+	// The leader doesn't receive heartbeats, sending them is its contact with the cluster.
+	// It measures its clock against the members it sends them to instead.
+	if isLeader {
+		d.edgeContact()
+
+		skew, measured := heartbeatData.ClockSkew()
+		if measured {
+			d.timeSkewUpdate(s, skew, "skew measured against the other cluster members")
+		}
+	}
+
 	localClusterAddress := s.LocalConfig.ClusterAddress()
 
 	if !heartbeatData.FullStateList || len(heartbeatData.Members) <= 0 {
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/lxc/incus/v6/client"
@@ -28,10 +29,14 @@ import (
 	"github.com/lxc/incus/v6/shared/cancel"
 	"github.com/lxc/incus/v6/shared/ioprogress"
 	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/osarch"
 	"github.com/lxc/incus/v6/shared/units"
 	"github.com/lxc/incus/v6/shared/util"
 )
 
+// imageDownloadRetries is the maximum number of times an interrupted image download is resumed.
+const imageDownloadRetries = 5
+
 // ImageDownloadArgs used with ImageDownload.
 type ImageDownloadArgs struct {
 	ProjectName       string
@@ -48,6 +53,7 @@ type ImageDownloadArgs struct {
 	StoragePool       string
 	Budget            int64
 	SourceProjectName string
+	Architecture      string
 }
 
 // imageOperationLock acquires a lock for operating on an image and returns the unlock function.
@@ -59,6 +65,34 @@ func imageOperationLock(ctx context.Context, fingerprint string) (locking.Unlock
 	return locking.Lock(ctx, fmt.Sprintf("ImageOperation_%s", fingerprint))
 }
 
+// imageAliasTargetForArchitecture returns the target of a remote alias for the given architecture.
+// An empty string is returned when the name isn't an alias.
+func imageAliasTargetForArchitecture(remote incus.ImageServer, imageType string, name string, architecture string) (string, error) {
+	architectureID, err := osarch.ArchitectureId(architecture)
+	if err != nil {
+		return "", fmt.Errorf("Invalid architecture %q: %w", architecture, err)
+	}
+
+	entries, err := remote.GetImageAliasArchitectures(imageType, name)
+	if err != nil {
+		return "", nil
+	}
+
+	available := make([]string, 0, len(entries))
+	for entryArchitecture, entry := range entries {
+		id, err := osarch.ArchitectureId(entryArchitecture)
+		if err == nil && id == architectureID {
+			return entry.Target, nil
+		}
+
+		available = append(available, entryArchitecture)
+	}
+
+	slices.Sort(available)
+
+	return "", api.StatusErrorf(http.StatusBadRequest, "Image %q isn't available for the %q architecture (available: %s)", name, architecture, strings.Join(available, ", "))
+}
+
 // ImageDownload resolves the image fingerprint and if not in the database, downloads it.
 func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *operations.Operation, args *ImageDownloadArgs) (*api.Image, error) {
 	var err error
@@ -110,10 +144,21 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 
 		// For public images, handle aliases and initial metadata
 		if args.Secret == "" {
-			// Look for a matching alias
-			entry, _, err := remote.GetImageAliasType(args.Type, fp)
-			if err == nil {
-				fp = entry.Target
+			// Look for a matching alias, for the requested architecture if any.
+			if args.Architecture != "" {
+				target, err := imageAliasTargetForArchitecture(remote, args.Type, fp, args.Architecture)
+				if err != nil {
+					return nil, err
+				}
+
+				if target != "" {
+					fp = target
+				}
+			} else {
+				entry, _, err := remote.GetImageAliasType(args.Type, fp)
+				if err == nil {
+					fp = entry.Target
+				}
 			}
 
 			// Expand partial fingerprints
@@ -126,6 +171,17 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 		}
 	}
 
+	// Images built from Git recipes are identified by the commit of their recipe until built.
+	var recipe *imageGitRecipe
+	if protocol == "git" {
+		recipe, err = imageGitFetch(ctx, args.Server, alias)
+		if err != nil {
+			return nil, err
+		}
+
+		fp = recipe.commit
+	}
+
 	// Ensure we are the only ones operating on this image.
 	unlock, err := imageOperationLock(ctx, fp)
 	if err != nil {
@@ -134,6 +190,18 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 
 	defer unlock()
 
+	// Reuse the image built from the same commit, which may have completed while waiting for the lock.
+	if recipe != nil {
+		fingerprint, err := imageGitResolve(ctx, s, r, args, recipe)
+		if err != nil {
+			return nil, err
+		}
+
+		if fingerprint != "" {
+			fp = fingerprint
+		}
+	}
+
 	// If auto-update is on and we're being given the image by
 	// alias, try to use a locally cached image matching the given
 	// server/protocol/alias, regardless of whether it's stale or
@@ -143,7 +211,17 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 
 	if args.PreferCached && interval > 0 && alias != fp {
 		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-			for _, architecture := range s.OS.Architectures {
+			architectures := s.OS.Architectures
+			if args.Architecture != "" {
+				architectureID, err := osarch.ArchitectureId(args.Architecture)
+				if err != nil {
+					return err
+				}
+
+				architectures = []int{architectureID}
+			}
+
+			for _, architecture := range architectures {
 				cachedFingerprint, err := tx.GetCachedImageSourceFingerprint(ctx, args.Server, args.Protocol, alias, args.Type, architecture)
 				if err == nil && cachedFingerprint != fp {
 					fp = cachedFingerprint
@@ -163,6 +241,17 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 		// Check if the image already exists in this project (partial hash match).
 		_, imgInfo, err = tx.GetImage(ctx, fp, cluster.ImageFilter{Project: &args.ProjectName})
+		if err == nil || !response.IsNotFoundError(err) || info == nil {
+			return err
+		}
+
+		// Otherwise look for a converted copy of the source image.
+		localFingerprint, err := tx.GetImageFingerprintFromSource(ctx, args.ProjectName, args.Server, fp)
+		if err != nil {
+			return err
+		}
+
+		_, imgInfo, err = tx.GetImage(ctx, localFingerprint, cluster.ImageFilter{Project: &args.ProjectName})
 
 		return err
 	})
@@ -313,11 +402,19 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 	destDir := internalUtil.VarPath("images")
 	destName := filepath.Join(destDir, fp)
 
+	// Simplestreams downloads are resumed from the partial files left by a past attempt.
+	resumable := protocol == "simplestreams"
+
 	failure := true
 	cleanup := func() {
 		if failure {
 			_ = os.Remove(destName)
 			_ = os.Remove(destName + ".rootfs")
+
+			if !resumable {
+				_ = os.Remove(destName + ".partial")
+				_ = os.Remove(destName + ".rootfs.partial")
+			}
 		}
 	}
 	defer cleanup()
@@ -339,6 +436,23 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 		}
 	}
 
+	// Setup a retry handler
+	retry := func(attempt int, err error) {
+		logger.Warn("Resuming interrupted image download", logger.Ctx{"fingerprint": fp, "attempt": attempt, "err": err})
+
+		if op == nil {
+			return
+		}
+
+		meta := op.Metadata()
+		if meta == nil {
+			meta = make(map[string]any)
+		}
+
+		meta["download_retries"] = attempt
+		_ = op.UpdateMetadata(meta)
+	}
+
 	var canceler *cancel.HTTPRequestCanceller
 	if op != nil {
 		canceler = cancel.NewHTTPRequestCanceller()
@@ -346,15 +460,20 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 	}
 
 	if slices.Contains([]string{"incus", "lxd", "simplestreams"}, protocol) {
-		// Create the target files
-		dest, err := os.Create(destName)
+		// Create the target files, keeping the content of partial downloads when resuming them.
+		flags := os.O_RDWR | os.O_CREATE
+		if !resumable {
+			flags |= os.O_TRUNC
+		}
+
+		dest, err := os.OpenFile(destName+".partial", flags, 0666)
 		if err != nil {
 			return nil, err
 		}
 
 		defer func() { _ = dest.Close() }()
 
-		destRootfs, err := os.Create(destName + ".rootfs")
+		destRootfs, err := os.OpenFile(destName+".rootfs.partial", flags, 0666)
 		if err != nil {
 			return nil, err
 		}
@@ -396,6 +515,9 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 			RootfsFile:      io.WriteSeeker(destRootfs),
 			ProgressHandler: progress,
 			Canceler:        canceler,
+			Resume:          resumable,
+			Retries:         imageDownloadRetries,
+			RetryHandler:    retry,
 			DeltaSourceRetriever: func(fingerprint string, file string) string {
 				path := internalUtil.VarPath("images", fmt.Sprintf("%s.%s", fingerprint, file))
 				if util.PathExists(path) {
@@ -429,14 +551,6 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 			return nil, err
 		}
 
-		// Deal with unified images
-		if resp.RootfsSize == 0 {
-			err := os.Remove(destName + ".rootfs")
-			if err != nil {
-				return nil, err
-			}
-		}
-
 		err = dest.Close()
 		if err != nil {
 			return nil, err
@@ -446,6 +560,25 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 		if err != nil {
 			return nil, err
 		}
+
+		// Move the complete files into place.
+		err = os.Rename(destName+".partial", destName)
+		if err != nil {
+			return nil, err
+		}
+
+		// Deal with unified images
+		if resp.RootfsSize == 0 {
+			err := os.Remove(destName + ".rootfs.partial")
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			err = os.Rename(destName+".rootfs.partial", destName+".rootfs")
+			if err != nil {
+				return nil, err
+			}
+		}
 	} else if protocol == "direct" {
 		// Setup HTTP client
 		httpClient, err := localUtil.HTTPClient(args.Certificate, s.Proxy)
@@ -536,6 +669,14 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 		if err != nil {
 			return nil, err
 		}
+	} else if protocol == "git" {
+		info, err = imageGitBuild(ctx, s, r, op, args, recipe)
+		if err != nil {
+			return nil, err
+		}
+
+		fp = info.Fingerprint
+		destName = filepath.Join(destDir, fp)
 	} else {
 		return nil, fmt.Errorf("Unsupported protocol: %v", protocol)
 	}
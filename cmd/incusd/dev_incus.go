@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -11,11 +12,13 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	"golang.org/x/sys/unix"
 
 	"github.com/lxc/incus/v6/internal/linux"
+	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/events"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
@@ -260,6 +263,74 @@ var devIncusDevicesGet = devIncusHandler{"/1.0/devices", func(d *Daemon, c insta
 	return response.DevIncusResponse(http.StatusOK, c.ExpandedDevices(), "json", c.Type() == instancetype.VM)
 }}
 
+var devIncusLeaseHandler = devIncusHandler{"/1.0/leases/{name}", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if util.IsFalse(c.ExpandedConfig()["security.guestapi"]) {
+		return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	if util.IsFalseOrEmpty(c.ExpandedConfig()["security.guestapi.leases"]) {
+		return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil || name == "" {
+		return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusBadRequest, "bad request"), c.Type() == instancetype.VM)
+	}
+
+	s := d.State()
+	projectName := c.Project().Name
+
+	var lease *db.Lease
+
+	switch r.Method {
+	case "GET":
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			lease, err = tx.GetLease(ctx, projectName, name)
+			return err
+		})
+	case "PUT":
+		req := apiGuest.DevIncusLeasePut{}
+
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusBadRequest, err.Error()), c.Type() == instancetype.VM)
+		}
+
+		if req.Duration < 1 || req.Duration > 86400 {
+			return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusBadRequest, "Lease duration must be between 1 and 86400 seconds"), c.Type() == instancetype.VM)
+		}
+
+		expiryDate := time.Now().Add(time.Duration(req.Duration) * time.Second)
+
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			lease, err = tx.AcquireLease(ctx, projectName, name, c.ID(), expiryDate)
+			return err
+		})
+	case "DELETE":
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.ReleaseLease(ctx, projectName, name, c.ID())
+		})
+		if err == nil {
+			return response.DevIncusResponse(http.StatusOK, "", "raw", c.Type() == instancetype.VM)
+		}
+
+	default:
+		return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusMethodNotAllowed, fmt.Sprintf("method %q not allowed", r.Method)), c.Type() == instancetype.VM)
+	}
+
+	if err != nil {
+		_, found := api.StatusErrorMatch(err)
+		if !found {
+			logger.Warn("Failed handling guest API lease request", logger.Ctx{"project": projectName, "instance": c.Name(), "lease": name, "err": err})
+			return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusInternalServerError, "internal server error"), c.Type() == instancetype.VM)
+		}
+
+		return response.DevIncusErrorResponse(err, c.Type() == instancetype.VM)
+	}
+
+	return response.DevIncusResponse(http.StatusOK, apiGuest.DevIncusLease{Name: lease.Name, Holder: lease.Instance, Token: lease.Token, ExpiresAt: lease.ExpiryDate}, "json", c.Type() == instancetype.VM)
+}}
+
 var handlers = []devIncusHandler{
 	{"/", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
 		return response.DevIncusResponse(http.StatusOK, []string{"/1.0"}, "json", c.Type() == instancetype.VM)
@@ -271,6 +342,7 @@ var handlers = []devIncusHandler{
 	devIncusEventsGet,
 	devIncusImageExport,
 	devIncusDevicesGet,
+	devIncusLeaseHandler,
 }
 
 func hoistReq(f func(*Daemon, instance.Instance, http.ResponseWriter, *http.Request) response.Response, d *Daemon) func(http.ResponseWriter, *http.Request) {
@@ -430,7 +502,7 @@ func findContainerForPid(pid int32, s *state.State) (instance.Container, error)
 				name = fields[1]
 			}
 
-			inst, err := instance.LoadByProjectAndName(s, projectName, name)
+			inst, err := instance.LoadByProjectAndRuntimeName(s, projectName, name)
 			if err != nil {
 				return nil, err
 			}
@@ -444,6 +444,12 @@ func deviceTaskBalance(s *state.State) {
 		}
 	}
 
+	// Get the effective memory nodes, used for instances without NUMA restrictions.
+	effectiveMems, err := cg.GetEffectiveCpusetMems()
+	if err != nil {
+		logger.Warn("Error reading host's cpuset.mems", logger.Ctx{"err": err})
+	}
+
 	effectiveCpusInt, err := resources.ParseCpuset(effectiveCpus)
 	if err != nil {
 		logger.Errorf("Error parsing effective CPU set")
@@ -451,6 +457,18 @@ func deviceTaskBalance(s *state.State) {
 	}
 
 	isolatedCpusInt := resources.GetCPUIsolated()
+
+	// Keep the instances off the CPUs reserved for the host.
+	reservedCpus, _ := s.LocalConfig.LimitsReserve()
+	if reservedCpus != "" {
+		reservedCpusInt, err := resources.ParseCpuset(reservedCpus)
+		if err != nil {
+			logger.Error("Error parsing reserved CPU set", logger.Ctx{"cpuset": reservedCpus, "err": err})
+			return
+		}
+
+		isolatedCpusInt = append(isolatedCpusInt, reservedCpusInt...)
+	}
 	effectiveCpusSlice := []string{}
 	for _, id := range effectiveCpusInt {
 		if slices.Contains(isolatedCpusInt, id) {
@@ -498,6 +516,8 @@ func deviceTaskBalance(s *state.State) {
 
 	fixedInstances := map[int64][]instance.Instance{}
 	balancedInstances := map[instance.Instance]int{}
+	instanceMems := map[instance.Instance]string{}
+	pinnedInstances := map[instance.Instance]bool{}
 	for _, c := range instances {
 		var numaCpus []int64
 		var numaCpusStr []string
@@ -522,6 +542,9 @@ func deviceTaskBalance(s *state.State) {
 			for _, numaCPU := range numaCpus {
 				numaCpusStr = append(numaCpusStr, fmt.Sprintf("%d", numaCPU))
 			}
+
+			// Allocate the memory from the same NUMA node(s).
+			instanceMems[c] = cpuNodes
 		}
 
 		cpulimit, ok := conf["limits.cpu"]
@@ -563,6 +586,10 @@ func deviceTaskBalance(s *state.State) {
 				logger.Warnf("The pinned CPUs: %v, override the NUMA configuration with the CPUs: %v", containerCpus, numaCpus)
 			}
 
+			if conf["limits.cpu"] != "" {
+				pinnedInstances[c] = true
+			}
+
 			fillFixedInstances(fixedInstances, c, cpus, containerCpus, len(containerCpus), false)
 		}
 	}
@@ -643,6 +670,32 @@ func deviceTaskBalance(s *state.State) {
 		if err != nil {
 			logger.Error("balance: Unable to set cpuset", logger.Ctx{"name": ctn.Name(), "err": err, "value": strings.Join(set, ",")})
 		}
+
+		// The CPU information presented to the container follows its CPU set.
+		c, ok := ctn.(instance.Container)
+		if ok {
+			err = c.RefreshCPUInfo()
+			if err != nil {
+				logger.Warn("balance: Unable to refresh CPU information", logger.Ctx{"name": ctn.Name(), "err": err})
+			}
+		}
+
+		// Leave the memory nodes of containers with explicitly pinned CPUs alone.
+		if pinnedInstances[ctn] {
+			continue
+		}
+
+		mems, ok := instanceMems[ctn]
+		if !ok {
+			mems = effectiveMems
+		}
+
+		if mems != "" {
+			err = cg.SetCpusetMems(mems)
+			if err != nil {
+				logger.Error("balance: Unable to set cpuset.mems", logger.Ctx{"name": ctn.Name(), "err": err, "value": mems})
+			}
+		}
 	}
 }
 
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/edge"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// edgeCacheTask periodically caches the state assigned to an edge member while it's connected.
+func edgeCacheTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		if !s.Edge.Enabled() || s.Edge.Disconnected() {
+			return
+		}
+
+		err := edgeCacheUpdate(ctx, s)
+		if err != nil {
+			logger.Warn("Failed caching edge state", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// edgeContact records a contact with the cluster for edge mode, reconciling the changes made while disconnected
+// when reconnecting.
+func (d *Daemon) edgeContact() {
+	s := d.State()
+
+	var offlineThreshold time.Duration
+	if s.GlobalConfig != nil {
+		offlineThreshold = s.GlobalConfig.OfflineThreshold()
+	}
+
+	if !d.edge.Contact(offlineThreshold) {
+		return
+	}
+
+	logger.Info("Reconnected to the cluster")
+
+	go func() {
+		err := edgeReconcile(s.ShutdownCtx, s)
+		if err != nil {
+			logger.Error("Failed reconciling edge state", logger.Ctx{"err": err})
+		}
+	}()
+}
+
+// edgeCacheUpdate saves the records of the local instances and their projects for use while disconnected.
+func edgeCacheUpdate(ctx context.Context, s *state.State) error {
+	cache := edge.Cache{
+		Synced:    time.Now().UTC(),
+		Projects:  []api.Project{},
+		Instances: []edge.Instance{},
+	}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		instances, err := cluster.GetInstances(ctx, tx.Tx(), cluster.InstanceFilter{Node: &s.ServerName})
+		if err != nil {
+			return fmt.Errorf("Failed loading local instances: %w", err)
+		}
+
+		projects := map[string]bool{}
+		for _, inst := range instances {
+			config, err := cluster.GetInstanceConfig(ctx, tx.Tx(), inst.ID)
+			if err != nil {
+				return fmt.Errorf("Failed loading configuration of instance %q: %w", inst.Name, err)
+			}
+
+			cache.Instances = append(cache.Instances, edge.Instance{
+				Project: inst.Project,
+				Name:    inst.Name,
+				Type:    inst.Type.String(),
+				Config:  config,
+			})
+
+			if projects[inst.Project] {
+				continue
+			}
+
+			dbProject, err := cluster.GetProject(ctx, tx.Tx(), inst.Project)
+			if err != nil {
+				return err
+			}
+
+			p, err := dbProject.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			cache.Projects = append(cache.Projects, *p)
+			projects[inst.Project] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.Edge.Save(cache)
+}
+
+// edgeReconcile applies the changes queued while an edge member was disconnected to the cluster database.
+// Changes conflicting with the ones made in the cluster in the meantime are dropped and reported as warnings.
+func edgeReconcile(ctx context.Context, s *state.State) error {
+	journal := s.Edge.Journal()
+	if len(journal) > 0 {
+		logger.Info("Reconciling changes made while disconnected from the cluster", logger.Ctx{"instances": len(journal)})
+	}
+
+	for _, change := range journal {
+		cached, _, _ := s.Edge.Instance(change.Project, change.Name)
+		l := logger.AddContext(logger.Ctx{"project": change.Project, "instance": change.Name})
+
+		var entityID int
+		var conflicts []edge.Conflict
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var current map[string]string
+			var location string
+
+			inst, err := cluster.GetInstance(ctx, tx.Tx(), change.Project, change.Name)
+			if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return err
+			}
+
+			if inst != nil {
+				entityID = inst.ID
+				location = inst.Node
+
+				current, err = cluster.GetInstanceConfig(ctx, tx.Tx(), inst.ID)
+				if err != nil {
+					return err
+				}
+			}
+
+			var apply map[string]string
+			apply, conflicts = edge.Reconcile(change, cached, current, location, s.ServerName)
+			if len(apply) == 0 {
+				return nil
+			}
+
+			return tx.UpdateInstanceConfig(inst.ID, apply)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed reconciling instance %q in project %q: %w", change.Name, change.Project, err)
+		}
+
+		if len(conflicts) == 0 {
+			continue
+		}
+
+		messages := make([]string, 0, len(conflicts))
+		for _, conflict := range conflicts {
+			messages = append(messages, conflict.String())
+		}
+
+		message := strings.Join(messages, "; ")
+		l.Warn("Conflicting changes made while disconnected from the cluster", logger.Ctx{"conflicts": message})
+
+		// Deleted instances have nothing left to attach the warning to.
+		entityType := cluster.TypeInstance
+		if entityID == 0 {
+			entityType = -1
+			entityID = -1
+			message = fmt.Sprintf("Instance %q in project %q: %s", change.Name, change.Project, message)
+		}
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, change.Project, entityType, entityID, warningtype.EdgeReconciliationConflict, message)
+		})
+		if err != nil {
+			l.Warn("Failed to create edge reconciliation conflict warning", logger.Ctx{"err": err})
+		}
+	}
+
+	err := s.Edge.ClearJournal()
+	if err != nil {
+		return err
+	}
+
+	return edgeCacheUpdate(ctx, s)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/edge"
+)
+
+func (suite *containerTestSuite) TestEdgeContactLeader() {
+	member, err := edge.NewMember(suite.T().TempDir())
+	suite.Req.NoError(err)
+
+	suite.d.edge = member
+	member.SetEnabled(true)
+
+	// Miss the heartbeats for longer than a short threshold.
+	member.Contact(time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	suite.Req.True(member.Disconnected())
+
+	// Heartbeat rounds of other members don't count as contact.
+	suite.d.nodeRefreshTask(&cluster.APIHeartbeat{}, false, nil)
+	suite.Req.True(member.Disconnected())
+
+	// The leader's own heartbeat rounds do.
+	suite.d.nodeRefreshTask(&cluster.APIHeartbeat{}, true, nil)
+	suite.Req.False(member.Disconnected())
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"path"
 	"slices"
 	"strings"
 
@@ -20,7 +23,7 @@ import (
 	"github.com/lxc/incus/v6/shared/ws"
 )
 
-var eventTypes = []string{api.EventTypeLogging, api.EventTypeOperation, api.EventTypeLifecycle, api.EventTypeNetworkACL}
+var eventTypes = []string{api.EventTypeLogging, api.EventTypeOperation, api.EventTypeLifecycle, api.EventTypeNetworkACL, api.EventTypeInstanceLog}
 var privilegedEventTypes = []string{api.EventTypeLogging}
 
 var eventsCmd = APIEndpoint{
@@ -99,6 +102,36 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 		return api.StatusErrorf(http.StatusForbidden, "Forbidden")
 	}
 
+	// Setup instance name filtering.
+	var filter events.EventFilter
+	instancePattern := request.QueryParam(r, "instance")
+	if instancePattern != "" {
+		_, err := path.Match(instancePattern, "")
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid instance name pattern %q: %v", instancePattern, err)
+		}
+
+		filter = func(event api.Event) bool {
+			for _, name := range eventInstanceNames(event) {
+				match, _ := path.Match(instancePattern, name)
+				if match {
+					return true
+				}
+			}
+
+			return false
+		}
+	}
+
+	// Validate the replay position.
+	since := request.QueryParam(r, "since")
+	if since != "" {
+		_, _, err := events.ParseJournalPosition(since)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "%v", err)
+		}
+	}
+
 	l := logger.AddContext(logger.Ctx{"remote": r.RemoteAddr})
 
 	var excludeLocations []string
@@ -155,12 +188,22 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 	defer func() { _ = conn.Close() }() // Ensure listener below ends when this function ends.
 
 	listenerConnection := events.NewWebsocketListenerConnection(conn)
-	listener, err := s.Events.AddListener(projectName, allProjects, projectPermissionFunc, listenerConnection, types, excludeSources, recvFunc, excludeLocations)
+	listener, err := s.Events.AddListener(projectName, allProjects, projectPermissionFunc, listenerConnection, types, excludeSources, recvFunc, excludeLocations, filter)
 	if err != nil {
 		l.Warn("Failed to add event listener", logger.Ctx{"err": err})
 		return nil
 	}
 
+	// Replay the journaled events the client missed.
+	if since != "" {
+		err = s.Events.Replay(listener, since)
+		if err != nil {
+			l.Warn("Failed replaying events", logger.Ctx{"err": err})
+			listener.Close()
+			return nil
+		}
+	}
+
 	listener.Wait(r.Context())
 
 	return nil
@@ -190,6 +233,16 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 //	    name: all-projects
 //	    description: Retrieve instances from all projects
 //	    type: boolean
+//	  - in: query
+//	    name: instance
+//	    description: Only send events related to instances matching this name pattern
+//	    type: string
+//	    example: web-*
+//	  - in: query
+//	    name: since
+//	    description: Replay the journaled events which came after this event ID or RFC3339 timestamp
+//	    type: string
+//	    example: 1234
 //	responses:
 //	  "200":
 //	    description: Websocket message (JSON)
@@ -202,3 +255,56 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 func eventsGet(d *Daemon, r *http.Request) response.Response {
 	return &eventsServe{req: r, s: d.State()}
 }
+
+// eventInstanceNames returns the names of the instances an event relates to.
+func eventInstanceNames(event api.Event) []string {
+	var sources []string
+
+	switch event.Type {
+	case api.EventTypeLifecycle:
+		lifecycleEvent := api.EventLifecycle{}
+		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+		if err != nil {
+			return nil
+		}
+
+		sources = []string{lifecycleEvent.Source}
+	case api.EventTypeOperation:
+		op := api.Operation{}
+		err := json.Unmarshal(event.Metadata, &op)
+		if err != nil {
+			return nil
+		}
+
+		sources = op.Resources["instances"]
+	case api.EventTypeLogging, api.EventTypeInstanceLog:
+		logEntry := api.EventLogging{}
+		err := json.Unmarshal(event.Metadata, &logEntry)
+		if err != nil {
+			return nil
+		}
+
+		if logEntry.Context["instance"] != "" {
+			return []string{logEntry.Context["instance"]}
+		}
+	}
+
+	names := []string{}
+	for _, source := range sources {
+		sourcePath, _, _ := strings.Cut(source, "?")
+
+		fields := strings.Split(strings.TrimPrefix(sourcePath, "/1.0/instances/"), "/")
+		if !strings.HasPrefix(sourcePath, "/1.0/instances/") || fields[0] == "" {
+			continue
+		}
+
+		name, err := url.PathUnescape(fields[0])
+		if err != nil {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/lxc/incus/v6/internal/ports"
+	"github.com/lxc/incus/v6/internal/server/firewall"
+	firewallDrivers "github.com/lxc/incus/v6/internal/server/firewall/drivers"
+	"github.com/lxc/incus/v6/internal/server/state"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+)
+
+// firewallServices are the server configuration keys of the services listening on the network, along with their
+// default port and protocols. The debug address isn't included as it's not meant to be reachable from outside.
+var firewallServices = []struct {
+	key         string
+	defaultPort int
+	protocols   []string
+}{
+	{"core.https_address", ports.HTTPSDefaultPort, []string{"tcp"}},
+	{"cluster.https_address", ports.HTTPSDefaultPort, []string{"tcp"}},
+	{"core.metrics_address", ports.HTTPSMetricsDefaultPort, []string{"tcp"}},
+	{"core.storage_buckets_address", ports.HTTPSStorageBucketsDefaultPort, []string{"tcp"}},
+	{"core.bgp_address", ports.BGPDefaultPort, []string{"tcp"}},
+	{"core.dns_address", ports.DNSDefaultPort, []string{"tcp", "udp"}},
+}
+
+// firewallServicesConfigKey returns whether the given configuration key affects the service firewall rules.
+func firewallServicesConfigKey(key string) bool {
+	for _, service := range firewallServices {
+		if service.key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// firewallServicePorts returns the ports that must be allowed for the services configured in the server configuration.
+// Host names are resolved with lookup and services only listening on loopback addresses are skipped.
+func firewallServicePorts(config map[string]string, lookup func(host string) ([]net.IP, error)) ([]firewallDrivers.ServicePort, error) {
+	servicePorts := []firewallDrivers.ServicePort{}
+
+	addPort := func(newPort firewallDrivers.ServicePort) {
+		for _, port := range servicePorts {
+			if port.Protocol == newPort.Protocol && port.Port == newPort.Port && port.Address.Equal(newPort.Address) {
+				return
+			}
+		}
+
+		servicePorts = append(servicePorts, newPort)
+	}
+
+	for _, service := range firewallServices {
+		address := config[service.key]
+		if address == "" {
+			continue
+		}
+
+		host, portStr, err := net.SplitHostPort(internalUtil.CanonicalNetworkAddress(address, service.defaultPort))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid address %q for %q: %w", address, service.key, err)
+		}
+
+		port, err := net.LookupPort("tcp", portStr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid port %q for %q: %w", portStr, service.key, err)
+		}
+
+		// Listening on all addresses.
+		var ips []net.IP
+		if host != "" {
+			ip := net.ParseIP(host)
+			if ip != nil {
+				ips = []net.IP{ip}
+			} else {
+				ips, err = lookup(host)
+				if err != nil {
+					return nil, fmt.Errorf("Failed resolving %q for %q: %w", host, service.key, err)
+				}
+			}
+		}
+
+		if len(ips) == 0 {
+			ips = []net.IP{nil}
+		}
+
+		for _, ip := range ips {
+			if ip != nil && ip.IsLoopback() {
+				continue
+			}
+
+			if ip != nil && ip.IsUnspecified() {
+				ip = nil
+			}
+
+			for _, protocol := range service.protocols {
+				addPort(firewallDrivers.ServicePort{Protocol: protocol, Address: ip, Port: uint64(port)})
+			}
+		}
+	}
+
+	return servicePorts, nil
+}
+
+// firewallServicesUpdate records the rules the host firewall must allow for the services of the daemon to be
+// reachable. Those rules are never applied, the host firewall policy is left to the administrator.
+func firewallServicesUpdate(s *state.State) error {
+	if s.OS.MockMode || s.Firewall == nil {
+		return nil
+	}
+
+	servicePorts, err := firewallServicePorts(s.LocalConfig.Dump(), net.LookupIP)
+	if err != nil {
+		return err
+	}
+
+	rules, err := s.Firewall.ServicesRequiredRules(servicePorts)
+	if err != nil {
+		return fmt.Errorf("Failed getting required service firewall rules: %w", err)
+	}
+
+	firewall.SetRequiredRules("services", rules)
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	firewallDrivers "github.com/lxc/incus/v6/internal/server/firewall/drivers"
+)
+
+func TestFirewallServicePorts(t *testing.T) {
+	lookup := func(host string) ([]net.IP, error) {
+		switch host {
+		case "server.example.com":
+			return []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("fd00::1")}, nil
+		case "localhost":
+			return []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}, nil
+		}
+
+		return nil, errors.New("Unknown host")
+	}
+
+	tests := []struct {
+		name       string
+		config     map[string]string
+		expected   []firewallDrivers.ServicePort
+		shouldFail bool
+	}{
+		{
+			"No services",
+			map[string]string{},
+			[]firewallDrivers.ServicePort{},
+			false,
+		},
+		{
+			"Wildcard address",
+			map[string]string{"core.https_address": ":8443"},
+			[]firewallDrivers.ServicePort{{Protocol: "tcp", Port: 8443}},
+			false,
+		},
+		{
+			"Unspecified address with default port",
+			map[string]string{"core.https_address": "[::]"},
+			[]firewallDrivers.ServicePort{{Protocol: "tcp", Port: 8443}},
+			false,
+		},
+		{
+			"Same API and cluster address",
+			map[string]string{"core.https_address": "10.0.0.1:8443", "cluster.https_address": "10.0.0.1"},
+			[]firewallDrivers.ServicePort{{Protocol: "tcp", Address: net.ParseIP("10.0.0.1"), Port: 8443}},
+			false,
+		},
+		{
+			"Loopback address",
+			map[string]string{"core.metrics_address": "127.0.0.1:9100", "core.storage_buckets_address": "localhost:9000"},
+			[]firewallDrivers.ServicePort{},
+			false,
+		},
+		{
+			"DNS over TCP and UDP",
+			map[string]string{"core.dns_address": "10.0.0.1"},
+			[]firewallDrivers.ServicePort{
+				{Protocol: "tcp", Address: net.ParseIP("10.0.0.1"), Port: 53},
+				{Protocol: "udp", Address: net.ParseIP("10.0.0.1"), Port: 53},
+			},
+			false,
+		},
+		{
+			"Host name",
+			map[string]string{"core.bgp_address": "server.example.com"},
+			[]firewallDrivers.ServicePort{
+				{Protocol: "tcp", Address: net.ParseIP("10.0.0.1"), Port: 179},
+				{Protocol: "tcp", Address: net.ParseIP("fd00::1"), Port: 179},
+			},
+			false,
+		},
+		{
+			"Unknown host name",
+			map[string]string{"core.metrics_address": "unknown.example.com:9100"},
+			nil,
+			true,
+		},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		servicePorts, err := firewallServicePorts(tt.config, lookup)
+		if tt.shouldFail {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, servicePorts)
+	}
+
+	require.True(t, firewallServicesConfigKey("core.dns_address"))
+	require.False(t, firewallServicesConfigKey("network.firewall.strict"))
+	require.False(t, firewallServicesConfigKey("core.debug_address"))
+}
@@ -19,6 +19,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -95,6 +96,12 @@ var imageRefreshCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: imageRefresh, AccessHandler: allowPermission(auth.ObjectTypeImage, auth.EntitlementCanEdit, "fingerprint")},
 }
 
+var imageOptimizeCmd = APIEndpoint{
+	Path: "images/{fingerprint}/optimize",
+
+	Post: APIEndpointAction{Handler: imageOptimize, AccessHandler: allowPermission(auth.ObjectTypeImage, auth.EntitlementCanEdit, "fingerprint")},
+}
+
 var imageAliasesCmd = APIEndpoint{
 	Path: "images/aliases",
 
@@ -1079,7 +1086,7 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 		return createTokenResponse(s, r, projectName, req.Source.Fingerprint, metadata)
 	}
 
-	if !imageUpload && !slices.Contains([]string{"container", "instance", "virtual-machine", "snapshot", "image", "url"}, req.Source.Type) {
+	if !imageUpload && !slices.Contains([]string{"container", "instance", "virtual-machine", "snapshot", "image", "url", "build"}, req.Source.Type) {
 		cleanup(builddir, post)
 		return response.InternalError(fmt.Errorf("Invalid images JSON"))
 	}
@@ -1125,6 +1132,9 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 			} else if req.Source.Type == "url" {
 				/* Processing image copy from URL */
 				info, err = imgPostURLInfo(context.TODO(), s, r, req, op, projectName, budget)
+			} else if req.Source.Type == "build" {
+				/* Processing image build from definition */
+				info, err = imgPostBuildInfo(context.TODO(), s, r, req, op, projectName, builddir, budget)
 			} else {
 				/* Processing image creation from container */
 				imagePublishLock.Lock()
@@ -1342,9 +1352,7 @@ func getImageMetadata(fname string) (*api.ImageMetadata, string, error) {
 	return &result, imageType, nil
 }
 
-func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectName string, public bool, clauses *filter.ClauseSet, hasPermission auth.PermissionChecker, allProjects bool) (any, error) {
-	mustLoadObjects := recursion || (clauses != nil && len(clauses.Clauses) > 0)
-
+func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectName string, public bool, clauses *filter.ClauseSet, hasPermission auth.PermissionChecker, allProjects bool, limit int, offset int) (any, error) {
 	imagesProjectsMap := map[string][]string{}
 	if allProjects {
 		var err error
@@ -1364,30 +1372,56 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 		}
 	}
 
+	// Sort the fingerprints so that the pages are consistent.
+	fingerprints := make([]string, 0, len(imagesProjectsMap))
+	for fingerprint := range imagesProjectsMap {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+
+	sort.Strings(fingerprints)
+
+	filtered := clauses != nil && len(clauses.Clauses) > 0
+
+	// Stop loading images once the requested page is complete.
+	pageEnd := -1
+	if limit > 0 {
+		pageEnd = offset + limit
+	}
+
 	var resultString []string
 	var resultMap []*api.Image
+	matched := 0
 
-	if recursion {
-		resultMap = make([]*api.Image, 0, len(imagesProjectsMap))
-	} else {
-		resultString = make([]string, 0, len(imagesProjectsMap))
-	}
+	for _, fingerprint := range fingerprints {
+		if matched == pageEnd {
+			break
+		}
+
+		projects := imagesProjectsMap[fingerprint]
+		sort.Strings(projects)
 
-	for fingerprint, projects := range imagesProjectsMap {
 		for _, curProjectName := range projects {
-			image, err := doImageGet(ctx, tx, curProjectName, fingerprint, public)
-			if err != nil {
-				continue
+			if matched == pageEnd {
+				break
 			}
 
-			if !image.Public && !hasPermission(auth.ObjectImage(curProjectName, fingerprint)) {
-				continue
-			}
+			// Images the user has access to are only loaded when filtering or when part of the page.
+			allowed := hasPermission(auth.ObjectImage(curProjectName, fingerprint))
 
-			if !mustLoadObjects {
-				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "images", fingerprint).String())
-			} else {
-				if clauses != nil && len(clauses.Clauses) > 0 {
+			var image *api.Image
+			if public || !allowed || filtered || (recursion && matched >= offset) {
+				var err error
+
+				image, err = doImageGet(ctx, tx, curProjectName, fingerprint, public)
+				if err != nil {
+					continue
+				}
+
+				if !image.Public && !allowed {
+					continue
+				}
+
+				if filtered {
 					match, err := filter.Match(*image, *clauses)
 					if err != nil {
 						return nil, err
@@ -1397,20 +1431,33 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 						continue
 					}
 				}
+			}
 
-				if recursion {
-					resultMap = append(resultMap, image)
-				} else {
-					resultString = append(resultString, api.NewURL().Path(version.APIVersion, "images", image.Fingerprint).String())
-				}
+			matched++
+			if matched <= offset {
+				continue
+			}
+
+			if recursion {
+				resultMap = append(resultMap, image)
+			} else {
+				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "images", fingerprint).String())
 			}
 		}
 	}
 
 	if recursion {
+		if resultMap == nil {
+			resultMap = []*api.Image{}
+		}
+
 		return resultMap, nil
 	}
 
+	if resultString == nil {
+		resultString = []string{}
+	}
+
 	return resultString, nil
 }
 
@@ -1435,6 +1482,16 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //      type: string
 //      example: default
 //    - in: query
+//      name: limit
+//      description: Maximum number of entries to return (all of them if 0)
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: offset
+//      description: Number of entries to skip
+//      type: integer
+//      example: 200
+//    - in: query
 //      name: all-projects
 //      description: Retrieve images from all projects
 //      type: boolean
@@ -1493,6 +1550,16 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //      type: string
 //      example: default
 //    - in: query
+//      name: limit
+//      description: Maximum number of entries to return (all of them if 0)
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: offset
+//      description: Number of entries to skip
+//      type: integer
+//      example: 200
+//    - in: query
 //      name: all-projects
 //      description: Retrieve images from all projects
 //      type: boolean
@@ -1546,6 +1613,16 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //      type: string
 //      example: default
 //    - in: query
+//      name: limit
+//      description: Maximum number of entries to return (all of them if 0)
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: offset
+//      description: Number of entries to skip
+//      type: integer
+//      example: 200
+//    - in: query
 //      name: all-projects
 //      description: Retrieve images from all projects
 //      type: boolean
@@ -1604,6 +1681,16 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //	    type: string
 //	    example: default
 //	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return (all of them if 0)
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: offset
+//	    description: Number of entries to skip
+//	    type: integer
+//	    example: 200
+//	  - in: query
 //	    name: all-projects
 //	    description: Retrieve images from all projects
 //	    type: boolean
@@ -1660,9 +1747,14 @@ func imagesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Invalid filter: %w", err))
 	}
 
+	limit, offset, err := localUtil.PaginationFromRequest(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	var result any
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-		result, err = doImagesGet(ctx, tx, localUtil.IsRecursionRequest(r), projectName, public, clauses, hasPermission, allProjects)
+		result, err = doImagesGet(ctx, tx, localUtil.IsRecursionRequest(r), projectName, public, clauses, hasPermission, allProjects, limit, offset)
 		if err != nil {
 			return err
 		}
@@ -1845,6 +1937,14 @@ func autoUpdateImages(ctx context.Context, s *state.State) error {
 				}
 			}
 
+			// Rebase the instances which requested it onto the new image.
+			for _, image := range images {
+				err := instancesAutoRebase(s, image.Project, fingerprint, newImage.Fingerprint)
+				if err != nil {
+					logger.Error("Failed to rebase instances onto new image", logger.Ctx{"err": err, "project": image.Project, "fingerprint": newImage.Fingerprint})
+				}
+			}
+
 			_ = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 				for _, ID := range deleteIDs {
 					// Remove the database entry for the image after distributing to cluster members.
@@ -2405,6 +2505,14 @@ func pruneLeftoverImages(s *state.State) {
 
 		// Check and delete leftovers
 		for _, entry := range entries {
+			// Keep recent partial downloads around so they can be resumed.
+			if strings.HasSuffix(entry.Name(), ".partial") {
+				info, err := entry.Info()
+				if err == nil && time.Since(info.ModTime()) < 24*time.Hour {
+					continue
+				}
+			}
+
 			fp := strings.Split(entry.Name(), ".")[0]
 			if !slices.Contains(images, fp) {
 				err = os.RemoveAll(internalUtil.VarPath("images", entry.Name()))
@@ -4572,7 +4680,7 @@ func imageSyncBetweenNodes(ctx context.Context, s *state.State, r *http.Request,
 	var syncNodeAddresses []string
 
 	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-		desiredSyncNodeCount = s.GlobalConfig.ImagesMinimalReplica()
+		desiredSyncNodeCount = s.GlobalConfig.ImagesReplication()
 
 		// -1 means that we want to replicate the image on all nodes
 		if desiredSyncNodeCount == -1 {
@@ -4610,16 +4718,6 @@ func imageSyncBetweenNodes(ctx context.Context, s *state.State, r *http.Request,
 		return nil
 	}
 
-	// Pick a random node from that slice as the source.
-	syncNodeAddress := syncNodeAddresses[rand.Intn(len(syncNodeAddresses))]
-
-	source, err := cluster.Connect(syncNodeAddress, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
-	if err != nil {
-		return fmt.Errorf("Failed to connect to source node for image synchronization: %w", err)
-	}
-
-	source = source.UseProject(project)
-
 	var image *api.Image
 
 	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
@@ -4632,14 +4730,12 @@ func imageSyncBetweenNodes(ctx context.Context, s *state.State, r *http.Request,
 		return fmt.Errorf("Failed to get image: %w", err)
 	}
 
-	// Populate the copy arguments with properties from the source image.
-	args := incus.ImageCopyArgs{
-		Type:   image.Type,
-		Public: image.Public,
-	}
+	// Replicate on as many nodes as needed. Every member holding the image serves it to one new member per
+	// round, so the number of sources doubles with each round instead of a single member sending all copies.
+	sources := syncNodeAddresses
+	rand.Shuffle(len(sources), func(i, j int) { sources[i], sources[j] = sources[j], sources[i] })
 
-	// Replicate on as many nodes as needed.
-	for i := 0; i < int(nodeCount); i++ {
+	for nodeCount > 0 {
 		var addresses []string
 
 		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
@@ -4657,33 +4753,78 @@ func imageSyncBetweenNodes(ctx context.Context, s *state.State, r *http.Request,
 			return nil
 		}
 
-		// Pick a random node from that slice as the target.
-		targetNodeAddress := addresses[rand.Intn(len(addresses))]
+		// Pick random nodes from that slice as the targets.
+		rand.Shuffle(len(addresses), func(i, j int) { addresses[i], addresses[j] = addresses[j], addresses[i] })
+		count := min(len(sources), len(addresses), int(nodeCount))
 
-		client, err := cluster.Connect(targetNodeAddress, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
-		if err != nil {
-			return fmt.Errorf("Failed to connect node for image synchronization: %w", err)
+		type copyResult struct {
+			address string
+			err     error
 		}
 
-		// Select the right project.
-		client = client.UseProject(project)
+		results := make(chan copyResult, count)
+		for i := 0; i < count; i++ {
+			go func(sourceAddress string, targetAddress string) {
+				err := imageCopyBetweenNodes(s, r, project, image, sourceAddress, targetAddress)
+				results <- copyResult{address: targetAddress, err: err}
+			}(sources[i], addresses[i])
+		}
 
-		// Copy the image to the target server.
-		logger.Info("Copying image to member", logger.Ctx{"fingerprint": fingerprint, "address": targetNodeAddress, "project": project, "public": args.Public, "type": args.Type})
-		op, err := client.CopyImage(source, *image, &args)
-		if err != nil {
-			return fmt.Errorf("Failed to copy image to %q: %w", targetNodeAddress, err)
+		var copyErr error
+		for i := 0; i < count; i++ {
+			result := <-results
+			if result.err != nil {
+				copyErr = result.err
+				continue
+			}
+
+			// The new member can now serve the image too.
+			sources = append(sources, result.address)
+			nodeCount--
 		}
 
-		err = op.Wait()
-		if err != nil {
-			return err
+		if copyErr != nil {
+			return copyErr
 		}
 	}
 
 	return nil
 }
 
+// imageCopyBetweenNodes copies an image from one cluster member to another over the cluster network.
+// The target verifies the hash of the image as part of the download.
+func imageCopyBetweenNodes(s *state.State, r *http.Request, project string, image *api.Image, sourceAddress string, targetAddress string) error {
+	source, err := cluster.Connect(sourceAddress, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to source node for image synchronization: %w", err)
+	}
+
+	source = source.UseProject(project)
+
+	client, err := cluster.Connect(targetAddress, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+	if err != nil {
+		return fmt.Errorf("Failed to connect node for image synchronization: %w", err)
+	}
+
+	// Select the right project.
+	client = client.UseProject(project)
+
+	// Populate the copy arguments with properties from the source image.
+	args := incus.ImageCopyArgs{
+		Type:   image.Type,
+		Public: image.Public,
+	}
+
+	// Copy the image to the target server.
+	logger.Info("Copying image to member", logger.Ctx{"fingerprint": image.Fingerprint, "source": sourceAddress, "address": targetAddress, "project": project, "public": args.Public, "type": args.Type})
+	op, err := client.CopyImage(source, *image, &args)
+	if err != nil {
+		return fmt.Errorf("Failed to copy image to %q: %w", targetAddress, err)
+	}
+
+	return op.Wait()
+}
+
 func createTokenResponse(s *state.State, r *http.Request, projectName string, fingerprint string, metadata jmap.Map) response.Response {
 	secret, err := internalUtil.RandomHexString(32)
 	if err != nil {
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	internalIO "github.com/lxc/incus/v6/internal/io"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/state"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/osarch"
+)
+
+// imageBuildLogLines is the number of build log lines kept in the operation metadata.
+const imageBuildLogLines = 100
+
+// imageBuildPath is the path of the build directory inside of the builder instance.
+const imageBuildPath = "/root/incus-build"
+
+/*
+ * This function builds an image from a distrobuilder definition inside of an
+ * ephemeral builder container and imports the resulting image.
+ */
+func imgPostBuildInfo(ctx context.Context, s *state.State, r *http.Request, req api.ImagesPost, op *operations.Operation, projectName string, builddir string, budget int64) (*api.Image, error) {
+	imageType := instancetype.Container
+	if req.Source.ImageType != "" {
+		var err error
+		imageType, err = instancetype.New(req.Source.ImageType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	info, files, err := imageBuildFiles(ctx, s, r, op, projectName, *req.Source, req.Source.Definition, imageType, builddir, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		for _, file := range files {
+			_ = os.Remove(file)
+		}
+	}()
+
+	info.Filename = req.Filename
+	info.Public = req.Public
+	info.AutoUpdate = false
+
+	if !req.ExpiresAt.IsZero() {
+		info.ExpiresAt = req.ExpiresAt
+	}
+
+	for k, v := range req.Properties {
+		info.Properties[k] = v
+	}
+
+	var exists bool
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		exists, err = tx.ImageExists(ctx, projectName, info.Fingerprint)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return info, fmt.Errorf("The image already exists: %s", info.Fingerprint)
+	}
+
+	err = internalUtil.FileMove(files[0], internalUtil.VarPath("images", info.Fingerprint))
+	if err != nil {
+		return nil, err
+	}
+
+	err = internalUtil.FileMove(files[1], internalUtil.VarPath("images", info.Fingerprint+".rootfs"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.CreateImage(ctx, projectName, info.Fingerprint, info.Filename, info.Size, info.Public, info.AutoUpdate, info.Architecture, info.CreatedAt, info.ExpiresAt, info.Properties, info.Type, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// imageBuildFiles builds the image described by the distrobuilder definition in an ephemeral builder container
+// created from the image referenced by the source. It returns the information of the built image along with the
+// temporary paths of its metadata and rootfs files, which the caller must move or remove.
+func imageBuildFiles(ctx context.Context, s *state.State, r *http.Request, op *operations.Operation, projectName string, source api.ImagesPostSource, definition string, imageType instancetype.Type, builddir string, budget int64) (*api.Image, []string, error) {
+	if definition == "" {
+		return nil, nil, fmt.Errorf("No image definition provided")
+	}
+
+	// Basic validation of the definition, the rest is up to distrobuilder.
+	sections := map[string]any{}
+	err := yaml.Unmarshal([]byte(definition), &sections)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid image definition: %w", err)
+	}
+
+	for _, section := range []string{"image", "source"} {
+		_, ok := sections[section]
+		if !ok {
+			return nil, nil, fmt.Errorf("Invalid image definition: Missing %q section", section)
+		}
+	}
+
+	// Get the builder image.
+	builderImage, err := imageBuildGetBuilder(ctx, s, r, source, op, projectName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed getting builder image: %w", err)
+	}
+
+	// Create the builder instance.
+	builder, err := imageBuildCreateBuilder(ctx, s, r, op, projectName, builderImage, imageType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed creating builder instance: %w", err)
+	}
+
+	defer func() {
+		err := builder.Stop(false)
+		if err != nil {
+			logger.Warn("Failed stopping image builder instance", logger.Ctx{"project": projectName, "instance": builder.Name(), "err": err})
+		}
+	}()
+
+	// Push the definition.
+	client, err := builder.FileSFTP()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed connecting to builder instance: %w", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	err = client.MkdirAll(filepath.Join(imageBuildPath, "output"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed creating build directory: %w", err)
+	}
+
+	definitionFile, err := client.Create(filepath.Join(imageBuildPath, "image.yaml"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed creating image definition: %w", err)
+	}
+
+	_, err = definitionFile.Write([]byte(definition))
+	_ = definitionFile.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed writing image definition: %w", err)
+	}
+
+	// Run the build.
+	cmd := []string{"distrobuilder", "build-incus", filepath.Join(imageBuildPath, "image.yaml"), filepath.Join(imageBuildPath, "output")}
+	if imageType == instancetype.VM {
+		cmd = append(cmd, "--vm")
+	}
+
+	err = imageBuildRun(builder, op, cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Retrieve the resulting image.
+	rootfsName := "rootfs.squashfs"
+	if imageType == instancetype.VM {
+		rootfsName = "disk.qcow2"
+	}
+
+	info := api.Image{}
+	info.Type = imageType.String()
+
+	hash := sha256.New()
+	quota := internalIO.NewQuotaWriter(hash, budget)
+	files := []string{}
+
+	revert := func() {
+		for _, file := range files {
+			_ = os.Remove(file)
+		}
+	}
+
+	for _, name := range []string{"incus.tar.xz", rootfsName} {
+		target, err := os.CreateTemp(builddir, "incus_build_image_")
+		if err != nil {
+			revert()
+			return nil, nil, err
+		}
+
+		files = append(files, target.Name())
+
+		source, err := client.Open(filepath.Join(imageBuildPath, "output", name))
+		if err != nil {
+			_ = target.Close()
+			revert()
+			return nil, nil, fmt.Errorf("Failed opening built image file %q: %w", name, err)
+		}
+
+		size, err := io.Copy(io.MultiWriter(target, quota), source)
+		_ = source.Close()
+		_ = target.Close()
+		if err != nil {
+			revert()
+			return nil, nil, fmt.Errorf("Failed retrieving built image file %q: %w", name, err)
+		}
+
+		info.Size += size
+	}
+
+	info.Fingerprint = fmt.Sprintf("%x", hash.Sum(nil))
+
+	imageMeta, _, err := getImageMetadata(files[0])
+	if err != nil {
+		revert()
+		return nil, nil, err
+	}
+
+	info.Architecture = imageMeta.Architecture
+	info.CreatedAt = time.Now().UTC()
+	if imageMeta.CreationDate > 0 {
+		info.CreatedAt = time.Unix(imageMeta.CreationDate, 0)
+	}
+
+	if imageMeta.ExpiryDate > 0 {
+		info.ExpiresAt = time.Unix(imageMeta.ExpiryDate, 0)
+	}
+
+	info.Properties = imageMeta.Properties
+	if info.Properties == nil {
+		info.Properties = map[string]string{}
+	}
+
+	return &info, files, nil
+}
+
+// imageBuildGetBuilder returns the image to run the build in, downloading it if needed.
+func imageBuildGetBuilder(ctx context.Context, s *state.State, r *http.Request, source api.ImagesPostSource, op *operations.Operation, projectName string) (*api.Image, error) {
+	alias := source.Alias
+	if alias == "" {
+		alias = source.Fingerprint
+	}
+
+	if alias == "" {
+		return nil, fmt.Errorf("Must specify the alias or fingerprint of the builder image")
+	}
+
+	if source.Server != "" {
+		return ImageDownload(ctx, r, s, op, &ImageDownloadArgs{
+			Server:      source.Server,
+			Protocol:    source.Protocol,
+			Certificate: source.Certificate,
+			Secret:      source.Secret,
+			Alias:       alias,
+			Type:        instancetype.Container.String(),
+			SetCached:   true,
+			ProjectName: projectName,
+		})
+	}
+
+	var img *api.Image
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		fingerprint := alias
+
+		_, imgAlias, err := tx.GetImageAlias(ctx, projectName, alias, true)
+		if err == nil {
+			fingerprint = imgAlias.Target
+		}
+
+		_, img, err = tx.GetImageByFingerprintPrefix(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = ensureImageIsLocallyAvailable(ctx, s, r, img, projectName, instancetype.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
+
+// imageBuildBuilderRequest returns the creation request of the builder container of an image of the given type.
+// Container images are built in an unprivileged container, allowed to mount filesystems and create device nodes.
+// Virtual machine images need loop devices and so a privileged container.
+func imageBuildBuilderRequest(name string, imageType instancetype.Type, profiles []string) api.InstancesPost {
+	config := map[string]string{
+		"security.nesting":                  "true",
+		"security.syscalls.intercept.mknod": "true",
+	}
+
+	if imageType == instancetype.VM {
+		config = map[string]string{"security.privileged": "true"}
+	}
+
+	return api.InstancesPost{
+		Name: name,
+		Type: api.InstanceTypeContainer,
+		InstancePut: api.InstancePut{
+			Description: "Image builder",
+			Ephemeral:   true,
+			Profiles:    profiles,
+			Config:      config,
+		},
+	}
+}
+
+// imageBuildCreateBuilder creates and starts an ephemeral builder container from the given image.
+func imageBuildCreateBuilder(ctx context.Context, s *state.State, r *http.Request, op *operations.Operation, projectName string, img *api.Image, imageType instancetype.Type) (instance.Instance, error) {
+	// Privileged builders can escape to the host, only server administrators may get one.
+	if imageType == instancetype.VM && r != nil {
+		err := s.Authorizer.CheckPermission(ctx, r, auth.ObjectServer(), auth.EntitlementCanEdit)
+		if err != nil {
+			return nil, fmt.Errorf("Only server administrators can build virtual machine images: %w", err)
+		}
+	}
+
+	suffix, err := internalUtil.RandomHexString(4)
+	if err != nil {
+		return nil, err
+	}
+
+	architecture, err := osarch.ArchitectureId(img.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []api.Profile
+	req := imageBuildBuilderRequest(fmt.Sprintf("incus-build-%s", suffix), imageType, []string{api.ProjectDefaultName})
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		profileProjectName := project.ProfileProjectFromRecord(p)
+
+		profileName := api.ProjectDefaultName
+		dbProfiles, err := dbCluster.GetProfiles(ctx, tx.Tx(), dbCluster.ProfileFilter{Project: &profileProjectName, Name: &profileName})
+		if err != nil {
+			return err
+		}
+
+		for _, dbProfile := range dbProfiles {
+			apiProfile, err := dbProfile.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			profiles = append(profiles, *apiProfile)
+		}
+
+		// The builder is subject to the limits and restrictions of the project as any other instance.
+		return project.AllowInstanceCreation(tx, projectName, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	args := db.InstanceArgs{
+		Project:      projectName,
+		Name:         req.Name,
+		Description:  req.Description,
+		Type:         instancetype.Container,
+		Architecture: architecture,
+		Ephemeral:    req.Ephemeral,
+		Profiles:     profiles,
+		Config:       req.Config,
+	}
+
+	err = instanceCreateFromImage(ctx, s, r, img, args, op)
+	if err != nil {
+		return nil, err
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, args.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = inst.Start(false)
+	if err != nil {
+		_ = inst.Delete(true)
+		return nil, err
+	}
+
+	return inst, nil
+}
+
+// imageBuildRun runs the build command inside of the builder, streaming its output to the operation metadata.
+func imageBuildRun(builder instance.Instance, op *operations.Operation, command []string) error {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stdoutReader.Close() }()
+
+	cmd, err := builder.Exec(api.InstanceExecPost{
+		Command:     command,
+		Environment: map[string]string{"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin:/snap/bin", "HOME": "/root"},
+		Cwd:         imageBuildPath,
+	}, nil, stdoutWriter, stdoutWriter)
+	_ = stdoutWriter.Close()
+	if err != nil {
+		return fmt.Errorf("Failed running the build: %w", err)
+	}
+
+	// Stream the build log, the operation metadata getting its own copy of the last lines.
+	var logLines []string
+	var logLinesLock sync.Mutex
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		scanner := bufio.NewScanner(stdoutReader)
+		for scanner.Scan() {
+			logLinesLock.Lock()
+			logLines = append(logLines, scanner.Text())
+			if len(logLines) > imageBuildLogLines {
+				logLines = logLines[len(logLines)-imageBuildLogLines:]
+			}
+
+			buildLog := slices.Clone(logLines)
+			logLinesLock.Unlock()
+
+			_ = op.ExtendMetadata(map[string]any{"build_log": buildLog})
+		}
+	}()
+
+	exitStatus, err := cmd.Wait()
+	wg.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed running the build: %w", err)
+	}
+
+	if exitStatus != 0 {
+		logLinesLock.Lock()
+		defer logLinesLock.Unlock()
+
+		if len(logLines) > 0 {
+			return fmt.Errorf("Image build failed with exit code %d: %s", exitStatus, strings.TrimSpace(logLines[len(logLines)-1]))
+		}
+
+		return fmt.Errorf("Image build failed with exit code %d", exitStatus)
+	}
+
+	return nil
+}
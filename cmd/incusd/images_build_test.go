@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+func TestImageBuildBuilderRequest(t *testing.T) {
+	req := imageBuildBuilderRequest("incus-build-1234", instancetype.Container, []string{"default"})
+	require.Equal(t, "incus-build-1234", req.Name)
+	require.Equal(t, api.InstanceTypeContainer, req.Type)
+	require.True(t, req.Ephemeral)
+	require.Equal(t, []string{"default"}, req.Profiles)
+	require.False(t, util.IsTrue(req.Config["security.privileged"]))
+	require.True(t, util.IsTrue(req.Config["security.nesting"]))
+	require.True(t, util.IsTrue(req.Config["security.syscalls.intercept.mknod"]))
+
+	// Virtual machine images need a privileged builder container.
+	req = imageBuildBuilderRequest("incus-build-1234", instancetype.VM, []string{"default"})
+	require.Equal(t, api.InstanceTypeContainer, req.Type)
+	require.True(t, util.IsTrue(req.Config["security.privileged"]))
+}
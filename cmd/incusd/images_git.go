@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/state"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+// imageGitRecipe is a distrobuilder definition fetched from a Git repository.
+type imageGitRecipe struct {
+	repository string
+	path       string
+	commit     string
+	definition string
+}
+
+// imageGitParseAlias splits the alias of a Git image source, in the `<path>[@<ref>]` format, into the path of the
+// recipe in the repository and the Git reference to build it from.
+func imageGitParseAlias(alias string) (string, string, error) {
+	path, ref, _ := strings.Cut(alias, "@")
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	// The reference is passed to git, it mustn't be mistaken for an option.
+	if strings.HasPrefix(ref, "-") || strings.HasPrefix(path, "-") {
+		return "", "", fmt.Errorf("Invalid recipe alias %q", alias)
+	}
+
+	path = filepath.Clean(strings.TrimPrefix(path, "/"))
+	if path == "." || path == ".." || strings.HasPrefix(path, "../") {
+		return "", "", fmt.Errorf("Invalid recipe path %q", alias)
+	}
+
+	if filepath.Ext(path) == "" {
+		path += ".yaml"
+	}
+
+	return path, ref, nil
+}
+
+// imageGitFetchArgs returns the git arguments fetching the reference from the repository, which must be reachable
+// over HTTPS or the Git protocol.
+func imageGitFetchArgs(repository string, ref string) ([]string, error) {
+	u, err := url.Parse(repository)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "git") || u.Host == "" {
+		return nil, fmt.Errorf("Git repository %q must be an https:// or git:// URL", repository)
+	}
+
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("Invalid Git reference %q", ref)
+	}
+
+	return []string{"-c", "protocol.allow=never", "-c", "protocol.https.allow=always", "-c", "protocol.git.allow=always", "fetch", "-q", "--depth", "1", "--", repository, ref}, nil
+}
+
+// imageGitFetch fetches the recipe referenced by the alias from the Git repository, resolving its reference to a
+// commit.
+func imageGitFetch(ctx context.Context, repository string, alias string) (*imageGitRecipe, error) {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		return nil, errors.New("Git must be installed to build images from Git recipes")
+	}
+
+	path, ref, err := imageGitParseAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchArgs, err := imageGitFetchArgs(repository, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(internalUtil.VarPath("images"), "incus_git_")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// Never wait on credentials, the repository must be reachable as is.
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	git := func(args ...string) (string, error) {
+		stdout, _, err := subprocess.RunCommandSplit(ctx, env, nil, "git", append([]string{"-C", dir}, args...)...)
+		return stdout, err
+	}
+
+	_, err = git("init", "-q")
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing Git repository: %w", err)
+	}
+
+	_, err = git(fetchArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching %q from %q: %w", ref, repository, err)
+	}
+
+	commit, err := git("rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("Failed resolving %q: %w", ref, err)
+	}
+
+	definition, err := git("show", "FETCH_HEAD:"+path)
+	if err != nil {
+		return nil, fmt.Errorf("Recipe %q not found in %q at %q", path, repository, ref)
+	}
+
+	return &imageGitRecipe{
+		repository: repository,
+		path:       path,
+		commit:     strings.TrimSpace(commit),
+		definition: definition,
+	}, nil
+}
+
+// imageGitResolve returns the fingerprint of the image built from the commit of the recipe in the project, having it
+// built by the builder member first if it's another one. An empty fingerprint is returned when the image is to be
+// built locally.
+func imageGitResolve(ctx context.Context, s *state.State, r *http.Request, args *ImageDownloadArgs, recipe *imageGitRecipe) (string, error) {
+	fingerprint, err := imageGitLookup(ctx, s, args.ProjectName, args.Type, recipe)
+	if err != nil || fingerprint != "" {
+		return fingerprint, err
+	}
+
+	member := s.GlobalConfig.ImagesBuilderMember()
+	if !s.ServerClustered || member == "" || member == s.ServerName {
+		return "", nil
+	}
+
+	var address string
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		node, err := tx.GetNodeByName(ctx, member)
+		if err != nil {
+			return err
+		}
+
+		address = node.Address
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("Failed loading image builder member %q: %w", member, err)
+	}
+
+	client, err := cluster.Connect(address, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+	if err != nil {
+		return "", fmt.Errorf("Failed connecting to image builder member %q: %w", member, err)
+	}
+
+	logger.Info("Building image from Git recipe on builder member", logger.Ctx{"member": member, "repository": recipe.repository, "recipe": recipe.path, "commit": recipe.commit})
+
+	op, err := client.UseProject(args.ProjectName).CreateImage(api.ImagesPost{
+		ImagePut: api.ImagePut{
+			AutoUpdate: args.AutoUpdate,
+			Public:     args.Public,
+		},
+		Source: &api.ImagesPostSource{
+			ImageSource: api.ImageSource{
+				Server:      args.Server,
+				Protocol:    "git",
+				Certificate: args.Certificate,
+				Alias:       args.Alias,
+				ImageType:   args.Type,
+			},
+			Type: "image",
+			Mode: "pull",
+		},
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("Failed building image on builder member %q: %w", member, err)
+	}
+
+	err = op.WaitContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("Failed building image on builder member %q: %w", member, err)
+	}
+
+	return imageGitLookup(ctx, s, args.ProjectName, args.Type, recipe)
+}
+
+// imageGitLookup returns the fingerprint of the image of the given type built from the commit of the recipe in the
+// project, or an empty string if there's none.
+func imageGitLookup(ctx context.Context, s *state.State, projectName string, imageType string, recipe *imageGitRecipe) (string, error) {
+	var fingerprint string
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		fingerprints, err := tx.GetImagesFingerprints(ctx, projectName, false)
+		if err != nil {
+			return err
+		}
+
+		for _, imageHash := range fingerprints {
+			_, img, err := tx.GetImageByFingerprintPrefix(ctx, imageHash, dbCluster.ImageFilter{Project: &projectName})
+			if err != nil {
+				continue
+			}
+
+			if imageType != "" && img.Type != imageType {
+				continue
+			}
+
+			if img.Properties["git.repository"] == recipe.repository && img.Properties["git.recipe"] == recipe.path && img.Properties["git.commit"] == recipe.commit {
+				fingerprint = img.Fingerprint
+				return nil
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fingerprint, nil
+}
+
+// imageGitBuild builds the recipe in a builder container and moves the resulting image files in place, returning
+// the information of the image for the caller to record it.
+func imageGitBuild(ctx context.Context, s *state.State, r *http.Request, op *operations.Operation, args *ImageDownloadArgs, recipe *imageGitRecipe) (*api.Image, error) {
+	server, alias := s.GlobalConfig.ImagesBuilder()
+	if alias == "" {
+		return nil, errors.New("The images.builder.alias server configuration key must be set to build images from Git recipes")
+	}
+
+	source := api.ImagesPostSource{ImageSource: api.ImageSource{Server: server, Alias: alias}}
+	if server != "" {
+		source.Protocol = "simplestreams"
+	}
+
+	imageType := instancetype.Container
+	if args.Type != "" {
+		var err error
+		imageType, err = instancetype.New(args.Type)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	builddir, err := os.MkdirTemp(internalUtil.VarPath("images"), "incus_build_")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.RemoveAll(builddir) }()
+
+	logger.Info("Building image from Git recipe", logger.Ctx{"repository": recipe.repository, "recipe": recipe.path, "commit": recipe.commit})
+
+	info, files, err := imageBuildFiles(ctx, s, r, op, args.ProjectName, source, recipe.definition, imageType, builddir, args.Budget)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Properties["git.repository"] = recipe.repository
+	info.Properties["git.recipe"] = recipe.path
+	info.Properties["git.commit"] = recipe.commit
+
+	for i, suffix := range []string{"", ".rootfs"} {
+		err = internalUtil.FileMove(files[i], internalUtil.VarPath("images", info.Fingerprint+suffix))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
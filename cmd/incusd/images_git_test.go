@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageGitParseAlias(t *testing.T) {
+	tests := []struct {
+		name       string
+		alias      string
+		path       string
+		ref        string
+		shouldFail bool
+	}{
+		{"Path only", "alpine/edge", "alpine/edge.yaml", "HEAD", false},
+		{"Path and branch", "alpine/edge@main", "alpine/edge.yaml", "main", false},
+		{"Path with extension", "/alpine/edge.yml@v1.0", "alpine/edge.yml", "v1.0", false},
+		{"Path leaving the repository", "../etc/passwd@main", "", "", true},
+		{"Empty path", "@main", "", "", true},
+		{"Reference looking like an option", "alpine/edge@--upload-pack=touch /tmp/pwned", "", "", true},
+		{"Path looking like an option", "--upload-pack=id", "", "", true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		path, ref, err := imageGitParseAlias(tt.alias)
+		if tt.shouldFail {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.path, path)
+		require.Equal(t, tt.ref, ref)
+	}
+}
+
+func TestImageGitFetchArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		ref        string
+		expected   []string
+		shouldFail bool
+	}{
+		{
+			"HTTPS repository",
+			"https://git.example.com/images.git",
+			"main",
+			[]string{"-c", "protocol.allow=never", "-c", "protocol.https.allow=always", "-c", "protocol.git.allow=always", "fetch", "-q", "--depth", "1", "--", "https://git.example.com/images.git", "main"},
+			false,
+		},
+		{
+			"Git repository",
+			"git://git.example.com/images.git",
+			"HEAD",
+			[]string{"-c", "protocol.allow=never", "-c", "protocol.https.allow=always", "-c", "protocol.git.allow=always", "fetch", "-q", "--depth", "1", "--", "git://git.example.com/images.git", "HEAD"},
+			false,
+		},
+		{"Repository looking like an option", "--upload-pack=touch /tmp/pwned", "main", nil, true},
+		{"Reference looking like an option", "https://git.example.com/images.git", "--upload-pack=id", nil, true},
+		{"Local path", "/var/lib/incus", "main", nil, true},
+		{"File URL", "file:///var/lib/incus", "main", nil, true},
+		{"SSH URL", "ssh://git.example.com/images.git", "main", nil, true},
+		{"Plain HTTP URL", "http://git.example.com/images.git", "main", nil, true},
+		{"External transport", "ext::sh -c id", "main", nil, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		args, err := imageGitFetchArgs(tt.repository, tt.ref)
+		if tt.shouldFail {
+			require.Error(t, err)
+			require.Nil(t, args)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, args)
+	}
+}
@@ -0,0 +1,752 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/archive"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+// swagger:operation POST /1.0/images/{fingerprint}/optimize images images_optimize_post
+//
+//	Optimize an image
+//
+//	Converts the stored files of the image to a new compression algorithm or
+//	format (unified or split). The converted image replaces the existing one,
+//	keeping its properties and aliases, and has a new fingerprint which is
+//	returned in the operation metadata.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: image
+//	    description: Image conversion request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ImageOptimizePost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func imageOptimize(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	fingerprint, err := url.PathUnescape(mux.Vars(r)["fingerprint"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.ImageOptimizePost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.CompressionAlgorithm != "" {
+		err = validate.IsCompressionAlgorithm(req.CompressionAlgorithm)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid compression algorithm: %w", err))
+		}
+	}
+
+	if req.Format != "" && req.Format != "unified" && req.Format != "split" {
+		return response.BadRequest(fmt.Errorf("Invalid image format %q, must be %q or %q", req.Format, "unified", "split"))
+	}
+
+	var imageID int
+	var imageInfo *api.Image
+	var address string
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		imageID, imageInfo, err = tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return err
+		}
+
+		// Check if the image files are only available on another member.
+		address, err = tx.LocateImage(ctx, imageInfo.Fingerprint)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if address != "" {
+		client, err := cluster.Connect(address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.ForwardedResponse(client, r)
+	}
+
+	compress := req.CompressionAlgorithm
+	if compress == "" {
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+			if err != nil {
+				return err
+			}
+
+			p, err := dbProject.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			compress = p.Config["images.compression_algorithm"]
+
+			return nil
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if compress == "" {
+			compress = s.GlobalConfig.ImagesCompressionAlgorithm()
+		}
+	}
+
+	run := func(op *operations.Operation) error {
+		unlock, err := imageOperationLock(context.TODO(), imageInfo.Fingerprint)
+		if err != nil {
+			return err
+		}
+
+		defer unlock()
+
+		newInfo, err := imageOptimizeRun(context.TODO(), s, op, projectName, imageID, imageInfo, compress, req.Format)
+		if err != nil {
+			return fmt.Errorf("Failed optimizing image %q in project %q: %w", imageInfo.Fingerprint, projectName, err)
+		}
+
+		return op.UpdateMetadata(map[string]any{
+			"fingerprint": newInfo.Fingerprint,
+			"size":        newInfo.Size,
+		})
+	}
+
+	resources := map[string][]api.URL{}
+	resources["images"] = []api.URL{*api.NewURL().Path(version.APIVersion, "images", imageInfo.Fingerprint)}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.ImageOptimize, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// imageOptimizeRun converts the files of the image and replaces the image with the converted one.
+// The new image is returned, or the existing one if the conversion gave identical files.
+func imageOptimizeRun(ctx context.Context, s *state.State, op *operations.Operation, projectName string, imageID int, info *api.Image, compress string, format string) (*api.Image, error) {
+	imagePath := internalUtil.VarPath("images", info.Fingerprint)
+	rootfsPath := imagePath + ".rootfs"
+	if !util.PathExists(rootfsPath) {
+		rootfsPath = ""
+	}
+
+	if format == "" {
+		format = "unified"
+		if rootfsPath != "" {
+			format = "split"
+		}
+	}
+
+	builddir, err := os.MkdirTemp(internalUtil.VarPath("images"), "incus_optimize_")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.RemoveAll(builddir) }()
+
+	files, err := imageOptimizeConvert(builddir, imagePath, rootfsPath, info.Type, format, compress)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute the fingerprint and size of the converted image.
+	hash := sha256.New()
+	var size int64
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := io.Copy(hash, f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		size += n
+	}
+
+	newFingerprint := fmt.Sprintf("%x", hash.Sum(nil))
+	if newFingerprint == info.Fingerprint {
+		return info, nil
+	}
+
+	var exists bool
+	var source *api.ImageSource
+	var sourceFingerprint string
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		exists, err = tx.ImageExists(ctx, projectName, newFingerprint)
+		if err != nil {
+			return err
+		}
+
+		_, imageSource, err := tx.GetImageSource(ctx, imageID)
+		if err == nil {
+			source = &imageSource
+		} else if !response.IsNotFoundError(err) {
+			return err
+		}
+
+		// Keep track of the fingerprint of the image on its source server, which is the one of the image being
+		// converted unless it was converted already.
+		sourceFingerprint, err = tx.GetImageSourceFingerprint(ctx, imageID)
+		if err != nil {
+			return err
+		}
+
+		if sourceFingerprint == "" {
+			sourceFingerprint = info.Fingerprint
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return nil, fmt.Errorf("The image already exists: %s", newFingerprint)
+	}
+
+	// Move the converted files in place.
+	newImagePath := internalUtil.VarPath("images", newFingerprint)
+	err = internalUtil.FileMove(files[0], newImagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) > 1 {
+		err = internalUtil.FileMove(files[1], newImagePath+".rootfs")
+		if err != nil {
+			imageDeleteFromDisk(newFingerprint)
+			return nil, err
+		}
+	}
+
+	newInfo := *info
+	newInfo.Fingerprint = newFingerprint
+	newInfo.Size = size
+
+	var newID int
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.CreateImage(ctx, projectName, newInfo.Fingerprint, newInfo.Filename, newInfo.Size, newInfo.Public, newInfo.AutoUpdate, newInfo.Architecture, newInfo.CreatedAt, newInfo.ExpiresAt, newInfo.Properties, newInfo.Type, nil)
+		if err != nil {
+			return err
+		}
+
+		newID, _, err = tx.GetImage(ctx, newInfo.Fingerprint, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return err
+		}
+
+		if source != nil {
+			err = tx.CreateImageSource(ctx, newID, source.Server, source.Protocol, source.Certificate, source.Alias)
+			if err != nil {
+				return err
+			}
+
+			// Auto-updates and downloads from the source server then match the converted image.
+			err = tx.SetImageSourceFingerprint(ctx, newID, sourceFingerprint)
+			if err != nil {
+				return err
+			}
+		}
+
+		if info.Cached {
+			err = tx.SetImageCachedAndLastUseDate(ctx, projectName, newInfo.Fingerprint, info.LastUsedAt)
+		} else {
+			err = tx.UpdateImageLastUseDate(ctx, projectName, newInfo.Fingerprint, info.LastUsedAt)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = tx.MoveImageAlias(ctx, imageID, newID)
+		if err != nil {
+			return err
+		}
+
+		return tx.CopyDefaultImageProfiles(ctx, imageID, newID)
+	})
+	if err != nil {
+		imageDeleteFromDisk(newFingerprint)
+		return nil, err
+	}
+
+	err = s.Authorizer.AddImage(s.ShutdownCtx, projectName, newInfo.Fingerprint)
+	if err != nil {
+		logger.Error("Failed to add image to authorizer", logger.Ctx{"fingerprint": newInfo.Fingerprint, "project": projectName, "error": err})
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.ImageCreated.Event(newInfo.Fingerprint, projectName, op.Requestor(), logger.Ctx{"type": newInfo.Type, "source": info.Fingerprint}))
+
+	var poolNames []string
+	var nodes []string
+	var referenced bool
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		poolIDs, err := tx.GetPoolsWithImage(ctx, info.Fingerprint)
+		if err != nil {
+			return err
+		}
+
+		poolNames, err = tx.GetPoolNamesFromIDs(ctx, poolIDs)
+		if err != nil {
+			return err
+		}
+
+		nodes, err = tx.GetNodesWithImage(ctx, info.Fingerprint)
+		if err != nil {
+			return err
+		}
+
+		referenced, err = tx.ImageIsReferencedByOtherProjects(ctx, projectName, info.Fingerprint)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the image volumes on the storage pools holding the old image.
+	for _, poolName := range poolNames {
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			logger.Error("Error loading storage pool to replace image", logger.Ctx{"err": err, "pool": poolName, "fingerprint": info.Fingerprint})
+			continue
+		}
+
+		err = pool.EnsureImage(newInfo.Fingerprint, op)
+		if err != nil {
+			logger.Error("Error creating image in storage pool", logger.Ctx{"err": err, "pool": poolName, "fingerprint": newInfo.Fingerprint})
+			continue
+		}
+
+		if !referenced {
+			err = pool.DeleteImage(info.Fingerprint, op)
+			if err != nil {
+				logger.Error("Error deleting image from storage pool", logger.Ctx{"err": err, "pool": poolName, "fingerprint": info.Fingerprint})
+			}
+		}
+	}
+
+	// Distribute the new image to the other cluster members holding the old one.
+	if len(nodes) > 1 {
+		err = distributeImage(ctx, s, nodes, info.Fingerprint, &newInfo)
+		if err != nil {
+			logger.Error("Failed to distribute optimized image", logger.Ctx{"err": err, "fingerprint": newInfo.Fingerprint})
+		}
+	}
+
+	// Remove the old image, the leftover files on other cluster members get pruned later on.
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteImage(ctx, imageID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error deleting old image from database: %w", err)
+	}
+
+	if !referenced {
+		imageDeleteFromDisk(info.Fingerprint)
+	}
+
+	err = s.Authorizer.DeleteImage(s.ShutdownCtx, projectName, info.Fingerprint)
+	if err != nil {
+		logger.Error("Failed to remove image from authorizer", logger.Ctx{"fingerprint": info.Fingerprint, "project": projectName, "error": err})
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.ImageDeleted.Event(info.Fingerprint, projectName, op.Requestor(), nil))
+
+	return &newInfo, nil
+}
+
+// imageOptimizeConvert writes the image files converted to the given format and compression into builddir.
+// It returns the path of the metadata (or unified) file, followed by the path of the rootfs file for split images.
+func imageOptimizeConvert(builddir string, imagePath string, rootfsPath string, imageType string, format string, compress string) ([]string, error) {
+	isVM := imageType == instancetype.VM.String()
+
+	metaReader, metaDone, err := imageOptimizeOpen(imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = metaDone() }()
+
+	metaFile, err := os.CreateTemp(builddir, "incus_image_")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = metaFile.Close() }()
+
+	// The squashfs format only applies to the root filesystem of split images.
+	metaCompress := compress
+	if format == "split" && compress == "squashfs" {
+		metaCompress = "xz"
+	}
+
+	metaWriter, metaClose := imageOptimizeCreate(metaFile, metaCompress)
+
+	files := []string{metaFile.Name()}
+
+	var rootfsFile *os.File
+	var rootfsWriter *tar.Writer
+	rootfsClose := func() error { return nil }
+
+	if format == "split" {
+		rootfsFile, err = os.CreateTemp(builddir, "incus_image_")
+		if err != nil {
+			_ = metaClose()
+			return nil, err
+		}
+
+		defer func() { _ = rootfsFile.Close() }()
+
+		files = append(files, rootfsFile.Name())
+
+		if !isVM {
+			rootfsWriter, rootfsClose = imageOptimizeCreate(rootfsFile, compress)
+		}
+	}
+
+	closeAll := func() error {
+		err := metaClose()
+		rootfsErr := rootfsClose()
+		if err != nil {
+			return err
+		}
+
+		return rootfsErr
+	}
+
+	// Copy the content of the metadata (or unified) file.
+	for {
+		hdr, err := metaReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			_ = closeAll()
+			return nil, fmt.Errorf("Failed reading image: %w", err)
+		}
+
+		name := strings.TrimPrefix(hdr.Name, "./")
+
+		if format == "split" && isVM && name == "rootfs.img" {
+			_, err = io.Copy(rootfsFile, metaReader)
+		} else if format == "split" && !isVM && (name == "rootfs" || name == "rootfs/" || strings.HasPrefix(name, "rootfs/")) {
+			err = imageOptimizeCopyEntry(rootfsWriter, hdr, metaReader, func(name string) string {
+				name = strings.Trim(strings.TrimPrefix(strings.TrimPrefix(name, "./"), "rootfs"), "/")
+				if name == "" {
+					return "./"
+				}
+
+				return name
+			})
+		} else {
+			err = imageOptimizeCopyEntry(metaWriter, hdr, metaReader, nil)
+		}
+
+		if err != nil {
+			_ = closeAll()
+			return nil, fmt.Errorf("Failed converting image entry %q: %w", hdr.Name, err)
+		}
+	}
+
+	err = metaDone()
+	if err != nil {
+		_ = closeAll()
+		return nil, fmt.Errorf("Failed reading image: %w", err)
+	}
+
+	// Copy the content of the rootfs file of split images.
+	if rootfsPath != "" {
+		if isVM {
+			err = imageOptimizeCopyRootfsImage(rootfsPath, format, metaWriter, rootfsFile)
+		} else {
+			err = imageOptimizeCopyRootfs(rootfsPath, format, metaWriter, rootfsWriter)
+		}
+
+		if err != nil {
+			_ = closeAll()
+			return nil, err
+		}
+	}
+
+	err = closeAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// imageOptimizeCopyRootfs copies the root filesystem of a split container image into the unified or rootfs writer.
+func imageOptimizeCopyRootfs(rootfsPath string, format string, metaWriter *tar.Writer, rootfsWriter *tar.Writer) error {
+	rootfsReader, rootfsDone, err := imageOptimizeOpen(rootfsPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = rootfsDone() }()
+
+	writer := rootfsWriter
+	var rename func(string) string
+
+	if format == "unified" {
+		writer = metaWriter
+		rename = func(name string) string {
+			name = strings.TrimSuffix(strings.TrimPrefix(name, "./"), "/")
+			if name == "" || name == "." {
+				return "rootfs"
+			}
+
+			return "rootfs/" + name
+		}
+	}
+
+	for {
+		hdr, err := rootfsReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed reading image root filesystem: %w", err)
+		}
+
+		err = imageOptimizeCopyEntry(writer, hdr, rootfsReader, rename)
+		if err != nil {
+			return fmt.Errorf("Failed converting image root filesystem entry %q: %w", hdr.Name, err)
+		}
+	}
+
+	return rootfsDone()
+}
+
+// imageOptimizeCopyRootfsImage copies the disk image of a split VM image into the unified or rootfs file.
+func imageOptimizeCopyRootfsImage(rootfsPath string, format string, metaWriter *tar.Writer, rootfsFile *os.File) error {
+	f, err := os.Open(rootfsPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	if format == "split" {
+		_, err = io.Copy(rootfsFile, f)
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	err = metaWriter.WriteHeader(&tar.Header{
+		Name:     "rootfs.img",
+		Typeflag: tar.TypeReg,
+		Mode:     0600,
+		Size:     fi.Size(),
+		ModTime:  fi.ModTime(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(metaWriter, f)
+
+	return err
+}
+
+// imageOptimizeCopyEntry copies a tarball entry, optionally renaming it and the hard link it points to.
+func imageOptimizeCopyEntry(tw *tar.Writer, hdr *tar.Header, r io.Reader, rename func(string) string) error {
+	if rename != nil {
+		hdr.Name = rename(hdr.Name)
+		if hdr.Typeflag == tar.TypeDir && !strings.HasSuffix(hdr.Name, "/") {
+			hdr.Name += "/"
+		}
+
+		if hdr.Typeflag == tar.TypeLink {
+			hdr.Linkname = rename(hdr.Linkname)
+		}
+	}
+
+	err := tw.WriteHeader(hdr)
+	if err != nil {
+		return err
+	}
+
+	if hdr.Typeflag == tar.TypeReg {
+		_, err = io.Copy(tw, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// imageOptimizeOpen returns a tar reader for the (optionally compressed) tarball or squashfs image file.
+// The returned function must be called once done with the reader.
+func imageOptimizeOpen(fname string) (*tar.Reader, func() error, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, algo, unpacker, err := archive.DetectCompressionFile(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+
+	if algo == ".qcow2" {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("Image file %q isn't a tarball", fname)
+	}
+
+	if len(unpacker) == 0 {
+		return tar.NewReader(f), f.Close, nil
+	}
+
+	args := append([]string{}, unpacker[1:]...)
+	if algo == ".squashfs" {
+		// sqfs2tar can only read from a file.
+		args = append(args, fname)
+	}
+
+	cmd := exec.Command(unpacker[0], args...)
+	cmd.Stdin = f
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	var waitErr error
+	done := func() error {
+		once.Do(func() {
+			_ = stdout.Close()
+			waitErr = cmd.Wait()
+			_ = f.Close()
+		})
+
+		return waitErr
+	}
+
+	return tar.NewReader(stdout), done, nil
+}
+
+// imageOptimizeCreate returns a tar writer compressing its content with the given algorithm into the file.
+// The returned function must be called to flush the content and returns the compression error, if any.
+func imageOptimizeCreate(f *os.File, compress string) (*tar.Writer, func() error) {
+	if compress == "none" {
+		tw := tar.NewWriter(f)
+		return tw, tw.Close
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	tw := tar.NewWriter(pipeWriter)
+
+	var wg sync.WaitGroup
+	var compressErr error
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		compressErr = compressFile(compress, pipeReader, f)
+
+		// Unblock the writer if the compression failed early.
+		_ = pipeReader.CloseWithError(compressErr)
+	}()
+
+	return tw, func() error {
+		err := tw.Close()
+		_ = pipeWriter.Close()
+		wg.Wait()
+
+		if compressErr != nil {
+			return compressErr
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"archive/tar"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// imageOptimizeTestEntry is an entry of a test image tarball, a directory if the body is nil.
+type imageOptimizeTestEntry struct {
+	name string
+	body []byte
+}
+
+func imageOptimizeWriteTar(t *testing.T, path string, entries []imageOptimizeTestEntry) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	tw := tar.NewWriter(f)
+	for _, entry := range entries {
+		hdr := &tar.Header{Name: entry.name, Typeflag: tar.TypeDir, Mode: 0755}
+		if entry.body != nil {
+			hdr = &tar.Header{Name: entry.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(entry.body))}
+		}
+
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err = tw.Write(entry.body)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+}
+
+func imageOptimizeReadTar(t *testing.T, path string) map[string]string {
+	tr, done, err := imageOptimizeOpen(path)
+	require.NoError(t, err)
+
+	entries := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(tr)
+		require.NoError(t, err)
+
+		entries[hdr.Name] = string(body)
+	}
+
+	require.NoError(t, done())
+
+	return entries
+}
+
+func TestImageOptimizeConvert(t *testing.T) {
+	dir := t.TempDir()
+
+	unified := filepath.Join(dir, "unified")
+	imageOptimizeWriteTar(t, unified, []imageOptimizeTestEntry{
+		{name: "metadata.yaml", body: []byte("architecture: x86_64\n")},
+		{name: "rootfs/"},
+		{name: "rootfs/hello", body: []byte("world")},
+	})
+
+	split := filepath.Join(dir, "split")
+	imageOptimizeWriteTar(t, split, []imageOptimizeTestEntry{
+		{name: "metadata.yaml", body: []byte("architecture: x86_64\n")},
+	})
+
+	splitRootfs := filepath.Join(dir, "split.rootfs")
+	imageOptimizeWriteTar(t, splitRootfs, []imageOptimizeTestEntry{
+		{name: "./"},
+		{name: "./hello", body: []byte("world")},
+	})
+
+	vmRootfs := filepath.Join(dir, "vm.rootfs")
+	require.NoError(t, os.WriteFile(vmRootfs, []byte("disk"), 0600))
+
+	compressions := []string{"none"}
+	_, err := exec.LookPath("gzip")
+	if err == nil {
+		compressions = append(compressions, "gzip")
+	}
+
+	tests := []struct {
+		name       string
+		imagePath  string
+		rootfsPath string
+		imageType  string
+		format     string
+		files      []map[string]string
+	}{
+		{"Unified to split container", unified, "", "container", "split", []map[string]string{
+			{"metadata.yaml": "architecture: x86_64\n"},
+			{"./": "", "hello": "world"},
+		}},
+		{"Split to unified container", split, splitRootfs, "container", "unified", []map[string]string{
+			{"metadata.yaml": "architecture: x86_64\n", "rootfs/": "", "rootfs/hello": "world"},
+		}},
+		{"Unified container", unified, "", "container", "unified", []map[string]string{
+			{"metadata.yaml": "architecture: x86_64\n", "rootfs/": "", "rootfs/hello": "world"},
+		}},
+		{"Split to unified virtual machine", split, vmRootfs, "virtual-machine", "unified", []map[string]string{
+			{"metadata.yaml": "architecture: x86_64\n", "rootfs.img": "disk"},
+		}},
+	}
+
+	for _, compress := range compressions {
+		for i, tt := range tests {
+			log.Printf("Running test #%d: %s (%s)", i, tt.name, compress)
+
+			files, err := imageOptimizeConvert(t.TempDir(), tt.imagePath, tt.rootfsPath, tt.imageType, tt.format, compress)
+			require.NoError(t, err)
+			require.Len(t, files, len(tt.files))
+
+			for j, file := range files {
+				require.Equal(t, tt.files[j], imageOptimizeReadTar(t, file))
+			}
+		}
+	}
+}
@@ -67,6 +67,50 @@ func instanceCreateAsEmpty(s *state.State, args db.InstanceArgs) (instance.Insta
 	return inst, nil
 }
 
+// instanceCreateFromVolume creates a virtual machine whose root disk is a copy of a block custom volume.
+// The volume is looked up on srcPoolName, or on the storage pool of the instance if empty.
+func instanceCreateFromVolume(s *state.State, args db.InstanceArgs, srcPoolName string, srcVolName string, op *operations.Operation) (instance.Instance, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create the instance record.
+	inst, instOp, cleanup, err := instance.CreateInternal(s, args, true, true)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating instance record: %w", err)
+	}
+
+	revert.Add(cleanup)
+	defer instOp.Done(err)
+
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance storage pool: %w", err)
+	}
+
+	srcPool := pool
+	if srcPoolName != "" && srcPoolName != pool.Name() {
+		srcPool, err = storagePools.LoadByName(s, srcPoolName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading source storage pool %q: %w", srcPoolName, err)
+		}
+	}
+
+	err = pool.CreateInstanceFromVolume(inst, srcPool, srcVolName, op)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating instance from custom volume: %w", err)
+	}
+
+	revert.Add(func() { _ = inst.Delete(true) })
+
+	err = inst.UpdateBackupFile()
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Success()
+	return inst, nil
+}
+
 // instanceImageTransfer transfers an image from another cluster node.
 func instanceImageTransfer(s *state.State, r *http.Request, projectName string, hash string, nodeAddress string) error {
 	logger.Debugf("Transferring image %q from node %q", hash, nodeAddress)
@@ -186,6 +230,11 @@ func instanceCreateFromImage(ctx context.Context, s *state.State, r *http.Reques
 
 	revert.Add(func() { _ = inst.Delete(true) })
 
+	err = instanceRebaseRecord(s, inst, op)
+	if err != nil {
+		return err
+	}
+
 	err = inst.UpdateBackupFile()
 	if err != nil {
 		return err
@@ -216,7 +265,7 @@ func instanceRebuildFromImage(ctx context.Context, s *state.State, r *http.Reque
 		return fmt.Errorf("Failed rebuilding instance from image: %w", err)
 	}
 
-	return nil
+	return instanceRebaseRecord(s, inst, op)
 }
 
 func instanceRebuildFromEmpty(s *state.State, inst instance.Instance, op *operations.Operation) error {
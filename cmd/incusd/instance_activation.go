@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// instanceActivationStartTimeout is how long a connection waits for the activated instance to accept it.
+const instanceActivationStartTimeout = 2 * time.Minute
+
+// instanceActivationStopTimeout is how long an idle instance is given to shut down cleanly.
+const instanceActivationStopTimeout = 30 * time.Second
+
+// instanceActivationReloadInterval is how often the local instances are reloaded when no event marked them as changed.
+const instanceActivationReloadInterval = time.Minute
+
+// instanceActivationRetryInterval is how long to wait before listening again on addresses which couldn't be bound.
+const instanceActivationRetryInterval = 30 * time.Second
+
+// instanceActivationState tracks the listeners of a socket activated instance.
+type instanceActivationState struct {
+	projectName  string
+	instanceName string
+	listen       string
+	port         string
+
+	listeners []net.Listener
+	retryAt   time.Time // When to listen again after a failure.
+
+	mu           sync.Mutex // Serializes the starts of the instance.
+	connsMu      sync.Mutex
+	conns        int
+	lastActivity time.Time
+}
+
+// instanceActivationStates holds the socket activation state of the local instances, keyed by project and name.
+var instanceActivationStates = map[string]*instanceActivationState{}
+var instanceActivationStatesMu sync.Mutex
+
+// instanceActivationInstances caches the local instances between the updates, until instanceActivationStale is set
+// or instanceActivationReloadInterval elapsed.
+var instanceActivationInstances []instance.Instance
+var instanceActivationLoaded time.Time
+var instanceActivationStale atomic.Bool
+
+// instanceActivationHandleEvent marks the cached instances as stale on the lifecycle events which may change their
+// socket activation configuration.
+func instanceActivationHandleEvent(event api.Event) {
+	if instanceActivationEventReloads(event) {
+		instanceActivationStale.Store(true)
+	}
+}
+
+// instanceActivationEventReloads returns whether the event requires reloading the local instances.
+func instanceActivationEventReloads(event api.Event) bool {
+	if event.Type != api.EventTypeLifecycle {
+		return false
+	}
+
+	lifecycleEvent := api.EventLifecycle{}
+
+	err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+	if err != nil {
+		return false
+	}
+
+	for _, prefix := range []string{"instance-", "profile-", "project-"} {
+		if strings.HasPrefix(lifecycleEvent.Action, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// instanceActivationLoad returns the local instances, reloading them when they may have changed.
+func instanceActivationLoad(s *state.State) ([]instance.Instance, error) {
+	if instanceActivationInstances != nil && !instanceActivationStale.Load() && time.Since(instanceActivationLoaded) < instanceActivationReloadInterval {
+		return instanceActivationInstances, nil
+	}
+
+	// Clear the flag first so that changes happening while loading trigger another reload.
+	instanceActivationStale.Store(false)
+
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		instanceActivationStale.Store(true)
+		return nil, err
+	}
+
+	instanceActivationInstances = instances
+	instanceActivationLoaded = time.Now()
+
+	return instances, nil
+}
+
+// instanceActivationTask periodically updates the socket activation listeners and stops the idle instances.
+func instanceActivationTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := instanceActivationSync(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed updating socket activated instances", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(5 * time.Second)
+}
+
+// instanceActivationSync opens and closes the listeners following the configuration of the local instances, and stops
+// the running instances which have been idle for longer than their activation.idle_timeout.
+func instanceActivationSync(ctx context.Context, s *state.State) error {
+	instanceActivationStatesMu.Lock()
+	defer instanceActivationStatesMu.Unlock()
+
+	instances, err := instanceActivationLoad(s)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(instances))
+
+	for _, inst := range instances {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		config := inst.ExpandedConfig()
+		if config["activation.listen"] == "" || config["activation.port"] == "" {
+			continue
+		}
+
+		key := project.Instance(inst.Project().Name, inst.Name())
+		seen[key] = struct{}{}
+
+		// Re-create the listeners when their configuration changed.
+		activation, ok := instanceActivationStates[key]
+		if ok && (activation.listen != config["activation.listen"] || activation.port != config["activation.port"]) {
+			activation.close()
+			ok = false
+		}
+
+		if !ok {
+			activation = &instanceActivationState{
+				projectName:  inst.Project().Name,
+				instanceName: inst.Name(),
+				listen:       config["activation.listen"],
+				port:         config["activation.port"],
+				lastActivity: time.Now(),
+			}
+
+			instanceActivationStates[key] = activation
+		}
+
+		// Listen on the addresses, retrying those which couldn't be bound previously.
+		if activation.listeners == nil && !time.Now().Before(activation.retryAt) {
+			err := activation.open(s)
+			if err != nil {
+				activation.retryAt = time.Now().Add(instanceActivationRetryInterval)
+				logger.Warn("Failed listening for socket activated instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			}
+		}
+
+		// Stop the idle instances.
+		idleTimeout, _ := strconv.Atoi(config["activation.idle_timeout"])
+		if idleTimeout <= 0 || !inst.IsRunning() {
+			continue
+		}
+
+		if activation.idleSince() < time.Duration(idleTimeout)*time.Second {
+			continue
+		}
+
+		go instanceActivationStop(s, activation, inst)
+	}
+
+	// Close the listeners of the instances which aren't socket activated anymore.
+	for key, activation := range instanceActivationStates {
+		_, ok := seen[key]
+		if !ok {
+			activation.close()
+			delete(instanceActivationStates, key)
+		}
+	}
+
+	return nil
+}
+
+// instanceActivationStop stops an idle socket activated instance.
+func instanceActivationStop(s *state.State, activation *instanceActivationState, inst instance.Instance) {
+	// Skip instances which are already being started or stopped.
+	if !activation.mu.TryLock() {
+		return
+	}
+
+	defer activation.mu.Unlock()
+
+	// A connection may have come in since the check.
+	if activation.active() || !inst.IsRunning() {
+		return
+	}
+
+	logger.Info("Stopping idle socket activated instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	err := inst.Shutdown(instanceActivationStopTimeout)
+	if err != nil {
+		err = inst.Stop(false)
+		if err != nil {
+			logger.Warn("Failed stopping idle socket activated instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+		}
+	}
+
+	activation.touch()
+}
+
+// open starts listening on the configured addresses. Either all the addresses are listened on or none of them.
+func (a *instanceActivationState) open(s *state.State) error {
+	listeners := []net.Listener{}
+
+	for _, address := range util.SplitNTrimSpace(a.listen, ",", -1, true) {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			for _, listener := range listeners {
+				_ = listener.Close()
+			}
+
+			return fmt.Errorf("Failed listening on %q: %w", address, err)
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	a.listeners = listeners
+
+	for _, listener := range listeners {
+		go a.serve(s, listener)
+	}
+
+	return nil
+}
+
+// close stops listening, leaving the established connections alone.
+func (a *instanceActivationState) close() {
+	for _, listener := range a.listeners {
+		_ = listener.Close()
+	}
+
+	a.listeners = nil
+}
+
+// serve accepts the connections of a listener until it's closed or the daemon shuts down.
+func (a *instanceActivationState) serve(s *state.State, listener net.Listener) {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-s.ShutdownCtx.Done():
+			_ = listener.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			logger.Warn("Failed accepting connection for socket activated instance", logger.Ctx{"project": a.projectName, "instance": a.instanceName, "err": err})
+			continue
+		}
+
+		go a.forward(s, conn)
+	}
+}
+
+// forward starts the instance if needed and forwards the connection to it.
+func (a *instanceActivationState) forward(s *state.State, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	a.connsMu.Lock()
+	a.conns++
+	a.connsMu.Unlock()
+
+	defer func() {
+		a.connsMu.Lock()
+		a.conns--
+		a.lastActivity = time.Now()
+		a.connsMu.Unlock()
+	}()
+
+	backend, err := a.connect(s)
+	if err != nil {
+		logger.Warn("Failed forwarding connection to socket activated instance", logger.Ctx{"project": a.projectName, "instance": a.instanceName, "err": err})
+		return
+	}
+
+	defer func() { _ = backend.Close() }()
+
+	done := make(chan struct{}, 2)
+
+	go func() {
+		_, _ = io.Copy(backend, conn)
+		done <- struct{}{}
+	}()
+
+	go func() {
+		_, _ = io.Copy(conn, backend)
+		done <- struct{}{}
+	}()
+
+	<-done
+}
+
+// connect starts the instance if it's stopped and connects to its port, waiting for the instance to accept the
+// connection.
+func (a *instanceActivationState) connect(s *state.State) (net.Conn, error) {
+	// Load the instance before waiting on other connections starting it.
+	inst, err := instance.LoadByProjectAndName(s, a.projectName, a.instanceName)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !inst.IsRunning() {
+		logger.Info("Starting socket activated instance", logger.Ctx{"project": a.projectName, "instance": a.instanceName})
+
+		err = inst.Start(false)
+		if err != nil {
+			return nil, fmt.Errorf("Failed starting instance: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(s.ShutdownCtx, instanceActivationStartTimeout)
+	defer cancel()
+
+	for {
+		address, err := instanceHealthAddress(inst, a.port)
+		if err == nil {
+			dialer := net.Dialer{Timeout: 5 * time.Second}
+
+			var backend net.Conn
+			backend, err = dialer.DialContext(ctx, "tcp", address)
+			if err == nil {
+				return backend, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("Instance didn't accept the connection in time: %w", err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// active returns whether connections are being forwarded to the instance.
+func (a *instanceActivationState) active() bool {
+	a.connsMu.Lock()
+	defer a.connsMu.Unlock()
+
+	return a.conns > 0
+}
+
+// touch records activity on the instance.
+func (a *instanceActivationState) touch() {
+	a.connsMu.Lock()
+	defer a.connsMu.Unlock()
+
+	a.lastActivity = time.Now()
+}
+
+// idleSince returns how long no connection has been forwarded to the instance.
+func (a *instanceActivationState) idleSince() time.Duration {
+	a.connsMu.Lock()
+	defer a.connsMu.Unlock()
+
+	if a.conns > 0 {
+		return 0
+	}
+
+	return time.Since(a.lastActivity)
+}
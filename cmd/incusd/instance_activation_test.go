@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestInstanceActivationEventReloads(t *testing.T) {
+	newEvent := func(eventType string, action string) api.Event {
+		metadata, err := json.Marshal(api.EventLifecycle{Action: action})
+		require.NoError(t, err)
+
+		return api.Event{Type: eventType, Metadata: metadata}
+	}
+
+	tests := []struct {
+		name    string
+		event   api.Event
+		reloads bool
+	}{
+		{"Instance updated", newEvent(api.EventTypeLifecycle, api.EventLifecycleInstanceUpdated), true},
+		{"Instance created", newEvent(api.EventTypeLifecycle, api.EventLifecycleInstanceCreated), true},
+		{"Profile updated", newEvent(api.EventTypeLifecycle, api.EventLifecycleProfileUpdated), true},
+		{"Project updated", newEvent(api.EventTypeLifecycle, api.EventLifecycleProjectUpdated), true},
+		{"Network updated", newEvent(api.EventTypeLifecycle, api.EventLifecycleNetworkUpdated), false},
+		{"Logging event", newEvent(api.EventTypeLogging, api.EventLifecycleInstanceUpdated), false},
+		{"Invalid metadata", api.Event{Type: api.EventTypeLifecycle, Metadata: []byte("{")}, false},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		require.Equal(t, tt.reloads, instanceActivationEventReloads(tt.event))
+	}
+}
+
+func TestInstanceActivationOpen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &state.State{ShutdownCtx: ctx}
+
+	// Occupy an address so that listening on it fails.
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	freeAddress := free.Addr().String()
+	require.NoError(t, free.Close())
+
+	activation := &instanceActivationState{listen: freeAddress + "," + busy.Addr().String()}
+
+	err = activation.open(s)
+	require.Error(t, err)
+	require.Nil(t, activation.listeners)
+
+	// The address which could be bound was released again.
+	check, err := net.Listen("tcp", freeAddress)
+	require.NoError(t, err)
+	require.NoError(t, check.Close())
+
+	// Retrying succeeds once the address is available.
+	require.NoError(t, busy.Close())
+
+	goroutines := runtime.NumGoroutine()
+
+	err = activation.open(s)
+	require.NoError(t, err)
+	require.Len(t, activation.listeners, 2)
+
+	activation.close()
+	require.Nil(t, activation.listeners)
+
+	// Closing the listeners stops their goroutines.
+	for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
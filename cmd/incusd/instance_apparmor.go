@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/apparmor"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+)
+
+var instanceAppArmorCmd = APIEndpoint{
+	Name: "instanceAppArmor",
+	Path: "instances/{name}/apparmor",
+
+	Get:  APIEndpointAction{Handler: instanceAppArmorGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "name")},
+	Post: APIEndpointAction{Handler: instanceAppArmorPost, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+// instanceAppArmorLoad loads the container targeted by an AppArmor request.
+// A response is returned instead when the request must be forwarded to another cluster member or fails.
+func instanceAppArmorLoad(d *Daemon, r *http.Request) (instance.Instance, response.Response) {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return nil, response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different node
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	if resp != nil {
+		return nil, resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	if inst.Type() != instancetype.Container {
+		return nil, response.BadRequest(fmt.Errorf("AppArmor profiles can only be loaded for containers"))
+	}
+
+	return inst, nil
+}
+
+// swagger:operation GET /1.0/instances/{name}/apparmor instances instance_apparmor_get
+//
+//	Get the AppArmor profiles
+//
+//	Gets the AppArmor profiles defined in `raw.apparmor.profiles` and whether
+//	they're loaded in the AppArmor namespace of the container.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: AppArmor profiles
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceAppArmor"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceAppArmorGet(d *Daemon, r *http.Request) response.Response {
+	inst, resp := instanceAppArmorLoad(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	profiles, err := apparmor.InstanceProfiles(d.State().OS, inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, profiles)
+}
+
+// swagger:operation POST /1.0/instances/{name}/apparmor instances instance_apparmor_post
+//
+//	Reload the AppArmor profiles
+//
+//	Validates the AppArmor profiles defined in `raw.apparmor.profiles` and
+//	loads them again in the AppArmor namespace of the running container.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceAppArmorPost(d *Daemon, r *http.Request) response.Response {
+	inst, resp := instanceAppArmorLoad(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	if !inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("The AppArmor profiles can only be reloaded while the container is running"))
+	}
+
+	err := apparmor.InstanceProfilesLoad(d.State().OS, inst)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Failed loading raw.apparmor.profiles: %w", err))
+	}
+
+	return response.EmptySyncResponse
+}
@@ -329,7 +329,7 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 			CompressionAlgorithm: req.CompressionAlgorithm,
 		}
 
-		err := backupCreate(s, args, inst, op)
+		err := backupCreate(s, args, inst, req.NetworkState, op)
 		if err != nil {
 			return fmt.Errorf("Create backup: %w", err)
 		}
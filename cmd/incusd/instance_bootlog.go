@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	liblxc "github.com/lxc/go-lxc"
+	"golang.org/x/sys/unix"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/apparmor"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// instanceBootLogMaxSize is the maximum amount of each log included in a boot log report.
+const instanceBootLogMaxSize = 1024 * 1024
+
+// instanceConsoleLogMaxSize is the size over which the console log of a virtual machine is trimmed to its end.
+const instanceConsoleLogMaxSize = 8 * 1024 * 1024
+
+// instanceBootLogAuditTime matches the timestamp of the audit messages of the kernel log.
+var instanceBootLogAuditTime = regexp.MustCompile(`audit\((\d+)\.\d+:\d+\)`)
+
+// instanceBootLogSetupError matches the instance log errors related to the id mapping and control group setup.
+var instanceBootLogSetupError = regexp.MustCompile(`(?i)idmap|uid_map|gid_map|newuidmap|newgidmap|id mapping|cgroup|cgfsng`)
+
+var instanceBootLogCmd = APIEndpoint{
+	Name: "instanceBootLog",
+	Path: "instances/{name}/bootlog",
+
+	Get: APIEndpointAction{Handler: instanceBootLogGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "name")},
+}
+
+// swagger:operation GET /1.0/instances/{name}/bootlog instances instance_bootlog_get
+//
+//	Get the boot diagnostics
+//
+//	Gets a report of the last start attempt of the instance, aggregating the
+//	instance log, the console output and the AppArmor and seccomp violations.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: since
+//	    description: Only include the kernel log entries from this time on (defaults to the end of the previous run)
+//	    type: string
+//	    example: 2024-05-02T10:15:12Z
+//	responses:
+//	  "200":
+//	    description: Boot log
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceBootLog"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceBootLogGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different node
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	var since time.Time
+	if request.QueryParam(r, "since") != "" {
+		since, err = time.Parse(time.RFC3339, request.QueryParam(r, "since"))
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid since time: %w", err))
+		}
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// The instance log is rotated on every start, so the previous log was last written at the end of the previous run.
+	if since.IsZero() {
+		info, err := os.Stat(inst.LogFilePath() + ".old")
+		if err == nil {
+			since = info.ModTime()
+		}
+	}
+
+	report := api.InstanceBootLog{
+		Status:            inst.State(),
+		CollectedAt:       time.Now().UTC(),
+		Since:             since.UTC(),
+		AppArmorDenials:   []string{},
+		SeccompViolations: []string{},
+		SetupErrors:       []string{},
+		Warnings:          []string{},
+	}
+
+	// The instance and console logs only cover the last start attempt.
+	report.Log, err = instanceBootLogRead(inst.LogFilePath())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	report.Console, err = instanceBootLogConsole(inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if inst.Type() == instancetype.Container {
+		for _, line := range strings.Split(report.Log, "\n") {
+			if strings.Contains(line, " ERROR ") && instanceBootLogSetupError.MatchString(line) {
+				report.SetupErrors = append(report.SetupErrors, line)
+			}
+		}
+	}
+
+	// Look for the violations of the instance in the kernel log, the rest of the report is still useful without it.
+	kernelLog, err := instanceBootLogKernel()
+	if err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+	} else {
+		report.AppArmorDenials, report.SeccompViolations = instanceBootLogViolations(kernelLog, apparmor.InstanceProfileName(inst), since)
+	}
+
+	return response.SyncResponse(true, report)
+}
+
+// instanceBootLogViolations returns the AppArmor denials and seccomp violations of the kernel log lines labeled with
+// the AppArmor profile of an instance, leaving out the audit messages from before the since time.
+func instanceBootLogViolations(lines []string, profile string, since time.Time) ([]string, []string) {
+	denials := []string{}
+	violations := []string{}
+
+	for _, line := range lines {
+		if !strings.Contains(line, profile) {
+			continue
+		}
+
+		fields := instanceBootLogAuditTime.FindStringSubmatch(line)
+		if fields != nil && !since.IsZero() {
+			seconds, err := strconv.ParseInt(fields[1], 10, 64)
+			if err == nil && time.Unix(seconds, 0).Before(since.Truncate(time.Second)) {
+				continue
+			}
+		}
+
+		if strings.Contains(line, `apparmor="DENIED"`) {
+			denials = append(denials, line)
+		} else if strings.Contains(line, "type=1326") {
+			violations = append(violations, line)
+		}
+	}
+
+	return denials, violations
+}
+
+// instanceBootLogRead returns the end of a log file, or an empty string if it doesn't exist.
+func instanceBootLogRead(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	if info.Size() > instanceBootLogMaxSize {
+		_, err = f.Seek(-instanceBootLogMaxSize, io.SeekEnd)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// instanceBootLogConsole returns the console output of an instance.
+// Virtual machines record the output of their console in the console buffer log file.
+func instanceBootLogConsole(inst instance.Instance) (string, error) {
+	c, ok := inst.(instance.Container)
+	if !ok || !c.IsRunning() || !liblxc.RuntimeLiblxcVersionAtLeast(liblxc.Version(), 3, 0, 0) {
+		return instanceBootLogRead(inst.ConsoleBufferLogPath())
+	}
+
+	// Query the container's console ringbuffer.
+	logContents, err := c.ConsoleLog(liblxc.ConsoleLogOptions{ReadLog: true})
+	if err != nil {
+		return instanceBootLogRead(inst.ConsoleBufferLogPath())
+	}
+
+	if len(logContents) > instanceBootLogMaxSize {
+		logContents = logContents[len(logContents)-instanceBootLogMaxSize:]
+	}
+
+	return logContents, nil
+}
+
+// instanceBootLogKernel returns the lines of the kernel ring buffer.
+func instanceBootLogKernel() ([]string, error) {
+	size, err := unix.Klogctl(unix.SYSLOG_ACTION_SIZE_BUFFER, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting kernel log size: %w", err)
+	}
+
+	buf := make([]byte, size)
+	n, err := unix.Klogctl(unix.SYSLOG_ACTION_READ_ALL, buf)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading kernel log: %w", err)
+	}
+
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(buf[:n]))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, nil
+}
+
+// instanceConsoleLogTrimTask periodically trims the console logs of the running local virtual machines.
+// QEMU doesn't limit the size of the console log file, which a guest could otherwise use to fill the host.
+func instanceConsoleLogTrimTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		instances, err := instance.LoadNodeAll(d.State(), instancetype.VM)
+		if err != nil {
+			logger.Warn("Failed loading instances", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range instances {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if !inst.IsRunning() {
+				continue
+			}
+
+			err := instanceConsoleLogTrim(inst.ConsoleBufferLogPath(), instanceConsoleLogMaxSize, instanceBootLogMaxSize)
+			if err != nil {
+				logger.Warn("Failed trimming console log", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name, "err": err})
+			}
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// instanceConsoleLogTrim only keeps the last keepSize bytes of a log file once it grows over maxSize.
+// The writer must append to the file so that its next writes land at the new end of the file.
+// Output written while the file is being trimmed may be lost.
+func instanceConsoleLogTrim(path string, maxSize int64, keepSize int64) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() || info.Size() <= maxSize {
+		return nil
+	}
+
+	content := make([]byte, keepSize)
+	n, err := f.ReadAt(content, info.Size()-keepSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	err = f.Truncate(0)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(content[:n])
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceBootLogViolations(t *testing.T) {
+	lines := []string{
+		`audit: type=1400 audit(1714644000.123:40): apparmor="DENIED" operation="mount" profile="incus-c1_</var/lib/incus>"`,
+		`audit: type=1400 audit(1714644912.345:42): apparmor="DENIED" operation="mount" profile="incus-c1_</var/lib/incus>"`,
+		`audit: type=1326 audit(1714644912.345:43): auid=4294967295 comm="mount" exe="/bin/mount" syscall=165 profile="incus-c1_</var/lib/incus>"`,
+		`audit: type=1400 audit(1714644912.345:44): apparmor="DENIED" operation="mount" profile="incus-c2_</var/lib/incus>"`,
+		`incus-c1_</var/lib/incus>: unrelated`,
+	}
+
+	// Without a time, all the violations of the instance are included.
+	denials, violations := instanceBootLogViolations(lines, "incus-c1_</var/lib/incus>", time.Time{})
+	require.Equal(t, []string{lines[0], lines[1]}, denials)
+	require.Equal(t, []string{lines[2]}, violations)
+
+	// The audit messages from before the time are left out.
+	denials, violations = instanceBootLogViolations(lines, "incus-c1_</var/lib/incus>", time.Unix(1714644912, 500000000))
+	require.Equal(t, []string{lines[1]}, denials)
+	require.Equal(t, []string{lines[2]}, violations)
+
+	denials, violations = instanceBootLogViolations(lines, "incus-c1_</var/lib/incus>", time.Unix(1714645000, 0))
+	require.Empty(t, denials)
+	require.Empty(t, violations)
+}
+
+func TestInstanceConsoleLogTrim(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+
+	// A missing log is ignored.
+	require.NoError(t, instanceConsoleLogTrim(path, 10, 4))
+
+	// A log under the limit is left alone.
+	require.NoError(t, os.WriteFile(path, []byte("0123456789"), 0o600))
+	require.NoError(t, instanceConsoleLogTrim(path, 10, 4))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "0123456789", string(content))
+
+	// A log over the limit is trimmed to its end.
+	require.NoError(t, os.WriteFile(path, []byte("0123456789abc"), 0o600))
+	require.NoError(t, instanceConsoleLogTrim(path, 10, 4))
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "9abc", string(content))
+
+	// Appending writers keep writing at the end of the trimmed log.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	_, err = f.WriteString("defghijklmn")
+	require.NoError(t, err)
+	require.NoError(t, instanceConsoleLogTrim(path, 10, 4))
+
+	_, err = f.WriteString("op")
+	require.NoError(t, err)
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "klmnop", string(content))
+}
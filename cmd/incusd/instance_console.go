@@ -80,6 +80,22 @@ func (s *consoleWs) Metadata() any {
 	return jmap.Map{"fds": fds}
 }
 
+// Disconnect closes the websockets of the console session.
+func (s *consoleWs) Disconnect() {
+	s.connsLock.Lock()
+	defer s.connsLock.Unlock()
+
+	for _, conn := range s.conns {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}
+
+	for conn := range s.dynamic {
+		_ = conn.Close()
+	}
+}
+
 func (s *consoleWs) Connect(op *operations.Operation, r *http.Request, w http.ResponseWriter) error {
 	switch s.protocol {
 	case instance.ConsoleTypeConsole:
@@ -486,6 +502,12 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("VGA console is only supported by virtual machines"))
 	}
 
+	// Resume instances suspended by their idle policy.
+	err = instanceIdleResume(inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	if !inst.IsRunning() {
 		return response.BadRequest(fmt.Errorf("Instance is not running"))
 	}
@@ -522,6 +544,8 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
+	instanceShareSessionTrack(r, op, projectName, inst.Name(), ws.Disconnect)
+
 	return operations.OperationResponse(op)
 }
 
@@ -0,0 +1,708 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/rsync"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/device"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/idmap"
+	"github.com/lxc/incus/v6/shared/osarch"
+	"github.com/lxc/incus/v6/shared/subprocess"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// instanceConvertExcludes are the paths specific to an instance type.
+// They aren't copied from the instance being converted and are kept from the image of the new instance type,
+// providing the kernel, boot loader and agent of virtual machines.
+var instanceConvertExcludes = []string{
+	"/boot",
+	"/efi",
+	"/lib/modules",
+	"/usr/lib/modules",
+	"/etc/fstab",
+	"/dev",
+	"/proc",
+	"/sys",
+	"incus-agent*",
+	"*-incus-agent.rules",
+}
+
+// instanceConvertFilesystems are the filesystems the root partition of a converted virtual machine may use.
+var instanceConvertFilesystems = []string{"ext2", "ext3", "ext4", "xfs", "btrfs"}
+
+// instanceConvertConfig presents the configuration of an instance as if it was of another instance type.
+type instanceConvertConfig struct {
+	instance.ConfigReader
+
+	instanceType instancetype.Type
+}
+
+// Type returns the instance type the configuration is converted to.
+func (c *instanceConvertConfig) Type() instancetype.Type {
+	return c.instanceType
+}
+
+// swagger:operation POST /1.0/instances/{name}/convert instances instance_convert_post
+//
+//	Convert an instance
+//
+//	Convert a container to a virtual machine or a virtual machine to a container.
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: instance
+//	    description: InstanceConvert request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceConvertPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceConvertPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instancetype.Any)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	// Parse the request.
+	req := api.InstanceConvertPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	targetType, err := instancetype.New(string(req.Type))
+	if err != nil || targetType == instancetype.Any {
+		return response.BadRequest(fmt.Errorf("Invalid instance type %q", req.Type))
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if inst.Type() == targetType {
+		return response.BadRequest(fmt.Errorf("Instance is already of type %q", targetType))
+	}
+
+	if inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("Instance must be stopped to be converted"))
+	}
+
+	var targetProject *api.Project
+	var sourceImage *api.Image
+	var sourceImageRef string
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return fmt.Errorf("Failed loading project: %w", err)
+		}
+
+		targetProject, err = dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		// Default to the same image as the instance, for the new instance type.
+		if req.Source.Type == "" {
+			req.Source, err = instanceConvertDefaultSource(ctx, tx, inst)
+			if err != nil {
+				return err
+			}
+		}
+
+		if req.Source.Type != "image" {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid source type %q", req.Source.Type)
+		}
+
+		sourceImage, err = getSourceImageFromInstanceSource(ctx, s, tx, targetProject.Name, req.Source, &sourceImageRef, targetType.String())
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(op *operations.Operation) error {
+		if req.Source.Server != "" {
+			architecture, err := osarch.ArchitectureName(inst.Architecture())
+			if err != nil {
+				return err
+			}
+
+			sourceImage, err = ensureDownloadedImageFitWithinBudget(context.TODO(), s, r, op, *targetProject, sourceImage, sourceImageRef, req.Source, targetType.String(), architecture)
+			if err != nil {
+				return err
+			}
+		}
+
+		if sourceImage == nil {
+			return fmt.Errorf("Image not provided for instance conversion")
+		}
+
+		if sourceImage.Type != targetType.String() {
+			return fmt.Errorf("Requested image's type %q doesn't match instance type %q", sourceImage.Type, targetType)
+		}
+
+		err := ensureImageIsLocallyAvailable(context.TODO(), s, r, sourceImage, targetProject.Name, targetType)
+		if err != nil {
+			return err
+		}
+
+		return instanceConvert(context.TODO(), s, r, inst, targetType, sourceImage, op)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
+
+	op, err := operations.OperationCreate(s, targetProject.Name, operations.OperationClassTask, operationtype.InstanceConvert, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// instanceConvertDefaultSource returns the source the image of the instance was downloaded from.
+func instanceConvertDefaultSource(ctx context.Context, tx *db.ClusterTx, inst instance.Instance) (api.InstanceSource, error) {
+	fingerprint := inst.LocalConfig()["volatile.base_image"]
+	if fingerprint == "" {
+		return api.InstanceSource{}, api.StatusErrorf(http.StatusBadRequest, "The instance wasn't created from an image, an image must be provided")
+	}
+
+	projectName := inst.Project().Name
+	imageID, _, err := tx.GetImageByFingerprintPrefix(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return api.InstanceSource{}, api.StatusErrorf(http.StatusBadRequest, "The image of the instance isn't available anymore, an image must be provided")
+		}
+
+		return api.InstanceSource{}, err
+	}
+
+	_, imageSource, err := tx.GetImageSource(ctx, imageID)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return api.InstanceSource{}, api.StatusErrorf(http.StatusBadRequest, "The image of the instance wasn't downloaded from a remote server, an image must be provided")
+		}
+
+		return api.InstanceSource{}, err
+	}
+
+	return api.InstanceSource{
+		Type:        "image",
+		Server:      imageSource.Server,
+		Protocol:    imageSource.Protocol,
+		Certificate: imageSource.Certificate,
+		Alias:       imageSource.Alias,
+	}, nil
+}
+
+// instanceConvert rebuilds the instance as the target instance type from the supplied image, copying the root
+// filesystem of the instance and of its snapshots over the one of the image, then replaces the instance.
+func instanceConvert(ctx context.Context, s *state.State, r *http.Request, inst instance.Instance, targetType instancetype.Type, img *api.Image, op *operations.Operation) error {
+	unlock, err := instanceOperationLock(s.ShutdownCtx, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	if inst.IsRunning() {
+		return fmt.Errorf("Instance must be stopped to be converted")
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Use the same temporary name as moves, allowing the copy to share the addresses of the instance.
+	tempName, err := instance.MoveTemporaryName(inst)
+	if err != nil {
+		return err
+	}
+
+	// Keep the configuration keys supported by the new instance type.
+	config := instanceConvertLocalConfig(inst.LocalConfig(), targetType)
+
+	// Keep the devices supported by the new instance type.
+	targetConfig := &instanceConvertConfig{ConfigReader: inst, instanceType: targetType}
+	devices := deviceConfig.Devices{}
+	for devName, dev := range inst.LocalDevices() {
+		err := device.Validate(targetConfig, s, devName, dev.Clone())
+		if err != nil {
+			if errors.Is(err, device.ErrUnsupportedDevType) {
+				continue
+			}
+
+			return fmt.Errorf("Device %q isn't valid for the converted instance: %w", devName, err)
+		}
+
+		devices[devName] = dev
+	}
+
+	args := db.InstanceArgs{
+		Project:      inst.Project().Name,
+		Name:         tempName,
+		Type:         targetType,
+		Architecture: inst.Architecture(),
+		Config:       config,
+		Description:  inst.Description(),
+		Devices:      devices,
+		Ephemeral:    inst.IsEphemeral(),
+		Profiles:     inst.Profiles(),
+		ExpiryDate:   inst.ExpiryDate(),
+	}
+
+	// Check that the project's limits allow the converted instance.
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		profileNames := make([]string, 0, len(args.Profiles))
+		for _, profile := range args.Profiles {
+			profileNames = append(profileNames, profile.Name)
+		}
+
+		req := api.InstancesPost{
+			InstancePut: api.InstancePut{
+				Config:    args.Config,
+				Devices:   args.Devices.CloneNative(),
+				Ephemeral: args.Ephemeral,
+				Profiles:  profileNames,
+			},
+			Name:   args.Name,
+			Source: api.InstanceSource{}, // Only relevant for "copy" or "migration", but may not be nil.
+			Type:   args.Type.ToAPI(),
+		}
+
+		return project.AllowInstanceCreation(tx, args.Project, req)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = instanceCreateFromImage(ctx, s, r, img, args, op)
+	if err != nil {
+		return fmt.Errorf("Failed creating converted instance: %w", err)
+	}
+
+	newInst, err := instance.LoadByProjectAndName(s, inst.Project().Name, tempName)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _ = newInst.Delete(true) })
+
+	// Copy the snapshots, oldest first, then the current state of the instance.
+	snapshots, err := inst.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range snapshots {
+		err = instanceConvertCopy(s, snap, newInst, op)
+		if err != nil {
+			return err
+		}
+
+		_, snapName, _ := api.GetParentAndSnapshotName(snap.Name())
+		err = newInst.Snapshot(snapName, snap.ExpiryDate(), false)
+		if err != nil {
+			return fmt.Errorf("Failed creating snapshot %q: %w", snapName, err)
+		}
+	}
+
+	err = instanceConvertCopy(s, inst, newInst, op)
+	if err != nil {
+		return err
+	}
+
+	// Replace the instance with the converted one, moving the original out of the way first so that it can be
+	// restored if the converted instance can't take over its name.
+	name := inst.Name()
+	oldName := fmt.Sprintf("convert-of-%d", rand.Uint64())
+
+	err = inst.Rename(oldName, false)
+	if err != nil {
+		return fmt.Errorf("Failed renaming original instance: %w", err)
+	}
+
+	revert.Add(func() { _ = inst.Rename(name, false) })
+
+	err = newInst.Rename(name, false)
+	if err != nil {
+		return fmt.Errorf("Failed renaming converted instance: %w", err)
+	}
+
+	revert.Success()
+
+	err = inst.Delete(true)
+	if err != nil {
+		return fmt.Errorf("Failed deleting original instance %q: %w", oldName, err)
+	}
+
+	return nil
+}
+
+// instanceConvertLocalConfig returns the configuration keys to keep from an instance converted to the target type.
+func instanceConvertLocalConfig(localConfig map[string]string, targetType instancetype.Type) map[string]string {
+	config := map[string]string{}
+	for key, value := range localConfig {
+		if strings.HasPrefix(key, "image.") {
+			continue
+		}
+
+		if strings.HasPrefix(key, "volatile.") && key != "volatile.uuid" && !strings.HasSuffix(key, ".hwaddr") {
+			continue
+		}
+
+		_, err := internalInstance.ConfigKeyChecker(key, targetType.ToAPI())
+		if err != nil {
+			continue
+		}
+
+		config[key] = value
+	}
+
+	return config
+}
+
+// instanceConvertCopy copies the root filesystem of an instance or snapshot over the one of the converted instance.
+func instanceConvertCopy(s *state.State, src instance.Instance, dst instance.Instance, op *operations.Operation) error {
+	srcPath, srcCleanup, err := instanceConvertMount(s, src, true, op)
+	if err != nil {
+		return err
+	}
+
+	defer srcCleanup()
+
+	dstPath, dstCleanup, err := instanceConvertMount(s, dst, false, op)
+	if err != nil {
+		return err
+	}
+
+	defer dstCleanup()
+
+	// The root filesystem of the converted container may be shifted to the IDs it's mapped to on the host. Bring it
+	// back to the IDs used inside the instance while copying the files of the virtual machine, then shift it again.
+	var dstIdmap *idmap.Set
+	c, ok := dst.(instance.Container)
+	if ok {
+		dstIdmap, err = c.DiskIdmap()
+		if err != nil {
+			return err
+		}
+
+		if dstIdmap != nil && len(dstIdmap.Entries) > 0 {
+			err = dstIdmap.UnshiftPath(dstPath, nil)
+			if err != nil {
+				return fmt.Errorf("Failed unshifting root filesystem of %q: %w", dst.Name(), err)
+			}
+		} else {
+			dstIdmap = nil
+		}
+	}
+
+	_, err = rsync.LocalCopy(srcPath, dstPath, "", true, instanceConvertRsyncArgs()...)
+	if err != nil {
+		return fmt.Errorf("Failed copying root filesystem of %q: %w", src.Name(), err)
+	}
+
+	if dstIdmap != nil {
+		err = dstIdmap.ShiftPath(dstPath, nil)
+		if err != nil {
+			return fmt.Errorf("Failed shifting root filesystem of %q: %w", dst.Name(), err)
+		}
+	}
+
+	// The files of containers are owned by the IDs they are mapped to on the host, bring them back to the IDs
+	// used inside the virtual machine. Files which aren't mapped come from the image and are left untouched.
+	c, ok = src.(instance.Container)
+	if ok {
+		diskIdmap, err := c.DiskIdmap()
+		if err != nil {
+			return err
+		}
+
+		if diskIdmap != nil && len(diskIdmap.Entries) > 0 {
+			skipper := func(dir string, absPath string, fi os.FileInfo, newuid int64, newgid int64) error {
+				if newuid < 0 || newgid < 0 {
+					return fmt.Errorf("Not mapped")
+				}
+
+				return nil
+			}
+
+			err = diskIdmap.UnshiftPath(dstPath, idmap.ShiftSkipper(skipper))
+			if err != nil {
+				return fmt.Errorf("Failed unshifting root filesystem of %q: %w", src.Name(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// instanceConvertRsyncArgs returns the rsync arguments skipping the paths which are specific to an instance type.
+func instanceConvertRsyncArgs() []string {
+	rsyncArgs := make([]string, 0, len(instanceConvertExcludes))
+	for _, exclude := range instanceConvertExcludes {
+		rsyncArgs = append(rsyncArgs, "--exclude="+exclude)
+	}
+
+	return rsyncArgs
+}
+
+// instanceConvertMountOptions returns the options used to mount the root partition of a virtual machine.
+// The guest filesystem is untrusted, so nothing on it may be executed or used as a device on the host.
+func instanceConvertMountOptions(readOnly bool) string {
+	options := []string{"nosuid", "nodev", "noexec"}
+	if readOnly {
+		options = append([]string{"ro"}, options...)
+	}
+
+	return strings.Join(options, ",")
+}
+
+// instanceConvertMountArgs returns the arguments of the mount command for the root partition of a virtual machine.
+// The type of its filesystem is probed from userspace beforehand, and only the few filesystems a root partition is
+// expected to use are mounted so that the untrusted guest disk isn't exposed to every filesystem driver of the host.
+func instanceConvertMountArgs(fsType string, partition string, mountPath string, readOnly bool) ([]string, error) {
+	if !slices.Contains(instanceConvertFilesystems, fsType) {
+		return nil, fmt.Errorf("Unsupported filesystem %q on partition %q", fsType, partition)
+	}
+
+	return []string{"-t", fsType, "-o", instanceConvertMountOptions(readOnly), partition, mountPath}, nil
+}
+
+// instanceConvertPartitionNumber returns the number of a partition of a loop device from its name.
+func instanceConvertPartitionNumber(loopDev string, partition string) (int, error) {
+	prefix := filepath.Base(loopDev) + "p"
+
+	name := filepath.Base(partition)
+	if !strings.HasPrefix(name, prefix) {
+		return -1, fmt.Errorf("Partition %q doesn't belong to %q", partition, loopDev)
+	}
+
+	number, err := strconv.Atoi(strings.TrimPrefix(name, prefix))
+	if err != nil || number < 1 {
+		return -1, fmt.Errorf("Invalid partition %q", partition)
+	}
+
+	return number, nil
+}
+
+// instanceConvertGrowArgs returns the command growing a mounted filesystem to the size of its partition.
+func instanceConvertGrowArgs(fsType string, partition string, mountPath string) ([]string, error) {
+	switch fsType {
+	case "ext2", "ext3", "ext4":
+		return []string{"resize2fs", partition}, nil
+	case "xfs":
+		return []string{"xfs_growfs", mountPath}, nil
+	case "btrfs":
+		return []string{"btrfs", "filesystem", "resize", "max", mountPath}, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported filesystem %q on partition %q", fsType, partition)
+}
+
+// instanceConvertGrowPartition grows a partition of a loop device and its mounted filesystem to use all the space
+// left after it, as the disk of the converted instance can be larger than the one of its image.
+func instanceConvertGrowPartition(loopDev string, partition string, fsType string, mountPath string) error {
+	growArgs, err := instanceConvertGrowArgs(fsType, partition, mountPath)
+	if err != nil {
+		return err
+	}
+
+	number, err := instanceConvertPartitionNumber(loopDev, partition)
+	if err != nil {
+		return err
+	}
+
+	// Writing the partition table also moves the GPT backup header to the end of the disk.
+	err = subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(", +\n"), nil, "sfdisk", "--no-reread", "--no-tell-kernel", "-N", strconv.Itoa(number), loopDev)
+	if err != nil {
+		return fmt.Errorf("Failed growing partition %q: %w", partition, err)
+	}
+
+	_, err = subprocess.RunCommand("partx", "--update", "--nr", strconv.Itoa(number), loopDev)
+	if err != nil {
+		return fmt.Errorf("Failed updating partition %q: %w", partition, err)
+	}
+
+	_, err = subprocess.RunCommand(growArgs[0], growArgs[1:]...)
+	if err != nil {
+		return fmt.Errorf("Failed growing filesystem of partition %q: %w", partition, err)
+	}
+
+	return nil
+}
+
+// instanceConvertMount mounts the root filesystem of an instance or snapshot and returns its path along with the
+// function to unmount it. For virtual machines, the root partition of the disk is mounted.
+func instanceConvertMount(s *state.State, inst instance.Instance, readOnly bool, op *operations.Operation) (string, func(), error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed loading instance storage pool: %w", err)
+	}
+
+	var mountInfo *storagePools.MountInfo
+	if inst.IsSnapshot() {
+		mountInfo, err = pool.MountInstanceSnapshot(inst, op)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed mounting snapshot %q: %w", inst.Name(), err)
+		}
+
+		revert.Add(func() { _ = pool.UnmountInstanceSnapshot(inst, op) })
+	} else {
+		mountInfo, err = pool.MountInstance(inst, op)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed mounting instance %q: %w", inst.Name(), err)
+		}
+
+		revert.Add(func() { _ = pool.UnmountInstance(inst, op) })
+	}
+
+	if inst.Type() == instancetype.Container {
+		cleanup := revert.Clone().Fail
+		revert.Success()
+
+		return inst.RootfsPath(), cleanup, nil
+	}
+
+	// Expose the partitions of the disk.
+	losetupArgs := []string{"--find", "--show", "--partscan"}
+	if readOnly {
+		losetupArgs = append(losetupArgs, "--read-only")
+	}
+
+	out, err := subprocess.RunCommand("losetup", append(losetupArgs, mountInfo.DiskPath)...)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed setting up loop device for %q: %w", inst.Name(), err)
+	}
+
+	loopDev := strings.TrimSpace(out)
+	revert.Add(func() { _, _ = subprocess.RunCommand("losetup", "--detach", loopDev) })
+
+	mountPath, err := os.MkdirTemp("", "incus_convert_")
+	if err != nil {
+		return "", nil, err
+	}
+
+	revert.Add(func() { _ = os.Remove(mountPath) })
+
+	// Look for the partition holding the root filesystem.
+	partitions, err := filepath.Glob(filepath.Join("/sys/class/block", filepath.Base(loopDev), filepath.Base(loopDev)+"p*"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, partition := range partitions {
+		partitionDev := filepath.Join("/dev", filepath.Base(partition))
+
+		fsType, err := subprocess.RunCommand("blkid", "-s", "TYPE", "-o", "value", partitionDev)
+		if err != nil {
+			continue
+		}
+
+		fsType = strings.TrimSpace(fsType)
+
+		mountArgs, err := instanceConvertMountArgs(fsType, partitionDev, mountPath, readOnly)
+		if err != nil {
+			continue
+		}
+
+		_, err = subprocess.RunCommand("mount", mountArgs...)
+		if err != nil {
+			continue
+		}
+
+		if util.PathExists(filepath.Join(mountPath, "etc")) {
+			revert.Add(func() { _, _ = subprocess.RunCommand("umount", mountPath) })
+
+			// Make the whole disk available to the files being copied in.
+			if !readOnly {
+				err = instanceConvertGrowPartition(loopDev, partitionDev, fsType, mountPath)
+				if err != nil {
+					return "", nil, err
+				}
+			}
+
+			cleanup := revert.Clone().Fail
+			revert.Success()
+
+			return mountPath, cleanup, nil
+		}
+
+		_, _ = subprocess.RunCommand("umount", mountPath)
+	}
+
+	return "", nil, fmt.Errorf("Couldn't find the root partition of %q", inst.Name())
+}
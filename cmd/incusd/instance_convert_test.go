@@ -0,0 +1,138 @@
+package main
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+)
+
+func TestInstanceConvertLocalConfig(t *testing.T) {
+	config := map[string]string{
+		"limits.cpu":                "2",
+		"security.nesting":          "true",
+		"security.secureboot":       "false",
+		"image.os":                  "Debian",
+		"volatile.uuid":             "a8f8e4f0-1f5b-4c7b-9d5e-6c7b1b2f3a4d",
+		"volatile.eth0.hwaddr":      "10:66:6a:00:00:01",
+		"volatile.last_state.power": "STOPPED",
+		"volatile.base_image":       "abcdef",
+		"user.environment":          "production",
+		"raw.lxc":                   "lxc.apparmor.profile=unconfined",
+		"raw.qemu":                  "-serial none",
+	}
+
+	tests := []struct {
+		name       string
+		targetType instancetype.Type
+		kept       []string
+		dropped    []string
+	}{
+		{
+			"To virtual machine",
+			instancetype.VM,
+			[]string{"limits.cpu", "security.secureboot", "volatile.uuid", "volatile.eth0.hwaddr", "user.environment", "raw.qemu"},
+			[]string{"security.nesting", "image.os", "volatile.last_state.power", "volatile.base_image", "raw.lxc"},
+		},
+		{
+			"To container",
+			instancetype.Container,
+			[]string{"limits.cpu", "security.nesting", "volatile.uuid", "volatile.eth0.hwaddr", "user.environment", "raw.lxc"},
+			[]string{"security.secureboot", "image.os", "volatile.last_state.power", "volatile.base_image", "raw.qemu"},
+		},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		result := instanceConvertLocalConfig(config, tt.targetType)
+
+		for _, key := range tt.kept {
+			require.Equal(t, config[key], result[key], key)
+		}
+
+		for _, key := range tt.dropped {
+			require.NotContains(t, result, key)
+		}
+	}
+}
+
+func TestInstanceConvertMountOptions(t *testing.T) {
+	require.Equal(t, "ro,nosuid,nodev,noexec", instanceConvertMountOptions(true))
+	require.Equal(t, "nosuid,nodev,noexec", instanceConvertMountOptions(false))
+}
+
+func TestInstanceConvertMountArgs(t *testing.T) {
+	args, err := instanceConvertMountArgs("ext4", "/dev/loop0p1", "/tmp/root", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"-t", "ext4", "-o", "ro,nosuid,nodev,noexec", "/dev/loop0p1", "/tmp/root"}, args)
+
+	args, err = instanceConvertMountArgs("xfs", "/dev/loop0p2", "/tmp/root", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"-t", "xfs", "-o", "nosuid,nodev,noexec", "/dev/loop0p2", "/tmp/root"}, args)
+
+	// Other filesystems, or partitions without a recognized one, aren't mounted.
+	for _, fsType := range []string{"", "vfat", "squashfs", "ntfs"} {
+		_, err = instanceConvertMountArgs(fsType, "/dev/loop0p1", "/tmp/root", true)
+		require.Error(t, err)
+	}
+}
+
+func TestInstanceConvertGrowArgs(t *testing.T) {
+	args, err := instanceConvertGrowArgs("ext3", "/dev/loop0p1", "/tmp/root")
+	require.NoError(t, err)
+	require.Equal(t, []string{"resize2fs", "/dev/loop0p1"}, args)
+
+	args, err = instanceConvertGrowArgs("btrfs", "/dev/loop0p2", "/tmp/root")
+	require.NoError(t, err)
+	require.Equal(t, []string{"btrfs", "filesystem", "resize", "max", "/tmp/root"}, args)
+
+	// Every filesystem that can be mounted can also be grown.
+	for _, fsType := range instanceConvertFilesystems {
+		_, err = instanceConvertMountArgs(fsType, "/dev/loop0p1", "/tmp/root", false)
+		require.NoError(t, err)
+
+		_, err = instanceConvertGrowArgs(fsType, "/dev/loop0p1", "/tmp/root")
+		require.NoError(t, err)
+	}
+
+	_, err = instanceConvertGrowArgs("vfat", "/dev/loop0p1", "/tmp/root")
+	require.Error(t, err)
+}
+
+func TestInstanceConvertPartitionNumber(t *testing.T) {
+	tests := []struct {
+		name       string
+		loopDev    string
+		partition  string
+		expected   int
+		shouldFail bool
+	}{
+		{"First partition", "/dev/loop3", "/dev/loop3p1", 1, false},
+		{"Sysfs path", "/dev/loop3", "/sys/class/block/loop3/loop3p2", 2, false},
+		{"Double digit", "/dev/loop12", "/dev/loop12p14", 14, false},
+		{"Other device", "/dev/loop1", "/dev/loop12p1", -1, true},
+		{"Whole device", "/dev/loop3", "/dev/loop3", -1, true},
+		{"Partition zero", "/dev/loop3", "/dev/loop3p0", -1, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		number, err := instanceConvertPartitionNumber(tt.loopDev, tt.partition)
+		if tt.shouldFail {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, number)
+	}
+}
+
+func TestInstanceConvertRsyncArgs(t *testing.T) {
+	args := instanceConvertRsyncArgs()
+	require.Len(t, args, len(instanceConvertExcludes))
+	require.Contains(t, args, "--exclude=/lib/modules")
+	require.Contains(t, args, "--exclude=/proc")
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/coredump"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+var instanceCoreDumpCmd = APIEndpoint{
+	Name: "instanceCoreDump",
+	Path: "instances/{name}/coredumps/{file}",
+
+	Delete: APIEndpointAction{Handler: instanceCoreDumpDelete, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "name")},
+	Get:    APIEndpointAction{Handler: instanceCoreDumpGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanExec, "name")},
+}
+
+var instanceCoreDumpsCmd = APIEndpoint{
+	Name: "instanceCoreDumps",
+	Path: "instances/{name}/coredumps",
+
+	Get: APIEndpointAction{Handler: instanceCoreDumpsGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanExec, "name")},
+}
+
+// instanceCoreDumpsLoad loads the instance targeted by a core dump request.
+// A response is returned instead when the request must be forwarded to another cluster member or fails.
+func instanceCoreDumpsLoad(d *Daemon, r *http.Request) (instance.Instance, response.Response) {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return nil, response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different node
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	if resp != nil {
+		return nil, resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	return inst, nil
+}
+
+// swagger:operation GET /1.0/instances/{name}/coredumps instances instance_coredumps_get
+//
+//	Get the core dumps
+//
+//	Returns a list of core dumps (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/instances/foo/coredumps/20240502T101512Z_nginx_1234.core"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instances/{name}/coredumps?recursion=1 instances instance_coredumps_get_recursion1
+//
+//	Get the core dumps
+//
+//	Returns a list of core dumps (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of core dumps
+//	          items:
+//	            $ref: "#/definitions/InstanceCoreDump"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceCoreDumpsGet(d *Daemon, r *http.Request) response.Response {
+	inst, resp := instanceCoreDumpsLoad(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	dumps, err := coredump.List(inst.Project().Name, inst.Name())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if localUtil.IsRecursionRequest(r) {
+		return response.SyncResponse(true, dumps)
+	}
+
+	result := make([]string, 0, len(dumps))
+	for _, dump := range dumps {
+		result = append(result, api.NewURL().Path(version.APIVersion, "instances", inst.Name(), "coredumps", dump.Name).String())
+	}
+
+	return response.SyncResponse(true, result)
+}
+
+// swagger:operation GET /1.0/instances/{name}/coredumps/{filename} instances instance_coredump_get
+//
+//	Get the core dump
+//
+//	Gets the core dump file.
+//
+//	---
+//	produces:
+//	  - application/json
+//	  - application/octet-stream
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	     description: Raw file
+//	     content:
+//	       application/octet-stream:
+//	         schema:
+//	           type: string
+//	           example: raw data
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceCoreDumpGet(d *Daemon, r *http.Request) response.Response {
+	inst, resp := instanceCoreDumpsLoad(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	file, err := url.PathUnescape(mux.Vars(r)["file"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	path, err := coredump.FilePath(inst.Project().Name, inst.Name(), file)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !util.PathExists(path) {
+		return response.NotFound(fmt.Errorf("Core dump %q not found", file))
+	}
+
+	ent := response.FileResponseEntry{
+		Path:     path,
+		Filename: file,
+	}
+
+	d.State().Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceCoreDumpRetrieved.Event(file, inst, request.CreateRequestor(r), nil))
+
+	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+}
+
+// swagger:operation DELETE /1.0/instances/{name}/coredumps/{filename} instances instance_coredump_delete
+//
+//	Delete the core dump
+//
+//	Removes the core dump file.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceCoreDumpDelete(d *Daemon, r *http.Request) response.Response {
+	inst, resp := instanceCoreDumpsLoad(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	file, err := url.PathUnescape(mux.Vars(r)["file"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = coredump.Delete(inst.Project().Name, inst.Name(), file)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	d.State().Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceCoreDumpDeleted.Event(file, inst, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
 )
@@ -24,6 +25,7 @@ import (
 //	Deletes a specific instance.
 //
 //	This also deletes anything owned by the instance such as snapshots and backups.
+//	If the recycle bin is enabled, the instance is kept in it until it expires.
 //
 //	---
 //	produces:
@@ -84,7 +86,7 @@ func instanceDelete(d *Daemon, r *http.Request) response.Response {
 	}
 
 	rmct := func(op *operations.Operation) error {
-		return inst.Delete(false)
+		return doInstanceDelete(s, inst, op)
 	}
 
 	resources := map[string][]api.URL{}
@@ -97,3 +99,22 @@ func instanceDelete(d *Daemon, r *http.Request) response.Response {
 
 	return operations.OperationResponse(op)
 }
+
+// doInstanceDelete deletes a stopped instance, moving it into the recycle bin if enabled.
+func doInstanceDelete(s *state.State, inst instance.Instance, op *operations.Operation) error {
+	recycleBinRevert, err := recycleBinAddInstance(s, inst, op)
+	if err != nil {
+		return err
+	}
+
+	err = inst.Delete(false)
+	if err != nil {
+		if recycleBinRevert != nil {
+			recycleBinRevert()
+		}
+
+		return err
+	}
+
+	return nil
+}
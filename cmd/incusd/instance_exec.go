@@ -77,6 +77,18 @@ func (s *execWs) Metadata() any {
 	}
 }
 
+// Disconnect closes the websockets of the session, which kills the command.
+func (s *execWs) Disconnect() {
+	s.connsLock.Lock()
+	defer s.connsLock.Unlock()
+
+	for _, conn := range s.conns {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}
+}
+
 func (s *execWs) Connect(op *operations.Operation, r *http.Request, w http.ResponseWriter) error {
 	secret := r.FormValue("secret")
 	if secret == "" {
@@ -507,6 +519,9 @@ func (s *execWs) Do(op *operations.Operation) error {
 //	An additional "control" socket is always added on top which can be used for out of band communications.
 //	This allows sending signals and window sizing information through.
 //
+//	For virtual machines, a structured request instead runs the command through the agent and
+//	directly returns its exit code, captured output and resource usage, without any websocket.
+//
 //	---
 //	consumes:
 //	  - application/json
@@ -524,6 +539,26 @@ func (s *execWs) Do(op *operations.Operation) error {
 //	    schema:
 //	      $ref: "#/definitions/InstanceExecPost"
 //	responses:
+//	  "200":
+//	    description: Structured exec result
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceExecResult"
 //	  "202":
 //	    $ref: "#/responses/Operation"
 //	  "400":
@@ -570,6 +605,19 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Cannot use %q in combination with %q", "interactive", "record-output"))
 	}
 
+	if post.Structured && post.WaitForWS {
+		return response.BadRequest(fmt.Errorf("Cannot use %q in combination with %q", "structured", "wait-for-websocket"))
+	}
+
+	if post.Structured && (post.Interactive || post.RecordOutput) {
+		return response.BadRequest(fmt.Errorf("Cannot use %q in combination with %q or %q", "structured", "interactive", "record-output"))
+	}
+
+	// Sessions of instance shares must be tied to websockets, to be disconnected when the share goes away.
+	if !post.WaitForWS && request.CreateRequestor(r).Protocol == instanceShareProtocol {
+		return response.BadRequest(fmt.Errorf("Instance shares require %q", "wait-for-websocket"))
+	}
+
 	// Forward the request if the container is remote.
 	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, name, r, instanceType)
 	if err != nil {
@@ -583,6 +631,16 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
+		if post.Structured {
+			result := api.InstanceExecResult{}
+			err = resp.MetadataAsStruct(&result)
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			return response.SyncResponse(true, result)
+		}
+
 		opAPI, err := resp.MetadataAsOperation()
 		if err != nil {
 			return response.SmartError(err)
@@ -596,6 +654,12 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Resume instances suspended by their idle policy.
+	err = instanceIdleResume(inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	if !inst.IsRunning() {
 		return response.BadRequest(fmt.Errorf("Instance is not running"))
 	}
@@ -665,6 +729,20 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		post.Environment["LANG"] = "C.UTF-8"
 	}
 
+	if post.Structured {
+		vm, ok := inst.(instance.VM)
+		if !ok {
+			return response.BadRequest(fmt.Errorf("Structured exec is only supported for virtual machines"))
+		}
+
+		result, err := vm.ExecStructured(post)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.SyncResponse(true, result)
+	}
+
 	if post.WaitForWS {
 		ws := &execWs{}
 		ws.s = d.State()
@@ -699,6 +777,8 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 			return response.InternalError(err)
 		}
 
+		instanceShareSessionTrack(r, op, projectName, inst.Name(), ws.Disconnect)
+
 		return operations.OperationResponse(op)
 	}
 
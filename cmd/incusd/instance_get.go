@@ -139,6 +139,10 @@ func instanceGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if request.CreateRequestor(r).Protocol == instanceShareProtocol {
+		return instanceShareRender(c)
+	}
+
 	var state any
 	var etag any
 	if recursion == 0 {
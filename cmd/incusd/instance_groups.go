@@ -0,0 +1,637 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+var instanceGroupsCmd = APIEndpoint{
+	Path: "instance-groups",
+
+	Get:  APIEndpointAction{Handler: instanceGroupsGet, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+	Post: APIEndpointAction{Handler: instanceGroupsPost, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+}
+
+var instanceGroupCmd = APIEndpoint{
+	Path: "instance-groups/{name}",
+
+	Delete: APIEndpointAction{Handler: instanceGroupDelete, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+	Get:    APIEndpointAction{Handler: instanceGroupGet, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+	Put:    APIEndpointAction{Handler: instanceGroupPut, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+	Patch:  APIEndpointAction{Handler: instanceGroupPut, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+}
+
+// instanceGroupPolicies are the supported placement policies of instance groups.
+var instanceGroupPolicies = []string{"affinity", "anti-affinity"}
+
+// instanceGroupValidate validates the fields of an instance group.
+func instanceGroupValidate(info api.InstanceGroupPut) error {
+	if !slices.Contains(instanceGroupPolicies, info.Policy) {
+		return fmt.Errorf("Invalid policy %q, must be one of %v", info.Policy, instanceGroupPolicies)
+	}
+
+	return nil
+}
+
+// instanceGroupLoad loads an instance group along with the URLs of its members.
+func instanceGroupLoad(ctx context.Context, tx *db.ClusterTx, projectName string, name string) (int64, *api.InstanceGroup, error) {
+	id, group, err := tx.GetInstanceGroup(ctx, projectName, name)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	members, err := tx.GetInstanceGroupMembers(ctx, projectName, name)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	group.UsedBy = make([]string, 0, len(members))
+	for instName := range members {
+		group.UsedBy = append(group.UsedBy, api.NewURL().Path(version.APIVersion, "instances", instName).Project(projectName).String())
+	}
+
+	sort.Strings(group.UsedBy)
+
+	return id, group, nil
+}
+
+// instanceGroupAllowedMembers returns the candidate cluster members allowed by the policy of an instance group,
+// given the cluster members which the other instances of the group are located on.
+func instanceGroupAllowedMembers(policy string, used map[string]bool, candidates []db.NodeInfo) []db.NodeInfo {
+	// Nothing to place the instance with yet.
+	if policy == "affinity" && len(used) == 0 {
+		return candidates
+	}
+
+	allowed := make([]db.NodeInfo, 0, len(candidates))
+	for _, candidate := range candidates {
+		if used[candidate.Name] == (policy == "affinity") {
+			allowed = append(allowed, candidate)
+		}
+	}
+
+	return allowed
+}
+
+// instanceGroupCheckPolicy checks that the current location of the members of an instance group, mapped to the
+// cluster member they're on, satisfies the given policy.
+func instanceGroupCheckPolicy(policy string, members map[string]string) error {
+	instances := map[string]string{}
+	for instName, location := range members {
+		other, ok := instances[location]
+		if ok && policy == "anti-affinity" {
+			return fmt.Errorf("Instances %q and %q are both located on cluster member %q", min(instName, other), max(instName, other), location)
+		}
+
+		instances[location] = instName
+	}
+
+	if len(instances) > 1 && policy == "affinity" {
+		locations := make([]string, 0, len(instances))
+		for location := range instances {
+			locations = append(locations, location)
+		}
+
+		sort.Strings(locations)
+
+		return fmt.Errorf("Instances are located on several cluster members (%s)", strings.Join(locations, ", "))
+	}
+
+	return nil
+}
+
+// instanceGroupFilterMembers restricts the candidate cluster members of an instance to those allowed by the
+// policy of the instance group set in its expanded placement.group configuration.
+// The instances of the group located on sourceMember are disregarded, as they are being moved away from it.
+func instanceGroupFilterMembers(ctx context.Context, tx *db.ClusterTx, projectName string, instanceName string, config map[string]string, candidates []db.NodeInfo, sourceMember string) ([]db.NodeInfo, error) {
+	groupName := config["placement.group"]
+	if groupName == "" {
+		return candidates, nil
+	}
+
+	_, group, err := tx.GetInstanceGroup(ctx, projectName, groupName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance group %q: %w", groupName, err)
+	}
+
+	members, err := tx.GetInstanceGroupMembers(ctx, projectName, groupName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading members of instance group %q: %w", groupName, err)
+	}
+
+	// The instance itself doesn't constrain its own placement.
+	delete(members, instanceName)
+
+	used := map[string]bool{}
+	for _, member := range members {
+		if member != sourceMember {
+			used[member] = true
+		}
+	}
+
+	allowed := instanceGroupAllowedMembers(group.Policy, used, candidates)
+	if len(allowed) == 0 {
+		if len(candidates) == 1 {
+			return nil, api.StatusErrorf(http.StatusConflict, "Cluster member %q doesn't satisfy the %s policy of instance group %q", candidates[0].Name, group.Policy, groupName)
+		}
+
+		return nil, api.StatusErrorf(http.StatusConflict, "No cluster member satisfies the %s policy of instance group %q", group.Policy, groupName)
+	}
+
+	return allowed, nil
+}
+
+// instanceGroupCheckLocation checks that the current cluster member of an instance satisfies the policy of the
+// instance group set in its new expanded configuration, when the group changes.
+func instanceGroupCheckLocation(ctx context.Context, tx *db.ClusterTx, inst instance.Instance, config map[string]string) error {
+	if config["placement.group"] == "" || config["placement.group"] == inst.ExpandedConfig()["placement.group"] {
+		return nil
+	}
+
+	member, err := tx.GetNodeByName(ctx, inst.Location())
+	if err != nil {
+		return fmt.Errorf("Failed getting current cluster member of instance %q: %w", inst.Name(), err)
+	}
+
+	_, err = instanceGroupFilterMembers(ctx, tx, inst.Project().Name, inst.Name(), config, []db.NodeInfo{member}, "")
+
+	return err
+}
+
+// swagger:operation GET /1.0/instance-groups instance-groups instance_groups_get
+//
+//  Get the instance groups
+//
+//  Returns a list of instance groups (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/instance-groups/web",
+//                "/1.0/instance-groups/db"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instance-groups?recursion=1 instance-groups instance_groups_get_recursion1
+//
+//	Get the instance groups
+//
+//	Returns a list of instance groups (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of instance groups
+//	          items:
+//	            $ref: "#/definitions/InstanceGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := localUtil.IsRecursionRequest(r)
+
+	resultString := []string{}
+	resultMap := []api.InstanceGroup{}
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		names, err := tx.GetInstanceGroupNames(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if !recursion {
+				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "instance-groups", name).String())
+				continue
+			}
+
+			_, group, err := instanceGroupLoad(ctx, tx, projectName, name)
+			if err != nil {
+				return err
+			}
+
+			resultMap = append(resultMap, *group)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/instance-groups instance-groups instance_groups_post
+//
+//	Add an instance group
+//
+//	Creates a new instance group.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Instance group
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceGroupsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.InstanceGroupsPost{}
+
+	// Parse the request.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = validate.IsHostname(req.Name)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid instance group name %q: %w", req.Name, err))
+	}
+
+	err = instanceGroupValidate(req.InstanceGroupPut)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, _, err := tx.GetInstanceGroup(ctx, projectName, req.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "The instance group already exists")
+		} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		_, err = tx.CreateInstanceGroup(ctx, projectName, req)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lc := lifecycle.InstanceGroupCreated.Event(req.Name, projectName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/instance-groups/{name} instance-groups instance_group_delete
+//
+//	Delete the instance group
+//
+//	Removes the instance group, which must not have any members.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, group, err := instanceGroupLoad(ctx, tx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		if len(group.UsedBy) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "The instance group is currently in use")
+		}
+
+		return tx.DeleteInstanceGroup(ctx, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceGroupDeleted.Event(name, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/instance-groups/{name} instance-groups instance_group_get
+//
+//	Get the instance group
+//
+//	Gets a specific instance group.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Instance group
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var group *api.InstanceGroup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, group, err = instanceGroupLoad(ctx, tx, projectName, name)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, group, group.Writable())
+}
+
+// swagger:operation PATCH /1.0/instance-groups/{name} instance-groups instance_group_patch
+//
+//  Partially update the instance group
+//
+//  Updates a subset of the instance group fields.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: body
+//      name: group
+//      description: Instance group
+//      required: true
+//      schema:
+//        $ref: "#/definitions/InstanceGroupPut"
+//  responses:
+//    "200":
+//      $ref: "#/responses/EmptySyncResponse"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/instance-groups/{name} instance-groups instance_group_put
+//
+//	Update the instance group
+//
+//	Updates the entire instance group.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Instance group
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceGroupPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, group, err := tx.GetInstanceGroup(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		// Validate the ETag.
+		err = localUtil.EtagCheck(r, group.Writable())
+		if err != nil {
+			return err
+		}
+
+		// With PATCH, the fields missing from the request keep their current value.
+		req := api.InstanceGroupPut{}
+		if r.Method == http.MethodPatch {
+			req = group.Writable()
+		}
+
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "%v", err)
+		}
+
+		err = instanceGroupValidate(req)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "%v", err)
+		}
+
+		// Check that the current members of the group satisfy the new policy.
+		if s.ServerClustered && req.Policy != group.Policy {
+			members, err := tx.GetInstanceGroupMembers(ctx, projectName, name)
+			if err != nil {
+				return err
+			}
+
+			err = instanceGroupCheckPolicy(req.Policy, members)
+			if err != nil {
+				return api.StatusErrorf(http.StatusBadRequest, "The instance group members don't satisfy the %s policy: %v", req.Policy, err)
+			}
+		}
+
+		return tx.UpdateInstanceGroup(ctx, id, req)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceGroupUpdated.Event(name, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
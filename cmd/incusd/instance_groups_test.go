@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+)
+
+func TestInstanceGroupAllowedMembers(t *testing.T) {
+	candidates := []db.NodeInfo{{Name: "server01"}, {Name: "server02"}, {Name: "server03"}}
+
+	tests := []struct {
+		name    string
+		policy  string
+		used    map[string]bool
+		allowed []string
+	}{
+		{"Affinity without members", "affinity", map[string]bool{}, []string{"server01", "server02", "server03"}},
+		{"Affinity with members", "affinity", map[string]bool{"server02": true}, []string{"server02"}},
+		{"Affinity with members elsewhere", "affinity", map[string]bool{"server04": true}, []string{}},
+		{"Anti-affinity without members", "anti-affinity", map[string]bool{}, []string{"server01", "server02", "server03"}},
+		{"Anti-affinity with members", "anti-affinity", map[string]bool{"server01": true, "server03": true}, []string{"server02"}},
+		{"Anti-affinity with all members used", "anti-affinity", map[string]bool{"server01": true, "server02": true, "server03": true}, []string{}},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		names := []string{}
+		for _, member := range instanceGroupAllowedMembers(tt.policy, tt.used, candidates) {
+			names = append(names, member.Name)
+		}
+
+		require.Equal(t, tt.allowed, names)
+	}
+}
+
+func TestInstanceGroupCheckPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		members map[string]string
+		fails   bool
+	}{
+		{"Affinity on one member", "affinity", map[string]string{"c1": "server01", "c2": "server01"}, false},
+		{"Affinity on several members", "affinity", map[string]string{"c1": "server01", "c2": "server02"}, true},
+		{"Anti-affinity on distinct members", "anti-affinity", map[string]string{"c1": "server01", "c2": "server02"}, false},
+		{"Anti-affinity on a shared member", "anti-affinity", map[string]string{"c1": "server01", "c2": "server01"}, true},
+		{"No members", "anti-affinity", map[string]string{}, false},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		err := instanceGroupCheckPolicy(tt.policy, tt.members)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// Health status of an instance as recorded in volatile.health.
+const (
+	instanceHealthStarting  = "starting"
+	instanceHealthHealthy   = "healthy"
+	instanceHealthUnhealthy = "unhealthy"
+)
+
+// instanceHealthMaxRestartDelay is the longest delay before restarting an unhealthy instance.
+const instanceHealthMaxRestartDelay = 5 * time.Minute
+
+// instanceHealthState tracks the health checks of an instance.
+type instanceHealthState struct {
+	lastCheck time.Time
+	failures  int
+	restarts  int
+	restartAt time.Time
+
+	// Whether a health check or restart of the instance is in progress.
+	busy bool
+}
+
+// instanceHealthUpdate is the outcome of a health check.
+type instanceHealthUpdate struct {
+	// Health status to record, empty to keep the current one.
+	status string
+
+	// Delay before restarting the instance, zero if no restart is scheduled.
+	restartDelay time.Duration
+
+	// Whether the instance keeps being unhealthy after its allowed restarts.
+	gaveUp bool
+}
+
+// instanceHealthStates holds the health state of the local instances, keyed by project and name.
+var instanceHealthStates = map[string]*instanceHealthState{}
+var instanceHealthStatesMu sync.Mutex
+
+// instanceHealthHTTPClient is the client of the HTTP health checks. It reports redirects rather than following
+// them, so that a check never leaves the instance.
+var instanceHealthHTTPClient = &http.Client{
+	Transport: &http.Transport{DisableKeepAlives: true},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// instanceHealthTask periodically runs the health checks of the local instances.
+func instanceHealthTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := instanceHealthCheck(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed checking instance health", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(5 * time.Second)
+}
+
+// instanceHealthCheck starts the due health checks of the running local instances, which record their health
+// and restart the unhealthy ones following their health.check.restart policy. The checks run in the background,
+// an instance not being checked again until its current health check or restart is over.
+func instanceHealthCheck(ctx context.Context, s *state.State) error {
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(instances))
+
+	for _, inst := range instances {
+		if ctx.Err() != nil {
+			break
+		}
+
+		config := inst.ExpandedConfig()
+		if config["health.check.type"] == "" || !inst.IsRunning() {
+			// Clear the health of instances which aren't checked anymore.
+			if inst.LocalConfig()["volatile.health"] != "" {
+				err := inst.VolatileSet(map[string]string{"volatile.health": ""})
+				if err != nil {
+					logger.Warn("Failed clearing instance health", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+				}
+			}
+
+			continue
+		}
+
+		key := project.Instance(inst.Project().Name, inst.Name())
+		seen[key] = struct{}{}
+
+		instanceHealthStatesMu.Lock()
+		healthState, ok := instanceHealthStates[key]
+		if !ok {
+			healthState = &instanceHealthState{}
+			instanceHealthStates[key] = healthState
+		}
+
+		due, restart := healthState.due(time.Now(), instanceHealthSeconds(config["health.check.interval"], 30))
+		instanceHealthStatesMu.Unlock()
+
+		if !ok && inst.LocalConfig()["volatile.health"] == "" {
+			instanceHealthSet(s, inst, instanceHealthStarting, 0)
+		}
+
+		if !due {
+			continue
+		}
+
+		go func(inst instance.Instance, healthState *instanceHealthState) {
+			defer func() {
+				instanceHealthStatesMu.Lock()
+				healthState.busy = false
+				instanceHealthStatesMu.Unlock()
+			}()
+
+			if restart {
+				instanceHealthRestart(s, inst)
+				return
+			}
+
+			err := instanceHealthProbe(ctx, inst)
+			instanceHealthRecord(s, inst, healthState, err)
+		}(inst, healthState)
+	}
+
+	// Forget about instances which are gone or aren't running anymore.
+	instanceHealthStatesMu.Lock()
+	defer instanceHealthStatesMu.Unlock()
+
+	for key := range instanceHealthStates {
+		_, ok := seen[key]
+		if !ok {
+			delete(instanceHealthStates, key)
+		}
+	}
+
+	return nil
+}
+
+// due returns whether the instance is due for a health check, or for a restart once its backoff expired, and marks
+// it busy if so. The caller must hold instanceHealthStatesMu.
+func (h *instanceHealthState) due(now time.Time, interval time.Duration) (bool, bool) {
+	if h.busy {
+		return false, false
+	}
+
+	if !h.restartAt.IsZero() {
+		if now.Before(h.restartAt) {
+			return false, false
+		}
+
+		h.restartAt = time.Time{}
+		h.failures = 0
+		h.lastCheck = now
+		h.busy = true
+
+		return true, true
+	}
+
+	if now.Sub(h.lastCheck) < interval {
+		return false, false
+	}
+
+	h.lastCheck = now
+	h.busy = true
+
+	return true, false
+}
+
+// record updates the health state from the result of a health check, scheduling a restart of the instance when
+// it becomes unhealthy and restartEnabled is set, at most maxRetries times in a row (unlimited if 0).
+// The caller must hold instanceHealthStatesMu.
+func (h *instanceHealthState) record(probeErr error, threshold int, restartEnabled bool, maxRetries int, now time.Time) instanceHealthUpdate {
+	if probeErr == nil {
+		h.failures = 0
+		h.restarts = 0
+
+		return instanceHealthUpdate{status: instanceHealthHealthy}
+	}
+
+	h.failures++
+	if h.failures < threshold {
+		return instanceHealthUpdate{}
+	}
+
+	update := instanceHealthUpdate{status: instanceHealthUnhealthy}
+
+	// Only act when the instance becomes unhealthy.
+	if !restartEnabled || h.failures > threshold {
+		return update
+	}
+
+	if maxRetries > 0 && h.restarts >= maxRetries {
+		update.gaveUp = true
+		return update
+	}
+
+	update.restartDelay = min(time.Second<<min(h.restarts, 10), instanceHealthMaxRestartDelay)
+	h.restarts++
+	h.restartAt = now.Add(update.restartDelay)
+
+	return update
+}
+
+// instanceHealthRecord updates the health of an instance from the result of a health check and
+// schedules its restart when it becomes unhealthy.
+func instanceHealthRecord(s *state.State, inst instance.Instance, healthState *instanceHealthState, probeErr error) {
+	config := inst.ExpandedConfig()
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	threshold := 3
+	if config["health.check.failure_threshold"] != "" {
+		threshold, _ = strconv.Atoi(config["health.check.failure_threshold"])
+	}
+
+	enabled, maxRetries, err := internalInstance.ParseAutoRestart(config["health.check.restart"])
+	if err != nil {
+		enabled = false
+	}
+
+	instanceHealthStatesMu.Lock()
+	update := healthState.record(probeErr, threshold, enabled, maxRetries, time.Now())
+	failures := healthState.failures
+	restarts := healthState.restarts
+	instanceHealthStatesMu.Unlock()
+
+	if probeErr != nil {
+		l.Debug("Instance health check failed", logger.Ctx{"failures": failures, "err": probeErr})
+	}
+
+	if update.status != "" {
+		instanceHealthSet(s, inst, update.status, failures)
+	}
+
+	if update.gaveUp {
+		l.Warn("Instance is unhealthy, giving up on restarting it", logger.Ctx{"attempts": restarts})
+	} else if update.restartDelay > 0 {
+		l.Warn("Instance is unhealthy, restarting it", logger.Ctx{"attempt": restarts, "delay": update.restartDelay, "err": probeErr})
+	}
+}
+
+// instanceHealthRestart restarts an unhealthy instance.
+func instanceHealthRestart(s *state.State, inst instance.Instance) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+	if err != nil {
+		timeout = 30
+	}
+
+	err = inst.Restart(time.Duration(timeout) * time.Second)
+	if err != nil {
+		l.Warn("Failed restarting unhealthy instance", logger.Ctx{"err": err})
+		return
+	}
+
+	instanceHealthSet(s, inst, instanceHealthStarting, 0)
+}
+
+// instanceHealthSet records the health of an instance, emitting a lifecycle event when it changes.
+func instanceHealthSet(s *state.State, inst instance.Instance, status string, failures int) {
+	previous := inst.LocalConfig()["volatile.health"]
+	if previous == status {
+		return
+	}
+
+	err := inst.VolatileSet(map[string]string{"volatile.health": status})
+	if err != nil {
+		logger.Warn("Failed recording instance health", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+		return
+	}
+
+	ctx := map[string]any{"status": status}
+	if previous != "" {
+		ctx["previous"] = previous
+	}
+
+	if failures > 0 {
+		ctx["failures"] = failures
+	}
+
+	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceHealthChanged.Event(inst, ctx))
+}
+
+// instanceHealthProbe runs the configured health check of an instance.
+func instanceHealthProbe(ctx context.Context, inst instance.Instance) error {
+	config := inst.ExpandedConfig()
+	timeout := instanceHealthSeconds(config["health.check.timeout"], 5)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch config["health.check.type"] {
+	case "command":
+		return instanceHealthProbeCommand(ctx, inst, config["health.check.command"])
+	case "tcp", "http":
+		address, err := instanceHealthAddress(inst, config["health.check.port"])
+		if err != nil {
+			return err
+		}
+
+		if config["health.check.type"] == "tcp" {
+			dialer := net.Dialer{}
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err != nil {
+				return err
+			}
+
+			return conn.Close()
+		}
+
+		return instanceHealthProbeHTTP(ctx, address, config["health.check.path"])
+	}
+
+	return fmt.Errorf("Unsupported health check type %q", config["health.check.type"])
+}
+
+// instanceHealthProbeHTTP checks that a GET request to the given path succeeds, redirects being successes.
+func instanceHealthProbeHTTP(ctx context.Context, address string, path string) error {
+	if path == "" {
+		path = "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("http://%s%s", address, path), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := instanceHealthHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("Unexpected HTTP status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// instanceHealthProbeCommand runs the health check command in the instance and checks its exit status.
+func instanceHealthProbeCommand(ctx context.Context, inst instance.Instance, command string) error {
+	if command == "" {
+		return fmt.Errorf("No health check command configured")
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = devNull.Close() }()
+
+	cmd, err := inst.Exec(api.InstanceExecPost{
+		Command:     []string{"sh", "-c", command},
+		Environment: map[string]string{"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+	}, devNull, devNull, devNull)
+	if err != nil {
+		return fmt.Errorf("Failed running health check command: %w", err)
+	}
+
+	type result struct {
+		status int
+		err    error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		status, err := cmd.Wait()
+		done <- result{status: status, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return res.err
+		}
+
+		if res.status != 0 {
+			return fmt.Errorf("Health check command exited with status %d", res.status)
+		}
+
+		return nil
+	case <-ctx.Done():
+		_ = cmd.Signal(unix.SIGKILL)
+		return fmt.Errorf("Health check command timed out")
+	}
+}
+
+// instanceHealthAddress returns the address to probe for the network health checks of an instance.
+func instanceHealthAddress(inst instance.Instance, port string) (string, error) {
+	if port == "" {
+		return "", fmt.Errorf("No health check port configured")
+	}
+
+	hostInterfaces, _ := net.Interfaces()
+	instState, err := inst.RenderState(hostInterfaces)
+	if err != nil {
+		return "", fmt.Errorf("Failed getting instance state: %w", err)
+	}
+
+	for _, network := range instState.Network {
+		if network.Type == "loopback" {
+			continue
+		}
+
+		for _, addr := range network.Addresses {
+			if addr.Scope == "global" {
+				return net.JoinHostPort(addr.Address, port), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("No global address found for the instance")
+}
+
+// instanceHealthSeconds parses a number of seconds from the configuration, falling back to a default.
+func instanceHealthSeconds(value string, defaultValue int) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		seconds = defaultValue
+	}
+
+	return time.Duration(seconds) * time.Second
+}
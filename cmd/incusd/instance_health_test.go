@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceHealthStateRecord(t *testing.T) {
+	now := time.Now()
+	failure := errors.New("Connection refused")
+
+	tests := []struct {
+		name           string
+		results        []error
+		restartEnabled bool
+		maxRetries     int
+		restarts       int
+		expected       instanceHealthUpdate
+	}{
+		{"Healthy", []error{nil}, true, 0, 0, instanceHealthUpdate{status: instanceHealthHealthy}},
+		{"Failures below the threshold", []error{failure, failure}, true, 0, 0, instanceHealthUpdate{}},
+		{"Recovered", []error{failure, failure, nil}, true, 0, 0, instanceHealthUpdate{status: instanceHealthHealthy}},
+		{"Unhealthy without restart", []error{failure, failure, failure}, false, 0, 0, instanceHealthUpdate{status: instanceHealthUnhealthy}},
+		{"Unhealthy with restart", []error{failure, failure, failure}, true, 0, 0, instanceHealthUpdate{status: instanceHealthUnhealthy, restartDelay: time.Second}},
+		{"Restart backoff", []error{failure, failure, failure}, true, 0, 3, instanceHealthUpdate{status: instanceHealthUnhealthy, restartDelay: 8 * time.Second}},
+		{"Restart backoff limit", []error{failure, failure, failure}, true, 0, 20, instanceHealthUpdate{status: instanceHealthUnhealthy, restartDelay: instanceHealthMaxRestartDelay}},
+		{"Still unhealthy", []error{failure, failure, failure, failure}, true, 0, 0, instanceHealthUpdate{status: instanceHealthUnhealthy}},
+		{"Out of restarts", []error{failure, failure, failure}, true, 2, 2, instanceHealthUpdate{status: instanceHealthUnhealthy, gaveUp: true}},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		healthState := &instanceHealthState{restarts: tt.restarts}
+
+		var update instanceHealthUpdate
+		for _, result := range tt.results {
+			update = healthState.record(result, 3, tt.restartEnabled, tt.maxRetries, now)
+		}
+
+		require.Equal(t, tt.expected, update)
+
+		if update.restartDelay > 0 {
+			require.Equal(t, now.Add(update.restartDelay), healthState.restartAt)
+			require.Equal(t, tt.restarts+1, healthState.restarts)
+		}
+	}
+}
+
+func TestInstanceHealthStateDue(t *testing.T) {
+	now := time.Now()
+	healthState := &instanceHealthState{}
+
+	// A new instance is checked right away, and not again until its check is over.
+	due, restart := healthState.due(now, 30*time.Second)
+	require.True(t, due)
+	require.False(t, restart)
+
+	due, _ = healthState.due(now.Add(time.Minute), 30*time.Second)
+	require.False(t, due)
+
+	// The next check waits for the interval.
+	healthState.busy = false
+	due, _ = healthState.due(now.Add(10*time.Second), 30*time.Second)
+	require.False(t, due)
+
+	due, restart = healthState.due(now.Add(30*time.Second), 30*time.Second)
+	require.True(t, due)
+	require.False(t, restart)
+
+	// A scheduled restart happens once its backoff expired.
+	healthState.busy = false
+	healthState.failures = 3
+	healthState.restartAt = now.Add(time.Minute)
+
+	due, _ = healthState.due(now.Add(50*time.Second), 30*time.Second)
+	require.False(t, due)
+
+	due, restart = healthState.due(now.Add(time.Minute), 30*time.Second)
+	require.True(t, due)
+	require.True(t, restart)
+	require.True(t, healthState.restartAt.IsZero())
+	require.Equal(t, 0, healthState.failures)
+}
+
+func TestInstanceHealthProbeHTTP(t *testing.T) {
+	outside := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Redirect was followed")
+	}))
+	defer outside.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			w.WriteHeader(http.StatusOK)
+		case "/redirect":
+			http.Redirect(w, r, outside.URL, http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	require.NoError(t, instanceHealthProbeHTTP(context.Background(), address, "/healthz"))
+	require.NoError(t, instanceHealthProbeHTTP(context.Background(), address, "/redirect"))
+	require.ErrorContains(t, instanceHealthProbeHTTP(context.Background(), address, ""), "503")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/hugepages"
+	instanceDrivers "github.com/lxc/incus/v6/internal/server/instance/drivers"
+	"github.com/lxc/incus/v6/internal/server/resources"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/units"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// instanceHugepagesFilterMembers returns the candidate members with enough free huge pages to back the memory of a
+// virtual machine with the given expanded configuration, so that placement fails rather than the instance start.
+// Members whose resources can't be retrieved are kept, the instance start checking them again anyway.
+func instanceHugepagesFilterMembers(s *state.State, config map[string]string, candidates []db.NodeInfo) ([]db.NodeInfo, error) {
+	if !util.IsTrue(config["limits.memory.hugepages"]) || len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	memSize := config["limits.memory"]
+	if memSize == "" {
+		memSize = instanceDrivers.QEMUDefaultMemSize
+	}
+
+	// Percentages depend on the total memory of each member and are left to the instance start.
+	memory, err := units.ParseByteSizeString(memSize)
+	if err != nil {
+		return candidates, nil
+	}
+
+	var size int64
+	if config["limits.memory.hugepages.size"] != "" {
+		size, err = units.ParseByteSizeString(config["limits.memory.hugepages.size"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid limits.memory.hugepages.size: %w", err)
+		}
+	}
+
+	dynamic := s.GlobalConfig.InstancesHugepagesDynamic()
+
+	allowed := make([]db.NodeInfo, 0, len(candidates))
+	for _, member := range candidates {
+		res, err := instanceHugepagesMemberResources(s, member)
+		if err != nil {
+			logger.Warn("Failed getting cluster member resources, not checking its huge pages", logger.Ctx{"member": member.Name, "err": err})
+			allowed = append(allowed, member)
+			continue
+		}
+
+		if hugepages.ResourcesFit(&res.Memory, uint64(size), uint64(memory), dynamic) {
+			allowed = append(allowed, member)
+		}
+	}
+
+	if len(allowed) == 0 {
+		if len(candidates) == 1 {
+			return nil, api.StatusErrorf(http.StatusConflict, "Cluster member %q doesn't have enough free huge pages to back %s of memory", candidates[0].Name, memSize)
+		}
+
+		return nil, api.StatusErrorf(http.StatusConflict, "No cluster member has enough free huge pages to back %s of memory", memSize)
+	}
+
+	return allowed, nil
+}
+
+// instanceHugepagesMemberResources returns the resources of a cluster member.
+func instanceHugepagesMemberResources(s *state.State, member db.NodeInfo) (*api.Resources, error) {
+	if member.Name == s.ServerName {
+		return resources.GetResources()
+	}
+
+	client, err := cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetServerResources()
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/metrics"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/internal/server/warnings"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+// instanceIdleSampleInterval is how often instance activity counters are sampled.
+const instanceIdleSampleInterval = 10 * time.Minute
+
+// instanceIdleSuspendInterval is how often the activity of instances with an idle policy is sampled.
+const instanceIdleSuspendInterval = time.Minute
+
+// instanceIdleSample records the activity counters of an instance at a given time.
+type instanceIdleSample struct {
+	time         time.Time
+	cpuSeconds   float64
+	networkBytes float64
+	diskBytes    float64
+}
+
+// instanceIdleActivity is the activity of an instance over a window.
+type instanceIdleActivity struct {
+	cpuUsage     float64
+	networkBytes int64
+	diskBytes    int64
+}
+
+// idle returns whether the activity stays below the CPU (percentage of a single CPU), network and disk (bytes)
+// thresholds.
+func (a instanceIdleActivity) idle(cpuThreshold int64, networkThreshold int64, diskThreshold int64) bool {
+	return a.cpuUsage < float64(cpuThreshold) && a.networkBytes < networkThreshold && a.diskBytes < diskThreshold
+}
+
+// instanceIdleSamples holds the recent activity samples of the local instances, keyed by project and name.
+var instanceIdleSamples = map[string][]instanceIdleSample{}
+var instanceIdleSamplesMu sync.Mutex
+
+// instanceIdleSuspendSamples holds the recent activity samples of the local instances with an idle policy, keyed by
+// project and name.
+var instanceIdleSuspendSamples = map[string][]instanceIdleSample{}
+var instanceIdleSuspendSamplesMu sync.Mutex
+
+// instanceIdleDetectionTask periodically samples the activity of local instances and flags idle ones.
+func instanceIdleDetectionTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		window, _, _, _ := s.GlobalConfig.InstancesIdleThresholds()
+		if window == 0 {
+			instanceIdleSamplesMu.Lock()
+			instanceIdleSamples = map[string][]instanceIdleSample{}
+			instanceIdleSamplesMu.Unlock()
+
+			return
+		}
+
+		err := instanceIdleDetect(ctx, s)
+		if err != nil {
+			logger.Warn("Failed detecting idle instances", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(instanceIdleSampleInterval)
+}
+
+// instanceIdleDetect samples the activity of all running local instances and updates their idle state.
+func instanceIdleDetect(ctx context.Context, s *state.State) error {
+	window, cpuThreshold, networkThreshold, diskThreshold := s.GlobalConfig.InstancesIdleThresholds()
+
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	hostInterfaces, _ := net.Interfaces()
+	now := time.Now()
+
+	instanceIdleSamplesMu.Lock()
+	defer instanceIdleSamplesMu.Unlock()
+
+	seen := make(map[string]struct{}, len(instances))
+
+	for _, inst := range instances {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		key := fmt.Sprintf("%s/%s", inst.Project().Name, inst.Name())
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+		// Stopped instances aren't idle, they simply aren't running.
+		if !inst.IsRunning() {
+			instanceIdleClear(s, inst)
+			continue
+		}
+
+		sample, err := instanceIdleSampleGet(inst, hostInterfaces, now)
+		if err != nil {
+			l.Debug("Failed getting instance metrics for idle detection", logger.Ctx{"err": err})
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		samples, activity, ok := instanceIdleMeasure(instanceIdleSamples[key], sample, window)
+		instanceIdleSamples[key] = samples
+		if !ok {
+			continue // Not enough history yet.
+		}
+
+		if !activity.idle(cpuThreshold, networkThreshold, diskThreshold) {
+			instanceIdleClear(s, inst)
+			continue
+		}
+
+		if inst.LocalConfig()["volatile.idle.since"] == "" {
+			l.Info("Instance detected as idle", logger.Ctx{"cpuUsage": activity.cpuUsage, "networkBytes": activity.networkBytes, "diskBytes": activity.diskBytes})
+
+			err = inst.VolatileSet(map[string]string{"volatile.idle.since": samples[0].time.UTC().Format(time.RFC3339)})
+			if err != nil {
+				l.Warn("Failed marking instance as idle", logger.Ctx{"err": err})
+			}
+		}
+
+		message := fmt.Sprintf("No significant activity over the last %s (CPU: %.2f%%, network: %s, disk: %s)", window, activity.cpuUsage, units.GetByteSizeStringIEC(activity.networkBytes, 2), units.GetByteSizeStringIEC(activity.diskBytes, 2))
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, cluster.TypeInstance, inst.ID(), warningtype.InstanceIdle, message)
+		})
+		if err != nil {
+			l.Warn("Failed to create instance idle warning", logger.Ctx{"err": err})
+		}
+	}
+
+	// Forget about instances which are gone or aren't running anymore.
+	for key := range instanceIdleSamples {
+		_, ok := seen[key]
+		if !ok {
+			delete(instanceIdleSamples, key)
+		}
+	}
+
+	return nil
+}
+
+// instanceIdleSuspendTask periodically samples the activity of local instances with an idle.timeout and suspends the
+// idle ones.
+func instanceIdleSuspendTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := instanceIdleSuspend(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed suspending idle instances", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(instanceIdleSuspendInterval)
+}
+
+// instanceIdleSuspend applies the idle.action of the running local instances whose activity stayed below their idle
+// thresholds for longer than their idle.timeout.
+func instanceIdleSuspend(ctx context.Context, s *state.State) error {
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	hostInterfaces, _ := net.Interfaces()
+	now := time.Now()
+	_, cpuDefault, networkDefault, diskDefault := s.GlobalConfig.InstancesIdleThresholds()
+
+	instanceIdleSuspendSamplesMu.Lock()
+	defer instanceIdleSuspendSamplesMu.Unlock()
+
+	seen := make(map[string]struct{}, len(instances))
+	wg := sync.WaitGroup{}
+
+	for _, inst := range instances {
+		if ctx.Err() != nil {
+			break
+		}
+
+		timeout, _ := strconv.Atoi(inst.ExpandedConfig()["idle.timeout"])
+		if timeout <= 0 || !inst.IsRunning() || inst.IsFrozen() {
+			continue
+		}
+
+		key := project.Instance(inst.Project().Name, inst.Name())
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+		// The instance was resumed since it was suspended.
+		if inst.LocalConfig()["volatile.idle.suspended"] != "" {
+			err := inst.VolatileSet(map[string]string{"volatile.idle.suspended": ""})
+			if err != nil {
+				l.Warn("Failed clearing idle suspension", logger.Ctx{"err": err})
+			}
+		}
+
+		sample, err := instanceIdleSampleGet(inst, hostInterfaces, now)
+		if err != nil {
+			l.Debug("Failed getting instance metrics for idle suspension", logger.Ctx{"err": err})
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		window := time.Duration(timeout) * time.Minute
+		samples, activity, ok := instanceIdleMeasure(instanceIdleSuspendSamples[key], sample, window)
+		instanceIdleSuspendSamples[key] = samples
+		if !ok || !activity.idle(instanceIdleSuspendThresholds(inst.ExpandedConfig(), cpuDefault, networkDefault, diskDefault)) {
+			continue
+		}
+
+		// Start measuring from scratch once the instance is resumed.
+		delete(instanceIdleSuspendSamples, key)
+
+		wg.Add(1)
+		go func(inst instance.Instance) {
+			defer wg.Done()
+			instanceIdleSuspendApply(s, inst, window)
+		}(inst)
+	}
+
+	wg.Wait()
+
+	// Forget about instances which are gone or aren't running anymore.
+	for key := range instanceIdleSuspendSamples {
+		_, ok := seen[key]
+		if !ok {
+			delete(instanceIdleSuspendSamples, key)
+		}
+	}
+
+	return nil
+}
+
+// instanceIdleSuspendThresholds returns the CPU (percentage of a single CPU), network and disk (bytes) thresholds of
+// the idle policy of an instance, falling back to the server wide thresholds for those it doesn't set.
+func instanceIdleSuspendThresholds(config map[string]string, cpuDefault int64, networkDefault int64, diskDefault int64) (int64, int64, int64) {
+	cpuThreshold, err := strconv.ParseInt(config["idle.cpu_threshold"], 10, 64)
+	if err != nil {
+		cpuThreshold = cpuDefault
+	}
+
+	networkThreshold, err := units.ParseByteSizeString(config["idle.network_threshold"])
+	if err != nil || config["idle.network_threshold"] == "" {
+		networkThreshold = networkDefault
+	}
+
+	diskThreshold, err := units.ParseByteSizeString(config["idle.disk_threshold"])
+	if err != nil || config["idle.disk_threshold"] == "" {
+		diskThreshold = diskDefault
+	}
+
+	return cpuThreshold, networkThreshold, diskThreshold
+}
+
+// instanceIdleSuspendAction returns the action to apply to an idle instance.
+// Ephemeral instances are always frozen, as stopping them deletes them and they couldn't be resumed.
+func instanceIdleSuspendAction(config map[string]string, ephemeral bool) string {
+	if config["idle.action"] == "stop" && !ephemeral {
+		return "stop"
+	}
+
+	return "freeze"
+}
+
+// instanceIdleSuspendApply freezes or stops an idle instance following its idle.action.
+func instanceIdleSuspendApply(s *state.State, inst instance.Instance, window time.Duration) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	action := instanceIdleSuspendAction(inst.ExpandedConfig(), inst.IsEphemeral())
+
+	l.Info("Suspending idle instance", logger.Ctx{"action": action, "timeout": window})
+
+	// Record the suspension first so that the instance can be resumed as soon as it's suspended.
+	err := inst.VolatileSet(map[string]string{"volatile.idle.suspended": action})
+	if err != nil {
+		l.Warn("Failed recording idle suspension", logger.Ctx{"err": err})
+		return
+	}
+
+	if action == "stop" {
+		timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+		if err != nil {
+			timeout = 30
+		}
+
+		err = inst.Shutdown(time.Duration(timeout) * time.Second)
+		if err != nil {
+			err = inst.Stop(false)
+		}
+	} else {
+		err = inst.Freeze()
+	}
+
+	if err != nil {
+		l.Warn("Failed suspending idle instance", logger.Ctx{"action": action, "err": err})
+
+		err = inst.VolatileSet(map[string]string{"volatile.idle.suspended": ""})
+		if err != nil {
+			l.Warn("Failed clearing idle suspension", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceIdleSuspended.Event(inst, map[string]any{"action": action, "timeout": int(window.Minutes())}))
+}
+
+// instanceIdleResume resumes an instance suspended by its idle policy, so it can be accessed through the console or
+// exec. Instances which weren't suspended by their idle policy are left alone.
+func instanceIdleResume(inst instance.Instance) error {
+	action := inst.LocalConfig()["volatile.idle.suspended"]
+
+	var err error
+	if action == "freeze" && inst.IsFrozen() {
+		err = inst.Unfreeze()
+	} else if action == "stop" && !inst.IsRunning() {
+		err = inst.Start(false)
+	} else {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed resuming idle instance: %w", err)
+	}
+
+	return inst.VolatileSet(map[string]string{"volatile.idle.suspended": ""})
+}
+
+// instanceIdleClear removes the idle flag and resolves the idle warning of an instance.
+func instanceIdleClear(s *state.State, inst instance.Instance) {
+	if inst.LocalConfig()["volatile.idle.since"] == "" {
+		return
+	}
+
+	err := inst.VolatileSet(map[string]string{"volatile.idle.since": ""})
+	if err != nil {
+		logger.Warn("Failed clearing instance idle flag", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+	}
+
+	err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Project().Name, warningtype.InstanceIdle, cluster.TypeInstance, inst.ID())
+	if err != nil {
+		logger.Warn("Failed to resolve instance idle warning", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+	}
+}
+
+// instanceIdleSampleGet samples the activity counters of an instance.
+func instanceIdleSampleGet(inst instance.Instance, hostInterfaces []net.Interface, now time.Time) (instanceIdleSample, error) {
+	metricSet, err := inst.Metrics(hostInterfaces)
+	if err != nil {
+		return instanceIdleSample{}, err
+	}
+
+	return instanceIdleSample{
+		time:         now,
+		cpuSeconds:   instanceIdleMetricSum(metricSet, metrics.CPUSecondsTotal),
+		networkBytes: instanceIdleMetricSum(metricSet, metrics.NetworkReceiveBytesTotal) + instanceIdleMetricSum(metricSet, metrics.NetworkTransmitBytesTotal),
+		diskBytes:    instanceIdleMetricSum(metricSet, metrics.DiskReadBytesTotal) + instanceIdleMetricSum(metricSet, metrics.DiskWrittenBytesTotal),
+	}, nil
+}
+
+// instanceIdleMeasure adds a sample to the history of an instance and returns the updated history along with the
+// activity over the window. The activity is only valid once the history covers the whole window.
+func instanceIdleMeasure(samples []instanceIdleSample, sample instanceIdleSample, window time.Duration) ([]instanceIdleSample, instanceIdleActivity, bool) {
+	// Counters going backwards means the instance was restarted, start over.
+	if len(samples) > 0 {
+		last := samples[len(samples)-1]
+		if sample.cpuSeconds < last.cpuSeconds || sample.networkBytes < last.networkBytes || sample.diskBytes < last.diskBytes {
+			samples = nil
+		}
+	}
+
+	samples = append(samples, sample)
+
+	// Only keep the most recent sample which covers the whole window as the baseline.
+	for len(samples) > 1 && !samples[1].time.After(sample.time.Add(-window)) {
+		samples = samples[1:]
+	}
+
+	baseline := samples[0]
+	if baseline.time.After(sample.time.Add(-window)) {
+		return samples, instanceIdleActivity{}, false
+	}
+
+	elapsed := sample.time.Sub(baseline.time).Seconds()
+
+	return samples, instanceIdleActivity{
+		cpuUsage:     (sample.cpuSeconds - baseline.cpuSeconds) / elapsed * 100,
+		networkBytes: int64(sample.networkBytes - baseline.networkBytes),
+		diskBytes:    int64(sample.diskBytes - baseline.diskBytes),
+	}, true
+}
+
+// instanceIdleMetricSum returns the sum of all samples of a metric, ignoring the loopback interface and
+// idle CPU time.
+func instanceIdleMetricSum(metricSet *metrics.MetricSet, metricType metrics.MetricType) float64 {
+	var total float64
+
+	for _, sample := range metricSet.Samples(metricType) {
+		if sample.Labels["device"] == "lo" {
+			continue
+		}
+
+		mode := sample.Labels["mode"]
+		if mode == "idle" || mode == "iowait" || mode == "steal" {
+			continue
+		}
+
+		total += sample.Value
+	}
+
+	return total
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/metrics"
+)
+
+func TestInstanceIdleSuspendThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		cpu     int64
+		network int64
+		disk    int64
+	}{
+		{"Server defaults", map[string]string{}, 1, 10 * 1024 * 1024, 10 * 1024 * 1024},
+		{"Partial", map[string]string{"idle.network_threshold": "1MiB"}, 1, 1024 * 1024, 10 * 1024 * 1024},
+		{"Custom", map[string]string{"idle.cpu_threshold": "5", "idle.network_threshold": "10MiB", "idle.disk_threshold": "2kB"}, 5, 10 * 1024 * 1024, 2000},
+		{"Zero", map[string]string{"idle.cpu_threshold": "0", "idle.network_threshold": "0", "idle.disk_threshold": "0"}, 0, 0, 0},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		cpu, network, disk := instanceIdleSuspendThresholds(tt.config, 1, 10*1024*1024, 10*1024*1024)
+		require.Equal(t, tt.cpu, cpu)
+		require.Equal(t, tt.network, network)
+		require.Equal(t, tt.disk, disk)
+	}
+}
+
+func TestInstanceIdleSuspendAction(t *testing.T) {
+	require.Equal(t, "freeze", instanceIdleSuspendAction(map[string]string{}, false))
+	require.Equal(t, "freeze", instanceIdleSuspendAction(map[string]string{"idle.action": "freeze"}, false))
+	require.Equal(t, "stop", instanceIdleSuspendAction(map[string]string{"idle.action": "stop"}, false))
+
+	// Ephemeral instances are frozen as stopping deletes them.
+	require.Equal(t, "freeze", instanceIdleSuspendAction(map[string]string{"idle.action": "stop"}, true))
+}
+
+func TestInstanceIdleMeasure(t *testing.T) {
+	start := time.Now()
+	sample := func(minutes int, cpuSeconds float64, bytes float64) instanceIdleSample {
+		return instanceIdleSample{time: start.Add(time.Duration(minutes) * time.Minute), cpuSeconds: cpuSeconds, networkBytes: bytes, diskBytes: bytes}
+	}
+
+	// Not enough history to cover the window yet.
+	samples, _, ok := instanceIdleMeasure(nil, sample(0, 0, 0), 10*time.Minute)
+	require.False(t, ok)
+
+	samples, _, ok = instanceIdleMeasure(samples, sample(5, 1, 1024), 10*time.Minute)
+	require.False(t, ok)
+
+	// The activity is measured from the most recent sample covering the window.
+	samples, activity, ok := instanceIdleMeasure(samples, sample(10, 6, 2048), 10*time.Minute)
+	require.True(t, ok)
+	require.Len(t, samples, 3)
+	require.InDelta(t, 1, activity.cpuUsage, 0.001)
+	require.Equal(t, int64(2048), activity.networkBytes)
+	require.True(t, activity.idle(2, 4096, 4096))
+	require.False(t, activity.idle(1, 4096, 4096))
+
+	samples, activity, ok = instanceIdleMeasure(samples, sample(15, 7, 4096), 10*time.Minute)
+	require.True(t, ok)
+	require.Len(t, samples, 3)
+	require.Equal(t, int64(3072), activity.diskBytes)
+
+	// Counters going backwards start over.
+	samples, _, ok = instanceIdleMeasure(samples, sample(20, 0, 0), 10*time.Minute)
+	require.False(t, ok)
+	require.Len(t, samples, 1)
+}
+
+func TestInstanceIdleActivity(t *testing.T) {
+	activity := instanceIdleActivity{cpuUsage: 0.5, networkBytes: 1024, diskBytes: 2048}
+
+	tests := []struct {
+		name     string
+		cpu      int64
+		network  int64
+		disk     int64
+		expected bool
+	}{
+		{"Below all thresholds", 1, 4096, 4096, true},
+		{"CPU threshold reached", 0, 4096, 4096, false},
+		{"Network threshold reached", 1, 1024, 4096, false},
+		{"Disk threshold reached", 1, 4096, 2048, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, activity.idle(tt.cpu, tt.network, tt.disk))
+		})
+	}
+}
+
+func TestInstanceIdleMetricSum(t *testing.T) {
+	metricSet := metrics.NewMetricSet(nil)
+
+	metricSet.AddSamples(metrics.CPUSecondsTotal,
+		metrics.Sample{Labels: map[string]string{"cpu": "0", "mode": "user"}, Value: 10},
+		metrics.Sample{Labels: map[string]string{"cpu": "0", "mode": "system"}, Value: 5},
+		metrics.Sample{Labels: map[string]string{"cpu": "0", "mode": "idle"}, Value: 1000},
+		metrics.Sample{Labels: map[string]string{"cpu": "0", "mode": "iowait"}, Value: 100},
+		metrics.Sample{Labels: map[string]string{"cpu": "0", "mode": "steal"}, Value: 100},
+		metrics.Sample{Labels: map[string]string{"cpu": "1", "mode": "user"}, Value: 2},
+	)
+
+	metricSet.AddSamples(metrics.NetworkReceiveBytesTotal,
+		metrics.Sample{Labels: map[string]string{"device": "eth0"}, Value: 1024},
+		metrics.Sample{Labels: map[string]string{"device": "eth1"}, Value: 512},
+		metrics.Sample{Labels: map[string]string{"device": "lo"}, Value: 4096},
+	)
+
+	// Idle CPU time and loopback traffic aren't activity.
+	require.Equal(t, float64(17), instanceIdleMetricSum(metricSet, metrics.CPUSecondsTotal))
+	require.Equal(t, float64(1536), instanceIdleMetricSum(metricSet, metrics.NetworkReceiveBytesTotal))
+
+	// Missing metrics count as no activity.
+	require.Equal(t, float64(0), instanceIdleMetricSum(metricSet, metrics.DiskReadBytesTotal))
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/internal/server/warnings"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// instanceLimitsState records what was last seen of the limits of an instance.
+type instanceLimitsState struct {
+	processesLimitHits     int64
+	fdsLimitReached        bool
+	memoryPressureExceeded bool
+	memoryPressureFrozen   bool
+}
+
+// instanceLimitsStates holds the limits state of the local instances, keyed by project and name.
+var instanceLimitsStates = map[string]*instanceLimitsState{}
+var instanceLimitsStatesMu sync.Mutex
+
+// instanceLimitsTask periodically checks whether the local containers are running into their limits and whether the
+// local instances are under sustained memory pressure.
+func instanceLimitsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := instanceLimitsCheck(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed checking instance limits", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// instanceLimitsCheck emits lifecycle events for the running local containers which hit their process or
+// file descriptor limits since the last check, and applies the memory pressure action of the running local instances
+// whose memory pressure over the last minute is above their threshold.
+func instanceLimitsCheck(ctx context.Context, s *state.State) error {
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	instanceLimitsStatesMu.Lock()
+	defer instanceLimitsStatesMu.Unlock()
+
+	seen := make(map[string]struct{}, len(instances))
+
+	for _, inst := range instances {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		processesLimit := inst.ExpandedConfig()["limits.processes"]
+		fdsLimit := inst.ExpandedConfig()["limits.fds"]
+		pressureThreshold := inst.ExpandedConfig()["limits.memory.pressure.threshold"]
+		if !inst.IsRunning() || (processesLimit == "" && fdsLimit == "" && pressureThreshold == "") {
+			instanceMemoryPressureClear(s, inst)
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s", inst.Project().Name, inst.Name())
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+		seen[key] = struct{}{}
+
+		limitsState, ok := instanceLimitsStates[key]
+		if !ok {
+			limitsState = &instanceLimitsState{processesLimitHits: -1}
+			instanceLimitsStates[key] = limitsState
+		}
+
+		instanceMemoryPressureCheck(ctx, s, inst, limitsState)
+
+		c, ok := inst.(instance.Container)
+		if !ok {
+			continue
+		}
+
+		if processesLimit != "" {
+			hits, err := c.ProcessesLimitHits()
+			if err != nil {
+				l.Debug("Failed getting process limit hits", logger.Ctx{"err": err})
+			} else {
+				// The first check only records the baseline.
+				if limitsState.processesLimitHits >= 0 && hits > limitsState.processesLimitHits {
+					l.Warn("Instance reached its process limit", logger.Ctx{"limit": processesLimit, "failures": hits - limitsState.processesLimitHits})
+					s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceLimitReached.Event(inst, map[string]any{"limit": "limits.processes", "value": processesLimit, "failures": hits - limitsState.processesLimitHits}))
+				}
+
+				limitsState.processesLimitHits = hits
+			}
+		}
+
+		if fdsLimit != "" {
+			limit, err := strconv.ParseInt(fdsLimit, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			_, highest, err := c.FileDescriptorsUsage()
+			if err != nil {
+				l.Debug("Failed getting file descriptor usage", logger.Ctx{"err": err})
+				continue
+			}
+
+			// Only report when the limit starts being reached.
+			reached := highest >= limit
+			if reached && !limitsState.fdsLimitReached {
+				l.Warn("Instance reached its file descriptor limit", logger.Ctx{"limit": fdsLimit})
+				s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceLimitReached.Event(inst, map[string]any{"limit": "limits.fds", "value": fdsLimit}))
+			}
+
+			limitsState.fdsLimitReached = reached
+		}
+	}
+
+	// Forget about instances which are gone or aren't running anymore.
+	for key := range instanceLimitsStates {
+		_, ok := seen[key]
+		if !ok {
+			delete(instanceLimitsStates, key)
+		}
+	}
+
+	return nil
+}
+
+// instanceMemoryPressureCheck compares the memory pressure of a running instance over the last minute with its
+// threshold. An instance frozen because of its memory pressure is unfrozen once its pressure over the last five
+// minutes falls back below the threshold, since frozen tasks aren't stalled.
+func instanceMemoryPressureCheck(ctx context.Context, s *state.State, inst instance.Instance, limitsState *instanceLimitsState) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	pressureThreshold := inst.ExpandedConfig()["limits.memory.pressure.threshold"]
+	if pressureThreshold == "" {
+		instanceMemoryPressureClear(s, inst)
+		return
+	}
+
+	threshold, err := strconv.ParseFloat(pressureThreshold, 64)
+	if err != nil {
+		return
+	}
+
+	// Leave the instances frozen by others alone, and take over again once they're unfrozen.
+	if !inst.IsFrozen() {
+		instanceMemoryPressureFrozenSet(inst, limitsState, false)
+	} else if !limitsState.memoryPressureFrozen {
+		return
+	}
+
+	cg, err := inst.CGroup()
+	if err != nil {
+		l.Debug("Failed getting instance cgroup", logger.Ctx{"err": err})
+		return
+	}
+
+	pressure, err := cg.GetPressure("memory")
+	if err != nil {
+		l.Debug("Failed getting memory pressure", logger.Ctx{"err": err})
+		return
+	}
+
+	if limitsState.memoryPressureFrozen {
+		if pressure.Some.Avg300 <= threshold {
+			instanceMemoryPressureClear(s, inst)
+		}
+
+		return
+	}
+
+	if pressure.Some.Avg60 > threshold {
+		instanceMemoryPressureApply(ctx, s, inst, limitsState, pressure.Some.Avg60)
+	} else {
+		instanceMemoryPressureClear(s, inst)
+	}
+}
+
+// instanceMemoryPressureApply raises the memory pressure warning of an instance and, when the pressure just crossed
+// the threshold, takes the action set in limits.memory.pressure.action.
+func instanceMemoryPressureApply(ctx context.Context, s *state.State, inst instance.Instance, limitsState *instanceLimitsState, pressure float64) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+	threshold := inst.ExpandedConfig()["limits.memory.pressure.threshold"]
+
+	message := fmt.Sprintf("Tasks stalled on memory %.2f%% of the time over the last minute (threshold: %s%%)", pressure, threshold)
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, cluster.TypeInstance, inst.ID(), warningtype.InstanceMemoryPressure, message)
+	})
+	if err != nil {
+		l.Warn("Failed to create instance memory pressure warning", logger.Ctx{"err": err})
+	}
+
+	if limitsState.memoryPressureExceeded {
+		return
+	}
+
+	limitsState.memoryPressureExceeded = true
+
+	action := inst.ExpandedConfig()["limits.memory.pressure.action"]
+	if action == "" {
+		action = "warn"
+	}
+
+	l.Warn("Instance is under sustained memory pressure", logger.Ctx{"pressure": pressure, "threshold": threshold, "action": action})
+
+	if action == "warn" {
+		return
+	}
+
+	if action == "freeze" {
+		// Record the freeze first so that the instance gets unfrozen even if the daemon restarts in between.
+		instanceMemoryPressureFrozenSet(inst, limitsState, true)
+
+		err = inst.Freeze()
+		if err != nil {
+			l.Warn("Failed freezing instance under memory pressure", logger.Ctx{"err": err})
+			instanceMemoryPressureFrozenSet(inst, limitsState, false)
+		}
+	}
+
+	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceLimitReached.Event(inst, map[string]any{"limit": "limits.memory.pressure.threshold", "value": threshold, "pressure": pressure, "action": action}))
+}
+
+// instanceMemoryPressureClear resolves the memory pressure warning of an instance and unfreezes it if it was frozen
+// because of its memory pressure. It must be called with instanceLimitsStatesMu held.
+func instanceMemoryPressureClear(s *state.State, inst instance.Instance) {
+	limitsState, ok := instanceLimitsStates[fmt.Sprintf("%s/%s", inst.Project().Name, inst.Name())]
+	if !ok {
+		return
+	}
+
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	if limitsState.memoryPressureFrozen && inst.IsFrozen() {
+		l.Info("Unfreezing instance as its memory pressure fell back")
+
+		err := inst.Unfreeze()
+		if err != nil {
+			l.Warn("Failed unfreezing instance after memory pressure", logger.Ctx{"err": err})
+			return
+		}
+	}
+
+	instanceMemoryPressureFrozenSet(inst, limitsState, false)
+
+	if !limitsState.memoryPressureExceeded {
+		return
+	}
+
+	err := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Project().Name, warningtype.InstanceMemoryPressure, cluster.TypeInstance, inst.ID())
+	if err != nil {
+		l.Warn("Failed to resolve instance memory pressure warning", logger.Ctx{"err": err})
+	}
+
+	limitsState.memoryPressureExceeded = false
+}
+
+// instanceMemoryPressureFrozenSet records whether an instance is frozen because of its memory pressure, persisting it
+// in volatile.memory.pressure.frozen so that it survives daemon restarts.
+func instanceMemoryPressureFrozenSet(inst instance.Instance, limitsState *instanceLimitsState, frozen bool) {
+	limitsState.memoryPressureFrozen = frozen
+
+	value := ""
+	if frozen {
+		value = "true"
+	}
+
+	if inst.LocalConfig()["volatile.memory.pressure.frozen"] == value {
+		return
+	}
+
+	err := inst.VolatileSet(map[string]string{"volatile.memory.pressure.frozen": value})
+	if err != nil {
+		logger.Warn("Failed recording memory pressure freeze", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+	}
+}
+
+// instanceLimitsRestore picks the local instances frozen because of their memory pressure back up after a daemon
+// restart, so that they get unfrozen once their pressure falls back. The record of the instances which aren't
+// frozen anymore is cleared.
+func instanceLimitsRestore(instances []instance.Instance) {
+	instanceLimitsStatesMu.Lock()
+	defer instanceLimitsStatesMu.Unlock()
+
+	for _, inst := range instances {
+		if !util.IsTrue(inst.LocalConfig()["volatile.memory.pressure.frozen"]) {
+			continue
+		}
+
+		limitsState := &instanceLimitsState{processesLimitHits: -1, memoryPressureExceeded: true}
+
+		if !inst.IsFrozen() {
+			instanceMemoryPressureFrozenSet(inst, limitsState, false)
+			continue
+		}
+
+		limitsState.memoryPressureFrozen = true
+		instanceLimitsStates[fmt.Sprintf("%s/%s", inst.Project().Name, inst.Name())] = limitsState
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+)
+
+// Instances recorded as frozen on memory pressure which aren't frozen anymore get their record cleared.
+func (suite *containerTestSuite) TestInstanceLimitsRestore() {
+	c, op, _, err := instance.CreateInternal(suite.d.State(), db.InstanceArgs{
+		Type:   instancetype.Container,
+		Name:   "testLimitsRestore",
+		Config: map[string]string{"volatile.memory.pressure.frozen": "true"},
+	}, true, true)
+	suite.Req.Nil(err)
+	op.Done(nil)
+	defer func() { _ = c.Delete(true) }()
+
+	instanceLimitsRestore([]instance.Instance{c})
+
+	suite.Req.Equal("", c.LocalConfig()["volatile.memory.pressure.frozen"])
+	suite.Req.NotContains(instanceLimitsStates, "default/testLimitsRestore")
+}
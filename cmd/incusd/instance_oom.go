@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/linux"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// instanceOOMState records what was last seen of the out-of-memory kills of a running instance.
+type instanceOOMState struct {
+	kills int64
+	pid   int
+}
+
+// instanceOOMStates holds the out-of-memory state of the local instances, keyed by project and name.
+var instanceOOMStates = map[string]*instanceOOMState{}
+var instanceOOMStatesMu sync.Mutex
+
+// instanceOOMTask periodically checks whether processes of the local instances were killed by the out-of-memory
+// killer.
+func instanceOOMTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := instanceOOMCheck(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed checking instance out-of-memory kills", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(30 * time.Second)
+}
+
+// instanceOOMCheck reports the out-of-memory kills which happened in the running local instances since the last
+// check, as well as the QEMU processes of the local virtual machines killed by the host, and applies their oom.policy.
+// The kernel log is only read when there are new kills.
+func instanceOOMCheck(ctx context.Context, s *state.State) error {
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	instanceOOMStatesMu.Lock()
+	defer instanceOOMStatesMu.Unlock()
+
+	hostKills := sync.OnceValue(func() []linux.OOMKill {
+		kills, err := linux.OOMKills()
+		if err != nil {
+			logger.Debug("Failed getting the out-of-memory kills from the kernel log", logger.Ctx{"err": err})
+		}
+
+		return kills
+	})
+
+	seen := make(map[string]struct{}, len(instances))
+	wg := sync.WaitGroup{}
+
+	apply := func(inst instance.Instance, kills int64, process string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instanceOOMApply(ctx, s, inst, kills, process)
+		}()
+	}
+
+	for _, inst := range instances {
+		if ctx.Err() != nil {
+			break
+		}
+
+		key := fmt.Sprintf("%s/%s", inst.Project().Name, inst.Name())
+		previous, ok := instanceOOMStates[key]
+
+		pid := -1
+		if inst.IsRunning() {
+			pid = inst.InitPID()
+		}
+
+		// The QEMU process of a virtual machine killed by the host takes the whole virtual machine down.
+		if ok && inst.Type() == instancetype.VM && previous.pid > 0 && pid != previous.pid {
+			for _, kill := range hostKills() {
+				if kill.PID == int64(previous.pid) {
+					apply(inst, 1, kill.Task)
+					break
+				}
+			}
+		}
+
+		if pid <= 0 {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		if inst.IsFrozen() {
+			continue
+		}
+
+		current := &instanceOOMState{kills: -1, pid: pid}
+		instanceOOMStates[key] = current
+
+		count, process, err := inst.OOMKills()
+		if err != nil {
+			logger.Debug("Failed getting out-of-memory kills", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			continue
+		}
+
+		current.kills = count
+
+		// The first check only records the baseline.
+		if !ok || previous.kills < 0 {
+			continue
+		}
+
+		// The counter starts over when the instance is restarted.
+		base := previous.kills
+		if count < base || pid != previous.pid {
+			base = 0
+		}
+
+		if count == base {
+			continue
+		}
+
+		c, isContainer := inst.(instance.Container)
+		if isContainer && process == "" {
+			process = c.OOMKillTask(hostKills())
+		}
+
+		apply(inst, count-base, process)
+	}
+
+	wg.Wait()
+
+	// Forget about instances which are gone or aren't running anymore.
+	for key := range instanceOOMStates {
+		_, ok := seen[key]
+		if !ok {
+			delete(instanceOOMStates, key)
+		}
+	}
+
+	return nil
+}
+
+// instanceOOMApply reports the out-of-memory kills of an instance and applies its oom.policy.
+func instanceOOMApply(ctx context.Context, s *state.State, inst instance.Instance, kills int64, process string) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	policy := inst.ExpandedConfig()["oom.policy"]
+	if policy == "" {
+		policy = "ignore"
+	}
+
+	l.Warn("Instance process killed by the out-of-memory killer", logger.Ctx{"process": process, "kills": kills, "policy": policy})
+
+	message := "A process was killed by the out-of-memory killer"
+	if process != "" {
+		message = fmt.Sprintf("Process %q was killed by the out-of-memory killer", process)
+	}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, cluster.TypeInstance, inst.ID(), warningtype.InstanceOOMKill, message)
+	})
+	if err != nil {
+		l.Warn("Failed to create instance out-of-memory kill warning", logger.Ctx{"err": err})
+	}
+
+	eventCtx := map[string]any{"kills": kills, "policy": policy}
+	if process != "" {
+		eventCtx["process"] = process
+	}
+
+	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceOOMKilled.Event(inst, eventCtx))
+
+	if policy == "ignore" {
+		return
+	}
+
+	timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+	if err != nil {
+		timeout = 30
+	}
+
+	// A virtual machine whose QEMU process was killed is already stopped.
+	running := inst.IsRunning()
+
+	if policy == "restart" {
+		if running {
+			err = inst.Restart(time.Duration(timeout) * time.Second)
+		} else {
+			err = inst.Start(false)
+		}
+	} else if running {
+		err = inst.Shutdown(time.Duration(timeout) * time.Second)
+		if err != nil {
+			err = inst.Stop(false)
+		}
+	}
+
+	if err != nil {
+		l.Warn("Failed applying the out-of-memory policy of the instance", logger.Ctx{"policy": policy, "err": err})
+	}
+}
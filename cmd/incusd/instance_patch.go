@@ -201,12 +201,25 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 			apiProfiles = append(apiProfiles, *apiProfile)
 		}
 
+		// Check that the instance location satisfies the policy of its new instance group.
+		if s.ServerClustered {
+			err = instanceGroupCheckLocation(ctx, tx, c, db.ExpandInstanceConfig(req.Config, apiProfiles))
+			if err != nil {
+				return err
+			}
+		}
+
 		return projecthelpers.AllowInstanceUpdate(tx, projectName, name, req, c.LocalConfig())
 	})
 	if err != nil {
 		return response.SmartError(err)
 	}
 
+	err = instanceRefreshConfigCheck(s, r, c.LocalConfig(), req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Update container configuration
 	args := db.InstanceArgs{
 		Architecture: architecture,
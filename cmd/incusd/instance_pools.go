@@ -0,0 +1,1305 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/locking"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/task"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/osarch"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+var instancePoolsCmd = APIEndpoint{
+	Path: "instance-pools",
+
+	Get:  APIEndpointAction{Handler: instancePoolsGet, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+	Post: APIEndpointAction{Handler: instancePoolsPost, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+}
+
+var instancePoolCmd = APIEndpoint{
+	Path: "instance-pools/{name}",
+
+	Delete: APIEndpointAction{Handler: instancePoolDelete, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+	Get:    APIEndpointAction{Handler: instancePoolGet, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+	Put:    APIEndpointAction{Handler: instancePoolPut, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+	Patch:  APIEndpointAction{Handler: instancePoolPut, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+}
+
+var instancePoolAcquireCmd = APIEndpoint{
+	Path: "instance-pools/{name}/acquire",
+
+	Post: APIEndpointAction{Handler: instancePoolAcquirePost, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+}
+
+var instancePoolReleaseCmd = APIEndpoint{
+	Path: "instance-pools/{name}/release",
+
+	Post: APIEndpointAction{Handler: instancePoolReleasePost, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateInstances)},
+}
+
+// instancePoolReleasePolicies are the supported ways of handling the instances released to an instance pool.
+var instancePoolReleasePolicies = []string{"recycle", "destroy"}
+
+// instancePoolSnapshotName is the name of the snapshot recycled instances are restored to.
+const instancePoolSnapshotName = "instance-pool"
+
+// instancePoolMaxSize is the maximum number of ready instances of an instance pool.
+const instancePoolMaxSize = 1000
+
+// instancePoolValidate validates the fields of an instance pool.
+func instancePoolValidate(info api.InstancePoolPut) error {
+	if info.Image == "" {
+		return fmt.Errorf("An image must be provided")
+	}
+
+	if info.Type != api.InstanceTypeContainer && info.Type != api.InstanceTypeVM {
+		return fmt.Errorf("Invalid instance type %q", info.Type)
+	}
+
+	if info.Size < 0 || info.Size > instancePoolMaxSize {
+		return fmt.Errorf("Invalid size %d, must be between 0 and %d", info.Size, instancePoolMaxSize)
+	}
+
+	if !slices.Contains(instancePoolReleasePolicies, info.Release) {
+		return fmt.Errorf("Invalid release policy %q, must be one of %v", info.Release, instancePoolReleasePolicies)
+	}
+
+	return nil
+}
+
+// instancePoolLoad loads an instance pool along with its members.
+func instancePoolLoad(ctx context.Context, tx *db.ClusterTx, projectName string, name string) (int64, *api.InstancePool, []db.InstancePoolMember, error) {
+	id, pool, err := tx.GetInstancePoolByName(ctx, projectName, name)
+	if err != nil {
+		return -1, nil, nil, err
+	}
+
+	members, err := tx.GetInstancePoolMembers(ctx, id)
+	if err != nil {
+		return -1, nil, nil, err
+	}
+
+	pool.UsedBy = make([]string, 0, len(members))
+	for _, member := range members {
+		if member.Acquired {
+			pool.Acquired++
+		} else {
+			pool.Ready++
+		}
+
+		pool.UsedBy = append(pool.UsedBy, api.NewURL().Path(version.APIVersion, "instances", member.Instance).Project(projectName).String())
+	}
+
+	return id, pool, members, nil
+}
+
+// instancePoolLeader returns the address of the cluster leader if the local server isn't the one filling the
+// instance pools.
+func instancePoolLeader(d *Daemon) (string, error) {
+	s := d.State()
+	if !s.ServerClustered {
+		return "", nil
+	}
+
+	leader, err := d.gateway.LeaderAddress()
+	if err != nil {
+		return "", err
+	}
+
+	if leader == s.LocalConfig.ClusterAddress() {
+		return "", nil
+	}
+
+	return leader, nil
+}
+
+// instancePoolForwardToLeader forwards the request to the cluster leader, which manages the instance pools.
+// It returns nil when the local server is the leader.
+func instancePoolForwardToLeader(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	leader, err := instancePoolLeader(d)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if leader == "" {
+		return nil
+	}
+
+	client, err := cluster.Connect(leader, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.ForwardedResponse(client, r)
+}
+
+// instancePoolFillAsync fills the instance pool in the background.
+func instancePoolFillAsync(s *state.State, projectName string, name string) {
+	go func() {
+		err := instancePoolFill(s.ShutdownCtx, s, projectName, name)
+		if err != nil {
+			logger.Warn("Failed filling instance pool", logger.Ctx{"project": projectName, "pool": name, "err": err})
+		}
+	}()
+}
+
+// instancePoolFill creates the missing ready instances of an instance pool and removes the surplus ones.
+func instancePoolFill(ctx context.Context, s *state.State, projectName string, name string) error {
+	unlock, err := locking.Lock(ctx, fmt.Sprintf("InstancePool_%s", project.Instance(projectName, name)))
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	var id int64
+	var pool *api.InstancePool
+	var img *api.Image
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		id, pool, _, err = instancePoolLoad(ctx, tx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		if pool.Ready >= pool.Size {
+			return nil
+		}
+
+		// Resolve the image.
+		fingerprint := pool.Image
+		_, alias, err := tx.GetImageAlias(ctx, projectName, pool.Image, true)
+		if err == nil {
+			fingerprint = alias.Target
+		}
+
+		_, img, err = tx.GetImageByFingerprintPrefix(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return fmt.Errorf("Failed loading image %q: %w", pool.Image, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove the surplus ready instances. They're taken out of the instance pool first so that they can't be
+	// acquired while being deleted.
+	if pool.Ready > pool.Size {
+		var surplus []db.InstancePoolMember
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			surplus, err = tx.RemoveInstancePoolSurplusMembers(ctx, id, pool.Size)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, member := range surplus {
+			err := instancePoolRemoveInstance(s, projectName, member.Instance)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for i := pool.Ready; i < pool.Size; i++ {
+		err := instancePoolCreateInstance(ctx, s, id, pool, img)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// instancePoolCreateRequest returns the request creating a new ready instance of an instance pool.
+func instancePoolCreateRequest(pool *api.InstancePool, name string, img *api.Image) api.InstancesPost {
+	return api.InstancesPost{
+		Name: name,
+		Type: pool.Type,
+		Source: api.InstanceSource{
+			Type:        "image",
+			Fingerprint: img.Fingerprint,
+		},
+		InstancePut: api.InstancePut{
+			Architecture: img.Architecture,
+			Description:  fmt.Sprintf("Instance of instance pool %q", pool.Name),
+			Profiles:     slices.Clone(pool.Profiles),
+			Config:       map[string]string{},
+			Devices:      map[string]map[string]string{},
+		},
+	}
+}
+
+// instancePoolCreateInstance creates and starts a new ready instance of an instance pool, on the cluster member
+// with the least instances.
+func instancePoolCreateInstance(ctx context.Context, s *state.State, id int64, pool *api.InstancePool, img *api.Image) error {
+	suffix, err := internalUtil.RandomHexString(4)
+	if err != nil {
+		return err
+	}
+
+	req := instancePoolCreateRequest(pool, fmt.Sprintf("%s-%s", pool.Name, suffix), img)
+
+	architecture, err := osarch.ArchitectureId(img.Architecture)
+	if err != nil {
+		return err
+	}
+
+	var target *db.NodeInfo
+	var profiles []api.Profile
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		// Check that the project's limits are not violated.
+		err := project.AllowInstanceCreation(tx, pool.Project, req)
+		if err != nil {
+			return err
+		}
+
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), pool.Project)
+		if err != nil {
+			return err
+		}
+
+		apiProject, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		profileProject := project.ProfileProjectFromRecord(apiProject)
+		profiles = make([]api.Profile, 0, len(pool.Profiles))
+		for _, profileName := range pool.Profiles {
+			dbProfile, err := dbCluster.GetProfile(ctx, tx.Tx(), profileProject, profileName)
+			if err != nil {
+				return fmt.Errorf("Failed loading profile %q: %w", profileName, err)
+			}
+
+			apiProfile, err := dbProfile.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			profiles = append(profiles, *apiProfile)
+		}
+
+		if !s.ServerClustered {
+			return nil
+		}
+
+		// Spread the instances over the cluster members like the instances created through the API.
+		allMembers, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		candidateMembers, err := tx.GetCandidateMembers(ctx, allMembers, []int{architecture}, "", project.GetRestrictedClusterGroups(apiProject), s.GlobalConfig.OfflineThreshold())
+		if err != nil {
+			return err
+		}
+
+		target, err = tx.GetNodeWithLeastInstances(ctx, candidateMembers)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if target != nil && target.Name != s.ServerName {
+		err = instancePoolCreateRemoteInstance(s, target, pool, req)
+	} else {
+		err = instancePoolCreateLocalInstance(ctx, s, pool, req, architecture, img, profiles)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() { _ = instancePoolRemoveInstance(s, pool.Project, req.Name) })
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.AddInstancePoolMember(ctx, id, pool.Project, req.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// instancePoolCreateLocalInstance creates and starts a new ready instance of an instance pool on the local server.
+func instancePoolCreateLocalInstance(ctx context.Context, s *state.State, pool *api.InstancePool, req api.InstancesPost, architecture int, img *api.Image, profiles []api.Profile) error {
+	instanceType, err := instancetype.New(string(pool.Type))
+	if err != nil {
+		return err
+	}
+
+	err = ensureImageIsLocallyAvailable(ctx, s, nil, img, pool.Project, instanceType)
+	if err != nil {
+		return err
+	}
+
+	args := db.InstanceArgs{
+		Project:      pool.Project,
+		Name:         req.Name,
+		Type:         instanceType,
+		Architecture: architecture,
+		Config:       req.Config,
+		Description:  req.Description,
+		Devices:      deviceConfig.ApplyDeviceInitialValues(deviceConfig.Devices{}, profiles),
+		Profiles:     profiles,
+	}
+
+	err = instanceCreateFromImage(ctx, s, nil, img, args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed creating instance: %w", err)
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, pool.Project, args.Name)
+	if err != nil {
+		return err
+	}
+
+	// Recycled instances are restored to their state before their first start.
+	if pool.Release == "recycle" {
+		err = inst.Snapshot(instancePoolSnapshotName, time.Time{}, false)
+		if err != nil {
+			_ = inst.Delete(true)
+			return fmt.Errorf("Failed creating snapshot of instance %q: %w", inst.Name(), err)
+		}
+	}
+
+	err = inst.Start(false)
+	if err != nil {
+		_ = inst.Delete(true)
+		return fmt.Errorf("Failed starting instance %q: %w", inst.Name(), err)
+	}
+
+	return nil
+}
+
+// instancePoolCreateRemoteInstance creates and starts a new ready instance of an instance pool on another cluster
+// member.
+func instancePoolCreateRemoteInstance(s *state.State, target *db.NodeInfo, pool *api.InstancePool, req api.InstancesPost) error {
+	client, err := cluster.Connect(target.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return err
+	}
+
+	client = client.UseProject(pool.Project)
+
+	op, err := client.CreateInstance(req)
+	if err == nil {
+		err = op.Wait()
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed creating instance on cluster member %q: %w", target.Name, err)
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	reverter.Add(func() {
+		op, err := client.DeleteInstance(req.Name)
+		if err == nil {
+			_ = op.Wait()
+		}
+	})
+
+	// Recycled instances are restored to their state before their first start.
+	if pool.Release == "recycle" {
+		op, err = client.CreateInstanceSnapshot(req.Name, api.InstanceSnapshotsPost{Name: instancePoolSnapshotName})
+		if err == nil {
+			err = op.Wait()
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed creating snapshot of instance %q: %w", req.Name, err)
+		}
+	}
+
+	op, err = client.UpdateInstanceState(req.Name, api.InstanceStatePut{Action: "start"}, "")
+	if err == nil {
+		err = op.Wait()
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed starting instance %q: %w", req.Name, err)
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// instancePoolRemoveInstance stops and deletes an instance of an instance pool.
+func instancePoolRemoveInstance(s *state.State, projectName string, name string) error {
+	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, name, nil, instancetype.Any)
+	if err != nil {
+		return err
+	}
+
+	if client != nil {
+		client = client.UseProject(projectName)
+
+		op, err := client.UpdateInstanceState(name, api.InstanceStatePut{Action: "stop", Force: true}, "")
+		if err == nil {
+			_ = op.Wait()
+		}
+
+		op, err = client.DeleteInstance(name)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return err
+	}
+
+	if inst.IsRunning() {
+		err = inst.Stop(false)
+		if err != nil {
+			return fmt.Errorf("Failed stopping instance %q: %w", name, err)
+		}
+	}
+
+	return inst.Delete(true)
+}
+
+// instancePoolsFillTask fills the instance pools of all projects, on the cluster leader.
+func instancePoolsFillTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		leader, err := instancePoolLeader(d)
+		if err != nil || leader != "" {
+			return
+		}
+
+		pools := map[string][]string{}
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			projectNames, err := dbCluster.GetProjectNames(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			for _, projectName := range projectNames {
+				names, err := tx.GetInstancePoolNames(ctx, projectName)
+				if err != nil {
+					return err
+				}
+
+				if len(names) > 0 {
+					pools[projectName] = names
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Warn("Failed loading instance pools", logger.Ctx{"err": err})
+			return
+		}
+
+		for projectName, names := range pools {
+			for _, name := range names {
+				err := instancePoolFill(ctx, s, projectName, name)
+				if err != nil {
+					logger.Warn("Failed filling instance pool", logger.Ctx{"project": projectName, "pool": name, "err": err})
+				}
+			}
+		}
+	}
+
+	return f, task.Every(10 * time.Second)
+}
+
+// swagger:operation GET /1.0/instance-pools instance-pools instance_pools_get
+//
+//  Get the instance pools
+//
+//  Returns a list of instance pools (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/instance-pools/ci",
+//                "/1.0/instance-pools/builders"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instance-pools?recursion=1 instance-pools instance_pools_get_recursion1
+//
+//	Get the instance pools
+//
+//	Returns a list of instance pools (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of instance pools
+//	          items:
+//	            $ref: "#/definitions/InstancePool"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := localUtil.IsRecursionRequest(r)
+
+	resultString := []string{}
+	resultMap := []api.InstancePool{}
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		names, err := tx.GetInstancePoolNames(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if !recursion {
+				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "instance-pools", name).String())
+				continue
+			}
+
+			_, pool, _, err := instancePoolLoad(ctx, tx, projectName, name)
+			if err != nil {
+				return err
+			}
+
+			resultMap = append(resultMap, *pool)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/instance-pools instance-pools instance_pools_post
+//
+//	Add an instance pool
+//
+//	Creates a new instance pool and starts filling it with ready instances.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: pool
+//	    description: Instance pool
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstancePoolsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := instancePoolForwardToLeader(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName := request.ProjectParam(r)
+
+	req := api.InstancePoolsPost{}
+
+	// Parse the request.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Leave room for the suffix of the names of the instances.
+	err = validate.IsHostname(req.Name)
+	if err != nil || len(req.Name) > 54 {
+		return response.BadRequest(fmt.Errorf("Invalid instance pool name %q", req.Name))
+	}
+
+	if req.Profiles == nil {
+		req.Profiles = []string{"default"}
+	}
+
+	err = instancePoolValidate(req.InstancePoolPut)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, _, err := tx.GetInstancePoolByName(ctx, projectName, req.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "The instance pool already exists")
+		} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		_, err = tx.CreateInstancePool(ctx, projectName, req)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	instancePoolFillAsync(s, projectName, req.Name)
+
+	lc := lifecycle.InstancePoolCreated.Event(req.Name, projectName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/instance-pools/{name} instance-pools instance_pool_delete
+//
+//	Delete the instance pool
+//
+//	Removes the instance pool along with its ready instances. The acquired instances are kept.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := instancePoolForwardToLeader(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	unlock, err := locking.Lock(r.Context(), fmt.Sprintf("InstancePool_%s", project.Instance(projectName, name)))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	defer unlock()
+
+	var members []db.InstancePoolMember
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, poolMembers, err := instancePoolLoad(ctx, tx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		members = poolMembers
+
+		return tx.DeleteInstancePool(ctx, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, member := range members {
+		if member.Acquired {
+			continue
+		}
+
+		err := instancePoolRemoveInstance(s, projectName, member.Instance)
+		if err != nil {
+			logger.Warn("Failed deleting instance of deleted instance pool", logger.Ctx{"project": projectName, "pool": name, "instance": member.Instance, "err": err})
+		}
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstancePoolDeleted.Event(name, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/instance-pools/{name} instance-pools instance_pool_get
+//
+//	Get the instance pool
+//
+//	Gets a specific instance pool.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Instance pool
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstancePool"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var pool *api.InstancePool
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, pool, _, err = instancePoolLoad(ctx, tx, projectName, name)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, pool, pool.Writable())
+}
+
+// swagger:operation PATCH /1.0/instance-pools/{name} instance-pools instance_pool_patch
+//
+//  Partially update the instance pool
+//
+//  Updates a subset of the instance pool fields.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: body
+//      name: pool
+//      description: Instance pool
+//      required: true
+//      schema:
+//        $ref: "#/definitions/InstancePoolPut"
+//  responses:
+//    "200":
+//      $ref: "#/responses/EmptySyncResponse"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/instance-pools/{name} instance-pools instance_pool_put
+//
+//	Update the instance pool
+//
+//	Updates the entire instance pool.
+//	The existing instances are kept, the changes apply to the instances created afterwards.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: pool
+//	    description: Instance pool
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstancePoolPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := instancePoolForwardToLeader(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, pool, err := tx.GetInstancePoolByName(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		// Validate the ETag.
+		err = localUtil.EtagCheck(r, pool.Writable())
+		if err != nil {
+			return err
+		}
+
+		// With PATCH, the fields missing from the request keep their current value.
+		req := api.InstancePoolPut{}
+		if r.Method == http.MethodPatch {
+			req = pool.Writable()
+		}
+
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "%v", err)
+		}
+
+		if req.Profiles == nil {
+			req.Profiles = []string{}
+		}
+
+		err = instancePoolValidate(req)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "%v", err)
+		}
+
+		return tx.UpdateInstancePool(ctx, id, req)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	instancePoolFillAsync(s, projectName, name)
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstancePoolUpdated.Event(name, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/instance-pools/{name}/acquire instance-pools instance_pool_acquire_post
+//
+//	Acquire an instance
+//
+//	Hands out one of the ready instances of the instance pool, which is then replaced by a new one.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Acquired instance
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstancePoolMember"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+//	  "503":
+//	    description: No instance is ready
+func instancePoolAcquirePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := instancePoolForwardToLeader(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var member *db.InstancePoolMember
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetInstancePoolByName(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		member, err = tx.AcquireInstancePoolMember(ctx, id)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	instancePoolFillAsync(s, projectName, name)
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstancePoolAcquired.Event(name, projectName, request.CreateRequestor(r), map[string]any{"instance": member.Instance}))
+
+	return response.SyncResponse(true, api.InstancePoolMember{Instance: member.Instance, Location: member.Node})
+}
+
+// swagger:operation POST /1.0/instance-pools/{name}/release instance-pools instance_pool_release_post
+//
+//	Release an instance
+//
+//	Gives an acquired instance back to the instance pool, which recycles or deletes it following its release policy.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: release
+//	    description: Released instance
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstancePoolReleasePost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolReleasePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.InstancePoolReleasePost{}
+
+	// Parse the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Instance == "" || internalInstance.IsSnapshot(req.Instance) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name %q", req.Instance))
+	}
+
+	// Release the instance on the cluster member it's on.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, req.Instance, instancetype.Any)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	var id int64
+	var pool *api.InstancePool
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var members []db.InstancePoolMember
+
+		id, pool, members, err = instancePoolLoad(ctx, tx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			if member.Instance != req.Instance {
+				continue
+			}
+
+			if !member.Acquired {
+				return api.StatusErrorf(http.StatusBadRequest, "The instance isn't acquired")
+			}
+
+			return nil
+		}
+
+		return api.StatusErrorf(http.StatusNotFound, "Instance isn't part of the instance pool")
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, req.Instance)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(op *operations.Operation) error {
+		if inst.IsRunning() {
+			err := inst.Stop(false)
+			if err != nil {
+				return fmt.Errorf("Failed stopping instance: %w", err)
+			}
+		}
+
+		if pool.Release == "destroy" {
+			err := inst.Delete(true)
+			if err != nil {
+				return fmt.Errorf("Failed deleting instance: %w", err)
+			}
+		} else {
+			err := instancePoolRecycleInstance(s, id, inst)
+			if err != nil {
+				// Delete the instance rather than leaving it acquired forever, a new one replaces it.
+				removeErr := instancePoolRemoveInstance(s, projectName, req.Instance)
+				if removeErr != nil {
+					logger.Warn("Failed deleting instance which couldn't be recycled", logger.Ctx{"project": projectName, "pool": name, "instance": req.Instance, "err": removeErr})
+				}
+
+				leader, leaderErr := instancePoolLeader(d)
+				if leaderErr == nil && leader == "" {
+					instancePoolFillAsync(s, projectName, name)
+				}
+
+				return err
+			}
+		}
+
+		leader, err := instancePoolLeader(d)
+		if err == nil && leader == "" {
+			instancePoolFillAsync(s, projectName, name)
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.InstancePoolReleased.Event(name, projectName, op.Requestor(), map[string]any{"instance": req.Instance}))
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", req.Instance)}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstancePoolRelease, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// instancePoolRecycleInstance restores a released instance of an instance pool to its state before its first start,
+// starts it and makes it ready again.
+func instancePoolRecycleInstance(s *state.State, id int64, inst instance.Instance) error {
+	snap, err := instance.LoadByProjectAndName(s, inst.Project().Name, fmt.Sprintf("%s%s%s", inst.Name(), internalInstance.SnapshotDelimiter, instancePoolSnapshotName))
+	if err != nil {
+		return fmt.Errorf("Failed loading snapshot of instance: %w", err)
+	}
+
+	err = inst.Restore(snap, false)
+	if err != nil {
+		return fmt.Errorf("Failed restoring instance: %w", err)
+	}
+
+	err = inst.Start(false)
+	if err != nil {
+		return fmt.Errorf("Failed starting instance: %w", err)
+	}
+
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.ReleaseInstancePoolMember(ctx, id, inst.Name())
+	})
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestInstancePoolCreateRequest(t *testing.T) {
+	pool := &api.InstancePool{
+		Name:    "ci",
+		Project: "builds",
+		InstancePoolPut: api.InstancePoolPut{
+			Image:    "debian/12",
+			Type:     api.InstanceTypeVM,
+			Profiles: []string{"default", "ci"},
+			Size:     2,
+			Release:  "recycle",
+		},
+	}
+
+	img := &api.Image{Fingerprint: "abcdef0123456789", Architecture: "x86_64"}
+
+	req := instancePoolCreateRequest(pool, "ci-1234abcd", img)
+	require.Equal(t, "ci-1234abcd", req.Name)
+	require.Equal(t, api.InstanceTypeVM, req.Type)
+	require.Equal(t, api.InstanceSource{Type: "image", Fingerprint: "abcdef0123456789"}, req.Source)
+	require.Equal(t, "x86_64", req.Architecture)
+	require.Equal(t, []string{"default", "ci"}, req.Profiles)
+	require.Empty(t, req.Config)
+	require.Empty(t, req.Devices)
+
+	// The request doesn't share the profiles of the instance pool.
+	req.Profiles[0] = "other"
+	require.Equal(t, "default", pool.Profiles[0])
+}
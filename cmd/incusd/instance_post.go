@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"github.com/lxc/incus/v6/client"
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/cluster"
 	"github.com/lxc/incus/v6/internal/server/db"
@@ -21,6 +23,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/scheduler"
 	"github.com/lxc/incus/v6/internal/server/scriptlet"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
@@ -30,6 +33,10 @@ import (
 	"github.com/lxc/incus/v6/shared/logger"
 )
 
+// instanceMoveShutdownTimeout is how long a running instance is given to shut down before the final sync of a live
+// storage pool change.
+const instanceMoveShutdownTimeout = 5 * time.Minute
+
 // swagger:operation POST /1.0/instances/{name} instances instance_post
 //
 //	Rename or move/migrate an instance
@@ -184,15 +191,23 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 
 		// Check that the new isn't already in use.
 		var id int
+		var renamed string
 		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 			// Check that the name isn't already in use.
 			id, _ = tx.GetInstanceID(ctx, instProject, req.Name)
 
+			// Check that the name isn't still used by a container renamed while running.
+			renamed, _ = tx.GetInstanceByRuntimeName(ctx, instProject, req.Name)
+
 			return nil
 		})
 		if id > 0 {
 			return response.Conflict(fmt.Errorf("Name %q already in use", req.Name))
 		}
+
+		if renamed != "" && renamed != name {
+			return response.Conflict(fmt.Errorf("Name %q is still in use by the running instance %q", req.Name, renamed))
+		}
 	}
 
 	// Load the local instance.
@@ -230,9 +245,14 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			return response.BadRequest(fmt.Errorf("Instance must be stopped to be moved statelessly"))
 		}
 
-		// Storage pool changes require a stopped instance.
-		if req.Pool != "" {
-			return response.BadRequest(fmt.Errorf("Instance must be stopped to be moved across storage pools"))
+		// Storage pool changes of a running instance are only possible on the same server.
+		if req.Pool != "" && target != "" {
+			return response.BadRequest(fmt.Errorf("Instance must be stopped to be moved across storage pools and servers at once"))
+		}
+
+		// Ephemeral instances are deleted when stopped during the final sync of a storage pool change.
+		if req.Pool != "" && inst.IsEphemeral() {
+			return response.BadRequest(fmt.Errorf("Ephemeral instances must be stopped to be moved across storage pools"))
 		}
 
 		// Project changes require a stopped instance.
@@ -286,7 +306,10 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			// Check if the current location is fine.
 			targetMemberInfo, _, err = project.CheckTarget(ctx, s.Authorizer, r, tx, targetProject, instLocation, allMembers)
 			if err == nil && targetMemberInfo != nil {
-				return nil
+				// Check that the requested cluster member satisfies the policy of the instance group.
+				_, err = instanceGroupFilterMembers(ctx, tx, inst.Project().Name, inst.Name(), inst.ExpandedConfig(), []db.NodeInfo{*targetMemberInfo}, "")
+
+				return err
 			}
 
 			// If we must change location, validate access to requested member/group (if provided).
@@ -295,6 +318,13 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
+			if targetMemberInfo != nil {
+				_, err = instanceGroupFilterMembers(ctx, tx, inst.Project().Name, inst.Name(), inst.ExpandedConfig(), []db.NodeInfo{*targetMemberInfo}, "")
+				if err != nil {
+					return err
+				}
+			}
+
 			// If no specific server, get a list of allowed candidates.
 			if targetMemberInfo == nil {
 				clusterGroupsAllowed := project.GetRestrictedClusterGroups(targetProject)
@@ -303,6 +333,11 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				if err != nil {
 					return err
 				}
+
+				targetCandidates, err = instanceGroupFilterMembers(ctx, tx, inst.Project().Name, inst.Name(), inst.ExpandedConfig(), targetCandidates, "")
+				if err != nil {
+					return err
+				}
 			}
 
 			return nil
@@ -311,8 +346,9 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
-		// If no specific server and a placement scriplet exists, call it with the candidates.
-		if targetMemberInfo == nil && s.GlobalConfig.InstancesPlacementScriptlet() != "" {
+		// If no specific server and a placement scriplet or external placement exists, call it with the candidates.
+		externalEndpoint, _, _ := s.GlobalConfig.SchedulerExternal()
+		if targetMemberInfo == nil && (s.GlobalConfig.InstancesPlacementScriptlet() != "" || externalEndpoint != "") {
 			leaderAddress, err := d.gateway.LeaderAddress()
 			if err != nil {
 				return response.InternalError(err)
@@ -331,9 +367,15 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				Reason:  apiScriptlet.InstancePlacementReasonRelocation,
 			}
 
-			targetMemberInfo, err = scriptlet.InstancePlacementRun(r.Context(), logger.Log, s, &req, targetCandidates, leaderAddress)
-			if err != nil {
-				return response.BadRequest(fmt.Errorf("Failed instance placement scriptlet: %w", err))
+			if s.GlobalConfig.InstancesPlacementScriptlet() != "" {
+				targetMemberInfo, err = scriptlet.InstancePlacementRun(r.Context(), logger.Log, s, &req, targetCandidates, leaderAddress)
+				if err != nil {
+					return response.BadRequest(fmt.Errorf("Failed instance placement scriptlet: %w", err))
+				}
+			}
+
+			if targetMemberInfo == nil {
+				targetMemberInfo = scheduler.ExternalPlacementRun(r.Context(), logger.Log, s, &req, targetCandidates, leaderAddress)
 			}
 		}
 
@@ -646,16 +688,20 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 			}
 		}
 
+		reverter := revert.New()
+		defer reverter.Fail()
+
 		// Create the target instance.
 		destOp, err := target.CreateInstance(api.InstancesPost{
 			Name:        targetInstName,
 			InstancePut: targetInstInfo.Writable(),
 			Type:        api.InstanceType(targetInstInfo.Type),
 			Source: api.InstanceSource{
-				Type:         "copy",
-				Source:       inst.Name(),
-				Project:      inst.Project().Name,
-				InstanceOnly: req.InstanceOnly,
+				Type:              "copy",
+				Source:            inst.Name(),
+				Project:           inst.Project().Name,
+				InstanceOnly:      req.InstanceOnly,
+				AllowInconsistent: req.Live,
 			},
 		})
 		if err != nil {
@@ -672,18 +718,75 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 			return err
 		}
 
+		// Remove the copy if the move doesn't complete, including one partially created by a failed copy.
+		reverter.Add(func() {
+			deleteOp, err := target.DeleteInstance(targetInstName)
+			if err == nil {
+				err = deleteOp.Wait()
+			}
+
+			if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+				logger.Warn("Failed deleting instance copy after failed move", logger.Ctx{"project": targetProject, "instance": targetInstName, "err": err})
+			}
+		})
+
 		// Wait for the migration to complete.
 		err = destOp.Wait()
 		if err != nil {
 			return fmt.Errorf("Instance move to destination failed: %w", err)
 		}
 
+		// For running instances, the copy was made while in use, so stop the instance and sync the changes
+		// made in the meantime. The instance is started again once moved.
+		if req.Live {
+			err = inst.Shutdown(instanceMoveShutdownTimeout)
+			if err != nil {
+				err = inst.Stop(false)
+				if err != nil {
+					return fmt.Errorf("Failed stopping instance: %w", err)
+				}
+			}
+
+			destOp, err = target.CreateInstance(api.InstancesPost{
+				Name:        targetInstName,
+				InstancePut: targetInstInfo.Writable(),
+				Type:        api.InstanceType(targetInstInfo.Type),
+				Source: api.InstanceSource{
+					Type:         "copy",
+					Source:       inst.Name(),
+					Project:      inst.Project().Name,
+					InstanceOnly: req.InstanceOnly,
+					Refresh:      true,
+				},
+			})
+			if err == nil {
+				_, err = destOp.AddHandler(handler)
+				if err == nil {
+					err = destOp.Wait()
+				}
+			}
+
+			if err != nil {
+				// Keep the instance running on its current pool.
+				_ = inst.Start(false)
+
+				return fmt.Errorf("Instance refresh on destination failed: %w", err)
+			}
+		}
+
 		// Delete the source instance.
 		err = inst.Delete(true)
 		if err != nil {
+			if req.Live {
+				_ = inst.Start(false)
+			}
+
 			return err
 		}
 
+		// The source is gone, so the copy is now the instance.
+		reverter.Success()
+
 		// If using a temporary name, rename it.
 		if targetInstName != inst.Name() {
 			op, err := target.RenameInstance(targetInstName, api.InstancePost{Name: inst.Name()})
@@ -703,6 +806,16 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 			return err
 		}
 
+		// Start the instance again after a live storage pool change.
+		if req.Live {
+			err = inst.Start(false)
+			if err != nil {
+				return fmt.Errorf("Failed starting instance after move: %w", err)
+			}
+
+			req.Live = false
+		}
+
 		// Clear the pool and project part of the request.
 		req.Pool = ""
 		req.Project = ""
@@ -147,12 +147,25 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 				apiProfiles = append(apiProfiles, *apiProfile)
 			}
 
+			// Check that the instance location satisfies the policy of its new instance group.
+			if s.ServerClustered {
+				err = instanceGroupCheckLocation(ctx, tx, inst, db.ExpandInstanceConfig(configRaw.Config, apiProfiles))
+				if err != nil {
+					return err
+				}
+			}
+
 			return projecthelpers.AllowInstanceUpdate(tx, projectName, name, configRaw, inst.LocalConfig())
 		})
 		if err != nil {
 			return response.SmartError(err)
 		}
 
+		err = instanceRefreshConfigCheck(s, r, inst.LocalConfig(), configRaw.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Update container configuration
 		do = func(op *operations.Operation) error {
 			defer unlock()
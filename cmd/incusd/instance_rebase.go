@@ -0,0 +1,656 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	incus "github.com/lxc/incus/v6/client"
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/rsync"
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/subprocess"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// instanceRebaseShutdownTimeout is how long a running instance is given to shut down before being rebased.
+const instanceRebaseShutdownTimeout = 5 * time.Minute
+
+type internalImageRebasePost struct {
+	Project        string `json:"project"         yaml:"project"`
+	OldFingerprint string `json:"old_fingerprint" yaml:"old_fingerprint"`
+	NewFingerprint string `json:"new_fingerprint" yaml:"new_fingerprint"`
+}
+
+func internalRebaseImage(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	req := internalImageRebasePost{}
+
+	// Parse the request.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = instancesRebaseLocal(s, req.Project, req.OldFingerprint, req.NewFingerprint)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// instancesAutoRebase has all cluster members rebase their instances using the old image and having
+// image.auto_rebase enabled onto the new image.
+func instancesAutoRebase(s *state.State, projectName string, oldFingerprint string, newFingerprint string) error {
+	req := internalImageRebasePost{
+		Project:        projectName,
+		OldFingerprint: oldFingerprint,
+		NewFingerprint: newFingerprint,
+	}
+
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	err = notifier(func(client incus.InstanceServer) error {
+		_, _, err := client.RawQuery("POST", "/internal/image-rebase", req, "")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return instancesRebaseLocal(s, projectName, oldFingerprint, newFingerprint)
+}
+
+// instancesRebaseLocal starts an operation rebasing the local instances using the old image and having
+// image.auto_rebase enabled onto the new image.
+func instancesRebaseLocal(s *state.State, projectName string, oldFingerprint string, newFingerprint string) error {
+	instances, err := instance.LoadNodeAll(s, instancetype.Container)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	var rebaseInstances []instance.Instance
+	for _, inst := range instances {
+		if inst.Project().Name != projectName || inst.LocalConfig()["volatile.base_image"] != oldFingerprint || util.IsFalseOrEmpty(inst.ExpandedConfig()["image.auto_rebase"]) {
+			continue
+		}
+
+		// Stopping an ephemeral instance deletes it.
+		if inst.IsEphemeral() {
+			logger.Warn("Skipping rebase of ephemeral instance", logger.Ctx{"project": projectName, "instance": inst.Name()})
+			continue
+		}
+
+		rebaseInstances = append(rebaseInstances, inst)
+	}
+
+	if len(rebaseInstances) == 0 {
+		return nil
+	}
+
+	var img *api.Image
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, img, err = tx.GetImage(ctx, newFingerprint, dbCluster.ImageFilter{Project: &projectName})
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading image %q: %w", newFingerprint, err)
+	}
+
+	resources := map[string][]api.URL{}
+	for _, inst := range rebaseInstances {
+		resources["instances"] = append(resources["instances"], *api.NewURL().Path(version.APIVersion, "instances", inst.Name()).Project(projectName))
+	}
+
+	run := func(op *operations.Operation) error {
+		for _, inst := range rebaseInstances {
+			l := logger.AddContext(logger.Ctx{"project": projectName, "instance": inst.Name(), "fingerprint": img.Fingerprint})
+
+			l.Info("Rebasing instance onto updated image")
+			err := instanceRebase(s, inst, img, op)
+			if err != nil {
+				l.Error("Failed rebasing instance", logger.Ctx{"err": err})
+				continue
+			}
+
+			l.Info("Rebased instance onto updated image")
+		}
+
+		return nil
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceRebuild, resources, nil, run, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return op.Start()
+}
+
+// instanceRebase snapshots an instance, rebuilds it from the image and replays the overlay of the paths changed
+// since it was last created or rebuilt from an image. On failure, the instance is restored from the snapshot and
+// started again if it was running.
+func instanceRebase(s *state.State, inst instance.Instance, img *api.Image, op *operations.Operation) error {
+	wasRunning := inst.IsRunning()
+
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	// Keep the current state around.
+	snapName := fmt.Sprintf("rebase-%s", time.Now().UTC().Format("20060102-150405"))
+	err := inst.Snapshot(snapName, time.Time{}, false)
+	if err != nil {
+		return fmt.Errorf("Failed creating snapshot: %w", err)
+	}
+
+	snap, err := instance.LoadByProjectAndName(s, inst.Project().Name, fmt.Sprintf("%s/%s", inst.Name(), snapName))
+	if err != nil {
+		return fmt.Errorf("Failed loading snapshot: %w", err)
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if wasRunning {
+		err = inst.Shutdown(instanceRebaseShutdownTimeout)
+		if err != nil {
+			err = inst.Stop(false)
+			if err != nil {
+				return fmt.Errorf("Failed stopping instance: %w", err)
+			}
+		}
+
+		reverter.Add(func() {
+			err := inst.Start(false)
+			if err != nil {
+				l.Error("Failed starting instance after failed rebase", logger.Ctx{"err": err})
+			}
+		})
+	}
+
+	changed, removed, err := instanceRebaseOverlay(s, snap, instanceRebasePaths(inst.ExpandedConfig()["image.auto_rebase.paths"]), op)
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() {
+		err := inst.Restore(snap, false)
+		if err != nil {
+			l.Error("Failed restoring instance after failed rebase", logger.Ctx{"snapshot": snapName, "err": err})
+		}
+	})
+
+	// Rebuilding records the content of the new image as the base of the next rebase.
+	err = instanceRebuildFromImage(context.TODO(), s, nil, inst, img, op)
+	if err != nil {
+		return err
+	}
+
+	if len(changed) > 0 || len(removed) > 0 {
+		err = instanceRebaseReplay(s, inst, snap, changed, removed, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	if wasRunning {
+		err = inst.Start(false)
+		if err != nil {
+			return fmt.Errorf("Failed starting instance: %w", err)
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// instanceRebaseManifestFile is the file, next to the root file system of a container, recording the content of
+// the root file system as created from its image.
+const instanceRebaseManifestFile = "rebase.manifest"
+
+// instanceRebaseEntry is the recorded state of a path of the root file system of a container.
+type instanceRebaseEntry struct {
+	Mode    fs.FileMode `json:"mode"`
+	Size    int64       `json:"size,omitempty"`
+	ModTime int64       `json:"mtime,omitempty"`
+	Target  string      `json:"target,omitempty"`
+}
+
+// instanceRebaseRecord records the content of the root file system of a container having image.auto_rebase
+// enabled, right after it got created or rebuilt from an image. The paths changed afterwards make up the
+// overlay replayed when the container is rebased.
+func instanceRebaseRecord(s *state.State, inst instance.Instance, op *operations.Operation) error {
+	if inst.Type() != instancetype.Container || util.IsFalseOrEmpty(inst.ExpandedConfig()["image.auto_rebase"]) {
+		return nil
+	}
+
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return err
+	}
+
+	_, err = pool.MountInstance(inst, op)
+	if err != nil {
+		return fmt.Errorf("Failed mounting instance: %w", err)
+	}
+
+	defer func() { _ = pool.UnmountInstance(inst, op) }()
+
+	manifest, err := instanceRebaseManifest(inst.RootfsPath())
+	if err != nil {
+		return fmt.Errorf("Failed recording root file system content: %w", err)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(inst.Path(), instanceRebaseManifestFile), data, 0600)
+}
+
+// instanceRebaseManifest returns the state of all the paths of a root file system.
+func instanceRebaseManifest(rootfs string) (map[string]instanceRebaseEntry, error) {
+	manifest := map[string]instanceRebaseEntry{}
+
+	err := filepath.WalkDir(rootfs, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == rootfs {
+			return nil
+		}
+
+		entry, err := instanceRebaseEntryGet(path)
+		if err != nil {
+			return err
+		}
+
+		manifest["/"+strings.TrimPrefix(path, rootfs+"/")] = entry
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// instanceRebaseEntryGet returns the state of a path. Only the type and mode of directories are recorded as
+// their size and modification time change along with their content.
+func instanceRebaseEntryGet(path string) (instanceRebaseEntry, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return instanceRebaseEntry{}, err
+	}
+
+	entry := instanceRebaseEntry{Mode: info.Mode()}
+	if info.IsDir() {
+		return entry, nil
+	}
+
+	entry.Size = info.Size()
+	entry.ModTime = info.ModTime().UnixNano()
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		entry.Target, err = os.Readlink(path)
+		if err != nil {
+			return instanceRebaseEntry{}, err
+		}
+	}
+
+	return entry, nil
+}
+
+// instanceRebaseDiff compares a root file system with its recorded content, returning the paths which were
+// changed or added, and the ones which were removed. New directories are returned as a whole rather than
+// their content. Only the paths within the given ones are considered, if any.
+func instanceRebaseDiff(manifest map[string]instanceRebaseEntry, rootfs string, paths []string) ([]string, []string, error) {
+	selected := func(path string) bool {
+		if len(paths) == 0 {
+			return true
+		}
+
+		for _, p := range paths {
+			if path == p || strings.HasPrefix(path, p+"/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	changed := []string{}
+	seen := map[string]bool{}
+
+	err := filepath.WalkDir(rootfs, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == rootfs {
+			return nil
+		}
+
+		relPath := "/" + strings.TrimPrefix(path, rootfs+"/")
+		seen[relPath] = true
+
+		entry, err := instanceRebaseEntryGet(path)
+		if err != nil {
+			return err
+		}
+
+		recorded, ok := manifest[relPath]
+		if ok && recorded == entry {
+			return nil
+		}
+
+		if selected(relPath) {
+			changed = append(changed, relPath)
+		}
+
+		if d.IsDir() {
+			// Directories added or replacing something else are carried over as a whole.
+			if selected(relPath) {
+				return fs.SkipDir
+			}
+
+			// Look for the selected paths within the directory.
+			for _, p := range paths {
+				if strings.HasPrefix(p, relPath+"/") {
+					return nil
+				}
+			}
+
+			return fs.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Skip the content of the changed directories, they get replaced as a whole.
+	replaced := func(path string) bool {
+		for _, p := range changed {
+			if strings.HasPrefix(path, p+"/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	removed := []string{}
+	for path := range manifest {
+		if seen[path] || !selected(path) || replaced(path) {
+			continue
+		}
+
+		// Only keep the top-most removed path.
+		_, parentRecorded := manifest[filepath.Dir(path)]
+		if parentRecorded && !seen[filepath.Dir(path)] && selected(filepath.Dir(path)) {
+			continue
+		}
+
+		removed = append(removed, path)
+	}
+
+	sort.Strings(removed)
+
+	return changed, removed, nil
+}
+
+// instanceRebaseOverlay returns the paths of the snapshot of a container which were changed or removed since it
+// was last created or rebuilt from an image.
+func instanceRebaseOverlay(s *state.State, snap instance.Instance, paths []string, op *operations.Operation) ([]string, []string, error) {
+	pool, err := storagePools.LoadByInstance(s, snap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = pool.MountInstanceSnapshot(snap, op)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed mounting snapshot: %w", err)
+	}
+
+	defer func() { _ = pool.UnmountInstanceSnapshot(snap, op) }()
+
+	data, err := os.ReadFile(filepath.Join(snap.Path(), instanceRebaseManifestFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fmt.Errorf("No recorded content to rebase from, the instance must be created or rebuilt with image.auto_rebase enabled")
+		}
+
+		return nil, nil, err
+	}
+
+	manifest := map[string]instanceRebaseEntry{}
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed parsing recorded content: %w", err)
+	}
+
+	changed, removed, err := instanceRebaseDiff(manifest, snap.RootfsPath(), paths)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed comparing root file system with its recorded content: %w", err)
+	}
+
+	return changed, removed, nil
+}
+
+// instanceRebasePaths parses the list of paths to keep when rebasing an instance.
+func instanceRebasePaths(value string) []string {
+	var paths []string
+
+	for _, path := range strings.Split(value, ",") {
+		path = filepath.Clean(strings.TrimSpace(path))
+		if path == "." || path == "/" {
+			continue
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+// instanceRebaseReplay copies the changed paths from the snapshot into the root file system of the instance and
+// deletes the removed ones from it. The files are staged first so their ownership can be translated from the disk
+// idmap of the snapshot to the one of the rebuilt root file system, without having to start the instance.
+func instanceRebaseReplay(s *state.State, inst instance.Instance, snap instance.Instance, changed []string, removed []string, op *operations.Operation) error {
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return err
+	}
+
+	snapIdmap, err := snap.(instance.Container).DiskIdmap()
+	if err != nil {
+		return fmt.Errorf("Failed getting snapshot idmap: %w", err)
+	}
+
+	instIdmap, err := inst.(instance.Container).DiskIdmap()
+	if err != nil {
+		return fmt.Errorf("Failed getting instance idmap: %w", err)
+	}
+
+	_, err = pool.MountInstanceSnapshot(snap, op)
+	if err != nil {
+		return fmt.Errorf("Failed mounting snapshot: %w", err)
+	}
+
+	defer func() { _ = pool.UnmountInstanceSnapshot(snap, op) }()
+
+	_, err = pool.MountInstance(inst, op)
+	if err != nil {
+		return fmt.Errorf("Failed mounting instance: %w", err)
+	}
+
+	defer func() { _ = pool.UnmountInstance(inst, op) }()
+
+	// Stage the files on the instance volume, next to its root file system.
+	staging := filepath.Join(inst.Path(), "rebase")
+	err = os.RemoveAll(staging)
+	if err != nil {
+		return err
+	}
+
+	err = os.Mkdir(staging, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed creating staging directory: %w", err)
+	}
+
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	copyPath := func(source string, target string) error {
+		info, err := os.Lstat(source)
+		if err != nil {
+			return err
+		}
+
+		// Replace whatever is in the way.
+		targetInfo, err := os.Lstat(target)
+		if err == nil && (!info.IsDir() || !targetInfo.IsDir()) {
+			err = os.RemoveAll(target)
+			if err != nil {
+				return err
+			}
+		}
+
+		if info.IsDir() {
+			_, err = rsync.LocalCopy(source, target, "", true)
+
+			return err
+		}
+
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+
+		_, err = subprocess.RunCommand("cp", "-a", source, target)
+
+		return err
+	}
+
+	for _, path := range removed {
+		err = instanceRebaseCheckPath(inst.RootfsPath(), path)
+		if err != nil {
+			return fmt.Errorf("Failed removing %q: %w", path, err)
+		}
+
+		err = os.RemoveAll(filepath.Join(inst.RootfsPath(), path))
+		if err != nil {
+			return fmt.Errorf("Failed removing %q: %w", path, err)
+		}
+	}
+
+	staged := []string{}
+	for _, path := range changed {
+		// Don't follow symlinks out of the root file systems.
+		err = instanceRebaseCheckPath(snap.RootfsPath(), path)
+		if err == nil {
+			err = instanceRebaseCheckPath(inst.RootfsPath(), path)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed restoring %q: %w", path, err)
+		}
+
+		source := filepath.Join(snap.RootfsPath(), path)
+		if !util.PathExists(source) {
+			continue
+		}
+
+		err = copyPath(source, filepath.Join(staging, path))
+		if err != nil {
+			return fmt.Errorf("Failed restoring %q: %w", path, err)
+		}
+
+		staged = append(staged, path)
+	}
+
+	if len(staged) == 0 {
+		return nil
+	}
+
+	if snapIdmap != nil {
+		err = snapIdmap.UnshiftPath(staging, nil)
+		if err != nil {
+			return fmt.Errorf("Failed unshifting restored paths: %w", err)
+		}
+	}
+
+	if instIdmap != nil {
+		err = instIdmap.ShiftPath(staging, nil)
+		if err != nil {
+			return fmt.Errorf("Failed shifting restored paths: %w", err)
+		}
+	}
+
+	for _, path := range staged {
+		err = copyPath(filepath.Join(staging, path), filepath.Join(inst.RootfsPath(), path))
+		if err != nil {
+			return fmt.Errorf("Failed restoring %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// instanceRebaseCheckPath checks that none of the parent directories of the path within root are symlinks.
+func instanceRebaseCheckPath(root string, path string) error {
+	current := root
+
+	for _, component := range strings.Split(filepath.Dir(path), "/") {
+		if component == "" {
+			continue
+		}
+
+		current = filepath.Join(current, component)
+
+		info, err := os.Lstat(current)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("Path %q goes through a symlink", path)
+		}
+	}
+
+	return nil
+}
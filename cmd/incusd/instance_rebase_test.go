@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceRebaseDiff(t *testing.T) {
+	rootfs := t.TempDir()
+
+	for _, dir := range []string{"etc/app", "usr/bin", "var/lib/old"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(rootfs, dir), 0755))
+	}
+
+	for _, file := range []string{"etc/hostname", "etc/app/app.conf", "usr/bin/app", "var/lib/old/data"} {
+		require.NoError(t, os.WriteFile(filepath.Join(rootfs, file), []byte("image"), 0644))
+	}
+
+	require.NoError(t, os.Symlink("app", filepath.Join(rootfs, "usr/bin/app-link")))
+
+	manifest, err := instanceRebaseManifest(rootfs)
+	require.NoError(t, err)
+	assert.Contains(t, manifest, "/etc/app/app.conf")
+	assert.Equal(t, "app", manifest["/usr/bin/app-link"].Target)
+
+	// Nothing changed yet.
+	changed, removed, err := instanceRebaseDiff(manifest, rootfs, nil)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Empty(t, removed)
+
+	// Change a file, add a directory, replace a symlink and remove a directory.
+	modTime := time.Now().Add(time.Hour)
+	require.NoError(t, os.WriteFile(filepath.Join(rootfs, "etc/app/app.conf"), []byte("local"), 0644))
+	require.NoError(t, os.Chtimes(filepath.Join(rootfs, "etc/app/app.conf"), modTime, modTime))
+	require.NoError(t, os.MkdirAll(filepath.Join(rootfs, "home/user"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootfs, "home/user/notes"), []byte("local"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(rootfs, "usr/bin/app-link")))
+	require.NoError(t, os.Symlink("/opt/app", filepath.Join(rootfs, "usr/bin/app-link")))
+	require.NoError(t, os.RemoveAll(filepath.Join(rootfs, "var/lib/old")))
+
+	changed, removed, err = instanceRebaseDiff(manifest, rootfs, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"/etc/app/app.conf", "/home", "/usr/bin/app-link"}, changed)
+	assert.Equal(t, []string{"/var/lib/old"}, removed)
+
+	// Only the changes within the given paths are kept.
+	changed, removed, err = instanceRebaseDiff(manifest, rootfs, []string{"/etc", "/home/user"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"/etc/app/app.conf", "/home/user"}, changed)
+	assert.Empty(t, removed)
+}
@@ -0,0 +1,693 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/robfig/cron/v3"
+
+	incus "github.com/lxc/incus/v6/client"
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	projecthelpers "github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/task"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	"github.com/lxc/incus/v6/internal/server/warnings"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/osarch"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+var instanceRefreshesCmd = APIEndpoint{
+	Path: "instance-refreshes",
+
+	Get: APIEndpointAction{Handler: instanceRefreshesGet, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+}
+
+var instanceRefreshCmd = APIEndpoint{
+	Name: "instanceRefresh",
+	Path: "instances/{name}/refresh",
+
+	Get: APIEndpointAction{Handler: instanceRefreshGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "name")},
+}
+
+// swagger:operation GET /1.0/instance-refreshes instances instance_refreshes_get
+//
+//	Get the scheduled refreshes
+//
+//	Returns a list of the scheduled refreshes of the instances (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/instances/staging-web/refresh",
+//	              "/1.0/instances/staging-db/refresh"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instance-refreshes?recursion=1 instances instance_refreshes_get_recursion1
+//
+//	Get the scheduled refreshes
+//
+//	Returns a list of the scheduled refreshes of the instances (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of scheduled refreshes
+//	          items:
+//	            $ref: "#/definitions/InstanceRefresh"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceRefreshesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := localUtil.IsRecursionRequest(r)
+
+	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, auth.ObjectTypeInstance)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	now := time.Now()
+	refreshes := []api.InstanceRefresh{}
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			if !userHasPermission(auth.ObjectInstance(inst.Project, inst.Name)) {
+				return nil
+			}
+
+			refresh := instanceRefreshJob(inst.Name, inst.Project, inst.Node, inst.ID, db.ExpandInstanceConfig(inst.Config, inst.Profiles), now)
+			if refresh != nil {
+				refreshes = append(refreshes, *refresh)
+			}
+
+			return nil
+		}, cluster.InstanceFilter{Project: &projectName})
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !recursion {
+		urls := make([]string, 0, len(refreshes))
+		for _, refresh := range refreshes {
+			urls = append(urls, api.NewURL().Path(version.APIVersion, "instances", refresh.Name, "refresh").String())
+		}
+
+		return response.SyncResponse(true, urls)
+	}
+
+	return response.SyncResponse(true, refreshes)
+}
+
+// swagger:operation GET /1.0/instances/{name}/refresh instances instance_refresh_get
+//
+//	Get the scheduled refresh
+//
+//	Gets the scheduled refresh of the instance from its source instance, along with the outcome of the last one.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Scheduled refresh
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceRefresh"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceRefreshGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	refresh := instanceRefreshJob(inst.Name(), inst.Project().Name, inst.Location(), inst.ID(), inst.ExpandedConfig(), time.Now())
+	if refresh == nil {
+		return response.NotFound(fmt.Errorf("Instance %q has no scheduled refresh", name))
+	}
+
+	return response.SyncResponse(true, refresh)
+}
+
+// instanceRefreshScheduled returns whether the expanded configuration of an instance schedules its refresh.
+func instanceRefreshScheduled(config map[string]string) bool {
+	return config["copy.refresh.schedule"] != "" && config["copy.refresh.server"] != "" && config["copy.refresh.source"] != ""
+}
+
+// instanceRefreshNext returns the time of the next scheduled refresh of an instance after the given time.
+func instanceRefreshNext(schedule string, instanceID int, now time.Time) time.Time {
+	var next time.Time
+	for _, spec := range buildCronSpecs(schedule, int64(instanceID)) {
+		sched, err := cron.ParseStandard(spec)
+		if err != nil {
+			continue
+		}
+
+		specNext := sched.Next(now)
+		if next.IsZero() || specNext.Before(next) {
+			next = specNext
+		}
+	}
+
+	return next
+}
+
+// instanceRefreshJob returns the scheduled refresh of an instance from its expanded configuration, or nil if its
+// refresh isn't scheduled.
+func instanceRefreshJob(name string, projectName string, location string, instanceID int, config map[string]string, now time.Time) *api.InstanceRefresh {
+	if !instanceRefreshScheduled(config) {
+		return nil
+	}
+
+	refresh := api.InstanceRefresh{
+		Name:          name,
+		Project:       projectName,
+		Location:      location,
+		Schedule:      config["copy.refresh.schedule"],
+		Server:        config["copy.refresh.server"],
+		SourceProject: config["copy.refresh.project"],
+		Source:        config["copy.refresh.source"],
+		InstanceOnly:  util.IsTrue(config["copy.refresh.instance_only"]),
+		Status:        config["volatile.copy.refresh.status"],
+		Error:         config["volatile.copy.refresh.error"],
+		NextRefreshAt: instanceRefreshNext(config["copy.refresh.schedule"], instanceID, now),
+	}
+
+	if refresh.SourceProject == "" {
+		refresh.SourceProject = api.ProjectDefaultName
+	}
+
+	if refresh.Status == "" {
+		refresh.Status = "pending"
+	}
+
+	refresh.LastRefreshAt, _ = time.Parse(time.RFC3339, config["volatile.copy.refresh.last"])
+
+	return &refresh
+}
+
+func autoRefreshInstancesTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		allInstances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Error("Failed loading instances for scheduled refresh task", logger.Ctx{"err": err})
+			return
+		}
+
+		var instances []instance.Instance
+		for _, inst := range allInstances {
+			// No refresh runs between two runs of the task, so a refresh recorded as running was interrupted by
+			// a restart of the daemon.
+			if inst.LocalConfig()["volatile.copy.refresh.status"] == "running" {
+				instanceRefreshRecord(s, inst, fmt.Errorf("Refresh interrupted by a restart of the server"))
+			}
+
+			config := inst.ExpandedConfig()
+
+			if !instanceRefreshScheduled(config) {
+				continue
+			}
+
+			// Check if the refresh is scheduled.
+			if !snapshotIsScheduledNow(config["copy.refresh.schedule"], int64(inst.ID())) {
+				continue
+			}
+
+			instances = append(instances, inst)
+		}
+
+		if len(instances) == 0 {
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			return autoRefreshInstances(ctx, s, instances, op)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.InstanceRefresh, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating scheduled instance refresh operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Refreshing scheduled instances")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting scheduled instance refresh operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed scheduled instance refreshes", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done refreshing scheduled instances")
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+func autoRefreshInstances(ctx context.Context, s *state.State, instances []instance.Instance, op *operations.Operation) error {
+	// Refresh the instances sequentially, a failed refresh doesn't prevent the others.
+	for _, inst := range instances {
+		err := ctx.Err()
+		if err != nil {
+			return err // Stop if context is cancelled.
+		}
+
+		err = inst.VolatileSet(map[string]string{"volatile.copy.refresh.status": "running"})
+		if err != nil {
+			logger.Warn("Failed recording the scheduled refresh of the instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+		}
+
+		refreshErr := instanceRefreshFromSource(ctx, s, inst, op)
+		if refreshErr != nil {
+			logger.Error("Failed scheduled instance refresh", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": refreshErr})
+		}
+
+		instanceRefreshRecord(s, inst, refreshErr)
+	}
+
+	return nil
+}
+
+// instanceRefreshRecord records the outcome of the scheduled refresh of an instance in its volatile keys, and raises
+// a warning for failures until the next successful refresh.
+func instanceRefreshRecord(s *state.State, inst instance.Instance, refreshErr error) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	if refreshErr != nil {
+		err := inst.VolatileSet(map[string]string{
+			"volatile.copy.refresh.status": "failure",
+			"volatile.copy.refresh.error":  refreshErr.Error(),
+		})
+		if err != nil {
+			l.Warn("Failed recording the scheduled refresh of the instance", logger.Ctx{"err": err})
+		}
+
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, cluster.TypeInstance, inst.ID(), warningtype.InstanceRefreshFailure, refreshErr.Error())
+		})
+		if err != nil {
+			l.Warn("Failed to create instance refresh failure warning", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	err := inst.VolatileSet(map[string]string{
+		"volatile.copy.refresh.status": "success",
+		"volatile.copy.refresh.error":  "",
+		"volatile.copy.refresh.last":   time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		l.Warn("Failed recording the scheduled refresh of the instance", logger.Ctx{"err": err})
+	}
+
+	_ = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Project().Name, warningtype.InstanceRefreshFailure, cluster.TypeInstance, inst.ID())
+}
+
+// instanceRefreshFromSource pulls the changes of the source instance set in the copy.refresh configuration of the
+// instance, stopping the instance for the duration of the refresh if it's running.
+func instanceRefreshFromSource(ctx context.Context, s *state.State, inst instance.Instance, op *operations.Operation) error {
+	config := inst.ExpandedConfig()
+
+	server := strings.TrimSuffix(config["copy.refresh.server"], "/")
+	certificate := config["copy.refresh.certificate"]
+	instanceOnly := util.IsTrue(config["copy.refresh.instance_only"])
+
+	sourceProject := config["copy.refresh.project"]
+	if sourceProject == "" {
+		sourceProject = api.ProjectDefaultName
+	}
+
+	sourceName := config["copy.refresh.source"]
+
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "server": server, "sourceProject": sourceProject, "source": sourceName})
+
+	// Authenticate with the server certificate, which the source server must trust. Only server administrators can
+	// set the copy.refresh configuration, see instanceRefreshConfigCheck.
+	networkCert := s.Endpoints.NetworkCert()
+	source, err := incus.ConnectIncusWithContext(ctx, server, &incus.ConnectionArgs{
+		TLSServerCert: certificate,
+		TLSClientCert: string(networkCert.PublicKey()),
+		TLSClientKey:  string(networkCert.PrivateKey()),
+		UserAgent:     version.UserAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed connecting to source server %q: %w", server, err)
+	}
+
+	defer source.Disconnect()
+
+	source = source.UseProject(sourceProject)
+
+	sourceOp, err := source.MigrateInstance(sourceName, api.InstancePost{Migration: true, InstanceOnly: instanceOnly})
+	if err != nil {
+		return fmt.Errorf("Failed starting migration of source instance %q: %w", sourceName, err)
+	}
+
+	sourceSecrets := map[string]string{}
+	for k, v := range sourceOp.Get().Metadata {
+		sourceSecrets[k], _ = v.(string)
+	}
+
+	running := inst.IsRunning()
+	if running {
+		l.Info("Stopping instance for its scheduled refresh")
+
+		timeout, err := strconv.Atoi(config["boot.host_shutdown_timeout"])
+		if err != nil {
+			timeout = 30
+		}
+
+		err = inst.Shutdown(time.Duration(timeout) * time.Second)
+		if err != nil {
+			err = inst.Stop(false)
+			if err != nil {
+				_ = sourceOp.Cancel()
+				return fmt.Errorf("Failed stopping instance: %w", err)
+			}
+		}
+	}
+
+	l.Info("Refreshing instance from its source")
+
+	err = instanceRefreshSink(s, inst, fmt.Sprintf("%s/1.0/operations/%s", server, sourceOp.Get().ID), sourceSecrets, certificate, instanceOnly, op)
+	if err == nil {
+		err = sourceOp.WaitContext(ctx)
+	}
+
+	// Then apply the configuration of the source instance, as incus copy --refresh does.
+	if err == nil {
+		err = instanceRefreshUpdate(ctx, s, inst, source, sourceName)
+	}
+
+	// Start the instance back up, even when the refresh failed.
+	if running {
+		startErr := inst.Start(false)
+		if startErr != nil {
+			l.Warn("Failed starting instance after its scheduled refresh", logger.Ctx{"err": startErr})
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed refreshing from source instance %q: %w", sourceName, err)
+	}
+
+	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceRefreshed.Event(inst, map[string]any{"source": fmt.Sprintf("%s/1.0/instances/%s?project=%s", server, sourceName, sourceProject)}))
+
+	l.Info("Refreshed instance from its source")
+
+	return nil
+}
+
+// instanceRefreshSink receives the differences of the source instance of a refresh over the migration websockets of
+// the source operation.
+func instanceRefreshSink(s *state.State, inst instance.Instance, sourceOperation string, secrets map[string]string, certificate string, instanceOnly bool, op *operations.Operation) error {
+	instOp, err := inst.LockExclusive()
+	if err != nil {
+		return fmt.Errorf("Failed getting exclusive access to instance: %w", err)
+	}
+
+	dialer, err := setupWebsocketDialer(certificate)
+	if err != nil {
+		instOp.Done(err)
+		return fmt.Errorf("Failed setting up websocket dialer for migration sink connections: %w", err)
+	}
+
+	sink, err := newMigrationSink(&migrationSinkArgs{
+		URL:          sourceOperation,
+		Dialer:       dialer,
+		Instance:     inst,
+		Secrets:      secrets,
+		InstanceOnly: instanceOnly,
+		Refresh:      true,
+	})
+	if err != nil {
+		instOp.Done(err)
+		return err
+	}
+
+	sink.instance.SetOperation(op)
+
+	err = sink.Do(s, instOp)
+	instOp.Done(err)
+	if err != nil {
+		return fmt.Errorf("Error transferring instance data: %w", err)
+	}
+
+	return nil
+}
+
+// instanceRefreshPut returns the configuration of the refreshed instance, following the one of the source instance
+// while keeping the volatile keys, the scheduled refresh and the root disk pool of the instance.
+func instanceRefreshPut(source api.InstancePut, config map[string]string, devices map[string]map[string]string) api.InstancePut {
+	put := source
+	put.Config = map[string]string{}
+	put.Devices = map[string]map[string]string{}
+
+	for key, value := range source.Config {
+		if strings.HasPrefix(key, "volatile.") || strings.HasPrefix(key, "copy.refresh.") {
+			continue
+		}
+
+		put.Config[key] = value
+	}
+
+	for key, value := range config {
+		if strings.HasPrefix(key, "volatile.") || strings.HasPrefix(key, "copy.refresh.") {
+			put.Config[key] = value
+		}
+	}
+
+	for name, device := range source.Devices {
+		put.Devices[name] = map[string]string{}
+		for key, value := range device {
+			put.Devices[name][key] = value
+		}
+	}
+
+	sourceRootKey, _, _ := internalInstance.GetRootDiskDevice(put.Devices)
+	rootKey, rootDevice, _ := internalInstance.GetRootDiskDevice(devices)
+	if sourceRootKey != "" && sourceRootKey == rootKey {
+		put.Devices[rootKey]["pool"] = rootDevice["pool"]
+	}
+
+	return put
+}
+
+// instanceRefreshUpdate applies the configuration of the source instance to the refreshed instance.
+func instanceRefreshUpdate(ctx context.Context, s *state.State, inst instance.Instance, source incus.InstanceServer, sourceName string) error {
+	sourceInst, _, err := source.GetInstance(sourceName)
+	if err != nil {
+		return fmt.Errorf("Failed getting the configuration of source instance %q: %w", sourceName, err)
+	}
+
+	put := instanceRefreshPut(sourceInst.Writable(), inst.LocalConfig(), inst.LocalDevices().CloneNative())
+
+	apiProfiles := make([]api.Profile, 0, len(put.Profiles))
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		profiles, err := cluster.GetProfilesIfEnabled(ctx, tx.Tx(), inst.Project().Name, put.Profiles)
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range profiles {
+			apiProfile, err := profile.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			apiProfiles = append(apiProfiles, *apiProfile)
+		}
+
+		return projecthelpers.AllowInstanceUpdate(tx, inst.Project().Name, inst.Name(), put, inst.LocalConfig())
+	})
+	if err != nil {
+		return err
+	}
+
+	architecture, err := osarch.ArchitectureId(put.Architecture)
+	if err != nil {
+		architecture = inst.Architecture()
+	}
+
+	return inst.Update(db.InstanceArgs{
+		Architecture: architecture,
+		Config:       put.Config,
+		Description:  put.Description,
+		Devices:      deviceConfig.NewDevices(put.Devices),
+		Ephemeral:    put.Ephemeral,
+		Profiles:     apiProfiles,
+		Project:      inst.Project().Name,
+	}, true)
+}
+
+// instanceRefreshConfigCheck checks that only server administrators change the scheduled refresh of an instance or
+// profile, the refreshes connecting to any server with the certificate of this server.
+func instanceRefreshConfigCheck(s *state.State, r *http.Request, oldConfig map[string]string, newConfig map[string]string) error {
+	changed := func(config map[string]string) bool {
+		for key := range config {
+			if strings.HasPrefix(key, "copy.refresh.") && oldConfig[key] != newConfig[key] {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if !changed(oldConfig) && !changed(newConfig) {
+		return nil
+	}
+
+	err := s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectServer(), auth.EntitlementCanEdit)
+	if err != nil {
+		return fmt.Errorf("Only server administrators can change the copy.refresh configuration: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestInstanceRefreshPut(t *testing.T) {
+	source := api.InstancePut{
+		Architecture: "x86_64",
+		Description:  "Production web server",
+		Profiles:     []string{"default", "web"},
+		Config: map[string]string{
+			"limits.cpu":                "4",
+			"volatile.eth0.hwaddr":      "10:66:6a:00:00:01",
+			"volatile.last_state.power": "RUNNING",
+			"copy.refresh.schedule":     "@hourly",
+		},
+		Devices: map[string]map[string]string{
+			"root": {"type": "disk", "path": "/", "pool": "fast"},
+			"data": {"type": "disk", "path": "/srv", "source": "/srv/web"},
+		},
+	}
+
+	config := map[string]string{
+		"limits.cpu":                 "2",
+		"limits.memory":              "2GiB",
+		"volatile.eth0.hwaddr":       "10:66:6a:00:00:02",
+		"volatile.idmap.next":        "[]",
+		"copy.refresh.schedule":      "@daily",
+		"copy.refresh.server":        "https://production:8443",
+		"volatile.copy.refresh.last": "2026-10-13T00:00:00Z",
+	}
+
+	devices := map[string]map[string]string{
+		"root": {"type": "disk", "path": "/", "pool": "slow"},
+	}
+
+	put := instanceRefreshPut(source, config, devices)
+
+	require.Equal(t, "Production web server", put.Description)
+	require.Equal(t, []string{"default", "web"}, put.Profiles)
+	require.Equal(t, map[string]string{
+		"limits.cpu":                 "4",
+		"volatile.eth0.hwaddr":       "10:66:6a:00:00:02",
+		"volatile.idmap.next":        "[]",
+		"copy.refresh.schedule":      "@daily",
+		"copy.refresh.server":        "https://production:8443",
+		"volatile.copy.refresh.last": "2026-10-13T00:00:00Z",
+	}, put.Config)
+	require.Equal(t, map[string]map[string]string{
+		"root": {"type": "disk", "path": "/", "pool": "slow"},
+		"data": {"type": "disk", "path": "/srv", "source": "/srv/web"},
+	}, put.Devices)
+
+	// The source instance is left untouched.
+	require.Equal(t, "fast", source.Devices["root"]["pool"])
+	require.Equal(t, "@hourly", source.Config["copy.refresh.schedule"])
+}
+
+func TestInstanceRefreshJob(t *testing.T) {
+	now := time.Date(2026, 10, 13, 10, 0, 0, 0, time.UTC)
+
+	// Instances are only refreshed once their source is fully set.
+	require.Nil(t, instanceRefreshJob("c1", "default", "none", 1, map[string]string{}, now))
+	require.Nil(t, instanceRefreshJob("c1", "default", "none", 1, map[string]string{"copy.refresh.schedule": "@daily", "copy.refresh.server": "https://production:8443"}, now))
+
+	config := map[string]string{
+		"copy.refresh.schedule": "0 2 * * *",
+		"copy.refresh.server":   "https://production:8443",
+		"copy.refresh.source":   "web",
+	}
+
+	refresh := instanceRefreshJob("c1", "staging", "server01", 1, config, now)
+	require.Equal(t, &api.InstanceRefresh{
+		Name:          "c1",
+		Project:       "staging",
+		Location:      "server01",
+		Schedule:      "0 2 * * *",
+		Server:        "https://production:8443",
+		SourceProject: "default",
+		Source:        "web",
+		Status:        "pending",
+		NextRefreshAt: time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC),
+	}, refresh)
+
+	// The outcome of the last refresh is reported.
+	config["copy.refresh.project"] = "production"
+	config["copy.refresh.instance_only"] = "true"
+	config["volatile.copy.refresh.status"] = "failure"
+	config["volatile.copy.refresh.error"] = "Failed connecting to source server"
+	config["volatile.copy.refresh.last"] = "2026-10-12T02:00:30Z"
+
+	refresh = instanceRefreshJob("c1", "staging", "server01", 1, config, now)
+	require.Equal(t, "production", refresh.SourceProject)
+	require.True(t, refresh.InstanceOnly)
+	require.Equal(t, "failure", refresh.Status)
+	require.Equal(t, "Failed connecting to source server", refresh.Error)
+	require.Equal(t, time.Date(2026, 10, 12, 2, 0, 30, 0, time.UTC), refresh.LastRefreshAt)
+}
+
+func TestInstanceRefreshNext(t *testing.T) {
+	now := time.Date(2026, 10, 13, 10, 0, 0, 0, time.UTC)
+
+	require.Equal(t, time.Date(2026, 10, 13, 14, 0, 0, 0, time.UTC), instanceRefreshNext("0 2 * * *, 0 14 * * *", 1, now))
+	require.Equal(t, time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC), instanceRefreshNext("0 2 * * *", 1, now))
+
+	// Aliases are spread over the day depending on the instance, but always run within a day.
+	next := instanceRefreshNext("@daily", 42, now)
+	require.True(t, next.After(now))
+	require.False(t, next.After(now.Add(24*time.Hour)))
+	require.Equal(t, next, instanceRefreshNext("@daily", 42, now))
+
+	// Invalid schedules never run.
+	require.True(t, instanceRefreshNext("invalid", 1, now).IsZero())
+}
+
+func (suite *containerTestSuite) TestInstanceRefreshFailure() {
+	s := suite.d.State()
+
+	inst, op, _, err := instance.CreateInternal(s, db.InstanceArgs{
+		Type: instancetype.Container,
+		Name: "testInstanceRefresh",
+		Config: map[string]string{
+			"copy.refresh.schedule": "@daily",
+			"copy.refresh.server":   "https://127.0.0.1:1",
+			"copy.refresh.source":   "web",
+		},
+	}, true, true)
+	suite.Req.Nil(err)
+	op.Done(nil)
+	defer func() { _ = inst.Delete(true) }()
+
+	// A failed refresh is recorded and raises a warning.
+	err = autoRefreshInstances(context.TODO(), s, []instance.Instance{inst}, nil)
+	suite.Req.Nil(err)
+
+	inst, err = instance.LoadByProjectAndName(s, api.ProjectDefaultName, "testInstanceRefresh")
+	suite.Req.Nil(err)
+	suite.Req.Equal("failure", inst.LocalConfig()["volatile.copy.refresh.status"])
+	suite.Req.Contains(inst.LocalConfig()["volatile.copy.refresh.error"], "Failed connecting to source server")
+	suite.Req.Empty(inst.LocalConfig()["volatile.copy.refresh.last"])
+
+	typeCode := warningtype.InstanceRefreshFailure
+	entityID := inst.ID()
+	err = suite.d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbWarnings, err := dbCluster.GetWarnings(ctx, tx.Tx(), dbCluster.WarningFilter{TypeCode: &typeCode, EntityID: &entityID})
+		if err != nil {
+			return err
+		}
+
+		suite.Req.Len(dbWarnings, 1)
+		suite.Req.Equal(warningtype.StatusNew, dbWarnings[0].Status)
+
+		return nil
+	})
+	suite.Req.Nil(err)
+
+	// The scheduled refresh of the instance reports the failure.
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/1.0/instances/testInstanceRefresh/refresh", nil), map[string]string{"name": "testInstanceRefresh"})
+	recorder := httptest.NewRecorder()
+	err = instanceRefreshGet(suite.d, r).Render(recorder)
+	suite.Req.Nil(err)
+	suite.Req.Equal(http.StatusOK, recorder.Code)
+
+	resp := struct {
+		Metadata api.InstanceRefresh `json:"metadata"`
+	}{}
+
+	suite.Req.Nil(json.Unmarshal(recorder.Body.Bytes(), &resp))
+	suite.Req.Equal("failure", resp.Metadata.Status)
+	suite.Req.Equal("web", resp.Metadata.Source)
+	suite.Req.Contains(resp.Metadata.Error, "Failed connecting to source server")
+	suite.Req.False(resp.Metadata.NextRefreshAt.IsZero())
+
+	// A success clears the failure and resolves the warning.
+	instanceRefreshRecord(s, inst, nil)
+
+	inst, err = instance.LoadByProjectAndName(s, api.ProjectDefaultName, "testInstanceRefresh")
+	suite.Req.Nil(err)
+	suite.Req.Equal("success", inst.LocalConfig()["volatile.copy.refresh.status"])
+	suite.Req.Empty(inst.LocalConfig()["volatile.copy.refresh.error"])
+	suite.Req.NotEmpty(inst.LocalConfig()["volatile.copy.refresh.last"])
+
+	err = suite.d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbWarnings, err := dbCluster.GetWarnings(ctx, tx.Tx(), dbCluster.WarningFilter{TypeCode: &typeCode, EntityID: &entityID})
+		if err != nil {
+			return err
+		}
+
+		suite.Req.Len(dbWarnings, 1)
+		suite.Req.Equal(warningtype.StatusResolved, dbWarnings[0].Status)
+
+		return nil
+	})
+	suite.Req.Nil(err)
+
+	// Instances without a scheduled refresh aren't found.
+	err = inst.Update(db.InstanceArgs{
+		Architecture: inst.Architecture(),
+		Config:       map[string]string{},
+		Devices:      inst.LocalDevices(),
+		Profiles:     inst.Profiles(),
+		Project:      inst.Project().Name,
+	}, true)
+	suite.Req.Nil(err)
+
+	recorder = httptest.NewRecorder()
+	err = instanceRefreshGet(suite.d, r).Render(recorder)
+	suite.Req.Nil(err)
+	suite.Req.Equal(http.StatusNotFound, recorder.Code)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/lxc/incus/v6/internal/remap"
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// instanceRemapConfigKeys are the configuration keys which can be changed as part of a remap.
+var instanceRemapConfigKeys = []string{"security.privileged", "security.idmap.isolated", "security.idmap.base", "security.idmap.size"}
+
+func internalRemapInstance(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	req := remap.Post{}
+
+	// Parse the request.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Project == "" {
+		req.Project = api.ProjectDefaultName
+	}
+
+	for key, value := range req.Config {
+		if !slices.Contains(instanceRemapConfigKeys, key) {
+			return response.BadRequest(fmt.Errorf("Configuration key %q can't be changed when remapping", key))
+		}
+
+		// A remap can only lower the privileges of a container.
+		if key == "security.privileged" && util.IsTrue(value) {
+			return response.BadRequest(fmt.Errorf("Containers can't be remapped to privileged"))
+		}
+	}
+
+	// Forward the request if the container is remote.
+	client, err := cluster.ConnectIfInstanceIsRemote(s, req.Project, req.Instance, r, instancetype.Container)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if client != nil {
+		op, _, err := client.RawOperation("POST", "/internal/instance-remap", req, "")
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		opAPI := op.Get()
+		return operations.ForwardedOperationResponse(req.Project, &opAPI)
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, req.Project, req.Instance)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	c, ok := inst.(instance.Container)
+	if !ok {
+		return response.BadRequest(fmt.Errorf("Only containers can be remapped"))
+	}
+
+	if inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("The container must be stopped to be remapped"))
+	}
+
+	run := func(op *operations.Operation) error {
+		inst.SetOperation(op)
+
+		if len(req.Config) > 0 {
+			config := maps.Clone(inst.LocalConfig())
+			for key, value := range req.Config {
+				if value == "" {
+					delete(config, key)
+				} else {
+					config[key] = value
+				}
+			}
+
+			// Updating the configuration records the new ID map in volatile.idmap.next.
+			args := db.InstanceArgs{
+				Architecture: inst.Architecture(),
+				Config:       config,
+				Description:  inst.Description(),
+				Devices:      inst.LocalDevices(),
+				Ephemeral:    inst.IsEphemeral(),
+				Profiles:     inst.Profiles(),
+				Project:      inst.Project().Name,
+				ExpiryDate:   inst.ExpiryDate(),
+			}
+
+			err := inst.Update(args, true)
+			if err != nil {
+				return fmt.Errorf("Failed updating container configuration: %w", err)
+			}
+		}
+
+		return c.Remap()
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", inst.Name()).Project(req.Project)}
+
+	op, err := operations.OperationCreate(s, req.Project, operations.OperationClassTask, operationtype.InstanceUpdate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
@@ -0,0 +1,677 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	incus "github.com/lxc/incus/v6/client"
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	localtls "github.com/lxc/incus/v6/shared/tls"
+)
+
+// instanceShareProtocol is the authentication protocol of the requests authenticated through an instance share.
+const instanceShareProtocol = "share"
+
+var instanceSharesCmd = APIEndpoint{
+	Name: "instanceShares",
+	Path: "instances/{name}/shares",
+
+	Get:  APIEndpointAction{Handler: instanceSharesGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "name")},
+	Post: APIEndpointAction{Handler: instanceSharesPost, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+var instanceShareCmd = APIEndpoint{
+	Name: "instanceShare",
+	Path: "instances/{name}/shares/{id}",
+
+	Delete: APIEndpointAction{Handler: instanceShareDelete, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+// instanceShareSecretHash returns the hash of a share secret as stored in the database.
+func instanceShareSecretHash(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// swagger:operation GET /1.0/instances/{name}/shares instances instance_shares_get
+//
+//	Get the shares
+//
+//	Returns a list of the unexpired shares of the instance.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of instance shares
+//	          items:
+//	            $ref: "#/definitions/InstanceShare"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceSharesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var shares []api.InstanceShare
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.GetInstance(ctx, tx.Tx(), projectName, name)
+		if err != nil {
+			return err
+		}
+
+		shares, err = tx.GetInstanceShares(ctx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Expired shares are only pruned periodically.
+	result := make([]api.InstanceShare, 0, len(shares))
+	for _, share := range shares {
+		if time.Now().Before(share.ExpiresAt) {
+			result = append(result, share)
+		}
+	}
+
+	return response.SyncResponse(true, result)
+}
+
+// swagger:operation POST /1.0/instances/{name}/shares instances instance_shares_post
+//
+//	Create a share
+//
+//	Creates a time-limited share granting exec and console access to the
+//	instance. The returned token is needed to use the share and can't be
+//	retrieved later on.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: share
+//	    description: Share request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceSharesPost"
+//	responses:
+//	  "200":
+//	    description: Instance share
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceShare"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceSharesPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// A share grants exec and console access, which its creator must have.
+	for _, entitlement := range []auth.Entitlement{auth.EntitlementCanExec, auth.EntitlementCanAccessConsole} {
+		err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectInstance(projectName, name), entitlement)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	req := api.InstanceSharesPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.ExpiresAt.IsZero() {
+		return response.BadRequest(fmt.Errorf("Share expiry date is required"))
+	}
+
+	err = timeSkewCheck(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !time.Now().Before(req.ExpiresAt) {
+		return response.BadRequest(fmt.Errorf("Share expiry date must be in the future"))
+	}
+
+	// The token holds the addresses the server is listening on, so that the recipient doesn't have to
+	// specify one.
+	localHTTPSAddress := s.LocalConfig.HTTPSAddress()
+	if localHTTPSAddress == "" {
+		return response.BadRequest(fmt.Errorf("Can't share instances when server isn't listening on network"))
+	}
+
+	addresses, err := localUtil.ListenAddresses(localHTTPSAddress)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	fingerprint, err := localtls.CertFingerprintStr(string(s.Endpoints.NetworkPublicKey()))
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	id, err := internalUtil.RandomHexString(4)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	secret, err := internalUtil.RandomHexString(32)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+
+	share := api.InstanceShare{
+		ID:          id,
+		Description: req.Description,
+		Identity:    req.Identity,
+		CreatedBy:   requestor.Username,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   req.ExpiresAt.UTC(),
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.GetInstance(ctx, tx.Tx(), projectName, name)
+		if err != nil {
+			return err
+		}
+
+		return tx.CreateInstanceShare(ctx, projectName, name, share, instanceShareSecretHash(secret))
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	token := api.InstanceShareToken{
+		Fingerprint: fingerprint,
+		Addresses:   addresses,
+		Project:     projectName,
+		Instance:    name,
+		Secret:      secret,
+		ExpiresAt:   share.ExpiresAt,
+	}
+
+	share.Token = token.String()
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceShareCreated.Event(share.ID, name, projectName, requestor, map[string]any{"id": share.ID, "expires_at": share.ExpiresAt, "identity": share.Identity}))
+
+	return response.SyncResponseLocation(true, share, api.NewURL().Path(version.APIVersion, "instances", name, "shares", share.ID).Project(projectName).String())
+}
+
+// swagger:operation DELETE /1.0/instances/{name}/shares/{id} instances instance_share_delete
+//
+//	Delete a share
+//
+//	Revokes the share, preventing any further access through it.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceShareDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Other members only have to disconnect the sessions of the share.
+	if isClusterNotification(r) {
+		instanceSharesDisconnect(r.Context(), s)
+		return response.EmptySyncResponse
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteInstanceShare(ctx, projectName, name, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	instanceSharesDisconnect(r.Context(), s)
+
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifier(func(client incus.InstanceServer) error {
+		return client.UseProject(projectName).DeleteInstanceShare(name, id)
+	})
+	if err != nil {
+		logger.Warn("Failed notifying other members of revoked instance share", logger.Ctx{"project": projectName, "instance": name, "share": id, "err": err})
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceShareDeleted.Event(id, name, projectName, request.CreateRequestor(r), map[string]any{"id": id}))
+
+	return response.EmptySyncResponse
+}
+
+// instanceShareCovers returns whether the endpoint targeted by the request is one instance shares give access to.
+func instanceShareCovers(r *http.Request, c APIEndpoint) bool {
+	switch {
+	case r.Method == http.MethodPost && (c.Path == "instances/{name}/exec" || c.Path == "instances/{name}/console"):
+		return true
+	case r.Method == http.MethodGet && (c.Path == "instances/{name}" || c.Path == "operations/{id}" || c.Path == "operations/{id}/wait"):
+		return true
+	}
+
+	return false
+}
+
+// instanceShareUsername returns the username of the requests authenticated through the share, which always holds
+// the share ID so that the sessions of the share can be told apart from those of other shares.
+func instanceShareUsername(share *api.InstanceShare) string {
+	if share.Identity == "" {
+		return "share/" + share.ID
+	}
+
+	return "share/" + share.ID + "/" + share.Identity
+}
+
+// instanceShareID returns the ID of the share from the username of a request authenticated through it.
+func instanceShareID(username string) string {
+	fields := strings.SplitN(username, "/", 3)
+	if len(fields) < 2 || fields[0] != "share" {
+		return ""
+	}
+
+	return fields[1]
+}
+
+// instanceShareAuthenticate validates the instance share secret of a request against the endpoint it targets.
+// Shares only cover exec and console access to their instance, along with the few queries those need. For any
+// other endpoint, an empty username is returned and the request is handled as if it didn't refer to a share.
+// The username holds the share ID, followed by the identity the share is restricted to if any.
+func (d *Daemon) instanceShareAuthenticate(r *http.Request, c APIEndpoint, username string) (string, error) {
+	s := d.State()
+
+	if !instanceShareCovers(r, c) {
+		return "", nil
+	}
+
+	shareProject, shareInstance, share, err := instanceShareCheck(r.Context(), s, r, c)
+	if err != nil {
+		return "", err
+	}
+
+	shareUsername := instanceShareUsername(share)
+	if share.Identity != "" {
+		// The identity may either be an authenticated user or the fingerprint of a client certificate which
+		// isn't otherwise trusted.
+		identified := username == share.Identity
+		if !identified && r.TLS != nil {
+			for _, cert := range r.TLS.PeerCertificates {
+				if localtls.CertFingerprint(cert) == share.Identity {
+					identified = true
+					break
+				}
+			}
+		}
+
+		if !identified {
+			return "", fmt.Errorf("Instance share is restricted to another identity")
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		requestor := &api.EventLifecycleRequestor{Username: shareUsername, Protocol: instanceShareProtocol, Address: r.RemoteAddr}
+		action := "exec"
+		if c.Path == "instances/{name}/console" {
+			action = "console"
+		}
+
+		logger.Info("Instance accessed through share", logger.Ctx{"project": shareProject, "instance": shareInstance, "share": share.ID, "username": shareUsername, "action": action, "ip": r.RemoteAddr})
+		s.Events.SendLifecycle(shareProject, lifecycle.InstanceShareUsed.Event(share.ID, shareInstance, shareProject, requestor, map[string]any{"id": share.ID, "action": action}))
+	}
+
+	return shareUsername, nil
+}
+
+// instanceShareAuthenticateForwarded validates the instance share of a request forwarded by another cluster member,
+// as the share may have been revoked or may not cover what the request targets on this member. It returns whether
+// the request was made through a share.
+func (d *Daemon) instanceShareAuthenticateForwarded(r *http.Request, c APIEndpoint) (bool, error) {
+	if r.Header.Get(request.HeaderForwardedProtocol) != instanceShareProtocol {
+		return false, nil
+	}
+
+	if !instanceShareCovers(r, c) {
+		return false, fmt.Errorf("Instance share doesn't cover this endpoint")
+	}
+
+	_, _, share, err := instanceShareCheck(r.Context(), d.State(), r, c)
+	if err != nil {
+		return false, err
+	}
+
+	if r.Header.Get(request.HeaderForwardedUsername) != instanceShareUsername(share) {
+		return false, fmt.Errorf("Instance share doesn't match the forwarded user")
+	}
+
+	return true, nil
+}
+
+// instanceShareCheck loads the share matching the secret of the request and checks that it's valid and covers the
+// instance or operation the request targets. Operations running on other cluster members must exist, the member
+// the request is forwarded to checks them against the share.
+func instanceShareCheck(ctx context.Context, s *state.State, r *http.Request, c APIEndpoint) (string, string, *api.InstanceShare, error) {
+	var shareProject, shareInstance string
+	var share *api.InstanceShare
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		shareProject, shareInstance, share, err = tx.GetInstanceShareBySecret(ctx, instanceShareSecretHash(r.Header.Get("X-Incus-share")))
+
+		return err
+	})
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return "", "", nil, fmt.Errorf("Invalid instance share")
+		}
+
+		return "", "", nil, err
+	}
+
+	err = timeSkewCheck(s)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if !time.Now().Before(share.ExpiresAt) {
+		return "", "", nil, fmt.Errorf("Instance share has expired")
+	}
+
+	if strings.HasPrefix(c.Path, "instances/") {
+		instanceName, err := url.PathUnescape(mux.Vars(r)["name"])
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		if instanceName != shareInstance || request.ProjectParam(r) != shareProject {
+			return "", "", nil, fmt.Errorf("Instance share doesn't cover this instance")
+		}
+
+		return shareProject, shareInstance, share, nil
+	}
+
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	op, err := operations.OperationGetInternal(id)
+	if err == nil {
+		requestor := op.Requestor()
+		if requestor == nil || requestor.Protocol != instanceShareProtocol || requestor.Username != instanceShareUsername(share) || !instanceShareCoversOperation(op.Type()) {
+			return "", "", nil, fmt.Errorf("Instance share doesn't cover this operation")
+		}
+
+		return shareProject, shareInstance, share, nil
+	}
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		ops, err := dbCluster.GetOperations(ctx, tx.Tx(), dbCluster.OperationFilter{UUID: &id})
+		if err != nil {
+			return err
+		}
+
+		if len(ops) != 1 || ops[0].NodeID == s.DB.Cluster.GetNodeID() || !instanceShareCoversOperation(ops[0].Type) {
+			return fmt.Errorf("Instance share doesn't cover this operation")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return shareProject, shareInstance, share, nil
+}
+
+// instanceShareCoversOperation returns whether operations of the given type can be opened through an instance share.
+func instanceShareCoversOperation(opType operationtype.Type) bool {
+	return opType == operationtype.CommandExec || opType == operationtype.ConsoleShow
+}
+
+// instanceShareRender renders an instance for a request made through an instance share. Shares only grant exec and
+// console access, so the configuration and devices of the instance, which may hold secrets, are left out.
+func instanceShareRender(inst instance.Instance) response.Response {
+	state, _, err := inst.Render()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	apiInst, ok := state.(*api.Instance)
+	if !ok {
+		return response.InternalError(fmt.Errorf("Unexpected instance render type %T", state))
+	}
+
+	apiInst.Config = map[string]string{}
+	apiInst.ExpandedConfig = map[string]string{}
+	apiInst.Devices = map[string]map[string]string{}
+	apiInst.ExpandedDevices = map[string]map[string]string{}
+	apiInst.Profiles = []string{}
+
+	return response.SyncResponse(true, apiInst)
+}
+
+// instanceShareSession is an exec or console session opened through an instance share.
+type instanceShareSession struct {
+	project    string
+	instance   string
+	share      string
+	disconnect func()
+}
+
+// instanceShareSessions holds the sessions opened through instance shares on this member, by operation ID.
+var instanceShareSessions = map[string]instanceShareSession{}
+var instanceShareSessionsLock sync.Mutex
+
+// instanceShareSessionTrack records the session of the operation if it was opened through an instance share, for it
+// to be disconnected once the share is revoked or expires.
+func instanceShareSessionTrack(r *http.Request, op *operations.Operation, projectName string, instanceName string, disconnect func()) {
+	requestor := request.CreateRequestor(r)
+	if requestor.Protocol != instanceShareProtocol {
+		return
+	}
+
+	instanceShareSessionsLock.Lock()
+	defer instanceShareSessionsLock.Unlock()
+
+	instanceShareSessions[op.ID()] = instanceShareSession{
+		project:    projectName,
+		instance:   instanceName,
+		share:      instanceShareID(requestor.Username),
+		disconnect: disconnect,
+	}
+}
+
+// instanceSharesDisconnect disconnects the sessions of the instance shares which were revoked or expired.
+func instanceSharesDisconnect(ctx context.Context, s *state.State) {
+	instanceShareSessionsLock.Lock()
+	defer instanceShareSessionsLock.Unlock()
+
+	if len(instanceShareSessions) == 0 {
+		return
+	}
+
+	running := operations.Clone()
+	valid := map[string]bool{}
+	loaded := map[string]bool{}
+
+	for id, session := range instanceShareSessions {
+		op, ok := running[id]
+		if !ok || op.Status().IsFinal() {
+			delete(instanceShareSessions, id)
+			continue
+		}
+
+		key := session.project + "/" + session.instance
+		if !loaded[key] {
+			err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+				shares, err := tx.GetInstanceShares(ctx, session.project, session.instance)
+				if err != nil {
+					return err
+				}
+
+				for _, share := range shares {
+					if time.Now().Before(share.ExpiresAt) {
+						valid[key+"/"+share.ID] = true
+					}
+				}
+
+				return nil
+			})
+			if err != nil {
+				logger.Warn("Failed loading instance shares", logger.Ctx{"project": session.project, "instance": session.instance, "err": err})
+				continue
+			}
+
+			loaded[key] = true
+		}
+
+		if valid[key+"/"+session.share] {
+			continue
+		}
+
+		logger.Info("Disconnecting session of revoked or expired instance share", logger.Ctx{"project": session.project, "instance": session.instance, "share": session.share, "operation": id})
+		session.disconnect()
+		delete(instanceShareSessions, id)
+	}
+}
+
+// instanceSharesPruneExpired disconnects the sessions of expired instance shares and deletes those shares.
+func instanceSharesPruneExpired(ctx context.Context, d *Daemon) {
+	instanceSharesDisconnect(ctx, d.State())
+
+	err := d.State().DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteExpiredInstanceShares(ctx, time.Now())
+	})
+	if err != nil {
+		logger.Warn("Failed removing expired instance shares", logger.Ctx{"err": err})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func (suite *containerTestSuite) TestInstanceShareAuthenticate() {
+	for _, name := range []string{"c1", "c2"} {
+		c, op, _, err := instance.CreateInternal(suite.d.State(), db.InstanceArgs{Type: instancetype.Container, Name: name}, true, true)
+		suite.Req.Nil(err)
+		op.Done(nil)
+		defer func() { _ = c.Delete(true) }()
+	}
+
+	now := time.Now().UTC()
+	shares := map[string]api.InstanceShare{
+		"secret-open":       {ID: "aaaa0001", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		"secret-restricted": {ID: "aaaa0002", Identity: "alice", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		"secret-expired":    {ID: "aaaa0003", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	}
+
+	err := suite.d.db.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+		for secret, share := range shares {
+			err := tx.CreateInstanceShare(ctx, "default", "c1", share, instanceShareSecretHash(secret))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	suite.Req.Nil(err)
+
+	newRequest := func(method string, path string, vars map[string]string, secret string) *http.Request {
+		r := httptest.NewRequest(method, "/1.0/"+path+"?project=default", nil)
+		r.Header.Set("X-Incus-share", secret)
+
+		return mux.SetURLVars(r, vars)
+	}
+
+	exec := APIEndpoint{Path: "instances/{name}/exec"}
+
+	tests := []struct {
+		name     string
+		request  *http.Request
+		endpoint APIEndpoint
+		username string
+		expected string
+		fails    bool
+	}{
+		{"Exec through a share", newRequest(http.MethodPost, "instances/c1/exec", map[string]string{"name": "c1"}, "secret-open"), exec, "", "share/aaaa0001", false},
+		{"Instance through a share", newRequest(http.MethodGet, "instances/c1", map[string]string{"name": "c1"}, "secret-open"), APIEndpoint{Path: "instances/{name}"}, "", "share/aaaa0001", false},
+		{"Other endpoint", newRequest(http.MethodDelete, "instances/c1", map[string]string{"name": "c1"}, "secret-open"), APIEndpoint{Path: "instances/{name}"}, "", "", false},
+		{"Unknown share", newRequest(http.MethodPost, "instances/c1/exec", map[string]string{"name": "c1"}, "secret-unknown"), exec, "", "", true},
+		{"Expired share", newRequest(http.MethodPost, "instances/c1/exec", map[string]string{"name": "c1"}, "secret-expired"), exec, "", "", true},
+		{"Other instance", newRequest(http.MethodPost, "instances/c2/exec", map[string]string{"name": "c2"}, "secret-open"), exec, "", "", true},
+		{"Restricted share with its identity", newRequest(http.MethodPost, "instances/c1/exec", map[string]string{"name": "c1"}, "secret-restricted"), exec, "alice", "share/aaaa0002/alice", false},
+		{"Restricted share with another identity", newRequest(http.MethodPost, "instances/c1/exec", map[string]string{"name": "c1"}, "secret-restricted"), exec, "bob", "", true},
+		{"Restricted share without identity", newRequest(http.MethodPost, "instances/c1/exec", map[string]string{"name": "c1"}, "secret-restricted"), exec, "", "", true},
+		{"Unknown operation", newRequest(http.MethodGet, "operations/unknown", map[string]string{"id": "unknown"}, "secret-open"), APIEndpoint{Path: "operations/{id}"}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		username, err := suite.d.instanceShareAuthenticate(tt.request, tt.endpoint, tt.username)
+		if tt.fails {
+			suite.Req.Error(err, tt.name)
+			continue
+		}
+
+		suite.Req.NoError(err, tt.name)
+		suite.Req.Equal(tt.expected, username, tt.name)
+
+		if username != "" {
+			suite.Req.Equal(shares[tt.request.Header.Get("X-Incus-share")].ID, instanceShareID(username), tt.name)
+		}
+	}
+
+	// Requests forwarded by other members are checked against the share again.
+	forwarded := func(username string) *http.Request {
+		r := newRequest(http.MethodPost, "instances/c1/exec", map[string]string{"name": "c1"}, "secret-open")
+		r.Header.Set(request.HeaderForwardedProtocol, instanceShareProtocol)
+		r.Header.Set(request.HeaderForwardedUsername, username)
+
+		return r
+	}
+
+	shared, err := suite.d.instanceShareAuthenticateForwarded(forwarded("share/aaaa0001"), exec)
+	suite.Req.NoError(err)
+	suite.Req.True(shared)
+
+	_, err = suite.d.instanceShareAuthenticateForwarded(forwarded("share/aaaa0002/alice"), exec)
+	suite.Req.Error(err)
+
+	r := newRequest(http.MethodPost, "instances/c1/exec", map[string]string{"name": "c1"}, "secret-open")
+	shared, err = suite.d.instanceShareAuthenticateForwarded(r, exec)
+	suite.Req.NoError(err)
+	suite.Req.False(shared)
+}
+
+// The instances accessed through a share are rendered without their configuration.
+func (suite *containerTestSuite) TestInstanceShareRender() {
+	c, op, _, err := instance.CreateInternal(suite.d.State(), db.InstanceArgs{
+		Type:   instancetype.Container,
+		Name:   "testShareRender",
+		Config: map[string]string{"user.secret": "hunter2"},
+	}, true, true)
+	suite.Req.Nil(err)
+	op.Done(nil)
+	defer func() { _ = c.Delete(true) }()
+
+	recorder := httptest.NewRecorder()
+	suite.Req.Nil(instanceShareRender(c).Render(recorder))
+	suite.Req.Equal(http.StatusOK, recorder.Code)
+	suite.Req.NotContains(recorder.Body.String(), "hunter2")
+
+	resp := struct {
+		Metadata api.Instance `json:"metadata"`
+	}{}
+
+	suite.Req.Nil(json.Unmarshal(recorder.Body.Bytes(), &resp))
+	suite.Req.Equal("testShareRender", resp.Metadata.Name)
+	suite.Req.Empty(resp.Metadata.ExpandedConfig)
+	suite.Req.Empty(resp.Metadata.ExpandedDevices)
+}
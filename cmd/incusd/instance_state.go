@@ -18,6 +18,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/response"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/util"
 )
 
 // swagger:operation GET /1.0/instances/{name}/state instances instance_state_get
@@ -30,6 +31,9 @@ import (
 //	inside of the instance to retrieve the resource usage and network
 //	information.
 //
+//	With the `stream` parameter, the connection is upgraded to a websocket
+//	receiving the resource usage of the instance at every interval instead.
+//
 //	---
 //	produces:
 //	  - application/json
@@ -38,6 +42,15 @@ import (
 //	    name: project
 //	    description: Project name
 //	    type: string
+//	  - in: query
+//	    name: stream
+//	    description: Stream the resource usage over a websocket
+//	    type: boolean
+//	  - in: query
+//	    name: interval
+//	    description: Interval of the resource usage stream (in seconds)
+//	    type: integer
+//	    example: 1
 //	responses:
 //	  "200":
 //	    description: State
@@ -83,6 +96,11 @@ func instanceState(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Invalid instance name"))
 	}
 
+	// Stream the resource usage over a websocket if requested.
+	if util.IsTrue(request.QueryParam(r, "stream")) {
+		return instanceStateStream(s, r, projectName, name, instanceType)
+	}
+
 	// Handle requests targeted to a container on a different node
 	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
 	if err != nil {
@@ -176,8 +194,8 @@ func instanceStatePut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
-	// Check if the cluster member is evacuated.
-	if s.DB.Cluster.LocalNodeIsEvacuated() && req.Action != "stop" {
+	// Check if the cluster member is evacuated (disconnected edge members can't look it up).
+	if !s.Edge.Disconnected() && s.DB.Cluster.LocalNodeIsEvacuated() && req.Action != "stop" {
 		return response.Forbidden(fmt.Errorf("Cluster member is evacuated"))
 	}
 
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+	"github.com/lxc/incus/v6/shared/ws"
+)
+
+// instanceStateStreamInterval returns the sampling interval requested for a state stream.
+func instanceStateStreamInterval(r *http.Request) (time.Duration, error) {
+	value := request.QueryParam(r, "interval")
+	if value == "" {
+		return time.Second, nil
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 1 || seconds > 60 {
+		return 0, fmt.Errorf("Invalid interval %q, expected a number of seconds between 1 and 60", value)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// instanceStateCounters holds the values of the state of an instance the usage deltas are computed from.
+type instanceStateCounters struct {
+	cpu             int64
+	memory          int64
+	disk            int64
+	bytesReceived   int64
+	bytesSent       int64
+	packetsReceived int64
+	packetsSent     int64
+}
+
+// instanceStateSampler computes the resource usage of instances between two samples.
+type instanceStateSampler struct {
+	previous map[string]instanceStateCounters
+}
+
+// sample returns the usage of the instances since the previous sample, keyed by project and instance name. The
+// running instances sampled for the first time are left out, their deltas being unknown until the next sample.
+// Instances whose state can't be retrieved are returned with the error.
+func (st *instanceStateSampler) sample(instances []instance.Instance) map[string]api.InstanceStateUsage {
+	hostInterfaces, _ := net.Interfaces()
+
+	current := make(map[string]instanceStateCounters, len(instances))
+	usages := make(map[string]api.InstanceStateUsage, len(instances))
+	for _, inst := range instances {
+		usage := api.InstanceStateUsage{
+			Name:     inst.Name(),
+			Project:  inst.Project().Name,
+			Location: inst.Location(),
+			Status:   inst.State(),
+		}
+
+		key := project.Instance(usage.Project, usage.Name)
+
+		if !inst.IsRunning() {
+			usages[key] = usage
+			continue
+		}
+
+		instState, err := inst.RenderState(hostInterfaces)
+		if err != nil {
+			usage.Error = err.Error()
+			usages[key] = usage
+			continue
+		}
+
+		counters := instanceStateCounters{
+			cpu:    instState.CPU.Usage,
+			memory: instState.Memory.Usage,
+		}
+
+		for _, disk := range instState.Disk {
+			counters.disk += disk.Usage
+		}
+
+		for name, nic := range instState.Network {
+			if name == "lo" {
+				continue
+			}
+
+			counters.bytesReceived += nic.Counters.BytesReceived
+			counters.bytesSent += nic.Counters.BytesSent
+			counters.packetsReceived += nic.Counters.PacketsReceived
+			counters.packetsSent += nic.Counters.PacketsSent
+		}
+
+		current[key] = counters
+
+		previous, ok := st.previous[key]
+		if !ok {
+			continue
+		}
+
+		usage.Status = instState.Status
+		usage.CPUUsage = max(counters.cpu-previous.cpu, 0)
+		usage.MemoryUsage = counters.memory
+		usage.MemoryUsageDelta = counters.memory - previous.memory
+		usage.DiskUsage = counters.disk
+		usage.DiskUsageDelta = counters.disk - previous.disk
+		usage.NetworkBytesReceived = max(counters.bytesReceived-previous.bytesReceived, 0)
+		usage.NetworkBytesSent = max(counters.bytesSent-previous.bytesSent, 0)
+		usage.NetworkPacketsReceived = max(counters.packetsReceived-previous.packetsReceived, 0)
+		usage.NetworkPacketsSent = max(counters.packetsSent-previous.packetsSent, 0)
+
+		usages[key] = usage
+	}
+
+	st.previous = current
+
+	return usages
+}
+
+// instanceStateSubscriber is a state stream receiving the usage of the instances matching its filter.
+type instanceStateSubscriber struct {
+	filter   func(inst instance.Instance) bool
+	messages chan api.InstanceStateStream
+
+	// Instances matched by the filter at the last sample, keyed by project and instance name.
+	instances map[string]bool
+}
+
+// instanceStateBroadcaster samples the usage of the local instances at a given interval once for all its
+// subscribers, so that the number of streams doesn't multiply the cost of rendering the instance states.
+type instanceStateBroadcaster struct {
+	subscribers map[*instanceStateSubscriber]bool
+	cancel      context.CancelFunc
+}
+
+// instanceStateBroadcasters holds the running broadcasters, keyed by interval.
+var instanceStateBroadcasters = map[time.Duration]*instanceStateBroadcaster{}
+var instanceStateBroadcastersMu sync.Mutex
+
+// instanceStateSubscribe adds a subscriber receiving the usage of the local instances matching filter at every
+// interval, starting the sampling at that interval if not running yet.
+func instanceStateSubscribe(s *state.State, interval time.Duration, filter func(inst instance.Instance) bool) *instanceStateSubscriber {
+	sub := &instanceStateSubscriber{
+		filter:   filter,
+		messages: make(chan api.InstanceStateStream, 1),
+	}
+
+	instanceStateBroadcastersMu.Lock()
+	defer instanceStateBroadcastersMu.Unlock()
+
+	b, ok := instanceStateBroadcasters[interval]
+	if !ok {
+		ctx, cancel := context.WithCancel(s.ShutdownCtx)
+		b = &instanceStateBroadcaster{subscribers: map[*instanceStateSubscriber]bool{}, cancel: cancel}
+		instanceStateBroadcasters[interval] = b
+
+		load := func() ([]instance.Instance, error) {
+			return instance.LoadNodeAll(s, instancetype.Any)
+		}
+
+		go b.run(ctx, interval, load)
+	}
+
+	b.subscribers[sub] = true
+
+	return sub
+}
+
+// instanceStateUnsubscribe removes a subscriber, stopping the sampling at its interval if it was the last one.
+func instanceStateUnsubscribe(interval time.Duration, sub *instanceStateSubscriber) {
+	instanceStateBroadcastersMu.Lock()
+	defer instanceStateBroadcastersMu.Unlock()
+
+	b, ok := instanceStateBroadcasters[interval]
+	if !ok {
+		return
+	}
+
+	delete(b.subscribers, sub)
+
+	if len(b.subscribers) == 0 {
+		b.cancel()
+		delete(instanceStateBroadcasters, interval)
+	}
+}
+
+// subscribedInstances returns the instances matching the filter of at least one subscriber, recording the ones
+// each subscriber matches.
+func (b *instanceStateBroadcaster) subscribedInstances(instances []instance.Instance) []instance.Instance {
+	instanceStateBroadcastersMu.Lock()
+	defer instanceStateBroadcastersMu.Unlock()
+
+	subscribed := make([]instance.Instance, 0, len(instances))
+	for sub := range b.subscribers {
+		sub.instances = map[string]bool{}
+	}
+
+	for _, inst := range instances {
+		matched := false
+		for sub := range b.subscribers {
+			if sub.filter(inst) {
+				sub.instances[project.Instance(inst.Project().Name, inst.Name())] = true
+				matched = true
+			}
+		}
+
+		if matched {
+			subscribed = append(subscribed, inst)
+		}
+	}
+
+	return subscribed
+}
+
+// broadcast sends each subscriber the usage of the instances it subscribed to. Subscribers not done with the
+// previous message miss this one rather than holding the others back.
+func (b *instanceStateBroadcaster) broadcast(usages map[string]api.InstanceStateUsage, interval time.Duration, timestamp time.Time) {
+	instanceStateBroadcastersMu.Lock()
+	defer instanceStateBroadcastersMu.Unlock()
+
+	for sub := range b.subscribers {
+		message := api.InstanceStateStream{
+			Instances: []api.InstanceStateUsage{},
+			Interval:  interval.Milliseconds(),
+			Timestamp: timestamp.UTC(),
+		}
+
+		for key, usage := range usages {
+			if sub.instances[key] {
+				message.Instances = append(message.Instances, usage)
+			}
+		}
+
+		sort.Slice(message.Instances, func(i, j int) bool {
+			return project.Instance(message.Instances[i].Project, message.Instances[i].Name) < project.Instance(message.Instances[j].Project, message.Instances[j].Name)
+		})
+
+		select {
+		case sub.messages <- message:
+		default:
+		}
+	}
+}
+
+// run samples the subscribed instances at every interval until the context is cancelled.
+func (b *instanceStateBroadcaster) run(ctx context.Context, interval time.Duration, load func() ([]instance.Instance, error)) {
+	sampler := instanceStateSampler{}
+	lastSample := time.Time{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		instances, err := load()
+		if err != nil {
+			logger.Warn("Failed loading instances for state stream", logger.Ctx{"err": err})
+		} else {
+			now := time.Now()
+			usages := sampler.sample(b.subscribedInstances(instances))
+
+			// The first sample only provides the base of the deltas.
+			if !lastSample.IsZero() {
+				b.broadcast(usages, now.Sub(lastSample), now)
+			}
+
+			lastSample = now
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// instanceStateStreamRun sends the usage messages of the subscriber to the websocket, along with the messages
+// received from the remote streams, until the client disconnects.
+func instanceStateStreamRun(ctx context.Context, conn *websocket.Conn, sub *instanceStateSubscriber, remotes []*websocket.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writeMu := sync.Mutex{}
+	write := func(messageType int, data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+
+		return conn.WriteMessage(messageType, data)
+	}
+
+	// The client isn't expected to send anything, stop once it disconnects.
+	go func() {
+		defer cancel()
+
+		for {
+			_, _, err := conn.NextReader()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// Relay the streams of the other cluster members.
+	for _, remote := range remotes {
+		go func(remote *websocket.Conn) {
+			go func() {
+				<-ctx.Done()
+				_ = remote.Close()
+			}()
+
+			for {
+				messageType, data, err := remote.ReadMessage()
+				if err != nil {
+					return
+				}
+
+				err = write(messageType, data)
+				if err != nil {
+					cancel()
+					return
+				}
+			}
+		}(remote)
+	}
+
+	for {
+		var message api.InstanceStateStream
+
+		select {
+		case <-ctx.Done():
+			return
+		case message = <-sub.messages:
+		}
+
+		data, err := json.Marshal(message)
+		if err != nil {
+			return
+		}
+
+		err = write(websocket.TextMessage, data)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// instanceStateStreamResponse upgrades the request to a websocket streaming the usage of the local instances
+// matching filter.
+func instanceStateStreamResponse(s *state.State, r *http.Request, interval time.Duration, filter func(inst instance.Instance) bool, remotes []*websocket.Conn) response.Response {
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		conn, err := ws.Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			for _, remote := range remotes {
+				_ = remote.Close()
+			}
+
+			logger.Warn("Failed upgrading instance state stream connection", logger.Ctx{"err": err})
+			return nil
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		sub := instanceStateSubscribe(s, interval, filter)
+		defer instanceStateUnsubscribe(interval, sub)
+
+		instanceStateStreamRun(r.Context(), conn, sub, remotes)
+
+		return nil
+	})
+}
+
+// instanceStateStream streams the resource usage of a single instance, forwarding the request to the member the
+// instance is located on.
+func instanceStateStream(s *state.State, r *http.Request, projectName string, name string, instanceType instancetype.Type) response.Response {
+	interval, err := instanceStateStreamInterval(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, name, r, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if client != nil {
+		remote, err := client.RawWebsocket(strings.TrimPrefix(r.URL.RequestURI(), "/1.0"))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.ManualResponse(func(w http.ResponseWriter) error {
+			conn, err := ws.Upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				_ = remote.Close()
+				logger.Warn("Failed upgrading instance state stream connection", logger.Ctx{"err": err})
+				return nil
+			}
+
+			<-ws.Proxy(conn, remote)
+
+			return nil
+		})
+	}
+
+	_, err = instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	filter := func(inst instance.Instance) bool {
+		return inst.Project().Name == projectName && inst.Name() == name
+	}
+
+	return instanceStateStreamResponse(s, r, interval, filter, nil)
+}
+
+// instancesStateStream streams the resource usage of the instances of a project, or of all projects, across the
+// cluster members.
+func instancesStateStream(s *state.State, r *http.Request) response.Response {
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	interval, err := instanceStateStreamInterval(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	projectName := request.QueryParam(r, "project")
+	allProjects := util.IsTrue(request.QueryParam(r, "all-projects"))
+
+	if allProjects && projectName != "" {
+		return response.BadRequest(fmt.Errorf("Cannot specify a project when requesting all projects"))
+	} else if !allProjects && projectName == "" {
+		projectName = api.ProjectDefaultName
+	}
+
+	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, auth.ObjectTypeInstance)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Connect to the streams of the other cluster members, unless the request comes from one of them.
+	remotes := []*websocket.Conn{}
+	if s.ServerClustered && !isClusterNotification(r) {
+		var members []db.NodeInfo
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			members, err = tx.GetNodes(ctx)
+
+			return err
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		localAddress := s.LocalConfig.ClusterAddress()
+		offlineThreshold := s.GlobalConfig.OfflineThreshold()
+
+		for _, member := range members {
+			if member.Address == localAddress || member.IsOffline(offlineThreshold) {
+				continue
+			}
+
+			client, err := cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+			if err == nil {
+				var remote *websocket.Conn
+				remote, err = client.RawWebsocket(strings.TrimPrefix(r.URL.RequestURI(), "/1.0"))
+				if err == nil {
+					remotes = append(remotes, remote)
+					continue
+				}
+			}
+
+			logger.Warn("Failed connecting to instance state stream of cluster member", logger.Ctx{"member": member.Name, "err": err})
+		}
+	}
+
+	filter := func(inst instance.Instance) bool {
+		if instanceType != instancetype.Any && inst.Type() != instanceType {
+			return false
+		}
+
+		if !allProjects && inst.Project().Name != projectName {
+			return false
+		}
+
+		return userHasPermission(auth.ObjectInstance(inst.Project().Name, inst.Name()))
+	}
+
+	return instanceStateStreamResponse(s, r, interval, filter, remotes)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// instanceStateFake is an instance returning a fixed state.
+type instanceStateFake struct {
+	instance.Instance
+
+	name    string
+	project string
+	state   *api.InstanceState
+	err     error
+}
+
+func (f *instanceStateFake) Name() string         { return f.name }
+func (f *instanceStateFake) Project() api.Project { return api.Project{Name: f.project} }
+func (f *instanceStateFake) Location() string     { return "server01" }
+func (f *instanceStateFake) IsRunning() bool      { return f.state != nil || f.err != nil }
+
+func (f *instanceStateFake) State() string {
+	if f.IsRunning() {
+		return api.Running.String()
+	}
+
+	return api.Stopped.String()
+}
+
+func (f *instanceStateFake) RenderState(hostInterfaces []net.Interface) (*api.InstanceState, error) {
+	return f.state, f.err
+}
+
+func instanceStateFakeState(cpu int64, memory int64, bytesReceived int64) *api.InstanceState {
+	return &api.InstanceState{
+		Status: api.Running.String(),
+		CPU:    api.InstanceStateCPU{Usage: cpu},
+		Memory: api.InstanceStateMemory{Usage: memory},
+		Disk:   map[string]api.InstanceStateDisk{"root": {Usage: 1000}},
+		Network: map[string]api.InstanceStateNetwork{
+			"eth0": {Counters: api.InstanceStateNetworkCounters{BytesReceived: bytesReceived}},
+			"lo":   {Counters: api.InstanceStateNetworkCounters{BytesReceived: 1000000}},
+		},
+	}
+}
+
+func TestInstanceStateSamplerSample(t *testing.T) {
+	running := &instanceStateFake{name: "c1", project: "default", state: instanceStateFakeState(100, 2048, 10)}
+	stopped := &instanceStateFake{name: "c2", project: "default"}
+	failing := &instanceStateFake{name: "c3", project: "p1", err: errors.New("Failed to get memory usage")}
+
+	sampler := instanceStateSampler{}
+
+	// The running instance sampled for the first time is left out.
+	usages := sampler.sample([]instance.Instance{running, stopped, failing})
+	require.Len(t, usages, 2)
+	require.Equal(t, api.Stopped.String(), usages["c2"].Status)
+	require.Equal(t, "Failed to get memory usage", usages["p1_c3"].Error)
+
+	running.state = instanceStateFakeState(350, 1024, 60)
+	usages = sampler.sample([]instance.Instance{running, stopped, failing})
+	require.Len(t, usages, 3)
+	require.Equal(t, api.InstanceStateUsage{
+		Name:                 "c1",
+		Project:              "default",
+		Location:             "server01",
+		Status:               api.Running.String(),
+		CPUUsage:             250,
+		MemoryUsage:          1024,
+		MemoryUsageDelta:     -1024,
+		DiskUsage:            1000,
+		NetworkBytesReceived: 50,
+	}, usages["c1"])
+
+	// Counters going backwards, for example after a restart, don't give negative usage.
+	running.state = instanceStateFakeState(10, 1024, 5)
+	usages = sampler.sample([]instance.Instance{running})
+	require.Len(t, usages, 1)
+	require.Equal(t, int64(0), usages["c1"].CPUUsage)
+	require.Equal(t, int64(0), usages["c1"].NetworkBytesReceived)
+}
+
+func TestInstanceStateBroadcaster(t *testing.T) {
+	c1 := &instanceStateFake{name: "c1", project: "default"}
+	c2 := &instanceStateFake{name: "c2", project: "p1"}
+	c3 := &instanceStateFake{name: "c3", project: "p2"}
+
+	all := &instanceStateSubscriber{filter: func(inst instance.Instance) bool { return inst.Project().Name != "p2" }, messages: make(chan api.InstanceStateStream, 1)}
+	single := &instanceStateSubscriber{filter: func(inst instance.Instance) bool { return inst.Name() == "c2" }, messages: make(chan api.InstanceStateStream, 1)}
+
+	b := &instanceStateBroadcaster{subscribers: map[*instanceStateSubscriber]bool{all: true, single: true}}
+
+	// Only the instances some subscriber is interested in are sampled.
+	subscribed := b.subscribedInstances([]instance.Instance{c1, c2, c3})
+	require.Equal(t, []instance.Instance{c1, c2}, subscribed)
+
+	sampler := instanceStateSampler{}
+	b.broadcast(sampler.sample(subscribed), time.Second, time.Now())
+
+	message := <-all.messages
+	require.Equal(t, int64(1000), message.Interval)
+	require.Len(t, message.Instances, 2)
+	require.Equal(t, "c1", message.Instances[0].Name)
+	require.Equal(t, "c2", message.Instances[1].Name)
+
+	message = <-single.messages
+	require.Len(t, message.Instances, 1)
+	require.Equal(t, "c2", message.Instances[0].Name)
+
+	// A subscriber not reading its messages doesn't block the others.
+	b.broadcast(sampler.sample(subscribed), time.Second, time.Now())
+	b.broadcast(sampler.sample(subscribed), time.Second, time.Now())
+	require.Len(t, all.messages, 1)
+	require.Len(t, single.messages, 1)
+}
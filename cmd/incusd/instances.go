@@ -54,6 +54,13 @@ var instanceRebuildCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: instanceRebuildPost, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "name")},
 }
 
+var instanceConvertCmd = APIEndpoint{
+	Name: "instanceConvert",
+	Path: "instances/{name}/convert",
+
+	Post: APIEndpointAction{Handler: instanceConvertPost, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
 var instanceStateCmd = APIEndpoint{
 	Name: "instanceState",
 	Path: "instances/{name}/state",
@@ -191,6 +198,16 @@ func instanceShouldAutoStart(inst instance.Instance) bool {
 	return util.IsTrue(autoStart) || (autoStart == "" && lastState == instance.PowerStateRunning)
 }
 
+// instanceHostShutdownAction returns the action to take on the instance when the host shuts down.
+// Containers with security.criu enabled are checkpointed when shutdownCheckpoint (instances.shutdown_checkpoint) is set.
+func instanceHostShutdownAction(inst instance.Instance, shutdownCheckpoint bool) string {
+	if inst.Type() == instancetype.Container && shutdownCheckpoint && util.IsTrue(inst.ExpandedConfig()["security.criu"]) {
+		return "stateful-stop"
+	}
+
+	return inst.ExpandedConfig()["boot.host_shutdown_action"]
+}
+
 func instancesStart(s *state.State, instances []instance.Instance) {
 	// Check if the cluster is currently evacuated.
 	if s.DB.Cluster.LocalNodeIsEvacuated() {
@@ -201,12 +218,19 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 	instancesStartMu.Lock()
 	defer instancesStartMu.Unlock()
 
-	// Sort based on instance boot priority.
+	// Sort based on instance boot priority, then start dependencies first.
 	sort.Sort(instanceAutostartList(instances))
 
+	instances, err := instancesDependencyOrder(instances, instanceDependencies)
+	if err != nil {
+		logger.Warn("Ignoring boot dependencies of some instances", logger.Ctx{"err": err})
+	}
+
 	// Let's make up to 3 attempts to start instances.
 	maxAttempts := 3
 
+	shutdownCheckpoint := s.GlobalConfig != nil && s.GlobalConfig.InstancesShutdownCheckpoint()
+
 	// Start the instances
 	for _, inst := range instances {
 		if !instanceShouldAutoStart(inst) {
@@ -221,10 +245,13 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 		// Get the instance config.
 		config := inst.ExpandedConfig()
 		autoStartDelay := config["boot.autostart.delay"]
-		shutdownAction := config["boot.host_shutdown_action"]
+		shutdownAction := instanceHostShutdownAction(inst, shutdownCheckpoint)
 
 		instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+		// Wait for the dependencies of the instance.
+		instanceWaitDependencies(s, inst)
+
 		// Try to start the instance.
 		var attempt = 0
 		for {
@@ -378,6 +405,24 @@ func instancesOnDisk(s *state.State) ([]instance.Instance, error) {
 func instancesShutdown(s *state.State, instances []instance.Instance) {
 	sort.Sort(instanceStopList(instances))
 
+	// Instances with boot.depends_on.reverse_stop are shut down before their dependencies.
+	dependents := instanceStopDependents(instances)
+	instances, err := instancesDependencyOrder(instances, dependents)
+	if err != nil {
+		logger.Warn("Ignoring shutdown dependencies of some instances", logger.Ctx{"err": err})
+	}
+
+	// Track when each instance is done shutting down.
+	instDone := make(map[string]chan struct{}, len(instances))
+	instIndex := make(map[string]int, len(instances))
+	for i, inst := range instances {
+		key := project.Instance(inst.Project().Name, inst.Name())
+		instDone[key] = make(chan struct{})
+		instIndex[key] = i
+	}
+
+	shutdownCheckpoint := s.GlobalConfig != nil && s.GlobalConfig.InstancesShutdownCheckpoint()
+
 	// Limit shutdown concurrency to number of instances or number of CPU cores (which ever is less).
 	var wg sync.WaitGroup
 	instShutdownCh := make(chan instance.Instance)
@@ -390,6 +435,16 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 	for i := 0; i < maxConcurrent; i++ {
 		go func(instShutdownCh <-chan instance.Instance) {
 			for inst := range instShutdownCh {
+				key := project.Instance(inst.Project().Name, inst.Name())
+
+				// Wait for the instances depending on this one which were dispatched before it.
+				for _, dependent := range dependents(inst) {
+					index, ok := instIndex[dependent]
+					if ok && index < instIndex[key] {
+						<-instDone[dependent]
+					}
+				}
+
 				// Determine how long to wait for the instance to shutdown cleanly.
 				timeoutSeconds := 30
 				value, ok := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
@@ -397,7 +452,7 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 					timeoutSeconds, _ = strconv.Atoi(value)
 				}
 
-				action := inst.ExpandedConfig()["boot.host_shutdown_action"]
+				action := instanceHostShutdownAction(inst, shutdownCheckpoint)
 				if action == "stateful-stop" {
 					err := inst.Stop(true)
 					if err != nil {
@@ -426,6 +481,7 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 					_ = inst.VolatileSet(map[string]string{"volatile.last_state.power": instance.PowerStateRunning})
 				}
 
+				close(instDone[key])
 				wg.Done()
 			}
 		}(instShutdownCh)
@@ -435,6 +491,7 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 	for i, inst := range instances {
 		// Skip stopped instances.
 		if !inst.IsRunning() {
+			close(instDone[project.Instance(inst.Project().Name, inst.Name())])
 			continue
 		}
 
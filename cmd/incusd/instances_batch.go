@@ -0,0 +1,389 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/client"
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+// instancesBatchDefaultParallelism is the number of actions of a batch running at the same time when not specified.
+const instancesBatchDefaultParallelism = 10
+
+// The batch endpoint must be registered before instanceCmd as its path would otherwise match an instance name.
+var instancesBatchCmd = APIEndpoint{
+	Name: "instancesBatch",
+	Path: "instances/batch",
+
+	Post: APIEndpointAction{Handler: instancesBatchPost, AccessHandler: allowAuthenticated},
+}
+
+// instancesBatchEntitlement returns the entitlement required to run a batch action.
+func instancesBatchEntitlement(action string) (auth.Entitlement, error) {
+	switch action {
+	case "start", "stop":
+		return auth.EntitlementCanUpdateState, nil
+	case "delete":
+		return auth.EntitlementCanEdit, nil
+	case "snapshot":
+		return auth.EntitlementCanManageSnapshots, nil
+	}
+
+	return "", fmt.Errorf("Unknown action %q", action)
+}
+
+// swagger:operation POST /1.0/instances/batch instances instances_batch_post
+//
+//	Run actions on instances
+//
+//	Runs a list of start, stop, delete or snapshot actions on instances.
+//	The actions run in parallel, up to the requested parallelism.
+//
+//	The status of each action is reported in the `results` field of the operation metadata.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: batch
+//	    description: Batch of actions
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstancesBatchPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancesBatchPost(d *Daemon, r *http.Request) response.Response {
+	// Don't mess with instances while in setup mode.
+	<-d.waitReady.Done()
+
+	s := d.State()
+	projectName := request.ProjectParam(r)
+
+	req := api.InstancesBatchPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Parallelism < 0 {
+		return response.BadRequest(fmt.Errorf("Invalid parallelism %d", req.Parallelism))
+	}
+
+	parallelism := req.Parallelism
+	if parallelism == 0 {
+		parallelism = instancesBatchDefaultParallelism
+	}
+
+	err = instancesBatchValidate(req.Actions)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Check permissions before running any of the actions.
+	resources := map[string][]api.URL{}
+	for _, action := range req.Actions {
+		entitlement, err := instancesBatchEntitlement(action.Action)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectInstance(projectName, action.Instance), entitlement)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		resources["instances"] = append(resources["instances"], *api.NewURL().Path(version.APIVersion, "instances", action.Instance))
+	}
+
+	do := func(op *operations.Operation) error {
+		results := instancesBatchRunAll(req.Actions, parallelism, func(action api.InstancesBatchAction) error {
+			return instancesBatchRun(s, r, op, projectName, action)
+		}, func(results []api.InstancesBatchResult) {
+			_ = op.ExtendMetadata(map[string]any{"results": results})
+		})
+
+		var failures []string
+		for _, result := range results {
+			if result.Status == api.Failure.String() {
+				failures = append(failures, fmt.Sprintf(" - Instance: %s: %s: %s", result.Instance, result.Action, result.Error))
+			}
+		}
+
+		if len(failures) > 0 {
+			return fmt.Errorf("The following instance actions failed:\n%s", strings.Join(failures, "\n"))
+		}
+
+		return nil
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstancesBatch, resources, nil, do, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// instancesBatchValidate checks the actions of a batch.
+func instancesBatchValidate(actions []api.InstancesBatchAction) error {
+	if len(actions) == 0 {
+		return fmt.Errorf("No actions provided")
+	}
+
+	seen := map[string]bool{}
+	for _, action := range actions {
+		if action.Instance == "" || internalInstance.IsSnapshot(action.Instance) {
+			return fmt.Errorf("Invalid instance name %q", action.Instance)
+		}
+
+		if seen[action.Instance] {
+			return fmt.Errorf("Instance %q is listed more than once", action.Instance)
+		}
+
+		seen[action.Instance] = true
+
+		_, err := instancesBatchEntitlement(action.Action)
+		if err != nil {
+			return err
+		}
+
+		if action.Action == "snapshot" && action.Snapshot != "" {
+			err = validate.IsURLSegmentSafe(action.Snapshot)
+			if err != nil {
+				return fmt.Errorf("Invalid snapshot name: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// instancesBatchRunAll runs the actions of a batch with at most parallelism of them at the same time.
+// The results are reported every time the status of an action changes.
+func instancesBatchRunAll(actions []api.InstancesBatchAction, parallelism int, run func(action api.InstancesBatchAction) error, report func(results []api.InstancesBatchResult)) []api.InstancesBatchResult {
+	results := make([]api.InstancesBatchResult, len(actions))
+	for i, action := range actions {
+		results[i] = api.InstancesBatchResult{
+			Instance: action.Instance,
+			Action:   action.Action,
+			Status:   api.Pending.String(),
+		}
+	}
+
+	resultsLock := sync.Mutex{}
+	setStatus := func(i int, status api.StatusCode, err error) {
+		resultsLock.Lock()
+		defer resultsLock.Unlock()
+
+		results[i].Status = status.String()
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+
+		report(slices.Clone(results))
+	}
+
+	report(slices.Clone(results))
+
+	// Bound the number of actions running at the same time.
+	slots := make(chan struct{}, parallelism)
+	wgAction := sync.WaitGroup{}
+
+	for i, action := range actions {
+		wgAction.Add(1)
+		slots <- struct{}{}
+
+		go func(i int, action api.InstancesBatchAction) {
+			defer wgAction.Done()
+			defer func() { <-slots }()
+
+			setStatus(i, api.Running, nil)
+
+			err := run(action)
+			if err != nil {
+				setStatus(i, api.Failure, err)
+				return
+			}
+
+			setStatus(i, api.Success, nil)
+		}(i, action)
+	}
+
+	wgAction.Wait()
+
+	return results
+}
+
+// instancesBatchRun runs a batch action on an instance, forwarding it to the cluster member running the instance if needed.
+func instancesBatchRun(s *state.State, r *http.Request, op *operations.Operation, projectName string, action api.InstancesBatchAction) error {
+	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, action.Instance, r, instancetype.Any)
+	if err != nil {
+		return err
+	}
+
+	if client != nil {
+		return instancesBatchRunRemote(client, action)
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, action.Instance)
+	if err != nil {
+		return err
+	}
+
+	inst.SetOperation(op)
+
+	switch action.Action {
+	case "start":
+		if inst.IsRunning() {
+			return nil
+		}
+
+		return doInstanceStatePut(inst, api.InstanceStatePut{Action: "start", Stateful: action.Stateful, Timeout: -1})
+
+	case "stop":
+		if !inst.IsRunning() {
+			return nil
+		}
+
+		timeout := action.Timeout
+		if timeout == 0 {
+			timeout = -1
+		}
+
+		return doInstanceStatePut(inst, api.InstanceStatePut{Action: "stop", Stateful: action.Stateful, Force: action.Force, Timeout: timeout})
+
+	case "delete":
+		if inst.IsRunning() {
+			if !action.Force {
+				return fmt.Errorf("Instance is running")
+			}
+
+			err = doInstanceStatePut(inst, api.InstanceStatePut{Action: "stop", Force: true})
+			if err != nil {
+				return err
+			}
+		}
+
+		return doInstanceDelete(s, inst, op)
+
+	case "snapshot":
+		p := inst.Project()
+		err = project.AllowSnapshotCreation(&p)
+		if err != nil {
+			return err
+		}
+
+		name := action.Snapshot
+		if name == "" {
+			name, err = instance.NextSnapshotName(s, inst, "snap%d")
+			if err != nil {
+				return err
+			}
+		}
+
+		expiry, err := internalInstance.GetExpiry(time.Now(), inst.ExpandedConfig()["snapshots.expiry"])
+		if err != nil {
+			return err
+		}
+
+		return inst.Snapshot(name, expiry, action.Stateful)
+	}
+
+	return fmt.Errorf("Unknown action %q", action.Action)
+}
+
+// instancesBatchRunRemote runs a batch action on an instance of another cluster member.
+func instancesBatchRunRemote(client incus.InstanceServer, action api.InstancesBatchAction) error {
+	inst, _, err := client.GetInstance(action.Instance)
+	if err != nil {
+		return err
+	}
+
+	var op incus.Operation
+
+	switch action.Action {
+	case "start":
+		if inst.StatusCode == api.Running {
+			return nil
+		}
+
+		op, err = client.UpdateInstanceState(action.Instance, api.InstanceStatePut{Action: "start", Stateful: action.Stateful, Timeout: -1}, "")
+
+	case "stop":
+		if inst.StatusCode != api.Running {
+			return nil
+		}
+
+		timeout := action.Timeout
+		if timeout == 0 {
+			timeout = -1
+		}
+
+		op, err = client.UpdateInstanceState(action.Instance, api.InstanceStatePut{Action: "stop", Stateful: action.Stateful, Force: action.Force, Timeout: timeout}, "")
+
+	case "delete":
+		if inst.StatusCode == api.Running {
+			if !action.Force {
+				return fmt.Errorf("Instance is running")
+			}
+
+			op, err = client.UpdateInstanceState(action.Instance, api.InstanceStatePut{Action: "stop", Force: true, Timeout: -1}, "")
+			if err != nil {
+				return err
+			}
+
+			err = op.Wait()
+			if err != nil {
+				return err
+			}
+		}
+
+		op, err = client.DeleteInstance(action.Instance)
+
+	case "snapshot":
+		op, err = client.CreateInstanceSnapshot(action.Instance, api.InstanceSnapshotsPost{Name: action.Snapshot, Stateful: action.Stateful})
+
+	default:
+		return fmt.Errorf("Unknown action %q", action.Action)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
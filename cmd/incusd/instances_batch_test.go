@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestInstancesBatchValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []api.InstancesBatchAction
+		err     string
+	}{
+		{
+			"Valid",
+			[]api.InstancesBatchAction{{Instance: "c1", Action: "start"}, {Instance: "c2", Action: "stop"}, {Instance: "c3", Action: "delete"}, {Instance: "c4", Action: "snapshot", Snapshot: "snap0"}},
+			"",
+		},
+		{"No actions", nil, "No actions provided"},
+		{"Missing instance", []api.InstancesBatchAction{{Action: "start"}}, `Invalid instance name ""`},
+		{"Snapshot instance", []api.InstancesBatchAction{{Instance: "c1/snap0", Action: "start"}}, `Invalid instance name "c1/snap0"`},
+		{"Duplicate instance", []api.InstancesBatchAction{{Instance: "c1", Action: "start"}, {Instance: "c1", Action: "stop"}}, `Instance "c1" is listed more than once`},
+		{"Unknown action", []api.InstancesBatchAction{{Instance: "c1", Action: "restart"}}, `Unknown action "restart"`},
+		{"Invalid snapshot name", []api.InstancesBatchAction{{Instance: "c1", Action: "snapshot", Snapshot: "snap/0"}}, "Invalid snapshot name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := instancesBatchValidate(tt.actions)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestInstancesBatchRunAll(t *testing.T) {
+	actions := []api.InstancesBatchAction{}
+	for i := 0; i < 10; i++ {
+		actions = append(actions, api.InstancesBatchAction{Instance: fmt.Sprintf("c%d", i), Action: "stop"})
+	}
+
+	lock := sync.Mutex{}
+	running := 0
+	maxRunning := 0
+	reports := 0
+
+	results := instancesBatchRunAll(actions, 3, func(action api.InstancesBatchAction) error {
+		lock.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+
+		if action.Instance == "c4" {
+			return fmt.Errorf("Instance is busy")
+		}
+
+		return nil
+	}, func(results []api.InstancesBatchResult) {
+		reports++
+	})
+
+	// At most the requested number of actions run at the same time.
+	require.Equal(t, 3, maxRunning)
+
+	// The results are reported initially and on each change of each action.
+	require.Equal(t, 1+2*len(actions), reports)
+
+	require.Len(t, results, len(actions))
+	for i, result := range results {
+		require.Equal(t, actions[i].Instance, result.Instance)
+		require.Equal(t, "stop", result.Action)
+
+		if result.Instance == "c4" {
+			require.Equal(t, api.Failure.String(), result.Status)
+			require.Equal(t, "Instance is busy", result.Error)
+			continue
+		}
+
+		require.Equal(t, api.Success.String(), result.Status)
+		require.Empty(t, result.Error)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// instanceDependencies returns the instances listed in boot.depends_on, keyed by project and name.
+func instanceDependencies(inst instance.Instance) []string {
+	value := inst.ExpandedConfig()["boot.depends_on"]
+	if value == "" {
+		return nil
+	}
+
+	deps := []string{}
+	for _, name := range util.SplitNTrimSpace(value, ",", -1, true) {
+		deps = append(deps, project.Instance(inst.Project().Name, name))
+	}
+
+	return deps
+}
+
+// instancesDependencyOrder sorts the instances so that each of them comes after the instances returned by
+// before, otherwise keeping their existing order. The instances which are part of a dependency cycle are
+// appended in their existing order and reported in the returned error.
+func instancesDependencyOrder(instances []instance.Instance, before func(inst instance.Instance) []string) ([]instance.Instance, error) {
+	present := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		present[project.Instance(inst.Project().Name, inst.Name())] = true
+	}
+
+	placed := make(map[string]bool, len(instances))
+	ordered := make([]instance.Instance, 0, len(instances))
+	remaining := slices.Clone(instances)
+
+	for len(remaining) > 0 {
+		next := -1
+		for i, inst := range remaining {
+			key := project.Instance(inst.Project().Name, inst.Name())
+
+			ready := true
+			for _, dep := range before(inst) {
+				if dep != key && present[dep] && !placed[dep] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			names := make([]string, 0, len(remaining))
+			for _, inst := range remaining {
+				names = append(names, project.Instance(inst.Project().Name, inst.Name()))
+			}
+
+			ordered = append(ordered, remaining...)
+
+			return ordered, fmt.Errorf("Dependency cycle between instances %s", strings.Join(names, ", "))
+		}
+
+		inst := remaining[next]
+		placed[project.Instance(inst.Project().Name, inst.Name())] = true
+		ordered = append(ordered, inst)
+		remaining = slices.Delete(remaining, next, next+1)
+	}
+
+	return ordered, nil
+}
+
+// instanceStopDependents returns a function listing, for each instance, the instances which depend on it
+// and must be shut down before it as they have boot.depends_on.reverse_stop enabled.
+func instanceStopDependents(instances []instance.Instance) func(inst instance.Instance) []string {
+	dependents := map[string][]string{}
+	for _, inst := range instances {
+		if !util.IsTrue(inst.ExpandedConfig()["boot.depends_on.reverse_stop"]) {
+			continue
+		}
+
+		key := project.Instance(inst.Project().Name, inst.Name())
+		for _, dep := range instanceDependencies(inst) {
+			dependents[dep] = append(dependents[dep], key)
+		}
+	}
+
+	return func(inst instance.Instance) []string {
+		return dependents[project.Instance(inst.Project().Name, inst.Name())]
+	}
+}
+
+// instanceWaitDependencies waits for the local dependencies of an instance to reach the state set in
+// boot.depends_on.wait, for at most boot.depends_on.timeout.
+func instanceWaitDependencies(s *state.State, inst instance.Instance) {
+	deps := instanceDependencies(inst)
+	if len(deps) == 0 {
+		return
+	}
+
+	config := inst.ExpandedConfig()
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	timeout := 300
+	if config["boot.depends_on.timeout"] != "" {
+		timeout, _ = strconv.Atoi(config["boot.depends_on.timeout"])
+	}
+
+	wait := config["boot.depends_on.wait"]
+	if wait == "" {
+		wait = "started"
+	}
+
+	ctx, cancel := context.WithTimeout(s.ShutdownCtx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	for _, name := range util.SplitNTrimSpace(config["boot.depends_on"], ",", -1, true) {
+		err := instanceWaitDependency(ctx, s, inst.Project().Name, name, wait)
+		if err != nil {
+			l.Warn("Starting instance without waiting for its dependency", logger.Ctx{"dependency": name, "wait": wait, "err": err})
+		}
+	}
+}
+
+// instanceWaitDependency waits for a local instance to reach the given state.
+func instanceWaitDependency(ctx context.Context, s *state.State, projectName string, name string, wait string) error {
+	for {
+		dep, err := instance.LoadByProjectAndName(s, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		if dep.Location() != "" && dep.Location() != s.ServerName {
+			// Only the local instances are started in order.
+			return nil
+		}
+
+		if !dep.IsRunning() {
+			return fmt.Errorf("Dependency isn't running")
+		}
+
+		switch wait {
+		case "started":
+			return nil
+		case "ready":
+			if util.IsTrue(dep.LocalConfig()["volatile.last_state.ready"]) {
+				return nil
+			}
+
+		case "cloud-init":
+			status, err := instanceCloudInitWait(ctx, dep)
+			if err == nil {
+				// A degraded status still means cloud-init completed.
+				if status != 0 && status != 2 {
+					return fmt.Errorf("cloud-init failed with status %d", status)
+				}
+
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Timed out")
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// instanceCloudInitWait waits for cloud-init to complete in the instance and returns its exit status.
+func instanceCloudInitWait(ctx context.Context, inst instance.Instance) (int, error) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return -1, err
+	}
+
+	defer func() { _ = devNull.Close() }()
+
+	cmd, err := inst.Exec(api.InstanceExecPost{
+		Command:     []string{"cloud-init", "status", "--wait"},
+		Environment: map[string]string{"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+	}, devNull, devNull, devNull)
+	if err != nil {
+		return -1, err
+	}
+
+	type result struct {
+		status int
+		err    error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		status, err := cmd.Wait()
+		done <- result{status: status, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.status, res.err
+	case <-ctx.Done():
+		_ = cmd.Signal(unix.SIGKILL)
+		return -1, ctx.Err()
+	}
+}
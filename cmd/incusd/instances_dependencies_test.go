@@ -0,0 +1,122 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// instanceDependenciesFake is an instance with a fixed configuration.
+type instanceDependenciesFake struct {
+	instance.Instance
+
+	name   string
+	config map[string]string
+}
+
+func (f *instanceDependenciesFake) Name() string { return f.name }
+func (f *instanceDependenciesFake) Project() api.Project {
+	return api.Project{Name: api.ProjectDefaultName}
+}
+func (f *instanceDependenciesFake) ExpandedConfig() map[string]string { return f.config }
+
+func instanceDependenciesNames(instances []instance.Instance) []string {
+	names := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		names = append(names, inst.Name())
+	}
+
+	return names
+}
+
+func TestInstancesDependencyOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		instances []*instanceDependenciesFake
+		order     []string
+		cycle     bool
+	}{
+		{
+			"No dependencies",
+			[]*instanceDependenciesFake{
+				{name: "c1"},
+				{name: "c2"},
+			},
+			[]string{"c1", "c2"},
+			false,
+		},
+		{
+			"Chain",
+			[]*instanceDependenciesFake{
+				{name: "app", config: map[string]string{"boot.depends_on": "db"}},
+				{name: "web", config: map[string]string{"boot.depends_on": "app"}},
+				{name: "db"},
+			},
+			[]string{"db", "app", "web"},
+			false,
+		},
+		{
+			"Multiple dependencies",
+			[]*instanceDependenciesFake{
+				{name: "web", config: map[string]string{"boot.depends_on": "db, cache"}},
+				{name: "cache"},
+				{name: "db"},
+			},
+			[]string{"cache", "db", "web"},
+			false,
+		},
+		{
+			"Missing and self dependencies",
+			[]*instanceDependenciesFake{
+				{name: "c1", config: map[string]string{"boot.depends_on": "c1,missing"}},
+				{name: "c2"},
+			},
+			[]string{"c1", "c2"},
+			false,
+		},
+		{
+			"Cycle",
+			[]*instanceDependenciesFake{
+				{name: "c1", config: map[string]string{"boot.depends_on": "c2"}},
+				{name: "c2", config: map[string]string{"boot.depends_on": "c1"}},
+				{name: "c3"},
+			},
+			[]string{"c3", "c1", "c2"},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instances := make([]instance.Instance, 0, len(tt.instances))
+			for _, inst := range tt.instances {
+				instances = append(instances, inst)
+			}
+
+			ordered, err := instancesDependencyOrder(instances, instanceDependencies)
+			if tt.cycle {
+				require.ErrorContains(t, err, "Dependency cycle")
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tt.order, instanceDependenciesNames(ordered))
+		})
+	}
+}
+
+func TestInstanceStopDependents(t *testing.T) {
+	instances := []instance.Instance{
+		&instanceDependenciesFake{name: "db"},
+		&instanceDependenciesFake{name: "app", config: map[string]string{"boot.depends_on": "db", "boot.depends_on.reverse_stop": "true"}},
+		&instanceDependenciesFake{name: "web", config: map[string]string{"boot.depends_on": "db"}},
+	}
+
+	// Only the dependents with reverse_stop enabled are shut down first.
+	ordered, err := instancesDependencyOrder(instances, instanceStopDependents(instances))
+	require.NoError(t, err)
+	require.Equal(t, []string{"app", "db", "web"}, instanceDependenciesNames(ordered))
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
 	"github.com/lxc/incus/v6/internal/server/state"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
@@ -50,6 +51,9 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //
 //  Returns a list of instances (URLs).
 //
+//  With the `stream` parameter, the connection is upgraded to a websocket
+//  receiving the resource usage of the instances at every interval instead.
+//
 //  ---
 //  produces:
 //    - application/json
@@ -65,9 +69,28 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //      type: string
 //      example: default
 //    - in: query
+//      name: limit
+//      description: Maximum number of entries to return (all of them if 0)
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: offset
+//      description: Number of entries to skip
+//      type: integer
+//      example: 200
+//    - in: query
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
+//    - in: query
+//      name: stream
+//      description: Stream the resource usage over a websocket
+//      type: boolean
+//    - in: query
+//      name: interval
+//      description: Interval of the resource usage stream (in seconds)
+//      type: integer
+//      example: 1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -123,6 +146,16 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //      type: string
 //      example: default
 //    - in: query
+//      name: limit
+//      description: Maximum number of entries to return (all of them if 0)
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: offset
+//      description: Number of entries to skip
+//      type: integer
+//      example: 200
+//    - in: query
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
@@ -180,6 +213,16 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //      type: string
 //      example: default
 //    - in: query
+//      name: limit
+//      description: Maximum number of entries to return (all of them if 0)
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: offset
+//      description: Number of entries to skip
+//      type: integer
+//      example: 200
+//    - in: query
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
@@ -215,6 +258,11 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 func instancesGet(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
+	// Stream the resource usage over a websocket if requested.
+	if util.IsTrue(request.QueryParam(r, "stream")) {
+		return instancesStateStream(s, r)
+	}
+
 	for i := 0; i < 100; i++ {
 		result, err := doInstancesGet(s, r)
 		if err == nil {
@@ -236,6 +284,12 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 }
 
 func doInstancesGet(s *state.State, r *http.Request) (any, error) {
+	return doInstancesGetSelected(s, r, nil)
+}
+
+// doInstancesGetSelected is doInstancesGet restricted to the instances for which selected returns true, if set,
+// so that only those instances get rendered.
+func doInstancesGetSelected(s *state.State, r *http.Request, selected func(inst db.Instance) bool) (any, error) {
 	resultFullList := []*api.InstanceFull{}
 	resultMu := sync.Mutex{}
 
@@ -257,6 +311,12 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 		return nil, fmt.Errorf("Invalid filter: %w", err)
 	}
 
+	// Parse pagination values.
+	limit, offset, err := localUtil.PaginationFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
 	mustLoadObjects := recursion > 0 || (recursion == 0 && clauses != nil && len(clauses.Clauses) > 0)
 
 	// Detect project mode.
@@ -320,6 +380,32 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 		memberAddressInstances[address] = filteredInstances
 	}
 
+	// Only keep the selected instances.
+	if selected != nil {
+		for address, instances := range memberAddressInstances {
+			var selectedInstances []db.Instance
+
+			for _, inst := range instances {
+				if selected(inst) {
+					selectedInstances = append(selectedInstances, inst)
+				}
+			}
+
+			if len(selectedInstances) == 0 {
+				delete(memberAddressInstances, address)
+				continue
+			}
+
+			memberAddressInstances[address] = selectedInstances
+		}
+	}
+
+	// Without filters, select the requested page before loading the instances so that only its instances get rendered.
+	paginated := (clauses == nil || len(clauses.Clauses) == 0) && (limit > 0 || offset > 0)
+	if paginated {
+		memberAddressInstances = instancesPaginate(memberAddressInstances, limit, offset)
+	}
+
 	resultErrListAppend := func(inst db.Instance, err error) {
 		instFull := &api.InstanceFull{
 			Instance: api.Instance{
@@ -372,6 +458,25 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 			go func(memberAddress string, instances []db.Instance) {
 				defer wg.Done()
 
+				// Only retrieve the selected instances or the instances of the page from the member.
+				if paginated || selected != nil {
+					cs, err := doInstancesGetByNameFromNode(instances, memberAddress, recursion > 1, networkCert, s.ServerCert(), r)
+					if err != nil {
+						for _, inst := range instances {
+							resultErrListAppend(inst, err)
+						}
+
+						return
+					}
+
+					for _, c := range cs {
+						c := c // Local variable for append.
+						resultFullListAppend(&c)
+					}
+
+					return
+				}
+
 				if recursion == 1 {
 					apiInsts, err := doContainersGetFromNode(filteredProjects, memberAddress, allProjects, networkCert, s.ServerCert(), r, instanceType)
 					if err != nil {
@@ -503,6 +608,10 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 		}
 	}
 
+	if !paginated {
+		resultFullList = localUtil.Paginate(resultFullList, limit, offset)
+	}
+
 	if recursion == 0 {
 		resultList := make([]string, 0, len(resultFullList))
 		for i := range resultFullList {
@@ -527,6 +636,89 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 
 // Fetch information about the containers on the given remote node, using the
 // rest API and with a timeout of 30 seconds.
+// instancesPaginate only keeps the instances of the requested page, sorted by project and then instance name.
+func instancesPaginate(memberAddressInstances map[string][]db.Instance, limit int, offset int) map[string][]db.Instance {
+	type memberInstance struct {
+		address  string
+		instance db.Instance
+	}
+
+	entries := []memberInstance{}
+	for address, instances := range memberAddressInstances {
+		for _, inst := range instances {
+			entries = append(entries, memberInstance{address: address, instance: inst})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].instance.Project == entries[j].instance.Project {
+			return entries[i].instance.Name < entries[j].instance.Name
+		}
+
+		return entries[i].instance.Project < entries[j].instance.Project
+	})
+
+	page := map[string][]db.Instance{}
+	for _, entry := range localUtil.Paginate(entries, limit, offset) {
+		page[entry.address] = append(page[entry.address], entry.instance)
+	}
+
+	return page
+}
+
+// doInstancesGetByNameFromNode retrieves the given instances from a cluster member, one at a time.
+func doInstancesGetByNameFromNode(dbInstances []db.Instance, node string, full bool, networkCert *localtls.CertInfo, serverCert *localtls.CertInfo, r *http.Request) ([]api.InstanceFull, error) {
+	f := func() ([]api.InstanceFull, error) {
+		client, err := cluster.Connect(node, networkCert, serverCert, r, true)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to connect to member %s: %w", node, err)
+		}
+
+		instances := make([]api.InstanceFull, 0, len(dbInstances))
+		for _, dbInst := range dbInstances {
+			projectClient := client.UseProject(dbInst.Project)
+
+			if full {
+				inst, _, err := projectClient.GetInstanceFull(dbInst.Name)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get instance %q from member %s: %w", dbInst.Name, node, err)
+				}
+
+				instances = append(instances, *inst)
+				continue
+			}
+
+			inst, _, err := projectClient.GetInstance(dbInst.Name)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get instance %q from member %s: %w", dbInst.Name, node, err)
+			}
+
+			instances = append(instances, api.InstanceFull{Instance: *inst})
+		}
+
+		return instances, nil
+	}
+
+	timeout := time.After(30 * time.Second)
+	done := make(chan struct{})
+
+	var instances []api.InstanceFull
+	var err error
+
+	go func() {
+		instances, err = f()
+		done <- struct{}{}
+	}()
+
+	select {
+	case <-timeout:
+		err = fmt.Errorf("Timeout getting instances from member %s", node)
+	case <-done:
+	}
+
+	return instances, err
+}
+
 func doContainersGetFromNode(projects []string, node string, allProjects bool, networkCert *localtls.CertInfo, serverCert *localtls.CertInfo, r *http.Request, instanceType instancetype.Type) ([]api.Instance, error) {
 	f := func() ([]api.Instance, error) {
 		client, err := cluster.Connect(node, networkCert, serverCert, r, true)
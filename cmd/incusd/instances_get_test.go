@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+)
+
+func TestInstancesPaginate(t *testing.T) {
+	memberAddressInstances := map[string][]db.Instance{
+		"":         {{Name: "c3", Project: "default"}, {Name: "c1", Project: "default"}},
+		"10.0.0.2": {{Name: "c2", Project: "default"}, {Name: "c1", Project: "foo"}},
+		"0.0.0.0":  {{Name: "c4", Project: "default"}},
+	}
+
+	page := instancesPaginate(memberAddressInstances, 2, 1)
+	require.Equal(t, map[string][]db.Instance{
+		"":         {{Name: "c3", Project: "default"}},
+		"10.0.0.2": {{Name: "c2", Project: "default"}},
+	}, page)
+
+	page = instancesPaginate(memberAddressInstances, 0, 4)
+	require.Equal(t, map[string][]db.Instance{
+		"10.0.0.2": {{Name: "c1", Project: "foo"}},
+	}, page)
+
+	require.Empty(t, instancesPaginate(memberAddressInstances, 2, 5))
+}
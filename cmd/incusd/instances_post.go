@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 
 	petname "github.com/dustinkirkland/golang-petname"
@@ -28,6 +29,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/scheduler"
 	"github.com/lxc/incus/v6/internal/server/scriptlet"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
@@ -41,7 +43,7 @@ import (
 	"github.com/lxc/incus/v6/shared/util"
 )
 
-func ensureDownloadedImageFitWithinBudget(ctx context.Context, s *state.State, r *http.Request, op *operations.Operation, p api.Project, img *api.Image, imgAlias string, source api.InstanceSource, imgType string) (*api.Image, error) {
+func ensureDownloadedImageFitWithinBudget(ctx context.Context, s *state.State, r *http.Request, op *operations.Operation, p api.Project, img *api.Image, imgAlias string, source api.InstanceSource, imgType string, architecture string) (*api.Image, error) {
 	var autoUpdate bool
 	var err error
 	if p.Config["images.auto_update_cached"] != "" {
@@ -72,6 +74,7 @@ func ensureDownloadedImageFitWithinBudget(ctx context.Context, s *state.State, r
 		PreferCached: true,
 		ProjectName:  p.Name,
 		Budget:       budget,
+		Architecture: architecture,
 	})
 	if err != nil {
 		return nil, err
@@ -105,7 +108,7 @@ func createFromImage(s *state.State, r *http.Request, p api.Project, profiles []
 		}
 
 		if req.Source.Server != "" {
-			img, err = ensureDownloadedImageFitWithinBudget(context.TODO(), s, r, op, p, img, imgAlias, req.Source, string(req.Type))
+			img, err = ensureDownloadedImageFitWithinBudget(context.TODO(), s, r, op, p, img, imgAlias, req.Source, string(req.Type), req.Architecture)
 			if err != nil {
 				return err
 			}
@@ -196,6 +199,58 @@ func createFromNone(s *state.State, r *http.Request, projectName string, profile
 	return operations.OperationResponse(op)
 }
 
+func createFromVolume(s *state.State, r *http.Request, projectName string, profiles []api.Profile, req *api.InstancesPost) response.Response {
+	if s.DB.Cluster.LocalNodeIsEvacuated() {
+		return response.Forbidden(fmt.Errorf("Cluster member is evacuated"))
+	}
+
+	if req.Type != api.InstanceTypeVM {
+		return response.BadRequest(fmt.Errorf("Only virtual machines can be created from a custom volume"))
+	}
+
+	devices := deviceConfig.NewDevices(req.Devices)
+
+	args := db.InstanceArgs{
+		Project:     projectName,
+		Config:      req.Config,
+		Type:        instancetype.VM,
+		Description: req.Description,
+		Devices:     deviceConfig.ApplyDeviceInitialValues(devices, profiles),
+		Ephemeral:   req.Ephemeral,
+		Name:        req.Name,
+		Profiles:    profiles,
+	}
+
+	if req.Architecture != "" {
+		architecture, err := osarch.ArchitectureId(req.Architecture)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		args.Architecture = architecture
+	}
+
+	run := func(op *operations.Operation) error {
+		// Actually create the instance.
+		_, err := instanceCreateFromVolume(s, args, req.Source.Pool, req.Source.Source, op)
+		if err != nil {
+			return err
+		}
+
+		return instanceCreateFinish(s, req, args)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", req.Name)}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceCreate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
 func createFromMigration(ctx context.Context, s *state.State, r *http.Request, projectName string, profiles []api.Profile, req *api.InstancesPost) response.Response {
 	if s.DB.Cluster.LocalNodeIsEvacuated() && r != nil && r.Context().Value(request.CtxProtocol) != "cluster" {
 		return response.Forbidden(fmt.Errorf("Cluster member is evacuated"))
@@ -563,7 +618,8 @@ func createFromCopy(ctx context.Context, s *state.State, r *http.Request, projec
 	return operations.OperationResponse(op)
 }
 
-func createFromBackup(s *state.State, r *http.Request, projectName string, data io.Reader, pool string, instanceName string) response.Response {
+// createFromBackup imports an instance from a backup tarball.
+func createFromBackup(s *state.State, r *http.Request, projectName string, data io.Reader, pool string, instanceName string, networkState bool) response.Response {
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -735,6 +791,19 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 
 		runRevert.Add(revertHook)
 
+		// Recreate the network dependencies before the instance, as its NICs may refer to them.
+		networkReport, err := backupNetworkStateRestore(s, r, bInfo.Project, bInfo.Config, networkState, runRevert)
+		if err != nil {
+			return fmt.Errorf("Failed restoring network state: %w", err)
+		}
+
+		if len(networkReport) > 0 {
+			err = op.UpdateMetadata(map[string]any{"network_state": networkReport})
+			if err != nil {
+				return err
+			}
+		}
+
 		err = internalImportFromBackup(context.TODO(), s, bInfo.Project, bInfo.Name, instanceName != "")
 		if err != nil {
 			return fmt.Errorf("Failed importing backup: %w", err)
@@ -828,7 +897,7 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 
 	// If we're getting binary content, process separately
 	if r.Header.Get("Content-Type") == "application/octet-stream" {
-		return createFromBackup(s, r, targetProjectName, r.Body, r.Header.Get("X-Incus-pool"), r.Header.Get("X-Incus-name"))
+		return createFromBackup(s, r, targetProjectName, r.Body, r.Header.Get("X-Incus-pool"), r.Header.Get("X-Incus-name"), util.IsTrue(r.Header.Get("X-Incus-network-state")))
 	}
 
 	// Parse the request
@@ -873,6 +942,9 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Keep track of the architecture requested by the client as it's later set from the source image.
+	requestedArchitecture := req.Architecture
+
 	var targetProject *api.Project
 	var profiles []api.Profile
 	var sourceInst *dbCluster.Instance
@@ -945,6 +1017,11 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 				}
 			}
 
+		case "volume":
+			if req.Source.Source == "" {
+				return api.StatusErrorf(http.StatusBadRequest, "Must specify a source volume")
+			}
+
 		case "image":
 			// Check if the image has an entry in the database but fail only if the error
 			// is different than the image not being found.
@@ -1029,35 +1106,89 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			logger.Debug("No name provided for new instance, using auto-generated name", logger.Ctx{"project": targetProjectName, "instance": req.Name})
 		}
 
-		if s.ServerClustered && !clusterNotification && targetMemberInfo == nil {
+		// Work out the architectures usable for the new instance, also checking that images can run on a requested member.
+		if s.ServerClustered && !clusterNotification && (targetMemberInfo == nil || req.Source.Type == "image") {
 			architectures, err := instance.SuitableArchitectures(ctx, s, tx, targetProjectName, sourceInst, sourceImageRef, req)
 			if err != nil {
 				return err
 			}
 
-			// If no architectures have been ascertained from the source then use the default
-			// architecture from project or global config if available.
-			if len(architectures) < 1 {
-				defaultArch := targetProject.Config["images.default_architecture"]
-				if defaultArch == "" {
-					defaultArch = s.GlobalConfig.ImagesDefaultArchitecture()
+			// Restrict image sources to the architecture requested by the client.
+			if req.Source.Type == "image" && requestedArchitecture != "" && len(architectures) > 0 {
+				requestedArchitectureID, err := osarch.ArchitectureId(requestedArchitecture)
+				if err != nil {
+					return api.StatusErrorf(http.StatusBadRequest, "Invalid architecture %q", requestedArchitecture)
 				}
 
-				if defaultArch != "" {
-					defaultArchID, err := osarch.ArchitectureId(defaultArch)
+				if !slices.Contains(architectures, requestedArchitectureID) {
+					return api.StatusErrorf(http.StatusBadRequest, "Image %q isn't available for the %q architecture (available: %s)", instanceSourceImageName(req.Source), requestedArchitecture, architectureNames(architectures))
+				}
+
+				architectures = []int{requestedArchitectureID}
+			}
+
+			// Check that the requested cluster member can run the image.
+			if targetMemberInfo != nil {
+				if len(architectures) > 0 {
+					supportedArchitectures, err := targetMemberInfo.SupportedArchitectures()
 					if err != nil {
 						return err
 					}
 
-					architectures = append(architectures, defaultArchID)
-				} else {
-					architectures = nil // Don't exclude candidate members based on architecture.
+					if !slices.ContainsFunc(architectures, func(arch int) bool { return slices.Contains(supportedArchitectures, arch) }) {
+						return api.StatusErrorf(http.StatusBadRequest, "Cluster member %q (%s) doesn't support the architectures available for the source (%s)", targetMemberInfo.Name, architectureNames([]int{targetMemberInfo.Architecture}), architectureNames(architectures))
+					}
+				}
+			} else {
+				// If no architectures have been ascertained from the source then use the default
+				// architecture from project or global config if available.
+				if len(architectures) < 1 {
+					defaultArch := targetProject.Config["images.default_architecture"]
+					if defaultArch == "" {
+						defaultArch = s.GlobalConfig.ImagesDefaultArchitecture()
+					}
+
+					if defaultArch != "" {
+						defaultArchID, err := osarch.ArchitectureId(defaultArch)
+						if err != nil {
+							return err
+						}
+
+						architectures = append(architectures, defaultArchID)
+					} else {
+						architectures = nil // Don't exclude candidate members based on architecture.
+					}
+				}
+
+				clusterGroupsAllowed := project.GetRestrictedClusterGroups(targetProject)
+
+				candidateMembers, err = tx.GetCandidateMembers(ctx, allMembers, architectures, targetGroupName, clusterGroupsAllowed, s.GlobalConfig.OfflineThreshold())
+				if err != nil {
+					return err
 				}
-			}
 
-			clusterGroupsAllowed := project.GetRestrictedClusterGroups(targetProject)
+				// Report when the architecture is the reason no member can be used.
+				if len(candidateMembers) == 0 && architectures != nil {
+					anyArchMembers, err := tx.GetCandidateMembers(ctx, allMembers, nil, targetGroupName, clusterGroupsAllowed, s.GlobalConfig.OfflineThreshold())
+					if err != nil {
+						return err
+					}
 
-			candidateMembers, err = tx.GetCandidateMembers(ctx, allMembers, architectures, targetGroupName, clusterGroupsAllowed, s.GlobalConfig.OfflineThreshold())
+					if len(anyArchMembers) > 0 {
+						return api.StatusErrorf(http.StatusBadRequest, "No available cluster member supports the architectures available for the source (%s)", architectureNames(architectures))
+					}
+				}
+
+				candidateMembers, err = instanceGroupFilterMembers(ctx, tx, targetProjectName, req.Name, db.ExpandInstanceConfig(req.Config, profiles), candidateMembers, "")
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Check that the requested cluster member satisfies the policy of the instance group.
+		if s.ServerClustered && !clusterNotification && targetMemberInfo != nil {
+			_, err = instanceGroupFilterMembers(ctx, tx, targetProjectName, req.Name, db.ExpandInstanceConfig(req.Config, profiles), []db.NodeInfo{*targetMemberInfo}, "")
 			if err != nil {
 				return err
 			}
@@ -1083,9 +1214,25 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	if !clusterNotification {
+		err = instanceRefreshConfigCheck(s, r, nil, req.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if s.ServerClustered && !clusterNotification && targetMemberInfo == nil {
-		// Run instance placement scriptlet if enabled and no cluster member selected yet.
-		if s.GlobalConfig.InstancesPlacementScriptlet() != "" {
+		// Only consider the members with enough free huge pages for virtual machines using them.
+		if req.Type == api.InstanceTypeVM {
+			candidateMembers, err = instanceHugepagesFilterMembers(s, db.ExpandInstanceConfig(req.Config, profiles), candidateMembers)
+			if err != nil {
+				return response.SmartError(err)
+			}
+		}
+
+		// Run instance placement scriptlet or external placement if enabled and no cluster member selected yet.
+		externalEndpoint, _, _ := s.GlobalConfig.SchedulerExternal()
+		if s.GlobalConfig.InstancesPlacementScriptlet() != "" || externalEndpoint != "" {
 			leaderAddress, err := d.gateway.LeaderAddress()
 			if err != nil {
 				return response.InternalError(err)
@@ -1101,9 +1248,15 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			reqExpanded.Config = db.ExpandInstanceConfig(reqExpanded.Config, profiles)
 			reqExpanded.Devices = db.ExpandInstanceDevices(deviceConfig.NewDevices(reqExpanded.Devices), profiles).CloneNative()
 
-			targetMemberInfo, err = scriptlet.InstancePlacementRun(r.Context(), logger.Log, s, &reqExpanded, candidateMembers, leaderAddress)
-			if err != nil {
-				return response.SmartError(fmt.Errorf("Failed instance placement scriptlet: %w", err))
+			if s.GlobalConfig.InstancesPlacementScriptlet() != "" {
+				targetMemberInfo, err = scriptlet.InstancePlacementRun(r.Context(), logger.Log, s, &reqExpanded, candidateMembers, leaderAddress)
+				if err != nil {
+					return response.SmartError(fmt.Errorf("Failed instance placement scriptlet: %w", err))
+				}
+			}
+
+			if targetMemberInfo == nil {
+				targetMemberInfo = scheduler.ExternalPlacementRun(r.Context(), logger.Log, s, &reqExpanded, candidateMembers, leaderAddress)
 			}
 		}
 
@@ -1119,6 +1272,50 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Pick the variant of the local image matching the architectures of the server that will create the instance.
+	if req.Source.Type == "image" && req.Source.Server == "" && sourceImage != nil && !clusterNotification && (targetMemberInfo != nil || !s.ServerClustered) {
+		supportedArchitectures := s.OS.Architectures
+		location := "this server"
+		if targetMemberInfo != nil {
+			supportedArchitectures, err = targetMemberInfo.SupportedArchitectures()
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			location = fmt.Sprintf("cluster member %q", targetMemberInfo.Name)
+		}
+
+		var variant *api.Image
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			variant, err = instanceImageVariant(ctx, tx, targetProjectName, sourceImage, req.Source.Alias != "" && req.Source.Fingerprint == "", supportedArchitectures, requestedArchitecture)
+			return err
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if variant == nil {
+			return response.BadRequest(fmt.Errorf("Image %q isn't available for the architectures supported by %s (%s)", instanceSourceImageName(req.Source), location, architectureNames(supportedArchitectures)))
+		}
+
+		if variant.Fingerprint != sourceImage.Fingerprint {
+			logger.Debug("Using image variant matching the server architecture", logger.Ctx{"image": instanceSourceImageName(req.Source), "fingerprint": variant.Fingerprint, "architecture": variant.Architecture})
+
+			sourceImage = variant
+			sourceImageRef = variant.Fingerprint
+			req.Source.Fingerprint = variant.Fingerprint
+		}
+
+		if req.Architecture != "" {
+			req.Architecture = sourceImage.Architecture
+		}
+	}
+
+	// Remote images are downloaded by the server creating the instance, for its own architecture unless requested otherwise.
+	if req.Source.Type == "image" && req.Source.Server != "" && !clusterNotification {
+		req.Architecture = requestedArchitecture
+	}
+
 	// Record the cluster group as a volatile config key if present.
 	if !clusterNotification && targetGroupName != "" {
 		req.Config["volatile.cluster.group"] = targetGroupName
@@ -1152,11 +1349,82 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return createFromMigration(r.Context(), s, r, targetProjectName, profiles, &req)
 	case "copy":
 		return createFromCopy(r.Context(), s, r, targetProjectName, profiles, &req)
+	case "volume":
+		return createFromVolume(s, r, targetProjectName, profiles, &req)
 	default:
 		return response.BadRequest(fmt.Errorf("Unknown source type %s", req.Source.Type))
 	}
 }
 
+// instanceSourceImageName returns the name of the image used as an instance source, for use in error messages.
+func instanceSourceImageName(source api.InstanceSource) string {
+	if source.Alias != "" {
+		return source.Alias
+	}
+
+	return source.Fingerprint
+}
+
+// architectureNames returns a comma-separated list of architecture names.
+func architectureNames(architectures []int) string {
+	names := make([]string, 0, len(architectures))
+	for _, arch := range architectures {
+		name, err := osarch.ArchitectureName(arch)
+		if err != nil {
+			name = strconv.Itoa(arch)
+		}
+
+		names = append(names, name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// instanceImageVariant returns the local image to use on a server supporting the given architectures, or nil if none fits.
+// The resolved image is preferred, other architectures are only considered when resolving an alias.
+func instanceImageVariant(ctx context.Context, tx *db.ClusterTx, projectName string, img *api.Image, byAlias bool, supportedArchitectures []int, requestedArchitecture string) (*api.Image, error) {
+	wantedArchitectures := supportedArchitectures
+	if requestedArchitecture != "" {
+		requestedArchitectureID, err := osarch.ArchitectureId(requestedArchitecture)
+		if err != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid architecture %q", requestedArchitecture)
+		}
+
+		if !slices.Contains(supportedArchitectures, requestedArchitectureID) {
+			return nil, nil
+		}
+
+		wantedArchitectures = []int{requestedArchitectureID}
+	}
+
+	imgArchitecture, err := osarch.ArchitectureId(img.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(wantedArchitectures, imgArchitecture) {
+		return img, nil
+	}
+
+	if !byAlias {
+		return nil, nil
+	}
+
+	variants, err := instance.ImageArchitectureVariants(ctx, tx, projectName, img)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arch := range wantedArchitectures {
+		variant, found := variants[arch]
+		if found {
+			return variant, nil
+		}
+	}
+
+	return nil, nil
+}
+
 func instanceFindStoragePool(ctx context.Context, s *state.State, projectName string, req *api.InstancesPost) (string, string, string, map[string]string, response.Response) {
 	// Grab the container's root device if one is specified
 	storagePool := ""
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+)
+
+// instanceShutdownFake is an instance of a fixed type and configuration.
+type instanceShutdownFake struct {
+	instance.Instance
+
+	instanceType instancetype.Type
+	config       map[string]string
+}
+
+func (f *instanceShutdownFake) Type() instancetype.Type           { return f.instanceType }
+func (f *instanceShutdownFake) ExpandedConfig() map[string]string { return f.config }
+
+func TestInstanceHostShutdownAction(t *testing.T) {
+	tests := []struct {
+		name               string
+		instanceType       instancetype.Type
+		config             map[string]string
+		shutdownCheckpoint bool
+		expected           string
+	}{
+		{"Default", instancetype.Container, map[string]string{}, false, ""},
+		{"Configured action", instancetype.Container, map[string]string{"boot.host_shutdown_action": "force-stop"}, false, "force-stop"},
+		{"Checkpoint disabled on the server", instancetype.Container, map[string]string{"security.criu": "true", "boot.host_shutdown_action": "force-stop"}, false, "force-stop"},
+		{"Checkpoint disabled on the container", instancetype.Container, map[string]string{"security.criu": "false"}, true, ""},
+		{"Checkpoint", instancetype.Container, map[string]string{"security.criu": "true", "boot.host_shutdown_action": "force-stop"}, true, "stateful-stop"},
+		{"Virtual machines aren't checkpointed", instancetype.VM, map[string]string{"security.criu": "true"}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst := &instanceShutdownFake{instanceType: tt.instanceType, config: tt.config}
+			require.Equal(t, tt.expected, instanceHostShutdownAction(inst, tt.shutdownCheckpoint))
+		})
+	}
+}
@@ -113,6 +113,10 @@ func main() {
 	forkconsoleCmd := cmdForkconsole{global: &globalCmd}
 	app.AddCommand(forkconsoleCmd.Command())
 
+	// forkcoredump sub-command
+	forkcoredumpCmd := cmdForkCoreDump{global: &globalCmd}
+	app.AddCommand(forkcoredumpCmd.Command())
+
 	// forkexec sub-command
 	forkexecCmd := cmdForkexec{global: &globalCmd}
 	app.AddCommand(forkexecCmd.Command())
@@ -0,0 +1,376 @@
+package main
+
+/*
+#include "config.h"
+
+#include <errno.h>
+#include <fcntl.h>
+#include <grp.h>
+#include <sched.h>
+#include <stdbool.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/types.h>
+#include <unistd.h>
+
+#include "file_utils.h"
+#include "incus.h"
+#include "memory_utils.h"
+
+// forkcoredump_write writes the core dump read from stdin to a file as the crashing task would, from within its
+// mount namespace, root and working directory and with its filesystem IDs.
+static void forkcoredump_write(void)
+{
+	__do_close int ns_fd = -EBADF, root_fd = -EBADF, cwd_fd = -EBADF, fd = -EBADF;
+	char buf[4096];
+	char *path;
+	pid_t pid;
+	uid_t uid;
+	gid_t gid;
+	ssize_t n;
+
+	pid = atoi(advance_arg(true));
+	uid = atoi(advance_arg(true));
+	gid = atoi(advance_arg(true));
+	path = advance_arg(true);
+
+	snprintf(buf, sizeof(buf), "/proc/%d/root", pid);
+	root_fd = open(buf, O_PATH | O_RDONLY | O_CLOEXEC | O_NOFOLLOW);
+	if (root_fd < 0) {
+		error("error: open root");
+		_exit(EXIT_FAILURE);
+	}
+
+	snprintf(buf, sizeof(buf), "/proc/%d/cwd", pid);
+	cwd_fd = open(buf, O_PATH | O_RDONLY | O_CLOEXEC);
+	if (cwd_fd < 0) {
+		error("error: open cwd");
+		_exit(EXIT_FAILURE);
+	}
+
+	ns_fd = pidfd_nsfd(-EBADF, pid);
+	if (ns_fd < 0)
+		_exit(EXIT_FAILURE);
+
+	if (!change_namespaces(-EBADF, ns_fd, CLONE_NEWNS)) {
+		error("error: setns");
+		_exit(EXIT_FAILURE);
+	}
+
+	if (fchdir(root_fd) < 0 || chroot(".") < 0) {
+		error("error: chroot");
+		_exit(EXIT_FAILURE);
+	}
+
+	if (fchdir(cwd_fd) < 0) {
+		error("error: fchdir");
+		_exit(EXIT_FAILURE);
+	}
+
+	// Drop all privileges before creating the file.
+	if (setgroups(0, NULL) < 0 || setresgid(gid, gid, gid) < 0 || setresuid(uid, uid, uid) < 0) {
+		error("error: drop privileges");
+		_exit(EXIT_FAILURE);
+	}
+
+	fd = open(path, O_WRONLY | O_CREAT | O_EXCL | O_NOFOLLOW | O_CLOEXEC, 0600);
+	if (fd < 0) {
+		error("error: open core dump");
+		_exit(EXIT_FAILURE);
+	}
+
+	for (;;) {
+		n = read(STDIN_FILENO, buf, sizeof(buf));
+		if (n == 0)
+			break;
+
+		if (n < 0) {
+			if (errno == EINTR)
+				continue;
+
+			error("error: read");
+			_exit(EXIT_FAILURE);
+		}
+
+		if (write_nointr(fd, buf, n) != n) {
+			error("error: write");
+			_exit(EXIT_FAILURE);
+		}
+	}
+
+	_exit(EXIT_SUCCESS);
+}
+
+void forkcoredump(void)
+{
+	char *cur = NULL;
+
+	// Only the write subcommand is handled here, the rest is left to the Go code.
+	cur = advance_arg(false);
+	if (cur == NULL || strcmp(cur, "write") != 0)
+		return;
+
+	// Check that we're root.
+	if (geteuid() != 0) {
+		fprintf(stderr, "Error: forkcoredump requires root privileges\n");
+		_exit(EXIT_FAILURE);
+	}
+
+	forkcoredump_write();
+}
+*/
+import "C"
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus/v6/client"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+type cmdForkCoreDump struct {
+	global *cmdGlobal
+}
+
+func (c *cmdForkCoreDump) Command() *cobra.Command {
+	// Main subcommand
+	cmd := &cobra.Command{}
+	cmd.Use = "forkcoredump <path> <specifiers> <values>... <command>"
+	cmd.Short = "Handle the core dump of a process"
+	cmd.Long = `Description:
+  Handle the core dump of a process
+
+  This internal command is run by the kernel through kernel.core_pattern.
+  The core dumps of container processes with core dumps enabled are stored
+  by the daemon, others are handed over to the previous core dump handler.
+
+  The specifiers are the letters of the core dump pattern specifiers whose
+  values follow, the command name being always passed last.
+
+  The write subcommand writes a core dump to a file within the mount
+  namespace and with the filesystem IDs of the crashing task.
+`
+	cmd.RunE = c.Run
+	cmd.Hidden = true
+
+	return cmd
+}
+
+func (c *cmdForkCoreDump) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	if len(args) < 3 {
+		_ = cmd.Help()
+
+		if len(args) == 0 {
+			return nil
+		}
+
+		return fmt.Errorf("Missing required arguments")
+	}
+
+	// Only root should run this
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("This must be run as root")
+	}
+
+	// The kernel runs the handler with an empty environment.
+	err := os.Setenv("INCUS_DIR", args[0])
+	if err != nil {
+		return err
+	}
+
+	values, err := coreDumpHandlerValues(args[1:])
+	if err != nil {
+		return err
+	}
+
+	captured, err := c.capture(values)
+	if captured {
+		return err
+	}
+
+	return c.forward(values)
+}
+
+// capture sends the core dump to the daemon if it belongs to a container with core dumps enabled.
+func (c *cmdForkCoreDump) capture(values map[byte]string) (bool, error) {
+	d, err := incus.ConnectIncusUnix("", &incus.ConnectionArgs{SkipGetServer: true})
+	if err != nil {
+		return false, nil
+	}
+
+	query := url.Values{}
+	query.Set("pid", values['P'])
+	query.Set("signal", values['s'])
+	query.Set("time", values['t'])
+	query.Set("limit", values['c'])
+	query.Set("comm", values['e'])
+
+	// Check the core dump is wanted before consuming it.
+	_, _, err = d.RawQuery("GET", "/internal/coredumps?"+query.Encode(), nil, "")
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed checking core dump handling: %v\n", err)
+		}
+
+		return false, nil
+	}
+
+	_, _, err = d.RawQuery("POST", "/internal/coredumps?"+query.Encode(), os.Stdin, "")
+	if err != nil {
+		return true, fmt.Errorf("Failed storing core dump: %w", err)
+	}
+
+	return true, nil
+}
+
+// forward hands the core dump over to the handler configured before the daemon's.
+func (c *cmdForkCoreDump) forward(values map[byte]string) error {
+	content, err := os.ReadFile(coreDumpSavedPatternPath())
+	if err != nil {
+		return nil
+	}
+
+	pattern := strings.TrimSpace(string(content))
+	if pattern == "" {
+		return nil
+	}
+
+	// Pipe to the previous handler.
+	if strings.HasPrefix(pattern, "|") {
+		fields := strings.Fields(strings.TrimPrefix(pattern, "|"))
+		if len(fields) == 0 {
+			return nil
+		}
+
+		for i := range fields {
+			fields[i] = coreDumpPatternExpand(fields[i], values)
+		}
+
+		cmd := exec.Command(fields[0], fields[1:]...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		return cmd.Run()
+	}
+
+	// Write the core dump to a file.
+	if values['c'] == "0" {
+		return nil
+	}
+
+	// The file is created by a subprocess running as the crashing task within its mount namespace, so that it
+	// can't be used to create files the task itself couldn't.
+	content, err = os.ReadFile(fmt.Sprintf("/proc/%s/status", values['P']))
+	if err != nil {
+		return err
+	}
+
+	uid, gid, err := coreDumpStatusFSIDs(content)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("/proc/self/exe", "forkcoredump", "write", values['P'], strconv.FormatInt(uid, 10), strconv.FormatInt(gid, 10), coreDumpPatternExpand(pattern, values))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// coreDumpHandlerValues returns the values of the core dump pattern specifiers passed to the handler.
+// The first argument lists the specifiers whose values follow in the same order, the remaining arguments are the
+// command name which may have been split on spaces by the kernel.
+func coreDumpHandlerValues(args []string) (map[byte]string, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Missing core dump specifiers")
+	}
+
+	specifiers := args[0]
+	if len(args) < len(specifiers)+2 {
+		return nil, fmt.Errorf("Missing values for core dump specifiers %q", specifiers)
+	}
+
+	values := map[byte]string{}
+	for i := 0; i < len(specifiers); i++ {
+		values[specifiers[i]] = args[i+1]
+	}
+
+	values['e'] = strings.Join(args[len(specifiers)+1:], " ")
+
+	return values, nil
+}
+
+// coreDumpStatusFSIDs returns the filesystem user and group IDs from the content of a /proc/<pid>/status file.
+func coreDumpStatusFSIDs(content []byte) (int64, int64, error) {
+	ids := map[string]int64{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found || (key != "Uid" && key != "Gid") {
+			continue
+		}
+
+		// The fields are the real, effective, saved and filesystem IDs.
+		fields := strings.Fields(value)
+		if len(fields) != 4 {
+			return -1, -1, fmt.Errorf("Invalid %s line %q", key, scanner.Text())
+		}
+
+		id, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Invalid %s line %q: %w", key, scanner.Text(), err)
+		}
+
+		ids[key] = id
+	}
+
+	uid, foundUID := ids["Uid"]
+	gid, foundGID := ids["Gid"]
+	if !foundUID || !foundGID {
+		return -1, -1, fmt.Errorf("Missing user or group IDs")
+	}
+
+	return uid, gid, nil
+}
+
+// coreDumpPatternExpand replaces the specifiers of a core dump pattern with their values.
+// Specifiers without a known value are removed.
+func coreDumpPatternExpand(pattern string, values map[byte]string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			b.WriteByte(pattern[i])
+			continue
+		}
+
+		i++
+		if i == len(pattern) {
+			break
+		}
+
+		if pattern[i] == '%' {
+			b.WriteByte('%')
+			continue
+		}
+
+		b.WriteString(values[pattern[i]])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+)
+
+func TestCoreDumpStatusFSIDs(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		uid        int64
+		gid        int64
+		shouldFail bool
+	}{
+		{"Same IDs", "Name:\tbash\nUid:\t1000\t1000\t1000\t1000\nGid:\t1000\t1000\t1000\t1000\n", 1000, 1000, false},
+		{"Different filesystem IDs", "Name:\tsetuid\nUid:\t1000\t0\t0\t0\nGid:\t100\t100\t100\t101\nGroups:\t100\n", 0, 101, false},
+		{"Container IDs", "Uid:\t1001000\t1001000\t1001000\t1001000\nGid:\t1001000\t1001000\t1001000\t1001000\n", 1001000, 1001000, false},
+		{"Missing group IDs", "Uid:\t1000\t1000\t1000\t1000\n", -1, -1, true},
+		{"Truncated line", "Uid:\t1000\t1000\nGid:\t1000\t1000\t1000\t1000\n", -1, -1, true},
+		{"Invalid ID", "Uid:\t1000\t1000\t1000\tabc\nGid:\t1000\t1000\t1000\t1000\n", -1, -1, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		uid, gid, err := coreDumpStatusFSIDs([]byte(tt.content))
+		if tt.shouldFail {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.uid, uid)
+		require.Equal(t, tt.gid, gid)
+	}
+}
+
+func TestCoreDumpHandlerValues(t *testing.T) {
+	values, err := coreDumpHandlerValues([]string{"Ps", "1234", "11", "my", "app"})
+	require.NoError(t, err)
+	require.Equal(t, map[byte]string{'P': "1234", 's': "11", 'e': "my app"}, values)
+
+	_, err = coreDumpHandlerValues([]string{"Ps", "1234", "11"})
+	require.Error(t, err)
+
+	_, err = coreDumpHandlerValues(nil)
+	require.Error(t, err)
+}
+
+func TestCoreDumpHandlerForward(t *testing.T) {
+	previous := "|/usr/lib/systemd/systemd-coredump %P %u %g %s %t %c %h %d %F"
+	kernel := map[byte]string{
+		'P': "1234",
+		'p': "12",
+		'i': "1235",
+		'I': "13",
+		'u': "1000",
+		'g': "1001",
+		'd': "1",
+		's': "11",
+		't': "1700000000",
+		'h': "host",
+		'e': "app",
+		'c': "18446744073709551615",
+		'F': "42",
+	}
+
+	// The previous pattern's specifiers are all passed to the handler.
+	pattern := coreDumpHandlerPattern("/usr/bin/incusd", previous)
+	require.Equal(t, "|/usr/bin/incusd forkcoredump "+internalUtil.VarPath()+" PiugstchdF %P %i %u %g %s %t %c %h %d %F %e", pattern)
+
+	// Expand the handler pattern as the kernel does, then check the previous handler gets the same arguments.
+	fields := strings.Fields(strings.TrimPrefix(pattern, "|"))
+	for i := range fields {
+		fields[i] = coreDumpPatternExpand(fields[i], kernel)
+	}
+
+	values, err := coreDumpHandlerValues(fields[3:])
+	require.NoError(t, err)
+
+	for _, field := range strings.Fields(strings.TrimPrefix(previous, "|")) {
+		require.Equal(t, coreDumpPatternExpand(field, kernel), coreDumpPatternExpand(field, values))
+	}
+
+	// Specifiers that are already passed, unknown ones and escaped percent signs aren't added.
+	require.Equal(t, "PpE", coreDumpPatternSpecifiers("core.%P.%p.%P.%%d.%z.%E"))
+	require.Equal(t, "|/usr/bin/incusd forkcoredump "+internalUtil.VarPath()+" Piugstch %P %i %u %g %s %t %c %h %e", coreDumpHandlerPattern("/usr/bin/incusd", "core"))
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -20,13 +21,15 @@ type cmdForklimits struct {
 func (c *cmdForklimits) Command() *cobra.Command {
 	// Main subcommand
 	cmd := &cobra.Command{}
-	cmd.Use = "forklimits [fd=<number>...] [limit=<name>:<softlimit>:<hardlimit>...] -- <command> [<arg>...]"
+	cmd.Use = "forklimits [fd=<number>...] [limit=<name>:<softlimit>:<hardlimit>...] [cgroup=<path>] -- <command> [<arg>...]"
 	cmd.Short = "Execute a task inside the container"
 	cmd.Long = `Description:
   Execute a command with specific limits set.
 
   This internal command is used to spawn a command with limits set. It can also pass through one or more filed escriptors specified by fd=n arguments.
   These are passed through in the order they are specified.
+
+  The command can also be started in the cgroup at the path given by the cgroup=path argument.
 `
 	cmd.RunE = c.Run
 	cmd.Hidden = true
@@ -57,6 +60,7 @@ func (c *cmdForklimits) Run(cmd *cobra.Command, _ []string) error {
 	var limits []limit
 	var fds []uintptr
 	var cmdParts []string
+	var cgroupPath string
 
 	for i, arg := range args {
 		matches := reLimitsArg.FindStringSubmatch(arg)
@@ -75,6 +79,8 @@ func (c *cmdForklimits) Run(cmd *cobra.Command, _ []string) error {
 			}
 
 			fds = append(fds, uintptr(fdNum))
+		} else if strings.HasPrefix(arg, "cgroup=") {
+			cgroupPath = strings.TrimPrefix(arg, "cgroup=")
 		} else if arg == "--" {
 			if len(args)-1 > i {
 				cmdParts = args[i+1:]
@@ -131,6 +137,14 @@ func (c *cmdForklimits) Run(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("Missing required command argument")
 	}
 
+	// Move into the cgroup before running the command, so that all its processes and threads are created in it.
+	if cgroupPath != "" {
+		err := os.WriteFile(filepath.Join(cgroupPath, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0)
+		if err != nil {
+			return fmt.Errorf("Failed moving into cgroup %q: %w", cgroupPath, err)
+		}
+	}
+
 	// Clear the cloexec flag on the file descriptors we are passing through.
 	for _, fd := range fds {
 		_, _, syscallErr := unix.Syscall(unix.SYS_FCNTL, fd, unix.F_SETFD, uintptr(0))
@@ -344,6 +344,8 @@ __attribute__((constructor)) void init(void) {
 		forkproxy();
 	else if (strcmp(cmdline_cur, "forkuevent") == 0)
 		forkuevent();
+	else if (strcmp(cmdline_cur, "forkcoredump") == 0)
+		forkcoredump();
 	else if (strcmp(cmdline_cur, "forkcoresched") == 0)
 		forkcoresched();
 	else if (strcmp(cmdline_cur, "forkzfs") == 0) {
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	instanceDrivers "github.com/lxc/incus/v6/internal/server/instance/drivers"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/metrics"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/logger"
+	localtls "github.com/lxc/incus/v6/shared/tls"
+)
+
+// metricsRemoteWriteTimeout is the timeout of each attempt at pushing the metrics.
+const metricsRemoteWriteTimeout = 30 * time.Second
+
+// metricsRemoteWriteRetries is the number of times a push is retried after a transient failure.
+const metricsRemoteWriteRetries = 3
+
+// metricsRemoteWriteBackoff is the delay before the first retry, doubling with every retry.
+var metricsRemoteWriteBackoff = time.Second
+
+// metricsRemoteWriteTask periodically pushes the metrics of the local server to the Prometheus remote write endpoint.
+func metricsRemoteWriteTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := metricsRemoteWrite(ctx, d)
+		if err != nil {
+			logger.Warn("Failed pushing metrics to Prometheus", logger.Ctx{"err": err})
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		apiURL, _, _, _, _, interval := d.State().GlobalConfig.PrometheusRemoteWrite()
+
+		if first || apiURL == "" {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+// metricsRemoteWrite collects the metrics of the local server and its instances, and pushes them.
+func metricsRemoteWrite(ctx context.Context, d *Daemon) error {
+	s := d.State()
+
+	apiURL, username, password, token, caCert, interval := s.GlobalConfig.PrometheusRemoteWrite()
+	if apiURL == "" {
+		return nil
+	}
+
+	// Label the metrics with the server they come from, as a scrape would.
+	serverName := s.ServerName
+	if !s.ServerClustered {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+
+		serverName = hostname
+	}
+
+	metricSet := metrics.NewMetricSet(map[string]string{"job": "incus", "instance": serverName})
+	timestamp := time.Now()
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		metricSet.Merge(internalMetrics(ctx, s.StartTime, tx))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	metricSet.Merge(d.apiRateLimitMetrics())
+
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	hostInterfaces, _ := net.Interfaces()
+	for _, inst := range instances {
+		instanceMetrics, err := inst.Metrics(hostInterfaces)
+		if err != nil {
+			// Ignore stopped instances.
+			if !errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
+				logger.Warn("Failed getting instance metrics", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name, "err": err})
+			}
+
+			continue
+		}
+
+		metricSet.Merge(instanceMetrics)
+	}
+
+	client, err := metricsRemoteWriteClient(s.Proxy, caCert, min(metricsRemoteWriteTimeout, interval))
+	if err != nil {
+		return err
+	}
+
+	// Give up on the push once the next one is due, as it carries more recent samples.
+	ctx, cancel := context.WithTimeout(ctx, interval)
+	defer cancel()
+
+	body := metricSet.RemoteWrite(timestamp)
+
+	return metricsRemoteWriteSend(ctx, client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		req.Header.Set("Content-Encoding", "snappy")
+		req.Header.Set("User-Agent", version.UserAgent)
+		req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+
+		return req, nil
+	})
+}
+
+// metricsRemoteWriteClient returns the client pushing the metrics through the proxy, trusting caCert if set.
+func metricsRemoteWriteClient(proxy func(req *http.Request) (*url.URL, error), caCert string, timeout time.Duration) (*http.Client, error) {
+	transport := &http.Transport{Proxy: proxy}
+	if caCert != "" {
+		tlsConfig, err := localtls.GetTLSConfigMem("", "", caCert, "", false)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// metricsRemoteWriteSend sends the push requests returned by newRequest until one succeeds.
+// Connection failures, rate limiting and server errors are retried with an exponential backoff, while the other
+// errors returned by the endpoint mean that the request was rejected and are returned right away.
+func metricsRemoteWriteSend(ctx context.Context, client *http.Client, newRequest func() (*http.Request, error)) error {
+	backoff := metricsRemoteWriteBackoff
+
+	for attempt := 0; ; attempt++ {
+		retry, err := func() (bool, error) {
+			req, err := newRequest()
+			if err != nil {
+				return false, err
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return true, err
+			}
+
+			defer func() { _ = resp.Body.Close() }()
+
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return false, nil
+			}
+
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			err = fmt.Errorf("Prometheus remote write endpoint returned %q: %s", resp.Status, bytes.TrimSpace(message))
+
+			return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+		}()
+		if err == nil || !retry || attempt >= metricsRemoteWriteRetries {
+			return err
+		}
+
+		logger.Debug("Retrying to push metrics to Prometheus", logger.Ctx{"err": err, "retry": attempt + 1})
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
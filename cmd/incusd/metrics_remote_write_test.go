@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/pem"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRemoteWriteSend(t *testing.T) {
+	metricsRemoteWriteBackoff = time.Millisecond
+
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int
+		fails    bool
+	}{
+		{"Success", []int{http.StatusNoContent}, 1, false},
+		{"Server error then success", []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusNoContent}, 3, false},
+		{"Rate limited then success", []int{http.StatusTooManyRequests, http.StatusOK}, 2, false},
+		{"Rejected", []int{http.StatusBadRequest, http.StatusNoContent}, 1, true},
+		{"Persistent server error", []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusNoContent}, 4, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.statuses[attempts])
+			attempts++
+		}))
+
+		err := metricsRemoteWriteSend(context.Background(), server.Client(), func() (*http.Request, error) {
+			return http.NewRequest(http.MethodPost, server.URL, nil)
+		})
+		server.Close()
+
+		require.Equal(t, tt.attempts, attempts)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+	}
+
+	// Retries stop once the context is done.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := metricsRemoteWriteSend(ctx, server.Client(), func() (*http.Request, error) {
+		return http.NewRequest(http.MethodPost, server.URL, nil)
+	})
+	require.Error(t, err)
+}
+
+func TestMetricsRemoteWriteClient(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsServer.Close()
+
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw}))
+
+	proxied := 0
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	// The endpoint is only reachable through the proxy, with or without a CA certificate.
+	for _, cert := range []string{"", caCert} {
+		client, err := metricsRemoteWriteClient(http.ProxyURL(proxyURL), cert, time.Second)
+		require.NoError(t, err)
+
+		err = metricsRemoteWriteSend(context.Background(), client, func() (*http.Request, error) {
+			return http.NewRequest(http.MethodPost, "http://remote-write.invalid/api/v1/write", nil)
+		})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 2, proxied)
+
+	// The CA certificate is trusted.
+	client, err := metricsRemoteWriteClient(nil, caCert, time.Second)
+	require.NoError(t, err)
+
+	resp, err := client.Get(tlsServer.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/lxc/incus/v6/internal/filter"
 	"github.com/lxc/incus/v6/internal/jmap"
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/cluster"
@@ -25,6 +27,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/state"
 	"github.com/lxc/incus/v6/internal/server/task"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/util"
@@ -400,6 +403,21 @@ func operationCancel(s *state.State, r *http.Request, projectName string, op *ap
 //      name: all-projects
 //      description: Retrieve operations from all projects
 //      type: boolean
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return (all of them if 0)
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: offset
+//      description: Number of entries to skip
+//      type: integer
+//      example: 200
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -456,6 +474,21 @@ func operationCancel(s *state.State, r *http.Request, projectName string, op *ap
 //	    name: all-projects
 //	    description: Retrieve operations from all projects
 //	    type: boolean
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return (all of them if 0)
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: offset
+//	    description: Number of entries to skip
+//	    type: integer
+//	    example: 200
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -499,6 +532,22 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 		projectName = api.ProjectDefaultName
 	}
 
+	// Parse filter value.
+	clauses, err := filter.Parse(r.FormValue("filter"), filter.QueryOperatorSet())
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid filter: %w", err))
+	}
+
+	// Parse pagination values.
+	limit, offset, err := localUtil.PaginationFromRequest(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Filtering and pagination require the full operations.
+	mustFilter := (clauses != nil && len(clauses.Clauses) > 0) || limit > 0 || offset > 0
+	mustLoadObjects := recursion || mustFilter
+
 	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanViewOperations, auth.ObjectTypeProject)
 	if err != nil {
 		return response.InternalError(fmt.Errorf("Failed to get operation permission checker: %w", err))
@@ -590,7 +639,7 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 	// Start with local operations.
 	var md jmap.Map
 
-	if recursion {
+	if mustLoadObjects {
 		md, err = localOperations()
 		if err != nil {
 			return response.InternalError(err)
@@ -604,6 +653,13 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 
 	// If not clustered, then just return local operations.
 	if !s.ServerClustered {
+		if mustFilter {
+			md, err = operationsFilter(md, clauses, limit, offset, recursion)
+			if err != nil {
+				return response.SmartError(err)
+			}
+		}
+
 		return response.SyncResponse(true, md)
 	}
 
@@ -689,14 +745,14 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 
 			_, ok := md[status]
 			if !ok {
-				if recursion {
+				if mustLoadObjects {
 					md[status] = make([]*api.Operation, 0)
 				} else {
 					md[status] = make([]string, 0)
 				}
 			}
 
-			if recursion {
+			if mustLoadObjects {
 				md[status] = append(md[status].([]*api.Operation), &op)
 			} else {
 				md[status] = append(md[status].([]string), fmt.Sprintf("/1.0/operations/%s", op.ID))
@@ -704,9 +760,68 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	if mustFilter {
+		md, err = operationsFilter(md, clauses, limit, offset, recursion)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	return response.SyncResponse(true, md)
 }
 
+// operationsFilter filters the operations of a status map, sorted by creation date, and only keeps the requested page.
+func operationsFilter(md jmap.Map, clauses *filter.ClauseSet, limit int, offset int, recursion bool) (jmap.Map, error) {
+	ops := []*api.Operation{}
+	for _, entries := range md {
+		statusOps, ok := entries.([]*api.Operation)
+		if !ok {
+			continue
+		}
+
+		ops = append(ops, statusOps...)
+	}
+
+	sort.SliceStable(ops, func(i, j int) bool {
+		if !ops[i].CreatedAt.Equal(ops[j].CreatedAt) {
+			return ops[i].CreatedAt.Before(ops[j].CreatedAt)
+		}
+
+		return ops[i].ID < ops[j].ID
+	})
+
+	filtered := make([]*api.Operation, 0, len(ops))
+	for _, op := range ops {
+		if clauses != nil && len(clauses.Clauses) > 0 {
+			match, err := filter.Match(*op, *clauses)
+			if err != nil {
+				return nil, err
+			}
+
+			if !match {
+				continue
+			}
+		}
+
+		filtered = append(filtered, op)
+	}
+
+	result := jmap.Map{}
+	for _, op := range localUtil.Paginate(filtered, limit, offset) {
+		status := strings.ToLower(op.Status)
+
+		if recursion {
+			statusOps, _ := result[status].([]*api.Operation)
+			result[status] = append(statusOps, op)
+		} else {
+			statusURLs, _ := result[status].([]string)
+			result[status] = append(statusURLs, api.NewURL().Path(version.APIVersion, "operations", op.ID).String())
+		}
+	}
+
+	return result, nil
+}
+
 // operationsGetByType gets all operations for a project and type.
 func operationsGetByType(s *state.State, r *http.Request, projectName string, opType operationtype.Type) ([]*api.Operation, error) {
 	ops := make([]*api.Operation, 0)
@@ -917,8 +1032,9 @@ func operationWaitGet(d *Daemon, r *http.Request) response.Response {
 
 	secret := r.FormValue("secret")
 
+	// Requests authenticated through an instance share were already validated.
 	trusted, _, _, _ := d.Authenticate(nil, r)
-	if !trusted && secret == "" {
+	if !trusted && secret == "" && r.Context().Value(request.CtxProtocol) != instanceShareProtocol {
 		return response.Forbidden(nil)
 	}
 
@@ -51,6 +51,12 @@ var profileCmd = APIEndpoint{
 	Put:    APIEndpointAction{Handler: profilePut, AccessHandler: allowPermission(auth.ObjectTypeProfile, auth.EntitlementCanEdit, "name")},
 }
 
+var profilePreviewCmd = APIEndpoint{
+	Path: "profiles/{name}/preview",
+
+	Post: APIEndpointAction{Handler: profilePreviewPost, AccessHandler: allowPermission(auth.ObjectTypeProfile, auth.EntitlementCanEdit, "name")},
+}
+
 // swagger:operation GET /1.0/profiles profiles profiles_get
 //
 //  Get the profiles
@@ -309,6 +315,11 @@ func profilesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	err = instanceRefreshConfigCheck(s, r, nil, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// At this point we don't know the instance type, so just use instancetype.Any type for validation.
 	err = instance.ValidDevices(s, *p, instancetype.Any, deviceConfig.NewDevices(req.Devices), nil)
 	if err != nil {
@@ -544,6 +555,11 @@ func profilePut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	err = instanceRefreshConfigCheck(s, r, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	err = doProfileUpdate(r.Context(), s, *p, name, id, profile, req)
 
 	if err == nil && !isClusterNotification(r) {
@@ -567,6 +583,109 @@ func profilePut(d *Daemon, r *http.Request) response.Response {
 	return response.SmartError(err)
 }
 
+// swagger:operation POST /1.0/profiles/{name}/preview profiles profile_preview_post
+//
+//	Preview a profile update
+//
+//	Returns the instances which would be affected by replacing the profile configuration,
+//	along with the configuration keys and devices which would change for each of them.
+//	Nothing is modified.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: profile
+//	    description: Profile configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ProfilePut"
+//	responses:
+//	  "200":
+//	    description: Profile preview
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ProfilePreview"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func profilePreviewPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check that the profile exists.
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.GetProfile(ctx, tx.Tx(), p.Name, name)
+		if err != nil {
+			return fmt.Errorf("Failed to retrieve profile %q: %w", name, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.ProfilePut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Quick checks, same as when updating the profile.
+	err = instance.ValidConfig(s.OS, req.Config, false, instancetype.Any)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = instance.ValidDevices(s, *p, instancetype.Any, deviceConfig.NewDevices(req.Devices), nil)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	preview, err := doProfilePreview(r.Context(), s, p.Name, name, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, preview)
+}
+
 // swagger:operation PATCH /1.0/profiles/{name} profiles profile_patch
 //
 //	Partially update the profile
@@ -696,6 +815,11 @@ func profilePatch(d *Daemon, r *http.Request) response.Response {
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(p.Name, lifecycle.ProfileUpdated.Event(name, p.Name, requestor, nil))
 
+	err = instanceRefreshConfigCheck(s, r, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	return response.SmartError(doProfileUpdate(r.Context(), s, *p, name, id, profile, req))
 }
 
@@ -3,13 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
+	"maps"
+	"sort"
+	"strings"
 
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/device"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/metadata"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/state"
 	"github.com/lxc/incus/v6/shared/api"
@@ -244,6 +249,173 @@ func doProfileUpdateInstance(ctx context.Context, s *state.State, args db.Instan
 	}, true)
 }
 
+// doProfilePreview computes the effect replacing the profile configuration with req would have on the
+// instances using the profile, without applying it.
+func doProfilePreview(ctx context.Context, s *state.State, projectName string, profileName string, req api.ProfilePut) (*api.ProfilePreview, error) {
+	insts, projects, err := getProfileInstancesInfo(ctx, s.DB.Cluster, projectName, profileName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query instances associated with profile %q: %w", profileName, err)
+	}
+
+	preview := &api.ProfilePreview{
+		Instances: []api.ProfilePreviewInstance{},
+	}
+
+	for _, inst := range insts {
+		newProfiles := make([]api.Profile, 0, len(inst.Profiles))
+		for _, profile := range inst.Profiles {
+			if profile.Name == profileName {
+				profile.Config = req.Config
+				profile.Devices = req.Devices
+			}
+
+			newProfiles = append(newProfiles, profile)
+		}
+
+		oldConfig := db.ExpandInstanceConfig(inst.Config, inst.Profiles)
+		newConfig := db.ExpandInstanceConfig(inst.Config, newProfiles)
+		oldDevices := db.ExpandInstanceDevices(inst.Devices, inst.Profiles)
+		newDevices := db.ExpandInstanceDevices(inst.Devices, newProfiles)
+
+		instPreview := api.ProfilePreviewInstance{
+			Name:     inst.Name,
+			Project:  inst.Project,
+			Location: inst.Node,
+			Running:  inst.Config["volatile.last_state.power"] == instance.PowerStateRunning,
+			Config:   []string{},
+			Devices:  []string{},
+		}
+
+		for key, value := range newConfig {
+			oldValue, found := oldConfig[key]
+			if !found || oldValue != value {
+				instPreview.Config = append(instPreview.Config, key)
+			}
+		}
+
+		for key := range oldConfig {
+			_, found := newConfig[key]
+			if !found {
+				instPreview.Config = append(instPreview.Config, key)
+			}
+		}
+
+		for name, device := range newDevices {
+			oldDevice, found := oldDevices[name]
+			if !found || !maps.Equal(oldDevice, device) {
+				instPreview.Devices = append(instPreview.Devices, name)
+			}
+		}
+
+		for name := range oldDevices {
+			_, found := newDevices[name]
+			if !found {
+				instPreview.Devices = append(instPreview.Devices, name)
+			}
+		}
+
+		if instPreview.Running {
+			for _, key := range instPreview.Config {
+				if profilePreviewRequiresRestart(key) {
+					instPreview.RequiresRestart = true
+					break
+				}
+			}
+
+			if !instPreview.RequiresRestart && len(instPreview.Devices) > 0 {
+				instPreview.RequiresRestart = profilePreviewDevicesRequireRestart(s, inst, *projects[inst.Project], oldDevices, newDevices)
+			}
+		}
+
+		// Instances not affected by the change are left out.
+		if len(instPreview.Config) == 0 && len(instPreview.Devices) == 0 {
+			continue
+		}
+
+		sort.Strings(instPreview.Config)
+		sort.Strings(instPreview.Devices)
+
+		// Validate the resulting instance configuration.
+		err = instance.ValidConfig(s.OS, newConfig, true, inst.Type)
+		if err == nil {
+			err = instance.ValidDevices(s, *projects[inst.Project], inst.Type, inst.Devices, newDevices)
+		}
+
+		if err != nil {
+			instPreview.Error = err.Error()
+		}
+
+		preview.Instances = append(preview.Instances, instPreview)
+	}
+
+	sort.Slice(preview.Instances, func(i, j int) bool {
+		if preview.Instances[i].Project != preview.Instances[j].Project {
+			return preview.Instances[i].Project < preview.Instances[j].Project
+		}
+
+		return preview.Instances[i].Name < preview.Instances[j].Name
+	})
+
+	return preview, nil
+}
+
+// profilePreviewRequiresRestart returns whether changing the key on a running instance only takes effect
+// after a restart.
+func profilePreviewRequiresRestart(key string) bool {
+	// Keys which are only read at specific times and so never disrupt a running instance.
+	for _, prefix := range []string{"boot.", "cloud-init.", "environment.", "image.", "snapshots.", "user.", "volatile."} {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+
+	liveUpdate, found := metadata.LiveUpdate("instance", key)
+	if !found {
+		return false
+	}
+
+	return !liveUpdate
+}
+
+// profilePreviewDevicesRequireRestart returns whether changing the devices of a running instance only takes effect
+// after a restart. Devices which can't be updated live get removed and added again, which requires them to be
+// hot-pluggable.
+func profilePreviewDevicesRequireRestart(s *state.State, args db.InstanceArgs, p api.Project, oldDevices deviceConfig.Devices, newDevices deviceConfig.Devices) bool {
+	inst, err := instance.Load(s, args, p)
+	if err != nil {
+		return true
+	}
+
+	removeDevices, addDevices, _, _ := oldDevices.Update(newDevices, func(oldDevice deviceConfig.Device, newDevice deviceConfig.Device) []string {
+		oldDevType, err := device.LoadByType(s, p.Name, oldDevice)
+		if err != nil {
+			return []string{}
+		}
+
+		newDevType, err := device.LoadByType(s, p.Name, newDevice)
+		if err != nil {
+			return []string{}
+		}
+
+		return newDevType.UpdatableFields(oldDevType)
+	})
+
+	volatileGet := func() map[string]string { return map[string]string{} }
+	volatileSet := func(map[string]string) error { return nil }
+
+	for _, devices := range []deviceConfig.Devices{removeDevices, addDevices} {
+		for name, config := range devices {
+			// Validation errors are reported separately.
+			dev, _ := device.New(inst, s, name, config.Clone(), volatileGet, volatileSet)
+			if dev == nil || !dev.CanHotPlug() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // Query the db for information about instances associated with the given profile.
 func getProfileInstancesInfo(ctx context.Context, dbCluster *db.Cluster, projectName string, profileName string) (map[int]db.InstanceArgs, map[string]*api.Project, error) {
 	var projectInstNames map[string][]string
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Adding devices which can't be hot-plugged requires running instances to restart.
+func (suite *containerTestSuite) TestProfilePreviewDevices() {
+	c, op, _, err := instance.CreateInternal(suite.d.State(), db.InstanceArgs{
+		Type:   instancetype.Container,
+		Name:   "testProfilePreview",
+		Config: map[string]string{"volatile.last_state.power": instance.PowerStateRunning},
+	}, true, true)
+	suite.Req.Nil(err)
+	op.Done(nil)
+	defer func() { _ = c.Delete(true) }()
+
+	profile := c.Profiles()[0]
+
+	preview := func(devices map[string]map[string]string) api.ProfilePreviewInstance {
+		for name, device := range profile.Devices {
+			devices[name] = device
+		}
+
+		result, err := doProfilePreview(context.Background(), suite.d.State(), api.ProjectDefaultName, profile.Name, api.ProfilePut{Config: profile.Config, Devices: devices})
+		suite.Req.Nil(err)
+		suite.Req.Len(result.Instances, 1)
+		suite.Req.True(result.Instances[0].Running)
+
+		return result.Instances[0]
+	}
+
+	// Hot-pluggable devices are added live.
+	result := preview(map[string]map[string]string{"test": {"type": "none"}})
+	suite.Req.Equal([]string{"test"}, result.Devices)
+	suite.Req.False(result.RequiresRestart)
+
+	// MIG devices can't be hot-plugged.
+	result = preview(map[string]map[string]string{"gpu0": {"type": "gpu", "gputype": "mig", "pci": "0000:01:00.0", "mig.uuid": "MIG-6bcc3bc8-4a7a-5b35-a3f6-e7b42b2a1c33"}})
+	suite.Req.Equal([]string{"gpu0"}, result.Devices)
+	suite.Req.True(result.RequiresRestart)
+}
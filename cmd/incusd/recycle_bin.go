@@ -0,0 +1,899 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v2"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/backup"
+	backupConfig "github.com/lxc/incus/v6/internal/server/backup/config"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
+	"github.com/lxc/incus/v6/internal/server/task"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+var recycleBinCmd = APIEndpoint{
+	Path: "recycle-bin",
+
+	Get: APIEndpointAction{Handler: recycleBinGet, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+}
+
+var recycleBinEntryCmd = APIEndpoint{
+	Path: "recycle-bin/{id}",
+
+	Delete: APIEndpointAction{Handler: recycleBinEntryDelete, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+	Get:    APIEndpointAction{Handler: recycleBinEntryGet, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+	Post:   APIEndpointAction{Handler: recycleBinEntryPost, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+}
+
+// recycleBinPath returns the path of the file holding the backup config of a recycle bin entry.
+func recycleBinPath(id int64) string {
+	return internalUtil.VarPath("backups", "recycle-bin", strconv.FormatInt(id, 10))
+}
+
+// recycleBinLoadConfig loads the backup config of a recycle bin entry.
+func recycleBinLoadConfig(id int64) (*backupConfig.Config, error) {
+	return backup.ParseConfigYamlFile(recycleBinPath(id))
+}
+
+// recycleBinTooLarge returns whether a resource exceeds the size limit of the recycle bin, given its disk usage.
+// Resources of unknown usage are kept in the recycle bin.
+func recycleBinTooLarge(usage *storagePools.VolumeUsage, usageErr error, maxSize int64) bool {
+	if maxSize <= 0 || usageErr != nil || usage == nil {
+		return false
+	}
+
+	return usage.Used > maxSize
+}
+
+// recycleBinSkip records a warning on the project of a resource which is deleted without being kept in the recycle
+// bin as it exceeds instances.recycle_bin.max_size.
+func recycleBinSkip(s *state.State, projectName string, message string) {
+	logger.Warn(message, logger.Ctx{"project": projectName})
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		projectID, err := cluster.GetProjectID(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		return tx.UpsertWarningLocalNode(ctx, projectName, cluster.TypeProject, int(projectID), warningtype.RecycleBinSkipped, message)
+	})
+	if err != nil {
+		logger.Warn("Failed to create recycle bin warning", logger.Ctx{"project": projectName, "err": err})
+	}
+}
+
+// recycleBinAdd records a resource which is about to be deleted in the recycle bin and moves its storage volume
+// into it. The entry is recorded in entryProjectName, while the volume belongs to volumeProjectName.
+// Returns a revert hook moving the volume back and removing the entry again.
+func recycleBinAdd(s *state.State, pool storagePools.Pool, entryType string, entryProjectName string, volumeProjectName string, name string, poolVol *backupConfig.Config, op *operations.Operation) (revert.Hook, error) {
+	now := time.Now()
+
+	expiresAt, err := internalInstance.GetExpiry(now, s.GlobalConfig.InstancesRecycleBinExpiry())
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(internalUtil.VarPath("backups", "recycle-bin"), 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := yaml.Marshal(poolVol)
+	if err != nil {
+		return nil, err
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	var id int64
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, err = tx.CreateRecycleBinEntry(ctx, api.RecycleBinEntry{
+			Type:      entryType,
+			Name:      name,
+			Project:   entryProjectName,
+			Pool:      pool.Name(),
+			DeletedAt: now,
+			ExpiresAt: expiresAt,
+		})
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed adding recycle bin entry: %w", err)
+	}
+
+	reverter.Add(func() {
+		_ = os.Remove(recycleBinPath(id))
+		_ = s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.DeleteRecycleBinEntry(ctx, id)
+		})
+	})
+
+	err = os.WriteFile(recycleBinPath(id), data, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed writing recycle bin entry: %w", err)
+	}
+
+	err = pool.RecycleVolume(volumeProjectName, poolVol, storagePools.RecycledVolumeName(id), op)
+	if err != nil {
+		return nil, fmt.Errorf("Failed moving storage volume into the recycle bin: %w", err)
+	}
+
+	reverter.Add(func() {
+		err := pool.RestoreRecycledVolume(volumeProjectName, poolVol, storagePools.RecycledVolumeName(id), nil)
+		if err != nil {
+			logger.Error("Failed moving storage volume out of the recycle bin", logger.Ctx{"id": id, "err": err})
+		}
+	})
+
+	cleanup := reverter.Clone().Fail
+	reverter.Success()
+
+	return cleanup, nil
+}
+
+// recycleBinAddInstance moves the storage volume of an instance which is about to be deleted into the recycle bin.
+// Returns a nil hook without doing anything if the recycle bin is disabled.
+func recycleBinAddInstance(s *state.State, inst instance.Instance, op *operations.Operation) (revert.Hook, error) {
+	if s.GlobalConfig.InstancesRecycleBinExpiry() == "" || inst.IsSnapshot() || inst.IsEphemeral() {
+		return nil, nil
+	}
+
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keeping large instances would retain too much disk space.
+	usage, usageErr := pool.GetInstanceUsage(inst)
+	if recycleBinTooLarge(usage, usageErr, s.GlobalConfig.InstancesRecycleBinMaxSize()) {
+		recycleBinSkip(s, inst.Project().Name, fmt.Sprintf("Instance %q was too large to be kept in the recycle bin", inst.Name()))
+		return nil, nil
+	}
+
+	poolVol, err := pool.GenerateInstanceBackupConfig(inst, true, op)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating instance backup config: %w", err)
+	}
+
+	return recycleBinAdd(s, pool, "instance", inst.Project().Name, inst.Project().Name, inst.Name(), poolVol, op)
+}
+
+// recycleBinAddCustomVolume moves a custom volume which is about to be deleted into the recycle bin.
+// The entry is recorded in the requested project, which may differ from the project holding the volume.
+// Returns a nil hook without doing anything if the recycle bin is disabled.
+func recycleBinAddCustomVolume(s *state.State, requestProjectName string, projectName string, pool storagePools.Pool, volumeName string, op *operations.Operation) (revert.Hook, error) {
+	if s.GlobalConfig.InstancesRecycleBinExpiry() == "" {
+		return nil, nil
+	}
+
+	// Keeping large volumes would retain too much disk space.
+	usage, usageErr := pool.GetCustomVolumeUsage(projectName, volumeName)
+	if recycleBinTooLarge(usage, usageErr, s.GlobalConfig.InstancesRecycleBinMaxSize()) {
+		recycleBinSkip(s, requestProjectName, fmt.Sprintf("Storage volume %q of pool %q was too large to be kept in the recycle bin", volumeName, pool.Name()))
+		return nil, nil
+	}
+
+	poolVol, err := pool.GenerateCustomVolumeBackupConfig(projectName, volumeName, true, op)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating storage volume backup config: %w", err)
+	}
+
+	return recycleBinAdd(s, pool, "volume", requestProjectName, projectName, volumeName, poolVol, op)
+}
+
+// recycleBinLoadEntry loads the recycle bin entry referenced by the request, which must be part of the project.
+func recycleBinLoadEntry(s *state.State, r *http.Request) (*api.RecycleBinEntry, error) {
+	idStr, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid recycle bin entry %q", idStr)
+	}
+
+	var entry *api.RecycleBinEntry
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		entry, err = tx.GetRecycleBinEntry(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Project != request.ProjectParam(r) {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Recycle bin entry not found")
+	}
+
+	return entry, nil
+}
+
+// recycleBinCheckPermission checks that the requestor may create the kind of resource held by the entry.
+func recycleBinCheckPermission(s *state.State, r *http.Request, entry *api.RecycleBinEntry) error {
+	entitlement := auth.EntitlementCanCreateInstances
+	if entry.Type == "volume" {
+		entitlement = auth.EntitlementCanCreateStorageVolumes
+	}
+
+	return s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectProject(entry.Project), entitlement)
+}
+
+// recycleBinRemove deletes a recycle bin entry along with its storage volume.
+func recycleBinRemove(s *state.State, id int64) error {
+	err := recycleBinDeleteVolume(s, id)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteRecycleBinEntry(ctx, id)
+	})
+}
+
+// recycleBinDeleteVolume deletes the storage volume held by a recycle bin entry along with its backup config.
+func recycleBinDeleteVolume(s *state.State, id int64) error {
+	poolVol, err := recycleBinLoadConfig(id)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if poolVol.Pool == nil {
+		return fmt.Errorf("No storage pool in recycle bin entry %d", id)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolVol.Pool.Name)
+	if err != nil && !response.IsNotFoundError(err) {
+		return err
+	}
+
+	// The volume went away along with its storage pool otherwise.
+	if pool != nil {
+		err = pool.DeleteRecycledVolume(poolVol, storagePools.RecycledVolumeName(id), nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(recycleBinPath(id))
+}
+
+// recycleBinRestoreInstance moves the storage volume of a recycle bin entry back and recreates the instance under
+// the given name, along with its snapshots.
+func recycleBinRestoreInstance(s *state.State, pool storagePools.Pool, entry *api.RecycleBinEntry, poolVol *backupConfig.Config, name string, op *operations.Operation) error {
+	if poolVol.Container == nil {
+		return fmt.Errorf("No instance config in recycle bin entry")
+	}
+
+	err := instance.ValidName(name, false)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "%w", err)
+	}
+
+	var profiles []api.Profile
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := tx.GetInstanceID(ctx, entry.Project, name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "Instance %q already exists", name)
+		} else if !response.IsNotFoundError(err) {
+			return err
+		}
+
+		// Check project limits.
+		err = project.AllowInstanceCreation(tx, entry.Project, api.InstancesPost{
+			InstancePut: poolVol.Container.InstancePut,
+			Name:        name,
+			Source:      api.InstanceSource{}, // Only relevant for "copy" or "migration", but may not be nil.
+			Type:        api.InstanceType(poolVol.Container.Type),
+		})
+		if err != nil {
+			return err
+		}
+
+		profiles, err = tx.GetProfiles(ctx, entry.Project, poolVol.Container.Profiles)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	poolVol.Container.Name = name
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	err = pool.RestoreRecycledVolume(entry.Project, poolVol, storagePools.RecycledVolumeName(entry.ID), op)
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() { _ = pool.RecycleVolume(entry.Project, poolVol, storagePools.RecycledVolumeName(entry.ID), nil) })
+
+	inst, cleanup, err := internalRecoverImportInstance(s, pool, entry.Project, poolVol, profiles)
+	if err != nil {
+		return fmt.Errorf("Failed creating instance record: %w", err)
+	}
+
+	reverter.Add(cleanup)
+
+	for _, snap := range poolVol.Snapshots {
+		var snapProfiles []api.Profile
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			snapProfiles, err = tx.GetProfiles(ctx, entry.Project, snap.Profiles)
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		cleanup, err := internalRecoverImportInstanceSnapshot(s, pool, entry.Project, poolVol, snap, snapProfiles)
+		if err != nil {
+			return fmt.Errorf("Failed creating instance snapshot %q record: %w", snap.Name, err)
+		}
+
+		reverter.Add(cleanup)
+	}
+
+	// Recreate the instance mount path and symlinks (must come after the snapshots).
+	cleanup, err = pool.ImportInstance(inst, poolVol, op)
+	if err != nil {
+		return fmt.Errorf("Failed importing instance: %w", err)
+	}
+
+	reverter.Add(cleanup)
+
+	// Reinitialize the root disk quota, which may depend on the new storage volume ID.
+	_, rootConfig, err := internalInstance.GetRootDiskDevice(inst.ExpandedDevices().CloneNative())
+	if err == nil {
+		err = pool.SetInstanceQuota(inst, rootConfig["size"], rootConfig["size.state"], op)
+		if err != nil {
+			return fmt.Errorf("Failed reinitializing root disk quota: %w", err)
+		}
+	}
+
+	volType, err := storagePools.InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	err = s.Authorizer.AddStoragePoolVolume(s.ShutdownCtx, entry.Project, pool.Name(), volType.Singular(), name, "")
+	if err != nil {
+		logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": name, "type": volType, "pool": pool.Name(), "project": entry.Project, "error": err})
+	}
+
+	// Record the new name in the backup file of the instance.
+	err = inst.UpdateBackupFile()
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+	return nil
+}
+
+// recycleBinRestoreCustomVolume moves the storage volume of a recycle bin entry back and recreates the custom volume
+// under the given name, along with its snapshots.
+func recycleBinRestoreCustomVolume(s *state.State, pool storagePools.Pool, entry *api.RecycleBinEntry, poolVol *backupConfig.Config, name string, op *operations.Operation) error {
+	if poolVol.Volume == nil {
+		return fmt.Errorf("No storage volume config in recycle bin entry")
+	}
+
+	if strings.Contains(name, "/") {
+		return api.StatusErrorf(http.StatusBadRequest, "Storage volume names may not contain slashes")
+	}
+
+	volumeProjectName, err := project.StorageVolumeProject(s.DB.Cluster, entry.Project, db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	_, err = storagePools.VolumeDBGet(pool, volumeProjectName, name, storageDrivers.VolumeTypeCustom)
+	if err == nil {
+		return api.StatusErrorf(http.StatusConflict, "Storage volume %q already exists", name)
+	} else if !response.IsNotFoundError(err) {
+		return err
+	}
+
+	// Check project limits.
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return project.AllowVolumeCreation(tx, volumeProjectName, api.StorageVolumesPost{
+			Name:             name,
+			Type:             db.StoragePoolVolumeTypeNameCustom,
+			StorageVolumePut: api.StorageVolumePut{Config: poolVol.Volume.Config},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	poolVol.Volume.Name = name
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	err = pool.RestoreRecycledVolume(volumeProjectName, poolVol, storagePools.RecycledVolumeName(entry.ID), op)
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() {
+		_ = pool.RecycleVolume(volumeProjectName, poolVol, storagePools.RecycledVolumeName(entry.ID), nil)
+	})
+
+	cleanup, err := pool.ImportCustomVolume(volumeProjectName, poolVol, op)
+	if err != nil {
+		return fmt.Errorf("Failed importing storage volume: %w", err)
+	}
+
+	reverter.Add(cleanup)
+
+	var location string
+	if s.ServerClustered && !pool.Driver().Info().Remote {
+		location = s.ServerName
+	}
+
+	err = s.Authorizer.AddStoragePoolVolume(s.ShutdownCtx, volumeProjectName, pool.Name(), storageDrivers.VolumeTypeCustom.Singular(), name, location)
+	if err != nil {
+		logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": name, "type": storageDrivers.VolumeTypeCustom, "pool": pool.Name(), "project": volumeProjectName, "error": err})
+	}
+
+	reverter.Success()
+	return nil
+}
+
+// swagger:operation GET /1.0/recycle-bin recycle-bin recycle_bin_get
+//
+//	Get the recycle bin
+//
+//	Returns a list of recycle bin entries (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/recycle-bin/1",
+//	              "/1.0/recycle-bin/2"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/recycle-bin?recursion=1 recycle-bin recycle_bin_get_recursion1
+//
+//	Get the recycle bin
+//
+//	Returns a list of recycle bin entries (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of recycle bin entries
+//	          items:
+//	            $ref: "#/definitions/RecycleBinEntry"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func recycleBinGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := localUtil.IsRecursionRequest(r)
+
+	var entries []api.RecycleBinEntry
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		entries, err = tx.GetRecycleBinEntries(ctx, projectName)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if recursion {
+		return response.SyncResponse(true, entries)
+	}
+
+	urls := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		urls = append(urls, api.NewURL().Path(version.APIVersion, "recycle-bin", strconv.FormatInt(entry.ID, 10)).String())
+	}
+
+	return response.SyncResponse(true, urls)
+}
+
+// swagger:operation GET /1.0/recycle-bin/{id} recycle-bin recycle_bin_entry_get
+//
+//	Get the recycle bin entry
+//
+//	Gets a specific recycle bin entry.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Recycle bin entry
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/RecycleBinEntry"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func recycleBinEntryGet(d *Daemon, r *http.Request) response.Response {
+	entry, err := recycleBinLoadEntry(d.State(), r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, entry)
+}
+
+// swagger:operation POST /1.0/recycle-bin/{id} recycle-bin recycle_bin_entry_post
+//
+//	Restore the recycle bin entry
+//
+//	Restores the deleted instance or custom storage volume and removes it from the recycle bin.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: entry
+//	    description: Restore request
+//	    required: false
+//	    schema:
+//	      $ref: "#/definitions/RecycleBinEntryPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func recycleBinEntryPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	entry, err := recycleBinLoadEntry(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = recycleBinCheckPermission(s, r, entry)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// The entry, and for local storage pools its storage volume, are held by the cluster member the resource was deleted on.
+	if s.ServerClustered && entry.Location != s.ServerName {
+		return forwardedResponseToNode(s, r, entry.Location)
+	}
+
+	req := api.RecycleBinEntryPost{}
+	if r.ContentLength > 0 {
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
+	name := req.Name
+	if name == "" {
+		name = entry.Name
+	}
+
+	poolVol, err := recycleBinLoadConfig(entry.ID)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	opType := operationtype.InstanceCreate
+	resources := map[string][]api.URL{}
+	if entry.Type == "volume" {
+		opType = operationtype.VolumeCreate
+		resources["storage_volumes"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", entry.Pool, "volumes", "custom", name)}
+	} else {
+		resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
+	}
+
+	run := func(op *operations.Operation) error {
+		pool, err := storagePools.LoadByName(s, entry.Pool)
+		if err != nil {
+			return err
+		}
+
+		if entry.Type == "volume" {
+			err = recycleBinRestoreCustomVolume(s, pool, entry, poolVol, name, op)
+		} else {
+			err = recycleBinRestoreInstance(s, pool, entry, poolVol, name, op)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// The storage volume no longer belongs to the recycle bin.
+		err = os.Remove(recycleBinPath(entry.ID))
+		if err != nil {
+			logger.Warn("Failed removing restored recycle bin entry", logger.Ctx{"id": entry.ID, "err": err})
+		}
+
+		return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.DeleteRecycleBinEntry(ctx, entry.ID)
+		})
+	}
+
+	op, err := operations.OperationCreate(s, entry.Project, operations.OperationClassTask, opType, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// swagger:operation DELETE /1.0/recycle-bin/{id} recycle-bin recycle_bin_entry_delete
+//
+//	Purge the recycle bin entry
+//
+//	Permanently deletes the instance or custom storage volume held by the recycle bin entry.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func recycleBinEntryDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	entry, err := recycleBinLoadEntry(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = recycleBinCheckPermission(s, r, entry)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if s.ServerClustered && entry.Location != s.ServerName {
+		return forwardedResponseToNode(s, r, entry.Location)
+	}
+
+	err = recycleBinRemove(s, entry.ID)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// recycleBinPurgeTask periodically purges the expired entries of the recycle bin held by this server.
+func recycleBinPurgeTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := recycleBinPurge(ctx, d.State())
+		if err != nil {
+			logger.Error("Failed purging the recycle bin", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Hour)
+}
+
+// recycleBinPurge deletes the expired local recycle bin entries and any leftover storage volume without an entry.
+func recycleBinPurge(ctx context.Context, s *state.State) error {
+	var expired []int64
+	var known []int64
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		expired, err = tx.GetLocalRecycleBinEntryIDs(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+
+		known, err = tx.GetLocalRecycleBinEntryIDs(ctx, time.Time{})
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range expired {
+		err := recycleBinRemove(s, id)
+		if err != nil {
+			return fmt.Errorf("Failed purging recycle bin entry %d: %w", id, err)
+		}
+
+		logger.Info("Purged expired recycle bin entry", logger.Ctx{"id": id})
+	}
+
+	// Remove the storage volumes of entries deleted along with their project.
+	recycleBinDir := internalUtil.VarPath("backups", "recycle-bin")
+	if !util.PathExists(recycleBinDir) {
+		return nil
+	}
+
+	files, err := os.ReadDir(recycleBinDir)
+	if err != nil {
+		return err
+	}
+
+	knownNames := make(map[string]bool, len(known))
+	for _, id := range known {
+		knownNames[strconv.FormatInt(id, 10)] = true
+	}
+
+	for _, file := range files {
+		if knownNames[file.Name()] {
+			continue
+		}
+
+		id, err := strconv.ParseInt(file.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		// Skip entries which may still be in the process of being added.
+		info, err := file.Info()
+		if err != nil || time.Since(info.ModTime()) < time.Hour {
+			continue
+		}
+
+		err = recycleBinDeleteVolume(s, id)
+		if err != nil {
+			return fmt.Errorf("Failed purging leftover recycle bin entry %d: %w", id, err)
+		}
+	}
+
+	return nil
+}
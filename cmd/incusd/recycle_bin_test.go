@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/revert"
+	backupConfig "github.com/lxc/incus/v6/internal/server/backup/config"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/response"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestRecycleBinTooLarge(t *testing.T) {
+	tests := []struct {
+		name     string
+		usage    *storagePools.VolumeUsage
+		usageErr error
+		maxSize  int64
+		tooLarge bool
+	}{
+		{"Within the limit", &storagePools.VolumeUsage{Used: 1024}, nil, 2048, false},
+		{"Above the limit", &storagePools.VolumeUsage{Used: 4096}, nil, 2048, true},
+		{"No limit", &storagePools.VolumeUsage{Used: 4096}, nil, 0, false},
+		{"Unknown usage", nil, errors.New("Not supported"), 2048, false},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		require.Equal(t, tt.tooLarge, recycleBinTooLarge(tt.usage, tt.usageErr, tt.maxSize))
+	}
+}
+
+// recycleBinTestPool records the recycle bin operations of a storage pool and fails the import of the restored
+// storage volume.
+type recycleBinTestPool struct {
+	storagePools.Pool
+
+	calls []string
+}
+
+func (p *recycleBinTestPool) RecycleVolume(projectName string, poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error {
+	p.calls = append(p.calls, "recycle "+recycledName)
+	return nil
+}
+
+func (p *recycleBinTestPool) RestoreRecycledVolume(projectName string, poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error {
+	p.calls = append(p.calls, "restore "+recycledName)
+	return nil
+}
+
+func (p *recycleBinTestPool) ImportInstance(inst instance.Instance, poolVol *backupConfig.Config, op *operations.Operation) (revert.Hook, error) {
+	p.calls = append(p.calls, "import")
+	return nil, errors.New("Import failure")
+}
+
+func (p *recycleBinTestPool) ImportCustomVolume(projectName string, poolVol *backupConfig.Config, op *operations.Operation) (revert.Hook, error) {
+	p.calls = append(p.calls, "import")
+	return nil, errors.New("Import failure")
+}
+
+func (suite *containerTestSuite) TestRecycleBinRestoreInstanceRevert() {
+	s := suite.d.State()
+
+	pool, err := storagePools.LoadByName(s, daemonTestSuiteDefaultStoragePool)
+	suite.Req.Nil(err)
+
+	entry := &api.RecycleBinEntry{ID: 1, Type: "instance", Name: "testRecycled", Project: api.ProjectDefaultName, Pool: pool.Name()}
+	poolVol := &backupConfig.Config{
+		Container: &api.Instance{
+			Name: "testRecycled",
+			Type: "container",
+			InstancePut: api.InstancePut{
+				Profiles: []string{"default"},
+				Config:   map[string]string{},
+				Devices:  map[string]map[string]string{},
+			},
+		},
+		Pool: &api.StoragePool{Name: pool.Name()},
+	}
+
+	// A failed import moves the storage volume back into the recycle bin and removes the instance again.
+	testPool := &recycleBinTestPool{Pool: pool}
+	err = recycleBinRestoreInstance(s, testPool, entry, poolVol, "testRestored", nil)
+	suite.Req.ErrorContains(err, "Import failure")
+	suite.Req.Equal([]string{"restore _recycle-bin_1", "import", "recycle _recycle-bin_1"}, testPool.calls)
+
+	_, err = instance.LoadByProjectAndName(s, api.ProjectDefaultName, "testRestored")
+	suite.Req.True(response.IsNotFoundError(err))
+
+	// Nothing is moved when the instance can't be restored under the requested name.
+	c, op, _, err := instance.CreateInternal(s, db.InstanceArgs{Type: instancetype.Container, Name: "testExisting"}, true, true)
+	suite.Req.Nil(err)
+	op.Done(nil)
+	defer func() { _ = c.Delete(true) }()
+
+	testPool = &recycleBinTestPool{Pool: pool}
+	err = recycleBinRestoreInstance(s, testPool, entry, poolVol, "testExisting", nil)
+	suite.Req.True(api.StatusErrorCheck(err, http.StatusConflict))
+	suite.Req.Empty(testPool.calls)
+}
+
+func (suite *containerTestSuite) TestRecycleBinRestoreCustomVolumeRevert() {
+	s := suite.d.State()
+
+	pool, err := storagePools.LoadByName(s, daemonTestSuiteDefaultStoragePool)
+	suite.Req.Nil(err)
+
+	entry := &api.RecycleBinEntry{ID: 2, Type: "volume", Name: "testRecycled", Project: api.ProjectDefaultName, Pool: pool.Name()}
+	poolVol := &backupConfig.Config{
+		Volume: &api.StorageVolume{
+			Name:        "testRecycled",
+			Type:        "custom",
+			ContentType: "filesystem",
+		},
+		Pool: &api.StoragePool{Name: pool.Name()},
+	}
+
+	// A failed import moves the storage volume back into the recycle bin.
+	testPool := &recycleBinTestPool{Pool: pool}
+	err = recycleBinRestoreCustomVolume(s, testPool, entry, poolVol, "testRestored", nil)
+	suite.Req.ErrorContains(err, "Import failure")
+	suite.Req.Equal([]string{"restore _recycle-bin_2", "import", "recycle _recycle-bin_2"}, testPool.calls)
+
+	// Nothing is moved when the volume can't be restored under the requested name.
+	testPool = &recycleBinTestPool{Pool: pool}
+	err = recycleBinRestoreCustomVolume(s, testPool, entry, poolVol, "invalid/name", nil)
+	suite.Req.True(api.StatusErrorCheck(err, http.StatusBadRequest))
+	suite.Req.Empty(testPool.calls)
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/gorilla/mux"
 
 	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	instanceDrivers "github.com/lxc/incus/v6/internal/server/instance/drivers"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/resources"
 	"github.com/lxc/incus/v6/internal/server/response"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/idmap"
+	"github.com/lxc/incus/v6/shared/util"
 )
 
 var api10ResourcesCmd = APIEndpoint{
@@ -19,6 +28,12 @@ var api10ResourcesCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: api10ResourcesGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanViewResources)},
 }
 
+var api10ResourcesIdmapCmd = APIEndpoint{
+	Path: "resources/idmap",
+
+	Get: APIEndpointAction{Handler: api10ResourcesIdmapGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanViewResources)},
+}
+
 var storagePoolResourcesCmd = APIEndpoint{
 	Path: "storage-pools/{name}/resources",
 
@@ -80,6 +95,159 @@ func api10ResourcesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Report the resources reserved for the host.
+	reservedCPUs, reservedMemory := s.LocalConfig.LimitsReserve()
+	err = resources.SetReserved(res, reservedCPUs, reservedMemory)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, res)
+}
+
+// swagger:operation GET /1.0/resources/idmap server resources_idmap_get
+//
+//	Get ID map resources information
+//
+//	Gets the user and group ID ranges of the server and their use by the containers.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: server01
+//	  - in: query
+//	    name: size
+//	    description: Size of the ID range of an isolated instance for the estimate
+//	    type: integer
+//	    example: 65536
+//	responses:
+//	  "200":
+//	    description: ID map resources
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ResourcesIdmap"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func api10ResourcesIdmapGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// If a target was specified, forward the request to the relevant node.
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	isolatedSize, err := instanceDrivers.IdmapSize(s, "true", "")
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	if request.QueryParam(r, "size") != "" {
+		size, err := strconv.ParseInt(request.QueryParam(r, "size"), 10, 64)
+		if err != nil || size <= 0 {
+			return response.BadRequest(fmt.Errorf("Invalid ID range size %q", request.QueryParam(r, "size")))
+		}
+
+		isolatedSize = size
+	}
+
+	res := api.ResourcesIdmap{
+		Ranges:       []api.ResourcesIdmapRange{},
+		IsolatedSize: isolatedSize,
+		Instances:    []api.ResourcesIdmapInstance{},
+	}
+
+	// Get the subordinate IDs of the host.
+	systemIdmap, err := idmap.NewSetFromSystem("", "root")
+	if err != nil && !errors.Is(err, idmap.ErrSubidUnsupported) {
+		return response.InternalError(fmt.Errorf("Failed getting the system ID map: %w", err))
+	}
+
+	if systemIdmap != nil {
+		for _, entry := range systemIdmap.Entries {
+			if entry.IsUID {
+				res.TotalUIDs += entry.MapRange
+			}
+
+			if entry.IsGID {
+				res.TotalGIDs += entry.MapRange
+			}
+		}
+	}
+
+	// Without an ID map, only privileged containers can run.
+	if s.OS.IdmapSet == nil || len(s.OS.IdmapSet.Entries) == 0 {
+		return response.SyncResponse(true, res)
+	}
+
+	for _, entry := range s.OS.IdmapSet.Entries {
+		res.Ranges = append(res.Ranges, api.ResourcesIdmapRange{UID: entry.IsUID, GID: entry.IsGID, HostID: entry.HostID, Size: entry.MapRange})
+	}
+
+	serverRange := s.OS.IdmapSet.Entries[0]
+	isolatedRanges := idmap.Set{}
+
+	cts, err := instance.LoadNodeAll(s, instancetype.Container)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, ct := range cts {
+		if ct.IsPrivileged() {
+			continue
+		}
+
+		config := ct.ExpandedConfig()
+		inst := api.ResourcesIdmapInstance{
+			Name:     ct.Name(),
+			Project:  ct.Project().Name,
+			Isolated: util.IsTrue(config["security.idmap.isolated"]),
+			HostID:   serverRange.HostID,
+			Size:     serverRange.MapRange,
+		}
+
+		if inst.Isolated {
+			inst.HostID, _ = strconv.ParseInt(config["volatile.idmap.base"], 10, 64)
+
+			inst.Size, err = instanceDrivers.IdmapSize(s, config["security.idmap.isolated"], config["security.idmap.size"])
+			if err != nil {
+				return response.InternalError(fmt.Errorf("Invalid security.idmap.size of instance %q: %w", ct.Name(), err))
+			}
+
+			isolatedRanges.Entries = append(isolatedRanges.Entries, idmap.Entry{HostID: inst.HostID, MapRange: inst.Size})
+		}
+
+		res.Instances = append(res.Instances, inst)
+	}
+
+	// Count the ranges which can still be allocated, the same way as at instance start.
+	start, end := instanceDrivers.IdmapIsolatedRange(s)
+	res.IsolatedAvailable = isolatedRanges.CountFreeRanges(start, end, isolatedSize)
+
 	return response.SyncResponse(true, res)
 }
 
@@ -48,6 +48,12 @@ var storagePoolBucketCmd = APIEndpoint{
 	Put:    APIEndpointAction{Handler: storagePoolBucketPut, AccessHandler: allowPermission(auth.ObjectTypeStorageBucket, auth.EntitlementCanEdit, "poolName", "bucketName", "location")},
 }
 
+var storagePoolBucketStateCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/buckets/{bucketName}/state",
+
+	Get: APIEndpointAction{Handler: storagePoolBucketStateGet, AccessHandler: allowPermission(auth.ObjectTypeStorageBucket, auth.EntitlementCanView, "poolName", "bucketName", "location")},
+}
+
 var storagePoolBucketKeysCmd = APIEndpoint{
 	Path: "storage-pools/{poolName}/buckets/{bucketName}/keys",
 
@@ -361,6 +367,93 @@ func storagePoolBucketGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponseETag(true, bucket, bucket.Etag())
 }
 
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName}/state storage storage_pool_bucket_state_get
+//
+//	Get the storage pool bucket state
+//
+//	Gets the space used by a specific storage pool bucket.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: server01
+//	responses:
+//	  "200":
+//	    description: Storage pool bucket state
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/StorageBucketState"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBucketStateGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	bucketProjectName, err := project.StorageBucketProject(r.Context(), s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading storage pool: %w", err))
+	}
+
+	if !pool.Driver().Info().Buckets {
+		return response.BadRequest(fmt.Errorf("Storage pool does not support buckets"))
+	}
+
+	bucketName, err := url.PathUnescape(mux.Vars(r)["bucketName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	state, err := pool.GetBucketState(bucketProjectName, bucketName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, state)
+}
+
 // swagger:operation POST /1.0/storage-pools/{poolName}/buckets storage storage_pool_bucket_post
 //
 //	Add a storage pool bucket.
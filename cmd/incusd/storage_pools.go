@@ -13,6 +13,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/lxc/incus/v6/client"
+	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/cluster"
 	clusterRequest "github.com/lxc/incus/v6/internal/server/cluster/request"
@@ -951,6 +952,22 @@ func storagePoolDelete(d *Daemon, r *http.Request) response.Response {
 			return response.BadRequest(fmt.Errorf("The storage pool is currently in use"))
 		}
 
+		// The storage volumes of the recycle bin have no database record, so they aren't seen as using the pool.
+		var recycleBinEntries []api.RecycleBinEntry
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			recycleBinEntries, err = tx.GetRecycleBinEntries(ctx, "")
+			return err
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		for _, entry := range recycleBinEntries {
+			if entry.Pool == pool.Name() {
+				return response.BadRequest(fmt.Errorf("The storage pool still holds entries of the recycle bin, purge them first"))
+			}
+		}
+
 		// Get the cluster notifier
 		notifier, err = cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAll)
 		if err != nil {
@@ -993,6 +1010,38 @@ func storagePoolDelete(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	notifyDelete := func() error {
+		return notifier(func(client incus.InstanceServer) error {
+			_, _, err := client.GetServer()
+			if err != nil {
+				return err
+			}
+
+			return client.DeleteStoragePool(pool.Name())
+		})
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Remote storage is released by the other nodes first, so it's no longer in use when removed.
+	if !clusterNotification && pool.Driver().Info().Remote {
+		reverter.Add(func() {
+			err := notifier(func(client incus.InstanceServer) error {
+				_, _, err := client.RawQuery("POST", "/internal/storage-pools/"+url.PathEscape(pool.Name())+"/mount", nil, "")
+				return err
+			})
+			if err != nil {
+				logger.Error("Failed to mount storage pool again on other members", logger.Ctx{"name": pool.Name(), "error": err})
+			}
+		})
+
+		err = notifyDelete()
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if pool.LocalStatus() != api.StoragePoolStatusPending {
 		err = pool.Delete(clientType, nil)
 		if err != nil {
@@ -1007,16 +1056,11 @@ func storagePoolDelete(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// If we are clustered, also notify all other nodes.
-	err = notifier(func(client incus.InstanceServer) error {
-		_, _, err := client.GetServer()
+	if !pool.Driver().Info().Remote {
+		err = notifyDelete()
 		if err != nil {
-			return err
+			return response.SmartError(err)
 		}
-
-		return client.DeleteStoragePool(pool.Name())
-	})
-	if err != nil {
-		return response.SmartError(err)
 	}
 
 	err = dbStoragePoolDeleteAndUpdateCache(r.Context(), s, pool.Name())
@@ -1024,6 +1068,8 @@ func storagePoolDelete(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	reverter.Success()
+
 	// Remove the storage pool from the authorizer.
 	err = s.Authorizer.DeleteStoragePool(r.Context(), pool.Name())
 	if err != nil {
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/internal/server/warnings"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// storagePoolHealthTimeout is how long a storage pool health check may take before the pool is considered failed.
+const storagePoolHealthTimeout = 30 * time.Second
+
+// storagePoolHealthThreshold is the number of consecutive failed health checks after which a storage pool is
+// considered failed, so that a transient error doesn't stop its instances.
+const storagePoolHealthThreshold = 3
+
+// storagePoolHealthStreaks holds the number of consecutive failed health checks of the storage pools, keyed by
+// pool name.
+var storagePoolHealthStreaks = map[string]int{}
+
+// storagePoolHealthFailures holds the failure of the storage pools which failed on this server, keyed by pool name.
+var storagePoolHealthFailures = map[string]string{}
+
+// storagePoolHealthChecks holds the start time of the running health checks, keyed by pool name.
+// Checks stuck on unresponsive storage keep running in the background, so only one is run at a time per pool.
+var storagePoolHealthChecks = map[string]time.Time{}
+var storagePoolHealthMu sync.Mutex
+
+// storagePoolHealthTask periodically checks the health of the local storage pools and fences the instances using
+// the failed ones.
+func storagePoolHealthTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := storagePoolHealthCheck(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed checking storage pool health", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(10 * time.Second)
+}
+
+// storagePoolHealthCheck checks the health of all the storage pools mounted on this server.
+func storagePoolHealthCheck(ctx context.Context, s *state.State) error {
+	var poolNames []string
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolNames, err = tx.GetCreatedStoragePoolNames(ctx)
+
+		return err
+	})
+	if err != nil {
+		if response.IsNotFoundError(err) {
+			return nil
+		}
+
+		return fmt.Errorf("Failed loading storage pools: %w", err)
+	}
+
+	wg := sync.WaitGroup{}
+
+	for _, poolName := range poolNames {
+		storagePoolHealthMu.Lock()
+		_, failed := storagePoolHealthFailures[poolName]
+		storagePoolHealthMu.Unlock()
+
+		// Pools which couldn't be initialized are retried by storageStartup.
+		if !failed && !storagePools.IsAvailable(poolName) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(poolName string) {
+			defer wg.Done()
+			storagePoolHealthCheckPool(s, poolName)
+		}(poolName)
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// storagePoolHealthCheckPool checks the health of a storage pool, fencing it when it fails and recovering it once
+// it's healthy again.
+func storagePoolHealthCheckPool(s *state.State, poolName string) {
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		if !response.IsNotFoundError(err) {
+			logger.Warn("Failed loading storage pool for health check", logger.Ctx{"pool": poolName, "err": err})
+		}
+
+		return
+	}
+
+	probeErr := storagePoolHealthProbe(pool)
+
+	storagePoolHealthMu.Lock()
+	fence, recovered := storagePoolHealthRecord(poolName, probeErr)
+	streak := storagePoolHealthStreaks[poolName]
+	storagePoolHealthMu.Unlock()
+
+	if fence {
+		storagePoolFence(s, pool, probeErr)
+	} else if recovered {
+		storagePoolRecover(s, pool)
+	} else if probeErr != nil && streak < storagePoolHealthThreshold {
+		logger.Warn("Storage pool health check failed", logger.Ctx{"pool": poolName, "failures": streak, "err": probeErr})
+	}
+}
+
+// storagePoolHealthRecord records the result of a health check of a storage pool, returning whether the pool just
+// failed and must be fenced, or just recovered. The caller must hold storagePoolHealthMu.
+func storagePoolHealthRecord(poolName string, probeErr error) (bool, bool) {
+	_, failed := storagePoolHealthFailures[poolName]
+
+	if probeErr == nil {
+		delete(storagePoolHealthStreaks, poolName)
+		return false, failed
+	}
+
+	storagePoolHealthStreaks[poolName]++
+	if failed || storagePoolHealthStreaks[poolName] < storagePoolHealthThreshold {
+		return false, false
+	}
+
+	storagePoolHealthFailures[poolName] = probeErr.Error()
+
+	return true, false
+}
+
+// storagePoolHealthProbe runs the health check of a storage pool, giving up after storagePoolHealthTimeout.
+func storagePoolHealthProbe(pool storagePools.Pool) error {
+	storagePoolHealthMu.Lock()
+	startedAt, running := storagePoolHealthChecks[pool.Name()]
+	if !running {
+		storagePoolHealthChecks[pool.Name()] = time.Now()
+	}
+
+	storagePoolHealthMu.Unlock()
+
+	if running {
+		return fmt.Errorf("Storage pool health check still hasn't completed after %s", time.Since(startedAt).Truncate(time.Second))
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := pool.CheckHealth()
+
+		storagePoolHealthMu.Lock()
+		delete(storagePoolHealthChecks, pool.Name())
+		storagePoolHealthMu.Unlock()
+
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(storagePoolHealthTimeout):
+		return fmt.Errorf("Storage pool health check timed out after %s", storagePoolHealthTimeout)
+	}
+}
+
+// storagePoolFence marks a failed storage pool as unavailable and stops the local instances using it.
+func storagePoolFence(s *state.State, pool storagePools.Pool, failure error) {
+	l := logger.AddContext(logger.Ctx{"pool": pool.Name()})
+	l.Error("Storage pool failed, stopping the instances using it", logger.Ctx{"err": failure})
+
+	// Prevent instances from being started on the pool until it recovers.
+	storagePools.SetUnavailable(pool.Name())
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, "", cluster.TypeStoragePool, int(pool.ID()), warningtype.StoragePoolFailure, failure.Error())
+	})
+	if err != nil {
+		l.Warn("Failed to create storage pool failure warning", logger.Ctx{"err": err})
+	}
+
+	instances, err := storagePoolHealthInstances(s, pool.Name())
+	if err != nil {
+		l.Error("Failed loading the instances using the failed storage pool", logger.Ctx{"err": err})
+		return
+	}
+
+	message := fmt.Sprintf("Storage pool %q failed: %v", pool.Name(), failure)
+
+	for _, inst := range instances {
+		if !inst.IsRunning() {
+			continue
+		}
+
+		storagePoolHealthMarkInstance(s, inst, message)
+
+		// Stopping may hang on the failed storage, so don't wait for it.
+		go storagePoolFenceInstance(inst)
+	}
+}
+
+// storagePoolHealthMarkInstance records the storage pool failure affecting an instance and raises a warning
+// for it.
+func storagePoolHealthMarkInstance(s *state.State, inst instance.Instance, message string) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	err := inst.VolatileSet(map[string]string{"volatile.storage.failure": message})
+	if err != nil {
+		l.Warn("Failed marking instance as affected by the storage pool failure", logger.Ctx{"err": err})
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, cluster.TypeInstance, inst.ID(), warningtype.InstanceStorageFailure, message)
+	})
+	if err != nil {
+		l.Warn("Failed to create instance storage failure warning", logger.Ctx{"err": err})
+	}
+}
+
+// storagePoolHealthClearInstance clears the storage pool failure of an instance and resolves its warning.
+func storagePoolHealthClearInstance(s *state.State, inst instance.Instance) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	err := inst.VolatileSet(map[string]string{"volatile.storage.failure": ""})
+	if err != nil {
+		l.Warn("Failed clearing instance storage failure", logger.Ctx{"err": err})
+	}
+
+	err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Project().Name, warningtype.InstanceStorageFailure, cluster.TypeInstance, inst.ID())
+	if err != nil {
+		l.Warn("Failed to resolve instance storage failure warning", logger.Ctx{"err": err})
+	}
+}
+
+// storagePoolFenceInstance stops an instance affected by a storage pool failure, cleanly if possible.
+func storagePoolFenceInstance(inst instance.Instance) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+	if err != nil {
+		timeout = 30
+	}
+
+	err = inst.Shutdown(time.Duration(timeout) * time.Second)
+	if err == nil {
+		l.Info("Stopped instance affected by storage pool failure")
+		return
+	}
+
+	l.Warn("Failed shutting down instance affected by storage pool failure, forcing it to stop", logger.Ctx{"err": err})
+
+	err = inst.Stop(false)
+	if err != nil {
+		l.Error("Failed stopping instance affected by storage pool failure", logger.Ctx{"err": err})
+		return
+	}
+
+	l.Info("Stopped instance affected by storage pool failure")
+}
+
+// storagePoolRecover mounts a storage pool which is healthy again and clears the failure of its instances.
+// The stopped instances aren't started again.
+func storagePoolRecover(s *state.State, pool storagePools.Pool) {
+	l := logger.AddContext(logger.Ctx{"pool": pool.Name()})
+
+	_, err := pool.Mount()
+	if err != nil {
+		l.Warn("Failed mounting recovered storage pool", logger.Ctx{"err": err})
+		return
+	}
+
+	storagePoolHealthMu.Lock()
+	delete(storagePoolHealthFailures, pool.Name())
+	storagePoolHealthMu.Unlock()
+
+	l.Info("Storage pool recovered")
+
+	err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, "", warningtype.StoragePoolFailure, cluster.TypeStoragePool, int(pool.ID()))
+	if err != nil {
+		l.Warn("Failed to resolve storage pool failure warning", logger.Ctx{"err": err})
+	}
+
+	instances, err := storagePoolHealthInstances(s, pool.Name())
+	if err != nil {
+		l.Warn("Failed loading the instances using the recovered storage pool", logger.Ctx{"err": err})
+		return
+	}
+
+	for _, inst := range instances {
+		if inst.LocalConfig()["volatile.storage.failure"] == "" {
+			continue
+		}
+
+		storagePoolHealthClearInstance(s, inst)
+	}
+}
+
+// storagePoolHealthInstances returns the local instances with a disk on the storage pool.
+func storagePoolHealthInstances(s *state.State, poolName string) ([]instance.Instance, error) {
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return nil, err
+	}
+
+	affected := []instance.Instance{}
+	for _, inst := range instances {
+		for _, dev := range inst.ExpandedDevices() {
+			if dev["type"] == "disk" && dev["pool"] == poolName {
+				affected = append(affected, inst)
+				break
+			}
+		}
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+)
+
+func TestStoragePoolHealthRecord(t *testing.T) {
+	failure := errors.New("Pool is offline")
+
+	defer func() {
+		delete(storagePoolHealthStreaks, "pool1")
+		delete(storagePoolHealthFailures, "pool1")
+	}()
+
+	// Failures below the threshold don't fence the pool, and a success resets them.
+	for i := 0; i < storagePoolHealthThreshold-1; i++ {
+		fence, recovered := storagePoolHealthRecord("pool1", failure)
+		require.False(t, fence)
+		require.False(t, recovered)
+	}
+
+	fence, recovered := storagePoolHealthRecord("pool1", nil)
+	require.False(t, fence)
+	require.False(t, recovered)
+	require.Zero(t, storagePoolHealthStreaks["pool1"])
+
+	// Consecutive failures fence the pool once.
+	for i := 0; i < storagePoolHealthThreshold-1; i++ {
+		fence, _ = storagePoolHealthRecord("pool1", failure)
+		require.False(t, fence)
+	}
+
+	fence, recovered = storagePoolHealthRecord("pool1", failure)
+	require.True(t, fence)
+	require.False(t, recovered)
+	require.Equal(t, failure.Error(), storagePoolHealthFailures["pool1"])
+
+	fence, recovered = storagePoolHealthRecord("pool1", failure)
+	require.False(t, fence)
+	require.False(t, recovered)
+
+	// A successful check recovers the failed pool.
+	fence, recovered = storagePoolHealthRecord("pool1", nil)
+	require.False(t, fence)
+	require.True(t, recovered)
+}
+
+// storagePoolHealthInstanceWarning returns the status of the storage failure warning of an instance.
+func (suite *containerTestSuite) storagePoolHealthInstanceWarning(inst instance.Instance) warningtype.Status {
+	var status warningtype.Status
+
+	err := suite.d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		typeCode := warningtype.InstanceStorageFailure
+		entityID := inst.ID()
+
+		dbWarnings, err := dbCluster.GetWarnings(ctx, tx.Tx(), dbCluster.WarningFilter{TypeCode: &typeCode, EntityID: &entityID})
+		if err != nil {
+			return err
+		}
+
+		if len(dbWarnings) > 0 {
+			status = dbWarnings[0].Status
+		}
+
+		return nil
+	})
+	suite.Req.Nil(err)
+
+	return status
+}
+
+func (suite *containerTestSuite) TestStoragePoolHealthInstanceFailure() {
+	s := suite.d.State()
+
+	c, op, _, err := instance.CreateInternal(s, db.InstanceArgs{Type: instancetype.Container, Name: "c1"}, true, true)
+	suite.Req.Nil(err)
+	op.Done(nil)
+	defer func() { _ = c.Delete(true) }()
+
+	// Only the instances with a disk on the storage pool are affected.
+	instances, err := storagePoolHealthInstances(s, daemonTestSuiteDefaultStoragePool)
+	suite.Req.Nil(err)
+	suite.Req.Len(instances, 1)
+	suite.Req.Equal("c1", instances[0].Name())
+
+	instances, err = storagePoolHealthInstances(s, "other")
+	suite.Req.Nil(err)
+	suite.Req.Empty(instances)
+
+	// The failure is recorded with a warning and the instance is reported in error, even though it's stopped.
+	suite.Req.Equal("STOPPED", c.State())
+	storagePoolHealthMarkInstance(s, c, "Storage pool failed")
+
+	inst, err := instance.LoadByProjectAndName(s, "default", "c1")
+	suite.Req.Nil(err)
+	suite.Req.Equal("Storage pool failed", inst.LocalConfig()["volatile.storage.failure"])
+	suite.Req.Equal("ERROR", inst.State())
+	suite.Req.Equal(warningtype.StatusNew, suite.storagePoolHealthInstanceWarning(inst))
+
+	// Once the storage pool recovers, the failure is cleared and the warning resolved.
+	pool, err := storagePools.LoadByName(s, daemonTestSuiteDefaultStoragePool)
+	suite.Req.Nil(err)
+	storagePoolRecover(s, pool)
+
+	inst, err = instance.LoadByProjectAndName(s, "default", "c1")
+	suite.Req.Nil(err)
+	suite.Req.Empty(inst.LocalConfig()["volatile.storage.failure"])
+	suite.Req.Equal("STOPPED", inst.State())
+	suite.Req.Equal(warningtype.StatusResolved, suite.storagePoolHealthInstanceWarning(inst))
+}
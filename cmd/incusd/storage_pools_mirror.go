@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+var storagePoolMirrorCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/mirror",
+
+	Get:  APIEndpointAction{Handler: storagePoolMirrorGet, AccessHandler: allowPermission(auth.ObjectTypeStoragePool, auth.EntitlementCanView, "poolName")},
+	Post: APIEndpointAction{Handler: storagePoolMirrorPost, AccessHandler: allowPermission(auth.ObjectTypeStoragePool, auth.EntitlementCanEdit, "poolName")},
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/mirror storage storage_pool_mirror_get
+//
+//	Get the storage pool mirroring state
+//
+//	Gets the mirroring mode, health and peers of the storage pool.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Storage pool mirroring state
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/StoragePoolMirrorState"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolMirrorGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	state, err := pool.GetMirrorState()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, state)
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/mirror storage storage_pool_mirror_post
+//
+//	Promote or demote the storage pool
+//
+//	Makes the mirrored volumes of the storage pool primary or non-primary, to fail over to or from a peer cluster.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: mirror
+//	    description: Mirroring role change
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/StoragePoolMirrorPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolMirrorPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.StoragePoolMirrorPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Demoting the pool makes its volumes read-only, so they mustn't be in use by running instances.
+	if req.Action == "demote" {
+		err = storagePoolMirrorDemoteCheck(s, r, pool)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	err = pool.UpdateMirror(req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+	if req.Action == "demote" {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.StoragePoolDemoted.Event(pool.Name(), requestor, nil))
+	} else {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.StoragePoolPromoted.Event(pool.Name(), requestor, logger.Ctx{"force": req.Force}))
+	}
+
+	return response.EmptySyncResponse
+}
+
+// storagePoolMirrorDemoteCheck returns an error if a volume of the pool is used by an instance running on any
+// cluster member.
+func storagePoolMirrorDemoteCheck(s *state.State, r *http.Request, pool storagePools.Pool) error {
+	var volumes []*db.StorageVolume
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		volumes, err = tx.GetStoragePoolVolumes(ctx, pool.ID(), false)
+		if err != nil {
+			return fmt.Errorf("Failed loading storage volumes: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, vol := range volumes {
+		switch vol.Type {
+		case db.StoragePoolVolumeTypeNameContainer, db.StoragePoolVolumeTypeNameVM:
+			// Snapshots can't be running.
+			if internalInstance.IsSnapshot(vol.Name) {
+				continue
+			}
+
+			inst, err := instance.LoadByProjectAndName(s, vol.Project, vol.Name)
+			if err != nil {
+				return err
+			}
+
+			running, err := storagePoolMirrorInstanceRunning(s, r, inst)
+			if err != nil {
+				return err
+			}
+
+			if running {
+				return api.StatusErrorf(http.StatusBadRequest, "Cannot demote storage pool used by running instance %q in project %q", inst.Name(), inst.Project().Name)
+			}
+
+		case db.StoragePoolVolumeTypeNameCustom:
+			err = storagePools.VolumeUsedByInstanceDevices(s, pool.Name(), vol.Project, &vol.StorageVolume, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+				inst, err := instance.Load(s, dbInst, project)
+				if err != nil {
+					return err
+				}
+
+				running, err := storagePoolMirrorInstanceRunning(s, r, inst)
+				if err != nil {
+					return err
+				}
+
+				if running {
+					return api.StatusErrorf(http.StatusBadRequest, "Cannot demote storage pool with custom volume %q used by running instance %q in project %q", vol.Name, inst.Name(), project.Name)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// storagePoolMirrorInstanceRunning returns whether the instance is running, asking the cluster member it's located
+// on when that's not the local one.
+func storagePoolMirrorInstanceRunning(s *state.State, r *http.Request, inst instance.Instance) (bool, error) {
+	client, err := cluster.ConnectIfInstanceIsRemote(s, inst.Project().Name, inst.Name(), r, inst.Type())
+	if err != nil {
+		return false, fmt.Errorf("Failed connecting to the cluster member of instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
+
+	if client == nil {
+		return inst.IsRunning(), nil
+	}
+
+	apiInst, _, err := client.GetInstance(inst.Name())
+	if err != nil {
+		return false, fmt.Errorf("Failed getting the state of instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
+
+	return apiInst.StatusCode == api.Running, nil
+}
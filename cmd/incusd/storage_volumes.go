@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -30,19 +31,23 @@ import (
 	"github.com/lxc/incus/v6/internal/server/db"
 	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/archive"
 	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/osarch"
 	localtls "github.com/lxc/incus/v6/shared/tls"
 	"github.com/lxc/incus/v6/shared/util"
 )
@@ -96,6 +101,16 @@ var storagePoolVolumeTypeCmd = APIEndpoint{
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return (all of them if 0)
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: offset
+//      description: Number of entries to skip
+//      type: integer
+//      example: 200
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -157,6 +172,16 @@ var storagePoolVolumeTypeCmd = APIEndpoint{
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return (all of them if 0)
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: offset
+//      description: Number of entries to skip
+//      type: integer
+//      example: 200
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -325,6 +350,11 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Invalid filter: %w", err))
 	}
 
+	limit, offset, err := localUtil.PaginationFromRequest(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Retrieve the storage pool (and check if the storage pool exists).
 	pool, err := storagePools.LoadByName(s, poolName)
 	if err != nil {
@@ -439,7 +469,7 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	// Sort by type then volume name.
+	// Sort by type then volume name (then project and location so that the pages are consistent).
 	sort.SliceStable(dbVolumes, func(i, j int) bool {
 		volA := dbVolumes[i]
 		volB := dbVolumes[j]
@@ -448,7 +478,15 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 			return dbVolumes[i].Type < dbVolumes[j].Type
 		}
 
-		return volA.Name < volB.Name
+		if volA.Name != volB.Name {
+			return volA.Name < volB.Name
+		}
+
+		if volA.Project != volB.Project {
+			return volA.Project < volB.Project
+		}
+
+		return volA.Location < volB.Location
 	})
 
 	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, auth.ObjectTypeStorageVolume)
@@ -456,21 +494,30 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Only keep the volumes the user can see, before selecting the requested page.
+	allowedVolumes := make([]*db.StorageVolume, 0, len(dbVolumes))
+	for _, dbVol := range dbVolumes {
+		volumeName, _, _ := api.GetParentAndSnapshotName(dbVol.Name)
+
+		var location string
+		if s.ServerClustered && !pool.Driver().Info().Remote {
+			location = dbVol.Location
+		}
+
+		if !userHasPermission(auth.ObjectStorageVolume(dbVol.Project, poolName, dbVol.Type, volumeName, location)) {
+			continue
+		}
+
+		allowedVolumes = append(allowedVolumes, dbVol)
+	}
+
+	dbVolumes = localUtil.Paginate(allowedVolumes, limit, offset)
+
 	if localUtil.IsRecursionRequest(r) {
 		volumes := make([]*api.StorageVolume, 0, len(dbVolumes))
 		for _, dbVol := range dbVolumes {
 			vol := &dbVol.StorageVolume
 
-			var location string
-			if s.ServerClustered && !pool.Driver().Info().Remote {
-				location = vol.Location
-			}
-
-			volumeName, _, _ := api.GetParentAndSnapshotName(vol.Name)
-			if !userHasPermission(auth.ObjectStorageVolume(vol.Project, poolName, dbVol.Type, volumeName, location)) {
-				continue
-			}
-
 			// Fill in UsedBy if we haven't previously done so.
 			if clauses == nil || len(clauses.Clauses) == 0 {
 				volumeUsedBy, err := storagePoolVolumeUsedByGet(s, requestProjectName, poolName, dbVol)
@@ -489,17 +536,6 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 
 	urls := make([]string, 0, len(dbVolumes))
 	for _, dbVol := range dbVolumes {
-		volumeName, _, _ := api.GetParentAndSnapshotName(dbVol.Name)
-
-		var location string
-		if s.ServerClustered && !pool.Driver().Info().Remote {
-			location = dbVol.Location
-		}
-
-		if !userHasPermission(auth.ObjectStorageVolume(dbVol.Project, poolName, dbVol.Type, volumeName, location)) {
-			continue
-		}
-
 		urls = append(urls, dbVol.StorageVolume.URL(version.APIVersion, poolName).String())
 	}
 
@@ -643,8 +679,31 @@ func storagePoolVolumesPost(d *Daemon, r *http.Request) response.Response {
 
 	// If we're getting binary content, process separately.
 	if r.Header.Get("Content-Type") == "application/octet-stream" {
+		attach := storagePoolVolumeImportAttach{
+			instanceName: r.Header.Get("X-Incus-attach-to"),
+			deviceName:   r.Header.Get("X-Incus-device"),
+		}
+
+		if attach.instanceName != "" {
+			if !slices.Contains([]string{"iso", "disk"}, r.Header.Get("X-Incus-type")) {
+				return response.BadRequest(fmt.Errorf("Only ISO and disk images can be attached on import"))
+			}
+
+			// Check the instance before receiving the image.
+			_, err = storagePoolVolumeImportAttachInstance(s, request.ProjectParam(r), attach.instanceName)
+			if err != nil {
+				return response.SmartError(err)
+			}
+		} else if attach.deviceName != "" {
+			return response.BadRequest(fmt.Errorf("The device name requires an instance to attach the volume to"))
+		}
+
 		if r.Header.Get("X-Incus-type") == "iso" {
-			return createStoragePoolVolumeFromISO(s, r, request.ProjectParam(r), projectName, r.Body, poolName, r.Header.Get("X-Incus-name"))
+			return createStoragePoolVolumeFromISO(s, r, request.ProjectParam(r), projectName, r.Body, poolName, r.Header.Get("X-Incus-name"), attach)
+		}
+
+		if r.Header.Get("X-Incus-type") == "disk" {
+			return createStoragePoolVolumeFromDiskImage(s, r, request.ProjectParam(r), projectName, r.Body, poolName, r.Header.Get("X-Incus-name"), attach)
 		}
 
 		return createStoragePoolVolumeFromBackup(s, r, request.ProjectParam(r), projectName, r.Body, poolName, r.Header.Get("X-Incus-name"))
@@ -748,6 +807,11 @@ func storagePoolVolumesPost(d *Daemon, r *http.Request) response.Response {
 		return clusterCopyCustomVolumeInternal(s, r, nodeAddress, projectName, poolName, &req)
 	}
 
+	err = storagePoolVolumeTransferConfigCheck(s, r, nil, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	switch req.Source.Type {
 	case "":
 		return doVolumeCreateOrCopy(s, r, request.ProjectParam(r), projectName, poolName, &req)
@@ -1339,6 +1403,17 @@ func storagePoolVolumePost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Live moves only apply to pool changes on the same project.
+	if req.Live {
+		if req.Pool == "" || req.Pool == srcPoolName {
+			return response.BadRequest(fmt.Errorf("Live moves require a different storage pool"))
+		}
+
+		if projectName != targetProjectName {
+			return response.BadRequest(fmt.Errorf("Storage volumes cannot be moved live across projects"))
+		}
+	}
+
 	// Check if a running instance is using it.
 	err = storagePools.VolumeUsedByInstanceDevices(s, srcPoolName, projectName, &dbVolume.StorageVolume, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
 		inst, err := instance.Load(s, dbInst, project)
@@ -1346,10 +1421,19 @@ func storagePoolVolumePost(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
-		if inst.IsRunning() {
+		if !inst.IsRunning() {
+			return nil
+		}
+
+		if !req.Live {
 			return fmt.Errorf("Volume is still in use by running instances")
 		}
 
+		// Filesystem volumes can't be swapped under a running virtual machine.
+		if inst.Type() == instancetype.VM && dbVolume.ContentType == db.StoragePoolVolumeContentTypeNameFS {
+			return fmt.Errorf("Filesystem volumes used by running virtual machines cannot be moved live")
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -1587,6 +1671,10 @@ func storagePoolVolumeTypePostMove(s *state.State, r *http.Request, poolName str
 	}
 
 	run := func(op *operations.Operation) error {
+		if req.Live {
+			return storagePoolVolumeLiveMove(s, pool, newPool, requestProjectName, vol, &newVol, op)
+		}
+
 		revert := revert.New()
 		defer revert.Fail()
 
@@ -1624,6 +1712,97 @@ func storagePoolVolumeTypePostMove(s *state.State, r *http.Request, poolName str
 	return operations.OperationResponse(op)
 }
 
+// storagePoolVolumeLiveMove moves a custom volume to a new pool on the same server while the instances using it keep
+// running. The volume is first copied while still in use, then the running instances using it are frozen while the
+// changes made during the copy are synced and their devices are switched over to the new volume.
+func storagePoolVolumeLiveMove(s *state.State, pool storagePools.Pool, newPool storagePools.Pool, projectName string, vol *api.StorageVolume, newVol *api.StorageVolume, op *operations.Operation) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Copy the volume and its snapshots while it's still in use.
+	err := newPool.CreateCustomVolumeFromCopy(projectName, projectName, newVol.Name, "", nil, pool.Name(), vol.Name, true, op)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _ = newPool.DeleteCustomVolume(projectName, newVol.Name, nil) })
+
+	// Freeze the running instances using the volume so it doesn't change during the final sync.
+	frozen := []instance.Instance{}
+	unfreeze := func() {
+		for _, inst := range frozen {
+			err := inst.Unfreeze()
+			if err != nil {
+				logger.Warn("Failed unfreezing instance after storage volume move", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			}
+		}
+
+		frozen = nil
+	}
+
+	defer unfreeze()
+
+	err = storagePools.VolumeUsedByInstanceDevices(s, pool.Name(), projectName, vol, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+		inst, err := instance.Load(s, dbInst, project)
+		if err != nil {
+			return err
+		}
+
+		if !inst.IsRunning() || inst.IsFrozen() {
+			return nil
+		}
+
+		err = inst.Freeze()
+		if err != nil {
+			return fmt.Errorf("Failed freezing instance %q: %w", inst.Name(), err)
+		}
+
+		frozen = append(frozen, inst)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sync the changes made during the copy.
+	err = newPool.RefreshCustomVolume(projectName, projectName, newVol.Name, "", nil, pool.Name(), vol.Name, true, op)
+	if err != nil {
+		return err
+	}
+
+	// Switch the devices using the volume in instances and profiles over to the new volume. Switching back also
+	// covers the users which were already updated when this fails part way.
+	revert.Add(func() {
+		_ = storagePoolVolumeUpdateUsers(context.TODO(), s, projectName, newPool.Name(), newVol, pool.Name(), vol)
+	})
+
+	err = storagePoolVolumeUpdateUsers(context.TODO(), s, projectName, pool.Name(), vol, newPool.Name(), newVol)
+	if err != nil {
+		return err
+	}
+
+	unfreeze()
+
+	// Only delete the source volume once it's confirmed to be detached from all instances.
+	_, err = pool.UnmountCustomVolume(projectName, vol.Name, op)
+	if err != nil {
+		if errors.Is(err, storageDrivers.ErrInUse) {
+			return fmt.Errorf("Storage volume %q is still in use after switching its users to the new pool", vol.Name)
+		}
+
+		return fmt.Errorf("Failed unmounting storage volume %q: %w", vol.Name, err)
+	}
+
+	err = pool.DeleteCustomVolume(projectName, vol.Name, op)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
 // swagger:operation GET /1.0/storage-pools/{poolName}/volumes/{type}/{volumeName} storage storage_pool_volume_type_get
 //
 //	Get the storage volume
@@ -1879,6 +2058,11 @@ func storagePoolVolumePut(d *Daemon, r *http.Request) response.Response {
 		// Only apply changes during a snapshot restore if a non-nil config is supplied to avoid clearing
 		// the volume's config if only restoring snapshot.
 		if req.Config != nil || req.Restore == "" {
+			err = storagePoolVolumeTransferConfigCheck(s, r, dbVolume.Config, req.Config)
+			if err != nil {
+				return response.SmartError(err)
+			}
+
 			// Possibly check if project limits are honored.
 			err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 				return project.AllowVolumeUpdate(tx, projectName, volumeName, req, dbVolume.Config)
@@ -2046,6 +2230,11 @@ func storagePoolVolumePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	err = storagePoolVolumeTransferConfigCheck(s, r, dbVolume.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Use an empty operation for this sync response to pass the requestor
 	op := &operations.Operation{}
 	op.SetRequestor(r)
@@ -2063,6 +2252,7 @@ func storagePoolVolumePatch(d *Daemon, r *http.Request) response.Response {
 //	Delete the storage volume
 //
 //	Removes the storage volume.
+//	If the recycle bin is enabled, custom volumes are kept in it until they expire.
 //
 //	---
 //	produces:
@@ -2186,7 +2376,17 @@ func storagePoolVolumeDelete(d *Daemon, r *http.Request) response.Response {
 
 	switch volumeType {
 	case db.StoragePoolVolumeTypeCustom:
+		// Move the volume into the recycle bin if enabled.
+		var recycleBinRevert revert.Hook
+		recycleBinRevert, err = recycleBinAddCustomVolume(s, requestProjectName, volumeProjectName, pool, volumeName, op)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		err = pool.DeleteCustomVolume(volumeProjectName, volumeName, op)
+		if err != nil && recycleBinRevert != nil {
+			recycleBinRevert()
+		}
 	case db.StoragePoolVolumeTypeImage:
 		err = pool.DeleteImage(volumeName, op)
 	default:
@@ -2200,7 +2400,7 @@ func storagePoolVolumeDelete(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
-func createStoragePoolVolumeFromISO(s *state.State, r *http.Request, requestProjectName string, projectName string, data io.Reader, pool string, volName string) response.Response {
+func createStoragePoolVolumeFromISO(s *state.State, r *http.Request, requestProjectName string, projectName string, data io.Reader, pool string, volName string, attach storagePoolVolumeImportAttach) response.Response {
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -2249,6 +2449,13 @@ func createStoragePoolVolumeFromISO(s *state.State, r *http.Request, requestProj
 			return fmt.Errorf("Failed creating custom volume from ISO: %w", err)
 		}
 
+		runRevert.Add(func() { _ = pool.DeleteCustomVolume(projectName, volName, op) })
+
+		err = attach.run(s, requestProjectName, pool.Name(), volName, op)
+		if err != nil {
+			return err
+		}
+
 		runRevert.Success()
 		return nil
 	}
@@ -2265,6 +2472,188 @@ func createStoragePoolVolumeFromISO(s *state.State, r *http.Request, requestProj
 	return operations.OperationResponse(op)
 }
 
+// createStoragePoolVolumeFromDiskImage imports a custom block volume from a disk image, optionally attaching it
+// to an instance. The volume is deleted again if it can't be attached.
+func createStoragePoolVolumeFromDiskImage(s *state.State, r *http.Request, requestProjectName string, projectName string, data io.Reader, pool string, volName string, attach storagePoolVolumeImportAttach) response.Response {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if volName == "" {
+		return response.BadRequest(fmt.Errorf("Missing volume name"))
+	}
+
+	// Create temporary file to store uploaded disk image data.
+	imgFile, err := os.CreateTemp(internalUtil.VarPath("images"), fmt.Sprintf("%s_", "incus_disk"))
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	revert.Add(func() {
+		_ = imgFile.Close()
+		_ = os.Remove(imgFile.Name())
+	})
+
+	// Stream uploaded disk image data into temporary file.
+	_, err = io.Copy(imgFile, data)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	err = imgFile.Close()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	// Copy reverter so far so we can use it inside run after this function has finished.
+	runRevert := revert.Clone()
+
+	run := func(op *operations.Operation) error {
+		defer func() { _ = os.Remove(imgFile.Name()) }()
+		defer runRevert.Fail()
+
+		pool, err := storagePools.LoadByName(s, pool)
+		if err != nil {
+			return err
+		}
+
+		// Convert the disk image into the storage volume.
+		err = pool.CreateCustomVolumeFromDiskImage(projectName, volName, imgFile.Name(), op)
+		if err != nil {
+			return fmt.Errorf("Failed creating custom volume from disk image: %w", err)
+		}
+
+		runRevert.Add(func() { _ = pool.DeleteCustomVolume(projectName, volName, op) })
+
+		err = attach.run(s, requestProjectName, pool.Name(), volName, op)
+		if err != nil {
+			return err
+		}
+
+		runRevert.Success()
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_volumes"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", pool, "volumes", "custom", volName)}
+
+	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeCreate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	revert.Success()
+	return operations.OperationResponse(op)
+}
+
+// storagePoolVolumeImportAttach is the instance an imported custom volume gets attached to.
+type storagePoolVolumeImportAttach struct {
+	instanceName string
+	deviceName   string
+}
+
+// run attaches the imported volume to the instance, if any.
+func (a storagePoolVolumeImportAttach) run(s *state.State, projectName string, poolName string, volName string, op *operations.Operation) error {
+	if a.instanceName == "" {
+		return nil
+	}
+
+	if op != nil {
+		_ = op.UpdateMetadata(map[string]any{"create_volume_progress": fmt.Sprintf("Attaching to %s", a.instanceName)})
+	}
+
+	inst, err := storagePoolVolumeImportAttachInstance(s, projectName, a.instanceName)
+	if err != nil {
+		return err
+	}
+
+	devices, err := storagePoolVolumeAttachDevice(inst.LocalDevices(), poolName, volName, a.deviceName)
+	if err != nil {
+		return err
+	}
+
+	apiProfiles := inst.Profiles()
+	profileNames := make([]string, 0, len(apiProfiles))
+	for _, profile := range apiProfiles {
+		profileNames = append(profileNames, profile.Name)
+	}
+
+	architectureName, err := osarch.ArchitectureName(inst.Architecture())
+	if err != nil {
+		return err
+	}
+
+	req := api.InstancePut{
+		Architecture: architectureName,
+		Config:       inst.LocalConfig(),
+		Description:  inst.Description(),
+		Devices:      devices.CloneNative(),
+		Ephemeral:    inst.IsEphemeral(),
+		Profiles:     profileNames,
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return project.AllowInstanceUpdate(tx, projectName, inst.Name(), req, inst.LocalConfig())
+	})
+	if err != nil {
+		return err
+	}
+
+	args := db.InstanceArgs{
+		Architecture: inst.Architecture(),
+		Config:       inst.LocalConfig(),
+		Description:  inst.Description(),
+		Devices:      devices,
+		Ephemeral:    inst.IsEphemeral(),
+		Profiles:     apiProfiles,
+		Project:      projectName,
+	}
+
+	err = inst.Update(args, true)
+	if err != nil {
+		return fmt.Errorf("Failed attaching the volume to %q: %w", a.instanceName, err)
+	}
+
+	return nil
+}
+
+// storagePoolVolumeImportAttachInstance loads the instance an imported volume is attached to, which must be
+// on the local member.
+func storagePoolVolumeImportAttachInstance(s *state.State, projectName string, instName string) (instance.Instance, error) {
+	inst, err := instance.LoadByProjectAndName(s, projectName, instName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance %q: %w", instName, err)
+	}
+
+	if s.ServerClustered && inst.Location() != s.ServerName {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Instance %q isn't on the cluster member importing the volume", instName)
+	}
+
+	return inst, nil
+}
+
+// storagePoolVolumeAttachDevice returns the devices with a new disk device for a custom volume. The device is
+// named after the volume unless a device name is given.
+func storagePoolVolumeAttachDevice(devices deviceConfig.Devices, poolName string, volName string, devName string) (deviceConfig.Devices, error) {
+	if devName == "" {
+		devName = volName
+	}
+
+	_, ok := devices[devName]
+	if ok {
+		return nil, api.StatusErrorf(http.StatusConflict, "Device %q already exists", devName)
+	}
+
+	newDevices := devices.Clone()
+	newDevices[devName] = deviceConfig.Device{
+		"type":   "disk",
+		"pool":   poolName,
+		"source": volName,
+	}
+
+	return newDevices, nil
+}
+
+// createStoragePoolVolumeFromBackup imports a custom volume from a backup tarball.
 func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestProjectName string, projectName string, data io.Reader, pool string, volName string) response.Response {
 	revert := revert.New()
 	defer revert.Fail()
@@ -2345,6 +2734,14 @@ func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestP
 		bInfo.Name = volName
 	}
 
+	// The transfer configuration of the imported volume comes from the backup.
+	if bInfo.Config != nil && bInfo.Config.Volume != nil {
+		err = storagePoolVolumeTransferConfigCheck(s, r, nil, bInfo.Config.Volume.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	logger.Debug("Backup file info loaded", logger.Ctx{
 		"type":      bInfo.Type,
 		"name":      bInfo.Name,
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+var storagePoolVolumeTypeMirrorCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/volumes/{type}/{volumeName}/mirror",
+
+	Post: APIEndpointAction{Handler: storagePoolVolumeTypeMirrorPost, AccessHandler: allowPermission(auth.ObjectTypeStorageVolume, auth.EntitlementCanEdit, "poolName", "type", "volumeName")},
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/volumes/{type}/{volumeName}/mirror storage storage_pool_volume_type_mirror_post
+//
+//	Promote or demote the storage volume
+//
+//	Makes the mirrored storage volume primary or non-primary, to fail it over to or from a peer cluster.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: server01
+//	  - in: body
+//	    name: mirror
+//	    description: Mirroring role change
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/StorageVolumeMirrorPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolVolumeTypeMirrorPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// Get the name of the pool the storage volume is supposed to be attached to.
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get the name of the volume type.
+	volumeTypeName, err := url.PathUnescape(mux.Vars(r)["type"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get the name of the storage volume.
+	volumeName, err := url.PathUnescape(mux.Vars(r)["volumeName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Convert the volume type name to our internal integer representation.
+	volumeType, err := storagePools.VolumeTypeNameToDBType(volumeTypeName)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Check that the storage volume type is valid.
+	if !slices.Contains([]int{db.StoragePoolVolumeTypeCustom, db.StoragePoolVolumeTypeContainer, db.StoragePoolVolumeTypeVM}, volumeType) {
+		return response.BadRequest(fmt.Errorf("Invalid storage volume type %q", volumeTypeName))
+	}
+
+	// Get the storage project name.
+	projectName, err := project.StorageVolumeProject(s.DB.Cluster, request.ProjectParam(r), volumeType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.StorageVolumeMirrorPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Load the storage pool.
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if volumeType == db.StoragePoolVolumeTypeCustom {
+		resp := forwardedResponseIfTargetIsRemote(s, r)
+		if resp != nil {
+			return resp
+		}
+
+		resp = forwardedResponseIfVolumeIsRemote(s, r, poolName, projectName, volumeName, volumeType)
+		if resp != nil {
+			return resp
+		}
+
+		// Custom volumes.
+		err = pool.UpdateCustomVolumeMirror(projectName, volumeName, req, nil)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	} else {
+		resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, volumeName, instancetype.Any)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if resp != nil {
+			return resp
+		}
+
+		// Instance volumes.
+		inst, err := instance.LoadByProjectAndName(s, projectName, volumeName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = pool.UpdateInstanceMirror(inst, req, nil)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	return response.EmptySyncResponse
+}
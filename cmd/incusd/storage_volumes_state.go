@@ -114,14 +114,26 @@ func storagePoolVolumeTypeStateGet(d *Daemon, r *http.Request) response.Response
 		return response.SmartError(err)
 	}
 
-	// Fetch the current usage.
+	// Fetch the current usage, mirroring state and IO counters.
 	var usage *storagePools.VolumeUsage
+	var mirror *api.StorageVolumeStateMirror
+	var io *api.StorageVolumeStateIO
 	if volumeType == db.StoragePoolVolumeTypeCustom {
 		// Custom volumes.
 		usage, err = pool.GetCustomVolumeUsage(projectName, volumeName)
 		if err != nil {
 			return response.SmartError(err)
 		}
+
+		mirror, err = pool.GetCustomVolumeMirrorState(projectName, volumeName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		io, err = pool.GetCustomVolumeIOStats(projectName, volumeName)
+		if err != nil {
+			return response.SmartError(err)
+		}
 	} else {
 		resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, volumeName, instancetype.Any)
 		if err != nil {
@@ -142,11 +154,23 @@ func storagePoolVolumeTypeStateGet(d *Daemon, r *http.Request) response.Response
 		if err != nil {
 			return response.SmartError(err)
 		}
+
+		mirror, err = pool.GetInstanceMirrorState(inst)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		io, err = pool.GetInstanceIOStats(inst)
+		if err != nil {
+			return response.SmartError(err)
+		}
 	}
 
 	// Prepare the state struct.
 	state := api.StorageVolumeState{}
 	state.Usage = &api.StorageVolumeStateUsage{}
+	state.Mirror = mirror
+	state.IO = io
 
 	// Only fill 'used' field if receiving a valid value.
 	if usage.Used >= 0 {
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestStoragePoolVolumeAttachDevice(t *testing.T) {
+	devices := deviceConfig.Devices{
+		"root": deviceConfig.Device{"type": "disk", "pool": "default", "path": "/"},
+	}
+
+	// The device is named after the volume by default.
+	newDevices, err := storagePoolVolumeAttachDevice(devices, "default", "data", "")
+	require.NoError(t, err)
+	assert.Equal(t, deviceConfig.Device{"type": "disk", "pool": "default", "source": "data"}, newDevices["data"])
+	assert.Equal(t, devices["root"], newDevices["root"])
+
+	// The current devices are left untouched.
+	assert.Len(t, devices, 1)
+
+	// A device name can be given.
+	newDevices, err = storagePoolVolumeAttachDevice(devices, "default", "data", "data1")
+	require.NoError(t, err)
+	assert.Contains(t, newDevices, "data1")
+	assert.NotContains(t, newDevices, "data")
+
+	// Existing devices are never replaced.
+	_, err = storagePoolVolumeAttachDevice(devices, "default", "root", "")
+	require.Error(t, err)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict))
+}
+
+func (suite *containerTestSuite) TestStoragePoolVolumeImportAttach() {
+	c, op, _, err := instance.CreateInternal(suite.d.State(), db.InstanceArgs{
+		Type:    instancetype.Container,
+		Name:    "c1",
+		Devices: deviceConfig.Devices{"data": deviceConfig.Device{"type": "none"}},
+	}, true, true)
+	suite.Req.Nil(err)
+	op.Done(nil)
+	defer func() { _ = c.Delete(true) }()
+
+	// Nothing is attached without an instance.
+	err = storagePoolVolumeImportAttach{}.run(suite.d.State(), "default", "default", "data", nil)
+	suite.Req.Nil(err)
+
+	// The instance must exist.
+	attach := storagePoolVolumeImportAttach{instanceName: "c2"}
+	err = attach.run(suite.d.State(), "default", "default", "data", nil)
+	suite.Req.True(api.StatusErrorCheck(err, http.StatusNotFound))
+
+	// Existing devices of the instance are never replaced.
+	attach = storagePoolVolumeImportAttach{instanceName: "c1"}
+	err = attach.run(suite.d.State(), "default", "default", "data", nil)
+	suite.Req.True(api.StatusErrorCheck(err, http.StatusConflict))
+
+	inst, err := instance.LoadByProjectAndName(suite.d.State(), "default", "c1")
+	suite.Req.Nil(err)
+	suite.Req.Equal(deviceConfig.Device{"type": "none"}, inst.LocalDevices()["data"])
+}
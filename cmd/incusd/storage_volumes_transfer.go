@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+var storagePoolVolumeTypeTransferCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/volumes/{type}/{volumeName}/transfer",
+
+	Post: APIEndpointAction{Handler: storagePoolVolumeTypeTransferPost, AccessHandler: allowPermission(auth.ObjectTypeStorageVolume, auth.EntitlementCanManageBackups, "poolName", "type", "volumeName")},
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/volumes/{type}/{volumeName}/transfer storage storage_pool_volume_type_transfer_post
+//
+//	Transfer the storage volume
+//
+//	Sends the storage volume to an external target, such as a ZFS dataset on a remote host.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: server01
+//	  - in: body
+//	    name: transfer
+//	    description: Transfer request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/StorageVolumeTransferPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolVolumeTypeTransferPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// Get the name of the storage volume.
+	volumeName, err := url.PathUnescape(mux.Vars(r)["volumeName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get the name of the storage pool the volume is supposed to be attached to.
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get the volume type.
+	volumeTypeName, err := url.PathUnescape(mux.Vars(r)["type"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Convert the volume type name to our internal integer representation.
+	volumeType, err := storagePools.VolumeTypeNameToDBType(volumeTypeName)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Check that the storage volume type is valid.
+	if volumeType != db.StoragePoolVolumeTypeCustom {
+		return response.BadRequest(fmt.Errorf("Invalid storage volume type %q", volumeTypeName))
+	}
+
+	projectName, err := project.StorageVolumeProject(s.DB.Cluster, request.ProjectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	resp = forwardedResponseIfVolumeIsRemote(s, r, poolName, projectName, volumeName, db.StoragePoolVolumeTypeCustom)
+	if resp != nil {
+		return resp
+	}
+
+	req := api.StorageVolumeTransferPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Transfers run as root on the host, only server administrators can choose where they go.
+	if req.Target != "" {
+		err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectServer(), auth.EntitlementCanEdit)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	transfer := func(op *operations.Operation) error {
+		return pool.TransferCustomVolume(projectName, volumeName, req.Target, op)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_volumes"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "volumes", volumeTypeName, volumeName)}
+
+	op, err := operations.OperationCreate(s, request.ProjectParam(r), operations.OperationClassTask, operationtype.VolumeTransfer, resources, nil, transfer, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// storagePoolVolumeTransferConfigCheck checks that only server administrators change the transfer configuration of
+// a volume, the transfers using the SSH keys of the host.
+func storagePoolVolumeTransferConfigCheck(s *state.State, r *http.Request, oldConfig map[string]string, newConfig map[string]string) error {
+	for _, key := range []string{"zfs.transfer.target", "zfs.transfer.schedule"} {
+		if oldConfig[key] == newConfig[key] {
+			continue
+		}
+
+		err := s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectServer(), auth.EntitlementCanEdit)
+		if err != nil {
+			return fmt.Errorf("Only server administrators can set %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func autoTransferCustomVolumesTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		var volumes []db.StorageVolumeArgs
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, db.StoragePoolVolumeTypeCustom, true)
+			if err != nil {
+				return fmt.Errorf("Failed getting volumes for auto custom volume transfer task: %w", err)
+			}
+
+			for _, v := range allVolumes {
+				// Transfers are only supported by local storage pools.
+				if v.NodeID < 0 {
+					continue
+				}
+
+				schedule := v.Config["zfs.transfer.schedule"]
+				if schedule == "" || v.Config["zfs.transfer.target"] == "" {
+					continue
+				}
+
+				// Check if the transfer is scheduled.
+				if !snapshotIsScheduledNow(schedule, v.ID) {
+					continue
+				}
+
+				volumes = append(volumes, v)
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed getting custom volume info", logger.Ctx{"err": err})
+			return
+		}
+
+		if len(volumes) == 0 {
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			return autoTransferCustomVolumes(ctx, s, volumes, op)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.VolumeTransfer, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating scheduled volume transfer operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Transferring scheduled volumes")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting scheduled volume transfer operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed scheduled custom volume transfers", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done transferring scheduled volumes")
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+func autoTransferCustomVolumes(ctx context.Context, s *state.State, volumes []db.StorageVolumeArgs, op *operations.Operation) error {
+	// Transfer the volumes sequentially.
+	for _, v := range volumes {
+		err := ctx.Err()
+		if err != nil {
+			return err // Stop if context is cancelled.
+		}
+
+		pool, err := storagePools.LoadByName(s, v.PoolName)
+		if err != nil {
+			return fmt.Errorf("Error loading pool for volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+		}
+
+		err = pool.TransferCustomVolume(v.ProjectName, v.Name, "", op)
+		if err != nil {
+			return fmt.Errorf("Error transferring volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+		}
+	}
+
+	return nil
+}
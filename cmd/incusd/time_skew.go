@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	clusterConfig "github.com/lxc/incus/v6/internal/server/cluster/config"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/task"
+	"github.com/lxc/incus/v6/internal/server/warnings"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// timeSkewRemediation tells how to fix a clock skew.
+const timeSkewRemediation = "check that an NTP client, like chrony or systemd-timesyncd, keeps the clock of all cluster members in sync"
+
+// timeSkewUpdate records the clock skew of the local member with the rest of the cluster, raising or resolving the
+// cluster time skew warning as needed.
+func (d *Daemon) timeSkewUpdate(s *state.State, skew time.Duration, details string) {
+	skewThreshold := 5 * time.Second
+	if s.GlobalConfig != nil {
+		skewThreshold = s.GlobalConfig.TimeSkewThreshold()
+	}
+
+	d.timeSkewMeasured.Store(time.Now().UnixNano())
+
+	if skew.Abs() > skewThreshold {
+		if d.timeSkew.Swap(int64(skew)) == 0 {
+			logger.Warn("Time skew detected between cluster and local", logger.Ctx{"details": details, "skew": skew})
+
+			if d.db.Cluster != nil {
+				err := d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+					return tx.UpsertWarningLocalNode(ctx, "", -1, -1, warningtype.ClusterTimeSkew, fmt.Sprintf("%s, skew: %s, %s", details, skew.Round(time.Millisecond), timeSkewRemediation))
+				})
+				if err != nil {
+					logger.Warn("Failed to create cluster time skew warning", logger.Ctx{"err": err})
+				}
+			}
+		}
+
+		return
+	}
+
+	if d.timeSkew.Swap(0) != 0 {
+		logger.Warn("Time skew resolved")
+		d.timeSkewResolve()
+	}
+}
+
+// timeSkewResolve resolves the cluster time skew warning of the local member.
+func (d *Daemon) timeSkewResolve() {
+	if d.db.Cluster == nil {
+		return
+	}
+
+	err := warnings.ResolveWarningsByLocalNodeAndType(d.db.Cluster, warningtype.ClusterTimeSkew)
+	if err != nil {
+		logger.Warn("Failed to resolve cluster time skew warning", logger.Ctx{"err": err})
+	}
+}
+
+// timeSkewCurrent returns the clock skew of the local member, ignoring measurements too old to be trusted.
+func (d *Daemon) timeSkewCurrent(config *clusterConfig.Config) time.Duration {
+	maxAge := time.Duration(db.DefaultOfflineThreshold) * time.Second
+	if config != nil {
+		maxAge = config.OfflineThreshold()
+	}
+
+	return timeSkewValid(time.Duration(d.timeSkew.Load()), time.Unix(0, d.timeSkewMeasured.Load()), time.Now(), maxAge)
+}
+
+// timeSkewValid returns the clock skew measured at the given time, or zero if the measurement is older than maxAge.
+// Heartbeats are sent twice per offline threshold, so missing measurements for longer means they stopped.
+func timeSkewValid(skew time.Duration, measured time.Time, now time.Time, maxAge time.Duration) time.Duration {
+	if skew == 0 || now.Sub(measured) > maxAge {
+		return 0
+	}
+
+	return skew
+}
+
+// timeSkewExpireTask resolves the cluster time skew warning once the clock skew is no longer measured, for example
+// because heartbeats stopped.
+func timeSkewExpireTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		skew := d.timeSkew.Load()
+		if skew == 0 || d.State().TimeSkew != 0 {
+			return
+		}
+
+		if !d.timeSkew.CompareAndSwap(skew, 0) {
+			return
+		}
+
+		logger.Warn("Time skew no longer measured, resolving it")
+		d.timeSkewResolve()
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// timeSkewCheck returns an error when the clock of the local member is too skewed from the rest of the cluster to
+// run time-sensitive operations, like validating or issuing tokens.
+func timeSkewCheck(s *state.State) error {
+	if s.TimeSkew == 0 {
+		return nil
+	}
+
+	return api.StatusErrorf(http.StatusServiceUnavailable, "The clock of this member is off by %s from the cluster, retry on another member and %s", s.TimeSkew.Round(time.Second), timeSkewRemediation)
+}
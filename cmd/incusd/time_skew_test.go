@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestTimeSkewValid(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		skew     time.Duration
+		measured time.Time
+		result   time.Duration
+	}{
+		{"No skew", 0, now, 0},
+		{"Recent measurement", 10 * time.Second, now.Add(-5 * time.Second), 10 * time.Second},
+		{"Negative skew", -10 * time.Second, now.Add(-5 * time.Second), -10 * time.Second},
+		{"Stale measurement", 10 * time.Second, now.Add(-time.Minute), 0},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		require.Equal(t, tt.result, timeSkewValid(tt.skew, tt.measured, now, 20*time.Second))
+	}
+}
+
+func TestTimeSkewCheck(t *testing.T) {
+	require.NoError(t, timeSkewCheck(&state.State{}))
+
+	err := timeSkewCheck(&state.State{TimeSkew: 12 * time.Second})
+	require.Error(t, err)
+	require.True(t, api.StatusErrorCheck(err, http.StatusServiceUnavailable))
+	require.Contains(t, err.Error(), "off by 12s")
+}
@@ -69,6 +69,7 @@ func autoRemoveExpiredTokens(ctx context.Context, s *state.State) {
 func autoRemoveExpiredTokensTask(d *Daemon) (task.Func, task.Schedule) {
 	f := func(ctx context.Context) {
 		autoRemoveExpiredTokens(ctx, d.State())
+		instanceSharesPruneExpired(ctx, d)
 	}
 
 	return f, task.Every(time.Minute)
@@ -18,11 +18,14 @@ import (
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/db/operationtype"
 	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/network"
 	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
 	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
 	"github.com/lxc/incus/v6/internal/server/task"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
@@ -44,6 +47,12 @@ var warningCmd = APIEndpoint{
 	Delete: APIEndpointAction{Handler: warningDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
 }
 
+var warningResolveCmd = APIEndpoint{
+	Path: "warnings/{id}/resolve",
+
+	Post: APIEndpointAction{Handler: warningResolvePost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+}
+
 func filterWarnings(warnings []api.Warning, clauses *filter.ClauseSet) ([]api.Warning, error) {
 	filtered := []api.Warning{}
 
@@ -435,6 +444,299 @@ func warningDelete(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
+// swagger:operation POST /1.0/warnings/{uuid}/resolve warnings warning_resolve_post
+//
+//	Resolve the warning
+//
+//	Applies the known-safe fix of the warning and marks it as resolved.
+//	The request is handled by the cluster member the warning occurred on.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func warningResolvePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var dbWarning *cluster.Warning
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbWarning, err = cluster.GetWarning(ctx, tx.Tx(), id)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Fixes are applied by the member the warning occurred on.
+	if dbWarning.Node != "" {
+		resp := forwardedResponseToNode(s, r, dbWarning.Node)
+		if resp != nil {
+			return resp
+		}
+	}
+
+	if dbWarning.Status == warningtype.StatusResolved {
+		return response.EmptySyncResponse
+	}
+
+	remediation, ok := dbWarning.TypeCode.Remediation()
+	if !ok || remediation.Action == "" {
+		return response.BadRequest(fmt.Errorf("Warning %q can't be resolved automatically", id))
+	}
+
+	err = warningApplyRemediation(s, *dbWarning, remediation.Action)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed applying %q: %w", remediation.Action, err))
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateWarningStatus(id, warningtype.StatusResolved)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.WarningResolved.Event(id, request.CreateRequestor(r), map[string]any{"action": remediation.Action}))
+
+	return response.EmptySyncResponse
+}
+
+// warningApplyRemediation applies a known-safe fix to the entity affected by a local warning.
+func warningApplyRemediation(s *state.State, w cluster.Warning, action warningtype.Action) error {
+	switch action {
+	case warningtype.ActionStartInstance:
+		if w.EntityTypeCode != cluster.TypeInstance {
+			return fmt.Errorf("Warning isn't associated with an instance")
+		}
+
+		inst, err := instance.LoadByID(s, w.EntityID)
+		if err != nil {
+			return err
+		}
+
+		if inst.IsRunning() {
+			return nil
+		}
+
+		// Don't try starting the instance while its storage pool is unavailable.
+		poolName, err := inst.StoragePool()
+		if err != nil {
+			return err
+		}
+
+		if !storagePools.IsAvailable(poolName) {
+			return api.StatusErrorf(http.StatusServiceUnavailable, "Storage pool %q of the instance is unavailable", poolName)
+		}
+
+		return inst.Start(false)
+
+	case warningtype.ActionStartNetwork:
+		if w.EntityTypeCode != cluster.TypeNetwork {
+			return fmt.Errorf("Warning isn't associated with a network")
+		}
+
+		var networkName, projectName string
+		err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+			networkName, projectName, err = tx.GetNetworkNameAndProjectWithID(ctx, w.EntityID)
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		n, err := network.LoadByName(s, projectName, networkName)
+		if err != nil {
+			return err
+		}
+
+		return n.Start()
+
+	case warningtype.ActionMountStoragePool:
+		if w.EntityTypeCode != cluster.TypeStoragePool {
+			return fmt.Errorf("Warning isn't associated with a storage pool")
+		}
+
+		var poolName string
+		err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+			_, pool, _, err := tx.GetStoragePoolWithID(ctx, w.EntityID)
+			if err != nil {
+				return err
+			}
+
+			poolName = pool.Name
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			return err
+		}
+
+		_, err = pool.Mount()
+
+		return err
+	}
+
+	return fmt.Errorf("Unknown remediation action %q", action)
+}
+
+// warningConditionCleared returns whether the condition behind a local warning no longer applies.
+func warningConditionCleared(ctx context.Context, s *state.State, w cluster.Warning) (bool, error) {
+	switch w.TypeCode {
+	case warningtype.InstanceAutostartFailure, warningtype.InstanceStorageFailure:
+		if w.EntityTypeCode != cluster.TypeInstance {
+			return false, nil
+		}
+
+		inst, err := instance.LoadByID(s, w.EntityID)
+		if err != nil {
+			return false, err
+		}
+
+		return inst.IsRunning(), nil
+
+	case warningtype.NetworkUnvailable:
+		if w.EntityTypeCode != cluster.TypeNetwork {
+			return false, nil
+		}
+
+		var networkName, projectName string
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+			networkName, projectName, err = tx.GetNetworkNameAndProjectWithID(ctx, w.EntityID)
+
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+
+		n, err := network.LoadByName(s, projectName, networkName)
+		if err != nil {
+			return false, err
+		}
+
+		return n.LocalStatus() == api.NetworkStatusCreated, nil
+
+	case warningtype.StoragePoolUnvailable:
+		if w.EntityTypeCode != cluster.TypeStoragePool {
+			return false, nil
+		}
+
+		var poolName string
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			_, pool, _, err := tx.GetStoragePoolWithID(ctx, w.EntityID)
+			if err != nil {
+				return err
+			}
+
+			poolName = pool.Name
+
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+
+		return storagePools.IsAvailable(poolName), nil
+	}
+
+	return false, nil
+}
+
+// autoResolveWarningsTask periodically resolves the local warnings whose underlying condition has cleared.
+func autoResolveWarningsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := autoResolveWarnings(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed auto-resolving warnings", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(5 * time.Minute)
+}
+
+func autoResolveWarnings(ctx context.Context, s *state.State) error {
+	var localWarnings []cluster.Warning
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		localName, err := tx.GetLocalNodeName(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting local member name: %w", err)
+		}
+
+		filters := []cluster.WarningFilter{}
+		for _, typeCode := range []warningtype.Type{warningtype.InstanceAutostartFailure, warningtype.InstanceStorageFailure, warningtype.NetworkUnvailable, warningtype.StoragePoolUnvailable} {
+			typeCode := typeCode
+			filters = append(filters, cluster.WarningFilter{Node: &localName, TypeCode: &typeCode})
+		}
+
+		warnings, err := cluster.GetWarnings(ctx, tx.Tx(), filters...)
+		if err != nil {
+			return fmt.Errorf("Failed to get local warnings: %w", err)
+		}
+
+		for _, w := range warnings {
+			if w.Status != warningtype.StatusResolved {
+				localWarnings = append(localWarnings, w)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range localWarnings {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		cleared, err := warningConditionCleared(ctx, s, w)
+		if err != nil {
+			logger.Debug("Failed checking warning condition", logger.Ctx{"uuid": w.UUID, "err": err})
+			continue
+		}
+
+		if !cleared {
+			continue
+		}
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateWarningStatus(w.UUID, warningtype.StatusResolved)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to resolve warning %q: %w", w.UUID, err)
+		}
+
+		logger.Info("Resolved warning whose condition has cleared", logger.Ctx{"uuid": w.UUID, "type": warningtype.TypeNames[w.TypeCode]})
+
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.WarningResolved.Event(w.UUID, nil, nil))
+	}
+
+	return nil
+}
+
 func pruneResolvedWarningsTask(d *Daemon) (task.Func, task.Schedule) {
 	f := func(ctx context.Context) {
 		s := d.State()
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// warningsTestUpsert records a local warning and returns it.
+func (suite *containerTestSuite) warningsTestUpsert(projectName string, entityTypeCode int, entityID int, typeCode warningtype.Type) dbCluster.Warning {
+	var warning dbCluster.Warning
+
+	err := suite.d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.UpsertWarningLocalNode(ctx, projectName, entityTypeCode, entityID, typeCode, "Test warning")
+		if err != nil {
+			return err
+		}
+
+		dbWarnings, err := dbCluster.GetWarnings(ctx, tx.Tx(), dbCluster.WarningFilter{TypeCode: &typeCode, EntityID: &entityID})
+		if err != nil {
+			return err
+		}
+
+		warning = dbWarnings[0]
+
+		return nil
+	})
+	suite.Req.Nil(err)
+
+	return warning
+}
+
+// warningsTestStatus returns the status of a warning.
+func (suite *containerTestSuite) warningsTestStatus(uuid string) warningtype.Status {
+	var status warningtype.Status
+
+	err := suite.d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbWarning, err := dbCluster.GetWarning(ctx, tx.Tx(), uuid)
+		if err != nil {
+			return err
+		}
+
+		status = dbWarning.Status
+
+		return nil
+	})
+	suite.Req.Nil(err)
+
+	return status
+}
+
+func (suite *containerTestSuite) TestWarningRemediation() {
+	s := suite.d.State()
+
+	inst, op, _, err := instance.CreateInternal(s, db.InstanceArgs{Type: instancetype.Container, Name: "testWarningRemediation"}, true, true)
+	suite.Req.Nil(err)
+	op.Done(nil)
+	defer func() { _ = inst.Delete(true) }()
+
+	var poolID int64
+	err = suite.d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		poolID, err = tx.GetStoragePoolID(ctx, daemonTestSuiteDefaultStoragePool)
+		return err
+	})
+	suite.Req.Nil(err)
+
+	instWarning := suite.warningsTestUpsert(api.ProjectDefaultName, dbCluster.TypeInstance, inst.ID(), warningtype.InstanceAutostartFailure)
+	poolWarning := suite.warningsTestUpsert("", dbCluster.TypeStoragePool, int(poolID), warningtype.StoragePoolUnvailable)
+
+	// The remediation must match the entity of the warning.
+	err = warningApplyRemediation(s, instWarning, warningtype.ActionMountStoragePool)
+	suite.Req.Error(err)
+
+	err = warningApplyRemediation(s, poolWarning, warningtype.ActionStartNetwork)
+	suite.Req.Error(err)
+
+	err = warningApplyRemediation(s, poolWarning, warningtype.Action("unknown"))
+	suite.Req.Error(err)
+
+	// Instances aren't started while their storage pool is unavailable.
+	storagePools.SetUnavailable(daemonTestSuiteDefaultStoragePool)
+
+	err = warningApplyRemediation(s, instWarning, warningtype.ActionStartInstance)
+	suite.Req.True(api.StatusErrorCheck(err, http.StatusServiceUnavailable))
+
+	// Warnings are only auto-resolved once their condition has cleared.
+	err = autoResolveWarnings(context.TODO(), s)
+	suite.Req.Nil(err)
+	suite.Req.Equal(warningtype.StatusNew, suite.warningsTestStatus(instWarning.UUID))
+	suite.Req.Equal(warningtype.StatusNew, suite.warningsTestStatus(poolWarning.UUID))
+
+	// Mounting the storage pool makes it available again.
+	err = warningApplyRemediation(s, poolWarning, warningtype.ActionMountStoragePool)
+	suite.Req.Nil(err)
+	suite.Req.True(storagePools.IsAvailable(daemonTestSuiteDefaultStoragePool))
+
+	err = autoResolveWarnings(context.TODO(), s)
+	suite.Req.Nil(err)
+	suite.Req.Equal(warningtype.StatusNew, suite.warningsTestStatus(instWarning.UUID))
+	suite.Req.Equal(warningtype.StatusResolved, suite.warningsTestStatus(poolWarning.UUID))
+}
+
+func (suite *containerTestSuite) TestWarningResolvePost() {
+	s := suite.d.State()
+
+	inst, op, _, err := instance.CreateInternal(s, db.InstanceArgs{Type: instancetype.Container, Name: "testWarningResolve"}, true, true)
+	suite.Req.Nil(err)
+	op.Done(nil)
+	defer func() { _ = inst.Delete(true) }()
+
+	var poolID int64
+	err = suite.d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		poolID, err = tx.GetStoragePoolID(ctx, daemonTestSuiteDefaultStoragePool)
+		return err
+	})
+	suite.Req.Nil(err)
+
+	resolve := func(uuid string) int {
+		r := httptest.NewRequest(http.MethodPost, "/1.0/warnings/"+uuid+"/resolve", nil)
+		r = mux.SetURLVars(r, map[string]string{"id": uuid})
+
+		recorder := httptest.NewRecorder()
+		err := warningResolvePost(suite.d, r).Render(recorder)
+		suite.Req.Nil(err)
+
+		return recorder.Code
+	}
+
+	// Warnings with a known-safe fix get resolved.
+	storagePools.SetUnavailable(daemonTestSuiteDefaultStoragePool)
+	poolWarning := suite.warningsTestUpsert("", dbCluster.TypeStoragePool, int(poolID), warningtype.StoragePoolUnvailable)
+
+	suite.Req.Equal(http.StatusOK, resolve(poolWarning.UUID))
+	suite.Req.Equal(warningtype.StatusResolved, suite.warningsTestStatus(poolWarning.UUID))
+	suite.Req.True(storagePools.IsAvailable(daemonTestSuiteDefaultStoragePool))
+
+	// Resolving them again does nothing.
+	suite.Req.Equal(http.StatusOK, resolve(poolWarning.UUID))
+
+	// Warnings without a known-safe fix are left alone.
+	idleWarning := suite.warningsTestUpsert(api.ProjectDefaultName, dbCluster.TypeInstance, inst.ID(), warningtype.InstanceIdle)
+
+	suite.Req.Equal(http.StatusBadRequest, resolve(idleWarning.UUID))
+	suite.Req.Equal(warningtype.StatusNew, suite.warningsTestStatus(idleWarning.UUID))
+
+	// Unknown warnings aren't found.
+	suite.Req.Equal(http.StatusNotFound, resolve("00000000-0000-0000-0000-000000000000"))
+}
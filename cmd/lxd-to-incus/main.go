@@ -67,6 +67,11 @@ type cmdMigrate struct {
 	flagYes                bool
 	flagClusterMember      bool
 	flagIgnoreVersionCheck bool
+
+	flagRemote  string
+	flagToken   string
+	flagProject string
+	flagLive    bool
 }
 
 // Command generates the command definition.
@@ -77,6 +82,10 @@ func (c *cmdMigrate) Command() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&c.flagYes, "yes", false, "Migrate without prompting")
 	cmd.PersistentFlags().BoolVar(&c.flagClusterMember, "cluster-member", false, "Used internally for cluster migrations")
 	cmd.PersistentFlags().BoolVar(&c.flagIgnoreVersionCheck, "ignore-version-check", false, "Bypass source version check")
+	cmd.PersistentFlags().StringVar(&c.flagRemote, "remote", "", "Address of a remote LXD server to import from (defaults to the one in the token)")
+	cmd.PersistentFlags().StringVar(&c.flagToken, "token", "", "Trust token issued by the remote LXD server")
+	cmd.PersistentFlags().StringVar(&c.flagProject, "project", "", "Only import the given project from the remote LXD server")
+	cmd.PersistentFlags().BoolVar(&c.flagLive, "live", false, "Live migrate running instances from the remote LXD server")
 
 	return cmd
 }
@@ -106,6 +115,18 @@ func (c *cmdMigrate) Run(app *cobra.Command, args []string) error {
 		return fmt.Errorf("Failed to set permissions on log file: %w", err)
 	}
 
+	// Import from a remote LXD server over the API.
+	if c.flagRemote != "" || c.flagToken != "" {
+		if c.flagToken == "" {
+			return fmt.Errorf("A trust token is required to import from a remote server (--token)")
+		}
+
+		_, _ = logFile.WriteString("Running in remote import mode\n")
+
+		r := &remoteImport{cmd: c, logFile: logFile}
+		return r.run()
+	}
+
 	if c.flagClusterMember {
 		_, _ = logFile.WriteString("Running in cluster member mode\n")
 	}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v6/client"
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	localtls "github.com/lxc/incus/v6/shared/tls"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// remoteImport imports the content of a remote LXD server into the local Incus server over the API.
+type remoteImport struct {
+	cmd     *cmdMigrate
+	logFile *os.File
+
+	srcClient    incus.InstanceServer
+	targetClient incus.InstanceServer
+}
+
+func (r *remoteImport) log(format string, args ...any) {
+	_, _ = r.logFile.WriteString(fmt.Sprintf(format+"\n", args...))
+}
+
+func (r *remoteImport) fail(err error) error {
+	r.log("ERROR: %v", err)
+	return err
+}
+
+// remoteProjectFeatures are the features which must be the same on the source and target projects, as they
+// decide in which project the imported entities end up.
+var remoteProjectFeatures = []string{
+	"features.images",
+	"features.networks",
+	"features.networks.zones",
+	"features.profiles",
+	"features.storage.buckets",
+	"features.storage.volumes",
+}
+
+// remoteAddress returns the URL of the remote server, defaulting to the first address of the trust token.
+func remoteAddress(address string, token *api.CertificateAddToken) (string, error) {
+	if address == "" {
+		if len(token.Addresses) == 0 {
+			return "", fmt.Errorf("The trust token doesn't include any address, use --remote")
+		}
+
+		address = token.Addresses[0]
+	}
+
+	if !strings.Contains(address, "://") {
+		address = fmt.Sprintf("https://%s", address)
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("Invalid remote address %q: %w", address, err)
+	}
+
+	if u.Port() == "" {
+		u.Host = fmt.Sprintf("%s:8443", u.Host)
+	}
+
+	return u.String(), nil
+}
+
+// remoteProjects returns the source projects to import, checking that the certificate of the import can access them.
+func remoteProjects(projects []api.Project, cert *api.Certificate, only string) ([]api.Project, error) {
+	if cert.Type != api.CertificateTypeClient {
+		return nil, fmt.Errorf("The trust token must be for a client certificate, not %q", cert.Type)
+	}
+
+	if only != "" {
+		idx := slices.IndexFunc(projects, func(project api.Project) bool { return project.Name == only })
+		if idx < 0 {
+			return nil, fmt.Errorf("Project %q doesn't exist on the source server", only)
+		}
+
+		projects = projects[idx : idx+1]
+	}
+
+	// A restricted certificate only sees some of the projects, so only allow importing those explicitly.
+	if cert.Restricted {
+		if only == "" {
+			return nil, fmt.Errorf("The trust token is restricted to some projects, use --project to import one of them")
+		}
+
+		if !slices.Contains(cert.Projects, only) {
+			return nil, fmt.Errorf("The trust token doesn't grant access to project %q", only)
+		}
+	}
+
+	return projects, nil
+}
+
+// remoteProjectCheck checks that an existing target project has the same features as the source one.
+func remoteProjectCheck(src api.Project, target api.Project) error {
+	for _, key := range remoteProjectFeatures {
+		if util.IsTrue(src.Config[key]) != util.IsTrue(target.Config[key]) {
+			return fmt.Errorf("Project %q has a different %q on the source (%q) and target (%q)", src.Name, key, src.Config[key], target.Config[key])
+		}
+	}
+
+	return nil
+}
+
+// connectRemote connects to the remote LXD server, using the trust token to get a temporary certificate trusted.
+// It returns the fingerprint of that certificate.
+func (r *remoteImport) connectRemote() (string, error) {
+	token, err := localtls.CertificateTokenDecode(r.cmd.flagToken)
+	if err != nil {
+		return "", fmt.Errorf("Invalid trust token: %w", err)
+	}
+
+	address, err := remoteAddress(r.cmd.flagRemote, token)
+	if err != nil {
+		return "", err
+	}
+
+	// Only trust the server the token was issued by.
+	serverCert, err := localtls.GetRemoteCertificate(address, "lxd-to-incus")
+	if err != nil {
+		return "", fmt.Errorf("Failed to get the certificate of %q: %w", address, err)
+	}
+
+	if localtls.CertFingerprint(serverCert) != token.Fingerprint {
+		return "", fmt.Errorf("The certificate of %q doesn't match the trust token", address)
+	}
+
+	serverCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Raw})
+
+	clientCert, clientKey, err := localtls.GenerateMemCert(true, false)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate a client certificate: %w", err)
+	}
+
+	args := &incus.ConnectionArgs{
+		TLSServerCert: string(serverCertPEM),
+		TLSClientCert: string(clientCert),
+		TLSClientKey:  string(clientKey),
+		UserAgent:     "lxd-to-incus",
+		SkipGetServer: true,
+	}
+
+	client, err := incus.ConnectIncus(address, args)
+	if err != nil {
+		return "", err
+	}
+
+	err = client.CreateCertificate(api.CertificatesPost{TrustToken: r.cmd.flagToken})
+	if err != nil {
+		return "", fmt.Errorf("Failed to add the certificate with the trust token: %w", err)
+	}
+
+	args.SkipGetServer = false
+	r.srcClient, err = incus.ConnectIncus(address, args)
+	if err != nil {
+		return "", err
+	}
+
+	return localtls.CertFingerprintStr(string(clientCert))
+}
+
+// run performs the import.
+func (r *remoteImport) run() error {
+	fmt.Println("=> Connecting to source server")
+	fingerprint, err := r.connectRemote()
+	if err != nil {
+		return r.fail(fmt.Errorf("Failed to connect to the source: %w", err))
+	}
+
+	// Don't leave the temporary certificate behind.
+	defer func() {
+		err := r.srcClient.DeleteCertificate(fingerprint)
+		if err != nil {
+			r.log("WARN: Failed to remove the temporary certificate from the source: %v", err)
+		}
+	}()
+
+	srcServer, _, err := r.srcClient.GetServer()
+	if err != nil {
+		return r.fail(fmt.Errorf("Failed to get source server info: %w", err))
+	}
+
+	if srcServer.Environment.Server != "lxd" {
+		return r.fail(fmt.Errorf("The source server isn't an LXD server"))
+	}
+
+	fmt.Printf("==> Source version: %s\n", srcServer.Environment.ServerVersion)
+	r.log("Source server: LXD %s", srcServer.Environment.ServerVersion)
+
+	fmt.Println("=> Validating version compatibility")
+	srcVersion, err := version.Parse(srcServer.Environment.ServerVersion)
+	if err != nil {
+		return r.fail(fmt.Errorf("Couldn't parse source server version: %w", err))
+	}
+
+	if srcVersion.Compare(minLXDVersion) < 0 {
+		return r.fail(fmt.Errorf("LXD version is lower than minimal version %q", minLXDVersion))
+	}
+
+	if !r.cmd.flagIgnoreVersionCheck {
+		if srcVersion.Compare(maxLXDVersion) > 0 {
+			return r.fail(fmt.Errorf("LXD version is newer than maximum version %q", maxLXDVersion))
+		}
+	} else {
+		fmt.Println("==> WARNING: User asked to bypass version check")
+	}
+
+	fmt.Println("=> Connecting to the target server")
+	r.targetClient, err = incus.ConnectIncusUnix("", nil)
+	if err != nil {
+		return r.fail(fmt.Errorf("Failed to connect to the target: %w", err))
+	}
+
+	// Check everything which can be checked before changing anything on the target.
+	fmt.Println("=> Checking projects")
+	cert, _, err := r.srcClient.GetCertificate(fingerprint)
+	if err != nil {
+		return r.fail(fmt.Errorf("Failed to get the temporary certificate from the source: %w", err))
+	}
+
+	projects := []api.Project{{Name: api.ProjectDefaultName}}
+	if r.srcClient.HasExtension("projects") {
+		projects, err = r.srcClient.GetProjects()
+		if err != nil {
+			return r.fail(fmt.Errorf("Failed to list source projects: %w", err))
+		}
+	}
+
+	projects, err = remoteProjects(projects, cert, r.cmd.flagProject)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	for _, project := range projects {
+		targetProject, _, err := r.targetClient.GetProject(project.Name)
+		if err != nil {
+			if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return r.fail(fmt.Errorf("Failed to get target project %q: %w", project.Name, err))
+			}
+
+			continue
+		}
+
+		err = remoteProjectCheck(project, *targetProject)
+		if err != nil {
+			return r.fail(err)
+		}
+	}
+
+	// The instances are imported on the storage pools of the same name.
+	fmt.Println("=> Checking storage pools")
+	err = r.checkStoragePools()
+	if err != nil {
+		return r.fail(err)
+	}
+
+	// Undo the whole import on failure, so that it can be retried from scratch.
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	for _, project := range projects {
+		err = r.importProject(project, reverter)
+		if err != nil {
+			fmt.Println("=> Reverting the import")
+			return r.fail(err)
+		}
+	}
+
+	reverter.Success()
+
+	fmt.Println("=> Import complete")
+	r.log("Import complete")
+
+	return nil
+}
+
+// undo returns a revert function running the given one and logging its failure.
+func (r *remoteImport) undo(description string, f func() error) func() {
+	return func() {
+		err := f()
+		if err != nil {
+			r.log("WARN: Failed to revert %s: %v", description, err)
+		}
+	}
+}
+
+// waitOperation waits for the operation returned by f.
+func waitOperation(op incus.Operation, err error) error {
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
+
+// checkStoragePools ensures every storage pool of the source exists on the target.
+func (r *remoteImport) checkStoragePools() error {
+	srcPools, err := r.srcClient.GetStoragePoolNames()
+	if err != nil {
+		return fmt.Errorf("Couldn't list source storage pools: %w", err)
+	}
+
+	targetPools, err := r.targetClient.GetStoragePoolNames()
+	if err != nil {
+		return fmt.Errorf("Couldn't list target storage pools: %w", err)
+	}
+
+	missing := []string{}
+	for _, pool := range srcPools {
+		if !slices.Contains(targetPools, pool) {
+			missing = append(missing, pool)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("Storage pools missing on the target, create them first: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// importProject imports a project along with its networks, profiles, images and instances.
+func (r *remoteImport) importProject(project api.Project, reverter *revert.Reverter) error {
+	fmt.Printf("=> Importing project %q\n", project.Name)
+	r.log("Importing project %q", project.Name)
+
+	_, _, err := r.targetClient.GetProject(project.Name)
+	if err != nil {
+		err = r.targetClient.CreateProject(api.ProjectsPost{Name: project.Name, ProjectPut: project.ProjectPut})
+		if err != nil {
+			return fmt.Errorf("Failed to create project %q: %w", project.Name, err)
+		}
+
+		reverter.Add(r.undo(fmt.Sprintf("project %q", project.Name), func() error { return r.targetClient.DeleteProject(project.Name) }))
+
+		fmt.Printf("==> Created project %q\n", project.Name)
+	}
+
+	src := r.srcClient.UseProject(project.Name)
+	target := r.targetClient.UseProject(project.Name)
+
+	// Networks.
+	networks, err := src.GetNetworks()
+	if err != nil {
+		return fmt.Errorf("Failed to list networks: %w", err)
+	}
+
+	targetNetworks, err := target.GetNetworkNames()
+	if err != nil {
+		return fmt.Errorf("Failed to list target networks: %w", err)
+	}
+
+	for _, network := range networks {
+		if !network.Managed {
+			continue
+		}
+
+		if slices.Contains(targetNetworks, network.Name) {
+			fmt.Printf("==> Skipping existing network %q\n", network.Name)
+			continue
+		}
+
+		err = target.CreateNetwork(api.NetworksPost{Name: network.Name, Type: network.Type, NetworkPut: network.Writable()})
+		if err != nil {
+			return fmt.Errorf("Failed to create network %q: %w", network.Name, err)
+		}
+
+		networkName := network.Name
+		reverter.Add(r.undo(fmt.Sprintf("network %q", networkName), func() error { return target.DeleteNetwork(networkName) }))
+
+		fmt.Printf("==> Created network %q\n", network.Name)
+	}
+
+	// Profiles.
+	profiles, err := src.GetProfiles()
+	if err != nil {
+		return fmt.Errorf("Failed to list profiles: %w", err)
+	}
+
+	for _, profile := range profiles {
+		targetProfile, etag, err := target.GetProfile(profile.Name)
+		if err != nil {
+			err = target.CreateProfile(api.ProfilesPost{Name: profile.Name, ProfilePut: profile.Writable()})
+			if err != nil {
+				return fmt.Errorf("Failed to create profile %q: %w", profile.Name, err)
+			}
+
+			profileName := profile.Name
+			reverter.Add(r.undo(fmt.Sprintf("profile %q", profileName), func() error { return target.DeleteProfile(profileName) }))
+
+			fmt.Printf("==> Created profile %q\n", profile.Name)
+			continue
+		}
+
+		// Only fill in profiles which haven't been configured yet, like the default one.
+		if len(targetProfile.Config) > 0 || len(targetProfile.Devices) > 0 {
+			fmt.Printf("==> Skipping existing profile %q\n", profile.Name)
+			continue
+		}
+
+		put := profile.Writable()
+		put.Description = targetProfile.Description
+
+		err = target.UpdateProfile(profile.Name, put, etag)
+		if err != nil {
+			return fmt.Errorf("Failed to update profile %q: %w", profile.Name, err)
+		}
+
+		profileName := profile.Name
+		reverter.Add(r.undo(fmt.Sprintf("profile %q", profileName), func() error { return target.UpdateProfile(profileName, targetProfile.Writable(), "") }))
+
+		fmt.Printf("==> Updated profile %q\n", profile.Name)
+	}
+
+	// Images.
+	images, err := src.GetImages()
+	if err != nil {
+		return fmt.Errorf("Failed to list images: %w", err)
+	}
+
+	for _, image := range images {
+		_, _, err := target.GetImage(image.Fingerprint)
+		if err == nil {
+			fmt.Printf("==> Skipping existing image %q\n", image.Fingerprint[0:12])
+			continue
+		}
+
+		fmt.Printf("==> Copying image %q\n", image.Fingerprint[0:12])
+		op, err := target.CopyImage(src, image, &incus.ImageCopyArgs{
+			Aliases:    image.Aliases,
+			AutoUpdate: image.AutoUpdate,
+			Public:     image.Public,
+			Type:       image.Type,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to copy image %q: %w", image.Fingerprint, err)
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed to copy image %q: %w", image.Fingerprint, err)
+		}
+
+		fingerprint := image.Fingerprint
+		reverter.Add(r.undo(fmt.Sprintf("image %q", fingerprint[0:12]), func() error { return waitOperation(target.DeleteImage(fingerprint)) }))
+	}
+
+	// Instances.
+	instances, err := src.GetInstances(api.InstanceTypeAny)
+	if err != nil {
+		return fmt.Errorf("Failed to list instances: %w", err)
+	}
+
+	targetInstances, err := target.GetInstanceNames(api.InstanceTypeAny)
+	if err != nil {
+		return fmt.Errorf("Failed to list target instances: %w", err)
+	}
+
+	for _, inst := range instances {
+		if slices.Contains(targetInstances, inst.Name) {
+			fmt.Printf("==> Skipping existing instance %q\n", inst.Name)
+			continue
+		}
+
+		live := r.cmd.flagLive && inst.StatusCode == api.Running
+
+		fmt.Printf("==> Copying instance %q\n", inst.Name)
+		r.log("Copying instance %q (live: %v)", inst.Name, live)
+
+		op, err := target.CopyInstance(src, inst, &incus.InstanceCopyArgs{Live: live})
+		if err != nil {
+			return fmt.Errorf("Failed to copy instance %q: %w", inst.Name, err)
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed to copy instance %q: %w", inst.Name, err)
+		}
+
+		instName := inst.Name
+		reverter.Add(r.undo(fmt.Sprintf("instance %q", instName), func() error { return r.deleteInstance(target, instName) }))
+	}
+
+	return nil
+}
+
+// deleteInstance stops and deletes an imported instance.
+func (r *remoteImport) deleteInstance(target incus.InstanceServer, name string) error {
+	inst, _, err := target.GetInstance(name)
+	if err != nil {
+		return err
+	}
+
+	if inst.StatusCode != api.Stopped {
+		err = waitOperation(target.UpdateInstanceState(name, api.InstanceStatePut{Action: "stop", Force: true, Timeout: -1}, ""))
+		if err != nil {
+			return err
+		}
+	}
+
+	return waitOperation(target.DeleteInstance(name))
+}
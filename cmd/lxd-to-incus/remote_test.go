@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestRemoteAddress(t *testing.T) {
+	token := &api.CertificateAddToken{Addresses: []string{"10.0.0.1:8443", "10.0.0.2:8443"}}
+
+	tests := []struct {
+		name    string
+		address string
+		token   *api.CertificateAddToken
+		result  string
+		fails   bool
+	}{
+		{"From the token", "", token, "https://10.0.0.1:8443", false},
+		{"Without port", "lxd01", token, "https://lxd01:8443", false},
+		{"With port", "lxd01:9443", token, "https://lxd01:9443", false},
+		{"IPv6", "[2001:db8::1]", token, "https://[2001:db8::1]:8443", false},
+		{"With scheme", "https://lxd01", token, "https://lxd01:8443", false},
+		{"No address", "", &api.CertificateAddToken{}, "", true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		result, err := remoteAddress(tt.address, tt.token)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.result, result)
+	}
+}
+
+func TestRemoteProjects(t *testing.T) {
+	projects := []api.Project{{Name: "default"}, {Name: "foo"}}
+
+	tests := []struct {
+		name   string
+		cert   api.Certificate
+		only   string
+		result []string
+		fails  bool
+	}{
+		{"All projects", api.Certificate{CertificatePut: api.CertificatePut{Type: api.CertificateTypeClient}}, "", []string{"default", "foo"}, false},
+		{"Single project", api.Certificate{CertificatePut: api.CertificatePut{Type: api.CertificateTypeClient}}, "foo", []string{"foo"}, false},
+		{"Missing project", api.Certificate{CertificatePut: api.CertificatePut{Type: api.CertificateTypeClient}}, "bar", nil, true},
+		{"Restricted certificate", api.Certificate{CertificatePut: api.CertificatePut{Type: api.CertificateTypeClient, Restricted: true, Projects: []string{"foo"}}}, "", nil, true},
+		{"Restricted certificate with project", api.Certificate{CertificatePut: api.CertificatePut{Type: api.CertificateTypeClient, Restricted: true, Projects: []string{"foo"}}}, "foo", []string{"foo"}, false},
+		{"Restricted certificate without access", api.Certificate{CertificatePut: api.CertificatePut{Type: api.CertificateTypeClient, Restricted: true, Projects: []string{"foo"}}}, "default", nil, true},
+		{"Metrics certificate", api.Certificate{CertificatePut: api.CertificatePut{Type: api.CertificateTypeMetrics}}, "", nil, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		result, err := remoteProjects(projects, &tt.cert, tt.only)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+
+		names := []string{}
+		for _, project := range result {
+			names = append(names, project.Name)
+		}
+
+		require.Equal(t, tt.result, names)
+	}
+}
+
+func TestRemoteProjectCheck(t *testing.T) {
+	src := api.Project{Name: "foo", ProjectPut: api.ProjectPut{Config: map[string]string{"features.images": "true", "features.profiles": "true"}}}
+
+	// Same features.
+	target := api.Project{Name: "foo", ProjectPut: api.ProjectPut{Config: map[string]string{"features.images": "true", "features.profiles": "true", "limits.instances": "10"}}}
+	require.NoError(t, remoteProjectCheck(src, target))
+
+	// Different features.
+	target = api.Project{Name: "foo", ProjectPut: api.ProjectPut{Config: map[string]string{"features.images": "true"}}}
+	require.Error(t, remoteProjectCheck(src, target))
+}
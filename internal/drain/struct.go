@@ -0,0 +1,20 @@
+package drain
+
+import (
+	"time"
+)
+
+// Post is used to start draining the server.
+type Post struct {
+	Timeout    int `json:"timeout" yaml:"timeout"`         // Number of seconds to wait for the running operations, 0 to wait forever.
+	RetryAfter int `json:"retry_after" yaml:"retry_after"` // Number of seconds clients are told to wait before retrying rejected requests.
+}
+
+// Status represents the draining state of the server.
+type Status struct {
+	Draining   bool      `json:"draining" yaml:"draining"`     // Whether the server is draining.
+	Since      time.Time `json:"since" yaml:"since"`           // When the server started draining.
+	Operations []string  `json:"operations" yaml:"operations"` // Descriptions of the operations still running.
+	Tasks      int       `json:"tasks" yaml:"tasks"`           // Number of background tasks still running.
+	Safe       bool      `json:"safe" yaml:"safe"`             // Whether the daemon can safely be stopped.
+}
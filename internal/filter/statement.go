@@ -0,0 +1,667 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Statement is a parsed query statement of the form:
+//
+//	SELECT <field>[, <field>...] FROM <collection> [WHERE <condition>] [ORDER BY <field> [ASC|DESC]] [LIMIT <count>]
+//
+// Fields use the same names as the filter strings, map entries can also be written as `config['image.os']`.
+type Statement struct {
+	Fields     []string
+	From       string
+	Where      Condition
+	OrderBy    string
+	Descending bool
+	Limit      int
+}
+
+// Condition is a parsed WHERE condition.
+type Condition interface {
+	// Match returns whether the given object matches the condition.
+	Match(obj any) (bool, error)
+
+	// Fields returns the fields the condition refers to.
+	Fields() []string
+}
+
+// statementKeywords are the reserved words which can't be used as top-level field names.
+var statementKeywords = []string{"select", "from", "where", "and", "or", "not", "order", "by", "asc", "desc", "limit"}
+
+// DefaultStatementFields are the fields returned when selecting `*`.
+var DefaultStatementFields = []string{"name", "project", "location", "type", "status"}
+
+type logicalCondition struct {
+	and   bool
+	left  Condition
+	right Condition
+}
+
+func (c logicalCondition) Match(obj any) (bool, error) {
+	match, err := c.left.Match(obj)
+	if err != nil {
+		return false, err
+	}
+
+	// Short-circuit the evaluation.
+	if c.and != match {
+		return match, nil
+	}
+
+	return c.right.Match(obj)
+}
+
+func (c logicalCondition) Fields() []string {
+	return append(c.left.Fields(), c.right.Fields()...)
+}
+
+type notCondition struct {
+	cond Condition
+}
+
+func (c notCondition) Match(obj any) (bool, error) {
+	match, err := c.cond.Match(obj)
+	if err != nil {
+		return false, err
+	}
+
+	return !match, nil
+}
+
+func (c notCondition) Fields() []string {
+	return c.cond.Fields()
+}
+
+type comparison struct {
+	field    string
+	operator string
+	value    string
+}
+
+func (c comparison) Match(obj any) (bool, error) {
+	value := ValueOf(obj, c.field)
+	if value == nil {
+		// Missing values only differ from everything.
+		return c.operator == "!=", nil
+	}
+
+	result, err := compareValue(value, c.value)
+	if err != nil {
+		return false, fmt.Errorf("Invalid comparison on field %q: %w", c.field, err)
+	}
+
+	switch c.operator {
+	case "=":
+		return result == 0, nil
+	case "!=":
+		return result != 0, nil
+	}
+
+	_, isBool := value.(bool)
+	if isBool {
+		return false, fmt.Errorf("Operator %q isn't supported on boolean field %q", c.operator, c.field)
+	}
+
+	switch c.operator {
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	case ">=":
+		return result >= 0, nil
+	}
+
+	return false, fmt.Errorf("Unknown operator %q", c.operator)
+}
+
+func (c comparison) Fields() []string {
+	return []string{c.field}
+}
+
+// compareValue compares an object value with a literal, returning -1, 0 or 1.
+func compareValue(value any, literal string) (int, error) {
+	switch v := value.(type) {
+	case string:
+		// Equality is case insensitive, like in filter strings.
+		if strings.EqualFold(v, literal) {
+			return 0, nil
+		}
+
+		return strings.Compare(v, literal), nil
+	case bool:
+		b, err := strconv.ParseBool(literal)
+		if err != nil {
+			return 0, err
+		}
+
+		if v == b {
+			return 0, nil
+		}
+
+		return 1, nil
+	case time.Time:
+		t, err := time.Parse(time.RFC3339, literal)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, literal)
+			if err != nil {
+				return 0, fmt.Errorf("Invalid date %q", literal)
+			}
+		}
+
+		return v.Compare(t), nil
+	}
+
+	number, ok := toFloat(value)
+	if !ok {
+		return 0, fmt.Errorf("Unsupported value type %T", value)
+	}
+
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid number %q", literal)
+	}
+
+	switch {
+	case number < f:
+		return -1, nil
+	case number > f:
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// toFloat converts a numeric value to a float64.
+func toFloat(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
+
+// lessValue returns whether a sorts before b, missing values sort first.
+func lessValue(a any, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+
+	switch va := a.(type) {
+	case string:
+		vb, ok := b.(string)
+		return ok && va < vb
+	case bool:
+		vb, ok := b.(bool)
+		return ok && !va && vb
+	case time.Time:
+		vb, ok := b.(time.Time)
+		return ok && va.Before(vb)
+	}
+
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+
+	return okA && okB && fa < fb
+}
+
+// Match returns whether the given object matches the WHERE condition of the statement.
+func (s *Statement) Match(obj any) (bool, error) {
+	if s.Where == nil {
+		return true, nil
+	}
+
+	return s.Where.Match(obj)
+}
+
+// Select returns the values of the selected fields for the given object.
+func (s *Statement) Select(obj any) []any {
+	row := make([]any, 0, len(s.Fields))
+	for _, field := range s.Fields {
+		row = append(row, ValueOf(obj, field))
+	}
+
+	return row
+}
+
+// Uses returns whether the statement refers to the given field or to any of its children.
+func (s *Statement) Uses(field string) bool {
+	fields := append([]string{s.OrderBy}, s.Fields...)
+	if s.Where != nil {
+		fields = append(fields, s.Where.Fields()...)
+	}
+
+	return usesField(fields, field)
+}
+
+// Prefilter returns the part of the WHERE condition which doesn't refer to any of the given fields or their
+// children, which all the objects matching the statement also match.
+// It returns nil if no part of the condition can be evaluated without those fields.
+func (s *Statement) Prefilter(fields ...string) Condition {
+	var prefilter Condition
+
+	for _, cond := range conjuncts(s.Where) {
+		condFields := cond.Fields()
+		if slices.ContainsFunc(fields, func(field string) bool { return usesField(condFields, field) }) {
+			continue
+		}
+
+		if prefilter == nil {
+			prefilter = cond
+		} else {
+			prefilter = logicalCondition{and: true, left: prefilter, right: cond}
+		}
+	}
+
+	return prefilter
+}
+
+// conjuncts splits a condition into the conditions which must all match.
+func conjuncts(cond Condition) []Condition {
+	if cond == nil {
+		return nil
+	}
+
+	logical, ok := cond.(logicalCondition)
+	if !ok || !logical.and {
+		return []Condition{cond}
+	}
+
+	return append(conjuncts(logical.left), conjuncts(logical.right)...)
+}
+
+// usesField returns whether the field or any of its children is part of the fields.
+func usesField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field || strings.HasPrefix(f, field+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Execute runs the statement against the given objects, returning one row of values per matching object.
+func Execute[T any](s *Statement, objs []T) ([][]any, error) {
+	matches := make([]T, 0, len(objs))
+	for _, obj := range objs {
+		match, err := s.Match(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		if match {
+			matches = append(matches, obj)
+		}
+	}
+
+	if s.OrderBy != "" {
+		sort.SliceStable(matches, func(i, j int) bool {
+			a := ValueOf(matches[i], s.OrderBy)
+			b := ValueOf(matches[j], s.OrderBy)
+
+			if s.Descending {
+				return lessValue(b, a)
+			}
+
+			return lessValue(a, b)
+		})
+	}
+
+	if s.Limit > 0 && len(matches) > s.Limit {
+		matches = matches[:s.Limit]
+	}
+
+	rows := make([][]any, 0, len(matches))
+	for _, obj := range matches {
+		rows = append(rows, s.Select(obj))
+	}
+
+	return rows, nil
+}
+
+// token is a lexical token of a statement.
+type token struct {
+	value  string
+	quoted bool
+}
+
+// tokenize splits a statement into tokens.
+func tokenize(s string) ([]token, error) {
+	tokens := []token{}
+
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end < 0 {
+				return nil, fmt.Errorf("Unterminated quote")
+			}
+
+			tokens = append(tokens, token{value: s[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		case strings.ContainsRune("(),.[]*", c):
+			tokens = append(tokens, token{value: string(c)})
+			i++
+		case strings.ContainsRune("=!<>", c):
+			op := string(c)
+			if i+1 < len(s) && strings.ContainsRune("=>", rune(s[i+1])) {
+				op += string(s[i+1])
+			}
+
+			switch op {
+			case "=", "!=", "<>", "<", "<=", ">", ">=":
+			default:
+				return nil, fmt.Errorf("Unknown operator %q", op)
+			}
+
+			if op == "<>" {
+				tokens = append(tokens, token{value: "!="})
+			} else {
+				tokens = append(tokens, token{value: op})
+			}
+
+			i += len(op)
+		default:
+			start := i
+			for i < len(s) && (unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i])) || strings.ContainsRune("_-:/", rune(s[i]))) {
+				i++
+			}
+
+			// Allow decimal numbers.
+			if i < len(s) && s[i] == '.' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1])) && isNumber(s[start:i]) {
+				i++
+				for i < len(s) && unicode.IsDigit(rune(s[i])) {
+					i++
+				}
+			}
+
+			if i == start {
+				return nil, fmt.Errorf("Unexpected character %q", c)
+			}
+
+			tokens = append(tokens, token{value: s[start:i]})
+		}
+	}
+
+	return tokens, nil
+}
+
+// isNumber returns whether a string only contains digits.
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if !unicode.IsDigit(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statementParser is a recursive descent parser for statements.
+type statementParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *statementParser) peek() string {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].quoted {
+		return ""
+	}
+
+	return p.tokens[p.pos].value
+}
+
+func (p *statementParser) keyword(keyword string) bool {
+	if strings.EqualFold(p.peek(), keyword) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *statementParser) expect(keyword string) error {
+	if !p.keyword(keyword) {
+		return fmt.Errorf("Expected %q", keyword)
+	}
+
+	return nil
+}
+
+func (p *statementParser) field() (string, error) {
+	name := p.peek()
+	if name == "" || strings.ContainsAny(name, "(),.[]*=!<>") || slices.Contains(statementKeywords, strings.ToLower(name)) {
+		return "", fmt.Errorf("Expected a field name")
+	}
+
+	p.pos++
+	parts := []string{name}
+
+	for {
+		if p.keyword(".") {
+			part := p.peek()
+			if part == "" || strings.ContainsAny(part, "(),.[]*=!<>") {
+				return "", fmt.Errorf("Expected a field name after %q", strings.Join(parts, "."))
+			}
+
+			p.pos++
+			parts = append(parts, part)
+			continue
+		}
+
+		if p.keyword("[") {
+			if p.pos >= len(p.tokens) || !p.tokens[p.pos].quoted {
+				return "", fmt.Errorf("Expected a quoted key after %q", strings.Join(parts, "."))
+			}
+
+			parts = append(parts, p.tokens[p.pos].value)
+			p.pos++
+
+			err := p.expect("]")
+			if err != nil {
+				return "", err
+			}
+
+			continue
+		}
+
+		return strings.Join(parts, "."), nil
+	}
+}
+
+func (p *statementParser) or() (Condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicalCondition{and: false, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *statementParser) and() (Condition, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicalCondition{and: true, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *statementParser) unary() (Condition, error) {
+	if p.keyword("not") {
+		cond, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notCondition{cond: cond}, nil
+	}
+
+	if p.keyword("(") {
+		cond, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.expect(")")
+		if err != nil {
+			return nil, err
+		}
+
+		return cond, nil
+	}
+
+	field, err := p.field()
+	if err != nil {
+		return nil, err
+	}
+
+	operator := p.peek()
+	switch operator {
+	case "=", "!=", "<", "<=", ">", ">=":
+		p.pos++
+	default:
+		return nil, fmt.Errorf("Expected a comparison operator after %q", field)
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("Expected a value after %q", field+" "+operator)
+	}
+
+	value := p.tokens[p.pos]
+	if !value.quoted && strings.ContainsAny(value.value, "(),[]*=!<>") {
+		return nil, fmt.Errorf("Expected a value after %q", field+" "+operator)
+	}
+
+	p.pos++
+
+	return comparison{field: field, operator: operator, value: value.value}, nil
+}
+
+// ParseStatement parses a query statement.
+func ParseStatement(s string) (*Statement, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &statementParser{tokens: tokens}
+	stmt := &Statement{}
+
+	err = p.expect("select")
+	if err != nil {
+		return nil, err
+	}
+
+	if p.keyword("*") {
+		stmt.Fields = append(stmt.Fields, DefaultStatementFields...)
+	} else {
+		for {
+			field, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+
+			stmt.Fields = append(stmt.Fields, field)
+
+			if !p.keyword(",") {
+				break
+			}
+		}
+	}
+
+	err = p.expect("from")
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.From = strings.ToLower(p.peek())
+	if stmt.From == "" {
+		return nil, fmt.Errorf("Expected a collection name")
+	}
+
+	p.pos++
+
+	if p.keyword("where") {
+		stmt.Where, err = p.or()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.keyword("order") {
+		err = p.expect("by")
+		if err != nil {
+			return nil, err
+		}
+
+		stmt.OrderBy, err = p.field()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.keyword("desc") {
+			stmt.Descending = true
+		} else {
+			p.keyword("asc")
+		}
+	}
+
+	if p.keyword("limit") {
+		stmt.Limit, err = strconv.Atoi(p.peek())
+		if err != nil || stmt.Limit <= 0 {
+			return nil, fmt.Errorf("Expected a positive limit")
+		}
+
+		p.pos++
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Unexpected %q", p.tokens[p.pos].value)
+	}
+
+	return stmt, nil
+}
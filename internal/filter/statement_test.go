@@ -0,0 +1,172 @@
+package filter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/filter"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestParseStatement_Error(t *testing.T) {
+	cases := map[string]string{
+		"":                                       `Expected "select"`,
+		"select name":                            `Expected "from"`,
+		"select name from":                       "Expected a collection name",
+		"select name, from instances":            "Expected a field name",
+		"select name from instances where":       "Expected a field name",
+		"select name from instances where name":  `Expected a comparison operator after "name"`,
+		"select name from instances where name=": `Expected a value after "name ="`,
+		"select name from instances where config[image.os] = 'Ubuntu'": `Expected a quoted key after "config"`,
+		"select name from instances where name = 'c1":                  "Unterminated quote",
+		"select name from instances where (name = c1":                  `Expected ")"`,
+		"select name from instances where name == c1":                  `Unknown operator "=="`,
+		"select name from instances limit 0":                           "Expected a positive limit",
+		"select name from instances order name":                        `Expected "by"`,
+		"select name from instances foo":                               `Unexpected "foo"`,
+	}
+
+	for s, message := range cases {
+		t.Run(s, func(t *testing.T) {
+			stmt, err := filter.ParseStatement(s)
+			assert.Nil(t, stmt)
+			assert.EqualError(t, err, message)
+		})
+	}
+}
+
+func TestParseStatement(t *testing.T) {
+	stmt, err := filter.ParseStatement("SELECT name, state.memory FROM Instances WHERE config['image.os']='Ubuntu' and location='node3' ORDER BY state.memory.usage DESC LIMIT 5")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "state.memory"}, stmt.Fields)
+	assert.Equal(t, "instances", stmt.From)
+	assert.Equal(t, []string{"config.image.os", "location"}, stmt.Where.Fields())
+	assert.Equal(t, "state.memory.usage", stmt.OrderBy)
+	assert.True(t, stmt.Descending)
+	assert.Equal(t, 5, stmt.Limit)
+	assert.True(t, stmt.Uses("state"))
+	assert.False(t, stmt.Uses("snapshots"))
+
+	stmt, err = filter.ParseStatement("select * from instances")
+	require.NoError(t, err)
+	assert.Equal(t, filter.DefaultStatementFields, stmt.Fields)
+	assert.Nil(t, stmt.Where)
+}
+
+func TestExecute(t *testing.T) {
+	instances := []api.InstanceFull{
+		{
+			Instance: api.Instance{
+				Name:      "c1",
+				Location:  "node1",
+				Status:    "Running",
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				InstancePut: api.InstancePut{
+					Config: map[string]string{"image.os": "Ubuntu"},
+				},
+			},
+			State: &api.InstanceState{Memory: api.InstanceStateMemory{Usage: 2048}},
+		},
+		{
+			Instance: api.Instance{
+				Name:      "c2",
+				Location:  "node3",
+				Status:    "Running",
+				CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				InstancePut: api.InstancePut{
+					Config: map[string]string{"image.os": "ubuntu"},
+				},
+			},
+			State: &api.InstanceState{Memory: api.InstanceStateMemory{Usage: 1024}},
+		},
+		{
+			Instance: api.Instance{
+				Name:      "v1",
+				Location:  "node3",
+				Status:    "Stopped",
+				CreatedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+				InstancePut: api.InstancePut{
+					Config: map[string]string{"image.os": "Debian"},
+				},
+			},
+		},
+	}
+
+	cases := map[string][][]any{
+		"select name from instances where config['image.os']='Ubuntu' and location='node3'":        {{"c2"}},
+		"select name, state.memory.usage from instances order by state.memory.usage desc":          {{"c1", int64(2048)}, {"c2", int64(1024)}, {"v1", nil}},
+		"select name from instances where state.memory.usage > 1024 or not (status = running)":     {{"c1"}, {"v1"}},
+		"select name from instances where created_at >= 2025-01-01 and config.image.os != ubuntu":  {{"v1"}},
+		"select name from instances where location = node3 order by name desc limit 1":             {{"v1"}},
+		"select name, location from instances where state.memory.usage <= 1024 and status=Running": {{"c2", "node3"}},
+	}
+
+	for s, expected := range cases {
+		t.Run(s, func(t *testing.T) {
+			stmt, err := filter.ParseStatement(s)
+			require.NoError(t, err)
+
+			rows, err := filter.Execute(stmt, instances)
+			require.NoError(t, err)
+			assert.Equal(t, expected, rows)
+		})
+	}
+
+	stmt, err := filter.ParseStatement("select name from instances where state.memory.usage > big")
+	require.NoError(t, err)
+
+	_, err = filter.Execute(stmt, instances)
+	assert.EqualError(t, err, `Invalid comparison on field "state.memory.usage": Invalid number "big"`)
+}
+
+func TestStatementPrefilter(t *testing.T) {
+	instances := []api.Instance{
+		{Name: "c1", Location: "node1", Status: "Running"},
+		{Name: "c2", Location: "node3", Status: "Running"},
+		{Name: "v1", Location: "node3", Status: "Stopped"},
+	}
+
+	cases := map[string][]string{
+		"select name from instances where location = node3 and state.memory.usage > 1024":                    {"c2", "v1"},
+		"select name from instances where state.memory.usage > 1024 and location = node3 and status=Running": {"c2"},
+		"select name from instances where location = node3 and (state.pid > 0 and name != v1)":               {"c2"},
+		"select name from instances where location = node3 and not state.pid > 0":                            {"c2", "v1"},
+	}
+
+	for s, expected := range cases {
+		t.Run(s, func(t *testing.T) {
+			stmt, err := filter.ParseStatement(s)
+			require.NoError(t, err)
+
+			prefilter := stmt.Prefilter("state")
+			require.NotNil(t, prefilter)
+			assert.NotContains(t, prefilter.Fields(), "state.memory.usage")
+
+			names := []string{}
+			for _, inst := range instances {
+				match, err := prefilter.Match(inst)
+				require.NoError(t, err)
+
+				if match {
+					names = append(names, inst.Name)
+				}
+			}
+
+			assert.Equal(t, expected, names)
+		})
+	}
+
+	// Conditions which can't be split don't have a prefilter.
+	for _, s := range []string{
+		"select name from instances",
+		"select name from instances where state.memory.usage > 1024",
+		"select name from instances where location = node3 or state.memory.usage > 1024",
+	} {
+		stmt, err := filter.ParseStatement(s)
+		require.NoError(t, err)
+		assert.Nil(t, stmt.Prefilter("state"), s)
+	}
+}
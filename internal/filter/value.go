@@ -8,6 +8,19 @@ import (
 // ValueOf returns the value of the given field.
 func ValueOf(obj any, field string) any {
 	value := reflect.ValueOf(obj)
+	if !value.IsValid() {
+		return nil
+	}
+
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+		obj = value.Interface()
+	}
+
 	typ := value.Type()
 	parts := strings.Split(field, ".")
 
@@ -30,10 +43,30 @@ func ValueOf(obj any, field string) any {
 				m := value.MapIndex(entry)
 				return ValueOf(m.Interface(), rest)
 			}
+
+		default:
+			if value.Type().Key().Kind() != reflect.String {
+				return nil
+			}
+
+			m := value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key()))
+			if !m.IsValid() {
+				return nil
+			}
+
+			if len(parts) == 1 {
+				return m.Interface()
+			}
+
+			return ValueOf(m.Interface(), rest)
 		}
 		return nil
 	}
 
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
 	for i := 0; i < value.NumField(); i++ {
 		fieldValue := value.Field(i)
 		fieldType := typ.Field(i)
@@ -28,6 +28,34 @@ var HugePageSizeSuffix = [...]string{"64KB", "1MB", "2MB", "1GB"}
 
 // InstanceConfigKeysAny is a map of config key to validator. (keys applying to containers AND virtual machines).
 var InstanceConfigKeysAny = map[string]func(value string) error{
+	// gendoc:generate(entity=instance, group=activation, key=activation.listen)
+	// Comma-separated list of host addresses and ports (`<address>:<port>`) the server listens on for the instance.
+	// In restricted projects, this requires {config:option}`project-restricted:restricted.devices.proxy` to be set to `allow`.
+	// The first connection starts the instance if it's stopped, and all connections are forwarded to {config:option}`instance-activation:activation.port`.
+	// See {ref}`instance-options-activation` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Addresses to listen on for socket activation
+	"activation.listen": validate.Optional(validate.IsListOf(validate.IsListenAddress(false, true, true))),
+
+	// gendoc:generate(entity=instance, group=activation, key=activation.port)
+	// The port is reached on a global address of the instance.
+	// ---
+	//  type: integer
+	//  liveupdate: yes
+	//  shortdesc: Port of the instance that connections are forwarded to
+	"activation.port": validate.Optional(validate.IsNetworkPort),
+
+	// gendoc:generate(entity=instance, group=activation, key=activation.idle_timeout)
+	// Number of seconds without any forwarded connection after which the instance is stopped.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0` (never stop)
+	//  liveupdate: yes
+	//  shortdesc: How long to wait before stopping an idle socket activated instance
+	"activation.idle_timeout": validate.Optional(validate.IsUint32),
+
 	// gendoc:generate(entity=instance, group=boot, key=boot.autostart)
 	// If set to `false`, restore the last state.
 	// ---
@@ -54,6 +82,58 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What order to start the instances in
 	"boot.autostart.priority": validate.Optional(validate.IsInt64),
 
+	// gendoc:generate(entity=instance, group=boot, key=boot.autorestart)
+	// If set to `on-failure`, the instance is automatically restarted when it stops without being asked to.
+	// A maximum number of consecutive restarts can be set with `on-failure:<max_retries>`.
+	//
+	// Consecutive restarts are delayed with an exponential backoff.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Whether to restart the instance when it stops unexpectedly
+	"boot.autorestart": validate.Optional(func(value string) error {
+		_, _, err := ParseAutoRestart(value)
+		return err
+	}),
+
+	// gendoc:generate(entity=instance, group=boot, key=boot.depends_on)
+	// Comma-separated list of instances of the same project that must be started before this instance
+	// when the daemon starts. See {ref}`instance-options-boot-depends-on`.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Instances to start before this instance
+	"boot.depends_on": validate.Optional(validate.IsListOf(validate.IsHostname)),
+
+	// gendoc:generate(entity=instance, group=boot, key=boot.depends_on.wait)
+	// Possible values are `started` (the dependencies are running), `ready` (the dependencies reported
+	// themselves as ready through the guest API) and `cloud-init` (`cloud-init` completed in the dependencies).
+	// ---
+	//  type: string
+	//  defaultdesc: `started`
+	//  liveupdate: yes
+	//  shortdesc: What to wait for before starting the instance after its dependencies
+	"boot.depends_on.wait": validate.Optional(validate.IsOneOf("started", "ready", "cloud-init")),
+
+	// gendoc:generate(entity=instance, group=boot, key=boot.depends_on.timeout)
+	// Number of seconds to wait for the dependencies before starting the instance anyway.
+	// ---
+	//  type: integer
+	//  defaultdesc: 300
+	//  liveupdate: yes
+	//  shortdesc: How long to wait for the dependencies of the instance
+	"boot.depends_on.timeout": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=boot, key=boot.depends_on.reverse_stop)
+	// If set to `true`, the dependencies of the instance are only shut down after the instance when the host
+	// is shut down, regardless of their `boot.stop.priority`.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Whether to stop the instance before its dependencies
+	"boot.depends_on.reverse_stop": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=boot, key=boot.stop.priority)
 	// The instance with the highest value is shut down first.
 	// ---
@@ -63,6 +143,16 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What order to shut down the instances in
 	"boot.stop.priority": validate.Optional(validate.IsInt64),
 
+	// gendoc:generate(entity=instance, group=boot, key=boot.stop.drain_period)
+	// Number of seconds during which the network load balancers steer new connections away from the instance
+	// before it is shut down, so that the established connections can finish.
+	// ---
+	//  type: integer
+	//  defaultdesc: 0
+	//  liveupdate: yes
+	//  shortdesc: How long to drain connections before shutting down the instance
+	"boot.stop.drain_period": validate.Optional(validate.IsUint32),
+
 	// gendoc:generate(entity=instance, group=boot, key=boot.host_shutdown_action)
 	// Action to take on host shut down
 	// ---
@@ -138,6 +228,184 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  condition: If supported by image
 	//  shortdesc: Legacy version of `cloud-init.vendor-data`
 
+	// gendoc:generate(entity=instance, group=copy, key=copy.refresh.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable scheduled refreshes.
+	//
+	// See {ref}`instance-options-copy` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: yes
+	//  shortdesc: Schedule for refreshing the instance from its source
+	"copy.refresh.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
+
+	// gendoc:generate(entity=instance, group=copy, key=copy.refresh.server)
+	// The server must trust the certificate of this server.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: URL of the server the instance is refreshed from
+	"copy.refresh.server": validate.Optional(validate.IsRequestURL),
+
+	// gendoc:generate(entity=instance, group=copy, key=copy.refresh.certificate)
+	// Leave empty to validate the certificate of the server against the system CAs.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: PEM certificate of the server the instance is refreshed from
+	"copy.refresh.certificate": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=copy, key=copy.refresh.project)
+	//
+	// ---
+	//  type: string
+	//  defaultdesc: `default`
+	//  liveupdate: yes
+	//  shortdesc: Project of the instance the instance is refreshed from
+	"copy.refresh.project": validate.Optional(validate.IsURLSegmentSafe),
+
+	// gendoc:generate(entity=instance, group=copy, key=copy.refresh.source)
+	//
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Name of the instance the instance is refreshed from
+	"copy.refresh.source": validate.Optional(validate.IsHostname),
+
+	// gendoc:generate(entity=instance, group=copy, key=copy.refresh.instance_only)
+	//
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Whether to refresh the instance without its snapshots
+	"copy.refresh.instance_only": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=health, key=health.check.type)
+	// Possible values are `command` (run `health.check.command` in the instance), `tcp` (connect to
+	// `health.check.port`) and `http` (request `health.check.path` on `health.check.port`).
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Type of health check
+	"health.check.type": validate.Optional(validate.IsOneOf("command", "tcp", "http")),
+
+	// gendoc:generate(entity=instance, group=health, key=health.check.command)
+	// The command is run through `sh -c` and the instance is healthy if it exits with status 0.
+	// On virtual machines, this requires the agent to be running.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Command to run for the health check
+	"health.check.command": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=health, key=health.check.port)
+	// The port is reached on a global address of the instance.
+	// ---
+	//  type: integer
+	//  liveupdate: yes
+	//  shortdesc: Port to probe for `tcp` and `http` health checks
+	"health.check.port": validate.Optional(validate.IsNetworkPort),
+
+	// gendoc:generate(entity=instance, group=health, key=health.check.path)
+	// Any response with a status code lower than 400 is considered healthy, redirects not being followed.
+	// ---
+	//  type: string
+	//  defaultdesc: `/`
+	//  liveupdate: yes
+	//  shortdesc: Path to request for `http` health checks
+	"health.check.path": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=health, key=health.check.interval)
+	// Number of seconds between two health checks.
+	// ---
+	//  type: integer
+	//  defaultdesc: 30
+	//  liveupdate: yes
+	//  shortdesc: How often to check the health of the instance
+	"health.check.interval": validate.Optional(validate.IsInRange(1, 86400)),
+
+	// gendoc:generate(entity=instance, group=health, key=health.check.timeout)
+	// Number of seconds after which a health check is considered failed.
+	// ---
+	//  type: integer
+	//  defaultdesc: 5
+	//  liveupdate: yes
+	//  shortdesc: How long to wait for a health check
+	"health.check.timeout": validate.Optional(validate.IsInRange(1, 3600)),
+
+	// gendoc:generate(entity=instance, group=health, key=health.check.failure_threshold)
+	// Number of consecutive failed health checks after which the instance is considered unhealthy.
+	// ---
+	//  type: integer
+	//  defaultdesc: 3
+	//  liveupdate: yes
+	//  shortdesc: Failed checks before the instance is unhealthy
+	"health.check.failure_threshold": validate.Optional(validate.IsInRange(1, 100)),
+
+	// gendoc:generate(entity=instance, group=health, key=health.check.restart)
+	// If set to `on-failure`, the instance is restarted when it becomes unhealthy.
+	// A maximum number of consecutive restarts can be set with `on-failure:<max_retries>`.
+	//
+	// Consecutive restarts are delayed with an exponential backoff.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Whether to restart the instance when it becomes unhealthy
+	"health.check.restart": validate.Optional(func(value string) error {
+		_, _, err := ParseAutoRestart(value)
+		return err
+	}),
+
+	// gendoc:generate(entity=instance, group=idle, key=idle.timeout)
+	// Number of minutes over which the activity of the instance must stay below its idle thresholds
+	// ({config:option}`instance-idle:idle.cpu_threshold`, {config:option}`instance-idle:idle.network_threshold`
+	// and {config:option}`instance-idle:idle.disk_threshold`) for {config:option}`instance-idle:idle.action` to be applied.
+	// See {ref}`instance-options-idle` for more information.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0` (disabled)
+	//  liveupdate: yes
+	//  shortdesc: How long an instance must be idle before being suspended
+	"idle.timeout": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=idle, key=idle.action)
+	// What to do with idle instances: `freeze` or `stop`.
+	// Ephemeral instances are always frozen, as stopping them deletes them.
+	// ---
+	//  type: string
+	//  defaultdesc: `freeze`
+	//  liveupdate: yes
+	//  shortdesc: What to do with idle instances
+	"idle.action": validate.Optional(validate.IsOneOf("freeze", "stop")),
+
+	// gendoc:generate(entity=instance, group=idle, key=idle.cpu_threshold)
+	// Average CPU usage (as a percentage of a single CPU) over {config:option}`instance-idle:idle.timeout` below which the instance is idle.
+	// ---
+	//  type: integer
+	//  defaultdesc: value of `instances.idle.cpu_threshold`
+	//  liveupdate: yes
+	//  shortdesc: CPU usage threshold of the idle policy
+	"idle.cpu_threshold": validate.Optional(validate.IsInRange(0, 100)),
+
+	// gendoc:generate(entity=instance, group=idle, key=idle.network_threshold)
+	// Amount of network traffic (received and sent) over {config:option}`instance-idle:idle.timeout` below which the instance is idle.
+	// ---
+	//  type: string
+	//  defaultdesc: value of `instances.idle.network_threshold`
+	//  liveupdate: yes
+	//  shortdesc: Network traffic threshold of the idle policy
+	"idle.network_threshold": validate.Optional(validate.IsSize),
+
+	// gendoc:generate(entity=instance, group=idle, key=idle.disk_threshold)
+	// Amount of disk I/O (read and written) over {config:option}`instance-idle:idle.timeout` below which the instance is idle.
+	// ---
+	//  type: string
+	//  defaultdesc: value of `instances.idle.disk_threshold`
+	//  liveupdate: yes
+	//  shortdesc: Disk I/O threshold of the idle policy
+	"idle.disk_threshold": validate.Optional(validate.IsSize),
+
 	// gendoc:generate(entity=instance, group=miscellaneous, key=cluster.evacuate)
 	// The `cluster.evacuate` provides control over how instances are handled when a cluster member is being
 	// evacuated.
@@ -166,6 +434,50 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What to do when evacuating the instance
 	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop", "stateful-stop", "force-stop")),
 
+	// gendoc:generate(entity=instance, group=miscellaneous, key=coredumps.enabled)
+	// For containers, this requires {config:option}`server-core:core.coredumps` to be enabled on the server.
+	// For virtual machines, a crash dump of the guest memory is captured when the guest kernel panics.
+	// Virtual machines must be restarted for the guest to be able to report panics.
+	// See {ref}`instances-coredumps` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Whether to capture the core dumps of the instance
+	"coredumps.enabled": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=mdns.services)
+	// Comma-separated list of services in the `_<service>._<tcp|udp>:<port>` format (for example, `_http._tcp:80`).
+	// They are advertised over mDNS by the bridge networks with `mdns.advertise` enabled (see {ref}`network-bridge-mdns`).
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Services to advertise over mDNS
+	"mdns.services": validate.Optional(validate.IsListOf(validate.IsDNSSDService)),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=oom.policy)
+	// What to do when a process of the instance is killed by the out-of-memory killer: `restart` or `stop` the
+	// instance, or `ignore` the kill.
+	// The kill is reported through a lifecycle event and a warning in all cases.
+	// For virtual machines, this requires the agent with {config:option}`instance-security:security.agent.metrics` enabled.
+	// See {ref}`instance-options-oom` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `ignore`
+	//  liveupdate: yes
+	//  shortdesc: What to do when a process of the instance is killed by the out-of-memory killer
+	"oom.policy": validate.Optional(validate.IsOneOf("restart", "stop", "ignore")),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=placement.group)
+	// Name of the instance group that the instance is part of in its project.
+	// The affinity or anti-affinity policy of the group is applied when placing the instance in a cluster.
+	// See {ref}`cluster-instance-groups` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Instance group used for cluster placement
+	"placement.group": validate.Optional(validate.IsHostname),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
 	//
@@ -177,17 +489,45 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Which CPUs to expose to the instance
 	"limits.cpu": validate.Optional(validate.IsValidCPUSet),
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.features)
+	// A comma-separated list of CPU flags to expose to the instance (prefixed with `+` or not prefixed)
+	// or to hide from it (prefixed with `-`), for example `+avx2,-rdrand`.
+	// The special `nested` feature exposes or hides the hardware virtualization extension (`vmx` or `svm`) to control nested virtualization.
+	// Exposed flags must be supported by the host CPU, as listed in the `flags` of the CPU resources of the server.
+	// Containers can only hide flags, which are then removed from their `/proc/cpuinfo`.
+	//
+	// See {ref}`instance-options-limits-cpu-model` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: CPU flags to expose to or hide from the instance
+	"limits.cpu.features": validate.Optional(validateCPUFeatures),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.nodes)
-	// A comma-separated list of NUMA node IDs or ranges to place the instance CPUs on.
+	// A comma-separated list of NUMA node IDs or ranges to place the instance CPUs and memory on.
 	// Alternatively, the value `balanced` may be used to have Incus pick the least busy NUMA node on startup.
 	//
-	// See {ref}`instance-options-limits-cpu-container` for more information.
+	// See {ref}`instance-options-limits-cpu-numa` for more information.
 	// ---
 	//  type: string
 	//  liveupdate: yes
-	//  shortdesc: Which NUMA nodes to place the instance CPUs on
+	//  shortdesc: Which NUMA nodes to place the instance CPUs and memory on
 	"limits.cpu.nodes": validate.Optional(validate.Or(validate.IsValidCPUSet, validate.IsOneOf("balanced"))),
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.power)
+	// Power policy of the instance CPUs, one of `performance`, `balanced` or `efficiency`.
+	// The policy sets utilization clamping hints used by the scheduler to select the CPU frequency and placement.
+	// For virtual machines with pinned vCPUs, `performance` also sets the `performance` frequency governor
+	// and disables the deep idle states of the host CPUs while `efficiency` sets the `powersave` governor.
+	//
+	// See {ref}`instance-options-limits-cpu-power` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `balanced`
+	//  liveupdate: yes
+	//  shortdesc: Power policy of the instance CPUs
+	"limits.cpu.power": validate.Optional(validate.IsOneOf("performance", "balanced", "efficiency")),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.disk.priority)
 	// Controls how much priority to give to the instance's I/O requests when under load.
 	//
@@ -239,6 +579,28 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 		return nil
 	},
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.pressure.action)
+	// What to do when the memory pressure of the instance stays above {config:option}`instance-resource-limits:limits.memory.pressure.threshold`.
+	// Possible values are `warn` to raise a warning, `notify` to also send an `instance-limit-reached` lifecycle event
+	// and `freeze` to also freeze the instance until the pressure falls back.
+	// See {ref}`instance-options-limits-memory-pressure` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `warn`
+	//  liveupdate: yes
+	//  shortdesc: Action on sustained memory pressure
+	"limits.memory.pressure.action": validate.Optional(validate.IsOneOf("warn", "notify", "freeze")),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.pressure.threshold)
+	// Specify the share of time (in %) some tasks of the instance may be stalled waiting on memory over the last minute
+	// before {config:option}`instance-resource-limits:limits.memory.pressure.action` is taken.
+	// See {ref}`instance-options-limits-memory-pressure` for more information.
+	// ---
+	//  type: integer
+	//  liveupdate: yes
+	//  shortdesc: Sustained memory pressure to act on
+	"limits.memory.pressure.threshold": validate.Optional(validate.IsInRange(1, 100)),
+
 	// gendoc:generate(entity=instance, group=migration, key=migration.stateful)
 	// Enabling this option prevents the use of some features that are incompatible with it.
 	// ---
@@ -276,6 +638,15 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Whether `/dev/incus` is present in the instance
 	"security.guestapi": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=security, key=security.guestapi.leases)
+	// Leases are shared by all instances of the project which have this option enabled.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Controls the availability of the `/1.0/leases` API over `guestapi`
+	"security.guestapi.leases": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=security, key=security.protection.delete)
 	//
 	// ---
@@ -351,6 +722,27 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: `instance-id` (UUID) exposed to `cloud-init`
 	"volatile.cloud-init.instance-id": validate.Optional(validate.IsUUID),
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.copy.refresh.last)
+	// The time of the last successful scheduled refresh of the instance from its source, in RFC 3339 format.
+	// ---
+	//  type: string
+	//  shortdesc: Time of the last scheduled refresh
+	"volatile.copy.refresh.last": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.copy.refresh.status)
+	// The status of the last scheduled refresh of the instance from its source: `running`, `success` or `failure`.
+	// ---
+	//  type: string
+	//  shortdesc: Status of the last scheduled refresh
+	"volatile.copy.refresh.status": validate.Optional(validate.IsOneOf("running", "success", "failure")),
+
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.copy.refresh.error)
+	// The error of the last scheduled refresh of the instance from its source, if it failed.
+	// ---
+	//  type: string
+	//  shortdesc: Error of the last scheduled refresh
+	"volatile.copy.refresh.error": validate.IsAny,
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.cluster.group)
 	// The cluster group(s) that the instance was restricted to at creation time.
 	// This is used during re-scheduling events like an evacuation to keep the instance within the requested set.
@@ -373,6 +765,27 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: The origin of the evacuated instance
 	"volatile.evacuate.origin": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.health)
+	// The result of the health checks of the instance (`starting`, `healthy` or `unhealthy`).
+	// ---
+	//  type: string
+	//  shortdesc: Instance health
+	"volatile.health": validate.Optional(validate.IsOneOf("starting", "healthy", "unhealthy")),
+
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.idle.since)
+	// The time since which the instance has been detected as idle (see `instances.idle.window`).
+	// ---
+	//  type: string
+	//  shortdesc: Time since which the instance is idle
+	"volatile.idle.since": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.idle.suspended)
+	// The {config:option}`instance-idle:idle.action` applied to the instance when it was last suspended for being idle.
+	// ---
+	//  type: string
+	//  shortdesc: How the idle instance was suspended
+	"volatile.idle.suspended": validate.Optional(validate.IsOneOf("freeze", "stop")),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.last_state.power)
 	//
 	// ---
@@ -387,6 +800,27 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Instance marked itself as ready
 	"volatile.last_state.ready": validate.IsBool,
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.memory.pressure.frozen)
+	// Set while the instance is frozen because of its {config:option}`instance-resource-limits:limits.memory.pressure.action`, so that it gets unfrozen once its memory pressure falls back, even across daemon restarts.
+	// ---
+	//  type: bool
+	//  shortdesc: Whether the instance is frozen because of its memory pressure
+	"volatile.memory.pressure.frozen": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.snapshot.parent)
+	// The snapshot that was most recently taken or restored. On a snapshot, this is the snapshot it derives from.
+	// ---
+	//  type: string
+	//  shortdesc: Parent snapshot
+	"volatile.snapshot.parent": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.storage.failure)
+	// The failure of the storage pool which caused the instance to be stopped. The instance is reported in the `Error` state until the storage pool recovers.
+	// ---
+	//  type: string
+	//  shortdesc: Storage pool failure affecting the instance
+	"volatile.storage.failure": validate.IsAny,
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.uuid)
 	// The instance UUID is globally unique across all servers and projects.
 	// ---
@@ -405,6 +839,47 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 
 // InstanceConfigKeysContainer is a map of config key to validator. (keys applying to containers only).
 var InstanceConfigKeysContainer = map[string]func(value string) error{
+	// gendoc:generate(entity=instance, group=security, key=idmap.passthrough.users)
+	// Comma-separated list of host users to map to the same UID and primary GID in the container.
+	// Host groups can be listed with an `@` prefix (for example, `@video`) to only map their GID.
+	// The users and groups are resolved on the host when the ID map is computed, so changes to their IDs are picked up
+	// when the container restarts. See {ref}`userns-idmap-passthrough` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: unprivileged container
+	//  shortdesc: Host users and groups to map into the container
+	"idmap.passthrough.users": validate.Optional(validate.IsListOf(func(value string) error {
+		name := strings.TrimPrefix(value, "@")
+		if name == "" || strings.ContainsAny(name, " :/") {
+			return fmt.Errorf("Invalid user or group name %q", value)
+		}
+
+		return nil
+	})),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=image.auto_rebase)
+	// When the image the instance was created from is automatically updated, Incus takes a snapshot of the instance,
+	// rebuilds it from the new image and then replays the changes made since the instance was created or last rebuilt.
+	// This must be enabled when creating or rebuilding the instance for these changes to be recorded.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: Whether to rebuild the instance when its image is updated
+	"image.auto_rebase": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=image.auto_rebase.paths)
+	// Specify a comma-separated list of absolute paths (for example, `/etc/myapp,/home`) to only replay the changes made
+	// within them when the instance is rebased onto a new image.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: Paths whose changes are kept when rebasing the instance
+	"image.auto_rebase.paths": validate.Optional(validate.IsListOf(validate.IsAbsFilePath)),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.allowance)
 	// To control how much of the CPU can be used, specify either a percentage (`50%`) for a soft limit
 	// or a chunk of time (`25ms/100ms`) for a hard limit.
@@ -545,8 +1020,20 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Prevents the instance from being swapped to disk
 	"limits.memory.swap.priority": validate.Optional(validate.IsPriority),
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.fds)
+	// This sets the `nofile` resource limit of the processes in the instance and takes precedence over `limits.kernel.nofile`.
+	// An `instance-limit-reached` lifecycle event is emitted when a process reaches the limit.
+	// ---
+	//  type: integer
+	//  defaultdesc: empty
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Maximum number of open file descriptors per process
+	"limits.fds": validate.Optional(validate.IsUint32),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.processes)
 	// If left empty, no limit is set.
+	// An `instance-limit-reached` lifecycle event is emitted when the limit prevents new processes from being created.
 	// ---
 	//  type: integer
 	//  defaultdesc: empty
@@ -564,6 +1051,107 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Kernel modules to load before starting the instance
 	"linux.kernel_modules": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=miscellaneous, key=linux.kernel_modules.load)
+	// Possible values are `boot` or `on-demand`.
+	// When set to `boot`, the modules listed in {config:option}`instance-miscellaneous:linux.kernel_modules` are loaded on the host before the container starts.
+	// When set to `on-demand`, the modules are only loaded when the container attempts to load them, through system call interception.
+	// See {ref}`instance-options-kernel-modules` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `boot`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: When to load the kernel modules
+	"linux.kernel_modules.load": validate.Optional(validate.IsOneOf("boot", "on-demand")),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=linux.mqueue.msg_max)
+	// This sets the `fs.mqueue.msg_max` `sysctl` of the container, the maximum number of messages in a POSIX message queue.
+	// ---
+	//  type: integer
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Maximum number of messages in a POSIX message queue
+	"linux.mqueue.msg_max": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=linux.mqueue.msgsize_max)
+	// This sets the `fs.mqueue.msgsize_max` `sysctl` of the container, the maximum size of a POSIX message queue message in bytes.
+	// ---
+	//  type: integer
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Maximum size of a POSIX message queue message
+	"linux.mqueue.msgsize_max": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=linux.mqueue.queues_max)
+	// This sets the `fs.mqueue.queues_max` `sysctl` of the container, the maximum number of POSIX message queues.
+	// The total memory used by the queues of a user is also limited by `limits.kernel.msgqueue`.
+	// ---
+	//  type: integer
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Maximum number of POSIX message queues
+	"linux.mqueue.queues_max": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=linux.shm.size)
+	// When set, a dedicated `tmpfs` of that size is mounted on `/dev/shm` in the container.
+	// The size can be specified either in bytes or with a unit suffix (for example, `2GiB`).
+	// ---
+	//  type: string
+	//  defaultdesc: `/dev/shm` managed by the container
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Size of the `/dev/shm` shared memory file system
+	"linux.shm.size": validate.Optional(validate.IsSize),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=logging.syslog)
+	// When enabled, a syslog socket is provided at `/dev/incus/syslog` inside the container.
+	// Messages sent to it are tagged with the instance and project and forwarded as `instance-log` events, including to Loki.
+	// This requires {config:option}`instance-security:security.guestapi` to be enabled.
+	// See {ref}`instances-syslog` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: Whether to forward the syslog messages of the instance
+	"logging.syslog": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=lxcfs.cpuview)
+	// Set this option to `false` to present the container with the `/proc/cpuinfo`, `/proc/stat` and
+	// `/sys/devices/system/cpu/online` files of the host instead of the views provided by LXCFS.
+	// See {ref}`server-lxcfs` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Whether to provide the container with the LXCFS CPU views
+	"lxcfs.cpuview": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=lxcfs.loadavg)
+	// Set this option to `false` to present the container with the `/proc/loadavg` file of the host instead of the
+	// view provided by LXCFS.
+	// See {ref}`server-lxcfs` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Whether to provide the container with the LXCFS load average
+	"lxcfs.loadavg": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=identity, key=machine_id.copy)
+	// What to do with the `/etc/machine-id` file of the container when it's copied or moved to another server.
+	// Possible values are `keep` to keep the identifier of the source container and `regenerate` to give the copy a new random identifier on its first start.
+	// See {ref}`instance-options-identity` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `keep`
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: How the machine ID is handled on copy
+	"machine_id.copy": validate.Optional(validate.IsOneOf("keep", "regenerate")),
+
 	// gendoc:generate(entity=instance, group=migration, key=migration.incremental.memory)
 	// Using incremental memory transfer of the instance's memory can reduce downtime.
 	// ---
@@ -643,6 +1231,17 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Raw LXC configuration to be appended to the generated one
 	"raw.lxc": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=raw, key=raw.apparmor.profiles)
+	// The specified AppArmor profiles are loaded in the AppArmor namespace of the container, for its processes to use.
+	// Loading them requires AppArmor stacking.
+	// See {ref}`instance-options-raw-apparmor-profiles` for more information.
+	// ---
+	//  type: blob
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: AppArmor profiles loaded for use inside the container
+	"raw.apparmor.profiles": validate.IsAny,
+
 	// gendoc:generate(entity=instance, group=raw, key=raw.seccomp)
 	//
 	// ---
@@ -652,6 +1251,17 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Raw Seccomp configuration
 	"raw.seccomp": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=security, key=security.criu)
+	// When {config:option}`server-miscellaneous:instances.shutdown_checkpoint` is enabled, the container is checkpointed
+	// to disk with CRIU when the daemon shuts down cleanly, and restored from that checkpoint when the daemon starts again.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: Whether to checkpoint the container on daemon shutdown
+	"security.criu": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=security, key=security.guestapi.images)
 	//
 	// ---
@@ -690,6 +1300,17 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: The size of the idmap to use
 	"security.idmap.size": validate.Optional(validate.IsUint32),
 
+	// gendoc:generate(entity=instance, group=security, key=security.keyring.session)
+	// When set to `false`, the container doesn't get its own kernel session keyring and shares the one of the server instead.
+	// This is only needed by workloads which don't cope with a separate session keyring, as it exposes the keys of the server to the container.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Whether to create a new kernel session keyring for the container
+	"security.keyring.session": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=security, key=security.nesting)
 	//
 	// ---
@@ -893,10 +1514,31 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  type: string
 	//  shortdesc: The idmap to use the next time the instance starts
 	"volatile.idmap.next": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.runtime.name)
+	// Set on a container renamed while running, until it stops. This key is managed by the server and can't be
+	// changed.
+	// ---
+	//  type: string
+	//  shortdesc: Previous name the container is still running under
+	"volatile.runtime.name": validate.IsAny,
 }
 
 // InstanceConfigKeysVM is a map of config key to validator. (keys applying to VM only).
 var InstanceConfigKeysVM = map[string]func(value string) error{
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.model)
+	// The QEMU CPU model to use, for example `host`, `max` or `EPYC-v4`.
+	// Using a named model instead of passing through the host CPU allows live migration between servers with different CPUs.
+	//
+	// See {ref}`instance-options-limits-cpu-model` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `host`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: CPU model of the instance
+	"limits.cpu.model": validate.Optional(validateCPUModel),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.hugepages)
 	// If this option is set to `false`, regular system memory is used.
 	// ---
@@ -907,6 +1549,17 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to back the instance using huge pages
 	"limits.memory.hugepages": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.hugepages.size)
+	// The size must be supported by the host, for example `2MiB` or `1GiB` on x86_64.
+	// See {ref}`instance-options-limits-hugepages-vm` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: page size of the `hugetlbfs` mount
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Size of the huge pages backing the instance
+	"limits.memory.hugepages.size": validate.Optional(validate.IsSize),
+
 	// Caller is responsible for full validation of any raw.* value.
 
 	// gendoc:generate(entity=instance, group=raw, key=raw.qemu)
@@ -997,6 +1650,35 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: The guest owner's `base64`-encoded session blob
 	"security.sev.session.data": validate.Optional(validate.IsAny),
 
+	// gendoc:generate(entity=instance, group=identity, key=smbios.asset_tag)
+	// The asset tag is exposed in the SMBIOS chassis information (type 3).
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: SMBIOS asset tag
+	"smbios.asset_tag": validate.Optional(isSMBIOSString),
+
+	// gendoc:generate(entity=instance, group=identity, key=smbios.serial)
+	// The serial number is exposed in the SMBIOS system information (type 1).
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: SMBIOS system serial number
+	"smbios.serial": validate.Optional(isSMBIOSString),
+
+	// gendoc:generate(entity=instance, group=identity, key=smbios.uuid)
+	// The UUID is exposed in the SMBIOS system information (type 1) and is usually read by the guest as its product UUID.
+	// Unlike {config:option}`instance-volatile:volatile.uuid`, it's kept when the instance is copied.
+	// ---
+	//  type: string
+	//  defaultdesc: Value of `volatile.uuid`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: SMBIOS system UUID
+	"smbios.uuid": validate.Optional(validate.IsUUID),
+
 	// gendoc:generate(entity=instance, group=miscellaneous, key=user.*)
 	// User keys can be used in search.
 	// ---
@@ -1030,6 +1712,21 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	"volatile.vsock_id": validate.Optional(validate.IsInt64),
 }
 
+// isSMBIOSString validates a string exposed to the instance through SMBIOS.
+func isSMBIOSString(value string) error {
+	if len(value) > 64 {
+		return fmt.Errorf("Value must be at most 64 characters long")
+	}
+
+	for _, r := range value {
+		if r < 0x20 || r > 0x7e {
+			return fmt.Errorf("Value must only contain printable ASCII characters")
+		}
+	}
+
+	return nil
+}
+
 // ConfigKeyChecker returns a function that will check whether or not
 // a provide value is valid for the associate config key.  Returns an
 // error if the key is not known.  The checker function only performs
@@ -1260,6 +1957,17 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		return validate.IsAny, nil
 	}
 
+	// gendoc:generate(entity=instance, group=miscellaneous, key=ssh.authorized_keys.<name>)
+	// Specify an SSH public key, in the `authorized_keys` format, to install for the `root` user of the instance.
+	// Incus keeps the keys from the instance (including its profiles) and its project in sync in a managed section of `/root/.ssh/authorized_keys`.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: SSH public key to install in the instance
+	if strings.HasPrefix(key, "ssh.authorized_keys.") {
+		return validate.IsSSHAuthorizedKey, nil
+	}
+
 	if strings.HasPrefix(key, "user.") {
 		return validate.IsAny, nil
 	}
@@ -1417,5 +2125,9 @@ func InstanceIncludeWhenCopying(configKey string, remoteCopy bool) bool {
 		return false // Exclude all other volatile keys.
 	}
 
+	if strings.HasPrefix(configKey, "copy.refresh.") {
+		return false // Exclude the scheduled refresh of a copy, which only applies to it.
+	}
+
 	return true // Keep all other keys.
 }
@@ -0,0 +1,49 @@
+package instance
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestIsSMBIOSString(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		fails bool
+	}{
+		{"Empty", "", false},
+		{"Serial number", "SN-0001 (rack 4)", false},
+		{"Printable ASCII", "!~ azAZ09", false},
+		{"Maximum length", strings.Repeat("a", 64), false},
+		{"Too long", strings.Repeat("a", 65), true},
+		{"Control character", "SN\t0001", true},
+		{"Newline", "SN0001\n", true},
+		{"Delete character", "SN0001\x7f", true},
+		{"Non-ASCII", "Numéro", true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		err := isSMBIOSString(tt.value)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+	}
+}
+
+func TestConfigKeyCheckerSMBIOS(t *testing.T) {
+	checker, err := ConfigKeyChecker("smbios.serial", api.InstanceTypeVM)
+	require.NoError(t, err)
+	require.NoError(t, checker(""))
+	require.NoError(t, checker("SN0001"))
+	require.Error(t, checker("SN\x000001"))
+}
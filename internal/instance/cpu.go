@@ -0,0 +1,70 @@
+package instance
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CPUFeature is a CPU flag to expose to or hide from a virtual machine.
+type CPUFeature struct {
+	Name    string
+	Enabled bool
+}
+
+// CPUFeatureNested is the pseudo CPU feature controlling the hardware virtualization extension (`vmx` or `svm`).
+const CPUFeatureNested = "nested"
+
+var cpuFeatureNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+var cpuModelRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ParseCPUFeatures parses a comma-separated list of CPU features.
+// Each feature is exposed if prefixed with `+` or not prefixed, and hidden if prefixed with `-`.
+func ParseCPUFeatures(value string) ([]CPUFeature, error) {
+	features := []CPUFeature{}
+	seen := map[string]bool{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		feature := CPUFeature{Name: entry, Enabled: true}
+		if strings.HasPrefix(entry, "+") {
+			feature.Name = entry[1:]
+		} else if strings.HasPrefix(entry, "-") {
+			feature.Name = entry[1:]
+			feature.Enabled = false
+		}
+
+		if !cpuFeatureNameRegex.MatchString(feature.Name) {
+			return nil, fmt.Errorf("Invalid CPU feature %q", entry)
+		}
+
+		if seen[feature.Name] {
+			return nil, fmt.Errorf("CPU feature %q specified multiple times", feature.Name)
+		}
+
+		seen[feature.Name] = true
+		features = append(features, feature)
+	}
+
+	return features, nil
+}
+
+// validateCPUFeatures validates a list of CPU features.
+func validateCPUFeatures(value string) error {
+	_, err := ParseCPUFeatures(value)
+	return err
+}
+
+// validateCPUModel validates the name of a QEMU CPU model.
+func validateCPUModel(value string) error {
+	if !cpuModelRegex.MatchString(value) {
+		return fmt.Errorf("Invalid CPU model %q", value)
+	}
+
+	return nil
+}
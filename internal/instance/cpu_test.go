@@ -0,0 +1,47 @@
+package instance
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPUFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		features []CPUFeature
+		fails    bool
+	}{
+		{"Empty", "", []CPUFeature{}, false},
+		{"Exposed and hidden", "+avx2, -rdrand,sse4.2", []CPUFeature{{Name: "avx2", Enabled: true}, {Name: "rdrand", Enabled: false}, {Name: "sse4.2", Enabled: true}}, false},
+		{"Nested", "-nested", []CPUFeature{{Name: CPUFeatureNested, Enabled: false}}, false},
+		{"Duplicate", "+avx2,-avx2", nil, true},
+		{"Invalid name", "+AVX2", nil, true},
+		{"Missing name", "+", nil, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		features, err := ParseCPUFeatures(tt.value)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.features, features)
+	}
+}
+
+func TestValidateCPUModel(t *testing.T) {
+	for _, model := range []string{"host", "max", "EPYC-v4", "Skylake-Server-v5", "qemu64"} {
+		require.NoError(t, validateCPUModel(model))
+	}
+
+	for _, model := range []string{"", "-host", "host,+avx2", "EPYC v4"} {
+		require.Error(t, validateCPUModel(model))
+	}
+}
@@ -0,0 +1,33 @@
+package instance
+
+// DependencyCycle returns the names of the instances forming a dependency cycle which goes through the named
+// instance, starting and ending with it, or nil if there is none.
+// The dependencies map each instance name to the names of the instances it depends on.
+func DependencyCycle(dependencies map[string][]string, name string) []string {
+	visited := map[string]bool{}
+
+	var walk func(path []string) []string
+	walk = func(path []string) []string {
+		for _, dep := range dependencies[path[len(path)-1]] {
+			if dep == name {
+				cycle := make([]string, 0, len(path)+1)
+				return append(append(cycle, path...), name)
+			}
+
+			if visited[dep] {
+				continue
+			}
+
+			visited[dep] = true
+
+			cycle := walk(append(path, dep))
+			if cycle != nil {
+				return cycle
+			}
+		}
+
+		return nil
+	}
+
+	return walk([]string{name})
+}
@@ -0,0 +1,31 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies map[string][]string
+		instance     string
+		cycle        []string
+	}{
+		{"No dependencies", map[string][]string{}, "c1", nil},
+		{"Chain", map[string][]string{"c1": {"c2"}, "c2": {"c3"}}, "c1", nil},
+		{"Missing dependency", map[string][]string{"c1": {"c2", "missing"}}, "c1", nil},
+		{"Diamond", map[string][]string{"c1": {"c2", "c3"}, "c2": {"c4"}, "c3": {"c4"}}, "c1", nil},
+		{"Cycle not through the instance", map[string][]string{"c1": {"c2"}, "c2": {"c3"}, "c3": {"c2"}}, "c1", nil},
+		{"Self", map[string][]string{"c1": {"c1"}}, "c1", []string{"c1", "c1"}},
+		{"Direct", map[string][]string{"c1": {"c2"}, "c2": {"c1"}}, "c1", []string{"c1", "c2", "c1"}},
+		{"Indirect", map[string][]string{"c1": {"c4", "c2"}, "c2": {"c3"}, "c3": {"c1"}}, "c1", []string{"c1", "c2", "c3", "c1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.cycle, DependencyCycle(tt.dependencies, tt.instance))
+		})
+	}
+}
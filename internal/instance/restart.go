@@ -0,0 +1,32 @@
+package instance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseAutoRestart parses a boot.autorestart value.
+// It returns whether the instance should be restarted on failure and the maximum number of consecutive
+// restarts, 0 meaning unlimited.
+func ParseAutoRestart(value string) (bool, int, error) {
+	if value == "" {
+		return false, 0, nil
+	}
+
+	policy, retries, hasRetries := strings.Cut(value, ":")
+	if policy != "on-failure" {
+		return false, 0, fmt.Errorf("Invalid restart policy %q, must be \"on-failure\"", policy)
+	}
+
+	if !hasRetries {
+		return true, 0, nil
+	}
+
+	maxRetries, err := strconv.Atoi(retries)
+	if err != nil || maxRetries < 1 {
+		return false, 0, fmt.Errorf("Invalid maximum number of retries %q, must be a positive integer", retries)
+	}
+
+	return true, maxRetries, nil
+}
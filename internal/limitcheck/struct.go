@@ -0,0 +1,19 @@
+package limitcheck
+
+// Discrepancy is a configured limit which doesn't match what's applied on the host.
+type Discrepancy struct {
+	Project  string `json:"project" yaml:"project"`   // Project of the instance.
+	Instance string `json:"instance" yaml:"instance"` // Name of the instance.
+	Device   string `json:"device" yaml:"device"`     // Name of the device, empty for instance limits.
+
+	Key      string `json:"key" yaml:"key"`           // Configuration key of the limit.
+	Expected string `json:"expected" yaml:"expected"` // Value expected from the configuration.
+	Actual   string `json:"actual" yaml:"actual"`     // Value applied on the host.
+}
+
+// Report is the result of verifying the limits of the running instances of a server.
+type Report struct {
+	Instances     int           `json:"instances" yaml:"instances"`         // Number of running instances checked.
+	Discrepancies []Discrepancy `json:"discrepancies" yaml:"discrepancies"` // Limits which aren't applied as configured.
+	Errors        []string      `json:"errors" yaml:"errors"`               // Instances which couldn't be checked.
+}
@@ -0,0 +1,83 @@
+//go:build linux
+
+package linux
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// OOMKill represents a process killed by the out-of-memory killer, as reported in the kernel log.
+type OOMKill struct {
+	// Task is the name of the killed process.
+	Task string
+
+	// PID is the PID of the killed process.
+	PID int64
+
+	// MemoryCGroup is the memory cgroup which ran out of memory, empty if the system as a whole did.
+	MemoryCGroup string
+
+	// TaskCGroup is the memory cgroup of the killed process.
+	TaskCGroup string
+}
+
+// OOMKills returns the out-of-memory kills still recorded in the kernel log buffer, from the oldest to the latest.
+func OOMKills() ([]OOMKill, error) {
+	size, err := unix.Klogctl(unix.SYSLOG_ACTION_SIZE_BUFFER, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting the size of the kernel log buffer: %w", err)
+	}
+
+	buf := make([]byte, size)
+	n, err := unix.Klogctl(unix.SYSLOG_ACTION_READ_ALL, buf)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading the kernel log buffer: %w", err)
+	}
+
+	return parseOOMKills(string(buf[:n])), nil
+}
+
+// parseOOMKills extracts the out-of-memory kills from the kernel log, using the summary line logged by the kernel
+// for each of them:
+// oom-kill:constraint=CONSTRAINT_MEMCG,...,oom_memcg=/lxc.payload.c1,task_memcg=/lxc.payload.c1/init.scope,task=stress,pid=1234,uid=1000000
+func parseOOMKills(log string) []OOMKill {
+	kills := []OOMKill{}
+
+	for _, line := range strings.Split(log, "\n") {
+		_, summary, found := strings.Cut(line, "oom-kill:")
+		if !found {
+			continue
+		}
+
+		kill := OOMKill{}
+		for _, field := range strings.Split(summary, ",") {
+			key, value, found := strings.Cut(field, "=")
+			if !found {
+				continue
+			}
+
+			switch key {
+			case "oom_memcg":
+				kill.MemoryCGroup = value
+			case "task_memcg":
+				kill.TaskCGroup = value
+			case "task":
+				kill.Task = value
+			case "pid":
+				kill.PID, _ = strconv.ParseInt(value, 10, 64)
+			}
+		}
+
+		if kill.Task == "" {
+			continue
+		}
+
+		kills = append(kills, kill)
+	}
+
+	return kills
+}
@@ -0,0 +1,26 @@
+//go:build linux
+
+package linux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOOMKills(t *testing.T) {
+	log := `[ 1000.000000] stress invoked oom-killer: gfp_mask=0xcc0(GFP_KERNEL), order=0, oom_score_adj=0
+[ 1000.000001] oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),cpuset=lxc.payload.c1,mems_allowed=0,oom_memcg=/lxc.payload.c1,task_memcg=/lxc.payload.c1/init.scope,task=stress,pid=1234,uid=1000000
+[ 1000.000002] Memory cgroup out of memory: Killed process 1234 (stress) total-vm:1000kB
+[ 2000.000000] oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),cpuset=/,mems_allowed=0,global_oom,task_memcg=/incus.instances/qemu.default_v1,task=qemu-system-x86,pid=5678,uid=0
+[ 3000.000000] oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),pid=9999
+`
+
+	kills := parseOOMKills(log)
+	require.Equal(t, []OOMKill{
+		{Task: "stress", PID: 1234, MemoryCGroup: "/lxc.payload.c1", TaskCGroup: "/lxc.payload.c1/init.scope"},
+		{Task: "qemu-system-x86", PID: 5678, TaskCGroup: "/incus.instances/qemu.default_v1"},
+	}, kills)
+
+	require.Empty(t, parseOOMKills(""))
+}
@@ -0,0 +1,10 @@
+package remap
+
+// Post is used to remap the root filesystem of a stopped container.
+type Post struct {
+	Project  string `json:"project" yaml:"project"`   // Project of the container.
+	Instance string `json:"instance" yaml:"instance"` // Name of the container.
+
+	// Configuration keys to apply before remapping (security.privileged and security.idmap.*).
+	Config map[string]string `json:"config" yaml:"config"`
+}
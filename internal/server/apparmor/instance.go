@@ -68,6 +68,13 @@ func InstanceLoad(sysOS *sys.OS, inst instance, extraBinaries []string) error {
 		return err
 	}
 
+	if inst.Type() == instancetype.Container {
+		err = InstanceProfilesLoad(sysOS, inst)
+		if err != nil {
+			return fmt.Errorf("Failed loading raw.apparmor.profiles: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -96,11 +103,30 @@ func InstanceValidate(sysOS *sys.OS, inst instance, extraBinaries []string) erro
 		return err
 	}
 
-	return parseProfile(sysOS, instanceProfileFilename(inst))
+	err = parseProfile(sysOS, instanceProfileFilename(inst))
+	if err != nil {
+		return err
+	}
+
+	if inst.Type() == instancetype.Container {
+		_, err = instanceProfilesValidate(sysOS, inst)
+		if err != nil {
+			return fmt.Errorf("Failed validating raw.apparmor.profiles: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // InstanceDelete removes the policy from cache/disk.
 func InstanceDelete(sysOS *sys.OS, inst instance) error {
+	if inst.Type() == instancetype.Container {
+		err := instanceProfilesDelete(sysOS, inst)
+		if err != nil {
+			return err
+		}
+	}
+
 	return deleteProfile(sysOS, InstanceProfileName(inst), instanceProfileFilename(inst))
 }
 
@@ -219,6 +245,7 @@ func instanceProfile(sysOS *sys.OS, inst instance, extraBinaries []string) (stri
 			"devicesPath":    inst.DevicesPath(),
 			"exePath":        execPath,
 			"extra_binaries": extraBinaries,
+			"ivshmemPath":    filepath.Join("/dev/shm/incus-ivshmem", inst.Project().Name),
 			"libraryPath":    strings.Split(os.Getenv("LD_LIBRARY_PATH"), ":"),
 			"logPath":        inst.LogPath(),
 			"runPath":        inst.RunPath(),
@@ -0,0 +1,292 @@
+package apparmor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/sys"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/subprocess"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// Includes of arbitrary files would let the profiles read files of the host.
+var instanceProfilesIncludeRegex = regexp.MustCompile(`include\s+(?:if\s+exists\s+)?(["<])([^">]*)`)
+
+// instanceProfilesFilename returns the name of the on-disk file holding the raw.apparmor.profiles of the instance.
+func instanceProfilesFilename(inst instance) string {
+	name := project.Instance(inst.Project().Name, inst.Name())
+	return profileName("profiles", name)
+}
+
+// instanceProfilesSupported checks whether profiles can be loaded in the namespace of the instance.
+func instanceProfilesSupported(sysOS *sys.OS) error {
+	if !sysOS.AppArmorStacking || sysOS.AppArmorStacked {
+		return errors.New("AppArmor stacking is required for raw.apparmor.profiles")
+	}
+
+	return nil
+}
+
+// runApparmorNamespace runs the relevant AppArmor command against the instance namespace.
+func runApparmorNamespace(command string, namespace string, name string) error {
+	_, err := subprocess.RunCommand("apparmor_parser",
+		fmt.Sprintf("-%sWL", command),
+		filepath.Join(aaPath, "cache"),
+		"--namespace", namespace,
+		filepath.Join(aaPath, "profiles", name))
+
+	return err
+}
+
+// runApparmorContent runs apparmor_parser with the given arguments against the given profiles, without writing
+// anything to disk.
+func runApparmorContent(content string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+
+	err := subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(content), &stdout, "apparmor_parser", args...)
+	if err != nil {
+		return "", err
+	}
+
+	return stdout.String(), nil
+}
+
+// instanceProfilesContent returns the raw.apparmor.profiles of the instance as written to disk.
+func instanceProfilesContent(inst instance) string {
+	content := strings.TrimSpace(inst.ExpandedConfig()["raw.apparmor.profiles"])
+	if content != "" {
+		content += "\n"
+	}
+
+	return content
+}
+
+// instanceProfilesCheckIncludes checks that the profiles only include files from the AppArmor configuration.
+func instanceProfilesCheckIncludes(content string) error {
+	for _, include := range instanceProfilesIncludeRegex.FindAllStringSubmatch(content, -1) {
+		if include[1] != "<" || strings.HasPrefix(include[2], "/") || strings.Contains(include[2], "..") {
+			return fmt.Errorf("Only includes from the AppArmor configuration (<...>) are allowed in raw.apparmor.profiles: %q", include[0])
+		}
+	}
+
+	return nil
+}
+
+// instanceProfilesCheckNames checks that the profiles stay in the namespace of the instance.
+func instanceProfilesCheckNames(names []string) error {
+	for _, name := range names {
+		if strings.HasPrefix(name, ":") {
+			return fmt.Errorf("AppArmor profile %q of raw.apparmor.profiles can't be loaded in another namespace", name)
+		}
+	}
+
+	return nil
+}
+
+// instanceProfilesNames returns the names of the profiles defined in the given profiles.
+func instanceProfilesNames(content string) ([]string, error) {
+	out, err := runApparmorContent(content, "-N")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			names = append(names, line)
+		}
+	}
+
+	return names, nil
+}
+
+// instanceProfilesValidate validates the raw.apparmor.profiles of the instance without touching the loaded profiles
+// or the on-disk file. It returns false if the instance doesn't define any profile.
+func instanceProfilesValidate(sysOS *sys.OS, inst instance) (bool, error) {
+	if !sysOS.AppArmorAvailable {
+		return false, nil
+	}
+
+	content := instanceProfilesContent(inst)
+	if content == "" {
+		return false, nil
+	}
+
+	err := instanceProfilesSupported(sysOS)
+	if err != nil {
+		return false, err
+	}
+
+	err = instanceProfilesCheckIncludes(content)
+	if err != nil {
+		return false, err
+	}
+
+	names, err := instanceProfilesNames(content)
+	if err != nil {
+		return false, err
+	}
+
+	err = instanceProfilesCheckNames(names)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = runApparmorContent(content, "-Q", "--namespace", InstanceNamespaceName(inst))
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// InstanceProfilesLoad validates the raw.apparmor.profiles of the instance and loads them in its namespace,
+// replacing the already loaded ones. The previous profiles are only unloaded and replaced on disk once the new ones
+// are known to be valid.
+func InstanceProfilesLoad(sysOS *sys.OS, inst instance) error {
+	if !sysOS.AppArmorAvailable {
+		return nil
+	}
+
+	filename := instanceProfilesFilename(inst)
+	path := filepath.Join(aaPath, "profiles", filename)
+
+	found, err := instanceProfilesValidate(sysOS, inst)
+	if err != nil {
+		return err
+	}
+
+	content := ""
+	if found {
+		content = instanceProfilesContent(inst)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	loaded := sysOS.AppArmorAdmin && util.PathExists(filepath.Join("/sys/kernel/security/apparmor/policy/namespaces", InstanceNamespaceName(inst)))
+
+	// Unload the profiles which may not be defined anymore.
+	if len(current) > 0 && string(current) != content && loaded {
+		_ = runApparmorNamespace(cmdUnload, InstanceNamespaceName(inst), filename)
+	}
+
+	if !found {
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	if string(current) != content {
+		tmpPath := path + ".tmp"
+
+		err = os.WriteFile(tmpPath, []byte(content), 0600)
+		if err != nil {
+			return err
+		}
+
+		err = os.Rename(tmpPath, path)
+		if err != nil {
+			_ = os.Remove(tmpPath)
+			return err
+		}
+	}
+
+	if !sysOS.AppArmorAdmin {
+		return nil
+	}
+
+	return runApparmorNamespace(cmdLoad, InstanceNamespaceName(inst), filename)
+}
+
+// InstanceProfiles returns the profiles defined in the raw.apparmor.profiles of the instance and whether they're
+// loaded in its namespace. It doesn't change any state.
+func InstanceProfiles(sysOS *sys.OS, inst instance) (*api.InstanceAppArmor, error) {
+	result := &api.InstanceAppArmor{
+		Namespace: InstanceNamespaceName(inst),
+		Profiles:  []api.InstanceAppArmorProfile{},
+	}
+
+	content := instanceProfilesContent(inst)
+	if !sysOS.AppArmorAvailable || content == "" {
+		return result, nil
+	}
+
+	names, err := instanceProfilesNames(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the modes of the profiles loaded in the namespace.
+	modes := map[string]string{}
+	profilesPath := filepath.Join("/sys/kernel/security/apparmor/policy/namespaces", result.Namespace, "profiles")
+	entries, err := os.ReadDir(profilesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name, err := os.ReadFile(filepath.Join(profilesPath, entry.Name(), "name"))
+		if err != nil {
+			continue
+		}
+
+		mode, err := os.ReadFile(filepath.Join(profilesPath, entry.Name(), "mode"))
+		if err != nil {
+			continue
+		}
+
+		modes[strings.TrimSpace(string(name))] = strings.TrimSpace(string(mode))
+	}
+
+	for _, name := range names {
+		mode, loaded := modes[name]
+		result.Profiles = append(result.Profiles, api.InstanceAppArmorProfile{
+			Name:   name,
+			Loaded: loaded,
+			Mode:   mode,
+		})
+	}
+
+	return result, nil
+}
+
+// instanceProfilesDelete removes the raw.apparmor.profiles of the instance from cache/disk.
+func instanceProfilesDelete(sysOS *sys.OS, inst instance) error {
+	if !sysOS.AppArmorAdmin {
+		return nil
+	}
+
+	cacheDir, err := getCacheDir(sysOS)
+	if err != nil {
+		return err
+	}
+
+	name := instanceProfilesFilename(inst)
+
+	err = os.Remove(filepath.Join(cacheDir, name))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed to remove %s: %w", filepath.Join(cacheDir, name), err)
+	}
+
+	err = os.Remove(filepath.Join(aaPath, "profiles", name))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed to remove %s: %w", filepath.Join(aaPath, "profiles", name), err)
+	}
+
+	return nil
+}
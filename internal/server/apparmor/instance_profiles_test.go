@@ -0,0 +1,59 @@
+package apparmor
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceProfilesCheckIncludes(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		shouldFail bool
+	}{
+		{"No include", "profile nginx /usr/sbin/nginx {\n  /etc/nginx/** r,\n}\n", false},
+		{"Abstraction", "profile nginx {\n  include <abstractions/base>\n}\n", false},
+		{"Conditional abstraction", "profile nginx {\n  include if exists <local/nginx>\n}\n", false},
+		{"Legacy include", "profile nginx {\n  #include <tunables/global>\n}\n", false},
+		{"Absolute path", "profile nginx {\n  include \"/etc/shadow\"\n}\n", true},
+		{"Conditional absolute path", "profile nginx {\n  include if exists \"/root/.ssh/id_rsa\"\n}\n", true},
+		{"Absolute path in brackets", "profile nginx {\n  include </etc/shadow>\n}\n", true},
+		{"Leaving the configuration", "profile nginx {\n  include <../../etc/shadow>\n}\n", true},
+		{"Second include rejected", "profile nginx {\n  include <abstractions/base>\n  include \"/etc/shadow\"\n}\n", true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		err := instanceProfilesCheckIncludes(tt.content)
+		if tt.shouldFail {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
+
+func TestInstanceProfilesCheckNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		names      []string
+		shouldFail bool
+	}{
+		{"No profile", []string{}, false},
+		{"Local profiles", []string{"nginx", "nginx//php-fpm", "/usr/bin/foo"}, false},
+		{"Other namespace", []string{"nginx", ":incus-other_<var-lib-incus>:nginx"}, true},
+		{"Root namespace", []string{"://unconfined"}, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		err := instanceProfilesCheckNames(tt.names)
+		if tt.shouldFail {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
@@ -61,6 +61,7 @@ profile "{{ .name }}" flags=(attach_disconnected,mediate_deleted) {
   {{ .runPath }}/** rwk,
   {{ .path }}/** rwk,
   {{ .devicesPath }}/** rwk,
+  {{ .ivshmemPath }}/* rw,
 
   # Needed for the fork sub-commands
   {{ .exePath }} mr,
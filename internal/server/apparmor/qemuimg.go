@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -44,7 +47,7 @@ profile "{{ .name }}" flags=(attach_disconnected,mediate_deleted) {
 `))
 
 type nullWriteCloser struct {
-	*bytes.Buffer
+	io.Writer
 }
 
 func (nwc *nullWriteCloser) Close() error {
@@ -56,6 +59,59 @@ func (nwc *nullWriteCloser) Close() error {
 // will be added as an allowed command to the AppArmor profile. The remaining elements of the cmd slice are
 // expected to be the qemu-img command and its arguments.
 func QemuImg(sysOS *sys.OS, cmd []string, imgPath string, dstPath string) (string, error) {
+	var output bytes.Buffer
+
+	err := qemuImgRun(sysOS, cmd, imgPath, dstPath, &output)
+	if err != nil {
+		return "", err
+	}
+
+	return output.String(), nil
+}
+
+// QemuImgProgress runs qemu-img like QemuImg, calling progress with the completion percentage printed by
+// qemu-img when the command includes the -p flag.
+func QemuImgProgress(sysOS *sys.OS, cmd []string, imgPath string, dstPath string, progress func(percent int)) error {
+	return qemuImgRun(sysOS, cmd, imgPath, dstPath, &qemuImgProgressWriter{progress: progress, last: -1})
+}
+
+// qemuImgProgressRegex matches the progress lines printed by qemu-img, such as "(42.50/100%)".
+var qemuImgProgressRegex = regexp.MustCompile(`\((\d+(?:\.\d+)?)/100%\)`)
+
+// qemuImgProgressWriter parses the output of qemu-img and reports each new completion percentage.
+type qemuImgProgressWriter struct {
+	progress func(percent int)
+	buf      []byte
+	last     int
+}
+
+// Write parses the progress lines in the output of qemu-img, keeping any partial line for the next write.
+func (w *qemuImgProgressWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	matches := qemuImgProgressRegex.FindAllSubmatchIndex(w.buf, -1)
+	if len(matches) > 0 {
+		match := matches[len(matches)-1]
+
+		value, err := strconv.ParseFloat(string(w.buf[match[2]:match[3]]), 64)
+		if err == nil && int(value) != w.last {
+			w.last = int(value)
+			w.progress(w.last)
+		}
+
+		w.buf = append([]byte{}, w.buf[match[1]:]...)
+	}
+
+	// Only keep what could be the start of a progress line.
+	if len(w.buf) > 32 {
+		w.buf = append([]byte{}, w.buf[len(w.buf)-32:]...)
+	}
+
+	return len(p), nil
+}
+
+// qemuImgRun runs qemu-img with an AppArmor profile, writing its standard output to stdout.
+func qemuImgRun(sysOS *sys.OS, cmd []string, imgPath string, dstPath string, stdout io.Writer) error {
 	//It is assumed that command starts with a program which sets resource limits, like prlimit or nice
 	allowedCmds := []string{"qemu-img", cmd[0]}
 
@@ -63,7 +119,7 @@ func QemuImg(sysOS *sys.OS, cmd []string, imgPath string, dstPath string) (strin
 	for _, c := range allowedCmds {
 		cmdPath, err := exec.LookPath(c)
 		if err != nil {
-			return "", fmt.Errorf("Failed to find executable %q: %w", c, err)
+			return fmt.Errorf("Failed to find executable %q: %w", c, err)
 		}
 
 		allowedCmdPaths = append(allowedCmdPaths, cmdPath)
@@ -84,7 +140,7 @@ func QemuImg(sysOS *sys.OS, cmd []string, imgPath string, dstPath string) (strin
 
 	profileName, err := qemuImgProfileLoad(sysOS, imgPath, dstPath, allowedCmdPaths)
 	if err != nil {
-		return "", fmt.Errorf("Failed to load qemu-img profile: %w", err)
+		return fmt.Errorf("Failed to load qemu-img profile: %w", err)
 	}
 
 	defer func() {
@@ -92,25 +148,24 @@ func QemuImg(sysOS *sys.OS, cmd []string, imgPath string, dstPath string) (strin
 	}()
 
 	var buffer bytes.Buffer
-	var output bytes.Buffer
-	p := subprocess.NewProcessWithFds(cmd[0], cmd[1:], nil, &nullWriteCloser{&output}, &nullWriteCloser{&buffer})
+	p := subprocess.NewProcessWithFds(cmd[0], cmd[1:], nil, &nullWriteCloser{stdout}, &nullWriteCloser{&buffer})
 	if err != nil {
-		return "", fmt.Errorf("Failed creating qemu-img subprocess: %w", err)
+		return fmt.Errorf("Failed creating qemu-img subprocess: %w", err)
 	}
 
 	p.SetApparmor(profileName)
 
 	err = p.Start(context.Background())
 	if err != nil {
-		return "", fmt.Errorf("Failed running qemu-img: %w", err)
+		return fmt.Errorf("Failed running qemu-img: %w", err)
 	}
 
 	_, err = p.Wait(context.Background())
 	if err != nil {
-		return "", subprocess.NewRunError(cmd[0], cmd[1:], err, nil, &buffer)
+		return subprocess.NewRunError(cmd[0], cmd[1:], err, nil, &buffer)
 	}
 
-	return output.String(), nil
+	return nil
 }
 
 // qemuImgProfileLoad ensures that the qemu-img's policy is loaded into the kernel.
@@ -0,0 +1,35 @@
+package apparmor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQemuImgProgressWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		expected []int
+	}{
+		{"Single line", []string{"    (42.50/100%)\r"}, []int{42}},
+		{"Multiple lines", []string{"    (0.00/100%)\r    (1.01/100%)\r    (1.99/100%)\r    (100.00/100%)\r"}, []int{100}},
+		{"Lines over several writes", []string{"    (0.00/100%)\r", "    (12.", "34/100%)\r", "    (12.80/100%)\r", "    (55.00/100%)\r"}, []int{0, 12, 55}},
+		{"Unrelated output", []string{"Some output\n", "    (3.00/100%)\r"}, []int{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reported := []int{}
+			w := &qemuImgProgressWriter{progress: func(percent int) { reported = append(reported, percent) }, last: -1}
+
+			for _, chunk := range tt.chunks {
+				n, err := w.Write([]byte(chunk))
+				assert.NoError(t, err)
+				assert.Equal(t, len(chunk), n)
+			}
+
+			assert.Equal(t, tt.expected, reported)
+		})
+	}
+}
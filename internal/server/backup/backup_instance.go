@@ -22,6 +22,7 @@ type Instance interface {
 	Name() string
 	Project() api.Project
 	Operation() *operations.Operation
+	ExpandedConfig() map[string]string
 }
 
 // InstanceBackup represents an instance backup.
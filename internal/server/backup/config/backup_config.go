@@ -14,4 +14,26 @@ type Config struct {
 	VolumeSnapshots []*api.StorageVolumeSnapshot `yaml:"volume_snapshots,omitempty"`
 	Bucket          *api.StorageBucket           `yaml:"bucket,omitempty"`
 	BucketKeys      []*api.StorageBucketKey      `yaml:"bucket_keys,omitempty"`
+	Network         *NetworkState                `yaml:"network,omitempty"`
+}
+
+// NetworkState represents the network dependencies of an instance which can be included in its backup.
+type NetworkState struct {
+	ACLs       []*api.NetworkACL    `yaml:"acls,omitempty"`
+	Forwards   []*NetworkForward    `yaml:"forwards,omitempty"`
+	DNSRecords []*NetworkZoneRecord `yaml:"dns_records,omitempty"`
+}
+
+// NetworkForward represents a network forward targeting the instance, along with the network it belongs to.
+type NetworkForward struct {
+	api.NetworkForward `yaml:",inline"`
+
+	Network string `yaml:"network"`
+}
+
+// NetworkZoneRecord represents a DNS record pointing to the instance, along with the zone it belongs to.
+type NetworkZoneRecord struct {
+	api.NetworkZoneRecord `yaml:",inline"`
+
+	Zone string `yaml:"zone"`
 }
@@ -37,6 +37,35 @@ func (cg *CGroup) SetMaxProcesses(limit int64) error {
 	return ErrUnknownVersion
 }
 
+// GetMaxProcesses returns the maximum number of processes, -1 when unlimited.
+func (cg *CGroup) GetMaxProcesses() (int64, error) {
+	version := cgControllers["pids"]
+	switch version {
+	case Unavailable:
+		return -1, ErrControllerMissing
+	case V1:
+		fallthrough
+	case V2:
+		val, err := cg.rw.Get(version, "pids", "pids.max")
+		if err != nil {
+			return -1, err
+		}
+
+		if val == "max" {
+			return -1, nil
+		}
+
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return -1, fmt.Errorf("Failed parsing %q: %w", val, err)
+		}
+
+		return n, nil
+	}
+
+	return -1, ErrUnknownVersion
+}
+
 // GetMemorySoftLimit returns the soft limit for memory.
 func (cg *CGroup) GetMemorySoftLimit() (int64, error) {
 	version := cgControllers["memory"]
@@ -115,6 +144,10 @@ func (cg *CGroup) GetMemoryLimit() (int64, error) {
 			return -1, err
 		}
 
+		if val == "max" {
+			return -1, nil
+		}
+
 		n, err := strconv.ParseInt(val, 10, 64)
 		if err != nil {
 			return -1, fmt.Errorf("Failed parsing %q: %w", val, err)
@@ -136,7 +169,7 @@ func (cg *CGroup) GetEffectiveMemoryLimit() (int64, error) {
 	}
 
 	memoryLimit, err := cg.GetMemoryLimit()
-	if err != nil || memoryLimit > memoryTotal {
+	if err != nil || memoryLimit < 0 || memoryLimit > memoryTotal {
 		return memoryTotal, nil
 	}
 
@@ -222,6 +255,41 @@ func (cg *CGroup) GetProcessesUsage() (int64, error) {
 	return -1, ErrUnknownVersion
 }
 
+// GetProcessesLimitHits returns the number of times the pids limit was hit.
+func (cg *CGroup) GetProcessesLimitHits() (int64, error) {
+	version := cgControllers["pids"]
+	switch version {
+	case Unavailable:
+		return -1, ErrControllerMissing
+	case V1:
+		fallthrough
+	case V2:
+		stats, err := cg.rw.Get(version, "pids", "pids.events")
+		if err != nil {
+			return -1, err
+		}
+
+		for _, stat := range strings.Split(stats, "\n") {
+			field := strings.Split(stat, " ")
+			// skip incorrect lines
+			if len(field) != 2 || field[0] != "max" {
+				continue
+			}
+
+			n, err := strconv.ParseInt(field[1], 10, 64)
+			if err != nil {
+				return -1, fmt.Errorf("Failed parsing %q: %w", field[1], err)
+			}
+
+			return n, nil
+		}
+
+		return -1, fmt.Errorf("Failed getting pids limit hits")
+	}
+
+	return -1, ErrUnknownVersion
+}
+
 // SetMemorySwapLimit sets the hard limit for swap.
 func (cg *CGroup) SetMemorySwapLimit(limit int64) error {
 	version := cgControllers["memory"]
@@ -747,6 +815,91 @@ func (cg *CGroup) SetCPUCfsLimit(limitPeriod int64, limitQuota int64) error {
 	return ErrUnknownVersion
 }
 
+// GetCPUCfsLimit returns the CFS period and quota, with a quota of -1 when unlimited.
+func (cg *CGroup) GetCPUCfsLimit() (int64, int64, error) {
+	version := cgControllers["cpu"]
+	switch version {
+	case Unavailable:
+		return -1, -1, ErrControllerMissing
+	case V1:
+		quota, err := cg.rw.Get(version, "cpu", "cpu.cfs_quota_us")
+		if err != nil {
+			return -1, -1, err
+		}
+
+		period, err := cg.rw.Get(version, "cpu", "cpu.cfs_period_us")
+		if err != nil {
+			return -1, -1, err
+		}
+
+		quotaInt, err := strconv.ParseInt(quota, 10, 64)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Failed parsing %q: %w", quota, err)
+		}
+
+		periodInt, err := strconv.ParseInt(period, 10, 64)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Failed parsing %q: %w", period, err)
+		}
+
+		return periodInt, quotaInt, nil
+	case V2:
+		val, err := cg.rw.Get(version, "cpu", "cpu.max")
+		if err != nil {
+			return -1, -1, err
+		}
+
+		fields := strings.Fields(val)
+		if len(fields) != 2 {
+			return -1, -1, fmt.Errorf("Failed parsing %q", val)
+		}
+
+		periodInt, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Failed parsing %q: %w", val, err)
+		}
+
+		if fields[0] == "max" {
+			return periodInt, -1, nil
+		}
+
+		quotaInt, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Failed parsing %q: %w", val, err)
+		}
+
+		return periodInt, quotaInt, nil
+	}
+
+	return -1, -1, ErrUnknownVersion
+}
+
+// SetCPUUclamp sets the minimum and maximum utilization clamps, in percent of the CPU capacity.
+func (cg *CGroup) SetCPUUclamp(limitMin int64, limitMax int64) error {
+	version := cgControllers["cpu.uclamp.min"]
+	switch version {
+	case Unavailable:
+		return ErrControllerMissing
+	case V2:
+		uclampValue := func(limit int64) string {
+			if limit >= 100 {
+				return "max"
+			}
+
+			return fmt.Sprintf("%d.00", limit)
+		}
+
+		err := cg.rw.Set(version, "cpu", "cpu.uclamp.min", uclampValue(limitMin))
+		if err != nil {
+			return err
+		}
+
+		return cg.rw.Set(version, "cpu", "cpu.uclamp.max", uclampValue(limitMax))
+	}
+
+	return ErrUnknownVersion
+}
+
 // SetHugepagesLimit applies a limit to the number of processes.
 func (cg *CGroup) SetHugepagesLimit(pageType string, limit int64) error {
 	version := cgControllers["hugetlb"]
@@ -847,6 +1000,36 @@ func (cg *CGroup) SetCpuset(limit string) error {
 	return ErrUnknownVersion
 }
 
+// GetEffectiveCpusetMems returns the current set of memory nodes for the cgroup.
+func (cg *CGroup) GetEffectiveCpusetMems() (string, error) {
+	version := cgControllers["cpuset"]
+	switch version {
+	case Unavailable:
+		return "", ErrControllerMissing
+	case V1:
+		return cg.rw.Get(version, "cpuset", "cpuset.effective_mems")
+	case V2:
+		return cg.rw.Get(version, "cpuset", "cpuset.mems.effective")
+	}
+
+	return "", ErrUnknownVersion
+}
+
+// SetCpusetMems set the currently allowed set of memory nodes for the cgroups.
+func (cg *CGroup) SetCpusetMems(limit string) error {
+	version := cgControllers["cpuset"]
+	switch version {
+	case Unavailable:
+		return ErrControllerMissing
+	case V1:
+		return cg.rw.Set(version, "cpuset", "cpuset.mems", limit)
+	case V2:
+		return cg.rw.Set(version, "cpuset", "cpuset.mems", limit)
+	}
+
+	return ErrUnknownVersion
+}
+
 // GetMemoryStats returns memory stats.
 func (cg *CGroup) GetMemoryStats() (map[string]uint64, error) {
 	var (
@@ -1110,3 +1293,61 @@ func (cg *CGroup) GetIOStats() (map[string]*IOStats, error) {
 
 	return nil, ErrUnknownVersion
 }
+
+// PressureResources are the resources the pressure stall information is reported for.
+var PressureResources = []string{"cpu", "memory", "io"}
+
+// GetPressure returns the pressure stall information of the given resource (cpu, memory or io).
+func (cg *CGroup) GetPressure(resource string) (*Pressure, error) {
+	if cgLayout != CgroupsUnified || !slices.Contains(PressureResources, resource) {
+		return nil, ErrControllerMissing
+	}
+
+	value, err := cg.rw.Get(V2, resource, fmt.Sprintf("%s.pressure", resource))
+	if err != nil {
+		return nil, err
+	}
+
+	pressure := &Pressure{}
+	for _, line := range strings.Split(value, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var stall *PressureStall
+
+		switch fields[0] {
+		case "some":
+			stall = &pressure.Some
+		case "full":
+			stall = &pressure.Full
+		default:
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			key, val, found := strings.Cut(field, "=")
+			if !found {
+				continue
+			}
+
+			switch key {
+			case "avg10":
+				stall.Avg10, err = strconv.ParseFloat(val, 64)
+			case "avg60":
+				stall.Avg60, err = strconv.ParseFloat(val, 64)
+			case "avg300":
+				stall.Avg300, err = strconv.ParseFloat(val, 64)
+			case "total":
+				stall.Total, err = strconv.ParseUint(val, 10, 64)
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("Failed parsing %s.pressure: %w", resource, err)
+			}
+		}
+	}
+
+	return pressure, nil
+}
@@ -19,6 +19,20 @@ func TaskSchedulerTrigger(srcType string, srcName string, srcStatus string) {
 	}
 }
 
+// ParseCPUPower returns the minimum and maximum utilization clamps, in percent, for a CPU power policy.
+func ParseCPUPower(cpuPower string) (int64, int64, error) {
+	switch cpuPower {
+	case "", "balanced":
+		return 0, 100, nil
+	case "performance":
+		return 100, 100, nil
+	case "efficiency":
+		return 0, 50, nil
+	}
+
+	return -1, -1, fmt.Errorf("Invalid CPU power policy %q", cpuPower)
+}
+
 // ParseCPU parses CPU allowances.
 func ParseCPU(cpuAllowance string, cpuPriority string) (int64, int64, int64, error) {
 	var err error
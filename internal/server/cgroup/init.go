@@ -85,6 +85,9 @@ const (
 	// CPUSet resource control.
 	CPUSet
 
+	// CPUUclamp resource control.
+	CPUUclamp
+
 	// Devices resource control.
 	Devices
 
@@ -162,6 +165,9 @@ func (info *Info) SupportsVersion(resource Resource) (Backend, bool) {
 	case CPUSet:
 		val, ok := cgControllers["cpuset"]
 		return val, ok
+	case CPUUclamp:
+		val, ok := cgControllers["cpu.uclamp.min"]
+		return val, ok
 	case Devices:
 		val, ok := cgControllers["devices"]
 		return val, ok
@@ -463,6 +469,13 @@ func Init() {
 		}
 	}
 
+	val, ok = cgControllers["cpu"]
+	if ok && val == V2 {
+		if util.PathExists("/sys/fs/cgroup/init.scope/cpu.uclamp.min") {
+			cgControllers["cpu.uclamp.min"] = V2
+		}
+	}
+
 	if hasV1 && hasV2 {
 		cgLayout = CgroupsHybrid
 	} else if hasV1 {
@@ -0,0 +1,126 @@
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// InstancesParent is the cgroup the instances are placed under, limiting them to the host resources left once the
+// reserved ones are carved out.
+const InstancesParent = "incus.instances"
+
+// instancesParentPath is the path of the instances parent cgroup on the unified hierarchy.
+var instancesParentPath = filepath.Join("/sys/fs/cgroup", InstancesParent)
+
+// InstancesParentEnabled returns whether the instances are to be placed under the instances parent cgroup.
+func InstancesParentEnabled() bool {
+	return cgLayout == CgroupsUnified && util.PathExists(instancesParentPath)
+}
+
+// SetInstancesReservation restricts the instances parent cgroup to the given CPU set and memory limit, creating it
+// if needed. An empty CPU set or a memory limit of zero lifts the corresponding restriction.
+func SetInstancesReservation(cpus string, memoryMax int64) error {
+	if !InstancesParentEnabled() && cpus == "" && memoryMax <= 0 {
+		return nil
+	}
+
+	if cgLayout != CgroupsUnified {
+		return errors.New("Reserving host resources requires a pure cgroup2 layout")
+	}
+
+	err := os.MkdirAll(instancesParentPath, 0o755)
+	if err != nil {
+		return fmt.Errorf("Failed creating the instances parent cgroup: %w", err)
+	}
+
+	// Delegate the controllers to the instances parent and from it to the instances.
+	for _, path := range []string{filepath.Dir(instancesParentPath), instancesParentPath} {
+		err = os.WriteFile(filepath.Join(path, "cgroup.subtree_control"), []byte("+cpuset +memory"), 0)
+		if err != nil {
+			return fmt.Errorf("Failed enabling the cpuset and memory controllers in %q: %w", path, err)
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(instancesParentPath, "cpuset.cpus"), []byte(cpus), 0)
+	if err != nil {
+		return fmt.Errorf("Failed setting the CPUs of the instances parent cgroup: %w", err)
+	}
+
+	memory := "max"
+	if memoryMax > 0 {
+		memory = strconv.FormatInt(memoryMax, 10)
+	}
+
+	err = os.WriteFile(filepath.Join(instancesParentPath, "memory.max"), []byte(memory), 0)
+	if err != nil {
+		return fmt.Errorf("Failed setting the memory limit of the instances parent cgroup: %w", err)
+	}
+
+	return nil
+}
+
+// VMInstancesParentName returns the name of the cgroup of a VM under the instances parent cgroup, from the project
+// prefixed name of the VM.
+func VMInstancesParentName(name string) string {
+	return fmt.Sprintf("qemu.%s", name)
+}
+
+// PrepareInstancesParent creates a cgroup of the given name under the instances parent cgroup and returns its path,
+// for the process of an instance to be started in it. An empty path is returned when the instances parent cgroup
+// isn't in use.
+func PrepareInstancesParent(name string) (string, error) {
+	if !InstancesParentEnabled() {
+		return "", nil
+	}
+
+	path := filepath.Join(instancesParentPath, name)
+	err := os.MkdirAll(path, 0o755)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// AddToInstancesParent moves the running process into a cgroup of the given name under the instances parent cgroup.
+// Nothing is done when the instances parent cgroup isn't in use.
+func AddToInstancesParent(name string, pid int) error {
+	path, err := PrepareInstancesParent(name)
+	if err != nil || path == "" {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(path, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0)
+}
+
+// RemoveFromInstancesParent removes the cgroup of the given name from the instances parent cgroup, once its
+// processes have exited.
+func RemoveFromInstancesParent(name string) error {
+	err := os.Remove(filepath.Join(instancesParentPath, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// InstancesParentCGroup returns the cgroup of the given name under the instances parent cgroup, as used by the VMs.
+func InstancesParentCGroup(name string) (*CGroup, error) {
+	path := filepath.Join(instancesParentPath, name)
+	if !InstancesParentEnabled() || !util.PathExists(path) {
+		return nil, ErrControllerMissing
+	}
+
+	cg, err := New(&fileReadWriter{paths: map[string]string{"unified": path}})
+	if err != nil {
+		return nil, err
+	}
+
+	cg.UnifiedCapable = true
+	return cg, nil
+}
@@ -0,0 +1,114 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetInstancesReservation(t *testing.T) {
+	oldLayout := cgLayout
+	oldPath := instancesParentPath
+	defer func() {
+		cgLayout = oldLayout
+		instancesParentPath = oldPath
+	}()
+
+	cgLayout = CgroupsUnified
+	root := t.TempDir()
+	instancesParentPath = filepath.Join(root, InstancesParent)
+
+	readFile := func(path string) string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		return string(content)
+	}
+
+	// Nothing is created until something is reserved.
+	require.NoError(t, SetInstancesReservation("", 0))
+	require.False(t, InstancesParentEnabled())
+
+	require.NoError(t, SetInstancesReservation("2-3", 4096))
+	require.True(t, InstancesParentEnabled())
+	require.Equal(t, "+cpuset +memory", readFile(filepath.Join(root, "cgroup.subtree_control")))
+	require.Equal(t, "+cpuset +memory", readFile(filepath.Join(instancesParentPath, "cgroup.subtree_control")))
+	require.Equal(t, "2-3", readFile(filepath.Join(instancesParentPath, "cpuset.cpus")))
+	require.Equal(t, "4096", readFile(filepath.Join(instancesParentPath, "memory.max")))
+
+	// Lifting the reservation keeps the parent cgroup the running instances are in, without restrictions.
+	require.NoError(t, SetInstancesReservation("", 0))
+	require.True(t, InstancesParentEnabled())
+	require.Empty(t, readFile(filepath.Join(instancesParentPath, "cpuset.cpus")))
+	require.Equal(t, "max", readFile(filepath.Join(instancesParentPath, "memory.max")))
+
+	// The VMs get their own cgroup under the parent one.
+	path, err := PrepareInstancesParent(VMInstancesParentName("default_v1"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(instancesParentPath, "qemu.default_v1"), path)
+	require.DirExists(t, path)
+
+	require.NoError(t, AddToInstancesParent(VMInstancesParentName("default_v1"), 1234))
+	require.Equal(t, "1234", readFile(filepath.Join(path, "cgroup.procs")))
+
+	require.NoError(t, os.Remove(filepath.Join(path, "cgroup.procs")))
+	require.NoError(t, RemoveFromInstancesParent(VMInstancesParentName("default_v1")))
+	require.NoDirExists(t, path)
+	require.NoError(t, RemoveFromInstancesParent(VMInstancesParentName("default_v1")))
+
+	// Reserving requires a pure cgroup2 layout.
+	cgLayout = CgroupsHybrid
+	require.Error(t, SetInstancesReservation("2-3", 0))
+}
+
+func TestGetPressure(t *testing.T) {
+	oldLayout := cgLayout
+	oldPath := instancesParentPath
+	defer func() {
+		cgLayout = oldLayout
+		instancesParentPath = oldPath
+	}()
+
+	cgLayout = CgroupsUnified
+	instancesParentPath = filepath.Join(t.TempDir(), InstancesParent)
+	require.NoError(t, os.Mkdir(instancesParentPath, 0o755))
+
+	// The cgroup of a VM only exists while it's running.
+	_, err := InstancesParentCGroup(VMInstancesParentName("default_v1"))
+	require.ErrorIs(t, err, ErrControllerMissing)
+
+	path, err := PrepareInstancesParent(VMInstancesParentName("default_v1"))
+	require.NoError(t, err)
+
+	cg, err := InstancesParentCGroup(VMInstancesParentName("default_v1"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "memory.pressure"), []byte("some avg10=1.50 avg60=20.25 avg300=3.00 total=123456\nfull avg10=0.00 avg60=10.00 avg300=1.00 total=654\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "cpu.pressure"), []byte("some avg10=0.00 avg60=0.00 avg300=0.00 total=42\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "io.pressure"), []byte("some avg10=high\n"), 0o644))
+
+	pressure, err := cg.GetPressure("memory")
+	require.NoError(t, err)
+	require.Equal(t, &Pressure{
+		Some: PressureStall{Avg10: 1.5, Avg60: 20.25, Avg300: 3, Total: 123456},
+		Full: PressureStall{Avg60: 10, Avg300: 1, Total: 654},
+	}, pressure)
+
+	// Older kernels don't report the full line for the CPU.
+	pressure, err = cg.GetPressure("cpu")
+	require.NoError(t, err)
+	require.Equal(t, &Pressure{Some: PressureStall{Total: 42}}, pressure)
+
+	_, err = cg.GetPressure("io")
+	require.Error(t, err)
+
+	_, err = cg.GetPressure("hugetlb")
+	require.ErrorIs(t, err, ErrControllerMissing)
+
+	// The pressure stall information is only available with a pure cgroup2 layout.
+	cgLayout = CgroupsHybrid
+	_, err = cg.GetPressure("memory")
+	require.ErrorIs(t, err, ErrControllerMissing)
+}
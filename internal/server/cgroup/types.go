@@ -35,3 +35,17 @@ type CPUStats struct {
 	User   int64
 	System int64
 }
+
+// PressureStall represents how long some or all tasks were stalled on a resource.
+type PressureStall struct {
+	Avg10  float64 // Share of time (in %) over the last 10 seconds.
+	Avg60  float64 // Share of time (in %) over the last 60 seconds.
+	Avg300 float64 // Share of time (in %) over the last 300 seconds.
+	Total  uint64  // Total time in microseconds.
+}
+
+// Pressure represents the pressure stall information of a resource.
+type Pressure struct {
+	Some PressureStall
+	Full PressureStall
+}
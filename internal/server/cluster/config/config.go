@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -12,7 +13,13 @@ import (
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/server/config"
 	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/events"
+	"github.com/lxc/incus/v6/internal/server/ratelimit"
 	scriptletLoad "github.com/lxc/incus/v6/internal/server/scriptlet/load"
+	"github.com/lxc/incus/v6/internal/server/tracing"
+	"github.com/lxc/incus/v6/internal/server/webhook"
+	"github.com/lxc/incus/v6/shared/units"
+	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
@@ -44,6 +51,16 @@ func (c *Config) BackupsCompressionAlgorithm() string {
 	return c.m.GetString("backups.compression_algorithm")
 }
 
+// BackupsDatabaseCount returns the number of global database backups to keep on each server.
+func (c *Config) BackupsDatabaseCount() int64 {
+	return c.m.GetInt64("backups.database_count")
+}
+
+// CatalogAuthentication checks whether the catalog API requires authentication.
+func (c *Config) CatalogAuthentication() bool {
+	return c.m.GetBool("core.catalog_authentication")
+}
+
 // MetricsAuthentication checks whether metrics API requires authentication.
 func (c *Config) MetricsAuthentication() bool {
 	return c.m.GetBool("core.metrics_authentication")
@@ -80,6 +97,16 @@ func (c *Config) TrustCACertificates() bool {
 	return c.m.GetBool("core.trust_ca_certificates")
 }
 
+// Tracing returns the OTLP/HTTP endpoint the traces are exported to (if any), the headers added to the export
+// requests, the CA certificate of the collector and the ratio of the new traces which are sampled.
+func (c *Config) Tracing() (string, map[string]string, string, float64) {
+	// Both values are validated when set.
+	headers, _ := tracing.ParseHeaders(c.m.GetString("core.tracing.headers"))
+	sampleRatio, _ := strconv.ParseFloat(c.m.GetString("core.tracing.sample_ratio"), 64)
+
+	return c.m.GetString("core.tracing.endpoint"), headers, c.m.GetString("core.tracing.ca_cert"), sampleRatio
+}
+
 // ProxyHTTPS returns the configured HTTPS proxy, if any.
 func (c *Config) ProxyHTTPS() string {
 	return c.m.GetString("core.proxy_https")
@@ -95,11 +122,21 @@ func (c *Config) ProxyIgnoreHosts() string {
 	return c.m.GetString("core.proxy_ignore_hosts")
 }
 
+// HTTPSPathPrefix returns the configured URL path prefix of the API, if any.
+func (c *Config) HTTPSPathPrefix() string {
+	return c.m.GetString("core.https_path_prefix")
+}
+
 // HTTPSTrustedProxy returns the configured HTTPS trusted proxy setting, if any.
 func (c *Config) HTTPSTrustedProxy() string {
 	return c.m.GetString("core.https_trusted_proxy")
 }
 
+// APIRateLimits returns the configured API rate limits and burst sizes per source address and per identity.
+func (c *Config) APIRateLimits() (string, int64, string, int64) {
+	return c.m.GetString("api.rate_limit.address"), c.m.GetInt64("api.rate_limit.address.burst"), c.m.GetString("api.rate_limit.identity"), c.m.GetInt64("api.rate_limit.identity.burst")
+}
+
 // OfflineThreshold returns the configured heartbeat threshold, i.e. the
 // number of seconds before after which an unresponsive node is considered
 // offline..
@@ -108,11 +145,61 @@ func (c *Config) OfflineThreshold() time.Duration {
 	return time.Duration(n) * time.Second
 }
 
+// TimeSkewThreshold returns the clock drift from the leader after which a member is considered skewed.
+func (c *Config) TimeSkewThreshold() time.Duration {
+	n := c.m.GetInt64("cluster.time_skew_threshold")
+	return time.Duration(n) * time.Second
+}
+
+// EventsJournalSize returns the maximum size of the event journal in bytes.
+func (c *Config) EventsJournalSize() int64 {
+	size, _ := units.ParseByteSizeString(c.m.GetString("events.journal.size"))
+	return size
+}
+
+// EventsLifecycleEnrichment returns the instance fields to embed in lifecycle events.
+func (c *Config) EventsLifecycleEnrichment() events.LifecycleEnrichment {
+	enrichment, _ := events.ParseLifecycleEnrichment(c.m.GetString("events.lifecycle.enrichment"))
+	return enrichment
+}
+
+// EventsWebhooks returns all the webhook settings needed to send notifications.
+func (c *Config) EventsWebhooks() ([]string, []string, string, string, int) {
+	var urls []string
+	var actions []string
+
+	if c.m.GetString("events.webhooks.urls") != "" {
+		urls = util.SplitNTrimSpace(c.m.GetString("events.webhooks.urls"), ",", -1, true)
+	}
+
+	if c.m.GetString("events.webhooks.actions") != "" {
+		actions = util.SplitNTrimSpace(c.m.GetString("events.webhooks.actions"), ",", -1, true)
+	}
+
+	return urls, actions, c.m.GetString("events.webhooks.payload"), c.m.GetString("events.webhooks.secret"), int(c.m.GetInt64("events.webhooks.retries"))
+}
+
 // ImagesMinimalReplica returns the numbers of nodes for cluster images replication.
 func (c *Config) ImagesMinimalReplica() int64 {
 	return c.m.GetInt64("cluster.images_minimal_replica")
 }
 
+// ImagesReplication returns the number of nodes images are replicated to, taking cluster.images_replication
+// into account (-1 for all nodes).
+func (c *Config) ImagesReplication() int64 {
+	value := c.m.GetString("cluster.images_replication")
+	if value == "all" {
+		return -1
+	}
+
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		return count
+	}
+
+	return c.ImagesMinimalReplica()
+}
+
 // MaxVoters returns the maximum number of members in a cluster that will be
 // assigned the voter role.
 func (c *Config) MaxVoters() int64 {
@@ -125,6 +212,11 @@ func (c *Config) MaxStandBy() int64 {
 	return c.m.GetInt64("cluster.max_standby")
 }
 
+// NetworkFirewallStrict returns whether Incus refrains from adding broad host firewall rules and sysctls.
+func (c *Config) NetworkFirewallStrict() bool {
+	return c.m.GetBool("network.firewall.strict")
+}
+
 // NetworkOVNIntegrationBridge returns the integration OVS bridge to use for OVN networks.
 func (c *Config) NetworkOVNIntegrationBridge() string {
 	return c.m.GetString("network.ovn.integration_bridge")
@@ -152,6 +244,16 @@ func (c *Config) ImagesDefaultArchitecture() string {
 	return c.m.GetString("images.default_architecture")
 }
 
+// ImagesBuilder returns the server and alias of the image used to build images from Git recipes.
+func (c *Config) ImagesBuilder() (string, string) {
+	return c.m.GetString("images.builder.server"), c.m.GetString("images.builder.alias")
+}
+
+// ImagesBuilderMember returns the name of the cluster member building images from Git recipes.
+func (c *Config) ImagesBuilderMember() string {
+	return c.m.GetString("images.builder.member")
+}
+
 // ImagesCompressionAlgorithm returns the compression algorithm to use for images.
 func (c *Config) ImagesCompressionAlgorithm() string {
 	return c.m.GetString("images.compression_algorithm")
@@ -172,16 +274,51 @@ func (c *Config) ImagesRemoteCacheExpiryDays() int64 {
 	return c.m.GetInt64("images.remote_cache_expiry")
 }
 
+// InstancesHugepagesDynamic returns whether the kernel huge page pools are grown as needed when starting instances.
+func (c *Config) InstancesHugepagesDynamic() bool {
+	return c.m.GetBool("instances.hugepages.dynamic")
+}
+
 // InstancesNICHostname returns hostname mode to use for instance NICs.
 func (c *Config) InstancesNICHostname() string {
 	return c.m.GetString("instances.nic.host_name")
 }
 
+// InstancesIdleThresholds returns the window over which instance activity is measured for idle detection
+// along with the CPU (percentage of a single CPU), network and disk (bytes) activity thresholds.
+// A zero window means that idle detection is disabled.
+func (c *Config) InstancesIdleThresholds() (time.Duration, int64, int64, int64) {
+	window := time.Duration(c.m.GetInt64("instances.idle.window")) * time.Hour
+
+	networkThreshold, _ := units.ParseByteSizeString(c.m.GetString("instances.idle.network_threshold"))
+	diskThreshold, _ := units.ParseByteSizeString(c.m.GetString("instances.idle.disk_threshold"))
+
+	return window, c.m.GetInt64("instances.idle.cpu_threshold"), networkThreshold, diskThreshold
+}
+
+// InstancesShutdownCheckpoint returns whether containers with security.criu enabled are checkpointed on shutdown.
+func (c *Config) InstancesShutdownCheckpoint() bool {
+	return c.m.GetBool("instances.shutdown_checkpoint")
+}
+
 // InstancesPlacementScriptlet returns the instances placement scriptlet source code.
 func (c *Config) InstancesPlacementScriptlet() string {
 	return c.m.GetString("instances.placement.scriptlet")
 }
 
+// InstancesRecycleBinExpiry returns how long deleted instances and custom volumes are kept in the recycle bin.
+// An empty value means that the recycle bin is disabled.
+func (c *Config) InstancesRecycleBinExpiry() string {
+	return c.m.GetString("instances.recycle_bin.expiry")
+}
+
+// InstancesRecycleBinMaxSize returns the disk usage in bytes above which deleted instances and custom volumes aren't
+// kept in the recycle bin, 0 meaning no limit.
+func (c *Config) InstancesRecycleBinMaxSize() int64 {
+	size, _ := units.ParseByteSizeString(c.m.GetString("instances.recycle_bin.max_size"))
+	return size
+}
+
 // LokiServer returns all the Loki settings needed to connect to a server.
 func (c *Config) LokiServer() (string, string, string, string, string, string, []string, []string) {
 	var types []string
@@ -198,6 +335,11 @@ func (c *Config) LokiServer() (string, string, string, string, string, string, [
 	return c.m.GetString("loki.api.url"), c.m.GetString("loki.auth.username"), c.m.GetString("loki.auth.password"), c.m.GetString("loki.api.ca_cert"), c.m.GetString("loki.instance"), c.m.GetString("loki.loglevel"), labels, types
 }
 
+// OperationsConcurrency returns the maximum number of concurrent operations of the given class (0 for no limit).
+func (c *Config) OperationsConcurrency(class string) int {
+	return int(c.m.GetInt64(fmt.Sprintf("operations.concurrency.%s", class)))
+}
+
 // ACME returns all ACME settings needed for certificate renewal.
 func (c *Config) ACME() (string, string, string, bool) {
 	return c.m.GetString("acme.domain"), c.m.GetString("acme.email"), c.m.GetString("acme.ca_url"), c.m.GetBool("acme.agree_tos")
@@ -218,6 +360,16 @@ func (c *Config) OIDCServer() (string, string, string, string) {
 	return c.m.GetString("oidc.issuer"), c.m.GetString("oidc.client.id"), c.m.GetString("oidc.audience"), c.m.GetString("oidc.claim")
 }
 
+// PrometheusRemoteWrite returns the settings needed to push the metrics to a Prometheus remote write endpoint.
+func (c *Config) PrometheusRemoteWrite() (apiURL string, username string, password string, token string, caCert string, interval time.Duration) {
+	return c.m.GetString("prometheus.remote_write.url"), c.m.GetString("prometheus.remote_write.auth.username"), c.m.GetString("prometheus.remote_write.auth.password"), c.m.GetString("prometheus.remote_write.auth.token"), c.m.GetString("prometheus.remote_write.ca_cert"), time.Duration(c.m.GetInt64("prometheus.remote_write.interval")) * time.Second
+}
+
+// SchedulerExternal returns the URL, the signing secret and the timeout of the external instance placement service.
+func (c *Config) SchedulerExternal() (string, string, time.Duration) {
+	return c.m.GetString("scheduler.external.endpoint"), c.m.GetString("scheduler.external.secret"), time.Duration(c.m.GetInt64("scheduler.external.timeout")) * time.Second
+}
+
 // ClusterHealingThreshold returns the configured healing threshold, i.e. the
 // number of seconds after which an offline node will be evacuated automatically. If the config key
 // is set but its value is lower than cluster.offline_threshold it returns
@@ -268,6 +420,28 @@ func (c *Config) Patch(patch map[string]string) (map[string]string, error) {
 	return c.update(values)
 }
 
+// Preview returns a copy of the configuration with the given keys changed, along with what would change, without
+// persisting anything. The returned configuration can only be read.
+func (c *Config) Preview(patch map[string]string) (*Config, map[string]string, error) {
+	values := c.Dump()
+
+	m, err := config.SafeLoad(ConfigSchema, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for name, value := range patch {
+		values[name] = value
+	}
+
+	changed, err := m.Change(values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Config{m: m}, changed, nil
+}
+
 func (c *Config) update(values map[string]string) (map[string]string, error) {
 	changed, err := c.m.Change(values)
 	if err != nil {
@@ -318,6 +492,42 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Agree to ACME terms of service
 	"acme.agree_tos": {Type: config.Bool, Default: "false"},
 
+	// gendoc:generate(entity=server, group=api, key=api.rate_limit.address)
+	// Rate limit applied to the API requests from each source address, of the form `<requests>/<unit>` where the unit is `s`, `m` or `h` (for example, `100/m`).
+	// Requests from other cluster members and on the local Unix socket aren't limited.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Rate limit of the API requests per source address
+	"api.rate_limit.address": {Validator: validate.Optional(rateLimitValidator)},
+
+	// gendoc:generate(entity=server, group=api, key=api.rate_limit.address.burst)
+	// Number of requests from a source address that can be made at once before the rate limit applies.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: number of requests of `api.rate_limit.address`
+	//  shortdesc: Burst size of the API requests per source address
+	"api.rate_limit.address.burst": {Type: config.Int64, Default: "0", Validator: validate.IsUint32},
+
+	// gendoc:generate(entity=server, group=api, key=api.rate_limit.identity)
+	// Rate limit applied to the API requests of each authenticated identity (client certificate or OpenID Connect user), of the form `<requests>/<unit>` where the unit is `s`, `m` or `h` (for example, `100/m`).
+	// Requests from other cluster members and on the local Unix socket aren't limited.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Rate limit of the API requests per identity
+	"api.rate_limit.identity": {Validator: validate.Optional(rateLimitValidator)},
+
+	// gendoc:generate(entity=server, group=api, key=api.rate_limit.identity.burst)
+	// Number of requests of an identity that can be made at once before the rate limit applies.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: number of requests of `api.rate_limit.identity`
+	//  shortdesc: Burst size of the API requests per identity
+	"api.rate_limit.identity.burst": {Type: config.Int64, Default: "0", Validator: validate.IsUint32},
+
 	// gendoc:generate(entity=server, group=miscellaneous, key=backups.compression_algorithm)
 	// Possible values are `bzip2`, `gzip`, `lzma`, `xz`, or `none`.
 	// ---
@@ -327,6 +537,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Compression algorithm to use for backups
 	"backups.compression_algorithm": {Default: "gzip", Validator: validate.IsCompressionAlgorithm},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=backups.database_count)
+	// When creating a global database backup, the oldest backups of the server beyond that number are removed.
+	// Set to `0` to keep all backups.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `30`
+	//  shortdesc: Number of global database backups to keep on each server
+	"backups.database_count": {Type: config.Int64, Default: "30", Validator: validate.IsUint32},
+
 	// gendoc:generate(entity=server, group=cluster, key=cluster.offline_threshold)
 	// Specify the number of seconds after which an unresponsive member is considered offline.
 	// ---
@@ -346,6 +566,18 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Number of cluster members that replicate an image
 	"cluster.images_minimal_replica": {Type: config.Int64, Default: "3", Validator: imageMinimalReplicaValidator},
 
+	// gendoc:generate(entity=server, group=cluster, key=cluster.images_replication)
+	// Specify how many cluster members keep a copy of each image, either `all` or a number of members.
+	// When set, this takes precedence over {config:option}`server-cluster:cluster.images_minimal_replica`.
+	// Copies are distributed from member to member over the cluster network, each member that received
+	// the image serving it to the next ones.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: value of `cluster.images_minimal_replica`
+	//  shortdesc: Number of cluster members that replicate an image
+	"cluster.images_replication": {Validator: imageReplicationValidator},
+
 	// gendoc:generate(entity=server, group=cluster, key=cluster.healing_threshold)
 	// Specify the number of seconds after which an offline cluster member is to be evacuated.
 	// To disable evacuating offline members, set this option to `0`.
@@ -385,6 +617,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Number of database stand-by members
 	"cluster.max_standby": {Type: config.Int64, Default: "2", Validator: maxStandByValidator},
 
+	// gendoc:generate(entity=server, group=cluster, key=cluster.time_skew_threshold)
+	// Specify the number of seconds the clock of a member can drift from the clock of the leader before a warning is raised.
+	// Members over the threshold refuse the time-sensitive operations, like validating or issuing tokens.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `5`
+	//  shortdesc: Clock drift from the leader after which a member is considered skewed
+	"cluster.time_skew_threshold": {Type: config.Int64, Default: "5", Validator: validate.Optional(validate.IsInRange(1, 3600))},
+
 	// gendoc:generate(entity=server, group=core, key=core.metrics_authentication)
 	//
 	// ---
@@ -394,6 +636,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to enforce authentication on the metrics endpoint
 	"core.metrics_authentication": {Type: config.Bool, Default: "true"},
 
+	// gendoc:generate(entity=server, group=core, key=core.catalog_authentication)
+	// When disabled, the public image aliases and the instance size presets can be listed through
+	// `/1.0/catalog` without authentication.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `true`
+	//  shortdesc: Whether to enforce authentication on the catalog endpoint
+	"core.catalog_authentication": {Type: config.Bool, Default: "true"},
+
 	// gendoc:generate(entity=server, group=core, key=core.bgp_asn)
 	//
 	// ---
@@ -435,8 +687,19 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to set `Access-Control-Allow-Credentials`
 	"core.https_allowed_credentials": {Type: config.Bool, Default: "false"},
 
+	// gendoc:generate(entity=server, group=core, key=core.https_path_prefix)
+	// Specify a URL path prefix (for example, `/incus`) under which the API is also served.
+	// This is useful when the API is exposed by a reverse proxy under a sub-path.
+	// Requests that don't use the prefix keep working.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: URL path prefix of the API
+	"core.https_path_prefix": {Validator: validate.Optional(httpsPathPrefixValidator)},
+
 	// gendoc:generate(entity=server, group=core, key=core.https_trusted_proxy)
-	// Specify a comma-separated list of IP addresses of trusted servers that provide the client's address through the proxy connection header.
+	// Specify a comma-separated list of IP addresses or subnets (in CIDR notation) of trusted servers that provide the client's address through the proxy connection header.
+	// Both version 1 and version 2 of the PROXY protocol are supported.
 	// ---
 	//  type: string
 	//  scope: global
@@ -487,6 +750,43 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: How long to wait before shutdown
 	"core.shutdown_timeout": {Type: config.Int64, Default: "5"},
 
+	// gendoc:generate(entity=server, group=core, key=core.tracing.endpoint)
+	// Specify the OTLP/HTTP endpoint of an OpenTelemetry collector (for example, `http://collector:4318`).
+	// When set, the spans covering the API requests and the internal operations of each server are exported to it.
+	// The spans are posted to `/v1/traces` unless the URL contains a path.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: OpenTelemetry collector to export the traces to
+	"core.tracing.endpoint": {Validator: validate.Optional(webhookURLValidator)},
+
+	// gendoc:generate(entity=server, group=core, key=core.tracing.headers)
+	// Specify a comma-separated list of `<name>=<value>` headers to add to the requests exporting the traces, for example to authenticate with the collector.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Headers of the requests exporting the traces
+	"core.tracing.headers": {Validator: validate.Optional(tracingHeadersValidator)},
+
+	// gendoc:generate(entity=server, group=core, key=core.tracing.ca_cert)
+	// Specify the CA certificate of an `https` collector, in PEM format. The CA certificates of the system are used when not set.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: CA certificate for the OpenTelemetry collector
+	"core.tracing.ca_cert": {},
+
+	// gendoc:generate(entity=server, group=core, key=core.tracing.sample_ratio)
+	// Specify the ratio of the new traces which are sampled, between `0` and `1`.
+	// Requests carrying the trace context of an authenticated client follow the sampling decision of the client instead.
+	// The database transactions are only traced within sampled traces.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `1`
+	//  shortdesc: Ratio of the sampled traces
+	"core.tracing.sample_ratio": {Default: "1", Validator: tracingSampleRatioValidator},
+
 	// gendoc:generate(entity=server, group=core, key=core.trust_ca_certificates)
 	//
 	// ---
@@ -496,6 +796,73 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to automatically trust clients signed by the CA
 	"core.trust_ca_certificates": {Type: config.Bool, Default: "false"},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=events.journal.size)
+	// Specify the maximum size of the on-disk journal of recent events on each server.
+	// Clients can replay the journaled events after reconnecting using the `since` parameter of `/1.0/events`.
+	// Set it to `0` to disable the journal.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `10MiB`
+	//  shortdesc: Maximum size of the event journal
+	"events.journal.size": {Default: "10MiB", Validator: validate.IsSize},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=events.lifecycle.enrichment)
+	// Specify a comma-separated list of `<action>:<field>` rules selecting the instance fields to embed in the `enrichment` field of lifecycle events.
+	// The action is a lifecycle action, optionally ending with a `*` wildcard (for example, `instance-*`).
+	// The field is `labels` for the `user.*` configuration keys, `image` for the image origin, or `config.<key>` for an expanded configuration key, optionally ending with a `*` wildcard.
+	// See {ref}`events-enrichment` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Instance fields to embed in lifecycle events
+	"events.lifecycle.enrichment": {Validator: validate.Optional(validate.IsListOf(events.ValidateLifecycleEnrichmentRule))},
+
+	// gendoc:generate(entity=server, group=webhooks, key=events.webhooks.urls)
+	// Specify a comma-separated list of URLs to send a `POST` request to for every selected lifecycle event.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: URLs to notify about lifecycle events
+	"events.webhooks.urls": {Validator: validate.Optional(validate.IsListOf(webhookURLValidator))},
+
+	// gendoc:generate(entity=server, group=webhooks, key=events.webhooks.actions)
+	// Specify a comma-separated list of lifecycle actions (for example, `instance-created,instance-stopped,instance-backup-created`) to send notifications for.
+	// If empty, notifications are sent for all lifecycle events.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Lifecycle actions to send notifications for
+	"events.webhooks.actions": {Validator: validate.Optional(validate.IsListOf(validate.IsAny))},
+
+	// gendoc:generate(entity=server, group=webhooks, key=events.webhooks.payload)
+	// Specify a Go template used to render the body of the notifications.
+	// The template has access to the fields of the lifecycle event (`.Action`, `.Source`, `.Name`, `.Project`, `.Context`, `.Requestor` and `.Enrichment`) as well as `.Timestamp`, `.Location` and the full `.Event`.
+	// A `json` function is available to encode values.
+	// If empty, the event itself is sent as JSON.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Template for the body of the notifications
+	"events.webhooks.payload": {Validator: validate.Optional(webhookPayloadValidator)},
+
+	// gendoc:generate(entity=server, group=webhooks, key=events.webhooks.secret)
+	// If set, the notifications include an `X-Incus-Signature-256` header holding the HMAC-SHA256 signature of the body using this secret, in the form `sha256=<hex>`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Secret used to sign the notifications
+	"events.webhooks.secret": {},
+
+	// gendoc:generate(entity=server, group=webhooks, key=events.webhooks.retries)
+	// Specify how many times to retry a failed notification, with an exponential backoff starting at one second.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `3`
+	//  shortdesc: Number of retries for failed notifications
+	"events.webhooks.retries": {Type: config.Int64, Default: "3", Validator: validate.Optional(validate.IsInRange(0, 10))},
+
 	// gendoc:generate(entity=server, group=images, key=images.auto_update_cached)
 	//
 	// ---
@@ -515,6 +882,34 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Interval at which to look for updates to cached images
 	"images.auto_update_interval": {Type: config.Int64, Default: "6"},
 
+	// gendoc:generate(entity=server, group=images, key=images.builder.alias)
+	// Alias or fingerprint of the image providing `distrobuilder`, used to build images from Git recipes.
+	// See {ref}`images-create-git` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Image used to build images from Git recipes
+	"images.builder.alias": {},
+
+	// gendoc:generate(entity=server, group=images, key=images.builder.member)
+	// Name of the cluster member building the images from Git recipes.
+	// The other members get the built images from it.
+	// By default, the images are built by the member which needs them.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Cluster member building images from Git recipes
+	"images.builder.member": {},
+
+	// gendoc:generate(entity=server, group=images, key=images.builder.server)
+	// URL of the simple streams server providing {config:option}`server-images:images.builder.alias`.
+	// If not set, a local image is used.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Server providing the image used to build images from Git recipes
+	"images.builder.server": {Validator: validate.Optional(validate.IsRequestURL)},
+
 	// gendoc:generate(entity=server, group=images, key=images.compression_algorithm)
 	// Possible values are `bzip2`, `gzip`, `lzma`, `xz`, or `none`.
 	// ---
@@ -540,6 +935,68 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: When an unused cached remote image is flushed
 	"images.remote_cache_expiry": {Type: config.Int64, Default: "10"},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.idle.window)
+	// Specify the number of hours over which instance activity is measured to detect idle instances.
+	// Running instances whose CPU, network and disk activity stays below the configured thresholds over that window get flagged as idle.
+	// To disable idle instance detection, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Window over which to detect idle instances
+	"instances.idle.window": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsInRange(0, 24*365))},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.idle.cpu_threshold)
+	// Specify the average CPU usage (as a percentage of a single CPU) below which an instance is considered idle.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `1`
+	//  shortdesc: CPU usage threshold for idle instances
+	"instances.idle.cpu_threshold": {Type: config.Int64, Default: "1", Validator: validate.Optional(validate.IsInRange(0, 100))},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.idle.network_threshold)
+	// Specify the amount of network traffic (received and sent) over the whole window below which an instance is considered idle.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `10MiB`
+	//  shortdesc: Network traffic threshold for idle instances
+	"instances.idle.network_threshold": {Default: "10MiB", Validator: validate.IsSize},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.idle.disk_threshold)
+	// Specify the amount of disk I/O (read and written) over the whole window below which an instance is considered idle.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `10MiB`
+	//  shortdesc: Disk I/O threshold for idle instances
+	"instances.idle.disk_threshold": {Default: "10MiB", Validator: validate.IsSize},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.shutdown_checkpoint)
+	// If enabled, running containers with {config:option}`instance-security:security.criu` set to `true` are checkpointed
+	// to disk with CRIU when the daemon shuts down cleanly, and restored when it starts again.
+	// This takes precedence over {config:option}`instance-boot:boot.host_shutdown_action` for those containers.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to checkpoint containers on daemon shutdown
+	"instances.shutdown_checkpoint": {Type: config.Bool, Default: "false"},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.hugepages.dynamic)
+	// If enabled, the kernel pools of huge pages are grown when starting a virtual machine with
+	// {config:option}`instance-resource-limits:limits.memory.hugepages` set to `true` and not enough free pages,
+	// and shrunk again when it stops.
+	// Otherwise, the huge pages must be allocated beforehand and the virtual machine fails to start if not enough are free.
+	// See {ref}`instance-options-limits-hugepages-vm` for more information.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to allocate huge pages as needed
+	"instances.hugepages.dynamic": {Type: config.Bool, Default: "false"},
+
 	// gendoc:generate(entity=server, group=miscellaneous, key=instances.nic.host_name)
 	// Possible values are `random` and `mac`.
 	//
@@ -561,6 +1018,30 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Instance placement scriptlet for automatic instance placement
 	"instances.placement.scriptlet": {Validator: validate.Optional(scriptletLoad.InstancePlacementValidate)},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.recycle_bin.expiry)
+	// Specify how long deleted instances and custom storage volumes are kept in the recycle bin, for example `7d` or `2w`.
+	// Deleted resources can be restored with `incus restore-deleted` until they expire and get purged.
+	// To disable the recycle bin, leave this option empty.
+	// See {ref}`recycle-bin` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: empty (disabled)
+	//  shortdesc: How long deleted instances and custom volumes are kept
+	"instances.recycle_bin.expiry": {Validator: validate.Optional(expiryValidator)},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.recycle_bin.max_size)
+	// Specify the disk usage above which deleted instances and custom storage volumes aren't kept in the recycle bin,
+	// as they would keep using too much space on their storage pool.
+	// They're then deleted right away and a warning is raised on their project.
+	// Set it to `0` to keep resources of any size.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `10GiB`
+	//  shortdesc: Maximum size of the resources kept in the recycle bin
+	"instances.recycle_bin.max_size": {Default: "10GiB", Validator: validate.IsSize},
+
 	// gendoc:generate(entity=server, group=loki, key=loki.auth.username)
 	//
 	// ---
@@ -621,13 +1102,13 @@ var ConfigSchema = config.Schema{
 
 	// gendoc:generate(entity=server, group=loki, key=loki.types)
 	// Specify a comma-separated list of events to send to the Loki server.
-	// The events can be any combination of `lifecycle`, `logging`, and `network-acl`.
+	// The events can be any combination of `lifecycle`, `logging`, `network-acl`, and `instance-log`.
 	// ---
 	//  type: string
 	//  scope: global
 	//  defaultdesc: `lifecycle,logging`
 	//  shortdesc: Events to send to the Loki server
-	"loki.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "logging", "network-acl"))), Default: "lifecycle,logging"},
+	"loki.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "logging", "network-acl", "instance-log"))), Default: "lifecycle,logging"},
 
 	// gendoc:generate(entity=server, group=openfga, key=openfga.api.token)
 	//
@@ -685,6 +1166,17 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: OpenID Connect claim to use as the username
 	"oidc.claim": {},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=network.firewall.strict)
+	// When enabled, Incus doesn't add firewall rules allowing DHCP, DNS, ICMP and forwarded traffic on its bridges
+	// and doesn't change the global forwarding sysctls. The rules and sysctls that the administrator must then allow
+	// are reported by the managed rules of the cluster member.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to refrain from adding broad firewall rules
+	"network.firewall.strict": {Type: config.Bool, Default: "false"},
+
 	// OVN networking global keys.
 
 	// gendoc:generate(entity=server, group=miscellaneous, key=network.ovn.integration_bridge)
@@ -731,6 +1223,114 @@ var ConfigSchema = config.Schema{
 	//  defaultdesc: Content of `/etc/ovn/key_host` if present
 	//  shortdesc: OVN SSL client key
 	"network.ovn.client_key": {Default: ""},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=operations.concurrency.backups)
+	// Specify the maximum number of backup creation and restore operations running at the same time on each server.
+	// Additional operations are queued. Set it to `0` for no limit.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Maximum number of concurrent backup operations
+	"operations.concurrency.backups": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=operations.concurrency.images)
+	// Specify the maximum number of image download and refresh operations running at the same time on each server.
+	// Additional operations are queued. Set it to `0` for no limit.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Maximum number of concurrent image download operations
+	"operations.concurrency.images": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=operations.concurrency.migrations)
+	// Specify the maximum number of instance and volume migration operations running at the same time on each server.
+	// Additional operations are queued. Set it to `0` for no limit.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Maximum number of concurrent migration operations
+	"operations.concurrency.migrations": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
+	// gendoc:generate(entity=server, group=prometheus, key=prometheus.remote_write.url)
+	// Specify the full URL of the remote write endpoint, for example `https://prometheus.example.com/api/v1/write`.
+	// Every server pushes its own metrics, which makes it possible to monitor servers that can't be scraped.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: URL of the Prometheus remote write endpoint
+	"prometheus.remote_write.url": {Validator: validate.Optional(webhookURLValidator)},
+
+	// gendoc:generate(entity=server, group=prometheus, key=prometheus.remote_write.auth.username)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: User name used for Prometheus remote write authentication
+	"prometheus.remote_write.auth.username": {},
+
+	// gendoc:generate(entity=server, group=prometheus, key=prometheus.remote_write.auth.password)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Password used for Prometheus remote write authentication
+	"prometheus.remote_write.auth.password": {},
+
+	// gendoc:generate(entity=server, group=prometheus, key=prometheus.remote_write.auth.token)
+	// If set, the token is sent as a bearer token instead of using the user name and password.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Bearer token used for Prometheus remote write authentication
+	"prometheus.remote_write.auth.token": {},
+
+	// gendoc:generate(entity=server, group=prometheus, key=prometheus.remote_write.ca_cert)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: CA certificate for the Prometheus remote write endpoint
+	"prometheus.remote_write.ca_cert": {},
+
+	// gendoc:generate(entity=server, group=prometheus, key=prometheus.remote_write.interval)
+	// Specify the number of seconds between two pushes of the metrics.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `60`
+	//  shortdesc: Interval between two pushes of the metrics
+	"prometheus.remote_write.interval": {Type: config.Int64, Default: "60", Validator: validate.Optional(validate.IsInRange(10, 3600))},
+
+	// gendoc:generate(entity=server, group=cluster, key=scheduler.external.endpoint)
+	// Specify the URL of an HTTP service to send a `POST` request to when the server needs to pick a cluster member for an instance.
+	// The request holds the instance, its required resources and the candidate members, and the service replies with the name of the member to use.
+	// If the service fails, doesn't reply in time or doesn't pick a member, the built-in placement is used.
+	// See {ref}`clustering-instance-placement-external` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: URL of an external instance placement service
+	"scheduler.external.endpoint": {Validator: validate.Optional(webhookURLValidator)},
+
+	// gendoc:generate(entity=server, group=cluster, key=scheduler.external.secret)
+	// If set, the placement requests include an `X-Incus-Signature-256` header holding the HMAC-SHA256 signature of the body using this secret, in the form `sha256=<hex>`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Secret used to sign the placement requests
+	"scheduler.external.secret": {},
+
+	// gendoc:generate(entity=server, group=cluster, key=scheduler.external.timeout)
+	// Specify the number of seconds to wait for the external placement service before falling back to the built-in placement.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `5`
+	//  shortdesc: Timeout of the external instance placement service
+	"scheduler.external.timeout": {Type: config.Int64, Default: "5", Validator: validate.Optional(validate.IsInRange(1, 300))},
 }
 
 func expiryValidator(value string) error {
@@ -742,6 +1342,63 @@ func expiryValidator(value string) error {
 	return nil
 }
 
+func rateLimitValidator(value string) error {
+	_, _, err := ratelimit.ParseRate(value)
+	return err
+}
+
+func webhookURLValidator(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("Webhook URL must use http or https: %q", value)
+	}
+
+	return nil
+}
+
+func tracingHeadersValidator(value string) error {
+	_, err := tracing.ParseHeaders(value)
+	return err
+}
+
+func tracingSampleRatioValidator(value string) error {
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid sample ratio %q: %w", value, err)
+	}
+
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("Sample ratio must be between 0 and 1")
+	}
+
+	return nil
+}
+
+func httpsPathPrefixValidator(value string) error {
+	if !strings.HasPrefix(value, "/") || value == "/" {
+		return fmt.Errorf("Path prefix must start with a slash and can't be the root")
+	}
+
+	if strings.HasSuffix(value, "/") {
+		return fmt.Errorf("Path prefix can't end with a slash")
+	}
+
+	if strings.ContainsAny(value, "?#") {
+		return fmt.Errorf("Path prefix can't contain a query or fragment")
+	}
+
+	return nil
+}
+
+func webhookPayloadValidator(value string) error {
+	_, err := webhook.ParseTemplate(value)
+	return err
+}
+
 func logLevelValidator(value string) error {
 	if value == "" {
 		return nil
@@ -789,6 +1446,19 @@ func imageMinimalReplicaValidator(value string) error {
 	return nil
 }
 
+func imageReplicationValidator(value string) error {
+	if value == "" || value == "all" {
+		return nil
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 1 {
+		return fmt.Errorf("Image replication must be \"all\" or a positive number of members")
+	}
+
+	return nil
+}
+
 func maxVotersValidator(value string) error {
 	n, err := strconv.Atoi(value)
 	if err != nil {
@@ -3,6 +3,7 @@ package config_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -65,6 +66,32 @@ func TestConfigLoad_OfflineThresholdValidator(t *testing.T) {
 	require.EqualError(t, err, "cannot set 'cluster.offline_threshold' to '2': Value must be greater than '10'")
 }
 
+// Previewing changes validates them without persisting them.
+func TestConfigPreview(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	config, err := clusterConfig.Load(context.Background(), tx)
+	require.NoError(t, err)
+
+	_, err = config.Patch(map[string]string{"core.proxy_http": "foo.bar"})
+	require.NoError(t, err)
+
+	preview, changed, err := config.Preview(map[string]string{"core.proxy_https": "foo.baz"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"core.proxy_https": "foo.baz"}, changed)
+	assert.Equal(t, "foo.bar", preview.ProxyHTTP())
+	assert.Equal(t, "foo.baz", preview.ProxyHTTPS())
+	assert.Equal(t, "", config.ProxyHTTPS())
+
+	values, err := tx.Config(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"core.proxy_http": "foo.bar"}, values)
+
+	_, _, err = config.Preview(map[string]string{"cluster.max_voters": "4"})
+	require.EqualError(t, err, "cannot set 'cluster.max_voters' to '4': Value must be an odd number equal to or higher than 3")
+}
+
 // Max number of voters must be odd.
 func TestConfigLoad_MaxVotersValidator(t *testing.T) {
 	tx, cleanup := db.NewTestClusterTx(t)
@@ -121,3 +148,52 @@ func TestConfig_PatchKeepsValues(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, map[string]string{"core.proxy_http": "foo.bar"}, values)
 }
+
+// The idle thresholds are parsed from the instances.idle.* keys.
+func TestConfig_InstancesIdleThresholds(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	config, err := clusterConfig.Load(context.Background(), tx)
+	require.NoError(t, err)
+
+	// Idle detection is disabled by default.
+	window, cpuThreshold, networkThreshold, diskThreshold := config.InstancesIdleThresholds()
+	assert.Equal(t, time.Duration(0), window)
+	assert.Equal(t, int64(1), cpuThreshold)
+	assert.Equal(t, int64(10*1024*1024), networkThreshold)
+	assert.Equal(t, int64(10*1024*1024), diskThreshold)
+
+	_, err = config.Patch(map[string]string{
+		"instances.idle.window":            "24",
+		"instances.idle.cpu_threshold":     "5",
+		"instances.idle.network_threshold": "1MB",
+		"instances.idle.disk_threshold":    "2GiB",
+	})
+	require.NoError(t, err)
+
+	window, cpuThreshold, networkThreshold, diskThreshold = config.InstancesIdleThresholds()
+	assert.Equal(t, 24*time.Hour, window)
+	assert.Equal(t, int64(5), cpuThreshold)
+	assert.Equal(t, int64(1000*1000), networkThreshold)
+	assert.Equal(t, int64(2*1024*1024*1024), diskThreshold)
+
+	_, err = config.Patch(map[string]string{"instances.idle.cpu_threshold": "101"})
+	assert.Error(t, err)
+}
+
+// Checkpointing containers on shutdown is opt-in.
+func TestConfig_InstancesShutdownCheckpoint(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	config, err := clusterConfig.Load(context.Background(), tx)
+	require.NoError(t, err)
+
+	assert.False(t, config.InstancesShutdownCheckpoint())
+
+	_, err = config.Patch(map[string]string{"instances.shutdown_checkpoint": "true"})
+	require.NoError(t, err)
+
+	assert.True(t, config.InstancesShutdownCheckpoint())
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/internal/server/tracing"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/proxy"
@@ -63,10 +64,18 @@ func Connect(address string, networkCert *localtls.CertInfo, serverCert *localtl
 			val, ok = ctx.Value(request.CtxProtocol).(string)
 			if ok {
 				req.Header.Add(request.HeaderForwardedProtocol, val)
+
+				// The member the request is forwarded to checks the instance share again.
+				if val == "share" {
+					req.Header.Add("X-Incus-share", r.Header.Get("X-Incus-share"))
+				}
 			}
 
 			req.Header.Add(request.HeaderForwardedAddress, r.RemoteAddr)
 
+			// Attach the forwarded request to the trace of the original one.
+			tracing.Inject(ctx, req.Header)
+
 			return proxy.FromEnvironment(req)
 		}
 
@@ -86,6 +95,11 @@ func ConnectIfInstanceIsRemote(s *state.State, projectName string, instName stri
 		return nil, nil
 	}
 
+	// Disconnected edge members only serve their own instances.
+	if s.Edge.Disconnected() {
+		return nil, nil
+	}
+
 	var address string // Cluster member address.
 	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
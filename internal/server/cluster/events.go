@@ -67,8 +67,8 @@ func (lc *eventListenerClient) SetEventMode(eventMode EventMode, eventHubPushCh
 		go func() {
 			lc.hubPushCancel = cancel
 			info, _ := lc.client.GetConnectionInfo()
-			logger.Info("Event hub client started", logger.Ctx{"remote": info.URL})
-			defer logger.Info("Event hub client stopped", logger.Ctx{"remote": info.URL})
+			clusterLogger.Info("Event hub client started", logger.Ctx{"remote": info.URL})
+			defer clusterLogger.Info("Event hub client stopped", logger.Ctx{"remote": info.URL})
 			defer func() {
 				cancel()
 				lc.hubPushCancel = nil
@@ -240,7 +240,7 @@ func EventsUpdateListeners(endpoints *endpoints.Endpoints, cluster *db.Cluster,
 			return nil
 		})
 		if err != nil {
-			logger.Warn("Failed to get current cluster members", logger.Ctx{"err": err})
+			clusterLogger.Warn("Failed to get current cluster members", logger.Ctx{"err": err})
 			return
 		}
 
@@ -292,7 +292,7 @@ func EventsUpdateListeners(endpoints *endpoints.Endpoints, cluster *db.Cluster,
 			listenersLock.Unlock()
 
 			// Log after releasing listenersLock to avoid deadlock on listenersLock with EventHubPush.
-			logger.Info("Removed inactive member event listener client", logger.Ctx{"local": localAddress, "remote": hbMember.Address})
+			clusterLogger.Info("Removed inactive member event listener client", logger.Ctx{"local": localAddress, "remote": hbMember.Address})
 		} else {
 			listenersLock.Unlock()
 		}
@@ -302,7 +302,7 @@ func EventsUpdateListeners(endpoints *endpoints.Endpoints, cluster *db.Cluster,
 		// Connect to remote concurrently and add to active listeners if successful.
 		wg.Add(1)
 		go func(m APIHeartbeatMember) {
-			l := logger.AddContext(logger.Ctx{"local": localAddress, "remote": m.Address})
+			l := clusterLogger.AddContext(logger.Ctx{"local": localAddress, "remote": m.Address})
 
 			defer wg.Done()
 			listener, err := eventsConnect(m.Address, endpoints.NetworkCert(), serverCert())
@@ -364,11 +364,11 @@ func EventsUpdateListeners(endpoints *endpoints.Endpoints, cluster *db.Cluster,
 
 	// Log the listeners removed after releasing listenersLock.
 	for _, removedAddress := range removedAddresses {
-		logger.Info("Removed old member event listener client", logger.Ctx{"local": localAddress, "remote": removedAddress})
+		clusterLogger.Info("Removed old member event listener client", logger.Ctx{"local": localAddress, "remote": removedAddress})
 	}
 
 	if len(hbMembers) > 1 && len(keepListeners) <= 0 {
-		logger.Error("No active cluster event listener clients", logger.Ctx{"local": localAddress})
+		clusterLogger.Error("No active cluster event listener clients", logger.Ctx{"local": localAddress})
 	}
 }
 
@@ -32,6 +32,9 @@ import (
 	"github.com/lxc/incus/v6/shared/util"
 )
 
+// clusterLogger is the logger of the clustering subsystem.
+var clusterLogger = logger.Subsystem("cluster")
+
 // NewGateway creates a new Gateway for managing access to the dqlite cluster.
 //
 // When a new gateway is created, the node-level database is queried to check
@@ -200,7 +203,7 @@ func (g *Gateway) HandlerFuncs(heartbeatHandler HeartbeatHandler, trustedCerts f
 		// Handle heatbeats (these normally come from leader, but can come from joining nodes too).
 		if r.Method == "PUT" {
 			if g.shutdownCtx.Err() != nil {
-				logger.Warn("Rejecting heartbeat request as shutting down")
+				clusterLogger.Warn("Rejecting heartbeat request as shutting down")
 				http.Error(w, "503 Shutting down", http.StatusServiceUnavailable)
 				return
 			}
@@ -208,7 +211,7 @@ func (g *Gateway) HandlerFuncs(heartbeatHandler HeartbeatHandler, trustedCerts f
 			var heartbeatData APIHeartbeat
 			err := json.NewDecoder(r.Body).Decode(&heartbeatData)
 			if err != nil {
-				logger.Error("Failed decoding heartbeat", logger.Ctx{"err": err})
+				clusterLogger.Error("Failed decoding heartbeat", logger.Ctx{"err": err})
 				http.Error(w, "400 Failed decoding heartbeat", http.StatusBadRequest)
 				return
 			}
@@ -217,16 +220,19 @@ func (g *Gateway) HandlerFuncs(heartbeatHandler HeartbeatHandler, trustedCerts f
 			isLeader, err := g.isLeader()
 			g.lock.RUnlock()
 			if err != nil {
-				logger.Error("Failed checking if leader", logger.Ctx{"err": err})
+				clusterLogger.Error("Failed checking if leader", logger.Ctx{"err": err})
 				http.Error(w, "500 Failed checking if leader", http.StatusInternalServerError)
 				return
 			}
 
 			if heartbeatHandler == nil {
-				logger.Error("No heartbeat handler", logger.Ctx{"err": err})
+				clusterLogger.Error("No heartbeat handler", logger.Ctx{"err": err})
 				return
 			}
 
+			// Report the local time, for the leader to measure the clock skew.
+			w.Header().Set(HeartbeatTimeHeader, time.Now().UTC().Format(time.RFC3339Nano))
+
 			heartbeatHandler(w, r, isLeader, &heartbeatData)
 
 			return
@@ -450,7 +456,7 @@ func (g *Gateway) NodeStore() client.NodeStore {
 // connection with the dialer (typically for running some pre-shutdown
 // queries).
 func (g *Gateway) Kill() {
-	logger.Debug("Cancel ongoing or future gRPC connection attempts")
+	clusterLogger.Debug("Cancel ongoing or future gRPC connection attempts")
 	g.cancel()
 }
 
@@ -518,7 +524,7 @@ func (g *Gateway) DemoteOfflineNode(raftID uint64) error {
 
 // Shutdown this gateway, stopping the gRPC server and possibly the raft factory.
 func (g *Gateway) Shutdown() error {
-	logger.Infof("Stop database gateway")
+	clusterLogger.Infof("Stop database gateway")
 
 	var err error
 	if g.server != nil {
@@ -553,7 +559,7 @@ func (g *Gateway) Sync() {
 
 	client, err := g.getClient()
 	if err != nil {
-		logger.Warnf("Failed to get client: %v", err)
+		clusterLogger.Warnf("Failed to get client: %v", err)
 		return
 	}
 
@@ -562,7 +568,7 @@ func (g *Gateway) Sync() {
 	files, err := client.Dump(context.Background(), "db.bin")
 	if err != nil {
 		// Just log a warning, since this is not fatal.
-		logger.Warnf("Failed get database dump: %v", err)
+		clusterLogger.Warnf("Failed get database dump: %v", err)
 		return
 	}
 
@@ -571,7 +577,7 @@ func (g *Gateway) Sync() {
 		path := filepath.Join(dir, file.Name)
 		err := os.WriteFile(path, file.Data, 0600)
 		if err != nil {
-			logger.Warnf("Failed to dump database file %s: %v", file.Name, err)
+			clusterLogger.Warnf("Failed to dump database file %s: %v", file.Name, err)
 		}
 	}
 }
@@ -712,25 +718,25 @@ func (g *Gateway) LeaderAddress() (string, error) {
 		request = request.WithContext(ctx)
 		response, err := client.Do(request)
 		if err != nil {
-			logger.Debugf("Failed to fetch leader address from %s", address)
+			clusterLogger.Debugf("Failed to fetch leader address from %s", address)
 			continue
 		}
 
 		if response.StatusCode != http.StatusOK {
-			logger.Debugf("Request for leader address from %s failed", address)
+			clusterLogger.Debugf("Request for leader address from %s failed", address)
 			continue
 		}
 
 		info := map[string]string{}
 		err = json.NewDecoder(response.Body).Decode(&info)
 		if err != nil {
-			logger.Debugf("Failed to parse leader address from %s", address)
+			clusterLogger.Debugf("Failed to parse leader address from %s", address)
 			continue
 		}
 
 		leader := info["leader"]
 		if leader == "" {
-			logger.Debugf("Raft node %s returned no leader address", address)
+			clusterLogger.Debugf("Raft node %s returned no leader address", address)
 			continue
 		}
 
@@ -754,7 +760,7 @@ func (g *Gateway) NetworkUpdateCert(cert *localtls.CertInfo) {
 // @bootstrap should only be true when turning a non-clustered server into
 // the first (and leader) member of a new cluster.
 func (g *Gateway) init(bootstrap bool) error {
-	logger.Debugf("Initializing database gateway")
+	clusterLogger.Debugf("Initializing database gateway")
 	g.stopCh = make(chan struct{})
 
 	info, err := loadInfo(g.db, g.networkCert)
@@ -816,7 +822,7 @@ func (g *Gateway) init(bootstrap bool) error {
 		// when the raft node already has log entries, in which case a regular
 		// bootstrap fails, resulting in the node containing outdated configuration.
 		if bootstrap {
-			logger.Debugf("Bootstrap database gateway ID:%v Address:%v",
+			clusterLogger.Debugf("Bootstrap database gateway ID:%v Address:%v",
 				info.ID, info.Address)
 			cluster := []dqlite.NodeInfo{
 				{ID: uint64(info.ID), Address: info.Address},
@@ -963,7 +969,7 @@ func (g *Gateway) currentRaftNodes() ([]db.RaftNode, error) {
 			for i, server := range servers {
 				member, found := membersByAddress[server.Address]
 				if !found {
-					logger.Warn("Cluster member info not found", logger.Ctx{"address": server.Address})
+					clusterLogger.Warn("Cluster member info not found", logger.Ctx{"address": server.Address})
 				}
 
 				raftNodes[i].Name = member.Name
@@ -972,7 +978,7 @@ func (g *Gateway) currentRaftNodes() ([]db.RaftNode, error) {
 			return nil
 		})
 		if err != nil {
-			logger.Warn("Failed getting raft nodes", logger.Ctx{"err": err})
+			clusterLogger.Warn("Failed getting raft nodes", logger.Ctx{"err": err})
 		}
 	}
 
@@ -1049,7 +1055,7 @@ func dqliteNetworkDial(ctx context.Context, name string, addr string, g *Gateway
 
 	revert.Add(func() { _ = conn.Close() })
 
-	l := logger.AddContext(logger.Ctx{"name": name, "local": conn.LocalAddr(), "remote": conn.RemoteAddr()})
+	l := clusterLogger.AddContext(logger.Ctx{"name": name, "local": conn.LocalAddr(), "remote": conn.RemoteAddr()})
 	l.Info("Dqlite connected outbound")
 
 	remoteTCP, err := tcp.ExtractConn(conn)
@@ -1114,13 +1120,13 @@ func DqliteLog(l client.LogLevel, format string, a ...any) {
 	format = fmt.Sprintf("Dqlite: %s", format)
 	switch l {
 	case client.LogDebug:
-		logger.Debugf(format, a...)
+		clusterLogger.Debugf(format, a...)
 	case client.LogInfo:
-		logger.Debugf(format, a...)
+		clusterLogger.Debugf(format, a...)
 	case client.LogWarn:
-		logger.Warnf(format, a...)
+		clusterLogger.Warnf(format, a...)
 	case client.LogError:
-		logger.Errorf(format, a...)
+		clusterLogger.Errorf(format, a...)
 	}
 }
 
@@ -1141,7 +1147,7 @@ func runDqliteProxy(stopCh chan struct{}, bindAddress string, acceptCh chan net.
 // Copies data between a remote TLS network connection and a local unix socket.
 // Accepts name argument that can be used to identify the connection in the logs.
 func dqliteProxy(name string, stopCh chan struct{}, remote net.Conn, local net.Conn) {
-	l := logger.AddContext(logger.Ctx{"name": name, "local": remote.LocalAddr(), "remote": remote.RemoteAddr()})
+	l := clusterLogger.AddContext(logger.Ctx{"name": name, "local": remote.LocalAddr(), "remote": remote.RemoteAddr()})
 	l.Info("Dqlite proxy started")
 	defer l.Info("Dqlite proxy stopped")
 
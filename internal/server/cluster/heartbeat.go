@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -42,8 +43,12 @@ type APIHeartbeatMember struct {
 	Online        bool             // Calculated from offline threshold and LastHeatbeat time.
 	Roles         []db.ClusterRole // Supplementary non-database roles the member has.
 	updated       bool             // Has node been updated during this heartbeat run. Not sent to nodes.
+	clockSkew     *time.Duration   // Clock skew of the node measured during this heartbeat run. Not sent to nodes.
 }
 
+// HeartbeatTimeHeader is the header of the heartbeat responses holding the time of the responding member.
+const HeartbeatTimeHeader = "X-Incus-Time"
+
 // APIHeartbeatVersion contains max versions for all nodes in cluster.
 type APIHeartbeatVersion struct {
 	Schema        int
@@ -130,7 +135,7 @@ func (hbState *APIHeartbeat) Update(fullStateList bool, raftNodes []db.RaftNode,
 			for addr, raftNode := range raftNodeMap {
 				_, err := tx.GetPendingNodeByAddress(ctx, addr)
 				if err != nil {
-					logger.Errorf("Unaccounted raft node(s) not found in 'nodes' table for heartbeat: %+v", raftNode)
+					clusterLogger.Errorf("Unaccounted raft node(s) not found in 'nodes' table for heartbeat: %+v", raftNode)
 				}
 			}
 
@@ -162,7 +167,7 @@ func (hbState *APIHeartbeat) Send(ctx context.Context, networkCert *localtls.Cer
 		heartbeatData.Time = time.Now().UTC()
 
 		// Don't use ctx here, as we still want to finish off the request if the ctx has been cancelled.
-		err := HeartbeatNode(context.Background(), address, networkCert, serverCert, heartbeatData)
+		clockSkew, err := heartbeatNode(context.Background(), address, networkCert, serverCert, heartbeatData)
 		if err == nil {
 			heartbeatData.Lock()
 			// Ensure only update nodes that exist in Members already.
@@ -174,23 +179,24 @@ func (hbState *APIHeartbeat) Send(ctx context.Context, networkCert *localtls.Cer
 			hbNode.LastHeartbeat = time.Now()
 			hbNode.Online = true
 			hbNode.updated = true
+			hbNode.clockSkew = clockSkew
 			heartbeatData.Members[nodeID] = hbNode
 			heartbeatData.Unlock()
-			logger.Debug("Successful heartbeat", logger.Ctx{"remote": address})
+			clusterLogger.Debug("Successful heartbeat", logger.Ctx{"remote": address})
 
 			err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(hbState.cluster, "", warningtype.OfflineClusterMember, cluster.TypeNode, int(nodeID))
 			if err != nil {
-				logger.Warn("Failed to resolve warning", logger.Ctx{"err": err})
+				clusterLogger.Warn("Failed to resolve warning", logger.Ctx{"err": err})
 			}
 		} else {
-			logger.Warn("Failed heartbeat", logger.Ctx{"remote": address, "err": err})
+			clusterLogger.Warn("Failed heartbeat", logger.Ctx{"remote": address, "err": err})
 
 			if ctx.Err() == nil {
 				err = hbState.cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 					return tx.UpsertWarningLocalNode(ctx, "", cluster.TypeNode, int(nodeID), warningtype.OfflineClusterMember, err.Error())
 				})
 				if err != nil {
-					logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+					clusterLogger.Warn("Failed to create warning", logger.Ctx{"err": err})
 				}
 			}
 		}
@@ -316,7 +322,7 @@ func (g *Gateway) heartbeat(ctx context.Context, mode heartbeatMode) {
 			return
 		}
 
-		logger.Error("Failed to get current raft members", logger.Ctx{"err": err})
+		clusterLogger.Error("Failed to get current raft members", logger.Ctx{"err": err})
 		return
 	}
 
@@ -338,7 +344,7 @@ func (g *Gateway) heartbeat(ctx context.Context, mode heartbeatMode) {
 		return nil
 	})
 	if err != nil {
-		logger.Warn("Failed to get current cluster members", logger.Ctx{"err": err})
+		clusterLogger.Warn("Failed to get current cluster members", logger.Ctx{"err": err})
 		return
 	}
 
@@ -352,27 +358,27 @@ func (g *Gateway) heartbeat(ctx context.Context, mode heartbeatMode) {
 
 	if mode != hearbeatNormal {
 		// Log unscheduled heartbeats with a higher level than normal heartbeats.
-		logger.Info("Starting heartbeat round", logger.Ctx{"mode": modeStr, "local": localClusterAddress})
+		clusterLogger.Info("Starting heartbeat round", logger.Ctx{"mode": modeStr, "local": localClusterAddress})
 	} else {
 		// Don't spam the normal log with regular heartbeat messages.
-		logger.Debug("Starting heartbeat round", logger.Ctx{"mode": modeStr, "local": localClusterAddress})
+		clusterLogger.Debug("Starting heartbeat round", logger.Ctx{"mode": modeStr, "local": localClusterAddress})
 	}
 
 	// Replace the local raft_nodes table immediately because it
 	// might miss a row containing ourselves, since we might have
 	// been elected leader before the former leader had chance to
 	// send us a fresh update through the heartbeat pool.
-	logger.Debug("Heartbeat updating local raft members", logger.Ctx{"members": raftNodes})
+	clusterLogger.Debug("Heartbeat updating local raft members", logger.Ctx{"members": raftNodes})
 	err = g.db.Transaction(context.TODO(), func(ctx context.Context, tx *db.NodeTx) error {
 		return tx.ReplaceRaftNodes(raftNodes)
 	})
 	if err != nil {
-		logger.Warn("Failed to replace local raft members", logger.Ctx{"err": err, "mode": modeStr, "local": localClusterAddress})
+		clusterLogger.Warn("Failed to replace local raft members", logger.Ctx{"err": err, "mode": modeStr, "local": localClusterAddress})
 		return
 	}
 
 	if localClusterAddress == "" {
-		logger.Error("No local address set, aborting heartbeat round", logger.Ctx{"mode": modeStr})
+		clusterLogger.Error("No local address set, aborting heartbeat round", logger.Ctx{"mode": modeStr})
 		return
 	}
 
@@ -423,7 +429,7 @@ func (g *Gateway) heartbeat(ctx context.Context, mode heartbeatMode) {
 			return nil
 		})
 		if err != nil {
-			logger.Warn("Failed to get current cluster members", logger.Ctx{"err": err, "mode": modeStr, "local": localClusterAddress})
+			clusterLogger.Warn("Failed to get current cluster members", logger.Ctx{"err": err, "mode": modeStr, "local": localClusterAddress})
 			return
 		}
 
@@ -481,13 +487,13 @@ func (g *Gateway) heartbeat(ctx context.Context, mode heartbeatMode) {
 		})
 	})
 	if err != nil {
-		logger.Error("Failed updating cluster heartbeats", logger.Ctx{"err": err})
+		clusterLogger.Error("Failed updating cluster heartbeats", logger.Ctx{"err": err})
 		return
 	}
 
 	// If the context has been cancelled, return prematurely after saving the members we did manage to ping.
 	if ctxErr != nil {
-		logger.Warn("Aborting heartbeat round", logger.Ctx{"err": ctxErr, "mode": modeStr, "local": localClusterAddress})
+		clusterLogger.Warn("Aborting heartbeat round", logger.Ctx{"err": ctxErr, "mode": modeStr, "local": localClusterAddress})
 		return
 	}
 
@@ -498,25 +504,65 @@ func (g *Gateway) heartbeat(ctx context.Context, mode heartbeatMode) {
 
 	duration := time.Since(startTime)
 	if duration > heartbeatInterval {
-		logger.Warn("Heartbeat round duration greater than heartbeat interval", logger.Ctx{"duration": duration, "interval": heartbeatInterval})
+		clusterLogger.Warn("Heartbeat round duration greater than heartbeat interval", logger.Ctx{"duration": duration, "interval": heartbeatInterval})
 	}
 
 	if mode != hearbeatNormal {
 		// Log unscheduled heartbeats with a higher level than normal heartbeats.
-		logger.Info("Completed heartbeat round", logger.Ctx{"duration": duration, "local": localClusterAddress})
+		clusterLogger.Info("Completed heartbeat round", logger.Ctx{"duration": duration, "local": localClusterAddress})
 	} else {
 		// Don't spam the normal log with regular heartbeat messages.
-		logger.Debug("Completed heartbeat round", logger.Ctx{"duration": duration, "local": localClusterAddress})
+		clusterLogger.Debug("Completed heartbeat round", logger.Ctx{"duration": duration, "local": localClusterAddress})
 	}
 }
 
+// ClockSkew returns the clock skew of the local member from the other members which responded to the last
+// heartbeat run, as the median of their clock skews from the local member.
+func (hbState *APIHeartbeat) ClockSkew() (time.Duration, bool) {
+	hbState.Lock()
+	skews := []time.Duration{}
+	for _, member := range hbState.Members {
+		if member.updated && member.clockSkew != nil {
+			skews = append(skews, *member.clockSkew)
+		}
+	}
+
+	hbState.Unlock()
+
+	if len(skews) == 0 {
+		return 0, false
+	}
+
+	return -clockSkewMedian(skews), true
+}
+
+// clockSkewMedian returns the median of the given clock skews.
+func clockSkewMedian(skews []time.Duration) time.Duration {
+	sort.Slice(skews, func(i, j int) bool { return skews[i] < skews[j] })
+
+	middle := len(skews) / 2
+	if len(skews)%2 == 0 {
+		return (skews[middle-1] + skews[middle]) / 2
+	}
+
+	return skews[middle]
+}
+
 // HeartbeatNode performs a single heartbeat request against the node with the given address.
 func HeartbeatNode(taskCtx context.Context, address string, networkCert *localtls.CertInfo, serverCert *localtls.CertInfo, heartbeatData *APIHeartbeat) error {
-	logger.Debug("Sending heartbeat request", logger.Ctx{"address": address})
+	_, err := heartbeatNode(taskCtx, address, networkCert, serverCert, heartbeatData)
+
+	return err
+}
+
+// heartbeatNode performs a single heartbeat request against the node with the given address.
+// It returns the clock skew of the node from the local member, if the node reported its time.
+func heartbeatNode(taskCtx context.Context, address string, networkCert *localtls.CertInfo, serverCert *localtls.CertInfo, heartbeatData *APIHeartbeat) (*time.Duration, error) {
+	clusterLogger.Debug("Sending heartbeat request", logger.Ctx{"address": address})
 
 	config, err := tlsClientConfig(networkCert, serverCert)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	timeout := 2 * time.Second
@@ -533,12 +579,12 @@ func HeartbeatNode(taskCtx context.Context, address string, networkCert *localtl
 	err = json.NewEncoder(&buffer).Encode(heartbeatData)
 	heartbeatData.Unlock()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	request, err := http.NewRequest("PUT", url, bytes.NewReader(buffer.Bytes()))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	setDqliteVersionHeader(request)
@@ -549,16 +595,26 @@ func HeartbeatNode(taskCtx context.Context, address string, networkCert *localtl
 	request = request.WithContext(ctx)
 	request.Close = true // Immediately close the connection after the request is done
 
+	start := time.Now()
 	response, err := client.Do(request)
 	if err != nil {
-		return fmt.Errorf("Failed to send heartbeat request: %w", err)
+		return nil, fmt.Errorf("Failed to send heartbeat request: %w", err)
 	}
 
 	defer func() { _ = response.Body.Close() }()
 
 	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("Heartbeat request failed with status: %w", api.StatusErrorf(response.StatusCode, response.Status))
+		return nil, fmt.Errorf("Heartbeat request failed with status: %w", api.StatusErrorf(response.StatusCode, response.Status))
 	}
 
-	return nil
+	// Members which don't report their time can't be measured.
+	memberTime, err := time.Parse(time.RFC3339Nano, response.Header.Get(HeartbeatTimeHeader))
+	if err != nil {
+		return nil, nil
+	}
+
+	// Assume the member handled the request half way through the round trip.
+	clockSkew := memberTime.Sub(start.Add(time.Since(start) / 2))
+
+	return &clockSkew, nil
 }
@@ -0,0 +1,4 @@
+package cluster
+
+// ClockSkewMedian returns the median of the given clock skews.
+var ClockSkewMedian = clockSkewMedian
@@ -272,3 +272,10 @@ func (f *heartbeatFixture) Cleanup() {
 		server.Close()
 	}
 }
+
+// The clock skew of a member from the cluster is the median of its clock skews from the other members.
+func TestClockSkewMedian(t *testing.T) {
+	assert.Equal(t, 2*time.Second, cluster.ClockSkewMedian([]time.Duration{10 * time.Second, -time.Second, 2 * time.Second}))
+	assert.Equal(t, 1500*time.Millisecond, cluster.ClockSkewMedian([]time.Duration{2 * time.Second, time.Second}))
+	assert.Equal(t, -time.Second, cluster.ClockSkewMedian([]time.Duration{-time.Second}))
+}
@@ -35,7 +35,7 @@ func loadInfo(database *db.Node, cert *localtls.CertInfo) (*db.RaftNode, error)
 		info.Address = "1"
 	}
 
-	logger.Info("Starting database node", logger.Ctx{"id": info.ID, "local": info.Address, "role": info.Role})
+	clusterLogger.Info("Starting database node", logger.Ctx{"id": info.ID, "local": info.Address, "role": info.Role})
 
 	// Data directory
 	dir := filepath.Join(database.Dir(), "global")
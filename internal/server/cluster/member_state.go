@@ -54,7 +54,7 @@ func MemberState(ctx context.Context, s *state.State, memberName string) (*api.C
 	info := unix.Sysinfo_t{}
 	err = unix.Sysinfo(&info)
 	if err != nil {
-		logger.Warn("Failed getting sysinfo", logger.Ctx{"err": err})
+		clusterLogger.Warn("Failed getting sysinfo", logger.Ctx{"err": err})
 
 		return nil, err
 	}
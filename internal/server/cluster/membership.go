@@ -421,7 +421,7 @@ func Join(state *state.State, gateway *Gateway, networkCert *localtls.CertInfo,
 		panic("Joining member not found")
 	}
 
-	logger.Info("Joining dqlite raft cluster", logger.Ctx{"id": info.ID, "local": info.Address, "role": info.Role})
+	clusterLogger.Info("Joining dqlite raft cluster", logger.Ctx{"id": info.ID, "local": info.Address, "role": info.Role})
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 	client, err := client.FindLeader(
@@ -435,7 +435,7 @@ func Join(state *state.State, gateway *Gateway, networkCert *localtls.CertInfo,
 
 	defer func() { _ = client.Close() }()
 
-	logger.Info("Adding node to cluster", logger.Ctx{"id": info.ID, "local": info.Address, "role": info.Role})
+	clusterLogger.Info("Adding node to cluster", logger.Ctx{"id": info.ID, "local": info.Address, "role": info.Role})
 	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
@@ -467,7 +467,7 @@ func Join(state *state.State, gateway *Gateway, networkCert *localtls.CertInfo,
 	// connection, so new queries will be executed over the new gRPC
 	// network connection. Also, update the storage_pools and networks
 	// tables with our local configuration.
-	logger.Info("Migrate local data to cluster database")
+	clusterLogger.Info("Migrate local data to cluster database")
 	err = state.DB.Cluster.ExitExclusive(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		node, err := tx.GetPendingNodeByAddress(ctx, localClusterAddress)
 		if err != nil {
@@ -618,7 +618,7 @@ func NotifyHeartbeat(state *state.State, gateway *Gateway) {
 		return nil
 	})
 	if err != nil {
-		logger.Warn("Failed to get current raft members", logger.Ctx{"err": err, "local": localClusterAddress})
+		clusterLogger.Warn("Failed to get current raft members", logger.Ctx{"err": err, "local": localClusterAddress})
 		return
 	}
 
@@ -632,7 +632,7 @@ func NotifyHeartbeat(state *state.State, gateway *Gateway) {
 		return nil
 	})
 	if err != nil {
-		logger.Warn("Failed to get current cluster members", logger.Ctx{"err": err, "local": localClusterAddress})
+		clusterLogger.Warn("Failed to get current cluster members", logger.Ctx{"err": err, "local": localClusterAddress})
 		return
 	}
 
@@ -649,7 +649,7 @@ func NotifyHeartbeat(state *state.State, gateway *Gateway) {
 	}()
 
 	// Notify all other members of the change in membership.
-	logger.Info("Sending member change notification heartbeat to all members", logger.Ctx{"local": localClusterAddress})
+	clusterLogger.Info("Sending member change notification heartbeat to all members", logger.Ctx{"local": localClusterAddress})
 	for _, member := range members {
 		if member.Address == localClusterAddress {
 			continue
@@ -699,7 +699,7 @@ func Rebalance(state *state.State, gateway *Gateway, unavailableMembers []string
 
 	// Check if we have a spare node that we can promote to the missing role.
 	candidateAddress := candidates[0].Address
-	logger.Info("Found cluster member whose role needs to be changed", logger.Ctx{"candidateAddress": candidateAddress, "newRole": role, "local": localClusterAddress})
+	clusterLogger.Info("Found cluster member whose role needs to be changed", logger.Ctx{"candidateAddress": candidateAddress, "newRole": role, "local": localClusterAddress})
 
 	for i, node := range nodes {
 		if node.Address == candidateAddress {
@@ -801,7 +801,7 @@ func Assign(state *state.State, gateway *Gateway, nodes []db.RaftNode) error {
 	}
 
 assign:
-	logger.Info("Changing local dqlite raft role", logger.Ctx{"id": info.ID, "local": info.Address, "role": info.Role})
+	clusterLogger.Info("Changing local dqlite raft role", logger.Ctx{"id": info.ID, "local": info.Address, "role": info.Role})
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
@@ -914,7 +914,7 @@ assign:
 //
 // This function must be called by the cluster leader.
 func Leave(state *state.State, gateway *Gateway, name string, force bool) (string, error) {
-	logger.Debugf("Make node %s leave the cluster", name)
+	clusterLogger.Debugf("Make node %s leave the cluster", name)
 
 	// Check if the node can be deleted and track its address.
 	var address string
@@ -959,7 +959,7 @@ func Leave(state *state.State, gateway *Gateway, name string, force bool) (strin
 	}
 
 	// Get the address of another database node,
-	logger.Info(
+	clusterLogger.Info(
 		"Remove node from dqlite raft cluster",
 		logger.Ctx{"id": info.ID, "address": info.Address})
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -1064,7 +1064,7 @@ func newRolesChanges(state *state.State, gateway *Gateway, nodes []db.RaftNode,
 
 // Purge removes a node entirely from the cluster database.
 func Purge(c *db.Cluster, name string) error {
-	logger.Debugf("Remove node %s from the database", name)
+	clusterLogger.Debugf("Remove node %s from the database", name)
 
 	return c.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		// Get the node (if it doesn't exists an error is returned).
@@ -9,7 +9,6 @@ import (
 	"github.com/lxc/incus/v6/client"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/state"
-	"github.com/lxc/incus/v6/shared/logger"
 	localtls "github.com/lxc/incus/v6/shared/tls"
 )
 
@@ -89,7 +88,7 @@ func NewNotifier(state *state.State, networkCert *localtls.CertInfo, serverCert
 		wg := sync.WaitGroup{}
 		wg.Add(len(peers))
 		for i, address := range peers {
-			logger.Debugf("Notify node %s of state changes", address)
+			clusterLogger.Debugf("Notify node %s of state changes", address)
 			go func(i int, address string) {
 				defer wg.Done()
 				client, err := Connect(address, networkCert, serverCert, nil, true)
@@ -110,7 +109,7 @@ func NewNotifier(state *state.State, networkCert *localtls.CertInfo, serverCert
 		for i, err := range errs {
 			if err != nil {
 				if localtls.IsConnectionError(err) && policy == NotifyAlive {
-					logger.Warnf("Could not notify node %s", peers[i])
+					clusterLogger.Warnf("Could not notify node %s", peers[i])
 					continue
 				}
 
@@ -9,7 +9,6 @@ import (
 
 	"github.com/lxc/incus/v6/internal/server/certificate"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
-	"github.com/lxc/incus/v6/shared/logger"
 	localtls "github.com/lxc/incus/v6/shared/tls"
 )
 
@@ -82,7 +81,7 @@ func tlsCheckCert(r *http.Request, networkCert *localtls.CertInfo, serverCert *l
 			return true
 		}
 
-		logger.Errorf("Invalid client certificate %v (%v) from %v", i.Subject, localtls.CertFingerprint(i), r.RemoteAddr)
+		clusterLogger.Errorf("Invalid client certificate %v (%v) from %v", i.Subject, localtls.CertFingerprint(i), r.RemoteAddr)
 	}
 
 	return false
@@ -94,7 +94,7 @@ func MaybeUpdate(state *state.State) error {
 	}
 
 	if !shouldUpdate {
-		logger.Debugf("Cluster node is up-to-date")
+		clusterLogger.Debugf("Cluster node is up-to-date")
 		return nil
 	}
 
@@ -102,11 +102,11 @@ func MaybeUpdate(state *state.State) error {
 }
 
 func triggerUpdate() error {
-	logger.Warn("Member is out-of-date with respect to other cluster members")
+	clusterLogger.Warn("Member is out-of-date with respect to other cluster members")
 
 	updateExecutable := os.Getenv("INCUS_CLUSTER_UPDATE")
 	if updateExecutable == "" {
-		logger.Debug("No INCUS_CLUSTER_UPDATE variable set, skipping auto-update")
+		clusterLogger.Debug("No INCUS_CLUSTER_UPDATE variable set, skipping auto-update")
 		return nil
 	}
 
@@ -114,17 +114,17 @@ func triggerUpdate() error {
 	// restarting all cluster members at the same time, and make the
 	// upgrade more graceful.
 	wait := time.Duration(rand.Intn(30)) * time.Second
-	logger.Info("Triggering cluster auto-update soon", logger.Ctx{"wait": wait, "updateExecutable": updateExecutable})
+	clusterLogger.Info("Triggering cluster auto-update soon", logger.Ctx{"wait": wait, "updateExecutable": updateExecutable})
 	time.Sleep(wait)
 
-	logger.Info("Triggering cluster auto-update now")
+	clusterLogger.Info("Triggering cluster auto-update now")
 	_, err := subprocess.RunCommand(updateExecutable)
 	if err != nil {
-		logger.Error("Triggering cluster update failed", logger.Ctx{"err": err})
+		clusterLogger.Error("Triggering cluster update failed", logger.Ctx{"err": err})
 		return err
 	}
 
-	logger.Info("Triggering cluster auto-update succeeded")
+	clusterLogger.Info("Triggering cluster auto-update succeeded")
 
 	return nil
 }
@@ -182,7 +182,7 @@ func UpgradeMembersWithoutRole(gateway *Gateway, members []db.NodeInfo) error {
 			// This can't really happen (but has in the past) since there are always at least as many
 			// members as there are nodes, and all of them have different IDs.
 			if id == uint64(member.ID) {
-				logger.Error("No available raft ID for cluster member", logger.Ctx{"memberID": member.ID, "members": members, "raftMembers": nodes})
+				clusterLogger.Error("No available raft ID for cluster member", logger.Ctx{"memberID": member.ID, "members": members, "raftMembers": nodes})
 				return fmt.Errorf("No available raft ID for cluster member ID %d", member.ID)
 			}
 		}
@@ -197,7 +197,7 @@ func UpgradeMembersWithoutRole(gateway *Gateway, members []db.NodeInfo) error {
 			Name: "",
 		}
 
-		logger.Info("Add spare dqlite node", logger.Ctx{"id": info.ID, "address": info.Address})
+		clusterLogger.Info("Add spare dqlite node", logger.Ctx{"id": info.ID, "address": info.Address})
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
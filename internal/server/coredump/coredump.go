@@ -0,0 +1,301 @@
+package coredump
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+// DefaultMaxSize is the total size of the core dumps kept for a project when coredumps.max_size isn't set.
+const DefaultMaxSize = 1024 * 1024 * 1024
+
+// Suffix is the file name suffix of core dumps.
+const Suffix = ".core"
+
+// ErrTooLarge is returned when a core dump doesn't fit within the size limit of its project.
+var ErrTooLarge = errors.New("Core dump exceeds the size limit of the project")
+
+// ErrBusy is returned when too many core dumps are already being stored.
+var ErrBusy = errors.New("Too many core dumps are being stored")
+
+// maxStores is the number of core dumps which can be stored at the same time.
+var maxStores = 4
+
+// storeSlots limits the number of core dumps being stored at the same time.
+var storeSlots = make(chan struct{}, maxStores)
+
+// pruneMu serializes the addition of core dumps so that projects never exceed their size limit.
+var pruneMu sync.Mutex
+
+// ProjectPath returns the directory holding the core dumps of the instances of a project.
+func ProjectPath(projectName string) string {
+	return internalUtil.VarPath("coredumps", projectName)
+}
+
+// Path returns the directory holding the core dumps of an instance.
+func Path(projectName string, instanceName string) string {
+	return filepath.Join(ProjectPath(projectName), instanceName)
+}
+
+// ValidName returns whether the name is a valid core dump file name.
+func ValidName(name string) bool {
+	return name != "" &&
+		!strings.HasPrefix(name, ".") &&
+		!strings.Contains(name, "/") &&
+		strings.HasSuffix(name, Suffix)
+}
+
+// FilePath returns the path of a core dump of an instance.
+func FilePath(projectName string, instanceName string, name string) (string, error) {
+	if !ValidName(name) {
+		return "", api.StatusErrorf(http.StatusBadRequest, "Core dump name %q not valid", name)
+	}
+
+	return filepath.Join(Path(projectName, instanceName), name), nil
+}
+
+// List returns the core dumps of an instance, oldest first.
+func List(projectName string, instanceName string) ([]api.InstanceCoreDump, error) {
+	entries, err := os.ReadDir(Path(projectName, instanceName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []api.InstanceCoreDump{}, nil
+		}
+
+		return nil, err
+	}
+
+	dumps := []api.InstanceCoreDump{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !ValidName(entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		dumps = append(dumps, api.InstanceCoreDump{
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			CreatedAt: info.ModTime().UTC(),
+		})
+	}
+
+	sort.SliceStable(dumps, func(i, j int) bool { return dumps[i].CreatedAt.Before(dumps[j].CreatedAt) })
+
+	return dumps, nil
+}
+
+// MaxSize returns the total size of the core dumps kept for a project with the given configuration.
+func MaxSize(projectConfig map[string]string) int64 {
+	value := projectConfig["coredumps.max_size"]
+	if value == "" {
+		return DefaultMaxSize
+	}
+
+	maxSize, err := units.ParseByteSizeString(value)
+	if err != nil || maxSize < 0 {
+		return DefaultMaxSize
+	}
+
+	return maxSize
+}
+
+// tempPath returns the path of the file a core dump is written to before being added.
+func tempPath(projectName string, instanceName string, name string) string {
+	return filepath.Join(Path(projectName, instanceName), "."+name+".tmp")
+}
+
+// Create returns a new temporary file to write a core dump to.
+// The core dump becomes visible once passed to Add.
+func Create(projectName string, instanceName string, name string) (*os.File, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("Core dump name %q not valid", name)
+	}
+
+	err := os.MkdirAll(Path(projectName, instanceName), 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating core dump directory: %w", err)
+	}
+
+	return os.OpenFile(tempPath(projectName, instanceName, name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+}
+
+// Add makes a core dump written to a file returned by Create visible, removing the oldest core dumps of the
+// project until the total size of its core dumps fits within maxSize.
+func Add(projectName string, instanceName string, name string, maxSize int64) error {
+	path := tempPath(projectName, instanceName, name)
+	defer func() { _ = os.Remove(path) }()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.Size() > maxSize {
+		return ErrTooLarge
+	}
+
+	pruneMu.Lock()
+	defer pruneMu.Unlock()
+
+	err = prune(projectName, maxSize-info.Size())
+	if err != nil {
+		return fmt.Errorf("Failed removing old core dumps: %w", err)
+	}
+
+	return os.Rename(path, filepath.Join(Path(projectName, instanceName), name))
+}
+
+// sizeLimitWriter is a writer which fails with ErrTooLarge as soon as more than maxSize bytes are written.
+type sizeLimitWriter struct {
+	w       io.Writer
+	maxSize int64
+	written int64
+}
+
+func (lw *sizeLimitWriter) Write(p []byte) (int, error) {
+	if lw.written+int64(len(p)) > lw.maxSize {
+		return 0, ErrTooLarge
+	}
+
+	n, err := lw.w.Write(p)
+	lw.written += int64(n)
+
+	return n, err
+}
+
+// Store writes a core dump read from r, see Add.
+// Reading stops as soon as the core dump exceeds maxSize, and ErrBusy is returned if too many core dumps are
+// already being stored, so that crashing processes can't fill the disk.
+func Store(projectName string, instanceName string, name string, r io.Reader, maxSize int64) error {
+	select {
+	case storeSlots <- struct{}{}:
+		defer func() { <-storeSlots }()
+	default:
+		return ErrBusy
+	}
+
+	f, err := Create(projectName, instanceName, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(&sizeLimitWriter{w: f, maxSize: maxSize}, r)
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+
+		if errors.Is(err, ErrTooLarge) {
+			return err
+		}
+
+		return fmt.Errorf("Failed writing core dump: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("Failed writing core dump: %w", err)
+	}
+
+	return Add(projectName, instanceName, name, maxSize)
+}
+
+// prune removes the oldest core dumps of the project until their total size is at most maxSize.
+func prune(projectName string, maxSize int64) error {
+	type coreDump struct {
+		path string
+		info fs.FileInfo
+	}
+
+	paths, err := filepath.Glob(filepath.Join(ProjectPath(projectName), "*", "*"+Suffix))
+	if err != nil {
+		return err
+	}
+
+	dumps := make([]coreDump, 0, len(paths))
+	var total int64
+
+	for _, path := range paths {
+		if !ValidName(filepath.Base(path)) {
+			continue
+		}
+
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		dumps = append(dumps, coreDump{path: path, info: info})
+		total += info.Size()
+	}
+
+	sort.SliceStable(dumps, func(i, j int) bool { return dumps[i].info.ModTime().Before(dumps[j].info.ModTime()) })
+
+	for _, dump := range dumps {
+		if total <= maxSize {
+			break
+		}
+
+		err := os.Remove(dump.path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		total -= dump.info.Size()
+	}
+
+	return nil
+}
+
+// Delete removes a core dump of an instance.
+func Delete(projectName string, instanceName string, name string) error {
+	path, err := FilePath(projectName, instanceName, name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return api.StatusErrorf(http.StatusNotFound, "Core dump %q not found", name)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAll removes all the core dumps of an instance.
+func DeleteAll(projectName string, instanceName string) error {
+	return os.RemoveAll(Path(projectName, instanceName))
+}
+
+// Rename moves the core dumps of an instance to its new name.
+func Rename(projectName string, oldName string, newName string) error {
+	err := os.Rename(Path(projectName, oldName), Path(projectName, newName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
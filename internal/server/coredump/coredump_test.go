@@ -0,0 +1,148 @@
+package coredump
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidName(t *testing.T) {
+	assert.True(t, ValidName("20240502T101512Z_nginx_1234.core"))
+	assert.False(t, ValidName(""))
+	assert.False(t, ValidName(".core"))
+	assert.False(t, ValidName(".foo.core.tmp"))
+	assert.False(t, ValidName("../foo.core"))
+	assert.False(t, ValidName("foo.log"))
+}
+
+func TestMaxSize(t *testing.T) {
+	assert.Equal(t, int64(DefaultMaxSize), MaxSize(map[string]string{}))
+	assert.Equal(t, int64(2*1024*1024), MaxSize(map[string]string{"coredumps.max_size": "2MiB"}))
+	assert.Equal(t, int64(0), MaxSize(map[string]string{"coredumps.max_size": "0"}))
+}
+
+func TestStore(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	// Store a few core dumps, each older than the next.
+	for i, name := range []string{"a.core", "b.core", "c.core"} {
+		err := Store("default", "c1", name, bytes.NewReader(make([]byte, 100)), 250)
+		require.NoError(t, err)
+
+		modTime := time.Now().Add(time.Duration(i-10) * time.Minute)
+		err = os.Chtimes(filepath.Join(Path("default", "c1"), name), modTime, modTime)
+		require.NoError(t, err)
+	}
+
+	// Only the two most recent core dumps fit within the limit.
+	dumps, err := List("default", "c1")
+	require.NoError(t, err)
+	require.Len(t, dumps, 2)
+	assert.Equal(t, "b.core", dumps[0].Name)
+	assert.Equal(t, "c.core", dumps[1].Name)
+	assert.Equal(t, int64(100), dumps[0].Size)
+
+	// The limit applies to all the instances of the project.
+	err = Store("default", "c2", "d.core", bytes.NewReader(make([]byte, 100)), 250)
+	require.NoError(t, err)
+
+	dumps, err = List("default", "c1")
+	require.NoError(t, err)
+	require.Len(t, dumps, 1)
+	assert.Equal(t, "c.core", dumps[0].Name)
+
+	// Other projects aren't affected.
+	err = Store("other", "c1", "e.core", bytes.NewReader(make([]byte, 100)), 100)
+	require.NoError(t, err)
+
+	dumps, err = List("default", "c2")
+	require.NoError(t, err)
+	assert.Len(t, dumps, 1)
+
+	// Core dumps larger than the limit are rejected.
+	err = Store("default", "c1", "f.core", bytes.NewReader(make([]byte, 300)), 250)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	entries, err := os.ReadDir(Path("default", "c1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+// endlessReader is a reader of zeros which counts the bytes read.
+type endlessReader struct {
+	read int64
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	clear(p)
+	r.read += int64(len(p))
+
+	return len(p), nil
+}
+
+func TestStoreLimits(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	// Reading stops as soon as the core dump exceeds the limit.
+	r := &endlessReader{}
+	err := Store("default", "c1", "a.core", r, 1024*1024)
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.Less(t, r.read, int64(2*1024*1024))
+
+	entries, err := os.ReadDir(Path("default", "c1"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// Core dumps are discarded while too many are being stored.
+	for i := 0; i < maxStores; i++ {
+		storeSlots <- struct{}{}
+	}
+
+	err = Store("default", "c1", "b.core", bytes.NewReader([]byte("core")), 100)
+	assert.ErrorIs(t, err, ErrBusy)
+
+	<-storeSlots
+
+	err = Store("default", "c1", "b.core", bytes.NewReader([]byte("core")), 100)
+	require.NoError(t, err)
+
+	for i := 1; i < maxStores; i++ {
+		<-storeSlots
+	}
+}
+
+func TestDeleteRename(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	err := Store("default", "c1", "a.core", bytes.NewReader([]byte("core")), 100)
+	require.NoError(t, err)
+
+	err = Delete("default", "c1", "../a.core")
+	assert.Error(t, err)
+
+	err = Delete("default", "c1", "b.core")
+	assert.Error(t, err)
+
+	err = Rename("default", "c1", "c2")
+	require.NoError(t, err)
+
+	dumps, err := List("default", "c2")
+	require.NoError(t, err)
+	assert.Len(t, dumps, 1)
+
+	err = Delete("default", "c2", "a.core")
+	require.NoError(t, err)
+
+	dumps, err = List("default", "c2")
+	require.NoError(t, err)
+	assert.Empty(t, dumps)
+
+	// Renaming or deleting the core dumps of instances without any succeeds.
+	require.NoError(t, Rename("default", "c3", "c4"))
+	require.NoError(t, DeleteAll("default", "c4"))
+}
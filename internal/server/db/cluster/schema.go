@@ -95,6 +95,7 @@ CREATE TABLE "images_source" (
     protocol INTEGER NOT NULL,
     certificate TEXT NOT NULL,
     alias TEXT NOT NULL,
+    fingerprint TEXT NOT NULL DEFAULT "",
     FOREIGN KEY (image_id) REFERENCES "images" (id) ON DELETE CASCADE
 );
 CREATE TABLE "instances" (
@@ -150,6 +151,37 @@ CREATE TABLE "instances_devices_config" (
     UNIQUE (instance_device_id, key)
 );
 CREATE INDEX instances_node_id_idx ON instances (node_id);
+CREATE TABLE "instances_groups" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	policy TEXT NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+CREATE TABLE "instances_pools" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	image TEXT NOT NULL,
+	type INTEGER NOT NULL,
+	profiles TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	release TEXT NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+CREATE TABLE "instances_pools_members" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	instance_pool_id INTEGER NOT NULL,
+	instance_id INTEGER NOT NULL,
+	acquired INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (instance_id),
+	FOREIGN KEY (instance_pool_id) REFERENCES "instances_pools" (id) ON DELETE CASCADE,
+	FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE
+);
 CREATE TABLE "instances_profiles" (
     id INTEGER primary key AUTOINCREMENT NOT NULL,
     instance_id INTEGER NOT NULL,
@@ -167,6 +199,20 @@ CREATE INDEX instances_project_id_and_node_id_and_name_idx ON instances (project
 CREATE INDEX instances_project_id_and_node_id_idx ON instances (project_id,
     node_id);
 CREATE INDEX instances_project_id_idx ON instances (project_id);
+CREATE TABLE "instances_shares" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	instance_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	identity TEXT NOT NULL,
+	description TEXT NOT NULL,
+	created_by TEXT NOT NULL,
+	creation_date DATETIME NOT NULL,
+	expiry_date DATETIME NOT NULL,
+	UNIQUE (name),
+	UNIQUE (secret),
+	FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE
+);
 CREATE TABLE "instances_snapshots" (
     id INTEGER primary key AUTOINCREMENT NOT NULL,
     instance_id INTEGER NOT NULL,
@@ -202,6 +248,17 @@ CREATE TABLE "instances_snapshots_devices_config" (
     FOREIGN KEY (instance_snapshot_device_id) REFERENCES "instances_snapshots_devices" (id) ON DELETE CASCADE,
     UNIQUE (instance_snapshot_device_id, key)
 );
+CREATE TABLE "leases" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	instance_id INTEGER,
+	token INTEGER NOT NULL,
+	expiry_date DATETIME NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
+	FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE SET NULL
+);
 CREATE TABLE "networks" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     project_id INTEGER NOT NULL,
@@ -459,6 +516,18 @@ CREATE TABLE "projects_config" (
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
     UNIQUE (project_id, key)
 );
+CREATE TABLE "recycle_bin" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	node_id INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	name TEXT NOT NULL,
+	pool TEXT NOT NULL,
+	deletion_date DATETIME NOT NULL,
+	expiry_date DATETIME NOT NULL,
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
+	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
+);
 CREATE TABLE "storage_buckets" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	name TEXT NOT NULL,
@@ -649,5 +718,5 @@ CREATE TABLE "warnings" (
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (73, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (79, strftime("%s"))
 `
@@ -110,6 +110,154 @@ var updates = map[int]schema.Update{
 	71: updateFromV70,
 	72: updateFromV71,
 	73: updateFromV72,
+	74: updateFromV73,
+	75: updateFromV74,
+	76: updateFromV75,
+	77: updateFromV76,
+	78: updateFromV77,
+	79: updateFromV78,
+}
+
+// updateFromV78 adds the fingerprint column to images_source, recording the fingerprint of images on their
+// source server when their local files differ.
+func updateFromV78(ctx context.Context, tx *sql.Tx) error {
+	q := `
+ALTER TABLE images_source ADD COLUMN fingerprint TEXT NOT NULL DEFAULT "";
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding fingerprint column to images_source: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV77 adds the instances_shares table holding time-limited instance access grants.
+func updateFromV77(ctx context.Context, tx *sql.Tx) error {
+	q := `
+CREATE TABLE "instances_shares" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	instance_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	identity TEXT NOT NULL,
+	description TEXT NOT NULL,
+	created_by TEXT NOT NULL,
+	creation_date DATETIME NOT NULL,
+	expiry_date DATETIME NOT NULL,
+	UNIQUE (name),
+	UNIQUE (secret),
+	FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE
+);
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding instances_shares table: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV76 adds the instances_pools and instances_pools_members tables used by instance pools.
+func updateFromV76(ctx context.Context, tx *sql.Tx) error {
+	q := `
+CREATE TABLE "instances_pools" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	image TEXT NOT NULL,
+	type INTEGER NOT NULL,
+	profiles TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	release TEXT NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+CREATE TABLE "instances_pools_members" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	instance_pool_id INTEGER NOT NULL,
+	instance_id INTEGER NOT NULL,
+	acquired INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (instance_id),
+	FOREIGN KEY (instance_pool_id) REFERENCES "instances_pools" (id) ON DELETE CASCADE,
+	FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE
+);
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding instances_pools tables: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV75 adds the recycle_bin table holding deleted instances and custom volumes.
+func updateFromV75(ctx context.Context, tx *sql.Tx) error {
+	q := `
+CREATE TABLE "recycle_bin" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	node_id INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	name TEXT NOT NULL,
+	pool TEXT NOT NULL,
+	deletion_date DATETIME NOT NULL,
+	expiry_date DATETIME NOT NULL,
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
+	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
+);
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding recycle_bin table: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV74 adds the instances_groups table used for instance placement.
+func updateFromV74(ctx context.Context, tx *sql.Tx) error {
+	q := `
+CREATE TABLE "instances_groups" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	policy TEXT NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding instances_groups table: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV73 adds the leases table used by the guest API.
+func updateFromV73(ctx context.Context, tx *sql.Tx) error {
+	q := `
+CREATE TABLE "leases" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	instance_id INTEGER,
+	token INTEGER NOT NULL,
+	expiry_date DATETIME NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
+	FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE SET NULL
+);
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding leases table: %w", err)
+	}
+
+	return nil
 }
 
 // updateFromV72 removes the openfga.store.model_id server config key.
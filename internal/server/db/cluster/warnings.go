@@ -65,7 +65,7 @@ type WarningFilter struct {
 func (w Warning) ToAPI() api.Warning {
 	typeCode := warningtype.Type(w.TypeCode)
 
-	return api.Warning{
+	warning := api.Warning{
 		WarningPut: api.WarningPut{
 			Status: warningtype.Statuses[warningtype.Status(w.Status)],
 		},
@@ -79,4 +79,14 @@ func (w Warning) ToAPI() api.Warning {
 		LastMessage: w.LastMessage,
 		Severity:    warningtype.Severities[typeCode.Severity()],
 	}
+
+	remediation, ok := typeCode.Remediation()
+	if ok {
+		warning.Remediation = &api.WarningRemediation{
+			Description: remediation.Description,
+			Action:      string(remediation.Action),
+		}
+	}
+
+	return warning
 }
@@ -15,10 +15,12 @@ import (
 	"time"
 
 	"github.com/cowsql/go-cowsql/driver"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/db/node"
 	"github.com/lxc/incus/v6/internal/server/db/query"
+	"github.com/lxc/incus/v6/internal/server/tracing"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/logger"
 )
@@ -96,7 +98,10 @@ func (n *Node) Dir() string {
 // node-level database interactions invoked by the given function. If the
 // function returns no error, all database changes are committed to the
 // node-level database, otherwise they are rolled back.
-func (n *Node) Transaction(ctx context.Context, f func(context.Context, *NodeTx) error) error {
+func (n *Node) Transaction(ctx context.Context, f func(context.Context, *NodeTx) error) (err error) {
+	ctx, span := tracing.StartChild(ctx, "db.node.transaction", attribute.String("db.system", "sqlite"))
+	defer func() { tracing.End(span, err) }()
+
 	nodeTx := &NodeTx{}
 	return query.Transaction(ctx, n.db, func(ctx context.Context, tx *sql.Tx) error {
 		nodeTx.tx = tx
@@ -333,7 +338,10 @@ func (c *Cluster) ExitExclusive(ctx context.Context, f func(context.Context, *Cl
 	return c.transaction(ctx, f)
 }
 
-func (c *Cluster) transaction(ctx context.Context, f func(context.Context, *ClusterTx) error) error {
+func (c *Cluster) transaction(ctx context.Context, f func(context.Context, *ClusterTx) error) (err error) {
+	ctx, span := tracing.StartChild(ctx, "db.cluster.transaction", attribute.String("db.system", "cowsql"))
+	defer func() { tracing.End(span, err) }()
+
 	clusterTx := &ClusterTx{
 		nodeID: c.nodeID,
 	}
@@ -350,6 +358,7 @@ func (c *Cluster) transaction(ctx context.Context, f func(context.Context, *Clus
 			// Now that this query has been cancelled, a leader election should have taken place by now.
 			// So let's retry the transaction once more in case the global database is now available again.
 			logger.Warn("Transaction timed out. Retrying once", logger.Ctx{"member": c.nodeID, "err": err})
+			span.AddEvent("Retrying after timeout")
 			return query.Transaction(ctx, c.db, txFunc)
 		}
 
@@ -23,6 +23,7 @@ var ImageSourceProtocol = map[int]string{
 	0: "incus",
 	1: "direct",
 	2: "simplestreams",
+	3: "git",
 }
 
 // GetLocalImagesFingerprints returns the fingerprints of all local images.
@@ -239,6 +240,58 @@ func (c *ClusterTx) CreateImageSource(ctx context.Context, id int, server string
 	return err
 }
 
+// SetImageSourceFingerprint records the fingerprint of the image on its source server, for images whose
+// local files differ from the source ones.
+func (c *ClusterTx) SetImageSourceFingerprint(ctx context.Context, imageID int, fingerprint string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE images_source SET fingerprint=? WHERE image_id=?", fingerprint, imageID)
+	return err
+}
+
+// GetImageSourceFingerprint returns the fingerprint of the image on its source server, if recorded.
+func (c *ClusterTx) GetImageSourceFingerprint(ctx context.Context, imageID int) (string, error) {
+	fingerprints, err := query.SelectStrings(ctx, c.tx, "SELECT fingerprint FROM images_source WHERE image_id=? AND fingerprint != ''", imageID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(fingerprints) == 0 {
+		return "", nil
+	}
+
+	return fingerprints[0], nil
+}
+
+// GetImageFingerprintFromSource returns the fingerprint of the local image of the project which is stored under
+// the given fingerprint on the given source server, but whose local files differ from the source ones.
+func (c *ClusterTx) GetImageFingerprintFromSource(ctx context.Context, project string, server string, sourceFingerprint string) (string, error) {
+	enabled, err := cluster.ProjectHasImages(ctx, c.tx, project)
+	if err != nil {
+		return "", fmt.Errorf("Check if project has images: %w", err)
+	}
+
+	if !enabled {
+		project = "default"
+	}
+
+	q := `SELECT images.fingerprint
+			FROM images_source
+			JOIN images ON images.id = images_source.image_id
+			JOIN projects ON projects.id = images.project_id
+			WHERE projects.name=? AND images_source.server=? AND images_source.fingerprint=?
+`
+
+	fingerprints, err := query.SelectStrings(ctx, c.tx, q, project, server, sourceFingerprint)
+	if err != nil {
+		return "", err
+	}
+
+	if len(fingerprints) == 0 {
+		return "", api.StatusErrorf(http.StatusNotFound, "Image not found")
+	}
+
+	return fingerprints[0], nil
+}
+
 // GetCachedImageSourceFingerprint tries to find a source entry of a locally
 // cached image that matches the given remote details (server, protocol and
 // alias). Return the fingerprint linked to the matching entry, if any.
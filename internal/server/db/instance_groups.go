@@ -0,0 +1,120 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/query"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// GetInstanceGroupNames returns the names of the instance groups of the given project.
+func (c *ClusterTx) GetInstanceGroupNames(ctx context.Context, projectName string) ([]string, error) {
+	q := `
+SELECT instances_groups.name
+  FROM instances_groups
+  JOIN projects ON projects.id = instances_groups.project_id
+ WHERE projects.name = ?
+ ORDER BY instances_groups.name
+`
+
+	names := []string{}
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var name string
+
+		err := scan(&name)
+		if err != nil {
+			return err
+		}
+
+		names = append(names, name)
+
+		return nil
+	}, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// GetInstanceGroup returns the instance group with the given name in the given project.
+func (c *ClusterTx) GetInstanceGroup(ctx context.Context, projectName string, name string) (int64, *api.InstanceGroup, error) {
+	q := `
+SELECT instances_groups.id, instances_groups.description, instances_groups.policy
+  FROM instances_groups
+  JOIN projects ON projects.id = instances_groups.project_id
+ WHERE projects.name = ? AND instances_groups.name = ?
+`
+
+	var id int64
+	group := api.InstanceGroup{
+		Name:    name,
+		Project: projectName,
+	}
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&id, &group.Description, &group.Policy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Instance group not found")
+		}
+
+		return -1, nil, err
+	}
+
+	return id, &group, nil
+}
+
+// GetInstanceGroupMembers returns the instances of the given project which are part of the instance group
+// through their expanded placement.group configuration, mapped to the cluster member they're on.
+func (c *ClusterTx) GetInstanceGroupMembers(ctx context.Context, projectName string, name string) (map[string]string, error) {
+	members := map[string]string{}
+
+	err := c.InstanceList(ctx, func(inst InstanceArgs, _ api.Project) error {
+		if ExpandInstanceConfig(inst.Config, inst.Profiles)["placement.group"] == name {
+			members[inst.Name] = inst.Node
+		}
+
+		return nil
+	}, cluster.InstanceFilter{Project: &projectName})
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// CreateInstanceGroup creates a new instance group in the given project.
+func (c *ClusterTx) CreateInstanceGroup(ctx context.Context, projectName string, info api.InstanceGroupsPost) (int64, error) {
+	q := `
+INSERT INTO instances_groups (project_id, name, description, policy)
+  VALUES ((SELECT id FROM projects WHERE name = ?), ?, ?, ?)
+`
+
+	result, err := c.tx.ExecContext(ctx, q, projectName, info.Name, info.Description, info.Policy)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.LastInsertId()
+}
+
+// UpdateInstanceGroup updates the instance group with the given ID.
+func (c *ClusterTx) UpdateInstanceGroup(ctx context.Context, id int64, info api.InstanceGroupPut) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE instances_groups SET description = ?, policy = ? WHERE id = ?", info.Description, info.Policy, id)
+
+	return err
+}
+
+// DeleteInstanceGroup deletes the instance group with the given ID.
+func (c *ClusterTx) DeleteInstanceGroup(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM instances_groups WHERE id = ?", id)
+
+	return err
+}
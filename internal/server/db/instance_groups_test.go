@@ -0,0 +1,62 @@
+//go:build linux && cgo && !agent
+
+package db_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Create, update, list the members of and delete an instance group.
+func TestInstanceGroup(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	nodeID, err := tx.CreateNode("node1", "1.2.3.4:666")
+	require.NoError(t, err)
+
+	addContainer(t, tx, nodeID, "c1")
+	addContainer(t, tx, nodeID, "c2")
+	addContainerConfig(t, tx, "c1", "placement.group", "web")
+
+	_, _, err = tx.GetInstanceGroup(ctx, "default", "web")
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+
+	id, err := tx.CreateInstanceGroup(ctx, "default", api.InstanceGroupsPost{
+		Name:             "web",
+		InstanceGroupPut: api.InstanceGroupPut{Description: "Web servers", Policy: "anti-affinity"},
+	})
+	require.NoError(t, err)
+
+	names, err := tx.GetInstanceGroupNames(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, names)
+
+	members, err := tx.GetInstanceGroupMembers(ctx, "default", "web")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"c1": "node1"}, members)
+
+	err = tx.UpdateInstanceGroup(ctx, id, api.InstanceGroupPut{Policy: "affinity"})
+	require.NoError(t, err)
+
+	_, group, err := tx.GetInstanceGroup(ctx, "default", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "affinity", group.Policy)
+	assert.Equal(t, "", group.Description)
+
+	err = tx.DeleteInstanceGroup(ctx, id)
+	require.NoError(t, err)
+
+	names, err = tx.GetInstanceGroupNames(ctx, "default")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
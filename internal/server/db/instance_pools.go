@@ -0,0 +1,290 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/lxc/incus/v6/internal/server/db/query"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// InstancePoolMember is an instance of an instance pool.
+type InstancePoolMember struct {
+	Instance string
+	Node     string
+	Acquired bool
+}
+
+// GetInstancePoolNames returns the names of the instance pools of the given project.
+func (c *ClusterTx) GetInstancePoolNames(ctx context.Context, projectName string) ([]string, error) {
+	q := `
+SELECT instances_pools.name
+  FROM instances_pools
+  JOIN projects ON projects.id = instances_pools.project_id
+ WHERE projects.name = ?
+ ORDER BY instances_pools.name
+`
+
+	names := []string{}
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var name string
+
+		err := scan(&name)
+		if err != nil {
+			return err
+		}
+
+		names = append(names, name)
+
+		return nil
+	}, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// GetInstancePoolByName returns the instance pool with the given name in the given project.
+func (c *ClusterTx) GetInstancePoolByName(ctx context.Context, projectName string, name string) (int64, *api.InstancePool, error) {
+	q := `
+SELECT instances_pools.id, instances_pools.description, instances_pools.image, instances_pools.type,
+       instances_pools.profiles, instances_pools.size, instances_pools.release
+  FROM instances_pools
+  JOIN projects ON projects.id = instances_pools.project_id
+ WHERE projects.name = ? AND instances_pools.name = ?
+`
+
+	var id int64
+	var instanceType instancetype.Type
+	var profiles string
+	pool := api.InstancePool{
+		Name:    name,
+		Project: projectName,
+	}
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&id, &pool.Description, &pool.Image, &instanceType, &profiles, &pool.Size, &pool.Release)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Instance pool not found")
+		}
+
+		return -1, nil, err
+	}
+
+	pool.Type = instanceType.ToAPI()
+
+	err = json.Unmarshal([]byte(profiles), &pool.Profiles)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	return id, &pool, nil
+}
+
+// GetInstancePoolMembers returns the instances of the instance pool with the given ID, oldest first.
+func (c *ClusterTx) GetInstancePoolMembers(ctx context.Context, id int64) ([]InstancePoolMember, error) {
+	q := `
+SELECT instances.name, nodes.name, instances_pools_members.acquired
+  FROM instances_pools_members
+  JOIN instances ON instances.id = instances_pools_members.instance_id
+  JOIN nodes ON nodes.id = instances.node_id
+ WHERE instances_pools_members.instance_pool_id = ?
+ ORDER BY instances_pools_members.id
+`
+
+	members := []InstancePoolMember{}
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		member := InstancePoolMember{}
+
+		err := scan(&member.Instance, &member.Node, &member.Acquired)
+		if err != nil {
+			return err
+		}
+
+		members = append(members, member)
+
+		return nil
+	}, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// CreateInstancePool creates a new instance pool in the given project.
+func (c *ClusterTx) CreateInstancePool(ctx context.Context, projectName string, info api.InstancePoolsPost) (int64, error) {
+	instanceType, err := instancetype.New(string(info.Type))
+	if err != nil {
+		return -1, err
+	}
+
+	profiles, err := json.Marshal(info.Profiles)
+	if err != nil {
+		return -1, err
+	}
+
+	q := `
+INSERT INTO instances_pools (project_id, name, description, image, type, profiles, size, release)
+  VALUES ((SELECT id FROM projects WHERE name = ?), ?, ?, ?, ?, ?, ?, ?)
+`
+
+	result, err := c.tx.ExecContext(ctx, q, projectName, info.Name, info.Description, info.Image, instanceType, string(profiles), info.Size, info.Release)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.LastInsertId()
+}
+
+// UpdateInstancePool updates the instance pool with the given ID.
+func (c *ClusterTx) UpdateInstancePool(ctx context.Context, id int64, info api.InstancePoolPut) error {
+	instanceType, err := instancetype.New(string(info.Type))
+	if err != nil {
+		return err
+	}
+
+	profiles, err := json.Marshal(info.Profiles)
+	if err != nil {
+		return err
+	}
+
+	q := `
+UPDATE instances_pools
+   SET description = ?, image = ?, type = ?, profiles = ?, size = ?, release = ?
+ WHERE id = ?
+`
+
+	_, err = c.tx.ExecContext(ctx, q, info.Description, info.Image, instanceType, string(profiles), info.Size, info.Release, id)
+
+	return err
+}
+
+// DeleteInstancePool deletes the instance pool with the given ID.
+func (c *ClusterTx) DeleteInstancePool(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM instances_pools WHERE id = ?", id)
+
+	return err
+}
+
+// AddInstancePoolMember adds a ready instance of the given project to the instance pool with the given ID.
+func (c *ClusterTx) AddInstancePoolMember(ctx context.Context, id int64, projectName string, instanceName string) error {
+	q := `
+INSERT INTO instances_pools_members (instance_pool_id, instance_id)
+  VALUES (?, (SELECT instances.id FROM instances JOIN projects ON projects.id = instances.project_id WHERE projects.name = ? AND instances.name = ?))
+`
+
+	_, err := c.tx.ExecContext(ctx, q, id, projectName, instanceName)
+
+	return err
+}
+
+// AcquireInstancePoolMember marks the oldest ready instance of the instance pool with the given ID as acquired and
+// returns it.
+func (c *ClusterTx) AcquireInstancePoolMember(ctx context.Context, id int64) (*InstancePoolMember, error) {
+	members, err := c.GetInstancePoolMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		if member.Acquired {
+			continue
+		}
+
+		err = c.setInstancePoolMemberAcquired(ctx, id, member.Instance, true)
+		if err != nil {
+			return nil, err
+		}
+
+		member.Acquired = true
+
+		return &member, nil
+	}
+
+	return nil, api.StatusErrorf(http.StatusServiceUnavailable, "No instance is ready in the instance pool")
+}
+
+// RemoveInstancePoolSurplusMembers removes the newest ready instances of the instance pool with the given ID from
+// the instance pool until at most size of them are left, and returns them. The removed instances can't be acquired
+// anymore but are left for the caller to delete.
+func (c *ClusterTx) RemoveInstancePoolSurplusMembers(ctx context.Context, id int64, size int) ([]InstancePoolMember, error) {
+	members, err := c.GetInstancePoolMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ready := 0
+	for _, member := range members {
+		if !member.Acquired {
+			ready++
+		}
+	}
+
+	q := `
+DELETE FROM instances_pools_members
+ WHERE instance_pool_id = ? AND acquired = 0 AND instance_id IN (
+  SELECT instances.id FROM instances
+    JOIN instances_pools ON instances_pools.project_id = instances.project_id
+   WHERE instances_pools.id = ? AND instances.name = ?)
+`
+
+	surplus := []InstancePoolMember{}
+	for i := len(members) - 1; i >= 0 && ready > size; i-- {
+		if members[i].Acquired {
+			continue
+		}
+
+		_, err := c.tx.ExecContext(ctx, q, id, id, members[i].Instance)
+		if err != nil {
+			return nil, err
+		}
+
+		surplus = append(surplus, members[i])
+		ready--
+	}
+
+	return surplus, nil
+}
+
+// ReleaseInstancePoolMember marks the given acquired instance of the instance pool with the given ID as ready.
+func (c *ClusterTx) ReleaseInstancePoolMember(ctx context.Context, id int64, instanceName string) error {
+	return c.setInstancePoolMemberAcquired(ctx, id, instanceName, false)
+}
+
+// setInstancePoolMemberAcquired sets whether the given instance of the instance pool with the given ID is acquired.
+func (c *ClusterTx) setInstancePoolMemberAcquired(ctx context.Context, id int64, instanceName string, acquired bool) error {
+	q := `
+UPDATE instances_pools_members
+   SET acquired = ?
+ WHERE instance_pool_id = ? AND instance_id IN (
+  SELECT instances.id FROM instances
+    JOIN instances_pools ON instances_pools.project_id = instances.project_id
+   WHERE instances_pools.id = ? AND instances.name = ?)
+`
+
+	result, err := c.tx.ExecContext(ctx, q, acquired, id, id, instanceName)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Instance isn't part of the instance pool")
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+//go:build linux && cgo && !agent
+
+package db_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Create an instance pool, acquire and release its members and delete it.
+func TestInstancePool(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	nodeID, err := tx.CreateNode("node1", "1.2.3.4:666")
+	require.NoError(t, err)
+
+	addContainer(t, tx, nodeID, "ci-1")
+	addContainer(t, tx, nodeID, "ci-2")
+
+	_, _, err = tx.GetInstancePoolByName(ctx, "default", "ci")
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+
+	id, err := tx.CreateInstancePool(ctx, "default", api.InstancePoolsPost{
+		Name: "ci",
+		InstancePoolPut: api.InstancePoolPut{
+			Image:    "debian/12",
+			Type:     api.InstanceTypeContainer,
+			Profiles: []string{"default"},
+			Size:     2,
+			Release:  "recycle",
+		},
+	})
+	require.NoError(t, err)
+
+	names, err := tx.GetInstancePoolNames(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ci"}, names)
+
+	_, pool, err := tx.GetInstancePoolByName(ctx, "default", "ci")
+	require.NoError(t, err)
+	assert.Equal(t, api.InstanceTypeContainer, pool.Type)
+	assert.Equal(t, []string{"default"}, pool.Profiles)
+	assert.Equal(t, 2, pool.Size)
+
+	require.NoError(t, tx.AddInstancePoolMember(ctx, id, "default", "ci-1"))
+	require.NoError(t, tx.AddInstancePoolMember(ctx, id, "default", "ci-2"))
+
+	// Members are handed out oldest first, and only once.
+	member, err := tx.AcquireInstancePoolMember(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "ci-1", member.Instance)
+	assert.Equal(t, "node1", member.Node)
+
+	member, err = tx.AcquireInstancePoolMember(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "ci-2", member.Instance)
+
+	_, err = tx.AcquireInstancePoolMember(ctx, id)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusServiceUnavailable))
+
+	require.NoError(t, tx.ReleaseInstancePoolMember(ctx, id, "ci-1"))
+
+	err = tx.ReleaseInstancePoolMember(ctx, id, "other")
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+
+	members, err := tx.GetInstancePoolMembers(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, []db.InstancePoolMember{
+		{Instance: "ci-1", Node: "node1", Acquired: false},
+		{Instance: "ci-2", Node: "node1", Acquired: true},
+	}, members)
+
+	err = tx.UpdateInstancePool(ctx, id, api.InstancePoolPut{Image: "debian/13", Type: api.InstanceTypeVM, Profiles: []string{}, Size: 1, Release: "destroy"})
+	require.NoError(t, err)
+
+	_, pool, err = tx.GetInstancePoolByName(ctx, "default", "ci")
+	require.NoError(t, err)
+	assert.Equal(t, api.InstanceTypeVM, pool.Type)
+	assert.Equal(t, "destroy", pool.Release)
+
+	err = tx.DeleteInstancePool(ctx, id)
+	require.NoError(t, err)
+
+	names, err = tx.GetInstancePoolNames(ctx, "default")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+// Only the newest ready members are removed from the instance pool when it shrinks.
+func TestRemoveInstancePoolSurplusMembers(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	nodeID, err := tx.CreateNode("node1", "1.2.3.4:666")
+	require.NoError(t, err)
+
+	id, err := tx.CreateInstancePool(ctx, "default", api.InstancePoolsPost{
+		Name: "ci",
+		InstancePoolPut: api.InstancePoolPut{
+			Image:    "debian/12",
+			Type:     api.InstanceTypeContainer,
+			Profiles: []string{"default"},
+			Size:     1,
+			Release:  "destroy",
+		},
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"ci-1", "ci-2", "ci-3", "ci-4"} {
+		addContainer(t, tx, nodeID, name)
+		require.NoError(t, tx.AddInstancePoolMember(ctx, id, "default", name))
+	}
+
+	// ci-1 is handed out, leaving three ready members.
+	member, err := tx.AcquireInstancePoolMember(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "ci-1", member.Instance)
+
+	surplus, err := tx.RemoveInstancePoolSurplusMembers(ctx, id, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []db.InstancePoolMember{
+		{Instance: "ci-4", Node: "node1", Acquired: false},
+		{Instance: "ci-3", Node: "node1", Acquired: false},
+	}, surplus)
+
+	members, err := tx.GetInstancePoolMembers(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, []db.InstancePoolMember{
+		{Instance: "ci-1", Node: "node1", Acquired: true},
+		{Instance: "ci-2", Node: "node1", Acquired: false},
+	}, members)
+
+	// Nothing is left to remove.
+	surplus, err = tx.RemoveInstancePoolSurplusMembers(ctx, id, 1)
+	require.NoError(t, err)
+	assert.Empty(t, surplus)
+
+	// The removed members can't be acquired anymore.
+	member, err = tx.AcquireInstancePoolMember(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "ci-2", member.Instance)
+
+	_, err = tx.AcquireInstancePoolMember(ctx, id)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusServiceUnavailable))
+}
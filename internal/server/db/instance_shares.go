@@ -0,0 +1,108 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/db/query"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+const instanceShareSelect = `
+SELECT projects.name, instances.name, instances_shares.name, instances_shares.identity, instances_shares.description, instances_shares.created_by, instances_shares.creation_date, instances_shares.expiry_date
+  FROM instances_shares
+  JOIN instances ON instances.id = instances_shares.instance_id
+  JOIN projects ON projects.id = instances.project_id
+`
+
+// GetInstanceShares returns the shares of the given instance.
+func (c *ClusterTx) GetInstanceShares(ctx context.Context, projectName string, instanceName string) ([]api.InstanceShare, error) {
+	q := instanceShareSelect + " WHERE projects.name = ? AND instances.name = ? ORDER BY instances_shares.creation_date, instances_shares.id"
+
+	shares := []api.InstanceShare{}
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var shareProject, shareInstance string
+		share := api.InstanceShare{}
+
+		err := scan(&shareProject, &shareInstance, &share.ID, &share.Identity, &share.Description, &share.CreatedBy, &share.CreatedAt, &share.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		shares = append(shares, share)
+
+		return nil
+	}, projectName, instanceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// GetInstanceShareBySecret returns the project and instance names along with the share matching the given
+// secret hash.
+func (c *ClusterTx) GetInstanceShareBySecret(ctx context.Context, secretHash string) (string, string, *api.InstanceShare, error) {
+	var projectName, instanceName string
+	share := api.InstanceShare{}
+
+	err := c.tx.QueryRowContext(ctx, instanceShareSelect+" WHERE instances_shares.secret = ?", secretHash).Scan(&projectName, &instanceName, &share.ID, &share.Identity, &share.Description, &share.CreatedBy, &share.CreatedAt, &share.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", nil, api.StatusErrorf(http.StatusNotFound, "Instance share not found")
+		}
+
+		return "", "", nil, err
+	}
+
+	return projectName, instanceName, &share, nil
+}
+
+// CreateInstanceShare adds a share to the given instance, with the given secret hash.
+func (c *ClusterTx) CreateInstanceShare(ctx context.Context, projectName string, instanceName string, share api.InstanceShare, secretHash string) error {
+	q := `
+INSERT INTO instances_shares (instance_id, name, secret, identity, description, created_by, creation_date, expiry_date)
+  VALUES ((SELECT instances.id FROM instances JOIN projects ON projects.id = instances.project_id WHERE projects.name = ? AND instances.name = ?), ?, ?, ?, ?, ?, ?, ?)
+`
+
+	_, err := c.tx.ExecContext(ctx, q, projectName, instanceName, share.ID, secretHash, share.Identity, share.Description, share.CreatedBy, share.CreatedAt, share.ExpiresAt)
+
+	return err
+}
+
+// DeleteInstanceShare deletes the share of the given instance with the given ID.
+func (c *ClusterTx) DeleteInstanceShare(ctx context.Context, projectName string, instanceName string, id string) error {
+	q := `
+DELETE FROM instances_shares
+  WHERE name = ? AND instance_id = (SELECT instances.id FROM instances JOIN projects ON projects.id = instances.project_id WHERE projects.name = ? AND instances.name = ?)
+`
+
+	result, err := c.tx.ExecContext(ctx, q, id, projectName, instanceName)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Instance share not found")
+	}
+
+	return nil
+}
+
+// DeleteExpiredInstanceShares deletes all the instance shares which expired before the given time.
+func (c *ClusterTx) DeleteExpiredInstanceShares(ctx context.Context, expiredBefore time.Time) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM instances_shares WHERE expiry_date <= ?", expiredBefore)
+
+	return err
+}
@@ -0,0 +1,82 @@
+//go:build linux && cgo && !agent
+
+package db_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Create, look up, expire and delete instance shares.
+func TestInstanceShares(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	nodeID, err := tx.CreateNode("node1", "1.2.3.4:666")
+	require.NoError(t, err)
+
+	addContainer(t, tx, nodeID, "c1")
+
+	_, _, _, err = tx.GetInstanceShareBySecret(ctx, "hash1")
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+
+	err = tx.CreateInstanceShare(ctx, "default", "c1", api.InstanceShare{
+		ID:        "share1",
+		CreatedBy: "admin",
+		CreatedAt: now.Add(-2 * time.Hour),
+		ExpiresAt: now.Add(-time.Hour),
+	}, "hash1")
+	require.NoError(t, err)
+
+	err = tx.CreateInstanceShare(ctx, "default", "c1", api.InstanceShare{
+		ID:          "share2",
+		Description: "Vendor",
+		Identity:    "vendor",
+		CreatedBy:   "admin",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}, "hash2")
+	require.NoError(t, err)
+
+	shares, err := tx.GetInstanceShares(ctx, "default", "c1")
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+	assert.Equal(t, "share1", shares[0].ID)
+	assert.Equal(t, "share2", shares[1].ID)
+
+	projectName, instanceName, share, err := tx.GetInstanceShareBySecret(ctx, "hash2")
+	require.NoError(t, err)
+	assert.Equal(t, "default", projectName)
+	assert.Equal(t, "c1", instanceName)
+	assert.Equal(t, "vendor", share.Identity)
+	assert.True(t, now.Add(time.Hour).Equal(share.ExpiresAt))
+
+	err = tx.DeleteExpiredInstanceShares(ctx, now)
+	require.NoError(t, err)
+
+	shares, err = tx.GetInstanceShares(ctx, "default", "c1")
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.Equal(t, "share2", shares[0].ID)
+
+	err = tx.DeleteInstanceShare(ctx, "default", "c1", "share1")
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+
+	err = tx.DeleteInstanceShare(ctx, "default", "c1", "share2")
+	require.NoError(t, err)
+
+	shares, err = tx.GetInstanceShares(ctx, "default", "c1")
+	require.NoError(t, err)
+	assert.Empty(t, shares)
+}
@@ -920,6 +920,26 @@ func (c *ClusterTx) GetInstanceID(ctx context.Context, project, name string) (in
 	return int(id), err
 }
 
+// GetInstanceByRuntimeName returns the name of the instance of the project which is still running under the given
+// name after being renamed while running.
+func (c *ClusterTx) GetInstanceByRuntimeName(ctx context.Context, project string, name string) (string, error) {
+	q := `
+SELECT instances.name
+  FROM instances
+  JOIN projects ON projects.id = instances.project_id
+  JOIN instances_config ON instances_config.instance_id = instances.id
+ WHERE projects.name = ? AND instances_config.key = 'volatile.runtime.name' AND instances_config.value = ?
+`
+	instanceName := ""
+
+	err := c.tx.QueryRowContext(ctx, q, project, name).Scan(&instanceName)
+	if err == sql.ErrNoRows {
+		return "", api.StatusErrorf(http.StatusNotFound, "Instance not found")
+	}
+
+	return instanceName, err
+}
+
 // GetInstanceConfig returns the value of the given key in the configuration
 // of the instance with the given ID.
 func (c *ClusterTx) GetInstanceConfig(ctx context.Context, id int, key string) (string, error) {
@@ -962,6 +982,21 @@ func (c *ClusterTx) UpdateInstanceSnapshotCreationDate(ctx context.Context, inst
 	return nil
 }
 
+// UpdateInstanceSnapshotStatefulFlag toggles the stateful flag of the instance snapshot with ID.
+func (c *ClusterTx) UpdateInstanceSnapshotStatefulFlag(ctx context.Context, id int, stateful bool) error {
+	statefulInt := 0
+	if stateful {
+		statefulInt = 1
+	}
+
+	_, err := c.tx.ExecContext(ctx, "UPDATE instances_snapshots SET stateful=? WHERE id=?", statefulInt, id)
+	if err != nil {
+		return fmt.Errorf("Failed updating instance snapshot stateful flag: %w", err)
+	}
+
+	return nil
+}
+
 // GetInstanceSnapshotsNames returns the names of all snapshots of the instance
 // in the given project with the given name.
 // Returns snapshots slice ordered by when they were created, oldest first.
@@ -5,6 +5,7 @@ package db_test
 import (
 	"context"
 	"database/sql"
+	"net/http"
 	"testing"
 	"time"
 
@@ -546,6 +547,21 @@ func TestGetLocalInstancesInProject(t *testing.T) {
 	assert.Equal(t, map[string]map[string]string{"root": {"type": "disk", "x": "y"}}, cluster.DevicesToAPI(c3Devices))
 }
 
+func TestGetInstanceByRuntimeName(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	addContainer(t, tx, 1, "c2")
+	addContainerConfig(t, tx, "c2", "volatile.runtime.name", "c1")
+
+	name, err := tx.GetInstanceByRuntimeName(context.TODO(), "default", "c1")
+	require.NoError(t, err)
+	assert.Equal(t, "c2", name)
+
+	_, err = tx.GetInstanceByRuntimeName(context.TODO(), "default", "c2")
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+}
+
 func addContainer(t *testing.T, tx *db.ClusterTx, nodeID int64, name string) {
 	stmt := `
 INSERT INTO instances(node_id, name, architecture, type, project_id, description) VALUES (?, ?, 1, ?, 1, '')
@@ -0,0 +1,119 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Lease represents a named lease held by an instance of a project.
+type Lease struct {
+	Name       string
+	Instance   string
+	InstanceID int
+	Token      int64
+	ExpiryDate time.Time
+}
+
+// GetLease returns the lease with the given name in the given project.
+// Released and expired leases are returned with an empty holder.
+func (c *ClusterTx) GetLease(ctx context.Context, projectName string, name string) (*Lease, error) {
+	stmt := `
+SELECT leases.name, IFNULL(instances.name, ''), IFNULL(leases.instance_id, -1), leases.token, leases.expiry_date
+  FROM leases
+  JOIN projects ON projects.id = leases.project_id
+  LEFT JOIN instances ON instances.id = leases.instance_id
+ WHERE projects.name = ? AND leases.name = ?
+`
+
+	lease := Lease{}
+
+	err := c.tx.QueryRowContext(ctx, stmt, projectName, name).Scan(&lease.Name, &lease.Instance, &lease.InstanceID, &lease.Token, &lease.ExpiryDate)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Lease not found")
+		}
+
+		return nil, err
+	}
+
+	if lease.InstanceID < 0 || !lease.ExpiryDate.After(time.Now()) {
+		lease.Instance = ""
+		lease.InstanceID = -1
+	}
+
+	return &lease, nil
+}
+
+// AcquireLease acquires or renews a lease on behalf of an instance until the given expiry date.
+// The lease token is incremented every time the lease changes hands so it can be used as a fencing token.
+// Returns a conflict error if the lease is currently held by another instance.
+func (c *ClusterTx) AcquireLease(ctx context.Context, projectName string, name string, instanceID int, expiryDate time.Time) (*Lease, error) {
+	lease, err := c.GetLease(ctx, projectName, name)
+	if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return nil, err
+	}
+
+	if lease == nil {
+		stmt := `
+INSERT INTO leases (project_id, name, instance_id, token, expiry_date)
+  VALUES ((SELECT id FROM projects WHERE name = ?), ?, ?, 1, ?)
+`
+		_, err = c.tx.ExecContext(ctx, stmt, projectName, name, instanceID, expiryDate)
+		if err != nil {
+			return nil, err
+		}
+	} else if lease.InstanceID == instanceID {
+		stmt := `
+UPDATE leases SET expiry_date = ?
+ WHERE name = ? AND project_id = (SELECT id FROM projects WHERE name = ?)
+`
+		_, err = c.tx.ExecContext(ctx, stmt, expiryDate, name, projectName)
+		if err != nil {
+			return nil, err
+		}
+	} else if lease.InstanceID < 0 {
+		stmt := `
+UPDATE leases SET instance_id = ?, token = token + 1, expiry_date = ?
+ WHERE name = ? AND project_id = (SELECT id FROM projects WHERE name = ?)
+`
+		_, err = c.tx.ExecContext(ctx, stmt, instanceID, expiryDate, name, projectName)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, api.StatusErrorf(http.StatusConflict, "Lease is held by another instance")
+	}
+
+	return c.GetLease(ctx, projectName, name)
+}
+
+// ReleaseLease releases a lease held by an instance.
+// The lease record is kept so its token keeps increasing when it's acquired again.
+func (c *ClusterTx) ReleaseLease(ctx context.Context, projectName string, name string, instanceID int) error {
+	lease, err := c.GetLease(ctx, projectName, name)
+	if err != nil {
+		return err
+	}
+
+	if lease.InstanceID != instanceID {
+		return api.StatusErrorf(http.StatusConflict, "Lease isn't held by this instance")
+	}
+
+	stmt := `
+UPDATE leases SET instance_id = NULL, expiry_date = ?
+ WHERE name = ? AND project_id = (SELECT id FROM projects WHERE name = ?)
+`
+	_, err = c.tx.ExecContext(ctx, stmt, time.Now(), name, projectName)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
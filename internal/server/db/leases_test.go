@@ -0,0 +1,73 @@
+//go:build linux && cgo && !agent
+
+package db_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Acquire, renew, release and take over a lease.
+func TestLease(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	nodeID, err := tx.CreateNode("node1", "1.2.3.4:666")
+	require.NoError(t, err)
+
+	addContainer(t, tx, nodeID, "c1")
+	addContainer(t, tx, nodeID, "c2")
+
+	c1 := int(getContainerID(t, tx, "c1"))
+	c2 := int(getContainerID(t, tx, "c2"))
+
+	ctx := context.Background()
+
+	_, err = tx.GetLease(ctx, "default", "leader")
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+
+	// First acquisition.
+	lease, err := tx.AcquireLease(ctx, "default", "leader", c1, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "c1", lease.Instance)
+	assert.Equal(t, int64(1), lease.Token)
+
+	// Renewal by the holder keeps the token.
+	lease, err = tx.AcquireLease(ctx, "default", "leader", c1, time.Now().Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), lease.Token)
+
+	// Another instance can't take it.
+	_, err = tx.AcquireLease(ctx, "default", "leader", c2, time.Now().Add(time.Minute))
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict))
+
+	// Only the holder can release it.
+	err = tx.ReleaseLease(ctx, "default", "leader", c2)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict))
+
+	err = tx.ReleaseLease(ctx, "default", "leader", c1)
+	require.NoError(t, err)
+
+	lease, err = tx.GetLease(ctx, "default", "leader")
+	require.NoError(t, err)
+	assert.Equal(t, "", lease.Instance)
+
+	// Taking over a released lease increments the token.
+	lease, err = tx.AcquireLease(ctx, "default", "leader", c2, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), lease.Token)
+
+	// An expired lease can be taken over.
+	lease, err = tx.AcquireLease(ctx, "default", "leader", c1, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "c1", lease.Instance)
+	assert.Equal(t, int64(3), lease.Token)
+}
@@ -78,6 +78,16 @@ func (n NodeInfo) IsOffline(threshold time.Duration) bool {
 	return nodeIsOffline(threshold, n.Heartbeat)
 }
 
+// SupportedArchitectures returns the architecture of the node followed by its personalities.
+func (n NodeInfo) SupportedArchitectures() ([]int, error) {
+	personalities, err := osarch.ArchitecturePersonalities(n.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]int{n.Architecture}, personalities...), nil
+}
+
 // NodeInfoArgs provides information about the cluster environment for use with NodeInfo.ToAPI().
 type NodeInfoArgs struct {
 	LeaderAddress        string
@@ -1096,12 +1106,11 @@ func (c *ClusterTx) GetCandidateMembers(ctx context.Context, allMembers []NodeIn
 		// Consider target architectures if specified.
 		if targetArchitectures != nil {
 			// Get member personalities too.
-			personalities, err := osarch.ArchitecturePersonalities(member.Architecture)
+			supportedArchitectures, err := member.SupportedArchitectures()
 			if err != nil {
 				return nil, err
 			}
 
-			supportedArchitectures := append([]int{member.Architecture}, personalities...)
 			for _, supportedArchitecture := range supportedArchitectures {
 				if slices.Contains(targetArchitectures, supportedArchitecture) {
 					candidateMembers = append(candidateMembers, member)
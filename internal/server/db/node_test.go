@@ -424,6 +424,38 @@ INSERT INTO instances (id, node_id, name, architecture, type, project_id, descri
 	assert.Equal(t, "none", member.Name)
 }
 
+func TestGetCandidateMembers_Personalities(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	_, err := tx.CreateNodeWithArch("intel", "1.2.3.4:666", osarch.ARCH_64BIT_INTEL_X86)
+	require.NoError(t, err)
+
+	_, err = tx.CreateNodeWithArch("arm", "5.6.7.8:666", osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN)
+	require.NoError(t, err)
+
+	allMembers, err := tx.GetNodes(context.Background())
+	require.NoError(t, err)
+
+	// A 32-bit Intel image can only run on the 64-bit Intel member.
+	members, err := tx.GetCandidateMembers(context.Background(), allMembers, []int{osarch.ARCH_32BIT_INTEL_X86}, "", nil, time.Duration(db.DefaultOfflineThreshold)*time.Second)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, member := range members {
+		names = append(names, member.Name)
+
+		if member.Name == "intel" {
+			supported, err := member.SupportedArchitectures()
+			require.NoError(t, err)
+			assert.Equal(t, []int{osarch.ARCH_64BIT_INTEL_X86, osarch.ARCH_32BIT_INTEL_X86}, supported)
+		}
+	}
+
+	assert.Contains(t, names, "intel")
+	assert.NotContains(t, names, "arm")
+}
+
 func TestUpdateNodeFailureDomain(t *testing.T) {
 	tx, cleanup := db.NewTestClusterTx(t)
 	defer cleanup()
@@ -79,8 +79,25 @@ const (
 	BucketBackupRemove
 	BucketBackupRename
 	BucketBackupRestore
+	VolumeTransfer
+	InstancesBatch
+	ImageOptimize
+	InstanceConvert
+	InstancePoolRelease
+	ClusterConfigRollout
+	InstanceRefresh
 )
 
+// Classes of heavy operations whose concurrency can be limited.
+const (
+	ConcurrencyClassMigrations = "migrations"
+	ConcurrencyClassBackups    = "backups"
+	ConcurrencyClassImages     = "images"
+)
+
+// ConcurrencyClasses lists all the classes of heavy operations.
+var ConcurrencyClasses = []string{ConcurrencyClassMigrations, ConcurrencyClassBackups, ConcurrencyClassImages}
+
 // Description return a human-readable description of the operation type.
 func (t Type) Description() string {
 	switch t {
@@ -210,6 +227,20 @@ func (t Type) Description() string {
 		return "Renaming bucket backup"
 	case BucketBackupRestore:
 		return "Restoring bucket backup"
+	case VolumeTransfer:
+		return "Transferring storage volume"
+	case InstancesBatch:
+		return "Running instance batch"
+	case ImageOptimize:
+		return "Optimizing image"
+	case InstanceConvert:
+		return "Converting instance"
+	case InstancePoolRelease:
+		return "Releasing instance to pool"
+	case ClusterConfigRollout:
+		return "Rolling out cluster configuration"
+	case InstanceRefresh:
+		return "Refreshing instances"
 	default:
 		return "Executing operation"
 	}
@@ -253,6 +284,8 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 
 	case InstanceCreate:
 		return auth.ObjectTypeProject, auth.EntitlementCanCreateInstances
+	case InstancePoolRelease:
+		return auth.ObjectTypeProject, auth.EntitlementCanCreateInstances
 	case InstanceUpdate:
 		return auth.ObjectTypeInstance, auth.EntitlementCanEdit
 	case InstanceRename:
@@ -265,6 +298,10 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 		return auth.ObjectTypeInstance, auth.EntitlementCanEdit
 	case InstanceRebuild:
 		return auth.ObjectTypeInstance, auth.EntitlementCanEdit
+	case InstanceConvert:
+		return auth.ObjectTypeInstance, auth.EntitlementCanEdit
+	case InstanceRefresh:
+		return auth.ObjectTypeInstance, auth.EntitlementCanEdit
 	case SnapshotRestore:
 		return auth.ObjectTypeInstance, auth.EntitlementCanEdit
 
@@ -276,6 +313,8 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 		return auth.ObjectTypeImage, auth.EntitlementCanEdit
 	case ImageRefresh:
 		return auth.ObjectTypeImage, auth.EntitlementCanEdit
+	case ImageOptimize:
+		return auth.ObjectTypeImage, auth.EntitlementCanEdit
 	case ImagesUpdate:
 		return auth.ObjectTypeImage, auth.EntitlementCanEdit
 	case ImagesSynchronize:
@@ -300,7 +339,25 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanManageBackups
 	case BucketBackupRestore:
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanEdit
+
+	case VolumeTransfer:
+		return auth.ObjectTypeStorageVolume, auth.EntitlementCanManageBackups
 	}
 
 	return "", ""
 }
+
+// ConcurrencyClass returns the class of heavy operations used to limit how many of them run concurrently.
+// An empty class means the operation isn't limited.
+func (t Type) ConcurrencyClass() string {
+	switch t {
+	case InstanceMigrate, InstanceLiveMigrate, InstanceRefresh, SnapshotTransfer, VolumeMigrate, VolumeMove:
+		return ConcurrencyClassMigrations
+	case BackupCreate, BackupRestore, CustomVolumeBackupCreate, CustomVolumeBackupRestore, BucketBackupCreate, BucketBackupRestore, VolumeTransfer:
+		return ConcurrencyClassBackups
+	case ImageDownload, ImageRefresh, ImageOptimize:
+		return ConcurrencyClassImages
+	}
+
+	return ""
+}
@@ -0,0 +1,230 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// isDumpDataLine returns whether a line of a SQL text dump holds table data rather than schema.
+func isDumpDataLine(line string) bool {
+	return strings.HasPrefix(line, "INSERT INTO ") || line == "DELETE FROM sqlite_sequence;"
+}
+
+// DumpSchema returns the schema of a SQL text dump produced by Dump, in the format of a schema-only dump.
+func DumpSchema(dump string) string {
+	var builder strings.Builder
+
+	for _, line := range strings.SplitAfter(dump, "\n") {
+		if isDumpDataLine(strings.TrimSuffix(line, "\n")) {
+			continue
+		}
+
+		builder.WriteString(line)
+	}
+
+	return builder.String()
+}
+
+// dumpRows returns the INSERT statements of a SQL text dump produced by Dump.
+func dumpRows(dump string) []string {
+	rows := []string{}
+
+	for _, line := range strings.Split(dump, "\n") {
+		if strings.HasPrefix(line, "INSERT INTO ") {
+			rows = append(rows, line)
+		}
+	}
+
+	return rows
+}
+
+// dumpRowTable returns the name of the table an INSERT statement of a SQL text dump belongs to.
+// For the rows of the autoincrement sequences, this is the name of the table of the sequence.
+func dumpRowTable(stmt string) string {
+	table, ok := strings.CutPrefix(stmt, "INSERT INTO sqlite_sequence VALUES('")
+	if ok {
+		table, _, _ = strings.Cut(table, "'")
+
+		return table
+	}
+
+	table, _, _ = strings.Cut(strings.TrimPrefix(stmt, "INSERT INTO "), " ")
+
+	return strings.Trim(table, `"`)
+}
+
+// DumpDiff compares the rows of two SQL text dumps produced by Dump, ignoring the rows of the given tables.
+// It returns the INSERT statements only found in the target dump and those only found in the source dump.
+func DumpDiff(from string, to string, ignoreTables ...string) ([]string, []string) {
+	fromRows := dumpRows(from)
+	toRows := dumpRows(to)
+
+	fromCount := make(map[string]int, len(fromRows))
+	for _, row := range fromRows {
+		fromCount[row]++
+	}
+
+	toCount := make(map[string]int, len(toRows))
+	for _, row := range toRows {
+		toCount[row]++
+	}
+
+	added := []string{}
+	for _, row := range toRows {
+		if fromCount[row] > 0 {
+			fromCount[row]--
+			continue
+		}
+
+		if slices.Contains(ignoreTables, dumpRowTable(row)) {
+			continue
+		}
+
+		added = append(added, row)
+	}
+
+	removed := []string{}
+	for _, row := range fromRows {
+		if toCount[row] > 0 {
+			toCount[row]--
+			continue
+		}
+
+		if slices.Contains(ignoreTables, dumpRowTable(row)) {
+			continue
+		}
+
+		removed = append(removed, row)
+	}
+
+	return added, removed
+}
+
+// Restore replaces the rows of all tables with those of a SQL text dump produced by Dump.
+// The tables in keepTables keep their current rows, except for those referencing rows which no longer exist.
+// The schema of the dump must match the current schema of the database.
+func Restore(ctx context.Context, tx *sql.Tx, dump string, keepTables ...string) error {
+	currentSchema, err := Dump(ctx, tx, true)
+	if err != nil {
+		return err
+	}
+
+	if DumpSchema(dump) != currentSchema {
+		return fmt.Errorf("The schema of the dump doesn't match the schema of the database")
+	}
+
+	current, err := Dump(ctx, tx, false)
+	if err != nil {
+		return err
+	}
+
+	entitiesSchemas, entityNames, err := getEntitiesSchemas(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	// Only check the foreign keys once all rows were restored.
+	_, err = tx.ExecContext(ctx, "PRAGMA defer_foreign_keys=ON")
+	if err != nil {
+		return fmt.Errorf("Failed deferring foreign keys: %w", err)
+	}
+
+	for _, tableName := range entityNames {
+		if entitiesSchemas[tableName][0] != "table" || slices.Contains(keepTables, tableName) {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", tableName))
+		if err != nil {
+			return fmt.Errorf("Failed clearing table %q: %w", tableName, err)
+		}
+	}
+
+	sequences := []string{}
+	for _, stmt := range dumpRows(dump) {
+		if strings.HasPrefix(stmt, "INSERT INTO sqlite_sequence ") {
+			if !slices.Contains(keepTables, dumpRowTable(stmt)) {
+				sequences = append(sequences, stmt)
+			}
+
+			continue
+		}
+
+		if slices.Contains(keepTables, dumpRowTable(stmt)) {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, stmt)
+		if err != nil {
+			return fmt.Errorf("Failed restoring row %q: %w", stmt, err)
+		}
+	}
+
+	// Put back the rows of the kept tables which got deleted along with the rows they reference.
+	for _, stmt := range dumpRows(current) {
+		if strings.HasPrefix(stmt, "INSERT INTO sqlite_sequence ") {
+			if slices.Contains(keepTables, dumpRowTable(stmt)) {
+				sequences = append(sequences, stmt)
+			}
+
+			continue
+		}
+
+		if !slices.Contains(keepTables, dumpRowTable(stmt)) {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT OR IGNORE INTO "+strings.TrimPrefix(stmt, "INSERT INTO "))
+		if err != nil {
+			return fmt.Errorf("Failed keeping row %q: %w", stmt, err)
+		}
+	}
+
+	// Drop the rows of the kept tables referencing rows which weren't restored.
+	for _, tableName := range keepTables {
+		rowIDs := []int64{}
+		err = Scan(ctx, tx, fmt.Sprintf("PRAGMA foreign_key_check(%s)", tableName), func(scan func(dest ...any) error) error {
+			var table string
+			var rowID int64
+			var parent string
+			var fkID int
+
+			err := scan(&table, &rowID, &parent, &fkID)
+			if err != nil {
+				return err
+			}
+
+			rowIDs = append(rowIDs, rowID)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed checking foreign keys of table %q: %w", tableName, err)
+		}
+
+		for _, rowID := range rowIDs {
+			_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE rowid=?", tableName), rowID)
+			if err != nil {
+				return fmt.Errorf("Failed removing row %d of table %q: %w", rowID, tableName, err)
+			}
+		}
+	}
+
+	// Inserting rows updates the autoincrement sequences, reset them to the values they had.
+	_, err = tx.ExecContext(ctx, "DELETE FROM sqlite_sequence")
+	if err != nil {
+		return fmt.Errorf("Failed clearing table sqlite_sequence: %w", err)
+	}
+
+	for _, stmt := range sequences {
+		_, err = tx.ExecContext(ctx, stmt)
+		if err != nil {
+			return fmt.Errorf("Failed restoring row %q: %w", stmt, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db/query"
+)
+
+func TestDumpSchema(t *testing.T) {
+	tx := newTxForDump(t, "local")
+
+	dump, err := query.Dump(context.Background(), tx, false)
+	require.NoError(t, err)
+
+	schema, err := query.Dump(context.Background(), tx, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, schema, query.DumpSchema(dump))
+}
+
+func TestDumpDiff(t *testing.T) {
+	from := `INSERT INTO config VALUES(1,'a','1');
+INSERT INTO config VALUES(2,'b','2');
+INSERT INTO config VALUES(2,'b','2');
+`
+	to := `INSERT INTO config VALUES(1,'a','1');
+INSERT INTO config VALUES(2,'b','2');
+INSERT INTO config VALUES(3,'c','3');
+`
+
+	added, removed := query.DumpDiff(from, to)
+	assert.Equal(t, []string{"INSERT INTO config VALUES(3,'c','3');"}, added)
+	assert.Equal(t, []string{"INSERT INTO config VALUES(2,'b','2');"}, removed)
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	tx := newTxForDump(t, "global")
+
+	dump, err := query.Dump(ctx, tx, false)
+	require.NoError(t, err)
+
+	_, err = tx.Exec("INSERT INTO config VALUES(1,'core.https_address','[::]:8443')")
+	require.NoError(t, err)
+
+	_, err = tx.Exec("DELETE FROM storage_pools_config")
+	require.NoError(t, err)
+
+	changed, err := query.Dump(ctx, tx, false)
+	require.NoError(t, err)
+
+	added, removed := query.DumpDiff(changed, dump)
+	assert.Equal(t, []string{"INSERT INTO storage_pools_config VALUES(1,1,NULL,'k','v');"}, added)
+	assert.Contains(t, removed, "INSERT INTO config VALUES(1,'core.https_address','[::]:8443');")
+
+	err = query.Restore(ctx, tx, dump)
+	require.NoError(t, err)
+
+	restored, err := query.Dump(ctx, tx, false)
+	require.NoError(t, err)
+	assert.Equal(t, dump, restored)
+}
+
+func TestRestore_KeepTables(t *testing.T) {
+	ctx := context.Background()
+	tx := newTxForDump(t, "global")
+
+	dump, err := query.Dump(ctx, tx, false)
+	require.NoError(t, err)
+
+	_, err = tx.Exec("INSERT INTO config VALUES(1,'core.https_address','[::]:8443')")
+	require.NoError(t, err)
+
+	_, err = tx.Exec("DELETE FROM storage_pools_config")
+	require.NoError(t, err)
+
+	changed, err := query.Dump(ctx, tx, false)
+	require.NoError(t, err)
+
+	added, removed := query.DumpDiff(changed, dump, "config")
+	assert.Equal(t, []string{"INSERT INTO storage_pools_config VALUES(1,1,NULL,'k','v');"}, added)
+	assert.Empty(t, removed)
+
+	err = query.Restore(ctx, tx, dump, "config")
+	require.NoError(t, err)
+
+	restored, err := query.Dump(ctx, tx, false)
+	require.NoError(t, err)
+
+	added, removed = query.DumpDiff(restored, dump)
+	assert.Empty(t, added)
+	assert.Equal(t, []string{"INSERT INTO config VALUES(1,'core.https_address','[::]:8443');", "INSERT INTO sqlite_sequence VALUES('config',1);"}, removed)
+}
+
+func TestRestore_SchemaMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	dump, err := query.Dump(ctx, newTxForDump(t, "local"), false)
+	require.NoError(t, err)
+
+	err = query.Restore(ctx, newTxForDump(t, "global"), dump)
+	assert.EqualError(t, err, "The schema of the dump doesn't match the schema of the database")
+}
@@ -0,0 +1,124 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/db/query"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+const recycleBinEntrySelect = `
+SELECT recycle_bin.id, recycle_bin.type, recycle_bin.name, projects.name, recycle_bin.pool, nodes.name, recycle_bin.deletion_date, recycle_bin.expiry_date
+  FROM recycle_bin
+  JOIN projects ON projects.id = recycle_bin.project_id
+  JOIN nodes ON nodes.id = recycle_bin.node_id
+`
+
+// GetRecycleBinEntries returns the entries of the recycle bin of the given project, or of all projects if empty.
+func (c *ClusterTx) GetRecycleBinEntries(ctx context.Context, projectName string) ([]api.RecycleBinEntry, error) {
+	q := recycleBinEntrySelect
+	args := []any{}
+
+	if projectName != "" {
+		q += " WHERE projects.name = ?"
+		args = append(args, projectName)
+	}
+
+	q += " ORDER BY recycle_bin.deletion_date, recycle_bin.id"
+
+	entries := []api.RecycleBinEntry{}
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		entry := api.RecycleBinEntry{}
+
+		err := scan(&entry.ID, &entry.Type, &entry.Name, &entry.Project, &entry.Pool, &entry.Location, &entry.DeletedAt, &entry.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetRecycleBinEntry returns the entry of the recycle bin with the given ID.
+func (c *ClusterTx) GetRecycleBinEntry(ctx context.Context, id int64) (*api.RecycleBinEntry, error) {
+	entry := api.RecycleBinEntry{}
+
+	err := c.tx.QueryRowContext(ctx, recycleBinEntrySelect+" WHERE recycle_bin.id = ?", id).Scan(&entry.ID, &entry.Type, &entry.Name, &entry.Project, &entry.Pool, &entry.Location, &entry.DeletedAt, &entry.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Recycle bin entry not found")
+		}
+
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// GetLocalRecycleBinEntryIDs returns the IDs of the recycle bin entries held by this cluster member.
+// If expiredBefore isn't zero, only the entries which expired before it are returned.
+func (c *ClusterTx) GetLocalRecycleBinEntryIDs(ctx context.Context, expiredBefore time.Time) ([]int64, error) {
+	q := "SELECT id FROM recycle_bin WHERE node_id = ?"
+	args := []any{c.nodeID}
+
+	if !expiredBefore.IsZero() {
+		q += " AND expiry_date <= ?"
+		args = append(args, expiredBefore)
+	}
+
+	ids := []int64{}
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var id int64
+
+		err := scan(&id)
+		if err != nil {
+			return err
+		}
+
+		ids = append(ids, id)
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// CreateRecycleBinEntry adds an entry held by this cluster member to the recycle bin.
+func (c *ClusterTx) CreateRecycleBinEntry(ctx context.Context, entry api.RecycleBinEntry) (int64, error) {
+	q := `
+INSERT INTO recycle_bin (project_id, node_id, type, name, pool, deletion_date, expiry_date)
+  VALUES ((SELECT id FROM projects WHERE name = ?), ?, ?, ?, ?, ?, ?)
+`
+
+	result, err := c.tx.ExecContext(ctx, q, entry.Project, c.nodeID, entry.Type, entry.Name, entry.Pool, entry.DeletedAt, entry.ExpiresAt)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.LastInsertId()
+}
+
+// DeleteRecycleBinEntry deletes the entry of the recycle bin with the given ID.
+func (c *ClusterTx) DeleteRecycleBinEntry(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM recycle_bin WHERE id = ?", id)
+
+	return err
+}
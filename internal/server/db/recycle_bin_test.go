@@ -0,0 +1,80 @@
+//go:build linux && cgo && !agent
+
+package db_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Add, list, expire and delete recycle bin entries.
+func TestRecycleBin(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	_, err := tx.GetRecycleBinEntry(ctx, 1)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+
+	id1, err := tx.CreateRecycleBinEntry(ctx, api.RecycleBinEntry{
+		Type:      "instance",
+		Name:      "c1",
+		Project:   "default",
+		Pool:      "default",
+		DeletedAt: now.Add(-2 * time.Hour),
+		ExpiresAt: now.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	id2, err := tx.CreateRecycleBinEntry(ctx, api.RecycleBinEntry{
+		Type:      "volume",
+		Name:      "vol1",
+		Project:   "default",
+		Pool:      "default",
+		DeletedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	entries, err := tx.GetRecycleBinEntries(ctx, "default")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "c1", entries[0].Name)
+	assert.Equal(t, "none", entries[0].Location)
+	assert.Equal(t, "vol1", entries[1].Name)
+
+	entries, err = tx.GetRecycleBinEntries(ctx, "other")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entry, err := tx.GetRecycleBinEntry(ctx, id2)
+	require.NoError(t, err)
+	assert.Equal(t, "volume", entry.Type)
+	assert.True(t, now.Add(time.Hour).Equal(entry.ExpiresAt))
+
+	ids, err := tx.GetLocalRecycleBinEntryIDs(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{id1}, ids)
+
+	ids, err = tx.GetLocalRecycleBinEntryIDs(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{id1, id2}, ids)
+
+	err = tx.DeleteRecycleBinEntry(ctx, id1)
+	require.NoError(t, err)
+
+	entries, err = tx.GetRecycleBinEntries(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, id2, entries[0].ID)
+}
@@ -0,0 +1,66 @@
+//go:build linux && cgo && !agent
+
+package warningtype
+
+// Action is a known-safe fix which the server can apply to resolve a warning.
+type Action string
+
+const (
+	// ActionStartInstance starts the instance affected by the warning, once its storage pool is available.
+	ActionStartInstance Action = "start-instance"
+	// ActionStartNetwork retries starting the network affected by the warning.
+	ActionStartNetwork Action = "start-network"
+	// ActionMountStoragePool retries mounting the storage pool affected by the warning.
+	ActionMountStoragePool Action = "mount-storage-pool"
+)
+
+// Remediation describes how a warning of a given type can be addressed.
+type Remediation struct {
+	// Description is a human-readable explanation of the fix.
+	Description string
+
+	// Action is the fix the server can apply itself, if any.
+	Action Action
+}
+
+// Remediations associates a warning code to its remediation.
+var Remediations = map[Type]Remediation{
+	MissingCGroupBlkio:                {Description: "Enable the blkio controller on the host and restart the daemon"},
+	MissingCGroupBlkioWeight:          {Description: "Enable the blkio controller with weight support on the host and restart the daemon"},
+	MissingCGroupCPUController:        {Description: "Enable the cpu controller on the host and restart the daemon"},
+	MissingCGroupCPUsetController:     {Description: "Enable the cpuset controller on the host and restart the daemon"},
+	MissingCGroupCPUacctController:    {Description: "Enable the cpuacct controller on the host and restart the daemon"},
+	MissingCGroupDevicesController:    {Description: "Enable the devices controller on the host and restart the daemon"},
+	MissingCGroupFreezerController:    {Description: "Enable the freezer controller on the host and restart the daemon"},
+	MissingCGroupHugetlbController:    {Description: "Enable the hugetlb controller on the host and restart the daemon"},
+	MissingCGroupMemoryController:     {Description: "Enable the memory controller on the host and restart the daemon"},
+	MissingCGroupPidsController:       {Description: "Enable the pids controller on the host and restart the daemon"},
+	MissingCGroupMemorySwapAccounting: {Description: "Enable swap accounting on the host kernel command line and reboot"},
+	ClusterTimeSkew:                   {Description: "Synchronize the clocks of the cluster members using NTP"},
+	AppArmorNotAvailable:              {Description: "Enable AppArmor on the host and restart the daemon"},
+	MissingVirtiofsd:                  {Description: "Install virtiofsd on the host and restart the daemon"},
+	AppArmorDisabledDueToRawDnsmasq:   {Description: "Remove raw.dnsmasq from the network configuration"},
+	LargerIPv6PrefixThanSupported:     {Description: "Use a /64 IPv6 subnet for the network"},
+	ProxyBridgeNetfilterNotEnabled:    {Description: "Load the br_netfilter kernel module on the host"},
+	NetworkUnvailable:                 {Description: "Fix the underlying network issue and start the network again", Action: ActionStartNetwork},
+	OfflineClusterMember:              {Description: "Bring the cluster member back online or remove it from the cluster"},
+	InstanceAutostartFailure:          {Description: "Fix the reported error and start the instance", Action: ActionStartInstance},
+	InstanceTypeNotOperational:        {Description: "Install the missing instance driver dependencies and restart the daemon"},
+	StoragePoolUnvailable:             {Description: "Fix the underlying storage issue and mount the storage pool again", Action: ActionMountStoragePool},
+	UnableToUpdateClusterCertificate:  {Description: "Check the certificate and key files and update the cluster certificate again"},
+	InstanceIdle:                      {Description: "Stop or delete the instance if it is no longer needed"},
+	StoragePoolFailure:                {Description: "Repair the backing storage of the storage pool"},
+	InstanceStorageFailure:            {Description: "Start the instance once its storage pool has recovered", Action: ActionStartInstance},
+	RecycleBinSkipped:                 {Description: "Raise instances.recycle_bin.max_size to keep larger resources in the recycle bin"},
+	EdgeReconciliationConflict:        {Description: "Review the instance configuration, the cluster value was kept"},
+	InstanceMemoryPressure:            {Description: "Raise the memory limit of the instance or reduce its memory usage"},
+	InstanceOOMKill:                   {Description: "Raise the memory limit of the instance or reduce the memory usage of the killed process"},
+	InstanceRefreshFailure:            {Description: "Check that the source server is reachable and trusts the certificate of this server"},
+}
+
+// Remediation returns the remediation of the warning type.
+func (t Type) Remediation() (Remediation, bool) {
+	remediation, ok := Remediations[t]
+
+	return remediation, ok
+}
@@ -0,0 +1,36 @@
+//go:build linux && cgo && !agent
+
+package warningtype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemediations(t *testing.T) {
+	actions := []Action{ActionStartInstance, ActionStartNetwork, ActionMountStoragePool}
+
+	for typeCode, remediation := range Remediations {
+		name, ok := TypeNames[typeCode]
+		assert.True(t, ok, "Remediation of unknown warning type %d", typeCode)
+		assert.NotEmpty(t, remediation.Description, name)
+
+		if remediation.Action != "" {
+			assert.Contains(t, actions, remediation.Action, name)
+		}
+	}
+}
+
+func TestTypeRemediation(t *testing.T) {
+	remediation, ok := StoragePoolUnvailable.Remediation()
+	assert.True(t, ok)
+	assert.Equal(t, ActionMountStoragePool, remediation.Action)
+
+	remediation, ok = InstanceIdle.Remediation()
+	assert.True(t, ok)
+	assert.Empty(t, remediation.Action)
+
+	_, ok = Undefined.Remediation()
+	assert.False(t, ok)
+}
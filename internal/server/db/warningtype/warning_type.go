@@ -56,6 +56,22 @@ const (
 	StoragePoolUnvailable
 	// UnableToUpdateClusterCertificate represents the unable to update cluster certificate warning.
 	UnableToUpdateClusterCertificate
+	// InstanceIdle represents an instance with negligible activity over the idle detection window.
+	InstanceIdle
+	// StoragePoolFailure represents a storage pool whose backing storage failed on the local server.
+	StoragePoolFailure
+	// InstanceStorageFailure represents an instance stopped because of the failure of one of its storage pools.
+	InstanceStorageFailure
+	// RecycleBinSkipped represents a resource deleted without being kept in the recycle bin.
+	RecycleBinSkipped
+	// EdgeReconciliationConflict represents a change made on a disconnected edge member which couldn't be reconciled.
+	EdgeReconciliationConflict
+	// InstanceMemoryPressure represents an instance whose memory pressure stayed above its threshold.
+	InstanceMemoryPressure
+	// InstanceOOMKill represents a process of an instance killed by the out-of-memory killer.
+	InstanceOOMKill
+	// InstanceRefreshFailure represents a scheduled refresh of an instance from its source which failed.
+	InstanceRefreshFailure
 )
 
 // TypeNames associates a warning code to its name.
@@ -84,6 +100,14 @@ var TypeNames = map[Type]string{
 	InstanceTypeNotOperational:        "Instance type not operational",
 	StoragePoolUnvailable:             "Storage pool unavailable",
 	UnableToUpdateClusterCertificate:  "Unable to update cluster certificate",
+	InstanceIdle:                      "Instance is idle",
+	StoragePoolFailure:                "Storage pool failure",
+	InstanceStorageFailure:            "Instance storage failure",
+	RecycleBinSkipped:                 "Resource not kept in the recycle bin",
+	EdgeReconciliationConflict:        "Edge reconciliation conflict",
+	InstanceMemoryPressure:            "Instance memory pressure",
+	InstanceOOMKill:                   "Instance process killed by the out-of-memory killer",
+	InstanceRefreshFailure:            "Instance refresh failure",
 }
 
 // Severity returns the severity of the warning type.
@@ -137,6 +161,22 @@ func (t Type) Severity() Severity {
 		return SeverityHigh
 	case UnableToUpdateClusterCertificate:
 		return SeverityLow
+	case InstanceIdle:
+		return SeverityLow
+	case StoragePoolFailure:
+		return SeverityHigh
+	case InstanceStorageFailure:
+		return SeverityHigh
+	case RecycleBinSkipped:
+		return SeverityLow
+	case EdgeReconciliationConflict:
+		return SeverityModerate
+	case InstanceMemoryPressure:
+		return SeverityModerate
+	case InstanceOOMKill:
+		return SeverityModerate
+	case InstanceRefreshFailure:
+		return SeverityModerate
 	}
 
 	return SeverityLow
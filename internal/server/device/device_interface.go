@@ -1,6 +1,7 @@
 package device
 
 import (
+	"github.com/lxc/incus/v6/internal/limitcheck"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/state"
@@ -82,7 +83,18 @@ type device interface {
 	validateConfig(instance.ConfigReader) error
 }
 
+// LimitsVerifier provides the ability to check that the limits of a device are applied on the host.
+type LimitsVerifier interface {
+	VerifyLimits() ([]limitcheck.Discrepancy, error)
+}
+
 // NICState provides the ability to access NIC state.
 type NICState interface {
 	State() (*api.InstanceStateNetwork, error)
 }
+
+// InstanceRenamer provides the ability for a device to move the host-side state it keeps under the name of an
+// instance when that instance is renamed. It is called once the instance has its new name.
+type InstanceRenamer interface {
+	InstanceRename(oldName string, running bool) error
+}
@@ -421,12 +421,39 @@ func DiskVMVirtfsProxyStop(pidPath string) error {
 	return nil
 }
 
+// diskVirtiofsdArgs returns the virtiofsd arguments for the share path, cache mode and thread pool size.
+// Returns an error if the tuning options aren't listed in the virtiofsd help output.
+func diskVirtiofsdArgs(help string, sharePath string, cacheMode string, threadPoolSize int) ([]string, error) {
+	if cacheMode == "" {
+		cacheMode = "never"
+	}
+
+	if cacheMode != "never" && !strings.Contains(help, cacheMode) {
+		return nil, fmt.Errorf("The virtiofsd on this system doesn't support the %q cache mode", cacheMode)
+	}
+
+	args := []string{"--fd=3", fmt.Sprintf("--cache=%s", cacheMode)}
+	if threadPoolSize > 0 {
+		if !strings.Contains(help, "--thread-pool-size") {
+			return nil, fmt.Errorf("The virtiofsd on this system doesn't support setting the thread pool size")
+		}
+
+		args = append(args, fmt.Sprintf("--thread-pool-size=%d", threadPoolSize))
+	}
+
+	args = append(args, "-o", fmt.Sprintf("source=%s", sharePath))
+
+	return args, nil
+}
+
 // DiskVMVirtiofsdStart starts a new virtiofsd process.
 // If the idmaps slice is supplied then the proxy process is run inside a user namespace using the supplied maps.
+// The cacheMode defaults to "never" when empty and a threadPoolSize of 0 keeps the virtiofsd default.
+// Returns a normal error if virtiofsd doesn't support the requested cacheMode or threadPoolSize.
 // Returns UnsupportedError error if the host system or instance does not support virtiosfd, returns normal error
 // type if process cannot be started for other reasons.
 // Returns revert function and listener file handle on success.
-func DiskVMVirtiofsdStart(execPath string, inst instance.Instance, socketPath string, pidPath string, logPath string, sharePath string, idmaps []idmap.Entry) (func(), net.Listener, error) {
+func DiskVMVirtiofsdStart(execPath string, inst instance.Instance, socketPath string, pidPath string, logPath string, sharePath string, idmaps []idmap.Entry, cacheMode string, threadPoolSize int) (func(), net.Listener, error) {
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -499,8 +526,21 @@ func DiskVMVirtiofsdStart(execPath string, inst instance.Instance, socketPath st
 
 	defer func() { _ = unixFile.Close() }()
 
+	// Check that virtiofsd supports the requested tuning options.
+	var help string
+	if (cacheMode != "" && cacheMode != "never") || threadPoolSize > 0 {
+		help, err = subprocess.RunCommand(cmd, "--help")
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to check virtiofsd options: %w", err)
+		}
+	}
+
+	args, err := diskVirtiofsdArgs(help, sharePath, cacheMode, threadPoolSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Start the virtiofsd process in non-daemon mode.
-	args := []string{"--fd=3", "--cache=never", "-o", fmt.Sprintf("source=%s", sharePath)}
 	proc, err := subprocess.NewProcess(cmd, args, logPath, logPath)
 	if err != nil {
 		return nil, nil, err
@@ -90,3 +90,22 @@ func TestDiskAddRootUserNSEntry(t *testing.T) {
 
 	assert.Equal(t, idmaps, expected)
 }
+
+func TestDiskVirtiofsdArgs(t *testing.T) {
+	help := "--cache <CACHE>  The caching policy the file system should use (auto, always, metadata, never) [default: auto]\n--thread-pool-size <THREAD_POOL_SIZE>  Maximum thread pool size"
+
+	args, err := diskVirtiofsdArgs("", "/srv", "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--fd=3", "--cache=never", "-o", "source=/srv"}, args)
+
+	args, err = diskVirtiofsdArgs(help, "/srv", "metadata", 16)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--fd=3", "--cache=metadata", "--thread-pool-size=16", "-o", "source=/srv"}, args)
+
+	// Options missing from the virtiofsd help are rejected.
+	_, err = diskVirtiofsdArgs("-o cache=<mode>  cache mode: auto, always, none", "/srv", "metadata", 0)
+	assert.Error(t, err)
+
+	_, err = diskVirtiofsdArgs("-o cache=<mode>  cache mode: auto, always, none", "/srv", "", 16)
+	assert.Error(t, err)
+}
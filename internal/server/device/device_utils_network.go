@@ -16,6 +16,7 @@ import (
 	"github.com/j-keck/arping"
 	"github.com/mdlayher/ndp"
 
+	"github.com/lxc/incus/v6/internal/limitcheck"
 	"github.com/lxc/incus/v6/internal/revert"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	pcidev "github.com/lxc/incus/v6/internal/server/device/pci"
@@ -487,7 +488,8 @@ func networkNICRouteDelete(routeDev string, routes ...string) {
 }
 
 // networkSetupHostVethLimits applies any network rate limits to the veth device specified in the config.
-func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device, bridged bool) error {
+// When rateLimits is false, the ingress and egress limits are handled elsewhere and only cleared from the veth device.
+func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device, bridged bool, rateLimits bool) error {
 	var err error
 
 	veth := d.config["host_name"]
@@ -526,7 +528,7 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 	_ = qdisc.Delete()
 
 	// Apply new limits
-	if d.config["limits.ingress"] != "" {
+	if rateLimits && d.config["limits.ingress"] != "" {
 		qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: veth, Handle: "1:0", Root: true}, Default: "10"}
 		err := qdiscHTB.Add()
 		if err != nil {
@@ -546,7 +548,7 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 		}
 	}
 
-	if d.config["limits.egress"] != "" {
+	if rateLimits && d.config["limits.egress"] != "" {
 		qdisc = &ip.Qdisc{Dev: veth, Handle: "ffff:0", Ingress: true}
 		err := qdisc.Add()
 		if err != nil {
@@ -592,6 +594,139 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 	return nil
 }
 
+// networkVethTC is the tc setup of a host side veth device.
+type networkVethTC struct {
+	qdiscs         []ip.QdiscInfo
+	classes        []ip.ClassStats
+	rootFilters    []ip.FilterInfo
+	ingressFilters []ip.FilterInfo
+}
+
+// hasQdisc returns whether a qdisc of the kind is set up with the handle.
+func (tc networkVethTC) hasQdisc(kind string, handle string) bool {
+	return slices.ContainsFunc(tc.qdiscs, func(qdisc ip.QdiscInfo) bool {
+		return qdisc.Kind == kind && qdisc.Handle == handle
+	})
+}
+
+// networkVerifyHostVethLimits checks that the rate limits in the config are applied to the host side veth device.
+func networkVerifyHostVethLimits(d *deviceCommon) ([]limitcheck.Discrepancy, error) {
+	if d.config["limits.max"] == "" && d.config["limits.ingress"] == "" && d.config["limits.egress"] == "" {
+		return nil, nil
+	}
+
+	veth := d.volatileGet()["host_name"]
+	if veth == "" || !network.InterfaceExists(veth) {
+		return nil, fmt.Errorf("Unknown or missing host side veth device %q", veth)
+	}
+
+	var tc networkVethTC
+	var err error
+
+	tc.qdiscs, err = ip.GetQdiscs(veth)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing tc qdiscs: %w", err)
+	}
+
+	if tc.hasQdisc("htb", "1:") {
+		tc.classes, err = ip.GetClassStats(veth)
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing tc classes: %w", err)
+		}
+
+		tc.rootFilters, err = ip.GetFilters(veth, "1:")
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing tc filters: %w", err)
+		}
+	}
+
+	if tc.hasQdisc("ingress", "ffff:") {
+		tc.ingressFilters, err = ip.GetFilters(veth, "ffff:")
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing tc filters: %w", err)
+		}
+	}
+
+	return networkHostVethLimitsCheck(d.name, d.config, tc)
+}
+
+// networkHostVethLimitsCheck compares the rate limits in the config with the tc setup of the host side veth device,
+// as applied by networkSetupHostVethLimits.
+func networkHostVethLimitsCheck(devName string, config map[string]string, tc networkVethTC) ([]limitcheck.Discrepancy, error) {
+	ingressKey := "limits.ingress"
+	egressKey := "limits.egress"
+	if config["limits.max"] != "" {
+		ingressKey = "limits.max"
+		egressKey = "limits.max"
+	}
+
+	// actual returns the tc rate in bytes per second as a bit rate, or "not applied" if missing.
+	actual := func(rate uint64) string {
+		if rate == 0 {
+			return "not applied"
+		}
+
+		return fmt.Sprintf("%dbit", rate*8)
+	}
+
+	var discrepancies []limitcheck.Discrepancy
+
+	if config[ingressKey] != "" {
+		expected, err := units.ParseBitSizeString(config[ingressKey])
+		if err != nil {
+			return nil, err
+		}
+
+		// The traffic to the instance is shaped by the 1:10 class of the root htb qdisc, fed by an u32 filter.
+		var rate uint64
+		if tc.hasQdisc("htb", "1:") && slices.ContainsFunc(tc.rootFilters, func(filter ip.FilterInfo) bool { return filter.Kind == "u32" }) {
+			for _, class := range tc.classes {
+				if class.Classid == "1:10" {
+					rate = class.Rate
+				}
+			}
+		}
+
+		// tc rounds the bit rate down to bytes.
+		if rate != uint64(expected/8) {
+			discrepancies = append(discrepancies, limitcheck.Discrepancy{Device: devName, Key: ingressKey, Expected: config[ingressKey], Actual: actual(rate)})
+		}
+	}
+
+	// Report limits.max only once.
+	if config[egressKey] != "" && (egressKey != ingressKey || len(discrepancies) == 0) {
+		expected, err := units.ParseBitSizeString(config[egressKey])
+		if err != nil {
+			return nil, err
+		}
+
+		// The traffic from the instance is policed by an u32 filter of the ingress qdisc.
+		var police *ip.ActionInfo
+		if tc.hasQdisc("ingress", "ffff:") {
+			for _, filter := range tc.ingressFilters {
+				if filter.Kind != "u32" {
+					continue
+				}
+
+				for i, action := range filter.Options.Actions {
+					if action.Kind == "police" {
+						police = &filter.Options.Actions[i]
+					}
+				}
+			}
+		}
+
+		// Older versions of tc don't report the rate of police actions.
+		if police == nil {
+			discrepancies = append(discrepancies, limitcheck.Discrepancy{Device: devName, Key: egressKey, Expected: config[egressKey], Actual: actual(0)})
+		} else if police.Rate != 0 && police.Rate != uint64(expected/8) {
+			discrepancies = append(discrepancies, limitcheck.Discrepancy{Device: devName, Key: egressKey, Expected: config[egressKey], Actual: actual(police.Rate)})
+		}
+	}
+
+	return discrepancies, nil
+}
+
 // networkClearHostVethLimits clears any network rate limits to the veth device specified in the config.
 func networkClearHostVethLimits(d *deviceCommon) error {
 	err := d.state.Firewall.InstanceClearNetPrio(d.inst.Project().Name, d.inst.Name(), d.config["host_name"])
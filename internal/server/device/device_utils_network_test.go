@@ -0,0 +1,74 @@
+package device
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/limitcheck"
+	"github.com/lxc/incus/v6/internal/server/ip"
+)
+
+func TestNetworkHostVethLimitsCheck(t *testing.T) {
+	police := func(rate uint64) []ip.FilterInfo {
+		filter := ip.FilterInfo{Kind: "u32"}
+		filter.Options.Actions = []ip.ActionInfo{{Kind: "police", Rate: rate}}
+
+		return []ip.FilterInfo{{Kind: "u32"}, filter}
+	}
+
+	// The setup for 10Mbit in both directions.
+	applied := networkVethTC{
+		qdiscs:         []ip.QdiscInfo{{Kind: "htb", Handle: "1:", Root: true}, {Kind: "ingress", Handle: "ffff:", Parent: "ffff:fff1"}},
+		classes:        []ip.ClassStats{{Classid: "1:10", Rate: 1250000}},
+		rootFilters:    []ip.FilterInfo{{Kind: "u32"}},
+		ingressFilters: police(1250000),
+	}
+
+	tests := []struct {
+		name          string
+		config        map[string]string
+		tc            networkVethTC
+		discrepancies []limitcheck.Discrepancy
+		fails         bool
+	}{
+		{"Applied", map[string]string{"limits.ingress": "10Mbit", "limits.egress": "10Mbit"}, applied, nil, false},
+		{"Applied maximum", map[string]string{"limits.max": "10Mbit"}, applied, nil, false},
+		{"Not set up", map[string]string{"limits.ingress": "10Mbit", "limits.egress": "10Mbit"}, networkVethTC{}, []limitcheck.Discrepancy{
+			{Device: "eth0", Key: "limits.ingress", Expected: "10Mbit", Actual: "not applied"},
+			{Device: "eth0", Key: "limits.egress", Expected: "10Mbit", Actual: "not applied"},
+		}, false},
+		{"Maximum reported once", map[string]string{"limits.max": "10Mbit"}, networkVethTC{}, []limitcheck.Discrepancy{
+			{Device: "eth0", Key: "limits.max", Expected: "10Mbit", Actual: "not applied"},
+		}, false},
+		{"Different rates", map[string]string{"limits.ingress": "20Mbit", "limits.egress": "5Mbit"}, applied, []limitcheck.Discrepancy{
+			{Device: "eth0", Key: "limits.ingress", Expected: "20Mbit", Actual: "10000000bit"},
+			{Device: "eth0", Key: "limits.egress", Expected: "5Mbit", Actual: "10000000bit"},
+		}, false},
+		{"Missing filter", map[string]string{"limits.ingress": "10Mbit"}, networkVethTC{qdiscs: applied.qdiscs, classes: applied.classes}, []limitcheck.Discrepancy{
+			{Device: "eth0", Key: "limits.ingress", Expected: "10Mbit", Actual: "not applied"},
+		}, false},
+		{"Missing class", map[string]string{"limits.ingress": "10Mbit"}, networkVethTC{qdiscs: applied.qdiscs, rootFilters: applied.rootFilters}, []limitcheck.Discrepancy{
+			{Device: "eth0", Key: "limits.ingress", Expected: "10Mbit", Actual: "not applied"},
+		}, false},
+		{"Missing police action", map[string]string{"limits.egress": "10Mbit"}, networkVethTC{qdiscs: applied.qdiscs, ingressFilters: []ip.FilterInfo{{Kind: "u32"}}}, []limitcheck.Discrepancy{
+			{Device: "eth0", Key: "limits.egress", Expected: "10Mbit", Actual: "not applied"},
+		}, false},
+		{"Unreported police rate", map[string]string{"limits.egress": "5Mbit"}, networkVethTC{qdiscs: applied.qdiscs, ingressFilters: police(0)}, nil, false},
+		{"Invalid limit", map[string]string{"limits.ingress": "fast"}, applied, nil, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		discrepancies, err := networkHostVethLimitsCheck("eth0", tt.config, tt.tc)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.discrepancies, discrepancies)
+	}
+}
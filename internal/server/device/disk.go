@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
@@ -50,6 +51,10 @@ const diskSourceAgent = "agent:config"
 // the QEMU driver.
 const DiskVirtiofsdSockMountOpt = "virtiofsdSock"
 
+// DiskVirtiofsDAXMountOpt indicates the mount option prefix used to provide the size of the virtio-fs DAX window
+// to the instance driver.
+const DiskVirtiofsDAXMountOpt = "virtiofsDAX"
+
 // DiskFileDescriptorMountPrefix indicates the mount dev path is using a file descriptor rather than a normal path.
 // The Mount.DevPath field will be expected to be in the format: "fd:<fdNum>:<devPath>".
 // It still includes the original dev path so that the instance driver can perform additional probing of the path
@@ -87,11 +92,38 @@ func (e diskSourceNotFoundError) Unwrap() error {
 	return e.err
 }
 
+// diskSourceTemplate matches the substitution variables of host path templates in disk sources.
+var diskSourceTemplate = regexp.MustCompile(`\{\{\s*([a-z._]+)\s*\}\}`)
+
+// diskSourceExpand replaces the substitution variables of a host path template with the instance values.
+func diskSourceExpand(source string, instName string, projectName string) (string, error) {
+	var err error
+
+	expanded := diskSourceTemplate.ReplaceAllStringFunc(source, func(match string) string {
+		variable := diskSourceTemplate.FindStringSubmatch(match)[1]
+
+		switch variable {
+		case "instance.name":
+			return instName
+		case "instance.project":
+			return projectName
+		}
+
+		err = fmt.Errorf("Unknown variable %q in disk source %q", variable, source)
+		return match
+	})
+
+	return expanded, err
+}
+
 type disk struct {
 	deviceCommon
 
 	restrictedParentSourcePath string
 	pool                       storagePools.Pool
+
+	// sourceTemplate is the host path template the source was expanded from, the path being created on start.
+	sourceTemplate string
 }
 
 // CanMigrate returns whether the device can be migrated to any other cluster member.
@@ -119,6 +151,17 @@ func (d *disk) sourceIsCeph() bool {
 	return strings.HasPrefix(d.config["source"], "ceph:")
 }
 
+// virtiofsConfigKey returns the first virtio-fs tuning option set on the disk, if any.
+func (d *disk) virtiofsConfigKey() string {
+	for _, key := range []string{"virtiofs.cache", "virtiofs.thread_pool_size", "virtiofs.dax"} {
+		if d.config[key] != "" {
+			return key
+		}
+	}
+
+	return ""
+}
+
 // CanHotPlug returns whether the device can be managed whilst the instance is running.
 func (d *disk) CanHotPlug() bool {
 	// All disks can be hot-plugged.
@@ -336,6 +379,31 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		//  required: no
 		//  shortdesc: Only for VMs: Override the bus for the device (`nvme`, `virtio-blk`, or `virtio-scsi`)
 		"io.bus": validate.Optional(validate.IsOneOf("nvme", "virtio-blk", "virtio-scsi")),
+
+		// gendoc:generate(entity=devices, group=disk, key=virtiofs.cache)
+		//
+		// ---
+		//  type: string
+		//  default: `never`
+		//  required: no
+		//  shortdesc: Only for VMs: Caching mode of `virtiofsd` for directory shares (`never`, `metadata`, `auto` or `always`)
+		"virtiofs.cache": validate.Optional(validate.IsOneOf("never", "metadata", "auto", "always")),
+
+		// gendoc:generate(entity=devices, group=disk, key=virtiofs.thread_pool_size)
+		//
+		// ---
+		//  type: integer
+		//  required: no
+		//  shortdesc: Only for VMs: Number of threads of `virtiofsd` handling requests for directory shares
+		"virtiofs.thread_pool_size": validate.Optional(validate.IsInRange(1, 1024)),
+
+		// gendoc:generate(entity=devices, group=disk, key=virtiofs.dax)
+		//
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Only for VMs: Size of the DAX window mapping the files of directory shares into the guest memory (for example, `1GiB`)
+		"virtiofs.dax": validate.Optional(validate.IsSize),
 	}
 
 	err := d.config.Validate(rules)
@@ -343,6 +411,25 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	// Expand host path templates, so each instance gets its own host path.
+	if d.config["pool"] == "" && diskSourceTemplate.MatchString(d.config["source"]) {
+		if d.inst != nil {
+			instName, _, _ := api.GetParentAndSnapshotName(d.inst.Name())
+
+			d.sourceTemplate = d.config["source"]
+			d.config["source"], err = diskSourceExpand(d.sourceTemplate, instName, d.inst.Project().Name)
+			if err != nil {
+				return err
+			}
+		} else {
+			// Only check the variables when validating profiles.
+			_, err = diskSourceExpand(d.config["source"], "", "")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	if instConf.Type() == instancetype.Container && d.config["io.bus"] != "" {
 		return fmt.Errorf("IO bus configuration cannot be applied to containers")
 	}
@@ -351,6 +438,17 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("IO cache configuration cannot be applied to containers")
 	}
 
+	key := d.virtiofsConfigKey()
+	if key != "" {
+		if instConf.Type() == instancetype.Container {
+			return fmt.Errorf("The %q option cannot be applied to containers", key)
+		}
+
+		if d.config["path"] == "/" || d.sourceIsCeph() {
+			return fmt.Errorf("The %q option is only supported for directory shares", key)
+		}
+	}
+
 	if d.config["required"] != "" && d.config["optional"] != "" {
 		return fmt.Errorf(`Cannot use both "required" and deprecated "optional" properties at the same time`)
 	}
@@ -415,7 +513,7 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 	// source path exists when the disk device is required, is not an external ceph/cephfs source and is not a
 	// VM cloud-init drive. We only check this when an instance is loaded to avoid validating snapshot configs
 	// that may contain older config that no longer exists which can prevent migrations.
-	if d.inst != nil && srcPathIsLocal && d.isRequired(d.config) && !util.PathExists(d.config["source"]) {
+	if d.inst != nil && srcPathIsLocal && d.isRequired(d.config) && d.sourceTemplate == "" && !util.PathExists(d.config["source"]) {
 		return fmt.Errorf("Missing source path %q for disk %q", d.config["source"], d.name)
 	}
 
@@ -641,6 +739,14 @@ func (d *disk) validateEnvironmentSourcePath() error {
 
 	sourceHostPath := d.config["source"]
 
+	// Create the host path of the instance on first use when coming from a template.
+	if d.sourceTemplate != "" && !util.PathExists(sourceHostPath) {
+		err := d.createSourceTemplatePath(sourceHostPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check local external disk source path exists, but don't follow symlinks here (as we let openat2 do that
 	// safely later).
 	_, err := os.Lstat(sourceHostPath)
@@ -675,6 +781,106 @@ func (d *disk) validateEnvironmentSourcePath() error {
 	return nil
 }
 
+// checkSourceTemplatePath checks that a host path expanded from a template is allowed by the project.
+func (d *disk) checkSourceTemplatePath(sourceHostPath string) error {
+	instProject := d.inst.Project()
+	if util.IsTrue(instProject.Config["restricted"]) && instProject.Config["restricted.devices.disk.paths"] != "" {
+		allowed, _ := project.CheckRestrictedDevicesDiskPaths(instProject.Config, sourceHostPath)
+		if !allowed {
+			return fmt.Errorf("Disk source path %q not allowed by project for disk %q", sourceHostPath, d.name)
+		}
+	}
+
+	return nil
+}
+
+// createSourceTemplatePath creates the host path expanded from a template, owned by the root user of the instance.
+func (d *disk) createSourceTemplatePath(sourceHostPath string) error {
+	// Don't create paths outside of those allowed by the project.
+	err := d.checkSourceTemplatePath(sourceHostPath)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(sourceHostPath, 0o711)
+	if err != nil {
+		return fmt.Errorf("Failed creating source path %q for disk %q: %w", sourceHostPath, d.name, err)
+	}
+
+	// Shifted mounts and virtual machines see the host ownership as is.
+	if d.inst.Type() != instancetype.Container || util.IsTrue(d.config["shift"]) {
+		return nil
+	}
+
+	c, ok := d.inst.(instance.Container)
+	if !ok {
+		return nil
+	}
+
+	var instIdmap *idmap.Set
+	if c.IsRunning() {
+		instIdmap, err = c.CurrentIdmap()
+	} else {
+		instIdmap, err = c.NextIdmap()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if instIdmap == nil {
+		return nil
+	}
+
+	uid, gid := instIdmap.ShiftFromNS(0, 0)
+
+	err = os.Chown(sourceHostPath, int(uid), int(gid))
+	if err != nil {
+		return fmt.Errorf("Failed setting ownership of source path %q for disk %q: %w", sourceHostPath, d.name, err)
+	}
+
+	return nil
+}
+
+// InstanceRename moves the host path expanded from a template to the one of the new instance name, so the
+// instance keeps its data.
+func (d *disk) InstanceRename(oldName string, running bool) error {
+	if d.sourceTemplate == "" || d.inst.IsSnapshot() {
+		return nil
+	}
+
+	oldSourcePath, err := diskSourceExpand(d.sourceTemplate, oldName, d.inst.Project().Name)
+	if err != nil {
+		return err
+	}
+
+	sourceHostPath := d.config["source"]
+	if oldSourcePath == sourceHostPath || !util.PathExists(oldSourcePath) {
+		return nil
+	}
+
+	if util.PathExists(sourceHostPath) {
+		return fmt.Errorf("Source path %q for disk %q already exists", sourceHostPath, d.name)
+	}
+
+	err = d.checkSourceTemplatePath(sourceHostPath)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(sourceHostPath), 0o711)
+	if err != nil {
+		return fmt.Errorf("Failed creating parent of source path %q for disk %q: %w", sourceHostPath, d.name, err)
+	}
+
+	err = os.Rename(oldSourcePath, sourceHostPath)
+	if err != nil {
+		return fmt.Errorf("Failed renaming source path %q for disk %q: %w", oldSourcePath, d.name, err)
+	}
+
+	return nil
+}
+
 // validateEnvironment checks the runtime environment for correctness.
 func (d *disk) validateEnvironment() error {
 	if d.inst.Type() != instancetype.VM && slices.Contains([]string{diskSourceCloudInit, diskSourceAgent}, d.config["source"]) {
@@ -1146,6 +1352,11 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 				// If the pool is ceph backed and a block device, don't mount it, instead pass config to QEMU instance
 				// to use the built in RBD support.
 				if d.pool.Driver().Info().Name == "ceph" && (contentType == db.StoragePoolVolumeContentTypeBlock || contentType == db.StoragePoolVolumeContentTypeISO) {
+					key := d.virtiofsConfigKey()
+					if key != "" {
+						return nil, fmt.Errorf("The %q option is only supported for directory shares", key)
+					}
+
 					config := d.pool.ToAPI().Config
 					poolName := config["ceph.osd.pool_name"]
 
@@ -1233,10 +1444,26 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 					logPath := filepath.Join(d.inst.LogPath(), fmt.Sprintf("disk.%s.log", d.name))
 					_ = os.Remove(logPath) // Remove old log if needed.
 
-					revertFunc, unixListener, err := DiskVMVirtiofsdStart(d.state.OS.ExecPath, d.inst, sockPath, pidPath, logPath, mount.DevPath, rawIDMaps.Entries)
+					var threadPoolSize int
+					if d.config["virtiofs.thread_pool_size"] != "" {
+						size, err := strconv.Atoi(d.config["virtiofs.thread_pool_size"])
+						if err != nil {
+							return fmt.Errorf("Invalid virtiofs.thread_pool_size: %w", err)
+						}
+
+						threadPoolSize = size
+					}
+
+					revertFunc, unixListener, err := DiskVMVirtiofsdStart(d.state.OS.ExecPath, d.inst, sockPath, pidPath, logPath, mount.DevPath, rawIDMaps.Entries, d.config["virtiofs.cache"], threadPoolSize)
 					if err != nil {
 						var errUnsupported UnsupportedError
 						if errors.As(err, &errUnsupported) {
+							// The virtio-fs options don't apply to 9p, so don't fall back when they're set.
+							key := d.virtiofsConfigKey()
+							if key != "" {
+								return fmt.Errorf("The %q option requires virtio-fs: %w", key, err)
+							}
+
 							d.logger.Warn("Unable to use virtio-fs for device, using 9p as a fallback", logger.Ctx{"err": errUnsupported})
 
 							if errUnsupported == ErrMissingVirtiofsd {
@@ -1271,6 +1498,11 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 					// QEMU driver also setup the virtio-fs share.
 					mount.Opts = append(mount.Opts, fmt.Sprintf("%s=%s", DiskVirtiofsdSockMountOpt, sockPath))
 
+					// Pass the size of the DAX window, if any, to the QEMU driver in the same way.
+					if d.config["virtiofs.dax"] != "" {
+						mount.Opts = append(mount.Opts, fmt.Sprintf("%s=%s", DiskVirtiofsDAXMountOpt, d.config["virtiofs.dax"]))
+					}
+
 					return nil
 				}()
 				if err != nil {
@@ -1302,6 +1534,11 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 					}
 				}
 			} else {
+				key := d.virtiofsConfigKey()
+				if key != "" {
+					return nil, fmt.Errorf("The %q option is only supported for directory shares", key)
+				}
+
 				f, err := d.localSourceOpen(mount.DevPath)
 				if err != nil {
 					return nil, err
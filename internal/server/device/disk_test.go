@@ -0,0 +1,119 @@
+package device
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestDiskSourceExpand(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "No variable",
+			source:   "/srv/data",
+			expected: "/srv/data",
+		},
+		{
+			name:     "Instance name",
+			source:   "/srv/data/{{instance.name}}",
+			expected: "/srv/data/c1",
+		},
+		{
+			name:     "Project and instance name with spaces",
+			source:   "/srv/{{ instance.project }}/{{ instance.name }}/data",
+			expected: "/srv/foo/c1/data",
+		},
+		{
+			name:   "Unknown variable",
+			source: "/srv/{{ instance.type }}",
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := diskSourceExpand(tt.source, "c1", "foo")
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, expanded)
+		})
+	}
+}
+
+// diskRenameInstance is a fake instance with a name and a project.
+type diskRenameInstance struct {
+	instance.Instance
+
+	name string
+}
+
+func (i *diskRenameInstance) Name() string {
+	return i.name
+}
+
+func (i *diskRenameInstance) IsSnapshot() bool {
+	return false
+}
+
+func (i *diskRenameInstance) Project() api.Project {
+	return api.Project{Name: "foo"}
+}
+
+func TestDiskInstanceRename(t *testing.T) {
+	newDisk := func(template string, instName string) *disk {
+		source, err := diskSourceExpand(template, instName, "foo")
+		require.NoError(t, err)
+
+		d := &disk{sourceTemplate: template}
+		d.inst = &diskRenameInstance{name: instName}
+		d.name = "data"
+		d.config = deviceConfig.Device{"source": source}
+
+		return d
+	}
+
+	dir := t.TempDir()
+	template := filepath.Join(dir, "{{ instance.project }}", "{{ instance.name }}")
+
+	// The host path of the previous name is moved along with its content.
+	oldPath := filepath.Join(dir, "foo", "c1")
+	require.NoError(t, os.MkdirAll(oldPath, 0o711))
+	require.NoError(t, os.WriteFile(filepath.Join(oldPath, "file"), []byte("data"), 0o600))
+
+	err := newDisk(template, "c2").InstanceRename("c1", false)
+	require.NoError(t, err)
+	assert.NoDirExists(t, oldPath)
+	assert.FileExists(t, filepath.Join(dir, "foo", "c2", "file"))
+
+	// Nothing is done when the previous host path was never created.
+	err = newDisk(template, "c3").InstanceRename("c1", false)
+	require.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(dir, "foo", "c3"))
+
+	// The host path of another instance is never overwritten.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo", "c4"), 0o711))
+	err = newDisk(template, "c4").InstanceRename("c2", false)
+	assert.Error(t, err)
+	assert.DirExists(t, filepath.Join(dir, "foo", "c2"))
+
+	// Templates which don't use the instance name are left alone.
+	err = newDisk(filepath.Join(dir, "{{ instance.project }}"), "c5").InstanceRename("c2", false)
+	require.NoError(t, err)
+	assert.DirExists(t, filepath.Join(dir, "foo", "c2"))
+}
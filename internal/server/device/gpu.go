@@ -68,9 +68,9 @@ func gpuValidationRules(requiredFields []string, optionalFields []string) map[st
 	return validators
 }
 
-// Check if the device matches the given GPU card.
+// GPUSelected checks if the device matches the given GPU card.
 // It matches based on vendorid, pci, productid or id setting of the device.
-func gpuSelected(device config.Device, gpu api.ResourcesGPUCard) bool {
+func GPUSelected(device config.Device, gpu api.ResourcesGPUCard) bool {
 	return !((device["vendorid"] != "" && gpu.VendorID != device["vendorid"]) ||
 		(device["pci"] != "" && gpu.PCIAddress != device["pci"]) ||
 		(device["productid"] != "" && gpu.ProductID != device["productid"]) ||
@@ -120,7 +120,7 @@ func (d *gpuMIG) Start() (*deviceConfig.RunConfig, error) {
 	var pciAddress string
 	for _, gpu := range gpus.Cards {
 		// Skip any cards that are not selected.
-		if !gpuSelected(d.Config(), gpu) {
+		if !GPUSelected(d.Config(), gpu) {
 			continue
 		}
 
@@ -197,7 +197,7 @@ func (d *gpuSRIOV) getVF() (string, int, error) {
 
 	for _, gpu := range gpus.Cards {
 		// Skip any cards that are not selected.
-		if !gpuSelected(d.Config(), gpu) {
+		if !GPUSelected(d.Config(), gpu) {
 			continue
 		}
 
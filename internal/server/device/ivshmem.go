@@ -0,0 +1,240 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lxc/incus/v6/internal/linux"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/units"
+	"github.com/lxc/incus/v6/shared/util"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+// ivshmemBasePath is the directory holding the shared memory regions of ivshmem devices.
+const ivshmemBasePath = "/dev/shm/incus-ivshmem"
+
+// The regions are held in host memory, so their size and number are bounded.
+const (
+	ivshmemMaxSize    = 4 * 1024 * 1024 * 1024
+	ivshmemMaxDevices = 4
+)
+
+type ivshmem struct {
+	deviceCommon
+}
+
+// validateConfig checks the supplied config for correctness.
+func (d *ivshmem) validateConfig(instConf instance.ConfigReader) error {
+	if !instanceSupported(instConf.Type(), instancetype.VM) {
+		return ErrUnsupportedDevType
+	}
+
+	rules := map[string]func(string) error{
+		// gendoc:generate(entity=devices, group=ivshmem, key=name)
+		//
+		// ---
+		//  type: string
+		//  defaultdesc: device name
+		//  required: no
+		//  shortdesc: Name of the shared memory region, instances of the same project using the same name share it
+		"name": validate.Optional(validate.IsDeviceName),
+
+		// gendoc:generate(entity=devices, group=ivshmem, key=size)
+		//
+		// ---
+		//  type: string
+		//  required: yes
+		//  shortdesc: Size of the shared memory region, must be a power of two (for example, `64MiB`) up to `4GiB`
+		"size": validate.IsSize,
+	}
+
+	err := d.config.Validate(rules)
+	if err != nil {
+		return fmt.Errorf("Failed to validate config: %w", err)
+	}
+
+	size, err := units.ParseByteSizeString(d.config["size"])
+	if err != nil {
+		return err
+	}
+
+	if size <= 0 || size&(size-1) != 0 {
+		return fmt.Errorf("The size of the shared memory region must be a power of two")
+	}
+
+	if size > ivshmemMaxSize {
+		return fmt.Errorf("The size of the shared memory region can't exceed %s", units.GetByteSizeStringIEC(ivshmemMaxSize, 0))
+	}
+
+	count := 0
+	for _, devConfig := range instConf.ExpandedDevices() {
+		if devConfig["type"] == "ivshmem" {
+			count++
+		}
+	}
+
+	if count > ivshmemMaxDevices {
+		return fmt.Errorf("Instances can't have more than %d shared memory devices", ivshmemMaxDevices)
+	}
+
+	return nil
+}
+
+// CanHotPlug returns whether the device can be managed whilst the instance is running.
+// The shared memory device is only added to QEMU on startup.
+func (d *ivshmem) CanHotPlug() bool {
+	return false
+}
+
+// regionName returns the name of the shared memory region of the device.
+func (d *ivshmem) regionName() string {
+	if d.config["name"] != "" {
+		return d.config["name"]
+	}
+
+	return d.name
+}
+
+// regionPath returns the path of the file backing the shared memory region on the host.
+func (d *ivshmem) regionPath() string {
+	return filepath.Join(ivshmemBasePath, d.inst.Project().Name, linux.PathNameEncode(d.regionName()))
+}
+
+// Start is run when the device is added to the instance.
+func (d *ivshmem) Start() (*deviceConfig.RunConfig, error) {
+	if util.IsTrue(d.inst.ExpandedConfig()["migration.stateful"]) {
+		return nil, fmt.Errorf("Shared memory devices cannot be used when migration.stateful is enabled")
+	}
+
+	size, err := units.ParseByteSizeString(d.config["size"])
+	if err != nil {
+		return nil, err
+	}
+
+	path := d.regionPath()
+
+	err = os.MkdirAll(filepath.Dir(path), 0o711)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating shared memory directory: %w", err)
+	}
+
+	// Create the region if no other instance is using it already.
+	err = d.createRegion(path, size)
+	if err != nil {
+		return nil, err
+	}
+
+	runConf := deviceConfig.RunConfig{}
+	runConf.IvshmemDevice = append(runConf.IvshmemDevice,
+		[]deviceConfig.RunConfigItem{
+			{Key: "devName", Value: d.name},
+			{Key: "path", Value: path},
+			{Key: "size", Value: fmt.Sprintf("%d", size)},
+		}...)
+
+	return &runConf, nil
+}
+
+// createRegion creates the file backing the shared memory region unless it exists already, in which case
+// its size is checked. The file is sized in a temporary file first, so concurrent users never see it partially
+// created.
+func (d *ivshmem) createRegion(path string, size int64) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return fmt.Errorf("Failed creating shared memory region %q: %w", d.regionName(), err)
+	}
+
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	err = f.Truncate(size)
+	if err != nil {
+		return fmt.Errorf("Failed sizing shared memory region %q: %w", d.regionName(), err)
+	}
+
+	// QEMU may be running as an unprivileged user.
+	if d.state.OS.UnprivUser != "" {
+		err = f.Chown(int(d.state.OS.UnprivUID), -1)
+		if err != nil {
+			return fmt.Errorf("Failed setting ownership of shared memory region %q: %w", d.regionName(), err)
+		}
+	}
+
+	// Linking fails if the region was created by another instance in the meantime.
+	err = os.Link(f.Name(), path)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("Failed creating shared memory region %q: %w", d.regionName(), err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("Failed checking shared memory region %q: %w", d.regionName(), err)
+	}
+
+	if info.Size() != size {
+		return fmt.Errorf("Shared memory region %q already exists with a size of %s", d.regionName(), units.GetByteSizeStringIEC(info.Size(), 2))
+	}
+
+	return nil
+}
+
+// Stop is run when the device is removed from the instance.
+func (d *ivshmem) Stop() (*deviceConfig.RunConfig, error) {
+	runConf := deviceConfig.RunConfig{
+		PostHooks: []func() error{d.postStop},
+	}
+
+	return &runConf, nil
+}
+
+// postStop is run after the device is removed from the instance.
+// It frees the shared memory region once no other running instance on this server uses it.
+func (d *ivshmem) postStop() error {
+	instances, err := instance.LoadNodeAll(d.state, instancetype.VM)
+	if err != nil {
+		return err
+	}
+
+	for _, inst := range instances {
+		if inst.ID() == d.inst.ID() || inst.Project().Name != d.inst.Project().Name || !inst.IsRunning() {
+			continue
+		}
+
+		for devName, devConfig := range inst.ExpandedDevices() {
+			if devConfig["type"] != "ivshmem" {
+				continue
+			}
+
+			name := devConfig["name"]
+			if name == "" {
+				name = devName
+			}
+
+			if name == d.regionName() {
+				return nil
+			}
+		}
+	}
+
+	err = os.Remove(d.regionPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Failed removing shared memory region %q: %w", d.regionName(), err)
+	}
+
+	d.logger.Debug("Removed shared memory region", logger.Ctx{"region": d.regionName()})
+
+	return nil
+}
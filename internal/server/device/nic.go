@@ -35,6 +35,7 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		"ipv6.address":                         validate.Optional(validate.IsNetworkAddressV6),
 		"ipv4.routes":                          validate.Optional(validate.IsListOf(validate.IsNetworkV4)),
 		"ipv6.routes":                          validate.Optional(validate.IsListOf(validate.IsNetworkV6)),
+		"ipv6.delegated_prefixes":              validate.Optional(validate.IsListOf(validate.IsNetworkV6)),
 		"boot.priority":                        validate.Optional(validate.IsUint32),
 		"ipv4.gateway":                         networkValidGateway,
 		"ipv6.gateway":                         networkValidGateway,
@@ -19,6 +19,7 @@ import (
 	"github.com/google/gopacket/layers"
 	"github.com/mdlayher/netx/eui64"
 
+	"github.com/lxc/incus/v6/internal/limitcheck"
 	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
@@ -545,7 +546,7 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 	}
 
 	// Apply host-side limits.
-	err = networkSetupHostVethLimits(&d.deviceCommon, nil, true)
+	err = d.setupHostLimits(nil)
 	if err != nil {
 		return nil, err
 	}
@@ -735,7 +736,7 @@ func (d *nicBridged) Update(oldDevices deviceConfig.Devices, isRunning bool) err
 		}
 
 		// Apply host-side limits.
-		err = networkSetupHostVethLimits(&d.deviceCommon, oldConfig, true)
+		err = d.setupHostLimits(oldConfig)
 		if err != nil {
 			return err
 		}
@@ -797,6 +798,10 @@ func (d *nicBridged) Stop() (*deviceConfig.RunConfig, error) {
 	// Populate device config with volatile fields (hwaddr and host_name) if needed.
 	networkVethFillFromVolatile(d.config, d.volatileGet())
 
+	if d.usesNetworkShaping() {
+		network.BridgeShapingRemoveMember(d.config["parent"], d.config["host_name"])
+	}
+
 	err = networkClearHostVethLimits(&d.deviceCommon)
 	if err != nil {
 		return nil, err
@@ -880,6 +885,36 @@ func (d *nicBridged) Remove() error {
 	return nil
 }
 
+// usesNetworkShaping returns whether the NIC's rate limits are part of the parent network's traffic shaping hierarchy.
+func (d *nicBridged) usesNetworkShaping() bool {
+	return d.network != nil && d.network.Config()["bridge.driver"] != "openvswitch" && network.BridgeShapingEnabled(d.network.Config())
+}
+
+// setupHostLimits applies the NIC's limits. When the parent network uses hierarchical traffic shaping, the
+// ingress and egress limits cap the NIC's class in the network's hierarchy rather than being applied on the
+// host-side veth device.
+func (d *nicBridged) setupHostLimits(oldConfig deviceConfig.Device) error {
+	shaped := d.usesNetworkShaping()
+
+	err := networkSetupHostVethLimits(&d.deviceCommon, oldConfig, true, !shaped)
+	if err != nil {
+		return err
+	}
+
+	if !shaped {
+		return nil
+	}
+
+	ingress, egress := network.BridgeShapingNICLimits(d.config)
+
+	err = network.BridgeShapingAddMember(d.config["parent"], d.inst.Project().Name, d.inst.Project().Config, d.config["host_name"], d.config["hwaddr"], ingress, egress)
+	if err != nil {
+		return fmt.Errorf("Failed adding NIC to network traffic shaping: %w", err)
+	}
+
+	return nil
+}
+
 // rebuildDnsmasqEntry rebuilds the dnsmasq host entry if connected to a managed network and reloads dnsmasq.
 func (d *nicBridged) rebuildDnsmasqEntry() error {
 	// Rebuild dnsmasq config if parent is a managed bridge network using dnsmasq.
@@ -970,8 +1005,46 @@ func (d *nicBridged) setupHostFilters(oldConfig deviceConfig.Device) (revert.Hoo
 	return cleanup, nil
 }
 
+// InstanceRename moves the static DHCP allocation and the network filters of the device from the previous
+// name of the running instance to its new name.
+func (d *nicBridged) InstanceRename(oldName string, running bool) error {
+	if !running {
+		return nil
+	}
+
+	networkVethFillFromVolatile(d.config, d.volatileGet())
+
+	filtering := util.IsTrue(d.config["security.mac_filtering"]) || util.IsTrue(d.config["security.ipv4_filtering"]) || util.IsTrue(d.config["security.ipv6_filtering"])
+	if filtering {
+		d.removeInstanceFilters(oldName, d.config)
+	}
+
+	if d.config["parent"] != "" {
+		dnsmasq.ConfigMutex.Lock()
+		err := dnsmasq.RenameStaticEntry(d.config["parent"], d.inst.Project().Name, oldName, d.inst.Name(), d.Name())
+		dnsmasq.ConfigMutex.Unlock()
+		if err != nil {
+			return fmt.Errorf("Failed renaming static DHCP allocation: %w", err)
+		}
+	}
+
+	if filtering {
+		err := d.setFilters()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // removeFilters removes any network level filters defined for the instance.
 func (d *nicBridged) removeFilters(m deviceConfig.Device) {
+	d.removeInstanceFilters(d.inst.Name(), m)
+}
+
+// removeInstanceFilters removes any network level filters defined for the instance under the given name.
+func (d *nicBridged) removeInstanceFilters(instanceName string, m deviceConfig.Device) {
 	if m["hwaddr"] == "" {
 		d.logger.Error("Failed to remove network filters: hwaddr not defined")
 		return
@@ -991,7 +1064,7 @@ func (d *nicBridged) removeFilters(m deviceConfig.Device) {
 	// Remove filters for static MAC and IPs (if specified above).
 	// This covers the case when filtering is used with an unmanaged bridge.
 	d.logger.Debug("Clearing instance firewall static filters", logger.Ctx{"parent": m["parent"], "host_name": m["host_name"], "hwaddr": m["hwaddr"], "IPv4Nets": IPv4Nets, "IPv6Nets": IPv6Nets})
-	err = d.state.Firewall.InstanceClearBridgeFilter(d.inst.Project().Name, d.inst.Name(), d.name, m["parent"], m["host_name"], m["hwaddr"], IPv4Nets, IPv6Nets)
+	err = d.state.Firewall.InstanceClearBridgeFilter(d.inst.Project().Name, instanceName, d.name, m["parent"], m["host_name"], m["hwaddr"], IPv4Nets, IPv6Nets)
 	if err != nil {
 		d.logger.Error("Failed to remove static IP network filters", logger.Ctx{"err": err})
 	}
@@ -999,14 +1072,14 @@ func (d *nicBridged) removeFilters(m deviceConfig.Device) {
 	// If allowedIPNets returned nil for IPv4 or IPv6, it is possible that total protocol blocking was set up
 	// because the device has a managed parent network with DHCP disabled. Pass in empty slices to catch this case.
 	d.logger.Debug("Clearing instance total protocol filters", logger.Ctx{"parent": m["parent"], "host_name": m["host_name"], "hwaddr": m["hwaddr"], "IPv4Nets": IPv4Nets, "IPv6Nets": IPv6Nets})
-	err = d.state.Firewall.InstanceClearBridgeFilter(d.inst.Project().Name, d.inst.Name(), d.name, m["parent"], m["host_name"], m["hwaddr"], make([]*net.IPNet, 0), make([]*net.IPNet, 0))
+	err = d.state.Firewall.InstanceClearBridgeFilter(d.inst.Project().Name, instanceName, d.name, m["parent"], m["host_name"], m["hwaddr"], make([]*net.IPNet, 0), make([]*net.IPNet, 0))
 	if err != nil {
 		d.logger.Error("Failed to remove total protocol network filters", logger.Ctx{"err": err})
 	}
 
 	// Read current static DHCP IP allocation configured from dnsmasq host config (if exists).
 	// This covers the case when IPs are not defined in config, but have been assigned in managed DHCP.
-	deviceStaticFileName := dnsmasq.StaticAllocationFileName(d.inst.Project().Name, d.inst.Name(), d.Name())
+	deviceStaticFileName := dnsmasq.StaticAllocationFileName(d.inst.Project().Name, instanceName, d.Name())
 	_, IPv4Alloc, IPv6Alloc, err := dnsmasq.DHCPStaticAllocation(m["parent"], deviceStaticFileName)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -1039,7 +1112,7 @@ func (d *nicBridged) removeFilters(m deviceConfig.Device) {
 	}
 
 	d.logger.Debug("Clearing instance firewall dynamic filters", logger.Ctx{"parent": m["parent"], "host_name": m["host_name"], "hwaddr": m["hwaddr"], "ipv4": IPv4Alloc.IP, "ipv6": IPv6Alloc.IP})
-	err = d.state.Firewall.InstanceClearBridgeFilter(d.inst.Project().Name, d.inst.Name(), d.name, m["parent"], m["host_name"], m["hwaddr"], IPv4AllocNets, IPv6AllocNets)
+	err = d.state.Firewall.InstanceClearBridgeFilter(d.inst.Project().Name, instanceName, d.name, m["parent"], m["host_name"], m["hwaddr"], IPv4AllocNets, IPv6AllocNets)
 	if err != nil {
 		logger.Errorf("Failed to remove DHCP network assigned filters  for %q: %v", d.name, err)
 	}
@@ -1759,3 +1832,13 @@ func (d *nicBridged) Register() error {
 
 	return nil
 }
+
+// VerifyLimits checks that the NIC's rate limits are applied on the host side veth device.
+func (d *nicBridged) VerifyLimits() ([]limitcheck.Discrepancy, error) {
+	// Limits which are part of the parent network's shaping hierarchy aren't applied on the veth device.
+	if d.usesNetworkShaping() {
+		return nil, nil
+	}
+
+	return networkVerifyHostVethLimits(&d.deviceCommon)
+}
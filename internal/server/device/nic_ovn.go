@@ -976,7 +976,6 @@ func (d *nicOVN) postStop() error {
 		}
 
 		// Restoring host-side interface.
-		network.SRIOVVirtualFunctionMutex.Lock()
 		err = networkSRIOVRestoreVF(d.deviceCommon, false, v)
 		if err != nil {
 			network.SRIOVVirtualFunctionMutex.Unlock()
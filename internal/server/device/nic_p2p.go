@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/lxc/incus/v6/internal/limitcheck"
 	"github.com/lxc/incus/v6/internal/revert"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/instance"
@@ -131,7 +132,7 @@ func (d *nicP2P) Start() (*deviceConfig.RunConfig, error) {
 	}
 
 	// Apply host-side limits.
-	err = networkSetupHostVethLimits(&d.deviceCommon, nil, false)
+	err = networkSetupHostVethLimits(&d.deviceCommon, nil, false, true)
 	if err != nil {
 		return nil, err
 	}
@@ -190,7 +191,7 @@ func (d *nicP2P) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	}
 
 	// Apply host-side limits.
-	err = networkSetupHostVethLimits(&d.deviceCommon, oldConfig, false)
+	err = networkSetupHostVethLimits(&d.deviceCommon, oldConfig, false, true)
 	if err != nil {
 		return err
 	}
@@ -237,3 +238,8 @@ func (d *nicP2P) postStop() error {
 
 	return nil
 }
+
+// VerifyLimits checks that the NIC's rate limits are applied on the host side veth device.
+func (d *nicP2P) VerifyLimits() ([]limitcheck.Discrepancy, error) {
+	return networkVerifyHostVethLimits(&d.deviceCommon)
+}
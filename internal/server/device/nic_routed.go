@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/lxc/incus/v6/internal/limitcheck"
 	"github.com/lxc/incus/v6/internal/revert"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/instance"
@@ -25,6 +27,10 @@ var nicRoutedIPGateway = map[string]string{
 	"ipv6": "fe80::1",
 }
 
+// nicRoutedPrefixProxyMaxSize is the smallest delegated IPv6 prefix size for which individual neighbour
+// proxy entries get added on the parent interface. Larger prefixes must be routed to the host upstream.
+const nicRoutedPrefixProxyMaxSize = 120
+
 type nicRouted struct {
 	deviceCommon
 	effectiveParentName string
@@ -43,7 +49,7 @@ func (d *nicRouted) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv6.delegated_prefixes"}
 }
 
 // validateConfig checks the supplied config for correctness.
@@ -74,6 +80,7 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		"ipv6.gateway",
 		"ipv4.routes",
 		"ipv6.routes",
+		"ipv6.delegated_prefixes",
 		"ipv4.host_address",
 		"ipv6.host_address",
 		"ipv4.host_table",
@@ -117,6 +124,20 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		}
 	}
 
+	// Detect overlapping delegated prefixes.
+	prefixes, err := d.delegatedPrefixes(d.config)
+	if err != nil {
+		return err
+	}
+
+	for i, prefix := range prefixes {
+		for _, other := range prefixes[i+1:] {
+			if prefix.Contains(other.IP) || other.Contains(prefix.IP) {
+				return fmt.Errorf("Delegated prefix %q overlaps with %q", prefix.String(), other.String())
+			}
+		}
+	}
+
 	// Ensure that VLAN setting is only used with parent setting.
 	if d.config["parent"] == "" && d.config["vlan"] != "" {
 		return fmt.Errorf("The vlan setting can only be used when combined with a parent interface")
@@ -149,7 +170,7 @@ func (d *nicRouted) validateEnvironment() error {
 		}
 
 		// Check necessary "all" sysctls are configured for use with l2proxy parent for routed mode.
-		if d.config["ipv6.address"] != "" {
+		if d.usesIPv6() {
 			// net.ipv6.conf.all.forwarding=1 is required to enable general packet forwarding for IPv6.
 			ipv6FwdPath := fmt.Sprintf("net/ipv6/conf/%s/forwarding", "all")
 			sysctlVal, err := localUtil.SysctlGet(ipv6FwdPath)
@@ -190,7 +211,7 @@ func (d *nicRouted) validateEnvironment() error {
 		}
 
 		// Check necessary devic specific sysctls are configured for use with l2proxy parent for routed mode.
-		if d.config["ipv6.address"] != "" {
+		if d.usesIPv6() {
 			ipv6FwdPath := fmt.Sprintf("net/ipv6/conf/%s/forwarding", d.effectiveParentName)
 			sysctlVal, err := localUtil.SysctlGet(ipv6FwdPath)
 			if err != nil {
@@ -347,7 +368,7 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 	networkVethFillFromVolatile(d.config, saveData)
 
 	// Apply host-side limits.
-	err = networkSetupHostVethLimits(&d.deviceCommon, nil, false)
+	err = networkSetupHostVethLimits(&d.deviceCommon, nil, false, true)
 	if err != nil {
 		return nil, err
 	}
@@ -382,23 +403,8 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		addresses := util.SplitNTrimSpace(d.config[fmt.Sprintf("%s.address", keyPrefix)], ",", -1, true)
 
 		// Add host-side gateway addresses.
-		if len(addresses) > 0 {
-			// Add gateway IPs to the host end of the veth pair. This ensures that liveness detection
-			// of the gateways inside the instance work and ensure that traffic doesn't periodically
-			// halt whilst ARP/NDP is re-detected (which is what happens with just neighbour proxies).
-			addr := &ip.Addr{
-				DevName: saveData["host_name"],
-				Address: fmt.Sprintf("%s/%d", d.ipHostAddress(keyPrefix), subnetSize),
-				Family:  ipFamilyArg,
-			}
-
-			err = addr.Add()
-			if err != nil {
-				return nil, fmt.Errorf("Failed adding host gateway IP %q: %w", addr.Address, err)
-			}
-
-			// Enable IP forwarding on host_name.
-			err = localUtil.SysctlSet(fmt.Sprintf("net/%s/conf/%s/forwarding", keyPrefix, saveData["host_name"]), "1")
+		if len(addresses) > 0 || (keyPrefix == "ipv6" && d.config["ipv6.delegated_prefixes"] != "") {
+			err = d.setupHostGateway(saveData["host_name"], keyPrefix)
 			if err != nil {
 				return nil, err
 			}
@@ -478,6 +484,22 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		}
 	}
 
+	// Route any delegated IPv6 prefixes to the instance.
+	prefixes, err := d.delegatedPrefixes(d.config)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, prefix := range prefixes {
+		err = d.delegatedPrefixAdd(saveData["host_name"], prefix, prefixes[:i])
+		if err != nil {
+			return nil, err
+		}
+
+		delegatedPrefix := prefix
+		revert.Add(func() { d.delegatedPrefixProxyDelete(delegatedPrefix, nil) })
+	}
+
 	err = d.volatileSet(saveData)
 	if err != nil {
 		return nil, err
@@ -498,7 +520,8 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 			ipAddresses := util.SplitNTrimSpace(d.config[fmt.Sprintf("%s.address", keyPrefix)], ",", -1, true)
 
 			// Use a fixed address as the auto next-hop default gateway if using this IP family.
-			if len(ipAddresses) > 0 && nicHasAutoGateway(d.config[fmt.Sprintf("%s.gateway", keyPrefix)]) {
+			hasPrefixes := keyPrefix == "ipv6" && d.config["ipv6.delegated_prefixes"] != ""
+			if (len(ipAddresses) > 0 || hasPrefixes) && nicHasAutoGateway(d.config[fmt.Sprintf("%s.gateway", keyPrefix)]) {
 				runConf.NetworkInterface = append(runConf.NetworkInterface,
 					deviceConfig.RunConfigItem{Key: fmt.Sprintf("%s.gateway", keyPrefix), Value: d.ipHostAddress(keyPrefix)},
 				)
@@ -545,7 +568,7 @@ func (d *nicRouted) setupParentSysctls(parentName string) error {
 		}
 	}
 
-	if d.config["ipv6.address"] != "" {
+	if d.usesIPv6() {
 		// Set necessary sysctls use with l2proxy parent in routed mode.
 		ipv6FwdPath := fmt.Sprintf("net/ipv6/conf/%s/forwarding", parentName)
 		err := localUtil.SysctlSet(ipv6FwdPath, "1")
@@ -563,12 +586,51 @@ func (d *nicRouted) setupParentSysctls(parentName string) error {
 	return nil
 }
 
+// setupHostGateway adds the gateway address of the IP family to the host side interface and enables forwarding
+// on it. This ensures that liveness detection of the gateway inside the instance works and that traffic doesn't
+// periodically halt whilst ARP/NDP is re-detected (which is what happens with just neighbour proxies).
+func (d *nicRouted) setupHostGateway(hostName string, ipFamily string) error {
+	subnetSize := 32
+	ipFamilyArg := ip.FamilyV4
+	if ipFamily == "ipv6" {
+		subnetSize = 128
+		ipFamilyArg = ip.FamilyV6
+	}
+
+	addr := &ip.Addr{
+		DevName: hostName,
+		Address: fmt.Sprintf("%s/%d", d.ipHostAddress(ipFamily), subnetSize),
+		Family:  ipFamilyArg,
+	}
+
+	err := addr.Add()
+	if err != nil {
+		return fmt.Errorf("Failed adding host gateway IP %q: %w", addr.Address, err)
+	}
+
+	// Enable IP forwarding on host_name.
+	err = localUtil.SysctlSet(fmt.Sprintf("net/%s/conf/%s/forwarding", ipFamily, hostName), "1")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Update returns an error as most devices do not support live updates without being restarted.
 func (d *nicRouted) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	v := d.volatileGet()
 
 	// If instance is running, apply host side limits.
 	if isRunning {
+		// A VLAN parent created at start only got the sysctls of the IP families used back then.
+		if d.config["parent"] != "" && util.IsTrue(v["last_state.created"]) {
+			err := d.setupParentSysctls(network.GetHostDevice(d.config["parent"], d.config["vlan"]))
+			if err != nil {
+				return err
+			}
+		}
+
 		err := d.validateEnvironment()
 		if err != nil {
 			return err
@@ -578,12 +640,73 @@ func (d *nicRouted) Update(oldDevices deviceConfig.Devices, isRunning bool) erro
 		networkVethFillFromVolatile(d.config, v)
 
 		// Apply host-side limits.
-		err = networkSetupHostVethLimits(&d.deviceCommon, oldDevices[d.name], false)
+		err = networkSetupHostVethLimits(&d.deviceCommon, oldDevices[d.name], false, true)
+		if err != nil {
+			return err
+		}
+
+		// Apply changes to the delegated prefixes (such as following a renumbering of the host prefix).
+		err = d.updateDelegatedPrefixes(oldDevices[d.name])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateDelegatedPrefixes adds the routes and neighbour proxy entries of newly delegated prefixes and then removes
+// those of prefixes which are no longer delegated to the instance, so renewing the delegation with a new prefix
+// doesn't interrupt the routing of the addresses the old and new prefixes share.
+func (d *nicRouted) updateDelegatedPrefixes(oldConfig deviceConfig.Device) error {
+	oldPrefixes, err := d.delegatedPrefixes(oldConfig)
+	if err != nil {
+		return err
+	}
+
+	newPrefixes, err := d.delegatedPrefixes(d.config)
+	if err != nil {
+		return err
+	}
+
+	added, removed := nicRoutedPrefixChanges(oldPrefixes, newPrefixes)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// The host side IPv6 gateway is only set up at start if the NIC used IPv6 back then.
+	if oldConfig["ipv6.address"] == "" && len(oldPrefixes) == 0 && len(newPrefixes) > 0 {
+		err = d.setupHostGateway(d.config["host_name"], "ipv6")
 		if err != nil {
 			return err
 		}
 	}
 
+	existing := slices.Clone(oldPrefixes)
+	for _, prefix := range added {
+		err = d.delegatedPrefixAdd(d.config["host_name"], prefix, existing)
+		if err != nil {
+			return err
+		}
+
+		existing = append(existing, prefix)
+
+		delegatedPrefix := prefix
+		revert.Add(func() {
+			d.delegatedPrefixRouteDelete(delegatedPrefix)
+			d.delegatedPrefixProxyDelete(delegatedPrefix, oldPrefixes)
+		})
+	}
+
+	for _, prefix := range removed {
+		d.delegatedPrefixRouteDelete(prefix)
+		d.delegatedPrefixProxyDelete(prefix, newPrefixes)
+	}
+
+	revert.Success()
 	return nil
 }
 
@@ -644,6 +767,15 @@ func (d *nicRouted) postStop() error {
 				_ = neighProxy.Delete()
 			}
 		}
+
+		prefixes, err := d.delegatedPrefixes(d.config)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		for _, prefix := range prefixes {
+			d.delegatedPrefixProxyDelete(prefix, nil)
+		}
 	}
 
 	// This will delete the parent interface if we created it for VLAN parent.
@@ -667,6 +799,198 @@ func (d *nicRouted) postStop() error {
 	return nil
 }
 
+// usesIPv6 returns whether the NIC has any IPv6 addresses or delegated prefixes configured.
+func (d *nicRouted) usesIPv6() bool {
+	return d.config["ipv6.address"] != "" || d.config["ipv6.delegated_prefixes"] != ""
+}
+
+// delegatedPrefixes returns the parsed list of IPv6 prefixes delegated to the instance in the supplied config.
+func (d *nicRouted) delegatedPrefixes(config deviceConfig.Device) ([]*net.IPNet, error) {
+	var prefixes []*net.IPNet
+
+	for _, prefixStr := range util.SplitNTrimSpace(config["ipv6.delegated_prefixes"], ",", -1, true) {
+		_, prefix, err := net.ParseCIDR(prefixStr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid delegated prefix %q: %w", prefixStr, err)
+		}
+
+		prefixes = append(prefixes, prefix)
+	}
+
+	return prefixes, nil
+}
+
+// delegatedPrefixAdd routes a delegated prefix to the host side interface and, when using a parent
+// interface, adds neighbour proxy entries for the addresses within the prefix which aren't already covered by
+// one of the existing prefixes.
+func (d *nicRouted) delegatedPrefixAdd(hostName string, prefix *net.IPNet, existing []*net.IPNet) error {
+	// If the instance has an IPv6 address, route the prefix via it so the instance can sub-delegate it,
+	// otherwise route the prefix directly to the interface.
+	var via string
+	addresses := util.SplitNTrimSpace(d.config["ipv6.address"], ",", -1, true)
+	if len(addresses) > 0 {
+		via = addresses[0]
+	}
+
+	for _, table := range []string{"main", d.config["ipv6.host_table"]} {
+		if table == "" {
+			continue
+		}
+
+		r := ip.Route{
+			DevName: hostName,
+			Route:   prefix.String(),
+			Table:   table,
+			Family:  ip.FamilyV6,
+			Via:     via,
+		}
+
+		err := r.Add()
+		if err != nil {
+			return fmt.Errorf("Failed adding delegated prefix route %q to table %q: %w", r.Route, r.Table, err)
+		}
+	}
+
+	if d.effectiveParentName == "" {
+		return nil
+	}
+
+	// Neighbour proxy entries only cover individual addresses, so only do this for small prefixes.
+	ones, _ := prefix.Mask.Size()
+	if ones < nicRoutedPrefixProxyMaxSize {
+		d.logger.Debug("Skipping neighbour proxy for large delegated prefix", logger.Ctx{"prefix": prefix.String()})
+		return nil
+	}
+
+	for _, addr := range nicRoutedPrefixProxyAddresses(prefix, existing) {
+		np := ip.NeighProxy{
+			DevName: d.effectiveParentName,
+			Addr:    addr,
+		}
+
+		err := np.Add()
+		if err != nil {
+			return fmt.Errorf("Failed adding neighbour proxy %q to %q: %w", np.Addr.String(), np.DevName, err)
+		}
+	}
+
+	return nil
+}
+
+// delegatedPrefixRouteDelete removes the routes of a delegated prefix from the host.
+func (d *nicRouted) delegatedPrefixRouteDelete(prefix *net.IPNet) {
+	for _, table := range []string{"main", d.config["ipv6.host_table"]} {
+		if table == "" {
+			continue
+		}
+
+		r := ip.Route{
+			DevName: d.config["host_name"],
+			Route:   prefix.String(),
+			Table:   table,
+			Family:  ip.FamilyV6,
+		}
+
+		err := r.Delete()
+		if err != nil {
+			d.logger.Warn("Failed removing delegated prefix route", logger.Ctx{"prefix": prefix.String(), "table": table, "err": err})
+		}
+	}
+}
+
+// delegatedPrefixProxyDelete removes the neighbour proxy entries for a delegated prefix from the parent interface,
+// except for the addresses which are also part of one of the kept prefixes.
+func (d *nicRouted) delegatedPrefixProxyDelete(prefix *net.IPNet, keep []*net.IPNet) {
+	if d.effectiveParentName == "" {
+		return
+	}
+
+	for _, addr := range nicRoutedPrefixProxyAddresses(prefix, keep) {
+		np := ip.NeighProxy{
+			DevName: d.effectiveParentName,
+			Addr:    addr,
+		}
+
+		_ = np.Delete()
+	}
+}
+
+// nicRoutedPrefixChanges returns the prefixes which are only in the new list and those which are only in the old one.
+func nicRoutedPrefixChanges(oldPrefixes []*net.IPNet, newPrefixes []*net.IPNet) ([]*net.IPNet, []*net.IPNet) {
+	hasPrefix := func(prefixes []*net.IPNet, prefix *net.IPNet) bool {
+		for _, p := range prefixes {
+			if p.String() == prefix.String() {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var added, removed []*net.IPNet
+
+	for _, prefix := range newPrefixes {
+		if !hasPrefix(oldPrefixes, prefix) {
+			added = append(added, prefix)
+		}
+	}
+
+	for _, prefix := range oldPrefixes {
+		if !hasPrefix(newPrefixes, prefix) {
+			removed = append(removed, prefix)
+		}
+	}
+
+	return added, removed
+}
+
+// nicRoutedPrefixProxyAddresses returns the addresses of the prefix which get a neighbour proxy entry, leaving out
+// those which are also part of one of the excluded prefixes. Prefixes larger than nicRoutedPrefixProxyMaxSize get
+// no neighbour proxy entries.
+func nicRoutedPrefixProxyAddresses(prefix *net.IPNet, exclude []*net.IPNet) []net.IP {
+	ones, _ := prefix.Mask.Size()
+	if ones < nicRoutedPrefixProxyMaxSize {
+		return nil
+	}
+
+	var addresses []net.IP
+
+	for _, addr := range nicRoutedPrefixAddresses(prefix) {
+		excluded := false
+		for _, other := range exclude {
+			ones, _ := other.Mask.Size()
+			if ones >= nicRoutedPrefixProxyMaxSize && other.Contains(addr) {
+				excluded = true
+				break
+			}
+		}
+
+		if !excluded {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	return addresses
+}
+
+// nicRoutedPrefixAddresses returns all the addresses within the supplied prefix.
+// This is only meant for prefixes no larger than nicRoutedPrefixProxyMaxSize.
+func nicRoutedPrefixAddresses(prefix *net.IPNet) []net.IP {
+	ones, bits := prefix.Mask.Size()
+	count := 1 << (bits - ones)
+
+	addresses := make([]net.IP, 0, count)
+	addr := prefix.IP.Mask(prefix.Mask)
+	for i := 0; i < count; i++ {
+		next := make(net.IP, len(addr))
+		copy(next, addr)
+		next[len(next)-1] += byte(i)
+		addresses = append(addresses, next)
+	}
+
+	return addresses
+}
+
 func (d *nicRouted) ipHostAddress(ipFamily string) string {
 	key := fmt.Sprintf("%s.host_address", ipFamily)
 	if d.config[key] != "" {
@@ -697,3 +1021,8 @@ func (d *nicRouted) isUniqueWithGatewayAutoMode(instConf instance.ConfigReader)
 
 	return nil
 }
+
+// VerifyLimits checks that the NIC's rate limits are applied on the host side veth device.
+func (d *nicRouted) VerifyLimits() ([]limitcheck.Discrepancy, error) {
+	return networkVerifyHostVethLimits(&d.deviceCommon)
+}
@@ -0,0 +1,104 @@
+package device
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNicRoutedPrefixChanges(t *testing.T) {
+	d := &nicRouted{}
+
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		added   []string
+		removed []string
+	}{
+		{"Unchanged", "2001:db8:1::/64", "2001:db8:1::/64", nil, nil},
+		{"Same prefix written differently", "2001:db8:1::/64", "2001:0db8:0001::/64", nil, nil},
+		{"Reordered", "2001:db8:1::/64,2001:db8:2::/64", "2001:db8:2::/64, 2001:db8:1::/64", nil, nil},
+		{"First prefix", "", "2001:db8:1::/64", []string{"2001:db8:1::/64"}, nil},
+		{"Last prefix", "2001:db8:1::/64", "", nil, []string{"2001:db8:1::/64"}},
+		{"Renewal", "2001:db8:1::/64,2001:db8:5::/64", "2001:db8:2::/64,2001:db8:5::/64", []string{"2001:db8:2::/64"}, []string{"2001:db8:1::/64"}},
+		{"Grown prefix", "2001:db8:1::/64", "2001:db8::/56", []string{"2001:db8::/56"}, []string{"2001:db8:1::/64"}},
+	}
+
+	toStrings := func(prefixes []*net.IPNet) []string {
+		var out []string
+		for _, prefix := range prefixes {
+			out = append(out, prefix.String())
+		}
+
+		return out
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldPrefixes, err := d.delegatedPrefixes(map[string]string{"ipv6.delegated_prefixes": tt.old})
+			require.NoError(t, err)
+
+			newPrefixes, err := d.delegatedPrefixes(map[string]string{"ipv6.delegated_prefixes": tt.new})
+			require.NoError(t, err)
+
+			added, removed := nicRoutedPrefixChanges(oldPrefixes, newPrefixes)
+			require.Equal(t, tt.added, toStrings(added))
+			require.Equal(t, tt.removed, toStrings(removed))
+		})
+	}
+}
+
+func TestNicRoutedPrefixProxyAddresses(t *testing.T) {
+	parse := func(s string) *net.IPNet {
+		_, prefix, err := net.ParseCIDR(s)
+		require.NoError(t, err)
+
+		return prefix
+	}
+
+	tests := []struct {
+		name     string
+		prefix   string
+		exclude  []string
+		count    int
+		first    string
+		last     string
+		excluded string
+	}{
+		{"Single address", "2001:db8::5/128", nil, 1, "2001:db8::5", "2001:db8::5", ""},
+		{"Small prefix", "2001:db8::10/124", nil, 16, "2001:db8::10", "2001:db8::1f", ""},
+		{"Largest proxied prefix", "2001:db8::/120", nil, 256, "2001:db8::", "2001:db8::ff", ""},
+		{"Too large prefix", "2001:db8::/64", nil, 0, "", "", ""},
+		{"Renewal within a larger prefix", "2001:db8::/120", []string{"2001:db8::10/124"}, 240, "2001:db8::", "2001:db8::ff", "2001:db8::10"},
+		{"Renewal with a smaller prefix", "2001:db8::10/124", []string{"2001:db8::/120"}, 0, "", "", ""},
+		{"Unrelated prefix", "2001:db8::10/124", []string{"2001:db8:1::/120"}, 16, "2001:db8::10", "2001:db8::1f", ""},
+		{"Too large prefix isn't proxied", "2001:db8::10/124", []string{"2001:db8::/64"}, 16, "2001:db8::10", "2001:db8::1f", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exclude []*net.IPNet
+			for _, s := range tt.exclude {
+				exclude = append(exclude, parse(s))
+			}
+
+			addresses := nicRoutedPrefixProxyAddresses(parse(tt.prefix), exclude)
+			require.Len(t, addresses, tt.count)
+
+			if tt.count == 0 {
+				return
+			}
+
+			require.Equal(t, tt.first, addresses[0].String())
+			require.Equal(t, tt.last, addresses[len(addresses)-1].String())
+
+			if tt.excluded != "" {
+				for _, addr := range addresses {
+					require.NotEqual(t, tt.excluded, addr.String())
+				}
+			}
+		})
+	}
+}
@@ -3,11 +3,14 @@ package device
 import (
 	"fmt"
 	"net/http"
+	"slices"
 
 	"github.com/lxc/incus/v6/internal/linux"
+	"github.com/lxc/incus/v6/internal/revert"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/ip"
 	"github.com/lxc/incus/v6/internal/server/network"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/util"
@@ -45,6 +48,15 @@ func (d *nicSRIOV) validateConfig(instConf instance.ConfigReader) error {
 		"vlan",
 		"security.mac_filtering",
 		"boot.priority",
+		"acceleration",
+	}
+
+	if d.config["acceleration"] != "" && !slices.Contains([]string{"none", "vdpa"}, d.config["acceleration"]) {
+		return fmt.Errorf("Invalid value %q for %q, must be one of %q or %q", d.config["acceleration"], "acceleration", "none", "vdpa")
+	}
+
+	if d.config["acceleration"] == "vdpa" && instConf.Type() != instancetype.VM {
+		return fmt.Errorf("vDPA acceleration is only supported for virtual machines")
 	}
 
 	// Check that if network property is set that conflicting keys are not present.
@@ -140,15 +152,27 @@ func (d *nicSRIOV) Start() (*deviceConfig.RunConfig, error) {
 	}
 
 	saveData := make(map[string]string)
-
-	// If VM, then try and load the vfio-pci module first.
-	if d.inst.Type() == instancetype.VM {
+	vdpa := d.config["acceleration"] == "vdpa"
+
+	if vdpa {
+		// Load the vDPA modules, the VF stays bound to its host driver and is passed to QEMU through vhost-vdpa.
+		for _, module := range []string{"vdpa", "vhost_vdpa"} {
+			err = linux.LoadModule(module)
+			if err != nil {
+				return nil, fmt.Errorf("Error loading %q module: %w", module, err)
+			}
+		}
+	} else if d.inst.Type() == instancetype.VM {
+		// If VM, then try and load the vfio-pci module first.
 		err = linux.LoadModule("vfio-pci")
 		if err != nil {
 			return nil, fmt.Errorf("Error loading %q module: %w", "vfio-pci", err)
 		}
 	}
 
+	revert := revert.New()
+	defer revert.Fail()
+
 	// Find free VF exclusively.
 	network.SRIOVVirtualFunctionMutex.Lock()
 	vfDev, vfID, err := network.SRIOVFindFreeVirtualFunction(d.state, d.config["parent"])
@@ -164,6 +188,24 @@ func (d *nicSRIOV) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	revert.Add(func() {
+		network.SRIOVVirtualFunctionMutex.Lock()
+		_ = networkSRIOVRestoreVF(d.deviceCommon, true, saveData)
+		network.SRIOVVirtualFunctionMutex.Unlock()
+	})
+
+	// Create the vDPA management device.
+	var vDPADevice *ip.VDPADev
+	if vdpa {
+		vDPADevice, err = ip.AddVDPADevice(vfPCIDev.SlotName, saveData)
+		if err != nil {
+			network.SRIOVVirtualFunctionMutex.Unlock()
+			return nil, err
+		}
+
+		revert.Add(func() { _ = ip.DeleteVDPADevice(vDPADevice.Name) })
+	}
+
 	network.SRIOVVirtualFunctionMutex.Unlock()
 
 	if d.inst.Type() == instancetype.Container {
@@ -196,7 +238,17 @@ func (d *nicSRIOV) Start() (*deviceConfig.RunConfig, error) {
 		{Key: "hwaddr", Value: d.config["hwaddr"]},
 	}
 
-	if d.inst.Type() == instancetype.VM {
+	if vdpa {
+		runConf.NetworkInterface = append(runConf.NetworkInterface,
+			[]deviceConfig.RunConfigItem{
+				{Key: "devName", Value: d.name},
+				{Key: "pciSlotName", Value: vfPCIDev.SlotName},
+				{Key: "pciIOMMUGroup", Value: fmt.Sprintf("%d", pciIOMMUGroup)},
+				{Key: "maxVQP", Value: fmt.Sprintf("%d", vDPADevice.MaxVQs/2)},
+				{Key: "vDPADevName", Value: vDPADevice.Name},
+				{Key: "vhostVDPAPath", Value: vDPADevice.VhostVDPA.Path},
+			}...)
+	} else if d.inst.Type() == instancetype.VM {
 		runConf.NetworkInterface = append(runConf.NetworkInterface,
 			[]deviceConfig.RunConfigItem{
 				{Key: "devName", Value: d.name},
@@ -205,6 +257,8 @@ func (d *nicSRIOV) Start() (*deviceConfig.RunConfig, error) {
 			}...)
 	}
 
+	revert.Success()
+
 	return &runConf, nil
 }
 
@@ -235,12 +289,23 @@ func (d *nicSRIOV) postStop() error {
 			"last_state.vf.vlan":       "",
 			"last_state.vf.spoofcheck": "",
 			"last_state.pci.driver":    "",
+			"last_state.vdpa.name":     "",
 		})
 	}()
 
 	v := d.volatileGet()
 
 	network.SRIOVVirtualFunctionMutex.Lock()
+
+	// Delete the vDPA management device.
+	if v["last_state.vdpa.name"] != "" {
+		err := ip.DeleteVDPADevice(v["last_state.vdpa.name"])
+		if err != nil {
+			network.SRIOVVirtualFunctionMutex.Unlock()
+			return err
+		}
+	}
+
 	err := networkSRIOVRestoreVF(d.deviceCommon, true, v)
 	if err != nil {
 		network.SRIOVVirtualFunctionMutex.Unlock()
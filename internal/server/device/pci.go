@@ -3,12 +3,15 @@ package device
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/lxc/incus/v6/internal/linux"
+	"github.com/lxc/incus/v6/internal/revert"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	pcidev "github.com/lxc/incus/v6/internal/server/device/pci"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/validate"
 )
@@ -24,7 +27,8 @@ func (d *pci) validateConfig(instConf instance.ConfigReader) error {
 	}
 
 	rules := map[string]func(string) error{
-		"address": validate.IsPCIAddress,
+		"address":     validate.IsPCIAddress,
+		"iommu_group": validate.Optional(validate.IsBool),
 	}
 
 	err := d.config.Validate(rules)
@@ -43,7 +47,51 @@ func (d *pci) validateEnvironment() error {
 		return fmt.Errorf("PCI devices cannot be used when migration.stateful is enabled")
 	}
 
-	return validatePCIDevice(d.config["address"])
+	err := validatePCIDevice(d.config["address"])
+	if err != nil {
+		return err
+	}
+
+	// vfio-pci can only pass through devices that are part of an IOMMU group.
+	if !util.PathExists(filepath.Join(pcidev.SysBusPCI, "devices", d.config["address"], "iommu_group")) {
+		return fmt.Errorf("PCI device %q isn't part of an IOMMU group, check that the IOMMU is enabled in the firmware and kernel", d.config["address"])
+	}
+
+	return nil
+}
+
+// groupDevices returns the other devices of the IOMMU group to pass through along with the device.
+// Unless iommu_group is enabled, it returns no devices and fails if the group can't be safely passed through.
+func (d *pci) groupDevices(pciDev pcidev.Device) ([]pcidev.Device, error) {
+	slotNames, err := pcidev.DeviceIOMMUGroupDevices(pciDev.SlotName)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := []pcidev.Device{}
+	for _, slotName := range slotNames {
+		// Bridges stay with the host.
+		if pcidev.DeviceIsBridge(slotName) {
+			continue
+		}
+
+		groupDev, err := pcidev.ParseUeventFile(filepath.Join(pcidev.SysBusPCI, "devices", slotName, "uevent"))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get PCI device info for %q: %w", slotName, err)
+		}
+
+		if util.IsTrue(d.config["iommu_group"]) {
+			devices = append(devices, groupDev)
+			continue
+		}
+
+		// The device can only be passed through if no other device of its group is used by the host.
+		if groupDev.Driver != "" && groupDev.Driver != "vfio-pci" && groupDev.Driver != "pci-stub" {
+			return nil, fmt.Errorf("Device %q shares its IOMMU group with device %q which is bound to driver %q, set %q to pass through the whole group", pciDev.SlotName, slotName, groupDev.Driver, "iommu_group=true")
+		}
+	}
+
+	return devices, nil
 }
 
 // Start is run when the device is added to the instance.
@@ -64,31 +112,80 @@ func (d *pci) Start() (*deviceConfig.RunConfig, error) {
 
 	// Get PCI information about the device.
 	pciAddress := d.config["address"]
-	devicePath := filepath.Join("/sys/bus/pci/devices", pciAddress)
+	devicePath := filepath.Join(pcidev.SysBusPCI, "devices", pciAddress)
 	pciDev, err := pcidev.ParseUeventFile(filepath.Join(devicePath, "uevent"))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get PCI device info for %q: %w", pciAddress, err)
 	}
 
-	saveData["last_state.pci.slot.name"] = pciDev.SlotName
-	saveData["last_state.pci.driver"] = pciDev.Driver
-
-	err = pcidev.DeviceDriverOverride(pciDev, "vfio-pci")
+	groupDevs, err := d.groupDevices(pciDev)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to override IOMMU group driver: %w", err)
+		return nil, err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Devices left bound to vfio-pci by a failed reset on the previous stop keep their host driver.
+	hostDrivers := map[string]string{}
+	for _, dev := range d.lastStateDevices() {
+		hostDrivers[dev.SlotName] = dev.Driver
 	}
 
-	runConf.PCIDevice = append(runConf.PCIDevice,
-		[]deviceConfig.RunConfigItem{
-			{Key: "devName", Value: d.name},
-			{Key: "pciSlotName", Value: saveData["last_state.pci.slot.name"]},
-		}...)
+	// Bind all the devices to vfio-pci, restoring the ones already bound if any fails.
+	// Devices which are already bound to vfio-pci, for example by the host administrator, are left as they are.
+	groupSave := []string{}
+	for _, dev := range append([]pcidev.Device{pciDev}, groupDevs...) {
+		dev := dev
+
+		hostDriver, found := hostDrivers[dev.SlotName]
+		if !found {
+			hostDriver = dev.Driver
+		}
+
+		if dev.Driver != "vfio-pci" {
+			if !pcidev.DeviceCanReset(dev.SlotName) {
+				d.logger.Warn("PCI device can't be reset, its state may be visible to the next user", logger.Ctx{"address": dev.SlotName})
+			}
+
+			err = pcidev.DeviceDriverOverride(dev, "vfio-pci")
+			if err != nil {
+				return nil, fmt.Errorf("Failed to override IOMMU group driver of %q: %w", dev.SlotName, err)
+			}
+
+			revert.Add(func() {
+				vfioDev := pcidev.Device{
+					Driver:   "vfio-pci",
+					SlotName: dev.SlotName,
+				}
+
+				_ = pcidev.DeviceDriverOverride(vfioDev, dev.Driver)
+			})
+		}
+
+		runConf.PCIDevice = append(runConf.PCIDevice, deviceConfig.RunConfigItem{Key: "pciSlotName", Value: dev.SlotName})
+
+		if dev.SlotName != pciDev.SlotName && hostDriver != "vfio-pci" {
+			groupSave = append(groupSave, fmt.Sprintf("%s=%s", dev.SlotName, hostDriver))
+		}
+
+		if dev.SlotName == pciDev.SlotName {
+			saveData["last_state.pci.driver"] = hostDriver
+		}
+	}
+
+	runConf.PCIDevice = append(runConf.PCIDevice, deviceConfig.RunConfigItem{Key: "devName", Value: d.name})
+
+	saveData["last_state.pci.slot.name"] = pciDev.SlotName
+	saveData["last_state.pci.group"] = strings.Join(groupSave, ",")
 
 	err = d.volatileSet(saveData)
 	if err != nil {
 		return nil, err
 	}
 
+	revert.Success()
+
 	return &runConf, nil
 }
 
@@ -101,29 +198,89 @@ func (d *pci) Stop() (*deviceConfig.RunConfig, error) {
 	return &runConf, nil
 }
 
+// lastStateDevices returns the devices recorded as bound to vfio-pci along with their host driver.
+func (d *pci) lastStateDevices() []pcidev.Device {
+	v := d.volatileGet()
+
+	if v["last_state.pci.slot.name"] == "" {
+		return nil
+	}
+
+	devices := []pcidev.Device{{SlotName: v["last_state.pci.slot.name"], Driver: v["last_state.pci.driver"]}}
+	if v["last_state.pci.group"] != "" {
+		for _, entry := range strings.Split(v["last_state.pci.group"], ",") {
+			slotName, driver, _ := strings.Cut(entry, "=")
+			devices = append(devices, pcidev.Device{SlotName: slotName, Driver: driver})
+		}
+	}
+
+	return devices
+}
+
 // postStop is run after the device is removed from the instance.
 func (d *pci) postStop() error {
-	defer func() {
-		_ = d.volatileSet(map[string]string{
-			"last_state.pci.slot.name": "",
-			"last_state.pci.driver":    "",
-		})
-	}()
+	devices := d.lastStateDevices()
+	if len(devices) == 0 {
+		return nil
+	}
 
-	v := d.volatileGet()
+	// Reset the devices before giving them back to the host. The ones which fail to reset are left bound to
+	// vfio-pci so that the host doesn't use a device in an unknown state, and stay recorded so that they're given
+	// back on a later stop.
+	var firstErr error
+	failed := []string{}
+	for _, dev := range devices {
+		// Devices which were bound to vfio-pci before the instance started stay that way.
+		if dev.Driver == "vfio-pci" {
+			continue
+		}
 
-	// Unbind from vfio-pci and bind back to host driver.
-	if v["last_state.pci.slot.name"] != "" {
-		pciDev := pcidev.Device{
+		if pcidev.DeviceCanReset(dev.SlotName) {
+			err := pcidev.DeviceReset(dev.SlotName)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("Not binding device %q back to driver %q: %w", dev.SlotName, dev.Driver, err)
+				}
+
+				failed = append(failed, fmt.Sprintf("%s=%s", dev.SlotName, dev.Driver))
+				continue
+			}
+		}
+
+		// Unbind from vfio-pci and bind back to host driver.
+		vfioDev := pcidev.Device{
 			Driver:   "vfio-pci",
-			SlotName: v["last_state.pci.slot.name"],
+			SlotName: dev.SlotName,
 		}
 
-		err := pcidev.DeviceDriverOverride(pciDev, v["last_state.pci.driver"])
+		err := pcidev.DeviceDriverOverride(vfioDev, dev.Driver)
 		if err != nil {
-			return err
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			failed = append(failed, fmt.Sprintf("%s=%s", dev.SlotName, dev.Driver))
 		}
 	}
 
-	return nil
+	if len(failed) == 0 {
+		return d.volatileSet(map[string]string{
+			"last_state.pci.slot.name": "",
+			"last_state.pci.driver":    "",
+			"last_state.pci.group":     "",
+		})
+	}
+
+	// Only keep the devices still bound to vfio-pci recorded.
+	slotName, driver, _ := strings.Cut(failed[0], "=")
+	err := d.volatileSet(map[string]string{
+		"last_state.pci.slot.name": slotName,
+		"last_state.pci.driver":    driver,
+		"last_state.pci.group":     strings.Join(failed[1:], ","),
+	})
+	if err != nil {
+		d.logger.Warn("Failed recording the PCI devices left bound to vfio-pci", logger.Ctx{"err": err})
+	}
+
+	return firstErr
 }
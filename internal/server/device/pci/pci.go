@@ -13,6 +13,9 @@ import (
 	"github.com/lxc/incus/v6/shared/util"
 )
 
+// SysBusPCI is the sysfs path of the PCI bus.
+var SysBusPCI = "/sys/bus/pci"
+
 // ErrDeviceIsUSB is returned when dealing with a USB device.
 var ErrDeviceIsUSB = fmt.Errorf("Device is USB instead of PCI")
 
@@ -65,10 +68,10 @@ func ParseUeventFile(ueventFilePath string) (Device, error) {
 
 // DeviceUnbind unbinds a PCI device from the OS using its PCI Slot Name.
 func DeviceUnbind(pciDev Device) error {
-	driverUnbindPath := fmt.Sprintf("/sys/bus/pci/devices/%s/driver/unbind", pciDev.SlotName)
+	driverUnbindPath := filepath.Join(SysBusPCI, "devices", pciDev.SlotName, "driver", "unbind")
 	err := os.WriteFile(driverUnbindPath, []byte(pciDev.SlotName), 0600)
 	if err != nil {
-		if !os.IsNotExist(err) || !util.PathExists(fmt.Sprintf("/sys/bus/pci/devices/%s/", pciDev.SlotName)) {
+		if !os.IsNotExist(err) || !util.PathExists(filepath.Join(SysBusPCI, "devices", pciDev.SlotName)) {
 			return fmt.Errorf("Failed unbinding device %q via %q: %w", pciDev.SlotName, driverUnbindPath, err)
 		}
 	}
@@ -78,7 +81,7 @@ func DeviceUnbind(pciDev Device) error {
 
 // DeviceSetDriverOverride registers an override driver for a PCI device using its PCI Slot Name.
 func DeviceSetDriverOverride(pciDev Device, driverOverride string) error {
-	overridePath := filepath.Join("/sys/bus/pci/devices", pciDev.SlotName, "driver_override")
+	overridePath := filepath.Join(SysBusPCI, "devices", pciDev.SlotName, "driver_override")
 
 	// The "\n" at end is important to allow the driver override to be cleared by passing "" in.
 	err := os.WriteFile(overridePath, []byte(fmt.Sprintf("%s\n", driverOverride)), 0600)
@@ -91,7 +94,7 @@ func DeviceSetDriverOverride(pciDev Device, driverOverride string) error {
 
 // DeviceProbe probes a PCI device using its PCI Slot Name.
 func DeviceProbe(pciDev Device) error {
-	driveProbePath := "/sys/bus/pci/drivers_probe"
+	driveProbePath := filepath.Join(SysBusPCI, "drivers_probe")
 	err := os.WriteFile(driveProbePath, []byte(pciDev.SlotName), 0600)
 	if err != nil {
 		return fmt.Errorf("Failed probing device %q via %q: %w", pciDev.SlotName, driveProbePath, err)
@@ -150,7 +153,7 @@ func DeviceDriverOverride(pciDev Device, driverOverride string) error {
 
 // deviceProbeWait waits for PCI device to be activated with the specified driver after being probed.
 func deviceProbeWait(pciDev Device) error {
-	driverPath := fmt.Sprintf("/sys/bus/pci/drivers/%s/%s", pciDev.Driver, pciDev.SlotName)
+	driverPath := filepath.Join(SysBusPCI, "drivers", pciDev.Driver, pciDev.SlotName)
 
 	for i := 0; i < 10; i++ {
 		if util.PathExists(driverPath) {
@@ -180,7 +183,7 @@ func NormaliseAddress(addr string) string {
 
 // DeviceIOMMUGroup returns the IOMMU group for a PCI device.
 func DeviceIOMMUGroup(slotName string) (uint64, error) {
-	iommuGroupSymPath := fmt.Sprintf("/sys/bus/pci/devices/%s/iommu_group", slotName)
+	iommuGroupSymPath := filepath.Join(SysBusPCI, "devices", slotName, "iommu_group")
 	_, err := os.Lstat(iommuGroupSymPath)
 	if err != nil {
 		return 0, err
@@ -199,3 +202,57 @@ func DeviceIOMMUGroup(slotName string) (uint64, error) {
 
 	return iommuGroup, nil
 }
+
+// DeviceIOMMUGroupDevices returns the PCI slot names of the other devices in the IOMMU group of a PCI device.
+// No devices are returned when the kernel doesn't expose IOMMU groups.
+func DeviceIOMMUGroupDevices(slotName string) ([]string, error) {
+	iommuGroupPath := filepath.Join(SysBusPCI, "devices", slotName, "iommu_group", "devices")
+
+	entries, err := os.ReadDir(iommuGroupPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed listing IOMMU group devices of %q: %w", slotName, err)
+	}
+
+	slotNames := []string{}
+	for _, entry := range entries {
+		if entry.Name() == slotName {
+			continue
+		}
+
+		slotNames = append(slotNames, entry.Name())
+	}
+
+	return slotNames, nil
+}
+
+// DeviceIsBridge returns whether a PCI device is a PCI bridge.
+// Bridges don't need to be bound to vfio-pci for the other devices of their IOMMU group to be passed through.
+func DeviceIsBridge(slotName string) bool {
+	class, err := os.ReadFile(filepath.Join(SysBusPCI, "devices", slotName, "class"))
+	if err != nil {
+		return false
+	}
+
+	// Bridges are of the 0x06 base class and 0x04 sub-class.
+	return strings.HasPrefix(strings.TrimSpace(string(class)), "0x0604")
+}
+
+// DeviceCanReset returns whether the kernel knows a method to reset a PCI device.
+func DeviceCanReset(slotName string) bool {
+	return util.PathExists(filepath.Join(SysBusPCI, "devices", slotName, "reset"))
+}
+
+// DeviceReset resets a PCI device using its PCI Slot Name.
+func DeviceReset(slotName string) error {
+	resetPath := filepath.Join(SysBusPCI, "devices", slotName, "reset")
+	err := os.WriteFile(resetPath, []byte("1"), 0200)
+	if err != nil {
+		return fmt.Errorf("Failed resetting device %q via %q: %w", slotName, resetPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package device
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	pcidev "github.com/lxc/incus/v6/internal/server/device/pci"
+)
+
+// pciTestSysfs sets up a fake PCI bus for the duration of the test.
+func pciTestSysfs(t *testing.T) string {
+	sysBusPCI := pcidev.SysBusPCI
+	pcidev.SysBusPCI = t.TempDir()
+	t.Cleanup(func() { pcidev.SysBusPCI = sysBusPCI })
+
+	return pcidev.SysBusPCI
+}
+
+// pciTestDevice adds a device bound to driver to the fake PCI bus.
+func pciTestDevice(t *testing.T, root string, slotName string, driver string, class string, group ...string) {
+	devPath := filepath.Join(root, "devices", slotName)
+	require.NoError(t, os.MkdirAll(filepath.Join(devPath, "driver"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(devPath, "uevent"), []byte("DRIVER="+driver+"\nPCI_SLOT_NAME="+slotName+"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(devPath, "class"), []byte(class+"\n"), 0o644))
+
+	for _, slotName := range group {
+		require.NoError(t, os.MkdirAll(filepath.Join(devPath, "iommu_group", "devices", slotName), 0o755))
+	}
+}
+
+// pciTestBind makes the devices show up as bound to driver once probed.
+func pciTestBind(t *testing.T, root string, driver string, slotNames ...string) {
+	for _, slotName := range slotNames {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "drivers", driver, slotName), 0o755))
+	}
+}
+
+func newTestPCI(config deviceConfig.Device, volatile map[string]string) *pci {
+	d := &pci{}
+	d.name = "gpu"
+	d.config = config
+	d.volatileGet = func() map[string]string { return volatile }
+	d.volatileSet = func(save map[string]string) error {
+		for k, v := range save {
+			volatile[k] = v
+		}
+
+		return nil
+	}
+
+	return d
+}
+
+func TestPCIGroupDevices(t *testing.T) {
+	root := pciTestSysfs(t)
+
+	pciTestDevice(t, root, "0000:01:00.0", "nvidia", "0x030000", "0000:00:01.0", "0000:01:00.0", "0000:01:00.1")
+	pciTestDevice(t, root, "0000:00:01.0", "pcieport", "0x060400")
+	pciTestDevice(t, root, "0000:01:00.1", "snd_hda_intel", "0x040300")
+	pciTestDevice(t, root, "0000:02:00.0", "nvme", "0x010802")
+
+	dev := pcidev.Device{SlotName: "0000:01:00.0", Driver: "nvidia"}
+
+	// The device can't be passed through alone while another device of its group is used by the host.
+	_, err := newTestPCI(deviceConfig.Device{}, map[string]string{}).groupDevices(dev)
+	assert.ErrorContains(t, err, "0000:01:00.1")
+
+	// The whole group except for the bridge is passed through with iommu_group.
+	devices, err := newTestPCI(deviceConfig.Device{"iommu_group": "true"}, map[string]string{}).groupDevices(dev)
+	require.NoError(t, err)
+	assert.Equal(t, []pcidev.Device{{SlotName: "0000:01:00.1", Driver: "snd_hda_intel"}}, devices)
+
+	// The device can be passed through alone once the other devices of its group are unused.
+	pciTestDevice(t, root, "0000:01:00.1", "", "0x040300")
+	devices, err = newTestPCI(deviceConfig.Device{}, map[string]string{}).groupDevices(dev)
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+
+	// No devices are returned without IOMMU groups.
+	devices, err = newTestPCI(deviceConfig.Device{"iommu_group": "true"}, map[string]string{}).groupDevices(pcidev.Device{SlotName: "0000:02:00.0"})
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+}
+
+func TestPCILastStateDevices(t *testing.T) {
+	d := newTestPCI(deviceConfig.Device{}, map[string]string{})
+	assert.Empty(t, d.lastStateDevices())
+
+	d = newTestPCI(deviceConfig.Device{}, map[string]string{
+		"last_state.pci.slot.name": "0000:01:00.0",
+		"last_state.pci.driver":    "nvidia",
+	})
+
+	assert.Equal(t, []pcidev.Device{{SlotName: "0000:01:00.0", Driver: "nvidia"}}, d.lastStateDevices())
+
+	d = newTestPCI(deviceConfig.Device{}, map[string]string{
+		"last_state.pci.slot.name": "0000:01:00.0",
+		"last_state.pci.driver":    "nvidia",
+		"last_state.pci.group":     "0000:01:00.1=snd_hda_intel,0000:01:00.2=",
+	})
+
+	assert.Equal(t, []pcidev.Device{
+		{SlotName: "0000:01:00.0", Driver: "nvidia"},
+		{SlotName: "0000:01:00.1", Driver: "snd_hda_intel"},
+		{SlotName: "0000:01:00.2", Driver: ""},
+	}, d.lastStateDevices())
+}
+
+func TestPCIPostStop(t *testing.T) {
+	root := pciTestSysfs(t)
+
+	pciTestDevice(t, root, "0000:01:00.0", "vfio-pci", "0x030000")
+	pciTestDevice(t, root, "0000:01:00.1", "vfio-pci", "0x040300")
+	pciTestDevice(t, root, "0000:01:00.2", "vfio-pci", "0x0c0330")
+	pciTestBind(t, root, "nvidia", "0000:01:00.0")
+	pciTestBind(t, root, "snd_hda_intel", "0000:01:00.1")
+
+	// Both devices can be reset.
+	for _, slotName := range []string{"0000:01:00.0", "0000:01:00.1"} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "devices", slotName, "reset"), nil, 0o600))
+	}
+
+	// The devices are reset and given back to their host driver, except for the one already bound to vfio-pci.
+	volatile := map[string]string{
+		"last_state.pci.slot.name": "0000:01:00.0",
+		"last_state.pci.driver":    "nvidia",
+		"last_state.pci.group":     "0000:01:00.1=snd_hda_intel,0000:01:00.2=vfio-pci",
+	}
+
+	require.NoError(t, newTestPCI(deviceConfig.Device{}, volatile).postStop())
+	assert.Equal(t, map[string]string{
+		"last_state.pci.slot.name": "",
+		"last_state.pci.driver":    "",
+		"last_state.pci.group":     "",
+	}, volatile)
+
+	for slotName, driver := range map[string]string{"0000:01:00.0": "nvidia", "0000:01:00.1": "snd_hda_intel"} {
+		content, err := os.ReadFile(filepath.Join(root, "devices", slotName, "reset"))
+		require.NoError(t, err)
+		assert.Equal(t, "1", string(content))
+
+		content, err = os.ReadFile(filepath.Join(root, "devices", slotName, "driver_override"))
+		require.NoError(t, err)
+		assert.Equal(t, driver+"\n", string(content))
+	}
+
+	assert.NoFileExists(t, filepath.Join(root, "devices", "0000:01:00.2", "driver_override"))
+
+	// Nothing is done when no devices are recorded.
+	require.NoError(t, newTestPCI(deviceConfig.Device{}, volatile).postStop())
+
+	// A device which fails to reset stays bound to vfio-pci and recorded, the others are given back.
+	resetPath := filepath.Join(root, "devices", "0000:01:00.1", "reset")
+	require.NoError(t, os.Remove(resetPath))
+	require.NoError(t, os.Mkdir(resetPath, 0o755))
+	require.NoError(t, os.Remove(filepath.Join(root, "devices", "0000:01:00.1", "driver_override")))
+
+	volatile = map[string]string{
+		"last_state.pci.slot.name": "0000:01:00.0",
+		"last_state.pci.driver":    "nvidia",
+		"last_state.pci.group":     "0000:01:00.1=snd_hda_intel",
+	}
+
+	err := newTestPCI(deviceConfig.Device{}, volatile).postStop()
+	assert.ErrorContains(t, err, "0000:01:00.1")
+	assert.Equal(t, map[string]string{
+		"last_state.pci.slot.name": "0000:01:00.1",
+		"last_state.pci.driver":    "snd_hda_intel",
+		"last_state.pci.group":     "",
+	}, volatile)
+
+	assert.NoFileExists(t, filepath.Join(root, "devices", "0000:01:00.1", "driver_override"))
+
+	// The recorded device is given back once it can be reset.
+	require.NoError(t, os.Remove(resetPath))
+	require.NoError(t, newTestPCI(deviceConfig.Device{}, volatile).postStop())
+	assert.Equal(t, "", volatile["last_state.pci.slot.name"])
+	assert.FileExists(t, filepath.Join(root, "devices", "0000:01:00.1", "driver_override"))
+}
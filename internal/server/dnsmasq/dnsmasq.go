@@ -71,6 +71,19 @@ func RemoveStaticEntry(network string, projectName string, instanceName string,
 	return nil
 }
 
+// RenameStaticEntry moves the static host entry of an instance device to the new name of the instance.
+func RenameStaticEntry(network string, projectName string, oldInstanceName string, newInstanceName string, deviceName string) error {
+	oldPath := DHCPStaticAllocationPath(network, StaticAllocationFileName(projectName, oldInstanceName, deviceName))
+	newPath := DHCPStaticAllocationPath(network, StaticAllocationFileName(projectName, newInstanceName, deviceName))
+
+	err := os.Rename(oldPath, newPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
 // Kill kills dnsmasq for a particular network (or optionally reloads it).
 func Kill(name string, reload bool) error {
 	pidPath := internalUtil.VarPath("networks", name, "dnsmasq.pid")
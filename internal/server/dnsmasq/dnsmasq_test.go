@@ -1,9 +1,12 @@
 package dnsmasq
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_staticAllocationFileName(t *testing.T) {
@@ -13,3 +16,26 @@ func Test_staticAllocationFileName(t *testing.T) {
 	fileName := StaticAllocationFileName(projectName, instanceName, deviceName)
 	assert.Equal(t, "test.project_test-instance.test-.--_----.device", fileName)
 }
+
+func TestRenameStaticEntry(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	hostsPath := DHCPStaticAllocationPath("incusbr0", "")
+	require.NoError(t, os.MkdirAll(hostsPath, 0755))
+
+	err := UpdateStaticEntry("incusbr0", "default", "c1", "eth0", map[string]string{}, "00:16:3e:00:00:01", "10.0.0.2", "")
+	require.NoError(t, err)
+
+	err = RenameStaticEntry("incusbr0", "default", "c1", "c2", "eth0")
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(hostsPath, StaticAllocationFileName("default", "c1", "eth0")))
+
+	_, IPv4, _, err := DHCPStaticAllocation("incusbr0", StaticAllocationFileName("default", "c2", "eth0"))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", IPv4.IP.String())
+
+	// Devices without a static entry are skipped.
+	err = RenameStaticEntry("incusbr0", "default", "c1", "c2", "eth1")
+	assert.NoError(t, err)
+}
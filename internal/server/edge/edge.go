@@ -0,0 +1,295 @@
+// Package edge keeps the local state of cluster members with unreliable links to the rest of the cluster.
+package edge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// DefaultThreshold is how long the member waits for a heartbeat before considering itself disconnected.
+const DefaultThreshold = 20 * time.Second
+
+// Instance is the cached record of an instance assigned to the member.
+type Instance struct {
+	Project string            `yaml:"project"`
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"`
+	Config  map[string]string `yaml:"config"`
+}
+
+// Cache is the state assigned to the member by the cluster, as of the last time it was connected.
+type Cache struct {
+	Synced    time.Time     `yaml:"synced"`
+	Projects  []api.Project `yaml:"projects"`
+	Instances []Instance    `yaml:"instances"`
+}
+
+// Change holds the volatile configuration changes made to an instance while disconnected.
+type Change struct {
+	Project string            `yaml:"project"`
+	Name    string            `yaml:"name"`
+	Time    time.Time         `yaml:"time"`
+	Config  map[string]string `yaml:"config"`
+}
+
+// Member tracks the connectivity of an edge member, its cached state and the changes queued while disconnected.
+type Member struct {
+	mu sync.Mutex
+
+	path      string
+	enabled   bool
+	threshold time.Duration
+
+	lastContact  time.Time
+	disconnected bool
+
+	cache   Cache
+	journal []Change
+
+	now func() time.Time
+}
+
+// NewMember returns a member keeping its state in the given directory, loading any previously saved state.
+func NewMember(path string) (*Member, error) {
+	m := &Member{
+		path:      path,
+		threshold: DefaultThreshold,
+		now:       time.Now,
+	}
+
+	m.lastContact = m.now()
+
+	err := m.load("state.yaml", &m.cache)
+	if err != nil {
+		return m, err
+	}
+
+	err = m.load("journal.yaml", &m.journal)
+	if err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+// load reads a state file, leaving the target untouched if it doesn't exist.
+func (m *Member) load(name string, target any) error {
+	data, err := os.ReadFile(filepath.Join(m.path, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	err = yaml.Unmarshal(data, target)
+	if err != nil {
+		return fmt.Errorf("Failed parsing %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// save atomically writes a state file.
+func (m *Member) save(name string, source any) error {
+	data, err := yaml.Marshal(source)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(m.path, 0700)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.path, name)
+
+	err = os.WriteFile(path+".tmp", data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// SetEnabled turns edge mode on or off.
+func (m *Member) SetEnabled(enabled bool) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.enabled = enabled
+	m.lastContact = m.now()
+}
+
+// Enabled returns whether edge mode is enabled.
+func (m *Member) Enabled() bool {
+	if m == nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.enabled
+}
+
+// Contact records a heartbeat from the cluster, with the threshold after which missing heartbeats mean the
+// member is disconnected. It returns true when the member was disconnected until now.
+func (m *Member) Contact(threshold time.Duration) bool {
+	if m == nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if threshold > 0 {
+		m.threshold = threshold
+	}
+
+	m.lastContact = m.now()
+
+	reconnected := m.disconnected
+	m.disconnected = false
+
+	return reconnected
+}
+
+// Disconnected returns whether the member is in edge mode and hasn't heard from the cluster in a while.
+func (m *Member) Disconnected() bool {
+	if m == nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.enabled {
+		return false
+	}
+
+	if m.now().Sub(m.lastContact) > m.threshold {
+		m.disconnected = true
+	}
+
+	return m.disconnected
+}
+
+// Save replaces the cached state of the member.
+func (m *Member) Save(cache Cache) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	err := m.save("state.yaml", cache)
+	if err != nil {
+		return fmt.Errorf("Failed saving edge state: %w", err)
+	}
+
+	m.cache = cache
+
+	return nil
+}
+
+// Synced returns when the cached state was last saved.
+func (m *Member) Synced() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.cache.Synced
+}
+
+// Instance returns the cached record of an instance assigned to the member, along with its project.
+func (m *Member) Instance(projectName string, instanceName string) (*Instance, *api.Project, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, inst := range m.cache.Instances {
+		if inst.Project != projectName || inst.Name != instanceName {
+			continue
+		}
+
+		for j, p := range m.cache.Projects {
+			if p.Name == projectName {
+				return &m.cache.Instances[i], &m.cache.Projects[j], true
+			}
+		}
+
+		return nil, nil, false
+	}
+
+	return nil, nil, false
+}
+
+// Queue records volatile configuration changes made to an instance while disconnected.
+func (m *Member) Queue(projectName string, instanceName string, config map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	journal := make([]Change, 0, len(m.journal)+1)
+	merged := Change{Project: projectName, Name: instanceName, Config: map[string]string{}}
+
+	// Keep a single entry per instance, with the latest value of each key.
+	for _, change := range m.journal {
+		if change.Project == projectName && change.Name == instanceName {
+			for key, value := range change.Config {
+				merged.Config[key] = value
+			}
+
+			continue
+		}
+
+		journal = append(journal, change)
+	}
+
+	for key, value := range config {
+		merged.Config[key] = value
+	}
+
+	merged.Time = m.now()
+	journal = append(journal, merged)
+
+	err := m.save("journal.yaml", journal)
+	if err != nil {
+		return fmt.Errorf("Failed saving edge journal: %w", err)
+	}
+
+	m.journal = journal
+
+	return nil
+}
+
+// Journal returns the changes queued while disconnected.
+func (m *Member) Journal() []Change {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Change(nil), m.journal...)
+}
+
+// ClearJournal drops the queued changes once they have been reconciled.
+func (m *Member) ClearJournal() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	err := os.Remove(filepath.Join(m.path, "journal.yaml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	m.journal = nil
+
+	return nil
+}
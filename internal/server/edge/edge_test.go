@@ -0,0 +1,101 @@
+package edge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestMemberDisconnected(t *testing.T) {
+	m, err := NewMember(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	// Edge mode is off by default.
+	now = now.Add(time.Minute)
+	assert.False(t, m.Disconnected())
+
+	m.SetEnabled(true)
+	assert.False(t, m.Contact(10*time.Second))
+
+	now = now.Add(5 * time.Second)
+	assert.False(t, m.Disconnected())
+
+	now = now.Add(10 * time.Second)
+	assert.True(t, m.Disconnected())
+	assert.True(t, m.Contact(10*time.Second))
+	assert.False(t, m.Disconnected())
+
+	var nilMember *Member
+	assert.False(t, nilMember.Disconnected())
+}
+
+func TestMemberPersistence(t *testing.T) {
+	path := t.TempDir()
+
+	m, err := NewMember(path)
+	require.NoError(t, err)
+
+	cache := Cache{
+		Synced:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Projects:  []api.Project{{Name: "default"}},
+		Instances: []Instance{{Project: "default", Name: "c1", Type: "container", Config: map[string]string{"volatile.last_state.power": "RUNNING"}}},
+	}
+
+	require.NoError(t, m.Save(cache))
+	require.NoError(t, m.Queue("default", "c1", map[string]string{"volatile.last_state.power": "STOPPED"}))
+	require.NoError(t, m.Queue("default", "c1", map[string]string{"volatile.eth0.host_name": "veth1"}))
+
+	m, err = NewMember(path)
+	require.NoError(t, err)
+
+	inst, p, ok := m.Instance("default", "c1")
+	require.True(t, ok)
+	assert.Equal(t, "container", inst.Type)
+	assert.Equal(t, "default", p.Name)
+
+	_, _, ok = m.Instance("default", "c2")
+	assert.False(t, ok)
+
+	journal := m.Journal()
+	require.Len(t, journal, 1)
+	assert.Equal(t, map[string]string{"volatile.last_state.power": "STOPPED", "volatile.eth0.host_name": "veth1"}, journal[0].Config)
+
+	require.NoError(t, m.ClearJournal())
+
+	m, err = NewMember(path)
+	require.NoError(t, err)
+	assert.Empty(t, m.Journal())
+}
+
+func TestReconcile(t *testing.T) {
+	cached := &Instance{Project: "default", Name: "c1", Config: map[string]string{"volatile.last_state.power": "RUNNING", "volatile.eth0.hwaddr": "00:16:3e:00:00:01"}}
+	change := Change{Project: "default", Name: "c1", Config: map[string]string{
+		"volatile.last_state.power": "STOPPED",
+		"volatile.eth0.hwaddr":      "00:16:3e:00:00:02",
+		"volatile.eth0.host_name":   "veth1",
+	}}
+
+	current := map[string]string{"volatile.last_state.power": "RUNNING", "volatile.eth0.hwaddr": "00:16:3e:00:00:03"}
+
+	apply, conflicts := Reconcile(change, cached, current, "edge1", "edge1")
+	assert.Equal(t, map[string]string{"volatile.last_state.power": "STOPPED", "volatile.eth0.host_name": "veth1"}, apply)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, `volatile.eth0.hwaddr: Changed to "00:16:3e:00:00:03" in the cluster and to "00:16:3e:00:00:02" locally, keeping the cluster value`, conflicts[0].String())
+
+	apply, conflicts = Reconcile(change, cached, current, "server1", "edge1")
+	assert.Empty(t, apply)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, `Instance was moved to "server1" while disconnected`, conflicts[0].String())
+
+	apply, conflicts = Reconcile(change, cached, nil, "", "edge1")
+	assert.Empty(t, apply)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "Instance was deleted from the cluster while disconnected", conflicts[0].String())
+}
@@ -0,0 +1,73 @@
+package edge
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Conflict is a change queued while disconnected which couldn't be applied to the cluster database.
+type Conflict struct {
+	Project string
+	Name    string
+	Key     string
+	Reason  string
+}
+
+// String returns a human-readable description of the conflict.
+func (c Conflict) String() string {
+	if c.Key == "" {
+		return c.Reason
+	}
+
+	return fmt.Sprintf("%s: %s", c.Key, c.Reason)
+}
+
+// Reconcile compares the changes queued for an instance with its record in the cluster database.
+// The cached record is the one the member knew about when it got disconnected, current is the record
+// in the database now (nil if the instance was deleted) and location the member it's now assigned to.
+// It returns the changes to apply to the database and the ones conflicting with changes made in the cluster,
+// in which case the cluster value wins.
+func Reconcile(change Change, cached *Instance, current map[string]string, location string, member string) (map[string]string, []Conflict) {
+	conflict := func(key string, reason string, args ...any) Conflict {
+		return Conflict{Project: change.Project, Name: change.Name, Key: key, Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	if current == nil {
+		return nil, []Conflict{conflict("", "Instance was deleted from the cluster while disconnected")}
+	}
+
+	if location != member {
+		return nil, []Conflict{conflict("", "Instance was moved to %q while disconnected", location)}
+	}
+
+	keys := make([]string, 0, len(change.Config))
+	for key := range change.Config {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	apply := map[string]string{}
+	var conflicts []Conflict
+
+	for _, key := range keys {
+		value := change.Config[key]
+
+		known := ""
+		if cached != nil {
+			known = cached.Config[key]
+		}
+
+		// The key was changed in the cluster too, keep its value unless both agree.
+		if current[key] != known && current[key] != value {
+			conflicts = append(conflicts, conflict(key, "Changed to %q in the cluster and to %q locally, keeping the cluster value", current[key], value))
+			continue
+		}
+
+		if current[key] != value {
+			apply[key] = value
+		}
+	}
+
+	return apply, conflicts
+}
@@ -5,8 +5,6 @@ import (
 	"net"
 	"sync"
 
-	"github.com/armon/go-proxyproto"
-
 	"github.com/lxc/incus/v6/internal/server/util"
 	localtls "github.com/lxc/incus/v6/shared/tls"
 )
@@ -18,7 +16,7 @@ type FancyTLSListener struct {
 	net.Listener
 	mu           sync.RWMutex
 	config       *tls.Config
-	trustedProxy []net.IP
+	trustedProxy []*net.IPNet
 }
 
 // NewFancyTLSListener creates a new FancyTLSListener.
@@ -43,7 +41,7 @@ func (l *FancyTLSListener) Accept() (net.Conn, error) {
 	defer l.mu.RUnlock()
 	config := l.config
 	if isProxy(c.RemoteAddr().String(), l.trustedProxy) {
-		c = proxyproto.NewConn(c, 0)
+		c = newProxyConn(c)
 	}
 
 	return tls.Server(c, config), nil
@@ -60,25 +58,29 @@ func (l *FancyTLSListener) Config(cert *localtls.CertInfo) {
 }
 
 // TrustedProxy sets new the https trusted proxy configuration.
-func (l *FancyTLSListener) TrustedProxy(trustedProxy []net.IP) {
+func (l *FancyTLSListener) TrustedProxy(trustedProxy []*net.IPNet) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
 	l.trustedProxy = trustedProxy
 }
 
-func isProxy(addr string, proxies []net.IP) bool {
+func isProxy(addr string, proxies []*net.IPNet) bool {
 	host, _, err := net.SplitHostPort(addr)
 	if err != nil {
 		return false
 	}
 
 	hostIP := net.ParseIP(host)
+	if hostIP == nil {
+		return false
+	}
 
 	for _, p := range proxies {
-		if hostIP.Equal(p) {
+		if p.Contains(hostIP) {
 			return true
 		}
 	}
+
 	return false
 }
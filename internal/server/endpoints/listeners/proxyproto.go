@@ -0,0 +1,145 @@
+package listeners
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/armon/go-proxyproto"
+)
+
+// proxyV2Signature is the signature starting the headers of version 2 of the PROXY protocol.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyConn is a connection from a trusted proxy, which starts with a PROXY protocol header.
+// Both the text (version 1) and binary (version 2) headers are supported.
+// The header is read on first use of the connection so that Accept doesn't block on slow clients.
+type proxyConn struct {
+	net.Conn
+
+	once       sync.Once
+	reader     *bufio.Reader
+	v1Conn     net.Conn
+	remoteAddr net.Addr
+	err        error
+}
+
+// bufferedConn is a connection whose reads go through a buffered reader.
+type bufferedConn struct {
+	net.Conn
+
+	reader *bufio.Reader
+}
+
+// Read reads from the buffered reader.
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func newProxyConn(c net.Conn) net.Conn {
+	return &proxyConn{Conn: c}
+}
+
+func (c *proxyConn) readHeader() {
+	c.reader = bufio.NewReader(c.Conn)
+
+	signature, err := c.reader.Peek(len(proxyV2Signature))
+	if err != nil || !bytes.Equal(signature, proxyV2Signature) {
+		// Not a version 2 header, let the version 1 parser handle it.
+		c.v1Conn = proxyproto.NewConn(&bufferedConn{Conn: c.Conn, reader: c.reader}, 0)
+		return
+	}
+
+	c.remoteAddr, c.err = readProxyV2Header(c.reader)
+	if c.remoteAddr == nil {
+		c.remoteAddr = c.Conn.RemoteAddr()
+	}
+}
+
+// Read reads from the connection, past the PROXY protocol header.
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+
+	if c.v1Conn != nil {
+		return c.v1Conn.Read(b)
+	}
+
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the address of the client as provided by the proxy.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+
+	if c.v1Conn != nil {
+		return c.v1Conn.RemoteAddr()
+	}
+
+	return c.remoteAddr
+}
+
+// readProxyV2Header reads a version 2 PROXY protocol header and returns the source address it holds.
+// A nil address is returned for connections initiated by the proxy itself and for unsupported address families.
+func readProxyV2Header(r io.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading PROXY protocol header: %w", err)
+	}
+
+	verCmd := header[12]
+	if verCmd>>4 != 2 {
+		return nil, fmt.Errorf("Unsupported PROXY protocol version %d", verCmd>>4)
+	}
+
+	family := header[13]
+	length := binary.BigEndian.Uint16(header[14:16])
+
+	addresses := make([]byte, length)
+	_, err = io.ReadFull(r, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading PROXY protocol addresses: %w", err)
+	}
+
+	switch verCmd & 0x0F {
+	case 0x00:
+		// LOCAL command, the connection was initiated by the proxy.
+		return nil, nil
+	case 0x01:
+		// PROXY command.
+	default:
+		return nil, fmt.Errorf("Unsupported PROXY protocol command %d", verCmd&0x0F)
+	}
+
+	// Only streams are relevant to the listeners.
+	if family&0x0F != 0x01 {
+		return nil, nil
+	}
+
+	switch family >> 4 {
+	case 0x01:
+		// IPv4: source address, destination address, source port, destination port.
+		if len(addresses) < 12 {
+			return nil, fmt.Errorf("Invalid PROXY protocol IPv4 addresses length %d", len(addresses))
+		}
+
+		return &net.TCPAddr{IP: net.IP(addresses[0:4]), Port: int(binary.BigEndian.Uint16(addresses[8:10]))}, nil
+	case 0x02:
+		// IPv6: source address, destination address, source port, destination port.
+		if len(addresses) < 36 {
+			return nil, fmt.Errorf("Invalid PROXY protocol IPv6 addresses length %d", len(addresses))
+		}
+
+		return &net.TCPAddr{IP: net.IP(addresses[0:16]), Port: int(binary.BigEndian.Uint16(addresses[32:34]))}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,98 @@
+package listeners
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"log"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// proxyV2Header returns a version 2 PROXY protocol header with the given command, family and addresses.
+func proxyV2Header(command byte, family byte, addresses []byte) []byte {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x20|command, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(addresses)))
+
+	return append(header, addresses...)
+}
+
+func TestReadProxyV2Header(t *testing.T) {
+	ipv4 := []byte{192, 0, 2, 1, 192, 0, 2, 2}
+	ipv4 = binary.BigEndian.AppendUint16(ipv4, 51234)
+	ipv4 = binary.BigEndian.AppendUint16(ipv4, 8443)
+
+	ipv6 := append(net.ParseIP("2001:db8::1").To16(), net.ParseIP("2001:db8::2").To16()...)
+	ipv6 = binary.BigEndian.AppendUint16(ipv6, 51234)
+	ipv6 = binary.BigEndian.AppendUint16(ipv6, 8443)
+
+	tests := []struct {
+		name   string
+		header []byte
+		addr   string
+		fails  bool
+	}{
+		{"IPv4", proxyV2Header(0x01, 0x11, ipv4), "192.0.2.1:51234", false},
+		{"IPv6", proxyV2Header(0x01, 0x21, ipv6), "[2001:db8::1]:51234", false},
+		{"Local command", proxyV2Header(0x00, 0x00, nil), "", false},
+		{"Datagram", proxyV2Header(0x01, 0x12, ipv4), "", false},
+		{"Unknown command", proxyV2Header(0x02, 0x11, ipv4), "", true},
+		{"Short IPv4 addresses", proxyV2Header(0x01, 0x11, ipv4[:8]), "", true},
+		{"Truncated", proxyV2Header(0x01, 0x11, ipv4)[:20], "", true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		addr, err := readProxyV2Header(bytes.NewReader(tt.header))
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		if tt.addr == "" {
+			require.Nil(t, addr)
+			continue
+		}
+
+		require.Equal(t, tt.addr, addr.String())
+	}
+}
+
+func TestProxyConn(t *testing.T) {
+	addresses := []byte{192, 0, 2, 1, 192, 0, 2, 2}
+	addresses = binary.BigEndian.AppendUint16(addresses, 51234)
+	addresses = binary.BigEndian.AppendUint16(addresses, 8443)
+
+	tests := []struct {
+		name   string
+		header []byte
+		addr   string
+	}{
+		{"Version 1", []byte("PROXY TCP4 192.0.2.1 192.0.2.2 51234 8443\r\n"), "192.0.2.1:51234"},
+		{"Version 2", proxyV2Header(0x01, 0x11, addresses), "192.0.2.1:51234"},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		client, server := net.Pipe()
+		go func() {
+			_, _ = client.Write(append(tt.header, []byte("hello")...))
+			_ = client.Close()
+		}()
+
+		conn := newProxyConn(server)
+		require.Equal(t, tt.addr, conn.RemoteAddr().String())
+
+		// The data following the header is left untouched.
+		data, err := io.ReadAll(conn)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(data))
+		_ = conn.Close()
+	}
+}
@@ -12,7 +12,6 @@ import (
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/logger"
 	localtls "github.com/lxc/incus/v6/shared/tls"
-	"github.com/lxc/incus/v6/shared/util"
 )
 
 // NetworkPublicKey returns the public key of the TLS certificate used by the
@@ -150,15 +149,7 @@ func (e *Endpoints) NetworkUpdateCert(cert *localtls.CertInfo) {
 
 // NetworkUpdateTrustedProxy updates the https trusted proxy used by the network endpoint.
 func (e *Endpoints) NetworkUpdateTrustedProxy(trustedProxy string) {
-	var proxies []net.IP
-	for _, p := range util.SplitNTrimSpace(trustedProxy, ",", -1, true) {
-		proxyIP := net.ParseIP(p)
-		if proxyIP == nil {
-			continue
-		}
-
-		proxies = append(proxies, proxyIP)
-	}
+	proxies := parseTrustedProxies(trustedProxy)
 
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -6,10 +6,39 @@ import (
 	"regexp"
 
 	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
 )
 
+// parseTrustedProxies parses a comma-separated list of trusted proxy addresses and subnets.
+// Invalid entries are ignored.
+func parseTrustedProxies(value string) []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, p := range util.SplitNTrimSpace(value, ",", -1, true) {
+		_, subnet, err := net.ParseCIDR(p)
+		if err == nil {
+			proxies = append(proxies, subnet)
+			continue
+		}
+
+		ip := net.ParseIP(p)
+		if ip == nil {
+			continue
+		}
+
+		bits := 128
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 32
+		}
+
+		proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+
+	return proxies
+}
+
 type networkServerErrorLogWriter struct {
-	proxies []net.IP
+	proxies []*net.IPNet
 }
 
 // Regex for the log we want to ignore.
@@ -51,9 +80,10 @@ func (d networkServerErrorLogWriter) stripLog(p []byte) string {
 	}
 
 	// Discard the log if the source is in our list of trusted proxies.
-	if sourceIP != "" {
-		for _, ip := range d.proxies {
-			if ip.String() == sourceIP {
+	ip := net.ParseIP(sourceIP)
+	if ip != nil {
+		for _, proxy := range d.proxies {
+			if proxy.Contains(ip) {
 				return ""
 			}
 		}
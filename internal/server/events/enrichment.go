@@ -0,0 +1,151 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// LifecycleEnrichment maps lifecycle actions (or action patterns ending with `*`) to the instance fields embedded in their events.
+type LifecycleEnrichment map[string][]string
+
+// ValidateLifecycleEnrichmentRule validates a single `<action>:<field>` enrichment rule.
+func ValidateLifecycleEnrichmentRule(value string) error {
+	action, field, found := strings.Cut(value, ":")
+	if !found || action == "" {
+		return fmt.Errorf("Invalid enrichment rule %q, expected <action>:<field>", value)
+	}
+
+	if strings.Contains(strings.TrimSuffix(action, "*"), "*") {
+		return fmt.Errorf("Invalid action pattern %q, only a trailing wildcard is supported", action)
+	}
+
+	switch {
+	case field == "labels", field == "image":
+		return nil
+	case strings.HasPrefix(field, "config."):
+		key := strings.TrimPrefix(field, "config.")
+		if key == "" || strings.Contains(strings.TrimSuffix(key, "*"), "*") {
+			return fmt.Errorf("Invalid configuration key pattern %q", key)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("Unknown enrichment field %q, expected labels, image or config.<key>", field)
+}
+
+// ParseLifecycleEnrichment parses a comma-separated list of `<action>:<field>` enrichment rules.
+func ParseLifecycleEnrichment(value string) (LifecycleEnrichment, error) {
+	enrichment := LifecycleEnrichment{}
+
+	for _, rule := range strings.Split(value, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		err := ValidateLifecycleEnrichmentRule(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		action, field, _ := strings.Cut(rule, ":")
+		enrichment[action] = append(enrichment[action], field)
+	}
+
+	return enrichment, nil
+}
+
+// matchPattern returns whether a value matches a pattern, which may end with a `*` wildcard.
+func matchPattern(pattern string, value string) bool {
+	prefix, wildcard := strings.CutSuffix(pattern, "*")
+	if wildcard {
+		return strings.HasPrefix(value, prefix)
+	}
+
+	return pattern == value
+}
+
+// fields returns the fields to embed in the events of a lifecycle action.
+func (e LifecycleEnrichment) fields(action string) []string {
+	var fields []string
+	for pattern, patternFields := range e {
+		if matchPattern(pattern, action) {
+			fields = append(fields, patternFields...)
+		}
+	}
+
+	return fields
+}
+
+// Build returns the enrichment of the event of a lifecycle action from the expanded configuration of its instance.
+func (e LifecycleEnrichment) Build(action string, config map[string]string) *api.EventLifecycleEnrichment {
+	return buildLifecycleEnrichment(e.fields(action), config)
+}
+
+// buildLifecycleEnrichment returns the enrichment of an event from the configuration of its instance.
+func buildLifecycleEnrichment(fields []string, config map[string]string) *api.EventLifecycleEnrichment {
+	enrichment := &api.EventLifecycleEnrichment{}
+
+	for _, field := range fields {
+		switch {
+		case field == "labels":
+			for key, value := range config {
+				label, found := strings.CutPrefix(key, "user.")
+				if !found {
+					continue
+				}
+
+				if enrichment.Labels == nil {
+					enrichment.Labels = map[string]string{}
+				}
+
+				enrichment.Labels[label] = value
+			}
+
+		case field == "image":
+			for key, value := range config {
+				property, found := strings.CutPrefix(key, "image.")
+				if !found {
+					continue
+				}
+
+				if enrichment.Image == nil {
+					enrichment.Image = map[string]string{}
+				}
+
+				enrichment.Image[property] = value
+			}
+
+			if config["volatile.base_image"] != "" {
+				if enrichment.Image == nil {
+					enrichment.Image = map[string]string{}
+				}
+
+				enrichment.Image["fingerprint"] = config["volatile.base_image"]
+			}
+
+		case strings.HasPrefix(field, "config."):
+			pattern := strings.TrimPrefix(field, "config.")
+			for key, value := range config {
+				if !matchPattern(pattern, key) {
+					continue
+				}
+
+				if enrichment.Config == nil {
+					enrichment.Config = map[string]string{}
+				}
+
+				enrichment.Config[key] = value
+			}
+		}
+	}
+
+	if enrichment.Config == nil && enrichment.Labels == nil && enrichment.Image == nil {
+		return nil
+	}
+
+	return enrichment
+}
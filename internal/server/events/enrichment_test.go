@@ -0,0 +1,60 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLifecycleEnrichment(t *testing.T) {
+	enrichment, err := ParseLifecycleEnrichment("instance-*:labels, instance-started:config.limits.*,instance-started:image")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"labels", "config.limits.*", "image"}, enrichment.fields("instance-started"))
+	assert.Equal(t, []string{"labels"}, enrichment.fields("instance-stopped"))
+	assert.Empty(t, enrichment.fields("network-created"))
+
+	for _, value := range []string{"instance-started", "instance-started:foo", "in*stance:labels", ":labels", "instance-started:config."} {
+		_, err := ParseLifecycleEnrichment(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestBuildLifecycleEnrichment(t *testing.T) {
+	config := map[string]string{
+		"limits.cpu":          "4",
+		"limits.memory":       "4GiB",
+		"security.nesting":    "true",
+		"user.team":           "web",
+		"image.os":            "Debian",
+		"volatile.base_image": "a0dd6e2b7d2b",
+	}
+
+	enrichment := buildLifecycleEnrichment([]string{"config.limits.*", "labels", "image"}, config)
+	require.NotNil(t, enrichment)
+	assert.Equal(t, map[string]string{"limits.cpu": "4", "limits.memory": "4GiB"}, enrichment.Config)
+	assert.Equal(t, map[string]string{"team": "web"}, enrichment.Labels)
+	assert.Equal(t, map[string]string{"os": "Debian", "fingerprint": "a0dd6e2b7d2b"}, enrichment.Image)
+
+	assert.Nil(t, buildLifecycleEnrichment([]string{"config.boot.*"}, config))
+}
+
+func TestLifecycleEnrichmentBuild(t *testing.T) {
+	enrichment, err := ParseLifecycleEnrichment("instance-*:labels,instance-created:config.limits.*")
+	require.NoError(t, err)
+
+	config := map[string]string{"limits.cpu": "4", "user.team": "web"}
+
+	created := enrichment.Build("instance-created", config)
+	require.NotNil(t, created)
+	assert.Equal(t, map[string]string{"team": "web"}, created.Labels)
+	assert.Equal(t, map[string]string{"limits.cpu": "4"}, created.Config)
+
+	deleted := enrichment.Build("instance-deleted", config)
+	require.NotNil(t, deleted)
+	assert.Equal(t, map[string]string{"team": "web"}, deleted.Labels)
+	assert.Nil(t, deleted.Config)
+
+	assert.Nil(t, enrichment.Build("network-created", config))
+}
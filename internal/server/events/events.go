@@ -33,6 +33,9 @@ type InjectFunc func(event api.Event, eventSource EventSource)
 // NotifyFunc is called when an event is dispatched.
 type NotifyFunc func(event api.Event)
 
+// EventFilter returns whether an event should be delivered to a listener.
+type EventFilter func(event api.Event) bool
+
 // Server represents an instance of an event server.
 type Server struct {
 	serverCommon
@@ -40,6 +43,7 @@ type Server struct {
 	listeners map[string]*Listener
 	notify    NotifyFunc
 	location  string
+	journal   *Journal
 }
 
 // NewServer returns a new event server.
@@ -65,8 +69,58 @@ func (s *Server) SetLocalLocation(location string) {
 	s.location = location
 }
 
+// SetJournal enables the on-disk event journal at the given path or updates its maximum size.
+func (s *Server) SetJournal(path string, maxSize int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.journal != nil {
+		s.journal.SetMaxSize(maxSize)
+		return nil
+	}
+
+	journal, err := NewJournal(path, maxSize)
+	if err != nil {
+		return err
+	}
+
+	s.journal = journal
+
+	return nil
+}
+
+// Replay sends the journaled events which came after the given position to a listener.
+// Events broadcast while replaying may be delivered twice, their ID can be used to detect duplicates.
+func (s *Server) Replay(listener *Listener, since string) error {
+	s.lock.Lock()
+	journal := s.journal
+	s.lock.Unlock()
+
+	if journal == nil {
+		return fmt.Errorf("Event journal isn't available")
+	}
+
+	events, err := journal.Since(since)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if !listener.wants(event) {
+			continue
+		}
+
+		err := listener.WriteJSON(event)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // AddListener creates and returns a new event listener.
-func (s *Server) AddListener(projectName string, allProjects bool, projectPermissionFunc auth.PermissionChecker, connection EventListenerConnection, messageTypes []string, excludeSources []EventSource, recvFunc EventHandler, excludeLocations []string) (*Listener, error) {
+func (s *Server) AddListener(projectName string, allProjects bool, projectPermissionFunc auth.PermissionChecker, connection EventListenerConnection, messageTypes []string, excludeSources []EventSource, recvFunc EventHandler, excludeLocations []string, filter EventFilter) (*Listener, error) {
 	if allProjects && projectName != "" {
 		return nil, fmt.Errorf("Cannot specify project name when listening for events on all projects")
 	}
@@ -91,6 +145,7 @@ func (s *Server) AddListener(projectName string, allProjects bool, projectPermis
 		projectPermissionFunc: projectPermissionFunc,
 		excludeSources:        excludeSources,
 		excludeLocations:      excludeLocations,
+		filter:                filter,
 	}
 
 	s.lock.Lock()
@@ -174,6 +229,18 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 		event.Location = s.location
 	}
 
+	journal := s.journal
+	s.lock.Unlock()
+
+	// Record the event in the journal, logging events aren't kept as they're too verbose.
+	// This is done outside of the lock so that a slow disk doesn't hold up the delivery of other events.
+	var journalErr error
+	if journal != nil && event.Type != api.EventTypeLogging && event.Type != api.EventTypeInstanceLog {
+		journalErr = journal.Append(&event)
+	}
+
+	s.lock.Lock()
+
 	// If a notifcation hook is present, then call it for locally produced events.
 	// This can be used to send local events to another target (such as an event-hub member).
 	if s.notify != nil && eventSource == EventSourceLocal {
@@ -182,13 +249,7 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 
 	listeners := s.listeners
 	for _, listener := range listeners {
-		// If the event is project specific, check if the listener is requesting events from that project.
-		if event.Project != "" && !listener.allProjects && event.Project != listener.projectName {
-			continue
-		}
-
-		// If the event is project specific, ensure we have permission to view it.
-		if event.Project != "" && !listener.projectPermissionFunc(auth.ObjectProject(event.Project)) {
+		if !listener.wants(event) {
 			continue
 		}
 
@@ -196,10 +257,6 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 			continue
 		}
 
-		if !slices.Contains(listener.messageTypes, event.Type) {
-			continue
-		}
-
 		// If the event doesn't come from this member and has been excluded by listener, don't deliver it.
 		if eventSource != EventSourceLocal && slices.Contains(listener.excludeLocations, event.Location) {
 			continue
@@ -234,6 +291,11 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 
 	s.lock.Unlock()
 
+	// Log outside of the lock as logging produces events too.
+	if journalErr != nil {
+		logger.Warn("Failed recording event in journal", logger.Ctx{"err": journalErr})
+	}
+
 	return nil
 }
 
@@ -246,4 +308,28 @@ type Listener struct {
 	projectPermissionFunc auth.PermissionChecker
 	excludeSources        []EventSource
 	excludeLocations      []string
+	filter                EventFilter
+}
+
+// wants returns whether the event matches the project, type and filter of the listener.
+func (l *Listener) wants(event api.Event) bool {
+	// If the event is project specific, check if the listener is requesting events from that project.
+	if event.Project != "" && !l.allProjects && event.Project != l.projectName {
+		return false
+	}
+
+	// If the event is project specific, ensure we have permission to view it.
+	if event.Project != "" && !l.projectPermissionFunc(auth.ObjectProject(event.Project)) {
+		return false
+	}
+
+	if !slices.Contains(l.messageTypes, event.Type) {
+		return false
+	}
+
+	if l.filter != nil && !l.filter(event) {
+		return false
+	}
+
+	return true
 }
@@ -0,0 +1,217 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Journal is a bounded on-disk record of the recent events, allowing clients to catch up after disconnecting.
+// It's made of the current file and the previous one, each holding up to half of the maximum size.
+type Journal struct {
+	path    string
+	maxSize int64
+
+	lock   sync.Mutex
+	file   *os.File
+	size   int64
+	lastID uint64
+}
+
+// NewJournal opens (or creates) the event journal at the given path.
+func NewJournal(path string, maxSize int64) (*Journal, error) {
+	j := &Journal{
+		path:    path,
+		maxSize: maxSize,
+	}
+
+	// Find the last used ID so that IDs keep increasing across restarts.
+	for _, journalPath := range []string{j.previousPath(), j.path} {
+		err := journalRead(journalPath, func(event api.Event) {
+			j.lastID = max(j.lastID, event.ID)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := j.open(os.O_APPEND)
+	if err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// previousPath returns the path of the rotated journal file.
+func (j *Journal) previousPath() string {
+	return fmt.Sprintf("%s.1", j.path)
+}
+
+// open opens the current journal file for writing.
+func (j *Journal) open(flag int) error {
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|flag, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed opening event journal %q: %w", j.path, err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("Failed getting size of event journal %q: %w", j.path, err)
+	}
+
+	j.file = f
+	j.size = fi.Size()
+
+	return nil
+}
+
+// SetMaxSize changes the maximum size of the journal. A size of zero disables journaling.
+func (j *Journal) SetMaxSize(maxSize int64) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.maxSize = maxSize
+}
+
+// Append records an event in the journal, assigning it the next event ID.
+func (j *Journal) Append(event *api.Event) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.maxSize <= 0 || j.file == nil {
+		return nil
+	}
+
+	event.ID = j.lastID + 1
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+
+	// Rotate the journal once the current file is full.
+	if j.size > 0 && j.size+int64(len(data)) > j.maxSize/2 {
+		err = j.rotate()
+		if err != nil {
+			return err
+		}
+	}
+
+	n, err := j.file.Write(data)
+	j.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("Failed writing to event journal: %w", err)
+	}
+
+	j.lastID = event.ID
+
+	return nil
+}
+
+// rotate replaces the previous journal file with the current one and starts a new one.
+func (j *Journal) rotate() error {
+	_ = j.file.Close()
+	j.file = nil
+
+	err := os.Rename(j.path, j.previousPath())
+	if err != nil {
+		return fmt.Errorf("Failed rotating event journal: %w", err)
+	}
+
+	return j.open(os.O_TRUNC)
+}
+
+// Since returns the journaled events which came after the given position.
+// The position is either an event ID or an RFC3339 timestamp.
+func (j *Journal) Since(since string) ([]api.Event, error) {
+	sinceID, sinceTime, err := ParseJournalPosition(since)
+	if err != nil {
+		return nil, err
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	events := []api.Event{}
+
+	for _, journalPath := range []string{j.previousPath(), j.path} {
+		err := journalRead(journalPath, func(event api.Event) {
+			if (sinceTime.IsZero() && event.ID > sinceID) || (!sinceTime.IsZero() && event.Timestamp.After(sinceTime)) {
+				events = append(events, event)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+// Close closes the journal.
+func (j *Journal) Close() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+
+	return err
+}
+
+// ParseJournalPosition parses a journal position, returning either the event ID or the timestamp it refers to.
+func ParseJournalPosition(since string) (uint64, time.Time, error) {
+	sinceID, err := strconv.ParseUint(since, 10, 64)
+	if err == nil {
+		return sinceID, time.Time{}, nil
+	}
+
+	sinceTime, err := time.Parse(time.RFC3339Nano, since)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("Invalid journal position %q, must be an event ID or an RFC3339 timestamp", since)
+	}
+
+	return 0, sinceTime, nil
+}
+
+// journalRead calls the handler for every event stored in a journal file.
+// A missing file is treated as empty and reading stops at the first corrupted entry.
+func journalRead(path string, handler func(event api.Event)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("Failed opening event journal %q: %w", path, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	decoder := json.NewDecoder(f)
+	for {
+		event := api.Event{}
+
+		err := decoder.Decode(&event)
+		if err != nil {
+			// Stop at the end of the file or at a partially written entry.
+			return nil
+		}
+
+		handler(event)
+	}
+}
@@ -0,0 +1,115 @@
+package events
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.journal")
+
+	j, err := NewJournal(path, 1024*1024)
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		event := api.Event{Type: api.EventTypeLifecycle, Timestamp: start.Add(time.Duration(i) * time.Second)}
+		require.NoError(t, j.Append(&event))
+		assert.Equal(t, uint64(i+1), event.ID)
+	}
+
+	events, err := j.Since("1")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint64(2), events[0].ID)
+	assert.Equal(t, uint64(3), events[1].ID)
+
+	events, err = j.Since(start.Add(time.Second).Format(time.RFC3339Nano))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(3), events[0].ID)
+
+	_, err = j.Since("yesterday")
+	assert.Error(t, err)
+
+	// IDs keep increasing after re-opening the journal.
+	require.NoError(t, j.Close())
+
+	j, err = NewJournal(path, 1024*1024)
+	require.NoError(t, err)
+
+	event := api.Event{Type: api.EventTypeLifecycle, Timestamp: time.Now()}
+	require.NoError(t, j.Append(&event))
+	assert.Equal(t, uint64(4), event.ID)
+
+	require.NoError(t, j.Close())
+}
+
+func TestJournalRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.journal")
+
+	j, err := NewJournal(path, 1024)
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		event := api.Event{Type: api.EventTypeLifecycle, Timestamp: time.Now()}
+		require.NoError(t, j.Append(&event))
+	}
+
+	// Older events are dropped but the most recent ones are kept in order.
+	events, err := j.Since("0")
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+	assert.Less(t, len(events), 100)
+	assert.Equal(t, uint64(100), events[len(events)-1].ID)
+
+	for i := 1; i < len(events); i++ {
+		assert.Equal(t, events[i-1].ID+1, events[i].ID)
+	}
+
+	require.NoError(t, j.Close())
+}
+
+func TestBroadcastSlowJournal(t *testing.T) {
+	s := NewServer(false, false, nil)
+	require.NoError(t, s.SetJournal(filepath.Join(t.TempDir(), "events.journal"), 1024*1024))
+
+	// Simulate a slow disk by holding the journal lock.
+	s.journal.lock.Lock()
+
+	journaled := make(chan struct{})
+	go func() {
+		_ = s.broadcast(api.Event{Type: api.EventTypeLifecycle, Timestamp: time.Now()}, EventSourceLocal)
+		close(journaled)
+	}()
+
+	// Give the journaled event time to get stuck on the journal.
+	time.Sleep(100 * time.Millisecond)
+
+	// Events which aren't journaled are still delivered meanwhile.
+	delivered := make(chan struct{})
+	go func() {
+		_ = s.broadcast(api.Event{Type: api.EventTypeLogging, Timestamp: time.Now()}, EventSourceLocal)
+		close(delivered)
+	}()
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Event delivery blocked by the journal")
+	}
+
+	s.journal.lock.Unlock()
+	<-journaled
+
+	events, err := s.journal.Since("0")
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,198 @@
+package fault
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// Points at which faults can be injected.
+const (
+	PointStorageInstanceCreate = "storage.instance-create"
+	PointStorageInstanceDelete = "storage.instance-delete"
+	PointStorageVolumeCreate   = "storage.volume-create"
+	PointStorageVolumeDelete   = "storage.volume-delete"
+	PointStoragePoolMount      = "storage.pool-mount"
+	PointNetworkStart          = "network.start"
+	PointMigrationSend         = "migration.send"
+	PointMigrationReceive      = "migration.receive"
+)
+
+// Stages of an operation at which faults can be injected.
+const (
+	// StageStart is before the operation does anything.
+	StageStart = "start"
+
+	// StageEnd is once the operation did its work (for example created the volume or transferred the data),
+	// before it completes, so that the failure exercises its cleanup.
+	StageEnd = "end"
+)
+
+// Points is the list of all the points at which faults can be injected.
+var Points = []string{
+	PointStorageInstanceCreate,
+	PointStorageInstanceDelete,
+	PointStorageVolumeCreate,
+	PointStorageVolumeDelete,
+	PointStoragePoolMount,
+	PointNetworkStart,
+	PointMigrationSend,
+	PointMigrationReceive,
+}
+
+// Fault is a failure or delay injected into an operation.
+type Fault struct {
+	// Point at which to inject the fault.
+	Point string `json:"point" yaml:"point"`
+
+	// Stage of the operation at which to inject the fault (StageStart if empty).
+	Stage string `json:"stage" yaml:"stage"`
+
+	// Project restricts the fault to the instances, volumes and networks of a project (empty for any).
+	Project string `json:"project" yaml:"project"`
+
+	// Target restricts the fault to an instance, volume, pool or network name (empty for any).
+	Target string `json:"target" yaml:"target"`
+
+	// Error makes the operation fail with the given message (empty to only delay it).
+	Error string `json:"error" yaml:"error"`
+
+	// Delay before the operation proceeds or fails, as a Go duration.
+	Delay string `json:"delay" yaml:"delay"`
+
+	// Count is the number of times the fault is injected before being removed (0 for unlimited).
+	Count int `json:"count" yaml:"count"`
+}
+
+type entry struct {
+	fault Fault
+	delay time.Duration
+}
+
+var (
+	mu     sync.Mutex
+	faults []*entry
+)
+
+// Enabled returns whether fault injection was enabled when starting the daemon.
+func Enabled() bool {
+	return util.IsTrue(os.Getenv("INCUS_FAULT_INJECTION"))
+}
+
+// Add registers a new fault.
+func Add(f Fault) error {
+	if !Enabled() {
+		return fmt.Errorf("Fault injection isn't enabled")
+	}
+
+	if !slices.Contains(Points, f.Point) {
+		return fmt.Errorf("Unknown fault injection point %q", f.Point)
+	}
+
+	if f.Stage == "" {
+		f.Stage = StageStart
+	}
+
+	if !slices.Contains([]string{StageStart, StageEnd}, f.Stage) {
+		return fmt.Errorf("Unknown fault injection stage %q", f.Stage)
+	}
+
+	if f.Error == "" && f.Delay == "" {
+		return fmt.Errorf("A fault needs an error, a delay or both")
+	}
+
+	if f.Count < 0 {
+		return fmt.Errorf("Invalid fault count %d", f.Count)
+	}
+
+	var delay time.Duration
+	if f.Delay != "" {
+		var err error
+
+		delay, err = time.ParseDuration(f.Delay)
+		if err != nil {
+			return fmt.Errorf("Invalid fault delay %q: %w", f.Delay, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	faults = append(faults, &entry{fault: f, delay: delay})
+
+	return nil
+}
+
+// List returns the registered faults, with their remaining count.
+func List() []Fault {
+	mu.Lock()
+	defer mu.Unlock()
+
+	result := make([]Fault, 0, len(faults))
+	for _, e := range faults {
+		result = append(result, e.fault)
+	}
+
+	return result
+}
+
+// Clear removes all the registered faults.
+func Clear() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	faults = nil
+}
+
+// Inject applies the first fault registered for the point, stage, project and target, if any.
+// The project is empty for the targets which don't belong to a project, like storage pools.
+// It waits for the delay of the fault and returns its error.
+func Inject(point string, stage string, project string, target string) error {
+	mu.Lock()
+
+	var match *entry
+	for i, e := range faults {
+		if e.fault.Point != point || e.fault.Stage != stage {
+			continue
+		}
+
+		if (e.fault.Project != "" && e.fault.Project != project) || (e.fault.Target != "" && e.fault.Target != target) {
+			continue
+		}
+
+		match = e
+
+		if e.fault.Count > 0 {
+			e.fault.Count--
+
+			if e.fault.Count == 0 {
+				faults = slices.Delete(faults, i, i+1)
+			}
+		}
+
+		break
+	}
+
+	mu.Unlock()
+
+	if match == nil {
+		return nil
+	}
+
+	logger.Warn("Injecting fault", logger.Ctx{"point": point, "stage": stage, "project": project, "target": target, "delay": match.fault.Delay, "err": match.fault.Error})
+
+	if match.delay > 0 {
+		time.Sleep(match.delay)
+	}
+
+	if match.fault.Error != "" {
+		return fmt.Errorf("Injected fault: %s", match.fault.Error)
+	}
+
+	return nil
+}
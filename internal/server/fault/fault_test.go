@@ -0,0 +1,63 @@
+package fault
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddDisabled(t *testing.T) {
+	t.Setenv("INCUS_FAULT_INJECTION", "")
+
+	err := Add(Fault{Point: PointNetworkStart, Error: "boom"})
+	assert.EqualError(t, err, "Fault injection isn't enabled")
+}
+
+func TestAddInvalid(t *testing.T) {
+	t.Setenv("INCUS_FAULT_INJECTION", "true")
+	t.Cleanup(Clear)
+
+	assert.Error(t, Add(Fault{Point: "storage.unknown", Error: "boom"}))
+	assert.Error(t, Add(Fault{Point: PointNetworkStart}))
+	assert.Error(t, Add(Fault{Point: PointNetworkStart, Delay: "soon"}))
+	assert.Error(t, Add(Fault{Point: PointNetworkStart, Error: "boom", Count: -1}))
+	assert.Error(t, Add(Fault{Point: PointNetworkStart, Stage: "middle", Error: "boom"}))
+	assert.Empty(t, List())
+}
+
+func TestInject(t *testing.T) {
+	t.Setenv("INCUS_FAULT_INJECTION", "true")
+	t.Cleanup(Clear)
+
+	require.NoError(t, Add(Fault{Point: PointStorageVolumeCreate, Project: "foo", Target: "vol1", Error: "disk full", Count: 2}))
+	require.NoError(t, Add(Fault{Point: PointNetworkStart, Error: "no carrier"}))
+	require.Equal(t, StageStart, List()[0].Stage)
+
+	// Faults only apply to their point, project and target.
+	assert.NoError(t, Inject(PointStorageVolumeCreate, StageStart, "foo", "vol2"))
+	assert.NoError(t, Inject(PointStorageVolumeCreate, StageStart, "default", "vol1"))
+	assert.NoError(t, Inject(PointStorageVolumeDelete, StageStart, "foo", "vol1"))
+
+	// Faults only apply to their stage.
+	assert.NoError(t, Inject(PointStorageVolumeCreate, StageEnd, "foo", "vol1"))
+
+	// Counted faults are removed once exhausted.
+	assert.EqualError(t, Inject(PointStorageVolumeCreate, StageStart, "foo", "vol1"), "Injected fault: disk full")
+	assert.Equal(t, 1, List()[0].Count)
+	assert.EqualError(t, Inject(PointStorageVolumeCreate, StageStart, "foo", "vol1"), "Injected fault: disk full")
+	assert.NoError(t, Inject(PointStorageVolumeCreate, StageStart, "foo", "vol1"))
+	assert.Len(t, List(), 1)
+
+	// Faults without a project or target apply to all.
+	assert.EqualError(t, Inject(PointNetworkStart, StageStart, "default", "br0"), "Injected fault: no carrier")
+	assert.EqualError(t, Inject(PointNetworkStart, StageStart, "foo", "br1"), "Injected fault: no carrier")
+
+	Clear()
+	assert.NoError(t, Inject(PointNetworkStart, StageStart, "default", "br0"))
+
+	// Faults at the end of an operation.
+	require.NoError(t, Add(Fault{Point: PointMigrationReceive, Stage: StageEnd, Error: "connection reset"}))
+	assert.NoError(t, Inject(PointMigrationReceive, StageStart, "default", "c1"))
+	assert.EqualError(t, Inject(PointMigrationReceive, StageEnd, "default", "c1"), "Injected fault: connection reset")
+}
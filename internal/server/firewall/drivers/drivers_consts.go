@@ -49,3 +49,10 @@ type AddressForward struct {
 	ListenPorts   []uint64
 	TargetPorts   []uint64
 }
+
+// ServicePort represents a port a service of the daemon listens on, to allow through the host firewall.
+type ServicePort struct {
+	Protocol string // Either "tcp" or "udp".
+	Address  net.IP // Local address the service listens on. Any address if nil.
+	Port     uint64
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/subprocess"
 	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/validate"
@@ -576,25 +577,35 @@ func (d Nftables) InstanceClearProxyNAT(projectName string, instanceName string,
 // applyNftConfig loads the specified config template and then applies it to the common template before sending to
 // the nft command to be atomically applied to the system.
 func (d Nftables) applyNftConfig(tpl *template.Template, tplFields map[string]any) error {
+	config, err := d.renderNftConfig(tpl, tplFields)
+	if err != nil {
+		return err
+	}
+
+	err = subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(config), nil, "nft", "-f", "-")
+	if err != nil {
+		return fmt.Errorf("Failed apply nftables config: %w", err)
+	}
+
+	return nil
+}
+
+// renderNftConfig loads the specified config template and then applies it to the common template.
+func (d Nftables) renderNftConfig(tpl *template.Template, tplFields map[string]any) (string, error) {
 	// Load the specified template into the common template's parse tree under the nftableContentTemplate
 	// name so that the nftableContentTemplate template can use it with the generic name.
 	_, err := nftablesCommonTable.AddParseTree(nftablesContentTemplate, tpl.Tree)
 	if err != nil {
-		return fmt.Errorf("Failed loading %q template: %w", tpl.Name(), err)
+		return "", fmt.Errorf("Failed loading %q template: %w", tpl.Name(), err)
 	}
 
 	config := &strings.Builder{}
 	err = nftablesCommonTable.Execute(config, tplFields)
 	if err != nil {
-		return fmt.Errorf("Failed running %q template: %w", tpl.Name(), err)
+		return "", fmt.Errorf("Failed running %q template: %w", tpl.Name(), err)
 	}
 
-	err = subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
-	if err != nil {
-		return fmt.Errorf("Failed apply nftables config: %w", err)
-	}
-
-	return nil
+	return config.String(), nil
 }
 
 // removeChains removes the specified chains from the specified families.
@@ -1094,3 +1105,162 @@ func (d Nftables) NetworkApplyForwards(networkName string, rules []AddressForwar
 
 	return nil
 }
+
+// nftParseRules returns the rules found in the chains of the given nftables table listing or config.
+func (d Nftables) nftParseRules(family string, table string, config string, status string) []api.ClusterMemberManagedRule {
+	rules := []api.ClusterMemberManagedRule{}
+	chain := ""
+
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "chain ") && strings.HasSuffix(line, "{") {
+			chain = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "chain "), "{"))
+			continue
+		}
+
+		if line == "}" {
+			chain = ""
+			continue
+		}
+
+		// Skip anything outside of chains (sets, maps) and the chain definitions.
+		if chain == "" || line == "" || strings.HasPrefix(line, "type ") || strings.HasPrefix(line, "policy ") {
+			continue
+		}
+
+		rules = append(rules, api.ClusterMemberManagedRule{
+			Type:     d.String(),
+			Location: fmt.Sprintf("%s %s %s", family, table, chain),
+			Rule:     line,
+			Status:   status,
+		})
+	}
+
+	return rules
+}
+
+// ManagedRules returns the rules of the Incus tables.
+func (d Nftables) ManagedRules() ([]api.ClusterMemberManagedRule, error) {
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing nftables existing ruleset: %w", err)
+	}
+
+	rules := []api.ClusterMemberManagedRule{}
+	for _, item := range ruleset {
+		if item.ItemType != "table" || item.Name != nftablesNamespace {
+			continue
+		}
+
+		// Use -nn flags to avoid doing DNS lookups of IPs mentioned in any rules.
+		output, err := subprocess.RunCommandCLocale("nft", "-nn", "list", "table", item.Family, item.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing nftables table %q (%s): %w", item.Name, item.Family, err)
+		}
+
+		rules = append(rules, d.nftParseRules(item.Family, item.Name, output, api.ClusterMemberManagedRuleStatusApplied)...)
+	}
+
+	return rules, nil
+}
+
+// NetworkRequiredRules returns the rules NetworkSetup adds to allow the ICMP, DHCP and DNS access and the
+// forwarding enabled in opts, without applying them.
+func (d Nftables) NetworkRequiredRules(networkName string, opts Opts) ([]api.ClusterMemberManagedRule, error) {
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"networkName":    networkName,
+		"family":         "inet",
+	}
+
+	ipFamilies := []string{}
+	forwarding := false
+
+	if opts.FeaturesV4 != nil {
+		if opts.FeaturesV4.ICMPDHCPDNSAccess {
+			ipFamilies = append(ipFamilies, "ip")
+		}
+
+		if opts.FeaturesV4.ForwardingAllow {
+			tplFields["ip4Action"] = "accept"
+			forwarding = true
+		}
+	}
+
+	if opts.FeaturesV6 != nil {
+		if opts.FeaturesV6.ICMPDHCPDNSAccess {
+			ipFamilies = append(ipFamilies, "ip6")
+		}
+
+		if opts.FeaturesV6.ForwardingAllow {
+			tplFields["ip6Action"] = "accept"
+			forwarding = true
+		}
+	}
+
+	rules := []api.ClusterMemberManagedRule{}
+
+	if forwarding {
+		config, err := d.renderNftConfig(nftablesNetForwardingPolicy, tplFields)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, d.nftParseRules("inet", nftablesNamespace, config, api.ClusterMemberManagedRuleStatusRequired)...)
+	}
+
+	if len(ipFamilies) > 0 {
+		tplFields["ipFamilies"] = ipFamilies
+
+		config, err := d.renderNftConfig(nftablesNetICMPDHCPDNS, tplFields)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, d.nftParseRules("inet", nftablesNamespace, config, api.ClusterMemberManagedRuleStatusRequired)...)
+	}
+
+	return rules, nil
+}
+
+// nftablesServiceRules returns the rules allowing the given service ports.
+func (d Nftables) nftablesServiceRules(ports []ServicePort) []string {
+	rules := make([]string, 0, len(ports))
+	for _, port := range ports {
+		match := ""
+		if port.Address != nil {
+			if port.Address.To4() != nil {
+				match = fmt.Sprintf("ip daddr %s ", port.Address.String())
+			} else {
+				match = fmt.Sprintf("ip6 daddr %s ", port.Address.String())
+			}
+		}
+
+		rules = append(rules, fmt.Sprintf("%s%s dport %d accept", match, port.Protocol, port.Port))
+	}
+
+	return rules
+}
+
+// ServicesRequiredRules returns the rules the host firewall must allow for the given ports. They are never applied.
+func (d Nftables) ServicesRequiredRules(ports []ServicePort) ([]api.ClusterMemberManagedRule, error) {
+	if len(ports) == 0 {
+		return []api.ClusterMemberManagedRule{}, nil
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"family":         "inet",
+		"rules":          d.nftablesServiceRules(ports),
+	}
+
+	config, err := d.renderNftConfig(nftablesServices, tplFields)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.nftParseRules("inet", nftablesNamespace, config, api.ClusterMemberManagedRuleStatusRequired), nil
+}
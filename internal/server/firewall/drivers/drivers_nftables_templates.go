@@ -76,6 +76,16 @@ chain out{{.chainSeparator}}{{.networkName}} {
 }
 `))
 
+var nftablesServices = template.Must(template.New("nftablesServices").Parse(`
+chain svcin {
+	type filter hook input priority 0; policy accept;
+
+	{{- range .rules}}
+	{{.}}
+	{{- end}}
+}
+`))
+
 var nftablesNetProxyNAT = template.Must(template.New("nftablesNetProxyNAT").Parse(`
 add table {{.family}} {{.namespace}}
 add chain {{.family}} {{.namespace}} {{.chainPrefix}}prert{{.chainSeparator}}{{.label}} {type nat hook prerouting priority -100; policy accept;}
@@ -0,0 +1,56 @@
+package drivers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNftablesServiceRules(t *testing.T) {
+	ports := []ServicePort{
+		{Protocol: "tcp", Port: 8443},
+		{Protocol: "tcp", Address: net.ParseIP("10.0.0.1"), Port: 9100},
+		{Protocol: "udp", Address: net.ParseIP("fd00::1"), Port: 53},
+	}
+
+	assert.Equal(t, []string{
+		"tcp dport 8443 accept",
+		"ip daddr 10.0.0.1 tcp dport 9100 accept",
+		"ip6 daddr fd00::1 udp dport 53 accept",
+	}, Nftables{}.nftablesServiceRules(ports))
+}
+
+func TestNftablesServicesRequiredRules(t *testing.T) {
+	rules, err := Nftables{}.ServicesRequiredRules([]ServicePort{{Protocol: "tcp", Port: 8443}})
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, "inet incus svcin", rules[0].Location)
+	assert.Equal(t, "tcp dport 8443 accept", rules[0].Rule)
+
+	rules, err = Nftables{}.ServicesRequiredRules(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestXtablesServicesRules(t *testing.T) {
+	ports := []ServicePort{
+		{Protocol: "tcp", Port: 8443},
+		{Protocol: "tcp", Address: net.ParseIP("10.0.0.1"), Port: 9100},
+		{Protocol: "udp", Address: net.ParseIP("fd00::1"), Port: 53},
+	}
+
+	assert.Equal(t, [][]string{
+		{"4", "", "filter", "INPUT", "-p", "tcp", "--dport", "8443", "-j", "ACCEPT"},
+		{"6", "", "filter", "INPUT", "-p", "tcp", "--dport", "8443", "-j", "ACCEPT"},
+		{"4", "", "filter", "INPUT", "-d", "10.0.0.1", "-p", "tcp", "--dport", "9100", "-j", "ACCEPT"},
+		{"6", "", "filter", "INPUT", "-d", "fd00::1", "-p", "udp", "--dport", "53", "-j", "ACCEPT"},
+	}, Xtables{}.servicesRules(ports))
+
+	rules, err := Xtables{}.ServicesRequiredRules(ports[1:2])
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, "iptables", rules[0].Type)
+	assert.Equal(t, "filter INPUT", rules[0].Location)
+	assert.Equal(t, "-d 10.0.0.1 -p tcp --dport 9100 -j ACCEPT", rules[0].Rule)
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/subprocess"
 	"github.com/lxc/incus/v6/shared/util"
@@ -380,8 +381,8 @@ func (d Xtables) networkSetupOutboundNAT(networkName string, subnet *net.IPNet,
 	return nil
 }
 
-// networkSetupICMPDHCPDNSAccess sets up basic iptables overrides for ICMP, DHCP and DNS.
-func (d Xtables) networkSetupICMPDHCPDNSAccess(networkName string, ipVersion uint) error {
+// networkICMPDHCPDNSRules returns the basic iptables overrides for ICMP, DHCP and DNS.
+func (d Xtables) networkICMPDHCPDNSRules(networkName string, ipVersion uint) ([][]string, error) {
 	var rules [][]string
 	if ipVersion == 4 {
 		rules = [][]string{
@@ -416,7 +417,17 @@ func (d Xtables) networkSetupICMPDHCPDNSAccess(networkName string, ipVersion uin
 			rules = append(rules, []string{"6", networkName, "filter", "OUTPUT", "-o", networkName, "-p", "icmpv6", "-m", "icmp6", "--icmpv6-type", fmt.Sprintf("%d", icmpType), "-j", "ACCEPT"})
 		}
 	} else {
-		return fmt.Errorf("Invalid IP version")
+		return nil, fmt.Errorf("Invalid IP version")
+	}
+
+	return rules, nil
+}
+
+// networkSetupICMPDHCPDNSAccess sets up basic iptables overrides for ICMP, DHCP and DNS.
+func (d Xtables) networkSetupICMPDHCPDNSAccess(networkName string, ipVersion uint) error {
+	rules, err := d.networkICMPDHCPDNSRules(networkName, ipVersion)
+	if err != nil {
+		return err
 	}
 
 	comment := d.networkIPTablesComment(networkName)
@@ -1650,3 +1661,142 @@ func (d Xtables) NetworkApplyForwards(networkName string, rules []AddressForward
 	reverter.Success()
 	return nil
 }
+
+// ManagedRules returns the iptables rules added by Incus. Rules added to ebtables aren't included.
+func (d Xtables) ManagedRules() ([]api.ClusterMemberManagedRule, error) {
+	rules := []api.ClusterMemberManagedRule{}
+
+	for _, cmd := range []string{"iptables", "ip6tables"} {
+		// Detect kernels that lack IPv6 support.
+		if cmd == "ip6tables" && !util.PathExists("/proc/sys/net/ipv6") {
+			continue
+		}
+
+		_, err := exec.LookPath(cmd)
+		if err != nil {
+			continue
+		}
+
+		for _, table := range []string{"filter", "nat", "mangle", "raw"} {
+			output, err := subprocess.TryRunCommand(cmd, "-w", "-t", table, "-S")
+			if err != nil {
+				return nil, fmt.Errorf("Failed listing %s rules (table %s): %w", cmd, table, err)
+			}
+
+			for _, line := range strings.Split(output, "\n") {
+				fields := strings.Fields(line)
+				if len(fields) < 3 || fields[0] != "-A" {
+					continue
+				}
+
+				chain := fields[1]
+				if !strings.Contains(line, iptablesCommentPrefix) && !strings.HasPrefix(chain, iptablesChainNICFilterPrefix) && !strings.HasPrefix(chain, iptablesChainACLFilterPrefix) {
+					continue
+				}
+
+				rules = append(rules, api.ClusterMemberManagedRule{
+					Type:     cmd,
+					Location: fmt.Sprintf("%s %s", table, chain),
+					Rule:     strings.Join(fields[2:], " "),
+					Status:   api.ClusterMemberManagedRuleStatusApplied,
+				})
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// NetworkRequiredRules returns the rules NetworkSetup adds to allow the ICMP, DHCP and DNS access and the
+// forwarding enabled in opts, without applying them.
+func (d Xtables) NetworkRequiredRules(networkName string, opts Opts) ([]api.ClusterMemberManagedRule, error) {
+	rules := []api.ClusterMemberManagedRule{}
+
+	for _, ipVersion := range []uint{4, 6} {
+		cmd := "iptables"
+		features := opts.FeaturesV4
+		if ipVersion == 6 {
+			cmd = "ip6tables"
+			features = opts.FeaturesV6
+		}
+
+		if features == nil {
+			continue
+		}
+
+		var ruleArgs [][]string
+
+		if features.ICMPDHCPDNSAccess {
+			accessRules, err := d.networkICMPDHCPDNSRules(networkName, ipVersion)
+			if err != nil {
+				return nil, err
+			}
+
+			ruleArgs = append(ruleArgs, accessRules...)
+		}
+
+		if features.ForwardingAllow {
+			ruleArgs = append(ruleArgs,
+				[]string{"", networkName, "filter", "FORWARD", "-i", networkName, "-j", "ACCEPT"},
+				[]string{"", networkName, "filter", "FORWARD", "-o", networkName, "-j", "ACCEPT"},
+			)
+		}
+
+		for _, rule := range ruleArgs {
+			rules = append(rules, api.ClusterMemberManagedRule{
+				Type:     cmd,
+				Location: fmt.Sprintf("%s %s", rule[2], rule[3]),
+				Rule:     strings.Join(rule[4:], " "),
+				Status:   api.ClusterMemberManagedRuleStatusRequired,
+			})
+		}
+	}
+
+	return rules, nil
+}
+
+// servicesRules returns the iptables rules allowing the given service ports.
+func (d Xtables) servicesRules(ports []ServicePort) [][]string {
+	rules := [][]string{}
+	for _, port := range ports {
+		ipVersions := []string{"4", "6"}
+		args := []string{}
+
+		if port.Address != nil {
+			ipVersions = []string{"6"}
+			if port.Address.To4() != nil {
+				ipVersions = []string{"4"}
+			}
+
+			args = append(args, "-d", port.Address.String())
+		}
+
+		args = append(args, "-p", port.Protocol, "--dport", fmt.Sprintf("%d", port.Port), "-j", "ACCEPT")
+
+		for _, ipVersion := range ipVersions {
+			rules = append(rules, append([]string{ipVersion, "", "filter", "INPUT"}, args...))
+		}
+	}
+
+	return rules
+}
+
+// ServicesRequiredRules returns the rules the host firewall must allow for the given ports. They are never applied.
+func (d Xtables) ServicesRequiredRules(ports []ServicePort) ([]api.ClusterMemberManagedRule, error) {
+	rules := []api.ClusterMemberManagedRule{}
+	for _, rule := range d.servicesRules(ports) {
+		cmd := "iptables"
+		if rule[0] == "6" {
+			cmd = "ip6tables"
+		}
+
+		rules = append(rules, api.ClusterMemberManagedRule{
+			Type:     cmd,
+			Location: fmt.Sprintf("%s %s", rule[2], rule[3]),
+			Rule:     strings.Join(rule[4:], " "),
+			Status:   api.ClusterMemberManagedRuleStatusRequired,
+		})
+	}
+
+	return rules, nil
+}
@@ -4,6 +4,7 @@ import (
 	"net"
 
 	"github.com/lxc/incus/v6/internal/server/firewall/drivers"
+	"github.com/lxc/incus/v6/shared/api"
 )
 
 // Firewall represents an Incus firewall.
@@ -15,6 +16,7 @@ type Firewall interface {
 	NetworkClear(networkName string, delete bool, ipVersions []uint) error
 	NetworkApplyACLRules(networkName string, rules []drivers.ACLRule) error
 	NetworkApplyForwards(networkName string, rules []drivers.AddressForward) error
+	NetworkRequiredRules(networkName string, opts drivers.Opts) ([]api.ClusterMemberManagedRule, error)
 
 	InstanceSetupBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet, parentManaged bool) error
 	InstanceClearBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet) error
@@ -27,4 +29,8 @@ type Firewall interface {
 
 	InstanceSetupNetPrio(projectName string, instanceName string, deviceName string, netPrio uint32) error
 	InstanceClearNetPrio(projectName string, instanceName string, deviceName string) error
+
+	ServicesRequiredRules(ports []drivers.ServicePort) ([]api.ClusterMemberManagedRule, error)
+
+	ManagedRules() ([]api.ClusterMemberManagedRule, error)
 }
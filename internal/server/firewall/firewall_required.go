@@ -0,0 +1,47 @@
+package firewall
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+var requiredRulesMu sync.Mutex
+
+// requiredRules holds the rules that the administrator must allow, indexed by the entity needing them.
+var requiredRules = map[string][]api.ClusterMemberManagedRule{}
+
+// SetRequiredRules records the rules that weren't applied because of strict mode and that the administrator
+// must allow for the given entity (for example "network/incusbr0"). No rules clears the entity.
+func SetRequiredRules(entity string, rules []api.ClusterMemberManagedRule) {
+	requiredRulesMu.Lock()
+	defer requiredRulesMu.Unlock()
+
+	if len(rules) == 0 {
+		delete(requiredRules, entity)
+		return
+	}
+
+	requiredRules[entity] = rules
+}
+
+// RequiredRules returns the rules that the administrator must allow, sorted by entity.
+func RequiredRules() []api.ClusterMemberManagedRule {
+	requiredRulesMu.Lock()
+	defer requiredRulesMu.Unlock()
+
+	entities := make([]string, 0, len(requiredRules))
+	for entity := range requiredRules {
+		entities = append(entities, entity)
+	}
+
+	slices.Sort(entities)
+
+	rules := []api.ClusterMemberManagedRule{}
+	for _, entity := range entities {
+		rules = append(rules, requiredRules[entity]...)
+	}
+
+	return rules
+}
@@ -0,0 +1,285 @@
+// Package hugepages manages the kernel huge page pools used by the virtual machines.
+package hugepages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+var sysKernelMMHugepages = "/sys/kernel/mm/hugepages"
+var sysDevicesNode = "/sys/devices/system/node"
+
+// pool identifies a kernel huge page pool.
+type pool struct {
+	size uint64
+
+	// NUMA node of the pool, -1 for the global pool.
+	node int64
+}
+
+func (p pool) path() string {
+	name := fmt.Sprintf("hugepages-%dkB", p.size/1024)
+	if p.node < 0 {
+		return filepath.Join(sysKernelMMHugepages, name)
+	}
+
+	return filepath.Join(sysDevicesNode, fmt.Sprintf("node%d", p.node), "hugepages", name)
+}
+
+func (p pool) String() string {
+	size := units.GetByteSizeStringIEC(int64(p.size), 0)
+	if p.node < 0 {
+		return size
+	}
+
+	return fmt.Sprintf("%s on NUMA node %d", size, p.node)
+}
+
+func (p pool) read(file string) (uint64, error) {
+	content, err := os.ReadFile(filepath.Join(p.path(), file))
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+func (p pool) setTotal(total uint64) error {
+	return os.WriteFile(filepath.Join(p.path(), "nr_hugepages"), []byte(fmt.Sprintf("%d", total)), 0o644)
+}
+
+// available returns the number of free huge pages of the pool which aren't reserved yet, either by the kernel for
+// the mappings of running processes or by a Reserve whose pages aren't mapped yet.
+func (p pool) available() (uint64, error) {
+	free, err := p.read("free_hugepages")
+	if err != nil {
+		return 0, err
+	}
+
+	// Older kernels don't report the reserved pages of the NUMA node pools.
+	reserved, err := p.read("resv_hugepages")
+	if err != nil {
+		reserved = 0
+	}
+
+	reserved += pending[p]
+	if free < reserved {
+		return 0, nil
+	}
+
+	return free - reserved, nil
+}
+
+// Allocation records the huge pages added to the kernel pools by Reserve.
+type Allocation struct {
+	// Size of the huge pages (bytes).
+	Size uint64 `json:"size"`
+
+	// Number of pages added to the pool of each NUMA node, -1 for the global pool.
+	Pages map[int64]uint64 `json:"pages"`
+
+	// Number of pages reserved in the pool of each NUMA node until Commit is called.
+	reserved map[int64]uint64
+}
+
+var mu sync.Mutex
+
+// pending holds the pages reserved by Reserve which aren't mapped by their process yet.
+var pending = map[pool]uint64{}
+
+// poolPages returns the pools to take the given amount of memory from, along with the number of pages needed in each.
+func poolPages(size uint64, memory uint64, nodes []uint64) ([]pool, uint64) {
+	if len(nodes) == 0 {
+		return []pool{{size: size, node: -1}}, (memory + size - 1) / size
+	}
+
+	pools := make([]pool, 0, len(nodes))
+	for _, node := range nodes {
+		pools = append(pools, pool{size: size, node: int64(node)})
+	}
+
+	perNode := memory / uint64(len(nodes))
+
+	return pools, (perNode + size - 1) / size
+}
+
+// Fits returns whether enough free huge pages of the given size are available on the given NUMA node to back the given
+// amount of memory, without growing the pool.
+func Fits(size uint64, memory uint64, node uint64) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	pools, pages := poolPages(size, memory, []uint64{node})
+
+	available, err := pools[0].available()
+	if err != nil {
+		return false
+	}
+
+	return available >= pages
+}
+
+// Reserve makes sure that enough free huge pages of the given size are available to back the given amount of memory.
+// The memory is spread evenly over the given NUMA nodes, or taken from any node if none is given.
+// When dynamic is true, the kernel pools are grown as needed and the pages added are returned so they can be released later.
+// An error is returned if the pools don't have enough free pages and can't be grown.
+// The pages stay reserved for the caller until Commit is called once its process mapped them, or until Release.
+func Reserve(size uint64, memory uint64, nodes []uint64, dynamic bool) (*Allocation, error) {
+	if size == 0 {
+		return nil, fmt.Errorf("Invalid huge page size")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	pools, pages := poolPages(size, memory, nodes)
+
+	allocation := &Allocation{Size: size, Pages: map[int64]uint64{}, reserved: map[int64]uint64{}}
+	fail := func(err error) (*Allocation, error) {
+		_ = release(allocation)
+		return nil, err
+	}
+
+	for _, p := range pools {
+		free, err := p.available()
+		if err != nil {
+			return fail(fmt.Errorf("Huge pages of %s aren't supported: %w", p, err))
+		}
+
+		if free >= pages {
+			pending[p] += pages
+			allocation.reserved[p.node] = pages
+			continue
+		}
+
+		if !dynamic {
+			return fail(fmt.Errorf("Not enough free huge pages of %s (%d needed, %d free)", p, pages, free))
+		}
+
+		total, err := p.read("nr_hugepages")
+		if err != nil {
+			return fail(err)
+		}
+
+		missing := pages - free
+		err = p.setTotal(total + missing)
+		if err != nil {
+			return fail(fmt.Errorf("Failed growing the huge pages pool of %s: %w", p, err))
+		}
+
+		// The kernel allocates as many pages as it can, check that it got all of them.
+		newTotal, err := p.read("nr_hugepages")
+		if err != nil {
+			return fail(err)
+		}
+
+		if newTotal > total {
+			allocation.Pages[p.node] = newTotal - total
+			logger.Debug("Allocated huge pages", logger.Ctx{"pool": p.String(), "count": newTotal - total})
+		}
+
+		if newTotal < total+missing {
+			return fail(fmt.Errorf("Not enough memory to allocate huge pages of %s (%d needed, %d allocated)", p, missing, newTotal-total))
+		}
+
+		pending[p] += pages
+		allocation.reserved[p.node] = pages
+	}
+
+	return allocation, nil
+}
+
+// Commit gives up the reservation of the huge pages of an allocation, once they are mapped by the process using
+// them and accounted for by the kernel.
+func Commit(allocation *Allocation) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	commit(allocation)
+}
+
+func commit(allocation *Allocation) {
+	for node, count := range allocation.reserved {
+		p := pool{size: allocation.Size, node: node}
+
+		pending[p] -= min(count, pending[p])
+		if pending[p] == 0 {
+			delete(pending, p)
+		}
+	}
+
+	allocation.reserved = nil
+}
+
+// Release gives up the reservation of the huge pages of an allocation and shrinks the kernel pools by the huge pages
+// added by Reserve.
+func Release(allocation *Allocation) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return release(allocation)
+}
+
+func release(allocation *Allocation) error {
+	commit(allocation)
+
+	var errs []string
+	for node, count := range allocation.Pages {
+		p := pool{size: allocation.Size, node: node}
+
+		total, err := p.read("nr_hugepages")
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		if count > total {
+			count = total
+		}
+
+		// Pages still in use become surplus pages, which the kernel releases once they are freed.
+		err = p.setTotal(total - count)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		logger.Debug("Released huge pages", logger.Ctx{"pool": p.String(), "count": count})
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Failed releasing huge pages: %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// ResourcesFit returns whether the memory resources reported by a server have enough free huge pages of the given size
+// to back the given amount of memory, a size of 0 matching huge pages of any size.
+// When dynamic is true, the free memory the kernel pools can be grown with is accounted for too.
+func ResourcesFit(res *api.ResourcesMemory, size uint64, memory uint64, dynamic bool) bool {
+	var freeMemory uint64
+	if dynamic && res.Total > res.Used {
+		freeMemory = res.Total - res.Used
+	}
+
+	for _, hugepages := range res.Hugepages {
+		if hugepages.Size == 0 || (size != 0 && hugepages.Size != size) {
+			continue
+		}
+
+		if hugepages.Free*hugepages.Size+freeMemory >= memory {
+			return true
+		}
+	}
+
+	return false
+}
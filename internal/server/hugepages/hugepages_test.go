@@ -0,0 +1,130 @@
+package hugepages
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestReserve(t *testing.T) {
+	oldGlobal := sysKernelMMHugepages
+	oldNode := sysDevicesNode
+	defer func() {
+		sysKernelMMHugepages = oldGlobal
+		sysDevicesNode = oldNode
+	}()
+
+	root := t.TempDir()
+	sysKernelMMHugepages = filepath.Join(root, "hugepages")
+	sysDevicesNode = filepath.Join(root, "node")
+
+	const size = 2 * 1024 * 1024
+
+	write := func(p pool, file string, value uint64) {
+		require.NoError(t, os.MkdirAll(p.path(), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(p.path(), file), []byte(strconv.FormatUint(value, 10)+"\n"), 0o644))
+	}
+
+	read := func(p pool, file string) uint64 {
+		content, err := os.ReadFile(filepath.Join(p.path(), file))
+		require.NoError(t, err)
+
+		value, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+		require.NoError(t, err)
+
+		return value
+	}
+
+	global := pool{size: size, node: -1}
+	write(global, "nr_hugepages", 512)
+	write(global, "free_hugepages", 512)
+	write(global, "resv_hugepages", 256)
+
+	// The pages reserved by the kernel for the running processes aren't available.
+	_, err := Reserve(size, 1024*size, nil, false)
+	require.Error(t, err)
+
+	// The pages reserved until committed aren't available to other reservations either.
+	allocation, err := Reserve(size, 200*size, nil, false)
+	require.NoError(t, err)
+	require.Empty(t, allocation.Pages)
+
+	_, err = Reserve(size, 100*size, nil, false)
+	require.Error(t, err)
+
+	// Once mapped, the pages are accounted for by the kernel instead.
+	write(global, "resv_hugepages", 456)
+	Commit(allocation)
+	require.Empty(t, pending)
+
+	_, err = Reserve(size, 100*size, nil, false)
+	require.Error(t, err)
+
+	// Releasing a reservation which failed to start gives its pages back.
+	allocation, err = Reserve(size, 50*size, nil, false)
+	require.NoError(t, err)
+	require.NoError(t, Release(allocation))
+	require.Empty(t, pending)
+
+	// The pools of the NUMA nodes are grown as needed when dynamic, and shrunk again on release.
+	node0 := pool{size: size, node: 0}
+	node1 := pool{size: size, node: 1}
+	write(node0, "nr_hugepages", 10)
+	write(node0, "free_hugepages", 10)
+	write(node1, "nr_hugepages", 0)
+	write(node1, "free_hugepages", 0)
+
+	require.True(t, Fits(size, 10*size, 0))
+	require.False(t, Fits(size, 10*size, 1))
+	require.False(t, Fits(size, 10*size, 2))
+
+	_, err = Reserve(size, 20*size, []uint64{0, 1}, false)
+	require.Error(t, err)
+
+	allocation, err = Reserve(size, 20*size, []uint64{0, 1}, true)
+	require.NoError(t, err)
+	require.Equal(t, map[int64]uint64{1: 10}, allocation.Pages)
+	require.Equal(t, uint64(10), read(node1, "nr_hugepages"))
+
+	require.False(t, Fits(size, 2*size, 0))
+
+	require.NoError(t, Release(allocation))
+	require.Equal(t, uint64(0), read(node1, "nr_hugepages"))
+	require.True(t, Fits(size, 10*size, 0))
+}
+
+func TestResourcesFit(t *testing.T) {
+	const size2M = 2 * 1024 * 1024
+	const size1G = 1024 * 1024 * 1024
+
+	res := &api.ResourcesMemory{
+		Hugepages: []api.ResourcesMemoryHugepages{
+			{Size: size2M, Total: 1024, Free: 512},
+			{Size: size1G, Total: 2, Free: 0},
+		},
+		Used:  4 * size1G,
+		Total: 8 * size1G,
+	}
+
+	// The free pages of the requested size back the memory.
+	require.True(t, ResourcesFit(res, size2M, 512*size2M, false))
+	require.False(t, ResourcesFit(res, size2M, 513*size2M, false))
+	require.False(t, ResourcesFit(res, size1G, size1G, false))
+
+	// Any size is used without an explicit page size.
+	require.True(t, ResourcesFit(res, 0, size1G, false))
+	require.False(t, ResourcesFit(res, 0, 2*size1G, false))
+
+	// The pools can be grown with the free memory when dynamic.
+	require.True(t, ResourcesFit(res, size1G, 4*size1G, true))
+	require.False(t, ResourcesFit(res, size1G, 5*size1G, true))
+
+	// Servers without huge pages never fit.
+	require.False(t, ResourcesFit(&api.ResourcesMemory{Total: 8 * size1G}, 0, size2M, true))
+}
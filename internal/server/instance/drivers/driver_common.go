@@ -16,8 +16,11 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/limitcheck"
 	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/backup"
 	"github.com/lxc/incus/v6/internal/server/db"
@@ -30,11 +33,13 @@ import (
 	"github.com/lxc/incus/v6/internal/server/instance/operationlock"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
 	"github.com/lxc/incus/v6/internal/server/locking"
+	"github.com/lxc/incus/v6/internal/server/network"
 	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/resources"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/internal/server/tracing"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
@@ -205,6 +210,15 @@ func (d *common) IsStateful() bool {
 	return d.stateful
 }
 
+// snapshotType returns the API type of the snapshot.
+func (d *common) snapshotType() string {
+	if d.stateful {
+		return api.InstanceSnapshotTypeCheckpoint
+	}
+
+	return api.InstanceSnapshotTypeDisk
+}
+
 // Operation returns the instance's current operation.
 func (d *common) Operation() *operations.Operation {
 	return d.op
@@ -262,6 +276,13 @@ func (d *common) SetOperation(op *operations.Operation) {
 	d.op = op
 }
 
+// traceStart starts the span of an action on the instance, child of the span of the current operation if any.
+func (d *common) traceStart(name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(tracing.InstanceAttributes(d.project.Name, d.name), attrs...)
+
+	return tracing.Start(d.op.TraceContext(), name, attrs...)
+}
+
 // Snapshots returns a list of snapshots.
 func (d *common) Snapshots() ([]instance.Instance, error) {
 	if d.isSnapshot {
@@ -347,6 +368,9 @@ func (d *common) VolatileSet(changes map[string]string) error {
 			err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 				return tx.UpdateInstanceSnapshotConfig(d.id, changes)
 			})
+		} else if d.state.Edge.Disconnected() {
+			// Queue the changes until the edge member can reach the cluster database again.
+			err = d.state.Edge.Queue(d.project.Name, d.name, changes)
 		} else {
 			err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 				return tx.UpdateInstanceConfig(d.id, changes)
@@ -384,8 +408,7 @@ func (d *common) ConsoleBufferLogPath() string {
 
 // DevicesPath returns the instance's devices path.
 func (d *common) DevicesPath() string {
-	name := project.Instance(d.project.Name, d.name)
-	return internalUtil.VarPath("devices", name)
+	return internalUtil.VarPath("devices", d.runtimeName())
 }
 
 // LogPath returns the instance's log path.
@@ -417,8 +440,18 @@ func (d *common) RootfsPath() string {
 
 // ShmountsPath returns the instance's shared mounts path.
 func (d *common) ShmountsPath() string {
-	name := project.Instance(d.project.Name, d.name)
-	return internalUtil.VarPath("shmounts", name)
+	return internalUtil.VarPath("shmounts", d.runtimeName())
+}
+
+// runtimeName returns the project prefixed name the instance is running under.
+// This is its previous name until it stops when it was renamed while running.
+func (d *common) runtimeName() string {
+	name := d.localConfig["volatile.runtime.name"]
+	if name == "" {
+		name = d.name
+	}
+
+	return project.Instance(d.project.Name, name)
 }
 
 // StatePath returns the instance's state path.
@@ -618,9 +651,9 @@ func (d *common) restartCommon(inst instance.Instance, timeout time.Duration) er
 func (d *common) rebuildCommon(inst instance.Instance, img *api.Image, op *operations.Operation) error {
 	instLocalConfig := d.localConfig
 
-	// Reset the "image.*" keys.
+	// Reset the "image.*" keys (except for the rebase configuration).
 	for k := range instLocalConfig {
-		if strings.HasPrefix(k, "image.") {
+		if strings.HasPrefix(k, "image.") && !strings.HasPrefix(k, "image.auto_rebase") {
 			delete(instLocalConfig, k)
 		}
 	}
@@ -739,6 +772,15 @@ func (d *common) snapshotCommon(inst instance.Instance, name string, expiry time
 
 	revert.Add(func() { _ = snap.Delete(true) })
 
+	// Record the new snapshot as the one the instance now derives from.
+	oldParent := inst.LocalConfig()["volatile.snapshot.parent"]
+	err = inst.VolatileSet(map[string]string{"volatile.snapshot.parent": name})
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _ = inst.VolatileSet(map[string]string{"volatile.snapshot.parent": oldParent}) })
+
 	// Mount volume for backup.yaml writing.
 	_, err = pool.MountInstance(inst, d.op)
 	if err != nil {
@@ -757,6 +799,40 @@ func (d *common) snapshotCommon(inst instance.Instance, name string, expiry time
 	return nil
 }
 
+// snapshotReparent makes the instance and snapshots deriving from the oldParent snapshot derive from
+// newParent instead.
+func (d *common) snapshotReparent(oldParent string, newParent string) error {
+	parentName, _, _ := api.GetParentAndSnapshotName(d.name)
+
+	parent, err := instance.LoadByProjectAndName(d.state, d.project.Name, parentName)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := parent.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	return snapshotReparentInstances(append(snapshots, parent), oldParent, newParent)
+}
+
+// snapshotReparentInstances makes those of the instances deriving from the oldParent snapshot derive from newParent.
+func snapshotReparentInstances(instances []instance.Instance, oldParent string, newParent string) error {
+	for _, inst := range instances {
+		if inst.LocalConfig()["volatile.snapshot.parent"] != oldParent {
+			continue
+		}
+
+		err := inst.VolatileSet(map[string]string{"volatile.snapshot.parent": newParent})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // updateProgress updates the operation metadata with a new progress string.
 func (d *common) updateProgress(progress string) {
 	if d.op == nil {
@@ -807,6 +883,17 @@ func (d *common) isRunningStatusCode(statusCode api.StatusCode) bool {
 	return statusCode != api.Error && statusCode != api.Stopped
 }
 
+// renderStatusCode returns the status code to report for the instance.
+// Instances affected by a storage pool failure are reported in error, even once stopped, until the storage pool
+// recovers.
+func (d *common) renderStatusCode(statusCode api.StatusCode) api.StatusCode {
+	if d.localConfig["volatile.storage.failure"] != "" {
+		return api.Error
+	}
+
+	return statusCode
+}
+
 // isStartableStatusCode returns an error if the status code means the instance cannot be started currently.
 func (d *common) isStartableStatusCode(statusCode api.StatusCode) error {
 	if d.isRunningStatusCode(statusCode) {
@@ -874,9 +961,39 @@ func (d *common) validateStartup(stateful bool, statusCode api.StatusCode) error
 		return err
 	}
 
+	// Validate the CPU pinning against the CPUs reserved for the host, which may have changed since configured.
+	err = d.validateReservedCPUs()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// reservedCPUs returns the IDs of the CPU threads reserved for the host through limits.reserve.cpu.
+func (d *common) reservedCPUs() ([]int64, error) {
+	if d.state.LocalConfig == nil {
+		return nil, nil
+	}
+
+	reservedCPUs, _ := d.state.LocalConfig.LimitsReserve()
+	if reservedCPUs == "" {
+		return nil, nil
+	}
+
+	return resources.ParseCpuset(reservedCPUs)
+}
+
+// validateReservedCPUs checks that the instance isn't pinned to any of the CPUs reserved for the host.
+func (d *common) validateReservedCPUs() error {
+	reserved, err := d.reservedCPUs()
+	if err != nil {
+		return err
+	}
+
+	return resources.ValidateReservedCPUs(d.expandedConfig["limits.cpu"], reserved)
+}
+
 // onStopOperationSetup creates or picks up the relevant operation. This is used in the stopns and stop hooks to
 // ensure that a lock on their activities is held before the instance process is stopped. This prevents a start
 // request run at the same time from overlapping with the stop process.
@@ -1164,6 +1281,45 @@ func (d *common) deviceLoad(inst instance.Instance, deviceName string, rawConfig
 	return dev, err
 }
 
+// limitString returns the string representation of a limit read from the host, -1 meaning unlimited.
+func limitString(value int64) string {
+	if value < 0 {
+		return "unlimited"
+	}
+
+	return fmt.Sprintf("%d", value)
+}
+
+// verifyDeviceLimits checks the limits of the devices which support it are applied on the host.
+func (d *common) verifyDeviceLimits(inst instance.Instance) ([]limitcheck.Discrepancy, error) {
+	var discrepancies []limitcheck.Discrepancy
+
+	for _, entry := range d.expandedDevices.Sorted() {
+		dev, err := d.deviceLoad(inst, entry.Name, entry.Config)
+		if err != nil {
+			if errors.Is(err, device.ErrUnsupportedDevType) {
+				continue
+			}
+
+			return nil, fmt.Errorf("Failed loading device %q: %w", entry.Name, err)
+		}
+
+		verifier, ok := dev.(device.LimitsVerifier)
+		if !ok {
+			continue
+		}
+
+		result, err := verifier.VerifyLimits()
+		if err != nil {
+			return nil, fmt.Errorf("Failed verifying limits of device %q: %w", entry.Name, err)
+		}
+
+		discrepancies = append(discrepancies, result...)
+	}
+
+	return discrepancies, nil
+}
+
 // deviceAdd loads a new device and calls its Add() function.
 func (d *common) deviceAdd(dev device.Device, instanceRunning bool) error {
 	l := d.logger.AddContext(logger.Ctx{"device": dev.Name(), "type": dev.Config()["type"]})
@@ -1250,6 +1406,38 @@ func (d *common) devicesRegister(inst instance.Instance) {
 	}
 }
 
+// devicesRename lets the devices of the instance move the host-side state they keep under its previous name.
+func (d *common) devicesRename(inst instance.Instance, oldName string, instanceRunning bool) error {
+	for _, entry := range d.expandedDevices.Sorted() {
+		dev, err := d.deviceLoad(inst, entry.Name, entry.Config)
+		if err != nil {
+			if errors.Is(err, device.ErrUnsupportedDevType) {
+				continue
+			}
+
+			// Don't prevent renaming a stopped instance whose devices can't currently be validated.
+			if !instanceRunning {
+				d.logger.Warn("Failed loading device for rename", logger.Ctx{"err": err, "device": entry.Name})
+				continue
+			}
+
+			return fmt.Errorf("Failed loading device %q: %w", entry.Name, err)
+		}
+
+		renamer, ok := dev.(device.InstanceRenamer)
+		if !ok {
+			continue
+		}
+
+		err = renamer.InstanceRename(oldName, instanceRunning)
+		if err != nil {
+			return fmt.Errorf("Failed renaming device %q: %w", entry.Name, err)
+		}
+	}
+
+	return nil
+}
+
 // devicesUpdate applies device changes to an instance.
 func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfig.Devices, addDevices deviceConfig.Devices, updateDevices deviceConfig.Devices, oldExpandedDevices deviceConfig.Devices, instanceRunning bool, userRequested bool) error {
 	revert := revert.New()
@@ -1449,7 +1637,8 @@ func (d *common) deleteSnapshots(deleteFunc func(snapInst instance.Instance) err
 }
 
 // setNUMANode looks at all other instances and picks the least used NUMA node.
-func (d *common) setNUMANode() error {
+// When fits is set, the NUMA nodes it rejects are only picked if it rejects all of them.
+func (d *common) setNUMANode(fits func(node uint64) bool) error {
 	muNUMA.Lock()
 	defer muNUMA.Unlock()
 
@@ -1459,11 +1648,21 @@ func (d *common) setNUMANode() error {
 		return err
 	}
 
+	// Keep the instances off the NUMA nodes whose CPUs are all reserved for the host.
+	reserved, err := d.reservedCPUs()
+	if err != nil {
+		return err
+	}
+
 	// Get a list of NUMA nodes.
 	nodes := []uint64{}
 	for _, cpuSocket := range cpu.Sockets {
 		for _, cpuCore := range cpuSocket.Cores {
 			for _, cpuThread := range cpuCore.Threads {
+				if slices.Contains(reserved, cpuThread.ID) {
+					continue
+				}
+
 				if !slices.Contains(nodes, cpuThread.NUMANode) {
 					nodes = append(nodes, cpuThread.NUMANode)
 				}
@@ -1471,6 +1670,23 @@ func (d *common) setNUMANode() error {
 		}
 	}
 
+	if len(nodes) == 0 {
+		return fmt.Errorf("No NUMA node with CPUs not reserved for the host")
+	}
+
+	if fits != nil {
+		candidates := []uint64{}
+		for _, node := range nodes {
+			if fits(node) {
+				candidates = append(candidates, node)
+			}
+		}
+
+		if len(candidates) > 0 {
+			nodes = candidates
+		}
+	}
+
 	// Shortcut on single-node systems.
 	if len(nodes) == 1 {
 		return d.VolatileSet(map[string]string{"volatile.cpu.nodes": fmt.Sprintf("%d", nodes[0])})
@@ -1514,7 +1730,7 @@ func (d *common) setNUMANode() error {
 	}
 
 	// Pick least used node.
-	var node uint64
+	node := nodes[0]
 	for _, numaNode := range nodes {
 		if numaUsage[int64(numaNode)] < numaUsage[int64(node)] {
 			node = numaNode
@@ -1524,6 +1740,100 @@ func (d *common) setNUMANode() error {
 	return d.VolatileSet(map[string]string{"volatile.cpu.nodes": fmt.Sprintf("%d", node)})
 }
 
+// numaNodes returns the host NUMA nodes the instance CPUs and memory are placed on.
+// This is either the NUMA restriction of the instance or the NUMA nodes of its pinned CPUs.
+func (d *common) numaNodes() []uint64 {
+	nodes := d.expandedConfig["limits.cpu.nodes"]
+	if nodes == "balanced" {
+		nodes = d.expandedConfig["volatile.cpu.nodes"]
+	}
+
+	numaNodes := []uint64{}
+
+	if nodes != "" {
+		numaNodeSet, err := resources.ParseNumaNodeSet(nodes)
+		if err != nil {
+			return nil
+		}
+
+		for _, numaNode := range numaNodeSet {
+			numaNodes = append(numaNodes, uint64(numaNode))
+		}
+
+		return numaNodes
+	}
+
+	// Check if the CPUs are pinned.
+	limit := d.expandedConfig["limits.cpu"]
+	if limit == "" {
+		return nil
+	}
+
+	_, err := strconv.Atoi(limit)
+	if err == nil {
+		return nil
+	}
+
+	pins, err := resources.ParseCpuset(limit)
+	if err != nil {
+		return nil
+	}
+
+	threadNodes, err := numaThreadNodesGet()
+	if err != nil {
+		return nil
+	}
+
+	return numaNodesOfThreads(pins, threadNodes)
+}
+
+// numaThreadNodes caches the NUMA node of each host CPU thread, saving a walk of sysfs on every state render.
+var numaThreadNodes map[int64]uint64
+var numaThreadNodesMu sync.Mutex
+
+// numaThreadNodesGet returns the NUMA node of each host CPU thread.
+func numaThreadNodesGet() (map[int64]uint64, error) {
+	numaThreadNodesMu.Lock()
+	defer numaThreadNodesMu.Unlock()
+
+	if numaThreadNodes != nil {
+		return numaThreadNodes, nil
+	}
+
+	cpus, err := resources.GetCPU()
+	if err != nil {
+		return nil, err
+	}
+
+	threadNodes := map[int64]uint64{}
+	for _, socket := range cpus.Sockets {
+		for _, core := range socket.Cores {
+			for _, thread := range core.Threads {
+				threadNodes[thread.ID] = thread.NUMANode
+			}
+		}
+	}
+
+	numaThreadNodes = threadNodes
+
+	return numaThreadNodes, nil
+}
+
+// numaNodesOfThreads returns the sorted NUMA nodes of the given host CPU threads.
+func numaNodesOfThreads(threads []int64, threadNodes map[int64]uint64) []uint64 {
+	numaNodes := []uint64{}
+	for _, thread := range threads {
+		numaNode, ok := threadNodes[thread]
+		if ok && !slices.Contains(numaNodes, numaNode) {
+			numaNodes = append(numaNodes, numaNode)
+		}
+	}
+
+	slices.Sort(numaNodes)
+
+	return numaNodes
+}
+
 // Gets the process starting time.
 func (d *common) processStartedAt(pid int) (time.Time, error) {
 	file, err := os.Stat(fmt.Sprintf("/proc/%d", pid))
@@ -1538,3 +1848,161 @@ func (d *common) processStartedAt(pid int) (time.Time, error) {
 
 	return time.Unix(linuxInfo.Ctim.Sec, linuxInfo.Ctim.Nsec), nil
 }
+
+// autoRestartResetInterval is how long an instance must have been running for its restart backoff to be reset.
+const autoRestartResetInterval = 10 * time.Minute
+
+// autoRestartMaxDelay is the longest delay before automatically restarting an instance.
+const autoRestartMaxDelay = 5 * time.Minute
+
+// autoRestartState tracks the automatic restarts of an instance.
+type autoRestartState struct {
+	attempts  int
+	lastStart time.Time
+}
+
+// autoRestartStates holds the automatic restart state of the local instances, keyed by project and name.
+var autoRestartStates = map[string]*autoRestartState{}
+var autoRestartStatesMu sync.Mutex
+
+// autoRestart restarts an instance which stopped without being asked to, following its boot.autorestart
+// policy. Consecutive restarts are delayed with an exponential backoff.
+func autoRestart(s *state.State, inst instance.Instance) {
+	key := project.Instance(inst.Project().Name, inst.Name())
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	autoRestartStatesMu.Lock()
+	defer autoRestartStatesMu.Unlock()
+
+	enabled, maxRetries, err := internalInstance.ParseAutoRestart(inst.ExpandedConfig()["boot.autorestart"])
+	if err != nil || !enabled || inst.IsEphemeral() {
+		delete(autoRestartStates, key)
+		return
+	}
+
+	restartState, ok := autoRestartStates[key]
+	if !ok || time.Since(restartState.lastStart) > autoRestartResetInterval {
+		restartState = &autoRestartState{}
+		autoRestartStates[key] = restartState
+	}
+
+	if maxRetries > 0 && restartState.attempts >= maxRetries {
+		l.Warn("Instance stopped unexpectedly, giving up on restarting it", logger.Ctx{"attempts": restartState.attempts})
+		s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceAutoRestartFailed.Event(inst, map[string]any{"attempts": restartState.attempts}))
+		delete(autoRestartStates, key)
+		return
+	}
+
+	delay := min(time.Second<<min(restartState.attempts, 10), autoRestartMaxDelay)
+	restartState.attempts++
+	attempt := restartState.attempts
+
+	l.Warn("Instance stopped unexpectedly, restarting it", logger.Ctx{"attempt": attempt, "delay": delay})
+
+	go func() {
+		select {
+		case <-time.After(delay):
+		case <-s.ShutdownCtx.Done():
+			return
+		}
+
+		// Reload the instance as it may have been changed, started or deleted in the meantime.
+		inst, err := instance.LoadByProjectAndName(s, inst.Project().Name, inst.Name())
+		if err != nil {
+			l.Warn("Failed loading instance to restart it", logger.Ctx{"err": err})
+			return
+		}
+
+		enabled, _, _ := internalInstance.ParseAutoRestart(inst.ExpandedConfig()["boot.autorestart"])
+		if !enabled || inst.IsRunning() {
+			return
+		}
+
+		autoRestartStatesMu.Lock()
+		restartState.lastStart = time.Now()
+		autoRestartStatesMu.Unlock()
+
+		err = inst.Start(false)
+		if err != nil {
+			l.Warn("Failed restarting instance", logger.Ctx{"attempt": attempt, "err": err})
+
+			// A failed start doesn't go through the stop hooks, so schedule the next attempt here.
+			autoRestart(s, inst)
+			return
+		}
+
+		s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceAutoRestarted.Event(inst, map[string]any{"attempt": attempt}))
+	}()
+}
+
+// networkDrainer is implemented by the networks able to steer new connections away from an instance.
+type networkDrainer interface {
+	InstanceDevicePortDrain(instanceUUID string, deviceName string) ([]string, func(), error)
+}
+
+// drainConnections steers the new connections of the network load balancers away from the instance and then
+// waits for boot.stop.drain_period so that the established connections can finish.
+// The returned function stops draining and is to be called once the instance is stopped.
+func (d *common) drainConnections() func() {
+	drainPeriod, _ := strconv.Atoi(d.expandedConfig["boot.stop.drain_period"])
+	if drainPeriod <= 0 {
+		return func() {}
+	}
+
+	networkProjectName, _, err := project.NetworkProject(d.state.DB.Cluster, d.project.Name)
+	if err != nil {
+		d.logger.Warn("Failed getting network project, not draining connections", logger.Ctx{"err": err})
+		return func() {}
+	}
+
+	undrains := []func(){}
+	loadBalancers := []string{}
+
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Config["type"] != "nic" || dev.Config["network"] == "" {
+			continue
+		}
+
+		n, err := network.LoadByName(d.state, networkProjectName, dev.Config["network"])
+		if err != nil {
+			d.logger.Warn("Failed loading network, not draining connections", logger.Ctx{"device": dev.Name, "err": err})
+			continue
+		}
+
+		drainer, ok := n.(networkDrainer)
+		if !ok {
+			continue
+		}
+
+		listenAddresses, undrain, err := drainer.InstanceDevicePortDrain(d.localConfig["volatile.uuid"], dev.Name)
+		if err != nil {
+			d.logger.Warn("Failed draining connections", logger.Ctx{"device": dev.Name, "err": err})
+			continue
+		}
+
+		undrains = append(undrains, undrain)
+		loadBalancers = append(loadBalancers, listenAddresses...)
+	}
+
+	undrainAll := func() {
+		for _, undrain := range undrains {
+			undrain()
+		}
+	}
+
+	// Nothing to wait for if the instance isn't the backend of any load balancer.
+	if len(loadBalancers) == 0 {
+		undrainAll()
+		return func() {}
+	}
+
+	d.logger.Info("Draining connections", logger.Ctx{"loadBalancers": loadBalancers, "period": drainPeriod})
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceDrainStarted.Event(d, map[string]any{"load_balancers": loadBalancers, "period": drainPeriod}))
+
+	time.Sleep(time.Duration(drainPeriod) * time.Second)
+
+	return func() {
+		undrainAll()
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceDrainFinished.Event(d, map[string]any{"load_balancers": loadBalancers}))
+	}
+}
@@ -0,0 +1,75 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestNumaNodesOfThreads(t *testing.T) {
+	threadNodes := map[int64]uint64{0: 0, 1: 0, 2: 1, 3: 1, 4: 2}
+
+	require.Equal(t, []uint64{0}, numaNodesOfThreads([]int64{0, 1}, threadNodes))
+	require.Equal(t, []uint64{0, 1, 2}, numaNodesOfThreads([]int64{4, 2, 0, 3}, threadNodes))
+
+	// Unknown threads are ignored.
+	require.Equal(t, []uint64{1}, numaNodesOfThreads([]int64{3, 8}, threadNodes))
+	require.Empty(t, numaNodesOfThreads([]int64{8}, threadNodes))
+}
+
+func TestSnapshotType(t *testing.T) {
+	d := &common{}
+	require.Equal(t, api.InstanceSnapshotTypeDisk, d.snapshotType())
+
+	d.stateful = true
+	require.Equal(t, api.InstanceSnapshotTypeCheckpoint, d.snapshotType())
+}
+
+// snapshotParentInstance is an instance which only implements the volatile config handling.
+type snapshotParentInstance struct {
+	instance.Instance
+
+	config map[string]string
+}
+
+func (i *snapshotParentInstance) LocalConfig() map[string]string {
+	return i.config
+}
+
+func (i *snapshotParentInstance) VolatileSet(changes map[string]string) error {
+	for k, v := range changes {
+		i.config[k] = v
+	}
+
+	return nil
+}
+
+func TestSnapshotReparentInstances(t *testing.T) {
+	snap0 := &snapshotParentInstance{config: map[string]string{}}
+	snap1 := &snapshotParentInstance{config: map[string]string{"volatile.snapshot.parent": "snap0"}}
+	snap2 := &snapshotParentInstance{config: map[string]string{"volatile.snapshot.parent": "snap1"}}
+	snap3 := &snapshotParentInstance{config: map[string]string{"volatile.snapshot.parent": "snap1"}}
+	inst := &snapshotParentInstance{config: map[string]string{"volatile.snapshot.parent": "snap2"}}
+	instances := []instance.Instance{snap0, snap1, snap2, snap3, inst}
+
+	// Deleting snap1 makes the snapshots deriving from it derive from its own parent.
+	require.NoError(t, snapshotReparentInstances(instances, "snap1", "snap0"))
+	require.Equal(t, "snap0", snap2.config["volatile.snapshot.parent"])
+	require.Equal(t, "snap0", snap3.config["volatile.snapshot.parent"])
+	require.Equal(t, "snap0", snap1.config["volatile.snapshot.parent"])
+	require.Equal(t, "snap2", inst.config["volatile.snapshot.parent"])
+
+	// Renaming snap2 follows the rename in the instance deriving from it.
+	require.NoError(t, snapshotReparentInstances(instances, "snap2", "snap4"))
+	require.Equal(t, "snap4", inst.config["volatile.snapshot.parent"])
+
+	// Deleting the first snapshot leaves those deriving from it without a parent.
+	require.NoError(t, snapshotReparentInstances(instances, "snap0", ""))
+	require.Equal(t, "", snap1.config["volatile.snapshot.parent"])
+	require.Equal(t, "", snap2.config["volatile.snapshot.parent"])
+	require.Equal(t, "", snap3.config["volatile.snapshot.parent"])
+	require.Empty(t, snap0.config)
+}
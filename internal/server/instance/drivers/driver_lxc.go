@@ -18,7 +18,6 @@ import (
 	"path/filepath"
 	"runtime"
 	"slices"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,6 +30,7 @@ import (
 	"github.com/gorilla/websocket"
 	liblxc "github.com/lxc/go-lxc"
 	"github.com/pkg/sftp"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sys/unix"
 	"google.golang.org/protobuf/proto"
@@ -40,6 +40,7 @@ import (
 	"github.com/lxc/incus/v6/internal/instancewriter"
 	internalIO "github.com/lxc/incus/v6/internal/io"
 	"github.com/lxc/incus/v6/internal/jmap"
+	"github.com/lxc/incus/v6/internal/limitcheck"
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/migration"
 	"github.com/lxc/incus/v6/internal/netutils"
@@ -47,6 +48,7 @@ import (
 	"github.com/lxc/incus/v6/internal/rsync"
 	"github.com/lxc/incus/v6/internal/server/apparmor"
 	"github.com/lxc/incus/v6/internal/server/cgroup"
+	"github.com/lxc/incus/v6/internal/server/coredump"
 	"github.com/lxc/incus/v6/internal/server/daemon"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
@@ -59,17 +61,20 @@ import (
 	"github.com/lxc/incus/v6/internal/server/instance/operationlock"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
 	"github.com/lxc/incus/v6/internal/server/locking"
+	"github.com/lxc/incus/v6/internal/server/lxcfs"
 	"github.com/lxc/incus/v6/internal/server/metrics"
 	localMigration "github.com/lxc/incus/v6/internal/server/migration"
 	"github.com/lxc/incus/v6/internal/server/network"
 	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/resources"
 	"github.com/lxc/incus/v6/internal/server/response"
 	"github.com/lxc/incus/v6/internal/server/seccomp"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
 	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
 	"github.com/lxc/incus/v6/internal/server/template"
+	"github.com/lxc/incus/v6/internal/server/tracing"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/api"
@@ -224,6 +229,13 @@ func lxcCreate(s *state.State, args db.InstanceArgs, p api.Project) (instance.In
 		return nil, nil, fmt.Errorf("Invalid config: %w", err)
 	}
 
+	if !d.IsSnapshot() {
+		err = instance.ValidDependencies(s, d.project.Name, d.name, d.expandedConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid config: %w", err)
+		}
+	}
+
 	err = instance.ValidDevices(s, d.project, d.Type(), d.localDevices, d.expandedDevices)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Invalid devices: %w", err)
@@ -272,6 +284,7 @@ func lxcCreate(s *state.State, args db.InstanceArgs, p api.Project) (instance.In
 			d.expandedConfig["security.idmap.base"],
 			d.expandedConfig["security.idmap.size"],
 			d.expandedConfig["raw.idmap"],
+			d.expandedConfig["idmap.passthrough.users"],
 		)
 
 		if err != nil {
@@ -442,7 +455,12 @@ type lxc struct {
 	idmapset *idmap.Set
 }
 
-func idmapSize(state *state.State, isolatedStr string, size string) (int64, error) {
+// idmapIsolatedSize is the size of the ID map of isolated containers without security.idmap.size.
+const idmapIsolatedSize = 65536
+
+// IdmapSize returns the size of the ID map of a container with the given security.idmap.isolated and
+// security.idmap.size.
+func IdmapSize(state *state.State, isolatedStr string, size string) (int64, error) {
 	isolated := false
 	if util.IsTrue(isolatedStr) {
 		isolated = true
@@ -451,7 +469,7 @@ func idmapSize(state *state.State, isolatedStr string, size string) (int64, erro
 	var idMapSize int64
 	if size == "" || size == "auto" {
 		if isolated {
-			idMapSize = 65536
+			idMapSize = idmapIsolatedSize
 		} else {
 			if len(state.OS.IdmapSet.Entries) != 2 {
 				return 0, fmt.Errorf("Bad initial idmap: %v", state.OS.IdmapSet)
@@ -471,9 +489,16 @@ func idmapSize(state *state.State, isolatedStr string, size string) (int64, erro
 	return idMapSize, nil
 }
 
+// IdmapIsolatedRange returns the range of host IDs the ID maps of isolated containers are allocated from.
+func IdmapIsolatedRange(s *state.State) (int64, int64) {
+	serverRange := s.OS.IdmapSet.Entries[0]
+
+	return serverRange.HostID + idmapIsolatedSize, serverRange.HostID + serverRange.MapRange
+}
+
 var idmapLock sync.Mutex
 
-func findIdmap(s *state.State, cName string, isolatedStr string, configBase string, configSize string, rawIdmap string) (*idmap.Set, int64, error) {
+func findIdmap(s *state.State, cName string, isolatedStr string, configBase string, configSize string, rawIdmap string, passthroughUsers string) (*idmap.Set, int64, error) {
 	isolated := false
 	if util.IsTrue(isolatedStr) {
 		isolated = true
@@ -484,6 +509,24 @@ func findIdmap(s *state.State, cName string, isolatedStr string, configBase stri
 		return nil, 0, err
 	}
 
+	// Host users and groups are resolved every time so the map follows changes of their IDs on the host.
+	passthroughMaps, err := idmap.NewSetFromPassthrough(passthroughUsers)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Failed parsing idmap.passthrough.users: %w", err)
+	}
+
+	for _, ent := range passthroughMaps.Entries {
+		if s.OS.IdmapSet != nil && s.OS.IdmapSet.Includes(&idmap.Set{Entries: []idmap.Entry{ent}}) {
+			return nil, 0, fmt.Errorf("Host ID %d can't be passed through as it's part of the subordinate IDs allocated to the server", ent.HostID)
+		}
+
+		if rawMaps.Intersects(ent) {
+			return nil, 0, fmt.Errorf("Host ID %d passed through conflicts with raw.idmap", ent.HostID)
+		}
+
+		rawMaps.Entries = append(rawMaps.Entries, ent)
+	}
+
 	if !isolated {
 		newIdmapset := idmap.Set{Entries: make([]idmap.Entry, len(s.OS.IdmapSet.Entries))}
 		copy(newIdmapset.Entries, s.OS.IdmapSet.Entries)
@@ -498,7 +541,7 @@ func findIdmap(s *state.State, cName string, isolatedStr string, configBase stri
 		return &newIdmapset, 0, nil
 	}
 
-	size, err := idmapSize(s, isolatedStr, configSize)
+	size, err := IdmapSize(s, isolatedStr, configSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -541,9 +584,7 @@ func findIdmap(s *state.State, cName string, isolatedStr string, configBase stri
 		return nil, 0, err
 	}
 
-	offset := s.OS.IdmapSet.Entries[0].HostID + 65536
-
-	mapentries := idmap.ByHostID{}
+	mapentries := idmap.Set{}
 	for _, container := range cts {
 		if container.Type() != instancetype.Container {
 			continue
@@ -572,7 +613,7 @@ func findIdmap(s *state.State, cName string, isolatedStr string, configBase stri
 			}
 		}
 
-		cSize, err := idmapSize(s, container.ExpandedConfig()["security.idmap.isolated"], container.ExpandedConfig()["security.idmap.size"])
+		cSize, err := IdmapSize(s, container.ExpandedConfig()["security.idmap.isolated"], container.ExpandedConfig()["security.idmap.size"])
 		if err != nil {
 			return nil, 0, err
 		}
@@ -580,51 +621,18 @@ func findIdmap(s *state.State, cName string, isolatedStr string, configBase stri
 		mapentries.Entries = append(mapentries.Entries, idmap.Entry{HostID: int64(cBase), MapRange: cSize})
 	}
 
-	sort.Sort(mapentries)
-
-	for i := range mapentries.Entries {
-		if i == 0 {
-			if mapentries.Entries[0].HostID < offset+size {
-				offset = mapentries.Entries[0].HostID + mapentries.Entries[0].MapRange
-				continue
-			}
-
-			set, err := mkIdmap(offset, size)
-			if err != nil && err == idmap.ErrHostIDIsSubID {
-				return nil, 0, err
-			}
-
-			return set, offset, nil
-		}
-
-		if mapentries.Entries[i-1].HostID+mapentries.Entries[i-1].MapRange > offset {
-			offset = mapentries.Entries[i-1].HostID + mapentries.Entries[i-1].MapRange
-			continue
-		}
-
-		offset = mapentries.Entries[i-1].HostID + mapentries.Entries[i-1].MapRange
-		if offset+size < mapentries.Entries[i].HostID {
-			set, err := mkIdmap(offset, size)
-			if err != nil && err == idmap.ErrHostIDIsSubID {
-				return nil, 0, err
-			}
-
-			return set, offset, nil
-		}
-
-		offset = mapentries.Entries[i].HostID + mapentries.Entries[i].MapRange
+	start, end := IdmapIsolatedRange(s)
+	offset, err := mapentries.FindFreeRange(start, end, size)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Not enough uid/gid available for the container")
 	}
 
-	if offset+size < s.OS.IdmapSet.Entries[0].HostID+s.OS.IdmapSet.Entries[0].MapRange {
-		set, err := mkIdmap(offset, size)
-		if err != nil && err == idmap.ErrHostIDIsSubID {
-			return nil, 0, err
-		}
-
-		return set, offset, nil
+	set, err := mkIdmap(offset, size)
+	if err != nil && err == idmap.ErrHostIDIsSubID {
+		return nil, 0, err
 	}
 
-	return nil, 0, fmt.Errorf("Not enough uid/gid available for the container")
+	return set, offset, nil
 }
 
 func (d *lxc) init() error {
@@ -664,7 +672,7 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 	defer revert.Fail()
 
 	// Load the go-lxc struct
-	cname := project.Instance(d.Project().Name, d.Name())
+	cname := d.runtimeName()
 	cc, err := liblxc.NewContainer(cname, d.state.OS.LxcPath)
 	if err != nil {
 		return nil, err
@@ -732,6 +740,14 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// Share the session keyring of the server if requested.
+	if util.IsFalse(d.expandedConfig["security.keyring.session"]) {
+		err = lxcSetConfigItem(cc, "lxc.keyring.session", "0")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Allow for lightweight init
 	d.cConfig = config
 	if !config {
@@ -784,6 +800,19 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		return nil, err
 	}
 
+	// Place the container under the instances parent cgroup, limiting it to the resources not reserved for the host.
+	if cgroup.InstancesParentEnabled() {
+		err = lxcSetConfigItem(cc, "lxc.cgroup.dir.monitor", fmt.Sprintf("lxc.monitor.%s", cname))
+		if err != nil {
+			return nil, err
+		}
+
+		err = lxcSetConfigItem(cc, "lxc.cgroup.dir.container", fmt.Sprintf("%s/lxc.payload.%s", cgroup.InstancesParent, cname))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = lxcSetConfigItem(cc, "lxc.autodev", "1")
 	if err != nil {
 		return nil, err
@@ -826,6 +855,19 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		bindMounts = append(bindMounts, "/dev/mqueue")
 	}
 
+	// Mount a dedicated tmpfs on /dev/shm so its size doesn't depend on the container's init.
+	if d.expandedConfig["linux.shm.size"] != "" {
+		shmSize, err := units.ParseByteSizeString(d.expandedConfig["linux.shm.size"])
+		if err != nil {
+			return nil, err
+		}
+
+		err = lxcSetConfigItem(cc, "lxc.mount.entry", fmt.Sprintf("tmpfs dev/shm tmpfs rw,nosuid,nodev,mode=1777,size=%d,create=dir 0 0", shmSize))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	for _, mnt := range bindMounts {
 		if !util.PathExists(mnt) {
 			continue
@@ -844,13 +886,14 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
-	// For lxcfs
+	// For lxcfs, unless managed by the server which then mounts the views enabled for the container itself.
 	templateConfDir := os.Getenv("INCUS_LXC_TEMPLATE_CONFIG")
 	if templateConfDir == "" {
 		templateConfDir = "/usr/share/lxc/config"
 	}
 
-	if util.PathExists(fmt.Sprintf("%s/common.conf.d/", templateConfDir)) {
+	lxcfsManaged := d.lxcfsManaged()
+	if !lxcfsManaged && util.PathExists(fmt.Sprintf("%s/common.conf.d/", templateConfDir)) {
 		err = lxcSetConfigItem(cc, "lxc.include", fmt.Sprintf("%s/common.conf.d/", templateConfDir))
 		if err != nil {
 			return nil, err
@@ -976,6 +1019,24 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// Expose the modules of the host kernel, so that tools like modprobe can find the modules to request.
+	if d.expandedConfig["linux.kernel_modules.load"] == "on-demand" {
+		modulesPath := filepath.Join("/lib/modules", d.state.OS.Uname.Release)
+		if util.PathExists(modulesPath) {
+			// With a merged /usr, /lib is a symlink which mount entries can't go through.
+			target := strings.TrimPrefix(modulesPath, "/")
+			fi, err := os.Lstat(filepath.Join(d.RootfsPath(), "lib"))
+			if err == nil && fi.Mode()&os.ModeSymlink != 0 {
+				target = filepath.Join("usr", target)
+			}
+
+			err = lxcSetConfigItem(cc, "lxc.mount.entry", fmt.Sprintf("%s %s none bind,ro,create=dir,optional 0 0", modulesPath, target))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Setup AppArmor
 	if d.state.OS.AppArmorAvailable {
 		if d.state.OS.AppArmorConfined || !d.state.OS.AppArmorAdmin {
@@ -1120,6 +1181,36 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	if lxcfsManaged {
+		// Mount the LXCFS views enabled for the container.
+		for _, view := range d.lxcfsMountedViews() {
+			err = lxcSetConfigItem(cc, "lxc.hook.mount", fmt.Sprintf("[ ! -e \"${LXC_ROOTFS_MOUNT}/%s\" ] || mount -n --bind %s \"${LXC_ROOTFS_MOUNT}/%s\"", view, strconv.Quote(filepath.Join(lxcfs.Path, view)), view))
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		// Hide the LXCFS views disabled for the container, mounted by the LXCFS hook.
+		for _, view := range lxcfsOptionalViews {
+			if d.lxcfsViewEnabled(view) {
+				continue
+			}
+
+			err = lxcSetConfigItem(cc, "lxc.hook.mount", fmt.Sprintf("umount -n -l \"${LXC_ROOTFS_MOUNT}/%s\" 2>/dev/null || true", view))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Present the container its own CPU information, mounted over the one provided by LXCFS.
+	if d.cpuinfoNeeded() {
+		err = lxcSetConfigItem(cc, "lxc.hook.mount", fmt.Sprintf("mount -n --bind %s \"${LXC_ROOTFS_MOUNT}/proc/cpuinfo\"", strconv.Quote(d.cpuinfoPath())))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Memory limits
 	if d.state.OS.CGInfo.Supports(cgroup.Memory, cg) {
 		memory := d.expandedConfig["limits.memory"]
@@ -1237,6 +1328,20 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// CPU power policy.
+	cpuPower := d.expandedConfig["limits.cpu.power"]
+	if cpuPower != "" && d.state.OS.CGInfo.Supports(cgroup.CPUUclamp, cg) {
+		uclampMin, uclampMax, err := cgroup.ParseCPUPower(cpuPower)
+		if err != nil {
+			return nil, err
+		}
+
+		err = cg.SetCPUUclamp(uclampMin, uclampMax)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Disk priority limits.
 	diskPriority := d.ExpandedConfig()["limits.disk.priority"]
 	if diskPriority != "" {
@@ -1297,9 +1402,23 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 	}
 
 	// Setup process limits
+	fds := d.expandedConfig["limits.fds"]
+	if fds != "" {
+		err = lxcSetConfigItem(cc, "lxc.prlimit.nofile", fds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	for k, v := range d.expandedConfig {
 		if strings.HasPrefix(k, "limits.kernel.") {
 			prlimitSuffix := strings.TrimPrefix(k, "limits.kernel.")
+
+			// limits.fds takes precedence over limits.kernel.nofile.
+			if prlimitSuffix == "nofile" && fds != "" {
+				continue
+			}
+
 			prlimitKey := fmt.Sprintf("lxc.prlimit.%s", prlimitSuffix)
 			err = lxcSetConfigItem(cc, prlimitKey, v)
 			if err != nil {
@@ -1308,6 +1427,19 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// Setup POSIX message queue limits, linux.sysctl.fs.mqueue.* keys take precedence.
+	for _, key := range []string{"msg_max", "msgsize_max", "queues_max"} {
+		v := d.expandedConfig[fmt.Sprintf("linux.mqueue.%s", key)]
+		if v == "" || d.expandedConfig[fmt.Sprintf("linux.sysctl.fs.mqueue.%s", key)] != "" {
+			continue
+		}
+
+		err = lxcSetConfigItem(cc, fmt.Sprintf("lxc.sysctl.fs.mqueue.%s", key), v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Setup sysctls
 	for k, v := range d.expandedConfig {
 		// gendoc:generate(entity=instance, group=miscellaneous, key=linux.sysctl.*)
@@ -1647,7 +1779,7 @@ func (d *lxc) deviceDetachNIC(configCopy map[string]string, netIF []deviceConfig
 	// If container is running, perform live detach of interface back to host.
 	if instanceRunning {
 		// For some reason, having network config confuses detach, so get our own go-lxc struct.
-		cname := project.Instance(d.Project().Name, d.Name())
+		cname := d.runtimeName()
 		cc, err := liblxc.NewContainer(cname, d.state.OS.LxcPath)
 		if err != nil {
 			return err
@@ -1925,9 +2057,17 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 	revert := revert.New()
 	defer revert.Fail()
 
+	// Forget a runtime name left over by a container which stopped without going through its stop hook.
+	if d.localConfig["volatile.runtime.name"] != "" {
+		err := d.releaseRuntimeName()
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
 	// Assign a NUMA node if needed.
 	if d.expandedConfig["limits.cpu.nodes"] == "balanced" {
-		err := d.setNUMANode()
+		err := d.setNUMANode(nil)
 		if err != nil {
 			return "", nil, err
 		}
@@ -1941,6 +2081,7 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		}
 
 		// Check if we need to change idmap.
+		// This is always the case with IDs mapped from outside the host's range, which keeps passed through users current.
 		if nextMap != nil && d.state.OS.IdmapSet != nil && !d.state.OS.IdmapSet.Includes(nextMap) {
 			// Update the idmap.
 			idmapSet, base, err := findIdmap(
@@ -1950,6 +2091,7 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 				d.expandedConfig["security.idmap.base"],
 				d.expandedConfig["security.idmap.size"],
 				d.expandedConfig["raw.idmap"],
+				d.expandedConfig["idmap.passthrough.users"],
 			)
 			if err != nil {
 				return "", nil, fmt.Errorf("Failed to get ID map: %w", err)
@@ -1973,6 +2115,19 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		}
 	}
 
+	// Generate the CPU information presented to the container.
+	if d.cpuinfoNeeded() {
+		err := d.cpuinfoGenerate()
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed generating CPU information: %w", err)
+		}
+	} else {
+		err := os.Remove(d.cpuinfoPath())
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", nil, fmt.Errorf("Failed removing CPU information: %w", err)
+		}
+	}
+
 	// Load the go-lxc struct
 	cc, err := d.initLXC(true)
 	if err != nil {
@@ -2003,7 +2158,7 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 
 	// Load any required kernel modules
 	kernelModules := d.expandedConfig["linux.kernel_modules"]
-	if kernelModules != "" {
+	if kernelModules != "" && d.expandedConfig["linux.kernel_modules.load"] != "on-demand" {
 		for _, module := range strings.Split(kernelModules, ",") {
 			module = strings.TrimPrefix(module, " ")
 			err := linux.LoadModule(module)
@@ -2048,6 +2203,11 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 
 	revert.Add(func() { _ = d.unmount() })
 
+	// Don't shift a filesystem which is halfway through a remap.
+	if util.PathExists(d.remapStatePath()) {
+		return "", nil, fmt.Errorf("An interrupted remap of the container is pending, run `incus admin remap` to complete it")
+	}
+
 	idmapType, nextIdmap, err := d.handleIdmappedStorage()
 	if err != nil {
 		return "", nil, fmt.Errorf("Failed to handle idmapped storage: %w", err)
@@ -2404,7 +2564,7 @@ func (d *lxc) detachInterfaceRename(netns string, ifName string, hostName string
 }
 
 // Start starts the instance.
-func (d *lxc) Start(stateful bool) error {
+func (d *lxc) Start(stateful bool) (err error) {
 	// Check that migration.stateful is set for stateful actions.
 	if stateful && util.IsFalse(d.expandedConfig["migration.stateful"]) {
 		return fmt.Errorf("Stateful start requires that the instance migration.stateful be set to true")
@@ -2420,6 +2580,9 @@ func (d *lxc) Start(stateful bool) error {
 	d.logger.Debug("Start started", logger.Ctx{"stateful": stateful})
 	defer d.logger.Debug("Start finished", logger.Ctx{"stateful": stateful})
 
+	traceCtx, span := d.traceStart("lxc.Start", attribute.Bool("incus.stateful", stateful))
+	defer func() { tracing.End(span, err) }()
+
 	// Check that we are startable before creating an operation lock.
 	// Must happen before creating operation Start lock to avoid the status check returning Stopped due to the
 	// existence of a Start operation lock.
@@ -2514,7 +2677,9 @@ func (d *lxc) Start(stateful bool) error {
 	}
 
 	// Run the shared start code.
+	_, startCommonSpan := tracing.Start(traceCtx, "lxc.startCommon")
 	configPath, postStartHooks, err := d.startCommon()
+	tracing.End(startCommonSpan, err)
 	if err != nil {
 		op.Done(err)
 		return err
@@ -2523,12 +2688,14 @@ func (d *lxc) Start(stateful bool) error {
 	name := project.Instance(d.Project().Name, d.name)
 
 	// Start the LXC container
+	_, forkstartSpan := tracing.Start(traceCtx, "lxc.forkstart")
 	_, err = subprocess.RunCommand(
 		d.state.OS.ExecPath,
 		"forkstart",
 		name,
 		d.state.OS.LxcPath,
 		configPath)
+	tracing.End(forkstartSpan, err)
 	if err != nil && !d.IsRunning() {
 		// Attempt to extract the LXC errors
 		lxcLog := ""
@@ -2575,6 +2742,14 @@ func (d *lxc) Start(stateful bool) error {
 		return err
 	}
 
+	// Install the SSH keys from the configuration.
+	if len(instance.SSHAuthorizedKeys(d)) > 0 {
+		err = instance.SyncSSHAuthorizedKeys(d)
+		if err != nil {
+			d.logger.Warn("Failed installing SSH keys", logger.Ctx{"err": err})
+		}
+	}
+
 	if op.Action() == "start" {
 		d.logger.Info("Started instance", ctxMap)
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
@@ -2611,6 +2786,15 @@ func (d *lxc) onStart(_ map[string]string) error {
 	// Template anything that needs templating
 	key := "volatile.apply_template"
 	if d.localConfig[key] != "" {
+		// Give copies their own machine ID if requested.
+		if d.localConfig[key] == string(instance.TemplateTriggerCopy) && d.expandedConfig["machine_id.copy"] == "regenerate" {
+			err = d.regenerateMachineID()
+			if err != nil {
+				_ = apparmor.InstanceUnload(d.state.OS, d)
+				return err
+			}
+		}
+
 		// Run any template that needs running
 		err = d.templateApplyNow(instance.TemplateTrigger(d.localConfig[key]))
 		if err != nil {
@@ -2869,6 +3053,11 @@ func (d *lxc) Shutdown(timeout time.Duration) error {
 		return err
 	}
 
+	// Steer the new connections of the network load balancers away from the instance, holding the operation
+	// lock so that the instance can't be started or stopped meanwhile.
+	undrain := d.drainConnections()
+	defer undrain()
+
 	// If frozen, resume so the signal can be handled.
 	if d.IsFrozen() {
 		err := d.Unfreeze()
@@ -3055,7 +3244,7 @@ func (d *lxc) onStop(args map[string]string) error {
 		}
 
 		// Unload the apparmor profile
-		err = apparmor.InstanceUnload(d.state.OS, d)
+		err = apparmor.InstanceUnload(d.state.OS, d.runtimeInstance())
 		if err != nil {
 			op.Done(fmt.Errorf("Failed to destroy apparmor namespace: %w", err))
 			return
@@ -3075,6 +3264,15 @@ func (d *lxc) onStop(args map[string]string) error {
 			return
 		}
 
+		// Release what's still named after the container's name from before it was renamed while running.
+		if d.localConfig["volatile.runtime.name"] != "" {
+			err = d.releaseRuntimeName()
+			if err != nil {
+				op.Done(err)
+				return
+			}
+		}
+
 		// Log and emit lifecycle if not user triggered
 		if op.GetInstanceInitiated() {
 			ctxMap := logger.Ctx{
@@ -3106,6 +3304,11 @@ func (d *lxc) onStop(args map[string]string) error {
 		// Trigger a rebalance
 		cgroup.TaskSchedulerTrigger("container", d.name, "stopped")
 
+		// Restart the container if its init process exited without being asked to.
+		if op.GetInstanceInitiated() && !d.ephemeral {
+			autoRestart(d.state, d)
+		}
+
 		// Destroy ephemeral containers
 		if d.ephemeral {
 			err = d.delete(true)
@@ -3299,6 +3502,8 @@ func (d *lxc) Render(options ...func(response any) error) (any, any, error) {
 			LastUsedAt:      d.lastUsedDate,
 			Name:            strings.SplitN(d.name, "/", 2)[1],
 			Stateful:        d.stateful,
+			Type:            d.snapshotType(),
+			Parent:          d.localConfig["volatile.snapshot.parent"],
 			Size:            -1, // Default to uninitialized/error state (0 means no CoW usage).
 		}
 
@@ -3322,7 +3527,7 @@ func (d *lxc) Render(options ...func(response any) error) (any, any, error) {
 	// Prepare the ETag
 	etag := []any{d.architecture, d.localConfig, d.localDevices, d.ephemeral, d.profiles}
 
-	statusCode := d.statusCode()
+	statusCode := d.renderStatusCode(d.statusCode())
 	instState := api.Instance{
 		ExpandedConfig:  d.expandedConfig,
 		ExpandedDevices: d.expandedDevices.CloneNative(),
@@ -3427,10 +3632,19 @@ func (d *lxc) renderState(statusCode api.StatusCode, hostInterfaces []net.Interf
 		var err error
 
 		status.CPU = d.cpuState()
+
+		// The memory of containers is only restricted to NUMA nodes through limits.cpu.nodes.
+		if d.expandedConfig["limits.cpu.nodes"] != "" {
+			status.CPU.NUMANodes = d.numaNodes()
+		}
+
 		status.Memory = d.memoryState()
 		status.Network = d.networkState(hostInterfaces)
 		status.Pid = int64(pid)
 		status.Processes = processesState
+		status.FileDescriptors = d.fileDescriptorsState()
+		status.GPU = d.gpuState()
+		status.Pressure = d.pressureState()
 
 		status.StartedAt, err = d.processStartedAt(d.InitPID())
 		if err != nil {
@@ -3447,7 +3661,7 @@ func (d *lxc) renderState(statusCode api.StatusCode, hostInterfaces []net.Interf
 
 // RenderState renders just the running state of the instance.
 func (d *lxc) RenderState(hostInterfaces []net.Interface) (*api.InstanceState, error) {
-	return d.renderState(d.statusCode(), hostInterfaces)
+	return d.renderState(d.renderStatusCode(d.statusCode()), hostInterfaces)
 }
 
 // snapshot creates a snapshot of the instance.
@@ -3687,6 +3901,14 @@ func (d *lxc) Restore(sourceContainer instance.Instance, stateful bool) error {
 		return err
 	}
 
+	// Record the restored snapshot as the one the instance now derives from.
+	_, snapName, _ := api.GetParentAndSnapshotName(sourceContainer.Name())
+	err = d.VolatileSet(map[string]string{"volatile.snapshot.parent": snapName})
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
 	// If the container wasn't running but was stateful, should we restore it as running?
 	if stateful {
 		if !util.PathExists(d.StatePath()) {
@@ -3800,6 +4022,13 @@ func (d *lxc) Delete(force bool) error {
 			return fmt.Errorf("Invalid parent: %w", err)
 		}
 
+		// Keep the snapshot tree connected.
+		_, snapName, _ := api.GetParentAndSnapshotName(d.name)
+		err = d.snapshotReparent(snapName, d.localConfig["volatile.snapshot.parent"])
+		if err != nil {
+			return err
+		}
+
 		// Update the backup file.
 		err = parent.UpdateBackupFile()
 		if err != nil {
@@ -3883,6 +4112,12 @@ func (d *lxc) delete(force bool) error {
 			}
 		}
 
+		// Remove all core dumps.
+		err = coredump.DeleteAll(d.project.Name, d.Name())
+		if err != nil {
+			return fmt.Errorf("Failed deleting core dumps: %w", err)
+		}
+
 		// Run device removal function for each device.
 		d.devicesRemove(d)
 
@@ -3919,6 +4154,47 @@ func (d *lxc) delete(force bool) error {
 	return nil
 }
 
+// lxcRuntime is a container seen under the name it's running under, for the resources which were named after it
+// when the container started and can't be renamed while it's running.
+type lxcRuntime struct {
+	*lxc
+
+	name string
+}
+
+// Name returns the name the container is running under.
+func (r *lxcRuntime) Name() string {
+	return r.name
+}
+
+// runtimeInstance returns the container as seen under the name it's running under.
+func (d *lxc) runtimeInstance() *lxcRuntime {
+	name := d.localConfig["volatile.runtime.name"]
+	if name == "" {
+		name = d.name
+	}
+
+	return &lxcRuntime{lxc: d, name: name}
+}
+
+// releaseRuntimeName removes the resources of a stopped container which are named after its name from before it
+// was renamed while running and forgets about that name.
+func (d *lxc) releaseRuntimeName() error {
+	runtime := d.runtimeInstance()
+
+	_ = apparmor.InstanceDelete(d.state.OS, runtime)
+	seccomp.DeleteProfile(runtime)
+	_ = os.Remove(d.DevicesPath())
+	_ = os.RemoveAll(d.ShmountsPath())
+
+	err := d.VolatileSet(map[string]string{"volatile.runtime.name": ""})
+	if err != nil {
+		return fmt.Errorf("Failed clearing runtime name: %w", err)
+	}
+
+	return nil
+}
+
 // Rename renames the instance. Accepts an argument to enable applying deferred TemplateTriggerRename.
 func (d *lxc) Rename(newName string, applyTemplateTrigger bool) error {
 	unlock, err := d.updateBackupFileLock(context.Background())
@@ -3943,18 +4219,30 @@ func (d *lxc) Rename(newName string, applyTemplateTrigger bool) error {
 		return err
 	}
 
-	if d.IsRunning() {
-		return fmt.Errorf("Renaming of running instance not allowed")
-	}
-
-	// Clean things up.
-	d.cleanup()
-
 	pool, err := storagePools.LoadByInstance(d.state, d)
 	if err != nil {
 		return fmt.Errorf("Failed loading instance storage pool: %w", err)
 	}
 
+	// Running containers keep their runtime resources under their previous name until they stop.
+	running := d.IsRunning()
+	if running {
+		if !pool.Driver().Info().RunningRename {
+			return fmt.Errorf("Renaming of running instance not supported on %q storage pools", pool.Driver().Info().Name)
+		}
+	} else {
+		// Release the previous name of a container which stopped without going through its stop hook.
+		if d.localConfig["volatile.runtime.name"] != "" {
+			err = d.releaseRuntimeName()
+			if err != nil {
+				return err
+			}
+		}
+
+		// Clean things up.
+		d.cleanup()
+	}
+
 	if d.IsSnapshot() {
 		_, newSnapName, _ := api.GetParentAndSnapshotName(newName)
 		err = pool.RenameInstanceSnapshot(d, newSnapName, nil)
@@ -4023,8 +4311,19 @@ func (d *lxc) Rename(newName string, applyTemplateTrigger bool) error {
 		return fmt.Errorf("Failed renaming instance: %w", err)
 	}
 
+	// Keep the snapshot tree connected.
+	if d.IsSnapshot() {
+		_, oldSnapName, _ := api.GetParentAndSnapshotName(oldName)
+		_, newSnapName, _ := api.GetParentAndSnapshotName(newName)
+
+		err = d.snapshotReparent(oldSnapName, newSnapName)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Rename the logging path.
-	newFullName := project.Instance(d.Project().Name, d.Name())
+	newFullName := project.Instance(d.Project().Name, newName)
 	_ = os.RemoveAll(internalUtil.LogPath(newFullName))
 	if util.PathExists(d.LogPath()) {
 		err := os.Rename(d.LogPath(), internalUtil.LogPath(newFullName))
@@ -4034,8 +4333,16 @@ func (d *lxc) Rename(newName string, applyTemplateTrigger bool) error {
 		}
 	}
 
+	// Rename the core dumps path.
+	if !d.IsSnapshot() {
+		err = coredump.Rename(d.project.Name, oldName, newName)
+		if err != nil {
+			d.logger.Error("Failed renaming instance", ctxMap)
+			return fmt.Errorf("Failed renaming instance: %w", err)
+		}
+	}
+
 	// Rename the runtime path.
-	newFullName = project.Instance(d.Project().Name, d.Name())
 	_ = os.RemoveAll(internalUtil.RunPath(newFullName))
 	if util.PathExists(d.RunPath()) {
 		err := os.Rename(d.RunPath(), internalUtil.RunPath(newFullName))
@@ -4071,6 +4378,30 @@ func (d *lxc) Rename(newName string, applyTemplateTrigger bool) error {
 		revert.Add(func() { _ = b.Rename(oldName) })
 	}
 
+	// Record the name the container runs under, unless it's renamed back to it.
+	if running {
+		runtimeName := d.localConfig["volatile.runtime.name"]
+		if runtimeName == "" {
+			runtimeName = oldName
+		}
+
+		if runtimeName == newName {
+			runtimeName = ""
+		}
+
+		err = d.VolatileSet(map[string]string{"volatile.runtime.name": runtimeName})
+		if err != nil {
+			return fmt.Errorf("Failed recording runtime name: %w", err)
+		}
+	}
+
+	if !d.IsSnapshot() {
+		err = d.devicesRename(d, oldName, running)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Invalidate the go-lxc cache.
 	d.release()
 
@@ -4390,12 +4721,29 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 			}
 		}
 
+		// The hooks of the container find it through the name it's running under.
+		if slices.Contains(changedConfig, "volatile.runtime.name") {
+			return fmt.Errorf("Key %q can't be changed by the user", "volatile.runtime.name")
+		}
+
 		// Do some validation of the config diff (allows mixed instance types for profiles).
 		err = instance.ValidConfig(d.state.OS, d.expandedConfig, true, instancetype.Any)
 		if err != nil {
 			return fmt.Errorf("Invalid expanded config: %w", err)
 		}
 
+		err = d.validateReservedCPUs()
+		if err != nil {
+			return fmt.Errorf("Invalid expanded config: %w", err)
+		}
+
+		if slices.Contains(changedConfig, "boot.depends_on") {
+			err = instance.ValidDependencies(d.state, d.project.Name, d.name, d.expandedConfig)
+			if err != nil {
+				return fmt.Errorf("Invalid expanded config: %w", err)
+			}
+		}
+
 		// Do full expanded validation of the devices diff.
 		err = instance.ValidDevices(d.state, d.project, d.Type(), d.localDevices, d.expandedDevices)
 		if err != nil {
@@ -4445,14 +4793,14 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 	}
 
 	// If apparmor changed, re-validate the apparmor profile (even if not running).
-	if slices.Contains(changedConfig, "raw.apparmor") || slices.Contains(changedConfig, "security.nesting") {
+	if slices.Contains(changedConfig, "raw.apparmor") || slices.Contains(changedConfig, "raw.apparmor.profiles") || slices.Contains(changedConfig, "security.nesting") {
 		err = apparmor.InstanceValidate(d.state.OS, d, nil)
 		if err != nil {
 			return fmt.Errorf("Parse AppArmor profile: %w", err)
 		}
 	}
 
-	if slices.Contains(changedConfig, "security.idmap.isolated") || slices.Contains(changedConfig, "security.idmap.base") || slices.Contains(changedConfig, "security.idmap.size") || slices.Contains(changedConfig, "raw.idmap") || slices.Contains(changedConfig, "security.privileged") {
+	if slices.Contains(changedConfig, "security.idmap.isolated") || slices.Contains(changedConfig, "security.idmap.base") || slices.Contains(changedConfig, "security.idmap.size") || slices.Contains(changedConfig, "raw.idmap") || slices.Contains(changedConfig, "idmap.passthrough.users") || slices.Contains(changedConfig, "security.privileged") {
 		var idmapSet *idmap.Set
 		base := int64(0)
 		if !d.IsPrivileged() {
@@ -4464,6 +4812,7 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 				d.expandedConfig["security.idmap.base"],
 				d.expandedConfig["security.idmap.size"],
 				d.expandedConfig["raw.idmap"],
+				d.expandedConfig["idmap.passthrough.users"],
 			)
 			if err != nil {
 				return fmt.Errorf("Failed to get ID map: %w", err)
@@ -4506,9 +4855,9 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 		for _, key := range changedConfig {
 			value := d.expandedConfig[key]
 
-			if key == "raw.apparmor" || key == "security.nesting" {
+			if key == "raw.apparmor" || key == "raw.apparmor.profiles" || key == "security.nesting" {
 				// Update the AppArmor profile
-				err = apparmor.InstanceLoad(d.state.OS, d, nil)
+				err = apparmor.InstanceLoad(d.state.OS, d.runtimeInstance(), nil)
 				if err != nil {
 					return err
 				}
@@ -4540,7 +4889,7 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 						}
 					}
 				}
-			} else if key == "linux.kernel_modules" && value != "" {
+			} else if key == "linux.kernel_modules" && value != "" && d.expandedConfig["linux.kernel_modules.load"] != "on-demand" {
 				for _, module := range strings.Split(value, ",") {
 					module = strings.TrimPrefix(module, " ")
 					err := linux.LoadModule(module)
@@ -4759,22 +5108,45 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 				if err != nil {
 					return err
 				}
-			} else if key == "limits.processes" {
-				if !d.state.OS.CGInfo.Supports(cgroup.Pids, cg) {
-					continue
-				}
 
-				if value == "" {
-					err = cg.SetMaxProcesses(-1)
-					if err != nil {
-						return err
-					}
-				} else {
-					valueInt, err := strconv.ParseInt(value, 10, 64)
+				// The hard allowance is reflected in the CPU information of the container.
+				if key == "limits.cpu.allowance" {
+					err = d.RefreshCPUInfo()
 					if err != nil {
-						return err
+						return fmt.Errorf("Failed refreshing CPU information: %w", err)
 					}
-
+				}
+			} else if key == "limits.cpu.power" {
+				// Skip if no utilization clamping support
+				if !d.state.OS.CGInfo.Supports(cgroup.CPUUclamp, cg) {
+					continue
+				}
+
+				uclampMin, uclampMax, err := cgroup.ParseCPUPower(value)
+				if err != nil {
+					return err
+				}
+
+				err = cg.SetCPUUclamp(uclampMin, uclampMax)
+				if err != nil {
+					return err
+				}
+			} else if key == "limits.processes" {
+				if !d.state.OS.CGInfo.Supports(cgroup.Pids, cg) {
+					continue
+				}
+
+				if value == "" {
+					err = cg.SetMaxProcesses(-1)
+					if err != nil {
+						return err
+					}
+				} else {
+					valueInt, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						return err
+					}
+
 					err = cg.SetMaxProcesses(valueInt)
 					if err != nil {
 						return err
@@ -4812,6 +5184,14 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 				}
 			}
 		}
+
+		// Update the SSH keys installed in the instance.
+		if slices.ContainsFunc(changedConfig, func(key string) bool { return strings.HasPrefix(key, "ssh.authorized_keys.") }) {
+			err = instance.SyncSSHAuthorizedKeys(d)
+			if err != nil {
+				d.logger.Warn("Failed updating SSH keys", logger.Ctx{"err": err})
+			}
+		}
 	}
 
 	// Re-generate the instance-id if needed.
@@ -5303,6 +5683,12 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 		}
 	}
 
+	// Steer the new connections of the network load balancers away from a running instance before moving it.
+	if d.IsRunning() {
+		undrain := d.drainConnections()
+		defer undrain()
+	}
+
 	pool, err := storagePools.LoadByInstance(d.state, d)
 	if err != nil {
 		return fmt.Errorf("Failed loading instance: %w", err)
@@ -6606,6 +6992,51 @@ func (d *lxc) migrate(args *instance.CriuMigrationArgs) error {
 	return nil
 }
 
+// regenerateMachineID replaces the machine ID of the container with a new random one.
+// Missing and empty machine ID files are left alone as the init system fills them on boot.
+func (d *lxc) regenerateMachineID() error {
+	machineID, err := internalUtil.RandomHexString(16)
+	if err != nil {
+		return err
+	}
+
+	rootfs, err := filepath.EvalSymlinks(d.RootfsPath())
+	if err != nil {
+		return err
+	}
+
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		fullPath := filepath.Join(rootfs, path)
+
+		// Only write regular files within the container root filesystem.
+		fi, err := os.Lstat(fullPath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return err
+		}
+
+		if !fi.Mode().IsRegular() || fi.Size() == 0 {
+			continue
+		}
+
+		parent, err := filepath.EvalSymlinks(filepath.Dir(fullPath))
+		if err != nil || !strings.HasPrefix(parent, rootfs+"/") {
+			continue
+		}
+
+		// Truncating the existing file keeps its ownership and mode.
+		err = os.WriteFile(filepath.Join(parent, filepath.Base(fullPath)), []byte(machineID+"\n"), 0)
+		if err != nil {
+			return fmt.Errorf("Failed writing machine ID to %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
 func (d *lxc) templateApplyNow(trigger instance.TemplateTrigger) error {
 	// If there's no metadata, just return
 	fname := filepath.Join(d.Path(), "metadata.yaml")
@@ -7108,7 +7539,7 @@ func (d *lxc) Console(protocol string) (*os.File, chan error, error) {
 	args := []string{
 		d.state.OS.ExecPath,
 		"forkconsole",
-		project.Instance(d.Project().Name, d.Name()),
+		d.runtimeName(),
 		d.state.OS.LxcPath,
 		filepath.Join(d.RunPath(), "lxc.conf"),
 		"tty=0",
@@ -7219,7 +7650,7 @@ func (d *lxc) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, st
 	defer func() { _ = logFile.Close() }()
 
 	// Prepare the subcommand
-	cname := project.Instance(d.Project().Name, d.Name())
+	cname := d.runtimeName()
 	args := []string{
 		d.state.OS.ExecPath,
 		"forkexec",
@@ -7388,6 +7819,21 @@ func (d *lxc) diskState() map[string]api.InstanceStateDisk {
 	return disk
 }
 
+// pressureState returns the pressure stall information of the container, if available.
+func (d *lxc) pressureState() map[string]api.InstanceStatePressure {
+	cc, err := d.initLXC(false)
+	if err != nil {
+		return nil
+	}
+
+	cg, err := d.cgroup(cc, true)
+	if err != nil {
+		return nil
+	}
+
+	return cgroupPressureState(cg)
+}
+
 func (d *lxc) memoryState() api.InstanceStateMemory {
 	memory := api.InstanceStateMemory{}
 
@@ -7515,6 +7961,143 @@ func (d *lxc) networkState(hostInterfaces []net.Interface) map[string]api.Instan
 	return result
 }
 
+// processes returns the PIDs of all the processes in the instance.
+func (d *lxc) processes(pid int) []int64 {
+	pids := []int64{int64(pid)}
+
+	// Go through the pid list, adding new pids at the end so we go through them all
+	for i := 0; i < len(pids); i++ {
+		fname := fmt.Sprintf("/proc/%d/task/%d/children", pids[i], pids[i])
+		fcont, err := os.ReadFile(fname)
+		if err != nil {
+			// the process terminated during execution of this loop
+			continue
+		}
+
+		content := strings.Split(string(fcont), " ")
+		for j := 0; j < len(content); j++ {
+			pid, err := strconv.ParseInt(content[j], 10, 64)
+			if err == nil {
+				pids = append(pids, pid)
+			}
+		}
+	}
+
+	return pids
+}
+
+// FileDescriptorsUsage returns the total number of open file descriptors in the instance along with the
+// highest number of open file descriptors of a single process.
+func (d *lxc) FileDescriptorsUsage() (int64, int64, error) {
+	pid := d.InitPID()
+	if pid == -1 {
+		return 0, 0, fmt.Errorf("PID of LXC instance could not be initialized")
+	}
+
+	var total int64
+	var highest int64
+
+	for _, pid := range d.processes(pid) {
+		entries, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid))
+		if err != nil {
+			// the process terminated during execution of this loop
+			continue
+		}
+
+		count := int64(len(entries))
+		total += count
+		highest = max(highest, count)
+	}
+
+	return total, highest, nil
+}
+
+// fileDescriptorsStateInterval is how long the number of open file descriptors reported in the instance state is
+// cached for, as counting them walks through every process of the instance.
+const fileDescriptorsStateInterval = 10 * time.Second
+
+type fileDescriptorsStateEntry struct {
+	total int64
+	time  time.Time
+}
+
+var fileDescriptorsStateMu sync.Mutex
+var fileDescriptorsStateCache = map[int]fileDescriptorsStateEntry{}
+
+// fileDescriptorsState returns the total number of open file descriptors in the instance for its state, counting them
+// at most every fileDescriptorsStateInterval.
+func (d *lxc) fileDescriptorsState() int64 {
+	fileDescriptorsStateMu.Lock()
+	defer fileDescriptorsStateMu.Unlock()
+
+	entry, ok := fileDescriptorsStateCache[d.id]
+	if ok && time.Since(entry.time) < fileDescriptorsStateInterval {
+		return entry.total
+	}
+
+	// Forget about the instances which weren't rendered recently.
+	for id, entry := range fileDescriptorsStateCache {
+		if time.Since(entry.time) >= fileDescriptorsStateInterval {
+			delete(fileDescriptorsStateCache, id)
+		}
+	}
+
+	total, _, err := d.FileDescriptorsUsage()
+	if err != nil {
+		return 0
+	}
+
+	fileDescriptorsStateCache[d.id] = fileDescriptorsStateEntry{total: total, time: time.Now()}
+
+	return total
+}
+
+// ProcessesLimitHits returns the number of times the process limit of the instance was hit.
+func (d *lxc) ProcessesLimitHits() (int64, error) {
+	cc, err := d.initLXC(false)
+	if err != nil {
+		return -1, err
+	}
+
+	cg, err := d.cgroup(cc, true)
+	if err != nil {
+		return -1, err
+	}
+
+	return cg.GetProcessesLimitHits()
+}
+
+// OOMKills returns the number of processes of the instance killed by the out-of-memory killer. The name of the
+// killed processes is only known from the kernel log, see OOMKillTask.
+func (d *lxc) OOMKills() (int64, string, error) {
+	cg, err := d.CGroup()
+	if err != nil {
+		return -1, "", err
+	}
+
+	count, err := cg.GetOOMKills()
+	if err != nil {
+		return -1, "", err
+	}
+
+	return count, "", nil
+}
+
+// OOMKillTask returns the name of the last process of the container among the given out-of-memory kills from the
+// kernel log, empty if none.
+func (d *lxc) OOMKillTask(kills []linux.OOMKill) string {
+	payload := fmt.Sprintf("lxc.payload.%s", d.runtimeName())
+
+	task := ""
+	for _, kill := range kills {
+		if slices.Contains(strings.Split(kill.TaskCGroup, "/"), payload) {
+			task = kill.Task
+		}
+	}
+
+	return task
+}
+
 func (d *lxc) processesState(pid int) (int64, error) {
 	// Return 0 if not running
 	if pid == -1 {
@@ -7540,27 +8123,84 @@ func (d *lxc) processesState(pid int) (int64, error) {
 		return value, nil
 	}
 
-	pids := []int64{int64(pid)}
+	return int64(len(d.processes(pid))), nil
+}
 
-	// Go through the pid list, adding new pids at the end so we go through them all
-	for i := 0; i < len(pids); i++ {
-		fname := fmt.Sprintf("/proc/%d/task/%d/children", pids[i], pids[i])
-		fcont, err := os.ReadFile(fname)
-		if err != nil {
-			// the process terminated during execution of this loop
-			continue
+// gpuState returns the usage of the physical GPUs passed to the instance, keyed by device name.
+// Utilization and memory usage are those of the whole GPU which may be shared with other instances.
+func (d *lxc) gpuState() map[string]api.InstanceStateGPU {
+	var gpuDevices []deviceConfig.DeviceNamed
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Config["type"] == "gpu" && slices.Contains([]string{"", "physical"}, dev.Config["gputype"]) {
+			gpuDevices = append(gpuDevices, dev)
 		}
+	}
 
-		content := strings.Split(string(fcont), " ")
-		for j := 0; j < len(content); j++ {
-			pid, err := strconv.ParseInt(content[j], 10, 64)
-			if err == nil {
-				pids = append(pids, pid)
+	if len(gpuDevices) == 0 {
+		return nil
+	}
+
+	usage, err := resources.GetGPUUsage()
+	if err != nil {
+		d.logger.Warn("Failed to get GPU usage", logger.Ctx{"err": err})
+		return nil
+	}
+
+	if len(usage) == 0 {
+		return nil
+	}
+
+	gpus, err := resources.GetGPU()
+	if err != nil {
+		d.logger.Warn("Failed to get GPUs", logger.Ctx{"err": err})
+		return nil
+	}
+
+	var pids []int64
+	pid := d.InitPID()
+	if pid > 0 {
+		pids = d.processes(pid)
+	}
+
+	gpuState := map[string]api.InstanceStateGPU{}
+	for _, dev := range gpuDevices {
+		for _, gpu := range gpus.Cards {
+			if gpu.PCIAddress == "" || !device.GPUSelected(dev.Config, gpu) {
+				continue
+			}
+
+			gpuUsage, ok := usage[gpu.PCIAddress]
+			if !ok {
+				continue
+			}
+
+			state := api.InstanceStateGPU{
+				PCIAddress:          gpu.PCIAddress,
+				Utilization:         gpuUsage.Utilization,
+				MemoryUsage:         gpuUsage.MemoryUsage,
+				MemoryTotal:         gpuUsage.MemoryTotal,
+				InstanceMemoryUsage: -1,
+			}
+
+			if gpuUsage.Processes != nil {
+				state.InstanceMemoryUsage = 0
+				for _, pid := range pids {
+					state.InstanceMemoryUsage += gpuUsage.Processes[pid]
+				}
 			}
+
+			// Devices matching multiple GPUs get one entry per GPU.
+			name := dev.Name
+			_, ok = gpuState[name]
+			if ok {
+				name = fmt.Sprintf("%s/%s", dev.Name, gpu.PCIAddress)
+			}
+
+			gpuState[name] = state
 		}
 	}
 
-	return int64(len(pids)), nil
+	return gpuState
 }
 
 // getStorageType returns the storage type of the instance's storage pool.
@@ -7716,7 +8356,7 @@ func (d *lxc) insertMountGo(source, target, fstype string, flags int, mntnsPID i
 }
 
 func (d *lxc) insertMountLXC(source, target, fstype string, flags int) error {
-	cname := project.Instance(d.Project().Name, d.Name())
+	cname := d.runtimeName()
 	configPath := filepath.Join(d.RunPath(), "lxc.conf")
 	if fstype == "" {
 		fstype = "none"
@@ -7814,7 +8454,7 @@ func (d *lxc) removeMount(mount string) error {
 
 	if d.state.OS.LXCFeatures["mount_injection_file"] {
 		configPath := filepath.Join(d.RunPath(), "lxc.conf")
-		cname := project.Instance(d.Project().Name, d.Name())
+		cname := d.runtimeName()
 
 		if !strings.HasPrefix(mount, "/") {
 			mount = "/" + mount
@@ -7980,7 +8620,7 @@ func (d *lxc) FillNetworkDevice(name string, m deviceConfig.Device) (deviceConfi
 		}
 
 		// Attempt to include all existing interfaces
-		cname := project.Instance(d.Project().Name, d.Name())
+		cname := d.runtimeName()
 		cc, err := liblxc.NewContainer(cname, d.state.OS.LxcPath)
 		if err == nil {
 			defer func() { _ = cc.Release() }()
@@ -8273,7 +8913,7 @@ func (d *lxc) statusCode() api.StatusCode {
 
 // State returns instance state.
 func (d *lxc) State() string {
-	return strings.ToUpper(d.statusCode().String())
+	return strings.ToUpper(d.renderStatusCode(d.statusCode()).String())
 }
 
 // LogFilePath log file path.
@@ -8526,9 +9166,186 @@ func (d *lxc) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error
 		out.AddSamples(metrics.ProcsTotal, metrics.Sample{Value: float64(pids)})
 	}
 
+	// Get pressure stall information
+	cgroupPressureMetrics(out, cg)
+
+	// Get number of times the process limit was hit
+	if d.state.OS.CGInfo.Supports(cgroup.Pids, cg) {
+		limitHits, err := cg.GetProcessesLimitHits()
+		if err != nil {
+			d.logger.Warn("Failed to get process limit hits", logger.Ctx{"err": err})
+		} else {
+			out.AddSamples(metrics.ProcsLimitHitsTotal, metrics.Sample{Value: float64(limitHits)})
+		}
+	}
+
+	// Get number of open file descriptors
+	fds, _, err := d.FileDescriptorsUsage()
+	if err != nil {
+		d.logger.Warn("Failed to get total number of file descriptors", logger.Ctx{"err": err})
+	} else {
+		out.AddSamples(metrics.FileDescriptors, metrics.Sample{Value: float64(fds)})
+	}
+
+	// Get GPU usage
+	for name, state := range d.gpuState() {
+		labels := map[string]string{"device": name, "pci": state.PCIAddress}
+
+		out.AddSamples(metrics.GPUUtilizationPercent, metrics.Sample{Value: float64(state.Utilization), Labels: labels})
+		out.AddSamples(metrics.GPUMemoryUsageBytes, metrics.Sample{Value: float64(state.MemoryUsage), Labels: labels})
+		out.AddSamples(metrics.GPUMemoryTotalBytes, metrics.Sample{Value: float64(state.MemoryTotal), Labels: labels})
+
+		if state.InstanceMemoryUsage >= 0 {
+			out.AddSamples(metrics.GPUInstanceMemoryUsageBytes, metrics.Sample{Value: float64(state.InstanceMemoryUsage), Labels: labels})
+		}
+	}
+
 	return out, nil
 }
 
+// VerifyLimits checks that the limits of the running container are applied to its cgroup and devices.
+func (d *lxc) VerifyLimits() ([]limitcheck.Discrepancy, error) {
+	if !d.IsRunning() {
+		return nil, ErrInstanceIsStopped
+	}
+
+	cc, err := d.initLXC(false)
+	if err != nil {
+		return nil, err
+	}
+
+	cg, err := d.cgroup(cc, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var discrepancies []limitcheck.Discrepancy
+	mismatch := func(key string, expected string, actual string) {
+		discrepancies = append(discrepancies, limitcheck.Discrepancy{Key: key, Expected: expected, Actual: actual})
+	}
+
+	// Memory limit.
+	memory := d.expandedConfig["limits.memory"]
+	if memory != "" && d.state.OS.CGInfo.Supports(cgroup.Memory, cg) {
+		var expected int64
+		if strings.HasSuffix(memory, "%") {
+			percent, err := strconv.ParseInt(strings.TrimSuffix(memory, "%"), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			memoryTotal, err := linux.DeviceTotalMemory()
+			if err != nil {
+				return nil, err
+			}
+
+			expected = int64((memoryTotal / 100) * percent)
+		} else {
+			expected, err = units.ParseByteSizeString(memory)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// The kernel rounds the limit down to a whole page.
+		pageSize := int64(os.Getpagesize())
+		expected = expected / pageSize * pageSize
+
+		var actual int64
+		if d.expandedConfig["limits.memory.enforce"] == "soft" {
+			actual, err = cg.GetMemorySoftLimit()
+		} else {
+			actual, err = cg.GetMemoryLimit()
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting memory limit: %w", err)
+		}
+
+		if actual != expected {
+			mismatch("limits.memory", fmt.Sprintf("%d", expected), limitString(actual))
+		}
+	}
+
+	// CPU allowance.
+	cpuAllowance := d.expandedConfig["limits.cpu.allowance"]
+	if cpuAllowance != "" && d.state.OS.CGInfo.Supports(cgroup.CPU, cg) {
+		_, expectedQuota, expectedPeriod, err := cgroup.ParseCPU(cpuAllowance, d.expandedConfig["limits.cpu.priority"])
+		if err != nil {
+			return nil, err
+		}
+
+		if expectedPeriod != -1 && expectedQuota != -1 {
+			period, quota, err := cg.GetCPUCfsLimit()
+			if err != nil {
+				return nil, fmt.Errorf("Failed getting CPU limit: %w", err)
+			}
+
+			if period != expectedPeriod || quota != expectedQuota {
+				mismatch("limits.cpu.allowance", fmt.Sprintf("%d/%d", expectedQuota, expectedPeriod), fmt.Sprintf("%s/%d", limitString(quota), period))
+			}
+		}
+	}
+
+	// CPU pinning, applied by the scheduler.
+	cpuLimit := d.expandedConfig["limits.cpu"]
+	if cpuLimit != "" && d.state.OS.CGInfo.Supports(cgroup.CPUSet, cg) {
+		cpuset, err := cg.GetCpuset()
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting CPU set: %w", err)
+		}
+
+		actual, err := resources.ParseCpuset(cpuset)
+		if err != nil {
+			return nil, err
+		}
+
+		count, err := strconv.Atoi(cpuLimit)
+		if err == nil {
+			if len(actual) > count {
+				mismatch("limits.cpu", cpuLimit, fmt.Sprintf("%d", len(actual)))
+			}
+		} else {
+			expected, err := resources.ParseCpuset(cpuLimit)
+			if err != nil {
+				return nil, err
+			}
+
+			slices.Sort(expected)
+			slices.Sort(actual)
+
+			if !slices.Equal(expected, actual) {
+				mismatch("limits.cpu", cpuLimit, cpuset)
+			}
+		}
+	}
+
+	// Processes limit.
+	processes := d.expandedConfig["limits.processes"]
+	if processes != "" && d.state.OS.CGInfo.Supports(cgroup.Pids, cg) {
+		expected, err := strconv.ParseInt(processes, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		actual, err := cg.GetMaxProcesses()
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting processes limit: %w", err)
+		}
+
+		if actual != expected {
+			mismatch("limits.processes", processes, limitString(actual))
+		}
+	}
+
+	devices, err := d.verifyDeviceLimits(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(discrepancies, devices...), nil
+}
+
 func (d *lxc) getFSStats() (*metrics.MetricSet, error) {
 	type mountInfo struct {
 		Mountpoint string
@@ -0,0 +1,308 @@
+package drivers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/cgroup"
+	"github.com/lxc/incus/v6/internal/server/resources"
+	"github.com/lxc/incus/v6/shared/idmap"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// cpuinfoPath returns the path of the CPU information presented to the container in place of /proc/cpuinfo.
+func (d *lxc) cpuinfoPath() string {
+	return filepath.Join(d.DevicesPath(), "proc.cpuinfo")
+}
+
+// cpuinfoHiddenFlags returns the CPU flags to hide from the container.
+func cpuinfoHiddenFlags(value string) ([]string, error) {
+	features, err := internalInstance.ParseCPUFeatures(value)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make([]string, 0, len(features))
+	for _, feature := range features {
+		if feature.Enabled {
+			return nil, fmt.Errorf("CPU feature %q can only be hidden from containers", feature.Name)
+		}
+
+		if feature.Name == internalInstance.CPUFeatureNested {
+			flags = append(flags, "vmx", "svm")
+			continue
+		}
+
+		flag, ok := cpuFeatureAliases[feature.Name]
+		if !ok {
+			flag = strings.ReplaceAll(feature.Name, "-", "_")
+		}
+
+		flags = append(flags, flag)
+	}
+
+	return flags, nil
+}
+
+// cpuinfoCount returns the number of CPUs the container is limited to by its CPU limits, or 0 if unlimited.
+func (d *lxc) cpuinfoCount() (int, error) {
+	count := 0
+
+	cpuLimit := d.expandedConfig["limits.cpu"]
+	if cpuLimit != "" {
+		cpus, err := strconv.Atoi(cpuLimit)
+		if err != nil {
+			cpuSet, err := resources.ParseCpuset(cpuLimit)
+			if err != nil {
+				return 0, err
+			}
+
+			cpus = len(cpuSet)
+		}
+
+		count = cpus
+	}
+
+	cpuAllowance := d.expandedConfig["limits.cpu.allowance"]
+	if cpuAllowance != "" && !strings.HasSuffix(cpuAllowance, "%") {
+		_, quota, period, err := cgroup.ParseCPU(cpuAllowance, "")
+		if err != nil {
+			return 0, err
+		}
+
+		if quota > 0 && period > 0 {
+			cpus := int((quota + period - 1) / period)
+			if count == 0 || cpus < count {
+				count = cpus
+			}
+		}
+	}
+
+	return count, nil
+}
+
+// cpuinfoCPUs returns the IDs of the host CPUs the container runs on, or nil if any of them.
+// This is the CPU set of the running container, as placed by the CPU scheduler, or else its pinned CPUs.
+func (d *lxc) cpuinfoCPUs() ([]int64, error) {
+	if d.InitPID() > 0 {
+		cg, err := d.CGroup()
+		if err == nil {
+			cpuset, err := cg.GetEffectiveCpuset()
+			if err == nil && cpuset != "" {
+				return resources.ParseCpuset(cpuset)
+			}
+		}
+	}
+
+	cpuLimit := d.expandedConfig["limits.cpu"]
+	if cpuLimit == "" {
+		return nil, nil
+	}
+
+	_, err := strconv.Atoi(cpuLimit)
+	if err == nil {
+		return nil, nil
+	}
+
+	return resources.ParseCpuset(cpuLimit)
+}
+
+// cpuinfoNeeded returns whether the container is presented its own CPU information, which is the case when it hides
+// CPU flags or is limited by a hard CPU allowance that LXCFS doesn't reflect. LXCFS only does so when managed by the
+// server with its CPU view enabled.
+func (d *lxc) cpuinfoNeeded() bool {
+	if d.expandedConfig["limits.cpu.features"] != "" {
+		return true
+	}
+
+	cpuAllowance := d.expandedConfig["limits.cpu.allowance"]
+	if cpuAllowance == "" || strings.HasSuffix(cpuAllowance, "%") {
+		return false
+	}
+
+	if d.state.LocalConfig != nil {
+		managed, _, cpuView := d.state.LocalConfig.LXCFS()
+		if managed && cpuView && d.lxcfsViewEnabled("proc/cpuinfo") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cpuinfoGenerate writes the CPU information presented to the container, based on the one of the host.
+// The file is rewritten in place, so that a container it's mounted in sees the new content.
+func (d *lxc) cpuinfoGenerate() error {
+	hidden, err := cpuinfoHiddenFlags(d.expandedConfig["limits.cpu.features"])
+	if err != nil {
+		return err
+	}
+
+	cpus, err := d.cpuinfoCPUs()
+	if err != nil {
+		return err
+	}
+
+	count, err := d.cpuinfoCount()
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(d.DevicesPath(), 0o711)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(d.cpuinfoPath(), []byte(cpuinfoRender(string(content), cpus, count, hidden)), 0o644)
+}
+
+// RefreshCPUInfo updates the CPU information presented to the running container, after its CPU limits or placement
+// changed. The view of LXCFS is restored once the container no longer needs its own CPU information.
+func (d *lxc) RefreshCPUInfo() error {
+	if !d.IsRunning() {
+		return nil
+	}
+
+	mounted := util.PathExists(d.cpuinfoPath())
+	needed := d.cpuinfoNeeded()
+
+	if !needed {
+		if !mounted {
+			return nil
+		}
+
+		// Unmounting the CPU information of the container reveals the view of LXCFS mounted underneath.
+		err := d.removeMount("/proc/cpuinfo")
+		if err != nil {
+			return fmt.Errorf("Failed unmounting the CPU information: %w", err)
+		}
+
+		return os.Remove(d.cpuinfoPath())
+	}
+
+	err := d.cpuinfoGenerate()
+	if err != nil {
+		return err
+	}
+
+	if mounted {
+		return nil
+	}
+
+	return d.insertMount(d.cpuinfoPath(), "/proc/cpuinfo", "none", unix.MS_BIND, idmap.IdmapStorageNone)
+}
+
+// cpuinfoRender returns the content of /proc/cpuinfo limited to the processors of the given host CPUs (all of them
+// if nil) and to the given number of them (all of them if 0), with the topology adjusted to match and the given
+// flags removed.
+func cpuinfoRender(content string, cpus []int64, count int, hidden []string) string {
+	blocks := strings.Split(strings.TrimRight(content, "\n"), "\n\n")
+
+	// Select the processors presented to the container.
+	processors := 0
+	presented := []int{}
+	for i, block := range blocks {
+		id, ok := cpuinfoProcessorID(block)
+		if !ok {
+			continue
+		}
+
+		processors++
+
+		if cpus != nil && !slices.Contains(cpus, id) {
+			continue
+		}
+
+		if count > 0 && len(presented) >= count {
+			continue
+		}
+
+		presented = append(presented, i)
+	}
+
+	limited := len(presented) < processors
+
+	rendered := make([]string, 0, len(blocks))
+	index := 0
+	for i, block := range blocks {
+		_, isProcessor := cpuinfoProcessorID(block)
+		if isProcessor && !slices.Contains(presented, i) {
+			continue
+		}
+
+		lines := strings.Split(block, "\n")
+		for j, line := range lines {
+			key, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+
+			switch strings.TrimSpace(key) {
+			case "processor":
+				if isProcessor {
+					lines[j] = fmt.Sprintf("%s: %d", key, index)
+				}
+
+			case "siblings", "cpu cores":
+				if limited {
+					lines[j] = fmt.Sprintf("%s: %d", key, len(presented))
+				}
+
+			case "core id", "apicid", "initial apicid":
+				if limited {
+					lines[j] = fmt.Sprintf("%s: %d", key, index)
+				}
+
+			case "physical id":
+				if limited {
+					lines[j] = fmt.Sprintf("%s: 0", key)
+				}
+
+			case "flags", "Features":
+				flags := strings.Fields(value)
+				flags = slices.DeleteFunc(flags, func(flag string) bool {
+					return slices.Contains(hidden, flag)
+				})
+
+				lines[j] = fmt.Sprintf("%s: %s", key, strings.Join(flags, " "))
+			}
+		}
+
+		if isProcessor {
+			index++
+		}
+
+		rendered = append(rendered, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(rendered, "\n\n") + "\n"
+}
+
+// cpuinfoProcessorID returns the host CPU ID of a processor block of /proc/cpuinfo.
+func cpuinfoProcessorID(block string) (int64, bool) {
+	if !strings.HasPrefix(block, "processor") {
+		return 0, false
+	}
+
+	line, _, _ := strings.Cut(block, "\n")
+	_, value, _ := strings.Cut(line, ":")
+
+	id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,97 @@
+package drivers
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCpuinfoRender(t *testing.T) {
+	processor := func(id int, physicalID int, coreID int) string {
+		return strings.Join([]string{
+			"processor\t: " + strconv.Itoa(id),
+			"model name\t: Test CPU",
+			"physical id\t: " + strconv.Itoa(physicalID),
+			"siblings\t: 4",
+			"core id\t\t: " + strconv.Itoa(coreID),
+			"cpu cores\t: 4",
+			"apicid\t\t: " + strconv.Itoa(id),
+			"flags\t\t: fpu avx avx512f vmx",
+		}, "\n")
+	}
+
+	host := strings.Join([]string{processor(0, 0, 0), processor(1, 0, 1), processor(2, 1, 0), processor(3, 1, 1)}, "\n\n") + "\n"
+
+	tests := []struct {
+		name       string
+		cpus       []int64
+		count      int
+		hidden     []string
+		processors []string
+		lines      []string
+	}{
+		{
+			name:       "Unlimited",
+			processors: []string{"Test CPU", "Test CPU", "Test CPU", "Test CPU"},
+			lines:      []string{"processor\t: 3", "physical id\t: 1", "siblings\t: 4", "flags\t\t: fpu avx avx512f vmx"},
+		},
+		{
+			name:       "Hidden flags",
+			hidden:     []string{"avx512f", "vmx"},
+			processors: []string{"Test CPU", "Test CPU", "Test CPU", "Test CPU"},
+			lines:      []string{"flags\t\t: fpu avx"},
+		},
+		{
+			name:       "Count",
+			count:      2,
+			processors: []string{"Test CPU", "Test CPU"},
+			lines:      []string{"processor\t: 1", "siblings\t: 2", "cpu cores\t: 2", "physical id\t: 0"},
+		},
+		{
+			name:       "Pinned CPUs",
+			cpus:       []int64{2, 3},
+			processors: []string{"Test CPU", "Test CPU"},
+			lines:      []string{"processor\t: 0", "processor\t: 1", "physical id\t: 0", "apicid\t\t: 1"},
+		},
+		{
+			name:       "Pinned CPUs and count",
+			cpus:       []int64{1, 3},
+			count:      1,
+			processors: []string{"Test CPU"},
+			lines:      []string{"processor\t: 0", "siblings\t: 1"},
+		},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		rendered := cpuinfoRender(host, tt.cpus, tt.count, tt.hidden)
+		require.Equal(t, len(tt.processors), strings.Count(rendered, "model name"))
+		require.True(t, strings.HasSuffix(rendered, "\n"))
+
+		for _, line := range tt.lines {
+			require.Contains(t, strings.Split(rendered, "\n"), line)
+		}
+	}
+}
+
+func TestCpuinfoRender_Pinned(t *testing.T) {
+	host := "processor\t: 0\nmodel name\t: CPU0\n\nprocessor\t: 1\nmodel name\t: CPU1\n\nprocessor\t: 2\nmodel name\t: CPU2\n"
+
+	// The processors of the pinned host CPUs are presented, not the first ones of the host.
+	rendered := cpuinfoRender(host, []int64{2}, 0, nil)
+	require.Equal(t, "processor\t: 0\nmodel name\t: CPU2\n", rendered)
+}
+
+func TestCpuinfoHiddenFlags(t *testing.T) {
+	hidden, err := cpuinfoHiddenFlags("-avx512f,-nested")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"avx512f", "vmx", "svm"}, hidden)
+
+	hidden, err = cpuinfoHiddenFlags("")
+	require.NoError(t, err)
+	require.Empty(t, hidden)
+}
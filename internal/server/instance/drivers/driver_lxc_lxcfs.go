@@ -0,0 +1,80 @@
+package drivers
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/server/lxcfs"
+	"github.com/lxc/incus/v6/shared/idmap"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// lxcfsOptionalViews are the LXCFS views which can be disabled per container, relative to its root.
+var lxcfsOptionalViews = []string{"proc/cpuinfo", "proc/loadavg", "proc/stat", "sys/devices/system/cpu/online"}
+
+// lxcfsViewEnabled returns whether the container is presented the given LXCFS view.
+func (d *lxc) lxcfsViewEnabled(view string) bool {
+	switch view {
+	case "proc/loadavg":
+		return util.IsTrueOrEmpty(d.expandedConfig["lxcfs.loadavg"])
+	case "proc/cpuinfo", "proc/stat", "sys/devices/system/cpu/online":
+		return util.IsTrueOrEmpty(d.expandedConfig["lxcfs.cpuview"])
+	}
+
+	return true
+}
+
+// lxcfsManaged returns whether LXCFS is managed by the server, which then mounts its views in the containers rather
+// than the LXCFS hook of LXC.
+func (d *lxc) lxcfsManaged() bool {
+	if d.state.LocalConfig == nil {
+		return false
+	}
+
+	managed, _, _ := d.state.LocalConfig.LXCFS()
+
+	return managed
+}
+
+// lxcfsMountedViews returns the LXCFS views to mount in the container, relative to its root.
+func (d *lxc) lxcfsMountedViews() []string {
+	views := []string{}
+	for _, view := range lxcfs.Views() {
+		if !d.lxcfsViewEnabled(view) {
+			continue
+		}
+
+		views = append(views, view)
+	}
+
+	return views
+}
+
+// RemountLXCFS mounts the views of LXCFS in the running container again, replacing the ones left behind by an
+// LXCFS which is no longer running.
+func (d *lxc) RemountLXCFS() error {
+	if !d.IsRunning() {
+		return nil
+	}
+
+	for _, view := range d.lxcfsMountedViews() {
+		target := "/" + view
+
+		// The CPU information of the container is mounted over the view, unmounting it would reveal the stale one.
+		if view == "proc/cpuinfo" && d.cpuinfoNeeded() {
+			continue
+		}
+
+		// The view may not be mounted, if LXCFS wasn't running when the container started.
+		_ = d.removeMount(target)
+
+		err := d.insertMount(filepath.Join(lxcfs.Path, view), target, "none", unix.MS_BIND, idmap.IdmapStorageNone)
+		if err != nil {
+			return fmt.Errorf("Failed mounting %q: %w", target, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,495 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/server/instance/operationlock"
+	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
+	"github.com/lxc/incus/v6/shared/idmap"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// lxcRemapState records the progress of a container filesystem remap so that it can be resumed.
+type lxcRemapState struct {
+	// On-disk ID map being reverted and ID map being applied.
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Remap step in progress, either "unshift" or "shift".
+	Step string `json:"step"`
+
+	// Path being remapped, relative to the root filesystem, along with its owner before being remapped.
+	// The paths walked before it are already remapped.
+	Path string `json:"path"`
+	UID  int64  `json:"uid"`
+	GID  int64  `json:"gid"`
+
+	// Extended attributes of the path being remapped holding IDs, which must be restored before being shifted
+	// again if the remap gets interrupted after the owner change as that drops the file capabilities.
+	Xattrs map[string][]byte `json:"xattrs,omitempty"`
+}
+
+// remapXattrs lists the extended attributes shifted along with the owner of the paths.
+var remapXattrs = []string{"system.posix_acl_access", "system.posix_acl_default", "security.capability"}
+
+// Steps of a remap.
+const (
+	lxcRemapUnshift = "unshift"
+	lxcRemapShift   = "shift"
+)
+
+// remapPathBefore returns whether the filesystem walk of the shifter visits path a before path b.
+// Both paths are relative to the walked directory, which is itself ".".
+func remapPathBefore(a string, b string) bool {
+	if a == b || b == "." {
+		return false
+	}
+
+	if a == "." {
+		return true
+	}
+
+	aParts := strings.Split(a, "/")
+	bParts := strings.Split(b, "/")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if aParts[i] != bParts[i] {
+			return aParts[i] < bParts[i]
+		}
+	}
+
+	// Directories are visited before their content.
+	return len(aParts) < len(bParts)
+}
+
+// remapPathDone returns whether the path, currently owned by the given IDs, was already remapped by the step
+// recorded in the state. The path being remapped when the step got interrupted only was if its owner changed.
+func remapPathDone(state lxcRemapState, path string, uid int64, gid int64) bool {
+	if state.Path == "" {
+		return false
+	}
+
+	if path == state.Path {
+		return uid != state.UID || gid != state.GID
+	}
+
+	return remapPathBefore(path, state.Path)
+}
+
+// remapSyncInterval is the number of paths whose progress is recorded before it's synced to disk.
+const remapSyncInterval = 1000
+
+// remapProgress records the progress of a remap in a file kept open for the whole remap.
+// The state is rewritten in place for every path, which keeps it accurate if the remap gets interrupted, but it's
+// only synced to disk every remapSyncInterval paths and at the end of each step.
+type remapProgress struct {
+	f        *os.File
+	unsynced int
+}
+
+// remapOpenProgress opens the remap progress file, creating it if needed.
+func remapOpenProgress(path string) (*remapProgress, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &remapProgress{f: f}, nil
+}
+
+// Save replaces the recorded state, syncing it to disk if requested or if enough paths were recorded since the
+// last sync.
+func (p *remapProgress) Save(state lxcRemapState, sync bool) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	// A previous state that is longer is only truncated after the new one is written, remapLoadState ignores what
+	// follows the state if that doesn't happen.
+	_, err = p.f.WriteAt(data, 0)
+	if err != nil {
+		return err
+	}
+
+	err = p.f.Truncate(int64(len(data)))
+	if err != nil {
+		return err
+	}
+
+	p.unsynced++
+	if !sync && p.unsynced < remapSyncInterval {
+		return nil
+	}
+
+	p.unsynced = 0
+
+	return p.f.Sync()
+}
+
+// Close closes the remap progress file.
+func (p *remapProgress) Close() error {
+	return p.f.Close()
+}
+
+// remapLoadState parses the content of a remap progress file.
+func remapLoadState(content []byte) (lxcRemapState, error) {
+	state := lxcRemapState{}
+
+	err := json.NewDecoder(bytes.NewReader(content)).Decode(&state)
+	if err != nil {
+		return lxcRemapState{}, err
+	}
+
+	return state, nil
+}
+
+// remapGetXattrs returns the extended attributes of the path which are shifted along with its owner.
+func remapGetXattrs(path string) (map[string][]byte, error) {
+	xattrs := map[string][]byte{}
+
+	for _, name := range remapXattrs {
+		size, err := unix.Lgetxattr(path, name, nil)
+		if err != nil {
+			if errors.Is(err, unix.ENODATA) || errors.Is(err, unix.ENOTSUP) {
+				continue
+			}
+
+			return nil, fmt.Errorf("Failed getting %q of %q: %w", name, path, err)
+		}
+
+		value := make([]byte, size)
+		size, err = unix.Lgetxattr(path, name, value)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting %q of %q: %w", name, path, err)
+		}
+
+		xattrs[name] = value[:size]
+	}
+
+	return xattrs, nil
+}
+
+// remapRestoreXattrs puts back the extended attributes recorded before the owner of the path got remapped and
+// shifts them again, as the remap may have been interrupted before they were.
+func remapRestoreXattrs(set *idmap.Set, out bool, path string, xattrs map[string][]byte) error {
+	hasACL := false
+	for _, name := range remapXattrs[:2] {
+		value, ok := xattrs[name]
+		if !ok {
+			continue
+		}
+
+		err := unix.Lsetxattr(path, name, value, 0)
+		if err != nil {
+			return fmt.Errorf("Failed restoring %q of %q: %w", name, path, err)
+		}
+
+		hasACL = true
+	}
+
+	if hasACL {
+		err := idmap.ShiftACL(path, func(uid int64, gid int64) (int64, int64) {
+			if out {
+				return set.ShiftFromNS(uid, gid)
+			}
+
+			return set.ShiftIntoNS(uid, gid)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	caps, ok := xattrs["security.capability"]
+	if !ok {
+		return nil
+	}
+
+	// Same as the shifter, the capabilities are only namespaced when supported.
+	rootUID := int64(0)
+	if !out {
+		if atomic.LoadInt32(&idmap.VFS3FSCaps) != idmap.VFS3FSCapsSupported {
+			return nil
+		}
+
+		rootUID, _ = set.ShiftIntoNS(0, 0)
+	}
+
+	return idmap.SetCaps(path, caps, rootUID)
+}
+
+// errRemapSkip makes the shifter leave a path alone.
+var errRemapSkip = errors.New("Skipped")
+
+// remapStatePath returns the path of the file tracking the progress of a filesystem remap.
+func (d *lxc) remapStatePath() string {
+	return filepath.Join(d.Path(), "remap.json")
+}
+
+// Remap shifts the root filesystem of the stopped container from its on-disk ID map to its next ID map.
+// The progress is recorded for every path so that an interrupted remap carries on where it stopped when run again.
+func (d *lxc) Remap() error {
+	op, err := operationlock.Create(d.Project().Name, d.Name(), operationlock.ActionUpdate, false, false)
+	if err != nil {
+		return fmt.Errorf("Failed to create instance remap operation: %w", err)
+	}
+
+	defer op.Done(nil)
+
+	if d.IsSnapshot() {
+		return fmt.Errorf("Snapshots can't be remapped")
+	}
+
+	if d.IsRunning() {
+		return fmt.Errorf("The container must be stopped to be remapped")
+	}
+
+	if util.IsTrue(d.expandedConfig["security.protection.shift"]) {
+		return fmt.Errorf("Container is protected against filesystem shifting")
+	}
+
+	diskIdmap, err := d.DiskIdmap()
+	if err != nil {
+		return fmt.Errorf("Failed getting on-disk ID map: %w", err)
+	}
+
+	nextIdmap, err := d.NextIdmap()
+	if err != nil {
+		return fmt.Errorf("Failed getting next ID map: %w", err)
+	}
+
+	fromJSON, err := diskIdmap.ToJSON()
+	if err != nil {
+		return err
+	}
+
+	toJSON, err := nextIdmap.ToJSON()
+	if err != nil {
+		return err
+	}
+
+	state := lxcRemapState{From: fromJSON, To: toJSON, Step: lxcRemapUnshift}
+
+	content, err := os.ReadFile(d.remapStatePath())
+	if err == nil {
+		pending, err := remapLoadState(content)
+		if err != nil {
+			return fmt.Errorf("Failed parsing remap progress: %w", err)
+		}
+
+		// The remap completed but the progress file couldn't be removed.
+		if pending.From != state.From && pending.To == state.To && (state.From == pending.To || state.From == "[]") {
+			return os.Remove(d.remapStatePath())
+		}
+
+		if pending.From != state.From || pending.To != state.To {
+			return fmt.Errorf("An interrupted remap towards a different ID map is pending, restore the previous configuration to resume it")
+		}
+
+		d.logger.Info("Resuming interrupted remap", logger.Ctx{"step": pending.Step, "path": pending.Path})
+		state = pending
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("Failed reading remap progress: %w", err)
+	} else if nextIdmap.Equals(diskIdmap) {
+		// Identical on-disk idmaps so no changes required.
+		return nil
+	}
+
+	_, err = d.mount()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = d.unmount() }()
+
+	// The progress is recorded before remapping every path.
+	progressFile, err := remapOpenProgress(d.remapStatePath())
+	if err != nil {
+		return fmt.Errorf("Failed opening remap progress: %w", err)
+	}
+
+	defer func() { _ = progressFile.Close() }()
+
+	err = progressFile.Save(state, true)
+	if err != nil {
+		return fmt.Errorf("Failed recording remap progress: %w", err)
+	}
+
+	storageType, err := d.getStorageType()
+	if err != nil {
+		return fmt.Errorf("Storage type: %w", err)
+	}
+
+	rootfs := d.RootfsPath()
+
+	// Read-only BTRFS subvolumes must be made writable for the duration of the remap.
+	if storageType == "btrfs" {
+		roSubvols := []string{}
+		subvols, _ := storageDrivers.BTRFSSubVolumesGet(rootfs)
+		for _, subvol := range subvols {
+			subvol = filepath.Join(rootfs, subvol)
+			if !storageDrivers.BTRFSSubVolumeIsRo(subvol) {
+				continue
+			}
+
+			roSubvols = append(roSubvols, subvol)
+			_ = storageDrivers.BTRFSSubVolumeMakeRw(subvol)
+		}
+
+		defer func() {
+			for _, subvol := range roSubvols {
+				_ = storageDrivers.BTRFSSubVolumeMakeRo(subvol)
+			}
+		}()
+	}
+
+	// The progress is reported as the share of the top-level entries of the root filesystem walked through.
+	entries, err := os.ReadDir(rootfs)
+	if err != nil {
+		return fmt.Errorf("Failed listing the root filesystem: %w", err)
+	}
+
+	entryIndex := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		entryIndex[entry.Name()] = i
+	}
+
+	// If the container can use idmapped storage once unshifted, leave its filesystem unshifted.
+	idmapType := idmap.IdmapStorageType(idmap.IdmapStorageNone)
+	if nextIdmap != nil {
+		idmapType = d.IdmappedStorage(rootfs, "none")
+	}
+
+	remapStep := func(set *idmap.Set, step string, out bool) error {
+		progress := -1
+		var stepErr error
+
+		skipper := func(dir string, absPath string, fi os.FileInfo, newuid int64, newgid int64) error {
+			path, err := filepath.Rel(dir, absPath)
+			if err != nil {
+				return err
+			}
+
+			if storageType == "zfs" && path == ".zfs" {
+				return filepath.SkipDir
+			}
+
+			top, _, _ := strings.Cut(path, "/")
+			index, ok := entryIndex[top]
+			if ok && index != progress {
+				progress = index
+				d.updateProgress(fmt.Sprintf("Remapping container filesystem (%s): %d%%", step, index*100/len(entries)))
+			}
+
+			var uid, gid int64
+			stat, ok := fi.Sys().(*syscall.Stat_t)
+			if ok {
+				uid = int64(stat.Uid)
+				gid = int64(stat.Gid)
+			}
+
+			isSymlink := fi.Mode()&os.ModeSymlink != 0
+
+			if remapPathDone(state, path, uid, gid) {
+				// The remap was interrupted after changing the owner of the path, finish remapping it.
+				if path == state.Path && !isSymlink {
+					stepErr = remapRestoreXattrs(set, out, absPath, state.Xattrs)
+					if stepErr != nil {
+						return filepath.SkipAll
+					}
+				}
+
+				// The content of directories that were entirely remapped is still walked through so that the
+				// shifter sees all the hardlinks and doesn't remap their files a second time.
+				return errRemapSkip
+			}
+
+			// Record the owner and extended attributes of the path before remapping it.
+			state.Path = path
+			state.UID = uid
+			state.GID = gid
+			state.Xattrs = nil
+
+			if !isSymlink {
+				state.Xattrs, stepErr = remapGetXattrs(absPath)
+				if stepErr != nil {
+					return filepath.SkipAll
+				}
+			}
+
+			stepErr = progressFile.Save(state, false)
+			if stepErr != nil {
+				stepErr = fmt.Errorf("Failed recording remap progress: %w", stepErr)
+				return filepath.SkipAll
+			}
+
+			return nil
+		}
+
+		if out {
+			err = set.UnshiftPath(rootfs, skipper)
+		} else {
+			err = set.ShiftPath(rootfs, skipper)
+		}
+
+		if stepErr != nil {
+			return stepErr
+		}
+
+		return err
+	}
+
+	if state.Step == lxcRemapUnshift {
+		if diskIdmap != nil {
+			err = remapStep(diskIdmap, lxcRemapUnshift, true)
+			if err != nil {
+				return fmt.Errorf("Failed unshifting the root filesystem: %w", err)
+			}
+		}
+
+		state = lxcRemapState{From: state.From, To: state.To, Step: lxcRemapShift}
+		err = progressFile.Save(state, true)
+		if err != nil {
+			return fmt.Errorf("Failed recording remap progress: %w", err)
+		}
+	}
+
+	if nextIdmap != nil && idmapType == idmap.IdmapStorageNone {
+		err = remapStep(nextIdmap, lxcRemapShift, false)
+		if err != nil {
+			return fmt.Errorf("Failed shifting the root filesystem: %w", err)
+		}
+	}
+
+	jsonDiskIdmap := "[]"
+	if nextIdmap != nil && idmapType == idmap.IdmapStorageNone {
+		jsonDiskIdmap = toJSON
+	}
+
+	err = d.VolatileSet(map[string]string{"volatile.last_state.idmap": jsonDiskIdmap})
+	if err != nil {
+		return fmt.Errorf("Failed setting volatile.last_state.idmap: %w", err)
+	}
+
+	_ = progressFile.Close()
+
+	err = os.Remove(d.remapStatePath())
+	if err != nil {
+		return fmt.Errorf("Failed removing remap progress: %w", err)
+	}
+
+	d.updateProgress("")
+
+	return nil
+}
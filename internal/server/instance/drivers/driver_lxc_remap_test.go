@@ -0,0 +1,107 @@
+package drivers
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemapPathBefore(t *testing.T) {
+	tests := []struct {
+		name   string
+		a      string
+		b      string
+		before bool
+	}{
+		{"Root before entry", ".", "bin", true},
+		{"Entry after root", "bin", ".", false},
+		{"Same path", "etc/hosts", "etc/hosts", false},
+		{"Sibling entries", "bin", "etc", true},
+		{"Directory before its content", "etc", "etc/hosts", true},
+		{"Content after its directory", "etc/hosts", "etc", false},
+		{"Content before the next sibling", "etc/hosts", "home", true},
+		{"Directory content before a longer sibling name", "a/b", "a.b", true},
+		{"Longer sibling name after directory content", "a.b", "a/z", false},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		require.Equal(t, tt.before, remapPathBefore(tt.a, tt.b))
+	}
+}
+
+func TestRemapPathDone(t *testing.T) {
+	state := lxcRemapState{Step: lxcRemapShift, Path: "etc/hosts", UID: 0, GID: 0}
+
+	tests := []struct {
+		name string
+		path string
+		uid  int64
+		gid  int64
+		done bool
+	}{
+		{"Root", ".", 1000000, 1000000, true},
+		{"Parent of the interrupted path", "etc", 1000000, 1000000, true},
+		{"Earlier path", "bin/sh", 1000000, 1000000, true},
+		{"Interrupted path with changed owner", "etc/hosts", 1000000, 1000000, true},
+		{"Interrupted path with changed group", "etc/hosts", 0, 1000000, true},
+		{"Interrupted path with its previous owner", "etc/hosts", 0, 0, false},
+		{"Later path", "etc/passwd", 0, 0, false},
+		{"Later entry", "usr", 0, 0, false},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		require.Equal(t, tt.done, remapPathDone(state, tt.path, tt.uid, tt.gid))
+	}
+
+	// Nothing is done at the start of a step.
+	require.False(t, remapPathDone(lxcRemapState{Step: lxcRemapUnshift}, ".", 0, 0))
+}
+
+func TestRemapProgress(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "remap.json")
+
+	progress, err := remapOpenProgress(path)
+	require.NoError(t, err)
+
+	defer func() { _ = progress.Close() }()
+
+	states := []lxcRemapState{
+		{From: "[]", To: "[]", Step: lxcRemapUnshift, Path: "usr/bin/ping", UID: 0, GID: 0, Xattrs: map[string][]byte{"security.capability": {1, 0, 0, 2}}},
+		{From: "[]", To: "[]", Step: lxcRemapUnshift, Path: "usr/bin/su", UID: 0, GID: 0},
+		{From: "[]", To: "[]", Step: lxcRemapShift},
+	}
+
+	for i, state := range states {
+		require.NoError(t, progress.Save(state, i == len(states)-1))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		saved, err := remapLoadState(content)
+		require.NoError(t, err)
+		require.Equal(t, state, saved)
+	}
+
+	// The state is rewritten in place.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// What follows the state when the file couldn't be truncated is ignored.
+	data, err := json.Marshal(states[2])
+	require.NoError(t, err)
+
+	saved, err := remapLoadState(append(data, []byte(`s/bin/su","uid":0,"gid":0}`)...))
+	require.NoError(t, err)
+	require.Equal(t, states[2], saved)
+
+	_, err = remapLoadState([]byte(`{"from":`))
+	require.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package drivers
+
+import (
+	"github.com/lxc/incus/v6/internal/server/cgroup"
+	"github.com/lxc/incus/v6/internal/server/metrics"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// cgroupPressureState returns the pressure stall information of the given instance cgroup, if available.
+func cgroupPressureState(cg *cgroup.CGroup) map[string]api.InstanceStatePressure {
+	pressure := map[string]api.InstanceStatePressure{}
+	for _, resource := range cgroup.PressureResources {
+		value, err := cg.GetPressure(resource)
+		if err != nil {
+			continue
+		}
+
+		pressure[resource] = api.InstanceStatePressure{
+			SomeAvg10:  value.Some.Avg10,
+			SomeAvg60:  value.Some.Avg60,
+			SomeAvg300: value.Some.Avg300,
+			SomeTotal:  value.Some.Total,
+			FullAvg10:  value.Full.Avg10,
+			FullAvg60:  value.Full.Avg60,
+			FullAvg300: value.Full.Avg300,
+			FullTotal:  value.Full.Total,
+		}
+	}
+
+	if len(pressure) == 0 {
+		return nil
+	}
+
+	return pressure
+}
+
+// cgroupPressureMetrics adds the total stall time of the given instance cgroup to the metrics, if available.
+func cgroupPressureMetrics(out *metrics.MetricSet, cg *cgroup.CGroup) {
+	for _, resource := range cgroup.PressureResources {
+		pressure, err := cg.GetPressure(resource)
+		if err != nil {
+			continue
+		}
+
+		out.AddSamples(metrics.PressureStalledSecondsTotal, metrics.Sample{Value: float64(pressure.Some.Total) / 1000000, Labels: map[string]string{"resource": resource, "kind": "some"}})
+		out.AddSamples(metrics.PressureStalledSecondsTotal, metrics.Sample{Value: float64(pressure.Full.Total) / 1000000, Labels: map[string]string{"resource": resource, "kind": "full"}})
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/kballard/go-shellquote"
 	"github.com/mdlayher/vsock"
 	"github.com/pkg/sftp"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sys/unix"
 	"google.golang.org/protobuf/proto"
@@ -44,17 +45,20 @@ import (
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/instancewriter"
 	"github.com/lxc/incus/v6/internal/jmap"
+	"github.com/lxc/incus/v6/internal/limitcheck"
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/migration"
 	"github.com/lxc/incus/v6/internal/ports"
 	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/apparmor"
 	"github.com/lxc/incus/v6/internal/server/cgroup"
+	"github.com/lxc/incus/v6/internal/server/coredump"
 	"github.com/lxc/incus/v6/internal/server/db"
 	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/device"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/device/nictype"
+	"github.com/lxc/incus/v6/internal/server/hugepages"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/drivers/qmp"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
@@ -71,6 +75,7 @@ import (
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
 	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
 	pongoTemplate "github.com/lxc/incus/v6/internal/server/template"
+	"github.com/lxc/incus/v6/internal/server/tracing"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	localvsock "github.com/lxc/incus/v6/internal/server/vsock"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
@@ -85,6 +90,9 @@ import (
 	"github.com/lxc/incus/v6/shared/util"
 )
 
+// qemuLogger is the logger of the qemu subsystem.
+var qemuLogger = logger.Subsystem("qemu")
+
 // incus-agent files
 //
 //go:embed agent-loader/*
@@ -190,7 +198,7 @@ func qemuInstantiate(s *state.State, args db.InstanceArgs, expandedDevices devic
 			lastUsedDate: args.LastUsedDate,
 			localConfig:  args.Config,
 			localDevices: args.Devices,
-			logger:       logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
+			logger:       qemuLogger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
 			name:         args.Name,
 			node:         args.Node,
 			profiles:     args.Profiles,
@@ -248,7 +256,7 @@ func qemuCreate(s *state.State, args db.InstanceArgs, p api.Project) (instance.I
 			lastUsedDate: args.LastUsedDate,
 			localConfig:  args.Config,
 			localDevices: args.Devices,
-			logger:       logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
+			logger:       qemuLogger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
 			name:         args.Name,
 			node:         args.Node,
 			profiles:     args.Profiles,
@@ -295,6 +303,13 @@ func qemuCreate(s *state.State, args db.InstanceArgs, p api.Project) (instance.I
 		return nil, nil, fmt.Errorf("Invalid config: %w", err)
 	}
 
+	if !d.IsSnapshot() {
+		err = instance.ValidDependencies(s, d.project.Name, d.name, d.expandedConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid config: %w", err)
+		}
+	}
+
 	err = instance.ValidDevices(s, d.project, d.Type(), d.localDevices, d.expandedDevices)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Invalid devices: %w", err)
@@ -354,7 +369,7 @@ func qemuCreate(s *state.State, args db.InstanceArgs, p api.Project) (instance.I
 	} else {
 		err = d.state.Authorizer.AddInstance(d.state.ShutdownCtx, d.project.Name, d.Name())
 		if err != nil {
-			logger.Error("Failed to add instance to authorizer", logger.Ctx{"name": d.Name(), "project": d.project.Name, "error": err})
+			d.logger.Error("Failed to add instance to authorizer", logger.Ctx{"name": d.Name(), "project": d.project.Name, "error": err})
 		}
 
 		revert.Add(func() { d.state.Authorizer.DeleteInstance(d.state.ShutdownCtx, d.project.Name, d.Name()) })
@@ -426,7 +441,7 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 	state := d.state
 
 	return func(event string, data map[string]any) {
-		if !slices.Contains([]string{qmp.EventVMShutdown, qmp.EventAgentStarted}, event) {
+		if !slices.Contains([]string{qmp.EventVMShutdown, qmp.EventAgentStarted, qmp.EventGuestPanicked}, event) {
 			return // Don't bother loading the instance from DB if we aren't going to handle the event.
 		}
 
@@ -437,7 +452,7 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 		if inst == nil {
 			inst, err = instance.LoadByProjectAndName(state, instProject.Name, instanceName)
 			if err != nil {
-				l := logger.AddContext(logger.Ctx{"project": instProject.Name, "instance": instanceName})
+				l := qemuLogger.AddContext(logger.Ctx{"project": instProject.Name, "instance": instanceName})
 				// If DB not available, try loading from backup file.
 				l.Warn("Failed loading instance from database to handle monitor event, trying backup file", logger.Ctx{"err": err})
 
@@ -459,6 +474,22 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 				d.logger.Warn("Failed to advertise vsock address to instance agent", logger.Ctx{"err": err})
 				return
 			}
+
+			// Install the SSH keys from the configuration now that the agent is reachable.
+			if len(instance.SSHAuthorizedKeys(d)) > 0 {
+				err = instance.SyncSSHAuthorizedKeys(d)
+				if err != nil {
+					d.logger.Warn("Failed installing SSH keys", logger.Ctx{"err": err})
+				}
+			}
+		} else if event == qmp.EventGuestPanicked {
+			d.logger.Warn("Instance guest kernel panicked")
+
+			err = d.captureCrashDump()
+			if err != nil {
+				d.logger.Error("Failed capturing guest crash dump", logger.Ctx{"err": err})
+				return
+			}
 		} else if event == qmp.EventVMShutdown {
 			target := "stop"
 			entry, ok := data["reason"]
@@ -477,10 +508,21 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 				d.logger.Error("Failed to cleanly stop instance", logger.Ctx{"err": err})
 				return
 			}
+
+			// Restart the instance if the QEMU process died or the guest crashed.
+			if qemuShutdownFailed(entry) {
+				autoRestart(d.state, d)
+			}
 		}
 	}
 }
 
+// qemuShutdownFailed returns whether the reason of a shutdown event means that the virtual machine failed,
+// because its QEMU process died or its guest kernel panicked, rather than being shut down from inside.
+func qemuShutdownFailed(reason any) bool {
+	return reason == qmp.EventVMShutdownReasonDisconnect || reason == "guest-panic"
+}
+
 // mount the instance's config volume if needed.
 func (d *qemu) mount() (*storagePools.MountInfo, error) {
 	var pool storagePools.Pool
@@ -650,6 +692,9 @@ func (d *qemu) onStop(target string) error {
 
 	// Cleanup.
 	d.cleanupDevices() // Must be called before unmount.
+	d.restoreCPUPower()
+	d.releaseHugepages()
+	_ = cgroup.RemoveFromInstancesParent(d.instancesParentCgroup())
 	_ = os.Remove(d.pidFilePath())
 	_ = os.Remove(d.monitorPath())
 
@@ -726,6 +771,11 @@ func (d *qemu) Shutdown(timeout time.Duration) error {
 		return err
 	}
 
+	// Steer the new connections of the network load balancers away from the instance, holding the operation
+	// lock so that the instance can't be started or stopped meanwhile.
+	undrain := d.drainConnections()
+	defer undrain()
+
 	// If frozen, resume so the signal can be handled.
 	if d.IsFrozen() {
 		err := d.Unfreeze()
@@ -1142,14 +1192,17 @@ func (d *qemu) Start(stateful bool) error {
 }
 
 // start starts the instance and can use an existing InstanceOperation lock.
-func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
+func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) (err error) {
 	d.logger.Debug("Start started", logger.Ctx{"stateful": stateful})
 	defer d.logger.Debug("Start finished", logger.Ctx{"stateful": stateful})
 
+	traceCtx, span := d.traceStart("qemu.Start", attribute.Bool("incus.stateful", stateful))
+	defer func() { tracing.End(span, err) }()
+
 	// Check that we are startable before creating an operation lock.
 	// Must happen before creating operation Start lock to avoid the status check returning Stopped due to the
 	// existence of a Start operation lock.
-	err := d.validateStartup(stateful, d.statusCode())
+	err = d.validateStartup(stateful, d.statusCode())
 	if err != nil {
 		return err
 	}
@@ -1169,9 +1222,14 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 
 	defer op.Done(err)
 
-	// Assign a NUMA node if needed.
+	// Assign a NUMA node if needed, with enough free huge pages unless the pools can be grown.
 	if d.expandedConfig["limits.cpu.nodes"] == "balanced" {
-		err := d.setNUMANode()
+		var fits func(node uint64) bool
+		if util.IsTrue(d.expandedConfig["limits.memory.hugepages"]) && !d.state.GlobalConfig.InstancesHugepagesDynamic() {
+			fits = d.hugepagesFit
+		}
+
+		err := d.setNUMANode(fits)
 		if err != nil {
 			op.Done(err)
 			return err
@@ -1199,6 +1257,13 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
+	// Only keep the console output of the current boot.
+	err = os.Remove(d.ConsoleBufferLogPath())
+	if err != nil && !os.IsNotExist(err) {
+		op.Done(err)
+		return err
+	}
+
 	// Remove old pid file if needed.
 	if util.PathExists(d.pidFilePath()) {
 		err = os.Remove(d.pidFilePath())
@@ -1438,13 +1503,30 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		return err
 	}
 
+	// Determine the CPU model.
+	cpuModel := d.expandedConfig["limits.cpu.model"]
+	if cpuModel == "" {
+		cpuModel = "host"
+	}
+
+	// Check the CPU model against the ones supported by QEMU on this host.
+	if cpuModel != "host" {
+		info := DriverStatuses()[instancetype.VM].Info
+		models, _ := info.Features["cpu_models"].(map[string][]string)
+		err = qemuCPUModelCheck(cpuModel, models)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+	}
+
 	// Determine additional CPU flags.
 	cpuExtensions := []string{}
 
 	if d.architecture == osarch.ARCH_64BIT_INTEL_X86 {
 		// If using Linux 5.10 or later, use HyperV optimizations.
 		minVer, _ := version.NewDottedVersion("5.10.0")
-		if d.state.OS.KernelVersion.Compare(minVer) >= 0 && util.IsFalseOrEmpty(d.expandedConfig["migration.stateful"]) {
+		if cpuModel == "host" && d.state.OS.KernelVersion.Compare(minVer) >= 0 && util.IsFalseOrEmpty(d.expandedConfig["migration.stateful"]) {
 			// x86_64 can use hv_time to improve Windows guest performance.
 			cpuExtensions = append(cpuExtensions, "hv_passthrough")
 		}
@@ -1455,7 +1537,28 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
-	cpuType := "host"
+	// Apply the user requested CPU features.
+	cpuFeatures, err := d.cpuFeatures(d.expandedConfig["limits.cpu.features"])
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
+	cpuExtensions = append(cpuExtensions, cpuFeatures...)
+
+	// Make sure enough huge pages are available to back the memory.
+	var hugepagesAllocation *hugepages.Allocation
+	if util.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		hugepagesAllocation, err = d.reserveHugepages()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		revert.Add(func() { d.revertHugepages(hugepagesAllocation) })
+	}
+
+	cpuType := cpuModel
 	if len(cpuExtensions) > 0 {
 		cpuType += "," + strings.Join(cpuExtensions, ",")
 	}
@@ -1467,13 +1570,19 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		return err
 	}
 
+	// The SMBIOS UUID defaults to the instance UUID.
+	smbiosUUID := instUUID
+	if d.expandedConfig["smbios.uuid"] != "" {
+		smbiosUUID = d.expandedConfig["smbios.uuid"]
+	}
+
 	// Start QEMU.
 	qemuCmd := []string{
 		"--",
 		qemuPath,
 		"-S",
 		"-name", d.Name(),
-		"-uuid", instUUID,
+		"-uuid", smbiosUUID,
 		"-daemonize",
 		"-cpu", cpuType,
 		"-nographic",
@@ -1516,6 +1625,15 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	// SMBIOS only on x86_64 and aarch64.
 	if d.architectureSupportsUEFI(d.architecture) {
 		qemuCmd = append(qemuCmd, "-smbios", "type=2,manufacturer=LinuxContainers,product=Incus")
+
+		// Commas are escaped by doubling them in QEMU options.
+		if d.expandedConfig["smbios.serial"] != "" {
+			qemuCmd = append(qemuCmd, "-smbios", fmt.Sprintf("type=1,serial=%s", strings.ReplaceAll(d.expandedConfig["smbios.serial"], ",", ",,")))
+		}
+
+		if d.expandedConfig["smbios.asset_tag"] != "" {
+			qemuCmd = append(qemuCmd, "-smbios", fmt.Sprintf("type=3,asset=%s", strings.ReplaceAll(d.expandedConfig["smbios.asset_tag"], ",", ",,")))
+		}
 	}
 
 	// Attempt to drop privileges (doesn't work when restoring state).
@@ -1568,7 +1686,7 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 
 	// Handle hugepages on architectures where we don't set NUMA nodes.
 	if d.architecture != osarch.ARCH_64BIT_INTEL_X86 && util.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
-		hugetlb, err := localUtil.HugepagesPath()
+		hugetlb, err := d.hugepagesPath()
 		if err != nil {
 			op.Done(err)
 			return err
@@ -1602,6 +1720,20 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		forkLimitsCmd = append(forkLimitsCmd, fmt.Sprintf("fd=%d", 3+i))
 	}
 
+	// Start QEMU under the instances parent cgroup, limiting it to the resources not reserved for the host from
+	// its very first thread.
+	instancesParentPath, err := cgroup.PrepareInstancesParent(d.instancesParentCgroup())
+	if err != nil {
+		err = fmt.Errorf("Failed creating the cgroup of the VM under the instances parent cgroup: %w", err)
+		op.Done(err)
+		return err
+	}
+
+	if instancesParentPath != "" {
+		revert.Add(func() { _ = cgroup.RemoveFromInstancesParent(d.instancesParentCgroup()) })
+		forkLimitsCmd = append(forkLimitsCmd, fmt.Sprintf("cgroup=%s", instancesParentPath))
+	}
+
 	// Setup background process.
 	p, err := subprocess.NewProcess(d.state.OS.ExecPath, append(forkLimitsCmd, qemuCmd...), d.EarlyLogFilePath(), d.EarlyLogFilePath())
 	if err != nil {
@@ -1628,8 +1760,11 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		return err
 	}
 
+	_, processSpan := tracing.Start(traceCtx, "qemu.process")
+
 	err = p.StartWithFiles(context.Background(), fdFiles)
 	if err != nil {
+		tracing.End(processSpan, err)
 		op.Done(err)
 		return err
 	}
@@ -1638,10 +1773,18 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	if err != nil {
 		stderr, _ := os.ReadFile(d.EarlyLogFilePath())
 		err = fmt.Errorf("Failed to run: %s: %s: %w", strings.Join(p.Args, " "), string(stderr), err)
+		tracing.End(processSpan, err)
 		op.Done(err)
 		return err
 	}
 
+	processSpan.End()
+
+	// QEMU mapped the huge pages backing its memory, they're now accounted for by the kernel.
+	if hugepagesAllocation != nil {
+		hugepages.Commit(hugepagesAllocation)
+	}
+
 	pid, err := d.pid()
 	if err != nil || pid <= 0 {
 		d.logger.Error("Failed to get VM process ID", logger.Ctx{"err": err, "pid": pid})
@@ -1664,6 +1807,10 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	// onStop hook isn't triggered prematurely (as this function's reverter will clean up on failure to start).
 	monitor.SetOnDisconnectEvent(false)
 
+	// Trace the commands sent until started.
+	monitor.SetTraceContext(traceCtx)
+	defer monitor.SetTraceContext(nil)
+
 	// Apply CPU pinning.
 	if cpuInfo.vcpus == nil {
 		if d.architectureSupportsCPUHotplug() && cpuInfo.cores > 1 {
@@ -1712,6 +1859,16 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
+	// Apply the CPU power policy.
+	if d.expandedConfig["limits.cpu.power"] != "" {
+		err = d.setCPUPower(monitor)
+		if err != nil {
+			err = fmt.Errorf("Failed to apply CPU power policy: %w", err)
+			op.Done(err)
+			return err
+		}
+	}
+
 	// Run monitor hooks from devices.
 	for _, monHook := range monHooks {
 		err = monHook(monitor)
@@ -2182,6 +2339,11 @@ func (d *qemu) deviceAttachPath(deviceName string, configCopy map[string]string,
 	reverter := revert.New()
 	defer reverter.Fail()
 
+	cacheSize, err := d.virtiofsCacheSize(mount)
+	if err != nil {
+		return err
+	}
+
 	// Check if the agent is running.
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
 	if err != nil {
@@ -2259,6 +2421,10 @@ func (d *qemu) deviceAttachPath(deviceName string, configCopy map[string]string,
 		"id":      deviceID,
 	}
 
+	if cacheSize != "" {
+		qemuDev["cache-size"] = cacheSize
+	}
+
 	err = monitor.AddDevice(qemuDev)
 	if err != nil {
 		return fmt.Errorf("Failed to add the virtiofs device: %w", err)
@@ -3078,7 +3244,7 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 	cfg = append(cfg, qemuControlSocket(&qemuControlSocketOpts{d.monitorPath()})...)
 
 	// Console output.
-	cfg = append(cfg, qemuConsole(&qemuConsoleOpts{d.consolePath()})...)
+	cfg = append(cfg, qemuConsole(&qemuConsoleOpts{path: d.consolePath(), logPath: d.ConsoleBufferLogPath()})...)
 
 	// Setup the bus allocator.
 	bus := qemuNewBus(busName, &cfg)
@@ -3381,6 +3547,14 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 			}
 		}
 
+		// Add ivshmem device.
+		if len(runConf.IvshmemDevice) > 0 {
+			err = d.addIvshmemDevConfig(&cfg, bus, runConf.IvshmemDevice)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
 		// Add USB devices.
 		for _, usbDev := range runConf.USBDevice {
 			monHook, err := d.addUSBDeviceConfig(usbDev)
@@ -3408,6 +3582,14 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 		}
 	}
 
+	// Guest crash notification is used to capture crash dumps.
+	if util.IsTrue(d.expandedConfig["coredumps.enabled"]) && slices.Contains([]string{"pcie", "pci"}, bus.name) {
+		err = d.addPVPanicDeviceConfig(&cfg, bus)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
 	// Allocate 4 PCI slots for hotplug devices.
 	for i := 0; i < 4; i++ {
 		bus.allocate(busFunctionGroupNone)
@@ -3548,7 +3730,7 @@ func (d *qemu) addCPUMemoryConfig(cfg *[]cfgSection, cpuInfo *cpuTopology) error
 
 	cpuOpts.hugepages = ""
 	if util.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
-		hugetlb, err := localUtil.HugepagesPath()
+		hugetlb, err := d.hugepagesPath()
 		if err != nil {
 			return err
 		}
@@ -3642,9 +3824,6 @@ func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os
 		agentMount.Options = append(agentMount.Options, "ro")
 	}
 
-	// Record the 9p mount for the agent.
-	*agentMounts = append(*agentMounts, agentMount)
-
 	// Check if the disk device has provided a virtiofsd socket path.
 	var virtiofsdSockPath string
 	for _, opt := range driveConf.Opts {
@@ -3660,6 +3839,16 @@ func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os
 			return fmt.Errorf("virtiofsd socket path %q doesn't exist", virtiofsdSockPath)
 		}
 
+		cacheSize, err := d.virtiofsCacheSize(driveConf)
+		if err != nil {
+			return err
+		}
+
+		// Indicate to agent to map the files of the share through the DAX window.
+		if cacheSize != "" {
+			agentMount.Options = append(agentMount.Options, "dax=always")
+		}
+
 		devBus, devAddr, multi := bus.allocate(busFunctionGroup9p)
 
 		// Add virtio-fs device as this will be preferred over 9p.
@@ -3670,14 +3859,18 @@ func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os
 				devAddr:       devAddr,
 				multifunction: multi,
 			},
-			devName:  driveConf.DevName,
-			mountTag: mountTag,
-			path:     virtiofsdSockPath,
-			protocol: "virtio-fs",
+			devName:   driveConf.DevName,
+			mountTag:  mountTag,
+			path:      virtiofsdSockPath,
+			protocol:  "virtio-fs",
+			cacheSize: cacheSize,
 		}
 		*cfg = append(*cfg, qemuDriveDir(&driveDirVirtioOpts)...)
 	}
 
+	// Record the mount for the agent.
+	*agentMounts = append(*agentMounts, agentMount)
+
 	// Add 9p share config.
 	devBus, devAddr, multi := bus.allocate(busFunctionGroup9p)
 
@@ -3706,6 +3899,35 @@ func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os
 	return nil
 }
 
+// virtiofsCacheSize returns the size in bytes of the DAX window requested by the disk device for its virtio-fs
+// share, or an empty string if none was requested.
+func (d *qemu) virtiofsCacheSize(driveConf deviceConfig.MountEntryItem) (string, error) {
+	var dax string
+	for _, opt := range driveConf.Opts {
+		if strings.HasPrefix(opt, fmt.Sprintf("%s=", device.DiskVirtiofsDAXMountOpt)) {
+			parts := strings.SplitN(opt, "=", 2)
+			dax = parts[1]
+		}
+	}
+
+	if dax == "" {
+		return "", nil
+	}
+
+	info := DriverStatuses()[instancetype.VM].Info
+	_, found := info.Features["virtiofs_dax"]
+	if !found {
+		return "", fmt.Errorf("QEMU doesn't support a DAX window for virtio-fs shares (requested by %q)", driveConf.DevName)
+	}
+
+	size, err := units.ParseByteSizeString(dax)
+	if err != nil {
+		return "", fmt.Errorf("Invalid DAX window size %q for %q: %w", dax, driveConf.DevName, err)
+	}
+
+	return fmt.Sprintf("%d", size), nil
+}
+
 // addDriveConfig adds the qemu config required for adding a supplementary drive.
 func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]int, driveConf deviceConfig.MountEntryItem) (monitorHook, error) {
 	aioMode := "native" // Use native kernel async IO and O_DIRECT by default.
@@ -4393,28 +4615,76 @@ func (d *qemu) writeNICDevConfig(mtuStr string, devName string, nicName string,
 }
 
 // addPCIDevConfig adds the qemu config required for adding a raw PCI device.
+// All the devices of an IOMMU group passed through together are added as functions of the same slot.
 func (d *qemu) addPCIDevConfig(cfg *[]cfgSection, bus *qemuBus, pciConfig []deviceConfig.RunConfigItem) error {
-	var devName, pciSlotName string
+	var devName string
+	var pciSlotNames []string
 	for _, pciItem := range pciConfig {
 		if pciItem.Key == "devName" {
 			devName = pciItem.Value
 		} else if pciItem.Key == "pciSlotName" {
-			pciSlotName = pciItem.Value
+			pciSlotNames = append(pciSlotNames, pciItem.Value)
+		}
+	}
+
+	for i, pciSlotName := range pciSlotNames {
+		qemuDevName := devName
+		if i > 0 {
+			qemuDevName = fmt.Sprintf("%s_%d", devName, i)
+		}
+
+		devBus, devAddr, multi := bus.allocate(fmt.Sprintf("incus_%s", devName))
+		pciPhysicalOpts := qemuPCIPhysicalOpts{
+			dev: qemuDevOpts{
+				busName:       bus.name,
+				devBus:        devBus,
+				devAddr:       devAddr,
+				multifunction: multi,
+			},
+			devName:     qemuDevName,
+			pciSlotName: pciSlotName,
+		}
+		*cfg = append(*cfg, qemuPCIPhysical(&pciPhysicalOpts)...)
+	}
+
+	return nil
+}
+
+// addIvshmemDevConfig adds the qemu config required for adding an ivshmem device.
+func (d *qemu) addIvshmemDevConfig(cfg *[]cfgSection, bus *qemuBus, ivshmemConfig []deviceConfig.RunConfigItem) error {
+	if !slices.Contains([]string{"pcie", "pci"}, bus.name) {
+		return fmt.Errorf("Shared memory devices require a PCI bus")
+	}
+
+	var devName, path string
+	var size int64
+	for _, ivshmemItem := range ivshmemConfig {
+		if ivshmemItem.Key == "devName" {
+			devName = ivshmemItem.Value
+		} else if ivshmemItem.Key == "path" {
+			path = ivshmemItem.Value
+		} else if ivshmemItem.Key == "size" {
+			var err error
+			size, err = strconv.ParseInt(ivshmemItem.Value, 10, 64)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	devBus, devAddr, multi := bus.allocate(fmt.Sprintf("incus_%s", devName))
-	pciPhysicalOpts := qemuPCIPhysicalOpts{
+	ivshmemOpts := qemuIvshmemOpts{
 		dev: qemuDevOpts{
 			busName:       bus.name,
 			devBus:        devBus,
 			devAddr:       devAddr,
 			multifunction: multi,
 		},
-		devName:     devName,
-		pciSlotName: pciSlotName,
+		devName: devName,
+		path:    path,
+		size:    size,
 	}
-	*cfg = append(*cfg, qemuPCIPhysical(&pciPhysicalOpts)...)
+	*cfg = append(*cfg, qemuIvshmem(&ivshmemOpts)...)
 
 	return nil
 }
@@ -4596,6 +4866,20 @@ func (d *qemu) addVmgenDeviceConfig(cfg *[]cfgSection, guid string) error {
 	return nil
 }
 
+// addPVPanicDeviceConfig adds the qemu config required for the guest to report kernel panics.
+func (d *qemu) addPVPanicDeviceConfig(cfg *[]cfgSection, bus *qemuBus) error {
+	devBus, devAddr, multi := bus.allocate(busFunctionGroupNone)
+	pvpanicOpts := qemuDevOpts{
+		busName:       bus.name,
+		devBus:        devBus,
+		devAddr:       devAddr,
+		multifunction: multi,
+	}
+	*cfg = append(*cfg, qemuPVPanic(&pvpanicOpts)...)
+
+	return nil
+}
+
 // pidFilePath returns the path where the qemu process should write its PID.
 func (d *qemu) pidFilePath() string {
 	return filepath.Join(d.RunPath(), "qemu.pid")
@@ -4891,6 +5175,10 @@ func (d *qemu) Snapshot(name string, expiry time.Time, stateful bool) error {
 
 // Restore restores an instance snapshot.
 func (d *qemu) Restore(source instance.Instance, stateful bool) error {
+	if stateful && !source.IsStateful() {
+		return fmt.Errorf("Stateful snapshot restore requested but snapshot is stateless")
+	}
+
 	op, err := operationlock.Create(d.Project().Name, d.Name(), operationlock.ActionRestore, false, false)
 	if err != nil {
 		return fmt.Errorf("Failed to create instance restore operation: %w", err)
@@ -4992,6 +5280,14 @@ func (d *qemu) Restore(source instance.Instance, stateful bool) error {
 		return err
 	}
 
+	// Record the restored snapshot as the one the instance now derives from.
+	_, snapName, _ := api.GetParentAndSnapshotName(source.Name())
+	err = d.VolatileSet(map[string]string{"volatile.snapshot.parent": snapName})
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
 	d.stateful = stateful
 
 	// Restart the instance.
@@ -5009,6 +5305,50 @@ func (d *qemu) Restore(source instance.Instance, stateful bool) error {
 	return nil
 }
 
+// diskLayoutChanged returns whether disks were added, removed or resized.
+func diskLayoutChanged(removeDevices deviceConfig.Devices, addDevices deviceConfig.Devices, updateDevices deviceConfig.Devices, oldDevices deviceConfig.Devices) bool {
+	for _, devices := range []deviceConfig.Devices{removeDevices, addDevices} {
+		for _, dev := range devices {
+			if dev["type"] == "disk" {
+				return true
+			}
+		}
+	}
+
+	for name, dev := range updateDevices {
+		if dev["type"] == "disk" && dev["size"] != oldDevices[name]["size"] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// invalidateStatefulSnapshots marks the stateful snapshots of the instance as stateless.
+func (d *qemu) invalidateStatefulSnapshots() error {
+	snapshots, err := d.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range snapshots {
+		if !snap.IsStateful() {
+			continue
+		}
+
+		err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateInstanceSnapshotStatefulFlag(ctx, snap.ID(), false)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed invalidating memory state of snapshot %q: %w", snap.Name(), err)
+		}
+
+		d.logger.Info("Invalidated memory state of snapshot after disk layout change", logger.Ctx{"snapshot": snap.Name()})
+	}
+
+	return nil
+}
+
 // Rename the instance. Accepts an argument to enable applying deferred TemplateTriggerRename.
 func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 	unlock, err := d.updateBackupFileLock(context.Background())
@@ -5112,6 +5452,17 @@ func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 		return err
 	}
 
+	// Keep the snapshot tree connected.
+	if d.IsSnapshot() {
+		_, oldSnapName, _ := api.GetParentAndSnapshotName(oldName)
+		_, newSnapName, _ := api.GetParentAndSnapshotName(newName)
+
+		err = d.snapshotReparent(oldSnapName, newSnapName)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Rename the logging path.
 	newFullName := project.Instance(d.Project().Name, d.Name())
 	_ = os.RemoveAll(internalUtil.LogPath(newFullName))
@@ -5123,6 +5474,15 @@ func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 		}
 	}
 
+	// Rename the core dumps path.
+	if !d.IsSnapshot() {
+		err = coredump.Rename(d.project.Name, oldName, newName)
+		if err != nil {
+			d.logger.Error("Failed renaming instance", ctxMap)
+			return fmt.Errorf("Failed renaming instance: %w", err)
+		}
+	}
+
 	// Rename the runtime path.
 	newFullName = project.Instance(d.Project().Name, d.Name())
 	_ = os.RemoveAll(internalUtil.RunPath(newFullName))
@@ -5161,6 +5521,13 @@ func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 		revert.Add(func() { _ = b.Rename(oldName) })
 	}
 
+	if !d.IsSnapshot() {
+		err = d.devicesRename(d, oldName, false)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Update lease files.
 	err = network.UpdateDNSMasqStatic(d.state, "")
 	if err != nil {
@@ -5188,7 +5555,7 @@ func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 	} else {
 		err = d.state.Authorizer.RenameInstance(d.state.ShutdownCtx, d.project.Name, oldName, newName)
 		if err != nil {
-			logger.Error("Failed to rename instance in authorizer", logger.Ctx{"old_name": oldName, "new_name": newName, "project": d.project.Name, "error": err})
+			d.logger.Error("Failed to rename instance in authorizer", logger.Ctx{"old_name": oldName, "new_name": newName, "project": d.project.Name, "error": err})
 		}
 
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRenamed.Event(d, map[string]any{"old_name": oldName}))
@@ -5432,6 +5799,18 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 			return fmt.Errorf("Invalid expanded config: %w", err)
 		}
 
+		err = d.validateReservedCPUs()
+		if err != nil {
+			return fmt.Errorf("Invalid expanded config: %w", err)
+		}
+
+		if slices.Contains(changedConfig, "boot.depends_on") {
+			err = instance.ValidDependencies(d.state, d.project.Name, d.name, d.expandedConfig)
+			if err != nil {
+				return fmt.Errorf("Invalid expanded config: %w", err)
+			}
+		}
+
 		// Do full expanded validation of the devices diff.
 		err = instance.ValidDevices(d.state, d.project, d.Type(), d.localDevices, d.expandedDevices)
 		if err != nil {
@@ -5476,6 +5855,8 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		// Only certain keys can be changed on a running VM.
 		liveUpdateKeys := []string{
 			"cluster.evacuate",
+			"coredumps.enabled",
+			"limits.cpu.power",
 			"limits.memory",
 			"security.agent.metrics",
 			"security.csm",
@@ -5489,6 +5870,7 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 			"environment.",
 			"image.",
 			"snapshots.",
+			"ssh.authorized_keys.",
 			"user.",
 			"volatile.",
 		}
@@ -5551,6 +5933,16 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 				if err != nil {
 					return fmt.Errorf("Failed updating cpu limit: %w", err)
 				}
+			} else if key == "limits.cpu.power" {
+				monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+				if err != nil {
+					return err
+				}
+
+				err = d.setCPUPower(monitor)
+				if err != nil {
+					return fmt.Errorf("Failed updating CPU power policy: %w", err)
+				}
 			} else if key == "limits.memory" {
 				err = d.updateMemoryLimit(value)
 				if err != nil {
@@ -5571,6 +5963,14 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 				}
 			}
 		}
+
+		// Update the SSH keys installed in the instance.
+		if slices.ContainsFunc(changedConfig, func(key string) bool { return strings.HasPrefix(key, "ssh.authorized_keys.") }) {
+			err = instance.SyncSSHAuthorizedKeys(d)
+			if err != nil {
+				d.logger.Warn("Failed updating SSH keys", logger.Ctx{"err": err})
+			}
+		}
 	}
 
 	if d.architectureSupportsUEFI(d.architecture) && (slices.Contains(changedConfig, "security.secureboot") || slices.Contains(changedConfig, "security.csm")) {
@@ -5650,6 +6050,14 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		return fmt.Errorf("Failed to update database: %w", err)
 	}
 
+	// The memory state of the snapshots can't be restored onto a different disk layout.
+	if userRequested && !d.IsSnapshot() && diskLayoutChanged(removeDevices, addDevices, updateDevices, oldExpandedDevices) {
+		err = d.invalidateStatefulSnapshots()
+		if err != nil {
+			return err
+		}
+	}
+
 	err = d.UpdateBackupFile()
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("Failed to write backup file: %w", err)
@@ -5961,6 +6369,13 @@ func (d *qemu) Delete(force bool) error {
 			return fmt.Errorf("Invalid parent: %w", err)
 		}
 
+		// Keep the snapshot tree connected.
+		_, snapName, _ := api.GetParentAndSnapshotName(d.name)
+		err = d.snapshotReparent(snapName, d.localConfig["volatile.snapshot.parent"])
+		if err != nil {
+			return err
+		}
+
 		// Update the backup file.
 		err = parent.UpdateBackupFile()
 		if err != nil {
@@ -6038,6 +6453,12 @@ func (d *qemu) delete(force bool) error {
 			}
 		}
 
+		// Remove all core dumps.
+		err = coredump.DeleteAll(d.project.Name, d.Name())
+		if err != nil {
+			return fmt.Errorf("Failed deleting core dumps: %w", err)
+		}
+
 		// Run device removal function for each device.
 		d.devicesRemove(d)
 
@@ -6065,7 +6486,7 @@ func (d *qemu) delete(force bool) error {
 	} else {
 		err = d.state.Authorizer.DeleteInstance(d.state.ShutdownCtx, d.project.Name, d.Name())
 		if err != nil {
-			logger.Error("Failed to remove instance from authorizer", logger.Ctx{"name": d.Name(), "project": d.project.Name, "error": err})
+			d.logger.Error("Failed to remove instance from authorizer", logger.Ctx{"name": d.Name(), "project": d.project.Name, "error": err})
 		}
 
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceDeleted.Event(d, nil))
@@ -6367,6 +6788,12 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 		return err
 	}
 
+	// Steer the new connections of the network load balancers away from a running instance before moving it.
+	if d.IsRunning() {
+		undrain := d.drainConnections()
+		defer undrain()
+	}
+
 	pool, err := storagePools.LoadByInstance(d.state, d)
 	if err != nil {
 		return fmt.Errorf("Failed loading instance: %w", err)
@@ -7370,9 +7797,18 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 	}
 }
 
-// CGroupSet is not implemented for VMs.
+// instancesParentCgroup returns the name of the cgroup of the VM under the instances parent cgroup.
+func (d *qemu) instancesParentCgroup() string {
+	return cgroup.VMInstancesParentName(project.Instance(d.Project().Name, d.Name()))
+}
+
+// CGroup returns the cgroup of the VM, only available when QEMU runs under the instances parent cgroup.
 func (d *qemu) CGroup() (*cgroup.CGroup, error) {
-	return nil, instance.ErrNotImplemented
+	if !cgroup.InstancesParentEnabled() {
+		return nil, instance.ErrNotImplemented
+	}
+
+	return cgroup.InstancesParentCGroup(d.instancesParentCgroup())
 }
 
 // FileSFTPConn returns a connection to the agent SFTP endpoint.
@@ -7571,6 +8007,35 @@ func (d *qemu) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, s
 	return instCmd, nil
 }
 
+// ExecStructured runs a command through the agent and returns its result without attaching any websocket.
+func (d *qemu) ExecStructured(req api.InstanceExecPost) (*api.InstanceExecResult, error) {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := incus.ConnectIncusHTTP(nil, client)
+	if err != nil {
+		d.logger.Error("Failed to connect to the agent", logger.Ctx{"err": err})
+		return nil, fmt.Errorf("Failed to connect to the agent")
+	}
+
+	defer agent.Disconnect()
+
+	// Retrieve the agent's API extensions to detect agents lacking support for structured exec.
+	_, _, err = agent.GetServer()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting agent information: %w", err)
+	}
+
+	req.WaitForWS = false
+	req.RecordOutput = false
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceExec.Event(d, logger.Ctx{"command": req.Command}))
+
+	return agent.ExecInstanceStructured("", req)
+}
+
 // Render returns info about the instance.
 func (d *qemu) Render(options ...func(response any) error) (any, any, error) {
 	profileNames := make([]string, 0, len(d.profiles))
@@ -7589,6 +8054,8 @@ func (d *qemu) Render(options ...func(response any) error) (any, any, error) {
 			LastUsedAt:      d.lastUsedDate,
 			Name:            strings.SplitN(d.name, "/", 2)[1],
 			Stateful:        d.stateful,
+			Type:            d.snapshotType(),
+			Parent:          d.localConfig["volatile.snapshot.parent"],
 			Size:            -1, // Default to uninitialized/error state (0 means no CoW usage).
 		}
 
@@ -7611,7 +8078,7 @@ func (d *qemu) Render(options ...func(response any) error) (any, any, error) {
 
 	// Prepare the ETag
 	etag := []any{d.architecture, d.localConfig, d.localDevices, d.ephemeral, d.profiles}
-	statusCode := d.statusCode()
+	statusCode := d.renderStatusCode(d.statusCode())
 
 	instState := api.Instance{
 		ExpandedConfig:  d.expandedConfig,
@@ -7762,7 +8229,14 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 			}
 		}
 
+		status.CPU.NUMANodes = d.numaNodes()
 		status.Pid = int64(pid)
+
+		cg, err := d.CGroup()
+		if err == nil {
+			status.Pressure = cgroupPressureState(cg)
+		}
+
 		status.StartedAt, err = d.processStartedAt(d.InitPID())
 		if err != nil {
 			return status, err
@@ -7781,7 +8255,7 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 
 // RenderState returns just state info about the instance.
 func (d *qemu) RenderState(hostInterfaces []net.Interface) (*api.InstanceState, error) {
-	return d.renderState(d.statusCode())
+	return d.renderState(d.renderStatusCode(d.statusCode()))
 }
 
 // diskState gets disk usage info.
@@ -8123,7 +8597,7 @@ func (d *qemu) statusCode() api.StatusCode {
 
 // State returns the instance's state code.
 func (d *qemu) State() string {
-	return strings.ToUpper(d.statusCode().String())
+	return strings.ToUpper(d.renderStatusCode(d.statusCode()).String())
 }
 
 // EarlyLogFilePath returns the instance's early log path.
@@ -8190,6 +8664,121 @@ func (d *qemu) UpdateBackupFile() error {
 	return pool.UpdateInstanceBackupFile(d, true, nil)
 }
 
+// cpuFeatureAliases maps the QEMU names of CPU features to their name in /proc/cpuinfo, when they differ.
+var cpuFeatureAliases = map[string]string{
+	"sse4.1": "sse4_1",
+	"sse4.2": "sse4_2",
+}
+
+// cpuFeatures validates the CPU features requested for the instance against the host CPU
+// and returns the matching QEMU CPU properties.
+func (d *qemu) cpuFeatures(value string) ([]string, error) {
+	features, err := internalInstance.ParseCPUFeatures(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(features) == 0 {
+		return nil, nil
+	}
+
+	cpus, err := resources.GetCPU()
+	if err != nil {
+		return nil, err
+	}
+
+	hostFlags := map[string]bool{}
+	for _, socket := range cpus.Sockets {
+		for _, flag := range socket.Flags {
+			hostFlags[flag] = true
+		}
+	}
+
+	properties := make([]string, 0, len(features))
+	for _, feature := range features {
+		name := feature.Name
+
+		if name == internalInstance.CPUFeatureNested {
+			if hostFlags["vmx"] {
+				name = "vmx"
+			} else if hostFlags["svm"] {
+				name = "svm"
+			} else if feature.Enabled {
+				return nil, fmt.Errorf("Nested virtualization isn't supported by the host CPU")
+			} else {
+				continue
+			}
+
+			if feature.Enabled {
+				err = cpuNestedEnabled(name)
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else if feature.Enabled && !strings.HasPrefix(name, "hv") && !strings.HasPrefix(name, "kvm") {
+			// Paravirtualized features are provided by KVM and aren't listed by the host CPU.
+			hostName, ok := cpuFeatureAliases[name]
+			if !ok {
+				hostName = strings.ReplaceAll(name, "-", "_")
+			}
+
+			if !hostFlags[hostName] {
+				return nil, fmt.Errorf("CPU feature %q isn't supported by the host CPU", name)
+			}
+		}
+
+		if feature.Enabled {
+			properties = append(properties, name+"=on")
+		} else {
+			properties = append(properties, name+"=off")
+		}
+	}
+
+	return properties, nil
+}
+
+// qemuCPUModelCheck checks that the CPU model is supported by QEMU and can be used on the host CPU.
+// The models map the CPU models supported by QEMU to the features the host CPU lacks to run them, nil if unknown.
+func qemuCPUModelCheck(model string, models map[string][]string) error {
+	if models == nil {
+		return nil
+	}
+
+	unavailable, ok := models[model]
+	if !ok {
+		return fmt.Errorf("CPU model %q isn't supported by QEMU", model)
+	}
+
+	if len(unavailable) > 0 {
+		return fmt.Errorf("CPU model %q can't be used as the host CPU lacks: %s", model, strings.Join(unavailable, ", "))
+	}
+
+	return nil
+}
+
+// cpuNestedEnabled checks that the KVM module allows nested virtualization with the given extension.
+func cpuNestedEnabled(extension string) error {
+	module := "kvm_intel"
+	if extension == "svm" {
+		module = "kvm_amd"
+	}
+
+	content, err := os.ReadFile(filepath.Join("/sys/module", module, "parameters", "nested"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if !slices.Contains([]string{"1", "Y"}, strings.TrimSpace(string(content))) {
+		return fmt.Errorf("Nested virtualization is disabled in the %q kernel module", module)
+	}
+
+	return nil
+}
+
 type cpuTopology struct {
 	sockets int
 	cores   int
@@ -8403,7 +8992,7 @@ func (d *qemu) Info() instance.Info {
 
 	hostArch, err := osarch.ArchitectureGetLocalID()
 	if err != nil {
-		logger.Errorf("Failed getting CPU architecture during QEMU initialization: %v", err)
+		qemuLogger.Errorf("Failed getting CPU architecture during QEMU initialization: %v", err)
 		data.Error = fmt.Errorf("Failed getting CPU architecture")
 		return data
 	}
@@ -8416,7 +9005,7 @@ func (d *qemu) Info() instance.Info {
 
 	out, err := exec.Command(qemuPath, "--version").Output()
 	if err != nil {
-		logger.Errorf("Failed getting version during QEMU initialization: %v", err)
+		qemuLogger.Errorf("Failed getting version during QEMU initialization: %v", err)
 		data.Error = fmt.Errorf("Failed getting QEMU version")
 		return data
 	}
@@ -8431,7 +9020,7 @@ func (d *qemu) Info() instance.Info {
 
 	data.Features, err = d.checkFeatures(hostArch, qemuPath)
 	if err != nil {
-		logger.Errorf("Unable to run feature checks during QEMU initialization: %v", err)
+		qemuLogger.Errorf("Unable to run feature checks during QEMU initialization: %v", err)
 		data.Error = fmt.Errorf("QEMU failed to run feature checks")
 		return data
 	}
@@ -8567,7 +9156,7 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 
 	err = monitor.AddBlockDevice(blockDev, nil)
 	if err != nil {
-		logger.Debug("Failed adding block device during VM feature check", logger.Ctx{"err": err})
+		d.logger.Debug("Failed adding block device during VM feature check", logger.Ctx{"err": err})
 	} else {
 		features["io_uring"] = struct{}{}
 	}
@@ -8575,7 +9164,7 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 	// Check CPU hotplug feature.
 	_, err = monitor.QueryHotpluggableCPUs()
 	if err != nil {
-		logger.Debug("Failed querying hotpluggable CPUs during VM feature check", logger.Ctx{"err": err})
+		d.logger.Debug("Failed querying hotpluggable CPUs during VM feature check", logger.Ctx{"err": err})
 	} else {
 		features["cpu_hotplug"] = struct{}{}
 	}
@@ -8602,7 +9191,7 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 			// Host supports SEV, check if QEMU supports it as well.
 			capabilities, err := monitor.SEVCapabilities()
 			if err != nil {
-				logger.Debug("Failed querying SEV capability during VM feature check", logger.Ctx{"err": err})
+				d.logger.Debug("Failed querying SEV capability during VM feature check", logger.Ctx{"err": err})
 			} else {
 				features["sev"] = capabilities
 
@@ -8610,7 +9199,7 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 				// check if the SEV-ES extension is enabled.
 				sevES, err := os.ReadFile("/sys/module/kvm_amd/parameters/sev_es")
 				if err != nil {
-					logger.Debug("Failed querying SEV-ES capability during VM feature check", logger.Ctx{"err": err})
+					d.logger.Debug("Failed querying SEV-ES capability during VM feature check", logger.Ctx{"err": err})
 				} else if strings.TrimSpace(string(sevES)) == "Y" {
 					features["sev-es"] = struct{}{}
 				}
@@ -8618,11 +9207,37 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 		}
 	}
 
+	// Check if virtio-fs supports a DAX window.
+	props, err := monitor.DeviceListProperties("vhost-user-fs-pci")
+	if err != nil {
+		d.logger.Debug("Failed listing virtio-fs device properties during VM feature check", logger.Ctx{"err": err})
+	} else if slices.Contains(props, "cache-size") {
+		features["virtiofs_dax"] = struct{}{}
+	}
+
 	// Check if vhost-net accelerator (for NIC CPU offloading) is available.
 	if util.PathExists("/dev/vhost-net") {
 		features["vhost_net"] = struct{}{}
 	}
 
+	// Record the CPU models supported by QEMU, along with the features the host CPU lacks to run them.
+	cpuModels, err := monitor.QueryCPUModels()
+	if err != nil {
+		d.logger.Debug("Failed querying CPU models during VM feature check", logger.Ctx{"err": err})
+	} else {
+		models := make(map[string][]string, len(cpuModels))
+		for _, model := range cpuModels {
+			// The missing features are only relevant with KVM acceleration.
+			if hostArch != osarch.ARCH_64BIT_INTEL_X86 {
+				model.UnavailableFeatures = nil
+			}
+
+			models[model.Name] = model.UnavailableFeatures
+		}
+
+		features["cpu_models"] = models
+	}
+
 	return features, nil
 }
 
@@ -8642,24 +9257,147 @@ func (d *qemu) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, erro
 		return nil, ErrInstanceIsStopped
 	}
 
+	var out *metrics.MetricSet
+	var err error
+
 	if d.agentMetricsEnabled() {
-		metrics, err := d.getAgentMetrics()
+		out, err = d.getAgentMetrics()
 		if err != nil {
 			if !errors.Is(err, errQemuAgentOffline) {
 				d.logger.Warn("Could not get VM metrics from agent", logger.Ctx{"err": err})
 			}
 
 			// Fallback data if agent is not reachable.
-			return d.getQemuMetrics()
+			out, err = d.getQemuMetrics()
 		}
+	} else {
+		out, err = d.getQemuMetrics()
+	}
+
+	if err != nil {
+		return nil, err
+	}
 
-		return metrics, nil
+	// Get pressure stall information
+	cg, err := d.CGroup()
+	if err == nil {
+		cgroupPressureMetrics(out, cg)
+	}
+
+	return out, nil
+}
+
+// OOMKills returns the number of processes of the guest killed by the out-of-memory killer and the name of the last
+// one, as reported by the agent.
+func (d *qemu) OOMKills() (int64, string, error) {
+	if !d.IsRunning() {
+		return -1, "", ErrInstanceIsStopped
+	}
+
+	if !d.agentMetricsEnabled() {
+		return -1, "", errors.New("The agent metrics are disabled")
+	}
+
+	m, err := d.getAgentRawMetrics()
+	if err != nil {
+		return -1, "", err
 	}
 
-	return d.getQemuMetrics()
+	return int64(m.Memory.OOMKills), m.Memory.OOMKillTask, nil
+}
+
+// VerifyLimits checks that the limits of the running VM are applied to QEMU and its devices.
+func (d *qemu) VerifyLimits() ([]limitcheck.Discrepancy, error) {
+	if !d.IsRunning() {
+		return nil, ErrInstanceIsStopped
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return nil, err
+	}
+
+	var discrepancies []limitcheck.Discrepancy
+	mismatch := func(key string, expected string, actual string) {
+		discrepancies = append(discrepancies, limitcheck.Discrepancy{Key: key, Expected: expected, Actual: actual})
+	}
+
+	// Memory limit, QEMU works in MiB and applies live reductions through the balloon.
+	memory := d.expandedConfig["limits.memory"]
+	if memory != "" {
+		expected, err := units.ParseByteSizeString(memory)
+		if err != nil {
+			return nil, err
+		}
+
+		expected = expected / 1024 / 1024 * 1024 * 1024
+
+		actual, err := monitor.GetMemoryBalloonSizeBytes()
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting memory size: %w", err)
+		}
+
+		if actual != expected {
+			mismatch("limits.memory", fmt.Sprintf("%d", expected), fmt.Sprintf("%d", actual))
+		}
+	}
+
+	// CPU count and pinning.
+	cpuLimit := d.expandedConfig["limits.cpu"]
+	if cpuLimit != "" {
+		cpuInfo, err := d.cpuTopology(cpuLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		pids, err := monitor.GetCPUs()
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting vCPUs: %w", err)
+		}
+
+		expected := cpuInfo.sockets * cpuInfo.cores * cpuInfo.threads
+		if len(pids) != expected {
+			mismatch("limits.cpu", fmt.Sprintf("%d vCPUs", expected), fmt.Sprintf("%d vCPUs", len(pids)))
+		} else if cpuInfo.vcpus != nil {
+			for i, pid := range pids {
+				set := unix.CPUSet{}
+				err := unix.SchedGetaffinity(pid, &set)
+				if err != nil {
+					return nil, fmt.Errorf("Failed getting affinity of vCPU %d: %w", i, err)
+				}
+
+				host := int(cpuInfo.vcpus[uint64(i)])
+				if set.Count() != 1 || !set.IsSet(host) {
+					mismatch("limits.cpu", fmt.Sprintf("vCPU %d pinned to CPU %d", i, host), fmt.Sprintf("vCPU %d on %d CPUs", i, set.Count()))
+				}
+			}
+		}
+	}
+
+	devices, err := d.verifyDeviceLimits(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(discrepancies, devices...), nil
 }
 
 func (d *qemu) getAgentMetrics() (*metrics.MetricSet, error) {
+	m, err := d.getAgentRawMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	metricSet, err := metrics.MetricSetFromAPI(m, map[string]string{"project": d.project.Name, "name": d.name, "type": instancetype.VM.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	return metricSet, nil
+}
+
+// getAgentRawMetrics returns the metrics reported by the agent.
+func (d *qemu) getAgentRawMetrics() (*metrics.Metrics, error) {
 	client, err := d.getAgentClient()
 	if err != nil {
 		return nil, err
@@ -8686,12 +9424,7 @@ func (d *qemu) getAgentMetrics() (*metrics.MetricSet, error) {
 		return nil, err
 	}
 
-	metricSet, err := metrics.MetricSetFromAPI(&m, map[string]string{"project": d.project.Name, "name": d.name, "type": instancetype.VM.String()})
-	if err != nil {
-		return nil, err
-	}
-
-	return metricSet, nil
+	return &m, nil
 }
 
 func (d *qemu) getNetworkState() (map[string]api.InstanceStateNetwork, error) {
@@ -8952,9 +9685,16 @@ func (d *qemu) postCPUHotplug(monitor *qmp.Monitor) error {
 			return err
 		}
 
-		// Get the isolated CPU ids.
+		// Get the isolated CPU ids, along with those reserved for the host.
 		isolatedCpusInt := resources.GetCPUIsolated()
 
+		reservedCpusInt, err := d.reservedCPUs()
+		if err != nil {
+			return err
+		}
+
+		isolatedCpusInt = append(isolatedCpusInt, reservedCpusInt...)
+
 		// Build a map of NUMA node to CPU threads.
 		numaNodeToCPU := make(map[int64][]int64)
 		for _, cpu := range cpusTopology.Sockets {
@@ -8994,5 +9734,13 @@ func (d *qemu) postCPUHotplug(monitor *qmp.Monitor) error {
 		return fmt.Errorf("Failed to allocate new core scheduling domain for vCPU threads: %w", err)
 	}
 
+	// Apply the CPU power policy to the new vCPU threads.
+	if d.expandedConfig["limits.cpu.power"] != "" {
+		err = d.setCPUPower(monitor)
+		if err != nil {
+			return fmt.Errorf("Failed to apply CPU power policy: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -10,7 +10,6 @@ import (
 
 	"github.com/lxc/incus/v6/client"
 	"github.com/lxc/incus/v6/shared/api"
-	"github.com/lxc/incus/v6/shared/logger"
 )
 
 // ErrExecDisconnected is returned when the guest disconnects the exec session.
@@ -51,7 +50,7 @@ func (c *qemuCmd) Signal(sig unix.Signal) error {
 		return err
 	}
 
-	logger.Debugf(`Forwarded signal "%d" to the agent`, sig)
+	qemuLogger.Debugf(`Forwarded signal "%d" to the agent`, sig)
 	return nil
 }
 
@@ -119,6 +118,6 @@ func (c *qemuCmd) WindowResize(fd, winchWidth, winchHeight int) error {
 		return err
 	}
 
-	logger.Debugf(`Forwarded window resize "%dx%d" to the agent`, winchWidth, winchHeight)
+	qemuLogger.Debugf(`Forwarded window resize "%dx%d" to the agent`, winchWidth, winchHeight)
 	return nil
 }
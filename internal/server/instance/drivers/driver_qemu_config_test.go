@@ -704,13 +704,23 @@ func TestQemuConfigTemplates(t *testing.T) {
 			opts     qemuConsoleOpts
 			expected string
 		}{{
-			qemuConsoleOpts{"/dev/shm/console-socket"},
+			qemuConsoleOpts{"/dev/shm/console-socket", ""},
 			`# Console
 			[chardev "console"]
 			backend = "socket"
 			path = "/dev/shm/console-socket"
 			server = "on"
 			wait = "off"`,
+		}, {
+			qemuConsoleOpts{"/dev/shm/console-socket", "/var/log/incus/vm1/console.log"},
+			`# Console
+			[chardev "console"]
+			backend = "socket"
+			path = "/dev/shm/console-socket"
+			server = "on"
+			wait = "off"
+			logfile = "/var/log/incus/vm1/console.log"
+			logappend = "on"`,
 		}}
 		for _, tc := range testCases {
 			runTest(tc.expected, qemuConsole(&tc.opts))
@@ -1085,6 +1095,52 @@ func TestQemuConfigTemplates(t *testing.T) {
 		}
 	})
 
+	t.Run("qemu_ivshmem", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuIvshmemOpts
+			expected string
+		}{{
+			qemuIvshmemOpts{
+				dev:     qemuDevOpts{"pcie", "qemu_pcie1", "00.0", false},
+				devName: "shm0",
+				path:    "/dev/shm/incus-ivshmem/default/shm0",
+				size:    67108864,
+			},
+			`[object "qemu_ivshmem-memdev_shm0"]
+			qom-type = "memory-backend-file"
+			mem-path = "/dev/shm/incus-ivshmem/default/shm0"
+			size = "67108864"
+			share = "on"
+
+			# Shared memory ("shm0" device)
+			[device "dev-incus_shm0"]
+			driver = "ivshmem-plain"
+			bus = "qemu_pcie1"
+			addr = "00.0"
+			memdev = "qemu_ivshmem-memdev_shm0"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuIvshmem(&tc.opts))
+		}
+	})
+
+	t.Run("qemu_pvpanic", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuDevOpts
+			expected string
+		}{{
+			qemuDevOpts{"pcie", "qemu_pcie5", "00.0", false},
+			`# Guest crash notification
+			[device "qemu_pvpanic"]
+			driver = "pvpanic-pci"
+			bus = "qemu_pcie5"
+			addr = "00.0"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuPVPanic(&tc.opts))
+		}
+	})
+
 	t.Run("qemu_raw_cfg_override", func(t *testing.T) {
 		cfg := []cfgSection{{
 			name: "global",
@@ -0,0 +1,82 @@
+package drivers
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/server/coredump"
+	"github.com/lxc/incus/v6/internal/server/instance/drivers/qmp"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/units"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// captureCrashDump stores a dump of the guest memory after the guest kernel reported a panic.
+// The instance is left paused so that it can be inspected further.
+func (d *qemu) captureCrashDump() error {
+	if util.IsFalseOrEmpty(d.expandedConfig["coredumps.enabled"]) {
+		return nil
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	// Don't attempt dumps which can't be kept.
+	maxSize := coredump.MaxSize(d.project.Config)
+
+	memSize, err := monitor.GetMemorySizeBytes()
+	if err != nil {
+		return err
+	}
+
+	if memSize > maxSize {
+		d.logger.Warn("Skipping crash dump larger than the project limit", logger.Ctx{"memory": units.GetByteSizeStringIEC(memSize, 2), "limit": units.GetByteSizeStringIEC(maxSize, 2)})
+		return nil
+	}
+
+	name := fmt.Sprintf("%s_kernel%s", time.Now().UTC().Format("20060102T150405Z"), coredump.Suffix)
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	f, err := coredump.Create(d.project.Name, d.name, name)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+	revert.Add(func() { _ = os.Remove(f.Name()) })
+
+	fdName := "coredump"
+	err = monitor.SendFile(fdName, f)
+	if err != nil {
+		return fmt.Errorf("Failed sending core dump file: %w", err)
+	}
+
+	err = monitor.DumpGuestMemory(fdName)
+	if err != nil {
+		return fmt.Errorf("Failed dumping guest memory: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	err = coredump.Add(d.project.Name, d.name, name, maxSize)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("Captured guest crash dump", logger.Ctx{"name": name})
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceCoreDumpCreated.Event(name, d, nil, nil))
+
+	return nil
+}
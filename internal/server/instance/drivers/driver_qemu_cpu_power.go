@@ -0,0 +1,297 @@
+package drivers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/server/cgroup"
+	"github.com/lxc/incus/v6/internal/server/instance/drivers/qmp"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// qemuCPUPowerIdleLatencyMax is the highest exit latency (in microseconds) of the idle states left enabled on
+// host CPUs running vCPUs with the performance policy.
+const qemuCPUPowerIdleLatencyMax = 5
+
+// qemuCPUPowerSysPath is the sysfs directory of the host CPUs.
+var qemuCPUPowerSysPath = "/sys/devices/system/cpu"
+
+// qemuCPUPowerUsers tracks the host CPUs tuned by running instances, so that overlapping instances
+// share the original settings and only the last one to stop restores them.
+var qemuCPUPowerUsers = map[int]*qemuCPUPowerUser{}
+var qemuCPUPowerUsersMu sync.Mutex
+
+// qemuCPUPowerUser records the original settings of a tuned host CPU and the number of instances using it.
+type qemuCPUPowerUser struct {
+	governor   string
+	idleStates []int
+	users      int
+}
+
+// qemuCPUPowerState records the host CPU settings changed for the pinned vCPUs so they can be restored.
+type qemuCPUPowerState struct {
+	Governors  map[int]string `json:"governors"`
+	IdleStates map[int][]int  `json:"idle_states"`
+}
+
+// cpuPowerStatePath returns the path of the file recording the host CPU settings changed for the instance.
+func (d *qemu) cpuPowerStatePath() string {
+	return filepath.Join(d.RunPath(), "cpu-power.json")
+}
+
+// setCPUPower applies the limits.cpu.power policy to the vCPU threads.
+// When the vCPUs are pinned, the frequency governor and idle states of the host CPUs are also adjusted.
+func (d *qemu) setCPUPower(monitor *qmp.Monitor) error {
+	cpuPower := d.expandedConfig["limits.cpu.power"]
+
+	uclampMin, uclampMax, err := cgroup.ParseCPUPower(cpuPower)
+	if err != nil {
+		return err
+	}
+
+	pids, err := monitor.GetCPUs()
+	if err != nil {
+		return err
+	}
+
+	cpuInfo, err := d.cpuTopology(d.expandedConfig["limits.cpu"])
+	if err != nil {
+		return err
+	}
+
+	hostCPUs := make([]uint64, 0, len(cpuInfo.vcpus))
+	for _, hostCPU := range cpuInfo.vcpus {
+		hostCPUs = append(hostCPUs, hostCPU)
+	}
+
+	// Utilization clamps are expressed relative to a capacity of 1024.
+	attr := unix.SchedAttr{
+		Flags:    unix.SCHED_FLAG_KEEP_ALL | unix.SCHED_FLAG_UTIL_CLAMP,
+		Util_min: uint32(uclampMin * 1024 / 100),
+		Util_max: uint32(uclampMax * 1024 / 100),
+	}
+
+	for _, pid := range pids {
+		err := unix.SchedSetAttr(pid, &attr, 0)
+		if err != nil {
+			if errors.Is(err, unix.EINVAL) || errors.Is(err, unix.EOPNOTSUPP) {
+				d.logger.Warn("Utilization clamping isn't supported by the kernel, ignoring limits.cpu.power for vCPU threads")
+				break
+			}
+
+			return fmt.Errorf("Failed setting utilization clamps on vCPU thread %d: %w", pid, err)
+		}
+	}
+
+	// Undo the previous changes first, so that the original settings are the ones recorded.
+	d.restoreCPUPower()
+
+	if len(hostCPUs) == 0 || cpuPower == "" || cpuPower == "balanced" {
+		return nil
+	}
+
+	state, tuneErr := qemuCPUPowerTune(qemuCPUPowerSysPath, hostCPUs, cpuPower)
+
+	// Record the changes even if only some could be made, so they get restored.
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(d.cpuPowerStatePath(), data, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return tuneErr
+}
+
+// restoreCPUPower restores the host CPU settings changed by setCPUPower.
+func (d *qemu) restoreCPUPower() {
+	data, err := os.ReadFile(d.cpuPowerStatePath())
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			d.logger.Warn("Failed reading CPU power state", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	defer func() { _ = os.Remove(d.cpuPowerStatePath()) }()
+
+	state := qemuCPUPowerState{}
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		d.logger.Warn("Failed parsing CPU power state", logger.Ctx{"err": err})
+		return
+	}
+
+	qemuCPUPowerRestore(qemuCPUPowerSysPath, state, d.logger)
+}
+
+// qemuCPUPowerTune adjusts the frequency governor and idle states of the host CPUs for a power policy.
+// It returns the original settings of what was changed, taking a reference on each tuned host CPU.
+func qemuCPUPowerTune(sysPath string, hostCPUs []uint64, cpuPower string) (qemuCPUPowerState, error) {
+	state := qemuCPUPowerState{
+		Governors:  map[int]string{},
+		IdleStates: map[int][]int{},
+	}
+
+	qemuCPUPowerUsersMu.Lock()
+	defer qemuCPUPowerUsersMu.Unlock()
+
+	for _, hostCPU := range hostCPUs {
+		err := qemuCPUPowerTuneCPU(sysPath, int(hostCPU), cpuPower, &state)
+		if err != nil {
+			return state, err
+		}
+	}
+
+	return state, nil
+}
+
+// qemuCPUPowerTuneCPU adjusts a single host CPU, recording its original settings in state.
+// The caller must hold qemuCPUPowerUsersMu.
+func qemuCPUPowerTuneCPU(sysPath string, cpu int, cpuPower string, state *qemuCPUPowerState) error {
+	cpuPath := filepath.Join(sysPath, fmt.Sprintf("cpu%d", cpu))
+
+	user := qemuCPUPowerUsers[cpu]
+	if user == nil {
+		user = &qemuCPUPowerUser{}
+	}
+
+	// Take a reference on the host CPU if it has been tuned, even partially.
+	defer func() {
+		if user.governor == "" && len(user.idleStates) == 0 {
+			return
+		}
+
+		user.users++
+		qemuCPUPowerUsers[cpu] = user
+
+		if user.governor != "" {
+			state.Governors[cpu] = user.governor
+		}
+
+		if len(user.idleStates) > 0 {
+			state.IdleStates[cpu] = slices.Clone(user.idleStates)
+		}
+	}()
+
+	// Adjust the frequency governor.
+	governor := "performance"
+	if cpuPower == "efficiency" {
+		governor = "powersave"
+	}
+
+	current, err := os.ReadFile(filepath.Join(cpuPath, "cpufreq", "scaling_governor"))
+	if err == nil && strings.TrimSpace(string(current)) != governor {
+		available, _ := os.ReadFile(filepath.Join(cpuPath, "cpufreq", "scaling_available_governors"))
+		if slices.Contains(strings.Fields(string(available)), governor) {
+			err = os.WriteFile(filepath.Join(cpuPath, "cpufreq", "scaling_governor"), []byte(governor), 0)
+			if err != nil {
+				return fmt.Errorf("Failed setting frequency governor of CPU %d: %w", cpu, err)
+			}
+
+			// Only the first instance to change the governor records the original one.
+			if user.governor == "" {
+				user.governor = strings.TrimSpace(string(current))
+			}
+		}
+	}
+
+	// Disable the deep idle states.
+	if cpuPower != "performance" {
+		return nil
+	}
+
+	idlePaths, _ := filepath.Glob(filepath.Join(cpuPath, "cpuidle", "state*"))
+	for _, idlePath := range idlePaths {
+		index, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(idlePath), "state"))
+		if err != nil {
+			continue
+		}
+
+		latency, err := os.ReadFile(filepath.Join(idlePath, "latency"))
+		if err != nil {
+			continue
+		}
+
+		latencyValue, err := strconv.Atoi(strings.TrimSpace(string(latency)))
+		if err != nil || latencyValue <= qemuCPUPowerIdleLatencyMax {
+			continue
+		}
+
+		disabled, err := os.ReadFile(filepath.Join(idlePath, "disable"))
+		if err != nil || strings.TrimSpace(string(disabled)) == "1" {
+			continue
+		}
+
+		err = os.WriteFile(filepath.Join(idlePath, "disable"), []byte("1"), 0)
+		if err != nil {
+			return fmt.Errorf("Failed disabling idle state %d of CPU %d: %w", index, cpu, err)
+		}
+
+		user.idleStates = append(user.idleStates, index)
+	}
+
+	return nil
+}
+
+// qemuCPUPowerRestore releases the references taken by qemuCPUPowerTune, restoring the original settings
+// of the host CPUs no other instance is using anymore.
+func qemuCPUPowerRestore(sysPath string, state qemuCPUPowerState, l logger.Logger) {
+	qemuCPUPowerUsersMu.Lock()
+	defer qemuCPUPowerUsersMu.Unlock()
+
+	cpus := map[int]struct{}{}
+	for cpu := range state.Governors {
+		cpus[cpu] = struct{}{}
+	}
+
+	for cpu := range state.IdleStates {
+		cpus[cpu] = struct{}{}
+	}
+
+	for cpu := range cpus {
+		governor := state.Governors[cpu]
+		idleStates := state.IdleStates[cpu]
+
+		// Host CPUs tuned before a daemon restart aren't tracked, restore what the instance recorded.
+		user := qemuCPUPowerUsers[cpu]
+		if user != nil {
+			user.users--
+			if user.users > 0 {
+				continue
+			}
+
+			delete(qemuCPUPowerUsers, cpu)
+			governor = user.governor
+			idleStates = user.idleStates
+		}
+
+		if governor != "" {
+			err := os.WriteFile(filepath.Join(sysPath, fmt.Sprintf("cpu%d", cpu), "cpufreq", "scaling_governor"), []byte(governor), 0)
+			if err != nil {
+				l.Warn("Failed restoring frequency governor", logger.Ctx{"cpu": cpu, "governor": governor, "err": err})
+			}
+		}
+
+		for _, index := range idleStates {
+			err := os.WriteFile(filepath.Join(sysPath, fmt.Sprintf("cpu%d", cpu), "cpuidle", fmt.Sprintf("state%d", index), "disable"), []byte("0"), 0)
+			if err != nil {
+				l.Warn("Failed restoring idle state", logger.Ctx{"cpu": cpu, "state": index, "err": err})
+			}
+		}
+	}
+}
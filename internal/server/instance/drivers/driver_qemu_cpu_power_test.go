@@ -0,0 +1,96 @@
+package drivers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+func TestQemuCPUPowerTune(t *testing.T) {
+	sysPath := t.TempDir()
+
+	write := func(path string, value string) {
+		path = filepath.Join(sysPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(value+"\n"), 0o644))
+	}
+
+	read := func(path string) string {
+		value, err := os.ReadFile(filepath.Join(sysPath, path))
+		require.NoError(t, err)
+
+		return strings.TrimSpace(string(value))
+	}
+
+	for _, cpu := range []string{"cpu0", "cpu1"} {
+		write(cpu+"/cpufreq/scaling_governor", "schedutil")
+		write(cpu+"/cpufreq/scaling_available_governors", "performance powersave schedutil")
+		write(cpu+"/cpuidle/state0/latency", "0")
+		write(cpu+"/cpuidle/state0/disable", "0")
+		write(cpu+"/cpuidle/state1/latency", "10")
+		write(cpu+"/cpuidle/state1/disable", "0")
+		write(cpu+"/cpuidle/state2/latency", "100")
+		write(cpu+"/cpuidle/state2/disable", "1")
+	}
+
+	// Only the host CPUs of the vCPUs are tuned, and the deep idle states which were enabled are recorded.
+	state, err := qemuCPUPowerTune(sysPath, []uint64{1}, "performance")
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: "schedutil"}, state.Governors)
+	require.Equal(t, map[int][]int{1: {1}}, state.IdleStates)
+
+	require.Equal(t, "performance", read("cpu1/cpufreq/scaling_governor"))
+	require.Equal(t, "0", read("cpu1/cpuidle/state0/disable"))
+	require.Equal(t, "1", read("cpu1/cpuidle/state1/disable"))
+	require.Equal(t, "schedutil", read("cpu0/cpufreq/scaling_governor"))
+	require.Equal(t, "0", read("cpu0/cpuidle/state1/disable"))
+
+	// Restoring brings back the original settings, leaving the idle states disabled by others alone.
+	qemuCPUPowerRestore(sysPath, state, logger.Log)
+	require.Equal(t, "schedutil", read("cpu1/cpufreq/scaling_governor"))
+	require.Equal(t, "0", read("cpu1/cpuidle/state1/disable"))
+	require.Equal(t, "1", read("cpu1/cpuidle/state2/disable"))
+
+	// The efficiency policy only changes the governor.
+	state, err = qemuCPUPowerTune(sysPath, []uint64{0, 1}, "efficiency")
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{0: "schedutil", 1: "schedutil"}, state.Governors)
+	require.Empty(t, state.IdleStates)
+	require.Equal(t, "powersave", read("cpu0/cpufreq/scaling_governor"))
+	require.Equal(t, "0", read("cpu0/cpuidle/state1/disable"))
+
+	// Overlapping instances share the original settings, only the last one restores them.
+	other, err := qemuCPUPowerTune(sysPath, []uint64{1}, "performance")
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: "schedutil"}, other.Governors)
+	require.Equal(t, map[int][]int{1: {1}}, other.IdleStates)
+	require.Equal(t, "performance", read("cpu1/cpufreq/scaling_governor"))
+
+	qemuCPUPowerRestore(sysPath, state, logger.Log)
+	require.Equal(t, "schedutil", read("cpu0/cpufreq/scaling_governor"))
+	require.Equal(t, "performance", read("cpu1/cpufreq/scaling_governor"))
+	require.Equal(t, "1", read("cpu1/cpuidle/state1/disable"))
+
+	qemuCPUPowerRestore(sysPath, other, logger.Log)
+	require.Equal(t, "schedutil", read("cpu1/cpufreq/scaling_governor"))
+	require.Equal(t, "0", read("cpu1/cpuidle/state1/disable"))
+	require.Empty(t, qemuCPUPowerUsers)
+
+	// Governors which aren't available are left alone.
+	write("cpu0/cpufreq/scaling_available_governors", "schedutil")
+	write("cpu0/cpufreq/scaling_governor", "schedutil")
+
+	state, err = qemuCPUPowerTune(sysPath, []uint64{0}, "performance")
+	require.NoError(t, err)
+	require.Empty(t, state.Governors)
+	require.Equal(t, map[int][]int{0: {1}}, state.IdleStates)
+
+	qemuCPUPowerRestore(sysPath, state, logger.Log)
+	require.Equal(t, "0", read("cpu0/cpuidle/state1/disable"))
+	require.Empty(t, qemuCPUPowerUsers)
+}
@@ -0,0 +1,169 @@
+package drivers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lxc/incus/v6/internal/server/hugepages"
+	"github.com/lxc/incus/v6/internal/server/resources"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/osarch"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+// hugepagesStatePath returns the path of the file recording the huge pages allocated for the instance.
+func (d *qemu) hugepagesStatePath() string {
+	return filepath.Join(d.RunPath(), "hugepages.json")
+}
+
+// hugepagesBacking returns the path of the hugetlbfs mount to back the instance memory with, along with its page size.
+// Without limits.memory.hugepages.size, any hugetlbfs mount is used.
+func (d *qemu) hugepagesBacking() (string, uint64, error) {
+	memory, err := resources.GetMemory()
+	if err != nil {
+		return "", 0, fmt.Errorf("Failed getting memory information: %w", err)
+	}
+
+	var size uint64
+	if d.expandedConfig["limits.memory.hugepages.size"] != "" {
+		value, err := units.ParseByteSizeString(d.expandedConfig["limits.memory.hugepages.size"])
+		if err != nil {
+			return "", 0, fmt.Errorf("Invalid limits.memory.hugepages.size: %w", err)
+		}
+
+		size = uint64(value)
+	}
+
+	path, size, err := localUtil.HugepagesPathForSize(size, memory.HugepagesSize)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if size == 0 {
+		return "", 0, fmt.Errorf("Huge pages aren't supported on this system")
+	}
+
+	return path, size, nil
+}
+
+// hugepagesPath returns the path of the hugetlbfs mount to back the instance memory with.
+func (d *qemu) hugepagesPath() (string, error) {
+	path, _, err := d.hugepagesBacking()
+	return path, err
+}
+
+// hugepagesMemory returns the amount of memory of the instance to back with huge pages.
+func (d *qemu) hugepagesMemory() (uint64, error) {
+	memSize := d.expandedConfig["limits.memory"]
+	if memSize == "" {
+		memSize = QEMUDefaultMemSize
+	}
+
+	memSizeBytes, err := units.ParseByteSizeString(memSize)
+	if err != nil {
+		return 0, fmt.Errorf("limits.memory invalid: %w", err)
+	}
+
+	return uint64(memSizeBytes), nil
+}
+
+// hugepagesFit returns whether a NUMA node has enough free huge pages to back the instance memory.
+func (d *qemu) hugepagesFit(node uint64) bool {
+	_, size, err := d.hugepagesBacking()
+	if err != nil {
+		return false
+	}
+
+	memory, err := d.hugepagesMemory()
+	if err != nil {
+		return false
+	}
+
+	return hugepages.Fits(size, memory, node)
+}
+
+// reserveHugepages makes sure that enough huge pages are free to back the instance memory, and reserves them until
+// hugepages.Commit is called once QEMU mapped them.
+// When instances.hugepages.dynamic is enabled, the pages added to the kernel pools are recorded so that
+// releaseHugepages can give them back once the instance stops.
+func (d *qemu) reserveHugepages() (*hugepages.Allocation, error) {
+	_, size, err := d.hugepagesBacking()
+	if err != nil {
+		return nil, err
+	}
+
+	memory, err := d.hugepagesMemory()
+	if err != nil {
+		return nil, err
+	}
+
+	// The memory is only bound to the NUMA nodes of the instance on x86_64.
+	var nodes []uint64
+	if d.architecture == osarch.ARCH_64BIT_INTEL_X86 {
+		nodes = d.numaNodes()
+	}
+
+	allocation, err := hugepages.Reserve(size, memory, nodes, d.state.GlobalConfig.InstancesHugepagesDynamic())
+	if err != nil {
+		return nil, fmt.Errorf("Failed reserving huge pages: %w", err)
+	}
+
+	if len(allocation.Pages) == 0 {
+		return allocation, nil
+	}
+
+	data, err := json.Marshal(allocation)
+	if err != nil {
+		_ = hugepages.Release(allocation)
+		return nil, err
+	}
+
+	err = os.WriteFile(d.hugepagesStatePath(), data, 0o600)
+	if err != nil {
+		_ = hugepages.Release(allocation)
+		return nil, err
+	}
+
+	return allocation, nil
+}
+
+// revertHugepages gives back the huge pages reserved by reserveHugepages when the instance fails to start.
+func (d *qemu) revertHugepages(allocation *hugepages.Allocation) {
+	_ = os.Remove(d.hugepagesStatePath())
+
+	err := hugepages.Release(allocation)
+	if err != nil {
+		d.logger.Warn("Failed releasing huge pages", logger.Ctx{"err": err})
+	}
+}
+
+// releaseHugepages gives back the huge pages allocated by reserveHugepages.
+func (d *qemu) releaseHugepages() {
+	data, err := os.ReadFile(d.hugepagesStatePath())
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			d.logger.Warn("Failed reading huge pages state", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	defer func() { _ = os.Remove(d.hugepagesStatePath()) }()
+
+	allocation := hugepages.Allocation{}
+	err = json.Unmarshal(data, &allocation)
+	if err != nil {
+		d.logger.Warn("Failed parsing huge pages state", logger.Ctx{"err": err})
+		return
+	}
+
+	err = hugepages.Release(&allocation)
+	if err != nil {
+		d.logger.Warn("Failed releasing huge pages", logger.Ctx{"err": err})
+	}
+}
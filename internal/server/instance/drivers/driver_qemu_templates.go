@@ -607,19 +607,28 @@ func qemuControlSocket(opts *qemuControlSocketOpts) []cfgSection {
 }
 
 type qemuConsoleOpts struct {
-	path string
+	path    string
+	logPath string
 }
 
 func qemuConsole(opts *qemuConsoleOpts) []cfgSection {
+	entries := []cfgEntry{
+		{key: "backend", value: "socket"},
+		{key: "path", value: opts.path},
+		{key: "server", value: "on"},
+		{key: "wait", value: "off"},
+	}
+
+	// Record the output of the current boot.
+	// The log is appended to so that it can be trimmed while QEMU writes to it.
+	if opts.logPath != "" {
+		entries = append(entries, cfgEntry{key: "logfile", value: opts.logPath}, cfgEntry{key: "logappend", value: "on"})
+	}
+
 	return []cfgSection{{
 		name:    `chardev "console"`,
 		comment: "Console",
-		entries: []cfgEntry{
-			{key: "backend", value: "socket"},
-			{key: "path", value: opts.path},
-			{key: "server", value: "on"},
-			{key: "wait", value: "off"},
-		},
+		entries: entries,
 	}}
 }
 
@@ -663,6 +672,7 @@ type qemuHostDriveOpts struct {
 	sockFd        string
 	readonly      bool
 	protocol      string
+	cacheSize     string
 }
 
 func qemuHostDrive(opts *qemuHostDriveOpts) []cfgSection {
@@ -713,6 +723,7 @@ func qemuHostDrive(opts *qemuHostDriveOpts) []cfgSection {
 		extraDeviceEntries = []cfgEntry{
 			{key: "tag", value: opts.mountTag},
 			{key: "chardev", value: opts.name},
+			{key: "cache-size", value: opts.cacheSize},
 		}
 	} else {
 		return []cfgSection{}
@@ -751,26 +762,28 @@ func qemuDriveConfig(opts *qemuDriveConfigOpts) []cfgSection {
 }
 
 type qemuDriveDirOpts struct {
-	dev      qemuDevOpts
-	devName  string
-	mountTag string
-	path     string
-	protocol string
-	proxyFD  int
-	readonly bool
+	dev       qemuDevOpts
+	devName   string
+	mountTag  string
+	path      string
+	protocol  string
+	proxyFD   int
+	readonly  bool
+	cacheSize string
 }
 
 func qemuDriveDir(opts *qemuDriveDirOpts) []cfgSection {
 	return qemuHostDrive(&qemuHostDriveOpts{
-		dev:      opts.dev,
-		name:     fmt.Sprintf("incus_%s", opts.devName),
-		comment:  fmt.Sprintf("%s drive (%s)", opts.devName, opts.protocol),
-		mountTag: opts.mountTag,
-		protocol: opts.protocol,
-		fsdriver: "proxy",
-		readonly: opts.readonly,
-		path:     opts.path,
-		sockFd:   fmt.Sprintf("%d", opts.proxyFD),
+		dev:       opts.dev,
+		name:      fmt.Sprintf("incus_%s", opts.devName),
+		comment:   fmt.Sprintf("%s drive (%s)", opts.devName, opts.protocol),
+		mountTag:  opts.mountTag,
+		protocol:  opts.protocol,
+		fsdriver:  "proxy",
+		readonly:  opts.readonly,
+		path:      opts.path,
+		sockFd:    fmt.Sprintf("%d", opts.proxyFD),
+		cacheSize: opts.cacheSize,
 	})
 }
 
@@ -910,6 +923,49 @@ func qemuTPM(opts *qemuTPMOpts) []cfgSection {
 	}}
 }
 
+type qemuIvshmemOpts struct {
+	dev     qemuDevOpts
+	devName string
+	path    string
+	size    int64
+}
+
+func qemuIvshmem(opts *qemuIvshmemOpts) []cfgSection {
+	memdev := fmt.Sprintf("qemu_ivshmem-memdev_%s", opts.devName)
+
+	deviceOpts := qemuDevEntriesOpts{
+		dev:     opts.dev,
+		pciName: "ivshmem-plain",
+	}
+
+	return []cfgSection{{
+		name: fmt.Sprintf(`object "%s"`, memdev),
+		entries: []cfgEntry{
+			{key: "qom-type", value: "memory-backend-file"},
+			{key: "mem-path", value: opts.path},
+			{key: "size", value: fmt.Sprintf("%d", opts.size)},
+			{key: "share", value: "on"},
+		},
+	}, {
+		name:    fmt.Sprintf(`device "%s%s"`, qemuDeviceIDPrefix, opts.devName),
+		comment: fmt.Sprintf(`Shared memory ("%s" device)`, opts.devName),
+		entries: append(qemuDeviceEntries(&deviceOpts), cfgEntry{key: "memdev", value: memdev}),
+	}}
+}
+
+func qemuPVPanic(opts *qemuDevOpts) []cfgSection {
+	entriesOpts := qemuDevEntriesOpts{
+		dev:     *opts,
+		pciName: "pvpanic-pci",
+	}
+
+	return []cfgSection{{
+		name:    `device "qemu_pvpanic"`,
+		comment: "Guest crash notification",
+		entries: qemuDeviceEntries(&entriesOpts),
+	}}
+}
+
 type qemuVmgenIDOpts struct {
 	guid string
 }
@@ -0,0 +1,61 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+)
+
+func TestQemuCPUModelCheck(t *testing.T) {
+	models := map[string][]string{"EPYC-v4": nil, "Skylake-Server-v5": {"avx512f", "pku"}}
+
+	require.NoError(t, qemuCPUModelCheck("EPYC-v4", models))
+	require.ErrorContains(t, qemuCPUModelCheck("Skylake-Server-v5", models), "avx512f, pku")
+	require.Error(t, qemuCPUModelCheck("Unknown-v1", models))
+
+	// Models can't be checked when QEMU didn't report them.
+	require.NoError(t, qemuCPUModelCheck("Unknown-v1", nil))
+}
+
+func TestQemuShutdownFailed(t *testing.T) {
+	require.True(t, qemuShutdownFailed("disconnect"))
+	require.True(t, qemuShutdownFailed("guest-panic"))
+
+	// A clean poweroff or reboot from inside the guest isn't a failure.
+	require.False(t, qemuShutdownFailed("guest-shutdown"))
+	require.False(t, qemuShutdownFailed("guest-reset"))
+	require.False(t, qemuShutdownFailed("host-qmp-quit"))
+	require.False(t, qemuShutdownFailed(nil))
+}
+
+func TestDiskLayoutChanged(t *testing.T) {
+	oldDevices := deviceConfig.Devices{
+		"root": {"type": "disk", "path": "/", "pool": "default", "size": "10GiB"},
+		"data": {"type": "disk", "pool": "default", "source": "data"},
+		"eth0": {"type": "nic", "network": "incusbr0"},
+	}
+
+	tests := []struct {
+		name    string
+		remove  deviceConfig.Devices
+		add     deviceConfig.Devices
+		update  deviceConfig.Devices
+		changed bool
+	}{
+		{"Nothing", nil, nil, nil, false},
+		{"Added disk", nil, deviceConfig.Devices{"extra": {"type": "disk", "pool": "default", "source": "extra"}}, nil, true},
+		{"Removed disk", deviceConfig.Devices{"data": oldDevices["data"]}, nil, nil, true},
+		{"Resized disk", nil, nil, deviceConfig.Devices{"root": {"type": "disk", "path": "/", "pool": "default", "size": "20GiB"}}, true},
+		{"Other disk change", nil, nil, deviceConfig.Devices{"root": {"type": "disk", "path": "/", "pool": "default", "size": "10GiB", "limits.read": "10MB"}}, false},
+		{"Added NIC", nil, deviceConfig.Devices{"eth1": {"type": "nic", "network": "incusbr0"}}, nil, false},
+		{"Removed NIC", deviceConfig.Devices{"eth0": oldDevices["eth0"]}, nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.changed, diskLayoutChanged(tt.remove, tt.add, tt.update, oldDevices))
+		})
+	}
+}
@@ -61,6 +61,27 @@ type HotpluggableCPU struct {
 	Props CPUInstanceProperties `json:"props"`
 }
 
+// CPUModel contains information about a CPU model supported by QEMU.
+type CPUModel struct {
+	Name                string   `json:"name"`
+	UnavailableFeatures []string `json:"unavailable-features,omitempty"`
+}
+
+// QueryCPUModels returns the list of CPU models supported by QEMU.
+func (m *Monitor) QueryCPUModels() ([]CPUModel, error) {
+	// Prepare the response.
+	var resp struct {
+		Return []CPUModel `json:"return"`
+	}
+
+	err := m.run("query-cpu-definitions", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query CPU models: %w", err)
+	}
+
+	return resp.Return, nil
+}
+
 // QueryCPUs returns a list of CPUs.
 func (m *Monitor) QueryCPUs() ([]CPU, error) {
 	// Prepare the response.
@@ -291,6 +312,22 @@ func (m *Monitor) Migrate(uri string) error {
 	return nil
 }
 
+// DumpGuestMemory writes an ELF dump of the guest memory to the file descriptor previously added with SendFile.
+func (m *Monitor) DumpGuestMemory(fdName string) error {
+	args := map[string]any{
+		"paging":   false,
+		"protocol": fmt.Sprintf("fd:%s", fdName),
+		"format":   "elf",
+	}
+
+	err := m.run("dump-guest-memory", args, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // MigrateWait waits until migration job reaches the specified status.
 // Returns nil if the migraton job reaches the specified status or an error if the migration job is in the failed
 // status.
@@ -1041,3 +1078,30 @@ func (m *Monitor) SetBlockThrottle(id string, bytesRead int, bytesWrite int, iop
 
 	return nil
 }
+
+// DeviceListProperties returns the names of the properties of the given device type.
+func (m *Monitor) DeviceListProperties(typeName string) ([]string, error) {
+	var args struct {
+		TypeName string `json:"typename"`
+	}
+
+	args.TypeName = typeName
+
+	var resp struct {
+		Return []struct {
+			Name string `json:"name"`
+		} `json:"return"`
+	}
+
+	err := m.run("device-list-properties", args, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing properties of device type %q: %w", typeName, err)
+	}
+
+	names := make([]string, 0, len(resp.Return))
+	for _, prop := range resp.Return {
+		names = append(names, prop.Name)
+	}
+
+	return names, nil
+}
@@ -9,10 +9,15 @@ import (
 	"time"
 
 	"github.com/digitalocean/go-qemu/qmp"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/lxc/incus/v6/internal/server/tracing"
 	"github.com/lxc/incus/v6/shared/logger"
 )
 
+// qemuLogger is the logger of the qemu subsystem.
+var qemuLogger = logger.Subsystem("qemu")
+
 var monitors = map[string]*Monitor{}
 var monitorsLock sync.Mutex
 
@@ -31,6 +36,9 @@ var EventVMShutdownReasonDisconnect = "disconnect"
 // EventDiskEjected is used to indicate that a disk device was ejected by the guest.
 var EventDiskEjected = "DEVICE_TRAY_MOVED"
 
+// EventGuestPanicked is the event sent when the guest reports a kernel panic.
+var EventGuestPanicked = "GUEST_PANICKED"
+
 // Monitor represents a QMP monitor.
 type Monitor struct {
 	path string
@@ -43,6 +51,10 @@ type Monitor struct {
 	eventHandler      func(name string, data map[string]any)
 	serialCharDev     string
 	onDisconnectEvent bool
+
+	// Context of the span the commands are traced under, commands aren't traced when nil.
+	traceCtx   context.Context
+	traceCtxMu sync.Mutex
 }
 
 // start handles the background goroutines for event handling and monitoring the ringbuffer.
@@ -93,8 +105,8 @@ func (m *Monitor) start() error {
 	}
 
 	go func() {
-		logger.Debug("QMP monitor started", logger.Ctx{"path": m.path})
-		defer logger.Debug("QMP monitor stopped", logger.Ctx{"path": m.path})
+		qemuLogger.Debug("QMP monitor started", logger.Ctx{"path": m.path})
+		defer qemuLogger.Debug("QMP monitor stopped", logger.Ctx{"path": m.path})
 
 		// Initial read from the ringbuffer.
 		go checkBuffer()
@@ -112,7 +124,7 @@ func (m *Monitor) start() error {
 						go func() {
 							err = m.Eject(id)
 							if err != nil {
-								logger.Warnf("Unable to eject media %q: %v", id, err)
+								qemuLogger.Warnf("Unable to eject media %q: %v", id, err)
 							}
 						}()
 					}
@@ -142,7 +154,7 @@ func (m *Monitor) start() error {
 				}
 
 				if e.Event == "" {
-					logger.Warnf("Unexpected empty event received from qmp event channel")
+					qemuLogger.Warnf("Unexpected empty event received from qmp event channel")
 					time.Sleep(time.Second) // Don't spin if we receive a lot of these.
 					continue
 				}
@@ -179,12 +191,22 @@ func (m *Monitor) ping() error {
 }
 
 // run executes a command.
-func (m *Monitor) run(cmd string, args any, resp any) error {
+func (m *Monitor) run(cmd string, args any, resp any) (err error) {
 	// Check if disconnected
 	if m.disconnected {
 		return ErrMonitorDisconnect
 	}
 
+	// Trace the command.
+	m.traceCtxMu.Lock()
+	traceCtx := m.traceCtx
+	m.traceCtxMu.Unlock()
+
+	if traceCtx != nil {
+		_, span := tracing.Start(traceCtx, "qmp."+cmd, attribute.String("rpc.system", "qmp"), attribute.String("rpc.method", cmd))
+		defer func() { tracing.End(span, err) }()
+	}
+
 	// Run the command.
 	requestArgs := struct {
 		Execute   string `json:"execute"`
@@ -325,3 +347,11 @@ func (m *Monitor) Wait() (chan struct{}, error) {
 func (m *Monitor) SetOnDisconnectEvent(enable bool) {
 	m.onDisconnectEvent = enable
 }
+
+// SetTraceContext sets the context of the span the commands are traced under, nil disabling their tracing.
+func (m *Monitor) SetTraceContext(ctx context.Context) {
+	m.traceCtxMu.Lock()
+	defer m.traceCtxMu.Unlock()
+
+	m.traceCtx = ctx
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/pkg/sftp"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/lxc/incus/v6/internal/limitcheck"
+	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/server/backup"
 	"github.com/lxc/incus/v6/internal/server/cgroup"
 	"github.com/lxc/incus/v6/internal/server/db"
@@ -169,6 +171,10 @@ type Instance interface {
 	DeferTemplateApply(trigger TemplateTrigger) error
 
 	Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error)
+	OOMKills() (int64, string, error)
+
+	// Limits.
+	VerifyLimits() ([]limitcheck.Discrepancy, error)
 }
 
 // Container interface is for container specific functions.
@@ -182,6 +188,12 @@ type Container interface {
 	InsertSeccompUnixDevice(prefix string, m deviceConfig.Device, pid int) error
 	DevptsFd() (*os.File, error)
 	IdmappedStorage(path string, fstype string) idmap.IdmapStorageType
+	FileDescriptorsUsage() (int64, int64, error)
+	ProcessesLimitHits() (int64, error)
+	OOMKillTask(kills []linux.OOMKill) string
+	RemountLXCFS() error
+	RefreshCPUInfo() error
+	Remap() error
 }
 
 // VM interface is for VM specific functions.
@@ -189,6 +201,7 @@ type VM interface {
 	Instance
 
 	AgentCertificate() *x509.Certificate
+	ExecStructured(req api.InstanceExecPost) (*api.InstanceExecResult, error)
 }
 
 // CriuMigrationArgs arguments for CRIU migration.
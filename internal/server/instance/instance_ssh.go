@@ -0,0 +1,139 @@
+package instance
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	sshAuthorizedKeysDir   = "/root/.ssh"
+	sshAuthorizedKeysPath  = "/root/.ssh/authorized_keys"
+	sshAuthorizedKeysStart = "# BEGIN Incus managed keys"
+	sshAuthorizedKeysEnd   = "# END Incus managed keys"
+)
+
+// SSHAuthorizedKeys returns the keys from the ssh.authorized_keys.* configuration of the instance and its project,
+// sorted by key name. Instance keys override project keys with the same name.
+func SSHAuthorizedKeys(inst Instance) []string {
+	keys := map[string]string{}
+
+	for _, config := range []map[string]string{inst.Project().Config, inst.ExpandedConfig()} {
+		for k, v := range config {
+			name, found := strings.CutPrefix(k, "ssh.authorized_keys.")
+			if found && strings.TrimSpace(v) != "" {
+				keys[name] = strings.TrimSpace(v)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		result = append(result, keys[name])
+	}
+
+	return result
+}
+
+// SyncSSHAuthorizedKeys installs the keys returned by SSHAuthorizedKeys in the authorized_keys file of the root
+// user of a running instance. Only the section managed by Incus is changed, other keys are left untouched.
+func SyncSSHAuthorizedKeys(inst Instance) error {
+	keys := SSHAuthorizedKeys(inst)
+
+	client, err := inst.FileSFTP()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = client.Close() }()
+
+	var content string
+
+	f, err := client.Open(sshAuthorizedKeysPath)
+	if err == nil {
+		data, err := io.ReadAll(f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+
+		content = string(data)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	newContent := sshAuthorizedKeysUpdate(content, keys)
+	if newContent == content {
+		return nil
+	}
+
+	err = client.MkdirAll(sshAuthorizedKeysDir)
+	if err != nil {
+		return err
+	}
+
+	err = client.Chmod(sshAuthorizedKeysDir, 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err = client.OpenFile(sshAuthorizedKeysPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write([]byte(newContent))
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Chmod(sshAuthorizedKeysPath, 0600)
+}
+
+// sshAuthorizedKeysUpdate replaces the section managed by Incus in the content of an authorized_keys file.
+// The section is removed when there are no keys.
+func sshAuthorizedKeysUpdate(content string, keys []string) string {
+	lines := []string{}
+	managed := false
+
+	content = strings.TrimRight(content, "\n")
+	if content != "" {
+		for _, line := range strings.Split(content, "\n") {
+			switch {
+			case line == sshAuthorizedKeysStart:
+				managed = true
+			case line == sshAuthorizedKeysEnd:
+				managed = false
+			case !managed:
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	if len(keys) > 0 {
+		lines = append(lines, sshAuthorizedKeysStart)
+		lines = append(lines, keys...)
+		lines = append(lines, sshAuthorizedKeysEnd)
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,36 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSHAuthorizedKeysUpdate(t *testing.T) {
+	keyA := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGLWdop a@host"
+	keyB := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGLWdop b@host"
+	userKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGLWdop user@host"
+
+	tests := []struct {
+		name     string
+		content  string
+		keys     []string
+		expected string
+	}{
+		{"Empty file without keys", "", nil, ""},
+		{"New file", "", []string{keyA, keyB}, sshAuthorizedKeysStart + "\n" + keyA + "\n" + keyB + "\n" + sshAuthorizedKeysEnd + "\n"},
+		{"Existing user keys are kept", userKey + "\n", []string{keyA}, userKey + "\n" + sshAuthorizedKeysStart + "\n" + keyA + "\n" + sshAuthorizedKeysEnd + "\n"},
+		{"Missing final newline", userKey, []string{keyA}, userKey + "\n" + sshAuthorizedKeysStart + "\n" + keyA + "\n" + sshAuthorizedKeysEnd + "\n"},
+		{"Managed keys are replaced", userKey + "\n" + sshAuthorizedKeysStart + "\n" + keyA + "\n" + sshAuthorizedKeysEnd + "\n", []string{keyB}, userKey + "\n" + sshAuthorizedKeysStart + "\n" + keyB + "\n" + sshAuthorizedKeysEnd + "\n"},
+		{"Managed section moves to the end", sshAuthorizedKeysStart + "\n" + keyA + "\n" + sshAuthorizedKeysEnd + "\n" + userKey + "\n", []string{keyA}, userKey + "\n" + sshAuthorizedKeysStart + "\n" + keyA + "\n" + sshAuthorizedKeysEnd + "\n"},
+		{"Managed section is removed without keys", userKey + "\n" + sshAuthorizedKeysStart + "\n" + keyA + "\n" + sshAuthorizedKeysEnd + "\n", nil, userKey + "\n"},
+		{"Only managed keys", sshAuthorizedKeysStart + "\n" + keyA + "\n" + sshAuthorizedKeysEnd + "\n", nil, ""},
+		{"Unchanged", userKey + "\n" + sshAuthorizedKeysStart + "\n" + keyA + "\n" + sshAuthorizedKeysEnd + "\n", []string{keyA}, userKey + "\n" + sshAuthorizedKeysStart + "\n" + keyA + "\n" + sshAuthorizedKeysEnd + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, sshAuthorizedKeysUpdate(tt.content, tt.keys))
+		})
+	}
+}
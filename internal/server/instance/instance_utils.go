@@ -29,6 +29,7 @@ import (
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/instance/operationlock"
+	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/seccomp"
 	"github.com/lxc/incus/v6/internal/server/state"
 	"github.com/lxc/incus/v6/internal/server/sys"
@@ -155,6 +156,19 @@ func validConfigKey(os *sys.OS, key string, value string, instanceType instancet
 		return lxcValidConfig(value)
 	}
 
+	if key == "limits.cpu.features" && instanceType == instancetype.Container {
+		features, err := instance.ParseCPUFeatures(value)
+		if err != nil {
+			return err
+		}
+
+		for _, feature := range features {
+			if feature.Enabled {
+				return fmt.Errorf("CPU feature %q can only be hidden from containers", feature.Name)
+			}
+		}
+	}
+
 	if key == "security.syscalls.deny_compat" || key == "security.syscalls.blacklist_compat" {
 		for _, arch := range os.Architectures {
 			if arch == osarch.ARCH_64BIT_INTEL_X86 ||
@@ -332,8 +346,33 @@ func LoadInstanceDatabaseObject(ctx context.Context, tx *db.ClusterTx, project,
 	return container, nil
 }
 
+// LoadByProjectAndRuntimeName loads an instance by project and by the name it's running under, which is its
+// previous name when it was renamed while running.
+func LoadByProjectAndRuntimeName(s *state.State, projectName string, instanceName string) (Instance, error) {
+	var renamed string
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		renamed, err = tx.GetInstanceByRuntimeName(ctx, projectName, instanceName)
+
+		return err
+	})
+	if err == nil {
+		instanceName = renamed
+	} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return nil, err
+	}
+
+	return LoadByProjectAndName(s, projectName, instanceName)
+}
+
 // LoadByProjectAndName loads an instance by project and name.
 func LoadByProjectAndName(s *state.State, projectName string, instanceName string) (Instance, error) {
+	// Disconnected edge members serve their instances from the local backup files.
+	if s.Edge.Disconnected() {
+		return loadFromEdgeCache(s, projectName, instanceName)
+	}
+
 	// Get the DB record
 	var args db.InstanceArgs
 	var p *api.Project
@@ -454,6 +493,47 @@ func LoadFromBackup(s *state.State, projectName string, instancePath string, app
 	return inst, nil
 }
 
+// loadFromEdgeCache loads a local instance without the database, using its backup file and the project cached
+// by the edge member.
+func loadFromEdgeCache(s *state.State, projectName string, instanceName string) (Instance, error) {
+	cached, p, ok := s.Edge.Instance(projectName, instanceName)
+	if !ok {
+		return nil, api.StatusErrorf(http.StatusServiceUnavailable, "Instance %q isn't available while disconnected from the cluster", instanceName)
+	}
+
+	instanceType, err := instancetype.New(cached.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := "containers"
+	if instanceType == instancetype.VM {
+		dir = "virtual-machines"
+	}
+
+	backupYamlPath := internalUtil.VarPath(dir, project.Instance(projectName, instanceName), "backup.yaml")
+	backupConf, err := backup.ParseConfigYamlFile(backupYamlPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing instance backup file from %q: %w", backupYamlPath, err)
+	}
+
+	instDBArgs, err := backup.ConfigToInstanceDBArgs(s, backupConf, projectName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the expanded config so the profiles don't need to be loaded.
+	instDBArgs.Config = backupConf.Container.ExpandedConfig
+	instDBArgs.Devices = deviceConfig.NewDevices(backupConf.Container.ExpandedDevices)
+
+	inst, err := Load(s, *instDBArgs, *p)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance from backup file %q: %w", backupYamlPath, err)
+	}
+
+	return inst, nil
+}
+
 // DeviceNextInterfaceHWAddr generates a random MAC address.
 func DeviceNextInterfaceHWAddr() (string, error) {
 	// Generate a new random MAC address using the usual prefix
@@ -562,6 +642,62 @@ func ResolveImage(ctx context.Context, tx *db.ClusterTx, projectName string, sou
 	return "", fmt.Errorf("Must specify one of alias, fingerprint or properties for init from image")
 }
 
+// ImageArchitectureVariants returns the local images equivalent to the given one, indexed by architecture.
+// Images are equivalent when they have the same type and were downloaded from the same alias of the same image
+// server, which is how the different architectures of an image get into the image store.
+func ImageArchitectureVariants(ctx context.Context, tx *db.ClusterTx, projectName string, img *api.Image) (map[int]*api.Image, error) {
+	id, err := osarch.ArchitectureId(img.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := map[int]*api.Image{id: img}
+
+	// Images which weren't downloaded from an alias have no known variant.
+	if img.UpdateSource == nil || img.UpdateSource.Alias == "" {
+		return variants, nil
+	}
+
+	fingerprints, err := tx.GetImagesFingerprints(ctx, projectName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fingerprint := range fingerprints {
+		if fingerprint == img.Fingerprint {
+			continue
+		}
+
+		_, candidate, err := tx.GetImageByFingerprintPrefix(ctx, fingerprint, cluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			continue
+		}
+
+		if candidate.Type != img.Type || candidate.UpdateSource == nil {
+			continue
+		}
+
+		if candidate.UpdateSource.Server != img.UpdateSource.Server || candidate.UpdateSource.Protocol != img.UpdateSource.Protocol || candidate.UpdateSource.Alias != img.UpdateSource.Alias {
+			continue
+		}
+
+		arch, err := osarch.ArchitectureId(candidate.Architecture)
+		if err != nil || arch == id {
+			continue
+		}
+
+		// Prefer the most recent image for each architecture.
+		existing, found := variants[arch]
+		if found && existing.CreatedAt.After(candidate.CreatedAt) {
+			continue
+		}
+
+		variants[arch] = candidate
+	}
+
+	return variants, nil
+}
+
 // SuitableArchitectures returns a slice of architecture ids based on an instance create request.
 //
 // An empty list indicates that the request may be handled by any architecture.
@@ -606,6 +742,23 @@ func SuitableArchitectures(ctx context.Context, s *state.State, tx *db.ClusterTx
 				return nil, err
 			}
 
+			// Aliases resolve to the matching image for every available architecture.
+			if req.Source.Alias != "" && req.Source.Fingerprint == "" {
+				variants, err := ImageArchitectureVariants(ctx, tx, projectName, img)
+				if err != nil {
+					return nil, err
+				}
+
+				architectures := []int{id}
+				for arch := range variants {
+					if arch != id {
+						architectures = append(architectures, arch)
+					}
+				}
+
+				return architectures, nil
+			}
+
 			return []int{id}, nil
 		}
 
@@ -616,6 +769,11 @@ func SuitableArchitectures(ctx context.Context, s *state.State, tx *db.ClusterTx
 				return nil, nil
 			}
 
+			if req.Source.Protocol == "git" {
+				// Images built from Git recipes only get an architecture once built.
+				return nil, nil
+			}
+
 			var err error
 			var remote incus.ImageServer
 			if slices.Contains([]string{"", "incus", "lxd"}, req.Source.Protocol) {
@@ -704,6 +862,48 @@ func ValidName(instanceName string, isSnapshot bool) error {
 		if err != nil {
 			return fmt.Errorf("Invalid instance name: %w", err)
 		}
+
+		// Reserved for the instance batch API endpoint.
+		if instanceName == "batch" {
+			return fmt.Errorf("Invalid instance name: %q is reserved", instanceName)
+		}
+	}
+
+	return nil
+}
+
+// ValidDependencies checks that the instances listed in the boot.depends_on of the expanded config don't depend
+// back on the instance, which would prevent ordering the startup of the instances of the project.
+func ValidDependencies(s *state.State, projectName string, instanceName string, expandedConfig map[string]string) error {
+	if expandedConfig["boot.depends_on"] == "" {
+		return nil
+	}
+
+	dependencies := map[string][]string{
+		instanceName: util.SplitNTrimSpace(expandedConfig["boot.depends_on"], ",", -1, true),
+	}
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			if dbInst.Name == instanceName {
+				return nil
+			}
+
+			config := db.ExpandInstanceConfig(dbInst.Config, dbInst.Profiles)
+			if config["boot.depends_on"] != "" {
+				dependencies[dbInst.Name] = util.SplitNTrimSpace(config["boot.depends_on"], ",", -1, true)
+			}
+
+			return nil
+		}, cluster.InstanceFilter{Project: &projectName})
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	cycle := instance.DependencyCycle(dependencies, instanceName)
+	if cycle != nil {
+		return fmt.Errorf("Dependency cycle between instances %s", strings.Join(cycle, " -> "))
 	}
 
 	return nil
@@ -755,6 +955,9 @@ func CreateInternal(s *state.State, args db.InstanceArgs, clearLogDir bool, chec
 
 	args.Config["volatile.uuid.generation"] = args.Config["volatile.uuid"]
 
+	// The name a container runs under is only ever set by the server when renaming it while running.
+	delete(args.Config, "volatile.runtime.name")
+
 	if args.Devices == nil {
 		args.Devices = deviceConfig.Devices{}
 	}
@@ -916,6 +1119,14 @@ func CreateInternal(s *state.State, args db.InstanceArgs, clearLogDir bool, chec
 			return nil
 		}
 
+		// A container renamed while running keeps using its previous name until it stops.
+		renamed, err := tx.GetInstanceByRuntimeName(ctx, args.Project, args.Name)
+		if err == nil {
+			return fmt.Errorf("Name %q is still in use by the running instance %q", args.Name, renamed)
+		} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
 		// Create the instance entry.
 		dbInst = cluster.Instance{
 			Project:      args.Project,
@@ -1,6 +1,9 @@
 package ip
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/lxc/incus/v6/shared/subprocess"
 )
 
@@ -11,10 +14,30 @@ type Class struct {
 	Classid string
 }
 
+// Delete deletes class from node.
+func (class *Class) Delete() error {
+	cmd := []string{"class", "del", "dev", class.Dev}
+	if class.Parent != "" {
+		cmd = append(cmd, "parent", class.Parent)
+	}
+
+	if class.Classid != "" {
+		cmd = append(cmd, "classid", class.Classid)
+	}
+
+	_, err := subprocess.RunCommand("tc", cmd...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ClassHTB represents htb qdisc class object.
 type ClassHTB struct {
 	Class
 	Rate string
+	Ceil string
 }
 
 // Add adds class to a node.
@@ -29,6 +52,10 @@ func (class *ClassHTB) Add() error {
 		cmd = append(cmd, "rate", class.Rate)
 	}
 
+	if class.Ceil != "" {
+		cmd = append(cmd, "ceil", class.Ceil)
+	}
+
 	_, err := subprocess.RunCommand("tc", cmd...)
 	if err != nil {
 		return err
@@ -36,3 +63,36 @@ func (class *ClassHTB) Add() error {
 
 	return nil
 }
+
+// ClassStats represents the rate and counters of a qdisc class.
+type ClassStats struct {
+	Classid string `json:"handle"`
+	Rate    uint64 `json:"rate"` // In bytes per second.
+	Stats   struct {
+		Bytes   int64 `json:"bytes"`
+		Packets int64 `json:"packets"`
+		Drops   int64 `json:"drops"`
+	} `json:"stats"`
+}
+
+// GetClassStats returns the counters of the classes of a device.
+func GetClassStats(dev string) ([]ClassStats, error) {
+	output, err := subprocess.RunCommand("tc", "-s", "-j", "class", "show", "dev", dev)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClassStats([]byte(output))
+}
+
+// parseClassStats parses the JSON output of "tc -s -j class show".
+func parseClassStats(output []byte) ([]ClassStats, error) {
+	classes := []ClassStats{}
+
+	err := json.Unmarshal(output, &classes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing tc class statistics: %w", err)
+	}
+
+	return classes, nil
+}
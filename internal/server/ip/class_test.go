@@ -0,0 +1,27 @@
+package ip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClassStats(t *testing.T) {
+	output := `[{"class":"htb","handle":"1:1","root":true,"rate":12500000,"ceil":12500000,"stats":{"bytes":1500,"packets":1,"drops":0,"overlimits":0,"requeues":0,"backlog":0,"qlen":0}},{"class":"htb","handle":"1:a","parent":"1:1","leaf":"a:","stats":{"bytes":3000,"packets":2,"drops":3,"overlimits":0,"requeues":0,"backlog":0,"qlen":0}}]`
+
+	classes, err := parseClassStats([]byte(output))
+	require.NoError(t, err)
+	require.Len(t, classes, 2)
+	require.Equal(t, uint64(12500000), classes[0].Rate)
+	require.Equal(t, "1:a", classes[1].Classid)
+	require.Equal(t, int64(3000), classes[1].Stats.Bytes)
+	require.Equal(t, int64(2), classes[1].Stats.Packets)
+	require.Equal(t, int64(3), classes[1].Stats.Drops)
+
+	classes, err = parseClassStats([]byte("[]"))
+	require.NoError(t, err)
+	require.Empty(t, classes)
+
+	_, err = parseClassStats([]byte("class htb 1:1"))
+	require.Error(t, err)
+}
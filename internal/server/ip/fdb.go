@@ -0,0 +1,66 @@
+package ip
+
+import (
+	"bytes"
+	"net"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// FDB represents arguments for bridge forwarding database manipulation.
+type FDB struct {
+	DevName   string
+	Master    string
+	MAC       net.HardwareAddr
+	Permanent bool
+}
+
+// Show list forwarding database entries of the Master bridge, optionally filtered by MAC address.
+func (f *FDB) Show() ([]FDB, error) {
+	out, err := subprocess.RunCommand("bridge", "fdb", "show", "br", f.Master)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []FDB{}
+
+	for _, line := range util.SplitNTrimSpace(out, "\n", -1, true) {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+
+		mac, err := net.ParseMAC(fields[0])
+		if err != nil {
+			continue
+		}
+
+		// Check entry matches desired MAC address if specified.
+		if f.MAC != nil && !bytes.Equal(f.MAC, mac) {
+			continue
+		}
+
+		entry := FDB{MAC: mac}
+		for i, field := range fields[1:] {
+			value := ""
+			if i+2 < len(fields) {
+				value = fields[i+2]
+			}
+
+			switch field {
+			case "dev":
+				entry.DevName = value
+			case "master":
+				entry.Master = value
+			case "permanent", "static":
+				entry.Permanent = true
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -1,6 +1,9 @@
 package ip
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/lxc/incus/v6/shared/subprocess"
 )
 
@@ -39,14 +42,53 @@ func (a *ActionPolice) AddAction() []string {
 	return result
 }
 
+// ActionMirred represents an action of 'mirred' type.
+type ActionMirred struct {
+	Direction string
+	Redirect  bool
+	Dev       string
+}
+
+// AddAction generates a part of command specific for 'mirred' action.
+func (a *ActionMirred) AddAction() []string {
+	result := []string{"action", "mirred", a.Direction}
+	if a.Redirect {
+		result = append(result, "redirect")
+	} else {
+		result = append(result, "mirror")
+	}
+
+	return append(result, "dev", a.Dev)
+}
+
 // Filter represents filter object.
 type Filter struct {
 	Dev      string
 	Parent   string
 	Protocol string
+	Priority string
 	Flowid   string
 }
 
+// Delete deletes filter from node.
+func (f *Filter) Delete() error {
+	cmd := []string{"filter", "del", "dev", f.Dev}
+	if f.Parent != "" {
+		cmd = append(cmd, "parent", f.Parent)
+	}
+
+	if f.Priority != "" {
+		cmd = append(cmd, "pref", f.Priority)
+	}
+
+	_, err := subprocess.RunCommand("tc", cmd...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // U32Filter represents universal 32bit traffic control filter.
 type U32Filter struct {
 	Filter
@@ -62,6 +104,10 @@ func (u32 *U32Filter) Add() error {
 		cmd = append(cmd, "parent", u32.Parent)
 	}
 
+	if u32.Priority != "" {
+		cmd = append(cmd, "pref", u32.Priority)
+	}
+
 	cmd = append(cmd, "protocol", u32.Protocol)
 	cmd = append(cmd, "u32", "match", "u32", u32.Value, u32.Mask)
 
@@ -81,3 +127,87 @@ func (u32 *U32Filter) Add() error {
 
 	return nil
 }
+
+// FlowerFilter represents a flow based traffic control filter.
+type FlowerFilter struct {
+	Filter
+	SrcMAC  string
+	DstMAC  string
+	Classid string
+	Actions []Action
+}
+
+// Add adds flower traffic control filter to a node.
+func (flower *FlowerFilter) Add() error {
+	cmd := []string{"filter", "add", "dev", flower.Dev}
+	if flower.Parent != "" {
+		cmd = append(cmd, "parent", flower.Parent)
+	}
+
+	if flower.Priority != "" {
+		cmd = append(cmd, "pref", flower.Priority)
+	}
+
+	cmd = append(cmd, "protocol", flower.Protocol, "flower")
+
+	if flower.SrcMAC != "" {
+		cmd = append(cmd, "src_mac", flower.SrcMAC)
+	}
+
+	if flower.DstMAC != "" {
+		cmd = append(cmd, "dst_mac", flower.DstMAC)
+	}
+
+	if flower.Classid != "" {
+		cmd = append(cmd, "classid", flower.Classid)
+	}
+
+	for _, action := range flower.Actions {
+		actionCmd := action.AddAction()
+		cmd = append(cmd, actionCmd...)
+	}
+
+	_, err := subprocess.RunCommand("tc", cmd...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FilterInfo represents a filter as reported by tc.
+type FilterInfo struct {
+	Kind    string `json:"kind"`
+	Options struct {
+		Flowid  string       `json:"flowid"`
+		Actions []ActionInfo `json:"actions"`
+	} `json:"options"`
+}
+
+// ActionInfo represents a filter action as reported by tc.
+type ActionInfo struct {
+	Kind string `json:"kind"`
+	Rate uint64 `json:"rate"` // In bytes per second, only reported by recent versions of tc for police actions.
+}
+
+// GetFilters returns the filters attached to a parent of a device.
+func GetFilters(dev string, parent string) ([]FilterInfo, error) {
+	output, err := subprocess.RunCommand("tc", "-j", "filter", "show", "dev", dev, "parent", parent)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseFilters([]byte(output))
+}
+
+// parseFilters parses the JSON output of "tc -j filter show".
+func parseFilters(output []byte) ([]FilterInfo, error) {
+	filters := []FilterInfo{}
+
+	err := json.Unmarshal(output, &filters)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing tc filters: %w", err)
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,26 @@
+package ip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilters(t *testing.T) {
+	output := `[{"parent":"ffff:","protocol":"all","pref":49152,"kind":"u32","chain":0},{"parent":"ffff:","protocol":"all","pref":49152,"kind":"u32","chain":0,"options":{"fh":"800:","ht_divisor":1}},{"parent":"ffff:","protocol":"all","pref":49152,"kind":"u32","chain":0,"options":{"fh":"800::800","order":2048,"key_ht":"800","bkt":"0","flowid":"1:1","not_in_hw":true,"match":{"value":"0","mask":"0","offmask":"","off":0},"actions":[{"order":1,"kind":"police","index":1,"control_action":{"type":"drop"},"overhead":0,"rate":1250000,"burst":1048576,"mtu":65536,"ref":1,"bind":1}]}}]`
+
+	filters, err := parseFilters([]byte(output))
+	require.NoError(t, err)
+	require.Len(t, filters, 3)
+	require.Equal(t, "u32", filters[2].Kind)
+	require.Equal(t, "1:1", filters[2].Options.Flowid)
+	require.Equal(t, []ActionInfo{{Kind: "police", Rate: 1250000}}, filters[2].Options.Actions)
+	require.Empty(t, filters[0].Options.Actions)
+
+	filters, err = parseFilters([]byte("[]"))
+	require.NoError(t, err)
+	require.Empty(t, filters)
+
+	_, err = parseFilters([]byte("filter parent ffff: protocol all pref 49152 u32"))
+	require.Error(t, err)
+}
@@ -1,6 +1,9 @@
 package ip
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/lxc/incus/v6/shared/subprocess"
 )
 
@@ -8,6 +11,7 @@ import (
 type Qdisc struct {
 	Dev     string
 	Handle  string
+	Parent  string
 	Root    bool
 	Ingress bool
 }
@@ -18,6 +22,10 @@ func (qdisc *Qdisc) mainCmd() []string {
 		cmd = append(cmd, "handle", qdisc.Handle)
 	}
 
+	if qdisc.Parent != "" {
+		cmd = append(cmd, "parent", qdisc.Parent)
+	}
+
 	if qdisc.Root {
 		cmd = append(cmd, "root")
 	}
@@ -81,3 +89,51 @@ func (qdisc *QdiscHTB) Add() error {
 
 	return nil
 }
+
+// QdiscFqCodel represents the fair queuing controlled delay qdisc object.
+type QdiscFqCodel struct {
+	Qdisc
+}
+
+// Add adds qdisc to a node.
+func (qdisc *QdiscFqCodel) Add() error {
+	cmd := qdisc.mainCmd()
+	cmd = append(cmd, "fq_codel")
+
+	_, err := subprocess.RunCommand("tc", cmd...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// QdiscInfo represents a qdisc as reported by tc.
+type QdiscInfo struct {
+	Kind   string `json:"kind"`
+	Handle string `json:"handle"`
+	Parent string `json:"parent"`
+	Root   bool   `json:"root"`
+}
+
+// GetQdiscs returns the qdiscs of a device.
+func GetQdiscs(dev string) ([]QdiscInfo, error) {
+	output, err := subprocess.RunCommand("tc", "-j", "qdisc", "show", "dev", dev)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseQdiscs([]byte(output))
+}
+
+// parseQdiscs parses the JSON output of "tc -j qdisc show".
+func parseQdiscs(output []byte) ([]QdiscInfo, error) {
+	qdiscs := []QdiscInfo{}
+
+	err := json.Unmarshal(output, &qdiscs)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing tc qdiscs: %w", err)
+	}
+
+	return qdiscs, nil
+}
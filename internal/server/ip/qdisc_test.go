@@ -0,0 +1,22 @@
+package ip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQdiscs(t *testing.T) {
+	output := `[{"kind":"htb","handle":"1:","root":true,"refcnt":2,"options":{"r2q":10,"default":"0x10","direct_packets_stat":0,"direct_qlen":1000}},{"kind":"ingress","handle":"ffff:","parent":"ffff:fff1","options":{}}]`
+
+	qdiscs, err := parseQdiscs([]byte(output))
+	require.NoError(t, err)
+	require.Equal(t, []QdiscInfo{{Kind: "htb", Handle: "1:", Root: true}, {Kind: "ingress", Handle: "ffff:", Parent: "ffff:fff1"}}, qdiscs)
+
+	qdiscs, err = parseQdiscs([]byte("[]"))
+	require.NoError(t, err)
+	require.Empty(t, qdiscs)
+
+	_, err = parseQdiscs([]byte("qdisc htb 1: root"))
+	require.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package lifecycle
+
+import (
+	"sync"
+
+	"github.com/lxc/incus/v6/internal/server/events"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+var instanceEnrichmentMu sync.RWMutex
+
+// instanceEnrichment holds the instance fields embedded in the lifecycle events of instances.
+var instanceEnrichment events.LifecycleEnrichment
+
+// SetInstanceEnrichment sets the instance fields embedded in the lifecycle events of instances.
+func SetInstanceEnrichment(enrichment events.LifecycleEnrichment) {
+	instanceEnrichmentMu.Lock()
+	defer instanceEnrichmentMu.Unlock()
+
+	instanceEnrichment = enrichment
+}
+
+// enrichInstanceEvent embeds the fields of the instance selected for the action in its lifecycle event.
+// The fields come from the instance the event is created for, so they are available even once it's deleted.
+func enrichInstanceEvent(inst instance, event api.EventLifecycle) api.EventLifecycle {
+	instanceEnrichmentMu.RLock()
+	defer instanceEnrichmentMu.RUnlock()
+
+	if len(instanceEnrichment) == 0 {
+		return event
+	}
+
+	event.Enrichment = instanceEnrichment.Build(event.Action, inst.ExpandedConfig())
+
+	return event
+}
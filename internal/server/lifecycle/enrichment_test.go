@@ -0,0 +1,56 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/events"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// enrichmentInstance is a fake instance with an expanded configuration.
+type enrichmentInstance struct {
+	config map[string]string
+}
+
+func (i *enrichmentInstance) Name() string {
+	return "c1"
+}
+
+func (i *enrichmentInstance) Project() api.Project {
+	return api.Project{Name: "foo"}
+}
+
+func (i *enrichmentInstance) Operation() *operations.Operation {
+	return nil
+}
+
+func (i *enrichmentInstance) ExpandedConfig() map[string]string {
+	return i.config
+}
+
+func TestInstanceEventEnrichment(t *testing.T) {
+	inst := &enrichmentInstance{config: map[string]string{"user.team": "web", "limits.cpu": "4"}}
+
+	// Events aren't enriched by default.
+	assert.Nil(t, InstanceStarted.Event(inst, nil).Enrichment)
+
+	enrichment, err := events.ParseLifecycleEnrichment("instance-*:labels")
+	require.NoError(t, err)
+
+	SetInstanceEnrichment(enrichment)
+	t.Cleanup(func() { SetInstanceEnrichment(nil) })
+
+	// The fields come from the instance the event is created for, including when it's deleted.
+	for _, event := range []api.EventLifecycle{
+		InstanceStarted.Event(inst, nil),
+		InstanceDeleted.Event(inst, nil),
+		InstanceSnapshotCreated.Event(inst, nil),
+	} {
+		require.NotNil(t, event.Enrichment, event.Action)
+		assert.Equal(t, map[string]string{"team": "web"}, event.Enrichment.Labels, event.Action)
+	}
+}
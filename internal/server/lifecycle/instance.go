@@ -11,6 +11,7 @@ type instance interface {
 	Name() string
 	Project() api.Project
 	Operation() *operations.Operation
+	ExpandedConfig() map[string]string
 }
 
 // InstanceAction represents a lifecycle event action for instances.
@@ -18,25 +19,35 @@ type InstanceAction string
 
 // All supported lifecycle events for instances.
 const (
-	InstanceCreated          = InstanceAction(api.EventLifecycleInstanceCreated)
-	InstanceStarted          = InstanceAction(api.EventLifecycleInstanceStarted)
-	InstanceStopped          = InstanceAction(api.EventLifecycleInstanceStopped)
-	InstanceShutdown         = InstanceAction(api.EventLifecycleInstanceShutdown)
-	InstanceRestarted        = InstanceAction(api.EventLifecycleInstanceRestarted)
-	InstancePaused           = InstanceAction(api.EventLifecycleInstancePaused)
-	InstanceReady            = InstanceAction(api.EventLifecycleInstanceReady)
-	InstanceResumed          = InstanceAction(api.EventLifecycleInstanceResumed)
-	InstanceRestored         = InstanceAction(api.EventLifecycleInstanceRestored)
-	InstanceDeleted          = InstanceAction(api.EventLifecycleInstanceDeleted)
-	InstanceRenamed          = InstanceAction(api.EventLifecycleInstanceRenamed)
-	InstanceUpdated          = InstanceAction(api.EventLifecycleInstanceUpdated)
-	InstanceExec             = InstanceAction(api.EventLifecycleInstanceExec)
-	InstanceConsole          = InstanceAction(api.EventLifecycleInstanceConsole)
-	InstanceConsoleRetrieved = InstanceAction(api.EventLifecycleInstanceConsoleRetrieved)
-	InstanceConsoleReset     = InstanceAction(api.EventLifecycleInstanceConsoleReset)
-	InstanceFileRetrieved    = InstanceAction(api.EventLifecycleInstanceFileRetrieved)
-	InstanceFilePushed       = InstanceAction(api.EventLifecycleInstanceFilePushed)
-	InstanceFileDeleted      = InstanceAction(api.EventLifecycleInstanceFileDeleted)
+	InstanceCreated            = InstanceAction(api.EventLifecycleInstanceCreated)
+	InstanceStarted            = InstanceAction(api.EventLifecycleInstanceStarted)
+	InstanceStopped            = InstanceAction(api.EventLifecycleInstanceStopped)
+	InstanceShutdown           = InstanceAction(api.EventLifecycleInstanceShutdown)
+	InstanceRestarted          = InstanceAction(api.EventLifecycleInstanceRestarted)
+	InstanceAutoRestarted      = InstanceAction(api.EventLifecycleInstanceAutoRestarted)
+	InstanceAutoRestartFailed  = InstanceAction(api.EventLifecycleInstanceAutoRestartFailed)
+	InstancePaused             = InstanceAction(api.EventLifecycleInstancePaused)
+	InstanceReady              = InstanceAction(api.EventLifecycleInstanceReady)
+	InstanceResumed            = InstanceAction(api.EventLifecycleInstanceResumed)
+	InstanceRestored           = InstanceAction(api.EventLifecycleInstanceRestored)
+	InstanceDeleted            = InstanceAction(api.EventLifecycleInstanceDeleted)
+	InstanceRenamed            = InstanceAction(api.EventLifecycleInstanceRenamed)
+	InstanceUpdated            = InstanceAction(api.EventLifecycleInstanceUpdated)
+	InstanceLimitReached       = InstanceAction(api.EventLifecycleInstanceLimitReached)
+	InstanceHealthChanged      = InstanceAction(api.EventLifecycleInstanceHealthChanged)
+	InstanceIdleSuspended      = InstanceAction(api.EventLifecycleInstanceIdleSuspended)
+	InstanceOOMKilled          = InstanceAction(api.EventLifecycleInstanceOOMKilled)
+	InstanceRefreshed          = InstanceAction(api.EventLifecycleInstanceRefreshed)
+	InstanceKernelModuleLoaded = InstanceAction(api.EventLifecycleInstanceKernelModuleLoaded)
+	InstanceExec               = InstanceAction(api.EventLifecycleInstanceExec)
+	InstanceConsole            = InstanceAction(api.EventLifecycleInstanceConsole)
+	InstanceConsoleRetrieved   = InstanceAction(api.EventLifecycleInstanceConsoleRetrieved)
+	InstanceConsoleReset       = InstanceAction(api.EventLifecycleInstanceConsoleReset)
+	InstanceFileRetrieved      = InstanceAction(api.EventLifecycleInstanceFileRetrieved)
+	InstanceFilePushed         = InstanceAction(api.EventLifecycleInstanceFilePushed)
+	InstanceFileDeleted        = InstanceAction(api.EventLifecycleInstanceFileDeleted)
+	InstanceDrainStarted       = InstanceAction(api.EventLifecycleInstanceDrainStarted)
+	InstanceDrainFinished      = InstanceAction(api.EventLifecycleInstanceDrainFinished)
 )
 
 // Event creates the lifecycle event for an action on an instance.
@@ -48,12 +59,12 @@ func (a InstanceAction) Event(inst instance, ctx map[string]any) api.EventLifecy
 		requestor = inst.Operation().Requestor()
 	}
 
-	return api.EventLifecycle{
+	return enrichInstanceEvent(inst, api.EventLifecycle{
 		Action:    string(a),
 		Source:    url.String(),
 		Context:   ctx,
 		Requestor: requestor,
 		Name:      inst.Name(),
 		Project:   inst.Project().Name,
-	}
+	})
 }
@@ -27,10 +27,10 @@ func (a InstanceBackupAction) Event(fullBackupName string, inst instance, ctx ma
 		requestor = inst.Operation().Requestor()
 	}
 
-	return api.EventLifecycle{
+	return enrichInstanceEvent(inst, api.EventLifecycle{
 		Action:    string(a),
 		Source:    u.String(),
 		Context:   ctx,
 		Requestor: requestor,
-	}
+	})
 }
@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// InstanceCoreDumpAction represents a lifecycle event action for instance core dumps.
+type InstanceCoreDumpAction string
+
+// All supported lifecycle events for instance core dumps.
+const (
+	InstanceCoreDumpCreated   = InstanceCoreDumpAction(api.EventLifecycleInstanceCoreDumpCreated)
+	InstanceCoreDumpDeleted   = InstanceCoreDumpAction(api.EventLifecycleInstanceCoreDumpDeleted)
+	InstanceCoreDumpRetrieved = InstanceCoreDumpAction(api.EventLifecycleInstanceCoreDumpRetrieved)
+)
+
+// Event creates the lifecycle event for an action on an instance core dump.
+func (a InstanceCoreDumpAction) Event(file string, inst instance, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "instances", inst.Name(), "coredumps", file).Project(inst.Project().Name)
+
+	return enrichInstanceEvent(inst, api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	})
+}
@@ -19,10 +19,10 @@ const (
 func (a InstanceMetadataTemplateAction) Event(inst instance, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
 	u := api.NewURL().Path(version.APIVersion, "instances", inst.Name(), "metadata", "templates").Project(inst.Project().Name)
 
-	return api.EventLifecycle{
+	return enrichInstanceEvent(inst, api.EventLifecycle{
 		Action:    string(a),
 		Source:    u.String(),
 		Context:   ctx,
 		Requestor: requestor,
-	}
+	})
 }
@@ -0,0 +1,30 @@
+package lifecycle
+
+import (
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// InstancePoolAction represents a lifecycle event action for instance pools.
+type InstancePoolAction string
+
+// All supported lifecycle events for instance pools.
+const (
+	InstancePoolAcquired = InstancePoolAction(api.EventLifecycleInstancePoolAcquired)
+	InstancePoolCreated  = InstancePoolAction(api.EventLifecycleInstancePoolCreated)
+	InstancePoolDeleted  = InstancePoolAction(api.EventLifecycleInstancePoolDeleted)
+	InstancePoolReleased = InstancePoolAction(api.EventLifecycleInstancePoolReleased)
+	InstancePoolUpdated  = InstancePoolAction(api.EventLifecycleInstancePoolUpdated)
+)
+
+// Event creates the lifecycle event for an action on an instance pool.
+func (a InstancePoolAction) Event(name string, projectName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "instance-pools", name).Project(projectName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
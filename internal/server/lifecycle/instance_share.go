@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// InstanceShareAction represents a lifecycle event action for instance shares.
+type InstanceShareAction string
+
+// All supported lifecycle events for instance shares.
+const (
+	InstanceShareCreated = InstanceShareAction(api.EventLifecycleInstanceShareCreated)
+	InstanceShareDeleted = InstanceShareAction(api.EventLifecycleInstanceShareDeleted)
+	InstanceShareUsed    = InstanceShareAction(api.EventLifecycleInstanceShareUsed)
+)
+
+// Event creates the lifecycle event for an action on an instance share.
+func (a InstanceShareAction) Event(id string, instanceName string, projectName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "instances", instanceName, "shares", id).Project(projectName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
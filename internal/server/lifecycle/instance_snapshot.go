@@ -27,10 +27,10 @@ func (a InstanceSnapshotAction) Event(inst instance, ctx map[string]any) api.Eve
 		requestor = inst.Operation().Requestor()
 	}
 
-	return api.EventLifecycle{
+	return enrichInstanceEvent(inst, api.EventLifecycle{
 		Action:    string(a),
 		Source:    u.String(),
 		Context:   ctx,
 		Requestor: requestor,
-	}
+	})
 }
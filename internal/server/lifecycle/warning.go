@@ -13,6 +13,7 @@ const (
 	WarningAcknowledged = WarningAction(api.EventLifecycleWarningAcknowledged)
 	WarningReset        = WarningAction(api.EventLifecycleWarningReset)
 	WarningDeleted      = WarningAction(api.EventLifecycleWarningDeleted)
+	WarningResolved     = WarningAction(api.EventLifecycleWarningResolved)
 )
 
 // Event creates the lifecycle event for an action on a warning.
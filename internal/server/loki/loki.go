@@ -314,7 +314,7 @@ func (c *Client) HandleEvent(event api.Event) {
 		}
 
 		entry.Line = fmt.Sprintf("%s%s", messagePrefix, lifecycleEvent.Action)
-	} else if event.Type == api.EventTypeLogging || event.Type == api.EventTypeNetworkACL {
+	} else if event.Type == api.EventTypeLogging || event.Type == api.EventTypeNetworkACL || event.Type == api.EventTypeInstanceLog {
 		logEvent := api.EventLogging{}
 
 		err := json.Unmarshal(event.Metadata, &logEvent)
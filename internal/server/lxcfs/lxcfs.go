@@ -0,0 +1,467 @@
+// Package lxcfs supervises the LXCFS instance providing the containers with their /proc and /sys views.
+package lxcfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/linux"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/subprocess"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// Path is where LXCFS exposes its views.
+const Path = "/var/lib/lxcfs"
+
+// checkInterval is how often the health of LXCFS is checked.
+const checkInterval = 5 * time.Second
+
+// startTimeout is how long LXCFS is given to serve its views once started.
+const startTimeout = 10 * time.Second
+
+// maxStackedMounts is how many mounts left behind by crashed LXCFS processes are cleared at most.
+const maxStackedMounts = 10
+
+// procPath is where the running processes are looked up.
+var procPath = "/proc"
+
+// serviceState returns the state of the LXCFS service of the system, as reported by systemd.
+var serviceState = func() string {
+	state, _ := subprocess.RunCommand("systemctl", "is-active", "lxcfs.service")
+
+	return strings.TrimSpace(state)
+}
+
+// Options are the features LXCFS is started with.
+type Options struct {
+	LoadAvg bool
+	CPUView bool
+}
+
+// args returns the arguments LXCFS is started with.
+func (o Options) args() []string {
+	// LXCFS locks its own PID file, keep it apart from the one of a system LXCFS.
+	args := []string{"--pidfile", internalUtil.RunPath("lxcfs.lock")}
+
+	if o.LoadAvg {
+		args = append(args, "--enable-loadavg")
+	}
+
+	if o.CPUView {
+		args = append(args, "--enable-cfs")
+	}
+
+	return append(args, Path)
+}
+
+// optionsFromArgs returns the options of an LXCFS process from its arguments.
+func optionsFromArgs(args []string) Options {
+	return Options{
+		LoadAvg: slices.Contains(args, "--enable-loadavg"),
+		CPUView: slices.Contains(args, "--enable-cfs"),
+	}
+}
+
+// Available returns whether LXCFS is serving its views.
+func Available() bool {
+	_, err := os.Stat(filepath.Join(Path, "proc", "meminfo"))
+
+	return err == nil
+}
+
+// Views returns the paths of the files served by LXCFS, relative to its mount point and to the root of a container.
+func Views() []string {
+	views := []string{}
+
+	entries, err := os.ReadDir(filepath.Join(Path, "proc"))
+	if err == nil {
+		for _, entry := range entries {
+			views = append(views, filepath.Join("proc", entry.Name()))
+		}
+	}
+
+	if util.PathExists(filepath.Join(Path, "sys", "devices", "system", "cpu", "online")) {
+		views = append(views, filepath.Join("sys", "devices", "system", "cpu", "online"))
+	}
+
+	return views
+}
+
+// pidPath returns the path of the file recording the LXCFS process started by the server.
+func pidPath() string {
+	return internalUtil.RunPath("lxcfs.pid")
+}
+
+// isLXCFS returns whether the process of the given PID is a running LXCFS.
+func isLXCFS(pid int64) bool {
+	cmdline, err := os.ReadFile(filepath.Join(procPath, strconv.FormatInt(pid, 10), "cmdline"))
+	if err != nil {
+		return false
+	}
+
+	name, _, _ := strings.Cut(string(cmdline), "\x00")
+
+	return filepath.Base(name) == "lxcfs"
+}
+
+// foreignPIDs returns the PIDs of the running LXCFS processes other than the one of the given PID.
+func foreignPIDs(own int64) []int64 {
+	pids := []int64{}
+
+	entries, err := os.ReadDir(procPath)
+	if err != nil {
+		return pids
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || pid == own {
+			continue
+		}
+
+		if isLXCFS(pid) {
+			pids = append(pids, pid)
+		}
+	}
+
+	return pids
+}
+
+// unmountStale clears the mounts left behind by crashed LXCFS processes, which are stacked when several of them
+// crashed in a row.
+func unmountStale() error {
+	for i := 0; linux.IsMountPoint(Path); i++ {
+		if i == maxStackedMounts {
+			return fmt.Errorf("Failed clearing the mounts stacked on %q", Path)
+		}
+
+		err := unix.Unmount(Path, unix.MNT_DETACH)
+		if err != nil {
+			return fmt.Errorf("Failed unmounting %q: %w", Path, err)
+		}
+	}
+
+	return nil
+}
+
+// action is what a check does with LXCFS.
+type action int
+
+const (
+	// actionKeep leaves LXCFS as it is.
+	actionKeep action = iota
+
+	// actionStart starts LXCFS as nothing serves its views yet.
+	actionStart
+
+	// actionRestart restarts LXCFS after it failed.
+	actionRestart
+
+	// actionReconfigure restarts LXCFS to apply new options.
+	actionReconfigure
+)
+
+// lxcfsState is the state of LXCFS a check decides from.
+type lxcfsState struct {
+	// Whether the server started LXCFS, or adopted the one started by a previous run.
+	owned bool
+
+	// Whether the LXCFS process of the server is running.
+	running bool
+
+	// Whether the views are served.
+	available bool
+
+	// Whether the mount point is still mounted, while its views aren't served.
+	stale bool
+
+	// Whether the LXCFS process of the server runs with the requested options.
+	sameOptions bool
+
+	// Whether another LXCFS is running, or its service is being restarted by systemd.
+	foreign bool
+
+	// Whether running containers use the views, which can't be replaced under them without a remount.
+	inUse bool
+}
+
+// nextAction returns what to do with LXCFS in the given state.
+func nextAction(state lxcfsState) action {
+	if state.running {
+		if !state.available {
+			return actionRestart
+		}
+
+		// The new options apply on the next restart, stopping LXCFS would break the views of the containers.
+		if state.sameOptions || state.inUse {
+			return actionKeep
+		}
+
+		return actionReconfigure
+	}
+
+	// Another LXCFS serves the views or is about to, starting one as well would stack their mounts.
+	if state.foreign || state.available {
+		return actionKeep
+	}
+
+	if state.owned || state.stale {
+		return actionRestart
+	}
+
+	return actionStart
+}
+
+// Manager keeps LXCFS running, restarting it when it fails.
+type Manager struct {
+	mu sync.Mutex
+
+	managed   bool
+	options   Options
+	process   *subprocess.Process
+	restarts  int64
+	cancel    context.CancelFunc
+	done      chan struct{}
+	onRestart func()
+	inUse     func() bool
+}
+
+// NewManager returns a manager calling onRestart every time LXCFS is started, for its views to be mounted again in
+// the running containers. LXCFS is only restarted to apply new options when inUse reports that no running container
+// uses its views.
+func NewManager(onRestart func(), inUse func() bool) *Manager {
+	return &Manager{onRestart: onRestart, inUse: inUse}
+}
+
+// Enable starts supervising LXCFS with the given options. An LXCFS already running with other options is restarted
+// once no running container uses its views.
+func (m *Manager) Enable(options Options) error {
+	_, err := exec.LookPath("lxcfs")
+	if err != nil {
+		return errors.New("LXCFS must be installed to be managed by the server")
+	}
+
+	m.Stop()
+
+	m.mu.Lock()
+	m.managed = true
+	m.options = options
+	m.mu.Unlock()
+
+	err = m.check()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	m.mu.Lock()
+	m.cancel = cancel
+	m.done = done
+	m.mu.Unlock()
+
+	go m.run(ctx, done)
+
+	return nil
+}
+
+// Disable stops supervising LXCFS. It's left running so that the containers keep their views.
+func (m *Manager) Disable() {
+	m.Stop()
+
+	m.mu.Lock()
+	m.managed = false
+	m.options = Options{}
+	m.mu.Unlock()
+}
+
+// Stop stops the supervision loop, leaving LXCFS running so that the containers keep their views.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	cancel := m.cancel
+	done := m.done
+	m.cancel = nil
+	m.done = nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Status returns the status of LXCFS, with the options of the running LXCFS process.
+func (m *Manager) Status() api.ServerLXCFS {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := api.ServerLXCFS{
+		Managed:  m.managed,
+		Running:  Available(),
+		Restarts: m.restarts,
+	}
+
+	if m.process != nil && isLXCFS(m.process.PID) {
+		options := optionsFromArgs(m.process.Args)
+		status.PID = m.process.PID
+		status.LoadAvg = options.LoadAvg
+		status.CPUView = options.CPUView
+	}
+
+	return status
+}
+
+// run checks the health of LXCFS until the context is cancelled.
+func (m *Manager) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := m.check()
+		if err != nil {
+			logger.Warn("Failed restarting LXCFS", logger.Ctx{"err": err})
+		}
+	}
+}
+
+// state returns the state of LXCFS, along with the process of the server, adopting the one started by a previous
+// run of the server.
+func (m *Manager) state(args []string, p *subprocess.Process) (lxcfsState, *subprocess.Process) {
+	if p == nil && util.PathExists(pidPath()) {
+		p, _ = subprocess.ImportProcess(pidPath())
+	}
+
+	state := lxcfsState{
+		owned:     p != nil,
+		running:   p != nil && isLXCFS(p.PID),
+		available: Available(),
+	}
+
+	if state.running {
+		state.sameOptions = slices.Equal(p.Args, args)
+
+		if state.available && !state.sameOptions && m.inUse != nil {
+			state.inUse = m.inUse()
+		}
+	} else {
+		own := int64(0)
+		if p != nil {
+			own = p.PID
+		}
+
+		state.foreign = len(foreignPIDs(own)) > 0 || serviceState() == "activating"
+		state.stale = !state.available && linux.IsMountPoint(Path)
+	}
+
+	return state, p
+}
+
+// check starts LXCFS if it isn't serving its views, or restarts it with the new options once they can be applied.
+// A process started by a previous run of the server is adopted, and one started outside of it is left alone.
+func (m *Manager) check() error {
+	m.mu.Lock()
+	args := m.options.args()
+	p := m.process
+	m.mu.Unlock()
+
+	state, p := m.state(args, p)
+
+	act := nextAction(state)
+	if act == actionKeep {
+		if state.running {
+			m.mu.Lock()
+			m.process = p
+			m.mu.Unlock()
+		}
+
+		return nil
+	}
+
+	if state.running {
+		err := p.Stop()
+		if err != nil && !errors.Is(err, subprocess.ErrNotRunning) {
+			return fmt.Errorf("Failed stopping LXCFS: %w", err)
+		}
+	}
+
+	err := unmountStale()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(Path, 0o755)
+	if err != nil {
+		return err
+	}
+
+	p, err = subprocess.NewProcess("lxcfs", args, "", internalUtil.LogPath("lxcfs.log"))
+	if err != nil {
+		return err
+	}
+
+	err = p.Start(context.Background())
+	if err != nil {
+		return fmt.Errorf("Failed starting LXCFS: %w", err)
+	}
+
+	err = p.Save(pidPath())
+	if err != nil {
+		_ = p.Stop()
+		return err
+	}
+
+	m.mu.Lock()
+	m.process = p
+	if act == actionRestart {
+		m.restarts++
+	}
+
+	m.mu.Unlock()
+
+	deadline := time.Now().Add(startTimeout)
+	for !Available() {
+		if !isLXCFS(p.PID) || time.Now().After(deadline) {
+			return errors.New("LXCFS didn't start serving its views, see lxcfs.log")
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	switch act {
+	case actionRestart:
+		logger.Warn("Restarted LXCFS after failure", logger.Ctx{"pid": p.PID})
+	case actionReconfigure:
+		logger.Info("Restarted LXCFS with new options", logger.Ctx{"pid": p.PID})
+	default:
+		logger.Info("Started LXCFS", logger.Ctx{"pid": p.PID})
+	}
+
+	if m.onRestart != nil {
+		m.onRestart()
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package lxcfs
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+)
+
+func TestOptionsArgs(t *testing.T) {
+	pidfile := internalUtil.RunPath("lxcfs.lock")
+
+	assert.Equal(t, []string{"--pidfile", pidfile, Path}, Options{}.args())
+	assert.Equal(t, []string{"--pidfile", pidfile, "--enable-loadavg", Path}, Options{LoadAvg: true}.args())
+	assert.Equal(t, []string{"--pidfile", pidfile, "--enable-loadavg", "--enable-cfs", Path}, Options{LoadAvg: true, CPUView: true}.args())
+
+	for _, options := range []Options{{}, {LoadAvg: true}, {CPUView: true}, {LoadAvg: true, CPUView: true}} {
+		assert.Equal(t, options, optionsFromArgs(options.args()))
+	}
+}
+
+func TestNextAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    lxcfsState
+		expected action
+	}{
+		{"Nothing running", lxcfsState{}, actionStart},
+		{"Serving", lxcfsState{owned: true, running: true, available: true, sameOptions: true}, actionKeep},
+		{"Hung", lxcfsState{owned: true, running: true, sameOptions: true}, actionRestart},
+		{"Crashed", lxcfsState{owned: true, stale: true}, actionRestart},
+		{"Crashed and unmounted", lxcfsState{owned: true}, actionRestart},
+		{"Other options, unused", lxcfsState{owned: true, running: true, available: true}, actionReconfigure},
+		{"Other options, in use", lxcfsState{owned: true, running: true, available: true, inUse: true}, actionKeep},
+		{"Served from outside", lxcfsState{available: true, foreign: true}, actionKeep},
+		{"Service restarting after a crash", lxcfsState{stale: true, foreign: true}, actionKeep},
+		{"Crashed, restarted from outside", lxcfsState{owned: true, stale: true, foreign: true}, actionKeep},
+		{"Crashed outside, not restarted", lxcfsState{stale: true}, actionRestart},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		assert.Equal(t, tt.expected, nextAction(tt.state))
+	}
+}
+
+func TestForeignPIDs(t *testing.T) {
+	oldProcPath := procPath
+	defer func() { procPath = oldProcPath }()
+
+	procPath = t.TempDir()
+
+	processes := map[string]string{
+		"10":   "/usr/bin/lxcfs\x00/var/lib/lxcfs\x00",
+		"20":   "lxcfs\x00--enable-loadavg\x00/var/lib/lxcfs\x00",
+		"30":   "/usr/sbin/sshd\x00-D\x00",
+		"self": "/usr/bin/lxcfs\x00",
+	}
+
+	for pid, cmdline := range processes {
+		require.NoError(t, os.Mkdir(filepath.Join(procPath, pid), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(procPath, pid, "cmdline"), []byte(cmdline), 0o644))
+	}
+
+	assert.ElementsMatch(t, []int64{10, 20}, foreignPIDs(0))
+	assert.Equal(t, []int64{10}, foreignPIDs(20))
+	assert.True(t, isLXCFS(10))
+	assert.False(t, isLXCFS(30))
+	assert.False(t, isLXCFS(40))
+}
+
+func TestManagerStatus(t *testing.T) {
+	m := NewManager(nil, nil)
+
+	status := m.Status()
+	assert.False(t, status.Managed)
+	assert.Equal(t, int64(0), status.PID)
+	assert.Equal(t, int64(0), status.Restarts)
+	assert.False(t, status.LoadAvg)
+	assert.False(t, status.CPUView)
+}
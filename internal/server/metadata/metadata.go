@@ -3,6 +3,7 @@ package metadata
 import (
 	"embed"
 	"encoding/json"
+	"strings"
 )
 
 var Data map[string]any
@@ -24,3 +25,61 @@ func init() {
 
 	Data = data
 }
+
+// LiveUpdate returns whether the documented configuration key of the entity can be updated while running.
+// The second return value indicates whether the key is documented at all.
+func LiveUpdate(entity string, key string) (bool, bool) {
+	configs, _ := Data["configs"].(map[string]any)
+	groups, _ := configs[entity].(map[string]any)
+
+	for _, group := range groups {
+		groupData, _ := group.(map[string]any)
+		keys, _ := groupData["keys"].([]any)
+
+		for _, entry := range keys {
+			entryData, _ := entry.(map[string]any)
+
+			for name, fields := range entryData {
+				if !keyMatches(name, key) {
+					continue
+				}
+
+				fieldsData, _ := fields.(map[string]any)
+				return fieldsData["liveupdate"] == "yes", true
+			}
+		}
+	}
+
+	return false, false
+}
+
+// keyMatches checks whether the key matches the documented name, which may contain a `<name>` placeholder
+// for a single component or a trailing `*` wildcard.
+func keyMatches(name string, key string) bool {
+	if name == key {
+		return true
+	}
+
+	prefix, found := strings.CutSuffix(name, "*")
+	if found {
+		return strings.HasPrefix(key, prefix) && len(key) > len(prefix)
+	}
+
+	nameFields := strings.Split(name, ".")
+	keyFields := strings.Split(key, ".")
+	if len(nameFields) != len(keyFields) {
+		return false
+	}
+
+	for i, field := range nameFields {
+		if strings.HasPrefix(field, "<") && strings.HasSuffix(field, ">") && keyFields[i] != "" {
+			continue
+		}
+
+		if field != keyFields[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,28 @@
+package metadata
+
+import (
+	"testing"
+)
+
+func TestLiveUpdate(t *testing.T) {
+	tests := []struct {
+		key        string
+		liveUpdate bool
+		found      bool
+	}{
+		{key: "limits.memory", liveUpdate: true, found: true},
+		{key: "security.privileged", liveUpdate: false, found: true},
+		{key: "linux.sysctl.net.ipv4.ip_forward", liveUpdate: false, found: true},
+		{key: "ssh.authorized_keys.admin", liveUpdate: true, found: true},
+		{key: "ssh.authorized_keys.admin.extra", liveUpdate: false, found: false},
+		{key: "linux.sysctl.", liveUpdate: false, found: false},
+		{key: "unknown.key", liveUpdate: false, found: false},
+	}
+
+	for _, test := range tests {
+		liveUpdate, found := LiveUpdate("instance", test.key)
+		if liveUpdate != test.liveUpdate || found != test.found {
+			t.Errorf("LiveUpdate(%q) = %v, %v; want %v, %v", test.key, liveUpdate, found, test.liveUpdate, test.found)
+		}
+	}
+}
@@ -65,6 +65,7 @@ type MemoryMetrics struct {
 	UnevictableBytes    uint64 `json:"memory_unevictable_bytes" yaml:"memory_unevictable_bytes"`
 	WritebackBytes      uint64 `json:"memory_writeback_bytes" yaml:"memory_writeback_bytes"`
 	OOMKills            uint64 `json:"memory_oom_kills" yaml:"memory_oom_kills"`
+	OOMKillTask         string `json:"memory_oom_kill_task,omitempty" yaml:"memory_oom_kill_task,omitempty"`
 }
 
 // NetworkMetrics represents network metrics for an instance.
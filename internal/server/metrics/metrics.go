@@ -65,6 +65,11 @@ func (m *MetricSet) AddSamples(metricType MetricType, samples ...Sample) {
 	m.set[metricType] = append(m.set[metricType], samples...)
 }
 
+// Samples returns the samples of the type metricType from the MetricSet.
+func (m *MetricSet) Samples(metricType MetricType) []Sample {
+	return m.set[metricType]
+}
+
 // Merge merges two MetricSets. Missing labels from m's samples are added to all samples in n.
 func (m *MetricSet) Merge(metricSet *MetricSet) {
 	if metricSet == nil {
@@ -109,7 +114,7 @@ func (m *MetricSet) String() string {
 		metricTypeName := ""
 
 		// ProcsTotal is a gauge according to the OpenMetrics spec as its value can decrease.
-		if metricType == ProcsTotal || metricType == CPUs || metricType == GoGoroutines || metricType == GoHeapObjects {
+		if metricType == ProcsTotal || metricType == FileDescriptors || metricType == GPUUtilizationPercent || metricType == CPUs || metricType == GoGoroutines || metricType == GoHeapObjects {
 			metricTypeName = "gauge"
 		} else if strings.HasSuffix(MetricNames[metricType], "_total") || strings.HasSuffix(MetricNames[metricType], "_seconds") {
 			metricTypeName = "counter"
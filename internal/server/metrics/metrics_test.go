@@ -1,9 +1,13 @@
 package metrics
 
 import (
+	"math"
 	"testing"
+	"time"
 
+	"github.com/klauspost/compress/snappy"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/lxc/incus/v6/internal/server/auth"
 )
@@ -56,3 +60,58 @@ func TestMetricSet_FilterSamples(t *testing.T) {
 		require.Contains(t, hasKeys, "project")
 	}
 }
+
+func TestMetricSet_RemoteWrite(t *testing.T) {
+	m := NewMetricSet(map[string]string{"project": "default", "name": "jammy"})
+	m.AddSamples(CPUSecondsTotal, Sample{Value: 1.5, Labels: map[string]string{"mode": "user", "cpu": ""}})
+
+	timestamp := time.Unix(1714644912, 0)
+
+	request, err := snappy.Decode(nil, m.RemoteWrite(timestamp))
+	require.NoError(t, err)
+
+	// consume returns the content of the next length-delimited field, checking its number.
+	consume := func(data []byte, number protowire.Number) ([]byte, []byte) {
+		num, typ, n := protowire.ConsumeTag(data)
+		require.Positive(t, n)
+		require.Equal(t, number, num)
+		require.Equal(t, protowire.BytesType, typ)
+
+		value, m := protowire.ConsumeBytes(data[n:])
+		require.Positive(t, m)
+
+		return value, data[n+m:]
+	}
+
+	series, rest := consume(request, 1)
+	require.Empty(t, rest)
+
+	// Labels are sorted, and the empty ones are skipped.
+	labels := [][2]string{}
+	for len(series) > 0 {
+		num, _, _ := protowire.ConsumeTag(series)
+		if num != 1 {
+			break
+		}
+
+		var label []byte
+		label, series = consume(series, 1)
+
+		name, rest := consume(label, 1)
+		value, _ := consume(rest, 2)
+		labels = append(labels, [2]string{string(name), string(value)})
+	}
+
+	require.Equal(t, [][2]string{{"__name__", "incus_cpu_seconds_total"}, {"mode", "user"}, {"name", "jammy"}, {"project", "default"}}, labels)
+
+	sample, rest := consume(series, 2)
+	require.Empty(t, rest)
+
+	_, _, n := protowire.ConsumeTag(sample)
+	value, m2 := protowire.ConsumeFixed64(sample[n:])
+	require.Equal(t, 1.5, math.Float64frombits(value))
+
+	_, _, n2 := protowire.ConsumeTag(sample[n+m2:])
+	ts, _ := protowire.ConsumeVarint(sample[n+m2+n2:])
+	require.Equal(t, uint64(timestamp.UnixMilli()), ts)
+}
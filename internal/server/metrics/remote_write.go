@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the Prometheus remote write protocol messages.
+const (
+	remoteWriteRequestTimeseries = 1
+	remoteWriteTimeSeriesLabels  = 1
+	remoteWriteTimeSeriesSamples = 2
+	remoteWriteLabelName         = 1
+	remoteWriteLabelValue        = 2
+	remoteWriteSampleValue       = 1
+	remoteWriteSampleTimestamp   = 2
+)
+
+// RemoteWrite returns the MetricSet as a snappy compressed Prometheus remote write request, with all the samples
+// taken at the given time.
+func (m *MetricSet) RemoteWrite(timestamp time.Time) []byte {
+	metricTypes := make([]MetricType, 0, len(m.set))
+	for metricType := range m.set {
+		metricTypes = append(metricTypes, metricType)
+	}
+
+	sort.Slice(metricTypes, func(i, j int) bool {
+		return metricTypes[i] < metricTypes[j]
+	})
+
+	var request []byte
+	for _, metricType := range metricTypes {
+		for _, sample := range m.set[metricType] {
+			labels := map[string]string{"__name__": MetricNames[metricType]}
+			for labelName, labelValue := range sample.Labels {
+				// Empty labels are the same as missing ones.
+				if labelValue != "" {
+					labels[labelName] = labelValue
+				}
+			}
+
+			// The labels of a time series must be sorted by name.
+			labelNames := make([]string, 0, len(labels))
+			for labelName := range labels {
+				labelNames = append(labelNames, labelName)
+			}
+
+			sort.Strings(labelNames)
+
+			var series []byte
+			for _, labelName := range labelNames {
+				var label []byte
+				label = protowire.AppendTag(label, remoteWriteLabelName, protowire.BytesType)
+				label = protowire.AppendString(label, labelName)
+				label = protowire.AppendTag(label, remoteWriteLabelValue, protowire.BytesType)
+				label = protowire.AppendString(label, labels[labelName])
+
+				series = protowire.AppendTag(series, remoteWriteTimeSeriesLabels, protowire.BytesType)
+				series = protowire.AppendBytes(series, label)
+			}
+
+			var value []byte
+			value = protowire.AppendTag(value, remoteWriteSampleValue, protowire.Fixed64Type)
+			value = protowire.AppendFixed64(value, math.Float64bits(sample.Value))
+			value = protowire.AppendTag(value, remoteWriteSampleTimestamp, protowire.VarintType)
+			value = protowire.AppendVarint(value, uint64(timestamp.UnixMilli()))
+
+			series = protowire.AppendTag(series, remoteWriteTimeSeriesSamples, protowire.BytesType)
+			series = protowire.AppendBytes(series, value)
+
+			request = protowire.AppendTag(request, remoteWriteRequestTimeseries, protowire.BytesType)
+			request = protowire.AppendBytes(request, series)
+		}
+	}
+
+	return snappy.Encode(nil, request)
+}
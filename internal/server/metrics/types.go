@@ -92,6 +92,18 @@ const (
 	NetworkTransmitPacketsTotal
 	// ProcsTotal represents the number of running processes.
 	ProcsTotal
+	// ProcsLimitHitsTotal represents the number of times the process limit was hit.
+	ProcsLimitHitsTotal
+	// FileDescriptors represents the number of open file descriptors.
+	FileDescriptors
+	// GPUUtilizationPercent represents the utilization of a GPU.
+	GPUUtilizationPercent
+	// GPUMemoryUsageBytes represents the amount of used memory on a GPU.
+	GPUMemoryUsageBytes
+	// GPUMemoryTotalBytes represents the total amount of memory on a GPU.
+	GPUMemoryTotalBytes
+	// GPUInstanceMemoryUsageBytes represents the amount of memory used on a GPU by the instance.
+	GPUInstanceMemoryUsageBytes
 	// OperationsTotal represents the number of running operations.
 	OperationsTotal
 	// WarningsTotal represents the number of active warnings.
@@ -144,6 +156,10 @@ const (
 	GoOtherSysBytes
 	// GoNextGCBytes represents the number of heap bytes when next garbage collection will take place.
 	GoNextGCBytes
+	// APIRateLimitedRequestsTotal represents the number of API requests rejected by the rate limits.
+	APIRateLimitedRequestsTotal
+	// PressureStalledSecondsTotal represents how long some or all tasks were stalled on a resource.
+	PressureStalledSecondsTotal
 )
 
 // MetricNames associates a metric type to its name.
@@ -209,9 +225,17 @@ var MetricNames = map[MetricType]string{
 	NetworkTransmitErrsTotal:    "incus_network_transmit_errs_total",
 	NetworkTransmitPacketsTotal: "incus_network_transmit_packets_total",
 	OperationsTotal:             "incus_operations_total",
+	PressureStalledSecondsTotal: "incus_pressure_stalled_seconds_total",
 	ProcsTotal:                  "incus_procs_total",
+	ProcsLimitHitsTotal:         "incus_procs_limit_hits_total",
+	FileDescriptors:             "incus_file_descriptors",
+	GPUUtilizationPercent:       "incus_gpu_utilization_percent",
+	GPUMemoryUsageBytes:         "incus_gpu_memory_usage_bytes",
+	GPUMemoryTotalBytes:         "incus_gpu_memory_total_bytes",
+	GPUInstanceMemoryUsageBytes: "incus_gpu_instance_memory_usage_bytes",
 	UptimeSeconds:               "incus_uptime_seconds",
 	WarningsTotal:               "incus_warnings_total",
+	APIRateLimitedRequestsTotal: "incus_api_rate_limited_requests_total",
 }
 
 // MetricHeaders represents the metric headers which contain help messages as specified by OpenMetrics.
@@ -277,7 +301,15 @@ var MetricHeaders = map[MetricType]string{
 	NetworkTransmitErrsTotal:    "# HELP incus_network_transmit_errs_total The amount of transmitted errors on a given interface.",
 	NetworkTransmitPacketsTotal: "# HELP incus_network_transmit_packets_total The amount of transmitted packets on a given interface.",
 	OperationsTotal:             "# HELP incus_operations_total The number of running operations",
+	PressureStalledSecondsTotal: "# HELP incus_pressure_stalled_seconds_total The total time some or all tasks were stalled on a resource.",
 	ProcsTotal:                  "# HELP incus_procs_total The number of running processes.",
+	ProcsLimitHitsTotal:         "# HELP incus_procs_limit_hits_total The number of times the process limit was hit.",
+	FileDescriptors:             "# HELP incus_file_descriptors The number of open file descriptors.",
+	GPUUtilizationPercent:       "# HELP incus_gpu_utilization_percent The utilization of the GPU in percent.",
+	GPUMemoryUsageBytes:         "# HELP incus_gpu_memory_usage_bytes The amount of used memory on the GPU.",
+	GPUMemoryTotalBytes:         "# HELP incus_gpu_memory_total_bytes The total amount of memory on the GPU.",
+	GPUInstanceMemoryUsageBytes: "# HELP incus_gpu_instance_memory_usage_bytes The amount of memory used on the GPU by the instance.",
 	UptimeSeconds:               "# HELP incus_uptime_seconds The daemon uptime in seconds.",
 	WarningsTotal:               "# HELP incus_warnings_total The number of active warnings.",
+	APIRateLimitedRequestsTotal: "# HELP incus_api_rate_limited_requests_total The number of API requests rejected by the rate limits.",
 }
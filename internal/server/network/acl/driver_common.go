@@ -27,6 +27,9 @@ import (
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
+// networkLogger is the logger of the network subsystem.
+var networkLogger = logger.Subsystem("network")
+
 // Define type for rule directions.
 type ruleDirection string
 
@@ -67,7 +70,7 @@ func (d *common) init(state *state.State, id int64, projectName string, info *ap
 		d.info = info
 	}
 
-	d.logger = logger.AddContext(logger.Ctx{"project": projectName, "networkACL": d.info.Name})
+	d.logger = networkLogger.AddContext(logger.Ctx{"project": projectName, "networkACL": d.info.Name})
 	d.id = id
 	d.projectName = projectName
 	d.state = state
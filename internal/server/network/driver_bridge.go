@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +27,8 @@ import (
 	"github.com/lxc/incus/v6/internal/server/db/warningtype"
 	"github.com/lxc/incus/v6/internal/server/dnsmasq"
 	"github.com/lxc/incus/v6/internal/server/dnsmasq/dhcpalloc"
+	"github.com/lxc/incus/v6/internal/server/fault"
+	"github.com/lxc/incus/v6/internal/server/firewall"
 	firewallDrivers "github.com/lxc/incus/v6/internal/server/firewall/drivers"
 	"github.com/lxc/incus/v6/internal/server/ip"
 	"github.com/lxc/incus/v6/internal/server/network/acl"
@@ -242,6 +245,11 @@ func (n *bridge) Validate(config map[string]string) error {
 		"ipv6.routes":                          validate.Optional(validate.IsListOf(validate.IsNetworkV6)),
 		"ipv6.routing":                         validate.Optional(validate.IsBool),
 		"ipv6.ovn.ranges":                      validate.Optional(validate.IsListOf(validate.IsNetworkRangeV6)),
+		"limits.ingress":                       validate.Optional(ValidBitRate),
+		"limits.egress":                        validate.Optional(ValidBitRate),
+		"mdns.advertise":                       validate.Optional(validate.IsBool),
+		"mdns.interfaces":                      validate.Optional(validate.IsListOf(validate.IsInterfaceName)),
+		"metadata.service":                     validate.Optional(validate.IsBool),
 		"dns.domain":                           validate.IsAny,
 		"dns.mode":                             validate.Optional(validate.IsOneOf("dynamic", "managed", "none")),
 		"dns.search":                           validate.IsAny,
@@ -423,6 +431,26 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check hierarchical traffic shaping is supported.
+	if BridgeShapingEnabled(config) {
+		if config["bridge.driver"] == "openvswitch" {
+			return fmt.Errorf("Network limits are only supported with the native bridge driver")
+		}
+
+		if config["limits.egress"] != "" && len(bridgeShapingIfbName(n.name)) > 15 {
+			return fmt.Errorf("Network name too long for shaping interface: %s", bridgeShapingIfbName(n.name))
+		}
+	}
+
+	// Check the metadata service can be reached.
+	if util.IsTrue(config["metadata.service"]) && slices.Contains([]string{"", "none"}, config["ipv4.address"]) {
+		return fmt.Errorf("The metadata service requires an IPv4 address on the network")
+	}
+
+	if util.IsTrue(config["metadata.service"]) && config["bridge.driver"] == "openvswitch" {
+		return fmt.Errorf("The metadata service is only supported with the native bridge driver")
+	}
+
 	return nil
 }
 
@@ -521,7 +549,17 @@ func (n *bridge) Start() error {
 
 	revert.Add(func() { n.setUnavailable() })
 
-	err := n.setup(nil)
+	err := fault.Inject(fault.PointNetworkStart, fault.StageStart, n.project, n.name)
+	if err != nil {
+		return err
+	}
+
+	err = n.setup(nil)
+	if err != nil {
+		return err
+	}
+
+	err = fault.Inject(fault.PointNetworkStart, fault.StageEnd, n.project, n.name)
 	if err != nil {
 		return err
 	}
@@ -859,6 +897,10 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		fwOpts.ACL = true
 	}
 
+	// In strict firewall mode, the broad rules and global sysctls are left to the administrator.
+	fwStrict := n.state.GlobalConfig.NetworkFirewallStrict()
+	fwRequired := []api.ClusterMemberManagedRule{}
+
 	// Snapshot container specific IPv4 routes (added with boot proto) before removing IPv4 addresses.
 	// This is because the kernel removes any static routes on an interface when all addresses removed.
 	ctRoutes, err := n.bootRoutesV4()
@@ -897,7 +939,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 
 		// Allow forwarding.
 		if util.IsTrueOrEmpty(n.config["ipv4.routing"]) {
-			err = localUtil.SysctlSet("net/ipv4/ip_forward", "1")
+			err = n.setupGlobalSysctl(fwStrict, &fwRequired, "net/ipv4/ip_forward", "1")
 			if err != nil {
 				return err
 			}
@@ -997,6 +1039,20 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			return err
 		}
 
+		// Add the metadata service address.
+		if util.IsTrue(n.config["metadata.service"]) {
+			addr = &ip.Addr{
+				DevName: n.name,
+				Address: fmt.Sprintf("%s/32", metadataServiceAddress),
+				Family:  ip.FamilyV4,
+			}
+
+			err = addr.Add()
+			if err != nil {
+				return err
+			}
+		}
+
 		// Configure NAT.
 		if util.IsTrue(n.config["ipv4.nat"]) {
 			//If a SNAT source address is specified, use that, otherwise default to MASQUERADE mode.
@@ -1155,7 +1211,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 				}
 
 				// If IPv6 router acceptance is enabled (set to 1) then we now set it to 2.
-				err = localUtil.SysctlSet(fmt.Sprintf("net/ipv6/conf/%s/accept_ra", entry.Name()), "2")
+				err = n.setupGlobalSysctl(fwStrict, &fwRequired, fmt.Sprintf("net/ipv6/conf/%s/accept_ra", entry.Name()), "2")
 				if err != nil && !os.IsNotExist(err) {
 					return err
 				}
@@ -1163,7 +1219,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 
 			// Then set forwarding for all of them.
 			for _, entry := range entries {
-				err = localUtil.SysctlSet(fmt.Sprintf("net/ipv6/conf/%s/forwarding", entry.Name()), "1")
+				err = n.setupGlobalSysctl(fwStrict, &fwRequired, fmt.Sprintf("net/ipv6/conf/%s/forwarding", entry.Name()), "1")
 				if err != nil && !os.IsNotExist(err) {
 					return err
 				}
@@ -1481,6 +1537,35 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 	}
 
+	// In strict firewall mode, report the rules allowing access and forwarding instead of adding them.
+	if fwStrict {
+		rules, err := n.state.Firewall.NetworkRequiredRules(n.name, fwOpts)
+		if err != nil {
+			return fmt.Errorf("Failed getting required firewall rules: %w", err)
+		}
+
+		fwRequired = append(fwRequired, rules...)
+
+		// Without the forwarding rules, don't add any rule so traffic isn't rejected ahead of the
+		// administrator's own rules.
+		if fwOpts.FeaturesV4 != nil && fwOpts.FeaturesV4.ForwardingAllow {
+			fwOpts.FeaturesV4 = nil
+		} else if fwOpts.FeaturesV4 != nil {
+			fwOpts.FeaturesV4.ICMPDHCPDNSAccess = false
+		}
+
+		if fwOpts.FeaturesV6 != nil && fwOpts.FeaturesV6.ForwardingAllow {
+			fwOpts.FeaturesV6 = nil
+		} else if fwOpts.FeaturesV6 != nil {
+			fwOpts.FeaturesV6.ICMPDHCPDNSAccess = false
+		}
+	}
+
+	firewall.SetRequiredRules(fmt.Sprintf("network/%s", n.name), fwRequired)
+	if len(fwRequired) > 0 {
+		n.logger.Warn("Strict firewall mode, rules and sysctls must be allowed by the administrator", logger.Ctx{"count": len(fwRequired)})
+	}
+
 	// Setup firewall.
 	n.logger.Debug("Setting up firewall")
 	err = n.state.Firewall.NetworkSetup(n.name, fwOpts)
@@ -1515,10 +1600,199 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Setup hierarchical traffic shaping.
+	if n.config["bridge.driver"] != "openvswitch" && (BridgeShapingEnabled(n.config) || BridgeShapingEnabled(oldConfig)) {
+		err = n.shapingSetup()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Setup the metadata service.
+	if util.IsTrue(n.config["metadata.service"]) {
+		err = metadataServiceStart(n.state, n.name, n.Project(), n.Name(), n.Type())
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { metadataServiceStop(n.name) })
+	} else {
+		metadataServiceStop(n.name)
+	}
+
+	// Setup the mDNS responder.
+	if util.IsTrue(n.config["mdns.advertise"]) {
+		interfaces := util.SplitNTrimSpace(n.config["mdns.interfaces"], ",", -1, true)
+		if len(interfaces) == 0 {
+			interfaces = []string{n.name}
+		}
+
+		err = mdnsStart(n.state, n.name, n.Project(), n.Name(), n.Type(), interfaces)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { mdnsStop(n.name) })
+	} else {
+		mdnsStop(n.name)
+	}
+
 	revert.Success()
 	return nil
 }
 
+// shapingSetup creates the aggregate traffic shaping classes of the network and adds the NICs of the
+// running local instances to them.
+func (n *bridge) shapingSetup() error {
+	err := BridgeShapingSetup(n.name, n.config)
+	if err != nil {
+		return fmt.Errorf("Failed setting up traffic shaping: %w", err)
+	}
+
+	if !BridgeShapingEnabled(n.config) {
+		return nil
+	}
+
+	// Projects of the instances, for their own aggregate limits.
+	projects := map[string]*api.Project{}
+
+	filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
+	err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", nicName)]
+		if hostName == "" || !InterfaceExists(hostName) {
+			return nil // Instance isn't running.
+		}
+
+		// Fill in the hwaddr from volatile.
+		if nicConfig["hwaddr"] == "" {
+			nicConfig["hwaddr"] = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		}
+
+		p, ok := projects[inst.Project]
+		if !ok {
+			err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), inst.Project)
+				if err != nil {
+					return err
+				}
+
+				p, err = dbProject.ToAPI(ctx, tx.Tx())
+
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("Failed loading project %q: %w", inst.Project, err)
+			}
+
+			projects[inst.Project] = p
+		}
+
+		ingress, egress := BridgeShapingNICLimits(nicConfig)
+
+		err := BridgeShapingAddMember(n.name, inst.Project, p.Config, hostName, nicConfig["hwaddr"], ingress, egress)
+		if err != nil {
+			n.logger.Warn("Failed adding instance NIC to traffic shaping", logger.Ctx{"project": inst.Project, "instance": inst.Name, "device": nicName, "err": err})
+		}
+
+		return nil
+	}, filter)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// State returns the network state, including the per-instance breakdown of the traffic shaping.
+func (n *bridge) State() (*api.NetworkState, error) {
+	state, err := n.common.State()
+	if err != nil {
+		return nil, err
+	}
+
+	if n.config["bridge.driver"] == "openvswitch" || !BridgeShapingEnabled(n.config) {
+		return state, nil
+	}
+
+	state.Shaping, err = n.shapingState()
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// shapingState returns the aggregate traffic shaping limits of the network along with the counters of the
+// NICs of the running local instances.
+func (n *bridge) shapingState() (*api.NetworkStateShaping, error) {
+	shaping := &api.NetworkStateShaping{
+		Ingress: n.config["limits.ingress"],
+		Egress:  n.config["limits.egress"],
+		Members: []api.NetworkStateShapingMember{},
+	}
+
+	var err error
+	var ingressClasses, egressClasses []ip.ClassStats
+
+	if shaping.Ingress != "" {
+		ingressClasses, err = ip.GetClassStats(n.name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting traffic shaping statistics: %w", err)
+		}
+	}
+
+	if shaping.Egress != "" {
+		egressClasses, err = ip.GetClassStats(bridgeShapingIfbName(n.name))
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting traffic shaping statistics: %w", err)
+		}
+	}
+
+	filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
+	err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", nicName)]
+		if hostName == "" || !InterfaceExists(hostName) {
+			return nil // Instance isn't running.
+		}
+
+		member := api.NetworkStateShapingMember{
+			Project:  inst.Project,
+			Instance: inst.Name,
+			Device:   nicName,
+		}
+
+		if shaping.Ingress != "" {
+			member.Ingress = bridgeShapingCounters(n.name, hostName, ingressClasses, true)
+		}
+
+		if shaping.Egress != "" {
+			member.Egress = bridgeShapingCounters(n.name, hostName, egressClasses, false)
+		}
+
+		shaping.Members = append(shaping.Members, member)
+
+		return nil
+	}, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(shaping.Members, func(i, j int) bool {
+		a, b := shaping.Members[i], shaping.Members[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+
+		if a.Instance != b.Instance {
+			return a.Instance < b.Instance
+		}
+
+		return a.Device < b.Device
+	})
+
+	return shaping, nil
+}
+
 // Stop stops the network.
 func (n *bridge) Stop() error {
 	n.logger.Debug("Stop")
@@ -1533,6 +1807,20 @@ func (n *bridge) Stop() error {
 		return err
 	}
 
+	// Stop the metadata service.
+	metadataServiceStop(n.name)
+
+	// Stop the mDNS responder.
+	mdnsStop(n.name)
+
+	// Remove the traffic shaping hierarchy along with its IFB device.
+	if n.config["bridge.driver"] != "openvswitch" {
+		err = BridgeShapingClear(n.name)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Destroy the bridge interface
 	if n.config["bridge.driver"] == "openvswitch" {
 		vswitch, err := ovs.NewVSwitch()
@@ -1571,6 +1859,8 @@ func (n *bridge) Stop() error {
 		}
 	}
 
+	firewall.SetRequiredRules(fmt.Sprintf("network/%s", n.name), nil)
+
 	// Kill any existing dnsmasq daemon for this network
 	err = dnsmasq.Kill(n.name, false)
 	if err != nil {
@@ -1728,6 +2018,30 @@ func (n *bridge) getTunnels() []string {
 	return tunnels
 }
 
+// setupGlobalSysctl sets a sysctl affecting the whole host. In strict firewall mode, it is instead recorded in
+// required when not already set to the value.
+func (n *bridge) setupGlobalSysctl(strict bool, required *[]api.ClusterMemberManagedRule, path string, value string) error {
+	if !strict {
+		return localUtil.SysctlSet(path, value)
+	}
+
+	currentValue, err := localUtil.SysctlGet(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(currentValue) != value {
+		*required = append(*required, api.ClusterMemberManagedRule{
+			Type:     "sysctl",
+			Location: path,
+			Rule:     value,
+			Status:   api.ClusterMemberManagedRuleStatusRequired,
+		})
+	}
+
+	return nil
+}
+
 // bootRoutesV4 returns a list of IPv4 boot routes on the network's device.
 func (n *bridge) bootRoutesV4() ([]string, error) {
 	r := &ip.Route{
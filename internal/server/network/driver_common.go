@@ -28,6 +28,9 @@ import (
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
+// networkLogger is the logger of the network subsystem.
+var networkLogger = logger.Subsystem("network")
+
 // Info represents information about a network driver.
 type Info struct {
 	Projects           bool // Indicates if driver can be used in network enabled projects.
@@ -96,7 +99,7 @@ type common struct {
 
 // init initialize internal variables.
 func (n *common) init(s *state.State, id int64, projectName string, netInfo *api.Network, netNodes map[int64]db.NetworkNode) error {
-	n.logger = logger.AddContext(logger.Ctx{"project": projectName, "driver": netInfo.Type, "network": netInfo.Name})
+	n.logger = networkLogger.AddContext(logger.Ctx{"project": projectName, "driver": netInfo.Type, "network": netInfo.Name})
 	n.id = id
 	n.project = projectName
 	n.name = netInfo.Name
@@ -1152,6 +1155,10 @@ func (n *common) getExternalSubnetInUse(ctx context.Context, tx *db.ClusterTx, u
 	return externalSubnets, nil
 }
 
+// loadBalancerVolatileDraining is the load balancer config key holding the comma separated backend addresses
+// which new connections are steered away from.
+const loadBalancerVolatileDraining = "volatile.draining"
+
 // loadBalancerValidate validates the load balancer request.
 func (n *common) loadBalancerValidate(listenAddress net.IP, forward *api.NetworkLoadBalancerPut) ([]*loadBalancerPortMap, error) {
 	if listenAddress == nil {
@@ -1184,6 +1191,15 @@ func (n *common) loadBalancerValidate(listenAddress net.IP, forward *api.Network
 			continue
 		}
 
+		if k == loadBalancerVolatileDraining {
+			err := validate.Optional(validate.IsListOf(validate.IsNetworkAddress))(forward.Config[k])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid value for %q: %w", k, err)
+			}
+
+			continue
+		}
+
 		return nil, fmt.Errorf("Invalid option %q", k)
 	}
 
@@ -6,6 +6,7 @@ import (
 	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/cluster/request"
 	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/fault"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/validate"
@@ -67,10 +68,20 @@ func (n *macvlan) Start() error {
 
 	revert.Add(func() { n.setUnavailable() })
 
+	err := fault.Inject(fault.PointNetworkStart, fault.StageStart, n.project, n.name)
+	if err != nil {
+		return err
+	}
+
 	if !InterfaceExists(n.config["parent"]) {
 		return fmt.Errorf("Parent interface %q not found", n.config["parent"])
 	}
 
+	err = fault.Inject(fault.PointNetworkStart, fault.StageEnd, n.project, n.name)
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 
 	// Ensure network is marked as available now its started.
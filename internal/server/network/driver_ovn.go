@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"maps"
 	"math/big"
 	"math/rand"
 	"net"
@@ -27,6 +28,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/db"
 	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/fault"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/ip"
 	"github.com/lxc/incus/v6/internal/server/locking"
@@ -2881,6 +2883,11 @@ func (n *ovn) Start() error {
 
 	revert.Add(func() { n.setUnavailable() })
 
+	err = fault.Inject(fault.PointNetworkStart, fault.StageStart, n.project, n.name)
+	if err != nil {
+		return err
+	}
+
 	// Check that uplink network is available.
 	if n.config["network"] != "" && !IsAvailable(api.ProjectDefaultName, n.config["network"]) {
 		return fmt.Errorf("Uplink network %q is unavailable", n.config["network"])
@@ -2939,6 +2946,11 @@ func (n *ovn) Start() error {
 		return err
 	}
 
+	err = fault.Inject(fault.PointNetworkStart, fault.StageEnd, n.project, n.name)
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 
 	// Ensure network is marked as available now its started.
@@ -4906,7 +4918,10 @@ func (n *ovn) ForwardDelete(listenAddress string, clientType request.ClientType)
 }
 
 // loadBalancerFlattenVIPs flattens port maps into format compatible with OVN load balancers.
-func (n *ovn) loadBalancerFlattenVIPs(listenAddress net.IP, portMaps []*loadBalancerPortMap) []networkOVN.OVNLoadBalancerVIP {
+// The backends whose address is in the load balancer's drained addresses are left out, unless all of them are.
+func (n *ovn) loadBalancerFlattenVIPs(listenAddress net.IP, portMaps []*loadBalancerPortMap, config map[string]string) []networkOVN.OVNLoadBalancerVIP {
+	drained := util.SplitNTrimSpace(config[loadBalancerVolatileDraining], ",", -1, true)
+
 	var vips []networkOVN.OVNLoadBalancerVIP
 
 	for _, portMap := range portMaps {
@@ -4917,7 +4932,19 @@ func (n *ovn) loadBalancerFlattenVIPs(listenAddress net.IP, portMaps []*loadBala
 				ListenPort:    lp,
 			}
 
+			// Skip the backends being drained, unless all of them are.
+			targets := make([]forwardTarget, 0, len(portMap.targets))
 			for _, target := range portMap.targets {
+				if !slices.Contains(drained, target.address.String()) {
+					targets = append(targets, target)
+				}
+			}
+
+			if len(targets) == 0 {
+				targets = portMap.targets
+			}
+
+			for _, target := range targets {
 				targetPort := lp // Default to using same port as listen port for target port.
 				targetPortsLen := len(target.ports)
 
@@ -4967,6 +4994,9 @@ func (n *ovn) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clie
 			return fmt.Errorf("Failed parsing %q: %w", loadBalancer.ListenAddress, err)
 		}
 
+		// The drained addresses are managed by the server.
+		delete(loadBalancer.Config, loadBalancerVolatileDraining)
+
 		portMaps, err := n.loadBalancerValidate(listenAddressNet.IP, &loadBalancer.NetworkLoadBalancerPut)
 		if err != nil {
 			return err
@@ -5056,7 +5086,7 @@ func (n *ovn) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clie
 			_ = n.loadBalancerBGPSetupPrefixes()
 		})
 
-		vips := n.loadBalancerFlattenVIPs(net.ParseIP(loadBalancer.ListenAddress), portMaps)
+		vips := n.loadBalancerFlattenVIPs(net.ParseIP(loadBalancer.ListenAddress), portMaps, loadBalancer.Config)
 
 		err = n.state.OVNNB.LoadBalancerApply(n.getLoadBalancerName(loadBalancer.ListenAddress), []networkOVN.OVNRouter{n.getRouterName()}, []networkOVN.OVNSwitch{n.getIntSwitchName()}, vips...)
 		if err != nil {
@@ -5132,6 +5162,17 @@ func (n *ovn) LoadBalancerUpdate(listenAddress string, req api.NetworkLoadBalanc
 			return err
 		}
 
+		// The drained addresses are managed by the server, keep the current ones.
+		req.Config = maps.Clone(req.Config)
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		delete(req.Config, loadBalancerVolatileDraining)
+		if curLoadBalancer.Config[loadBalancerVolatileDraining] != "" {
+			req.Config[loadBalancerVolatileDraining] = curLoadBalancer.Config[loadBalancerVolatileDraining]
+		}
+
 		portMaps, err := n.loadBalancerValidate(net.ParseIP(curLoadBalancer.ListenAddress), &req)
 		if err != nil {
 			return err
@@ -5156,7 +5197,7 @@ func (n *ovn) LoadBalancerUpdate(listenAddress string, req api.NetworkLoadBalanc
 			return nil // Nothing has changed.
 		}
 
-		vips := n.loadBalancerFlattenVIPs(net.ParseIP(newLoadBalancer.ListenAddress), portMaps)
+		vips := n.loadBalancerFlattenVIPs(net.ParseIP(newLoadBalancer.ListenAddress), portMaps, newLoadBalancer.Config)
 
 		err = n.state.OVNNB.LoadBalancerApply(n.getLoadBalancerName(newLoadBalancer.ListenAddress), []networkOVN.OVNRouter{n.getRouterName()}, []networkOVN.OVNSwitch{n.getIntSwitchName()}, vips...)
 		if err != nil {
@@ -5167,7 +5208,7 @@ func (n *ovn) LoadBalancerUpdate(listenAddress string, req api.NetworkLoadBalanc
 			// Apply old settings to OVN on failure.
 			portMaps, err := n.loadBalancerValidate(net.ParseIP(curLoadBalancer.ListenAddress), &curLoadBalancer.NetworkLoadBalancerPut)
 			if err == nil {
-				vips := n.loadBalancerFlattenVIPs(net.ParseIP(curLoadBalancer.ListenAddress), portMaps)
+				vips := n.loadBalancerFlattenVIPs(net.ParseIP(curLoadBalancer.ListenAddress), portMaps, curLoadBalancer.Config)
 				_ = n.state.OVNNB.LoadBalancerApply(n.getLoadBalancerName(curLoadBalancer.ListenAddress), []networkOVN.OVNRouter{n.getRouterName()}, []networkOVN.OVNSwitch{n.getIntSwitchName()}, vips...)
 				_ = n.forwardBGPSetupPrefixes()
 			}
@@ -5274,6 +5315,117 @@ func (n *ovn) LoadBalancerDelete(listenAddress string, clientType request.Client
 	return nil
 }
 
+// loadBalancersSetDrained adds or removes the addresses from the drained addresses of the load balancers with a
+// backend using one of them, then re-applies those load balancers.
+// The drained addresses are stored in the database so that all the cluster members apply the load balancers alike.
+// It returns the listen addresses of those load balancers.
+func (n *ovn) loadBalancersSetDrained(addresses []net.IP, drained bool) ([]string, error) {
+	changed := []*api.NetworkLoadBalancer{}
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		loadBalancers, err := tx.GetNetworkLoadBalancers(ctx, n.ID(), false)
+		if err != nil {
+			return fmt.Errorf("Failed loading network load balancers: %w", err)
+		}
+
+		changed = changed[:0]
+		for loadBalancerID, loadBalancer := range loadBalancers {
+			usesAddress := slices.ContainsFunc(loadBalancer.Backends, func(backend api.NetworkLoadBalancerBackend) bool {
+				return slices.ContainsFunc(addresses, func(address net.IP) bool {
+					return address.Equal(net.ParseIP(backend.TargetAddress))
+				})
+			})
+
+			if !usesAddress {
+				continue
+			}
+
+			drainedAddresses := util.SplitNTrimSpace(loadBalancer.Config[loadBalancerVolatileDraining], ",", -1, true)
+			for _, address := range addresses {
+				drainedAddresses = slices.DeleteFunc(drainedAddresses, func(drainedAddress string) bool {
+					return drainedAddress == address.String()
+				})
+
+				if drained {
+					drainedAddresses = append(drainedAddresses, address.String())
+				}
+			}
+
+			if loadBalancer.Config == nil {
+				loadBalancer.Config = map[string]string{}
+			}
+
+			if len(drainedAddresses) > 0 {
+				loadBalancer.Config[loadBalancerVolatileDraining] = strings.Join(drainedAddresses, ",")
+			} else {
+				delete(loadBalancer.Config, loadBalancerVolatileDraining)
+			}
+
+			err = tx.UpdateNetworkLoadBalancer(ctx, n.ID(), loadBalancerID, &loadBalancer.NetworkLoadBalancerPut)
+			if err != nil {
+				return fmt.Errorf("Failed updating network load balancer %q: %w", loadBalancer.ListenAddress, err)
+			}
+
+			changed = append(changed, loadBalancer)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	listenAddresses := make([]string, 0, len(changed))
+	for _, loadBalancer := range changed {
+		listenAddress := net.ParseIP(loadBalancer.ListenAddress)
+		portMaps, err := n.loadBalancerValidate(listenAddress, &loadBalancer.NetworkLoadBalancerPut)
+		if err != nil {
+			return nil, err
+		}
+
+		vips := n.loadBalancerFlattenVIPs(listenAddress, portMaps, loadBalancer.Config)
+		err = n.state.OVNNB.LoadBalancerApply(n.getLoadBalancerName(loadBalancer.ListenAddress), []networkOVN.OVNRouter{n.getRouterName()}, []networkOVN.OVNSwitch{n.getIntSwitchName()}, vips...)
+		if err != nil {
+			return nil, fmt.Errorf("Failed applying OVN load balancer: %w", err)
+		}
+
+		listenAddresses = append(listenAddresses, loadBalancer.ListenAddress)
+	}
+
+	sort.Strings(listenAddresses)
+
+	return listenAddresses, nil
+}
+
+// InstanceDevicePortDrain steers the new connections of the network load balancers away from the IPs of an
+// instance device port, while the connections already established keep going to it.
+// It returns the listen addresses of the affected load balancers and a function that stops draining.
+func (n *ovn) InstanceDevicePortDrain(instanceUUID string, deviceName string) ([]string, func(), error) {
+	devIPs, err := n.InstanceDevicePortIPs(instanceUUID, deviceName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(devIPs) == 0 {
+		return nil, func() {}, nil
+	}
+
+	undrain := func() {
+		_, err := n.loadBalancersSetDrained(devIPs, false)
+		if err != nil {
+			n.logger.Warn("Failed restoring load balancer backends after draining", logger.Ctx{"instance": instanceUUID, "device": deviceName, "err": err})
+		}
+	}
+
+	listenAddresses, err := n.loadBalancersSetDrained(devIPs, true)
+	if err != nil {
+		undrain()
+		return nil, nil, err
+	}
+
+	return listenAddresses, undrain, nil
+}
+
 // Leases returns a list of leases for the OVN network. Those are directly extracted from the OVN database.
 func (n *ovn) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
 	var err error
@@ -0,0 +1,38 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	networkOVN "github.com/lxc/incus/v6/internal/server/network/ovn"
+)
+
+func TestLoadBalancerFlattenVIPs(t *testing.T) {
+	n := &ovn{}
+	listenAddress := net.ParseIP("192.0.2.1")
+	portMaps := []*loadBalancerPortMap{{
+		listenPorts: []uint64{80},
+		protocol:    "tcp",
+		targets: []forwardTarget{
+			{address: net.ParseIP("10.0.0.2"), ports: []uint64{8080}},
+			{address: net.ParseIP("10.0.0.3"), ports: []uint64{8080}},
+		},
+	}}
+
+	targets := func(config map[string]string) []networkOVN.OVNLoadBalancerTarget {
+		vips := n.loadBalancerFlattenVIPs(listenAddress, portMaps, config)
+		require.Len(t, vips, 1)
+
+		return vips[0].Targets
+	}
+
+	require.Len(t, targets(nil), 2)
+
+	// Drained backends are left out.
+	require.Equal(t, []networkOVN.OVNLoadBalancerTarget{{Address: net.ParseIP("10.0.0.3"), Port: 8080}}, targets(map[string]string{loadBalancerVolatileDraining: "10.0.0.2"}))
+
+	// Unless all of them are drained.
+	require.Len(t, targets(map[string]string{loadBalancerVolatileDraining: "10.0.0.2,10.0.0.3"}), 2)
+}
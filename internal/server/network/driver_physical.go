@@ -10,6 +10,7 @@ import (
 	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/cluster/request"
 	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/fault"
 	"github.com/lxc/incus/v6/internal/server/ip"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
@@ -158,7 +159,17 @@ func (n *physical) Start() error {
 
 	revert.Add(func() { n.setUnavailable() })
 
-	err := n.setup(nil)
+	err := fault.Inject(fault.PointNetworkStart, fault.StageStart, n.project, n.name)
+	if err != nil {
+		return err
+	}
+
+	err = n.setup(nil)
+	if err != nil {
+		return err
+	}
+
+	err = fault.Inject(fault.PointNetworkStart, fault.StageEnd, n.project, n.name)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,419 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"golang.org/x/net/ipv4"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/state"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// mDNS constants.
+const (
+	mdnsAddress         = "224.0.0.251"
+	mdnsPort            = 5353
+	mdnsTTL             = 120
+	mdnsCacheFlush      = 1 << 15
+	mdnsUnicastResponse = 1 << 15
+	mdnsServicesPTR     = "_services._dns-sd._udp.local."
+)
+
+// mdnsResponders holds the running mDNS responders, keyed by bridge name.
+var mdnsResponders = map[string]*mdnsResponder{}
+var mdnsRespondersMu sync.Mutex
+
+// mdnsInstance is the information about an instance advertised over mDNS.
+type mdnsInstance struct {
+	name      string
+	addresses []net.IP
+	services  map[string]uint16
+}
+
+// mdnsResponder answers mDNS queries about the instances connected to a bridge.
+type mdnsResponder struct {
+	s           *state.State
+	projectName string
+	networkName string
+	networkType string
+	conns       []*ipv4.PacketConn
+
+	cacheMu      sync.Mutex
+	cache        []mdnsInstance
+	cacheExpires time.Time
+}
+
+// mdnsStart starts the mDNS responder of a bridge on the given interfaces, replacing any running one.
+func mdnsStart(s *state.State, bridgeName string, projectName string, networkName string, networkType string, interfaces []string) error {
+	mdnsStop(bridgeName)
+
+	responder := &mdnsResponder{
+		s:           s,
+		projectName: projectName,
+		networkName: networkName,
+		networkType: networkType,
+	}
+
+	group := &net.UDPAddr{IP: net.ParseIP(mdnsAddress), Port: mdnsPort}
+
+	for _, ifaceName := range interfaces {
+		iface, err := net.InterfaceByName(ifaceName)
+		if err != nil {
+			responder.close()
+			return fmt.Errorf("Failed getting interface %q: %w", ifaceName, err)
+		}
+
+		conn, err := net.ListenMulticastUDP("udp4", iface, group)
+		if err != nil {
+			responder.close()
+			return fmt.Errorf("Failed listening for mDNS queries on %q: %w", ifaceName, err)
+		}
+
+		// Multicast packets are delivered to all the sockets bound to the port, track where they came from.
+		pc := ipv4.NewPacketConn(conn)
+		err = pc.SetControlMessage(ipv4.FlagInterface, true)
+		if err != nil {
+			_ = conn.Close()
+			responder.close()
+			return fmt.Errorf("Failed configuring mDNS socket on %q: %w", ifaceName, err)
+		}
+
+		responder.conns = append(responder.conns, pc)
+		go responder.serve(pc, iface)
+	}
+
+	mdnsRespondersMu.Lock()
+	mdnsResponders[bridgeName] = responder
+	mdnsRespondersMu.Unlock()
+
+	return nil
+}
+
+// mdnsStop stops the mDNS responder of a bridge if running.
+func mdnsStop(bridgeName string) {
+	mdnsRespondersMu.Lock()
+	responder := mdnsResponders[bridgeName]
+	delete(mdnsResponders, bridgeName)
+	mdnsRespondersMu.Unlock()
+
+	if responder != nil {
+		responder.close()
+	}
+}
+
+// close closes all the sockets of the responder.
+func (r *mdnsResponder) close() {
+	for _, conn := range r.conns {
+		_ = conn.Close()
+	}
+}
+
+// serve answers the queries received on an interface until the socket is closed.
+func (r *mdnsResponder) serve(conn *ipv4.PacketConn, iface *net.Interface) {
+	buf := make([]byte, 9000)
+
+	for {
+		n, cm, src, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				networkLogger.Warn("mDNS responder stopped", logger.Ctx{"network": r.networkName, "interface": iface.Name, "err": err})
+			}
+
+			return
+		}
+
+		if cm != nil && cm.IfIndex != iface.Index {
+			continue
+		}
+
+		query := &dns.Msg{}
+		err = query.Unpack(buf[:n])
+		if err != nil || query.Response || query.Opcode != dns.OpcodeQuery {
+			continue
+		}
+
+		srcAddr, ok := src.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+
+		err = r.reply(conn, iface, query, srcAddr)
+		if err != nil {
+			networkLogger.Debug("Failed answering mDNS query", logger.Ctx{"network": r.networkName, "interface": iface.Name, "err": err})
+		}
+	}
+}
+
+// reply sends the answers to a query, if any.
+func (r *mdnsResponder) reply(conn *ipv4.PacketConn, iface *net.Interface, query *dns.Msg, src *net.UDPAddr) error {
+	// Only look up the instances for queries about the local domain.
+	if !slices.ContainsFunc(query.Question, func(q dns.Question) bool { return dns.IsSubDomain("local.", strings.ToLower(q.Name)) }) {
+		return nil
+	}
+
+	instances, err := r.instances()
+	if err != nil {
+		return err
+	}
+
+	resp := &dns.Msg{}
+	resp.Response = true
+	resp.Authoritative = true
+
+	unicast := false
+	for _, q := range query.Question {
+		answers, extra := mdnsAnswers(instances, q)
+		if len(answers) == 0 {
+			continue
+		}
+
+		resp.Answer = append(resp.Answer, answers...)
+		resp.Extra = append(resp.Extra, extra...)
+
+		if q.Qclass&mdnsUnicastResponse != 0 {
+			unicast = true
+		}
+	}
+
+	if len(resp.Answer) == 0 {
+		return nil
+	}
+
+	dst := &net.UDPAddr{IP: net.ParseIP(mdnsAddress), Port: mdnsPort}
+
+	// Legacy resolvers send queries from an ephemeral port and expect a regular DNS response.
+	if src.Port != mdnsPort {
+		resp.Id = query.Id
+		resp.Question = query.Question
+		unicast = true
+	}
+
+	if unicast {
+		dst = src
+	}
+
+	packet, err := resp.Pack()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.WriteTo(packet, &ipv4.ControlMessage{IfIndex: iface.Index}, dst)
+
+	return err
+}
+
+// instances returns the instances connected to the network on this server along with their addresses.
+// The result is cached for a few seconds to avoid hitting the database on every query.
+func (r *mdnsResponder) instances() ([]mdnsInstance, error) {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+
+	if time.Now().Before(r.cacheExpires) {
+		return r.cache, nil
+	}
+
+	leases := mdnsLeases(r.networkName)
+	instances := map[string]*mdnsInstance{}
+
+	filter := cluster.InstanceFilter{Node: &r.s.ServerName}
+	err := UsedByInstanceDevices(r.s, r.projectName, r.networkName, r.networkType, func(dbInst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		inst := instances[dbInst.Name]
+		if inst == nil {
+			config := db.ExpandInstanceConfig(dbInst.Config, dbInst.Profiles)
+
+			inst = &mdnsInstance{
+				name:     dbInst.Name,
+				services: mdnsParseServices(config["mdns.services"]),
+			}
+
+			instances[dbInst.Name] = inst
+		}
+
+		for _, k := range []string{"ipv4.address", "ipv6.address"} {
+			address := net.ParseIP(nicConfig[k])
+			if address != nil {
+				inst.addresses = append(inst.addresses, address)
+			}
+		}
+
+		hwaddr := nicConfig["hwaddr"]
+		if hwaddr == "" {
+			hwaddr = dbInst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		}
+
+		mac, err := net.ParseMAC(hwaddr)
+		if err == nil {
+			for _, address := range leases[mac.String()] {
+				if !slices.ContainsFunc(inst.addresses, address.Equal) {
+					inst.addresses = append(inst.addresses, address)
+				}
+			}
+		}
+
+		return nil
+	}, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache = make([]mdnsInstance, 0, len(instances))
+	for _, inst := range instances {
+		if len(inst.addresses) > 0 {
+			r.cache = append(r.cache, *inst)
+		}
+	}
+
+	r.cacheExpires = time.Now().Add(5 * time.Second)
+
+	return r.cache, nil
+}
+
+// mdnsLeases returns the dynamic IPv4 addresses handed out by dnsmasq on a network, keyed by MAC address.
+func mdnsLeases(networkName string) map[string][]net.IP {
+	leases := map[string][]net.IP{}
+
+	content, err := os.ReadFile(internalUtil.VarPath("networks", networkName, "dnsmasq.leases"))
+	if err != nil {
+		return leases
+	}
+
+	for _, lease := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(lease)
+		if len(fields) < 5 {
+			continue
+		}
+
+		mac, err := net.ParseMAC(fields[1])
+		address := net.ParseIP(fields[2])
+		if err != nil || address == nil || address.To4() == nil {
+			continue
+		}
+
+		leases[mac.String()] = append(leases[mac.String()], address)
+	}
+
+	return leases
+}
+
+// mdnsParseServices parses the mdns.services configuration of an instance into a map of service types to ports.
+// Invalid entries are ignored.
+func mdnsParseServices(value string) map[string]uint16 {
+	services := map[string]uint16{}
+
+	for _, entry := range strings.Split(value, ",") {
+		service, portStr, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			continue
+		}
+
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil {
+			continue
+		}
+
+		services[strings.ToLower(service)] = uint16(port)
+	}
+
+	return services
+}
+
+// mdnsAnswers returns the answers and additional records for a question about the instances.
+func mdnsAnswers(instances []mdnsInstance, q dns.Question) ([]dns.RR, []dns.RR) {
+	answers := []dns.RR{}
+	extra := []dns.RR{}
+
+	name := strings.ToLower(q.Name)
+	header := func(name string, rrtype uint16, unique bool) dns.RR_Header {
+		class := uint16(dns.ClassINET)
+		if unique {
+			class |= mdnsCacheFlush
+		}
+
+		return dns.RR_Header{Name: name, Rrtype: rrtype, Class: class, Ttl: mdnsTTL}
+	}
+
+	addressRecords := func(inst mdnsInstance, qtype uint16) []dns.RR {
+		host := dns.Fqdn(fmt.Sprintf("%s.local", inst.name))
+		records := []dns.RR{}
+
+		for _, address := range inst.addresses {
+			if address.To4() != nil && (qtype == dns.TypeA || qtype == dns.TypeANY) {
+				records = append(records, &dns.A{Hdr: header(host, dns.TypeA, true), A: address.To4()})
+			} else if address.To4() == nil && !address.IsLinkLocalUnicast() && (qtype == dns.TypeAAAA || qtype == dns.TypeANY) {
+				records = append(records, &dns.AAAA{Hdr: header(host, dns.TypeAAAA, true), AAAA: address})
+			}
+		}
+
+		return records
+	}
+
+	// List the advertised service types.
+	if name == mdnsServicesPTR && (q.Qtype == dns.TypePTR || q.Qtype == dns.TypeANY) {
+		serviceTypes := []string{}
+		for _, inst := range instances {
+			for service := range inst.services {
+				serviceType := dns.Fqdn(fmt.Sprintf("%s.local", service))
+				if !slices.Contains(serviceTypes, serviceType) {
+					serviceTypes = append(serviceTypes, serviceType)
+				}
+			}
+		}
+
+		sort.Strings(serviceTypes)
+		for _, serviceType := range serviceTypes {
+			answers = append(answers, &dns.PTR{Hdr: header(mdnsServicesPTR, dns.TypePTR, false), Ptr: serviceType})
+		}
+
+		return answers, extra
+	}
+
+	for _, inst := range instances {
+		host := dns.Fqdn(fmt.Sprintf("%s.local", inst.name))
+
+		// Host names.
+		if name == strings.ToLower(host) {
+			answers = append(answers, addressRecords(inst, q.Qtype)...)
+			continue
+		}
+
+		for service, port := range inst.services {
+			serviceType := dns.Fqdn(fmt.Sprintf("%s.local", service))
+			serviceInstance := dns.Fqdn(fmt.Sprintf("%s.%s.local", inst.name, service))
+
+			// Instances providing a service type.
+			if name == serviceType && (q.Qtype == dns.TypePTR || q.Qtype == dns.TypeANY) {
+				answers = append(answers, &dns.PTR{Hdr: header(serviceType, dns.TypePTR, false), Ptr: serviceInstance})
+				extra = append(extra, &dns.SRV{Hdr: header(serviceInstance, dns.TypeSRV, true), Port: port, Target: host})
+				extra = append(extra, addressRecords(inst, dns.TypeANY)...)
+				continue
+			}
+
+			// Service instance details.
+			if name == strings.ToLower(serviceInstance) {
+				if q.Qtype == dns.TypeSRV || q.Qtype == dns.TypeANY {
+					answers = append(answers, &dns.SRV{Hdr: header(serviceInstance, dns.TypeSRV, true), Port: port, Target: host})
+					extra = append(extra, addressRecords(inst, dns.TypeANY)...)
+				}
+
+				if q.Qtype == dns.TypeTXT || q.Qtype == dns.TypeANY {
+					answers = append(answers, &dns.TXT{Hdr: header(serviceInstance, dns.TypeTXT, true), Txt: []string{""}})
+				}
+			}
+		}
+	}
+
+	return answers, extra
+}
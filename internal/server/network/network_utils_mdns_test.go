@@ -0,0 +1,132 @@
+package network
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMDNSParseServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected map[string]uint16
+	}{
+		{"Empty", "", map[string]uint16{}},
+		{"Single", "_http._tcp:80", map[string]uint16{"_http._tcp": 80}},
+		{"Multiple", "_http._tcp:80, _ssh._tcp:22", map[string]uint16{"_http._tcp": 80, "_ssh._tcp": 22}},
+		{"Upper case", "_HTTP._tcp:8080", map[string]uint16{"_http._tcp": 8080}},
+		{"Missing port", "_http._tcp,_ssh._tcp:22", map[string]uint16{"_ssh._tcp": 22}},
+		{"Invalid port", "_http._tcp:http,_ssh._tcp:65536,_dns._udp:53", map[string]uint16{"_dns._udp": 53}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mdnsParseServices(tt.value))
+		})
+	}
+}
+
+func TestMDNSAnswers(t *testing.T) {
+	instances := []mdnsInstance{
+		{
+			name:      "c1",
+			addresses: []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("fd42::2"), net.ParseIP("fe80::2")},
+			services:  map[string]uint16{"_http._tcp": 80},
+		},
+		{
+			name:      "c2",
+			addresses: []net.IP{net.ParseIP("10.0.0.3")},
+			services:  map[string]uint16{"_http._tcp": 8080, "_ssh._tcp": 22},
+		},
+	}
+
+	// Records are rendered as "<name> <class> <type> <data>", sorted.
+	records := func(rrs []dns.RR) []string {
+		out := []string{}
+		for _, rr := range rrs {
+			fields := strings.Fields(rr.String())
+			out = append(out, strings.Join(append([]string{fields[0]}, fields[2:]...), " "))
+		}
+
+		sort.Strings(out)
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		qname   string
+		qtype   uint16
+		answers []string
+		extra   []string
+	}{
+		{
+			"IPv4 address", "c1.local.", dns.TypeA,
+			[]string{"c1.local. CLASS32769 A 10.0.0.2"},
+			[]string{},
+		},
+		{
+			"IPv6 address without link-local", "c1.local.", dns.TypeAAAA,
+			[]string{"c1.local. CLASS32769 AAAA fd42::2"},
+			[]string{},
+		},
+		{
+			"All addresses", "C1.local.", dns.TypeANY,
+			[]string{"c1.local. CLASS32769 A 10.0.0.2", "c1.local. CLASS32769 AAAA fd42::2"},
+			[]string{},
+		},
+		{
+			"No IPv6 address", "c2.local.", dns.TypeAAAA,
+			[]string{},
+			[]string{},
+		},
+		{
+			"Unknown host", "c3.local.", dns.TypeA,
+			[]string{},
+			[]string{},
+		},
+		{
+			"Service types", "_services._dns-sd._udp.local.", dns.TypePTR,
+			[]string{"_services._dns-sd._udp.local. IN PTR _http._tcp.local.", "_services._dns-sd._udp.local. IN PTR _ssh._tcp.local."},
+			[]string{},
+		},
+		{
+			"Service instances", "_http._tcp.local.", dns.TypePTR,
+			[]string{"_http._tcp.local. IN PTR c1._http._tcp.local.", "_http._tcp.local. IN PTR c2._http._tcp.local."},
+			[]string{
+				"c1._http._tcp.local. CLASS32769 SRV 0 0 80 c1.local.",
+				"c1.local. CLASS32769 A 10.0.0.2",
+				"c1.local. CLASS32769 AAAA fd42::2",
+				"c2._http._tcp.local. CLASS32769 SRV 0 0 8080 c2.local.",
+				"c2.local. CLASS32769 A 10.0.0.3",
+			},
+		},
+		{
+			"Service instance", "c2._ssh._tcp.local.", dns.TypeSRV,
+			[]string{"c2._ssh._tcp.local. CLASS32769 SRV 0 0 22 c2.local."},
+			[]string{"c2.local. CLASS32769 A 10.0.0.3"},
+		},
+		{
+			"Service instance text", "c2._ssh._tcp.local.", dns.TypeTXT,
+			[]string{`c2._ssh._tcp.local. CLASS32769 TXT ""`},
+			[]string{},
+		},
+		{
+			"Unknown service", "_ftp._tcp.local.", dns.TypePTR,
+			[]string{},
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answers, extra := mdnsAnswers(instances, dns.Question{Name: tt.qname, Qtype: tt.qtype, Qclass: dns.ClassINET})
+			assert.Equal(t, tt.answers, records(answers))
+			assert.Equal(t, tt.extra, records(extra))
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/ip"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// metadataServiceAddress is the well-known link-local address of the instance metadata service.
+const metadataServiceAddress = "169.254.169.254"
+
+// metadataServers holds the running metadata services, keyed by bridge name.
+var metadataServers = map[string]*http.Server{}
+var metadataServersMu sync.Mutex
+
+// metadataInstance is the information about an instance exposed through the metadata service.
+type metadataInstance struct {
+	name    string
+	project string
+	config  map[string]string
+	mac     string
+	address string
+}
+
+// metadataServiceStart starts the metadata service of a bridge, replacing any running one.
+// The listener is bound to the bridge interface so that every bridge can serve the same address.
+func metadataServiceStart(s *state.State, bridgeName string, projectName string, networkName string, networkType string) error {
+	metadataServiceStop(bridgeName)
+
+	lc := net.ListenConfig{
+		Control: func(network string, address string, c syscall.RawConn) error {
+			var sockErr error
+
+			err := c.Control(func(fd uintptr) {
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEADDR, 1)
+				if sockErr != nil {
+					return
+				}
+
+				sockErr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, bridgeName)
+			})
+			if err != nil {
+				return err
+			}
+
+			return sockErr
+		},
+	}
+
+	listener, err := lc.Listen(context.Background(), "tcp", net.JoinHostPort(metadataServiceAddress, "80"))
+	if err != nil {
+		return fmt.Errorf("Failed listening for the metadata service on %q: %w", bridgeName, err)
+	}
+
+	server := &http.Server{
+		Handler: &metadataHandler{
+			s:           s,
+			bridgeName:  bridgeName,
+			projectName: projectName,
+			networkName: networkName,
+			networkType: networkType,
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			networkLogger.Warn("Metadata service stopped", logger.Ctx{"network": networkName, "err": err})
+		}
+	}()
+
+	metadataServersMu.Lock()
+	metadataServers[bridgeName] = server
+	metadataServersMu.Unlock()
+
+	return nil
+}
+
+// metadataServiceStop stops the metadata service of a bridge if running.
+func metadataServiceStop(bridgeName string) {
+	metadataServersMu.Lock()
+	server := metadataServers[bridgeName]
+	delete(metadataServers, bridgeName)
+	metadataServersMu.Unlock()
+
+	if server != nil {
+		_ = server.Close()
+	}
+}
+
+// metadataHandler serves the metadata of the instance making the request.
+type metadataHandler struct {
+	s           *state.State
+	bridgeName  string
+	projectName string
+	networkName string
+	networkType string
+}
+
+// ServeHTTP implements http.Handler using the EC2 metadata service layout.
+func (h *metadataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Session tokens are handed out for compatibility with clients requiring them, the requester is
+	// identified through its network identity instead.
+	if r.URL.Path == "/latest/api/token" {
+		if r.Method != http.MethodPut {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := make([]byte, 32)
+		_, err := rand.Read(token)
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("X-aws-ec2-metadata-token-ttl-seconds", r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+		_, _ = w.Write([]byte(hex.EncodeToString(token)))
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	inst, err := h.instance(r)
+	if err != nil {
+		networkLogger.Debug("Failed identifying metadata service client", logger.Ctx{"network": h.networkName, "remote": r.RemoteAddr, "err": err})
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	value, found := metadataValue(inst, strings.TrimPrefix(r.URL.Path, "/"))
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(value))
+}
+
+// instance finds the instance making a request from the bridge port it came through.
+// The MAC address behind the source address must have been learned on the host interface of a NIC of the
+// instance using that MAC address, so that an instance can't claim the identity of another one on the bridge.
+func (h *metadataHandler) instance(r *http.Request) (*metadataInstance, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteIP := net.ParseIP(host)
+	if remoteIP == nil {
+		return nil, fmt.Errorf("Invalid remote address %q", host)
+	}
+
+	neigh := &ip.Neigh{DevName: h.bridgeName}
+	neighbours, err := neigh.Show()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting neighbours: %w", err)
+	}
+
+	var mac net.HardwareAddr
+	for _, neighbour := range neighbours {
+		if neighbour.Addr.Equal(remoteIP) && neighbour.MAC != nil {
+			mac = neighbour.MAC
+			break
+		}
+	}
+
+	if mac == nil {
+		return nil, fmt.Errorf("No neighbour entry for %q", remoteIP)
+	}
+
+	fdb := &ip.FDB{Master: h.bridgeName, MAC: mac}
+	entries, err := fdb.Show()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting forwarding database: %w", err)
+	}
+
+	port, err := metadataBridgePort(entries, h.bridgeName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed finding bridge port of %q: %w", mac, err)
+	}
+
+	var inst *metadataInstance
+
+	filter := cluster.InstanceFilter{Node: &h.s.ServerName}
+	err = UsedByInstanceDevices(h.s, h.projectName, h.networkName, h.networkType, func(dbInst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		if !metadataNICMatch(dbInst.Config, nicName, nicConfig, port, mac) {
+			return nil
+		}
+
+		inst = &metadataInstance{
+			name:    dbInst.Name,
+			project: dbInst.Project,
+			config:  db.ExpandInstanceConfig(dbInst.Config, dbInst.Profiles),
+			mac:     mac.String(),
+			address: remoteIP.String(),
+		}
+
+		return nil
+	}, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if inst == nil {
+		return nil, fmt.Errorf("No instance found on port %q with MAC address %q", port, mac)
+	}
+
+	return inst, nil
+}
+
+// metadataBridgePort returns the bridge port on which a MAC address was learned.
+func metadataBridgePort(entries []ip.FDB, bridgeName string) (string, error) {
+	port := ""
+	for _, entry := range entries {
+		// Skip the addresses of the bridge itself and of the host side of its ports.
+		if entry.Permanent || entry.Master != bridgeName || entry.DevName == "" || entry.DevName == bridgeName {
+			continue
+		}
+
+		if port != "" && port != entry.DevName {
+			return "", fmt.Errorf("Address learned on both %q and %q", port, entry.DevName)
+		}
+
+		port = entry.DevName
+	}
+
+	if port == "" {
+		return "", errors.New("Address not learned on any port")
+	}
+
+	return port, nil
+}
+
+// metadataNICMatch returns whether an instance NIC is connected through the given bridge port and uses the
+// given MAC address.
+func metadataNICMatch(instConfig map[string]string, nicName string, nicConfig map[string]string, port string, mac net.HardwareAddr) bool {
+	hostName := instConfig[fmt.Sprintf("volatile.%s.host_name", nicName)]
+	if hostName == "" || hostName != port {
+		return false
+	}
+
+	hwaddr := nicConfig["hwaddr"]
+	if hwaddr == "" {
+		hwaddr = instConfig[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+	}
+
+	nicMAC, err := net.ParseMAC(hwaddr)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(nicMAC, mac)
+}
+
+// metadataValue returns the metadata at the given path for an instance.
+// Directories are returned as newline separated listings with sub-directories ending with a slash.
+func metadataValue(inst *metadataInstance, path string) (string, bool) {
+	userData := inst.config["cloud-init.user-data"]
+	if userData == "" {
+		userData = inst.config["user.user-data"]
+	}
+
+	tags := map[string]string{}
+	for k, v := range inst.config {
+		key, found := strings.CutPrefix(k, "user.")
+		if found && !slices.Contains([]string{"user-data", "vendor-data", "network-config", "meta-data"}, key) {
+			tags[key] = v
+		}
+	}
+
+	instanceID := inst.config["volatile.uuid"]
+	if instanceID == "" {
+		instanceID = inst.name
+	}
+
+	metadata := map[string]string{
+		"instance-id": instanceID,
+		"hostname":    inst.name,
+		"local-ipv4":  inst.address,
+		"mac":         inst.mac,
+		"project":     inst.project,
+	}
+
+	listing := func(entries []string) (string, bool) {
+		sort.Strings(entries)
+		return strings.Join(entries, "\n"), true
+	}
+
+	switch path {
+	case "":
+		return "latest/", true
+	case "latest", "latest/":
+		entries := []string{"meta-data/"}
+		if userData != "" {
+			entries = append(entries, "user-data")
+		}
+
+		return listing(entries)
+	case "latest/user-data":
+		if userData == "" {
+			return "", false
+		}
+
+		return userData, true
+	case "latest/meta-data", "latest/meta-data/":
+		entries := []string{"tags/"}
+		for k := range metadata {
+			entries = append(entries, k)
+		}
+
+		return listing(entries)
+	case "latest/meta-data/tags", "latest/meta-data/tags/":
+		return "instance/", true
+	case "latest/meta-data/tags/instance", "latest/meta-data/tags/instance/":
+		entries := []string{}
+		for k := range tags {
+			entries = append(entries, k)
+		}
+
+		return listing(entries)
+	}
+
+	key, found := strings.CutPrefix(path, "latest/meta-data/tags/instance/")
+	if found {
+		value, ok := tags[key]
+		return value, ok
+	}
+
+	key, found = strings.CutPrefix(path, "latest/meta-data/")
+	if found {
+		value, ok := metadata[key]
+		return value, ok
+	}
+
+	return "", false
+}
@@ -0,0 +1,110 @@
+package network
+
+import (
+	"log"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/ip"
+)
+
+func TestMetadataBridgePort(t *testing.T) {
+	mac, _ := net.ParseMAC("00:16:3e:00:00:01")
+
+	tests := []struct {
+		name       string
+		entries    []ip.FDB
+		port       string
+		shouldFail bool
+	}{
+		{"Learned on a port", []ip.FDB{{DevName: "veth1", Master: "incusbr0", MAC: mac}}, "veth1", false},
+		{"Learned on several VLANs of a port", []ip.FDB{{DevName: "veth1", Master: "incusbr0", MAC: mac}, {DevName: "veth1", Master: "incusbr0", MAC: mac}}, "veth1", false},
+		{"Learned on two ports", []ip.FDB{{DevName: "veth1", Master: "incusbr0", MAC: mac}, {DevName: "veth2", Master: "incusbr0", MAC: mac}}, "", true},
+		{"Permanent entry", []ip.FDB{{DevName: "veth1", Master: "incusbr0", MAC: mac, Permanent: true}}, "", true},
+		{"Bridge entry", []ip.FDB{{DevName: "incusbr0", MAC: mac}}, "", true},
+		{"Other bridge", []ip.FDB{{DevName: "veth1", Master: "incusbr1", MAC: mac}}, "", true},
+		{"Not learned", []ip.FDB{}, "", true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		port, err := metadataBridgePort(tt.entries, "incusbr0")
+		if tt.shouldFail {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.port, port)
+	}
+}
+
+func TestMetadataNICMatch(t *testing.T) {
+	mac, _ := net.ParseMAC("00:16:3e:00:00:01")
+
+	instConfig := map[string]string{
+		"volatile.eth0.host_name": "veth1",
+		"volatile.eth0.hwaddr":    "00:16:3e:00:00:01",
+		"volatile.eth1.host_name": "veth2",
+		"volatile.eth1.hwaddr":    "00:16:3e:00:00:02",
+	}
+
+	tests := []struct {
+		name      string
+		nicName   string
+		nicConfig map[string]string
+		port      string
+		matches   bool
+	}{
+		{"Matching port and volatile MAC", "eth0", map[string]string{}, "veth1", true},
+		{"Matching port and configured MAC", "eth1", map[string]string{"hwaddr": "00:16:3e:00:00:01"}, "veth2", true},
+		{"Spoofed MAC from another port", "eth0", map[string]string{}, "veth2", false},
+		{"Other MAC on the port", "eth1", map[string]string{}, "veth2", false},
+		{"NIC not started", "eth2", map[string]string{"hwaddr": "00:16:3e:00:00:01"}, "", false},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		require.Equal(t, tt.matches, metadataNICMatch(instConfig, tt.nicName, tt.nicConfig, tt.port, mac))
+	}
+}
+
+func TestMetadataValue(t *testing.T) {
+	inst := &metadataInstance{
+		name:    "c1",
+		project: "default",
+		mac:     "00:16:3e:00:00:01",
+		address: "10.0.0.2",
+		config: map[string]string{
+			"volatile.uuid":        "c3e5a8e4-9d0c-4b53-a1b7-2f8a3b0e7c11",
+			"cloud-init.user-data": "#cloud-config",
+			"user.role":            "web",
+			"user.meta-data":       "ignored",
+		},
+	}
+
+	tests := []struct {
+		path  string
+		value string
+		found bool
+	}{
+		{"latest", "meta-data/\nuser-data", true},
+		{"latest/meta-data/", "hostname\ninstance-id\nlocal-ipv4\nmac\nproject\ntags/", true},
+		{"latest/meta-data/instance-id", "c3e5a8e4-9d0c-4b53-a1b7-2f8a3b0e7c11", true},
+		{"latest/meta-data/local-ipv4", "10.0.0.2", true},
+		{"latest/meta-data/tags/instance", "role", true},
+		{"latest/meta-data/tags/instance/role", "web", true},
+		{"latest/meta-data/tags/instance/meta-data", "", false},
+		{"latest/user-data", "#cloud-config", true},
+		{"latest/meta-data/unknown", "", false},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.path)
+		value, found := metadataValue(inst, tt.path)
+		require.Equal(t, tt.found, found)
+		require.Equal(t, tt.value, value)
+	}
+}
@@ -0,0 +1,562 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lxc/incus/v6/internal/server/ip"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/units"
+)
+
+// bridgeShapingRootClass is the class holding the aggregate limit of a bridge network.
+const bridgeShapingRootClass = "1:1"
+
+// BridgeShapingEnabled returns whether the network config enables hierarchical traffic shaping.
+func BridgeShapingEnabled(netConfig map[string]string) bool {
+	return netConfig["limits.ingress"] != "" || netConfig["limits.egress"] != ""
+}
+
+// ValidBitRate validates a bit rate such as "100Mbit".
+func ValidBitRate(value string) error {
+	_, err := units.ParseBitSizeString(value)
+	if err != nil {
+		return fmt.Errorf("Invalid bit rate %q: %w", value, err)
+	}
+
+	return nil
+}
+
+// bridgeShapingIfbName returns the name of the IFB device used to shape the traffic sent by the instances.
+func bridgeShapingIfbName(bridgeName string) string {
+	return fmt.Sprintf("%s-ifb", bridgeName)
+}
+
+// bridgeShapingMember represents an instance NIC within the traffic shaping hierarchy of a bridge.
+type bridgeShapingMember struct {
+	id      int
+	project string
+	hwaddr  string
+	ingress string
+	egress  string
+
+	// Effective limits of the NIC in bit/s, once capped by its project and the network.
+	ingressCeil int64
+	egressCeil  int64
+}
+
+// bridgeShaping represents the traffic shaping hierarchy of a bridge.
+type bridgeShaping struct {
+	ingress string
+	egress  string
+
+	// Members indexed by the name of their host-side interface.
+	members map[string]*bridgeShapingMember
+
+	// Class identifiers of the projects having their own aggregate limits.
+	projects map[string]int
+}
+
+// bridgeShapingMu protects bridgeShapings.
+var bridgeShapingMu sync.Mutex
+
+// bridgeShapings holds the traffic shaping hierarchies of the bridges, indexed by bridge name.
+var bridgeShapings = map[string]*bridgeShaping{}
+
+// allocateID returns an identifier for a class, leaf qdisc and filter which isn't used by another member or
+// project of the bridge. Minor 1 is the root class, major 0xffff the ingress qdisc and both are limited to 16 bits.
+func (b *bridgeShaping) allocateID() (int, error) {
+	used := make(map[int]bool, len(b.members)+len(b.projects))
+	for _, member := range b.members {
+		used[member.id] = true
+	}
+
+	for _, id := range b.projects {
+		used[id] = true
+	}
+
+	for id := 2; id < 0xffff; id++ {
+		if !used[id] {
+			return id, nil
+		}
+	}
+
+	return -1, fmt.Errorf("No traffic class left")
+}
+
+// projectMembers returns whether the project still has members in the bridge.
+func (b *bridgeShaping) projectMembers(projectName string) bool {
+	for _, member := range b.members {
+		if member.project == projectName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BridgeShapingProjectLimits returns the aggregate ingress and egress limits of the instances of a project on
+// each bridge using traffic shaping.
+func BridgeShapingProjectLimits(projectConfig map[string]string) (string, string) {
+	return projectConfig["limits.network.ingress"], projectConfig["limits.network.egress"]
+}
+
+// bridgeShapingDelete removes the traffic shaping hierarchy of a bridge, including its IFB device.
+// Must be called with bridgeShapingMu held.
+func bridgeShapingDelete(bridgeName string) error {
+	delete(bridgeShapings, bridgeName)
+
+	if !InterfaceExists(bridgeName) {
+		return nil
+	}
+
+	qdisc := &ip.Qdisc{Dev: bridgeName, Root: true}
+	_ = qdisc.Delete()
+	qdisc = &ip.Qdisc{Dev: bridgeName, Ingress: true}
+	_ = qdisc.Delete()
+
+	ifbName := bridgeShapingIfbName(bridgeName)
+	if InterfaceExists(ifbName) {
+		ifb := &ip.Link{Name: ifbName}
+		err := ifb.Delete()
+		if err != nil {
+			return fmt.Errorf("Failed deleting IFB interface %q: %w", ifbName, err)
+		}
+	}
+
+	return nil
+}
+
+// BridgeShapingClear removes the traffic shaping hierarchy of a bridge, including its IFB device.
+func BridgeShapingClear(bridgeName string) error {
+	bridgeShapingMu.Lock()
+	defer bridgeShapingMu.Unlock()
+
+	return bridgeShapingDelete(bridgeName)
+}
+
+// BridgeShapingSetup (re)creates the root classes holding the aggregate limits of a native bridge.
+// Traffic going to the instances is shaped on the egress of the bridge interface while traffic coming from
+// the instances is redirected from the bridge's ingress to an IFB device and shaped there.
+// Any previously existing hierarchy, including its member classes, is removed.
+func BridgeShapingSetup(bridgeName string, netConfig map[string]string) error {
+	bridgeShapingMu.Lock()
+	defer bridgeShapingMu.Unlock()
+
+	// Clean any existing hierarchy.
+	err := bridgeShapingDelete(bridgeName)
+	if err != nil {
+		return err
+	}
+
+	if !BridgeShapingEnabled(netConfig) {
+		return nil
+	}
+
+	ifbName := bridgeShapingIfbName(bridgeName)
+
+	if netConfig["limits.ingress"] != "" {
+		ingressInt, err := units.ParseBitSizeString(netConfig["limits.ingress"])
+		if err != nil {
+			return err
+		}
+
+		// No default class, traffic not belonging to an instance isn't shaped.
+		qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: bridgeName, Handle: "1:0", Root: true}}
+		err = qdiscHTB.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create root tc qdisc: %w", err)
+		}
+
+		classHTB := &ip.ClassHTB{Class: ip.Class{Dev: bridgeName, Parent: "1:0", Classid: bridgeShapingRootClass}, Rate: fmt.Sprintf("%dbit", ingressInt), Ceil: fmt.Sprintf("%dbit", ingressInt)}
+		err = classHTB.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create aggregate tc class: %w", err)
+		}
+	}
+
+	if netConfig["limits.egress"] != "" {
+		egressInt, err := units.ParseBitSizeString(netConfig["limits.egress"])
+		if err != nil {
+			return err
+		}
+
+		ifb := &ip.Ifb{Link: ip.Link{Name: ifbName}}
+		err = ifb.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating IFB interface %q: %w", ifbName, err)
+		}
+
+		err = ifb.SetUp()
+		if err != nil {
+			return fmt.Errorf("Failed bringing up IFB interface %q: %w", ifbName, err)
+		}
+
+		qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: ifbName, Handle: "1:0", Root: true}}
+		err = qdiscHTB.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create root tc qdisc: %w", err)
+		}
+
+		classHTB := &ip.ClassHTB{Class: ip.Class{Dev: ifbName, Parent: "1:0", Classid: bridgeShapingRootClass}, Rate: fmt.Sprintf("%dbit", egressInt), Ceil: fmt.Sprintf("%dbit", egressInt)}
+		err = classHTB.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create aggregate tc class: %w", err)
+		}
+
+		qdisc := &ip.Qdisc{Dev: bridgeName, Handle: "ffff:0", Ingress: true}
+		err = qdisc.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create ingress tc qdisc: %w", err)
+		}
+
+		mirred := &ip.ActionMirred{Direction: "egress", Redirect: true, Dev: ifbName}
+		filter := &ip.U32Filter{Filter: ip.Filter{Dev: bridgeName, Parent: "ffff:0", Protocol: "all"}, Value: "0", Mask: "0", Actions: []ip.Action{mirred}}
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create ingress tc filter: %w", err)
+		}
+	}
+
+	bridgeShapings[bridgeName] = &bridgeShaping{
+		ingress:  netConfig["limits.ingress"],
+		egress:   netConfig["limits.egress"],
+		members:  map[string]*bridgeShapingMember{},
+		projects: map[string]int{},
+	}
+
+	return nil
+}
+
+// bridgeShapingCeil returns the effective limit in bit/s of a class, which is its own limit if any, capped by
+// the limit of its parent.
+func bridgeShapingCeil(parent int64, limit string) (int64, error) {
+	if limit == "" {
+		return parent, nil
+	}
+
+	limitInt, err := units.ParseBitSizeString(limit)
+	if err != nil {
+		return -1, err
+	}
+
+	return min(limitInt, parent), nil
+}
+
+// bridgeShapingRate returns the guaranteed rate in bit/s of a class within an aggregate limit.
+// Only a small share of the aggregate is guaranteed so the classes mostly borrow from it.
+func bridgeShapingRate(aggregate int64, ceil int64) int64 {
+	return min(max(aggregate/100, 8000), ceil)
+}
+
+// BridgeShapingNICLimits returns the ingress and egress limits of a NIC config, limits.max taking precedence.
+func BridgeShapingNICLimits(nicConfig map[string]string) (string, string) {
+	if nicConfig["limits.max"] != "" {
+		return nicConfig["limits.max"], nicConfig["limits.max"]
+	}
+
+	return nicConfig["limits.ingress"], nicConfig["limits.egress"]
+}
+
+// bridgeShapingAddClass adds a class, along with its fq_codel leaf qdisc and its filter when matching on
+// MAC addresses, to a device of the hierarchy.
+func bridgeShapingAddClass(dev string, parent string, id int, rate int64, ceil int64, srcMAC string, dstMAC string) error {
+	classid := fmt.Sprintf("1:%x", id)
+
+	classHTB := &ip.ClassHTB{Class: ip.Class{Dev: dev, Parent: parent, Classid: classid}, Rate: fmt.Sprintf("%dbit", rate), Ceil: fmt.Sprintf("%dbit", ceil)}
+	err := classHTB.Add()
+	if err != nil {
+		return fmt.Errorf("Failed to create tc class: %w", err)
+	}
+
+	// Project classes are inner classes, only the members get a leaf qdisc and a filter.
+	if srcMAC == "" && dstMAC == "" {
+		return nil
+	}
+
+	leaf := &ip.QdiscFqCodel{Qdisc: ip.Qdisc{Dev: dev, Handle: fmt.Sprintf("%x:0", id), Parent: classid}}
+	err = leaf.Add()
+	if err != nil {
+		return fmt.Errorf("Failed to create member tc qdisc: %w", err)
+	}
+
+	filter := &ip.FlowerFilter{Filter: ip.Filter{Dev: dev, Parent: "1:0", Protocol: "all", Priority: fmt.Sprintf("%d", id)}, SrcMAC: srcMAC, DstMAC: dstMAC, Classid: classid}
+	err = filter.Add()
+	if err != nil {
+		return fmt.Errorf("Failed to create member tc filter: %w", err)
+	}
+
+	return nil
+}
+
+// bridgeShapingRemoveClass removes the filter and class of a member or project from a device, ignoring missing
+// entries.
+func bridgeShapingRemoveClass(dev string, id int) {
+	if !InterfaceExists(dev) {
+		return
+	}
+
+	filter := &ip.Filter{Dev: dev, Parent: "1:0", Priority: fmt.Sprintf("%d", id)}
+	_ = filter.Delete()
+
+	class := &ip.Class{Dev: dev, Classid: fmt.Sprintf("1:%x", id)}
+	_ = class.Delete()
+}
+
+// addProject adds the classes holding the aggregate limits of a project if it defines any.
+// Returns the ingress and egress parent classes and limits of the project's members.
+func (b *bridgeShaping) addProject(bridgeName string, projectName string, projectConfig map[string]string) (string, int64, string, int64, error) {
+	ingressAggregate, egressAggregate, err := b.aggregates()
+	if err != nil {
+		return "", -1, "", -1, err
+	}
+
+	projectIngress, projectEgress := BridgeShapingProjectLimits(projectConfig)
+	if projectIngress == "" && projectEgress == "" {
+		return bridgeShapingRootClass, ingressAggregate, bridgeShapingRootClass, egressAggregate, nil
+	}
+
+	ingressCeil, err := bridgeShapingCeil(ingressAggregate, projectIngress)
+	if err != nil {
+		return "", -1, "", -1, err
+	}
+
+	egressCeil, err := bridgeShapingCeil(egressAggregate, projectEgress)
+	if err != nil {
+		return "", -1, "", -1, err
+	}
+
+	id, ok := b.projects[projectName]
+	if !ok {
+		id, err = b.allocateID()
+		if err != nil {
+			return "", -1, "", -1, err
+		}
+
+		if b.ingress != "" {
+			err = bridgeShapingAddClass(bridgeName, bridgeShapingRootClass, id, bridgeShapingRate(ingressAggregate, ingressCeil), ingressCeil, "", "")
+			if err != nil {
+				return "", -1, "", -1, err
+			}
+		}
+
+		if b.egress != "" {
+			err = bridgeShapingAddClass(bridgeShapingIfbName(bridgeName), bridgeShapingRootClass, id, bridgeShapingRate(egressAggregate, egressCeil), egressCeil, "", "")
+			if err != nil {
+				bridgeShapingRemoveClass(bridgeName, id)
+				return "", -1, "", -1, err
+			}
+		}
+
+		b.projects[projectName] = id
+	}
+
+	classid := fmt.Sprintf("1:%x", id)
+
+	return classid, ingressCeil, classid, egressCeil, nil
+}
+
+// removeProject removes the classes of a project once it has no members left.
+func (b *bridgeShaping) removeProject(bridgeName string, projectName string) {
+	id, ok := b.projects[projectName]
+	if !ok || b.projectMembers(projectName) {
+		return
+	}
+
+	bridgeShapingRemoveClass(bridgeName, id)
+	bridgeShapingRemoveClass(bridgeShapingIfbName(bridgeName), id)
+	delete(b.projects, projectName)
+}
+
+// aggregates returns the aggregate limits of the bridge in bit/s, 0 when not limited.
+func (b *bridgeShaping) aggregates() (int64, int64, error) {
+	var err error
+	var ingress, egress int64
+
+	if b.ingress != "" {
+		ingress, err = units.ParseBitSizeString(b.ingress)
+		if err != nil {
+			return -1, -1, err
+		}
+	}
+
+	if b.egress != "" {
+		egress, err = units.ParseBitSizeString(b.egress)
+		if err != nil {
+			return -1, -1, err
+		}
+	}
+
+	return ingress, egress, nil
+}
+
+// addMember adds an instance NIC to the hierarchy, below the classes of its project if it has any.
+func (b *bridgeShaping) addMember(bridgeName string, projectConfig map[string]string, hostName string, member *bridgeShapingMember) error {
+	ingressAggregate, egressAggregate, err := b.aggregates()
+	if err != nil {
+		return err
+	}
+
+	ingressParent, ingressParentCeil, egressParent, egressParentCeil, err := b.addProject(bridgeName, member.project, projectConfig)
+	if err != nil {
+		return err
+	}
+
+	member.ingressCeil, err = bridgeShapingCeil(ingressParentCeil, member.ingress)
+	if err != nil {
+		return err
+	}
+
+	member.egressCeil, err = bridgeShapingCeil(egressParentCeil, member.egress)
+	if err != nil {
+		return err
+	}
+
+	member.id, err = b.allocateID()
+	if err != nil {
+		b.removeProject(bridgeName, member.project)
+		return err
+	}
+
+	b.members[hostName] = member
+
+	if b.ingress != "" {
+		err = bridgeShapingAddClass(bridgeName, ingressParent, member.id, bridgeShapingRate(ingressAggregate, member.ingressCeil), member.ingressCeil, "", member.hwaddr)
+		if err != nil {
+			b.removeMember(bridgeName, hostName)
+			return err
+		}
+	}
+
+	if b.egress != "" {
+		err = bridgeShapingAddClass(bridgeShapingIfbName(bridgeName), egressParent, member.id, bridgeShapingRate(egressAggregate, member.egressCeil), member.egressCeil, member.hwaddr, "")
+		if err != nil {
+			b.removeMember(bridgeName, hostName)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeMember removes an instance NIC from the hierarchy, along with the classes of its project if it was
+// its last member.
+func (b *bridgeShaping) removeMember(bridgeName string, hostName string) {
+	member, ok := b.members[hostName]
+	if !ok {
+		return
+	}
+
+	bridgeShapingRemoveClass(bridgeName, member.id)
+	bridgeShapingRemoveClass(bridgeShapingIfbName(bridgeName), member.id)
+	delete(b.members, hostName)
+
+	b.removeProject(bridgeName, member.project)
+}
+
+// BridgeShapingAddMember adds an instance NIC to the shaping hierarchy of a bridge.
+// The NIC gets its own class borrowing from the network's aggregate class, or from its project's class when
+// the project has its own aggregate limits, optionally capped by the NIC's own ingress and egress limits, with
+// a fq_codel leaf qdisc to keep latency low under contention.
+func BridgeShapingAddMember(bridgeName string, projectName string, projectConfig map[string]string, hostName string, hwaddr string, ingress string, egress string) error {
+	bridgeShapingMu.Lock()
+	defer bridgeShapingMu.Unlock()
+
+	b, ok := bridgeShapings[bridgeName]
+	if !ok {
+		return fmt.Errorf("Traffic shaping isn't set up on %q", bridgeName)
+	}
+
+	// Clean any existing entry.
+	b.removeMember(bridgeName, hostName)
+
+	member := &bridgeShapingMember{
+		project: projectName,
+		hwaddr:  hwaddr,
+		ingress: ingress,
+		egress:  egress,
+	}
+
+	return b.addMember(bridgeName, projectConfig, hostName, member)
+}
+
+// BridgeShapingRemoveMember removes an instance NIC from the shaping hierarchy of a bridge.
+func BridgeShapingRemoveMember(bridgeName string, hostName string) {
+	bridgeShapingMu.Lock()
+	defer bridgeShapingMu.Unlock()
+
+	b, ok := bridgeShapings[bridgeName]
+	if !ok {
+		return
+	}
+
+	b.removeMember(bridgeName, hostName)
+}
+
+// BridgeShapingProjectUpdate applies new aggregate limits of a project to the shaping hierarchies of the bridges.
+// The members of the project are moved below new project classes reflecting the new limits.
+func BridgeShapingProjectUpdate(projectName string, projectConfig map[string]string) error {
+	bridgeShapingMu.Lock()
+	defer bridgeShapingMu.Unlock()
+
+	for bridgeName, b := range bridgeShapings {
+		hostNames := []string{}
+		for hostName, member := range b.members {
+			if member.project == projectName {
+				hostNames = append(hostNames, hostName)
+			}
+		}
+
+		members := make([]*bridgeShapingMember, 0, len(hostNames))
+		for _, hostName := range hostNames {
+			members = append(members, b.members[hostName])
+			b.removeMember(bridgeName, hostName)
+		}
+
+		for i, hostName := range hostNames {
+			err := b.addMember(bridgeName, projectConfig, hostName, members[i])
+			if err != nil {
+				return fmt.Errorf("Failed updating traffic shaping of %q on %q: %w", hostName, bridgeName, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// bridgeShapingCounters returns the counters of the member class using the given interface from the class
+// statistics of a device. Returns nil if the interface isn't part of the hierarchy.
+func bridgeShapingCounters(bridgeName string, hostName string, classes []ip.ClassStats, ingress bool) *api.NetworkStateShapingCounters {
+	bridgeShapingMu.Lock()
+	defer bridgeShapingMu.Unlock()
+
+	b, ok := bridgeShapings[bridgeName]
+	if !ok {
+		return nil
+	}
+
+	member, ok := b.members[hostName]
+	if !ok {
+		return nil
+	}
+
+	counters := &api.NetworkStateShapingCounters{Limit: member.egressCeil}
+	if ingress {
+		counters.Limit = member.ingressCeil
+	}
+
+	classid := fmt.Sprintf("1:%x", member.id)
+	for _, class := range classes {
+		if class.Classid != classid {
+			continue
+		}
+
+		counters.Bytes = class.Stats.Bytes
+		counters.Packets = class.Stats.Packets
+		counters.Drops = class.Stats.Drops
+
+		break
+	}
+
+	return counters
+}
@@ -0,0 +1,106 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/ip"
+)
+
+func TestBridgeShapingAllocateID(t *testing.T) {
+	b := &bridgeShaping{members: map[string]*bridgeShapingMember{}, projects: map[string]int{}}
+
+	// Identifiers don't depend on the interface index and skip the root class.
+	id, err := b.allocateID()
+	require.NoError(t, err)
+	assert.Equal(t, 2, id)
+
+	b.members["veth1"] = &bridgeShapingMember{id: 2}
+	b.projects["p1"] = 3
+	b.members["veth2"] = &bridgeShapingMember{id: 5}
+
+	id, err = b.allocateID()
+	require.NoError(t, err)
+	assert.Equal(t, 4, id)
+
+	// Released identifiers are reused.
+	delete(b.members, "veth1")
+	id, err = b.allocateID()
+	require.NoError(t, err)
+	assert.Equal(t, 2, id)
+
+	// The identifiers are limited to 16 bits and never use the ingress qdisc major.
+	for id := 2; id < 0xffff; id++ {
+		b.projects[fmt.Sprintf("p%d", id)] = id
+	}
+
+	_, err = b.allocateID()
+	assert.Error(t, err)
+}
+
+func TestBridgeShapingCeil(t *testing.T) {
+	ceil, err := bridgeShapingCeil(1000000000, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000000000), ceil)
+
+	ceil, err = bridgeShapingCeil(1000000000, "100Mbit")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100000000), ceil)
+
+	ceil, err = bridgeShapingCeil(100000000, "1Gbit")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100000000), ceil)
+
+	_, err = bridgeShapingCeil(100000000, "fast")
+	assert.Error(t, err)
+
+	assert.Equal(t, int64(10000000), bridgeShapingRate(1000000000, 100000000))
+	assert.Equal(t, int64(8000), bridgeShapingRate(100000, 100000))
+	assert.Equal(t, int64(5000), bridgeShapingRate(100000, 5000))
+}
+
+func TestBridgeShapingProjectLimits(t *testing.T) {
+	ingress, egress := BridgeShapingProjectLimits(map[string]string{"limits.network.ingress": "100Mbit"})
+	assert.Equal(t, "100Mbit", ingress)
+	assert.Equal(t, "", egress)
+}
+
+func TestBridgeShapingCounters(t *testing.T) {
+	bridgeShapingMu.Lock()
+	bridgeShapings["incustest0"] = &bridgeShaping{
+		ingress:  "1Gbit",
+		egress:   "500Mbit",
+		members:  map[string]*bridgeShapingMember{"veth1": {id: 0x1234, project: "p1", ingressCeil: 100000000, egressCeil: 50000000}},
+		projects: map[string]int{"p1": 2},
+	}
+
+	bridgeShapingMu.Unlock()
+
+	defer func() {
+		bridgeShapingMu.Lock()
+		delete(bridgeShapings, "incustest0")
+		bridgeShapingMu.Unlock()
+	}()
+
+	classes := []ip.ClassStats{{Classid: "1:2"}, {Classid: "1:1234"}}
+	classes[1].Stats.Bytes = 1000
+	classes[1].Stats.Packets = 10
+	classes[1].Stats.Drops = 1
+
+	counters := bridgeShapingCounters("incustest0", "veth1", classes, true)
+	require.NotNil(t, counters)
+	assert.Equal(t, int64(100000000), counters.Limit)
+	assert.Equal(t, int64(1000), counters.Bytes)
+	assert.Equal(t, int64(10), counters.Packets)
+	assert.Equal(t, int64(1), counters.Drops)
+
+	counters = bridgeShapingCounters("incustest0", "veth1", classes, false)
+	require.NotNil(t, counters)
+	assert.Equal(t, int64(50000000), counters.Limit)
+
+	assert.Nil(t, bridgeShapingCounters("incustest0", "veth2", classes, true))
+	assert.Nil(t, bridgeShapingCounters("incustest1", "veth1", classes, true))
+}
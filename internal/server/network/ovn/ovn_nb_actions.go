@@ -2729,3 +2729,68 @@ func (o *NB) GetName(ctx context.Context) (string, error) {
 
 	return nbGlobal[0].Name, nil
 }
+
+// OVNManagedACL is an OVN ACL created by Incus.
+type OVNManagedACL struct {
+	Location string
+	Rule     string
+}
+
+// GetACLRules returns the ACLs applied to the port groups and logical switches created by Incus.
+func (o *NB) GetACLRules(ctx context.Context) ([]OVNManagedACL, error) {
+	acls := []ovnNB.ACL{}
+	err := o.client.List(ctx, &acls)
+	if err != nil {
+		return nil, err
+	}
+
+	aclsByUUID := make(map[string]ovnNB.ACL, len(acls))
+	for _, acl := range acls {
+		aclsByUUID[acl.UUID] = acl
+	}
+
+	rules := []OVNManagedACL{}
+	addRules := func(location string, aclUUIDs []string) {
+		for _, aclUUID := range aclUUIDs {
+			acl, ok := aclsByUUID[aclUUID]
+			if !ok {
+				continue
+			}
+
+			rules = append(rules, OVNManagedACL{
+				Location: location,
+				Rule:     fmt.Sprintf("%s %d %s %s", acl.Direction, acl.Priority, acl.Match, acl.Action),
+			})
+		}
+	}
+
+	portGroups := []ovnNB.PortGroup{}
+	err = o.client.List(ctx, &portGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, portGroup := range portGroups {
+		if !strings.HasPrefix(portGroup.Name, "incus") {
+			continue
+		}
+
+		addRules(fmt.Sprintf("port_group %s", portGroup.Name), portGroup.ACLs)
+	}
+
+	logicalSwitches := []ovnNB.LogicalSwitch{}
+	err = o.client.List(ctx, &logicalSwitches)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, logicalSwitch := range logicalSwitches {
+		if !strings.HasPrefix(logicalSwitch.Name, "incus-") {
+			continue
+		}
+
+		addRules(fmt.Sprintf("logical_switch %s", logicalSwitch.Name), logicalSwitch.ACLs)
+	}
+
+	return rules, nil
+}
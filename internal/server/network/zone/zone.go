@@ -25,6 +25,9 @@ import (
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
+// networkLogger is the logger of the network subsystem.
+var networkLogger = logger.Subsystem("network")
+
 // zone represents a Network zone.
 type zone struct {
 	logger      logger.Logger
@@ -42,7 +45,7 @@ func (d *zone) init(state *state.State, id int64, projectName string, info *api.
 		d.info = info
 	}
 
-	d.logger = logger.AddContext(logger.Ctx{"project": projectName, "networkzone": d.info.Name})
+	d.logger = networkLogger.AddContext(logger.Ctx{"project": projectName, "networkzone": d.info.Name})
 	d.id = id
 	d.projectName = projectName
 	d.state = state
@@ -8,6 +8,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/config"
 	"github.com/lxc/incus/v6/internal/server/db"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/units"
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
@@ -110,11 +111,34 @@ func (c *Config) StorageImagesVolume() string {
 	return c.m.GetString("storage.images_volume")
 }
 
+// ClusterEdge returns true if the member runs in edge mode, otherwise false.
+func (c *Config) ClusterEdge() bool {
+	return c.m.GetBool("cluster.edge")
+}
+
+// CoreDumps returns true if the capture of instance core dumps is enabled, otherwise false.
+func (c *Config) CoreDumps() bool {
+	return c.m.GetBool("core.coredumps")
+}
+
 // SyslogSocket returns true if the syslog socket is enabled, otherwise false.
 func (c *Config) SyslogSocket() bool {
 	return c.m.GetBool("core.syslog_socket")
 }
 
+// LimitsReserve returns the CPU set and the amount of memory (in bytes) reserved for the host.
+func (c *Config) LimitsReserve() (string, int64) {
+	memory, _ := units.ParseByteSizeString(c.m.GetString("limits.reserve.memory"))
+
+	return c.m.GetString("limits.reserve.cpu"), memory
+}
+
+// LXCFS returns whether LXCFS is managed by the server and whether its load average and CPU view virtualization are
+// enabled.
+func (c *Config) LXCFS() (bool, bool, bool) {
+	return c.m.GetBool("lxcfs.managed"), c.m.GetBool("lxcfs.loadavg"), c.m.GetBool("lxcfs.cpuview")
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]string {
@@ -172,6 +196,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Address to use for clustering traffic
 	"cluster.https_address": {Validator: validate.Optional(validate.IsListenAddress(true, false, false))},
 
+	// gendoc:generate(entity=server, group=cluster, key=cluster.edge)
+	// Set this option to `true` on cluster members with an unreliable link to the rest of the cluster.
+	// See {ref}`clustering-edge`.
+	// ---
+	//  type: bool
+	//  scope: local
+	//  defaultdesc: `false`
+	//  shortdesc: Whether the member keeps serving its instances while disconnected
+	"cluster.edge": {Validator: validate.Optional(validate.IsBool), Type: config.Bool},
+
 	// Network address for the BGP server
 
 	// gendoc:generate(entity=server, group=core, key=core.bgp_address)
@@ -192,6 +226,17 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: A unique identifier for the BGP server
 	"core.bgp_routerid": {Validator: validate.Optional(validate.IsNetworkAddressV4)},
 
+	// gendoc:generate(entity=server, group=core, key=core.coredumps)
+	// Set this option to `true` to have the server handle the core dumps of all processes on the host, storing those of
+	// the containers with {config:option}`instance-miscellaneous:coredumps.enabled` set.
+	// Other core dumps are passed on to the handler that was previously configured in `kernel.core_pattern`.
+	// ---
+	//  type: bool
+	//  scope: local
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to capture the core dumps of container processes
+	"core.coredumps": {Validator: validate.Optional(validate.IsBool), Type: config.Bool},
+
 	// Network address for the debug server
 
 	// gendoc:generate(entity=server, group=core, key=core.debug_address)
@@ -243,6 +288,60 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to enable the syslog unixgram socket listener
 	"core.syslog_socket": {Validator: validate.Optional(validate.IsBool), Type: config.Bool},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=limits.reserve.cpu)
+	// Specify the CPUs as a comma-separated list of IDs or ranges, for example `0-1`.
+	// The instances are kept off those CPUs, leaving them to the daemon and the system services.
+	// See {ref}`server-limits-reserve`.
+	// ---
+	//  type: string
+	//  scope: local
+	//  shortdesc: CPUs reserved for the host
+	"limits.reserve.cpu": {Validator: validate.Optional(validate.IsValidCPUSet)},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=limits.reserve.memory)
+	// Specify the amount of memory in bytes with an optional suffix, for example `2GiB`.
+	// The memory of all the instances together is limited to the memory of the host minus this amount.
+	// See {ref}`server-limits-reserve`.
+	// ---
+	//  type: string
+	//  scope: local
+	//  shortdesc: Memory reserved for the host
+	"limits.reserve.memory": {Validator: validate.Optional(validate.IsSize)},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=lxcfs.cpuview)
+	// Set this option to `true` to have LXCFS present the containers with CPU information and usage matching their
+	// CPU allowance.
+	// This only applies when {config:option}`server-miscellaneous:lxcfs.managed` is enabled, and takes effect once no container is running.
+	// See {ref}`server-lxcfs`.
+	// ---
+	//  type: bool
+	//  scope: local
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to virtualize the CPU view of containers
+	"lxcfs.cpuview": {Validator: validate.Optional(validate.IsBool), Type: config.Bool},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=lxcfs.loadavg)
+	// Set this option to `true` to have LXCFS present the containers with their own load average.
+	// This only applies when {config:option}`server-miscellaneous:lxcfs.managed` is enabled, and takes effect once no container is running.
+	// See {ref}`server-lxcfs`.
+	// ---
+	//  type: bool
+	//  scope: local
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to virtualize the load average of containers
+	"lxcfs.loadavg": {Validator: validate.Optional(validate.IsBool), Type: config.Bool},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=lxcfs.managed)
+	// Set this option to `true` to have the server run LXCFS, restart it when it fails and mount its views again in
+	// the running containers.
+	// See {ref}`server-lxcfs`.
+	// ---
+	//  type: bool
+	//  scope: local
+	//  defaultdesc: `false`
+	//  shortdesc: Whether the server manages LXCFS
+	"lxcfs.managed": {Validator: validate.Optional(validate.IsBool), Type: config.Bool},
+
 	// Storage volumes to store backups/images on
 
 	// gendoc:generate(entity=server, group=miscellaneous, key=storage.backups_volume)
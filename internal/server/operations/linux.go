@@ -5,15 +5,18 @@ package operations
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/db/operationtype"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
 )
 
 func registerDBOperation(op *Operation, opType operationtype.Type) error {
-	if op.state == nil {
+	// Disconnected edge members only track their operations locally.
+	if op.state == nil || op.state.Edge.Disconnected() {
 		return nil
 	}
 
@@ -44,7 +47,8 @@ func registerDBOperation(op *Operation, opType operationtype.Type) error {
 }
 
 func removeDBOperation(op *Operation) error {
-	if op.state == nil {
+	// Disconnected edge members only track their operations locally.
+	if op.state == nil || op.state.Edge.Disconnected() {
 		return nil
 	}
 
@@ -62,3 +66,39 @@ func (op *Operation) sendEvent(eventMessage any) {
 
 	_ = op.events.Send(op.projectName, api.EventTypeOperation, eventMessage)
 }
+
+// concurrencyLimits returns the server and project limits on the number of concurrent operations of a class.
+func (op *Operation) concurrencyLimits(class string) (int, int) {
+	if op.state == nil {
+		return 0, 0
+	}
+
+	serverLimit := 0
+	if op.state.GlobalConfig != nil {
+		serverLimit = op.state.GlobalConfig.OperationsConcurrency(class)
+	}
+
+	if op.projectName == "" {
+		return serverLimit, 0
+	}
+
+	var config map[string]string
+
+	err := op.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := cluster.GetProject(ctx, tx.Tx(), op.projectName)
+		if err != nil {
+			return err
+		}
+
+		config, err = cluster.GetProjectConfig(ctx, tx.Tx(), dbProject.ID)
+		return err
+	})
+	if err != nil {
+		op.logger.Warn("Failed loading project operation limits", logger.Ctx{"err": err})
+		return serverLimit, 0
+	}
+
+	projectLimit, _ := strconv.Atoi(config[fmt.Sprintf("limits.operations.%s", class)])
+
+	return serverLimit, projectLimit
+}
@@ -32,3 +32,8 @@ func (op *Operation) sendEvent(eventMessage any) {
 
 	op.events.Send(op.projectName, api.EventTypeOperation, eventMessage)
 }
+
+// concurrencyLimits returns the server and project limits on the number of concurrent operations of a class.
+func (op *Operation) concurrencyLimits(class string) (int, int) {
+	return 0, 0
+}
@@ -2,12 +2,14 @@ package operations
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/db/operationtype"
@@ -15,6 +17,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
 	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/tracing"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/cancel"
@@ -109,6 +112,12 @@ type Operation struct {
 	requestor   *api.EventLifecycleRequestor
 	logger      logger.Logger
 
+	// Context carrying the span of the operation, linking its work to the request which created it.
+	traceCtx context.Context
+
+	// Position of the operation in the queue of its concurrency class, 0 when not queued.
+	queuePosition int
+
 	// Those functions are called at various points in the Operation lifecycle
 	onRun     func(*Operation) error
 	onCancel  func(*Operation) error
@@ -146,6 +155,7 @@ func OperationCreate(s *state.State, projectName string, opClass OperationClass,
 	op.url = fmt.Sprintf("/%s/operations/%s", version.APIVersion, op.id)
 	op.resources = opResources
 	op.finished = cancel.New(context.Background())
+	op.traceCtx = context.Background()
 	op.state = s
 	op.logger = logger.AddContext(logger.Ctx{"operation": op.id, "project": op.projectName, "class": op.class.String(), "description": op.description})
 
@@ -185,6 +195,7 @@ func OperationCreate(s *state.State, projectName string, opClass OperationClass,
 	// Set requestor if request was provided.
 	if r != nil {
 		op.SetRequestor(r)
+		op.traceCtx = tracing.WithoutCancel(r.Context())
 	}
 
 	operationsLock.Lock()
@@ -221,6 +232,18 @@ func (op *Operation) Requestor() *api.EventLifecycleRequestor {
 	return op.requestor
 }
 
+// TraceContext returns a context carrying the span of the operation, for the spans of the work it does.
+func (op *Operation) TraceContext() context.Context {
+	if op == nil {
+		return context.Background()
+	}
+
+	op.lock.Lock()
+	defer op.lock.Unlock()
+
+	return op.traceCtx
+}
+
 func (op *Operation) done() {
 	if op.readonly {
 		return
@@ -281,8 +304,27 @@ func (op *Operation) Start() error {
 	op.status = api.Running
 
 	if op.onRun != nil {
+		onRun := op.onRun
+
+		ctx, span := tracing.Start(op.traceCtx, "operation "+op.description, attribute.String("incus.operation", op.id), attribute.String("incus.project", op.projectName))
+		op.traceCtx = ctx
+
 		go func(op *Operation) {
-			err := op.onRun(op)
+			// Wait for the concurrency limits to allow the operation to run.
+			release, err := schedulerAcquire(op)
+			if errors.Is(err, errCancelledWhileQueued) {
+				tracing.End(span, err)
+				return
+			}
+
+			if err == nil {
+				span.AddEvent("Running")
+				err = onRun(op)
+				release()
+			}
+
+			tracing.End(span, err)
+
 			if err != nil {
 				op.lock.Lock()
 				op.status = api.Failure
@@ -342,6 +384,23 @@ func (op *Operation) Cancel() (chan error, error) {
 
 	chanCancel := make(chan error, 1)
 
+	// Queued operations haven't started doing anything yet, simply drop them from the queue.
+	if op.queuePosition > 0 {
+		op.status = api.Cancelled
+		op.lock.Unlock()
+		op.done()
+		chanCancel <- nil
+
+		op.logger.Debug("Cancelled queued operation")
+		_, md, _ := op.Render()
+
+		op.lock.Lock()
+		op.sendEvent(md)
+		op.lock.Unlock()
+
+		return chanCancel, nil
+	}
+
 	oldStatus := op.status
 	op.status = api.Cancelling
 	op.lock.Unlock()
@@ -448,7 +507,7 @@ func (op *Operation) Connect(r *http.Request, w http.ResponseWriter) (chan error
 }
 
 func (op *Operation) mayCancel() bool {
-	if op.class == OperationClassToken {
+	if op.class == OperationClassToken || op.queuePosition > 0 {
 		return true
 	}
 
@@ -499,6 +558,10 @@ func (op *Operation) Render() (string, *api.Operation, error) {
 		MayCancel:   op.mayCancel(),
 	}
 
+	if op.queuePosition > 0 {
+		retOp.QueuePosition = op.queuePosition
+	}
+
 	if op.state != nil {
 		retOp.Location = op.state.ServerName
 	}
@@ -36,10 +36,11 @@ func (r *operationResponse) Render(w http.ResponseWriter) error {
 		Type:       api.AsyncResponse,
 		Status:     api.OperationCreated.String(),
 		StatusCode: int(api.OperationCreated),
-		Operation:  url,
+		Operation:  response.PathPrefix(w) + url,
 		Metadata:   md,
 	}
 
+	// The Location header gets the API path prefix from the response writer.
 	w.Header().Set("Location", url)
 
 	code := 202
@@ -89,10 +90,11 @@ func (r *forwardedOperationResponse) Render(w http.ResponseWriter) error {
 		Type:       api.AsyncResponse,
 		Status:     api.OperationCreated.String(),
 		StatusCode: int(api.OperationCreated),
-		Operation:  url,
+		Operation:  response.PathPrefix(w) + url,
 		Metadata:   r.op,
 	}
 
+	// The Location header gets the API path prefix from the response writer.
 	w.Header().Set("Location", url)
 
 	code := 202
@@ -0,0 +1,193 @@
+package operations
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// errCancelledWhileQueued is returned when an operation is cancelled before getting to run.
+var errCancelledWhileQueued = errors.New("Operation cancelled while queued")
+
+// Operations run in order of priority, then in the order they were queued.
+const (
+	priorityBackground  = 0
+	priorityInteractive = 1
+)
+
+// queuedOperation represents an operation waiting for a concurrency slot.
+type queuedOperation struct {
+	op           *Operation
+	class        string
+	priority     int
+	serverLimit  int
+	projectLimit int
+	ready        chan struct{}
+}
+
+var schedulerLock sync.Mutex
+var schedulerQueue []*queuedOperation
+var schedulerRunning = map[string]int{}
+
+// schedulerProjectKey returns the key used to count the running operations of a class within a project.
+func schedulerProjectKey(projectName string, class string) string {
+	return fmt.Sprintf("%s/%s", projectName, class)
+}
+
+// schedulerAcquire waits until the operation is allowed to run by the concurrency limits of its class and returns
+// the function to call once it's done. Operations which aren't part of a limited class run immediately.
+func schedulerAcquire(op *Operation) (func(), error) {
+	class := op.dbOpType.ConcurrencyClass()
+	if class == "" {
+		return func() {}, nil
+	}
+
+	serverLimit, projectLimit := op.concurrencyLimits(class)
+
+	entry := &queuedOperation{
+		op:           op,
+		class:        class,
+		priority:     priorityBackground,
+		serverLimit:  serverLimit,
+		projectLimit: projectLimit,
+		ready:        make(chan struct{}),
+	}
+
+	// Requests made by users take precedence over background tasks.
+	if op.requestor != nil {
+		entry.priority = priorityInteractive
+	}
+
+	schedulerLock.Lock()
+	schedulerInsert(entry)
+	changed := schedulerRun()
+	schedulerLock.Unlock()
+
+	schedulerNotify(changed)
+
+	release := func() {
+		schedulerLock.Lock()
+		schedulerRunning[class]--
+		schedulerRunning[schedulerProjectKey(op.projectName, class)]--
+		changed := schedulerRun()
+		schedulerLock.Unlock()
+
+		schedulerNotify(changed)
+	}
+
+	select {
+	case <-entry.ready:
+		// Check the operation wasn't cancelled right before being started.
+		op.lock.Lock()
+		cancelled := op.status != api.Running
+		op.lock.Unlock()
+
+		if cancelled {
+			release()
+			return nil, errCancelledWhileQueued
+		}
+
+		return release, nil
+	case <-op.finished.Done():
+	}
+
+	// The operation was cancelled, remove it from the queue unless it got started in the meantime.
+	schedulerLock.Lock()
+	index := slices.Index(schedulerQueue, entry)
+	if index < 0 {
+		schedulerLock.Unlock()
+		release()
+
+		return nil, errCancelledWhileQueued
+	}
+
+	schedulerQueue = slices.Delete(schedulerQueue, index, index+1)
+	changed = schedulerRun()
+	schedulerLock.Unlock()
+
+	schedulerNotify(changed)
+
+	return nil, errCancelledWhileQueued
+}
+
+// schedulerInsert queues the operation after those of the same or a higher priority.
+// Must be called with schedulerLock held.
+func schedulerInsert(entry *queuedOperation) {
+	index := len(schedulerQueue)
+	for i, queued := range schedulerQueue {
+		if queued.priority < entry.priority {
+			index = i
+			break
+		}
+	}
+
+	schedulerQueue = slices.Insert(schedulerQueue, index, entry)
+}
+
+// schedulerRun starts the queued operations allowed by the concurrency limits and updates the queue position of
+// the others. It returns the operations whose position changed. Must be called with schedulerLock held.
+func schedulerRun() []*Operation {
+	changed := []*Operation{}
+	positions := map[string]int{}
+	remaining := make([]*queuedOperation, 0, len(schedulerQueue))
+
+	for _, entry := range schedulerQueue {
+		projectKey := schedulerProjectKey(entry.op.projectName, entry.class)
+
+		if (entry.serverLimit == 0 || schedulerRunning[entry.class] < entry.serverLimit) && (entry.projectLimit == 0 || schedulerRunning[projectKey] < entry.projectLimit) {
+			schedulerRunning[entry.class]++
+			schedulerRunning[projectKey]++
+			close(entry.ready)
+
+			if entry.op.setQueuePosition(0) {
+				changed = append(changed, entry.op)
+			}
+
+			continue
+		}
+
+		positions[entry.class]++
+		remaining = append(remaining, entry)
+
+		if entry.op.setQueuePosition(positions[entry.class]) {
+			changed = append(changed, entry.op)
+		}
+	}
+
+	schedulerQueue = remaining
+
+	return changed
+}
+
+// schedulerNotify sends an event for every operation whose queue position changed.
+func schedulerNotify(ops []*Operation) {
+	for _, op := range ops {
+		_, md, _ := op.Render()
+		if md.QueuePosition > 0 {
+			op.logger.Debug("Queued operation", logger.Ctx{"position": md.QueuePosition})
+		}
+
+		op.lock.Lock()
+		op.sendEvent(md)
+		op.lock.Unlock()
+	}
+}
+
+// setQueuePosition records the position of the operation in the queue of its class (0 when not queued).
+// Returns whether it changed.
+func (op *Operation) setQueuePosition(position int) bool {
+	op.lock.Lock()
+	defer op.lock.Unlock()
+
+	if op.queuePosition == position {
+		return false
+	}
+
+	op.queuePosition = position
+
+	return true
+}
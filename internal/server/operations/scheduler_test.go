@@ -0,0 +1,120 @@
+package operations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// schedulerTestReset empties the scheduler state for the duration of a test.
+func schedulerTestReset(t *testing.T) {
+	schedulerQueue = nil
+	schedulerRunning = map[string]int{}
+
+	t.Cleanup(func() {
+		schedulerQueue = nil
+		schedulerRunning = map[string]int{}
+	})
+}
+
+func schedulerTestEntry(projectName string, class string, priority int, serverLimit int, projectLimit int) *queuedOperation {
+	return &queuedOperation{
+		op:           &Operation{projectName: projectName},
+		class:        class,
+		priority:     priority,
+		serverLimit:  serverLimit,
+		projectLimit: projectLimit,
+		ready:        make(chan struct{}),
+	}
+}
+
+func schedulerTestStarted(entry *queuedOperation) bool {
+	select {
+	case <-entry.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestSchedulerInsert(t *testing.T) {
+	schedulerTestReset(t)
+
+	background1 := schedulerTestEntry("default", "migrations", priorityBackground, 1, 0)
+	interactive1 := schedulerTestEntry("default", "migrations", priorityInteractive, 1, 0)
+	background2 := schedulerTestEntry("default", "migrations", priorityBackground, 1, 0)
+	interactive2 := schedulerTestEntry("default", "migrations", priorityInteractive, 1, 0)
+
+	for _, entry := range []*queuedOperation{background1, interactive1, background2, interactive2} {
+		schedulerInsert(entry)
+	}
+
+	// Interactive operations go first, each priority keeping the order the operations were queued in.
+	require.Equal(t, []*queuedOperation{interactive1, interactive2, background1, background2}, schedulerQueue)
+}
+
+func TestSchedulerRun(t *testing.T) {
+	schedulerTestReset(t)
+
+	// At most two migrations on the server and one per project.
+	p1First := schedulerTestEntry("p1", "migrations", priorityInteractive, 2, 1)
+	p1Second := schedulerTestEntry("p1", "migrations", priorityInteractive, 2, 1)
+	p2First := schedulerTestEntry("p2", "migrations", priorityInteractive, 2, 1)
+	p3First := schedulerTestEntry("p3", "migrations", priorityInteractive, 2, 1)
+	backup := schedulerTestEntry("p1", "backups", priorityInteractive, 1, 0)
+
+	for _, entry := range []*queuedOperation{p1First, p1Second, p2First, p3First, backup} {
+		schedulerInsert(entry)
+	}
+
+	changed := schedulerRun()
+	require.ElementsMatch(t, []*Operation{p1Second.op, p3First.op}, changed)
+
+	// The project limit holds back the second operation of p1, the server limit the one of p3.
+	require.True(t, schedulerTestStarted(p1First))
+	require.False(t, schedulerTestStarted(p1Second))
+	require.True(t, schedulerTestStarted(p2First))
+	require.False(t, schedulerTestStarted(p3First))
+
+	// Other classes have their own limits.
+	require.True(t, schedulerTestStarted(backup))
+
+	// Queue positions are counted within the class.
+	require.Equal(t, []*queuedOperation{p1Second, p3First}, schedulerQueue)
+	require.Equal(t, 1, p1Second.op.queuePosition)
+	require.Equal(t, 2, p3First.op.queuePosition)
+	require.Equal(t, 2, schedulerRunning["migrations"])
+	require.Equal(t, 1, schedulerRunning[schedulerProjectKey("p1", "migrations")])
+
+	// Nothing changes until an operation finishes.
+	require.Empty(t, schedulerRun())
+
+	// Once the first operation of p1 finishes, its second one takes the free slot.
+	schedulerRunning["migrations"]--
+	schedulerRunning[schedulerProjectKey("p1", "migrations")]--
+
+	changed = schedulerRun()
+	require.ElementsMatch(t, []*Operation{p1Second.op, p3First.op}, changed)
+	require.True(t, schedulerTestStarted(p1Second))
+	require.False(t, schedulerTestStarted(p3First))
+	require.Equal(t, []*queuedOperation{p3First}, schedulerQueue)
+	require.Equal(t, 1, p3First.op.queuePosition)
+}
+
+func TestSchedulerRunUnlimited(t *testing.T) {
+	schedulerTestReset(t)
+
+	entries := []*queuedOperation{}
+	for i := 0; i < 5; i++ {
+		entry := schedulerTestEntry("default", "images", priorityBackground, 0, 0)
+		entries = append(entries, entry)
+		schedulerInsert(entry)
+	}
+
+	require.Empty(t, schedulerRun())
+	require.Empty(t, schedulerQueue)
+
+	for _, entry := range entries {
+		require.True(t, schedulerTestStarted(entry))
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/idmap"
 )
 
@@ -75,3 +76,166 @@ func TestParseHostIDMapRange(t *testing.T) {
 		assert.Equal(t, idmaps, expected)
 	}
 }
+
+func TestCheckRestrictionsKernelModules(t *testing.T) {
+	project := api.Project{
+		Name: "p1",
+		ProjectPut: api.ProjectPut{
+			Config: map[string]string{
+				"restricted":                           "true",
+				"restricted.containers.kernel_modules": "wireguard, nbd",
+			},
+		},
+	}
+
+	newInstance := func(modules string) []api.Instance {
+		return []api.Instance{{
+			Name: "c1",
+			Type: "container",
+			InstancePut: api.InstancePut{
+				Config: map[string]string{"linux.kernel_modules": modules},
+			},
+		}}
+	}
+
+	err := checkRestrictions(project, newInstance("wireguard"), nil)
+	assert.NoError(t, err)
+
+	err = checkRestrictions(project, newInstance("nbd,wireguard"), nil)
+	assert.NoError(t, err)
+
+	err = checkRestrictions(project, newInstance("wireguard,overlay"), nil)
+	assert.Error(t, err)
+
+	project.Config["restricted.containers.lowlevel"] = "allow"
+	err = checkRestrictions(project, newInstance("overlay"), nil)
+	assert.NoError(t, err)
+}
+
+func TestCheckRestrictionsActivationListen(t *testing.T) {
+	project := api.Project{
+		Name: "p1",
+		ProjectPut: api.ProjectPut{
+			Config: map[string]string{
+				"restricted": "true",
+			},
+		},
+	}
+
+	instances := []api.Instance{{
+		Name: "c1",
+		Type: "container",
+		InstancePut: api.InstancePut{
+			Config: map[string]string{"activation.listen": "0.0.0.0:80", "activation.port": "80"},
+		},
+	}}
+
+	profiles := []api.Profile{{
+		Name: "web",
+		ProfilePut: api.ProfilePut{
+			Config: map[string]string{"activation.listen": "0.0.0.0:80"},
+		},
+	}}
+
+	err := checkRestrictions(project, instances, nil)
+	assert.Error(t, err)
+
+	err = checkRestrictions(project, nil, profiles)
+	assert.Error(t, err)
+
+	// Allowing the low-level options doesn't allow listening on the host.
+	project.Config["restricted.containers.lowlevel"] = "allow"
+	err = checkRestrictions(project, instances, nil)
+	assert.Error(t, err)
+
+	project.Config["restricted.devices.proxy"] = "allow"
+	err = checkRestrictions(project, instances, profiles)
+	assert.NoError(t, err)
+}
+
+func TestCheckRestrictionsContainerLowLevel(t *testing.T) {
+	project := api.Project{
+		Name: "p1",
+		ProjectPut: api.ProjectPut{
+			Config: map[string]string{
+				"restricted": "true",
+			},
+		},
+	}
+
+	for _, key := range []string{"raw.apparmor.profiles", "security.keyring.session"} {
+		instances := []api.Instance{{
+			Name: "c1",
+			Type: "container",
+			InstancePut: api.InstancePut{
+				Config: map[string]string{key: "false"},
+			},
+		}}
+
+		project.Config["restricted.containers.lowlevel"] = "block"
+		err := checkRestrictions(project, instances, nil)
+		assert.Error(t, err, key)
+
+		project.Config["restricted.containers.lowlevel"] = "allow"
+		err = checkRestrictions(project, instances, nil)
+		assert.NoError(t, err, key)
+	}
+}
+
+func TestCheckRestrictionsVMLowLevel(t *testing.T) {
+	project := api.Project{
+		Name: "p1",
+		ProjectPut: api.ProjectPut{
+			Config: map[string]string{
+				"restricted": "true",
+			},
+		},
+	}
+
+	instances := []api.Instance{{
+		Name: "v1",
+		Type: "virtual-machine",
+		InstancePut: api.InstancePut{
+			Config: map[string]string{"limits.cpu.power": "performance"},
+		},
+	}}
+
+	err := checkRestrictions(project, instances, nil)
+	assert.Error(t, err)
+
+	project.Config["restricted.virtual-machines.lowlevel"] = "allow"
+	err = checkRestrictions(project, instances, nil)
+	assert.NoError(t, err)
+
+	// Containers only get scheduler hints.
+	instances[0].Type = "container"
+	project.Config["restricted.virtual-machines.lowlevel"] = "block"
+	err = checkRestrictions(project, instances, nil)
+	assert.NoError(t, err)
+}
+
+func TestCheckRestrictionsIvshmem(t *testing.T) {
+	project := api.Project{
+		Name: "p1",
+		ProjectPut: api.ProjectPut{
+			Config: map[string]string{
+				"restricted": "true",
+			},
+		},
+	}
+
+	instances := []api.Instance{{
+		Name: "v1",
+		Type: "virtual-machine",
+		InstancePut: api.InstancePut{
+			Devices: map[string]map[string]string{"shm0": {"type": "ivshmem", "size": "64MiB"}},
+		},
+	}}
+
+	err := checkRestrictions(project, instances, nil)
+	assert.Error(t, err)
+
+	project.Config["restricted.devices.ivshmem"] = "allow"
+	err = checkRestrictions(project, instances, nil)
+	assert.NoError(t, err)
+}
@@ -437,7 +437,9 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 
 	allowContainerLowLevel := false
 	allowVMLowLevel := false
+	allowProxy := false
 	var allowedIDMapHostUIDs, allowedIDMapHostGIDs []idmap.Entry
+	var allowedKernelModules []string
 
 	for i := range allRestrictions {
 		// Check if this particular restriction is defined explicitly in the project config.
@@ -462,6 +464,9 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 					return nil
 				}
 			}
+		case "restricted.containers.kernel_modules":
+			allowedKernelModules = util.SplitNTrimSpace(restrictionValue, ",", -1, true)
+
 		case "restricted.containers.nesting":
 			containerConfigChecks["security.nesting"] = func(instanceValue string) error {
 				if restrictionValue == "block" && util.IsTrue(instanceValue) {
@@ -561,7 +566,20 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 				return nil
 			}
 
+		case "restricted.devices.ivshmem":
+			devicesChecks["ivshmem"] = func(device map[string]string) error {
+				if restrictionValue != "allow" {
+					return fmt.Errorf("Shared memory devices are forbidden")
+				}
+
+				return nil
+			}
+
 		case "restricted.devices.proxy":
+			if restrictionValue == "allow" {
+				allowProxy = true
+			}
+
 			devicesChecks["proxy"] = func(device map[string]string) error {
 				if restrictionValue != "allow" {
 					return fmt.Errorf("Proxy devices are forbidden")
@@ -679,6 +697,38 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 				continue
 			}
 
+			if isContainerOrProfile && !allowContainerLowLevel && key == "idmap.passthrough.users" {
+				// The host IDs of the passed through users and groups must be allowed like with raw.idmap.
+				idmaps, err := idmap.NewSetFromPassthrough(value)
+				if err != nil {
+					return err
+				}
+
+				for _, entry := range idmaps.Entries {
+					if !entry.HostIDsCoveredBy(allowedIDMapHostUIDs, allowedIDMapHostGIDs) {
+						return fmt.Errorf(`Passing through host ID %d with "idmap.passthrough.users" on %s %q of project %q is forbidden`, entry.HostID, entityTypeLabel, entityName, project.Name)
+					}
+				}
+
+				continue
+			}
+
+			if isContainerOrProfile && !allowContainerLowLevel && key == "linux.kernel_modules" {
+				// The kernel modules must be allowed by the project.
+				for _, module := range util.SplitNTrimSpace(value, ",", -1, true) {
+					if !slices.Contains(allowedKernelModules, module) {
+						return fmt.Errorf("Use of kernel module %q on %s %q of project %q is forbidden", module, entityTypeLabel, entityName, project.Name)
+					}
+				}
+
+				continue
+			}
+
+			if key == "activation.listen" && value != "" && !allowProxy {
+				// Socket activation listens on host addresses just like proxy devices do.
+				return fmt.Errorf("Use of %q on %s %q of project %q is forbidden", key, entityTypeLabel, entityName, project.Name)
+			}
+
 			if isContainerOrProfile && !allowContainerLowLevel && isContainerLowLevelOptionForbidden(key) {
 				return fmt.Errorf("Use of low-level config %q on %s %q of project %q is forbidden", key, entityTypeLabel, entityName, project.Name)
 			}
@@ -795,6 +845,7 @@ var allRestrictions = map[string]string{
 	"restricted.cluster.target":            "block",
 	"restricted.containers.nesting":        "block",
 	"restricted.containers.interception":   "block",
+	"restricted.containers.kernel_modules": "",
 	"restricted.containers.lowlevel":       "block",
 	"restricted.containers.privilege":      "unprivileged",
 	"restricted.virtual-machines.lowlevel": "block",
@@ -805,6 +856,7 @@ var allRestrictions = map[string]string{
 	"restricted.devices.gpu":               "block",
 	"restricted.devices.usb":               "block",
 	"restricted.devices.pci":               "block",
+	"restricted.devices.ivshmem":           "block",
 	"restricted.devices.proxy":             "block",
 	"restricted.devices.nic":               "managed",
 	"restricted.devices.disk":              "managed",
@@ -835,15 +887,16 @@ func isContainerLowLevelOptionForbidden(key string) bool {
 	if slices.Contains([]string{
 		"boot.host_shutdown_action",
 		"boot.host_shutdown_timeout",
-		"linux.kernel_modules",
 		"limits.memory.swap",
 		"raw.apparmor",
+		"raw.apparmor.profiles",
 		"raw.idmap",
 		"raw.lxc",
 		"raw.seccomp",
 		"security.guestapi.images",
 		"security.idmap.base",
 		"security.idmap.size",
+		"security.keyring.session",
 	},
 		key) {
 		return true
@@ -857,6 +910,7 @@ func isVMLowLevelOptionForbidden(key string) bool {
 	return slices.Contains([]string{
 		"boot.host_shutdown_action",
 		"boot.host_shutdown_timeout",
+		"limits.cpu.power",
 		"limits.memory.hugepages",
 		"raw.idmap",
 		"raw.qemu",
@@ -244,6 +244,35 @@ func NetworkIntegrationAllowed(reqProjectConfig map[string]string, integrationNa
 	return slices.Contains(allowedRestrictedIntegrations, integrationName)
 }
 
+// StorageVolumeDefaultKey splits a "storage.<pool>.volume.<option>" project config key into the pool name and
+// the volume config key it sets the default of.
+func StorageVolumeDefaultKey(key string) (string, string, bool) {
+	if !strings.HasPrefix(key, "storage.") {
+		return "", "", false
+	}
+
+	poolName, volKey, found := strings.Cut(strings.TrimPrefix(key, "storage."), ".volume.")
+	if !found || poolName == "" || volKey == "" {
+		return "", "", false
+	}
+
+	return poolName, volKey, true
+}
+
+// StorageVolumeDefaults returns the volume config defaults set by the project config for the given storage pool,
+// keyed by volume config key.
+func StorageVolumeDefaults(projectConfig map[string]string, poolName string) map[string]string {
+	defaults := map[string]string{}
+	for k, v := range projectConfig {
+		keyPoolName, volKey, ok := StorageVolumeDefaultKey(k)
+		if ok && keyPoolName == poolName {
+			defaults[volKey] = v
+		}
+	}
+
+	return defaults
+}
+
 // ImageProjectFromRecord returns the project name to use for the image based on the supplied project.
 // If the project supplied has the "features.images" flag enabled then the project name is returned,
 // otherwise the default project name is returned.
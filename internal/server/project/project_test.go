@@ -45,3 +45,18 @@ func ExampleStorageVolume() {
 	// Output: default_test
 	// project_name_test1
 }
+
+func ExampleStorageVolumeDefaultKey() {
+	poolName, volKey, ok := project.StorageVolumeDefaultKey("storage.default.volume.snapshots.schedule")
+	fmt.Println(poolName, volKey, ok)
+
+	poolName, volKey, ok = project.StorageVolumeDefaultKey("storage.default.volume.zfs.blocksize")
+	fmt.Println(poolName, volKey, ok)
+
+	_, _, ok = project.StorageVolumeDefaultKey("storage.default.size")
+	fmt.Println(ok)
+
+	// Output: default snapshots.schedule true
+	// default zfs.blocksize true
+	// false
+}
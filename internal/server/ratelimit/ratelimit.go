@@ -0,0 +1,117 @@
+// Package ratelimit provides token bucket rate limiting of requests grouped by key.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// purgeInterval is how often the buckets of idle keys are dropped.
+const purgeInterval = time.Minute
+
+// bucket tracks the available tokens of a single key.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter applies a token bucket rate limit to each key independently.
+type Limiter struct {
+	mu sync.Mutex
+
+	rate    float64 // Tokens added per second.
+	burst   float64
+	buckets map[string]*bucket
+
+	lastPurge time.Time
+	now       func() time.Time
+}
+
+// ParseRate parses a rate of the form "<requests>/<unit>" where unit is one of "s", "m" or "h".
+// It returns the number of requests and the period they are allowed in.
+func ParseRate(value string) (uint64, time.Duration, error) {
+	count, unit, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("Invalid rate %q, must be of the form <requests>/<unit>", value)
+	}
+
+	requests, err := strconv.ParseUint(count, 10, 64)
+	if err != nil || requests == 0 {
+		return 0, 0, fmt.Errorf("Invalid number of requests %q", count)
+	}
+
+	var period time.Duration
+	switch unit {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		return 0, 0, fmt.Errorf("Invalid rate unit %q, must be one of s, m or h", unit)
+	}
+
+	return requests, period, nil
+}
+
+// NewLimiter returns a limiter allowing the given number of requests per period with bursts of up to burst requests.
+// A burst of 0 allows the number of requests of a whole period at once.
+func NewLimiter(requests uint64, period time.Duration, burst uint64) *Limiter {
+	if burst == 0 {
+		burst = requests
+	}
+
+	return &Limiter{
+		rate:    float64(requests) / period.Seconds(),
+		burst:   float64(burst),
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// Allow consumes a token for the key.
+// If no token is available, it returns false along with the time until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	// Drop the buckets which refilled entirely, they are equivalent to new ones.
+	if now.Sub(l.lastPurge) >= purgeInterval {
+		for k, b := range l.buckets {
+			if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+				delete(l.buckets, k)
+			}
+		}
+
+		l.lastPurge = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	// Refill the bucket.
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+
+	return true, 0
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value    string
+		requests uint64
+		period   time.Duration
+		err      bool
+	}{
+		{value: "10/s", requests: 10, period: time.Second},
+		{value: "60/m", requests: 60, period: time.Minute},
+		{value: "7200/h", requests: 7200, period: time.Hour},
+		{value: "10", err: true},
+		{value: "0/s", err: true},
+		{value: "-1/s", err: true},
+		{value: "10/d", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			requests, period, err := ParseRate(test.value)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.requests, requests)
+			assert.Equal(t, test.period, period)
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	l := NewLimiter(1, time.Second, 2)
+	l.now = func() time.Time { return now }
+
+	// The burst is available immediately.
+	for i := 0; i < 2; i++ {
+		ok, _ := l.Allow("a")
+		assert.True(t, ok)
+	}
+
+	ok, wait := l.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, wait)
+
+	// Other keys have their own bucket.
+	ok, _ = l.Allow("b")
+	assert.True(t, ok)
+
+	// Tokens are added over time.
+	now = now.Add(500 * time.Millisecond)
+	ok, wait = l.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+
+	// Buckets of idle keys are dropped.
+	now = now.Add(purgeInterval)
+	ok, _ = l.Allow("c")
+	assert.True(t, ok)
+	assert.Len(t, l.buckets, 1)
+
+	// The burst defaults to the number of requests per period.
+	l = NewLimiter(3, time.Minute, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("a")
+		assert.True(t, ok)
+	}
+
+	ok, wait = l.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 20*time.Second, wait)
+}
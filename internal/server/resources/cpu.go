@@ -308,7 +308,7 @@ func GetCPU() (*api.ResourcesCPU, error) {
 					}
 
 					// Check if we already have the data and seek to next
-					if resSocket.Vendor != "" && resSocket.Name != "" {
+					if resSocket.Vendor != "" && resSocket.Name != "" && resSocket.Flags != nil {
 						continue
 					}
 
@@ -331,6 +331,11 @@ func GetCPU() (*api.ResourcesCPU, error) {
 						resSocket.Name = value
 						continue
 					}
+
+					if key == "flags" || key == "Features" {
+						resSocket.Flags = strings.Fields(value)
+						continue
+					}
 				}
 
 				break
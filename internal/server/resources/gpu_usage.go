@@ -0,0 +1,204 @@
+package resources
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+// GPUUsage represents the current usage of a GPU.
+type GPUUsage struct {
+	// Utilization of the GPU in percent.
+	Utilization int64
+
+	// Used and total memory of the GPU in bytes.
+	MemoryUsage int64
+	MemoryTotal int64
+
+	// Memory used by each process running on the GPU, keyed by host PID.
+	// This is nil when the driver doesn't provide per-process accounting.
+	Processes map[int64]int64
+}
+
+// gpuUsageCacheInterval is how long the GPU usage is cached for, so that rendering the state or metrics of all the
+// instances only queries the GPUs once.
+const gpuUsageCacheInterval = 5 * time.Second
+
+var gpuUsageMu sync.Mutex
+var gpuUsageCache map[string]*GPUUsage
+var gpuUsageCacheTime time.Time
+
+// GetGPUUsage returns the current usage of the NVIDIA and AMD GPUs on the system, keyed by PCI address.
+// The result is shared between callers and must not be modified.
+func GetGPUUsage() (map[string]*GPUUsage, error) {
+	gpuUsageMu.Lock()
+	defer gpuUsageMu.Unlock()
+
+	if gpuUsageCache != nil && time.Since(gpuUsageCacheTime) < gpuUsageCacheInterval {
+		return gpuUsageCache, nil
+	}
+
+	usage := map[string]*GPUUsage{}
+
+	err := loadNvidiaUsage(usage)
+	if err != nil {
+		return nil, err
+	}
+
+	err = loadAmdgpuUsage(usage)
+	if err != nil {
+		return nil, err
+	}
+
+	gpuUsageCache = usage
+	gpuUsageCacheTime = time.Now()
+
+	return usage, nil
+}
+
+// nvidiaPCIAddress converts a PCI bus ID as reported by nvidia-smi (00000000:01:00.0) to the usual format.
+func nvidiaPCIAddress(busID string) string {
+	busID = strings.ToLower(strings.TrimSpace(busID))
+
+	domain, rest, ok := strings.Cut(busID, ":")
+	if ok && len(domain) > 4 {
+		busID = domain[len(domain)-4:] + ":" + rest
+	}
+
+	return busID
+}
+
+// nvidiaValue parses a value as reported by nvidia-smi, like "1024 MiB" or "5 %".
+// Values are reported as "N/A" when not supported by the card.
+func nvidiaValue(value string) (int64, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return -1, fmt.Errorf("Empty value")
+	}
+
+	return strconv.ParseInt(fields[0], 10, 64)
+}
+
+// nvidiaSMILog is the part of the XML output of "nvidia-smi -q -x" holding the usage of the GPUs.
+type nvidiaSMILog struct {
+	GPUs []struct {
+		BusID string `xml:"pci>pci_bus_id"`
+
+		MemoryUsed  string `xml:"fb_memory_usage>used"`
+		MemoryTotal string `xml:"fb_memory_usage>total"`
+		Utilization string `xml:"utilization>gpu_util"`
+
+		Processes []struct {
+			PID        string `xml:"pid"`
+			UsedMemory string `xml:"used_memory"`
+		} `xml:"processes>process_info"`
+	} `xml:"gpu"`
+}
+
+// parseNvidiaUsage parses the XML output of "nvidia-smi -q -x".
+func parseNvidiaUsage(output []byte, usage map[string]*GPUUsage) error {
+	smiLog := nvidiaSMILog{}
+	err := xml.Unmarshal(output, &smiLog)
+	if err != nil {
+		return fmt.Errorf("Failed parsing nvidia-smi output: %w", err)
+	}
+
+	for _, card := range smiLog.GPUs {
+		gpu := &GPUUsage{Processes: map[int64]int64{}}
+
+		gpu.Utilization, _ = nvidiaValue(card.Utilization)
+		gpu.Utilization = max(gpu.Utilization, 0)
+
+		memoryUsage, err := nvidiaValue(card.MemoryUsed)
+		if err == nil {
+			gpu.MemoryUsage = memoryUsage * 1024 * 1024
+		}
+
+		memoryTotal, err := nvidiaValue(card.MemoryTotal)
+		if err == nil {
+			gpu.MemoryTotal = memoryTotal * 1024 * 1024
+		}
+
+		for _, process := range card.Processes {
+			pid, err := strconv.ParseInt(process.PID, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			memoryUsage, err := nvidiaValue(process.UsedMemory)
+			if err != nil {
+				continue
+			}
+
+			gpu.Processes[pid] += memoryUsage * 1024 * 1024
+		}
+
+		usage[nvidiaPCIAddress(card.BusID)] = gpu
+	}
+
+	return nil
+}
+
+func loadNvidiaUsage(usage map[string]*GPUUsage) error {
+	// Skip systems without the NVIDIA driver.
+	_, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		return nil
+	}
+
+	// A single query reports both the usage of the GPUs and that of their processes.
+	output, err := subprocess.RunCommand("nvidia-smi", "-q", "-x")
+	if err != nil {
+		return fmt.Errorf("Failed running nvidia-smi: %w", err)
+	}
+
+	return parseNvidiaUsage([]byte(output), usage)
+}
+
+func loadAmdgpuUsage(usage map[string]*GPUUsage) error {
+	if !sysfsExists(sysClassDrm) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(sysClassDrm)
+	if err != nil {
+		return fmt.Errorf("Failed to list %q: %w", sysClassDrm, err)
+	}
+
+	for _, entry := range entries {
+		// Only consider the primary nodes (cardN) and skip the connectors (cardN-DP-1).
+		name := entry.Name()
+		if !strings.HasPrefix(name, "card") || strings.Contains(name, "-") {
+			continue
+		}
+
+		devicePath := filepath.Join(sysClassDrm, name, "device")
+
+		// The usage files are only provided by the amdgpu driver.
+		if !sysfsExists(filepath.Join(devicePath, "gpu_busy_percent")) {
+			continue
+		}
+
+		linkTarget, err := filepath.EvalSymlinks(devicePath)
+		if err != nil {
+			continue
+		}
+
+		gpu := &GPUUsage{}
+		gpu.Utilization, _ = readInt(filepath.Join(devicePath, "gpu_busy_percent"))
+		gpu.MemoryUsage, _ = readInt(filepath.Join(devicePath, "mem_info_vram_used"))
+		gpu.MemoryTotal, _ = readInt(filepath.Join(devicePath, "mem_info_vram_total"))
+
+		usage[filepath.Base(linkTarget)] = gpu
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testNvidiaSMILog = `<?xml version="1.0" ?>
+<nvidia_smi_log>
+	<driver_version>550.54.14</driver_version>
+	<attached_gpus>2</attached_gpus>
+	<gpu id="00000000:01:00.0">
+		<pci>
+			<pci_bus_id>00000000:01:00.0</pci_bus_id>
+		</pci>
+		<fb_memory_usage>
+			<total>24576 MiB</total>
+			<reserved>312 MiB</reserved>
+			<used>2048 MiB</used>
+			<free>22216 MiB</free>
+		</fb_memory_usage>
+		<utilization>
+			<gpu_util>42 %</gpu_util>
+			<memory_util>10 %</memory_util>
+		</utilization>
+		<processes>
+			<process_info>
+				<pid>1234</pid>
+				<type>C</type>
+				<process_name>python3</process_name>
+				<used_memory>1024 MiB</used_memory>
+			</process_info>
+			<process_info>
+				<pid>5678</pid>
+				<type>G</type>
+				<process_name>Xorg</process_name>
+				<used_memory>512 MiB</used_memory>
+			</process_info>
+		</processes>
+	</gpu>
+	<gpu id="00000000:02:00.0">
+		<pci>
+			<pci_bus_id>00000000:02:00.0</pci_bus_id>
+		</pci>
+		<fb_memory_usage>
+			<total>N/A</total>
+			<used>N/A</used>
+		</fb_memory_usage>
+		<utilization>
+			<gpu_util>N/A</gpu_util>
+		</utilization>
+		<processes>
+		</processes>
+	</gpu>
+</nvidia_smi_log>
+`
+
+func TestParseNvidiaUsage(t *testing.T) {
+	usage := map[string]*GPUUsage{}
+	require.NoError(t, parseNvidiaUsage([]byte(testNvidiaSMILog), usage))
+
+	require.Equal(t, map[string]*GPUUsage{
+		"0000:01:00.0": {
+			Utilization: 42,
+			MemoryUsage: 2048 * 1024 * 1024,
+			MemoryTotal: 24576 * 1024 * 1024,
+			Processes:   map[int64]int64{1234: 1024 * 1024 * 1024, 5678: 512 * 1024 * 1024},
+		},
+		"0000:02:00.0": {
+			Processes: map[int64]int64{},
+		},
+	}, usage)
+
+	require.Error(t, parseNvidiaUsage([]byte("nvidia"), map[string]*GPUUsage{}))
+}
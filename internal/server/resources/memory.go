@@ -14,6 +14,7 @@ import (
 
 var sysDevicesNode = "/sys/devices/system/node"
 var sysDevicesSystemMemory = "/sys/devices/system/memory"
+var sysKernelMMHugepages = "/sys/kernel/mm/hugepages"
 
 type meminfo struct {
 	Cached         uint64
@@ -196,6 +197,50 @@ func getTotalMemory(sysDevicesBase string) uint64 {
 	return blockSize * count
 }
 
+// getHugepages returns the number of total and free huge pages of each size found in the given sysfs path.
+func getHugepages(path string) ([]api.ResourcesMemoryHugepages, error) {
+	if !sysfsExists(path) {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list %q: %w", path, err)
+	}
+
+	hugepages := []api.ResourcesMemoryHugepages{}
+	for _, entry := range entries {
+		// Entries are named after the page size, for example "hugepages-2048kB".
+		sizeKB, found := strings.CutSuffix(strings.TrimPrefix(entry.Name(), "hugepages-"), "kB")
+		if !found {
+			continue
+		}
+
+		size, err := strconv.ParseUint(sizeKB, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		total, err := readUint(filepath.Join(path, entry.Name(), "nr_hugepages"))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read %q: %w", filepath.Join(path, entry.Name(), "nr_hugepages"), err)
+		}
+
+		free, err := readUint(filepath.Join(path, entry.Name(), "free_hugepages"))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read %q: %w", filepath.Join(path, entry.Name(), "free_hugepages"), err)
+		}
+
+		hugepages = append(hugepages, api.ResourcesMemoryHugepages{
+			Size:  size * 1024,
+			Total: total,
+			Free:  free,
+		})
+	}
+
+	return hugepages, nil
+}
+
 // GetMemory returns a filled api.ResourcesMemory struct ready for use by Incus.
 func GetMemory() (*api.ResourcesMemory, error) {
 	memory := api.ResourcesMemory{}
@@ -219,6 +264,11 @@ func GetMemory() (*api.ResourcesMemory, error) {
 	memory.HugepagesTotal = info.HugepagesTotal * info.HugepagesSize
 	memory.HugepagesSize = info.HugepagesSize
 
+	memory.Hugepages, err = getHugepages(sysKernelMMHugepages)
+	if err != nil {
+		return nil, err
+	}
+
 	memory.Used = info.Total - info.Free - info.Cached - info.Buffers
 	memory.Total = info.Total
 
@@ -261,6 +311,11 @@ func GetMemory() (*api.ResourcesMemory, error) {
 			node.HugepagesUsed = (info.HugepagesTotal - info.HugepagesFree) * memory.HugepagesSize
 			node.HugepagesTotal = info.HugepagesTotal * memory.HugepagesSize
 
+			node.Hugepages, err = getHugepages(filepath.Join(entryPath, "hugepages"))
+			if err != nil {
+				return nil, err
+			}
+
 			node.Used = info.Used
 			node.Total = info.Total
 
@@ -2,6 +2,8 @@ package resources
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 
 	"github.com/lxc/incus/v6/shared/api"
 )
@@ -76,3 +78,55 @@ func GetResources() (*api.Resources, error) {
 
 	return &resources, nil
 }
+
+// SetReserved marks the CPU threads and the amount of memory reserved for the host in the resources.
+func SetReserved(res *api.Resources, reservedCPUs string, reservedMemory int64) error {
+	if reservedCPUs != "" {
+		reserved, err := ParseCpuset(reservedCPUs)
+		if err != nil {
+			return err
+		}
+
+		for i := range res.CPU.Sockets {
+			for j := range res.CPU.Sockets[i].Cores {
+				for k := range res.CPU.Sockets[i].Cores[j].Threads {
+					thread := &res.CPU.Sockets[i].Cores[j].Threads[k]
+					thread.Reserved = slices.Contains(reserved, thread.ID)
+				}
+			}
+		}
+	}
+
+	if reservedMemory > 0 {
+		res.Memory.Reserved = uint64(reservedMemory)
+	}
+
+	return nil
+}
+
+// ValidateReservedCPUs checks that a limits.cpu pinning the instance to specific CPUs doesn't include any of the
+// CPU threads reserved for the host.
+func ValidateReservedCPUs(cpuLimit string, reserved []int64) error {
+	if cpuLimit == "" || len(reserved) == 0 {
+		return nil
+	}
+
+	// A CPU count isn't pinned, the instance floating on the CPUs not reserved for the host.
+	_, err := strconv.Atoi(cpuLimit)
+	if err == nil {
+		return nil
+	}
+
+	pins, err := ParseCpuset(cpuLimit)
+	if err != nil {
+		return err
+	}
+
+	for _, pin := range pins {
+		if slices.Contains(reserved, pin) {
+			return fmt.Errorf("Cannot pin to CPU %d as it is reserved for the host by limits.reserve.cpu", pin)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package resources
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestSetReserved(t *testing.T) {
+	newResources := func() *api.Resources {
+		return &api.Resources{
+			CPU: api.ResourcesCPU{
+				Sockets: []api.ResourcesCPUSocket{{
+					Cores: []api.ResourcesCPUCore{
+						{Threads: []api.ResourcesCPUThread{{ID: 0}, {ID: 1}}},
+						{Threads: []api.ResourcesCPUThread{{ID: 2}, {ID: 3}}},
+					},
+				}},
+			},
+			Memory: api.ResourcesMemory{Total: 8 * 1024 * 1024 * 1024},
+		}
+	}
+
+	reservedThreads := func(res *api.Resources) []int64 {
+		ids := []int64{}
+		for _, core := range res.CPU.Sockets[0].Cores {
+			for _, thread := range core.Threads {
+				if thread.Reserved {
+					ids = append(ids, thread.ID)
+				}
+			}
+		}
+
+		return ids
+	}
+
+	res := newResources()
+	require.NoError(t, SetReserved(res, "0,2-3", 1024*1024*1024))
+	require.Equal(t, []int64{0, 2, 3}, reservedThreads(res))
+	require.Equal(t, uint64(1024*1024*1024), res.Memory.Reserved)
+
+	// Nothing is reserved without a reservation.
+	res = newResources()
+	require.NoError(t, SetReserved(res, "", 0))
+	require.Empty(t, reservedThreads(res))
+	require.Zero(t, res.Memory.Reserved)
+
+	require.Error(t, SetReserved(newResources(), "invalid", 0))
+}
+
+func TestValidateReservedCPUs(t *testing.T) {
+	tests := []struct {
+		name     string
+		cpuLimit string
+		reserved []int64
+		fails    bool
+	}{
+		{"No limit", "", []int64{0, 1}, false},
+		{"No reservation", "0-1", nil, false},
+		{"CPU count", "2", []int64{0, 1}, false},
+		{"Pinned to other CPUs", "2-3", []int64{0, 1}, false},
+		{"Pinned to a reserved CPU", "1-2", []int64{0, 1}, true},
+		{"Pinned to a reserved CPU in a list", "3,0", []int64{0, 1}, true},
+		{"Invalid pinning", "a-b", []int64{0, 1}, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		err := ValidateReservedCPUs(tt.cpuLimit, tt.reserved)
+		if tt.fails {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
@@ -566,3 +566,26 @@ func Unauthorized(err error) Response {
 
 	return &errorResponse{http.StatusUnauthorized, message}
 }
+
+// PathPrefixWriter is implemented by the response writers of the requests received under the API path prefix of a
+// reverse proxy.
+type PathPrefixWriter interface {
+	PathPrefix() string
+}
+
+// PathPrefix returns the API path prefix of the request answered through the response writer, if any.
+func PathPrefix(w http.ResponseWriter) string {
+	for {
+		prefixWriter, ok := w.(PathPrefixWriter)
+		if ok {
+			return prefixWriter.PathPrefix()
+		}
+
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return ""
+		}
+
+		w = unwrapper.Unwrap()
+	}
+}
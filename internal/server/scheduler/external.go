@@ -0,0 +1,185 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/scriptlet"
+	"github.com/lxc/incus/v6/internal/server/state"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	"github.com/lxc/incus/v6/internal/server/webhook"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	apiScriptlet "github.com/lxc/incus/v6/shared/api/scriptlet"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// maxResponseSize is the maximum size of a reply of the external placement service.
+const maxResponseSize = 1024 * 1024
+
+// ExternalPlacementRun asks the external instance placement service, if configured, which of the candidate members to use.
+// It returns nil when no external service is configured, when it fails or times out, and when it doesn't select a member,
+// in which case the built-in placement should be used.
+func ExternalPlacementRun(ctx context.Context, l logger.Logger, s *state.State, req *apiScriptlet.InstancePlacement, candidateMembers []db.NodeInfo, leaderAddress string) *db.NodeInfo {
+	endpoint, secret, timeout := s.GlobalConfig.SchedulerExternal()
+	if endpoint == "" || len(candidateMembers) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	memberName, err := externalPlacementQuery(ctx, s, endpoint, secret, req, candidateMembers, leaderAddress)
+	if err != nil {
+		l.Warn("Failed external instance placement, using built-in placement", logger.Ctx{"endpoint": endpoint, "project": req.Project, "instance": req.Name, "err": err})
+		return nil
+	}
+
+	if memberName == "" {
+		return nil
+	}
+
+	for i := range candidateMembers {
+		if candidateMembers[i].Name == memberName {
+			l.Info("External instance placement set member target", logger.Ctx{"project": req.Project, "instance": req.Name, "member": memberName})
+			return &candidateMembers[i]
+		}
+	}
+
+	l.Warn("External instance placement selected an invalid member, using built-in placement", logger.Ctx{"endpoint": endpoint, "project": req.Project, "instance": req.Name, "member": memberName})
+
+	return nil
+}
+
+// externalPlacementQuery asks the external service which of the candidate members to use.
+func externalPlacementQuery(ctx context.Context, s *state.State, endpoint string, secret string, req *apiScriptlet.InstancePlacement, candidateMembers []db.NodeInfo, leaderAddress string) (string, error) {
+	resources, err := scriptlet.InstanceResources(req)
+	if err != nil {
+		return "", err
+	}
+
+	members, err := candidateMembersInfo(ctx, s, candidateMembers, leaderAddress)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(apiScriptlet.InstancePlacementExternal{
+		Request:          *req,
+		Resources:        *resources,
+		CandidateMembers: members,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	client, err := localUtil.HTTPClient("", s.Proxy)
+	if err != nil {
+		return "", err
+	}
+
+	return externalPlacementSend(ctx, client, endpoint, secret, body)
+}
+
+// externalPlacementSend sends a placement request to the external service and returns the member it selected.
+func externalPlacementSend(ctx context.Context, client *http.Client, endpoint string, secret string, body []byte) (string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", version.UserAgent)
+
+	if secret != "" {
+		httpReq.Header.Set("X-Incus-Signature-256", fmt.Sprintf("sha256=%s", webhook.Sign(secret, body)))
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Server returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+
+	result := apiScriptlet.InstancePlacementExternalResult{}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return "", fmt.Errorf("Failed parsing reply: %w", err)
+	}
+
+	return result.Member, nil
+}
+
+// candidateMembersInfo returns the API representation of the candidate members.
+func candidateMembersInfo(ctx context.Context, s *state.State, candidateMembers []db.NodeInfo, leaderAddress string) ([]*api.ClusterMember, error) {
+	var err error
+	var raftNodes []db.RaftNode
+	err = s.DB.Node.Transaction(ctx, func(ctx context.Context, tx *db.NodeTx) error {
+		raftNodes, err = tx.GetRaftNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading RAFT nodes: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*api.ClusterMember, 0, len(candidateMembers))
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		failureDomains, err := tx.GetFailureDomainsNames(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading failure domains names: %w", err)
+		}
+
+		memberFailureDomains, err := tx.GetNodesFailureDomains(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading member failure domains: %w", err)
+		}
+
+		maxVersion, err := tx.GetNodeMaxVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting max member version: %w", err)
+		}
+
+		args := db.NodeInfoArgs{
+			LeaderAddress:        leaderAddress,
+			FailureDomains:       failureDomains,
+			MemberFailureDomains: memberFailureDomains,
+			OfflineThreshold:     s.GlobalConfig.OfflineThreshold(),
+			MaxMemberVersion:     maxVersion,
+			RaftNodes:            raftNodes,
+		}
+
+		for i := range candidateMembers {
+			member, err := candidateMembers[i].ToAPI(ctx, tx, args)
+			if err != nil {
+				return err
+			}
+
+			members = append(members, member)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/webhook"
+)
+
+func TestExternalPlacementSend(t *testing.T) {
+	body := []byte(`{"request": {"name": "c1"}}`)
+
+	tests := []struct {
+		name   string
+		secret string
+		status int
+		reply  string
+		delay  time.Duration
+		member string
+		fails  bool
+	}{
+		{"Selected member", "", http.StatusOK, `{"member": "server02"}`, 0, "server02", false},
+		{"Signed request", "secret", http.StatusOK, `{"member": "server02"}`, 0, "server02", false},
+		{"No member", "", http.StatusOK, `{}`, 0, "", false},
+		{"Server error", "", http.StatusInternalServerError, "Failed", 0, "", true},
+		{"Invalid reply", "", http.StatusOK, "server02", 0, "", true},
+		{"Oversized reply", "", http.StatusOK, `{"member": "` + strings.Repeat("a", maxResponseSize) + `"}`, 0, "", true},
+		{"Timeout", "", http.StatusOK, `{"member": "server02"}`, time.Second, "", true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, string(body), string(data))
+
+			if tt.secret != "" {
+				assert.Equal(t, "sha256="+webhook.Sign(tt.secret, body), r.Header.Get("X-Incus-Signature-256"))
+			} else {
+				assert.Empty(t, r.Header.Get("X-Incus-Signature-256"))
+			}
+
+			select {
+			case <-time.After(tt.delay):
+			case <-r.Context().Done():
+				return
+			}
+
+			w.WriteHeader(tt.status)
+			_, _ = w.Write([]byte(tt.reply))
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		member, err := externalPlacementSend(ctx, server.Client(), server.URL, tt.secret, body)
+		cancel()
+		server.Close()
+
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.member, member)
+	}
+}
@@ -94,6 +94,12 @@ func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State,
 			if err != nil {
 				return nil, err
 			}
+
+			reservedCPUs, reservedMemory := s.LocalConfig.LimitsReserve()
+			err = resources.SetReserved(res, reservedCPUs, reservedMemory)
+			if err != nil {
+				return nil, err
+			}
 		} else {
 			// Get remote member resource usage.
 			var targetMember *db.NodeInfo
@@ -177,62 +183,9 @@ func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State,
 	}
 
 	getInstanceResourcesFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		var err error
-		var res apiScriptlet.InstanceResources
-
-		// Parse limits.cpu.
-		if req.Config["limits.cpu"] != "" {
-			// Check if using shared CPU limits.
-			res.CPUCores, err = strconv.ParseUint(req.Config["limits.cpu"], 10, 64)
-			if err != nil {
-				// Or get count of pinned CPUs.
-				pinnedCPUs, err := resources.ParseCpuset(req.Config["limits.cpu"])
-				if err != nil {
-					return nil, fmt.Errorf("Failed parsing instance resources limits.cpu: %w", err)
-				}
-
-				res.CPUCores = uint64(len(pinnedCPUs))
-			}
-		} else if req.Type == api.InstanceTypeVM {
-			// Apply VM CPU cores defaults if not specified.
-			res.CPUCores = instanceDrivers.QEMUDefaultCPUCores
-		}
-
-		// Parse limits.memory.
-		memoryLimitStr := req.Config["limits.memory"]
-
-		// Apply VM memory limit defaults if not specified.
-		if req.Type == api.InstanceTypeVM && memoryLimitStr == "" {
-			memoryLimitStr = instanceDrivers.QEMUDefaultMemSize
-		}
-
-		if memoryLimitStr != "" {
-			memoryLimit, err := units.ParseByteSizeString(memoryLimitStr)
-			if err != nil {
-				return nil, fmt.Errorf("Failed parsing instance resources limits.memory: %w", err)
-			}
-
-			res.MemorySize = uint64(memoryLimit)
-		}
-
-		// Parse root disk size.
-		_, rootDiskConfig, err := instance.GetRootDiskDevice(req.Devices)
-		if err == nil {
-			rootDiskSizeStr := rootDiskConfig["size"]
-
-			// Apply VM root disk size defaults if not specified.
-			if req.Type == api.InstanceTypeVM && rootDiskSizeStr == "" {
-				rootDiskSizeStr = storageDrivers.DefaultBlockSize
-			}
-
-			if rootDiskSizeStr != "" {
-				rootDiskSize, err := units.ParseByteSizeString(rootDiskSizeStr)
-				if err != nil {
-					return nil, fmt.Errorf("Failed parsing instance resources root disk size: %w", err)
-				}
-
-				res.RootDiskSize = uint64(rootDiskSize)
-			}
+		res, err := InstanceResources(req)
+		if err != nil {
+			return nil, err
 		}
 
 		rv, err := StarlarkMarshal(res)
@@ -552,3 +505,66 @@ func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State,
 
 	return targetMember, nil
 }
+
+// InstanceResources returns the resources required by the instance of a placement request.
+func InstanceResources(req *apiScriptlet.InstancePlacement) (*apiScriptlet.InstanceResources, error) {
+	var err error
+	var res apiScriptlet.InstanceResources
+
+	// Parse limits.cpu.
+	if req.Config["limits.cpu"] != "" {
+		// Check if using shared CPU limits.
+		res.CPUCores, err = strconv.ParseUint(req.Config["limits.cpu"], 10, 64)
+		if err != nil {
+			// Or get count of pinned CPUs.
+			pinnedCPUs, err := resources.ParseCpuset(req.Config["limits.cpu"])
+			if err != nil {
+				return nil, fmt.Errorf("Failed parsing instance resources limits.cpu: %w", err)
+			}
+
+			res.CPUCores = uint64(len(pinnedCPUs))
+		}
+	} else if req.Type == api.InstanceTypeVM {
+		// Apply VM CPU cores defaults if not specified.
+		res.CPUCores = instanceDrivers.QEMUDefaultCPUCores
+	}
+
+	// Parse limits.memory.
+	memoryLimitStr := req.Config["limits.memory"]
+
+	// Apply VM memory limit defaults if not specified.
+	if req.Type == api.InstanceTypeVM && memoryLimitStr == "" {
+		memoryLimitStr = instanceDrivers.QEMUDefaultMemSize
+	}
+
+	if memoryLimitStr != "" {
+		memoryLimit, err := units.ParseByteSizeString(memoryLimitStr)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing instance resources limits.memory: %w", err)
+		}
+
+		res.MemorySize = uint64(memoryLimit)
+	}
+
+	// Parse root disk size.
+	_, rootDiskConfig, err := instance.GetRootDiskDevice(req.Devices)
+	if err == nil {
+		rootDiskSizeStr := rootDiskConfig["size"]
+
+		// Apply VM root disk size defaults if not specified.
+		if req.Type == api.InstanceTypeVM && rootDiskSizeStr == "" {
+			rootDiskSizeStr = storageDrivers.DefaultBlockSize
+		}
+
+		if rootDiskSizeStr != "" {
+			rootDiskSize, err := units.ParseByteSizeString(rootDiskSizeStr)
+			if err != nil {
+				return nil, fmt.Errorf("Failed parsing instance resources root disk size: %w", err)
+			}
+
+			res.RootDiskSize = uint64(rootDiskSize)
+		}
+	}
+
+	return &res, nil
+}
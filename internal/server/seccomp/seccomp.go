@@ -99,6 +99,8 @@ struct incus_seccomp_data_arch {
 	int nr_bpf;
 	int nr_sched_setscheduler;
 	int nr_sysinfo;
+	int nr_finit_module;
+	int nr_init_module;
 };
 
 #define INCUS_SECCOMP_NOTIFY_MKNOD    0
@@ -108,69 +110,71 @@ struct incus_seccomp_data_arch {
 #define INCUS_SECCOMP_NOTIFY_BPF 4
 #define INCUS_SECCOMP_NOTIFY_SCHED_SETSCHEDULER 5
 #define INCUS_SECCOMP_NOTIFY_SYSINFO 6
+#define INCUS_SECCOMP_NOTIFY_FINIT_MODULE 7
+#define INCUS_SECCOMP_NOTIFY_INIT_MODULE 8
 
 // ordered by likelihood of usage...
 static const struct incus_seccomp_data_arch seccomp_notify_syscall_table[] = {
-	{ -1, INCUS_SECCOMP_NOTIFY_MKNOD, INCUS_SECCOMP_NOTIFY_MKNODAT, INCUS_SECCOMP_NOTIFY_SETXATTR, INCUS_SECCOMP_NOTIFY_MOUNT, INCUS_SECCOMP_NOTIFY_BPF, INCUS_SECCOMP_NOTIFY_SCHED_SETSCHEDULER, INCUS_SECCOMP_NOTIFY_SYSINFO},
+	{ -1, INCUS_SECCOMP_NOTIFY_MKNOD, INCUS_SECCOMP_NOTIFY_MKNODAT, INCUS_SECCOMP_NOTIFY_SETXATTR, INCUS_SECCOMP_NOTIFY_MOUNT, INCUS_SECCOMP_NOTIFY_BPF, INCUS_SECCOMP_NOTIFY_SCHED_SETSCHEDULER, INCUS_SECCOMP_NOTIFY_SYSINFO, INCUS_SECCOMP_NOTIFY_FINIT_MODULE, INCUS_SECCOMP_NOTIFY_INIT_MODULE},
 #ifdef AUDIT_ARCH_X86_64
-	{ AUDIT_ARCH_X86_64,      133, 259, 188, 165, 321, 144, 99 },
+	{ AUDIT_ARCH_X86_64,      133, 259, 188, 165, 321, 144, 99, 313, 175 },
 #endif
 #ifdef AUDIT_ARCH_I386
-	{ AUDIT_ARCH_I386,         14, 297, 226,  21, 357, 156, 116 },
+	{ AUDIT_ARCH_I386,         14, 297, 226,  21, 357, 156, 116, 350, 128 },
 #endif
 #ifdef AUDIT_ARCH_AARCH64
-	{ AUDIT_ARCH_AARCH64,      -1,  33,   5,  21, 386, 156, 179 },
+	{ AUDIT_ARCH_AARCH64,      -1,  33,   5,  21, 386, 156, 179, 273, 105 },
 #endif
 #ifdef AUDIT_ARCH_ARM
-	{ AUDIT_ARCH_ARM,          14, 324, 226,  21, 386, 156, 116 },
+	{ AUDIT_ARCH_ARM,          14, 324, 226,  21, 386, 156, 116, 379, 128 },
 #endif
 #ifdef AUDIT_ARCH_ARMEB
-	{ AUDIT_ARCH_ARMEB,        14, 324, 226,  21, 386, 156, 116 },
+	{ AUDIT_ARCH_ARMEB,        14, 324, 226,  21, 386, 156, 116, 379, 128 },
 #endif
 #ifdef AUDIT_ARCH_S390
-	{ AUDIT_ARCH_S390,         14, 290, 224,  21, 386, 156, 116 },
+	{ AUDIT_ARCH_S390,         14, 290, 224,  21, 386, 156, 116, 344, 128 },
 #endif
 #ifdef AUDIT_ARCH_S390X
-	{ AUDIT_ARCH_S390X,        14, 290, 224,  21, 351, 156, 116 },
+	{ AUDIT_ARCH_S390X,        14, 290, 224,  21, 351, 156, 116, 344, 128 },
 #endif
 #ifdef AUDIT_ARCH_PPC
-	{ AUDIT_ARCH_PPC,          14, 288, 209,  21, 361, 156, 116 },
+	{ AUDIT_ARCH_PPC,          14, 288, 209,  21, 361, 156, 116, 353, 128 },
 #endif
 #ifdef AUDIT_ARCH_PPC64
-	{ AUDIT_ARCH_PPC64,        14, 288, 209,  21, 361, 156, 116 },
+	{ AUDIT_ARCH_PPC64,        14, 288, 209,  21, 361, 156, 116, 353, 128 },
 #endif
 #ifdef AUDIT_ARCH_PPC64LE
-	{ AUDIT_ARCH_PPC64LE,      14, 288, 209,  21, 361, 156, 116 },
+	{ AUDIT_ARCH_PPC64LE,      14, 288, 209,  21, 361, 156, 116, 353, 128 },
 #endif
 #ifdef AUDIT_ARCH_RISCV64
-	{ AUDIT_ARCH_RISCV64,      -1,  33,   5,  40, 280, -1, 179 },
+	{ AUDIT_ARCH_RISCV64,      -1,  33,   5,  40, 280, -1, 179, 273, 105 },
 #endif
 #ifdef AUDIT_ARCH_SPARC
-	{ AUDIT_ARCH_SPARC,        14, 286, 169, 167, 349, 243, 214 },
+	{ AUDIT_ARCH_SPARC,        14, 286, 169, 167, 349, 243, 214, 342, 190 },
 #endif
 #ifdef AUDIT_ARCH_SPARC64
-	{ AUDIT_ARCH_SPARC64,      14, 286, 169, 167, 349, 243, 214 },
+	{ AUDIT_ARCH_SPARC64,      14, 286, 169, 167, 349, 243, 214, 342, 190 },
 #endif
 #ifdef AUDIT_ARCH_MIPS
-	{ AUDIT_ARCH_MIPS,         14, 290, 224,  21,  -1, 141, 4116 },
+	{ AUDIT_ARCH_MIPS,         14, 290, 224,  21,  -1, 141, 4116, 4348, 4128 },
 #endif
 #ifdef AUDIT_ARCH_MIPSEL
-	{ AUDIT_ARCH_MIPSEL,       14, 290, 224,  21,  -1, 141, 4116 },
+	{ AUDIT_ARCH_MIPSEL,       14, 290, 224,  21,  -1, 141, 4116, 4348, 4128 },
 #endif
 #ifdef AUDIT_ARCH_MIPS64
-	{ AUDIT_ARCH_MIPS64,      131, 249, 180, 160,  -1, 141, 5097 },
+	{ AUDIT_ARCH_MIPS64,      131, 249, 180, 160,  -1, 141, 5097, 5307, 5168 },
 #endif
 #ifdef AUDIT_ARCH_MIPS64N32
-	{ AUDIT_ARCH_MIPS64N32,   131, 253, 180, 160,  -1, 141, 4116 },
+	{ AUDIT_ARCH_MIPS64N32,   131, 253, 180, 160,  -1, 141, 4116, 6312, 6168 },
 #endif
 #ifdef AUDIT_ARCH_MIPSEL64
-	{ AUDIT_ARCH_MIPSEL64,    131, 249, 180, 160,  -1, 141, 5097 },
+	{ AUDIT_ARCH_MIPSEL64,    131, 249, 180, 160,  -1, 141, 5097, 5307, 5168 },
 #endif
 #ifdef AUDIT_ARCH_MIPSEL64N32
-	{ AUDIT_ARCH_MIPSEL64N32, 131, 253, 180, 160,  -1, 141, 4116 },
+	{ AUDIT_ARCH_MIPSEL64N32, 131, 253, 180, 160,  -1, 141, 4116, 6312, 6168 },
 #endif
 #ifdef AUDIT_ARCH_LOONGARCH64
-	{ AUDIT_ARCH_LOONGARCH64, -1,  33,   5,  40, 280, 119, 179 },
+	{ AUDIT_ARCH_LOONGARCH64, -1,  33,   5,  40, 280, 119, 179, 273, 105 },
 #endif
 };
 
@@ -211,6 +215,12 @@ static int seccomp_notify_get_syscall(struct seccomp_notif *req,
 		if (entry->nr_sysinfo == req->data.nr)
 			return INCUS_SECCOMP_NOTIFY_SYSINFO;
 
+		if (entry->nr_finit_module == req->data.nr)
+			return INCUS_SECCOMP_NOTIFY_FINIT_MODULE;
+
+		if (entry->nr_init_module == req->data.nr)
+			return INCUS_SECCOMP_NOTIFY_INIT_MODULE;
+
 		break;
 	}
 
@@ -452,6 +462,7 @@ import "C"
 
 import (
 	"context"
+	"debug/elf"
 	"fmt"
 	"io"
 	"net"
@@ -471,6 +482,8 @@ import (
 	"github.com/lxc/incus/v6/internal/netutils"
 	"github.com/lxc/incus/v6/internal/server/cgroup"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/state"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
@@ -491,6 +504,8 @@ const incusSeccompNotifyMount = C.INCUS_SECCOMP_NOTIFY_MOUNT
 const incusSeccompNotifyBpf = C.INCUS_SECCOMP_NOTIFY_BPF
 const incusSeccompNotifySchedSetscheduler = C.INCUS_SECCOMP_NOTIFY_SCHED_SETSCHEDULER
 const incusSeccompNotifySysinfo = C.INCUS_SECCOMP_NOTIFY_SYSINFO
+const incusSeccompNotifyFinitModule = C.INCUS_SECCOMP_NOTIFY_FINIT_MODULE
+const incusSeccompNotifyInitModule = C.INCUS_SECCOMP_NOTIFY_INIT_MODULE
 
 const seccompHeader = `2
 `
@@ -525,6 +540,10 @@ const seccompNotifySchedSetscheduler = `sched_setscheduler notify
 const seccompNotifySysinfo = `sysinfo notify
 `
 
+const seccompNotifyKernelModules = `init_module notify
+finit_module notify
+`
+
 const seccompBlockNewMountAPI = `fsopen errno 38
 fsconfig errno 38
 fsinfo errno 38
@@ -628,6 +647,17 @@ type Instance interface {
 	InsertSeccompUnixDevice(prefix string, m deviceConfig.Device, pid int) error
 }
 
+// syscallInstance is the instance a syscall is intercepted for, as the source of lifecycle events.
+// Intercepted syscalls never run as part of an operation.
+type syscallInstance struct {
+	Instance
+}
+
+// Operation returns no operation.
+func (syscallInstance) Operation() *operations.Operation {
+	return nil
+}
+
 var seccompPath = internalUtil.VarPath("security", "seccomp")
 
 // ProfilePath returns the seccomp path for the instance.
@@ -673,6 +703,11 @@ func InstanceNeedsPolicy(c Instance) bool {
 		}
 	}
 
+	// Check for kernel modules loaded on demand
+	if config["linux.kernel_modules.load"] == "on-demand" {
+		return true
+	}
+
 	// Check for boolean keys that default to true
 	value, ok := config["security.syscalls.deny_default"]
 	if !ok {
@@ -723,6 +758,15 @@ func InstanceNeedsIntercept(s *state.State, c Instance) (bool, error) {
 		needed = true
 	}
 
+	if config["linux.kernel_modules.load"] == "on-demand" {
+		err := lxcSupportSeccompNotify(s)
+		if err != nil {
+			return needed, err
+		}
+
+		needed = true
+	}
+
 	return needed, nil
 }
 
@@ -777,7 +821,12 @@ func seccompGetPolicyContent(s *state.State, c Instance) (string, error) {
 		}
 
 		if !ok || util.IsTrue(defaultFlag) {
-			policy += defaultSeccompPolicy
+			if config["linux.kernel_modules.load"] == "on-demand" {
+				// Module loading is intercepted instead.
+				policy += strings.Replace(defaultSeccompPolicy, "init_module errno 38\nfinit_module errno 38\n", "", 1)
+			} else {
+				policy += defaultSeccompPolicy
+			}
 		}
 	}
 
@@ -820,6 +869,10 @@ func seccompGetPolicyContent(s *state.State, c Instance) (string, error) {
 		if util.IsTrue(config["security.syscalls.intercept.bpf"]) {
 			policy += seccompNotifyBpf
 		}
+
+		if config["linux.kernel_modules.load"] == "on-demand" {
+			policy += seccompNotifyKernelModules
+		}
 	}
 
 	if allowlist != "" {
@@ -1886,6 +1939,178 @@ func (s *Server) HandleSysinfoSyscall(c Instance, siov *Iovec) int {
 	return 0
 }
 
+// kernelModuleName returns the name of the kernel module stored at the given path.
+// Dashes and underscores are interchangeable in module names so dashes are normalized to underscores.
+func kernelModuleName(path string) string {
+	name := filepath.Base(path)
+	for _, suffix := range []string{".ko.xz", ".ko.zst", ".ko.gz", ".ko"} {
+		trimmed, found := strings.CutSuffix(name, suffix)
+		if found {
+			name = trimmed
+			break
+		}
+	}
+
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// kernelModuleImageName returns the name of the kernel module in the given ELF image, as recorded in its .modinfo
+// section. Dashes and underscores are interchangeable in module names so dashes are normalized to underscores.
+func kernelModuleImageName(image io.ReaderAt) (string, error) {
+	f, err := elf.NewFile(image)
+	if err != nil {
+		return "", fmt.Errorf("Invalid module image: %w", err)
+	}
+
+	section := f.Section(".modinfo")
+	if section == nil {
+		return "", fmt.Errorf("Module image has no .modinfo section")
+	}
+
+	// The section only holds a few short strings.
+	if section.Size > 1024*1024 {
+		return "", fmt.Errorf("Module image has a .modinfo section of %d bytes", section.Size)
+	}
+
+	data, err := section.Data()
+	if err != nil {
+		return "", fmt.Errorf("Failed reading the .modinfo section: %w", err)
+	}
+
+	for _, entry := range strings.Split(string(data), "\x00") {
+		name, ok := strings.CutPrefix(entry, "name=")
+		if ok && name != "" {
+			return strings.ReplaceAll(name, "-", "_"), nil
+		}
+	}
+
+	return "", fmt.Errorf("Module image has no name")
+}
+
+// processMemory reads the memory of a process through its /proc/<pid>/mem file descriptor.
+type processMemory int
+
+// ReadAt implements io.ReaderAt.
+func (fd processMemory) ReadAt(p []byte, off int64) (int, error) {
+	n, err := unix.Pread(int(fd), p, off)
+	if err != nil {
+		return n, err
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// kernelModuleRequesterAllowed returns whether the process behind a syscall may request kernel modules, which is
+// only allowed for root in the container.
+func kernelModuleRequesterAllowed(c Instance, siov *Iovec) bool {
+	uid, gid, _, _, err := TaskIDs(int(siov.req.pid))
+	if err != nil {
+		return false
+	}
+
+	idmapset, err := c.CurrentIdmap()
+	if err != nil {
+		return false
+	}
+
+	nsuid, nsgid := idmapset.ShiftFromNS(uid, gid)
+
+	return nsuid == 0 && nsgid == 0
+}
+
+// loadKernelModule loads a kernel module requested by a container, if allowed by its configuration.
+func (s *Server) loadKernelModule(c Instance, l logger.Logger, module string) int {
+	allowed := false
+	for _, entry := range util.SplitNTrimSpace(c.ExpandedConfig()["linux.kernel_modules"], ",", -1, true) {
+		if strings.ReplaceAll(entry, "-", "_") == module {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		l.Warn("Refused loading kernel module not in the allowed list", logger.Ctx{"module": module})
+		return int(-C.EPERM)
+	}
+
+	err := linux.LoadModule(module)
+	if err != nil {
+		l.Warn("Failed loading kernel module", logger.Ctx{"module": module, "err": err})
+		return int(-C.ENOENT)
+	}
+
+	l.Info("Loaded kernel module", logger.Ctx{"module": module})
+	s.s.Events.SendLifecycle(c.Project().Name, lifecycle.InstanceKernelModuleLoaded.Event(syscallInstance{c}, map[string]any{"module": module}))
+
+	return 0
+}
+
+// HandleFinitModuleSyscall handles finit_module syscalls.
+func (s *Server) HandleFinitModuleSyscall(c Instance, siov *Iovec) int {
+	l := logger.AddContext(logger.Ctx{
+		"container":             c.Name(),
+		"project":               c.Project().Name,
+		"syscall_number":        siov.req.data.nr,
+		"audit_architecture":    siov.req.data.arch,
+		"seccomp_notify_id":     siov.req.id,
+		"seccomp_notify_flags":  siov.req.flags,
+		"seccomp_notify_pid":    siov.req.pid,
+		"seccomp_notify_fd":     siov.notifyFd,
+		"seccomp_notify_mem_fd": siov.memFd,
+	})
+
+	defer l.Debug("Handling finit_module syscall")
+
+	if !kernelModuleRequesterAllowed(c, siov) {
+		return int(-C.EPERM)
+	}
+
+	// The module is identified by the name of the file the container passed.
+	// The content of the file itself is never used.
+	target, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/%d", siov.req.pid, int32(siov.req.data.args[0])))
+	if err != nil {
+		return int(-C.EBADF)
+	}
+
+	return s.loadKernelModule(c, l, kernelModuleName(target))
+}
+
+// HandleInitModuleSyscall handles init_module syscalls.
+func (s *Server) HandleInitModuleSyscall(c Instance, siov *Iovec) int {
+	l := logger.AddContext(logger.Ctx{
+		"container":             c.Name(),
+		"project":               c.Project().Name,
+		"syscall_number":        siov.req.data.nr,
+		"audit_architecture":    siov.req.data.arch,
+		"seccomp_notify_id":     siov.req.id,
+		"seccomp_notify_flags":  siov.req.flags,
+		"seccomp_notify_pid":    siov.req.pid,
+		"seccomp_notify_fd":     siov.notifyFd,
+		"seccomp_notify_mem_fd": siov.memFd,
+	})
+
+	defer l.Debug("Handling init_module syscall")
+
+	if !kernelModuleRequesterAllowed(c, siov) {
+		return int(-C.EPERM)
+	}
+
+	// The module is identified by the name recorded in the image the container passed.
+	// The rest of the image is never used.
+	image := io.NewSectionReader(processMemory(siov.memFd), int64(siov.req.data.args[0]), int64(siov.req.data.args[1]))
+	module, err := kernelModuleImageName(image)
+	if err != nil {
+		l.Warn("Failed identifying kernel module", logger.Ctx{"err": err})
+		return int(-C.ENOEXEC)
+	}
+
+	return s.loadKernelModule(c, l, module)
+}
+
 // MountArgs arguments for mount.
 type MountArgs struct {
 	source    string
@@ -2330,6 +2555,10 @@ func (s *Server) handleSyscall(c Instance, siov *Iovec) int {
 		return s.HandleSchedSetschedulerSyscall(c, siov)
 	case incusSeccompNotifySysinfo:
 		return s.HandleSysinfoSyscall(c, siov)
+	case incusSeccompNotifyFinitModule:
+		return s.HandleFinitModuleSyscall(c, siov)
+	case incusSeccompNotifyInitModule:
+		return s.HandleInitModuleSyscall(c, siov)
 	}
 
 	return int(-C.EINVAL)
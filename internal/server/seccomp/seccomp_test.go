@@ -3,8 +3,13 @@
 package seccomp
 
 import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestMountFlagsToOpts(t *testing.T) {
@@ -18,3 +23,58 @@ func TestMountFlagsToOpts(t *testing.T) {
 		t.Fatal(fmt.Errorf("Mount options parsing failed with invalid option string: %s", opts))
 	}
 }
+
+// kernelModuleImage returns a minimal ELF image holding the given .modinfo section.
+func kernelModuleImage(t *testing.T, modinfo string) []byte {
+	shstrtab := "\x00.modinfo\x00.shstrtab\x00"
+	dataOffset := uint64(binary.Size(elf.Header64{}))
+	sectionsOffset := dataOffset + uint64(len(modinfo)+len(shstrtab))
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_REL),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     sectionsOffset,
+		Ehsize:    uint16(binary.Size(elf.Header64{})),
+		Shentsize: uint16(binary.Size(elf.Section64{})),
+		Shnum:     3,
+		Shstrndx:  2,
+	}
+
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	sections := []elf.Section64{
+		{},
+		{Name: 1, Type: uint32(elf.SHT_PROGBITS), Off: dataOffset, Size: uint64(len(modinfo)), Addralign: 1},
+		{Name: 10, Type: uint32(elf.SHT_STRTAB), Off: dataOffset + uint64(len(modinfo)), Size: uint64(len(shstrtab)), Addralign: 1},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, header))
+	buf.WriteString(modinfo)
+	buf.WriteString(shstrtab)
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, sections))
+
+	return buf.Bytes()
+}
+
+func TestKernelModuleName(t *testing.T) {
+	require.Equal(t, "nf_tables", kernelModuleName("/lib/modules/6.8.0/kernel/net/netfilter/nf_tables.ko.zst"))
+	require.Equal(t, "ip6_tables", kernelModuleName("/lib/modules/6.8.0/kernel/net/ipv6/netfilter/ip6_tables.ko"))
+	require.Equal(t, "snd_hda_intel", kernelModuleName("snd-hda-intel.ko.xz"))
+}
+
+func TestKernelModuleImageName(t *testing.T) {
+	name, err := kernelModuleImageName(bytes.NewReader(kernelModuleImage(t, "license=GPL\x00depends=\x00name=snd-hda-intel\x00vermagic=6.8.0\x00")))
+	require.NoError(t, err)
+	require.Equal(t, "snd_hda_intel", name)
+
+	_, err = kernelModuleImageName(bytes.NewReader(kernelModuleImage(t, "license=GPL\x00")))
+	require.Error(t, err)
+
+	_, err = kernelModuleImageName(bytes.NewReader([]byte("not a module")))
+	require.Error(t, err)
+}
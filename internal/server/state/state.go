@@ -13,6 +13,7 @@ import (
 	clusterConfig "github.com/lxc/incus/v6/internal/server/cluster/config"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/dns"
+	"github.com/lxc/incus/v6/internal/server/edge"
 	"github.com/lxc/incus/v6/internal/server/endpoints"
 	"github.com/lxc/incus/v6/internal/server/events"
 	"github.com/lxc/incus/v6/internal/server/firewall"
@@ -76,9 +77,15 @@ type State struct {
 	// Whether the server is clustered.
 	ServerClustered bool
 
+	// Edge mode state of the local member.
+	Edge *edge.Member
+
 	// Local server start time.
 	StartTime time.Time
 
+	// Clock skew of the local member with the cluster leader, zero when within the threshold.
+	TimeSkew time.Duration
+
 	// Authorizer.
 	Authorizer auth.Authorizer
 
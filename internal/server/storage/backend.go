@@ -21,6 +21,8 @@ import (
 	"unicode"
 
 	"github.com/minio/minio-go/v7"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v2"
 
@@ -30,11 +32,13 @@ import (
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/migration"
 	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/server/apparmor"
 	"github.com/lxc/incus/v6/internal/server/backup"
 	backupConfig "github.com/lxc/incus/v6/internal/server/backup/config"
 	"github.com/lxc/incus/v6/internal/server/cluster/request"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/fault"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
@@ -48,6 +52,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/storage/memorypipe"
 	"github.com/lxc/incus/v6/internal/server/storage/s3"
 	"github.com/lxc/incus/v6/internal/server/storage/s3/miniod"
+	"github.com/lxc/incus/v6/internal/server/tracing"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/api"
@@ -133,6 +138,14 @@ func (b *backend) LocalStatus() string {
 	return db.StoragePoolStateToAPIStatus(node.State)
 }
 
+// traceStart starts the span of a storage operation on the pool, child of the span of the operation if any.
+func (b *backend) traceStart(op *operations.Operation, name string, attrs ...attribute.KeyValue) trace.Span {
+	attrs = append(attrs, attribute.String("incus.storage.pool", b.name), attribute.String("incus.storage.driver", b.driver.Info().Name))
+	_, span := tracing.Start(op.TraceContext(), "storage."+name, attrs...)
+
+	return span
+}
+
 // isStatusReady returns an error if pool is not ready for use on this server.
 func (b *backend) isStatusReady() error {
 	if b.Status() == api.StoragePoolStatusPending {
@@ -258,6 +271,75 @@ func (b *backend) GetResources() (*api.ResourcesStoragePool, error) {
 	return b.driver.GetResources()
 }
 
+// CheckHealth checks that the storage pool is still usable on this server.
+func (b *backend) CheckHealth() error {
+	// Accessing a mounted pool fails or hangs once the device backing it is gone.
+	_, err := os.ReadDir(drivers.GetPoolMountPath(b.name))
+	if err != nil {
+		return fmt.Errorf("Failed accessing the storage pool: %w", err)
+	}
+
+	err = b.driver.CheckHealth()
+	if err != nil && !errors.Is(err, drivers.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+// GetMirrorState returns the state of the mirroring of the pool to its peer clusters.
+func (b *backend) GetMirrorState() (*api.StoragePoolMirrorState, error) {
+	l := b.logger.AddContext(nil)
+	l.Debug("GetMirrorState started")
+	defer l.Debug("GetMirrorState finished")
+
+	state, err := b.driver.GetMirrorState()
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Storage pool driver %q doesn't support mirroring", b.driver.Info().Name)
+		}
+
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// UpdateMirror promotes or demotes the mirrored volumes of the pool.
+func (b *backend) UpdateMirror(req api.StoragePoolMirrorPost) error {
+	l := b.logger.AddContext(logger.Ctx{"action": req.Action, "force": req.Force})
+	l.Debug("UpdateMirror started")
+	defer l.Debug("UpdateMirror finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	switch req.Action {
+	case "promote":
+		err = b.driver.PromoteMirror(req.Force)
+	case "demote":
+		if req.Force {
+			return api.StatusErrorf(http.StatusBadRequest, "Demotions can't be forced")
+		}
+
+		err = b.driver.DemoteMirror()
+	default:
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid mirror action %q", req.Action)
+	}
+
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return api.StatusErrorf(http.StatusBadRequest, "Storage pool driver %q doesn't support mirroring", b.driver.Info().Name)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
 // IsUsed returns whether the storage pool is used by any volumes or profiles (excluding image volumes).
 func (b *backend) IsUsed() (bool, error) {
 	usedBy, err := UsedBy(context.TODO(), b.state, b, true, true, db.StoragePoolVolumeTypeNameImage)
@@ -353,6 +435,11 @@ func (b *backend) Delete(clientType request.ClientType, op *operations.Operation
 	}
 
 	if clientType != request.ClientTypeNormal && b.driver.Info().Remote {
+		err := b.driver.Leave()
+		if err != nil {
+			return err
+		}
+
 		if b.driver.Info().MountedRoot {
 			_, err := b.driver.Unmount()
 			if err != nil {
@@ -411,6 +498,11 @@ func (b *backend) Mount() (bool, error) {
 	b.logger.Debug("Mount started")
 	defer b.logger.Debug("Mount finished")
 
+	err := fault.Inject(fault.PointStoragePoolMount, fault.StageStart, "", b.name)
+	if err != nil {
+		return false, err
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -445,6 +537,11 @@ func (b *backend) Mount() (bool, error) {
 		return false, err
 	}
 
+	err = fault.Inject(fault.PointStoragePoolMount, fault.StageEnd, "", b.name)
+	if err != nil {
+		return false, err
+	}
+
 	revert.Success()
 
 	// Ensure pool is marked as available now its mounted.
@@ -636,16 +733,131 @@ func (b *backend) applyInstanceRootDiskInitialValues(inst instance.Instance, vol
 }
 
 // CreateInstance creates an empty instance.
-func (b *backend) CreateInstance(inst instance.Instance, op *operations.Operation) error {
+func (b *backend) CreateInstance(inst instance.Instance, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 	l.Debug("CreateInstance started")
 	defer l.Debug("CreateInstance finished")
 
+	span := b.traceStart(op, "CreateInstance", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
+	var filler *drivers.VolumeFiller
+	if inst.Type() == instancetype.Container {
+		filler = &drivers.VolumeFiller{
+			Fill: func(vol drivers.Volume, rootBlockPath string, allowUnsafeResize bool) (int64, error) {
+				// Create an empty rootfs.
+				err := os.Mkdir(filepath.Join(vol.MountPath(), "rootfs"), 0755)
+				if err != nil && !os.IsExist(err) {
+					return 0, err
+				}
+
+				return 0, nil
+			},
+		}
+	}
+
+	return b.createInstance(inst, filler, op)
+}
+
+// CreateInstanceFromVolume creates a virtual machine whose root disk is a copy of a block custom volume of srcPool.
+func (b *backend) CreateInstanceFromVolume(inst instance.Instance, srcPool Pool, srcVolName string, op *operations.Operation) (err error) {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "srcPool": srcPool.Name(), "srcVolName": srcVolName})
+	l.Debug("CreateInstanceFromVolume started")
+	defer l.Debug("CreateInstanceFromVolume finished")
+
+	span := b.traceStart(op, "CreateInstanceFromVolume", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
+	if inst.Type() != instancetype.VM {
+		return fmt.Errorf("Only virtual machines can be created from a custom volume")
+	}
+
+	srcVolRow, err := VolumeDBGet(srcPool, inst.Project().Name, srcVolName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	if srcVolRow.ContentType != db.StoragePoolVolumeContentTypeNameBlock {
+		return fmt.Errorf("Custom volume %q isn't a block volume", srcVolName)
+	}
+
+	srcVol := srcPool.GetVolume(drivers.VolumeTypeCustom, drivers.ContentTypeBlock, project.StorageVolume(inst.Project().Name, srcVolName), srcVolRow.Config)
+
+	filler := &drivers.VolumeFiller{
+		Fill: func(vol drivers.Volume, rootBlockPath string, allowUnsafeResize bool) (int64, error) {
+			var srcSize int64
+
+			err := srcVol.MountTask(func(_ string, _ *operations.Operation) error {
+				srcPath, err := srcPool.Driver().GetVolumeDiskPath(srcVol)
+				if err != nil {
+					return err
+				}
+
+				srcSize, err = drivers.BlockDiskSizeBytes(srcPath)
+				if err != nil {
+					return err
+				}
+
+				// Grow the root disk if it's smaller than the source volume.
+				if util.PathExists(rootBlockPath) {
+					volSize, err := drivers.BlockDiskSizeBytes(rootBlockPath)
+					if err != nil {
+						return fmt.Errorf("Error getting current size of %q: %w", rootBlockPath, err)
+					}
+
+					if volSize < srcSize {
+						l.Debug("Increasing volume size", logger.Ctx{"dstPath": rootBlockPath, "oldSize": volSize, "newSize": srcSize, "allowUnsafeResize": allowUnsafeResize})
+						err = vol.SetQuota(fmt.Sprintf("%d", srcSize), allowUnsafeResize, nil)
+						if err != nil {
+							return fmt.Errorf("Error increasing volume size: %w", err)
+						}
+					}
+				}
+
+				l.Debug("Copying custom volume to root disk", logger.Ctx{"srcPath": srcPath, "dstPath": rootBlockPath})
+
+				cmd := []string{
+					"nice", "-n19", // Run with low priority to reduce CPU impact on other processes.
+					"qemu-img", "convert", "-f", "raw", "-O", "raw",
+				}
+
+				// Don't recreate existing block devices.
+				if linux.IsBlockdevPath(rootBlockPath) {
+					cmd = append(cmd, "-n", "-W")
+				}
+
+				cmd = append(cmd, srcPath, rootBlockPath)
+
+				_, err = apparmor.QemuImg(b.state.OS, cmd, srcPath, rootBlockPath)
+				if err != nil {
+					return fmt.Errorf("Failed copying custom volume to %q: %w", rootBlockPath, err)
+				}
+
+				return nil
+			}, op)
+			if err != nil {
+				return -1, err
+			}
+
+			return srcSize, nil
+		},
+	}
+
+	return b.createInstance(inst, filler, op)
+}
+
+// createInstance creates the root volume of a new instance, filled by the optional filler.
+func (b *backend) createInstance(inst instance.Instance, filler *drivers.VolumeFiller, op *operations.Operation) error {
 	err := b.isStatusReady()
 	if err != nil {
 		return err
 	}
 
+	err = fault.Inject(fault.PointStorageInstanceCreate, fault.StageStart, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
 		return err
@@ -673,7 +885,7 @@ func (b *backend) CreateInstance(inst instance.Instance, op *operations.Operatio
 	// Record new volume with authorizer.
 	err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, inst.Project().Name, b.Name(), volType.Singular(), inst.Name(), "")
 	if err != nil {
-		logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
+		b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
 	}
 
 	revert.Add(func() {
@@ -688,21 +900,6 @@ func (b *backend) CreateInstance(inst instance.Instance, op *operations.Operatio
 		return err
 	}
 
-	var filler *drivers.VolumeFiller
-	if inst.Type() == instancetype.Container {
-		filler = &drivers.VolumeFiller{
-			Fill: func(vol drivers.Volume, rootBlockPath string, allowUnsafeResize bool) (int64, error) {
-				// Create an empty rootfs.
-				err := os.Mkdir(filepath.Join(vol.MountPath(), "rootfs"), 0755)
-				if err != nil && !os.IsExist(err) {
-					return 0, err
-				}
-
-				return 0, nil
-			},
-		}
-	}
-
 	err = b.driver.CreateVolume(vol, filler, op)
 	if err != nil {
 		return err
@@ -720,6 +917,11 @@ func (b *backend) CreateInstance(inst instance.Instance, op *operations.Operatio
 		return err
 	}
 
+	err = fault.Inject(fault.PointStorageInstanceCreate, fault.StageEnd, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
@@ -729,11 +931,14 @@ func (b *backend) CreateInstance(inst instance.Instance, op *operations.Operatio
 // it is necessary to return two functions; a post hook that can be run once the instance has been
 // created in the database to run any storage layer finalisations, and a revert hook that can be
 // run if the instance database load process fails that will remove anything created thus far.
-func (b *backend) CreateInstanceFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (func(instance.Instance) error, revert.Hook, error) {
+func (b *backend) CreateInstanceFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (_ func(instance.Instance) error, _ revert.Hook, err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": srcBackup.Project, "instance": srcBackup.Name, "snapshots": srcBackup.Snapshots, "optimizedStorage": *srcBackup.OptimizedStorage})
 	l.Debug("CreateInstanceFromBackup started")
 	defer l.Debug("CreateInstanceFromBackup finished")
 
+	span := b.traceStart(op, "CreateInstanceFromBackup", tracing.InstanceAttributes(srcBackup.Project, srcBackup.Name)...)
+	defer func() { tracing.End(span, err) }()
+
 	// Get the volume name on storage.
 	volStorageName := project.Instance(srcBackup.Project, srcBackup.Name)
 
@@ -859,7 +1064,7 @@ func (b *backend) CreateInstanceFromBackup(srcBackup backup.Info, srcData io.Rea
 		// Record new volume with authorizer.
 		err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, inst.Project().Name, b.Name(), volType.Singular(), inst.Name(), "")
 		if err != nil {
-			logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
+			b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
 		}
 
 		postHookRevert.Add(func() {
@@ -996,12 +1201,20 @@ func (b *backend) CreateInstanceFromBackup(srcBackup backup.Info, srcData io.Rea
 }
 
 // CreateInstanceFromCopy copies an instance volume and optionally its snapshots to new volume(s).
-func (b *backend) CreateInstanceFromCopy(inst instance.Instance, src instance.Instance, snapshots bool, allowInconsistent bool, op *operations.Operation) error {
+func (b *backend) CreateInstanceFromCopy(inst instance.Instance, src instance.Instance, snapshots bool, allowInconsistent bool, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "src": src.Name(), "snapshots": snapshots})
 	l.Debug("CreateInstanceFromCopy started")
 	defer l.Debug("CreateInstanceFromCopy finished")
 
-	err := b.isStatusReady()
+	span := b.traceStart(op, "CreateInstanceFromCopy", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
+	err = b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	err = fault.Inject(fault.PointStorageInstanceCreate, fault.StageStart, inst.Project().Name, inst.Name())
 	if err != nil {
 		return err
 	}
@@ -1093,7 +1306,7 @@ func (b *backend) CreateInstanceFromCopy(inst instance.Instance, src instance.In
 		// Record new volume with authorizer.
 		err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, inst.Project().Name, b.Name(), volType.Singular(), inst.Name(), "")
 		if err != nil {
-			logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
+			b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
 		}
 
 		revert.Add(func() {
@@ -1123,6 +1336,13 @@ func (b *backend) CreateInstanceFromCopy(inst instance.Instance, src instance.In
 			return err
 		}
 
+		transferMethod := api.InstanceTransferMethodCopy
+		if b.driver.CanCloneVolume(srcVol, len(snapshotNames) > 0) {
+			transferMethod = api.InstanceTransferMethodClone
+		}
+
+		recordTransferMethod(op, transferMethod)
+
 		err = b.driver.CreateVolumeFromCopy(vol, srcVol, snapshots, allowInconsistent, op)
 		if err != nil {
 			return err
@@ -1131,6 +1351,7 @@ func (b *backend) CreateInstanceFromCopy(inst instance.Instance, src instance.In
 		// We are copying volumes between storage pools so use migration system as it will
 		// be able to negotiate a common transfer method between pool types.
 		l.Debug("CreateInstanceFromCopy cross-pool mode detected")
+		recordTransferMethod(op, api.InstanceTransferMethodMigration)
 
 		// Negotiate the migration type to use.
 		offeredTypes := srcPool.MigrationTypes(contentType, false, snapshots)
@@ -1205,6 +1426,11 @@ func (b *backend) CreateInstanceFromCopy(inst instance.Instance, src instance.In
 		}
 	}
 
+	err = fault.Inject(fault.PointStorageInstanceCreate, fault.StageEnd, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
@@ -1481,11 +1707,14 @@ func (b *backend) RefreshCustomVolume(projectName string, srcProjectName string,
 // Snapshots that are not present in the source but are in the destination are removed from the
 // destination if snapshots are included in the synchronisation. An empty srcSnapshots argument
 // indicates a volume-only refresh.
-func (b *backend) RefreshInstance(inst instance.Instance, src instance.Instance, srcSnapshots []instance.Instance, allowInconsistent bool, op *operations.Operation) error {
+func (b *backend) RefreshInstance(inst instance.Instance, src instance.Instance, srcSnapshots []instance.Instance, allowInconsistent bool, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "src": src.Name(), "srcSnapshots": len(srcSnapshots)})
 	l.Debug("RefreshInstance started")
 	defer l.Debug("RefreshInstance finished")
 
+	span := b.traceStart(op, "RefreshInstance", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
 	// This indicates whether or not it's a volume-only refresh.
 	snapshots := len(srcSnapshots) > 0
 
@@ -1714,12 +1943,20 @@ func (b *backend) isoFiller(data io.Reader) func(vol drivers.Volume, rootBlockPa
 
 // CreateInstanceFromImage creates a new volume for an instance populated with the image requested.
 // On failure caller is expected to call DeleteInstance() to clean up.
-func (b *backend) CreateInstanceFromImage(inst instance.Instance, fingerprint string, op *operations.Operation) error {
+func (b *backend) CreateInstanceFromImage(inst instance.Instance, fingerprint string, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 	l.Debug("CreateInstanceFromImage started")
 	defer l.Debug("CreateInstanceFromImage finished")
 
-	err := b.isStatusReady()
+	span := b.traceStart(op, "CreateInstanceFromImage", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
+	err = b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	err = fault.Inject(fault.PointStorageInstanceCreate, fault.StageStart, inst.Project().Name, inst.Name())
 	if err != nil {
 		return err
 	}
@@ -1740,6 +1977,15 @@ func (b *backend) CreateInstanceFromImage(inst instance.Instance, fingerprint st
 		return err
 	}
 
+	// Apply the volume defaults of the project now so that they're accounted for when deciding on the
+	// optimized image, rather than only when the volume is recorded.
+	for k, v := range project.StorageVolumeDefaults(inst.Project().Config, b.name) {
+		_, found := volumeConfig[k]
+		if !found {
+			volumeConfig[k] = v
+		}
+	}
+
 	// Determine whether an optimized image should be used.
 	useOptimizedImage, err := b.shouldUseOptimizedImage(fingerprint, contentType, volumeConfig, op)
 	if err != nil {
@@ -1757,7 +2003,7 @@ func (b *backend) CreateInstanceFromImage(inst instance.Instance, fingerprint st
 	// Record new volume with authorizer.
 	err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, inst.Project().Name, b.Name(), volType.Singular(), inst.Name(), "")
 	if err != nil {
-		logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
+		b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
 	}
 
 	revert.Add(func() {
@@ -1851,18 +2097,31 @@ func (b *backend) CreateInstanceFromImage(inst instance.Instance, fingerprint st
 		return err
 	}
 
+	err = fault.Inject(fault.PointStorageInstanceCreate, fault.StageEnd, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
 
 // CreateInstanceFromMigration receives an instance being migrated.
 // The args.Name and args.Config fields are ignored and, instance properties are used instead.
-func (b *backend) CreateInstanceFromMigration(inst instance.Instance, conn io.ReadWriteCloser, args localMigration.VolumeTargetArgs, op *operations.Operation) error {
+func (b *backend) CreateInstanceFromMigration(inst instance.Instance, conn io.ReadWriteCloser, args localMigration.VolumeTargetArgs, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "args": fmt.Sprintf("%+v", args)})
 	l.Debug("CreateInstanceFromMigration started")
 	defer l.Debug("CreateInstanceFromMigration finished")
 
-	err := b.isStatusReady()
+	span := b.traceStart(op, "CreateInstanceFromMigration", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
+	err = b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	err = fault.Inject(fault.PointMigrationReceive, fault.StageStart, inst.Project().Name, inst.Name())
 	if err != nil {
 		return err
 	}
@@ -1871,6 +2130,8 @@ func (b *backend) CreateInstanceFromMigration(inst instance.Instance, conn io.Re
 		return fmt.Errorf("Migration VolumeTargetArgs.Config cannot be set for instances")
 	}
 
+	recordTransferMethod(op, api.InstanceTransferMethodMigration)
+
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
 		return err
@@ -1967,7 +2228,7 @@ func (b *backend) CreateInstanceFromMigration(inst instance.Instance, conn io.Re
 			// Record new volume with authorizer.
 			err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, inst.Project().Name, b.Name(), volType.Singular(), inst.Name(), "")
 			if err != nil {
-				logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
+				b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
 			}
 
 			revert.Add(func() {
@@ -2108,6 +2369,11 @@ func (b *backend) CreateInstanceFromMigration(inst instance.Instance, conn io.Re
 		}
 	}
 
+	err = fault.Inject(fault.PointMigrationReceive, fault.StageEnd, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
@@ -2252,7 +2518,7 @@ func (b *backend) RenameInstance(inst instance.Instance, newName string, op *ope
 	// Record volume rename with authorizer.
 	err = b.state.Authorizer.RenameStoragePoolVolume(b.state.ShutdownCtx, inst.Project().Name, b.Name(), vol.Type().Singular(), inst.Name(), newName, "")
 	if err != nil {
-		logger.Error("Failed to rename storage volume in authorizer", logger.Ctx{"name": inst.Name(), "newName": newName, "type": vol.Type(), "pool": b.Name(), "project": inst.Project().Name, "error": err})
+		b.logger.Error("Failed to rename storage volume in authorizer", logger.Ctx{"name": inst.Name(), "newName": newName, "type": vol.Type(), "pool": b.Name(), "project": inst.Project().Name, "error": err})
 	}
 
 	revert.Success()
@@ -2260,11 +2526,19 @@ func (b *backend) RenameInstance(inst instance.Instance, newName string, op *ope
 }
 
 // DeleteInstance removes the instance's root volume (all snapshots need to be removed first).
-func (b *backend) DeleteInstance(inst instance.Instance, op *operations.Operation) error {
+func (b *backend) DeleteInstance(inst instance.Instance, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 	l.Debug("DeleteInstance started")
 	defer l.Debug("DeleteInstance finished")
 
+	span := b.traceStart(op, "DeleteInstance", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
+	err = fault.Inject(fault.PointStorageInstanceDelete, fault.StageStart, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
 	if inst.IsSnapshot() {
 		return fmt.Errorf("Instance must not be a snapshot")
 	}
@@ -2329,7 +2603,12 @@ func (b *backend) DeleteInstance(inst instance.Instance, op *operations.Operatio
 	// Record volume deletion with authorizer.
 	err = b.state.Authorizer.DeleteStoragePoolVolume(b.state.ShutdownCtx, inst.Project().Name, b.Name(), vol.Type().Singular(), inst.Name(), "")
 	if err != nil {
-		logger.Error("Failed to remove storage volume from authorizer", logger.Ctx{"name": inst.Name(), "type": vol.Type(), "pool": b.Name(), "project": inst.Project().Name, "error": err})
+		b.logger.Error("Failed to remove storage volume from authorizer", logger.Ctx{"name": inst.Name(), "type": vol.Type(), "pool": b.Name(), "project": inst.Project().Name, "error": err})
+	}
+
+	err = fault.Inject(fault.PointStorageInstanceDelete, fault.StageEnd, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -2449,11 +2728,19 @@ func (b *backend) UpdateInstanceSnapshot(inst instance.Instance, newDesc string,
 
 // MigrateInstance sends an instance volume for migration.
 // The args.Name field is ignored and the name of the instance is used instead.
-func (b *backend) MigrateInstance(inst instance.Instance, conn io.ReadWriteCloser, args *localMigration.VolumeSourceArgs, op *operations.Operation) error {
+func (b *backend) MigrateInstance(inst instance.Instance, conn io.ReadWriteCloser, args *localMigration.VolumeSourceArgs, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "args": fmt.Sprintf("%+v", args)})
 	l.Debug("MigrateInstance started")
 	defer l.Debug("MigrateInstance finished")
 
+	span := b.traceStart(op, "MigrateInstance", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
+	err = fault.Inject(fault.PointMigrationSend, fault.StageStart, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
 		return err
@@ -2533,6 +2820,11 @@ func (b *backend) MigrateInstance(inst instance.Instance, conn io.ReadWriteClose
 		return err
 	}
 
+	err = fault.Inject(fault.PointMigrationSend, fault.StageEnd, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -2611,11 +2903,14 @@ func (b *backend) CleanupInstancePaths(inst instance.Instance, op *operations.Op
 }
 
 // BackupInstance creates an instance backup.
-func (b *backend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
+func (b *backend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "optimized": optimized, "snapshots": snapshots})
 	l.Debug("BackupInstance started")
 	defer l.Debug("BackupInstance finished")
 
+	span := b.traceStart(op, "BackupInstance", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
 		return err
@@ -2718,71 +3013,45 @@ func (b *backend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, error)
 	return &val, nil
 }
 
-// SetInstanceQuota sets the quota on the instance's root volume.
-// Returns ErrInUse if the instance is running and the storage driver doesn't support online resizing.
-func (b *backend) SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error {
-	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "size": size, "vm_state_size": vmStateSize})
-	l.Debug("SetInstanceQuota started")
-	defer l.Debug("SetInstanceQuota finished")
+// GetInstanceMirrorState returns the mirroring state of the instance's root volume, or nil if it isn't mirrored or
+// its state can't be queried.
+func (b *backend) GetInstanceMirrorState(inst instance.Instance) (*api.StorageVolumeStateMirror, error) {
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
 
-	// Check we can convert the instance to the volume type needed.
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	contentVolume := InstanceContentType(inst)
-	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+	contentType := InstanceContentType(inst)
 
-	// Load storage volume from database.
-	dbVol, err := VolumeDBGet(b, inst.Project().Name, inst.Name(), volType)
-	if err != nil {
-		return err
-	}
+	// There's no need to pass config as it's not needed when retrieving the mirroring state.
+	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+	vol := b.GetVolume(volType, contentType, volStorageName, nil)
 
-	// Apply the main volume quota.
-	// There's no need to pass config as it's not needed when setting quotas.
-	vol := b.GetVolume(volType, contentVolume, volStorageName, dbVol.Config)
-	err = b.driver.SetVolumeQuota(vol, size, false, op)
+	// The mirroring state is informational, so failing to query it doesn't fail the whole volume state.
+	mirror, err := b.driver.GetVolumeMirrorState(vol)
 	if err != nil {
-		return err
-	}
-
-	// Apply the filesystem volume quota (only when main volume is block).
-	if vol.IsVMBlock() {
-		// Apply default VM config filesystem size if main volume size is specified and no custom
-		// vmStateSize is specified. This way if the main volume size is empty (i.e removing quota) then
-		// this will also pass empty quota for the config filesystem volume as well, allowing a former
-		// quota to be removed from both volumes.
-		if vmStateSize == "" && size != "" {
-			vmStateSize = b.driver.Info().DefaultVMBlockFilesystemSize
+		if !errors.Is(err, drivers.ErrNotSupported) {
+			b.logger.Warn("Failed getting volume mirroring state", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
 		}
 
-		fsVol := vol.NewVMBlockFilesystemVolume()
-		err := b.driver.SetVolumeQuota(fsVol, vmStateSize, false, op)
-		if err != nil {
-			return err
-		}
+		return nil, nil
 	}
 
-	return nil
+	return mirror, nil
 }
 
-// MountInstance mounts the instance's root volume.
-func (b *backend) MountInstance(inst instance.Instance, op *operations.Operation) (*MountInfo, error) {
-	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
-	l.Debug("MountInstance started")
-	defer l.Debug("MountInstance finished")
-
+// GetInstanceIOStats returns the IO counters of the instance's root volume, or nil if the driver doesn't report them.
+func (b *backend) GetInstanceIOStats(inst instance.Instance) (*api.StorageVolumeStateIO, error) {
 	err := b.isStatusReady()
 	if err != nil {
 		return nil, err
 	}
 
-	revert := revert.New()
-	defer revert.Fail()
-
-	// Check we can convert the instance to the volume type needed.
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
 		return nil, err
@@ -2790,11 +3059,161 @@ func (b *backend) MountInstance(inst instance.Instance, op *operations.Operation
 
 	contentType := InstanceContentType(inst)
 
-	// Get the volume.
-	var vol drivers.Volume
+	// There's no need to pass config as it's not needed when retrieving the IO counters.
 	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+	vol := b.GetVolume(volType, contentType, volStorageName, nil)
 
-	if inst.ID() > -1 {
+	stats, err := b.driver.GetVolumeIOStats(vol)
+	if err != nil && !errors.Is(err, drivers.ErrNotSupported) {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// UpdateInstanceMirror promotes or demotes the mirrored root volume of the instance.
+func (b *backend) UpdateInstanceMirror(inst instance.Instance, req api.StorageVolumeMirrorPost, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "action": req.Action, "force": req.Force})
+	l.Debug("UpdateInstanceMirror started")
+	defer l.Debug("UpdateInstanceMirror finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	if req.Action == "demote" && inst.IsRunning() {
+		return api.StatusErrorf(http.StatusBadRequest, "Cannot demote the volume of a running instance")
+	}
+
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	contentType := InstanceContentType(inst)
+
+	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+	vol := b.GetVolume(volType, contentType, volStorageName, nil)
+
+	return b.updateVolumeMirror(vol, inst.Project().Name, req, op)
+}
+
+// updateVolumeMirror promotes or demotes a mirrored volume and sends the matching lifecycle event.
+func (b *backend) updateVolumeMirror(vol drivers.Volume, projectName string, req api.StorageVolumeMirrorPost, op *operations.Operation) error {
+	var err error
+	var action lifecycle.StorageVolumeAction
+	var ctx logger.Ctx
+
+	switch req.Action {
+	case "promote":
+		err = b.driver.PromoteVolumeMirror(vol, req.Force)
+		action = lifecycle.StorageVolumePromoted
+		ctx = logger.Ctx{"force": req.Force}
+	case "demote":
+		if req.Force {
+			return api.StatusErrorf(http.StatusBadRequest, "Demotions can't be forced")
+		}
+
+		err = b.driver.DemoteVolumeMirror(vol)
+		action = lifecycle.StorageVolumeDemoted
+	default:
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid mirror action %q", req.Action)
+	}
+
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return api.StatusErrorf(http.StatusBadRequest, "Storage pool driver %q doesn't support mirroring", b.driver.Info().Name)
+		}
+
+		return err
+	}
+
+	b.state.Events.SendLifecycle(projectName, action.Event(vol, string(vol.Type()), projectName, op, ctx))
+
+	return nil
+}
+
+// SetInstanceQuota sets the quota on the instance's root volume.
+// Returns ErrInUse if the instance is running and the storage driver doesn't support online resizing.
+func (b *backend) SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "size": size, "vm_state_size": vmStateSize})
+	l.Debug("SetInstanceQuota started")
+	defer l.Debug("SetInstanceQuota finished")
+
+	// Check we can convert the instance to the volume type needed.
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	contentVolume := InstanceContentType(inst)
+	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+
+	// Load storage volume from database.
+	dbVol, err := VolumeDBGet(b, inst.Project().Name, inst.Name(), volType)
+	if err != nil {
+		return err
+	}
+
+	// Apply the main volume quota.
+	// There's no need to pass config as it's not needed when setting quotas.
+	vol := b.GetVolume(volType, contentVolume, volStorageName, dbVol.Config)
+	err = b.driver.SetVolumeQuota(vol, size, false, op)
+	if err != nil {
+		return err
+	}
+
+	// Apply the filesystem volume quota (only when main volume is block).
+	if vol.IsVMBlock() {
+		// Apply default VM config filesystem size if main volume size is specified and no custom
+		// vmStateSize is specified. This way if the main volume size is empty (i.e removing quota) then
+		// this will also pass empty quota for the config filesystem volume as well, allowing a former
+		// quota to be removed from both volumes.
+		if vmStateSize == "" && size != "" {
+			vmStateSize = b.driver.Info().DefaultVMBlockFilesystemSize
+		}
+
+		fsVol := vol.NewVMBlockFilesystemVolume()
+		err := b.driver.SetVolumeQuota(fsVol, vmStateSize, false, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MountInstance mounts the instance's root volume.
+func (b *backend) MountInstance(inst instance.Instance, op *operations.Operation) (_ *MountInfo, err error) {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+	l.Debug("MountInstance started")
+	defer l.Debug("MountInstance finished")
+
+	span := b.traceStart(op, "MountInstance", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
+	err = b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Check we can convert the instance to the volume type needed.
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := InstanceContentType(inst)
+
+	// Get the volume.
+	var vol drivers.Volume
+	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+
+	if inst.ID() > -1 {
 		// Load storage volume from database.
 		dbVol, err := VolumeDBGet(b, inst.Project().Name, inst.Name(), volType)
 		if err != nil {
@@ -2848,11 +3267,14 @@ func (b *backend) MountInstance(inst instance.Instance, op *operations.Operation
 }
 
 // UnmountInstance unmounts the instance's root volume.
-func (b *backend) UnmountInstance(inst instance.Instance, op *operations.Operation) error {
+func (b *backend) UnmountInstance(inst instance.Instance, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 	l.Debug("UnmountInstance started")
 	defer l.Debug("UnmountInstance finished")
 
+	span := b.traceStart(op, "UnmountInstance", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
 	// Check we can convert the instance to the volume type needed.
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
@@ -2917,11 +3339,14 @@ func (b *backend) getInstanceDisk(inst instance.Instance) (string, error) {
 }
 
 // CreateInstanceSnapshot creates a snaphot of an instance volume.
-func (b *backend) CreateInstanceSnapshot(inst instance.Instance, src instance.Instance, op *operations.Operation) error {
+func (b *backend) CreateInstanceSnapshot(inst instance.Instance, src instance.Instance, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "src": src.Name()})
 	l.Debug("CreateInstanceSnapshot started")
 	defer l.Debug("CreateInstanceSnapshot finished")
 
+	span := b.traceStart(op, "CreateInstanceSnapshot", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
 	if inst.Type() != src.Type() {
 		return fmt.Errorf("Instance types must match")
 	}
@@ -3137,11 +3562,14 @@ func (b *backend) DeleteInstanceSnapshot(inst instance.Instance, op *operations.
 }
 
 // RestoreInstanceSnapshot restores an instance snapshot.
-func (b *backend) RestoreInstanceSnapshot(inst instance.Instance, src instance.Instance, op *operations.Operation) error {
+func (b *backend) RestoreInstanceSnapshot(inst instance.Instance, src instance.Instance, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "src": src.Name()})
 	l.Debug("RestoreInstanceSnapshot started")
 	defer l.Debug("RestoreInstanceSnapshot finished")
 
+	span := b.traceStart(op, "RestoreInstanceSnapshot", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -3347,12 +3775,15 @@ func (b *backend) UnmountInstanceSnapshot(inst instance.Instance, op *operations
 // doesn't already exist. If the volume already exists then it is checked to ensure it matches the pools current
 // volume settings ("volume.size" and "block.filesystem" if applicable). If not the optimized volume is removed
 // and regenerated to apply the pool's current volume settings.
-func (b *backend) EnsureImage(fingerprint string, op *operations.Operation) error {
+func (b *backend) EnsureImage(fingerprint string, op *operations.Operation) (err error) {
 	l := b.logger.AddContext(logger.Ctx{"fingerprint": fingerprint})
 	l.Debug("EnsureImage started")
 	defer l.Debug("EnsureImage finished")
 
-	err := b.isStatusReady()
+	span := b.traceStart(op, "EnsureImage", attribute.String("incus.image", fingerprint))
+	defer func() { tracing.End(span, err) }()
+
+	err = b.isStatusReady()
 	if err != nil {
 		return err
 	}
@@ -3522,7 +3953,7 @@ func (b *backend) EnsureImage(fingerprint string, op *operations.Operation) erro
 	// Record new volume with authorizer.
 	err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, api.ProjectDefaultName, b.Name(), drivers.VolumeTypeImage.Singular(), fingerprint, location)
 	if err != nil {
-		logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": fingerprint, "type": drivers.VolumeTypeImage, "pool": b.Name(), "project": api.ProjectDefaultName, "error": err})
+		b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": fingerprint, "type": drivers.VolumeTypeImage, "pool": b.Name(), "project": api.ProjectDefaultName, "error": err})
 	}
 
 	revert.Add(func() {
@@ -3676,7 +4107,7 @@ func (b *backend) DeleteImage(fingerprint string, op *operations.Operation) erro
 
 	err = b.state.Authorizer.DeleteStoragePoolVolume(b.state.ShutdownCtx, api.ProjectDefaultName, b.Name(), vol.Type().Singular(), fingerprint, location)
 	if err != nil {
-		logger.Error("Failed to remove storage volume from authorizer", logger.Ctx{"name": fingerprint, "type": vol.Type(), "pool": b.Name(), "project": api.ProjectDefaultName, "error": err})
+		b.logger.Error("Failed to remove storage volume from authorizer", logger.Ctx{"name": fingerprint, "type": vol.Type(), "pool": b.Name(), "project": api.ProjectDefaultName, "error": err})
 	}
 
 	b.state.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.StorageVolumeDeleted.Event(vol, string(vol.Type()), api.ProjectDefaultName, op, nil))
@@ -3820,6 +4251,14 @@ func (b *backend) CreateBucket(projectName string, bucket api.StorageBucketsPost
 		}
 
 		revert.Add(func() { _ = s3Client.RemoveBucket(ctx, bucket.Name) })
+
+		// Apply the initial quota and lifecycle rules if specified.
+		if bucket.Config["size"] != "" || bucket.Config["lifecycle.expiration"] != "" {
+			err = b.applyLocalBucketConfig(ctx, minioProc, bucket.Name, bucket.Config)
+			if err != nil {
+				return err
+			}
+		}
 	} else {
 		// Handle per-driver implementation for remote storage drivers.
 		err = b.driver.CreateBucket(bucketVol, op)
@@ -3915,6 +4354,28 @@ func (b *backend) UpdateBucket(projectName string, bucketName string, bucket api
 			if err != nil {
 				return err
 			}
+
+			// Apply the quota and lifecycle rules to the MinIO bucket if they have changed.
+			_, sizeChanged := changedConfig["size"]
+			lifecycleChanged := slices.ContainsFunc(drivers.BucketLifecycleKeys, func(key string) bool {
+				_, changed := changedConfig[key]
+				return changed
+			})
+
+			if sizeChanged || lifecycleChanged {
+				minioProc, err := b.ActivateBucket(projectName, curBucket.Name, op)
+				if err != nil {
+					return err
+				}
+
+				ctx, ctxCancel := context.WithTimeout(b.state.ShutdownCtx, time.Duration(time.Second*30))
+				defer ctxCancel()
+
+				err = b.applyLocalBucketConfig(ctx, minioProc, curBucket.Name, bucket.Config)
+				if err != nil {
+					return err
+				}
+			}
 		} else {
 			// Handle per-driver implementation for remote storage drivers.
 			err = b.driver.UpdateBucket(curBucketVol, changedConfig)
@@ -4521,6 +4982,108 @@ func (b *backend) ActivateBucket(projectName string, bucketName string, op *oper
 	return miniod.EnsureRunning(b.state, bucketVol)
 }
 
+// applyLocalBucketConfig applies the quota and lifecycle rules of the bucket config to a local MinIO bucket.
+func (b *backend) applyLocalBucketConfig(ctx context.Context, minioProc *miniod.Process, bucketName string, config map[string]string) error {
+	var sizeBytes int64
+	if config["size"] != "" {
+		var err error
+		sizeBytes, err = units.ParseByteSizeString(config["size"])
+		if err != nil {
+			return fmt.Errorf("Failed parsing bucket quota size: %w", err)
+		}
+	}
+
+	adminClient, err := minioProc.AdminClient()
+	if err != nil {
+		return err
+	}
+
+	err = adminClient.SetBucketQuota(ctx, bucketName, sizeBytes)
+	if err != nil {
+		return fmt.Errorf("Failed setting bucket quota: %w", err)
+	}
+
+	s3Client, err := minioProc.S3Client()
+	if err != nil {
+		return err
+	}
+
+	err = drivers.SetBucketLifecycle(ctx, s3Client, bucketName, config)
+	if err != nil {
+		return fmt.Errorf("Failed setting bucket lifecycle: %w", err)
+	}
+
+	return nil
+}
+
+// GetBucketState returns the space used by a bucket along with its quota.
+func (b *backend) GetBucketState(projectName string, bucketName string) (*api.StorageBucketState, error) {
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	if !b.Driver().Info().Buckets {
+		return nil, fmt.Errorf("Storage pool does not support buckets")
+	}
+
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
+	var bucket *db.StorageBucket
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, memberSpecific, bucketName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	state := &api.StorageBucketState{}
+	if bucket.Config["size"] != "" {
+		state.Total, err = units.ParseByteSizeString(bucket.Config["size"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if memberSpecific {
+		// Handle common MinIO implementation for local storage drivers.
+		minioProc, err := b.ActivateBucket(projectName, bucket.Name, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		adminClient, err := minioProc.AdminClient()
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, ctxCancel := context.WithTimeout(b.state.ShutdownCtx, time.Duration(time.Second*30))
+		defer ctxCancel()
+
+		usage, err := adminClient.BucketUsage(ctx, bucket.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting bucket usage: %w", err)
+		}
+
+		state.Used = usage.Size
+		state.Objects = usage.Objects
+	} else {
+		// Handle per-driver implementation for remote storage drivers.
+		bucketVol := b.GetVolume(drivers.VolumeTypeBucket, drivers.ContentTypeFS, project.StorageVolume(projectName, bucket.Name), bucket.Config)
+
+		usage, err := b.driver.GetBucketUsage(bucketVol)
+		if err != nil {
+			return nil, err
+		}
+
+		state.Used = usage.Size
+		state.Objects = usage.Objects
+	}
+
+	return state, nil
+}
+
 // GetBucketURL returns S3 URL for bucket.
 func (b *backend) GetBucketURL(bucketName string) *url.URL {
 	err := b.isStatusReady()
@@ -4561,6 +5124,11 @@ func (b *backend) CreateCustomVolume(projectName string, volName string, desc st
 		return err
 	}
 
+	err = fault.Inject(fault.PointStorageVolumeCreate, fault.StageStart, projectName, volName)
+	if err != nil {
+		return err
+	}
+
 	// Get the volume name on storage.
 	volStorageName := project.StorageVolume(projectName, volName)
 
@@ -4600,6 +5168,11 @@ func (b *backend) CreateCustomVolume(projectName string, volName string, desc st
 		return err
 	}
 
+	err = fault.Inject(fault.PointStorageVolumeCreate, fault.StageEnd, projectName, volName)
+	if err != nil {
+		return err
+	}
+
 	eventCtx := logger.Ctx{"type": vol.Type()}
 
 	var location string
@@ -4611,7 +5184,7 @@ func (b *backend) CreateCustomVolume(projectName string, volName string, desc st
 	// Record new volume with authorizer.
 	err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, projectName, b.Name(), vol.Type().Singular(), volName, location)
 	if err != nil {
-		logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": volName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
+		b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": volName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
 	}
 
 	b.state.Events.SendLifecycle(projectName, lifecycle.StorageVolumeCreated.Event(vol, string(vol.Type()), projectName, op, eventCtx))
@@ -4753,7 +5326,7 @@ func (b *backend) CreateCustomVolumeFromCopy(projectName string, srcProjectName
 		// Record new volume with authorizer.
 		err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, projectName, b.Name(), vol.Type().Singular(), volName, location)
 		if err != nil {
-			logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": volName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
+			b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": volName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
 		}
 
 		b.state.Events.SendLifecycle(projectName, lifecycle.StorageVolumeCreated.Event(vol, string(vol.Type()), projectName, op, eventCtx))
@@ -4966,6 +5539,11 @@ func (b *backend) MigrateCustomVolume(projectName string, conn io.ReadWriteClose
 	l.Debug("MigrateCustomVolume started")
 	defer l.Debug("MigrateCustomVolume finished")
 
+	err := fault.Inject(fault.PointMigrationSend, fault.StageStart, projectName, args.Name)
+	if err != nil {
+		return err
+	}
+
 	// Get the volume name on storage.
 	volStorageName := project.StorageVolume(projectName, args.Name)
 
@@ -5007,6 +5585,11 @@ func (b *backend) MigrateCustomVolume(projectName string, conn io.ReadWriteClose
 		return err
 	}
 
+	err = fault.Inject(fault.PointMigrationSend, fault.StageEnd, projectName, args.Name)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -5021,6 +5604,11 @@ func (b *backend) CreateCustomVolumeFromMigration(projectName string, conn io.Re
 		return err
 	}
 
+	err = fault.Inject(fault.PointMigrationReceive, fault.StageStart, projectName, args.Name)
+	if err != nil {
+		return err
+	}
+
 	storagePoolSupported := false
 	for _, supportedType := range b.Driver().Info().VolumeTypes {
 		if supportedType == drivers.VolumeTypeCustom {
@@ -5149,6 +5737,11 @@ func (b *backend) CreateCustomVolumeFromMigration(projectName string, conn io.Re
 		return err
 	}
 
+	err = fault.Inject(fault.PointMigrationReceive, fault.StageEnd, projectName, args.Name)
+	if err != nil {
+		return err
+	}
+
 	eventCtx := logger.Ctx{"type": vol.Type()}
 
 	var location string
@@ -5160,7 +5753,7 @@ func (b *backend) CreateCustomVolumeFromMigration(projectName string, conn io.Re
 	// Record new volume with authorizer.
 	err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, projectName, b.Name(), vol.Type().Singular(), args.Name, location)
 	if err != nil {
-		logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": args.Name, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
+		b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": args.Name, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
 	}
 
 	b.state.Events.SendLifecycle(projectName, lifecycle.StorageVolumeCreated.Event(vol, string(vol.Type()), projectName, op, eventCtx))
@@ -5273,7 +5866,7 @@ func (b *backend) RenameCustomVolume(projectName string, volName string, newVolN
 
 	err = b.state.Authorizer.RenameStoragePoolVolume(b.state.ShutdownCtx, projectName, b.Name(), vol.Type().Singular(), volName, newVolStorageName, location)
 	if err != nil {
-		logger.Error("Failed to rename storage volume in authorizer", logger.Ctx{"old_name": volName, "new_name": newVolStorageName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
+		b.logger.Error("Failed to rename storage volume in authorizer", logger.Ctx{"old_name": volName, "new_name": newVolStorageName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
 	}
 
 	vol = b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), newVolStorageName, nil)
@@ -5503,6 +6096,11 @@ func (b *backend) DeleteCustomVolume(projectName string, volName string, op *ope
 	l.Debug("DeleteCustomVolume started")
 	defer l.Debug("DeleteCustomVolume finished")
 
+	err := fault.Inject(fault.PointStorageVolumeDelete, fault.StageStart, projectName, volName)
+	if err != nil {
+		return err
+	}
+
 	_, _, isSnap := api.GetParentAndSnapshotName(volName)
 	if isSnap {
 		return fmt.Errorf("Volume name cannot be a snapshot")
@@ -5573,6 +6171,11 @@ func (b *backend) DeleteCustomVolume(projectName string, volName string, op *ope
 		return err
 	}
 
+	err = fault.Inject(fault.PointStorageVolumeDelete, fault.StageEnd, projectName, volName)
+	if err != nil {
+		return err
+	}
+
 	var location string
 	if b.state.ServerClustered && !b.Driver().Info().Remote {
 		location = b.state.ServerName
@@ -5581,7 +6184,7 @@ func (b *backend) DeleteCustomVolume(projectName string, volName string, op *ope
 	// Record volume deletion with authorizer.
 	err = b.state.Authorizer.DeleteStoragePoolVolume(b.state.ShutdownCtx, projectName, b.Name(), vol.Type().Singular(), volName, location)
 	if err != nil {
-		logger.Error("Failed to remove storage volume from authorizer", logger.Ctx{"name": volName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
+		b.logger.Error("Failed to remove storage volume from authorizer", logger.Ctx{"name": volName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
 	}
 
 	b.state.Events.SendLifecycle(projectName, lifecycle.StorageVolumeDeleted.Event(vol, string(vol.Type()), projectName, op, nil))
@@ -5649,12 +6252,9 @@ func (b *backend) GetCustomVolumeUsage(projectName, volName string) (*VolumeUsag
 	return &val, nil
 }
 
-// MountCustomVolume mounts a custom volume.
-func (b *backend) MountCustomVolume(projectName, volName string, op *operations.Operation) (*MountInfo, error) {
-	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName})
-	l.Debug("MountCustomVolume started")
-	defer l.Debug("MountCustomVolume finished")
-
+// GetCustomVolumeMirrorState returns the mirroring state of the custom volume, or nil if it isn't mirrored or its
+// state can't be queried.
+func (b *backend) GetCustomVolumeMirrorState(projectName, volName string) (*api.StorageVolumeStateMirror, error) {
 	err := b.isStatusReady()
 	if err != nil {
 		return nil, err
@@ -5667,12 +6267,156 @@ func (b *backend) MountCustomVolume(projectName, volName string, op *operations.
 
 	// Get the volume name on storage.
 	volStorageName := project.StorageVolume(projectName, volName)
-	vol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, volume.Config)
 
-	// Perform the mount.
-	mountInfo := &MountInfo{}
-	err = b.driver.MountVolume(vol, op)
-	if err != nil {
+	// There's no need to pass config as it's not needed when getting the mirroring state.
+	vol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, nil)
+
+	// The mirroring state is informational, so failing to query it doesn't fail the whole volume state.
+	mirror, err := b.driver.GetVolumeMirrorState(vol)
+	if err != nil {
+		if !errors.Is(err, drivers.ErrNotSupported) {
+			b.logger.Warn("Failed getting volume mirroring state", logger.Ctx{"project": projectName, "volName": volName, "err": err})
+		}
+
+		return nil, nil
+	}
+
+	return mirror, nil
+}
+
+// GetCustomVolumeIOStats returns the IO counters of the custom volume, or nil if the driver doesn't report them.
+func (b *backend) GetCustomVolumeIOStats(projectName, volName string) (*api.StorageVolumeStateIO, error) {
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	volume, err := VolumeDBGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, volName)
+
+	// There's no need to pass config as it's not needed when getting the IO counters.
+	vol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, nil)
+
+	stats, err := b.driver.GetVolumeIOStats(vol)
+	if err != nil && !errors.Is(err, drivers.ErrNotSupported) {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// UpdateCustomVolumeMirror promotes or demotes a mirrored custom volume.
+func (b *backend) UpdateCustomVolumeMirror(projectName string, volName string, req api.StorageVolumeMirrorPost, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName, "action": req.Action, "force": req.Force})
+	l.Debug("UpdateCustomVolumeMirror started")
+	defer l.Debug("UpdateCustomVolumeMirror finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	volume, err := VolumeDBGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	// Demoting a volume makes it read-only, so it mustn't be in use by running instances.
+	if req.Action == "demote" {
+		err = VolumeUsedByInstanceDevices(b.state, b.Name(), projectName, &volume.StorageVolume, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+			inst, err := instance.Load(b.state, dbInst, project)
+			if err != nil {
+				return err
+			}
+
+			if inst.IsRunning() {
+				return api.StatusErrorf(http.StatusBadRequest, "Cannot demote custom volume used by running instances")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, volName)
+	vol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, volume.Config)
+
+	return b.updateVolumeMirror(vol, projectName, req, op)
+}
+
+// TransferCustomVolume sends a custom volume to an external target, using zfs.transfer.target if target is empty.
+func (b *backend) TransferCustomVolume(projectName string, volName string, target string, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName, "target": target})
+	l.Debug("TransferCustomVolume started")
+	defer l.Debug("TransferCustomVolume finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	volume, err := VolumeDBGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	if target == "" {
+		target = volume.Config["zfs.transfer.target"]
+		if target == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "No transfer target specified")
+		}
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, volName)
+	vol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, volume.Config)
+
+	err = b.driver.TransferVolume(vol, target, op)
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return api.StatusErrorf(http.StatusBadRequest, "Storage pool driver %q doesn't support volume transfers", b.driver.Info().Name)
+		}
+
+		return err
+	}
+
+	b.state.Events.SendLifecycle(projectName, lifecycle.StorageVolumeTransferred.Event(vol, string(vol.Type()), projectName, op, logger.Ctx{"target": target}))
+
+	return nil
+}
+
+// MountCustomVolume mounts a custom volume.
+func (b *backend) MountCustomVolume(projectName, volName string, op *operations.Operation) (*MountInfo, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName})
+	l.Debug("MountCustomVolume started")
+	defer l.Debug("MountCustomVolume finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	volume, err := VolumeDBGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, volName)
+	vol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, volume.Config)
+
+	// Perform the mount.
+	mountInfo := &MountInfo{}
+	err = b.driver.MountVolume(vol, op)
+	if err != nil {
 		return nil, err
 	}
 
@@ -6403,6 +7147,149 @@ func (b *backend) CheckInstanceBackupFileSnapshots(backupConf *backupConfig.Conf
 	return existingSnapshots, nil
 }
 
+// backupConfigVolumeType returns the volume and content types of the instance or custom volume described by a
+// backup config.
+func backupConfigVolumeType(poolVol *backupConfig.Config) (drivers.VolumeType, drivers.ContentType, error) {
+	if poolVol.Container != nil {
+		instType, err := instancetype.New(poolVol.Container.Type)
+		if err != nil {
+			return "", "", err
+		}
+
+		volType, err := InstanceTypeToVolumeType(instType)
+		if err != nil {
+			return "", "", err
+		}
+
+		if volType == drivers.VolumeTypeVM {
+			return volType, drivers.ContentTypeBlock, nil
+		}
+
+		return volType, drivers.ContentTypeFS, nil
+	}
+
+	if poolVol.Volume == nil {
+		return "", "", fmt.Errorf("Invalid pool volume config supplied")
+	}
+
+	return drivers.VolumeTypeCustom, drivers.ContentType(poolVol.Volume.ContentType), nil
+}
+
+// backupConfigVolume returns the storage volume of the instance or custom volume described by a backup config.
+func (b *backend) backupConfigVolume(projectName string, poolVol *backupConfig.Config) (drivers.Volume, error) {
+	volType, contentType, err := backupConfigVolumeType(poolVol)
+	if err != nil {
+		return drivers.Volume{}, err
+	}
+
+	var volConfig map[string]string
+	if poolVol.Volume != nil {
+		volConfig = poolVol.Volume.Config
+	}
+
+	volStorageName := project.Instance(projectName, poolVol.Container.Name)
+	if volType == drivers.VolumeTypeCustom {
+		volStorageName = project.StorageVolume(projectName, poolVol.Volume.Name)
+	}
+
+	return b.GetVolume(volType, contentType, volStorageName, volConfig), nil
+}
+
+// RecycleVolume moves the volume of an instance or custom volume which is about to be deleted, along with its
+// snapshots, into the recycle bin under the given name. Only the volume on storage is renamed, its database
+// records are left for the deletion to remove.
+func (b *backend) RecycleVolume(projectName string, poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "recycledName": recycledName})
+	l.Debug("RecycleVolume started")
+	defer l.Debug("RecycleVolume finished")
+
+	vol, err := b.backupConfigVolume(projectName, poolVol)
+	if err != nil {
+		return err
+	}
+
+	// Mount paths can't be renamed while in use.
+	_, err = b.driver.UnmountVolume(vol, false, op)
+	if err != nil {
+		return err
+	}
+
+	return b.driver.RenameVolume(vol, recycledName, op)
+}
+
+// RestoreRecycledVolume moves a volume of the recycle bin, along with its snapshots, back to the instance or
+// custom volume described by the backup config. Its database records are then recreated by importing it.
+func (b *backend) RestoreRecycledVolume(projectName string, poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "recycledName": recycledName})
+	l.Debug("RestoreRecycledVolume started")
+	defer l.Debug("RestoreRecycledVolume finished")
+
+	vol, err := b.backupConfigVolume(projectName, poolVol)
+	if err != nil {
+		return err
+	}
+
+	volExists, err := b.driver.HasVolume(vol)
+	if err != nil {
+		return err
+	}
+
+	if volExists {
+		return api.StatusErrorf(http.StatusConflict, "Storage volume %q already exists on storage pool %q", vol.Name(), b.name)
+	}
+
+	recycledVol := b.GetVolume(vol.Type(), vol.ContentType(), recycledName, vol.Config())
+
+	return b.driver.RenameVolume(recycledVol, vol.Name(), op)
+}
+
+// DeleteRecycledVolume deletes a volume of the recycle bin along with its snapshots.
+func (b *backend) DeleteRecycledVolume(poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"recycledName": recycledName})
+	l.Debug("DeleteRecycledVolume started")
+	defer l.Debug("DeleteRecycledVolume finished")
+
+	volType, contentType, err := backupConfigVolumeType(poolVol)
+	if err != nil {
+		return err
+	}
+
+	var volConfig map[string]string
+	if poolVol.Volume != nil {
+		volConfig = poolVol.Volume.Config
+	}
+
+	recycledVol := b.GetVolume(volType, contentType, recycledName, volConfig)
+
+	volExists, err := b.driver.HasVolume(recycledVol)
+	if err != nil {
+		return err
+	}
+
+	if !volExists {
+		return nil
+	}
+
+	snapshots, err := b.driver.VolumeSnapshots(recycledVol, op)
+	if err != nil {
+		return err
+	}
+
+	for _, snapName := range snapshots {
+		snapVol, err := recycledVol.NewSnapshot(snapName)
+		if err != nil {
+			return err
+		}
+
+		err = b.driver.DeleteVolumeSnapshot(snapVol, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	return b.driver.DeleteVolume(recycledVol, op)
+}
+
 // ListUnknownVolumes returns volumes that exist on the storage pool but don't have records in the database.
 // Returns the unknown volumes parsed/generated backup config in a slice (keyed on project name).
 func (b *backend) ListUnknownVolumes(op *operations.Operation) (map[string][]*backupConfig.Config, error) {
@@ -6420,6 +7307,11 @@ func (b *backend) ListUnknownVolumes(op *operations.Operation) (map[string][]*ba
 	for _, poolVol := range poolVols {
 		volType := poolVol.Type()
 
+		// Skip the volumes held by the recycle bin.
+		if strings.HasPrefix(poolVol.Name(), RecycledVolumePrefix) {
+			continue
+		}
+
 		// If the storage driver has returned a filesystem volume for a VM, this is a break of protocol.
 		if volType == drivers.VolumeTypeVM && poolVol.ContentType() == drivers.ContentTypeFS {
 			return nil, fmt.Errorf("Storage driver returned unexpected VM volume with filesystem content type (%q)", poolVol.Name())
@@ -6760,11 +7652,14 @@ func (b *backend) detectUnknownBuckets(vol *drivers.Volume, projectVols map[stri
 // and symlinks are restored as needed to make it operational with Incus. Used during the recovery import stage.
 // If the instance exists on the local cluster member then the local mount status is restored as needed.
 // If the optional poolVol argument is provided then it is used to create the storage volume database records.
-func (b *backend) ImportInstance(inst instance.Instance, poolVol *backupConfig.Config, op *operations.Operation) (revert.Hook, error) {
+func (b *backend) ImportInstance(inst instance.Instance, poolVol *backupConfig.Config, op *operations.Operation) (_ revert.Hook, err error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 	l.Debug("ImportInstance started")
 	defer l.Debug("ImportInstance finished")
 
+	span := b.traceStart(op, "ImportInstance", tracing.InstanceAttributes(inst.Project().Name, inst.Name())...)
+	defer func() { tracing.End(span, err) }()
+
 	volType, err := InstanceTypeToVolumeType(inst.Type())
 	if err != nil {
 		return nil, err
@@ -7063,7 +7958,129 @@ func (b *backend) CreateCustomVolumeFromISO(projectName string, volName string,
 	// Record new volume with authorizer.
 	err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, projectName, b.Name(), vol.Type().Singular(), volName, location)
 	if err != nil {
-		logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": volName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
+		b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": volName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
+	}
+
+	b.state.Events.SendLifecycle(projectName, lifecycle.StorageVolumeCreated.Event(vol, string(vol.Type()), projectName, op, eventCtx))
+
+	revert.Success()
+	return nil
+}
+
+// CreateCustomVolumeFromDiskImage creates a block custom volume from a disk image file, converting it to raw.
+func (b *backend) CreateCustomVolumeFromDiskImage(projectName string, volName string, imgPath string, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volume": volName, "imgPath": imgPath})
+	l.Debug("CreateCustomVolumeFromDiskImage started")
+	defer l.Debug("CreateCustomVolumeFromDiskImage finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	imgFormat, imgSize, err := diskImageInfo(b.state.OS, imgPath)
+	if err != nil {
+		return err
+	}
+
+	// Check whether we are allowed to create volumes.
+	req := api.StorageVolumesPost{
+		Name: volName,
+		StorageVolumePut: api.StorageVolumePut{
+			Config: map[string]string{
+				"size": fmt.Sprintf("%d", imgSize),
+			},
+		},
+		ContentType: string(drivers.ContentTypeBlock),
+	}
+
+	err = b.state.DB.Cluster.Transaction(b.state.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return project.AllowVolumeCreation(tx, projectName, req)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed checking volume creation allowed: %w", err)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, volName)
+
+	vol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentTypeBlock, volStorageName, req.Config)
+	err = b.driver.ValidateVolume(vol, false)
+	if err != nil {
+		return err
+	}
+
+	volExists, err := b.driver.HasVolume(vol)
+	if err != nil {
+		return err
+	}
+
+	if volExists {
+		return fmt.Errorf("Cannot create volume, already exists on target storage")
+	}
+
+	// Validate config and create database entry for new storage volume.
+	err = VolumeDBCreate(b, projectName, volName, "", vol.Type(), false, vol.Config(), time.Now().UTC(), time.Time{}, vol.ContentType(), true, true)
+	if err != nil {
+		return fmt.Errorf("Failed creating database entry for custom volume: %w", err)
+	}
+
+	revert.Add(func() { _ = VolumeDBDelete(b, projectName, volName, vol.Type()) })
+
+	volFiller := drivers.VolumeFiller{
+		Fill: func(vol drivers.Volume, rootBlockPath string, allowUnsafeResize bool) (int64, error) {
+			l.Debug("Converting disk image to raw disk", logger.Ctx{"format": imgFormat, "dstPath": rootBlockPath})
+
+			cmd := []string{
+				"nice", "-n19", // Run with low priority to reduce CPU impact on other processes.
+				"qemu-img", "convert", "-p", "-f", imgFormat, "-O", "raw",
+			}
+
+			// Don't recreate existing block devices.
+			if linux.IsBlockdevPath(rootBlockPath) {
+				cmd = append(cmd, "-n", "-W")
+			}
+
+			cmd = append(cmd, imgPath, rootBlockPath)
+
+			progress := func(percent int) {
+				if op == nil {
+					return
+				}
+
+				_ = op.UpdateMetadata(map[string]any{"create_volume_progress": fmt.Sprintf("Converting disk image: %d%%", percent)})
+			}
+
+			err := apparmor.QemuImgProgress(b.state.OS, cmd, imgPath, rootBlockPath, progress)
+			if err != nil {
+				return -1, fmt.Errorf("Failed converting disk image to raw at %q: %w", rootBlockPath, err)
+			}
+
+			return imgSize, nil
+		},
+	}
+
+	// Convert the disk image into the new storage volume.
+	err = b.driver.CreateVolume(vol, &volFiller, op)
+	if err != nil {
+		return fmt.Errorf("Failed creating volume: %w", err)
+	}
+
+	eventCtx := logger.Ctx{"type": vol.Type()}
+
+	var location string
+	if b.state.ServerClustered && !b.Driver().Info().Remote {
+		eventCtx["location"] = b.state.ServerName
+		location = b.state.ServerName
+	}
+
+	// Record new volume with authorizer.
+	err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, projectName, b.Name(), vol.Type().Singular(), volName, location)
+	if err != nil {
+		b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": volName, "type": vol.Type(), "pool": b.Name(), "project": projectName, "error": err})
 	}
 
 	b.state.Events.SendLifecycle(projectName, lifecycle.StorageVolumeCreated.Event(vol, string(vol.Type()), projectName, op, eventCtx))
@@ -7182,7 +8199,7 @@ func (b *backend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData io
 	// Record new volume with authorizer.
 	err = b.state.Authorizer.AddStoragePoolVolume(b.state.ShutdownCtx, srcBackup.Project, b.Name(), vol.Type().Singular(), srcBackup.Name, location)
 	if err != nil {
-		logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": srcBackup.Name, "type": vol.Type(), "pool": b.Name(), "project": srcBackup.Project, "error": err})
+		b.logger.Error("Failed to add storage volume to authorizer", logger.Ctx{"name": srcBackup.Name, "type": vol.Type(), "pool": b.Name(), "project": srcBackup.Project, "error": err})
 	}
 
 	b.state.Events.SendLifecycle(srcBackup.Project, lifecycle.StorageVolumeCreated.Event(vol, string(vol.Type()), srcBackup.Project, op, eventCtx))
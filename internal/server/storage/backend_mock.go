@@ -72,10 +72,22 @@ func (b *mockBackend) MigrationTypes(contentType drivers.ContentType, refresh bo
 	}
 }
 
+func (b *mockBackend) CheckHealth() error {
+	return nil
+}
+
 func (b *mockBackend) GetResources() (*api.ResourcesStoragePool, error) {
 	return nil, nil
 }
 
+func (b *mockBackend) GetMirrorState() (*api.StoragePoolMirrorState, error) {
+	return nil, nil
+}
+
+func (b *mockBackend) UpdateMirror(req api.StoragePoolMirrorPost) error {
+	return nil
+}
+
 func (b *mockBackend) IsUsed() (bool, error) {
 	return false, nil
 }
@@ -112,6 +124,10 @@ func (b *mockBackend) CreateInstance(inst instance.Instance, op *operations.Oper
 	return nil
 }
 
+func (b *mockBackend) CreateInstanceFromVolume(inst instance.Instance, srcPool Pool, srcVolName string, op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) CreateInstanceFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (func(instance.Instance) error, revert.Hook, error) {
 	return nil, nil, nil
 }
@@ -156,6 +172,18 @@ func (b *mockBackend) CheckInstanceBackupFileSnapshots(backupConf *backupConfig.
 	return nil, nil
 }
 
+func (b *mockBackend) RecycleVolume(projectName string, poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error {
+	return nil
+}
+
+func (b *mockBackend) RestoreRecycledVolume(projectName string, poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error {
+	return nil
+}
+
+func (b *mockBackend) DeleteRecycledVolume(poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) ListUnknownVolumes(op *operations.Operation) (map[string][]*backupConfig.Config, error) {
 	return nil, nil
 }
@@ -188,6 +216,18 @@ func (b *mockBackend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, er
 	return nil, nil
 }
 
+func (b *mockBackend) GetInstanceMirrorState(inst instance.Instance) (*api.StorageVolumeStateMirror, error) {
+	return nil, nil
+}
+
+func (b *mockBackend) GetInstanceIOStats(inst instance.Instance) (*api.StorageVolumeStateIO, error) {
+	return nil, nil
+}
+
+func (b *mockBackend) UpdateInstanceMirror(inst instance.Instance, req api.StorageVolumeMirrorPost, op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error {
 	return nil
 }
@@ -272,6 +312,10 @@ func (b *mockBackend) ActivateBucket(projectName string, bucketName string, op *
 	return nil, nil
 }
 
+func (b *mockBackend) GetBucketState(projectName string, bucketName string) (*api.StorageBucketState, error) {
+	return nil, nil
+}
+
 func (b *mockBackend) GetBucketURL(bucketName string) *url.URL {
 	return nil
 }
@@ -312,6 +356,22 @@ func (b *mockBackend) GetCustomVolumeUsage(projectName string, volName string) (
 	return nil, nil
 }
 
+func (b *mockBackend) GetCustomVolumeMirrorState(projectName string, volName string) (*api.StorageVolumeStateMirror, error) {
+	return nil, nil
+}
+
+func (b *mockBackend) GetCustomVolumeIOStats(projectName string, volName string) (*api.StorageVolumeStateIO, error) {
+	return nil, nil
+}
+
+func (b *mockBackend) UpdateCustomVolumeMirror(projectName string, volName string, req api.StorageVolumeMirrorPost, op *operations.Operation) error {
+	return nil
+}
+
+func (b *mockBackend) TransferCustomVolume(projectName string, volName string, target string, op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) MountCustomVolume(projectName string, volName string, op *operations.Operation) (*MountInfo, error) {
 	return nil, nil
 }
@@ -356,6 +416,10 @@ func (b *mockBackend) CreateCustomVolumeFromISO(projectName string, volName stri
 	return nil
 }
 
+func (b *mockBackend) CreateCustomVolumeFromDiskImage(projectName string, volName string, imgPath string, op *operations.Operation) error {
+	return nil
+}
+
 // GenerateBucketBackupConfig returns the backup config entry for this bucket.
 func (b *mockBackend) GenerateBucketBackupConfig(projectName string, bucketName string, op *operations.Operation) (*backupConfig.Config, error) {
 	return nil, nil
@@ -0,0 +1,83 @@
+//go:build linux && cgo && !agent
+
+package storage
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	backupConfig "github.com/lxc/incus/v6/internal/server/backup/config"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/storage/drivers"
+	"github.com/lxc/incus/v6/internal/server/sys"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// The recycled volumes are moved along with their snapshots, and can be restored or deleted without any database
+// record.
+func TestRecycledVolume(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	s := &state.State{OS: &sys.OS{}}
+	poolConfig := map[string]string{"source": drivers.GetPoolMountPath("pool1")}
+
+	driver, err := drivers.Load(s, "dir", "pool1", poolConfig, logger.Log, volIDFuncMake(s, 1), nil)
+	require.NoError(t, err)
+
+	pool := &backend{driver: driver, id: 1, name: "pool1", state: s, logger: logger.Log}
+
+	for _, dir := range []string{"custom", "custom-snapshots"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(drivers.GetPoolMountPath("pool1"), dir), 0o711))
+	}
+
+	poolVol := &backupConfig.Config{Volume: &api.StorageVolume{Name: "vol1", Type: "custom", ContentType: "filesystem"}}
+
+	vol := pool.GetVolume(drivers.VolumeTypeCustom, drivers.ContentTypeFS, project.StorageVolume(api.ProjectDefaultName, "vol1"), nil)
+	require.NoError(t, vol.EnsureMountPath())
+	require.NoError(t, os.WriteFile(filepath.Join(vol.MountPath(), "data"), []byte("volume"), 0o600))
+
+	snapVol, err := vol.NewSnapshot("snap0")
+	require.NoError(t, err)
+	require.NoError(t, snapVol.EnsureMountPath())
+	require.NoError(t, os.WriteFile(filepath.Join(snapVol.MountPath(), "data"), []byte("snapshot"), 0o600))
+
+	recycledName := RecycledVolumeName(1)
+	recycledPath := drivers.GetVolumeMountPath("pool1", drivers.VolumeTypeCustom, recycledName)
+	recycledSnapPath := drivers.GetVolumeMountPath("pool1", drivers.VolumeTypeCustom, drivers.GetSnapshotVolumeName(recycledName, "snap0"))
+
+	// The volume and its snapshots are moved into the recycle bin.
+	require.NoError(t, pool.RecycleVolume(api.ProjectDefaultName, poolVol, recycledName, nil))
+	require.NoDirExists(t, vol.MountPath())
+	require.NoDirExists(t, snapVol.MountPath())
+	require.FileExists(t, filepath.Join(recycledPath, "data"))
+	require.FileExists(t, filepath.Join(recycledSnapPath, "data"))
+
+	// And moved back when restored.
+	require.NoError(t, pool.RestoreRecycledVolume(api.ProjectDefaultName, poolVol, recycledName, nil))
+	require.NoDirExists(t, recycledPath)
+	require.FileExists(t, filepath.Join(vol.MountPath(), "data"))
+	require.FileExists(t, filepath.Join(snapVol.MountPath(), "data"))
+
+	// A volume isn't restored over another one created under the same name meanwhile.
+	require.NoError(t, pool.RecycleVolume(api.ProjectDefaultName, poolVol, recycledName, nil))
+	require.NoError(t, vol.EnsureMountPath())
+
+	err = pool.RestoreRecycledVolume(api.ProjectDefaultName, poolVol, recycledName, nil)
+	require.True(t, api.StatusErrorCheck(err, http.StatusConflict))
+	require.FileExists(t, filepath.Join(recycledPath, "data"))
+
+	// Deleting the recycled volume removes its snapshots but leaves the new volume alone.
+	require.NoError(t, pool.DeleteRecycledVolume(poolVol, recycledName, nil))
+	require.NoDirExists(t, recycledPath)
+	require.NoDirExists(t, recycledSnapPath)
+	require.DirExists(t, vol.MountPath())
+
+	// Deleting a volume that is already gone does nothing.
+	require.NoError(t, pool.DeleteRecycledVolume(poolVol, recycledName, nil))
+}
@@ -1,7 +1,52 @@
 package drivers
 
+import (
+	"context"
+	"strconv"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+)
+
 // S3Credentials represents the credentials to access a bucket.
 type S3Credentials struct {
 	AccessKey string `json:"access_key"`
 	SecretKey string `json:"secret_key"`
 }
+
+// BucketUsage represents the space used by a bucket.
+type BucketUsage struct {
+	Size    int64
+	Objects int64
+}
+
+// bucketLifecycleRuleID is the ID of the lifecycle rule managed through the bucket configuration.
+const bucketLifecycleRuleID = "incus-expiration"
+
+// BucketLifecycleKeys lists the bucket configuration keys which control the bucket lifecycle rules.
+var BucketLifecycleKeys = []string{"lifecycle.expiration", "lifecycle.expiration.prefix"}
+
+// BucketLifecycle returns the S3 lifecycle configuration for the bucket configuration.
+func BucketLifecycle(config map[string]string) *lifecycle.Configuration {
+	lifecycleConfig := lifecycle.NewConfiguration()
+
+	days, _ := strconv.Atoi(config["lifecycle.expiration"])
+	if days <= 0 {
+		return lifecycleConfig
+	}
+
+	lifecycleConfig.Rules = []lifecycle.Rule{{
+		ID:         bucketLifecycleRuleID,
+		Status:     "Enabled",
+		RuleFilter: lifecycle.Filter{Prefix: config["lifecycle.expiration.prefix"]},
+		Expiration: lifecycle.Expiration{Days: lifecycle.ExpirationDays(days)},
+	}}
+
+	return lifecycleConfig
+}
+
+// SetBucketLifecycle applies the lifecycle rules of the bucket configuration to an S3 bucket.
+// The lifecycle configuration of the bucket is removed when no expiration is configured.
+func SetBucketLifecycle(ctx context.Context, s3Client *minio.Client, bucketName string, config map[string]string) error {
+	return s3Client.SetBucketLifecycle(ctx, bucketName, BucketLifecycle(config))
+}
@@ -0,0 +1,35 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketLifecycle(t *testing.T) {
+	// Test that no rule is set without an expiration.
+	lifecycleConfig := BucketLifecycle(map[string]string{"lifecycle.expiration.prefix": "logs/"})
+	assert.Empty(t, lifecycleConfig.Rules)
+
+	lifecycleConfig = BucketLifecycle(map[string]string{"lifecycle.expiration": "0"})
+	assert.Empty(t, lifecycleConfig.Rules)
+
+	// Test an expiration of all objects.
+	lifecycleConfig = BucketLifecycle(map[string]string{"lifecycle.expiration": "30"})
+	require.Len(t, lifecycleConfig.Rules, 1)
+
+	rule := lifecycleConfig.Rules[0]
+	assert.Equal(t, bucketLifecycleRuleID, rule.ID)
+	assert.Equal(t, "Enabled", rule.Status)
+	assert.Equal(t, "", rule.RuleFilter.Prefix)
+	assert.EqualValues(t, 30, rule.Expiration.Days)
+
+	// Test an expiration of the objects under a prefix.
+	lifecycleConfig = BucketLifecycle(map[string]string{"lifecycle.expiration": "7", "lifecycle.expiration.prefix": "logs/"})
+	require.Len(t, lifecycleConfig.Rules, 1)
+
+	rule = lifecycleConfig.Rules[0]
+	assert.Equal(t, "logs/", rule.RuleFilter.Prefix)
+	assert.EqualValues(t, 7, rule.Expiration.Days)
+}
@@ -106,6 +106,7 @@ func (d *btrfs) Info() Info {
 		DirectIO:                     true,
 		IOUring:                      true,
 		MountedRoot:                  true,
+		RunningRename:                true,
 		Buckets:                      true,
 	}
 }
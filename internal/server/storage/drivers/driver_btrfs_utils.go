@@ -379,7 +379,7 @@ func (d *btrfs) sendSubvolume(path string, parent string, conn io.ReadWriteClose
 	// Read any error.
 	output, err := io.ReadAll(stderr)
 	if err != nil {
-		logger.Errorf("Problem reading btrfs send stderr: %s", err)
+		d.logger.Error("Problem reading btrfs send stderr", logger.Ctx{"err": err})
 	}
 
 	err = cmd.Wait()
@@ -407,6 +407,12 @@ func (d *btrfs) CreateVolumeFromBackup(vol Volume, srcBackup backup.Info, srcDat
 	return nil, revertHook, nil
 }
 
+// CanCloneVolume checks whether CreateVolumeFromCopy clones the volume rather than copying its data.
+// Volumes and their snapshots are always copied as subvolume snapshots.
+func (d *btrfs) CanCloneVolume(srcVol Volume, copySnapshots bool) bool {
+	return true
+}
+
 // CreateVolumeFromCopy provides same-pool volume copying functionality.
 func (d *btrfs) CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots bool, allowInconsistent bool, op *operations.Operation) error {
 	revert := revert.New()
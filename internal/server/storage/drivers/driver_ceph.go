@@ -390,6 +390,89 @@ func (d *ceph) GetResources() (*api.ResourcesStoragePool, error) {
 	return &res, nil
 }
 
+// GetMirrorState returns the state of the mirroring of the pool to its peer clusters.
+func (d *ceph) GetMirrorState() (*api.StoragePoolMirrorState, error) {
+	info, err := d.rbdGetPoolMirrorInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.StoragePoolMirrorState{
+		Mode:     info.Mode,
+		SiteName: info.SiteName,
+		Peers:    []api.StoragePoolMirrorPeer{},
+	}
+
+	for _, peer := range info.Peers {
+		state.Peers = append(state.Peers, api.StoragePoolMirrorPeer{
+			UUID:       peer.UUID,
+			SiteName:   peer.SiteName,
+			Direction:  peer.Direction,
+			ClientName: peer.ClientName,
+		})
+	}
+
+	if info.Mode == "disabled" {
+		return &state, nil
+	}
+
+	out, err := subprocess.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"--format", "json",
+		"mirror",
+		"pool",
+		"status",
+		d.config["ceph.osd.pool_name"])
+	if err != nil {
+		return nil, err
+	}
+
+	status := struct {
+		Summary struct {
+			Health       string           `json:"health"`
+			DaemonHealth string           `json:"daemon_health"`
+			ImageHealth  string           `json:"image_health"`
+			States       map[string]int64 `json:"states"`
+		} `json:"summary"`
+	}{}
+
+	err = json.Unmarshal([]byte(out), &status)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Health = status.Summary.Health
+	state.DaemonHealth = status.Summary.DaemonHealth
+	state.VolumeHealth = status.Summary.ImageHealth
+	state.Volumes = status.Summary.States
+
+	return &state, nil
+}
+
+// PromoteMirror makes the mirrored volumes of the pool primary, so they can be used on this cluster.
+func (d *ceph) PromoteMirror(force bool) error {
+	return d.rbdSetPoolMirrorRole("promote", force)
+}
+
+// DemoteMirror makes the mirrored volumes of the pool non-primary, so they can be promoted on a peer cluster.
+func (d *ceph) DemoteMirror() error {
+	return d.rbdSetPoolMirrorRole("demote", false)
+}
+
+// rbdSetPoolMirrorRole promotes or demotes the mirrored RBD images of the OSD pool.
+func (d *ceph) rbdSetPoolMirrorRole(action string, force bool) error {
+	args := append([]string{"--id", d.config["ceph.user.name"], "--cluster", d.config["ceph.cluster_name"]}, rbdMirrorRoleArgs("pool", action, d.config["ceph.osd.pool_name"], force)...)
+
+	_, err := subprocess.RunCommand("rbd", args...)
+	if err != nil {
+		return fmt.Errorf("Failed to %s OSD pool %q: %w", action, d.config["ceph.osd.pool_name"], err)
+	}
+
+	return nil
+}
+
 // MigrationType returns the type of transfer methods to be used when doing migrations between pools in preference order.
 func (d *ceph) MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool) []localMigration.Type {
 	var rsyncFeatures []string
@@ -1267,3 +1267,304 @@ func (d *ceph) resizeVolume(vol Volume, sizeBytes int64, allowShrink bool) error
 
 	return err
 }
+
+// cephRBDInfo represents the output of "rbd info".
+type cephRBDInfo struct {
+	Features  []string `json:"features"`
+	Mirroring *struct {
+		Mode    string `json:"mode"`
+		State   string `json:"state"`
+		Primary bool   `json:"primary"`
+	} `json:"mirroring"`
+	Parent *struct {
+		Pool     string `json:"pool"`
+		Image    string `json:"image"`
+		Snapshot string `json:"snapshot"`
+	} `json:"parent"`
+}
+
+// parseRBDInfo parses the JSON output of "rbd info".
+func parseRBDInfo(out []byte) (*cephRBDInfo, error) {
+	info := cephRBDInfo{}
+	err := json.Unmarshal(out, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// rbdGetImageInfo returns the features, mirroring state and parent of an RBD image, given with its OSD pool.
+func (d *ceph) rbdGetImageInfo(rbdName string) (*cephRBDInfo, error) {
+	out, err := subprocess.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"--format", "json",
+		"info",
+		rbdName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRBDInfo([]byte(out))
+}
+
+// rbdGetVolumeInfo returns the features, mirroring state and parent of an RBD storage volume.
+func (d *ceph) rbdGetVolumeInfo(vol Volume) (*cephRBDInfo, error) {
+	return d.rbdGetImageInfo(d.getRBDVolumeName(vol, "", false, true))
+}
+
+// rbdEnablePoolMirroring enables the per-image mirroring of the OSD pool if mirroring isn't enabled yet.
+func (d *ceph) rbdEnablePoolMirroring() error {
+	info, err := d.rbdGetPoolMirrorInfo()
+	if err != nil {
+		return err
+	}
+
+	if info.Mode != "disabled" {
+		return nil
+	}
+
+	_, err = subprocess.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"mirror",
+		"pool",
+		"enable",
+		d.config["ceph.osd.pool_name"],
+		"image")
+	if err != nil {
+		return fmt.Errorf("Failed enabling mirroring on OSD pool %q: %w", d.config["ceph.osd.pool_name"], err)
+	}
+
+	return nil
+}
+
+// cephRBDMirrorPoolInfo represents the output of "rbd mirror pool info".
+type cephRBDMirrorPoolInfo struct {
+	Mode     string `json:"mode"`
+	SiteName string `json:"site_name"`
+	Peers    []struct {
+		UUID       string `json:"uuid"`
+		Direction  string `json:"direction"`
+		SiteName   string `json:"site_name"`
+		ClientName string `json:"client_name"`
+	} `json:"peers"`
+}
+
+// rbdGetPoolMirrorInfo returns the mirroring mode and peers of the OSD pool.
+func (d *ceph) rbdGetPoolMirrorInfo() (*cephRBDMirrorPoolInfo, error) {
+	out, err := subprocess.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"--format", "json",
+		"mirror",
+		"pool",
+		"info",
+		d.config["ceph.osd.pool_name"])
+	if err != nil {
+		return nil, err
+	}
+
+	info := cephRBDMirrorPoolInfo{}
+	err = json.Unmarshal([]byte(out), &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// rbdMirroringCommands returns the arguments of the rbd commands enabling the mirroring of an RBD image in
+// the given mode, according to its current state. Nothing is returned if the image is already mirrored.
+func rbdMirroringCommands(rbdName string, mode string, info *cephRBDInfo) [][]string {
+	if info.Mirroring != nil && info.Mirroring.State == "enabled" {
+		return nil
+	}
+
+	commands := [][]string{}
+
+	// Journal based mirroring requires the journaling feature, which in turn requires exclusive locking.
+	if mode == "journal" {
+		for _, feature := range []string{"exclusive-lock", "journaling"} {
+			if !slices.Contains(info.Features, feature) {
+				commands = append(commands, []string{"feature", "enable", rbdName, feature})
+			}
+		}
+	}
+
+	return append(commands, []string{"mirror", "image", "enable", rbdName, mode})
+}
+
+// rbdEnableImageMirroring enables the mirroring of an RBD image, given with its OSD pool.
+// As a clone can only be mirrored when its parent is, the mirroring of its parents is enabled first.
+func (d *ceph) rbdEnableImageMirroring(rbdName string, mode string) error {
+	info, err := d.rbdGetImageInfo(rbdName)
+	if err != nil {
+		return err
+	}
+
+	if info.Parent != nil {
+		err = d.rbdEnableImageMirroring(fmt.Sprintf("%s/%s", info.Parent.Pool, info.Parent.Image), mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, command := range rbdMirroringCommands(rbdName, mode, info) {
+		args := append([]string{"--id", d.config["ceph.user.name"], "--cluster", d.config["ceph.cluster_name"]}, command...)
+
+		_, err = subprocess.RunCommand("rbd", args...)
+		if err != nil {
+			return fmt.Errorf("Failed enabling mirroring of RBD volume %q: %w", rbdName, err)
+		}
+	}
+
+	return nil
+}
+
+// rbdEnableVolumeMirroring enables the mirroring of an RBD storage volume to the peers of the OSD pool,
+// using the mode and schedule set in its configuration.
+func (d *ceph) rbdEnableVolumeMirroring(vol Volume) error {
+	mode := vol.config["ceph.rbd.mirroring"]
+	if mode == "" {
+		return nil
+	}
+
+	err := d.rbdEnablePoolMirroring()
+	if err != nil {
+		return err
+	}
+
+	err = d.rbdEnableImageMirroring(d.getRBDVolumeName(vol, "", false, true), mode)
+	if err != nil {
+		return err
+	}
+
+	if mode == "snapshot" && vol.config["ceph.rbd.mirroring.schedule"] != "" {
+		err = d.rbdMirrorSchedule(vol, "add", vol.config["ceph.rbd.mirroring.schedule"])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rbdDisableVolumeMirroring disables the mirroring of an RBD storage volume enabled by rbdEnableVolumeMirroring.
+func (d *ceph) rbdDisableVolumeMirroring(vol Volume) error {
+	mode := vol.config["ceph.rbd.mirroring"]
+	if mode == "" {
+		return nil
+	}
+
+	rbdName := d.getRBDVolumeName(vol, "", false, true)
+
+	if mode == "snapshot" && vol.config["ceph.rbd.mirroring.schedule"] != "" {
+		err := d.rbdMirrorSchedule(vol, "remove", vol.config["ceph.rbd.mirroring.schedule"])
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := subprocess.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"mirror",
+		"image",
+		"disable",
+		rbdName)
+	if err != nil {
+		return fmt.Errorf("Failed disabling mirroring of RBD volume %q: %w", rbdName, err)
+	}
+
+	if mode == "journal" {
+		_, err = subprocess.RunCommand(
+			"rbd",
+			"--id", d.config["ceph.user.name"],
+			"--cluster", d.config["ceph.cluster_name"],
+			"feature",
+			"disable",
+			rbdName,
+			"journaling")
+		if err != nil {
+			return fmt.Errorf("Failed disabling journaling on RBD volume %q: %w", rbdName, err)
+		}
+	}
+
+	return nil
+}
+
+// rbdMirrorRoleArgs returns the arguments promoting or demoting the mirrored RBD images of an OSD pool
+// or a single RBD image, depending on the level.
+func rbdMirrorRoleArgs(level string, action string, name string, force bool) []string {
+	args := []string{"mirror", level, action}
+
+	if force {
+		args = append(args, "--force")
+	}
+
+	return append(args, name)
+}
+
+// rbdMirrorRoleVolumes returns the RBD images to promote or demote along with a storage volume.
+func (d *ceph) rbdMirrorRoleVolumes(vol Volume) []string {
+	vols := []Volume{vol}
+	if vol.IsVMBlock() {
+		vols = append(vols, vol.NewVMBlockFilesystemVolume())
+	}
+
+	rbdNames := make([]string, 0, len(vols))
+	for _, v := range vols {
+		rbdNames = append(rbdNames, d.getRBDVolumeName(v, "", false, true))
+	}
+
+	return rbdNames
+}
+
+// rbdSetVolumeMirrorRole promotes or demotes a mirrored RBD storage volume.
+func (d *ceph) rbdSetVolumeMirrorRole(vol Volume, action string, force bool) error {
+	for _, rbdName := range d.rbdMirrorRoleVolumes(vol) {
+		args := append([]string{"--id", d.config["ceph.user.name"], "--cluster", d.config["ceph.cluster_name"]}, rbdMirrorRoleArgs("image", action, rbdName, force)...)
+
+		_, err := subprocess.RunCommand("rbd", args...)
+		if err != nil {
+			return fmt.Errorf("Failed to %s RBD volume %q: %w", action, rbdName, err)
+		}
+	}
+
+	return nil
+}
+
+// rbdMirrorSchedule adds or removes a mirror snapshot schedule of an RBD storage volume.
+func (d *ceph) rbdMirrorSchedule(vol Volume, action string, interval string) error {
+	_, err := subprocess.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"mirror",
+		"snapshot",
+		"schedule",
+		action,
+		"--pool", d.config["ceph.osd.pool_name"],
+		"--image", d.getRBDVolumeName(vol, "", false, false),
+		interval)
+	if err != nil {
+		return fmt.Errorf("Failed to %s mirror snapshot schedule of RBD volume %q: %w", action, d.getRBDVolumeName(vol, "", false, true), err)
+	}
+
+	return nil
+}
+
+// validateRBDMirrorSchedule validates an RBD mirror snapshot interval, such as 30m, 12h or 1d.
+func validateRBDMirrorSchedule(value string) error {
+	if !regexp.MustCompile(`^[1-9][0-9]*[mhd]$`).MatchString(value) {
+		return fmt.Errorf("Invalid mirror snapshot interval %q, expected a number followed by m, h or d", value)
+	}
+
+	return nil
+}
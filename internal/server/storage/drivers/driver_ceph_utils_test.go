@@ -149,3 +149,171 @@ func Example_ceph_parseParent() {
 	// pool container test-project_c4  block  <nil>
 	// pool zombie_container test-project_c1_28e7a7ab-740a-490c-8118-7caf7810f83b  filesystem zombie_snapshot_1027f4ab-de11-4cee-8015-bd532a1fed76 <nil>
 }
+
+func Example_validateRBDMirrorSchedule() {
+	for _, value := range []string{"30m", "12h", "1d", "0h", "1w", "h", "1h30m"} {
+		fmt.Println(value, validateRBDMirrorSchedule(value) == nil)
+	}
+
+	// Output: 30m true
+	// 12h true
+	// 1d true
+	// 0h false
+	// 1w false
+	// h false
+	// 1h30m false
+}
+
+func Test_parseRBDInfo(t *testing.T) {
+	info, err := parseRBDInfo([]byte(`{"name":"container_c1","features":["layering","exclusive-lock"],"mirroring":{"state":"disabled"},"parent":{"pool":"incus","pool_namespace":"","image":"image_a1b2","snapshot":"readonly","trash":false,"overlap":10737418240}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Parent == nil || info.Parent.Pool != "incus" || info.Parent.Image != "image_a1b2" || info.Parent.Snapshot != "readonly" {
+		t.Errorf("Unexpected parent %+v", info.Parent)
+	}
+
+	if info.Mirroring == nil || info.Mirroring.State != "disabled" {
+		t.Errorf("Unexpected mirroring state %+v", info.Mirroring)
+	}
+
+	info, err = parseRBDInfo([]byte(`{"name":"image_a1b2","features":["layering"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Parent != nil {
+		t.Errorf("Unexpected parent %+v", info.Parent)
+	}
+
+	_, err = parseRBDInfo([]byte(`not json`))
+	if err == nil {
+		t.Error("Expected an error for invalid output")
+	}
+}
+
+func Test_rbdMirroringCommands(t *testing.T) {
+	enabled := &cephRBDInfo{}
+	enabled.Mirroring = &struct {
+		Mode    string `json:"mode"`
+		State   string `json:"state"`
+		Primary bool   `json:"primary"`
+	}{Mode: "snapshot", State: "enabled", Primary: true}
+
+	tests := []struct {
+		name string
+		mode string
+		info *cephRBDInfo
+		want [][]string
+	}{
+		{
+			"Snapshot mode",
+			"snapshot",
+			&cephRBDInfo{Features: []string{"layering"}},
+			[][]string{{"mirror", "image", "enable", "incus/container_c1", "snapshot"}},
+		},
+		{
+			"Journal mode without the required features",
+			"journal",
+			&cephRBDInfo{Features: []string{"layering"}},
+			[][]string{
+				{"feature", "enable", "incus/container_c1", "exclusive-lock"},
+				{"feature", "enable", "incus/container_c1", "journaling"},
+				{"mirror", "image", "enable", "incus/container_c1", "journal"},
+			},
+		},
+		{
+			"Journal mode with exclusive locking",
+			"journal",
+			&cephRBDInfo{Features: []string{"layering", "exclusive-lock"}},
+			[][]string{
+				{"feature", "enable", "incus/container_c1", "journaling"},
+				{"mirror", "image", "enable", "incus/container_c1", "journal"},
+			},
+		},
+		{
+			"Already mirrored",
+			"snapshot",
+			enabled,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rbdMirroringCommands("incus/container_c1", tt.mode, tt.info)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("rbdMirroringCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rbdMirrorRoleArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  string
+		action string
+		target string
+		force  bool
+		want   []string
+	}{
+		{"Pool promotion", "pool", "promote", "incus", false, []string{"mirror", "pool", "promote", "incus"}},
+		{"Forced pool promotion", "pool", "promote", "incus", true, []string{"mirror", "pool", "promote", "--force", "incus"}},
+		{"Pool demotion", "pool", "demote", "incus", false, []string{"mirror", "pool", "demote", "incus"}},
+		{"Image promotion", "image", "promote", "incus/custom_default_vol1", false, []string{"mirror", "image", "promote", "incus/custom_default_vol1"}},
+		{"Forced image promotion", "image", "promote", "incus/custom_default_vol1", true, []string{"mirror", "image", "promote", "--force", "incus/custom_default_vol1"}},
+		{"Image demotion", "image", "demote", "incus/custom_default_vol1", false, []string{"mirror", "image", "demote", "incus/custom_default_vol1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rbdMirrorRoleArgs(tt.level, tt.action, tt.target, tt.force)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("rbdMirrorRoleArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ceph_rbdMirrorRoleVolumes(t *testing.T) {
+	d := &ceph{
+		common{
+			config: map[string]string{
+				"ceph.osd.pool_name": "incus",
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		vol  Volume
+		want []string
+	}{
+		{
+			"Custom volume",
+			NewVolume(nil, "testpool", VolumeTypeCustom, ContentTypeFS, "default_vol1", nil, nil),
+			[]string{"incus/custom_default_vol1"},
+		},
+		{
+			"Container volume",
+			NewVolume(nil, "testpool", VolumeTypeContainer, ContentTypeFS, "c1", nil, nil),
+			[]string{"incus/container_c1"},
+		},
+		{
+			"Virtual machine volume along with its filesystem volume",
+			NewVolume(nil, "testpool", VolumeTypeVM, ContentTypeBlock, "v1", nil, nil),
+			[]string{"incus/virtual-machine_v1.block", "incus/virtual-machine_v1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := d.rbdMirrorRoleVolumes(tt.vol)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("rbdMirrorRoleVolumes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"strings"
@@ -284,6 +285,12 @@ func (d *ceph) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Ope
 		}
 	}
 
+	// Mirror the volume to the peer clusters if requested.
+	err = d.rbdEnableVolumeMirroring(vol)
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
@@ -320,6 +327,12 @@ func (d *ceph) CreateVolumeFromBackup(vol Volume, srcBackup backup.Info, srcData
 	return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcData, op)
 }
 
+// CanCloneVolume checks whether CreateVolumeFromCopy clones the volume rather than copying its data.
+// Volumes copied along with their snapshots are exported in full.
+func (d *ceph) CanCloneVolume(srcVol Volume, copySnapshots bool) bool {
+	return !util.IsFalse(d.config["ceph.rbd.clone_copy"]) && !copySnapshots
+}
+
 // CreateVolumeFromCopy provides same-pool volume copying functionality.
 func (d *ceph) CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots bool, allowInconsistent bool, op *operations.Operation) error {
 	var err error
@@ -361,6 +374,12 @@ func (d *ceph) CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots boo
 			return err
 		}
 
+		// Mirror the volume to the peer clusters if requested.
+		err = d.rbdEnableVolumeMirroring(v)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	}
 
@@ -640,6 +659,12 @@ func (d *ceph) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, vo
 		return err
 	}
 
+	// Mirror the volume to the peer clusters if requested.
+	err = d.rbdEnableVolumeMirroring(vol)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -808,7 +833,7 @@ func (d *ceph) FillVolumeConfig(vol Volume) error {
 	if vol.ContentType() == ContentTypeFS || vol.IsVMBlock() {
 		// Inherit filesystem from pool if not set.
 		if vol.config["block.filesystem"] == "" {
-			vol.config["block.filesystem"] = d.config["volume.block.filesystem"]
+			vol.config["block.filesystem"] = d.volumeDefault(vol, "block.filesystem")
 		}
 
 		// Default filesystem if neither volume nor pool specify an override.
@@ -819,7 +844,7 @@ func (d *ceph) FillVolumeConfig(vol Volume) error {
 
 		// Inherit filesystem mount options from pool if not set.
 		if vol.config["block.mount_options"] == "" {
-			vol.config["block.mount_options"] = d.config["volume.block.mount_options"]
+			vol.config["block.mount_options"] = d.volumeDefault(vol, "block.mount_options")
 		}
 
 		// Default filesystem mount options if neither volume nor pool specify an override.
@@ -835,8 +860,10 @@ func (d *ceph) FillVolumeConfig(vol Volume) error {
 // commonVolumeRules returns validation rules which are common for pool and volume.
 func (d *ceph) commonVolumeRules() map[string]func(value string) error {
 	return map[string]func(value string) error{
-		"block.filesystem":    validate.Optional(validate.IsOneOf(blockBackedAllowedFilesystems...)),
-		"block.mount_options": validate.IsAny,
+		"block.filesystem":            validate.Optional(validate.IsOneOf(blockBackedAllowedFilesystems...)),
+		"block.mount_options":         validate.IsAny,
+		"ceph.rbd.mirroring":          validate.Optional(validate.IsOneOf("journal", "snapshot")),
+		"ceph.rbd.mirroring.schedule": validate.Optional(validateRBDMirrorSchedule),
 	}
 }
 
@@ -855,9 +882,137 @@ func (d *ceph) UpdateVolume(vol Volume, changedConfig map[string]string) error {
 		}
 	}
 
+	_, modeChanged := changedConfig["ceph.rbd.mirroring"]
+	_, scheduleChanged := changedConfig["ceph.rbd.mirroring.schedule"]
+	if modeChanged || scheduleChanged {
+		newConfig := make(map[string]string, len(vol.config))
+		maps.Copy(newConfig, vol.config)
+		maps.Copy(newConfig, changedConfig)
+
+		vols := []Volume{vol}
+		if vol.IsVMBlock() {
+			vols = append(vols, vol.NewVMBlockFilesystemVolume())
+		}
+
+		for _, v := range vols {
+			newVol := NewVolume(d, d.name, v.volType, v.contentType, v.name, newConfig, v.poolConfig)
+
+			err := d.updateVolumeMirroring(v, newVol)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
+// updateVolumeMirroring applies the mirroring configuration of newVol to the RBD volume of vol.
+func (d *ceph) updateVolumeMirroring(vol Volume, newVol Volume) error {
+	oldMode := vol.config["ceph.rbd.mirroring"]
+	newMode := newVol.config["ceph.rbd.mirroring"]
+
+	// Changing the schedule of a snapshot mirrored volume doesn't require a new full sync.
+	if oldMode == "snapshot" && newMode == "snapshot" {
+		oldSchedule := vol.config["ceph.rbd.mirroring.schedule"]
+		newSchedule := newVol.config["ceph.rbd.mirroring.schedule"]
+		if oldSchedule != "" {
+			err := d.rbdMirrorSchedule(vol, "remove", oldSchedule)
+			if err != nil {
+				return err
+			}
+		}
+
+		if newSchedule != "" {
+			err := d.rbdMirrorSchedule(vol, "add", newSchedule)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if oldMode == newMode {
+		return nil
+	}
+
+	err := d.rbdDisableVolumeMirroring(vol)
+	if err != nil {
+		return err
+	}
+
+	return d.rbdEnableVolumeMirroring(newVol)
+}
+
+// GetVolumeMirrorState returns the mirroring state of the volume, or nil if it isn't mirrored.
+func (d *ceph) GetVolumeMirrorState(vol Volume) (*api.StorageVolumeStateMirror, error) {
+	info, err := d.rbdGetVolumeInfo(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Mirroring == nil || info.Mirroring.State != "enabled" {
+		return nil, nil
+	}
+
+	out, err := subprocess.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"--format", "json",
+		"mirror",
+		"image",
+		"status",
+		d.getRBDVolumeName(vol, "", false, true))
+	if err != nil {
+		return nil, err
+	}
+
+	status := struct {
+		State       string `json:"state"`
+		Description string `json:"description"`
+	}{}
+
+	err = json.Unmarshal([]byte(out), &status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.StorageVolumeStateMirror{
+		Mode:        info.Mirroring.Mode,
+		Primary:     info.Mirroring.Primary,
+		State:       status.State,
+		Description: status.Description,
+	}, nil
+}
+
+// PromoteVolumeMirror makes the mirrored volume primary, so it can be used on this cluster.
+func (d *ceph) PromoteVolumeMirror(vol Volume, force bool) error {
+	return d.rbdSetVolumeMirrorRole(vol, "promote", force)
+}
+
+// DemoteVolumeMirror makes the mirrored volume non-primary, so it can be promoted on a peer cluster.
+func (d *ceph) DemoteVolumeMirror(vol Volume) error {
+	return d.rbdSetVolumeMirrorRole(vol, "demote", false)
+}
+
+// GetVolumeIOStats returns the IO counters of the volume.
+// Only volumes mapped on this server report IO counters.
+func (d *ceph) GetVolumeIOStats(vol Volume) (*api.StorageVolumeStateIO, error) {
+	if vol.IsSnapshot() {
+		return nil, ErrNotSupported
+	}
+
+	// Volumes which aren't mapped locally have no device to report counters for.
+	_, devPath, err := d.getRBDMappedDevPath(vol, false)
+	if err != nil {
+		return nil, ErrNotSupported
+	}
+
+	return blockDeviceIOStats(devPath)
+}
+
 // GetVolumeUsage returns the disk space used by the volume.
 func (d *ceph) GetVolumeUsage(vol Volume) (int64, error) {
 	isSnap := vol.IsSnapshot()
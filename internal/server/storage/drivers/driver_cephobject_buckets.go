@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
@@ -141,6 +142,14 @@ func (d *cephobject) CreateBucket(bucket Volume, op *operations.Operation) error
 		}
 	}
 
+	// Set initial lifecycle rules if specified.
+	if bucket.config["lifecycle.expiration"] != "" {
+		err = SetBucketLifecycle(ctx, minioClient, storageBucketName, bucket.config)
+		if err != nil {
+			return fmt.Errorf("Failed setting bucket lifecycle: %w", err)
+		}
+	}
+
 	revert.Success()
 	return nil
 }
@@ -191,9 +200,66 @@ func (d *cephobject) UpdateBucket(bucket Volume, changedConfig map[string]string
 		}
 	}
 
+	lifecycleChanged := false
+	newConfig := map[string]string{}
+	maps.Copy(newConfig, bucket.config)
+	for _, key := range BucketLifecycleKeys {
+		value, changed := changedConfig[key]
+		if changed {
+			lifecycleChanged = true
+			newConfig[key] = value
+		}
+	}
+
+	if lifecycleChanged {
+		err := d.setBucketLifecycle(bucket, newConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setBucketLifecycle applies the lifecycle rules of the supplied config to the bucket.
+func (d *cephobject) setBucketLifecycle(bucket Volume, config map[string]string) error {
+	adminUserInfo, _, err := d.radosgwadminGetUser(context.TODO(), cephobjectRadosgwAdminUser)
+	if err != nil {
+		return fmt.Errorf("Failed getting admin user %q: %w", cephobjectRadosgwAdminUser, err)
+	}
+
+	minioClient, err := d.s3Client(*adminUserInfo)
+	if err != nil {
+		return err
+	}
+
+	_, bucketName := project.StorageVolumeParts(bucket.name)
+	storageBucketName := d.radosgwBucketName(bucketName)
+
+	ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Duration(time.Second*30))
+	defer ctxCancel()
+
+	err = SetBucketLifecycle(ctx, minioClient, storageBucketName, config)
+	if err != nil {
+		return fmt.Errorf("Failed setting bucket lifecycle: %w", err)
+	}
+
 	return nil
 }
 
+// GetBucketUsage returns the space used by an existing bucket.
+func (d *cephobject) GetBucketUsage(bucket Volume) (*BucketUsage, error) {
+	_, bucketName := project.StorageVolumeParts(bucket.name)
+	storageBucketName := d.radosgwBucketName(bucketName)
+
+	usage, err := d.radosgwadminBucketStats(context.TODO(), storageBucketName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting bucket usage: %w", err)
+	}
+
+	return usage, nil
+}
+
 // bucketKeyRadosgwAccessRole returns the radosgw access setting for the specified role name.
 func (d *cephobject) bucketKeyRadosgwAccessRole(roleName string) (string, error) {
 	switch roleName {
@@ -235,6 +235,39 @@ func (d *cephobject) radosgwadminBucketSetQuota(ctx context.Context, bucket stri
 	return nil
 }
 
+// radosgwadminBucketStats returns the space used by a bucket.
+func (d *cephobject) radosgwadminBucketStats(ctx context.Context, bucket string) (*BucketUsage, error) {
+	out, err := d.radosgwadmin(ctx, "bucket", "stats", "--bucket", bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return radosgwBucketUsage(out)
+}
+
+// radosgwBucketUsage parses the output of the bucket stats, summing the usage of all its categories.
+func radosgwBucketUsage(out string) (*BucketUsage, error) {
+	stats := struct {
+		Usage map[string]struct {
+			Size       int64 `json:"size"`
+			NumObjects int64 `json:"num_objects"`
+		} `json:"usage"`
+	}{}
+
+	err := json.Unmarshal([]byte(out), &stats)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &BucketUsage{}
+	for _, category := range stats.Usage {
+		usage.Size += category.Size
+		usage.Objects += category.NumObjects
+	}
+
+	return usage, nil
+}
+
 // radosgwadminBucketList returns the list of buckets.
 func (d *cephobject) radosgwadminBucketList(ctx context.Context) ([]string, error) {
 	out, err := d.radosgwadmin(ctx, "bucket", "list")
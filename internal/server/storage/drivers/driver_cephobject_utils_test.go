@@ -0,0 +1,32 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRadosgwBucketUsage(t *testing.T) {
+	// Test that the usage of all the categories is summed up.
+	out := `{
+		"bucket": "foo",
+		"usage": {
+			"rgw.main": {"size": 4096, "size_actual": 8192, "num_objects": 3},
+			"rgw.multimeta": {"size": 1024, "size_actual": 1024, "num_objects": 1}
+		}
+	}`
+
+	usage, err := radosgwBucketUsage(out)
+	require.NoError(t, err)
+	assert.Equal(t, &BucketUsage{Size: 5120, Objects: 4}, usage)
+
+	// Test an empty bucket.
+	usage, err = radosgwBucketUsage(`{"bucket": "foo", "usage": {}}`)
+	require.NoError(t, err)
+	assert.Equal(t, &BucketUsage{}, usage)
+
+	// Test invalid output.
+	_, err = radosgwBucketUsage("not json")
+	assert.Error(t, err)
+}
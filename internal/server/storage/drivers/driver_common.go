@@ -18,6 +18,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/subprocess"
 	"github.com/lxc/incus/v6/shared/util"
@@ -97,13 +98,20 @@ func (d *common) validatePool(config map[string]string, driverRules map[string]f
 // Sometimes that can be useful when copying is dependant from specific conditions
 // and shouldn't be done in generic way.
 func (d *common) fillVolumeConfig(vol *Volume, excludedKeys ...string) error {
+	volKeys := []string{}
 	for k := range d.config {
-		if !strings.HasPrefix(k, "volume.") {
-			continue
+		if strings.HasPrefix(k, "volume.") {
+			volKeys = append(volKeys, strings.TrimPrefix(k, "volume."))
 		}
+	}
 
-		volKey := strings.TrimPrefix(k, "volume.")
+	for volKey := range vol.volumeDefaults {
+		if d.config["volume."+volKey] == "" {
+			volKeys = append(volKeys, volKey)
+		}
+	}
 
+	for _, volKey := range volKeys {
 		isExcluded := false
 		for _, excludedKey := range excludedKeys {
 			if excludedKey == volKey {
@@ -132,13 +140,24 @@ func (d *common) fillVolumeConfig(vol *Volume, excludedKeys ...string) error {
 		}
 
 		if vol.config[volKey] == "" {
-			vol.config[volKey] = d.config[k]
+			vol.config[volKey] = d.volumeDefault(*vol, volKey)
 		}
 	}
 
 	return nil
 }
 
+// volumeDefault returns the default value of a volume config key, from the volume defaults if set there or
+// else from the pool "volume.*" config.
+func (d *common) volumeDefault(vol Volume, key string) string {
+	value, ok := vol.volumeDefaults[key]
+	if ok {
+		return value
+	}
+
+	return d.config["volume."+key]
+}
+
 // FillVolumeConfig populate volume with default config.
 func (d *common) FillVolumeConfig(vol Volume) error {
 	return d.fillVolumeConfig(&vol)
@@ -248,6 +267,31 @@ func (d *common) Config() map[string]string {
 	return confCopy
 }
 
+// Leave releases a remote storage pool on the cluster members other than the one deleting it.
+func (d *common) Leave() error {
+	return nil
+}
+
+// CheckHealth checks that the storage backing the pool is still usable.
+func (d *common) CheckHealth() error {
+	return ErrNotSupported
+}
+
+// GetMirrorState returns the state of the mirroring of the pool.
+func (d *common) GetMirrorState() (*api.StoragePoolMirrorState, error) {
+	return nil, ErrNotSupported
+}
+
+// PromoteMirror makes the mirrored volumes of the pool primary.
+func (d *common) PromoteMirror(force bool) error {
+	return ErrNotSupported
+}
+
+// DemoteMirror makes the mirrored volumes of the pool non-primary.
+func (d *common) DemoteMirror() error {
+	return ErrNotSupported
+}
+
 // ApplyPatch looks for a suitable patch and runs it.
 func (d *common) ApplyPatch(name string) error {
 	if d.patches == nil {
@@ -370,6 +414,26 @@ func (d *common) GetVolumeUsage(vol Volume) (int64, error) {
 	return -1, ErrNotSupported
 }
 
+// GetVolumeMirrorState returns the mirroring state of a volume.
+func (d *common) GetVolumeMirrorState(vol Volume) (*api.StorageVolumeStateMirror, error) {
+	return nil, ErrNotSupported
+}
+
+// GetVolumeIOStats returns the IO counters of a volume.
+func (d *common) GetVolumeIOStats(vol Volume) (*api.StorageVolumeStateIO, error) {
+	return nil, ErrNotSupported
+}
+
+// PromoteVolumeMirror makes a mirrored volume primary.
+func (d *common) PromoteVolumeMirror(vol Volume, force bool) error {
+	return ErrNotSupported
+}
+
+// DemoteVolumeMirror makes a mirrored volume non-primary.
+func (d *common) DemoteVolumeMirror(vol Volume) error {
+	return ErrNotSupported
+}
+
 // SetVolumeQuota applies a size limit on volume.
 func (d *common) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
 	return ErrNotSupported
@@ -396,6 +460,11 @@ func (d *common) UnmountVolume(vol Volume, keepBlockDev bool, op *operations.Ope
 	return false, ErrNotSupported
 }
 
+// CanCloneVolume checks whether CreateVolumeFromCopy clones the volume rather than copying its data.
+func (d *common) CanCloneVolume(srcVol Volume, copySnapshots bool) bool {
+	return false
+}
+
 // CanDelegateVolume checks whether the volume can be delegated.
 func (d *common) CanDelegateVolume(vol Volume) bool {
 	return false
@@ -451,6 +520,11 @@ func (d *common) RestoreVolume(vol Volume, snapshotName string, op *operations.O
 	return ErrNotSupported
 }
 
+// TransferVolume sends a volume to an external target.
+func (d *common) TransferVolume(vol Volume, target string, op *operations.Operation) error {
+	return ErrNotSupported
+}
+
 // RenameVolumeSnapshot renames a snapshot.
 func (d *common) RenameVolumeSnapshot(snapVol Volume, newSnapshotName string, op *operations.Operation) error {
 	return ErrNotSupported
@@ -495,6 +569,11 @@ func (d *common) UpdateBucket(bucket Volume, changedConfig map[string]string) er
 	return ErrNotSupported
 }
 
+// GetBucketUsage returns the space used by an existing bucket.
+func (d *common) GetBucketUsage(bucket Volume) (*BucketUsage, error) {
+	return nil, ErrNotSupported
+}
+
 // ValidateBucketKey validates the supplied bucket key config.
 func (d *common) ValidateBucketKey(keyName string, creds S3Credentials, roleName string) error {
 	if keyName == "" {
@@ -0,0 +1,108 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFillVolumeConfig(t *testing.T) {
+	d := &common{config: map[string]string{
+		"volume.size":             "10GiB",
+		"volume.snapshots.expiry": "1d",
+		"volume.block.filesystem": "ext4",
+	}}
+
+	tests := []struct {
+		name         string
+		volType      VolumeType
+		config       map[string]string
+		defaults     map[string]string
+		excludedKeys []string
+		expected     map[string]string
+	}{
+		{
+			name:    "Pool defaults",
+			volType: VolumeTypeCustom,
+			config:  map[string]string{},
+			expected: map[string]string{
+				"size":             "10GiB",
+				"snapshots.expiry": "1d",
+				"block.filesystem": "ext4",
+			},
+		},
+		{
+			name:     "Project defaults override the pool defaults",
+			volType:  VolumeTypeCustom,
+			config:   map[string]string{},
+			defaults: map[string]string{"snapshots.expiry": "2d", "snapshots.schedule": "@daily"},
+			expected: map[string]string{
+				"size":               "10GiB",
+				"snapshots.expiry":   "2d",
+				"snapshots.schedule": "@daily",
+				"block.filesystem":   "ext4",
+			},
+		},
+		{
+			name:     "Volume config overrides the project defaults",
+			volType:  VolumeTypeCustom,
+			config:   map[string]string{"snapshots.expiry": "3d", "snapshots.schedule": "@hourly"},
+			defaults: map[string]string{"snapshots.expiry": "2d", "snapshots.schedule": "@daily"},
+			expected: map[string]string{
+				"size":               "10GiB",
+				"snapshots.expiry":   "3d",
+				"snapshots.schedule": "@hourly",
+				"block.filesystem":   "ext4",
+			},
+		},
+		{
+			name:         "Excluded keys",
+			volType:      VolumeTypeCustom,
+			config:       map[string]string{},
+			defaults:     map[string]string{"block.filesystem": "xfs", "snapshots.schedule": "@daily"},
+			excludedKeys: []string{"block.filesystem", "snapshots.schedule"},
+			expected: map[string]string{
+				"size":             "10GiB",
+				"snapshots.expiry": "1d",
+			},
+		},
+		{
+			name:     "No size for instance volumes",
+			volType:  VolumeTypeContainer,
+			config:   map[string]string{},
+			defaults: map[string]string{"size": "20GiB"},
+			expected: map[string]string{
+				"snapshots.expiry": "1d",
+				"block.filesystem": "ext4",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vol := Volume{volType: tt.volType, contentType: ContentTypeFS, config: tt.config}
+			vol.SetVolumeDefaults(tt.defaults)
+
+			err := d.fillVolumeConfig(&vol, tt.excludedKeys...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, vol.config)
+		})
+	}
+}
+
+func TestVolumeDefault(t *testing.T) {
+	d := &common{config: map[string]string{"volume.snapshots.expiry": "1d"}}
+
+	vol := Volume{volType: VolumeTypeCustom, contentType: ContentTypeFS}
+	assert.Equal(t, "1d", d.volumeDefault(vol, "snapshots.expiry"))
+	assert.Equal(t, "", d.volumeDefault(vol, "snapshots.schedule"))
+
+	vol.SetVolumeDefaults(map[string]string{"snapshots.expiry": "2d", "snapshots.schedule": "@daily"})
+	assert.Equal(t, "2d", d.volumeDefault(vol, "snapshots.expiry"))
+	assert.Equal(t, "@daily", d.volumeDefault(vol, "snapshots.schedule"))
+
+	// An empty project default unsets the pool default.
+	vol.SetVolumeDefaults(map[string]string{"snapshots.expiry": ""})
+	assert.Equal(t, "", d.volumeDefault(vol, "snapshots.expiry"))
+}
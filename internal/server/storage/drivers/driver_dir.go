@@ -47,6 +47,7 @@ func (d *dir) Info() Info {
 		DirectIO:                     true,
 		IOUring:                      true,
 		MountedRoot:                  true,
+		RunningRename:                true,
 		Buckets:                      true,
 	}
 }
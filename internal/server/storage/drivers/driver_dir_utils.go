@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/lxc/incus/v6/internal/revert"
 	"github.com/lxc/incus/v6/internal/server/storage/quota"
@@ -9,6 +10,10 @@ import (
 	"github.com/lxc/incus/v6/shared/units"
 )
 
+// dirQuotaReady records the paths of the volumes whose project quota is known to be set up, so that it's only
+// checked once.
+var dirQuotaReady sync.Map
+
 // withoutGetVolID returns a copy of this struct but with a volIDFunc which will cause quotas to be skipped.
 func (d *dir) withoutGetVolID() Driver {
 	newDriver := &dir{}
@@ -56,6 +61,76 @@ func (d *dir) setupInitialQuota(vol Volume) (revert.Hook, error) {
 	return revertFunc, nil
 }
 
+// ensureQuota sets up the project quota of a volume which doesn't have one yet. This is the case for volumes
+// created before project quotas were enabled on the backing filesystem or imported from a backup.
+func (d *dir) ensureQuota(vol Volume) error {
+	// The quota of VM volumes is set on their filesystem volume.
+	if vol.IsVMBlock() {
+		vol = vol.NewVMBlockFilesystemVolume()
+	}
+
+	if vol.IsSnapshot() || vol.Type() == VolumeTypeBucket {
+		return nil
+	}
+
+	volPath := vol.MountPath()
+	_, ready := dirQuotaReady.Load(volPath)
+	if ready {
+		return nil
+	}
+
+	ok, err := quota.Supported(volPath)
+	if err != nil || !ok {
+		// Skipping quota as underlying filesystem doesn't support project quotas.
+		dirQuotaReady.Store(volPath, true)
+		return nil
+	}
+
+	volID, err := d.getVolID(vol.volType, vol.name)
+	if err != nil {
+		return err
+	}
+
+	if volID == volIDQuotaSkip {
+		// Disabled on purpose, just ignore.
+		return nil
+	}
+
+	ready, err = dirQuotaCheck(volPath, d.quotaProjectID(volID), quota.GetProject)
+	if err != nil {
+		return err
+	}
+
+	if ready {
+		return nil
+	}
+
+	d.logger.Info("Setting up project quota of existing volume", logger.Ctx{"volName": vol.name, "path": volPath})
+
+	return d.SetVolumeQuota(vol, vol.ConfigSize(), false, nil)
+}
+
+// dirQuotaCheck returns whether the project quota of a volume path is set up, using getProject to read the
+// project of the path unless it's already known to be set up.
+func dirQuotaCheck(volPath string, projectID uint32, getProject func(path string) (uint32, error)) (bool, error) {
+	_, ready := dirQuotaReady.Load(volPath)
+	if ready {
+		return true, nil
+	}
+
+	currentProjectID, err := getProject(volPath)
+	if err != nil {
+		return false, err
+	}
+
+	if currentProjectID != projectID {
+		return false, nil
+	}
+
+	dirQuotaReady.Store(volPath, true)
+	return true, nil
+}
+
 // deleteQuota removes the project quota for a volID from a path.
 func (d *dir) deleteQuota(path string, volID int64) error {
 	if volID == volIDQuotaSkip {
@@ -78,6 +153,8 @@ func (d *dir) deleteQuota(path string, volID int64) error {
 		return err
 	}
 
+	dirQuotaReady.Delete(path)
+
 	return nil
 }
 
@@ -133,5 +210,12 @@ func (d *dir) setQuota(path string, volID int64, sizeBytes int64) error {
 	}
 
 	// Set the project quota size.
-	return quota.SetProjectQuota(path, projectID, sizeBytes)
+	err = quota.SetProjectQuota(path, projectID, sizeBytes)
+	if err != nil {
+		return err
+	}
+
+	dirQuotaReady.Store(path, true)
+
+	return nil
 }
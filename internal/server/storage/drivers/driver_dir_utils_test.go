@@ -0,0 +1,58 @@
+package drivers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test dirQuotaCheck.
+func TestDirQuotaCheck(t *testing.T) {
+	projects := map[string]uint32{}
+	calls := 0
+	getProject := func(path string) (uint32, error) {
+		calls++
+
+		projectID, ok := projects[path]
+		if !ok {
+			return 0, errors.New("Not found")
+		}
+
+		return projectID, nil
+	}
+
+	// Test a volume without project quota.
+	volPath := "/test/dir-quota-check/vol1"
+	t.Cleanup(func() { dirQuotaReady.Delete(volPath) })
+
+	projects[volPath] = 0
+	ready, err := dirQuotaCheck(volPath, 10001, getProject)
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	// Test that it's checked again as long as it isn't set up.
+	ready, err = dirQuotaCheck(volPath, 10001, getProject)
+	require.NoError(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, 2, calls)
+
+	// Test a volume whose project quota was set up meanwhile.
+	projects[volPath] = 10001
+	ready, err = dirQuotaCheck(volPath, 10001, getProject)
+	require.NoError(t, err)
+	assert.True(t, ready)
+	assert.Equal(t, 3, calls)
+
+	// Test that it's then only checked once.
+	ready, err = dirQuotaCheck(volPath, 10001, getProject)
+	require.NoError(t, err)
+	assert.True(t, ready)
+	assert.Equal(t, 3, calls)
+
+	// Test a failure reading the project of the path.
+	ready, err = dirQuotaCheck("/test/dir-quota-check/missing", 10002, getProject)
+	assert.Error(t, err)
+	assert.False(t, ready)
+}
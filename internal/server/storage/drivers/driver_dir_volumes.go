@@ -226,8 +226,7 @@ func (d *dir) FillVolumeConfig(vol Volume) error {
 		return err
 	}
 
-	// Buckets do not support default volume size.
-	// If size is specified manually, do not remove, so it triggers validation failure and an error to user.
+	// Buckets do not use the default volume size, their quota is only set when specified manually.
 	if vol.volType == VolumeTypeBucket && initialSize == "" {
 		delete(vol.config, "size")
 	}
@@ -237,16 +236,7 @@ func (d *dir) FillVolumeConfig(vol Volume) error {
 
 // ValidateVolume validates the supplied volume config. Optionally removes invalid keys from the volume's config.
 func (d *dir) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
-	err := d.validateVolume(vol, nil, removeUnknownKeys)
-	if err != nil {
-		return err
-	}
-
-	if vol.config["size"] != "" && vol.volType == VolumeTypeBucket {
-		return fmt.Errorf("Size cannot be specified for buckets")
-	}
-
-	return nil
+	return d.validateVolume(vol, nil, removeUnknownKeys)
 }
 
 // UpdateVolume applies config changes to the volume.
@@ -283,6 +273,16 @@ func (d *dir) GetVolumeUsage(vol Volume) (int64, error) {
 
 	projectID := d.quotaProjectID(volID)
 
+	// The usage of volumes whose project quota isn't set up yet isn't tracked.
+	ready, err := dirQuotaCheck(volPath, projectID, quota.GetProject)
+	if err != nil {
+		return -1, err
+	}
+
+	if !ready {
+		return -1, ErrNotSupported
+	}
+
 	// Get project quota used.
 	size, err := quota.GetProjectUsage(volPath, projectID)
 	if err != nil {
@@ -384,6 +384,12 @@ func (d *dir) MountVolume(vol Volume, op *operations.Operation) error {
 		}
 	}
 
+	// Make sure the volume usage is tracked and its size enforced.
+	err = d.ensureQuota(vol)
+	if err != nil {
+		d.logger.Warn("Failed setting up project quota", logger.Ctx{"volName": vol.name, "err": err})
+	}
+
 	vol.MountRefCountIncrement() // From here on it is up to caller to call UnmountVolume() when done.
 	return nil
 }
@@ -126,6 +126,7 @@ func (d *lvm) Info() Info {
 		DirectIO:                     true,
 		IOUring:                      true,
 		MountedRoot:                  false,
+		RunningRename:                true,
 		Buckets:                      true,
 	}
 }
@@ -291,12 +292,50 @@ func (d *lvm) Create() error {
 
 		d.config["lvm.vg_name"] = d.config["source"]
 
+		var remoteDevices []string
+
+		// Connect to the remote target backing the volume group.
+		if d.config["lvm.remote.protocol"] != "" {
+			err = d.connectRemoteTarget()
+			if err != nil {
+				return err
+			}
+
+			revert.Add(func() { _ = d.disconnectRemoteTarget(d.remotePortals(d.config["lvm.remote.addresses"])) })
+
+			// Wait for the devices of the target, so a volume group already on them is found.
+			remoteDevices, err = d.remoteTargetDevices()
+			if err != nil {
+				return err
+			}
+		}
+
 		// Check the volume group already exists.
 		vgExists, vgTags, err = d.volumeGroupExists(d.config["lvm.vg_name"])
 		if err != nil {
 			return err
 		}
 
+		// Create a shared volume group on the remote target if needed.
+		if !vgExists && d.config["lvm.remote.protocol"] != "" {
+			created, err := d.createRemoteVolumeGroup(remoteDevices)
+			if err != nil {
+				return err
+			}
+
+			if created {
+				revert.Add(func() {
+					_, _ = subprocess.TryRunCommand("vgremove", "-f", d.config["lvm.vg_name"])
+					_ = d.removeRemotePhysicalVolumes()
+				})
+			}
+
+			vgExists, vgTags, err = d.volumeGroupExists(d.config["lvm.vg_name"])
+			if err != nil {
+				return err
+			}
+		}
+
 		if !vgExists {
 			return fmt.Errorf("The requested volume group %q does not exist", d.config["lvm.vg_name"])
 		}
@@ -531,6 +570,22 @@ func (d *lvm) Delete(op *operations.Operation) error {
 		d.logger.Debug("Physical loop file removed", logger.Ctx{"file_name": d.config["source"]})
 	}
 
+	// If we have removed the volume group of a remote target, clean up its physical volumes too.
+	if removeVg && d.config["lvm.remote.protocol"] != "" {
+		err = d.removeRemotePhysicalVolumes()
+		if err != nil {
+			d.logger.Warn("Failed to destroy the physical volumes of the remote target of the lvm storage pool", logger.Ctx{"err": err})
+		}
+	}
+
+	// Disconnect from the remote target backing the volume group.
+	if d.config["lvm.remote.protocol"] != "" {
+		err = d.disconnectRemoteTarget(d.remotePortals(d.config["lvm.remote.addresses"]))
+		if err != nil {
+			d.logger.Warn("Failed to disconnect from the remote target of the lvm storage pool", logger.Ctx{"err": err})
+		}
+	}
+
 	// Wipe everything in the storage pool directory.
 	err = wipeDirectory(GetPoolMountPath(d.name))
 	if err != nil {
@@ -540,12 +595,46 @@ func (d *lvm) Delete(op *operations.Operation) error {
 	return nil
 }
 
+// Leave releases the storage pool on a cluster member other than the one deleting it.
+func (d *lvm) Leave() error {
+	if !d.clustered {
+		return nil
+	}
+
+	// The shared volume group can only be removed once its lock space is stopped on all the other members.
+	vgExists, _, err := d.volumeGroupExists(d.config["lvm.vg_name"])
+	if err != nil {
+		return err
+	}
+
+	if vgExists {
+		_, err = subprocess.TryRunCommand("vgchange", "--lockstop", d.config["lvm.vg_name"])
+		if err != nil {
+			return fmt.Errorf("Failed stopping the lock space of volume group %q: %w", d.config["lvm.vg_name"], err)
+		}
+	}
+
+	// Disconnect from the remote target backing the volume group.
+	if d.config["lvm.remote.protocol"] != "" {
+		err = d.disconnectRemoteTarget(d.remotePortals(d.config["lvm.remote.addresses"]))
+		if err != nil {
+			d.logger.Warn("Failed to disconnect from the remote target of the lvm storage pool", logger.Ctx{"err": err})
+		}
+	}
+
+	return nil
+}
+
 func (d *lvm) Validate(config map[string]string) error {
 	rules := map[string]func(value string) error{
 		"lvm.vg_name": validate.IsAny,
 	}
 
-	if !d.clustered {
+	if d.clustered {
+		rules["lvm.remote.addresses"] = validate.Optional(validate.IsListOf(validate.IsListenAddress(false, false, false)))
+		rules["lvm.remote.protocol"] = validate.Optional(validate.IsOneOf("iscsi", "nvme"))
+		rules["lvm.remote.target"] = validate.IsAny
+	} else {
 		rules["size"] = validate.Optional(validate.IsSize)
 		rules["lvm.thinpool_name"] = validate.IsAny
 		rules["lvm.thinpool_metadata_size"] = validate.Optional(validate.IsSize)
@@ -558,6 +647,10 @@ func (d *lvm) Validate(config map[string]string) error {
 		return err
 	}
 
+	if config["lvm.remote.protocol"] != "" && (config["lvm.remote.target"] == "" || config["lvm.remote.addresses"] == "") {
+		return fmt.Errorf("The keys lvm.remote.target and lvm.remote.addresses must be set when lvm.remote.protocol is set")
+	}
+
 	if util.IsFalse(config["lvm.use_thinpool"]) {
 		if config["lvm.thinpool_name"] != "" {
 			return fmt.Errorf("The key lvm.use_thinpool cannot be set to false when lvm.thinpool_name is set")
@@ -583,6 +676,41 @@ func (d *lvm) Update(changedConfig map[string]string) error {
 		return fmt.Errorf("lvm.thinpool_metadata_size cannot be changed")
 	}
 
+	_, changed = changedConfig["lvm.remote.protocol"]
+	if changed {
+		return fmt.Errorf("lvm.remote.protocol cannot be changed")
+	}
+
+	_, changed = changedConfig["lvm.remote.target"]
+	if changed {
+		return fmt.Errorf("lvm.remote.target cannot be changed")
+	}
+
+	newAddresses, changed := changedConfig["lvm.remote.addresses"]
+	if changed {
+		oldPortals := d.remotePortals(d.config["lvm.remote.addresses"])
+		newPortals := d.remotePortals(newAddresses)
+
+		// Connect the added paths before dropping the removed ones.
+		d.config["lvm.remote.addresses"] = newAddresses
+		err := d.connectRemoteTarget()
+		if err != nil {
+			return err
+		}
+
+		removedPortals := []string{}
+		for _, portal := range oldPortals {
+			if !slices.Contains(newPortals, portal) {
+				removedPortals = append(removedPortals, portal)
+			}
+		}
+
+		err = d.disconnectRemoteTarget(removedPortals)
+		if err != nil {
+			return err
+		}
+	}
+
 	_, changed = changedConfig["volume.lvm.stripes"]
 	if changed && d.usesThinpool() {
 		return fmt.Errorf("volume.lvm.stripes cannot be changed when using thin pool")
@@ -681,6 +809,21 @@ func (d *lvm) Mount() (bool, error) {
 	revert := revert.New()
 	defer revert.Fail()
 
+	// Connect to the remote target backing the volume group, so its physical volumes can be found.
+	if d.config["lvm.remote.protocol"] != "" {
+		err := d.connectRemoteTarget()
+		if err != nil {
+			return false, err
+		}
+
+		_, err = d.remoteTargetDevices()
+		if err != nil {
+			return false, err
+		}
+
+		vgExists, _, _ = d.volumeGroupExists(d.config["lvm.vg_name"])
+	}
+
 	// If clustered LVM, start lock manager.
 	if d.clustered {
 		_, err := subprocess.RunCommand("vgchange", "--lockstart")
@@ -746,6 +889,25 @@ func (d *lvm) Unmount() (bool, error) {
 	return false, nil
 }
 
+// CheckHealth checks that none of the physical volumes of the volume group are missing.
+func (d *lvm) CheckHealth() error {
+	output, err := subprocess.RunCommand("vgs", "--noheadings", "--options", "vg_missing_pv_count", d.config["lvm.vg_name"])
+	if err != nil {
+		return fmt.Errorf("Failed getting the state of volume group %q: %w", d.config["lvm.vg_name"], err)
+	}
+
+	missing, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return fmt.Errorf("Failed parsing the missing physical volume count of volume group %q: %w", d.config["lvm.vg_name"], err)
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("Volume group %q is missing %d physical volume(s)", d.config["lvm.vg_name"], missing)
+	}
+
+	return nil
+}
+
 // GetResources returns utilisation and space info about the pool.
 func (d *lvm) GetResources() (*api.ResourcesStoragePool, error) {
 	res := api.ResourcesStoragePool{}
@@ -31,7 +31,7 @@ func (d *lvm) patchStorageSkipActivation() error {
 
 		// Skip volumes that already have k flag set, meaning setactivationskip=y.
 		if strings.HasSuffix(volAttr, "k") {
-			logger.Infof("Skipping volume %q that already has skipactivation=y set in pool %q", volName, d.config["lvm.vg_name"])
+			d.logger.Info("Skipping volume that already has skipactivation=y set", logger.Ctx{"volume": volName, "vg": d.config["lvm.vg_name"]})
 			continue
 		}
 
@@ -41,7 +41,7 @@ func (d *lvm) patchStorageSkipActivation() error {
 			return fmt.Errorf("Error setting setactivationskip=y on LVM logical volume %q for storage pool %q: %w", volName, d.config["lvm.vg_name"], err)
 		}
 
-		logger.Infof("Set setactivationskip=y on volume %q in pool %q", volName, d.config["lvm.vg_name"])
+		d.logger.Info("Set setactivationskip=y on volume", logger.Ctx{"volume": volName, "vg": d.config["lvm.vg_name"]})
 	}
 
 	return nil
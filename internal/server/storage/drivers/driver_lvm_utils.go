@@ -3,10 +3,14 @@ package drivers
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -821,7 +825,7 @@ func (d *lvm) deactivateVolume(vol Volume) (bool, error) {
 				break
 			}
 
-			logger.Debug("Failed to deactivate LVM logical volume", logger.Ctx{"path": volDevPath, "attempt": i, "err": err})
+			d.logger.Debug("Failed to deactivate LVM logical volume", logger.Ctx{"path": volDevPath, "attempt": i, "err": err})
 			time.Sleep(500 * time.Millisecond)
 		}
 
@@ -835,3 +839,424 @@ func (d *lvm) deactivateVolume(vol Volume) (bool, error) {
 
 	return false, nil
 }
+
+// remotePortals returns the "host:port" portals of the remote block device target, adding the protocol's default
+// port to addresses which don't specify one.
+func (d *lvm) remotePortals(addresses string) []string {
+	defaultPort := "3260"
+	if d.config["lvm.remote.protocol"] == "nvme" {
+		defaultPort = "4420"
+	}
+
+	portals := []string{}
+	for _, address := range util.SplitNTrimSpace(addresses, ",", -1, true) {
+		_, _, err := net.SplitHostPort(address)
+		if err != nil {
+			address = net.JoinHostPort(strings.Trim(address, "[]"), defaultPort)
+		}
+
+		portals = append(portals, address)
+	}
+
+	return portals
+}
+
+// connectRemoteTarget connects to the remote block device target on all its portals.
+// Portals which are already connected are skipped.
+func (d *lvm) connectRemoteTarget() error {
+	portals := d.remotePortals(d.config["lvm.remote.addresses"])
+	if len(portals) == 0 {
+		return fmt.Errorf("No addresses set in %q", "lvm.remote.addresses")
+	}
+
+	tool := "iscsiadm"
+	if d.config["lvm.remote.protocol"] == "nvme" {
+		tool = "nvme"
+	}
+
+	_, err := exec.LookPath(tool)
+	if err != nil {
+		return fmt.Errorf("Required tool %q is missing", tool)
+	}
+
+	if d.config["lvm.remote.protocol"] == "nvme" {
+		// Multiple paths to the same namespace only show up as a single device with native NVMe multipath.
+		if len(portals) > 1 {
+			multipath, _ := os.ReadFile("/sys/module/nvme_core/parameters/multipath")
+			if strings.TrimSpace(string(multipath)) != "Y" {
+				return fmt.Errorf("Multiple NVMe addresses require native NVMe multipath to be enabled (nvme_core.multipath=Y)")
+			}
+		}
+
+		connected, err := d.nvmeControllers()
+		if err != nil {
+			return err
+		}
+
+		for _, portal := range portals {
+			if connected[portal] != "" {
+				continue
+			}
+
+			host, port, _ := net.SplitHostPort(portal)
+			_, err := subprocess.RunCommand("nvme", "connect", "--transport=tcp", "--traddr="+host, "--trsvcid="+port, "--nqn="+d.config["lvm.remote.target"])
+			if err != nil {
+				return fmt.Errorf("Failed connecting to NVMe target %q on %q: %w", d.config["lvm.remote.target"], portal, err)
+			}
+
+			d.logger.Debug("Connected to NVMe target", logger.Ctx{"target": d.config["lvm.remote.target"], "portal": portal})
+		}
+
+		return nil
+	}
+
+	// Paths to the same LUN must be merged by dm-multipath for LVM to use them.
+	if len(portals) > 1 {
+		_, err := exec.LookPath("multipathd")
+		if err != nil {
+			return fmt.Errorf("Multiple iSCSI addresses require multipathd to be running")
+		}
+	}
+
+	connected, err := d.iscsiSessions()
+	if err != nil {
+		return err
+	}
+
+	for _, portal := range portals {
+		if slices.Contains(connected, portal) {
+			continue
+		}
+
+		// Discover the target to create its node record.
+		_, err := subprocess.RunCommand("iscsiadm", "--mode", "discovery", "--type", "sendtargets", "--portal", portal)
+		if err != nil {
+			return fmt.Errorf("Failed discovering iSCSI targets on %q: %w", portal, err)
+		}
+
+		_, err = subprocess.RunCommand("iscsiadm", "--mode", "node", "--targetname", d.config["lvm.remote.target"], "--portal", portal, "--login")
+		if err != nil {
+			return fmt.Errorf("Failed logging into iSCSI target %q on %q: %w", d.config["lvm.remote.target"], portal, err)
+		}
+
+		d.logger.Debug("Logged into iSCSI target", logger.Ctx{"target": d.config["lvm.remote.target"], "portal": portal})
+	}
+
+	return nil
+}
+
+// disconnectRemoteTarget disconnects from the remote block device target on the given portals.
+func (d *lvm) disconnectRemoteTarget(portals []string) error {
+	if d.config["lvm.remote.protocol"] == "nvme" {
+		connected, err := d.nvmeControllers()
+		if err != nil {
+			return err
+		}
+
+		for _, portal := range portals {
+			if connected[portal] == "" {
+				continue
+			}
+
+			_, err := subprocess.RunCommand("nvme", "disconnect", "--device="+connected[portal])
+			if err != nil {
+				return fmt.Errorf("Failed disconnecting from NVMe target %q on %q: %w", d.config["lvm.remote.target"], portal, err)
+			}
+
+			d.logger.Debug("Disconnected from NVMe target", logger.Ctx{"target": d.config["lvm.remote.target"], "portal": portal})
+		}
+
+		return nil
+	}
+
+	connected, err := d.iscsiSessions()
+	if err != nil {
+		return err
+	}
+
+	for _, portal := range portals {
+		if !slices.Contains(connected, portal) {
+			continue
+		}
+
+		_, err := subprocess.RunCommand("iscsiadm", "--mode", "node", "--targetname", d.config["lvm.remote.target"], "--portal", portal, "--logout")
+		if err != nil {
+			return fmt.Errorf("Failed logging out of iSCSI target %q on %q: %w", d.config["lvm.remote.target"], portal, err)
+		}
+
+		d.logger.Debug("Logged out of iSCSI target", logger.Ctx{"target": d.config["lvm.remote.target"], "portal": portal})
+	}
+
+	return nil
+}
+
+// nvmeControllers returns the names of the NVMe controllers connected to the target, keyed by portal.
+func (d *lvm) nvmeControllers() (map[string]string, error) {
+	controllers := map[string]string{}
+
+	entries, err := os.ReadDir("/sys/class/nvme")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return controllers, nil
+		}
+
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join("/sys/class/nvme", entry.Name())
+
+		nqn, err := os.ReadFile(filepath.Join(path, "subsysnqn"))
+		if err != nil || strings.TrimSpace(string(nqn)) != d.config["lvm.remote.target"] {
+			continue
+		}
+
+		address, err := os.ReadFile(filepath.Join(path, "address"))
+		if err != nil {
+			continue
+		}
+
+		var host, port string
+		for _, field := range strings.Split(strings.TrimSpace(string(address)), ",") {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "traddr":
+				host = value
+			case "trsvcid":
+				port = value
+			}
+		}
+
+		controllers[net.JoinHostPort(host, port)] = entry.Name()
+	}
+
+	return controllers, nil
+}
+
+// iscsiSessions returns the portals with an active session to the target.
+func (d *lvm) iscsiSessions() ([]string, error) {
+	output, err := subprocess.RunCommand("iscsiadm", "--mode", "session")
+	if err != nil {
+		runError, ok := err.(subprocess.RunError)
+		if ok {
+			exitError, ok := runError.Unwrap().(*exec.ExitError)
+			if ok && exitError.ExitCode() == 21 {
+				// ISCSI_ERR_NO_OBJS_FOUND (no active sessions).
+				return nil, nil
+			}
+		}
+
+		return nil, err
+	}
+
+	return parseISCSISessions(output, d.config["lvm.remote.target"]), nil
+}
+
+// parseISCSISessions returns the portals of the sessions to the target listed in "iscsiadm --mode session" output.
+func parseISCSISessions(output string, target string) []string {
+	portals := []string{}
+
+	for _, line := range strings.Split(output, "\n") {
+		// Lines look like "tcp: [1] 192.0.2.10:3260,1 iqn.2024-01.org.example:target (non-flash)".
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[3] != target {
+			continue
+		}
+
+		portal, _, _ := strings.Cut(fields[2], ",")
+		portals = append(portals, portal)
+	}
+
+	return portals
+}
+
+// remoteTargetDevices returns the block devices of the namespaces or LUNs exported by the remote target.
+// It waits for the devices to show up after connecting to the target.
+func (d *lvm) remoteTargetDevices() ([]string, error) {
+	var devices []string
+
+	// The devices of a freshly connected target show up one at a time, so wait for the list to settle.
+	for i := 0; i < 20; i++ {
+		_, _ = subprocess.RunCommand("udevadm", "settle")
+
+		var found []string
+		var err error
+
+		if d.config["lvm.remote.protocol"] == "nvme" {
+			found, err = d.nvmeDevices()
+		} else {
+			found, err = d.iscsiDevices()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(found) > 0 && slices.Equal(found, devices) {
+			return devices, nil
+		}
+
+		devices = found
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	if len(devices) > 0 {
+		return devices, nil
+	}
+
+	return nil, fmt.Errorf("No block devices found for remote target %q", d.config["lvm.remote.target"])
+}
+
+// nvmeDevices returns the block devices of the namespaces exported by the NVMe target.
+func (d *lvm) nvmeDevices() ([]string, error) {
+	namespaceRegex := regexp.MustCompile(`^nvme[0-9]+n[0-9]+$`)
+
+	controllers, err := d.nvmeControllers()
+	if err != nil {
+		return nil, err
+	}
+
+	// With native multipath the namespaces are listed on the subsystem, otherwise on the controller.
+	dirs := []string{}
+	for _, controller := range controllers {
+		dirs = append(dirs, filepath.Join("/sys/class/nvme", controller), filepath.Join("/sys/class/nvme", controller, "subsystem"))
+	}
+
+	devices := []string{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if !namespaceRegex.MatchString(entry.Name()) {
+				continue
+			}
+
+			devPath := filepath.Join("/dev", entry.Name())
+			if !slices.Contains(devices, devPath) && linux.IsBlockdevPath(devPath) {
+				devices = append(devices, devPath)
+			}
+		}
+	}
+
+	sort.Strings(devices)
+
+	return devices, nil
+}
+
+// iscsiDevices returns the block devices of the LUNs exported by the iSCSI target.
+// LUNs reachable over multiple portals are returned as their dm-multipath device.
+func (d *lvm) iscsiDevices() ([]string, error) {
+	portals := d.remotePortals(d.config["lvm.remote.addresses"])
+
+	entries, err := os.ReadDir("/dev/disk/by-path")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	devices := []string{}
+	for _, portal := range portals {
+		prefix := fmt.Sprintf("ip-%s-iscsi-%s-lun-", portal, d.config["lvm.remote.target"])
+
+		for _, entry := range entries {
+			// Skip the partitions of the LUNs.
+			if !strings.HasPrefix(entry.Name(), prefix) || strings.Contains(entry.Name(), "-part") {
+				continue
+			}
+
+			devPath, err := filepath.EvalSymlinks(filepath.Join("/dev/disk/by-path", entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			if len(portals) > 1 {
+				holders, err := filepath.Glob(filepath.Join("/sys/block", filepath.Base(devPath), "holders", "dm-*"))
+				if err != nil {
+					return nil, err
+				}
+
+				if len(holders) == 0 {
+					return nil, fmt.Errorf("No multipath device found for %q, check that multipathd is running", devPath)
+				}
+
+				devPath = filepath.Join("/dev", filepath.Base(holders[0]))
+			}
+
+			if !slices.Contains(devices, devPath) {
+				devices = append(devices, devPath)
+			}
+		}
+	}
+
+	sort.Strings(devices)
+
+	return devices, nil
+}
+
+// createRemoteVolumeGroup creates a shared volume group spanning the given block devices of the remote target.
+// Returns false if the volume group was concurrently created by another server rather than by us.
+func (d *lvm) createRemoteVolumeGroup(devices []string) (bool, error) {
+	// Check all the devices before changing any of them.
+	for _, devPath := range devices {
+		pvExists, err := d.pysicalVolumeExists(devPath)
+		if err != nil {
+			return false, err
+		}
+
+		if pvExists {
+			return false, fmt.Errorf("A physical volume already exists for %q", devPath)
+		}
+	}
+
+	// Let vgcreate initialize the physical volumes, this way the whole creation happens under the LVM global
+	// lock and can't interleave with another server doing the same.
+	args := append([]string{"--shared", d.config["lvm.vg_name"]}, devices...)
+	_, err := subprocess.TryRunCommand("vgcreate", args...)
+	if err != nil {
+		vgExists, _, vgErr := d.volumeGroupExists(d.config["lvm.vg_name"])
+		if vgErr == nil && vgExists {
+			d.logger.Debug("Shared volume group created concurrently", logger.Ctx{"vg_name": d.config["lvm.vg_name"]})
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	d.logger.Debug("Shared volume group created", logger.Ctx{"pv_names": devices, "vg_name": d.config["lvm.vg_name"]})
+
+	return true, nil
+}
+
+// removeRemotePhysicalVolumes wipes the physical volume labels from the block devices of the remote target.
+func (d *lvm) removeRemotePhysicalVolumes() error {
+	devices, err := d.remoteTargetDevices()
+	if err != nil {
+		return err
+	}
+
+	for _, devPath := range devices {
+		pvExists, err := d.pysicalVolumeExists(devPath)
+		if err != nil {
+			return err
+		}
+
+		if !pvExists {
+			continue
+		}
+
+		_, err = subprocess.TryRunCommand("pvremove", "-f", devPath)
+		if err != nil {
+			return err
+		}
+
+		d.logger.Debug("Physical volume removed", logger.Ctx{"pv_name": devPath})
+	}
+
+	return nil
+}
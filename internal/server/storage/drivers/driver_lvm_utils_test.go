@@ -66,3 +66,19 @@ func Example_lvm_parseLogicalVolumeName() {
 	// custom_proj_testvol--with--hyphens.block: Unrecognised
 	// custom_proj_testvol--with--hyphens.block-snap1--with--hyphens.block: snap1-with-hyphens.block
 }
+
+func Example_parseISCSISessions() {
+	output := `tcp: [1] 192.0.2.10:3260,1 iqn.2024-01.org.example:incus (non-flash)
+tcp: [2] 192.0.2.11:3260,1 iqn.2024-01.org.example:incus (non-flash)
+tcp: [3] 192.0.2.10:3260,1 iqn.2024-01.org.example:other (non-flash)
+tcp: [4] [2001:db8::10]:3260,1 iqn.2024-01.org.example:incus (non-flash)
+`
+
+	for _, portal := range parseISCSISessions(output, "iqn.2024-01.org.example:incus") {
+		fmt.Println(portal)
+	}
+
+	// Output: 192.0.2.10:3260
+	// 192.0.2.11:3260
+	// [2001:db8::10]:3260
+}
@@ -125,6 +125,12 @@ func (d *lvm) CreateVolumeFromBackup(vol Volume, srcBackup backup.Info, srcData
 	return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcData, op)
 }
 
+// CanCloneVolume checks whether CreateVolumeFromCopy clones the volume rather than copying its data.
+// Only thin pools allow volumes to be cloned through thin snapshots.
+func (d *lvm) CanCloneVolume(srcVol Volume, copySnapshots bool) bool {
+	return d.usesThinpool()
+}
+
 // CreateVolumeFromCopy provides same-pool volume copying functionality.
 func (d *lvm) CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots bool, allowInconsistent bool, op *operations.Operation) error {
 	var err error
@@ -296,7 +302,7 @@ func (d *lvm) FillVolumeConfig(vol Volume) error {
 	if vol.ContentType() == ContentTypeFS || vol.IsVMBlock() {
 		// Inherit filesystem from pool if not set.
 		if vol.config["block.filesystem"] == "" {
-			vol.config["block.filesystem"] = d.config["volume.block.filesystem"]
+			vol.config["block.filesystem"] = d.volumeDefault(vol, "block.filesystem")
 		}
 
 		// Default filesystem if neither volume nor pool specify an override.
@@ -307,7 +313,7 @@ func (d *lvm) FillVolumeConfig(vol Volume) error {
 
 		// Inherit filesystem mount options from pool if not set.
 		if vol.config["block.mount_options"] == "" {
-			vol.config["block.mount_options"] = d.config["volume.block.mount_options"]
+			vol.config["block.mount_options"] = d.volumeDefault(vol, "block.mount_options")
 		}
 
 		// Default filesystem mount options if neither volume nor pool specify an override.
@@ -320,7 +326,7 @@ func (d *lvm) FillVolumeConfig(vol Volume) error {
 	// Inherit stripe settings from pool if not set and not using thin pool.
 	if !d.usesThinpool() {
 		if vol.config["lvm.stripes"] == "" {
-			vol.config["lvm.stripes"] = d.config["volume.lvm.stripes"]
+			vol.config["lvm.stripes"] = d.volumeDefault(vol, "lvm.stripes")
 		}
 
 		if vol.config["lvm.stripes.size"] == "" {
@@ -416,6 +422,22 @@ func (d *lvm) GetVolumeUsage(vol Volume) (int64, error) {
 	return -1, ErrNotSupported
 }
 
+// GetVolumeIOStats returns the IO counters of the volume.
+func (d *lvm) GetVolumeIOStats(vol Volume) (*api.StorageVolumeStateIO, error) {
+	// Snapshot IO counters not supported for LVM.
+	if vol.IsSnapshot() {
+		return nil, ErrNotSupported
+	}
+
+	// Inactive logical volumes don't have a device, so there are no counters to report.
+	volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
+	if !util.PathExists(volDevPath) {
+		return nil, ErrNotSupported
+	}
+
+	return blockDeviceIOStats(volDevPath)
+}
+
 // SetVolumeQuota applies a size limit on volume.
 // Does nothing if supplied with an empty/zero size.
 func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
@@ -794,7 +816,7 @@ func (d *lvm) UnmountVolume(vol Volume, keepBlockDev bool, op *operations.Operat
 func (d *lvm) RenameVolume(vol Volume, newVolName string, op *operations.Operation) error {
 	volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
 
-	return vol.UnmountTask(func(op *operations.Operation) error {
+	rename := func(op *operations.Operation) error {
 		snapNames, err := d.VolumeSnapshots(vol, op)
 		if err != nil {
 			return err
@@ -844,12 +866,12 @@ func (d *lvm) RenameVolume(vol Volume, newVolName string, op *operations.Operati
 		if vol.contentType == ContentTypeFS {
 			srcVolumePath := GetVolumeMountPath(d.name, vol.volType, vol.name)
 			dstVolumePath := GetVolumeMountPath(d.name, vol.volType, newVolName)
-			err = os.Rename(srcVolumePath, dstVolumePath)
+			err = renameVolumePath(srcVolumePath, dstVolumePath)
 			if err != nil {
 				return fmt.Errorf("Error renaming LVM logical volume mount path from %q to %q: %w", srcVolumePath, dstVolumePath, err)
 			}
 
-			revert.Add(func() { _ = os.Rename(dstVolumePath, srcVolumePath) })
+			revert.Add(func() { _ = renameVolumePath(dstVolumePath, srcVolumePath) })
 		}
 
 		// For VMs, also rename the filesystem volume.
@@ -861,9 +883,19 @@ func (d *lvm) RenameVolume(vol Volume, newVolName string, op *operations.Operati
 			}
 		}
 
+		// Volumes in use keep being tracked under their new name.
+		vol.mountRefCountRename(newVolName)
+
 		revert.Success()
 		return nil
-	}, false, op)
+	}
+
+	// Active logical volumes can be renamed, so leave the volumes in use mounted.
+	if vol.MountInUse() {
+		return rename(op)
+	}
+
+	return vol.UnmountTask(rename, false, op)
 }
 
 // MigrateVolume sends a volume for migration.
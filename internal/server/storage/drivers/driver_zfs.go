@@ -142,6 +142,7 @@ func (d *zfs) Info() Info {
 		RunningCopyFreeze:            false,
 		DirectIO:                     zfsDirectIO,
 		MountedRoot:                  false,
+		RunningRename:                true,
 		Buckets:                      true,
 	}
 
@@ -620,6 +621,24 @@ func (d *zfs) Unmount() (bool, error) {
 	return true, nil
 }
 
+// CheckHealth checks that the zpool backing the storage pool is online.
+func (d *zfs) CheckHealth() error {
+	poolName := strings.Split(d.config["zfs.pool_name"], "/")[0]
+
+	output, err := subprocess.RunCommand("zpool", "list", "-H", "-o", "health", poolName)
+	if err != nil {
+		return fmt.Errorf("Failed getting the health of ZFS pool %q: %w", poolName, err)
+	}
+
+	// Degraded pools still have enough devices to serve all data.
+	health := strings.TrimSpace(output)
+	if health != "ONLINE" && health != "DEGRADED" {
+		return fmt.Errorf("ZFS pool %q is %s", poolName, strings.ToLower(health))
+	}
+
+	return nil
+}
+
 func (d *zfs) GetResources() (*api.ResourcesStoragePool, error) {
 	// Get the total amount of space.
 	availableStr, err := d.getDatasetProperty(d.config["zfs.pool_name"], "available")
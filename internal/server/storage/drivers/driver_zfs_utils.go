@@ -2,13 +2,19 @@ package drivers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/google/uuid"
 
@@ -35,8 +41,104 @@ const (
 
 	// zfsMaxVolBlocksize is a maximum value for volblocksize property.
 	zfsMaxVolBlocksize = 128 * 1024
+
+	// zfsTransferSnapshotPrefix is the prefix of the snapshots used as the base of the transfers to remote hosts.
+	zfsTransferSnapshotPrefix = "@transfer-"
 )
 
+// zfsTransferDatasetRegex matches the dataset names accepted in a transfer target.
+var zfsTransferDatasetRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:/-]*$`)
+
+// zfsTransferTarget represents a dataset on a remote host reachable over SSH.
+type zfsTransferTarget struct {
+	user    string
+	host    string
+	port    string
+	dataset string
+}
+
+// parseZFSTransferTarget parses a transfer target of the form zfs://[user@]host[:port]/dataset.
+func parseZFSTransferTarget(value string) (*zfsTransferTarget, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid transfer target: %w", err)
+	}
+
+	if u.Scheme != "zfs" {
+		return nil, fmt.Errorf("Transfer target must be of the form zfs://[user@]host[:port]/dataset")
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("Transfer target is missing the host")
+	}
+
+	// The user and host are passed to ssh, they mustn't be mistaken for options.
+	for _, field := range []string{u.User.Username(), u.Hostname()} {
+		if strings.HasPrefix(field, "-") || strings.ContainsFunc(field, unicode.IsSpace) {
+			return nil, fmt.Errorf("Invalid user or host %q in transfer target", field)
+		}
+	}
+
+	if u.Port() != "" {
+		_, err := strconv.ParseUint(u.Port(), 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid port %q in transfer target", u.Port())
+		}
+	}
+
+	dataset := strings.Trim(u.Path, "/")
+	if !zfsTransferDatasetRegex.MatchString(dataset) {
+		return nil, fmt.Errorf("Invalid dataset %q in transfer target", dataset)
+	}
+
+	return &zfsTransferTarget{
+		user:    u.User.Username(),
+		host:    u.Hostname(),
+		port:    u.Port(),
+		dataset: dataset,
+	}, nil
+}
+
+// validateZFSTransferTarget validates a transfer target.
+func validateZFSTransferTarget(value string) error {
+	_, err := parseZFSTransferTarget(value)
+
+	return err
+}
+
+// sshArgs returns the ssh arguments to run the command on the target host.
+func (t *zfsTransferTarget) sshArgs(command ...string) []string {
+	args := []string{"-o", "BatchMode=yes"}
+	if t.port != "" {
+		args = append(args, "-p", t.port)
+	}
+
+	if t.user != "" {
+		args = append(args, "-l", t.user)
+	}
+
+	args = append(args, "--", t.host)
+
+	return append(args, command...)
+}
+
+// zfsTransferSnapshotMissing returns whether a remote "zfs list" failed because the dataset doesn't exist, as
+// opposed to ssh or the remote host failing.
+func zfsTransferSnapshotMissing(err error) bool {
+	// ssh exits with 255 on its own failures, zfs exits with 1.
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		return false
+	}
+
+	var runErr subprocess.RunError
+	if !errors.As(err, &runErr) {
+		return false
+	}
+
+	return strings.Contains(runErr.StdErr().String(), "dataset does not exist")
+}
+
 func (d *zfs) dataset(vol Volume, deleted bool) string {
 	name, snapName, _ := api.GetParentAndSnapshotName(vol.name)
 
@@ -247,6 +349,74 @@ func (d *zfs) getDatasetProperty(dataset string, key string) (string, error) {
 	return strings.TrimSpace(output), nil
 }
 
+// getDatasetIOStats returns the IO counters of a dataset from its objset kstat.
+func (d *zfs) getDatasetIOStats(dataset string) (*api.StorageVolumeStateIO, error) {
+	objsetID, err := d.getDatasetProperty(dataset, "objsetid")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseUint(objsetID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing objsetid of %q: %w", dataset, err)
+	}
+
+	poolName, _, _ := strings.Cut(dataset, "/")
+
+	content, err := os.ReadFile(fmt.Sprintf("/proc/spl/kstat/zfs/%s/objset-0x%x", poolName, id))
+	if err != nil {
+		// Per-dataset kstats were only added in OpenZFS 0.8.
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotSupported
+		}
+
+		return nil, err
+	}
+
+	return parseZFSObjsetStat(string(content))
+}
+
+// parseZFSObjsetStat parses the content of a /proc/spl/kstat/zfs/<pool>/objset-<id> file.
+func parseZFSObjsetStat(content string) (*api.StorageVolumeStateIO, error) {
+	stats := api.StorageVolumeStateIO{}
+	found := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+
+		var target *uint64
+		switch fields[0] {
+		case "reads":
+			target = &stats.ReadOps
+		case "nread":
+			target = &stats.ReadBytes
+		case "writes":
+			target = &stats.WriteOps
+		case "nwritten":
+			target = &stats.WriteBytes
+		default:
+			continue
+		}
+
+		value, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing objset stat %q: %w", fields[0], err)
+		}
+
+		*target = value
+		found++
+	}
+
+	if found != 4 {
+		return nil, fmt.Errorf("Unexpected objset stat format")
+	}
+
+	return &stats, nil
+}
+
 func (d *zfs) getDatasetProperties(dataset string, keys ...string) (map[string]string, error) {
 	output, err := subprocess.RunCommand("zfs", "get", "-H", "-p", "-o", "property,value", strings.Join(keys, ","), dataset)
 	if err != nil {
@@ -411,6 +581,77 @@ func (d *zfs) receiveDataset(vol Volume, r io.Reader, tracker *ioprogress.Progre
 	return nil
 }
 
+// sendDatasetToTarget sends a snapshot of the dataset to the target host over SSH, as an incremental
+// stream from parent if specified. The stream goes through mbuffer when it is available.
+func (d *zfs) sendDatasetToTarget(dataset string, parent string, snapshot string, target *zfsTransferTarget, tracker *ioprogress.ProgressTracker) error {
+	args := []string{"send"}
+	if parent != "" {
+		args = append(args, "-i", parent)
+	}
+
+	args = append(args, fmt.Sprintf("%s%s", dataset, snapshot))
+
+	cmds := []*exec.Cmd{exec.Command("zfs", args...)}
+
+	_, err := exec.LookPath("mbuffer")
+	if err == nil {
+		cmds = append(cmds, exec.Command("mbuffer", "-q", "-m", "128M"))
+	}
+
+	cmds = append(cmds, exec.Command("ssh", target.sshArgs("zfs", "receive", "-F", "-u", target.dataset)...))
+
+	// Chain the commands together.
+	stderrs := make([]strings.Builder, len(cmds))
+	for i, cmd := range cmds {
+		cmd.Stderr = &stderrs[i]
+
+		if i == 0 {
+			continue
+		}
+
+		stdout, err := cmds[i-1].StdoutPipe()
+		if err != nil {
+			return err
+		}
+
+		cmd.Stdin = stdout
+		if i == 1 && tracker != nil {
+			cmd.Stdin = &ioprogress.ProgressReader{
+				ReadCloser: stdout,
+				Tracker:    tracker,
+			}
+		}
+	}
+
+	for i, cmd := range cmds {
+		err := cmd.Start()
+		if err != nil {
+			for _, started := range cmds[:i] {
+				_ = started.Process.Kill()
+				_ = started.Wait()
+			}
+
+			return err
+		}
+	}
+
+	// Wait for the receiving end first, stopping the rest of the pipeline if it fails.
+	var pipelineErr error
+	for i := len(cmds) - 1; i >= 0; i-- {
+		cmd := cmds[i]
+		if pipelineErr != nil {
+			_ = cmd.Process.Kill()
+		}
+
+		err := cmd.Wait()
+		if err != nil && pipelineErr == nil {
+			pipelineErr = fmt.Errorf("%s failed: %w (%s)", filepath.Base(cmd.Path), err, strings.TrimSpace(stderrs[i].String()))
+		}
+	}
+
+	return pipelineErr
+}
+
 // ValidateZfsBlocksize validates blocksize property value on the pool.
 func ValidateZfsBlocksize(value string) error {
 	// Convert to bytes.
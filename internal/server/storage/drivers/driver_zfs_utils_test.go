@@ -0,0 +1,157 @@
+package drivers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+func Test_zfs_parseZFSTransferTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			"Host only",
+			"zfs://backup01/tank/backup/data",
+			[]string{"-o", "BatchMode=yes", "--", "backup01", "zfs", "list"},
+			false,
+		},
+		{
+			"User and port",
+			"zfs://root@backup01:2222/tank/backup/data",
+			[]string{"-o", "BatchMode=yes", "-p", "2222", "-l", "root", "--", "backup01", "zfs", "list"},
+			false,
+		},
+		{
+			"Host looking like an option",
+			"zfs://-oProxyCommand=touch%20%2Ftmp%2Fpwned/tank/data",
+			nil,
+			true,
+		},
+		{
+			"User looking like an option",
+			"zfs://-oProxyCommand=id@backup01/tank/data",
+			nil,
+			true,
+		},
+		{
+			"User with whitespace",
+			"zfs://root%20-oProxyCommand=id@backup01/tank/data",
+			nil,
+			true,
+		},
+		{
+			"Invalid port",
+			"zfs://backup01:-1/tank/data",
+			nil,
+			true,
+		},
+		{
+			"Wrong scheme",
+			"ssh://backup01/tank/data",
+			nil,
+			true,
+		},
+		{
+			"Missing host",
+			"zfs:///tank/data",
+			nil,
+			true,
+		},
+		{
+			"Dataset looking like an option",
+			"zfs://backup01/-o",
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := parseZFSTransferTarget(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseZFSTransferTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			got := target.sshArgs("zfs", "list")
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sshArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_zfsTransferSnapshotMissing(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		missing bool
+	}{
+		{"Missing snapshot", "echo \"cannot open 'tank/data@transfer-1': dataset does not exist\" >&2; exit 1", true},
+		{"SSH failure", "echo 'ssh: connect to host backup01 port 22: Connection timed out' >&2; exit 255", false},
+		{"Other zfs failure", "echo 'internal error: out of memory' >&2; exit 1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := subprocess.RunCommand("sh", "-c", tt.script)
+			require.Error(t, err)
+			assert.Equal(t, tt.missing, zfsTransferSnapshotMissing(err))
+		})
+	}
+}
+
+func Test_zfsRestoreCopy(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	// Block volumes get the content of the snapshot device.
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "snapshot.img")
+	targetPath := filepath.Join(dir, "volume.img")
+	require.NoError(t, os.WriteFile(srcPath, []byte("snapshot"), 0o600))
+	require.NoError(t, os.WriteFile(targetPath, []byte("volume content"), 0o600))
+
+	vol := NewVolume(nil, "pool1", VolumeTypeCustom, ContentTypeBlock, "vol1", nil, nil)
+	require.NoError(t, zfsRestoreCopy(vol, srcPath, targetPath, "", logger.Log))
+
+	content, err := os.ReadFile(targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot", string(content))
+
+	_, err = exec.LookPath("rsync")
+	if err != nil {
+		t.Skip("rsync is missing")
+	}
+
+	// Filesystem volumes get the content of the snapshot, files created since are removed.
+	vol = NewVolume(nil, "pool1", VolumeTypeCustom, ContentTypeFS, "vol1", nil, nil)
+	require.NoError(t, os.MkdirAll(vol.MountPath(), 0o711))
+	require.NoError(t, os.WriteFile(filepath.Join(vol.MountPath(), "new"), []byte("new"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(vol.MountPath(), "file"), []byte("changed"), 0o600))
+
+	snapPath := filepath.Join(dir, "snapshot")
+	require.NoError(t, os.Mkdir(snapPath, 0o711))
+	require.NoError(t, os.WriteFile(filepath.Join(snapPath, "file"), []byte("original"), 0o600))
+
+	require.NoError(t, zfsRestoreCopy(vol, snapPath, vol.MountPath(), "", logger.Log))
+
+	content, err = os.ReadFile(filepath.Join(vol.MountPath(), "file"))
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+	assert.NoFileExists(t, filepath.Join(vol.MountPath(), "new"))
+}
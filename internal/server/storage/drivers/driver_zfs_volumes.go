@@ -23,6 +23,7 @@ import (
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/migration"
 	"github.com/lxc/incus/v6/internal/revert"
+	"github.com/lxc/incus/v6/internal/rsync"
 	"github.com/lxc/incus/v6/internal/server/backup"
 	localMigration "github.com/lxc/incus/v6/internal/server/migration"
 	"github.com/lxc/incus/v6/internal/server/operations"
@@ -550,6 +551,12 @@ func (d *zfs) CreateVolumeFromBackup(vol Volume, srcBackup backup.Info, srcData
 	return postHook, cleanup, nil
 }
 
+// CanCloneVolume checks whether CreateVolumeFromCopy clones the volume rather than copying its data.
+// Volumes copied along with their snapshots are sent in full.
+func (d *zfs) CanCloneVolume(srcVol Volume, copySnapshots bool) bool {
+	return !util.IsFalse(d.config["zfs.clone_copy"]) && !copySnapshots
+}
+
 // CreateVolumeFromCopy provides same-pool volume copying functionality.
 func (d *zfs) CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots bool, allowInconsistent bool, op *operations.Operation) error {
 	var err error
@@ -1553,6 +1560,12 @@ func (d *zfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 		delete(commonRules, "block.mount_options")
 	}
 
+	// Scheduled transfers to remote hosts are only available for custom volumes.
+	if vol.volType == VolumeTypeCustom {
+		commonRules["zfs.transfer.target"] = validate.Optional(validateZFSTransferTarget)
+		commonRules["zfs.transfer.schedule"] = validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"}))
+	}
+
 	return d.validateVolume(vol, commonRules, removeUnknownKeys)
 }
 
@@ -1637,6 +1650,15 @@ func (d *zfs) GetVolumeUsage(vol Volume) (int64, error) {
 	return valueInt, nil
 }
 
+// GetVolumeIOStats returns the IO counters of the volume.
+func (d *zfs) GetVolumeIOStats(vol Volume) (*api.StorageVolumeStateIO, error) {
+	if vol.IsSnapshot() {
+		return nil, ErrNotSupported
+	}
+
+	return d.getDatasetIOStats(d.dataset(vol, false))
+}
+
 // SetVolumeQuota sets the quota/reservation on the volume.
 // Does nothing if supplied with an empty/zero size for block volumes.
 func (d *zfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
@@ -3236,6 +3258,7 @@ func (d *zfs) restoreVolume(vol Volume, snapshotName string, migration bool, op
 	// Check if more recent snapshots exist.
 	idx := -1
 	snapshots := []string{}
+	transferSnapshots := []string{}
 	for i, entry := range entries {
 		if entry == fmt.Sprintf("@snapshot-%s", snapshotName) {
 			// Located the current snapshot.
@@ -3252,6 +3275,12 @@ func (d *zfs) restoreVolume(vol Volume, snapshotName string, migration bool, op
 			continue
 		}
 
+		if strings.HasPrefix(entry, zfsTransferSnapshotPrefix) {
+			// Located the base of the next transfer.
+			transferSnapshots = append(transferSnapshots, entry)
+			continue
+		}
+
 		if strings.HasPrefix(entry, "@") {
 			// Located an internal snapshot.
 			return fmt.Errorf("Snapshot %q cannot be restored due to subsequent internal snapshot(s) (from a copy)", snapshotName)
@@ -3270,7 +3299,34 @@ func (d *zfs) restoreVolume(vol Volume, snapshotName string, migration bool, op
 		return err
 	}
 
-	// Restore the snapshot.
+	if len(transferSnapshots) > 0 {
+		// Rolling back would destroy the snapshot of the last transfer, so copy the snapshot over the
+		// volume instead to keep the next transfer incremental.
+		err = d.restoreVolumeCopy(vol, snapshotName, op)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = d.restoreVolumeRollback(vol, snapshotName)
+		if err != nil {
+			return err
+		}
+	}
+
+	// For VM images, restore the associated filesystem dataset too.
+	if !migration && vol.IsVMBlock() {
+		fsVol := vol.NewVMBlockFilesystemVolume()
+		err := d.restoreVolume(fsVol, snapshotName, migration, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreVolumeRollback restores a volume by rolling it back to the snapshot.
+func (d *zfs) restoreVolumeRollback(vol Volume, snapshotName string) error {
 	datasets, err := d.getDatasets(d.dataset(vol, false), "snapshot")
 	if err != nil {
 		return err
@@ -3307,16 +3363,58 @@ func (d *zfs) restoreVolume(vol Volume, snapshotName string, migration bool, op
 		}
 	}
 
-	// For VM images, restore the associated filesystem dataset too.
-	if !migration && vol.IsVMBlock() {
-		fsVol := vol.NewVMBlockFilesystemVolume()
-		err := d.restoreVolume(fsVol, snapshotName, migration, op)
+	return nil
+}
+
+// restoreVolumeCopy restores a volume by copying the content of the snapshot over it, keeping the
+// snapshots that follow it.
+func (d *zfs) restoreVolumeCopy(vol Volume, snapshotName string, op *operations.Operation) error {
+	snapVol, err := vol.NewSnapshot(snapshotName)
+	if err != nil {
+		return err
+	}
+
+	err = vol.MountTask(func(mountPath string, op *operations.Operation) error {
+		return snapVol.MountTask(func(srcMountPath string, op *operations.Operation) error {
+			if vol.contentType == ContentTypeFS {
+				return zfsRestoreCopy(vol, srcMountPath, mountPath, d.config["rsync.bwlimit"], d.logger)
+			}
+
+			srcDevPath, err := d.GetVolumeDiskPath(snapVol)
+			if err != nil {
+				return err
+			}
+
+			targetDevPath, err := d.GetVolumeDiskPath(vol)
+			if err != nil {
+				return err
+			}
+
+			return zfsRestoreCopy(vol, srcDevPath, targetDevPath, "", d.logger)
+		}, op)
+	}, op)
+	if err != nil {
+		return fmt.Errorf("Error restoring ZFS snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// zfsRestoreCopy copies the content of a mounted snapshot (or its block device) over the volume.
+func zfsRestoreCopy(vol Volume, srcPath string, targetPath string, bwlimit string, l logger.Logger) error {
+	if vol.contentType == ContentTypeFS {
+		l.Debug("Copying filesystem volume", logger.Ctx{"sourcePath": srcPath, "targetPath": targetPath, "bwlimit": bwlimit})
+		_, err := rsync.LocalCopy(srcPath, targetPath, bwlimit, true)
 		if err != nil {
 			return err
 		}
+
+		// Run EnsureMountPath after syncing to ensure the mounted directory has the correct permissions set.
+		return vol.EnsureMountPath()
 	}
 
-	return nil
+	l.Debug("Copying block volume", logger.Ctx{"srcDevPath": srcPath, "targetPath": targetPath})
+	return copyDevice(srcPath, targetPath)
 }
 
 // RenameVolumeSnapshot renames a volume snapshot.
@@ -3389,12 +3487,12 @@ func (d *zfs) FillVolumeConfig(vol Volume) error {
 	if d.isBlockBacked(vol) && vol.ContentType() == ContentTypeFS {
 		// Inherit block mode from pool if not set.
 		if vol.config["zfs.block_mode"] == "" {
-			vol.config["zfs.block_mode"] = d.config["volume.zfs.block_mode"]
+			vol.config["zfs.block_mode"] = d.volumeDefault(vol, "zfs.block_mode")
 		}
 
 		// Inherit filesystem from pool if not set.
 		if vol.config["block.filesystem"] == "" {
-			vol.config["block.filesystem"] = d.config["volume.block.filesystem"]
+			vol.config["block.filesystem"] = d.volumeDefault(vol, "block.filesystem")
 		}
 
 		// Default filesystem if neither volume nor pool specify an override.
@@ -3405,7 +3503,7 @@ func (d *zfs) FillVolumeConfig(vol Volume) error {
 
 		// Inherit filesystem mount options from pool if not set.
 		if vol.config["block.mount_options"] == "" {
-			vol.config["block.mount_options"] = d.config["volume.block.mount_options"]
+			vol.config["block.mount_options"] = d.volumeDefault(vol, "block.mount_options")
 		}
 
 		// Default filesystem mount options if neither volume nor pool specify an override.
@@ -3421,3 +3519,77 @@ func (d *zfs) FillVolumeConfig(vol Volume) error {
 func (d *zfs) isBlockBacked(vol Volume) bool {
 	return util.IsTrue(vol.Config()["zfs.block_mode"])
 }
+
+// TransferVolume sends the volume to a dataset on a remote host using zfs send over SSH.
+// The snapshot of each transfer is kept as the base of the next one, which is sent as an incremental
+// stream when the remote dataset still has that snapshot.
+func (d *zfs) TransferVolume(vol Volume, target string, op *operations.Operation) error {
+	transferTarget, err := parseZFSTransferTarget(target)
+	if err != nil {
+		return err
+	}
+
+	dataset := d.dataset(vol, false)
+
+	// Find the snapshots of the previous transfers.
+	entries, err := d.getDatasets(dataset, "snapshot")
+	if err != nil {
+		return err
+	}
+
+	previousSnapshots := []string{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry, zfsTransferSnapshotPrefix) {
+			previousSnapshots = append(previousSnapshots, entry)
+		}
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	snapshot := fmt.Sprintf("%s%d", zfsTransferSnapshotPrefix, time.Now().UnixNano())
+	_, err = subprocess.RunCommand("zfs", "snapshot", fmt.Sprintf("%s%s", dataset, snapshot))
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _, _ = subprocess.RunCommand("zfs", "destroy", fmt.Sprintf("%s%s", dataset, snapshot)) })
+
+	// Send an incremental stream if the remote dataset has the snapshot of the last transfer.
+	parent := ""
+	if len(previousSnapshots) > 0 {
+		lastSnapshot := previousSnapshots[len(previousSnapshots)-1]
+
+		_, err = subprocess.RunCommand("ssh", transferTarget.sshArgs("zfs", "list", "-H", "-o", "name", fmt.Sprintf("%s%s", transferTarget.dataset, lastSnapshot))...)
+		if err == nil {
+			parent = lastSnapshot
+		} else if zfsTransferSnapshotMissing(err) {
+			d.logger.Debug("Sending full stream as the remote dataset doesn't have the last transfer snapshot", logger.Ctx{"target": target, "snapshot": lastSnapshot})
+		} else {
+			// A full stream replaces the remote dataset and its snapshots, so only send one when sure it's needed.
+			return fmt.Errorf("Failed checking the last transfer snapshot on %q: %w", transferTarget.host, err)
+		}
+	}
+
+	var tracker *ioprogress.ProgressTracker
+	if op != nil {
+		tracker = localMigration.ProgressTracker(op, "fs_progress", vol.name)
+	}
+
+	err = d.sendDatasetToTarget(dataset, parent, snapshot, transferTarget, tracker)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	// Only keep the snapshot of this transfer.
+	for _, entry := range previousSnapshots {
+		_, err = subprocess.RunCommand("zfs", "destroy", fmt.Sprintf("%s%s", dataset, entry))
+		if err != nil {
+			d.logger.Warn("Failed deleting previous transfer snapshot", logger.Ctx{"snapshot": entry, "err": err})
+		}
+	}
+
+	return nil
+}
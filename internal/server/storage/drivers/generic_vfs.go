@@ -72,12 +72,12 @@ func genericVFSRenameVolume(d Driver, vol Volume, newVolName string, op *operati
 	dstVolumePath := GetVolumeMountPath(d.Name(), vol.volType, newVolName)
 
 	if util.PathExists(srcVolumePath) {
-		err := os.Rename(srcVolumePath, dstVolumePath)
+		err := renameVolumePath(srcVolumePath, dstVolumePath)
 		if err != nil {
 			return fmt.Errorf("Failed to rename %q to %q: %w", srcVolumePath, dstVolumePath, err)
 		}
 
-		revert.Add(func() { _ = os.Rename(dstVolumePath, srcVolumePath) })
+		revert.Add(func() { _ = renameVolumePath(dstVolumePath, srcVolumePath) })
 	}
 
 	// And if present, the snapshots too.
@@ -93,6 +93,9 @@ func genericVFSRenameVolume(d Driver, vol Volume, newVolName string, op *operati
 		revert.Add(func() { _ = os.Rename(dstSnapshotDir, srcSnapshotDir) })
 	}
 
+	// Volumes in use keep being tracked under their new name.
+	vol.mountRefCountRename(newVolName)
+
 	revert.Success()
 	return nil
 }
@@ -592,7 +595,7 @@ func genericVFSBackupVolume(d Driver, vol Volume, tarWriter *instancewriter.Inst
 				return filepath.Walk(mountPath, func(srcPath string, fi os.FileInfo, err error) error {
 					if err != nil {
 						if os.IsNotExist(err) {
-							logger.Warnf("File vanished during export: %q, skipping", srcPath)
+							storageLogger.Warnf("File vanished during export: %q, skipping", srcPath)
 							return nil
 						}
 
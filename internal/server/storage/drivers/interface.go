@@ -40,12 +40,19 @@ type Driver interface {
 	FillConfig() error
 	Create() error
 	Delete(op *operations.Operation) error
+
+	// Leave releases a remote storage pool on the cluster members other than the one deleting it.
+	Leave() error
 	// Mount mounts a storage pool if needed, returns true if we caused a new mount, false if already mounted.
 	Mount() (bool, error)
 
 	// Unmount unmounts a storage pool if needed, returns true if unmounted, false if was not mounted.
 	Unmount() (bool, error)
 	GetResources() (*api.ResourcesStoragePool, error)
+	CheckHealth() error
+	GetMirrorState() (*api.StoragePoolMirrorState, error)
+	PromoteMirror(force bool) error
+	DemoteMirror() error
 	Validate(config map[string]string) error
 	Update(changedConfig map[string]string) error
 	ApplyPatch(name string) error
@@ -56,6 +63,7 @@ type Driver interface {
 	CreateBucket(bucket Volume, op *operations.Operation) error
 	DeleteBucket(bucket Volume, op *operations.Operation) error
 	UpdateBucket(bucket Volume, changedConfig map[string]string) error
+	GetBucketUsage(bucket Volume) (*BucketUsage, error)
 	ValidateBucketKey(keyName string, creds S3Credentials, roleName string) error
 	CreateBucketKey(bucket Volume, keyName string, creds S3Credentials, roleName string, op *operations.Operation) (*S3Credentials, error)
 	UpdateBucketKey(bucket Volume, keyName string, creds S3Credentials, roleName string, op *operations.Operation) (*S3Credentials, error)
@@ -66,11 +74,19 @@ type Driver interface {
 	ValidateVolume(vol Volume, removeUnknownKeys bool) error
 	CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Operation) error
 	CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots bool, allowInconsistent bool, op *operations.Operation) error
+
+	// CanCloneVolume checks whether CreateVolumeFromCopy clones the volume rather than copying its data.
+	CanCloneVolume(srcVol Volume, copySnapshots bool) bool
+
 	RefreshVolume(vol Volume, srcVol Volume, srcSnapshots []Volume, allowInconsistent bool, op *operations.Operation) error
 	DeleteVolume(vol Volume, op *operations.Operation) error
 	RenameVolume(vol Volume, newName string, op *operations.Operation) error
 	UpdateVolume(vol Volume, changedConfig map[string]string) error
 	GetVolumeUsage(vol Volume) (int64, error)
+	GetVolumeMirrorState(vol Volume) (*api.StorageVolumeStateMirror, error)
+	GetVolumeIOStats(vol Volume) (*api.StorageVolumeStateIO, error)
+	PromoteVolumeMirror(vol Volume, force bool) error
+	DemoteVolumeMirror(vol Volume) error
 	SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error
 	GetVolumeDiskPath(vol Volume) (string, error)
 	ListVolumes() ([]Volume, error)
@@ -100,6 +116,7 @@ type Driver interface {
 	RenameVolumeSnapshot(snapVol Volume, newSnapshotName string, op *operations.Operation) error
 	VolumeSnapshots(vol Volume, op *operations.Operation) ([]string, error)
 	RestoreVolume(vol Volume, snapshotName string, op *operations.Operation) error
+	TransferVolume(vol Volume, target string, op *operations.Operation) error
 
 	// Migration.
 	MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool) []migration.Type
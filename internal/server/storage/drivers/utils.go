@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +26,9 @@ import (
 	"github.com/lxc/incus/v6/shared/util"
 )
 
+// storageLogger is the logger of the storage subsystem.
+var storageLogger = logger.Subsystem("storage")
+
 // MinBlockBoundary minimum block boundary size to use.
 const MinBlockBoundary = 8192
 
@@ -190,7 +194,7 @@ func TryUnmount(path string, flags int) error {
 			break
 		}
 
-		logger.Debug("Failed to unmount", logger.Ctx{"path": path, "attempt": i, "err": err})
+		storageLogger.Debug("Failed to unmount", logger.Ctx{"path": path, "attempt": i, "err": err})
 		time.Sleep(500 * time.Millisecond)
 	}
 
@@ -937,3 +941,88 @@ func sliceAny[T any](slice []T, predicate func(T) bool) bool {
 
 	return false
 }
+
+// blockDeviceIOStats returns the IO counters of a block device as reported by the kernel.
+func blockDeviceIOStats(devPath string) (*api.StorageVolumeStateIO, error) {
+	var stat unix.Stat_t
+
+	err := unix.Stat(devPath, &stat)
+	if err != nil {
+		return nil, err
+	}
+
+	if stat.Mode&unix.S_IFMT != unix.S_IFBLK {
+		return nil, fmt.Errorf("%q isn't a block device", devPath)
+	}
+
+	content, err := os.ReadFile(fmt.Sprintf("/sys/dev/block/%d:%d/stat", unix.Major(uint64(stat.Rdev)), unix.Minor(uint64(stat.Rdev))))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBlockDeviceStat(string(content))
+}
+
+// parseBlockDeviceStat parses the content of a /sys/block/<dev>/stat file.
+// Byte counts are derived from the 512 bytes sectors and latencies are averaged from the time spent on requests.
+func parseBlockDeviceStat(content string) (*api.StorageVolumeStateIO, error) {
+	fields := strings.Fields(content)
+	if len(fields) < 8 {
+		return nil, fmt.Errorf("Unexpected block device stat format %q", strings.TrimSpace(content))
+	}
+
+	values := make([]uint64, 8)
+	for i := range values {
+		value, err := strconv.ParseUint(fields[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing block device stat field %d: %w", i, err)
+		}
+
+		values[i] = value
+	}
+
+	stats := api.StorageVolumeStateIO{
+		ReadOps:    values[0],
+		ReadBytes:  values[2] * 512,
+		WriteOps:   values[4],
+		WriteBytes: values[6] * 512,
+	}
+
+	if stats.ReadOps > 0 {
+		stats.ReadLatency = values[3] * 1000 / stats.ReadOps
+	}
+
+	if stats.WriteOps > 0 {
+		stats.WriteLatency = values[7] * 1000 / stats.WriteOps
+	}
+
+	return &stats, nil
+}
+
+// renameVolumePath renames the mount path of a volume. The mount of a volume in use is moved along to the new path,
+// keeping the volume available to its users.
+func renameVolumePath(srcPath string, dstPath string) error {
+	if !linux.IsMountPoint(srcPath) {
+		return os.Rename(srcPath, dstPath)
+	}
+
+	err := os.Mkdir(dstPath, 0o711)
+	if err != nil {
+		return err
+	}
+
+	err = unix.Mount(srcPath, dstPath, "none", unix.MS_BIND|unix.MS_REC, "")
+	if err != nil {
+		_ = os.Remove(dstPath)
+		return fmt.Errorf("Failed moving the mount of %q: %w", srcPath, err)
+	}
+
+	err = unix.Unmount(srcPath, unix.MNT_DETACH)
+	if err != nil {
+		_ = unix.Unmount(dstPath, unix.MNT_DETACH)
+		_ = os.Remove(dstPath)
+		return fmt.Errorf("Failed moving the mount of %q: %w", srcPath, err)
+	}
+
+	return os.Remove(srcPath)
+}
@@ -1,9 +1,15 @@
 package drivers
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/shared/util"
 )
 
 // Test GetVolumeMountPath.
@@ -35,3 +41,77 @@ func TestGetVolumeMountPath(t *testing.T) {
 	expected = GetPoolMountPath(poolName) + "/virtual-machines/testvol"
 	assert.Equal(t, expected, path)
 }
+
+// Test parseBlockDeviceStat.
+func TestParseBlockDeviceStat(t *testing.T) {
+	stats, err := parseBlockDeviceStat("   13740     1032  1099264    5770    48526    12931  3588096    65517        0    48212    71288\n")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(13740), stats.ReadOps)
+	assert.Equal(t, uint64(1099264*512), stats.ReadBytes)
+	assert.Equal(t, uint64(48526), stats.WriteOps)
+	assert.Equal(t, uint64(3588096*512), stats.WriteBytes)
+	assert.Equal(t, uint64(5770*1000/13740), stats.ReadLatency)
+	assert.Equal(t, uint64(65517*1000/48526), stats.WriteLatency)
+
+	// Idle devices don't report latencies.
+	stats, err = parseBlockDeviceStat("0 0 0 0 0 0 0 0 0 0 0")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), stats.ReadLatency)
+	assert.Equal(t, uint64(0), stats.WriteLatency)
+
+	// Truncated content.
+	_, err = parseBlockDeviceStat("1 2 3")
+	assert.Error(t, err)
+}
+
+func TestRenameVolumePath(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "c1")
+	dstPath := filepath.Join(dir, "c2")
+
+	// A volume which isn't mounted is renamed.
+	require.NoError(t, os.Mkdir(srcPath, 0o711))
+	require.NoError(t, os.WriteFile(filepath.Join(srcPath, "data"), []byte("data"), 0o600))
+	require.NoError(t, renameVolumePath(srcPath, dstPath))
+	assert.NoDirExists(t, srcPath)
+	assert.FileExists(t, filepath.Join(dstPath, "data"))
+
+	// The mount of a volume in use is moved to the new path.
+	source := filepath.Join(dir, "source")
+	require.NoError(t, os.Mkdir(source, 0o711))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "data"), []byte("mounted"), 0o600))
+	require.NoError(t, os.Mkdir(srcPath, 0o711))
+
+	err := unix.Mount(source, srcPath, "none", unix.MS_BIND, "")
+	if err != nil {
+		t.Skipf("Bind mounts aren't available: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = unix.Unmount(srcPath, unix.MNT_DETACH)
+		_ = unix.Unmount(filepath.Join(dir, "c3"), unix.MNT_DETACH)
+	})
+
+	// A file held open through the previous path stays usable.
+	f, err := os.Open(filepath.Join(srcPath, "data"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	require.NoError(t, renameVolumePath(srcPath, filepath.Join(dir, "c3")))
+	assert.False(t, util.PathExists(srcPath))
+
+	content, err := os.ReadFile(filepath.Join(dir, "c3", "data"))
+	require.NoError(t, err)
+	assert.Equal(t, "mounted", string(content))
+
+	buf := make([]byte, 7)
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "mounted", string(buf))
+
+	// The target of a mounted volume must not exist.
+	require.NoError(t, os.Mkdir(srcPath, 0o711))
+	require.NoError(t, unix.Mount(source, srcPath, "none", unix.MS_BIND, ""))
+	assert.Error(t, renameVolumePath(srcPath, dstPath))
+	assert.True(t, util.PathExists(srcPath))
+}
@@ -105,6 +105,9 @@ type Volume struct {
 	mountCustomPath      string // Mount the filesystem volume at a custom location.
 	mountFilesystemProbe bool   // Probe filesystem type when mounting volume (when needed).
 	hasSource            bool   // Whether the volume is created from a source volume.
+
+	// Defaults of the volume config overriding the pool ones (without the "volume." prefix).
+	volumeDefaults map[string]string
 }
 
 // NewVolume instantiates a new Volume struct.
@@ -200,6 +203,20 @@ func (v Volume) MountRefCountDecrement() uint {
 	return refcount.Decrement(v.mountLockName(), 1)
 }
 
+// mountRefCountRename moves the mount ref counter of the volume over to its new name, for volumes renamed while
+// in use.
+func (v Volume) mountRefCountRename(newVolName string) {
+	newVol := NewVolume(v.driver, v.pool, v.volType, v.contentType, newVolName, nil, nil)
+
+	count := refcount.Get(v.mountLockName())
+	if count == 0 {
+		return
+	}
+
+	refcount.Decrement(v.mountLockName(), count)
+	refcount.Increment(newVol.mountLockName(), count)
+}
+
 // MountInUse returns whether the volume has a mount ref counter >0.
 func (v Volume) MountInUse() bool {
 	return refcount.Get(v.mountLockName()) > 0
@@ -570,6 +587,11 @@ func (v *Volume) SetHasSource(hasSource bool) {
 	v.hasSource = hasSource
 }
 
+// SetVolumeDefaults sets the defaults of the volume config taking precedence over the pool "volume.*" ones.
+func (v *Volume) SetVolumeDefaults(defaults map[string]string) {
+	v.volumeDefaults = defaults
+}
+
 // Clone returns a copy of the volume.
 func (v Volume) Clone() Volume {
 	// Copy the config map to avoid internal modifications affecting external state.
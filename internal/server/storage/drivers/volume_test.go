@@ -110,3 +110,23 @@ func Test_Volume_ConfigSizeFromSource(t *testing.T) {
 		assert.Equal(t, test.err, err)
 	}
 }
+
+// Test Volume_mountRefCountRename.
+func Test_Volume_mountRefCountRename(t *testing.T) {
+	vol := NewVolume(&dir{}, "pool1", VolumeTypeContainer, ContentTypeFS, "project_c1", nil, nil)
+	newVol := NewVolume(&dir{}, "pool1", VolumeTypeContainer, ContentTypeFS, "project_c2", nil, nil)
+
+	vol.MountRefCountIncrement()
+	vol.MountRefCountIncrement()
+
+	// The volume in use keeps being tracked under its new name.
+	vol.mountRefCountRename("project_c2")
+	assert.False(t, vol.MountInUse())
+	assert.True(t, newVol.MountInUse())
+	assert.Equal(t, uint(1), newVol.MountRefCountDecrement())
+	assert.Equal(t, uint(0), newVol.MountRefCountDecrement())
+
+	// Renaming an unused volume leaves no counter behind.
+	vol.mountRefCountRename("project_c2")
+	assert.False(t, newVol.MountInUse())
+}
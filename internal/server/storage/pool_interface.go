@@ -50,6 +50,9 @@ type Pool interface {
 	ToAPI() api.StoragePool
 
 	GetResources() (*api.ResourcesStoragePool, error)
+	CheckHealth() error
+	GetMirrorState() (*api.StoragePoolMirrorState, error)
+	UpdateMirror(req api.StoragePoolMirrorPost) error
 	IsUsed() (bool, error)
 	Delete(clientType request.ClientType, op *operations.Operation) error
 	Update(clientType request.ClientType, newDesc string, newConfig map[string]string, op *operations.Operation) error
@@ -64,6 +67,7 @@ type Pool interface {
 
 	// Instances.
 	CreateInstance(inst instance.Instance, op *operations.Operation) error
+	CreateInstanceFromVolume(inst instance.Instance, srcPool Pool, srcVolName string, op *operations.Operation) error
 	CreateInstanceFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (func(instance.Instance) error, revert.Hook, error)
 	CreateInstanceFromCopy(inst instance.Instance, src instance.Instance, snapshots bool, allowInconsistent bool, op *operations.Operation) error
 	CreateInstanceFromImage(inst instance.Instance, fingerprint string, op *operations.Operation) error
@@ -82,6 +86,9 @@ type Pool interface {
 	BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error
 
 	GetInstanceUsage(inst instance.Instance) (*VolumeUsage, error)
+	GetInstanceMirrorState(inst instance.Instance) (*api.StorageVolumeStateMirror, error)
+	GetInstanceIOStats(inst instance.Instance) (*api.StorageVolumeStateIO, error)
+	UpdateInstanceMirror(inst instance.Instance, req api.StorageVolumeMirrorPost, op *operations.Operation) error
 	SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error
 
 	MountInstance(inst instance.Instance, op *operations.Operation) (*MountInfo, error)
@@ -110,6 +117,7 @@ type Pool interface {
 	UpdateBucketKey(projectName string, bucketName string, keyName string, key api.StorageBucketKeyPut, op *operations.Operation) error
 	DeleteBucketKey(projectName string, bucketName string, keyName string, op *operations.Operation) error
 	ActivateBucket(projectName string, bucketName string, op *operations.Operation) (*miniod.Process, error)
+	GetBucketState(projectName string, bucketName string) (*api.StorageBucketState, error)
 	GetBucketURL(bucketName string) *url.URL
 	GenerateBucketBackupConfig(projectName string, bucketName string, op *operations.Operation) (*backupConfig.Config, error)
 	BackupBucket(projectName string, bucketName string, tarWriter *instancewriter.InstanceTarWriter, op *operations.Operation) error
@@ -123,12 +131,17 @@ type Pool interface {
 	DeleteCustomVolume(projectName string, volName string, op *operations.Operation) error
 	GetCustomVolumeDisk(projectName string, volName string) (string, error)
 	GetCustomVolumeUsage(projectName string, volName string) (*VolumeUsage, error)
+	GetCustomVolumeMirrorState(projectName string, volName string) (*api.StorageVolumeStateMirror, error)
+	GetCustomVolumeIOStats(projectName string, volName string) (*api.StorageVolumeStateIO, error)
+	UpdateCustomVolumeMirror(projectName string, volName string, req api.StorageVolumeMirrorPost, op *operations.Operation) error
 	MountCustomVolume(projectName string, volName string, op *operations.Operation) (*MountInfo, error)
 	UnmountCustomVolume(projectName string, volName string, op *operations.Operation) (bool, error)
 	ImportCustomVolume(projectName string, poolVol *backupConfig.Config, op *operations.Operation) (revert.Hook, error)
 	RefreshCustomVolume(projectName string, srcProjectName string, volName, desc string, config map[string]string, srcPoolName, srcVolName string, snapshots bool, op *operations.Operation) error
 	GenerateCustomVolumeBackupConfig(projectName string, volName string, snapshots bool, op *operations.Operation) (*backupConfig.Config, error)
 	CreateCustomVolumeFromISO(projectName string, volName string, srcData io.ReadSeeker, size int64, op *operations.Operation) error
+	CreateCustomVolumeFromDiskImage(projectName string, volName string, imgPath string, op *operations.Operation) error
+	TransferCustomVolume(projectName string, volName string, target string, op *operations.Operation) error
 
 	// Custom volume snapshots.
 	CreateCustomVolumeSnapshot(projectName string, volName string, newSnapshotName string, newExpiryDate time.Time, op *operations.Operation) error
@@ -146,6 +159,11 @@ type Pool interface {
 	BackupCustomVolume(projectName string, volName string, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error
 	CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error
 
+	// Recycle bin.
+	RecycleVolume(projectName string, poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error
+	RestoreRecycledVolume(projectName string, poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error
+	DeleteRecycledVolume(poolVol *backupConfig.Config, recycledName string, op *operations.Operation) error
+
 	// Storage volume recovery.
 	ListUnknownVolumes(op *operations.Operation) (map[string][]*backupConfig.Config, error)
 }
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/instance"
@@ -14,6 +15,9 @@ import (
 	"github.com/lxc/incus/v6/shared/logger"
 )
 
+// storageLogger is the logger of the storage subsystem.
+var storageLogger = logger.Subsystem("storage")
+
 // PoolIDTemporary is used to indicate a temporary pool instance that is not in the database.
 const PoolIDTemporary = -1
 
@@ -24,6 +28,11 @@ const PoolIDTemporary = -1
 func volIDFuncMake(state *state.State, poolID int64) func(volType drivers.VolumeType, volName string) (int64, error) {
 	// Return a function to retrieve a volume ID for a volume Name for use in driver.
 	return func(volType drivers.VolumeType, volName string) (int64, error) {
+		// The volumes held by the recycle bin have no database record, have the driver skip their quota.
+		if strings.HasPrefix(volName, RecycledVolumePrefix) {
+			return -1, nil
+		}
+
 		volTypeID, err := VolumeTypeToDBType(volType)
 		if err != nil {
 			return -1, err
@@ -75,7 +84,7 @@ func NewTemporary(state *state.State, info *api.StoragePool) (Pool, error) {
 		pool := mockBackend{}
 		pool.name = info.Name
 		pool.state = state
-		pool.logger = logger.AddContext(logger.Ctx{"driver": "mock", "pool": pool.name})
+		pool.logger = storageLogger.AddContext(logger.Ctx{"driver": "mock", "pool": pool.name})
 		driver, err := drivers.Load(state, "mock", "", nil, pool.logger, nil, nil)
 		if err != nil {
 			return nil, err
@@ -93,7 +102,7 @@ func NewTemporary(state *state.State, info *api.StoragePool) (Pool, error) {
 		info.Config = map[string]string{}
 	}
 
-	logger := logger.AddContext(logger.Ctx{"driver": info.Driver, "pool": info.Name})
+	logger := storageLogger.AddContext(logger.Ctx{"driver": info.Driver, "pool": info.Name})
 
 	// Load the storage driver.
 	driver, err := drivers.Load(state, info.Driver, info.Name, info.Config, logger, volIDFuncMake(state, poolID), commonRules())
@@ -116,7 +125,7 @@ func NewTemporary(state *state.State, info *api.StoragePool) (Pool, error) {
 
 // LoadByType loads a network by driver type.
 func LoadByType(state *state.State, driverType string) (Type, error) {
-	logger := logger.AddContext(logger.Ctx{"driver": driverType})
+	logger := storageLogger.AddContext(logger.Ctx{"driver": driverType})
 
 	driver, err := drivers.Load(state, driverType, "", nil, logger, nil, commonRules())
 	if err != nil {
@@ -141,7 +150,7 @@ func LoadByRecord(s *state.State, poolID int64, poolInfo api.StoragePool, poolMe
 		poolInfo.Config = map[string]string{}
 	}
 
-	logger := logger.AddContext(logger.Ctx{"driver": poolInfo.Driver, "pool": poolInfo.Name})
+	logger := storageLogger.AddContext(logger.Ctx{"driver": poolInfo.Driver, "pool": poolInfo.Name})
 
 	// Load the storage driver.
 	driver, err := drivers.Load(s, poolInfo.Driver, poolInfo.Name, poolInfo.Config, logger, volIDFuncMake(s, poolID), commonRules())
@@ -170,7 +179,7 @@ func LoadByName(s *state.State, name string) (Pool, error) {
 		pool := mockBackend{}
 		pool.name = name
 		pool.state = s
-		pool.logger = logger.AddContext(logger.Ctx{"driver": "mock", "pool": pool.name})
+		pool.logger = storageLogger.AddContext(logger.Ctx{"driver": "mock", "pool": pool.name})
 		driver, err := drivers.Load(s, "mock", "", nil, pool.logger, nil, nil)
 		if err != nil {
 			return nil, err
@@ -239,6 +248,14 @@ func IsAvailable(poolName string) bool {
 	return !found
 }
 
+// SetUnavailable marks a pool as unavailable on this server until it is mounted again.
+func SetUnavailable(poolName string) {
+	unavailablePoolsMu.Lock()
+	defer unavailablePoolsMu.Unlock()
+
+	unavailablePools[poolName] = struct{}{}
+}
+
 // Patch applies specified patch to all storage pools.
 // All storage pools must be available locally before any storage pools are patched.
 func Patch(s *state.State, patchName string) error {
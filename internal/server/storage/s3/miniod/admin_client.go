@@ -29,6 +29,12 @@ type InfoServiceAccountResp struct {
 	Policy        map[string]any `json:"policy"`
 }
 
+// BucketUsageResp is the response body of the bucket disk usage call.
+type BucketUsageResp struct {
+	Size    int64 `json:"size"`
+	Objects int64 `json:"objects"`
+}
+
 // AdminClient represents minio client.
 type AdminClient struct {
 	process    *Process
@@ -269,3 +275,69 @@ func (c *AdminClient) ExportIAM(ctx context.Context) ([]byte, error) {
 
 	return iamBytes, nil
 }
+
+// SetBucketQuota sets a hard quota of sizeBytes on the bucket, removing the quota if sizeBytes is zero.
+func (c *AdminClient) SetBucketQuota(ctx context.Context, bucketName string, sizeBytes int64) error {
+	target := fmt.Sprintf("%s/%s", c.alias, bucketName)
+
+	var err error
+	if sizeBytes > 0 {
+		_, err = c.runClientCommand(ctx, "", "quota", "set", target, "--size", fmt.Sprintf("%d", sizeBytes))
+	} else {
+		_, err = c.runClientCommand(ctx, "", "quota", "clear", target)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// BucketUsage returns the space used and the number of objects stored in the bucket.
+func (c *AdminClient) BucketUsage(ctx context.Context, bucketName string) (*BucketUsageResp, error) {
+	out, err := c.runClientCommand(ctx, "", "--json", "du", fmt.Sprintf("%s/%s", c.alias, bucketName))
+	if err != nil {
+		return nil, err
+	}
+
+	return bucketUsageParse(out)
+}
+
+// bucketUsageParse parses the JSON output of `mc du`, which prints one object per line and ends with the total.
+func bucketUsageParse(out string) (*BucketUsageResp, error) {
+	var resp *BucketUsageResp
+
+	decoder := json.NewDecoder(strings.NewReader(out))
+	for decoder.More() {
+		entry := struct {
+			BucketUsageResp
+
+			Status string `json:"status"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}{}
+
+		err := decoder.Decode(&entry)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing bucket usage: %w", err)
+		}
+
+		if entry.Status == "error" {
+			if entry.Error != nil {
+				return nil, fmt.Errorf("Failed getting bucket usage: %s", entry.Error.Message)
+			}
+
+			return nil, fmt.Errorf("Failed getting bucket usage")
+		}
+
+		resp = &entry.BucketUsageResp
+	}
+
+	if resp == nil {
+		return nil, fmt.Errorf("Bucket usage is missing")
+	}
+
+	return resp, nil
+}
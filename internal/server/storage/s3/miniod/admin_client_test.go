@@ -0,0 +1,38 @@
+package miniod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketUsageParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		size    int64
+		objects int64
+		fails   bool
+	}{
+		{"Bucket", `{"status":"success","prefix":"foo","size":1536,"objects":3,"versions":3}` + "\n", 1536, 3, false},
+		{"Empty bucket", `{"status":"success","prefix":"foo","size":0,"objects":0}`, 0, 0, false},
+		{"Prefixes then total", `{"status":"success","prefix":"foo/a","size":512,"objects":1}` + "\n" + `{"status":"success","prefix":"foo","size":1536,"objects":3}` + "\n", 1536, 3, false},
+		{"Error", `{"status":"error","error":{"message":"Bucket does not exist"}}`, 0, 0, true},
+		{"No output", "", 0, 0, true},
+		{"Not JSON", "mc: <ERROR> Unable to get disk usage", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage, err := bucketUsageParse(tt.out)
+			if tt.fails {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.size, usage.Size)
+			require.Equal(t, tt.objects, usage.Objects)
+		})
+	}
+}
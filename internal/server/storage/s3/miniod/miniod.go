@@ -30,6 +30,9 @@ import (
 	"github.com/lxc/incus/v6/shared/util"
 )
 
+// storageLogger is the logger of the storage subsystem.
+var storageLogger = logger.Subsystem("storage")
+
 // minioHost is the host address that the local MinIO processes will listen on.
 const minioHost = "127.0.0.1"
 
@@ -243,7 +246,7 @@ func EnsureRunning(s *state.State, bucketVol storageDrivers.Volume) (*Process, e
 		"--address", minioProc.url.Host,
 	}
 
-	l := logger.AddContext(logger.Ctx{"bucketName": bucketName, "bucketPath": bucketPath, "listenPort": listenPort})
+	l := storageLogger.AddContext(logger.Ctx{"bucketName": bucketName, "bucketPath": bucketPath, "listenPort": listenPort})
 
 	// Launch minio process in background.
 	go func() {
@@ -430,7 +433,7 @@ func StopAll() {
 	miniosMu.Unlock()
 
 	if len(minioProcs) > 0 {
-		logger.Info("Stopping MinIO processes")
+		storageLogger.Info("Stopping MinIO processes")
 		for _, minioProc := range minios {
 			_ = minioProc.Stop(context.Background())
 		}
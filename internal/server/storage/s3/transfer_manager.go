@@ -19,6 +19,9 @@ import (
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
+// storageLogger is the logger of the storage subsystem.
+var storageLogger = logger.Subsystem("storage")
+
 // TransferManager represents a transfer manager.
 type TransferManager struct {
 	s3URL     *url.URL
@@ -37,8 +40,8 @@ func NewTransferManager(s3URL *url.URL, accessKey string, secretKey string) Tran
 
 // DownloadAllFiles downloads all files from a bucket and writes them to a tar writer.
 func (t TransferManager) DownloadAllFiles(bucketName string, tarWriter *instancewriter.InstanceTarWriter) error {
-	logger.Debugf("Downloading all files from bucket %s", bucketName)
-	logger.Debugf("Endpoint: %s", t.getEndpoint())
+	storageLogger.Debugf("Downloading all files from bucket %s", bucketName)
+	storageLogger.Debugf("Endpoint: %s", t.getEndpoint())
 
 	minioClient, err := t.getMinioClient()
 	if err != nil {
@@ -54,13 +57,13 @@ func (t TransferManager) DownloadAllFiles(bucketName string, tarWriter *instance
 
 	for objectInfo := range objectCh {
 		if objectInfo.Err != nil {
-			logger.Errorf("Failed to get object info: %v", err)
+			storageLogger.Errorf("Failed to get object info: %v", err)
 			return objectInfo.Err
 		}
 
 		object, err := minioClient.GetObject(ctx, bucketName, objectInfo.Key, minio.GetObjectOptions{})
 		if err != nil {
-			logger.Errorf("Failed to get object: %v", err)
+			storageLogger.Errorf("Failed to get object: %v", err)
 			return err
 		}
 
@@ -76,18 +79,18 @@ func (t TransferManager) DownloadAllFiles(bucketName string, tarWriter *instance
 			FileModTime: time.Now(),
 		}
 
-		logger.Debugf("Writing file %s to tar writer", objectInfo.Key)
-		logger.Debugf("File size: %d", objectInfo.Size)
+		storageLogger.Debugf("Writing file %s to tar writer", objectInfo.Key)
+		storageLogger.Debugf("File size: %d", objectInfo.Size)
 
 		err = tarWriter.WriteFileFromReader(object, &fi)
 		if err != nil {
-			logger.Errorf("Failed to write file to tar writer: %v", err)
+			storageLogger.Errorf("Failed to write file to tar writer: %v", err)
 			return err
 		}
 
 		err = object.Close()
 		if err != nil {
-			logger.Errorf("Failed to close object: %v", err)
+			storageLogger.Errorf("Failed to close object: %v", err)
 			return err
 		}
 	}
@@ -97,8 +100,8 @@ func (t TransferManager) DownloadAllFiles(bucketName string, tarWriter *instance
 
 // UploadAllFiles uploads all the provided files to the bucket.
 func (t TransferManager) UploadAllFiles(bucketName string, srcData io.ReadSeeker) error {
-	logger.Debugf("Uploading all files to bucket %s", bucketName)
-	logger.Debugf("Endpoint: %s", t.getEndpoint())
+	storageLogger.Debugf("Uploading all files to bucket %s", bucketName)
+	storageLogger.Debugf("Endpoint: %s", t.getEndpoint())
 
 	minioClient, err := t.getMinioClient()
 	if err != nil {
@@ -115,7 +118,7 @@ func (t TransferManager) UploadAllFiles(bucketName string, srcData io.ReadSeeker
 	}
 
 	defer func() { _ = os.RemoveAll(mountPath) }()
-	logger.Debugf("Created temp mount path %s", mountPath)
+	storageLogger.Debugf("Created temp mount path %s", mountPath)
 
 	tr, cancelFunc, err := backup.TarReader(srcData, nil, mountPath)
 	if err != nil {
@@ -74,6 +74,15 @@ func ConfigDiff(oldConfig map[string]string, newConfig map[string]string) ([]str
 	return changedConfig, userOnly
 }
 
+// RecycledVolumePrefix is the prefix of the storage names of the volumes held by the recycle bin.
+// As project names can't contain underscores, the storage names of instances and custom volumes never start with it.
+const RecycledVolumePrefix = "_recycle-bin_"
+
+// RecycledVolumeName returns the storage name of the volume held by a recycle bin entry.
+func RecycledVolumeName(id int64) string {
+	return fmt.Sprintf("%s%d", RecycledVolumePrefix, id)
+}
+
 // VolumeTypeNameToDBType converts a volume type string to internal volume type DB code.
 func VolumeTypeNameToDBType(volumeTypeName string) (int, error) {
 	switch volumeTypeName {
@@ -272,6 +281,24 @@ func VolumeDBCreate(pool Pool, projectName string, volumeName string, volumeDesc
 	// Set source indicator.
 	vol.SetHasSource(hasSource)
 
+	// Apply the volume defaults of the project, taking precedence over the pool ones.
+	var projectConfig map[string]string
+	err = p.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		projectConfig, err = cluster.GetProjectConfig(ctx, tx.Tx(), dbProject.ID)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading project %q config: %w", projectName, err)
+	}
+
+	vol.SetVolumeDefaults(project.StorageVolumeDefaults(projectConfig, pool.Name()))
+
 	// Fill default config.
 	err = pool.Driver().FillVolumeConfig(vol)
 	if err != nil {
@@ -547,6 +574,12 @@ func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error
 		rules["volatile.rootfs.size"] = validate.Optional(validate.IsInt64)
 	}
 
+	// Lifecycle rules are only used for buckets.
+	if vol.Type() == drivers.VolumeTypeBucket {
+		rules["lifecycle.expiration"] = validate.Optional(validate.IsUint32)
+		rules["lifecycle.expiration.prefix"] = validate.IsAny
+	}
+
 	return rules
 }
 
@@ -1023,6 +1056,15 @@ func FallbackMigrationType(contentType drivers.ContentType) migration.MigrationF
 	return migration.MigrationFSType_RSYNC
 }
 
+// recordTransferMethod records in the operation metadata how the data of an instance is being transferred.
+func recordTransferMethod(op *operations.Operation, method string) {
+	if op == nil {
+		return
+	}
+
+	_ = op.ExtendMetadata(map[string]any{"transfer_method": method})
+}
+
 // RenderSnapshotUsage can be used as an optional argument to Instance.Render() to return snapshot usage.
 // As this is a relatively expensive operation it is provided as an optional feature rather than on by default.
 func RenderSnapshotUsage(s *state.State, snapInst instance.Instance) func(response any) error {
@@ -1156,3 +1198,47 @@ func CompareSnapshots(sourceSnapshots []ComparableSnapshot, targetSnapshots []Co
 
 	return syncFromSource, deleteFromTarget
 }
+
+// diskImageFormats are the disk image formats which can be imported as custom volumes.
+var diskImageFormats = []string{"qcow2", "raw", "vdi", "vhdx", "vmdk", "vpc"}
+
+// diskImageInfo returns the format and virtual size of a disk image to import.
+func diskImageInfo(sysOS *sys.OS, imgPath string) (string, int64, error) {
+	// Use prlimit because qemu-img can consume considerable RAM & CPU time if fed a maliciously crafted disk image.
+	cmd := []string{"prlimit", "--cpu=2", "--as=1073741824", "qemu-img", "info", "--output=json", imgPath}
+	imgJSON, err := apparmor.QemuImg(sysOS, cmd, imgPath, "")
+	if err != nil {
+		return "", -1, fmt.Errorf("Failed reading disk image info: %w", err)
+	}
+
+	imgInfo := struct {
+		Format          string `json:"format"`
+		VirtualSize     int64  `json:"virtual-size"`
+		BackingFilename string `json:"backing-filename"`
+		FormatSpecific  struct {
+			Data struct {
+				DataFile string `json:"data-file"`
+			} `json:"data"`
+		} `json:"format-specific"`
+	}{}
+
+	err = json.Unmarshal([]byte(imgJSON), &imgInfo)
+	if err != nil {
+		return "", -1, fmt.Errorf("Failed unmarshalling disk image info: %w (%q)", err, imgJSON)
+	}
+
+	if !slices.Contains(diskImageFormats, imgInfo.Format) {
+		return "", -1, fmt.Errorf("Unsupported disk image format %q", imgInfo.Format)
+	}
+
+	// Don't follow references to other files, those could point anywhere on the host.
+	if imgInfo.BackingFilename != "" || imgInfo.FormatSpecific.Data.DataFile != "" {
+		return "", -1, fmt.Errorf("Disk images referencing external files aren't supported")
+	}
+
+	if imgInfo.VirtualSize <= 0 {
+		return "", -1, fmt.Errorf("Invalid disk image size %d", imgInfo.VirtualSize)
+	}
+
+	return imgInfo.Format, imgInfo.VirtualSize, nil
+}
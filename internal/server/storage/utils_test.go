@@ -0,0 +1,102 @@
+//go:build linux && cgo && !agent
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/storage/drivers"
+	"github.com/lxc/incus/v6/internal/server/sys"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// The volume config takes precedence over the project volume defaults, which take precedence over the pool ones.
+func TestVolumeDBCreateDefaults(t *testing.T) {
+	dbCluster, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	s := &state.State{
+		DB: &db.DB{Cluster: dbCluster},
+		OS: &sys.OS{MockMode: true},
+	}
+
+	poolConfig := map[string]string{
+		"volume.snapshots.expiry":   "1d",
+		"volume.snapshots.schedule": "@weekly",
+		"volume.size":               "10GiB",
+	}
+
+	var poolID int64
+	err := dbCluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolID, err = tx.CreateStoragePool(ctx, "pool1", "", "mock", poolConfig)
+		if err != nil {
+			return err
+		}
+
+		projectID, err := cluster.CreateProject(ctx, tx.Tx(), cluster.Project{Name: "p1"})
+		if err != nil {
+			return err
+		}
+
+		return cluster.CreateProjectConfig(ctx, tx.Tx(), projectID, map[string]string{
+			"storage.pool1.volume.snapshots.expiry":   "2d",
+			"storage.pool1.volume.snapshots.schedule": "@daily",
+			"storage.pool2.volume.size":               "20GiB",
+		})
+	})
+	require.NoError(t, err)
+
+	driver, err := drivers.Load(s, "mock", "pool1", poolConfig, logger.Log, nil, nil)
+	require.NoError(t, err)
+
+	pool := &backend{driver: driver, id: poolID, name: "pool1", state: s, logger: logger.Log}
+
+	volumeConfig := func(projectName string, volumeName string) map[string]string {
+		var vol *db.StorageVolume
+		err := dbCluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+			vol, err = tx.GetStoragePoolVolume(ctx, poolID, projectName, db.StoragePoolVolumeTypeCustom, volumeName, true)
+			return err
+		})
+		require.NoError(t, err)
+
+		return vol.Config
+	}
+
+	// The project defaults override the pool ones, the pool ones still apply for other keys.
+	err = VolumeDBCreate(pool, "p1", "vol1", "", drivers.VolumeTypeCustom, false, nil, time.Now(), time.Time{}, drivers.ContentTypeFS, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"snapshots.expiry":   "2d",
+		"snapshots.schedule": "@daily",
+		"size":               "10GiB",
+	}, volumeConfig("p1", "vol1"))
+
+	// The volume config overrides the project defaults.
+	err = VolumeDBCreate(pool, "p1", "vol2", "", drivers.VolumeTypeCustom, false, map[string]string{"snapshots.expiry": "3d"}, time.Now(), time.Time{}, drivers.ContentTypeFS, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"snapshots.expiry":   "3d",
+		"snapshots.schedule": "@daily",
+		"size":               "10GiB",
+	}, volumeConfig("p1", "vol2"))
+
+	// Projects without volume defaults for the pool only get the pool ones.
+	err = VolumeDBCreate(pool, "default", "vol1", "", drivers.VolumeTypeCustom, false, nil, time.Now(), time.Time{}, drivers.ContentTypeFS, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"snapshots.expiry":   "1d",
+		"snapshots.schedule": "@weekly",
+		"size":               "10GiB",
+	}, volumeConfig("default", "vol1"))
+}
@@ -0,0 +1,255 @@
+package syslog
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// instanceMaxMessageSize is the maximum size of a syslog message sent by an instance, longer messages are truncated.
+const instanceMaxMessageSize = 8192
+
+// Message represents a syslog message.
+type Message struct {
+	Facility    int
+	Severity    int
+	Hostname    string
+	Application string
+	ProcessID   string
+	Text        string
+}
+
+// Level returns the log level matching the severity of the message.
+func (m Message) Level() logrus.Level {
+	switch {
+	case m.Severity <= 3:
+		return logrus.ErrorLevel
+	case m.Severity == 4:
+		return logrus.WarnLevel
+	case m.Severity <= 6:
+		return logrus.InfoLevel
+	}
+
+	return logrus.DebugLevel
+}
+
+// ParseMessage parses a RFC3164 or RFC5424 syslog message.
+func ParseMessage(data string) (Message, error) {
+	data = strings.TrimRight(data, "\x00\n")
+
+	// Parse the priority.
+	if !strings.HasPrefix(data, "<") {
+		return Message{}, fmt.Errorf("Missing syslog priority")
+	}
+
+	end := strings.Index(data, ">")
+	if end < 2 || end > 4 {
+		return Message{}, fmt.Errorf("Invalid syslog priority")
+	}
+
+	priority, err := strconv.Atoi(data[1:end])
+	if err != nil || priority > 191 {
+		return Message{}, fmt.Errorf("Invalid syslog priority %q", data[1:end])
+	}
+
+	msg := Message{
+		Facility: priority / 8,
+		Severity: priority % 8,
+	}
+
+	data = data[end+1:]
+
+	// RFC5424: VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	if strings.HasPrefix(data, "1 ") {
+		fields := strings.SplitN(data[2:], " ", 6)
+		if len(fields) < 6 {
+			return Message{}, fmt.Errorf("Invalid RFC5424 syslog message")
+		}
+
+		nilValue := func(value string) string {
+			if value == "-" {
+				return ""
+			}
+
+			return value
+		}
+
+		msg.Hostname = nilValue(fields[1])
+		msg.Application = nilValue(fields[2])
+		msg.ProcessID = nilValue(fields[3])
+		msg.Text = strings.TrimPrefix(skipStructuredData(fields[5]), "\ufeff")
+
+		return msg, nil
+	}
+
+	// RFC3164: [TIMESTAMP] [HOSTNAME] TAG[PID]: MSG
+	// Messages sent to a local socket usually don't include the hostname.
+	data = strings.TrimLeft(data, " ")
+	if len(data) >= len(time.Stamp) {
+		_, err := time.Parse(time.Stamp, data[:len(time.Stamp)])
+		if err == nil {
+			data = strings.TrimLeft(data[len(time.Stamp):], " ")
+		}
+	}
+
+	isTag := func(field string) bool {
+		return strings.HasSuffix(field, ":")
+	}
+
+	fields := strings.SplitN(data, " ", 3)
+	if len(fields) >= 2 && !isTag(fields[0]) && isTag(fields[1]) {
+		msg.Hostname = fields[0]
+		data = strings.TrimPrefix(data, fields[0]+" ")
+		fields = fields[1:]
+	}
+
+	if isTag(fields[0]) {
+		tag := strings.TrimSuffix(fields[0], ":")
+
+		name, pid, found := strings.Cut(tag, "[")
+		if found {
+			msg.ProcessID = strings.TrimSuffix(pid, "]")
+		}
+
+		msg.Application = name
+		data = strings.TrimLeft(strings.TrimPrefix(data, fields[0]), " ")
+	}
+
+	msg.Text = data
+
+	return msg, nil
+}
+
+// skipStructuredData returns what follows the RFC5424 structured data.
+func skipStructuredData(data string) string {
+	if strings.HasPrefix(data, "-") {
+		return strings.TrimPrefix(strings.TrimPrefix(data, "-"), " ")
+	}
+
+	inElement := false
+	inValue := false
+	for i := 0; i < len(data); i++ {
+		switch {
+		case inValue && data[i] == '\\':
+			i++
+		case data[i] == '"' && inElement:
+			inValue = !inValue
+		case data[i] == '[' && !inValue:
+			inElement = true
+		case data[i] == ']' && !inValue:
+			inElement = false
+
+			if i+1 == len(data) || data[i+1] != '[' {
+				return strings.TrimPrefix(data[i+1:], " ")
+			}
+		}
+	}
+
+	return ""
+}
+
+// InstanceSocketPath returns the path of the syslog socket exposed to the instances.
+// It lives in the guest API directory so that containers see it as /dev/incus/syslog.
+func InstanceSocketPath() string {
+	return internalUtil.VarPath("guestapi", "syslog")
+}
+
+// ListenInstances starts the syslog listener used by the instances.
+// The resolver is called with the PID of the sending process, as reported by the kernel, to find the instance it belongs to.
+// Its result is cached and the senders are rate-limited, so that messages don't each cause a lookup.
+// The handler is then called with the sender and the parsed message, only for instances with logging.syslog enabled.
+func ListenInstances(ctx context.Context, resolve func(pid int32) (*Sender, error), handler func(sender Sender, msg Message)) error {
+	sockFile := InstanceSocketPath()
+
+	if util.PathExists(sockFile) {
+		err := os.Remove(sockFile)
+		if err != nil {
+			return fmt.Errorf("Failed deleting stale instance syslog socket: %w", err)
+		}
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sockFile, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("Failed listening on instance syslog socket: %w", err)
+	}
+
+	// Have the kernel attach the credentials of the sender to each message.
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("Failed getting raw connection: %w", err)
+	}
+
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_PASSCRED, 1)
+	})
+	if err == nil {
+		err = sockErr
+	}
+
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("Failed setting SO_PASSCRED: %w", err)
+	}
+
+	err = os.Chmod(sockFile, 0o666)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("Failed setting instance syslog socket permissions: %w", err)
+	}
+
+	// This goroutine waits for the context to be cancelled and then closes the connection causing `ReadMsgUnix` to return an error and exit the goroutine below.
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+		_ = os.Remove(sockFile)
+	}()
+
+	go func() {
+		senders := newSenderCache(resolve)
+		buf := make([]byte, instanceMaxMessageSize)
+		oob := make([]byte, unix.CmsgSpace(unix.SizeofUcred))
+
+		for {
+			n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+			if err != nil {
+				return
+			}
+
+			cmsgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+			if err != nil || len(cmsgs) == 0 {
+				continue
+			}
+
+			ucred, err := unix.ParseUnixCredentials(&cmsgs[0])
+			if err != nil || ucred.Pid <= 0 {
+				continue
+			}
+
+			sender := senders.Get(ucred.Pid)
+			if sender == nil {
+				continue
+			}
+
+			msg, err := ParseMessage(string(buf[:n]))
+			if err != nil {
+				continue
+			}
+
+			handler(*sender, msg)
+		}
+	}()
+
+	return nil
+}
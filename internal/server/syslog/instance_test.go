@@ -0,0 +1,73 @@
+package syslog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Message
+		wantErr bool
+	}{
+		{
+			"Local RFC3164 message",
+			"<30>Oct 14 12:34:56 nginx[1234]: Server started\n",
+			Message{Facility: 3, Severity: 6, Application: "nginx", ProcessID: "1234", Text: "Server started"},
+			false,
+		},
+		{
+			"RFC3164 message with hostname",
+			"<11>Oct  4 02:03:04 web01 app: Failed to connect",
+			Message{Facility: 1, Severity: 3, Hostname: "web01", Application: "app", Text: "Failed to connect"},
+			false,
+		},
+		{
+			"RFC3164 message without tag",
+			"<13>Just a message",
+			Message{Facility: 1, Severity: 5, Text: "Just a message"},
+			false,
+		},
+		{
+			"RFC5424 message without structured data",
+			"<165>1 2026-10-14T12:34:56.003Z web01 myapp 42 ID47 - Disk almost full",
+			Message{Facility: 20, Severity: 5, Hostname: "web01", Application: "myapp", ProcessID: "42", Text: "Disk almost full"},
+			false,
+		},
+		{
+			"RFC5424 message with structured data",
+			`<14>1 2026-10-14T12:34:56Z - myapp - - [exampleSDID@32473 iut="3" eventSource="App] [x"][meta sequence="1"] Hello`,
+			Message{Facility: 1, Severity: 6, Application: "myapp", Text: "Hello"},
+			false,
+		},
+		{
+			"Missing priority",
+			"Oct 14 12:34:56 nginx: Server started",
+			Message{},
+			true,
+		},
+		{
+			"Invalid priority",
+			"<200>nginx: Server started",
+			Message{},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMessage(tt.data)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
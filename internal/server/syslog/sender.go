@@ -0,0 +1,98 @@
+package syslog
+
+import (
+	"time"
+)
+
+// instanceSenderRate is the maximum number of messages accepted from a single sending process per second.
+const instanceSenderRate = 100
+
+// instanceSenderTTL is how long the instance a sending process belongs to is cached for.
+const instanceSenderTTL = 10 * time.Second
+
+// Sender represents the instance a syslog message was sent from.
+type Sender struct {
+	Project  string
+	Instance string
+
+	// Enabled indicates whether logging.syslog is enabled on the instance.
+	Enabled bool
+}
+
+// senderEntry holds the cached state of a sending process.
+type senderEntry struct {
+	sender   *Sender
+	expiry   time.Time
+	window   time.Time
+	messages int
+}
+
+// senderCache rate-limits the sending processes and caches the instance they belong to.
+// This keeps the expensive process to instance resolution off the path of every single message.
+// It is only used by the listener goroutine and so isn't safe for concurrent use.
+type senderCache struct {
+	resolve func(pid int32) (*Sender, error)
+	now     func() time.Time
+	entries map[int32]*senderEntry
+}
+
+// newSenderCache returns a new sender cache using the provided resolver.
+func newSenderCache(resolve func(pid int32) (*Sender, error)) *senderCache {
+	return &senderCache{
+		resolve: resolve,
+		now:     time.Now,
+		entries: map[int32]*senderEntry{},
+	}
+}
+
+// Get returns the sender of a message sent by the process, or nil if the message should be dropped.
+// Messages are dropped when the process exceeds its rate or doesn't belong to an instance with logging.syslog enabled.
+func (c *senderCache) Get(pid int32) *Sender {
+	now := c.now()
+
+	entry, ok := c.entries[pid]
+	if !ok || now.After(entry.expiry) {
+		// Expire the entries of the other processes before adding a new one.
+		c.prune(now)
+
+		entry = &senderEntry{window: now}
+		c.entries[pid] = entry
+	}
+
+	// Apply the rate limit before resolving the sender.
+	if now.Sub(entry.window) >= time.Second {
+		entry.window = now
+		entry.messages = 0
+	}
+
+	entry.messages++
+	if entry.messages > instanceSenderRate {
+		return nil
+	}
+
+	if entry.expiry.IsZero() {
+		// Failures are cached too so that unknown processes can't cause repeated lookups.
+		sender, err := c.resolve(pid)
+		if err != nil {
+			sender = nil
+		}
+
+		entry.sender = sender
+		entry.expiry = now.Add(instanceSenderTTL)
+	}
+
+	if entry.sender == nil || !entry.sender.Enabled {
+		return nil
+	}
+
+	return entry.sender
+}
+
+// prune removes the expired entries.
+func (c *senderCache) prune(now time.Time) {
+	for pid, entry := range c.entries {
+		if now.After(entry.expiry) {
+			delete(c.entries, pid)
+		}
+	}
+}
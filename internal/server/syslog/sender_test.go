@@ -0,0 +1,60 @@
+package syslog
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSenderCache(t *testing.T) {
+	now := time.Now()
+	lookups := map[int32]int{}
+
+	cache := newSenderCache(func(pid int32) (*Sender, error) {
+		lookups[pid]++
+
+		switch pid {
+		case 100:
+			return &Sender{Project: "default", Instance: "c1", Enabled: true}, nil
+		case 200:
+			return &Sender{Project: "default", Instance: "c2", Enabled: false}, nil
+		}
+
+		return nil, fmt.Errorf("Not an instance process")
+	})
+
+	cache.now = func() time.Time { return now }
+
+	// Enabled instance.
+	sender := cache.Get(100)
+	assert.Equal(t, &Sender{Project: "default", Instance: "c1", Enabled: true}, sender)
+
+	// Disabled instance and unknown process.
+	assert.Nil(t, cache.Get(200))
+	assert.Nil(t, cache.Get(300))
+	assert.Nil(t, cache.Get(300))
+
+	// The senders are only resolved once.
+	for i := 1; i < instanceSenderRate; i++ {
+		assert.NotNil(t, cache.Get(100))
+	}
+
+	assert.Equal(t, map[int32]int{100: 1, 200: 1, 300: 1}, lookups)
+
+	// Messages over the rate are dropped.
+	assert.Nil(t, cache.Get(100))
+
+	// Until the next second.
+	now = now.Add(time.Second)
+	assert.NotNil(t, cache.Get(100))
+
+	// The senders are resolved again once expired.
+	now = now.Add(instanceSenderTTL)
+	assert.NotNil(t, cache.Get(100))
+	assert.Equal(t, 2, lookups[100])
+
+	// And the other expired entries are removed.
+	assert.Len(t, cache.entries, 1)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,6 +18,8 @@ type Group struct {
 	tasks   []Task
 	running map[int]bool
 	mu      sync.Mutex
+	paused  atomic.Bool
+	busy    atomic.Int64
 }
 
 // Add a new task to the group, returning its index.
@@ -29,6 +32,7 @@ func (g *Group) Add(f Func, schedule Schedule) *Task {
 		f:        f,
 		schedule: schedule,
 		reset:    make(chan struct{}, 16), // Buffered to not block senders
+		group:    g,
 	})
 
 	return &g.tasks[i]
@@ -120,3 +124,22 @@ func (g *Group) Stop(timeout time.Duration) error {
 		return nil
 	}
 }
+
+// Pause prevents the tasks of the group from executing their task function until Resume is called.
+//
+// Task functions which are already executing aren't interrupted, use Busy to
+// find out when they are done. The runs skipped while paused aren't caught up
+// on, the tasks next run according to their schedule.
+func (g *Group) Pause() {
+	g.paused.Store(true)
+}
+
+// Resume lets the tasks of the group execute their task function again after Pause.
+func (g *Group) Resume() {
+	g.paused.Store(false)
+}
+
+// Busy returns the number of tasks of the group currently executing their task function.
+func (g *Group) Busy() int {
+	return int(g.busy.Load())
+}
@@ -41,6 +41,35 @@ func TestGroup_StopUngracefully(t *testing.T) {
 	assert.EqualError(t, group.Stop(time.Millisecond), "Task(s) still running: IDs [0]")
 }
 
+func TestGroup_Pause(t *testing.T) {
+	group := &task.Group{}
+	ok := make(chan struct{})
+	f := func(context.Context) { ok <- struct{}{} }
+	group.Add(f, task.Every(10*time.Millisecond))
+	group.Pause()
+	group.Start(context.Background())
+
+	// The task function doesn't run while paused.
+	select {
+	case <-ok:
+		t.Fatal("task function executed while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, 0, group.Busy())
+
+	group.Resume()
+	assertRecv(t, ok)
+
+	go func() {
+		for range ok {
+		}
+	}()
+
+	assert.NoError(t, group.Stop(time.Second))
+	close(ok)
+}
+
 // Assert that the given channel receives an object within a second.
 func assertRecv(t *testing.T, ch chan struct{}) {
 	select {
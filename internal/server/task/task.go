@@ -11,6 +11,7 @@ type Task struct {
 	f        Func          // Function to execute.
 	schedule Schedule      // Decides if and when to execute f.
 	reset    chan struct{} // Resets the shedule and starts over.
+	group    *Group        // Group the task belongs to.
 }
 
 // Reset the state of the task as if it had just been started.
@@ -67,12 +68,17 @@ func (t *Task) loop(ctx context.Context) {
 
 		select {
 		case <-timer:
-			if err == nil {
+			if err == nil && t.group.paused.Load() {
+				// Skip this run while the group is paused.
+				delay = schedule
+			} else if err == nil {
 				// Execute the task function synchronously. Consumers
 				// are responsible for implementing proper cancellation
 				// of the task function itself using the tomb's context.
 				start := time.Now()
+				t.group.busy.Add(1)
 				t.f(ctx)
+				t.group.busy.Add(-1)
 				duration := time.Since(start)
 
 				delay = schedule - duration
@@ -0,0 +1,241 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/logger"
+	localtls "github.com/lxc/incus/v6/shared/tls"
+)
+
+// Name of the tracer used for the spans of the daemon.
+const tracerName = "github.com/lxc/incus/v6/internal/server"
+
+// How often the ended spans are exported, and how many of them are kept until then.
+const (
+	exportInterval = 5 * time.Second
+	exportMaxSpans = 4096
+)
+
+// Config is the configuration of the export of the spans.
+type Config struct {
+	// OTLP/HTTP endpoint the spans are exported to, tracing being disabled when empty.
+	Endpoint string
+
+	// Headers added to the export requests, for example to authenticate with the collector.
+	Headers map[string]string
+
+	// PEM encoded CA certificate of the collector, the system ones being used when empty.
+	CACertificate string
+
+	// Ratio of the new traces which are sampled, the traces continued from a parent following its decision.
+	SampleRatio float64
+
+	// Name identifying the spans of this server within a cluster.
+	ServerName string
+}
+
+var propagator = propagation.TraceContext{}
+
+var noopTracer = noop.NewTracerProvider().Tracer(tracerName)
+
+// Serializes the changes of configuration.
+var configMu sync.Mutex
+
+var config Config
+
+// The provider recording the spans, nil when tracing is disabled.
+var provider atomic.Pointer[sdktrace.TracerProvider]
+
+// tracer returns the tracer recording the spans, or a no-op one when tracing is disabled.
+func tracer() trace.Tracer {
+	p := provider.Load()
+	if p == nil {
+		return noopTracer
+	}
+
+	return p.Tracer(tracerName, trace.WithInstrumentationVersion(version.Version))
+}
+
+// newProvider returns a provider exporting the spans as set in the configuration.
+func newProvider(newConfig Config) (*sdktrace.TracerProvider, error) {
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpointURL(newConfig.Endpoint),
+		otlptracehttp.WithHeaders(newConfig.Headers),
+	}
+
+	if strings.HasPrefix(newConfig.Endpoint, "https://") {
+		tlsConfig, err := localtls.GetTLSConfigMem("", "", newConfig.CACertificate, "", false)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading the CA certificate of the collector: %w", err)
+		}
+
+		options = append(options, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewSchemaless(
+		attribute.String("service.name", "incus"),
+		attribute.String("service.version", version.Version),
+		attribute.String("service.instance.id", newConfig.ServerName),
+	)
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(exportInterval), sdktrace.WithMaxQueueSize(exportMaxSpans)),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(newConfig.SampleRatio))),
+	), nil
+}
+
+// shutdownProvider exports the queued spans of a provider and stops it.
+func shutdownProvider(p *sdktrace.TracerProvider) {
+	if p == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportInterval)
+	defer cancel()
+
+	err := p.Shutdown(ctx)
+	if err != nil {
+		logger.Warn("Failed exporting the remaining spans", logger.Ctx{"err": err})
+	}
+}
+
+// Configure sets up the export of the spans, replacing the previous configuration.
+func Configure(newConfig Config) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	if reflect.DeepEqual(newConfig, config) {
+		return nil
+	}
+
+	var newProv *sdktrace.TracerProvider
+	if newConfig.Endpoint != "" {
+		var err error
+		newProv, err = newProvider(newConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	shutdownProvider(provider.Swap(newProv))
+	config = newConfig
+
+	return nil
+}
+
+// Shutdown exports the remaining spans and disables tracing.
+func Shutdown() {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	shutdownProvider(provider.Swap(nil))
+	config = Config{}
+}
+
+// ParseHeaders parses a comma separated list of "<name>=<value>" headers.
+func ParseHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, headerValue, found := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("Invalid header %q, must be in the form <name>=<value>", entry)
+		}
+
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(headerValue)
+	}
+
+	return headers, nil
+}
+
+// Start creates a span with the given name and attributes, child of the span in the context if any.
+// The returned span must be ended by the caller.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartChild creates a span like Start, but only when the context carries a sampled span.
+// This is meant for frequent internal work, which isn't worth tracing unless part of a traced request.
+func StartChild(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !trace.SpanContextFromContext(ctx).IsSampled() {
+		return noopTracer.Start(ctx, name)
+	}
+
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartRequest creates the server span of an API request. When the client is trusted and the request carries a
+// W3C trace context, the span continues the trace of the client.
+func StartRequest(r *http.Request, trusted bool, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx := r.Context()
+	if trusted {
+		ctx = propagator.Extract(ctx, propagation.HeaderCarrier(r.Header))
+	}
+
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...), trace.WithSpanKind(trace.SpanKindServer))
+}
+
+// Inject adds the W3C trace context of the span in the context to the headers of an outgoing request.
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// WithoutCancel returns a context carrying the span of the given context, without its deadline and cancellation.
+// This allows the spans of background work to be linked to the request which triggered it.
+func WithoutCancel(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
+
+// InstanceAttributes returns the attributes identifying an instance in the spans.
+func InstanceAttributes(projectName string, instanceName string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("incus.project", projectName),
+		attribute.String("incus.instance", instanceName),
+	}
+}
+
+// End sets the status of the span from the error, then ends it.
+func End(s trace.Span, err error) {
+	if err != nil {
+		s.RecordError(err)
+		s.SetStatus(codes.Error, err.Error())
+	}
+
+	s.End()
+}
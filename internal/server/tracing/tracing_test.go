@@ -0,0 +1,157 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector returns a server recording the export requests it receives.
+func collector(t *testing.T, authorization string) (*httptest.Server, chan *coltracepb.ExportTraceServiceRequest) {
+	requests := make(chan *coltracepb.ExportTraceServiceRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, authorization, r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		request := &coltracepb.ExportTraceServiceRequest{}
+		assert.NoError(t, proto.Unmarshal(body, request))
+
+		requests <- request
+	}))
+
+	return server, requests
+}
+
+func TestStart_Disabled(t *testing.T) {
+	_, s := Start(context.Background(), "test")
+	defer s.End()
+
+	assert.False(t, s.IsRecording())
+	assert.False(t, s.SpanContext().IsValid())
+}
+
+func TestStart_Export(t *testing.T) {
+	server, requests := collector(t, "Bearer secret")
+	defer server.Close()
+
+	err := Configure(Config{Endpoint: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}, SampleRatio: 1, ServerName: "server01"})
+	require.NoError(t, err)
+	defer Shutdown()
+
+	req := httptest.NewRequest(http.MethodGet, "/1.0", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx, parent := StartRequest(req, true, "GET /1.0")
+	assert.True(t, parent.IsRecording())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", parent.SpanContext().TraceID().String())
+
+	childCtx, child := StartChild(ctx, "child", attribute.String("key", "value"))
+	assert.True(t, child.IsRecording())
+	assert.Equal(t, parent.SpanContext().TraceID(), child.SpanContext().TraceID())
+
+	// The trace context is passed on to the requests made on behalf of the span.
+	header := http.Header{}
+	Inject(childCtx, header)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+child.SpanContext().SpanID().String()+"-01", header.Get("traceparent"))
+
+	End(child, errors.New("Failed"))
+	assert.False(t, child.IsRecording())
+
+	parent.SetStatus(codes.Ok, "")
+	parent.End()
+
+	// Export on shutdown.
+	Shutdown()
+
+	request := <-requests
+	require.Len(t, request.ResourceSpans, 1)
+	require.Len(t, request.ResourceSpans[0].ScopeSpans, 1)
+
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	// The child span ended first.
+	childID := child.SpanContext().SpanID()
+	parentID := parent.SpanContext().SpanID()
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, childID[:], spans[0].SpanId)
+	assert.Equal(t, parentID[:], spans[0].ParentSpanId)
+	assert.Len(t, spans[0].Attributes, 1)
+	assert.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "GET /1.0", spans[1].Name)
+
+	// The remote parent of the request isn't exported.
+	remoteID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	assert.Equal(t, remoteID[:], spans[1].ParentSpanId)
+}
+
+func TestStartRequest_Untrusted(t *testing.T) {
+	server, _ := collector(t, "")
+	defer server.Close()
+
+	err := Configure(Config{Endpoint: server.URL, SampleRatio: 1, ServerName: "server01"})
+	require.NoError(t, err)
+	defer Shutdown()
+
+	req := httptest.NewRequest(http.MethodGet, "/1.0", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	_, s := StartRequest(req, false, "GET /1.0")
+	defer s.End()
+
+	assert.True(t, s.IsRecording())
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", s.SpanContext().TraceID().String())
+}
+
+func TestStart_Sampling(t *testing.T) {
+	server, _ := collector(t, "")
+	defer server.Close()
+
+	err := Configure(Config{Endpoint: server.URL, SampleRatio: 0, ServerName: "server01"})
+	require.NoError(t, err)
+	defer Shutdown()
+
+	ctx, s := Start(context.Background(), "test")
+	defer s.End()
+
+	assert.False(t, s.SpanContext().IsSampled())
+
+	// Frequent work isn't traced outside of sampled traces.
+	_, child := StartChild(ctx, "child")
+	defer child.End()
+
+	assert.False(t, child.IsRecording())
+
+	_, orphan := StartChild(context.Background(), "orphan")
+	defer orphan.End()
+
+	assert.False(t, orphan.IsRecording())
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("authorization=Bearer secret, x-scope-orgid = tenant1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer secret", "X-Scope-Orgid": "tenant1"}, headers)
+
+	headers, err = ParseHeaders("")
+	require.NoError(t, err)
+	assert.Empty(t, headers)
+
+	_, err = ParseHeaders("authorization")
+	assert.Error(t, err)
+}
@@ -210,6 +210,50 @@ func IsRecursionRequest(r *http.Request) bool {
 	return recursion != 0
 }
 
+// PaginationFromRequest returns the limit and offset requested through the "limit" and "offset" form values.
+// A limit of 0 means that all the entries are requested.
+func PaginationFromRequest(r *http.Request) (int, int, error) {
+	parse := func(name string) (int, error) {
+		valueStr := r.FormValue(name)
+		if valueStr == "" {
+			return 0, nil
+		}
+
+		value, err := strconv.Atoi(valueStr)
+		if err != nil || value < 0 {
+			return 0, api.StatusErrorf(http.StatusBadRequest, "Invalid %s %q", name, valueStr)
+		}
+
+		return value, nil
+	}
+
+	limit, err := parse("limit")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset, err := parse("offset")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return limit, offset, nil
+}
+
+// Paginate returns the entries of the page starting at offset and holding up to limit entries (all of them if limit is 0).
+func Paginate[T any](entries []T, limit int, offset int) []T {
+	if offset >= len(entries) {
+		return []T{}
+	}
+
+	entries = entries[offset:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+
+	return entries
+}
+
 // ListenAddresses returns a list of <host>:<port> combinations at which this machine can be reached.
 // It accepts the configured listen address in the following formats: <host>, <host>:<port> or :<port>.
 // If a listen port is not specified then then ports.HTTPSDefaultPort is used instead.
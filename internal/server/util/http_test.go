@@ -38,3 +38,19 @@ func ExampleListenAddresses() {
 	// "foo:8000:9000": [] address foo:8000:9000: too many colons in address
 	// ":::8000": [] address :::8000: too many colons in address
 }
+
+func ExamplePaginate() {
+	entries := []string{"a", "b", "c", "d", "e"}
+
+	fmt.Println(Paginate(entries, 0, 0))
+	fmt.Println(Paginate(entries, 2, 0))
+	fmt.Println(Paginate(entries, 2, 4))
+	fmt.Println(Paginate(entries, 0, 3))
+	fmt.Println(Paginate(entries, 2, 5))
+
+	// Output: [a b c d e]
+	// [a b]
+	// [e]
+	// [d e]
+	// []
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/lxc/incus/v6/shared/units"
 )
 
 // SupportsFilesystem checks whether a given filesystem is already supported
@@ -32,41 +35,83 @@ func SupportsFilesystem(filesystem string) bool {
 	return false
 }
 
-// HugepagesPath attempts to locate the mount point of the hugepages filesystem.
-func HugepagesPath() (string, error) {
-	// Find the source mount of the path
-	file, err := os.Open("/proc/mounts")
+// HugepagesPathForSize attempts to locate the mount point of the hugepages filesystem using the given page size and
+// returns it along with its page size.
+// Mounts without an explicit page size use the default huge page size of the system, and a size of 0 matches any mount.
+func HugepagesPathForSize(size uint64, defaultSize uint64) (string, uint64, error) {
+	mounts, err := os.ReadFile("/proc/mounts")
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
-	defer func() { _ = file.Close() }()
+	return hugepagesPathFromMounts(string(mounts), size, defaultSize)
+}
 
-	scanner := bufio.NewScanner(file)
-	matches := []string{}
-	for scanner.Scan() {
-		line := scanner.Text()
+// hugepagesPathFromMounts locates the mount point of the hugepages filesystem using the given page size in the given
+// mount table.
+func hugepagesPathFromMounts(mounts string, size uint64, defaultSize uint64) (string, uint64, error) {
+	type hugetlbfsMount struct {
+		path string
+		size uint64
+	}
+
+	matches := []hugetlbfsMount{}
+	for _, line := range strings.Split(mounts, "\n") {
 		cols := strings.Fields(line)
-		if len(cols) < 3 {
+		if len(cols) < 4 {
+			continue
+		}
+
+		if cols[2] != "hugetlbfs" {
 			continue
 		}
 
-		if cols[2] == "hugetlbfs" {
-			matches = append(matches, cols[1])
+		pageSize := hugetlbfsPageSize(cols[3], defaultSize)
+		if size != 0 && pageSize != size {
+			continue
 		}
+
+		matches = append(matches, hugetlbfsMount{path: cols[1], size: pageSize})
 	}
 
 	if len(matches) == 0 {
-		return "", fmt.Errorf("No hugetlbfs mount found, can't use hugepages")
+		if size != 0 {
+			return "", 0, fmt.Errorf("No hugetlbfs mount found with a page size of %s, can't use hugepages", units.GetByteSizeStringIEC(int64(size), 0))
+		}
+
+		return "", 0, fmt.Errorf("No hugetlbfs mount found, can't use hugepages")
 	}
 
 	if len(matches) > 1 {
-		if slices.Contains(matches, "/dev/hugepages") {
-			return "/dev/hugepages", nil
+		i := slices.IndexFunc(matches, func(match hugetlbfsMount) bool { return match.path == "/dev/hugepages" })
+		if i >= 0 {
+			return matches[i].path, matches[i].size, nil
+		}
+
+		return "", 0, fmt.Errorf("More than one hugetlbfs instance found and none at standard /dev/hugepages")
+	}
+
+	return matches[0].path, matches[0].size, nil
+}
+
+// hugetlbfsPageSize returns the page size of a hugetlbfs mount from its options.
+func hugetlbfsPageSize(options string, defaultSize uint64) uint64 {
+	for _, option := range strings.Split(options, ",") {
+		value, ok := strings.CutPrefix(option, "pagesize=")
+		if !ok {
+			continue
 		}
 
-		return "", fmt.Errorf("More than one hugetlbfs instance found and none at standard /dev/hugepages")
+		size, err := strconv.ParseUint(value, 10, 64)
+		if err == nil {
+			return size
+		}
+
+		parsed, err := units.ParseByteSizeString(value + "iB")
+		if err == nil {
+			return uint64(parsed)
+		}
 	}
 
-	return matches[0], nil
+	return defaultSize
 }
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHugepagesPathFromMounts(t *testing.T) {
+	const defaultSize = 2 * 1024 * 1024
+
+	tests := []struct {
+		name   string
+		mounts string
+		size   uint64
+		path   string
+		result uint64
+		fails  bool
+	}{
+		{"Default mount", "hugetlbfs /dev/hugepages hugetlbfs rw,relatime,pagesize=2M 0 0", 0, "/dev/hugepages", defaultSize, false},
+		{"Only 1GiB pages", "hugetlbfs /dev/hugepages1G hugetlbfs rw,relatime,pagesize=1024M 0 0", 0, "/dev/hugepages1G", 1024 * 1024 * 1024, false},
+		{"Without page size", "none /mnt/huge hugetlbfs rw,relatime 0 0", 0, "/mnt/huge", defaultSize, false},
+		{"Page size in bytes", "none /mnt/huge hugetlbfs rw,pagesize=2097152 0 0", defaultSize, "/mnt/huge", defaultSize, false},
+		{"Requested size", "hugetlbfs /dev/hugepages hugetlbfs rw,pagesize=2M 0 0\nhugetlbfs /dev/hugepages1G hugetlbfs rw,pagesize=1024M 0 0", 1024 * 1024 * 1024, "/dev/hugepages1G", 1024 * 1024 * 1024, false},
+		{"Standard mount preferred", "hugetlbfs /dev/hugepages hugetlbfs rw,pagesize=2M 0 0\nhugetlbfs /dev/hugepages1G hugetlbfs rw,pagesize=1024M 0 0", 0, "/dev/hugepages", defaultSize, false},
+		{"Several mounts", "none /mnt/a hugetlbfs rw 0 0\nnone /mnt/b hugetlbfs rw 0 0", 0, "", 0, true},
+		{"Missing size", "hugetlbfs /dev/hugepages hugetlbfs rw,pagesize=2M 0 0", 1024 * 1024 * 1024, "", 0, true},
+		{"No mount", "proc /proc proc rw 0 0", 0, "", 0, true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		path, size, err := hugepagesPathFromMounts(tt.mounts, tt.size, defaultSize)
+		if tt.fails {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.path, path)
+		require.Equal(t, tt.result, size)
+	}
+}
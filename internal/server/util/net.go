@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/lxc/incus/v6/internal/ports"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
@@ -238,6 +240,11 @@ func IsWildCardAddress(address string) bool {
 	return false
 }
 
+var sysctlManagedMu sync.Mutex
+
+// sysctlManaged records the sysctls changed by SysctlSet and the value they were set to.
+var sysctlManaged = map[string]string{}
+
 // SysctlGet retrieves the value of a sysctl file in /proc/sys.
 func SysctlGet(path string) (string, error) {
 	// Read the current content
@@ -263,16 +270,39 @@ func SysctlSet(parts ...string) error {
 
 		// Get current value.
 		currentValue, err := SysctlGet(path)
-		if err == nil && currentValue == newValue {
-			// Nothing to update.
-			return nil
+		if err != nil || strings.TrimSpace(currentValue) != newValue {
+			err = os.WriteFile(fmt.Sprintf("/proc/sys/%s", path), []byte(newValue), 0)
+			if err != nil {
+				return err
+			}
 		}
 
-		err = os.WriteFile(fmt.Sprintf("/proc/sys/%s", path), []byte(newValue), 0)
-		if err != nil {
-			return err
-		}
+		// Record the value even if it was already set, as Incus still relies on it (for example when it was
+		// set before a restart).
+		sysctlManagedMu.Lock()
+		sysctlManaged[path] = newValue
+		sysctlManagedMu.Unlock()
 	}
 
 	return nil
 }
+
+// SysctlManaged returns the sysctls changed by SysctlSet that still hold the value it set, indexed by path.
+func SysctlManaged() map[string]string {
+	sysctlManagedMu.Lock()
+	defer sysctlManagedMu.Unlock()
+
+	managed := map[string]string{}
+	for path, value := range sysctlManaged {
+		currentValue, err := SysctlGet(path)
+		if err != nil || strings.TrimSpace(currentValue) != value {
+			// Forget about sysctls that were removed (along with their interface) or changed since.
+			delete(sysctlManaged, path)
+			continue
+		}
+
+		managed[path] = value
+	}
+
+	return managed
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -121,3 +122,18 @@ func TestListenImplicitIPv6Wildcard(t *testing.T) {
 
 	assert.Equal(t, "[::]:9999", listener.Addr().String())
 }
+
+// Sysctls already holding the value are still reported as managed.
+func TestSysctlManagedAlreadySet(t *testing.T) {
+	value, err := util.SysctlGet("net/ipv4/ip_forward")
+	if err != nil {
+		t.Skip("Sysctl not available")
+	}
+
+	value = strings.TrimSpace(value)
+
+	err = util.SysctlSet("net/ipv4/ip_forward", value)
+	require.NoError(t, err)
+
+	assert.Equal(t, value, util.SysctlManaged()["net/ipv4/ip_forward"])
+}
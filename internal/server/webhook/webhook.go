@@ -0,0 +1,217 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"text/template"
+	"time"
+
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+const (
+	contentType  = "application/json"
+	maxErrMsgLen = 1024
+)
+
+// Payload is the data made available to payload templates.
+type Payload struct {
+	api.EventLifecycle
+
+	Timestamp time.Time
+	Location  string
+	Event     api.Event
+}
+
+// Client represents a webhook client notifying URLs about lifecycle events.
+type Client struct {
+	client  *http.Client
+	ctx     context.Context
+	cancel  context.CancelFunc
+	urls    []string
+	actions []string
+	payload *template.Template
+	secret  string
+	retries int
+
+	timeout time.Duration
+	backoff time.Duration
+}
+
+// ParseTemplate parses a payload template.
+func ParseTemplate(payload string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"json": func(value any) (string, error) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+
+			return string(data), nil
+		},
+	}
+
+	return template.New("payload").Funcs(funcs).Option("missingkey=zero").Parse(payload)
+}
+
+// NewClient returns a Client notifying the URLs about the given lifecycle actions (all of them if empty).
+// An empty payload template sends the event itself as JSON and a non-empty secret enables HMAC signing.
+// The URLs are reached through the given proxy function.
+func NewClient(ctx context.Context, urls []string, actions []string, payload string, secret string, retries int, proxy func(req *http.Request) (*url.URL, error)) (*Client, error) {
+	httpClient, err := localUtil.HTTPClient("", proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		client:  httpClient,
+		urls:    urls,
+		actions: actions,
+		secret:  secret,
+		retries: retries,
+		timeout: 10 * time.Second,
+		backoff: time.Second,
+	}
+
+	if payload != "" {
+		tpl, err := ParseTemplate(payload)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing webhook payload template: %w", err)
+		}
+
+		client.payload = tpl
+	}
+
+	client.ctx, client.cancel = context.WithCancel(ctx)
+
+	return client, nil
+}
+
+// Stop stops the client, aborting any pending retries.
+func (c *Client) Stop() {
+	c.cancel()
+}
+
+// HandleEvent notifies the URLs about a lifecycle event.
+func (c *Client) HandleEvent(event api.Event) {
+	if event.Type != api.EventTypeLifecycle {
+		return
+	}
+
+	lifecycleEvent := api.EventLifecycle{}
+	err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+	if err != nil {
+		return
+	}
+
+	if len(c.actions) > 0 && !slices.Contains(c.actions, lifecycleEvent.Action) {
+		return
+	}
+
+	body, err := c.render(event, lifecycleEvent)
+	if err != nil {
+		logger.Warn("Failed rendering webhook payload", logger.Ctx{"action": lifecycleEvent.Action, "err": err})
+		return
+	}
+
+	for _, url := range c.urls {
+		go c.deliver(url, lifecycleEvent.Action, body)
+	}
+}
+
+// render returns the body to send for an event.
+func (c *Client) render(event api.Event, lifecycleEvent api.EventLifecycle) ([]byte, error) {
+	if c.payload == nil {
+		return json.Marshal(event)
+	}
+
+	buf := bytes.Buffer{}
+	err := c.payload.Execute(&buf, Payload{
+		EventLifecycle: lifecycleEvent,
+		Timestamp:      event.Timestamp,
+		Location:       event.Location,
+		Event:          event,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// deliver sends a notification to a URL, retrying with an exponential backoff.
+func (c *Client) deliver(url string, action string, body []byte) {
+	backoff := c.backoff
+
+	for i := 0; ; i++ {
+		status, err := c.send(url, action, body)
+		if err == nil {
+			return
+		}
+
+		// Only retry 429s, 500s and connection-level errors.
+		if i >= c.retries || (status > 0 && status != http.StatusTooManyRequests && status/100 != 5) {
+			logger.Warn("Failed sending webhook notification", logger.Ctx{"url": url, "action": action, "err": err})
+			return
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// send makes a single notification request, returning the HTTP status code if a response was received.
+func (c *Client) send(url string, action string, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(c.ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Incus-Event", action)
+
+	if c.secret != "" {
+		req.Header.Set("X-Incus-Signature-256", fmt.Sprintf("sha256=%s", Sign(c.secret, body)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrMsgLen))
+		return resp.StatusCode, fmt.Errorf("Server returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of a payload.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+type request struct {
+	body      string
+	event     string
+	signature string
+}
+
+func newEvent(t *testing.T, action string) api.Event {
+	metadata, err := json.Marshal(api.EventLifecycle{Action: action, Source: "/1.0/instances/c1", Name: "c1", Project: "default"})
+	require.NoError(t, err)
+
+	return api.Event{Type: api.EventTypeLifecycle, Timestamp: time.Now(), Metadata: metadata}
+}
+
+func newServer(t *testing.T, statuses ...int) (*httptest.Server, chan request) {
+	requests := make(chan request, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status = statuses[0]
+			statuses = statuses[1:]
+		}
+
+		w.WriteHeader(status)
+		requests <- request{body: string(body), event: r.Header.Get("X-Incus-Event"), signature: r.Header.Get("X-Incus-Signature-256")}
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func receive(t *testing.T, requests chan request) request {
+	select {
+	case req := <-requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for webhook request")
+	}
+
+	return request{}
+}
+
+func TestClient(t *testing.T) {
+	server, requests := newServer(t)
+
+	client, err := NewClient(context.Background(), []string{server.URL}, []string{"instance-stopped"}, `{"instance": {{ json .Name }}, "action": "{{ .Action }}"}`, "secret", 0, nil)
+	require.NoError(t, err)
+	defer client.Stop()
+
+	// Actions which aren't selected are ignored.
+	client.HandleEvent(newEvent(t, "instance-created"))
+	client.HandleEvent(newEvent(t, "instance-stopped"))
+
+	req := receive(t, requests)
+	assert.Equal(t, `{"instance": "c1", "action": "instance-stopped"}`, req.body)
+	assert.Equal(t, "instance-stopped", req.event)
+	assert.Equal(t, "sha256="+Sign("secret", []byte(req.body)), req.signature)
+	assert.Empty(t, requests)
+}
+
+func TestClientRetry(t *testing.T) {
+	server, requests := newServer(t, http.StatusServiceUnavailable, http.StatusOK)
+
+	client, err := NewClient(context.Background(), []string{server.URL}, nil, "", "", 3, nil)
+	require.NoError(t, err)
+	defer client.Stop()
+
+	client.backoff = time.Millisecond
+
+	event := newEvent(t, "instance-created")
+	client.HandleEvent(event)
+
+	first := receive(t, requests)
+	second := receive(t, requests)
+	assert.Equal(t, first.body, second.body)
+	assert.Empty(t, first.signature)
+
+	received := api.Event{}
+	require.NoError(t, json.Unmarshal([]byte(second.body), &received))
+	assert.Equal(t, event.Type, received.Type)
+}
+
+func TestClientProxy(t *testing.T) {
+	proxy, requests := newServer(t)
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	// The webhook URL is only reachable through the proxy.
+	client, err := NewClient(context.Background(), []string{"http://webhook.invalid/hook"}, nil, "", "", 0, http.ProxyURL(proxyURL))
+	require.NoError(t, err)
+	defer client.Stop()
+
+	client.HandleEvent(newEvent(t, "instance-created"))
+
+	req := receive(t, requests)
+	assert.Equal(t, "instance-created", req.event)
+}
+
+func TestParseTemplate(t *testing.T) {
+	_, err := ParseTemplate("{{ .Name ")
+	assert.Error(t, err)
+
+	_, err = ParseTemplate("{{ .Name }}")
+	assert.NoError(t, err)
+}
@@ -1,5 +1,9 @@
 package sql
 
+import (
+	"time"
+)
+
 // SQLDump represents a full database dump.
 type SQLDump struct {
 	Text string `json:"text" yaml:"text"`
@@ -23,3 +27,27 @@ type SQLResult struct {
 	Rows         [][]any  `json:"rows"          yaml:"rows"`
 	RowsAffected int64    `json:"rows_affected" yaml:"rows_affected"`
 }
+
+// SQLBackup represents a backup of the global database.
+type SQLBackup struct {
+	Name      string    `json:"name"       yaml:"name"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	Size      int64     `json:"size"       yaml:"size"`
+}
+
+// SQLRestore represents a request to restore the global database from a backup.
+// The backup is either selected by name or as the most recent one created at or before the timestamp.
+type SQLRestore struct {
+	Name      string    `json:"name"      yaml:"name"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	DryRun    bool      `json:"dry_run"   yaml:"dry_run"`
+}
+
+// SQLRestoreResult represents the rows changed by a global database restore.
+// Previous is the backup of the content replaced by the restore, which isn't set for dry runs.
+type SQLRestoreResult struct {
+	Backup   SQLBackup  `json:"backup"   yaml:"backup"`
+	Previous *SQLBackup `json:"previous" yaml:"previous"`
+	Added    []string   `json:"added"    yaml:"added"`
+	Removed  []string   `json:"removed"  yaml:"removed"`
+}
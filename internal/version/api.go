@@ -421,6 +421,95 @@ var APIExtensions = []string{
 	"network_acls_all_projects",
 	"storage_buckets_all_projects",
 	"resources_load",
+	"instance_nic_routed_delegated_prefixes",
+	"instances_idle_detection",
+	"network_bridge_limits",
+	"guestapi_leases",
+	"events_filtering_replay",
+	"network_bridge_metadata_service",
+	"events_webhooks",
+	"operations_concurrency_limits",
+	"instance_ssh_authorized_keys",
+	"network_bridge_mdns",
+	"image_download_resume",
+	"cluster_images_replication",
+	"catalog",
+	"image_build",
+	"instance_limits_fds",
+	"image_auto_rebase",
+	"profile_preview",
+	"instance_autorestart",
+	"instance_snapshot_tree",
+	"instances_shutdown_checkpoint",
+	"instance_gpu_usage",
+	"instance_exec_structured",
+	"instance_health_checks",
+	"instance_boot_depends_on",
+	"resources_idmap",
+	"instance_groups",
+	"instance_idmap_passthrough",
+	"instance_rename_running",
+	"storage_bucket_quota_lifecycle",
+	"storage_volume_transfer",
+	"storage_ceph_rbd_mirroring",
+	"storage_ceph_rbd_mirroring_volume_role",
+	"storage_volume_state_io",
+	"storage_lvmcluster_remote_target",
+	"storage_pool_health",
+	"storage_volume_live_move",
+	"storage_volume_import_disk",
+	"disk_source_templates",
+	"recycle_bin",
+	"instance_shared_memory",
+	"instance_cpu_power",
+	"api_rate_limit",
+	"instance_coredumps",
+	"instance_kernel_modules_on_demand",
+	"instances_batch",
+	"instance_syslog",
+	"event_lifecycle_enrichment",
+	"api_pagination",
+	"query_list",
+	"cluster_architecture_placement",
+	"warnings_remediation",
+	"cluster_edge_mode",
+	"instances_placement_external",
+	"server_https_reverse_proxy",
+	"instances_cpu_model",
+	"instances_numa_placement",
+	"instance_snapshot_type",
+	"hugepages_management",
+	"instance_copy_transfer_method",
+	"cluster_member_managed_rules",
+	"pci_iommu_group",
+	"instance_drain",
+	"vm_device_acceleration",
+	"image_optimize",
+	"instance_convert",
+	"instance_pools",
+	"instance_identity",
+	"clustering_config_rollout",
+	"instance_socket_activation",
+	"instance_bootlog",
+	"instance_idle_suspend",
+	"metrics_remote_write",
+	"instance_shares",
+	"instance_backup_network_state",
+	"server_tracing",
+	"projects_storage_volume_defaults",
+	"cluster_time_skew_threshold",
+	"instance_state_stream",
+	"server_limits_reserve",
+	"container_cpu_features",
+	"lxcfs_management",
+	"instance_pressure",
+	"instance_oom_policy",
+	"image_git_recipes",
+	"instance_refresh_schedule",
+	"instance_apparmor_profiles",
+	"instance_create_from_volume",
+	"backups_database_count",
+	"network_state_bridge_limits",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
@@ -0,0 +1,64 @@
+package api
+
+// Catalog represents the images and instance sizes available to launch instances.
+//
+// swagger:model
+//
+// API extension: catalog.
+type Catalog struct {
+	// Images available through an alias
+	Images []CatalogImage `json:"images" yaml:"images"`
+
+	// Instance size presets
+	Sizes []CatalogSize `json:"sizes" yaml:"sizes"`
+}
+
+// CatalogImage represents an image alias listed in the catalog.
+//
+// swagger:model
+//
+// API extension: catalog.
+type CatalogImage struct {
+	// Alias name
+	// Example: ubuntu-24.04
+	Alias string `json:"alias" yaml:"alias"`
+
+	// Alias description
+	// Example: Our preferred Ubuntu image
+	Description string `json:"description" yaml:"description"`
+
+	// Target image fingerprint
+	// Example: 06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+
+	// Type of image (container or virtual-machine)
+	// Example: container
+	Type string `json:"type" yaml:"type"`
+
+	// Architecture of the image
+	// Example: x86_64
+	Architecture string `json:"architecture" yaml:"architecture"`
+
+	// Descriptive properties of the image
+	// Example: {"os": "Ubuntu", "release": "noble", "variant": "cloud"}
+	Properties map[string]string `json:"properties" yaml:"properties"`
+}
+
+// CatalogSize represents an instance size preset listed in the catalog.
+//
+// swagger:model
+//
+// API extension: catalog.
+type CatalogSize struct {
+	// Name of the size, usable as the instance type when creating instances
+	// Example: aws:t2.micro
+	Name string `json:"name" yaml:"name"`
+
+	// Number of CPUs (can be a fraction)
+	// Example: 1
+	CPU float64 `json:"cpu" yaml:"cpu"`
+
+	// Amount of memory in bytes
+	// Example: 1073741824
+	Memory int64 `json:"memory" yaml:"memory"`
+}
@@ -309,3 +309,26 @@ type ClusterGroupPut struct {
 func (c *ClusterGroup) Writable() ClusterGroupPut {
 	return c.ClusterGroupPut
 }
+
+// ClusterConfigRolloutPost represents a cluster-wide configuration change applied to canary members first.
+//
+// swagger:model
+//
+// API extension: clustering_config_rollout.
+type ClusterConfigRolloutPost struct {
+	// Cluster-wide configuration keys to change
+	// Example: {"loki.api.url": "https://loki.example.net"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Cluster members the change is applied to first (defaults to one member)
+	// Example: ["server01"]
+	Canaries []string `json:"canaries" yaml:"canaries"`
+
+	// How long to wait before verifying the canary members, in seconds (defaults to 30)
+	// Example: 60
+	Period int64 `json:"period" yaml:"period"`
+
+	// Only validate the change and select the canary members
+	// Example: false
+	DryRun bool `json:"dry_run" yaml:"dry_run"`
+}
@@ -26,3 +26,33 @@ type ClusterMemberState struct {
 	SysInfo      ClusterMemberSysInfo        `json:"sysinfo" yaml:"sysinfo"`
 	StoragePools map[string]StoragePoolState `json:"storage_pools" yaml:"storage_pools"`
 }
+
+// ClusterMemberManagedRuleStatusApplied is the status of the rules Incus applied on the cluster member.
+const ClusterMemberManagedRuleStatusApplied = "applied"
+
+// ClusterMemberManagedRuleStatusRequired is the status of the rules Incus didn't apply because of
+// network.firewall.strict and that the administrator must allow.
+const ClusterMemberManagedRuleStatusRequired = "required"
+
+// ClusterMemberManagedRule represents a firewall rule, OVN ACL or sysctl managed by Incus on a cluster member.
+//
+// swagger:model
+//
+// API extension: cluster_member_managed_rules.
+type ClusterMemberManagedRule struct {
+	// Kind of rule (nftables, iptables, ip6tables, ovn or sysctl)
+	// Example: nftables
+	Type string `json:"type" yaml:"type"`
+
+	// Table and chain, OVN entity or sysctl the rule applies to
+	// Example: inet incus in.incusbr0
+	Location string `json:"location" yaml:"location"`
+
+	// Rule or sysctl value
+	// Example: iifname "incusbr0" udp dport 67 accept
+	Rule string `json:"rule" yaml:"rule"`
+
+	// Whether the rule is applied or must be allowed by the administrator (applied or required)
+	// Example: applied
+	Status string `json:"status" yaml:"status"`
+}
@@ -8,10 +8,11 @@ import (
 
 // Event types.
 const (
-	EventTypeLifecycle  = "lifecycle"
-	EventTypeLogging    = "logging"
-	EventTypeOperation  = "operation"
-	EventTypeNetworkACL = "network-acl"
+	EventTypeLifecycle   = "lifecycle"
+	EventTypeLogging     = "logging"
+	EventTypeOperation   = "operation"
+	EventTypeNetworkACL  = "network-acl"
+	EventTypeInstanceLog = "instance-log"
 )
 
 // Event represents an event entry (over websocket)
@@ -41,11 +42,17 @@ type Event struct {
 	//
 	// API extension: event_project
 	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+
+	// Identifier of the event in the server's event journal
+	// Example: 1234
+	//
+	// API extension: events_filtering_replay
+	ID uint64 `yaml:"id,omitempty" json:"id,omitempty"`
 }
 
 // ToLogging creates log record for the event.
 func (event *Event) ToLogging() (EventLogRecord, error) {
-	if event.Type == EventTypeLogging || event.Type == EventTypeNetworkACL {
+	if event.Type == EventTypeLogging || event.Type == EventTypeNetworkACL || event.Type == EventTypeInstanceLog {
 		e := &EventLogging{}
 		err := json.Unmarshal(event.Metadata, &e)
 		if err != nil {
@@ -152,6 +159,26 @@ type EventLifecycle struct {
 	// API extension: event_lifecycle_name_and_project
 	Name    string `yaml:"name,omitempty" json:"name,omitempty"`
 	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+
+	// API extension: event_lifecycle_enrichment
+	Enrichment *EventLifecycleEnrichment `yaml:"enrichment,omitempty" json:"enrichment,omitempty"`
+}
+
+// EventLifecycleEnrichment represents the instance fields embedded in a lifecycle event
+//
+// API extension: event_lifecycle_enrichment.
+type EventLifecycleEnrichment struct {
+	// Selected configuration keys of the instance
+	// Example: {"limits.cpu": "4"}
+	Config map[string]string `yaml:"config,omitempty" json:"config,omitempty"`
+
+	// Labels of the instance (user.* configuration keys, without the prefix)
+	// Example: {"team": "web"}
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	// Origin of the instance image (image.* configuration keys, without the prefix, and the fingerprint)
+	// Example: {"os": "Debian", "release": "bookworm", "fingerprint": "a0dd6e2b7d2b"}
+	Image map[string]string `yaml:"image,omitempty" json:"image,omitempty"`
 }
 
 // EventLifecycleRequestor represents the initial requestor for an event
@@ -28,6 +28,8 @@ const (
 	EventLifecycleImageRetrieved                    = "image-retrieved"
 	EventLifecycleImageSecretCreated                = "image-secret-created"
 	EventLifecycleImageUpdated                      = "image-updated"
+	EventLifecycleInstanceAutoRestartFailed         = "instance-auto-restart-failed"
+	EventLifecycleInstanceAutoRestarted             = "instance-auto-restarted"
 	EventLifecycleInstanceBackupCreated             = "instance-backup-created"
 	EventLifecycleInstanceBackupDeleted             = "instance-backup-deleted"
 	EventLifecycleInstanceBackupRenamed             = "instance-backup-renamed"
@@ -35,12 +37,24 @@ const (
 	EventLifecycleInstanceConsole                   = "instance-console"
 	EventLifecycleInstanceConsoleReset              = "instance-console-reset"
 	EventLifecycleInstanceConsoleRetrieved          = "instance-console-retrieved"
+	EventLifecycleInstanceCoreDumpCreated           = "instance-coredump-created"
+	EventLifecycleInstanceCoreDumpDeleted           = "instance-coredump-deleted"
+	EventLifecycleInstanceCoreDumpRetrieved         = "instance-coredump-retrieved"
 	EventLifecycleInstanceCreated                   = "instance-created"
 	EventLifecycleInstanceDeleted                   = "instance-deleted"
+	EventLifecycleInstanceDrainFinished             = "instance-drain-finished"
+	EventLifecycleInstanceDrainStarted              = "instance-drain-started"
 	EventLifecycleInstanceExec                      = "instance-exec"
 	EventLifecycleInstanceFileDeleted               = "instance-file-deleted"
 	EventLifecycleInstanceFilePushed                = "instance-file-pushed"
 	EventLifecycleInstanceFileRetrieved             = "instance-file-retrieved"
+	EventLifecycleInstanceGroupCreated              = "instance-group-created"
+	EventLifecycleInstanceGroupDeleted              = "instance-group-deleted"
+	EventLifecycleInstanceGroupUpdated              = "instance-group-updated"
+	EventLifecycleInstanceHealthChanged             = "instance-health-changed"
+	EventLifecycleInstanceIdleSuspended             = "instance-idle-suspended"
+	EventLifecycleInstanceKernelModuleLoaded        = "instance-kernel-module-loaded"
+	EventLifecycleInstanceLimitReached              = "instance-limit-reached"
 	EventLifecycleInstanceLogDeleted                = "instance-log-deleted"
 	EventLifecycleInstanceLogRetrieved              = "instance-log-retrieved"
 	EventLifecycleInstanceMetadataRetrieved         = "instance-metadata-retrieved"
@@ -48,12 +62,22 @@ const (
 	EventLifecycleInstanceMetadataTemplateDeleted   = "instance-metadata-template-deleted"
 	EventLifecycleInstanceMetadataTemplateRetrieved = "instance-metadata-template-retrieved"
 	EventLifecycleInstanceMetadataUpdated           = "instance-metadata-updated"
+	EventLifecycleInstanceOOMKilled                 = "instance-oom-killed"
 	EventLifecycleInstancePaused                    = "instance-paused"
+	EventLifecycleInstancePoolAcquired              = "instance-pool-acquired"
+	EventLifecycleInstancePoolCreated               = "instance-pool-created"
+	EventLifecycleInstancePoolDeleted               = "instance-pool-deleted"
+	EventLifecycleInstancePoolReleased              = "instance-pool-released"
+	EventLifecycleInstancePoolUpdated               = "instance-pool-updated"
 	EventLifecycleInstanceReady                     = "instance-ready"
+	EventLifecycleInstanceRefreshed                 = "instance-refreshed"
 	EventLifecycleInstanceRenamed                   = "instance-renamed"
 	EventLifecycleInstanceRestarted                 = "instance-restarted"
 	EventLifecycleInstanceRestored                  = "instance-restored"
 	EventLifecycleInstanceResumed                   = "instance-resumed"
+	EventLifecycleInstanceShareCreated              = "instance-share-created"
+	EventLifecycleInstanceShareDeleted              = "instance-share-deleted"
+	EventLifecycleInstanceShareUsed                 = "instance-share-used"
 	EventLifecycleInstanceShutdown                  = "instance-shutdown"
 	EventLifecycleInstanceSnapshotCreated           = "instance-snapshot-created"
 	EventLifecycleInstanceSnapshotDeleted           = "instance-snapshot-deleted"
@@ -100,6 +124,8 @@ const (
 	EventLifecycleProjectUpdated                    = "project-updated"
 	EventLifecycleStoragePoolCreated                = "storage-pool-created"
 	EventLifecycleStoragePoolDeleted                = "storage-pool-deleted"
+	EventLifecycleStoragePoolDemoted                = "storage-pool-demoted"
+	EventLifecycleStoragePoolPromoted               = "storage-pool-promoted"
 	EventLifecycleStoragePoolUpdated                = "storage-pool-updated"
 	EventLifecycleStorageBucketBackupCreated        = "storage-bucket-backup-created"
 	EventLifecycleStorageBucketBackupDeleted        = "storage-bucket-backup-deleted"
@@ -117,14 +143,18 @@ const (
 	EventLifecycleStorageVolumeBackupRenamed        = "storage-volume-backup-renamed"
 	EventLifecycleStorageVolumeBackupRetrieved      = "storage-volume-backup-retrieved"
 	EventLifecycleStorageVolumeDeleted              = "storage-volume-deleted"
+	EventLifecycleStorageVolumeDemoted              = "storage-volume-demoted"
+	EventLifecycleStorageVolumePromoted             = "storage-volume-promoted"
 	EventLifecycleStorageVolumeRenamed              = "storage-volume-renamed"
 	EventLifecycleStorageVolumeRestored             = "storage-volume-restored"
 	EventLifecycleStorageVolumeSnapshotCreated      = "storage-volume-snapshot-created"
 	EventLifecycleStorageVolumeSnapshotDeleted      = "storage-volume-snapshot-deleted"
 	EventLifecycleStorageVolumeSnapshotRenamed      = "storage-volume-snapshot-renamed"
 	EventLifecycleStorageVolumeSnapshotUpdated      = "storage-volume-snapshot-updated"
+	EventLifecycleStorageVolumeTransferred          = "storage-volume-transferred"
 	EventLifecycleStorageVolumeUpdated              = "storage-volume-updated"
 	EventLifecycleWarningAcknowledged               = "warning-acknowledged"
 	EventLifecycleWarningDeleted                    = "warning-deleted"
 	EventLifecycleWarningReset                      = "warning-reset"
+	EventLifecycleWarningResolved                   = "warning-resolved"
 )
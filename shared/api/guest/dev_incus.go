@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // DevIncusPut represents the modifiable data.
 type DevIncusPut struct {
 	// Instance state
@@ -23,3 +27,33 @@ type DevIncusGet struct {
 	// Example: server01
 	Location string `json:"location" yaml:"location"`
 }
+
+// DevIncusLeasePut represents the modifiable fields of a guest API lease.
+//
+// API extension: guestapi_leases.
+type DevIncusLeasePut struct {
+	// How long to hold the lease for (in seconds)
+	// Example: 30
+	Duration int64 `json:"duration" yaml:"duration"`
+}
+
+// DevIncusLease represents a named lease shared by the instances of a project.
+//
+// API extension: guestapi_leases.
+type DevIncusLease struct {
+	// Lease name
+	// Example: leader
+	Name string `json:"name" yaml:"name"`
+
+	// Name of the instance holding the lease (empty if not held)
+	// Example: c1
+	Holder string `json:"holder" yaml:"holder"`
+
+	// Fencing token, incremented every time the lease changes holder
+	// Example: 3
+	Token int64 `json:"token" yaml:"token"`
+
+	// When the lease expires unless renewed
+	// Example: 2021-03-23T20:00:00-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
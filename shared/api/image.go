@@ -38,6 +38,21 @@ type ImageExportPost struct {
 	Profiles []string `json:"profiles" yaml:"profiles"`
 }
 
+// ImageOptimizePost represents the fields required to convert the stored files of an image
+//
+// swagger:model
+//
+// API extension: image_optimize.
+type ImageOptimizePost struct {
+	// Compression algorithm to use for the converted image (defaults to images.compression_algorithm)
+	// Example: zstd -19
+	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
+
+	// Format of the converted image (unified or split, empty to keep the current one)
+	// Example: unified
+	Format string `json:"format" yaml:"format"`
+}
+
 // ImagesPost represents the fields available for a new image
 //
 // swagger:model
@@ -74,7 +89,7 @@ type ImagesPostSource struct {
 	// Example: pull
 	Mode string `json:"mode" yaml:"mode"`
 
-	// Type of image source (instance, snapshot, image or url)
+	// Type of image source (instance, snapshot, image, url or build)
 	// Example: instance
 	Type string `json:"type" yaml:"type"`
 
@@ -99,6 +114,12 @@ type ImagesPostSource struct {
 	//
 	// API extension: image_source_project
 	Project string `json:"project" yaml:"project"`
+
+	// Image definition in the distrobuilder format (for type "build")
+	// Example: image:\n  distribution: alpine\nsource:\n  downloader: alpinelinux-http\n
+	//
+	// API extension: image_build
+	Definition string `json:"definition,omitempty" yaml:"definition,omitempty"`
 }
 
 // ImagePut represents the modifiable fields of an image
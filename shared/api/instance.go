@@ -27,6 +27,15 @@ const InstanceTypeContainer = InstanceType("container")
 // InstanceTypeVM defines the instance type value for a virtual-machine.
 const InstanceTypeVM = InstanceType("virtual-machine")
 
+// InstanceTransferMethodClone is the transfer method of instance copies cloned by the storage driver without copying their data.
+const InstanceTransferMethodClone = "clone"
+
+// InstanceTransferMethodCopy is the transfer method of instance copies whose data is copied by the storage driver within the storage pool.
+const InstanceTransferMethodCopy = "copy"
+
+// InstanceTransferMethodMigration is the transfer method of instance copies whose data is streamed through the migration protocol.
+const InstanceTransferMethodMigration = "migration"
+
 // InstancesPost represents the fields available for a new instance.
 //
 // swagger:model
@@ -197,6 +206,20 @@ type InstanceRebuildPost struct {
 	Source InstanceSource `json:"source" yaml:"source"`
 }
 
+// InstanceConvertPost indicates how to convert an instance to another instance type.
+//
+// swagger:model
+//
+// API extension: instance_convert.
+type InstanceConvertPost struct {
+	// Type to convert the instance to
+	// Example: virtual-machine
+	Type InstanceType `json:"type" yaml:"type"`
+
+	// Image providing the kernel and boot configuration of the new instance type (defaults to the instance's image)
+	Source InstanceSource `json:"source" yaml:"source"`
+}
+
 // Instance represents an instance.
 //
 // swagger:model
@@ -346,7 +369,7 @@ type InstanceSource struct {
 	// Example: {"criu": "RANDOM-STRING", "rsync": "RANDOM-STRING"}
 	Websockets map[string]string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
 
-	// Existing instance name or snapshot (for copy)
+	// Existing instance name or snapshot (for copy), or block custom volume name (for volume)
 	// Example: foo/snap0
 	Source string `json:"source,omitempty" yaml:"source,omitempty"`
 
@@ -371,4 +394,10 @@ type InstanceSource struct {
 	//
 	// API extension: instance_allow_inconsistent_copy
 	AllowInconsistent bool `json:"allow_inconsistent" yaml:"allow_inconsistent"`
+
+	// Storage pool of the block custom volume (for volume, defaults to the pool of the root disk)
+	// Example: default
+	//
+	// API extension: instance_create_from_volume
+	Pool string `json:"pool,omitempty" yaml:"pool,omitempty"`
 }
@@ -0,0 +1,34 @@
+package api
+
+// InstanceAppArmor represents the AppArmor profiles loaded for use inside an instance.
+//
+// swagger:model
+//
+// API extension: instance_apparmor_profiles.
+type InstanceAppArmor struct {
+	// AppArmor namespace of the instance
+	// Example: incus-foo_<var-lib-incus>
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Profiles defined in raw.apparmor.profiles
+	Profiles []InstanceAppArmorProfile `json:"profiles" yaml:"profiles"`
+}
+
+// InstanceAppArmorProfile represents an AppArmor profile defined in raw.apparmor.profiles.
+//
+// swagger:model
+//
+// API extension: instance_apparmor_profiles.
+type InstanceAppArmorProfile struct {
+	// Profile name
+	// Example: nginx
+	Name string `json:"name" yaml:"name"`
+
+	// Whether the profile is loaded in the namespace of the instance
+	// Example: true
+	Loaded bool `json:"loaded" yaml:"loaded"`
+
+	// Mode of the loaded profile (enforce, complain, kill, unconfined)
+	// Example: enforce
+	Mode string `json:"mode" yaml:"mode"`
+}
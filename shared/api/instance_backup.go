@@ -31,6 +31,12 @@ type InstanceBackupsPost struct {
 	//
 	// API extension: backup_compression_algorithm
 	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
+
+	// Whether to include the network dependencies of the instance (ACLs, forwards and DNS records)
+	// Example: true
+	//
+	// API extension: instance_backup_network_state
+	NetworkState bool `json:"network_state" yaml:"network_state"`
 }
 
 // InstanceBackup represents an instance backup.
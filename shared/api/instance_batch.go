@@ -0,0 +1,69 @@
+package api
+
+// InstancesBatchPost represents a list of actions to run on instances.
+//
+// swagger:model
+//
+// API extension: instances_batch.
+type InstancesBatchPost struct {
+	// List of actions to run
+	Actions []InstancesBatchAction `json:"actions" yaml:"actions"`
+
+	// Maximum number of actions running at the same time (0 for the default)
+	// Example: 4
+	Parallelism int `json:"parallelism" yaml:"parallelism"`
+}
+
+// InstancesBatchAction represents an action to run on an instance as part of a batch.
+//
+// swagger:model
+//
+// API extension: instances_batch.
+type InstancesBatchAction struct {
+	// Name of the instance
+	// Example: foo
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Action to run (start, stop, delete or snapshot)
+	// Example: stop
+	Action string `json:"action" yaml:"action"`
+
+	// How long to wait (in s) for the instance to shut down cleanly, 0 for the default (for stop)
+	// Example: 30
+	Timeout int `json:"timeout" yaml:"timeout"`
+
+	// Whether to force the action (for stop, and for delete to stop a running instance first)
+	// Example: false
+	Force bool `json:"force" yaml:"force"`
+
+	// Whether to use the runtime state (for start, stop and snapshot)
+	// Example: false
+	Stateful bool `json:"stateful" yaml:"stateful"`
+
+	// Name of the snapshot to create, generated if empty (for snapshot)
+	// Example: snap0
+	Snapshot string `json:"snapshot" yaml:"snapshot"`
+}
+
+// InstancesBatchResult represents the status of an action of a batch.
+//
+// swagger:model
+//
+// API extension: instances_batch.
+type InstancesBatchResult struct {
+	// Name of the instance
+	// Example: foo
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Action run on the instance
+	// Example: stop
+	Action string `json:"action" yaml:"action"`
+
+	// Status of the action (Pending, Running, Success or Failure)
+	// Example: Success
+	Status string `json:"status" yaml:"status"`
+
+	// Error message of a failed action
+	// Example: Instance is running
+	Error string `json:"error" yaml:"error"`
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceBootLog represents the diagnostics collected about the last start attempt of an instance.
+//
+// swagger:model
+//
+// API extension: instance_bootlog.
+type InstanceBootLog struct {
+	// Instance status when the report was collected
+	// Example: Stopped
+	Status string `json:"status" yaml:"status"`
+
+	// When the report was collected
+	// Example: 2024-05-02T10:15:12Z
+	CollectedAt time.Time `json:"collected_at" yaml:"collected_at"`
+
+	// Kernel log entries from before this time are left out
+	// Example: 2024-05-02T10:10:03Z
+	Since time.Time `json:"since" yaml:"since"`
+
+	// Content of the instance log (lxc.log or qemu.log) of the last start attempt
+	// Example: lxc c1 20240502101512.345 ERROR    start - ../src/lxc/start.c:start:2197 - No such file or directory
+	Log string `json:"log" yaml:"log"`
+
+	// Console output of the last start attempt
+	// Example: Failed to mount proc at /proc: Operation not permitted
+	Console string `json:"console" yaml:"console"`
+
+	// AppArmor denials of the instance found in the kernel log
+	// Example: ["audit: type=1400 audit(1714644912.345:42): apparmor=\"DENIED\" operation=\"mount\" ..."]
+	AppArmorDenials []string `json:"apparmor_denials" yaml:"apparmor_denials"`
+
+	// Seccomp violations of the instance found in the kernel log
+	// Example: ["audit: type=1326 audit(1714644912.345:43): ... syscall=165 ..."]
+	SeccompViolations []string `json:"seccomp_violations" yaml:"seccomp_violations"`
+
+	// Errors of the instance log related to the id mapping and control group setup
+	// Example: ["lxc c1 20240502101512.345 ERROR    cgfsng - ../src/lxc/cgroups/cgfsng.c:cgfsng_setup_limits:3209 - No such file or directory"]
+	SetupErrors []string `json:"setup_errors" yaml:"setup_errors"`
+
+	// Problems encountered while collecting the report
+	// Example: ["Failed reading kernel log: operation not permitted"]
+	Warnings []string `json:"warnings" yaml:"warnings"`
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceCoreDump represents a core dump captured from an instance.
+//
+// swagger:model
+//
+// API extension: instance_coredumps.
+type InstanceCoreDump struct {
+	// Core dump file name
+	// Example: 20240502T101512Z_nginx_1234.core
+	Name string `json:"name" yaml:"name"`
+
+	// Size of the core dump in bytes
+	// Example: 2097152
+	Size int64 `json:"size" yaml:"size"`
+
+	// When the core dump was captured
+	// Example: 2024-05-02T10:15:12Z
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
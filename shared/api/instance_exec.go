@@ -53,4 +53,41 @@ type InstanceExecPost struct {
 	// Current working directory for the command
 	// Example: /home/foo/
 	Cwd string `json:"cwd" yaml:"cwd"`
+
+	// Whether to run the command through the agent and return its result directly (virtual machines only, requires non-interactive)
+	// Example: false
+	//
+	// API extension: instance_exec_structured
+	Structured bool `json:"structured" yaml:"structured"`
+}
+
+// InstanceExecResult represents the result of a structured instance exec request.
+//
+// swagger:model
+//
+// API extension: instance_exec_structured.
+type InstanceExecResult struct {
+	// Exit code of the command
+	// Example: 0
+	ExitCode int `json:"exit_code" yaml:"exit_code"`
+
+	// Captured standard output
+	// Example: hello world
+	Stdout string `json:"stdout" yaml:"stdout"`
+
+	// Captured standard error
+	// Example: warning: something happened
+	Stderr string `json:"stderr" yaml:"stderr"`
+
+	// Whether the captured output was truncated
+	// Example: false
+	OutputTruncated bool `json:"output_truncated" yaml:"output_truncated"`
+
+	// Duration of the command in nanoseconds
+	// Example: 1500000000
+	Duration int64 `json:"duration" yaml:"duration"`
+
+	// Peak resident set size of the command in bytes
+	// Example: 10485760
+	MaxRSS int64 `json:"max_rss" yaml:"max_rss"`
 }
@@ -0,0 +1,56 @@
+package api
+
+// InstanceGroupsPost represents the fields of a new instance group
+//
+// swagger:model
+//
+// API extension: instance_groups.
+type InstanceGroupsPost struct {
+	InstanceGroupPut `yaml:",inline"`
+
+	// The name of the group
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+}
+
+// InstanceGroupPut represents the modifiable fields of an instance group
+//
+// swagger:model
+//
+// API extension: instance_groups.
+type InstanceGroupPut struct {
+	// Description of the group
+	// Example: Web servers
+	Description string `json:"description" yaml:"description"`
+
+	// Placement policy of the members of the group (affinity or anti-affinity)
+	// Example: anti-affinity
+	Policy string `json:"policy" yaml:"policy"`
+}
+
+// InstanceGroup represents a group of instances placed together or apart in a cluster
+//
+// swagger:model
+//
+// API extension: instance_groups.
+type InstanceGroup struct {
+	InstanceGroupPut `yaml:",inline"`
+
+	// The name of the group
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+
+	// Project name
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// List of URLs of the instances in the group
+	// Read only: true
+	// Example: ["/1.0/instances/web1", "/1.0/instances/web2"]
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+}
+
+// Writable converts a full InstanceGroup struct into a InstanceGroupPut struct (filters read-only fields).
+func (g *InstanceGroup) Writable() InstanceGroupPut {
+	return g.InstanceGroupPut
+}
@@ -0,0 +1,108 @@
+package api
+
+// InstancePoolsPost represents the fields of a new instance pool
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePoolsPost struct {
+	InstancePoolPut `yaml:",inline"`
+
+	// The name of the pool
+	// Example: ci
+	Name string `json:"name" yaml:"name"`
+}
+
+// InstancePoolPut represents the modifiable fields of an instance pool
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePoolPut struct {
+	// Description of the pool
+	// Example: CI runners
+	Description string `json:"description" yaml:"description"`
+
+	// Alias or fingerprint of the local image the instances are created from
+	// Example: debian/12
+	Image string `json:"image" yaml:"image"`
+
+	// Type of the instances (container or virtual-machine)
+	// Example: container
+	Type InstanceType `json:"type" yaml:"type"`
+
+	// List of profiles applied to the instances
+	// Example: ["default"]
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Number of started instances kept ready to be acquired
+	// Example: 5
+	Size int `json:"size" yaml:"size"`
+
+	// What to do with the instances when they're released (recycle or destroy)
+	// Example: recycle
+	Release string `json:"release" yaml:"release"`
+}
+
+// InstancePool represents a pool of pre-started instances handed out on request
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePool struct {
+	InstancePoolPut `yaml:",inline"`
+
+	// The name of the pool
+	// Example: ci
+	Name string `json:"name" yaml:"name"`
+
+	// Project name
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Number of instances ready to be acquired
+	// Read only: true
+	// Example: 5
+	Ready int `json:"ready" yaml:"ready"`
+
+	// Number of instances currently acquired
+	// Read only: true
+	// Example: 2
+	Acquired int `json:"acquired" yaml:"acquired"`
+
+	// List of URLs of the instances of the pool
+	// Read only: true
+	// Example: ["/1.0/instances/ci-4f2b9a1c", "/1.0/instances/ci-8c3d0e7f"]
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+}
+
+// Writable converts a full InstancePool struct into a InstancePoolPut struct (filters read-only fields).
+func (p *InstancePool) Writable() InstancePoolPut {
+	return p.InstancePoolPut
+}
+
+// InstancePoolMember represents an instance handed out by an instance pool
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePoolMember struct {
+	// Name of the instance
+	// Example: ci-4f2b9a1c
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Cluster member the instance is on
+	// Example: server01
+	Location string `json:"location" yaml:"location"`
+}
+
+// InstancePoolReleasePost represents an instance given back to an instance pool
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePoolReleasePost struct {
+	// Name of the instance
+	// Example: ci-4f2b9a1c
+	Instance string `json:"instance" yaml:"instance"`
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceRefresh represents the scheduled refresh of an instance from its source instance
+//
+// swagger:model
+//
+// API extension: instance_refresh_schedule.
+type InstanceRefresh struct {
+	// Name of the refreshed instance
+	// Example: staging-web
+	Name string `json:"name" yaml:"name"`
+
+	// Project of the refreshed instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Cluster member the refresh runs on
+	// Example: server01
+	Location string `json:"location" yaml:"location"`
+
+	// Schedule of the refresh
+	// Example: @daily
+	Schedule string `json:"schedule" yaml:"schedule"`
+
+	// URL of the server of the source instance
+	// Example: https://production:8443
+	Server string `json:"server" yaml:"server"`
+
+	// Project of the source instance
+	// Example: default
+	SourceProject string `json:"source_project" yaml:"source_project"`
+
+	// Name of the source instance
+	// Example: web
+	Source string `json:"source" yaml:"source"`
+
+	// Whether the instance is refreshed without its snapshots
+	// Example: false
+	InstanceOnly bool `json:"instance_only" yaml:"instance_only"`
+
+	// Status of the last refresh (pending, running, success or failure)
+	// Example: failure
+	Status string `json:"status" yaml:"status"`
+
+	// Error of the last refresh if it failed
+	// Example: Failed connecting to source server "https://production:8443": connection refused
+	Error string `json:"error" yaml:"error"`
+
+	// Time of the last successful refresh
+	// Example: 2026-10-13T02:17:00Z
+	LastRefreshAt time.Time `json:"last_refresh_at" yaml:"last_refresh_at"`
+
+	// Time of the next refresh
+	// Example: 2026-10-14T02:17:00Z
+	NextRefreshAt time.Time `json:"next_refresh_at" yaml:"next_refresh_at"`
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// InstanceSharesPost represents the fields available for a new instance share.
+//
+// swagger:model
+//
+// API extension: instance_shares.
+type InstanceSharesPost struct {
+	// Description of the share
+	// Example: Vendor debugging session
+	Description string `json:"description" yaml:"description"`
+
+	// Identity the share is restricted to (certificate fingerprint or OIDC user name), empty for anyone holding the token
+	// Example: 3c3e4c4b9f7f0fbb6e0f2e5e7b1a1c1d7c0d8f44cda5fd4d6b5e6e38d66e4f43
+	Identity string `json:"identity" yaml:"identity"`
+
+	// When the share expires
+	// Example: 2024-05-02T18:15:12Z
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
+// InstanceShare represents a time-limited grant of exec and console access to a single instance.
+//
+// swagger:model
+//
+// API extension: instance_shares.
+type InstanceShare struct {
+	// Share identifier
+	// Example: 4f3a9c1b
+	ID string `json:"id" yaml:"id"`
+
+	// Description of the share
+	// Example: Vendor debugging session
+	Description string `json:"description" yaml:"description"`
+
+	// Identity the share is restricted to (certificate fingerprint or OIDC user name), empty for anyone holding the token
+	// Example: 3c3e4c4b9f7f0fbb6e0f2e5e7b1a1c1d7c0d8f44cda5fd4d6b5e6e38d66e4f43
+	Identity string `json:"identity" yaml:"identity"`
+
+	// User who created the share
+	// Example: admin
+	CreatedBy string `json:"created_by" yaml:"created_by"`
+
+	// When the share was created
+	// Example: 2024-05-02T10:15:12Z
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// When the share expires
+	// Example: 2024-05-02T18:15:12Z
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+
+	// Token to hand over to the recipient of the share (only returned when the share is created)
+	// Example: eyJmaW5nZXJwcmludCI6IjU3YmIwZmY0MzQwYjViYjI4NTE3ZTA2MjAyMzEwMWFkZjc4OGMzNzg0NmRjOGI2MTllYjJjM2NiNGVmMjk0MzYiLCJhZGRyZXNzZXMiOlsiMTAuOTguMzAuMjI5Ojg0NDMiXX0=
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+// InstanceShareToken represents the information needed to access an instance through a share.
+//
+// API extension: instance_shares.
+type InstanceShareToken struct {
+	// The fingerprint of the network certificate
+	// Example: 57bb0ff4340b5bb28517e062023101adf788c37846dc8b619eb2c3cb4ef29436
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+
+	// The addresses of the server
+	// Example: ["10.98.30.229:8443"]
+	Addresses []string `json:"addresses" yaml:"addresses"`
+
+	// The project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// The shared instance
+	// Example: web1
+	Instance string `json:"instance" yaml:"instance"`
+
+	// The random share secret
+	// Example: 2b2284d44db32675923fe0d2020477e0e9be11801ff70c435e032b97028c35cd
+	Secret string `json:"secret" yaml:"secret"`
+
+	// The share's expiry date.
+	// Example: 2024-05-02T18:15:12Z
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
+// String encodes the instance share token as JSON and then base64.
+func (t *InstanceShareToken) String() string {
+	tokenJSON, err := json.Marshal(t)
+	if err != nil {
+		return ""
+	}
+
+	return base64.StdEncoding.EncodeToString(tokenJSON)
+}
@@ -4,6 +4,12 @@ import (
 	"time"
 )
 
+// InstanceSnapshotTypeDisk is the type of snapshots holding only the disks of the instance.
+const InstanceSnapshotTypeDisk = "disk"
+
+// InstanceSnapshotTypeCheckpoint is the type of snapshots also holding the memory and device state of the instance.
+const InstanceSnapshotTypeCheckpoint = "checkpoint"
+
 // InstanceSnapshotsPost represents the fields available for a new instance snapshot.
 //
 // swagger:model
@@ -110,11 +116,23 @@ type InstanceSnapshot struct {
 	// Example: false
 	Stateful bool `json:"stateful" yaml:"stateful"`
 
+	// Type of snapshot (`disk` or `checkpoint` when it also holds the running state)
+	// Example: checkpoint
+	//
+	// API extension: instance_snapshot_type
+	Type string `json:"type" yaml:"type"`
+
 	// Size of the snapshot in bytes
 	// Example: 143360
 	//
 	// API extension: snapshot_disk_usage
 	Size int64 `json:"size" yaml:"size"`
+
+	// Name of the snapshot this snapshot derives from
+	// Example: snap0
+	//
+	// API extension: instance_snapshot_tree
+	Parent string `json:"parent" yaml:"parent"`
 }
 
 // Writable converts a full InstanceSnapshot struct into a InstanceSnapshotPut struct
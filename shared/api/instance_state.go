@@ -58,6 +58,12 @@ type InstanceState struct {
 	// Example: 50
 	Processes int64 `json:"processes" yaml:"processes"`
 
+	// Number of open file descriptors in the instance
+	// Example: 420
+	//
+	// API extension: instance_limits_fds
+	FileDescriptors int64 `json:"file_descriptors" yaml:"file_descriptors"`
+
 	// CPU usage information
 	CPU InstanceStateCPU `json:"cpu" yaml:"cpu"`
 
@@ -65,6 +71,16 @@ type InstanceState struct {
 	//
 	// API extension: instance_state_started_at.
 	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+
+	// GPU usage key/value pairs
+	//
+	// API extension: instance_gpu_usage
+	GPU map[string]InstanceStateGPU `json:"gpu,omitempty" yaml:"gpu,omitempty"`
+
+	// Pressure stall information key/value pairs (cpu, memory and io)
+	//
+	// API extension: instance_pressure
+	Pressure map[string]InstanceStatePressure `json:"pressure,omitempty" yaml:"pressure,omitempty"`
 }
 
 // InstanceStateDisk represents the disk information section of an instance's state.
@@ -84,6 +100,72 @@ type InstanceStateDisk struct {
 	Total int64 `json:"total" yaml:"total"`
 }
 
+// InstanceStateGPU represents the GPU information section of an instance's state.
+//
+// swagger:model
+//
+// API extension: instance_gpu_usage.
+type InstanceStateGPU struct {
+	// PCI address of the GPU
+	// Example: 0000:01:00.0
+	PCIAddress string `json:"pci_address" yaml:"pci_address"`
+
+	// Utilization of the whole GPU in percent
+	// Example: 42
+	Utilization int64 `json:"utilization" yaml:"utilization"`
+
+	// Memory used on the whole GPU in bytes
+	// Example: 4294967296
+	MemoryUsage int64 `json:"memory_usage" yaml:"memory_usage"`
+
+	// Total memory of the GPU in bytes
+	// Example: 17179869184
+	MemoryTotal int64 `json:"memory_total" yaml:"memory_total"`
+
+	// Memory used by the processes of the instance in bytes (-1 if not available)
+	// Example: 2147483648
+	InstanceMemoryUsage int64 `json:"instance_memory_usage" yaml:"instance_memory_usage"`
+}
+
+// InstanceStatePressure represents the pressure stall information of a resource of an instance's state.
+//
+// swagger:model
+//
+// API extension: instance_pressure.
+type InstanceStatePressure struct {
+	// Share of time (in %) some tasks were stalled over the last 10 seconds
+	// Example: 1.5
+	SomeAvg10 float64 `json:"some_avg10" yaml:"some_avg10"`
+
+	// Share of time (in %) some tasks were stalled over the last 60 seconds
+	// Example: 0.8
+	SomeAvg60 float64 `json:"some_avg60" yaml:"some_avg60"`
+
+	// Share of time (in %) some tasks were stalled over the last 300 seconds
+	// Example: 0.2
+	SomeAvg300 float64 `json:"some_avg300" yaml:"some_avg300"`
+
+	// Total time (in microseconds) some tasks were stalled
+	// Example: 1840522
+	SomeTotal uint64 `json:"some_total" yaml:"some_total"`
+
+	// Share of time (in %) all tasks were stalled over the last 10 seconds
+	// Example: 0.5
+	FullAvg10 float64 `json:"full_avg10" yaml:"full_avg10"`
+
+	// Share of time (in %) all tasks were stalled over the last 60 seconds
+	// Example: 0.3
+	FullAvg60 float64 `json:"full_avg60" yaml:"full_avg60"`
+
+	// Share of time (in %) all tasks were stalled over the last 300 seconds
+	// Example: 0.1
+	FullAvg300 float64 `json:"full_avg300" yaml:"full_avg300"`
+
+	// Total time (in microseconds) all tasks were stalled
+	// Example: 620130
+	FullTotal uint64 `json:"full_total" yaml:"full_total"`
+}
+
 // InstanceStateCPU represents the cpu information section of an instance's state.
 //
 // swagger:model
@@ -93,6 +175,12 @@ type InstanceStateCPU struct {
 	// CPU usage in nanoseconds
 	// Example: 3637691016
 	Usage int64 `json:"usage" yaml:"usage"`
+
+	// Host NUMA nodes the instance CPUs and memory are placed on
+	// Example: [0]
+	//
+	// API extension: instances_numa_placement
+	NUMANodes []uint64 `json:"numa_nodes,omitempty" yaml:"numa_nodes,omitempty"`
 }
 
 // InstanceStateMemory represents the memory information section of an instance's state.
@@ -220,3 +308,84 @@ type InstanceStateNetworkCounters struct {
 	// Example: 179
 	PacketsDroppedInbound int64 `json:"packets_dropped_inbound" yaml:"packets_dropped_inbound"`
 }
+
+// InstanceStateStream represents a message of the resource usage stream of instances.
+//
+// swagger:model
+//
+// API extension: instance_state_stream.
+type InstanceStateStream struct {
+	// Time at which the usage was sampled
+	// Example: 2024-10-14T15:04:05Z
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+
+	// Duration of the interval the deltas are computed over (in milliseconds)
+	// Example: 1000
+	Interval int64 `json:"interval" yaml:"interval"`
+
+	// Resource usage of the instances
+	Instances []InstanceStateUsage `json:"instances" yaml:"instances"`
+}
+
+// InstanceStateUsage represents the resource usage of an instance over an interval of its state stream.
+//
+// swagger:model
+//
+// API extension: instance_state_stream.
+type InstanceStateUsage struct {
+	// Name of the instance
+	// Example: foo
+	Name string `json:"name" yaml:"name"`
+
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Cluster member the instance is located on
+	// Example: server01
+	Location string `json:"location" yaml:"location"`
+
+	// Current status (Running, Stopped, Frozen or Error)
+	// Example: Running
+	Status string `json:"status" yaml:"status"`
+
+	// Error getting the state of the instance, the usage being unknown
+	// Example: Failed to get memory usage
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// CPU time used during the interval (in nanoseconds)
+	// Example: 250000000
+	CPUUsage int64 `json:"cpu_usage" yaml:"cpu_usage"`
+
+	// Memory usage in bytes
+	// Example: 73248768
+	MemoryUsage int64 `json:"memory_usage" yaml:"memory_usage"`
+
+	// Change of the memory usage during the interval (in bytes)
+	// Example: -4096
+	MemoryUsageDelta int64 `json:"memory_usage_delta" yaml:"memory_usage_delta"`
+
+	// Disk usage of all the disks in bytes
+	// Example: 502239232
+	DiskUsage int64 `json:"disk_usage" yaml:"disk_usage"`
+
+	// Change of the disk usage during the interval (in bytes)
+	// Example: 8192
+	DiskUsageDelta int64 `json:"disk_usage_delta" yaml:"disk_usage_delta"`
+
+	// Number of bytes received during the interval
+	// Example: 1024
+	NetworkBytesReceived int64 `json:"network_bytes_received" yaml:"network_bytes_received"`
+
+	// Number of bytes sent during the interval
+	// Example: 2048
+	NetworkBytesSent int64 `json:"network_bytes_sent" yaml:"network_bytes_sent"`
+
+	// Number of packets received during the interval
+	// Example: 12
+	NetworkPacketsReceived int64 `json:"network_packets_received" yaml:"network_packets_received"`
+
+	// Number of packets sent during the interval
+	// Example: 16
+	NetworkPacketsSent int64 `json:"network_packets_sent" yaml:"network_packets_sent"`
+}
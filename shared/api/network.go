@@ -188,6 +188,11 @@ type NetworkState struct {
 	//
 	// API extension: network_state_ovn
 	OVN *NetworkStateOVN `json:"ovn" yaml:"ovn"`
+
+	// Traffic shaping information
+	//
+	// API extension: network_state_bridge_limits
+	Shaping *NetworkStateShaping `json:"shaping" yaml:"shaping"`
 }
 
 // NetworkStateAddress represents a network address
@@ -329,3 +334,69 @@ type NetworkStateOVN struct {
 	// API extension: network_state_ovn_lr
 	LogicalRouter string `json:"logical_router" yaml:"logical_router"`
 }
+
+// NetworkStateShaping represents the traffic shaping state of a bridge network
+//
+// swagger:model
+//
+// API extension: network_state_bridge_limits.
+type NetworkStateShaping struct {
+	// Aggregate limit of the traffic received by the instances
+	// Example: 1Gbit
+	Ingress string `json:"ingress" yaml:"ingress"`
+
+	// Aggregate limit of the traffic sent by the instances
+	// Example: 500Mbit
+	Egress string `json:"egress" yaml:"egress"`
+
+	// Per-instance breakdown of the traffic
+	Members []NetworkStateShapingMember `json:"members" yaml:"members"`
+}
+
+// NetworkStateShapingMember represents the traffic shaping state of an instance NIC
+//
+// swagger:model
+//
+// API extension: network_state_bridge_limits.
+type NetworkStateShapingMember struct {
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Name of the instance
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Name of the NIC device
+	// Example: eth0
+	Device string `json:"device" yaml:"device"`
+
+	// Traffic received by the instance
+	Ingress *NetworkStateShapingCounters `json:"ingress" yaml:"ingress"`
+
+	// Traffic sent by the instance
+	Egress *NetworkStateShapingCounters `json:"egress" yaml:"egress"`
+}
+
+// NetworkStateShapingCounters represents the traffic of an instance NIC within the shaping hierarchy
+//
+// swagger:model
+//
+// API extension: network_state_bridge_limits.
+type NetworkStateShapingCounters struct {
+	// Effective limit of the NIC in bit/s
+	// Example: 100000000
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// Number of bytes
+	// Example: 17524040140
+	Bytes int64 `json:"bytes" yaml:"bytes"`
+
+	// Number of packets
+	// Example: 1567934
+	Packets int64 `json:"packets" yaml:"packets"`
+
+	// Number of packets dropped by the shaping
+	// Example: 24
+	Drops int64 `json:"drops" yaml:"drops"`
+}
@@ -67,6 +67,12 @@ type Operation struct {
 	//
 	// API extension: operation_location
 	Location string `json:"location" yaml:"location"`
+
+	// Position of the operation in the queue of its concurrency class (0 if not queued)
+	// Example: 2
+	//
+	// API extension: operations_concurrency_limits
+	QueuePosition int `json:"queue_position,omitempty" yaml:"queue_position,omitempty"`
 }
 
 // ToCertificateAddToken creates a certificate add token from the operation metadata.
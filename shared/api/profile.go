@@ -71,3 +71,52 @@ func (profile *Profile) Writable() ProfilePut {
 func (profile *Profile) URL(apiVersion string, projectName string) *URL {
 	return NewURL().Path(apiVersion, "profiles", profile.Name).Project(projectName)
 }
+
+// ProfilePreview represents the effect a profile change would have on the instances using the profile.
+//
+// swagger:model
+//
+// API extension: profile_preview.
+type ProfilePreview struct {
+	// Instances which would be affected by the change
+	Instances []ProfilePreviewInstance `json:"instances" yaml:"instances"`
+}
+
+// ProfilePreviewInstance represents the effect a profile change would have on a single instance.
+//
+// swagger:model
+//
+// API extension: profile_preview.
+type ProfilePreviewInstance struct {
+	// Instance name
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Project name
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Cluster member the instance is located on
+	// Example: server01
+	Location string `json:"location" yaml:"location"`
+
+	// Whether the instance is running
+	// Example: true
+	Running bool `json:"running" yaml:"running"`
+
+	// Configuration keys whose effective value would change
+	// Example: ["limits.cpu", "security.nesting"]
+	Config []string `json:"config" yaml:"config"`
+
+	// Devices which would be added, removed or changed
+	// Example: ["eth0"]
+	Devices []string `json:"devices" yaml:"devices"`
+
+	// Whether the instance would need to be restarted for the change to fully apply
+	// Example: true
+	RequiresRestart bool `json:"requires_restart" yaml:"requires_restart"`
+
+	// Validation error the change would cause for the instance
+	// Example: Invalid value for config key "limits.cpu"
+	Error string `json:"error" yaml:"error"`
+}
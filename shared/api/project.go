@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // ProjectDefaultName is the name of the default project that can never be deleted.
 const ProjectDefaultName = "default"
 
@@ -85,6 +89,33 @@ type ProjectState struct {
 	Resources map[string]ProjectStateResource `json:"resources" yaml:"resources"`
 }
 
+// ProjectIdleInstance represents an instance of a project which was detected as idle
+//
+// swagger:model
+//
+// API extension: instances_idle_detection.
+type ProjectIdleInstance struct {
+	// Name of the instance
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// The type of instance (container or virtual-machine)
+	// Example: container
+	Type string `json:"type" yaml:"type"`
+
+	// What cluster member this instance is located on
+	// Example: server01
+	Location string `json:"location" yaml:"location"`
+
+	// Time since which the instance is idle
+	// Example: 2021-03-23T20:00:00-04:00
+	IdleSince time.Time `json:"idle_since" yaml:"idle_since"`
+
+	// Last start timestamp
+	// Example: 2021-03-23T20:00:00-04:00
+	LastUsedAt time.Time `json:"last_used_at" yaml:"last_used_at"`
+}
+
 // ProjectStateResource represents the state of a particular resource in a project
 //
 // swagger:model
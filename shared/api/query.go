@@ -0,0 +1,27 @@
+package api
+
+// QueryPost represents a query statement to run against the server.
+//
+// swagger:model
+//
+// API extension: query_list.
+type QueryPost struct {
+	// Query statement
+	// Example: select name, state.memory.usage from instances where config['image.os'] = 'Ubuntu'
+	Query string `json:"query" yaml:"query"`
+}
+
+// QueryResult represents the result of a query statement.
+//
+// swagger:model
+//
+// API extension: query_list.
+type QueryResult struct {
+	// Selected fields
+	// Example: ["name", "state.memory.usage"]
+	Columns []string `json:"columns" yaml:"columns"`
+
+	// One row of values per matching object, in the order of the columns
+	// Example: [["c1", 123456]]
+	Rows [][]any `json:"rows" yaml:"rows"`
+}
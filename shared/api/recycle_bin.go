@@ -0,0 +1,55 @@
+package api
+
+import (
+	"time"
+)
+
+// RecycleBinEntry represents a deleted instance or custom storage volume kept in the recycle bin
+//
+// swagger:model
+//
+// API extension: recycle_bin.
+type RecycleBinEntry struct {
+	// Identifier of the entry
+	// Example: 42
+	ID int64 `json:"id" yaml:"id"`
+
+	// Type of the deleted resource (instance or volume)
+	// Example: instance
+	Type string `json:"type" yaml:"type"`
+
+	// Name of the deleted resource
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Project of the deleted resource
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Storage pool of the deleted resource
+	// Example: default
+	Pool string `json:"pool" yaml:"pool"`
+
+	// Cluster member holding the deleted resource
+	// Example: server01
+	Location string `json:"location" yaml:"location"`
+
+	// When the resource was deleted
+	// Example: 2021-03-23T20:00:00-04:00
+	DeletedAt time.Time `json:"deleted_at" yaml:"deleted_at"`
+
+	// When the resource gets purged from the recycle bin
+	// Example: 2021-03-30T20:00:00-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
+// RecycleBinEntryPost represents the fields used to restore an entry of the recycle bin
+//
+// swagger:model
+//
+// API extension: recycle_bin.
+type RecycleBinEntryPost struct {
+	// New name of the restored resource (defaults to its original name)
+	// Example: c1-restored
+	Name string `json:"name" yaml:"name"`
+}
@@ -92,6 +92,12 @@ type ResourcesCPUSocket struct {
 	// List of CPU cores
 	Cores []ResourcesCPUCore `json:"cores" yaml:"cores"`
 
+	// List of CPU flags
+	// Example: ["fpu", "vme", "vmx"]
+	//
+	// API extension: resources_cpu_flags
+	Flags []string `json:"flags,omitempty" yaml:"flags,omitempty"`
+
 	// Current CPU frequency (Mhz)
 	// Example: 3499
 	Frequency uint64 `json:"frequency,omitempty" yaml:"frequency,omitempty"`
@@ -175,6 +181,12 @@ type ResourcesCPUThread struct {
 	//
 	// API extension: resource_cpu_isolated
 	Isolated bool `json:"isolated" yaml:"isolated"`
+
+	// Whether the thread is reserved for the host (never used by instances)
+	// Example: false
+	//
+	// API extension: server_limits_reserve
+	Reserved bool `json:"reserved" yaml:"reserved"`
 }
 
 // ResourcesGPU represents the GPU resources available on the system
@@ -733,6 +745,11 @@ type ResourcesMemory struct {
 	// Example: 2097152
 	HugepagesSize uint64 `json:"hugepages_size" yaml:"hugepages_size"`
 
+	// Huge pages of each supported size
+	//
+	// API extension: hugepages_management
+	Hugepages []ResourcesMemoryHugepages `json:"hugepages,omitempty" yaml:"hugepages,omitempty"`
+
 	// Used system memory (bytes)
 	// Example: 557450502144
 	Used uint64 `json:"used" yaml:"used"`
@@ -740,6 +757,12 @@ type ResourcesMemory struct {
 	// Total system memory (bytes)
 	// Example: 687194767360
 	Total uint64 `json:"total" yaml:"total"`
+
+	// System memory reserved for the host, never used by instances (bytes)
+	// Example: 4294967296
+	//
+	// API extension: server_limits_reserve
+	Reserved uint64 `json:"reserved" yaml:"reserved"`
 }
 
 // ResourcesMemoryNode represents the node-specific memory resources available on the system
@@ -760,6 +783,11 @@ type ResourcesMemoryNode struct {
 	// Example: 214536552448
 	HugepagesTotal uint64 `json:"hugepages_total" yaml:"hugepages_total"`
 
+	// Huge pages of each supported size on the node
+	//
+	// API extension: hugepages_management
+	Hugepages []ResourcesMemoryHugepages `json:"hugepages,omitempty" yaml:"hugepages,omitempty"`
+
 	// Used system memory (bytes)
 	// Example: 264880439296
 	Used uint64 `json:"used" yaml:"used"`
@@ -769,6 +797,25 @@ type ResourcesMemoryNode struct {
 	Total uint64 `json:"total" yaml:"total"`
 }
 
+// ResourcesMemoryHugepages represents the huge pages of a given size
+//
+// swagger:model
+//
+// API extension: hugepages_management.
+type ResourcesMemoryHugepages struct {
+	// Size of the huge pages (bytes)
+	// Example: 2097152
+	Size uint64 `json:"size" yaml:"size"`
+
+	// Total number of huge pages
+	// Example: 1024
+	Total uint64 `json:"total" yaml:"total"`
+
+	// Number of free huge pages
+	// Example: 512
+	Free uint64 `json:"free" yaml:"free"`
+}
+
 // ResourcesStoragePool represents the resources available to a given storage pool
 //
 // swagger:model
@@ -812,6 +859,85 @@ type ResourcesStoragePoolInodes struct {
 	Total uint64 `json:"total" yaml:"total"`
 }
 
+// ResourcesIdmap represents the user and group ID ranges available to instances
+//
+// swagger:model
+//
+// API extension: resources_idmap.
+type ResourcesIdmap struct {
+	// Number of subordinate user IDs of the host available to root
+	// Example: 1000000000
+	TotalUIDs int64 `json:"total_uids" yaml:"total_uids"`
+
+	// Number of subordinate group IDs of the host available to root
+	// Example: 1000000000
+	TotalGIDs int64 `json:"total_gids" yaml:"total_gids"`
+
+	// ID ranges used by the server
+	Ranges []ResourcesIdmapRange `json:"ranges" yaml:"ranges"`
+
+	// Size of the ID range of an isolated instance used for the estimate
+	// Example: 65536
+	IsolatedSize int64 `json:"isolated_size" yaml:"isolated_size"`
+
+	// Number of isolated instances which can still be given an ID range
+	// Example: 15255
+	IsolatedAvailable int64 `json:"isolated_available" yaml:"isolated_available"`
+
+	// ID ranges used by the unprivileged containers
+	Instances []ResourcesIdmapInstance `json:"instances" yaml:"instances"`
+}
+
+// ResourcesIdmapRange represents a range of host user or group IDs
+//
+// swagger:model
+//
+// API extension: resources_idmap.
+type ResourcesIdmapRange struct {
+	// Whether the range applies to user IDs
+	// Example: true
+	UID bool `json:"uid" yaml:"uid"`
+
+	// Whether the range applies to group IDs
+	// Example: true
+	GID bool `json:"gid" yaml:"gid"`
+
+	// First host ID of the range
+	// Example: 1000000
+	HostID int64 `json:"host_id" yaml:"host_id"`
+
+	// Number of IDs in the range
+	// Example: 1000000000
+	Size int64 `json:"size" yaml:"size"`
+}
+
+// ResourcesIdmapInstance represents the ID range used by an instance
+//
+// swagger:model
+//
+// API extension: resources_idmap.
+type ResourcesIdmapInstance struct {
+	// Name of the instance
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Whether the instance has its own isolated ID range
+	// Example: true
+	Isolated bool `json:"isolated" yaml:"isolated"`
+
+	// First host ID of the range
+	// Example: 1065536
+	HostID int64 `json:"host_id" yaml:"host_id"`
+
+	// Number of IDs in the range
+	// Example: 65536
+	Size int64 `json:"size" yaml:"size"`
+}
+
 // ResourcesUSB represents the USB devices available on the system
 //
 // swagger:model
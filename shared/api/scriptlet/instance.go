@@ -31,3 +31,25 @@ type InstancePlacement struct {
 	Reason  string `json:"reason"`
 	Project string `json:"project"`
 }
+
+// InstancePlacementExternal represents the request sent to an external instance placement service.
+//
+// API extension: instances_placement_external.
+type InstancePlacementExternal struct {
+	// The instance to place
+	Request InstancePlacement `json:"request"`
+
+	// The resources required by the instance
+	Resources InstanceResources `json:"resources"`
+
+	// The cluster members the instance can be placed on
+	CandidateMembers []*api.ClusterMember `json:"candidate_members"`
+}
+
+// InstancePlacementExternalResult represents the reply of an external instance placement service.
+//
+// API extension: instances_placement_external.
+type InstancePlacementExternalResult struct {
+	// Name of the selected cluster member (empty to use the built-in placement)
+	Member string `json:"member"`
+}
@@ -56,6 +56,11 @@ type ServerEnvironment struct {
 	// API extension: lxc_features
 	LXCFeatures map[string]string `json:"lxc_features" yaml:"lxc_features"`
 
+	// Status of the LXCFS instance managed by the server
+	//
+	// API extension: lxcfs_management
+	LXCFS ServerLXCFS `json:"lxcfs" yaml:"lxcfs"`
+
 	// Name of the operating system (Linux distribution)
 	// Example: Ubuntu
 	//
@@ -142,6 +147,49 @@ type ServerStorageDriverInfo struct {
 	Remote bool
 }
 
+// ServerLXCFS represents the status of the LXCFS instance managed by the server
+//
+// swagger:model
+//
+// API extension: lxcfs_management.
+type ServerLXCFS struct {
+	// Whether LXCFS is managed by the server
+	// Example: true
+	//
+	// API extension: lxcfs_management
+	Managed bool `json:"managed" yaml:"managed"`
+
+	// Whether LXCFS is running and serving its views
+	// Example: true
+	//
+	// API extension: lxcfs_management
+	Running bool `json:"running" yaml:"running"`
+
+	// PID of the LXCFS process
+	// Example: 1234
+	//
+	// API extension: lxcfs_management
+	PID int64 `json:"pid" yaml:"pid"`
+
+	// Number of times LXCFS was restarted after failing
+	// Example: 0
+	//
+	// API extension: lxcfs_management
+	Restarts int64 `json:"restarts" yaml:"restarts"`
+
+	// Whether the load average virtualization is enabled
+	// Example: true
+	//
+	// API extension: lxcfs_management
+	LoadAvg bool `json:"loadavg" yaml:"loadavg"`
+
+	// Whether the CPU view virtualization is enabled
+	// Example: false
+	//
+	// API extension: lxcfs_management
+	CPUView bool `json:"cpuview" yaml:"cpuview"`
+}
+
 // ServerPut represents the modifiable fields of a server configuration
 //
 // swagger:model
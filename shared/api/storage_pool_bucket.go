@@ -67,6 +67,25 @@ type StorageBucket struct {
 	Project string `json:"project" yaml:"project"`
 }
 
+// StorageBucketState represents the live state of a storage pool bucket
+//
+// swagger:model
+//
+// API extension: storage_bucket_quota_lifecycle.
+type StorageBucketState struct {
+	// Space used by the objects of the bucket in bytes
+	// Example: 1693552640
+	Used int64 `json:"used" yaml:"used"`
+
+	// Quota of the bucket in bytes (0 if unlimited)
+	// Example: 5368709120
+	Total int64 `json:"total" yaml:"total"`
+
+	// Number of objects in the bucket
+	// Example: 42
+	Objects int64 `json:"objects" yaml:"objects"`
+}
+
 // Etag returns the values used for etag generation.
 func (b *StorageBucket) Etag() []any {
 	return []any{b.Name, b.Description, b.Config}
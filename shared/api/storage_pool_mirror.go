@@ -0,0 +1,73 @@
+package api
+
+// StoragePoolMirrorState represents the state of the mirroring of a storage pool to its peer clusters
+//
+// swagger:model
+//
+// API extension: storage_ceph_rbd_mirroring.
+type StoragePoolMirrorState struct {
+	// Mirroring mode of the pool (disabled, image or pool)
+	// Example: image
+	Mode string `json:"mode" yaml:"mode"`
+
+	// Name of the local site
+	// Example: site-a
+	SiteName string `json:"site_name" yaml:"site_name"`
+
+	// Overall mirroring health
+	// Example: OK
+	Health string `json:"health" yaml:"health"`
+
+	// Health of the mirroring daemons
+	// Example: OK
+	DaemonHealth string `json:"daemon_health" yaml:"daemon_health"`
+
+	// Health of the mirrored volumes
+	// Example: OK
+	VolumeHealth string `json:"volume_health" yaml:"volume_health"`
+
+	// Number of mirrored volumes in each replication state
+	// Example: {"replaying": 3}
+	Volumes map[string]int64 `json:"volumes" yaml:"volumes"`
+
+	// Peer clusters
+	Peers []StoragePoolMirrorPeer `json:"peers" yaml:"peers"`
+}
+
+// StoragePoolMirrorPeer represents a peer cluster of a mirrored storage pool
+//
+// swagger:model
+//
+// API extension: storage_ceph_rbd_mirroring.
+type StoragePoolMirrorPeer struct {
+	// Peer UUID
+	// Example: 3b52a1a4-5a62-4a6c-9b5b-4d4c5a1e3f6d
+	UUID string `json:"uuid" yaml:"uuid"`
+
+	// Name of the peer site
+	// Example: site-b
+	SiteName string `json:"site_name" yaml:"site_name"`
+
+	// Replication direction (rx-only or rx-tx)
+	// Example: rx-tx
+	Direction string `json:"direction" yaml:"direction"`
+
+	// Ceph client used to connect to the peer
+	// Example: client.rbd-mirror-peer
+	ClientName string `json:"client_name" yaml:"client_name"`
+}
+
+// StoragePoolMirrorPost represents a change of the mirroring role of a storage pool
+//
+// swagger:model
+//
+// API extension: storage_ceph_rbd_mirroring.
+type StoragePoolMirrorPost struct {
+	// Action to perform (promote or demote)
+	// Example: promote
+	Action string `json:"action" yaml:"action"`
+
+	// Whether to promote the pool even if the peer cluster can't be reached
+	// Example: false
+	Force bool `json:"force" yaml:"force"`
+}
@@ -32,6 +32,32 @@ type StorageVolumesPost struct {
 	ContentType string `json:"content_type" yaml:"content_type"`
 }
 
+// StorageVolumeTransferPost represents the fields required to send a storage volume to an external target
+//
+// swagger:model
+//
+// API extension: storage_volume_transfer.
+type StorageVolumeTransferPost struct {
+	// Transfer target (defaults to the zfs.transfer.target configuration of the volume)
+	// Example: zfs://backup@backup01/tank/backup/vol1
+	Target string `json:"target" yaml:"target"`
+}
+
+// StorageVolumeMirrorPost represents a change of the mirroring role of a storage volume
+//
+// swagger:model
+//
+// API extension: storage_ceph_rbd_mirroring_volume_role.
+type StorageVolumeMirrorPost struct {
+	// Action to perform (promote or demote)
+	// Example: promote
+	Action string `json:"action" yaml:"action"`
+
+	// Whether to promote the volume even if the peer cluster can't be reached
+	// Example: false
+	Force bool `json:"force" yaml:"force"`
+}
+
 // StorageVolumePost represents the fields required to rename a storage pool volume
 //
 // swagger:model
@@ -75,6 +101,12 @@ type StorageVolumePost struct {
 	//
 	// API extension: cluster_internal_custom_volume_copy
 	Source StorageVolumeSource `json:"source" yaml:"source"`
+
+	// Whether to move the volume to the new pool while the instances using it keep running
+	// Example: false
+	//
+	// API extension: storage_volume_live_move
+	Live bool `json:"live,omitempty" yaml:"live,omitempty"`
 }
 
 // StorageVolumePostTarget represents the migration target host and operation
@@ -8,6 +8,16 @@ package api
 type StorageVolumeState struct {
 	// Volume usage
 	Usage *StorageVolumeStateUsage `json:"usage" yaml:"usage"`
+
+	// Volume mirroring state
+	//
+	// API extension: storage_ceph_rbd_mirroring
+	Mirror *StorageVolumeStateMirror `json:"mirror,omitempty" yaml:"mirror,omitempty"`
+
+	// Volume IO counters
+	//
+	// API extension: storage_volume_state_io
+	IO *StorageVolumeStateIO `json:"io,omitempty" yaml:"io,omitempty"`
 }
 
 // StorageVolumeStateUsage represents the disk usage of a volume
@@ -26,3 +36,57 @@ type StorageVolumeStateUsage struct {
 	// API extension: storage_volume_state_total
 	Total int64 `json:"total" yaml:"total"`
 }
+
+// StorageVolumeStateMirror represents the mirroring state of a volume
+//
+// swagger:model
+//
+// API extension: storage_ceph_rbd_mirroring.
+type StorageVolumeStateMirror struct {
+	// Mirroring mode
+	// Example: snapshot
+	Mode string `json:"mode" yaml:"mode"`
+
+	// Whether the local copy of the volume is the primary one
+	// Example: true
+	Primary bool `json:"primary" yaml:"primary"`
+
+	// Replication state
+	// Example: up+stopped
+	State string `json:"state" yaml:"state"`
+
+	// Description of the replication state
+	// Example: local image is primary
+	Description string `json:"description" yaml:"description"`
+}
+
+// StorageVolumeStateIO represents the IO counters of a volume
+//
+// swagger:model
+//
+// API extension: storage_volume_state_io.
+type StorageVolumeStateIO struct {
+	// Number of completed read operations
+	// Example: 13740
+	ReadOps uint64 `json:"read_ops" yaml:"read_ops"`
+
+	// Number of bytes read
+	// Example: 562823168
+	ReadBytes uint64 `json:"read_bytes" yaml:"read_bytes"`
+
+	// Number of completed write operations
+	// Example: 48526
+	WriteOps uint64 `json:"write_ops" yaml:"write_ops"`
+
+	// Number of bytes written
+	// Example: 1837105152
+	WriteBytes uint64 `json:"write_bytes" yaml:"write_bytes"`
+
+	// Average time to complete a read operation in microseconds (if reported by the storage driver)
+	// Example: 420
+	ReadLatency uint64 `json:"read_latency,omitempty" yaml:"read_latency,omitempty"`
+
+	// Average time to complete a write operation in microseconds (if reported by the storage driver)
+	// Example: 1350
+	WriteLatency uint64 `json:"write_latency,omitempty" yaml:"write_latency,omitempty"`
+}
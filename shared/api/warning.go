@@ -51,6 +51,26 @@ type Warning struct {
 	// The entity affected by this warning
 	// Example: /1.0/instances/c1?project=default
 	EntityURL string `json:"entity_url" yaml:"entity_url"`
+
+	// How to address the warning
+	//
+	// API extension: warnings_remediation
+	Remediation *WarningRemediation `json:"remediation" yaml:"remediation"`
+}
+
+// WarningRemediation represents the known fix for a warning.
+//
+// swagger:model
+//
+// API extension: warnings_remediation.
+type WarningRemediation struct {
+	// Human-readable description of the fix
+	// Example: Fix the reported error and start the instance
+	Description string `json:"description" yaml:"description"`
+
+	// Fix the server can apply itself (empty if it must be done manually)
+	// Example: start-instance
+	Action string `json:"action" yaml:"action"`
 }
 
 // WarningPut represents the modifiable fields of a warning.
@@ -30,6 +30,7 @@ type Remote struct {
 	Project   string `yaml:"project,omitempty"`
 	Protocol  string `yaml:"protocol,omitempty"`
 	Public    bool   `yaml:"public"`
+	Share     string `yaml:"share,omitempty"`
 	Global    bool   `yaml:"-"`
 	Static    bool   `yaml:"-"`
 }
@@ -223,8 +224,9 @@ func (c *Config) GetImageServer(name string) (incus.ImageServer, error) {
 func (c *Config) getConnectionArgs(name string) (*incus.ConnectionArgs, error) {
 	remote := c.Remotes[name]
 	args := incus.ConnectionArgs{
-		UserAgent: c.UserAgent,
-		AuthType:  remote.AuthType,
+		UserAgent:   c.UserAgent,
+		AuthType:    remote.AuthType,
+		ShareSecret: remote.Share,
 	}
 
 	if args.AuthType == api.AuthenticationMethodOIDC {
@@ -314,6 +314,76 @@ func (m *Set) ShiftFromNS(uid int64, gid int64) (int64, int64) {
 	return m.doShiftIntoNS(uid, gid, "out")
 }
 
+// shiftSegment is a range of namespace IDs shifted by the same offset.
+type shiftSegment struct {
+	start int64
+	end   int64
+	delta int64
+}
+
+// shiftIndex returns the sorted and non-overlapping ranges of namespace IDs mapped by the set.
+// Where entries overlap, the first one wins, as with ShiftIntoNS.
+func (m *Set) shiftIndex(isUID bool) []shiftSegment {
+	entries := []Entry{}
+	bounds := []int64{}
+	for _, e := range m.Entries {
+		if (isUID && !e.IsUID) || (!isUID && !e.IsGID) || e.MapRange <= 0 {
+			continue
+		}
+
+		entries = append(entries, e)
+		bounds = append(bounds, e.NSID, e.NSID+e.MapRange)
+	}
+
+	slices.Sort(bounds)
+	bounds = slices.Compact(bounds)
+
+	segments := []shiftSegment{}
+	for i := 0; i+1 < len(bounds); i++ {
+		start := bounds[i]
+		end := bounds[i+1]
+
+		for _, e := range entries {
+			if start < e.NSID || start >= e.NSID+e.MapRange {
+				continue
+			}
+
+			delta := e.HostID - e.NSID
+
+			// Merge with the previous segment when contiguous.
+			last := len(segments) - 1
+			if last >= 0 && segments[last].end == start && segments[last].delta == delta {
+				segments[last].end = end
+			} else {
+				segments = append(segments, shiftSegment{start: start, end: end, delta: delta})
+			}
+
+			break
+		}
+	}
+
+	return segments
+}
+
+// ShiftMany shifts the provided uids (or gids) into their container equivalent.
+// It gives the same results as ShiftIntoNS but looks the IDs up in a precomputed index, which makes it
+// much faster for the large numbers of IDs found when scanning a filesystem.
+func (m *Set) ShiftMany(ids []int64, isUID bool) ([]int64, error) {
+	segments := m.shiftIndex(isUID)
+
+	shifted := make([]int64, len(ids))
+	for i, id := range ids {
+		n := sort.Search(len(segments), func(j int) bool { return segments[j].end > id })
+		if n == len(segments) || id < segments[n].start {
+			return nil, fmt.Errorf("ID %d isn't mapped", id)
+		}
+
+		shifted[i] = id + segments[n].delta
+	}
+
+	return shifted, nil
+}
+
 // ToJSON marshals a Set to its JSON reprensetation.
 func (m *Set) ToJSON() (string, error) {
 	if m == nil {
@@ -385,6 +455,52 @@ func (m *Set) Split(minSize int64, maxSize int64, minHost int64, maxHost int64)
 	return &Set{Entries: []Entry{*uidEntry, *gidEntry}}, nil
 }
 
+// freeRanges returns the ranges of host IDs between start and end which none of the entries use.
+func (m *Set) freeRanges(start int64, end int64) []Entry {
+	entries := ByHostID{Entries: slices.Clone(m.Entries)}
+	sort.Sort(entries)
+
+	free := []Entry{}
+	offset := start
+	for _, entry := range entries.Entries {
+		if entry.HostID > offset {
+			free = append(free, Entry{HostID: offset, MapRange: min(entry.HostID, end) - offset})
+		}
+
+		offset = max(offset, entry.HostID+entry.MapRange)
+		if offset >= end {
+			return free
+		}
+	}
+
+	if end > offset {
+		free = append(free, Entry{HostID: offset, MapRange: end - offset})
+	}
+
+	return free
+}
+
+// FindFreeRange returns the lowest host ID between start and end from which size IDs are used by none of the entries.
+func (m *Set) FindFreeRange(start int64, end int64, size int64) (int64, error) {
+	for _, entry := range m.freeRanges(start, end) {
+		if entry.MapRange >= size {
+			return entry.HostID, nil
+		}
+	}
+
+	return 0, ErrNoSuitableSubmap
+}
+
+// CountFreeRanges returns how many ranges of size IDs FindFreeRange can still allocate between start and end.
+func (m *Set) CountFreeRanges(start int64, end int64, size int64) int64 {
+	count := int64(0)
+	for _, entry := range m.freeRanges(start, end) {
+		count += entry.MapRange / size
+	}
+
+	return count
+}
+
 // Includes checks whether the provided Set is fully covered by the current Set.
 func (m *Set) Includes(sub *Set) bool {
 	// Populate the allowed entries.
@@ -410,3 +526,64 @@ func (m *Set) Includes(sub *Set) bool {
 
 	return true
 }
+
+// Compose returns the mapping of a nested namespace described by other, whose host IDs are IDs of the
+// namespace described by the current Set, directly to the host IDs of the current Set.
+// IDs of the nested namespace which don't map to an ID of the current Set are left out.
+func (m *Set) Compose(other *Set) *Set {
+	composed := &Set{Entries: []Entry{}}
+	if m == nil || other == nil {
+		return composed
+	}
+
+	for _, nested := range other.Entries {
+		for _, parent := range m.Entries {
+			isUID := nested.IsUID && parent.IsUID
+			isGID := nested.IsGID && parent.IsGID
+			if !isUID && !isGID {
+				continue
+			}
+
+			// Intersect the IDs the nested entry maps to with the IDs the parent entry maps from.
+			start := max(nested.HostID, parent.NSID)
+			end := min(nested.HostID+nested.MapRange, parent.NSID+parent.MapRange)
+			if start >= end {
+				continue
+			}
+
+			composed.Entries = append(composed.Entries, Entry{
+				IsUID:    isUID,
+				IsGID:    isGID,
+				HostID:   parent.HostID + (start - parent.NSID),
+				NSID:     nested.NSID + (start - nested.HostID),
+				MapRange: end - start,
+			})
+		}
+	}
+
+	sort.Sort(composed)
+
+	return composed
+}
+
+// Invert returns the reverse mapping of the current Set, from its host IDs to its namespace IDs.
+func (m *Set) Invert() *Set {
+	inverted := &Set{Entries: []Entry{}}
+	if m == nil {
+		return inverted
+	}
+
+	for _, entry := range m.Entries {
+		inverted.Entries = append(inverted.Entries, Entry{
+			IsUID:    entry.IsUID,
+			IsGID:    entry.IsGID,
+			HostID:   entry.NSID,
+			NSID:     entry.HostID,
+			MapRange: entry.MapRange,
+		})
+	}
+
+	sort.Sort(inverted)
+
+	return inverted
+}
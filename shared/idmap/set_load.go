@@ -130,6 +130,73 @@ func NewSetFromIncusIDMap(value string) (*Set, error) {
 	return ret, nil
 }
 
+// NewSetFromPassthrough resolves a comma-separated list of host users and groups into a Set which maps their
+// IDs to the same IDs in the container. Users are given by name and have both their UID and primary GID mapped,
+// groups are given by name prefixed with `@` and only have their GID mapped.
+func NewSetFromPassthrough(value string) (*Set, error) {
+	ret := &Set{}
+
+	hasID := func(isUID bool, id int64) bool {
+		for _, e := range ret.Entries {
+			if ((isUID && e.IsUID) || (!isUID && e.IsGID)) && id >= e.HostID && id < e.HostID+e.MapRange {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	add := func(isUID bool, id string) error {
+		hostID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		if hostID == 0 {
+			return fmt.Errorf("The host root user and group can't be passed through")
+		}
+
+		if hasID(isUID, hostID) {
+			return nil
+		}
+
+		return ret.AddSafe(Entry{IsUID: isUID, IsGID: !isUID, HostID: hostID, NSID: hostID, MapRange: 1})
+	}
+
+	for _, name := range util.SplitNTrimSpace(value, ",", -1, true) {
+		groupName, isGroup := strings.CutPrefix(name, "@")
+		if isGroup {
+			g, err := user.LookupGroup(groupName)
+			if err != nil {
+				return nil, fmt.Errorf("Failed resolving host group %q: %w", groupName, err)
+			}
+
+			err = add(false, g.Gid)
+			if err != nil {
+				return nil, fmt.Errorf("Failed mapping host group %q: %w", groupName, err)
+			}
+
+			continue
+		}
+
+		u, err := user.Lookup(name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed resolving host user %q: %w", name, err)
+		}
+
+		err = add(true, u.Uid)
+		if err == nil {
+			err = add(false, u.Gid)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed mapping host user %q: %w", name, err)
+		}
+	}
+
+	return ret, nil
+}
+
 // NewSetFromCurrentProcess returns a Set from the process' current uid/gid map.
 func NewSetFromCurrentProcess() (*Set, error) {
 	// Check if system doesn't have user namespaces.
@@ -2,6 +2,9 @@ package idmap
 
 import (
 	"fmt"
+	"os/user"
+	"slices"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -200,3 +203,237 @@ func TestIdmapHostIDMapRange(t *testing.T) {
 	assert.Equal(t, false, combinedEntry.HostIDsCoveredBy(nil, allowedCombinedMaps))
 	assert.Equal(t, true, combinedEntry.HostIDsCoveredBy(allowedCombinedMaps, allowedCombinedMaps))
 }
+
+func TestSetCompose(t *testing.T) {
+	// Container mapped to 100000 on the host, nested container mapped to 10000 in the container.
+	parent := &Set{Entries: []Entry{
+		{IsUID: true, IsGID: true, HostID: 100000, NSID: 0, MapRange: 65536},
+	}}
+
+	nested := &Set{Entries: []Entry{
+		{IsUID: true, HostID: 10000, NSID: 0, MapRange: 1000},
+		{IsGID: true, HostID: 60000, NSID: 0, MapRange: 10000},
+	}}
+
+	composed := parent.Compose(nested)
+	assert.Equal(t, []Entry{
+		{IsUID: true, HostID: 110000, NSID: 0, MapRange: 1000},
+		{IsGID: true, HostID: 160000, NSID: 0, MapRange: 5536},
+	}, composed.Entries)
+
+	// Translating an ID in one step matches chaining the shifts.
+	uid, _ := composed.ShiftIntoNS(999, 0)
+	containerUID, _ := nested.ShiftIntoNS(999, 0)
+	hostUID, _ := parent.ShiftIntoNS(containerUID, 0)
+	assert.Equal(t, hostUID, uid)
+
+	// The last ID of a range is mapped but not the one past it.
+	_, gid := composed.ShiftIntoNS(0, 5535)
+	assert.Equal(t, int64(165535), gid)
+
+	_, gid = composed.ShiftIntoNS(0, 5536)
+	assert.Equal(t, int64(-1), gid)
+
+	// Nested ranges spanning several parent entries are split.
+	parent = &Set{Entries: []Entry{
+		{IsUID: true, HostID: 100000, NSID: 0, MapRange: 1000},
+		{IsUID: true, HostID: 500000, NSID: 1000, MapRange: 1000},
+	}}
+
+	nested = &Set{Entries: []Entry{
+		{IsUID: true, HostID: 500, NSID: 0, MapRange: 1000},
+	}}
+
+	assert.Equal(t, []Entry{
+		{IsUID: true, HostID: 100500, NSID: 0, MapRange: 500},
+		{IsUID: true, HostID: 500000, NSID: 500, MapRange: 500},
+	}, parent.Compose(nested).Entries)
+
+	// Nothing maps through disjoint ranges.
+	nested = &Set{Entries: []Entry{
+		{IsUID: true, HostID: 2000, NSID: 0, MapRange: 1000},
+	}}
+
+	assert.Empty(t, parent.Compose(nested).Entries)
+}
+
+func TestSetInvert(t *testing.T) {
+	orig := &Set{Entries: []Entry{
+		{IsUID: true, IsGID: true, HostID: 100000, NSID: 0, MapRange: 65536},
+		{IsUID: true, HostID: 1000, NSID: 70000, MapRange: 1},
+	}}
+
+	inverted := orig.Invert()
+	assert.Equal(t, []Entry{
+		{IsUID: true, IsGID: true, HostID: 0, NSID: 100000, MapRange: 65536},
+		{IsUID: true, HostID: 70000, NSID: 1000, MapRange: 1},
+	}, inverted.Entries)
+
+	// Shifting through the inverted map undoes the original shift.
+	for _, id := range []int64{0, 65535, 70000} {
+		hostUID, hostGID := orig.ShiftIntoNS(id, id)
+		uid, _ := inverted.ShiftIntoNS(hostUID, hostGID)
+		assert.Equal(t, id, uid)
+	}
+
+	assert.True(t, orig.Equals(inverted.Invert()))
+}
+
+func TestSetShiftMany(t *testing.T) {
+	set := &Set{Entries: []Entry{
+		{IsUID: true, IsGID: true, HostID: 100000, NSID: 0, MapRange: 1000},
+		{IsUID: true, HostID: 1000, NSID: 1000, MapRange: 1},
+		{IsUID: true, IsGID: true, HostID: 101001, NSID: 1001, MapRange: 64535},
+		{IsUID: true, HostID: 200000, NSID: 500, MapRange: 10},
+	}}
+
+	ids := []int64{0, 499, 500, 509, 999, 1000, 1001, 65535}
+
+	uids, err := set.ShiftMany(ids, true)
+	assert.NoError(t, err)
+
+	gids, err := set.ShiftMany(slices.DeleteFunc(slices.Clone(ids), func(id int64) bool { return id == 1000 }), false)
+	assert.NoError(t, err)
+
+	for i, id := range ids {
+		uid, _ := set.ShiftIntoNS(id, 0)
+		assert.Equal(t, uid, uids[i])
+	}
+
+	for _, gid := range gids {
+		assert.NotEqual(t, int64(-1), gid)
+	}
+
+	// Unmapped IDs are reported.
+	_, err = set.ShiftMany([]int64{65536}, true)
+	assert.Error(t, err)
+
+	_, err = set.ShiftMany([]int64{1000}, false)
+	assert.Error(t, err)
+}
+
+// benchmarkSet returns a set made of many small entries and IDs spread over all of them.
+func benchmarkSet() (*Set, []int64) {
+	set := &Set{}
+	for i := int64(0); i < 256; i++ {
+		set.Entries = append(set.Entries, Entry{IsUID: true, IsGID: true, HostID: 1000000 + i*1000, NSID: i * 256, MapRange: 256})
+	}
+
+	ids := make([]int64, 0, 65536)
+	for id := int64(0); id < 65536; id++ {
+		ids = append(ids, (id*7919)%65536)
+	}
+
+	return set, ids
+}
+
+func BenchmarkSetShiftIntoNS(b *testing.B) {
+	set, ids := benchmarkSet()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, id := range ids {
+			set.ShiftIntoNS(id, 0)
+		}
+	}
+}
+
+func BenchmarkSetShiftMany(b *testing.B) {
+	set, ids := benchmarkSet()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := set.ShiftMany(ids, true)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNewSetFromPassthrough(t *testing.T) {
+	u, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("No nobody user on the host")
+	}
+
+	uid, _ := strconv.ParseInt(u.Uid, 10, 64)
+	gid, _ := strconv.ParseInt(u.Gid, 10, 64)
+
+	// Users are mapped to their UID and primary GID, listing them twice is harmless.
+	set, err := NewSetFromPassthrough("nobody, nobody")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []Entry{
+		{IsUID: true, HostID: uid, NSID: uid, MapRange: 1},
+		{IsGID: true, HostID: gid, NSID: gid, MapRange: 1},
+	}, set.Entries)
+
+	g, err := user.LookupGroupId(u.Gid)
+	if err == nil {
+		// The primary group of a user is only mapped once.
+		set, err = NewSetFromPassthrough(fmt.Sprintf("@%s,nobody", g.Name))
+		assert.NoError(t, err)
+		assert.Len(t, set.Entries, 2)
+	}
+
+	set, err = NewSetFromPassthrough("")
+	assert.NoError(t, err)
+	assert.Empty(t, set.Entries)
+
+	_, err = NewSetFromPassthrough("root")
+	assert.Error(t, err)
+
+	_, err = NewSetFromPassthrough("incus-missing-user")
+	assert.Error(t, err)
+
+	_, err = NewSetFromPassthrough("@incus-missing-group")
+	assert.Error(t, err)
+}
+
+func TestSetFindFreeRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   []Entry
+		size      int64
+		offset    int64
+		available int64
+	}{
+		{"Empty", nil, 100, 1000, 10},
+		{"Gap before", []Entry{{HostID: 1200, MapRange: 100}}, 100, 1000, 9},
+		{"Gap too small", []Entry{{HostID: 1050, MapRange: 100}, {HostID: 1300, MapRange: 100}}, 100, 1150, 7},
+		{"Overlapping entries", []Entry{{HostID: 1500, MapRange: 100}, {HostID: 1000, MapRange: 600}}, 100, 1600, 4},
+		{"Unsorted entries", []Entry{{HostID: 1800, MapRange: 200}, {HostID: 1000, MapRange: 100}}, 100, 1100, 7},
+		{"Entry past the end", []Entry{{HostID: 1000, MapRange: 900}, {HostID: 2500, MapRange: 100}}, 100, 1900, 1},
+		{"Full", []Entry{{HostID: 900, MapRange: 1200}}, 100, -1, 0},
+		{"Larger size", []Entry{{HostID: 1300, MapRange: 100}}, 300, 1000, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := &Set{Entries: slices.Clone(tt.entries)}
+
+			offset, err := set.FindFreeRange(1000, 2000, tt.size)
+			if tt.offset == -1 {
+				assert.ErrorIs(t, err, ErrNoSuitableSubmap)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.offset, offset)
+			}
+
+			assert.Equal(t, tt.available, set.CountFreeRanges(1000, 2000, tt.size))
+
+			// The count matches how many ranges can be allocated in a row.
+			allocated := int64(0)
+			for {
+				offset, err := set.FindFreeRange(1000, 2000, tt.size)
+				if err != nil {
+					break
+				}
+
+				set.Entries = append(set.Entries, Entry{HostID: offset, MapRange: tt.size})
+				allocated++
+			}
+
+			assert.Equal(t, tt.available, allocated)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Subsystems contains the names of the subsystems whose log level can be set separately.
+var Subsystems = []string{"auth", "cluster", "network", "qemu", "storage"}
+
+// subsystemKey is the context key identifying the subsystem of a log entry.
+const subsystemKey = "subsystem"
+
+var levelsMu sync.RWMutex
+var defaultLevel = logrus.WarnLevel
+var subsystemLevels = map[string]logrus.Level{}
+
+// SetLevels sets the log level of the given subsystems, an empty level resetting one to the default level.
+func SetLevels(levels map[string]string) error {
+	logLevels := make(map[string]logrus.Level, len(levels))
+	for subsystem, level := range levels {
+		if !slices.Contains(Subsystems, subsystem) {
+			return fmt.Errorf("Unknown logging subsystem %q", subsystem)
+		}
+
+		if level == "" {
+			continue
+		}
+
+		logLevel, err := logrus.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+
+		if logLevel < logrus.ErrorLevel || logLevel > logrus.DebugLevel {
+			return fmt.Errorf("Unsupported log level %q", level)
+		}
+
+		logLevels[subsystem] = logLevel
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	for subsystem := range levels {
+		logLevel, ok := logLevels[subsystem]
+		if ok {
+			subsystemLevels[subsystem] = logLevel
+		} else {
+			delete(subsystemLevels, subsystem)
+		}
+	}
+
+	return nil
+}
+
+// Levels returns the effective log level of each subsystem.
+func Levels() map[string]string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	levels := make(map[string]string, len(Subsystems))
+	for _, subsystem := range Subsystems {
+		level, ok := subsystemLevels[subsystem]
+		if !ok {
+			level = defaultLevel
+		}
+
+		levels[subsystem] = level.String()
+	}
+
+	return levels
+}
+
+// entryEnabled returns whether the entry is to be logged, using the level of its subsystem if set or else the
+// given level.
+func entryEnabled(entry *logrus.Entry, level logrus.Level) bool {
+	subsystem, ok := entry.Data[subsystemKey].(string)
+	if ok {
+		levelsMu.RLock()
+		subsystemLevel, ok := subsystemLevels[subsystem]
+		levelsMu.RUnlock()
+
+		if ok {
+			level = subsystemLevel
+		}
+	}
+
+	return entry.Level <= level
+}
+
+// levelHook writes the entries enabled by the default or subsystem log levels.
+type levelHook struct {
+	writer io.Writer
+}
+
+func (h *levelHook) Fire(entry *logrus.Entry) error {
+	levelsMu.RLock()
+	level := defaultLevel
+	levelsMu.RUnlock()
+
+	if !entryEnabled(entry, level) {
+		return nil
+	}
+
+	line, err := entry.Bytes()
+	if err != nil {
+		return err
+	}
+
+	_, err = h.writer.Write(line)
+	return err
+}
+
+func (h *levelHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
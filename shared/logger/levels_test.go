@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// resetLevels restores the default log levels once the test is done.
+func resetLevels(t *testing.T) {
+	t.Cleanup(func() {
+		levelsMu.Lock()
+		defaultLevel = logrus.WarnLevel
+		subsystemLevels = map[string]logrus.Level{}
+		levelsMu.Unlock()
+	})
+}
+
+func TestSetLevels(t *testing.T) {
+	resetLevels(t)
+
+	require.NoError(t, SetLevels(map[string]string{"storage": "debug", "network": "info"}))
+	require.Equal(t, "debug", Levels()["storage"])
+	require.Equal(t, "info", Levels()["network"])
+	require.Equal(t, "warning", Levels()["cluster"])
+	require.Len(t, Levels(), len(Subsystems))
+
+	// An empty level resets the subsystem to the default level, leaving the others alone.
+	require.NoError(t, SetLevels(map[string]string{"storage": ""}))
+	require.Equal(t, "warning", Levels()["storage"])
+	require.Equal(t, "info", Levels()["network"])
+
+	// Invalid requests don't change any level.
+	tests := []struct {
+		name   string
+		levels map[string]string
+	}{
+		{"Unknown subsystem", map[string]string{"network": "debug", "unknown": "debug"}},
+		{"Invalid level", map[string]string{"network": "debug", "storage": "verbose"}},
+		{"Unsupported level", map[string]string{"network": "debug", "storage": "trace"}},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		require.Error(t, SetLevels(tt.levels))
+		require.Equal(t, "info", Levels()["network"])
+		require.Equal(t, "warning", Levels()["storage"])
+	}
+}
+
+func TestEntryEnabled(t *testing.T) {
+	resetLevels(t)
+
+	require.NoError(t, SetLevels(map[string]string{"storage": "debug", "network": "error"}))
+
+	newEntry := func(level logrus.Level, subsystem string) *logrus.Entry {
+		entry := logrus.NewEntry(logrus.New())
+		entry.Level = level
+		if subsystem != "" {
+			entry.Data[subsystemKey] = subsystem
+		}
+
+		return entry
+	}
+
+	tests := []struct {
+		name    string
+		entry   *logrus.Entry
+		enabled bool
+	}{
+		{"Warning without subsystem", newEntry(logrus.WarnLevel, ""), true},
+		{"Info without subsystem", newEntry(logrus.InfoLevel, ""), false},
+		{"Debug of a more verbose subsystem", newEntry(logrus.DebugLevel, "storage"), true},
+		{"Warning of a less verbose subsystem", newEntry(logrus.WarnLevel, "network"), false},
+		{"Error of a less verbose subsystem", newEntry(logrus.ErrorLevel, "network"), true},
+		{"Info of a subsystem at the default level", newEntry(logrus.InfoLevel, "cluster"), false},
+		{"Warning of a subsystem at the default level", newEntry(logrus.WarnLevel, "cluster"), true},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		require.Equal(t, tt.enabled, entryEnabled(tt.entry, logrus.WarnLevel))
+	}
+}
+
+func TestSubsystemLogger(t *testing.T) {
+	resetLevels(t)
+
+	oldLog := Log
+	t.Cleanup(func() { Log = oldLog })
+
+	var buf bytes.Buffer
+	target := logrus.New()
+	target.Level = logrus.DebugLevel
+	target.SetOutput(&bytes.Buffer{})
+	target.Formatter = &logrus.TextFormatter{DisableTimestamp: true}
+	target.AddHook(&levelHook{writer: &buf})
+	Log = newWrapper(target)
+
+	require.NoError(t, SetLevels(map[string]string{"storage": "debug"}))
+
+	storage := Subsystem("storage")
+	network := Subsystem("network")
+
+	storage.Debug("storage debug")
+	network.Debug("network debug")
+	network.Warn("network warning")
+	Debug("main debug")
+
+	require.Contains(t, buf.String(), `msg="storage debug" subsystem=storage`)
+	require.Contains(t, buf.String(), `msg="network warning" subsystem=network`)
+	require.NotContains(t, buf.String(), "network debug")
+	require.NotContains(t, buf.String(), "main debug")
+
+	// The logger with the subsystem context is only built again when the main logger changes.
+	require.Same(t, storage.logger(), storage.logger())
+
+	previous := storage.logger()
+	Log = newWrapper(target)
+	require.NotSame(t, previous, storage.logger())
+}
@@ -5,7 +5,6 @@ import (
 	"os"
 
 	"github.com/sirupsen/logrus"
-	lWriter "github.com/sirupsen/logrus/hooks/writer"
 
 	"github.com/lxc/incus/v6/shared/termios"
 )
@@ -27,14 +26,18 @@ func InitLogger(filepath string, syslogName string, verbose bool, debug bool, ho
 	// Setup the formatter.
 	logger.Formatter = &logrus.TextFormatter{PadLevelText: true, FullTimestamp: true, ForceColors: termios.IsTerminal(int(os.Stderr.Fd()))}
 
-	// Setup log level.
-	levels := []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+	// Setup the default log level, which subsystems can override.
+	level := logrus.WarnLevel
 	if debug {
-		levels = append(levels, logrus.InfoLevel, logrus.DebugLevel)
+		level = logrus.DebugLevel
 	} else if verbose {
-		levels = append(levels, logrus.InfoLevel)
+		level = logrus.InfoLevel
 	}
 
+	levelsMu.Lock()
+	defaultLevel = level
+	levelsMu.Unlock()
+
 	// Setup writers.
 	writers := []io.Writer{os.Stderr}
 
@@ -47,10 +50,7 @@ func InitLogger(filepath string, syslogName string, verbose bool, debug bool, ho
 		writers = append(writers, f)
 	}
 
-	logger.AddHook(&lWriter.Hook{
-		Writer:    io.MultiWriter(writers...),
-		LogLevels: levels,
-	})
+	logger.AddHook(&levelHook{writer: io.MultiWriter(writers...)})
 
 	// Setup syslog.
 	if syslogName != "" {
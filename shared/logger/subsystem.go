@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// SubsystemLogger is a logger tagging its entries with a subsystem, so that they follow the log level of the
+// subsystem. It uses the main logger at the time of each call and so can be created before it's initialized.
+type SubsystemLogger struct {
+	name string
+
+	// The main logger with the subsystem context added, built again when the main logger changes.
+	target atomic.Pointer[subsystemTarget]
+}
+
+// subsystemTarget is the main logger with the subsystem context added.
+type subsystemTarget struct {
+	main   Logger
+	logger Logger
+}
+
+// Subsystem returns a logger for the given subsystem.
+func Subsystem(name string) *SubsystemLogger {
+	return &SubsystemLogger{name: name}
+}
+
+// logger returns the main logger with the subsystem context added.
+func (l *SubsystemLogger) logger() Logger {
+	base := Log
+
+	target := l.target.Load()
+	if target == nil || target.main != base {
+		target = &subsystemTarget{main: base, logger: base.AddContext(Ctx{subsystemKey: l.name})}
+		l.target.Store(target)
+	}
+
+	return target.logger
+}
+
+// Panic logs a message (with optional context) at the PANIC log level.
+func (l *SubsystemLogger) Panic(msg string, ctx ...Ctx) {
+	l.logger().Panic(msg, ctx...)
+}
+
+// Fatal logs a message (with optional context) at the FATAL log level.
+func (l *SubsystemLogger) Fatal(msg string, ctx ...Ctx) {
+	l.logger().Fatal(msg, ctx...)
+}
+
+// Error logs a message (with optional context) at the ERROR log level.
+func (l *SubsystemLogger) Error(msg string, ctx ...Ctx) {
+	l.logger().Error(msg, ctx...)
+}
+
+// Warn logs a message (with optional context) at the WARNING log level.
+func (l *SubsystemLogger) Warn(msg string, ctx ...Ctx) {
+	l.logger().Warn(msg, ctx...)
+}
+
+// Info logs a message (with optional context) at the INFO log level.
+func (l *SubsystemLogger) Info(msg string, ctx ...Ctx) {
+	l.logger().Info(msg, ctx...)
+}
+
+// Debug logs a message (with optional context) at the DEBUG log level.
+func (l *SubsystemLogger) Debug(msg string, ctx ...Ctx) {
+	l.logger().Debug(msg, ctx...)
+}
+
+// Trace logs a message (with optional context) at the TRACE log level.
+func (l *SubsystemLogger) Trace(msg string, ctx ...Ctx) {
+	l.logger().Trace(msg, ctx...)
+}
+
+// Tracef logs at the TRACE log level using a standard printf format string.
+func (l *SubsystemLogger) Tracef(format string, args ...any) {
+	l.logger().Trace(fmt.Sprintf(format, args...))
+}
+
+// Errorf logs at the ERROR log level using a standard printf format string.
+func (l *SubsystemLogger) Errorf(format string, args ...any) {
+	l.logger().Error(fmt.Sprintf(format, args...))
+}
+
+// Warnf logs at the WARNING log level using a standard printf format string.
+func (l *SubsystemLogger) Warnf(format string, args ...any) {
+	l.logger().Warn(fmt.Sprintf(format, args...))
+}
+
+// Infof logs at the INFO log level using a standard printf format string.
+func (l *SubsystemLogger) Infof(format string, args ...any) {
+	l.logger().Info(fmt.Sprintf(format, args...))
+}
+
+// Debugf logs at the DEBUG log level using a standard printf format string.
+func (l *SubsystemLogger) Debugf(format string, args ...any) {
+	l.logger().Debug(fmt.Sprintf(format, args...))
+}
+
+// AddContext returns a new logger with the subsystem and the given context added.
+func (l *SubsystemLogger) AddContext(ctx Ctx) Logger {
+	return l.logger().AddContext(ctx)
+}
@@ -14,6 +14,10 @@ type syslogHandler struct {
 }
 
 func (h syslogHandler) Fire(entry *logrus.Entry) error {
+	if !entryEnabled(entry, logrus.InfoLevel) {
+		return nil
+	}
+
 	return h.handler.Fire(entry)
 }
 
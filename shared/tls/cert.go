@@ -507,6 +507,38 @@ func CertificateTokenDecode(input string) (*api.CertificateAddToken, error) {
 	return &j, nil
 }
 
+// InstanceShareTokenDecode decodes a base64 and JSON encoded instance share token.
+func InstanceShareTokenDecode(input string) (*api.InstanceShareToken, error) {
+	tokenJSON, err := base64.StdEncoding.DecodeString(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var j api.InstanceShareToken
+	err = json.Unmarshal(tokenJSON, &j)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.Instance == "" {
+		return nil, fmt.Errorf("No instance in instance share token")
+	}
+
+	if len(j.Addresses) < 1 {
+		return nil, fmt.Errorf("No server addresses in instance share token")
+	}
+
+	if j.Secret == "" {
+		return nil, fmt.Errorf("No secret in instance share token")
+	}
+
+	if j.Fingerprint == "" {
+		return nil, fmt.Errorf("No certificate fingerprint in instance share token")
+	}
+
+	return &j, nil
+}
+
 // GenerateTrustCertificate converts the specified serverCert and serverName into an api.Certificate suitable for
 // use as a trusted cluster server certificate.
 func GenerateTrustCertificate(cert *CertInfo, name string) (*api.Certificate, error) {
@@ -2,20 +2,164 @@ package util
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lxc/incus/v6/shared/cancel"
 	"github.com/lxc/incus/v6/shared/ioprogress"
 	"github.com/lxc/incus/v6/shared/units"
 )
 
+// DownloadResume configures how DownloadFileHashResume resumes transfers.
+type DownloadResume struct {
+	// Keep the existing content of the target and only fetch the rest (the target must also implement io.Reader).
+	Existing bool
+
+	// Maximum number of times an interrupted transfer is resumed.
+	Retries int
+
+	// Called before resuming an interrupted transfer.
+	RetryHandler func(attempt int, err error)
+}
+
+// errDownloadFailed is an error which can't be recovered from by resuming the transfer.
+type errDownloadFailed struct {
+	err error
+}
+
+func (e errDownloadFailed) Error() string {
+	return e.err.Error()
+}
+
+func (e errDownloadFailed) Unwrap() error {
+	return e.err
+}
+
+// truncateTarget drops the content of the target past the given size, left by a previous larger content.
+// Targets which can't be truncated are left as they are.
+func truncateTarget(target io.WriteSeeker, size int64) error {
+	truncater, ok := target.(interface{ Truncate(size int64) error })
+	if !ok {
+		return nil
+	}
+
+	return truncater.Truncate(size)
+}
+
+// DownloadFileHash downloads a file and validates its hash.
 func DownloadFileHash(ctx context.Context, httpClient *http.Client, useragent string, progress func(progress ioprogress.ProgressData), canceler *cancel.HTTPRequestCanceller, filename string, url string, hash string, hashFunc hash.Hash, target io.WriteSeeker) (int64, error) {
-	// Always seek to the beginning
-	_, _ = target.Seek(0, io.SeekStart)
+	return DownloadFileHashResume(ctx, httpClient, useragent, progress, canceler, filename, url, hash, hashFunc, target, nil)
+}
+
+// DownloadFileHashResume downloads a file and validates its hash, using range requests to resume from the
+// existing content of the target and after transfer interruptions as configured by resume.
+func DownloadFileHashResume(ctx context.Context, httpClient *http.Client, useragent string, progress func(progress ioprogress.ProgressData), canceler *cancel.HTTPRequestCanceller, filename string, url string, hash string, hashFunc hash.Hash, target io.WriteSeeker, resume *DownloadResume) (int64, error) {
+	if resume == nil {
+		resume = &DownloadResume{}
+	}
+
+	var offset int64
+
+	// Hash the existing content.
+	reader, ok := target.(io.Reader)
+	if resume.Existing && ok && hashFunc != nil {
+		_, err := target.Seek(0, io.SeekStart)
+		if err != nil {
+			return -1, err
+		}
+
+		offset, err = io.Copy(hashFunc, reader)
+		if err != nil {
+			return -1, err
+		}
+	} else {
+		// Always seek to the beginning
+		_, _ = target.Seek(0, io.SeekStart)
+	}
+
+	restarted := offset == 0
+	for attempt := 0; ; attempt++ {
+		size, err := downloadFileRange(ctx, httpClient, useragent, progress, canceler, filename, url, hashFunc, target, offset)
+		if err == nil {
+			if hashFunc == nil {
+				err = truncateTarget(target, size)
+				if err != nil {
+					return -1, err
+				}
+
+				return size, nil
+			}
+
+			result := fmt.Sprintf("%x", hashFunc.Sum(nil))
+			if result == hash {
+				err = truncateTarget(target, size)
+				if err != nil {
+					return -1, err
+				}
+
+				return size, nil
+			}
+
+			// The existing content may be corrupted, try again from scratch.
+			if !restarted {
+				restarted = true
+				offset = 0
+				hashFunc.Reset()
+
+				_, err = target.Seek(0, io.SeekStart)
+				if err != nil {
+					return -1, err
+				}
+
+				err = truncateTarget(target, 0)
+				if err != nil {
+					return -1, err
+				}
 
+				continue
+			}
+
+			return -1, fmt.Errorf("Hash mismatch for %s: %s != %s", url, result, hash)
+		}
+
+		var failedErr errDownloadFailed
+		if errors.As(err, &failedErr) || attempt >= resume.Retries || (ctx != nil && ctx.Err() != nil) || err.Error() == "net/http: request canceled" {
+			return -1, err
+		}
+
+		if resume.RetryHandler != nil {
+			resume.RetryHandler(attempt+1, err)
+		}
+
+		// Resume from what was written so far.
+		offset, err = target.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1, err
+		}
+
+		backoff := time.Duration(1<<min(attempt, 5)) * time.Second
+		if ctx != nil {
+			select {
+			case <-ctx.Done():
+				return -1, ctx.Err()
+			case <-time.After(backoff):
+			}
+		} else {
+			time.Sleep(backoff)
+		}
+	}
+}
+
+// downloadFileRange downloads a file from the given offset into the target, feeding it to hashFunc.
+// If the server doesn't honor the range, the target and hash are reset and the whole file is fetched.
+// It returns the total size of the file.
+func downloadFileRange(ctx context.Context, httpClient *http.Client, useragent string, progress func(progress ioprogress.ProgressData), canceler *cancel.HTTPRequestCanceller, filename string, url string, hashFunc hash.Hash, target io.WriteSeeker, offset int64) (int64, error) {
 	var req *http.Request
 	var err error
 
@@ -27,13 +171,17 @@ func DownloadFileHash(ctx context.Context, httpClient *http.Client, useragent st
 	}
 
 	if err != nil {
-		return -1, err
+		return -1, errDownloadFailed{err: err}
 	}
 
 	if useragent != "" {
 		req.Header.Set("User-Agent", useragent)
 	}
 
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
 	// Perform the request
 	r, doneCh, err := cancel.CancelableDownload(canceler, httpClient.Do, req)
 	if err != nil {
@@ -43,8 +191,57 @@ func DownloadFileHash(ctx context.Context, httpClient *http.Client, useragent st
 	defer func() { _ = r.Body.Close() }()
 	defer close(doneCh)
 
-	if r.StatusCode != http.StatusOK {
-		return -1, fmt.Errorf("Unable to fetch %s: %s", url, r.Status)
+	switch {
+	case r.StatusCode == http.StatusOK:
+		// The whole file is being sent.
+		if offset > 0 {
+			offset = 0
+
+			if hashFunc != nil {
+				hashFunc.Reset()
+			}
+		}
+
+		_, err = target.Seek(0, io.SeekStart)
+		if err != nil {
+			return -1, errDownloadFailed{err: err}
+		}
+
+		err = truncateTarget(target, 0)
+		if err != nil {
+			return -1, errDownloadFailed{err: err}
+		}
+
+	case r.StatusCode == http.StatusPartialContent && offset > 0:
+		// Check the server is sending the expected range.
+		start, _, _ := strings.Cut(strings.TrimPrefix(r.Header.Get("Content-Range"), "bytes "), "-")
+		if start != strconv.FormatInt(offset, 10) {
+			return -1, errDownloadFailed{err: fmt.Errorf("Unexpected range %q for %s", r.Header.Get("Content-Range"), url)}
+		}
+
+		_, err = target.Seek(offset, io.SeekStart)
+		if err != nil {
+			return -1, errDownloadFailed{err: err}
+		}
+
+	case r.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The existing content is already complete (or larger than the file and its hash won't match).
+		return offset, nil
+
+	default:
+		err := fmt.Errorf("Unable to fetch %s: %s", url, r.Status)
+
+		// Only retry server-side errors.
+		if r.StatusCode/100 != 5 {
+			return -1, errDownloadFailed{err: err}
+		}
+
+		return -1, err
+	}
+
+	length := r.ContentLength
+	if length > 0 {
+		length += offset
 	}
 
 	// Handle the data
@@ -55,6 +252,11 @@ func DownloadFileHash(ctx context.Context, httpClient *http.Client, useragent st
 			Tracker: &ioprogress.ProgressTracker{
 				Length: r.ContentLength,
 				Handler: func(percent int64, speed int64) {
+					// Account for the content which was already there.
+					if length > 0 && offset > 0 {
+						percent = (offset*100 + percent*r.ContentLength) / length
+					}
+
 					if filename != "" {
 						progress(ioprogress.ProgressData{Text: fmt.Sprintf("%s: %d%% (%s/s)", filename, percent, units.GetByteSizeString(speed, 2))})
 					} else {
@@ -65,24 +267,15 @@ func DownloadFileHash(ctx context.Context, httpClient *http.Client, useragent st
 		}
 	}
 
-	var size int64
-
+	var writer io.Writer = target
 	if hashFunc != nil {
-		size, err = io.Copy(io.MultiWriter(target, hashFunc), body)
-		if err != nil {
-			return -1, err
-		}
+		writer = io.MultiWriter(target, hashFunc)
+	}
 
-		result := fmt.Sprintf("%x", hashFunc.Sum(nil))
-		if result != hash {
-			return -1, fmt.Errorf("Hash mismatch for %s: %s != %s", url, result, hash)
-		}
-	} else {
-		size, err = io.Copy(target, body)
-		if err != nil {
-			return -1, err
-		}
+	size, err := io.Copy(writer, body)
+	if err != nil {
+		return -1, err
 	}
 
-	return size, nil
+	return offset + size, nil
 }
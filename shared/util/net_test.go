@@ -0,0 +1,140 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// downloadContent is the file served in the download tests.
+var downloadContent = bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+// downloadTarget returns a target file holding the given content.
+func downloadTarget(t *testing.T, content []byte) *os.File {
+	path := filepath.Join(t.TempDir(), "target")
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	target, err := os.OpenFile(path, os.O_RDWR, 0)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = target.Close() })
+
+	return target
+}
+
+func TestDownloadFileHashResume(t *testing.T) {
+	hash := fmt.Sprintf("%x", sha256.Sum256(downloadContent))
+
+	ranged := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(downloadContent))
+	})
+
+	unranged := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(downloadContent)
+	})
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		existing []byte
+		resume   bool
+	}{
+		{"New download", ranged, nil, true},
+		{"Resumed download", ranged, downloadContent[:1000], true},
+		{"Complete download", ranged, downloadContent, true},
+		{"Corrupted partial download", ranged, []byte("corrupted"), true},
+		{"Corrupted larger partial download", ranged, append(bytes.Clone(downloadContent), []byte("stale")...), true},
+		{"Server ignoring ranges", unranged, append(bytes.Clone(downloadContent[:1000]), []byte("stale")...), true},
+		{"Stale larger content without resume", ranged, append(bytes.Clone(downloadContent), []byte("stale")...), false},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+
+		server := httptest.NewServer(tt.handler)
+		target := downloadTarget(t, tt.existing)
+
+		size, err := DownloadFileHashResume(context.Background(), server.Client(), "", nil, nil, "", server.URL, hash, sha256.New(), target, &DownloadResume{Existing: tt.resume})
+		server.Close()
+		require.NoError(t, err)
+		require.Equal(t, int64(len(downloadContent)), size)
+
+		content, err := os.ReadFile(target.Name())
+		require.NoError(t, err)
+		require.Equal(t, downloadContent, content)
+	}
+}
+
+func TestDownloadFileHashResume_Interrupted(t *testing.T) {
+	hash := fmt.Sprintf("%x", sha256.Sum256(downloadContent))
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Interrupt the first transfer halfway through.
+		if requests.Add(1) == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(downloadContent)))
+			_, _ = w.Write(downloadContent[:len(downloadContent)/2])
+			return
+		}
+
+		require.Equal(t, fmt.Sprintf("bytes=%d-", len(downloadContent)/2), r.Header.Get("Range"))
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(downloadContent))
+	}))
+	defer server.Close()
+
+	retries := []int{}
+	resume := &DownloadResume{
+		Retries:      1,
+		RetryHandler: func(attempt int, err error) { retries = append(retries, attempt) },
+	}
+
+	target := downloadTarget(t, nil)
+	size, err := DownloadFileHashResume(context.Background(), server.Client(), "", nil, nil, "", server.URL, hash, sha256.New(), target, resume)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(downloadContent)), size)
+	require.Equal(t, []int{1}, retries)
+	require.Equal(t, int32(2), requests.Load())
+
+	content, err := os.ReadFile(target.Name())
+	require.NoError(t, err)
+	require.Equal(t, downloadContent, content)
+}
+
+func TestDownloadFileHashResume_Failures(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(downloadContent))
+	}))
+	defer server.Close()
+
+	// A hash mismatch of a new download isn't retried.
+	target := downloadTarget(t, nil)
+	_, err := DownloadFileHashResume(context.Background(), server.Client(), "", nil, nil, "", server.URL, "invalid", sha256.New(), target, &DownloadResume{Retries: 5})
+	require.ErrorContains(t, err, "Hash mismatch")
+	require.Equal(t, int32(1), requests.Load())
+
+	// Client errors aren't retried.
+	requests.Store(0)
+	_, err = DownloadFileHashResume(context.Background(), server.Client(), "", nil, nil, "", server.URL+"/missing", "invalid", sha256.New(), target, &DownloadResume{Retries: 5})
+	require.ErrorContains(t, err, "404")
+	require.Equal(t, int32(1), requests.Load())
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/kballard/go-shellquote"
 	"github.com/robfig/cron/v3"
+	"golang.org/x/crypto/ssh"
 	"gopkg.in/yaml.v2"
 
 	"github.com/lxc/incus/v6/shared/osarch"
@@ -783,6 +784,39 @@ func IsCloudInitUserData(value string) error {
 	return nil
 }
 
+// IsSSHAuthorizedKey checks value is a single valid SSH authorized key line.
+func IsSSHAuthorizedKey(value string) error {
+	if strings.Contains(strings.TrimSpace(value), "\n") {
+		return fmt.Errorf("Only a single key is allowed")
+	}
+
+	_, _, _, _, err := ssh.ParseAuthorizedKey([]byte(value))
+	if err != nil {
+		return fmt.Errorf("Invalid SSH key: %w", err)
+	}
+
+	return nil
+}
+
+// IsDNSSDService checks value is a DNS-SD service in the `_<service>._<tcp|udp>:<port>` format.
+func IsDNSSDService(value string) error {
+	service, port, found := strings.Cut(value, ":")
+	if !found {
+		return fmt.Errorf("Service must be in the _<service>._<tcp|udp>:<port> format")
+	}
+
+	name, proto, found := strings.Cut(service, ".")
+	if !found || !slices.Contains([]string{"_tcp", "_udp"}, proto) {
+		return fmt.Errorf("Service protocol must be _tcp or _udp")
+	}
+
+	if !regexp.MustCompile(`^_[a-z0-9]([a-z0-9-]{0,13}[a-z0-9])?$`).MatchString(name) {
+		return fmt.Errorf("Invalid service name %q", name)
+	}
+
+	return IsNetworkPort(port)
+}
+
 // IsYAML checks value is valid YAML.
 func IsYAML(value string) error {
 	out := struct{}{}
@@ -140,3 +140,61 @@ func ExampleIsValidCPUSet() {
 	// Cannot define CPU multiple times
 	// Cannot define CPU multiple times
 }
+
+func ExampleIsSSHAuthorizedKey() {
+	tests := []string{
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGLWdop",                                   // valid
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGLWdop user@host",                         // valid with comment
+		`restrict,command="uptime" ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGLWdop`,         // valid with options
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGLWdop\n",                                 // valid with trailing newline
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDtqJ7zOtqQtYqOo0CpvDXNlMhV3HeJDpjrASKGLWdop\nssh-ed25519 AAAAC3NzaC1lZDI1NTE5", // two keys
+		"ssh-ed25519 AAAAinvalid", // invalid key data
+		"invalid",
+		"",
+	}
+
+	for _, v := range tests {
+		err := validate.IsSSHAuthorizedKey(v)
+		fmt.Printf("%t\n", err == nil)
+	}
+
+	// Output: true
+	// true
+	// true
+	// true
+	// false
+	// false
+	// false
+	// false
+}
+
+func ExampleIsDNSSDService() {
+	tests := []string{
+		"_http._tcp:80",             // valid
+		"_dns-sd._udp:5353",         // valid
+		"_http._tcp",                // missing port
+		"_http._sctp:80",            // invalid protocol
+		"http._tcp:80",              // missing underscore
+		"_HTTP._tcp:80",             // upper case
+		"_http-._tcp:80",            // trailing dash
+		"_averylongservice._tcp:80", // name too long
+		"_http._tcp:65536",          // invalid port
+		"",
+	}
+
+	for _, v := range tests {
+		err := validate.IsDNSSDService(v)
+		fmt.Printf("%s, %t\n", v, err == nil)
+	}
+
+	// Output: _http._tcp:80, true
+	// _dns-sd._udp:5353, true
+	// _http._tcp, false
+	// _http._sctp:80, false
+	// http._tcp:80, false
+	// _HTTP._tcp:80, false
+	// _http-._tcp:80, false
+	// _averylongservice._tcp:80, false
+	// _http._tcp:65536, false
+	// , false
+}
@@ -131,6 +131,35 @@ test_basic_usage() {
   incus list | grep foo
   incus rename foo bar
 
+  # Test renaming a running container
+  if [ "${incus_backend}" != "ceph" ]; then
+    incus start bar
+    PID=$(incus info bar | awk '/^PID:/ {print $2}')
+    incus rename bar baz
+
+    # The container keeps running under its previous name.
+    [ "$(incus config get baz volatile.runtime.name)" = "bar" ]
+    [ "$(incus info baz | awk '/^PID:/ {print $2}')" = "${PID}" ]
+    grep -qF "lxc.payload.bar" "/proc/${PID}/cgroup"
+    [ -d "${INCUS_DIR}/logs/baz" ]
+    incus exec baz -- true
+
+    # The previous name can't be reused until the container stops.
+    ! incus init testimage bar || false
+
+    # Renaming back to the previous name forgets about it.
+    incus rename baz bar
+    [ "$(incus config get bar volatile.runtime.name)" = "" ]
+    incus rename bar baz
+
+    # The container runs under its new name once restarted.
+    incus restart baz -f
+    [ "$(incus config get baz volatile.runtime.name)" = "" ]
+    grep -qF "lxc.payload.baz" "/proc/$(incus info baz | awk '/^PID:/ {print $2}')/cgroup"
+    incus stop baz -f
+    incus rename baz bar
+  fi
+
   # Test container copy
   incus copy bar foo
   incus delete foo